@@ -21,7 +21,7 @@ func main() {
 
 // runApp initializes and executes the primary command for the application and manages lifecycle signals gracefully.
 // It listens for termination signals (SIGINT, SIGTERM) to clean up the application state before exiting.
-// Returns 1 if command execution fails due to an error; otherwise, returns 0 for successful execution.
+// Returns 0 for successful execution, otherwise the exit code carried by the command's error (see cmd.ExitCode).
 func runApp() int {
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
@@ -31,9 +31,5 @@ func runApp() int {
 		AppName: name,
 	})
 
-	if err := command.ExecuteContext(ctx); err != nil {
-		return 1
-	}
-
-	return 0
+	return cmd.ExitCode(command.ExecuteContext(ctx))
 }
@@ -0,0 +1,235 @@
+package omnidex
+
+import (
+	"log/slog"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/api"
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/ksysoev/omnidex/pkg/prov/asciidoc"
+	"github.com/ksysoev/omnidex/pkg/prov/embed"
+	"github.com/ksysoev/omnidex/pkg/prov/github"
+	"github.com/ksysoev/omnidex/pkg/prov/linkcheck"
+	"github.com/ksysoev/omnidex/pkg/prov/llm"
+	"github.com/ksysoev/omnidex/pkg/prov/mail"
+	"github.com/ksysoev/omnidex/pkg/prov/markdown"
+	"github.com/ksysoev/omnidex/pkg/prov/oidc"
+	"github.com/ksysoev/omnidex/pkg/prov/policy"
+	"github.com/ksysoev/omnidex/pkg/repo/s3store"
+	"github.com/ksysoev/omnidex/pkg/repo/search"
+	"github.com/ksysoev/omnidex/pkg/views"
+)
+
+// Config holds the configuration of an Omnidex server, as read by the server command from
+// its configuration file and environment variables. A server reads its configuration
+// once, so its sections are kept in a readable order, with storage, search and the API
+// first, rather than the one fieldalignment suggests.
+type Config struct { //nolint:govet // fieldalignment: read once at startup, sections are kept in a readable order
+	Storage   StorageConfig         `mapstructure:"storage"`
+	Search    SearchConfig          `mapstructure:"search"`
+	API       api.Config            `mapstructure:"api"`
+	Markdown  markdown.Config       `mapstructure:"markdown"`
+	Lint      core.LintConfig       `mapstructure:"lint"`
+	Policy    policy.Config         `mapstructure:"policy"`
+	Warmup    WarmupConfig          `mapstructure:"warmup"`
+	OIDC      oidc.Config           `mapstructure:"oidc"`
+	Republish github.Config         `mapstructure:"republish"`
+	LinkCheck linkcheck.Config      `mapstructure:"link_check"`
+	Freshness views.FreshnessConfig `mapstructure:"freshness"`
+	Dedup     DedupConfig           `mapstructure:"dedup"`
+	Summary   llm.Config            `mapstructure:"summary"`
+	Edit      core.EditConfig       `mapstructure:"edit"`
+	Suggest   core.SuggestConfig    `mapstructure:"suggest"`
+	SMTP      mail.Config           `mapstructure:"smtp"`
+	Digest    core.DigestConfig     `mapstructure:"digest"`
+	Journal   JournalConfig         `mapstructure:"journal"`
+	Render    RenderBudgetConfig    `mapstructure:"render_budget"`
+	Saved     SavedSearchConfig     `mapstructure:"saved_searches"`
+	UI        UIConfig              `mapstructure:"ui"`
+	AsciiDoc  asciidoc.Config       `mapstructure:"asciidoc"`
+	Retention RetentionConfig       `mapstructure:"retention"`
+}
+
+// LogValue implements slog.LogValuer. The configuration is logged field by field, keyed
+// by configuration names, with the secrets of fields tagged redact:"true", such as
+// passwords and API keys, replaced by a placeholder. The entries of fields tagged
+// redact:"value" are name=secret pairs and keep their names.
+func (c Config) LogValue() slog.Value { //nolint:gocritic // slog resolves Config values, so the receiver is a value
+	return configValue(reflect.ValueOf(c))
+}
+
+// sqliteFileName is the name of the database file the "sqlite" storage backend keeps in
+// the storage path.
+const sqliteFileName = "omnidex.db"
+
+// StorageConfig holds configuration for document storage.
+// Type selects the storage backend: "local" (default), "git", "s3" or "sqlite".
+type StorageConfig struct {
+	Path string         `mapstructure:"path"`
+	Type string         `mapstructure:"type"`
+	S3   s3store.Config `mapstructure:"s3"`
+}
+
+// SearchConfig holds configuration for the search engine.
+// Type selects the search backend: "bleve" (default), "elasticsearch", "opensearch" or
+// "meilisearch". Semantic configures the embedding model of semantic search, whose vectors
+// are kept next to IndexPath with a ".vectors" suffix whatever the backend, and Hybrid how
+// keyword and semantic results are merged. Experiment runs a ranking experiment comparing
+// hybrid ranking configurations. Migration writes the index to a second backend as well,
+// to move to it without downtime.
+type SearchConfig struct {
+	Meilisearch search.MeilisearchConfig   `mapstructure:"meilisearch"`
+	IndexPath   string                     `mapstructure:"index_path"`
+	Type        string                     `mapstructure:"type"`
+	Elastic     search.ElasticSearchConfig `mapstructure:"elasticsearch"`
+	OpenSearch  search.OpenSearchConfig    `mapstructure:"opensearch"`
+	Experiment  core.ExperimentConfig      `mapstructure:"experiment"`
+	Migration   SearchMigrationConfig      `mapstructure:"migration"`
+	Semantic    embed.Config               `mapstructure:"semantic"`
+	Hybrid      core.HybridConfig          `mapstructure:"hybrid"`
+	Bleve       search.BleveConfig         `mapstructure:"bleve"`
+}
+
+// redacted replaces the secrets of a logged configuration.
+const redacted = "[REDACTED]"
+
+// configValue returns the log value of a configuration value. Structs with fields read
+// from the configuration are logged as groups of those fields, and other values as is.
+func configValue(v reflect.Value) slog.Value {
+	if v.Kind() == reflect.Struct {
+		if attrs := configAttrs(v); attrs != nil {
+			return slog.GroupValue(attrs...)
+		}
+	}
+
+	return slog.AnyValue(v.Interface())
+}
+
+// configAttrs returns an attribute for each field of a configuration struct read from the
+// configuration, keyed by its configuration name, with its secrets redacted, or nil when
+// the struct has no such fields. Fields left out of the configuration are skipped, and
+// the fields of squashed structs are inlined.
+func configAttrs(v reflect.Value) []slog.Attr {
+	var attrs []slog.Attr
+
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+
+		switch {
+		case opts == "squash":
+			attrs = append(attrs, configAttrs(v.Field(i))...)
+		case name == "" || name == "-" || !field.IsExported():
+			continue
+		case field.Tag.Get("redact") == "true":
+			attrs = append(attrs, slog.Attr{Key: name, Value: redactValue(v.Field(i), func(string) string { return redacted })})
+		case field.Tag.Get("redact") == "value":
+			attrs = append(attrs, slog.Attr{Key: name, Value: redactValue(v.Field(i), func(entry string) string {
+				scope, _, _ := strings.Cut(entry, "=")
+				return scope + "=" + redacted
+			})})
+		default:
+			attrs = append(attrs, slog.Attr{Key: name, Value: configValue(v.Field(i))})
+		}
+	}
+
+	return attrs
+}
+
+// redactValue returns the log value of a secret string, or a list of them, with each
+// secret replaced by redact. Secrets left empty stay empty, showing they are not set.
+func redactValue(v reflect.Value, redact func(string) string) slog.Value {
+	switch v.Kind() {
+	case reflect.String:
+		if v.String() == "" {
+			return slog.StringValue("")
+		}
+
+		return slog.StringValue(redact(v.String()))
+	case reflect.Slice:
+		secrets := make([]string, v.Len())
+		for i := range secrets {
+			secrets[i] = redact(v.Index(i).String())
+		}
+
+		return slog.AnyValue(secrets)
+	default:
+		return slog.StringValue(redacted)
+	}
+}
+
+// SearchMigrationConfig holds configuration for migrating the search index to another
+// backend. Target is the backend migrated to: "elasticsearch", "opensearch" or
+// "meilisearch", configured in its own section. While it is set every index change is
+// written to both backends, and ReadTarget serves searches from the target.
+type SearchMigrationConfig struct {
+	Target     string `mapstructure:"target"`
+	ReadTarget bool   `mapstructure:"read_target"`
+}
+
+// Enabled reports whether a search migration is configured.
+func (c SearchMigrationConfig) Enabled() bool {
+	return c.Target != ""
+}
+
+// WarmupConfig holds configuration for the optional startup warm-up, which renders
+// documents and runs search queries before the readiness probe passes.
+// Queries defaults to the titles of the rendered documents.
+type WarmupConfig struct {
+	Queries     []string      `mapstructure:"queries"`
+	Timeout     time.Duration `mapstructure:"timeout"`       // Upper bound on the warm-up (default 2m).
+	DocsPerRepo int           `mapstructure:"docs_per_repo"` // Documents rendered per repository (default 5).
+	Enabled     bool          `mapstructure:"enabled"`
+}
+
+// JournalConfig holds configuration for the change journal, which records the documents
+// changed by each ingest request for digests. Changes older than Retention are discarded on
+// startup and by the maintenance scheduler, see RetentionConfig.
+type JournalConfig struct {
+	Path      string        `mapstructure:"path"`      // Journal file (default ./data/changes.jsonl).
+	Retention time.Duration `mapstructure:"retention"` // How long changes are kept (default 2160h, 90 days).
+}
+
+// RetentionConfig holds configuration for the maintenance scheduler, which discards data
+// past its retention every Interval. History is how long the git storage backend keeps
+// document versions after they are replaced or deleted; zero keeps them forever. The
+// change journal is kept for journal.retention.
+type RetentionConfig struct {
+	History  time.Duration `mapstructure:"history"`  // How long replaced versions are kept (default forever).
+	Interval time.Duration `mapstructure:"interval"` // How often data is pruned (default 24h).
+}
+
+// SavedSearchConfig holds configuration for saved searches, whose newly published matches
+// are listed in a feed and posted to webhooks. Webhooks may only be delivered to
+// WebhookHosts, and their payloads link to documents under PortalURL.
+type SavedSearchConfig struct {
+	Path         string   `mapstructure:"path"`       // Saved search file (default ./data/saved_searches.json).
+	PortalURL    string   `mapstructure:"portal_url"` // Base URL of document links in webhook payloads.
+	WebhookHosts []string `mapstructure:"webhook_hosts"`
+	Enabled      bool     `mapstructure:"enabled"`
+}
+
+// DedupConfig holds configuration for finding documents published identically in several
+// repositories.
+type DedupConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// UIConfig holds configuration for the pages of the portal. DisableInlineScripts leaves
+// their inline scripts out, for deployments whose Content-Security-Policy forbids them.
+// KaTeXURL is the base URL of a self-hosted KaTeX distribution that formulas are typeset
+// with in place of the public CDN.
+type UIConfig struct {
+	KaTeXURL             string `mapstructure:"katex_url"`
+	DisableInlineScripts bool   `mapstructure:"disable_inline_scripts"`
+}
+
+// RenderBudgetConfig holds configuration for measuring how long documents take to render
+// and how large their HTML is, flagging those exceeding the budget.
+type RenderBudgetConfig struct {
+	MaxDuration time.Duration `mapstructure:"max_duration"` // Render time budget (default 250ms).
+	MaxBytes    int           `mapstructure:"max_bytes"`    // Rendered HTML size budget (default 1 MiB).
+	Enabled     bool          `mapstructure:"enabled"`
+}
@@ -1,4 +1,6 @@
-// Package omnidex is the root package that embeds static web assets.
+// Package omnidex is the root package that embeds static web assets and assembles the
+// server from its configuration, for the server command and for Go applications embedding
+// the portal, see NewServer.
 package omnidex
 
 import "embed"
@@ -8,8 +8,11 @@ import (
 	"io/fs"
 	"log/slog"
 	"net/http"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/ksysoev/omnidex/pkg/api/middleware"
 	"github.com/ksysoev/omnidex/pkg/core"
 )
 
@@ -22,66 +25,285 @@ const (
 
 // API is the main HTTP server that serves both the ingest API and the documentation portal.
 type API struct {
-	svc    Service
-	views  ViewRenderer
-	config Config
+	svc   Service
+	views ViewRenderer
+	// hostViews holds the view renderers of hosts with their own site name, by hostname.
+	hostViews map[string]ViewRenderer
+	// verifiers accept API keys in addition to the configured and managed ones.
+	verifiers []middleware.KeyVerifier
+	// closing is closed when the server shuts down, ending event streams.
+	closing chan struct{}
+	// shedder limits concurrent requests; nil when load shedding is disabled.
+	shedder *loadShedder
+	// mode is the operating mode; nil means normal mode.
+	mode atomic.Pointer[ModeStatus]
+	// signatures remembers recently accepted ingest signatures to refuse replays.
+	signatures signatureGuard
+	config     Config
+	// unready is set while startup work is in progress; the zero value reports ready.
+	unready atomic.Bool
 }
 
 // Config holds the configuration for the API server.
 type Config struct {
-	StaticFS         fs.FS    `mapstructure:"-"`
-	Listen           string   `mapstructure:"listen"`
-	APIKeys          []string `mapstructure:"api_keys"`
-	MaxIngestBodyMiB int64    `mapstructure:"max_ingest_body_mib"` // Maximum ingest request body in MiB (default 50).
+	StaticFS fs.FS `mapstructure:"-"`
+	// RepoTokens, when set, verifies repository tokens accepted by the ingest and highlights
+	// endpoints in addition to API keys. Requests authenticated by such a token may only
+	// publish or change the token's repository and its projects.
+	RepoTokens middleware.RepoTokenVerifier `mapstructure:"-"`
+	Listen     string                       `mapstructure:"listen"`
+	// Mode is the operating mode the server starts in, see Mode; admins can change it at
+	// runtime. ModeMessage explains it to readers and publishers.
+	Mode        Mode     `mapstructure:"mode"`
+	ModeMessage string   `mapstructure:"mode_message"`
+	APIKeys     []string `mapstructure:"api_keys" redact:"true"`
+	// Hosts maps hostnames to portals serving a subset of the repositories, such as a
+	// team's documentation on its own domain. Other hostnames are served everything.
+	Hosts []HostConfig `mapstructure:"hosts"`
+	// Login requires readers of the portal to sign in, except on public hosts.
+	Login LoginConfig `mapstructure:"login"`
+	// CORS allows browser applications on other origins to call the JSON API and document
+	// content routes. The first rule whose routes match a request applies.
+	CORS []CORSConfig `mapstructure:"cors"`
+	// SigningKeys are the shared secrets accepted for HMAC-SHA256 signatures of ingest
+	// payloads, each bound to the owner or repository it may sign for, as owner=secret or
+	// owner/repo=secret. Documents published with a valid signature are marked as verified.
+	SigningKeys []string `mapstructure:"signing_keys" redact:"value"`
+	// LoadShedding limits concurrent requests, giving reads priority over ingests.
+	LoadShedding     LoadSheddingConfig `mapstructure:"load_shedding"`
+	MaxIngestBodyMiB int64              `mapstructure:"max_ingest_body_mib"` // Maximum ingest request body in MiB (default 50).
+	RequireSignature bool               `mapstructure:"require_signature"`   // Reject unsigned ingest payloads.
+	// Profiling serves the net/http/pprof profiles under /debug/pprof/ to authenticated clients.
+	Profiling bool `mapstructure:"profiling"`
+}
+
+// HostConfig configures the portal served on a hostname.
+type HostConfig struct {
+	Host  string   `mapstructure:"host"`  // Hostname, e.g. docs.team-x.example.com.
+	Name  string   `mapstructure:"name"`  // Site name shown in place of "Omnidex".
+	Repos []string `mapstructure:"repos"` // Owners ("team-x") or repositories ("team-x/api") served on the host.
+	// Public hosts are served without signing in when Login is enabled, and may be crawled.
+	Public bool `mapstructure:"public"`
+}
+
+// LoginConfig requires readers of the portal to sign in through an authenticating reverse
+// proxy, such as oauth2-proxy, in front of the instance.
+type LoginConfig struct {
+	// UserHeader is the header in which the proxy passes the signed-in user, e.g.
+	// X-Forwarded-User. Login is disabled when it is empty. The proxy must strip the header
+	// from the requests it receives, or readers could claim to be signed in.
+	UserHeader string `mapstructure:"user_header"`
+	// URL is where readers who are not signed in are redirected, e.g. /oauth2/start, with
+	// the page they asked for in the rd parameter. Without it they get 401 Unauthorized.
+	URL string `mapstructure:"url"`
+}
+
+// CORSConfig allows cross-origin requests from browser applications, such as internal
+// single-page apps and browser extensions, to a set of routes.
+type CORSConfig struct {
+	Routes  []string      `mapstructure:"routes"`  // Path prefixes, e.g. /api/v1/suggest or /raw/.
+	Origins []string      `mapstructure:"origins"` // Allowed origins, e.g. https://app.example.com; "*" allows any.
+	Methods []string      `mapstructure:"methods"` // Allowed methods (default GET and HEAD).
+	Headers []string      `mapstructure:"headers"` // Allowed request headers, e.g. Authorization.
+	MaxAge  time.Duration `mapstructure:"max_age"` // How long browsers may cache preflight answers.
+}
+
+// Option configures optional API behaviour.
+type Option func(*API)
+
+// WithSiteViews sets the function creating the view renderer of a host with its own site
+// name. Without it, hosts are served with the default view renderer.
+func WithSiteViews(newViews func(siteName string) ViewRenderer) Option {
+	return func(a *API) {
+		for _, h := range a.config.Hosts {
+			if h.Name != "" {
+				a.hostViews[strings.ToLower(h.Host)] = newViews(h.Name)
+			}
+		}
+	}
+}
+
+// WithKeyVerifier accepts the Bearer tokens verify reports as valid wherever an API key is
+// accepted, in addition to the configured keys and those managed at runtime, such as
+// tokens issued by the identity provider of an application embedding the portal.
+func WithKeyVerifier(verify middleware.KeyVerifier) Option {
+	return func(a *API) {
+		a.verifiers = append(a.verifiers, verify)
+	}
 }
 
 // Service defines the interface for core business logic operations.
 type Service interface {
 	IngestDocuments(ctx context.Context, req *core.IngestRequest) (*core.IngestResponse, error)
 	GetDocument(ctx context.Context, repo, path string) (core.Document, []byte, []core.Heading, error)
+	GetDocumentSource(ctx context.Context, repo, path string) (core.Document, error)
+	GetDocumentText(ctx context.Context, repo, path string) (core.Document, string, error)
+	GetExcerpt(ctx context.Context, repo, path, anchor string) (*core.Excerpt, error)
 	GetAsset(ctx context.Context, repo, path string) ([]byte, error)
+	GetSection(ctx context.Context, repo, dir string) ([]core.SectionDocument, error)
 	SearchDocs(ctx context.Context, query string, opts core.SearchOpts) (*core.SearchResults, error)
+	SuggestSearch(ctx context.Context, prefix string, limit int) ([]core.SearchSuggestion, error)
+	QuickSearch(ctx context.Context, query string, repos []string, limit int) ([]core.QuickResult, error)
 	ListRepos(ctx context.Context) ([]core.RepoInfo, error)
+	ContentTypes() []core.ContentTypeInfo
 	ListDocuments(ctx context.Context, repo string) ([]core.DocumentMeta, error)
+	RenameRepo(ctx context.Context, req core.RenameRepoRequest) (*core.RenameRepoResponse, error)
+	ResolveRedirect(ctx context.Context, repo, path string) (newRepo, newPath string, moved bool)
+	LintReports(ctx context.Context) []core.LintReport
+	LinkReports(ctx context.Context) []core.LinkReport
+	DeleteRepo(ctx context.Context, repo string) (*core.DeleteRepoResponse, error)
+	Republish(ctx context.Context, repo string) error
+	CreateAPIKey(ctx context.Context, name string) (*core.CreateAPIKeyResponse, error)
+	ListAPIKeys(ctx context.Context) ([]core.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id string) error
+	DuplicateReport(ctx context.Context) ([]core.DuplicateGroup, error)
+	RenderReport(ctx context.Context) (*core.RenderReport, error)
+	CitationManifest(ctx context.Context, repo string) (*core.CitationManifest, error)
+	ExportArchive(ctx context.Context, w io.Writer) error
+	ImportArchive(ctx context.Context, r io.Reader) (*core.ImportResponse, error)
+	RotateAPIKey(ctx context.Context, id string, overlap time.Duration) (*core.RotateAPIKeyResponse, error)
+	Subscribe(ctx context.Context) <-chan core.DocumentEvent
+	StartIncident(ctx context.Context, repo, path, name string) (*core.Incident, error)
+	GetRepoHighlights(ctx context.Context, repo string) (*core.RepoHighlights, error)
+	SetRepoHighlights(ctx context.Context, req core.RepoHighlightsRequest) (*core.RepoHighlights, error)
+	PageHighlights(ctx context.Context, repo string, docs []core.DocumentMeta) *core.PageHighlights
+	GetSiteBanner(ctx context.Context) (*core.SiteBanner, error)
+	SetSiteBanner(ctx context.Context, banner core.SiteBanner) (*core.SiteBanner, error)
+	ClearSiteBanner(ctx context.Context) error
+	EndIncident(ctx context.Context, repo, path string) error
+	ListIncidents(ctx context.Context) []core.Incident
+	Presence(ctx context.Context, repo, path string) (*core.Incident, []core.Viewer, error)
+	UpdatePresence(ctx context.Context, repo, path string, viewer core.Viewer, leave bool) error
+	Editable(repo string) bool
+	LockDocument(ctx context.Context, repo, path, owner, token string) (*core.DocumentLock, core.Document, error)
+	UnlockDocument(ctx context.Context, repo, path, token string) error
+	PreviewEdit(ctx context.Context, repo, path, content string) ([]byte, []core.Heading, error)
+	SaveEdit(ctx context.Context, req core.EditRequest) (*core.EditResponse, error)
+	Suggestible(repo string) bool
+	SuggestEdit(ctx context.Context, sg core.Suggestion) (*core.SuggestionResponse, error)
+	VerifyAPIKey(ctx context.Context, token string) bool
+	StartReindex(ctx context.Context) error
+	StartIndexRebuild(ctx context.Context, req core.IndexRebuildRequest) error
+	IndexRebuildStatus(ctx context.Context) (*core.IndexRebuildStatus, error)
+	VerifyMigration(ctx context.Context, req core.MigrationVerifyRequest) (*core.MigrationReport, error)
+	SaveSearch(ctx context.Context, req core.SavedSearchRequest) (*core.SavedSearch, error)
+	GetSavedSearch(ctx context.Context, id string) (*core.SavedSearch, error)
+	DeleteSavedSearch(ctx context.Context, id string) error
+	Notifications(ctx context.Context, reader string) ([]core.Notification, error)
+	MarkNotificationsRead(ctx context.Context, reader string) error
+	Stats(ctx context.Context) (*core.Stats, error)
+	RankingExperiment() string
+	RecordSearchClick(session string, rank int)
 }
 
 // ViewRenderer defines the interface for rendering HTML views.
 type ViewRenderer interface {
 	RenderHome(w io.Writer, repos []core.RepoInfo, partial bool) error
-	RenderRepoIndex(w io.Writer, repo string, docs []core.DocumentMeta, partial bool) error
-	RenderDoc(w io.Writer, doc core.Document, html []byte, headings []core.Heading, navDocs []core.DocumentMeta, partial bool) error
-	RenderSearch(w io.Writer, query string, results *core.SearchResults, partial bool) error
+	RenderRepoIndex(w io.Writer, repo string, docs []core.DocumentMeta, landing *core.RepoLanding, highlights *core.PageHighlights, filesTab, partial bool) error
+	RenderDoc(w io.Writer, doc core.Document, html []byte, headings []core.Heading, navDocs []core.DocumentMeta, highlights *core.PageHighlights, partial bool) error
+	RenderSearch(w io.Writer, query string, opts core.SearchOpts, results *core.SearchResults, repos []core.RepoInfo, partial bool) error
+	RenderRepoSearch(w io.Writer, repo, query string, opts core.SearchOpts, results *core.SearchResults, partial bool) error
+	RenderSuggestions(w io.Writer, query string, suggestions []core.SearchSuggestion) error
+	RenderStats(w io.Writer, stats *core.Stats, partial bool) error
+	RenderNotifications(w io.Writer, notifications []core.Notification, partial bool) error
+	RenderNotificationsLink(w io.Writer, unread int) error
 	RenderNotFound(w io.Writer) error
+	RenderMaintenance(w io.Writer, message string, partial bool) error
+	RenderSection(w io.Writer, repo, dir string, docs []core.SectionDocument) error
+	RenderPreview(w io.Writer, doc core.Document, html []byte) error
+	RenderEditor(w io.Writer, doc core.Document) error
+	RenderSuggestion(w io.Writer, doc core.Document) error
+	RenderEPUB(w io.Writer, repo string, docs []core.SectionDocument, asset func(path string) ([]byte, error)) error
 }
 
 // New creates a new API instance with the provided configuration, service, and view renderer.
-// It validates the configuration and returns an error if the listen address is not specified.
-func New(cfg Config, svc Service, views ViewRenderer) (*API, error) {
+// It validates the configuration and returns an error if the listen address is not specified,
+// a host is configured without a hostname or repositories, a CORS rule without routes or
+// origins, a signing key without an owner or repository, or the mode is unknown.
+func New(cfg Config, svc Service, views ViewRenderer, opts ...Option) (*API, error) {
 	if cfg.Listen == "" {
 		return nil, fmt.Errorf("listen address must be specified")
 	}
 
+	for _, h := range cfg.Hosts {
+		if h.Host == "" || len(h.Repos) == 0 {
+			return nil, fmt.Errorf("host %q must specify a hostname and repositories", h.Host)
+		}
+	}
+
+	for _, c := range cfg.CORS {
+		if len(c.Routes) == 0 || len(c.Origins) == 0 {
+			return nil, fmt.Errorf("cors rule for %v must specify routes and origins", c.Routes)
+		}
+	}
+
+	if err := validateSigningKeys(cfg.SigningKeys); err != nil {
+		return nil, err
+	}
+
 	if cfg.MaxIngestBodyMiB <= 0 {
 		cfg.MaxIngestBodyMiB = defaultMaxIngestBodyMiB
 	}
 
 	api := &API{
-		config: cfg,
-		svc:    svc,
-		views:  views,
+		config:    cfg,
+		svc:       svc,
+		views:     views,
+		hostViews: make(map[string]ViewRenderer),
+		closing:   make(chan struct{}),
+		shedder:   newLoadShedder(cfg.LoadShedding),
+	}
+
+	if cfg.Mode != "" {
+		if err := api.SetMode(cfg.Mode, cfg.ModeMessage); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, opt := range opts {
+		opt(api)
 	}
 
 	return api, nil
 }
 
+// viewsFor returns the view renderer for the hostname of the request.
+func (a *API) viewsFor(r *http.Request) ViewRenderer {
+	if views, ok := a.hostViews[middleware.Hostname(r)]; ok {
+		return views
+	}
+
+	return a.views
+}
+
+// SetReady sets whether the readiness probe reports the server as ready to receive
+// traffic. A new API is ready; callers that need to finish startup work while already
+// serving, such as warming caches, mark it not ready until that work is done.
+func (a *API) SetReady(ready bool) {
+	a.unready.Store(!ready)
+}
+
+// Handler returns the handler serving the ingest API and the portal, for applications that
+// serve it from their own HTTP server, under their own routing and middleware, instead of
+// calling Run. The portal links to its pages by absolute paths, so it must be served at
+// the root of its host rather than below a path prefix.
+func (a *API) Handler() (http.Handler, error) {
+	mux, err := a.newMux()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mux: %w", err)
+	}
+
+	return mux, nil
+}
+
 // Run starts the API server with the provided configuration.
 // It listens on the address specified in the configuration and handles graceful shutdown.
 // When the context is cancelled, in-flight requests are given a grace period to complete
 // before the server is forcefully closed.
 func (a *API) Run(ctx context.Context) error {
-	mux, err := a.newMux()
+	mux, err := a.Handler()
 	if err != nil {
-		return fmt.Errorf("failed to create mux: %w", err)
+		return err
 	}
 
 	s := &http.Server{
@@ -91,6 +313,9 @@ func (a *API) Run(ctx context.Context) error {
 		Handler:           mux,
 	}
 
+	// Event streams never finish on their own, so end them as soon as shutdown starts.
+	s.RegisterOnShutdown(func() { close(a.closing) })
+
 	go func() {
 		<-ctx.Done()
 
@@ -2,13 +2,37 @@ package api
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
+// newTestMux creates an API with fresh mocks, configured with cfg and listening on ":0"
+// unless cfg sets an address, and returns it with its mux.
+func newTestMux(t *testing.T, cfg Config) (*API, http.Handler, *MockService, *MockViewRenderer) {
+	t.Helper()
+
+	if cfg.Listen == "" {
+		cfg.Listen = ":0"
+	}
+
+	svc := NewMockService(t)
+	views := NewMockViewRenderer(t)
+
+	api, err := New(cfg, svc, views)
+	require.NoError(t, err)
+
+	mux, err := api.newMux()
+	require.NoError(t, err)
+
+	return api, mux, svc, views
+}
+
 func TestNew_ValidConfig(t *testing.T) {
 	cfg := Config{Listen: ":8080", APIKeys: []string{"key1"}}
 	svc := NewMockService(t)
@@ -31,6 +55,26 @@ func TestNew_EmptyListen(t *testing.T) {
 	assert.Contains(t, err.Error(), "listen address must be specified")
 }
 
+func TestNew_InvalidHost(t *testing.T) {
+	for _, host := range []HostConfig{{Host: "docs.example.com"}, {Repos: []string{"team-x"}}} {
+		cfg := Config{Listen: ":8080", Hosts: []HostConfig{host}}
+
+		_, err := New(cfg, NewMockService(t), NewMockViewRenderer(t))
+
+		assert.ErrorContains(t, err, "must specify a hostname and repositories")
+	}
+}
+
+func TestNew_InvalidCORS(t *testing.T) {
+	for _, rule := range []CORSConfig{{Routes: []string{"/api/v1/"}}, {Origins: []string{"*"}}} {
+		cfg := Config{Listen: ":8080", CORS: []CORSConfig{rule}}
+
+		_, err := New(cfg, NewMockService(t), NewMockViewRenderer(t))
+
+		assert.ErrorContains(t, err, "must specify routes and origins")
+	}
+}
+
 func TestRun_GracefulShutdown(t *testing.T) {
 	cfg := Config{Listen: "127.0.0.1:0", APIKeys: []string{"key1"}}
 	svc := NewMockService(t)
@@ -49,3 +93,27 @@ func TestRun_GracefulShutdown(t *testing.T) {
 	err = api.Run(ctx)
 	assert.NoError(t, err)
 }
+
+func TestHandler_WithKeyVerifier(t *testing.T) {
+	svc := NewMockService(t)
+	svc.EXPECT().VerifyAPIKey(mock.Anything, mock.Anything).Return(false)
+	svc.EXPECT().ListRepos(mock.Anything).Return(nil, nil)
+
+	api, err := New(Config{Listen: ":0"}, svc, NewMockViewRenderer(t), WithKeyVerifier(func(_ context.Context, token string) bool {
+		return token == "sso-token"
+	}))
+	require.NoError(t, err)
+
+	handler, err := api.Handler()
+	require.NoError(t, err)
+
+	for token, want := range map[string]int{"sso-token": http.StatusOK, "other": http.StatusUnauthorized} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/repos", http.NoBody)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, want, rec.Code, token)
+	}
+}
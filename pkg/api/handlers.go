@@ -16,3 +16,27 @@ func (a *API) healthCheck(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// readinessCheck reports whether the server is ready to receive traffic. It returns
+// 503 Service Unavailable while startup work such as the index warm-up is in progress.
+func (a *API) readinessCheck(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+
+	body := "Ok"
+
+	if a.unready.Load() {
+		body = "Not ready"
+
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	if _, err := w.Write([]byte(body)); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to write response", "error", err)
+	}
+}
+
+// corsPreflight handles OPTIONS requests the CORS middleware did not answer: preflight
+// requests from origins no CORS rule allows, and requests that are not preflights.
+func (a *API) corsPreflight(w http.ResponseWriter, _ *http.Request) {
+	http.Error(w, "cross-origin request not allowed", http.StatusForbidden)
+}
@@ -0,0 +1,414 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+// deleteRepo handles DELETE /api/v1/repos/{repo...} - removes every document and asset of
+// a repository.
+func (a *API) deleteRepo(w http.ResponseWriter, r *http.Request) {
+	repo := r.PathValue("repo")
+
+	resp, err := a.svc.DeleteRepo(r.Context(), repo)
+	if err != nil {
+		switch {
+		case errors.Is(err, core.ErrInvalidPath):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, core.ErrNotFound):
+			http.Error(w, "repository not found", http.StatusNotFound)
+		default:
+			slog.ErrorContext(r.Context(), "Failed to delete repo", "error", err, "repo", repo)
+			http.Error(w, "failed to delete repository", http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, resp)
+}
+
+// republishRepo handles POST /api/v1/repos/{owner}/{repo}/republish - asks the repository
+// to publish its documentation again. The publish runs asynchronously, so a successful
+// response only means it was triggered.
+func (a *API) republishRepo(w http.ResponseWriter, r *http.Request) {
+	repo := r.PathValue("owner") + "/" + r.PathValue("repo")
+
+	if err := a.svc.Republish(r.Context(), repo); err != nil {
+		switch {
+		case errors.Is(err, core.ErrInvalidPath):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, core.ErrNotFound):
+			http.Error(w, "repository not found", http.StatusNotFound)
+		case errors.Is(err, core.ErrNotSupported):
+			http.Error(w, "republish is not configured", http.StatusNotImplemented)
+		default:
+			slog.ErrorContext(r.Context(), "Failed to trigger republish", "error", err, "repo", repo)
+			http.Error(w, "failed to trigger republish", http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	writeJSON(w, r, http.StatusAccepted, map[string]string{"repo": repo, "status": "triggered"})
+}
+
+// listAPIKeys handles GET /api/v1/keys - lists the managed API keys without their tokens.
+func (a *API) listAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := a.svc.ListAPIKeys(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Failed to list api keys", "error", err)
+		http.Error(w, "failed to list API keys", http.StatusInternalServerError)
+
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, map[string]any{"keys": keys})
+}
+
+// createAPIKey handles POST /api/v1/keys - issues a managed API key. The token is only
+// returned in this response.
+func (a *API) createAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req core.CreateAPIKeyRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to decode create key request", "error", err)
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+
+		return
+	}
+
+	resp, err := a.svc.CreateAPIKey(r.Context(), req.Name)
+	if err != nil {
+		if errors.Is(err, core.ErrInvalidPath) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		slog.ErrorContext(r.Context(), "Failed to create api key", "error", err)
+		http.Error(w, "failed to create API key", http.StatusInternalServerError)
+
+		return
+	}
+
+	writeJSON(w, r, http.StatusCreated, resp)
+}
+
+// revokeAPIKey handles DELETE /api/v1/keys/{id} - revokes a managed API key.
+func (a *API) revokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if err := a.svc.RevokeAPIKey(r.Context(), id); err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			http.Error(w, "API key not found", http.StatusNotFound)
+			return
+		}
+
+		slog.ErrorContext(r.Context(), "Failed to revoke api key", "error", err, "id", id)
+		http.Error(w, "failed to revoke API key", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// rotateAPIKey handles POST /api/v1/keys/{id}/rotate - replaces a managed API key by a new
+// one with the same name. The old key keeps working for the requested overlap. The new token
+// is only returned in this response.
+func (a *API) rotateAPIKey(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	var req core.RotateAPIKeyRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		slog.ErrorContext(r.Context(), "Failed to decode rotate key request", "error", err)
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+
+		return
+	}
+
+	overlap := core.DefaultKeyOverlap
+
+	if req.Overlap != "" {
+		var err error
+
+		if overlap, err = time.ParseDuration(req.Overlap); err != nil || overlap < 0 {
+			http.Error(w, "overlap must be a non-negative duration such as 24h", http.StatusBadRequest)
+			return
+		}
+	}
+
+	resp, err := a.svc.RotateAPIKey(r.Context(), id, overlap)
+	if err != nil {
+		switch {
+		case errors.Is(err, core.ErrNotFound):
+			http.Error(w, "API key not found", http.StatusNotFound)
+		case errors.Is(err, core.ErrConflict):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			slog.ErrorContext(r.Context(), "Failed to rotate api key", "error", err, "id", id)
+			http.Error(w, "failed to rotate API key", http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	writeJSON(w, r, http.StatusCreated, resp)
+}
+
+// reindex handles POST /api/v1/reindex - starts rebuilding the search index from the
+// document store in the background.
+func (a *API) reindex(w http.ResponseWriter, r *http.Request) {
+	if err := a.svc.StartReindex(r.Context()); err != nil {
+		if errors.Is(err, core.ErrConflict) {
+			http.Error(w, "a reindex is already running", http.StatusConflict)
+			return
+		}
+
+		slog.ErrorContext(r.Context(), "Failed to start reindex", "error", err)
+		http.Error(w, "failed to start reindex", http.StatusInternalServerError)
+
+		return
+	}
+
+	writeJSON(w, r, http.StatusAccepted, map[string]string{"status": "started"})
+}
+
+// startIndexRebuild handles POST /api/v1/reindex/blue-green - starts rebuilding the search
+// index alongside the live one, which is replaced only if sampled queries return similar
+// results on both.
+func (a *API) startIndexRebuild(w http.ResponseWriter, r *http.Request) {
+	var req core.IndexRebuildRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		slog.ErrorContext(r.Context(), "Failed to decode index rebuild request", "error", err)
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+
+		return
+	}
+
+	if req.MinOverlap < 0 || req.MinOverlap > 1 {
+		http.Error(w, "min_overlap must be between 0 and 1", http.StatusBadRequest)
+		return
+	}
+
+	if err := a.svc.StartIndexRebuild(r.Context(), req); err != nil {
+		switch {
+		case errors.Is(err, core.ErrNotSupported):
+			http.Error(w, err.Error(), http.StatusNotImplemented)
+		case errors.Is(err, core.ErrConflict):
+			http.Error(w, "a reindex is already running", http.StatusConflict)
+		default:
+			slog.ErrorContext(r.Context(), "Failed to start index rebuild", "error", err)
+			http.Error(w, "failed to start index rebuild", http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	writeJSON(w, r, http.StatusAccepted, map[string]string{"status": "started"})
+}
+
+// indexRebuildStatus handles GET /api/v1/reindex/blue-green - reports the progress and
+// outcome of the latest blue/green index rebuild.
+func (a *API) indexRebuildStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := a.svc.IndexRebuildStatus(r.Context())
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			http.Error(w, "no index rebuild was started", http.StatusNotFound)
+			return
+		}
+
+		slog.ErrorContext(r.Context(), "Failed to get index rebuild status", "error", err)
+		http.Error(w, "failed to get index rebuild status", http.StatusInternalServerError)
+
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, status)
+}
+
+// verifyMigration handles GET /api/v1/search/migration - compares the search engines of
+// the search migration on the queries given as q parameters, or sampled titles.
+func (a *API) verifyMigration(w http.ResponseWriter, r *http.Request) {
+	report, err := a.svc.VerifyMigration(r.Context(), core.MigrationVerifyRequest{Queries: r.URL.Query()["q"]})
+	if err != nil {
+		if errors.Is(err, core.ErrNotSupported) {
+			http.Error(w, "no search migration is configured", http.StatusNotImplemented)
+			return
+		}
+
+		slog.ErrorContext(r.Context(), "Failed to verify search migration", "error", err)
+		http.Error(w, "failed to verify search migration", http.StatusInternalServerError)
+
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, report)
+}
+
+// listIncidents handles GET /api/v1/incidents - lists the documents flagged as in use
+// during an incident.
+func (a *API) listIncidents(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, r, http.StatusOK, map[string]any{"incidents": a.svc.ListIncidents(r.Context())})
+}
+
+// startIncident handles POST /api/v1/incidents - flags a document as in use during an
+// incident, which shows its readers who else is reading it and where.
+func (a *API) startIncident(w http.ResponseWriter, r *http.Request) {
+	var req core.Incident
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to decode incident request", "error", err)
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+
+		return
+	}
+
+	if req.Repo == "" || req.Path == "" {
+		http.Error(w, "repo and path are required", http.StatusBadRequest)
+		return
+	}
+
+	inc, err := a.svc.StartIncident(r.Context(), req.Repo, req.Path, req.Name)
+	if err != nil {
+		switch {
+		case errors.Is(err, core.ErrNotFound):
+			http.Error(w, "document not found", http.StatusNotFound)
+		case errors.Is(err, core.ErrInvalidPath):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			slog.ErrorContext(r.Context(), "Failed to start incident", "error", err, "repo", req.Repo, "path", req.Path)
+			http.Error(w, "failed to start incident", http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	writeJSON(w, r, http.StatusCreated, inc)
+}
+
+// endIncident handles DELETE /api/v1/incidents?repo=...&path=... - removes the incident
+// flag of a document.
+func (a *API) endIncident(w http.ResponseWriter, r *http.Request) {
+	repo, path := r.URL.Query().Get("repo"), r.URL.Query().Get("path")
+
+	if err := a.svc.EndIncident(r.Context(), repo, path); err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			http.Error(w, "no incident for this document", http.StatusNotFound)
+			return
+		}
+
+		slog.ErrorContext(r.Context(), "Failed to end incident", "error", err, "repo", repo, "path", path)
+		http.Error(w, "failed to end incident", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// duplicateReport handles GET /api/v1/duplicates - lists the groups of documents published
+// identically in several repositories.
+func (a *API) duplicateReport(w http.ResponseWriter, r *http.Request) {
+	groups, err := a.svc.DuplicateReport(r.Context())
+	if err != nil {
+		if errors.Is(err, core.ErrNotSupported) {
+			http.Error(w, err.Error(), http.StatusNotImplemented)
+			return
+		}
+
+		slog.ErrorContext(r.Context(), "Failed to build duplicate report", "error", err)
+		http.Error(w, "failed to build duplicate report", http.StatusInternalServerError)
+
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, map[string]any{"groups": groups})
+}
+
+// renderReport handles GET /api/v1/renders - lists the render times and rendered sizes of
+// the documents measured since the server started, those over the render budget first.
+func (a *API) renderReport(w http.ResponseWriter, r *http.Request) {
+	report, err := a.svc.RenderReport(r.Context())
+	if err != nil {
+		if errors.Is(err, core.ErrNotSupported) {
+			http.Error(w, err.Error(), http.StatusNotImplemented)
+			return
+		}
+
+		slog.ErrorContext(r.Context(), "Failed to build render report", "error", err)
+		http.Error(w, "failed to build render report", http.StatusInternalServerError)
+
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, report)
+}
+
+// citationManifest handles GET /api/v1/citations - exports the audit records of the
+// published documents, with the hashes of their source and rendered HTML, as a JSON
+// download. The optional repo query parameter limits the manifest to one repository.
+func (a *API) citationManifest(w http.ResponseWriter, r *http.Request) {
+	repo := r.URL.Query().Get("repo")
+
+	manifest, err := a.svc.CitationManifest(r.Context(), repo)
+	if err != nil {
+		switch {
+		case errors.Is(err, core.ErrInvalidPath):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, core.ErrNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			slog.ErrorContext(r.Context(), "Failed to build citation manifest", "error", err, "repo", repo)
+			http.Error(w, "failed to build citation manifest", http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	filename := "citations.json"
+	if repo != "" {
+		filename = "citations-" + strings.ReplaceAll(repo, "/", "-") + ".json"
+	}
+
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	writeJSON(w, r, http.StatusOK, manifest)
+}
+
+// stats handles GET /api/v1/stats - reports content totals, sizes, recent activity and the
+// requests shed by load shedding.
+func (a *API) stats(w http.ResponseWriter, r *http.Request) {
+	stats, err := a.svc.Stats(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Failed to get stats", "error", err)
+		http.Error(w, "failed to get stats", http.StatusInternalServerError)
+
+		return
+	}
+
+	if a.shedder != nil {
+		counts := a.shedder.shedCounts()
+		stats.Shed = &counts
+	}
+
+	writeJSON(w, r, http.StatusOK, stats)
+}
+
+// writeJSON writes v as a JSON response with the given status code.
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to encode response", "error", err)
+	}
+}
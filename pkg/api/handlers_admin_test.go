@@ -0,0 +1,435 @@
+//go:build !compile
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// adminTestConfig accepts the admin-key API key sent by serveAdmin.
+var adminTestConfig = Config{APIKeys: []string{"admin-key"}}
+
+func serveAdmin(mux http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer admin-key")
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestDeleteRepo(t *testing.T) {
+	tests := []struct {
+		err      error
+		resp     *core.DeleteRepoResponse
+		name     string
+		repo     string
+		wantBody string
+		wantCode int
+	}{
+		{
+			name:     "success",
+			repo:     "owner/mono/billing",
+			resp:     &core.DeleteRepoResponse{Repo: "owner/mono/billing", Deleted: 4},
+			wantCode: http.StatusOK,
+			wantBody: `{"repo":"owner/mono/billing","deleted":4}`,
+		},
+		{name: "invalid", repo: "owner", err: fmt.Errorf("%w: bad", core.ErrInvalidPath), wantCode: http.StatusBadRequest},
+		{name: "not found", repo: "owner/repo", err: fmt.Errorf("%w: repo owner/repo", core.ErrNotFound), wantCode: http.StatusNotFound},
+		{name: "internal error", repo: "owner/repo", err: errors.New("disk failure"), wantCode: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, mux, svc, _ := newTestMux(t, adminTestConfig)
+
+			svc.EXPECT().DeleteRepo(mock.Anything, tt.repo).Return(tt.resp, tt.err)
+
+			rec := serveAdmin(mux, http.MethodDelete, "/api/v1/repos/"+tt.repo, "")
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestRepublishRepo(t *testing.T) {
+	tests := []struct {
+		err      error
+		name     string
+		wantBody string
+		wantCode int
+	}{
+		{name: "success", wantCode: http.StatusAccepted, wantBody: `{"repo":"owner/repo","status":"triggered"}`},
+		{name: "not configured", err: fmt.Errorf("%w: no trigger", core.ErrNotSupported), wantCode: http.StatusNotImplemented},
+		{name: "not found", err: fmt.Errorf("%w: repo owner/repo", core.ErrNotFound), wantCode: http.StatusNotFound},
+		{name: "invalid", err: fmt.Errorf("%w: bad", core.ErrInvalidPath), wantCode: http.StatusBadRequest},
+		{name: "internal error", err: errors.New("GitHub unavailable"), wantCode: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, mux, svc, _ := newTestMux(t, adminTestConfig)
+
+			svc.EXPECT().Republish(mock.Anything, "owner/repo").Return(tt.err)
+
+			rec := serveAdmin(mux, http.MethodPost, "/api/v1/repos/owner/repo/republish", "")
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestAPIKeyHandlers(t *testing.T) {
+	_, mux, svc, _ := newTestMux(t, adminTestConfig)
+
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	svc.EXPECT().CreateAPIKey(mock.Anything, "ci").Return(&core.CreateAPIKeyResponse{
+		Token:  "omx_secret",
+		APIKey: core.APIKey{ID: "a1b2", Name: "ci", CreatedAt: created},
+	}, nil)
+	svc.EXPECT().ListAPIKeys(mock.Anything).Return([]core.APIKey{{ID: "a1b2", Name: "ci", CreatedAt: created}}, nil)
+	svc.EXPECT().RevokeAPIKey(mock.Anything, "a1b2").Return(nil)
+	svc.EXPECT().RevokeAPIKey(mock.Anything, "missing").Return(fmt.Errorf("%w: api key missing", core.ErrNotFound))
+
+	rec := serveAdmin(mux, http.MethodPost, "/api/v1/keys", `{"name":"ci"}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.JSONEq(t, `{"token":"omx_secret","id":"a1b2","name":"ci","created_at":"2026-01-02T03:04:05Z"}`, rec.Body.String())
+
+	rec = serveAdmin(mux, http.MethodGet, "/api/v1/keys", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"keys":[{"id":"a1b2","name":"ci","created_at":"2026-01-02T03:04:05Z"}]}`, rec.Body.String())
+
+	rec = serveAdmin(mux, http.MethodDelete, "/api/v1/keys/a1b2", "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = serveAdmin(mux, http.MethodDelete, "/api/v1/keys/missing", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = serveAdmin(mux, http.MethodPost, "/api/v1/keys", `{`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestCreateAPIKey_InvalidName(t *testing.T) {
+	_, mux, svc, _ := newTestMux(t, adminTestConfig)
+
+	svc.EXPECT().CreateAPIKey(mock.Anything, "").Return(nil, fmt.Errorf("%w: key name must be between 1 and 100 characters", core.ErrInvalidPath))
+
+	rec := serveAdmin(mux, http.MethodPost, "/api/v1/keys", `{}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestAdminAPI_ManagedKeyAuth(t *testing.T) {
+	_, mux, svc, _ := newTestMux(t, adminTestConfig)
+
+	svc.EXPECT().VerifyAPIKey(mock.Anything, "omx_managed").Return(true)
+	svc.EXPECT().VerifyAPIKey(mock.Anything, "omx_revoked").Return(false)
+	svc.EXPECT().Stats(mock.Anything).Return(&core.Stats{Repos: 2, Documents: 7}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/stats", http.NoBody)
+	req.Header.Set("Authorization", "Bearer omx_managed")
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"repos":2,"documents":7`)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/stats", http.NoBody)
+	req.Header.Set("Authorization", "Bearer omx_revoked")
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestReindex(t *testing.T) {
+	tests := []struct {
+		err      error
+		name     string
+		wantCode int
+	}{
+		{name: "started", wantCode: http.StatusAccepted},
+		{name: "already running", err: fmt.Errorf("%w: a reindex is already running", core.ErrConflict), wantCode: http.StatusConflict},
+		{name: "internal error", err: errors.New("boom"), wantCode: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, mux, svc, _ := newTestMux(t, adminTestConfig)
+
+			svc.EXPECT().StartReindex(mock.Anything).Return(tt.err)
+
+			rec := serveAdmin(mux, http.MethodPost, "/api/v1/reindex", "")
+			assert.Equal(t, tt.wantCode, rec.Code)
+		})
+	}
+}
+
+func TestStartIndexRebuild(t *testing.T) {
+	tests := []struct {
+		err      error
+		name     string
+		body     string
+		wantReq  core.IndexRebuildRequest
+		wantCode int
+		noCall   bool
+	}{
+		{name: "defaults", wantCode: http.StatusAccepted},
+		{
+			name:     "options",
+			body:     `{"queries":["install"],"min_overlap":0.8,"dry_run":true}`,
+			wantReq:  core.IndexRebuildRequest{Queries: []string{"install"}, MinOverlap: 0.8, DryRun: true},
+			wantCode: http.StatusAccepted,
+		},
+		{name: "invalid body", body: `{`, wantCode: http.StatusBadRequest, noCall: true},
+		{name: "invalid overlap", body: `{"min_overlap":1.5}`, wantCode: http.StatusBadRequest, noCall: true},
+		{name: "not supported", err: fmt.Errorf("%w: no shadow index", core.ErrNotSupported), wantCode: http.StatusNotImplemented},
+		{name: "already running", err: fmt.Errorf("%w: a reindex is already running", core.ErrConflict), wantCode: http.StatusConflict},
+		{name: "internal error", err: errors.New("boom"), wantCode: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, mux, svc, _ := newTestMux(t, adminTestConfig)
+
+			if !tt.noCall {
+				svc.EXPECT().StartIndexRebuild(mock.Anything, tt.wantReq).Return(tt.err)
+			}
+
+			rec := serveAdmin(mux, http.MethodPost, "/api/v1/reindex/blue-green", tt.body)
+			assert.Equal(t, tt.wantCode, rec.Code)
+		})
+	}
+}
+
+func TestIndexRebuildStatus(t *testing.T) {
+	_, mux, svc, _ := newTestMux(t, adminTestConfig)
+
+	svc.EXPECT().IndexRebuildStatus(mock.Anything).Return(nil, fmt.Errorf("%w: no index rebuild was started", core.ErrNotFound)).Once()
+
+	assert.Equal(t, http.StatusNotFound, serveAdmin(mux, http.MethodGet, "/api/v1/reindex/blue-green", "").Code)
+
+	svc.EXPECT().IndexRebuildStatus(mock.Anything).Return(&core.IndexRebuildStatus{State: core.RebuildPromoted, Indexed: 3, Overlap: 0.9}, nil).Once()
+
+	rec := serveAdmin(mux, http.MethodGet, "/api/v1/reindex/blue-green", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"state":"promoted"`)
+	assert.Contains(t, rec.Body.String(), `"indexed":3`)
+}
+
+func TestVerifyMigration(t *testing.T) {
+	_, mux, svc, _ := newTestMux(t, adminTestConfig)
+
+	svc.EXPECT().VerifyMigration(mock.Anything, core.MigrationVerifyRequest{}).
+		Return(nil, fmt.Errorf("%w: no search migration is configured", core.ErrNotSupported)).Once()
+
+	assert.Equal(t, http.StatusNotImplemented, serveAdmin(mux, http.MethodGet, "/api/v1/search/migration", "").Code)
+
+	svc.EXPECT().VerifyMigration(mock.Anything, core.MigrationVerifyRequest{Queries: []string{"install", "deploy"}}).
+		Return(&core.MigrationReport{ReadFrom: "source", Overlap: 1, InSync: true}, nil).Once()
+
+	rec := serveAdmin(mux, http.MethodGet, "/api/v1/search/migration?q=install&q=deploy", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"in_sync":true`)
+
+	svc.EXPECT().VerifyMigration(mock.Anything, core.MigrationVerifyRequest{}).Return(nil, errors.New("index unavailable")).Once()
+
+	assert.Equal(t, http.StatusInternalServerError, serveAdmin(mux, http.MethodGet, "/api/v1/search/migration", "").Code)
+}
+
+func TestStats_Error(t *testing.T) {
+	_, mux, svc, _ := newTestMux(t, adminTestConfig)
+
+	svc.EXPECT().Stats(mock.Anything).Return(nil, errors.New("bucket unavailable"))
+
+	rec := serveAdmin(mux, http.MethodGet, "/api/v1/stats", "")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestStartIncident(t *testing.T) {
+	tests := []struct {
+		err      error
+		name     string
+		body     string
+		wantCode int
+		noCall   bool
+	}{
+		{name: "started", body: `{"repo":"owner/repo","path":"runbook.md","name":"INC-1"}`, wantCode: http.StatusCreated},
+		{name: "invalid body", body: `{`, wantCode: http.StatusBadRequest, noCall: true},
+		{name: "missing path", body: `{"repo":"owner/repo"}`, wantCode: http.StatusBadRequest, noCall: true},
+		{name: "not found", body: `{"repo":"owner/repo","path":"runbook.md","name":"INC-1"}`, err: core.ErrNotFound, wantCode: http.StatusNotFound},
+		{name: "internal error", body: `{"repo":"owner/repo","path":"runbook.md","name":"INC-1"}`, err: errors.New("boom"), wantCode: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, mux, svc, _ := newTestMux(t, adminTestConfig)
+
+			if !tt.noCall {
+				var inc *core.Incident
+				if tt.err == nil {
+					inc = &core.Incident{Repo: "owner/repo", Path: "runbook.md", Name: "INC-1"}
+				}
+
+				svc.EXPECT().StartIncident(mock.Anything, "owner/repo", "runbook.md", "INC-1").Return(inc, tt.err)
+			}
+
+			rec := serveAdmin(mux, http.MethodPost, "/api/v1/incidents", tt.body)
+			assert.Equal(t, tt.wantCode, rec.Code)
+		})
+	}
+}
+
+func TestEndIncident(t *testing.T) {
+	_, mux, svc, _ := newTestMux(t, adminTestConfig)
+
+	svc.EXPECT().EndIncident(mock.Anything, "owner/repo", "runbook.md").Return(nil).Once()
+
+	assert.Equal(t, http.StatusNoContent, serveAdmin(mux, http.MethodDelete, "/api/v1/incidents?repo=owner/repo&path=runbook.md", "").Code)
+
+	svc.EXPECT().EndIncident(mock.Anything, "owner/repo", "runbook.md").Return(core.ErrNotFound).Once()
+
+	assert.Equal(t, http.StatusNotFound, serveAdmin(mux, http.MethodDelete, "/api/v1/incidents?repo=owner/repo&path=runbook.md", "").Code)
+}
+
+func TestListIncidents(t *testing.T) {
+	_, mux, svc, _ := newTestMux(t, adminTestConfig)
+
+	svc.EXPECT().ListIncidents(mock.Anything).Return([]core.Incident{{Repo: "owner/repo", Path: "runbook.md", Name: "INC-1"}})
+
+	rec := serveAdmin(mux, http.MethodGet, "/api/v1/incidents", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"name":"INC-1"`)
+}
+
+func TestRotateAPIKey(t *testing.T) {
+	tests := []struct {
+		err         error
+		name        string
+		body        string
+		wantOverlap time.Duration
+		wantCode    int
+		noCall      bool
+	}{
+		{name: "default overlap", wantOverlap: core.DefaultKeyOverlap, wantCode: http.StatusCreated},
+		{name: "overlap", body: `{"overlap":"1h"}`, wantOverlap: time.Hour, wantCode: http.StatusCreated},
+		{name: "invalid overlap", body: `{"overlap":"soon"}`, wantCode: http.StatusBadRequest, noCall: true},
+		{name: "negative overlap", body: `{"overlap":"-1h"}`, wantCode: http.StatusBadRequest, noCall: true},
+		{name: "not found", wantOverlap: core.DefaultKeyOverlap, err: core.ErrNotFound, wantCode: http.StatusNotFound},
+		{name: "already rotated", wantOverlap: core.DefaultKeyOverlap, err: core.ErrConflict, wantCode: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, mux, svc, _ := newTestMux(t, adminTestConfig)
+
+			if !tt.noCall {
+				var resp *core.RotateAPIKeyResponse
+				if tt.err == nil {
+					resp = &core.RotateAPIKeyResponse{CreateAPIKeyResponse: core.CreateAPIKeyResponse{Token: "omx_new", APIKey: core.APIKey{ID: "c3d4"}}}
+				}
+
+				svc.EXPECT().RotateAPIKey(mock.Anything, "a1b2", tt.wantOverlap).Return(resp, tt.err)
+			}
+
+			rec := serveAdmin(mux, http.MethodPost, "/api/v1/keys/a1b2/rotate", tt.body)
+			assert.Equal(t, tt.wantCode, rec.Code)
+		})
+	}
+}
+
+func TestDuplicateReport(t *testing.T) {
+	_, mux, svc, _ := newTestMux(t, adminTestConfig)
+
+	svc.EXPECT().DuplicateReport(mock.Anything).Return(nil, fmt.Errorf("%w: duplicate detection is not enabled", core.ErrNotSupported)).Once()
+
+	assert.Equal(t, http.StatusNotImplemented, serveAdmin(mux, http.MethodGet, "/api/v1/duplicates", "").Code)
+
+	svc.EXPECT().DuplicateReport(mock.Anything).Return([]core.DuplicateGroup{{
+		SHA256:    "abc",
+		Canonical: core.DuplicateDocument{Repo: "team-a/api", Path: "setup.md"},
+		Copies:    []core.DuplicateDocument{{Repo: "team-b/web", Path: "setup.md"}},
+	}}, nil).Once()
+
+	rec := serveAdmin(mux, http.MethodGet, "/api/v1/duplicates", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"canonical":{"updated_at":"0001-01-01T00:00:00Z","repo":"team-a/api"`)
+}
+
+func TestRenderReport(t *testing.T) {
+	_, mux, svc, _ := newTestMux(t, adminTestConfig)
+
+	svc.EXPECT().RenderReport(mock.Anything).Return(nil, fmt.Errorf("%w: render budgets are not enabled", core.ErrNotSupported)).Once()
+
+	assert.Equal(t, http.StatusNotImplemented, serveAdmin(mux, http.MethodGet, "/api/v1/renders", "").Code)
+
+	svc.EXPECT().RenderReport(mock.Anything).Return(&core.RenderReport{
+		Budget:    core.RenderBudget{MaxDuration: time.Second, MaxBytes: 1024},
+		Documents: []core.RenderStat{{Repo: "acme/api", Path: "big.md", HTMLBytes: 4096, OverBudget: true}},
+	}, nil).Once()
+
+	rec := serveAdmin(mux, http.MethodGet, "/api/v1/renders", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"repo":"acme/api","path":"big.md","html_bytes":4096,"over_budget":true`)
+	assert.Contains(t, rec.Body.String(), `"budget":{"max_duration_ns":1000000000,"max_bytes":1024}`)
+}
+
+func TestCitationManifest(t *testing.T) {
+	tests := []struct {
+		err      error
+		name     string
+		query    string
+		repo     string
+		wantFile string
+		wantCode int
+	}{
+		{name: "all repositories", wantCode: http.StatusOK, wantFile: "citations.json"},
+		{name: "one repository", query: "?repo=owner/repo", repo: "owner/repo", wantCode: http.StatusOK, wantFile: "citations-owner-repo.json"},
+		{name: "invalid repository", query: "?repo=..", repo: "..", err: core.ErrInvalidPath, wantCode: http.StatusBadRequest},
+		{name: "unknown repository", query: "?repo=owner/none", repo: "owner/none", err: core.ErrNotFound, wantCode: http.StatusNotFound},
+		{name: "store failure", err: errors.New("disk failure"), wantCode: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, mux, svc, _ := newTestMux(t, adminTestConfig)
+
+			var manifest *core.CitationManifest
+			if tt.err == nil {
+				manifest = &core.CitationManifest{Repo: tt.repo, Documents: []core.CitationEntry{{Repo: "owner/repo", Path: "a.md", ContentSHA256: "abc"}}}
+			}
+
+			svc.EXPECT().CitationManifest(mock.Anything, tt.repo).Return(manifest, tt.err)
+
+			rec := serveAdmin(mux, http.MethodGet, "/api/v1/citations"+tt.query, "")
+			require.Equal(t, tt.wantCode, rec.Code)
+
+			if tt.wantFile != "" {
+				assert.Equal(t, `attachment; filename="`+tt.wantFile+`"`, rec.Header().Get("Content-Disposition"))
+				assert.Contains(t, rec.Body.String(), `"content_sha256":"abc"`)
+			}
+		})
+	}
+}
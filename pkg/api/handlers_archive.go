@@ -0,0 +1,82 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+// exportArchive handles GET /api/v1/archive - streams every stored document, asset and
+// redirect as a gzip-compressed tar archive, which POST /api/v1/archive loads into another
+// instance. When the export fails part way through, the connection is aborted so that the
+// client sees an incomplete download rather than a truncated archive.
+func (a *API) exportArchive(w http.ResponseWriter, r *http.Request) {
+	// Large instances take longer to export than the server's write timeout.
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		slog.ErrorContext(r.Context(), "Failed to clear write deadline of archive export", "error", err)
+		http.Error(w, "failed to export archive", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", `attachment; filename="omnidex-`+time.Now().UTC().Format("20060102-150405")+`.tar.gz"`)
+
+	cw := &countingWriter{w: w}
+
+	if err := a.svc.ExportArchive(r.Context(), cw); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to export archive", "error", err)
+
+		if cw.n > 0 {
+			panic(http.ErrAbortHandler)
+		}
+
+		w.Header().Del("Content-Disposition")
+		http.Error(w, "failed to export archive", http.StatusInternalServerError)
+	}
+}
+
+// importArchive handles POST /api/v1/archive - loads an archive written by GET
+// /api/v1/archive, storing and indexing its documents and storing its assets and
+// redirects alongside the content already published.
+func (a *API) importArchive(w http.ResponseWriter, r *http.Request) {
+	// Indexing a large archive takes longer than the server's write timeout.
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		slog.ErrorContext(r.Context(), "Failed to clear write deadline of archive import", "error", err)
+		http.Error(w, "failed to import archive", http.StatusInternalServerError)
+
+		return
+	}
+
+	resp, err := a.svc.ImportArchive(r.Context(), r.Body)
+	if err != nil {
+		if errors.Is(err, core.ErrInvalidArchive) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		slog.ErrorContext(r.Context(), "Failed to import archive", "error", err)
+		http.Error(w, "failed to import archive", http.StatusInternalServerError)
+
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, resp)
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+
+	return n, err
+}
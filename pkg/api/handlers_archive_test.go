@@ -0,0 +1,118 @@
+//go:build !compile
+
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestExportArchive(t *testing.T) {
+	svc := NewMockService(t)
+
+	svc.EXPECT().ExportArchive(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, w io.Writer) error {
+		_, err := w.Write([]byte("archive"))
+		return err
+	})
+
+	api := &API{svc: svc}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/archive", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	api.exportArchive(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/gzip", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), `attachment; filename="omnidex-`)
+	assert.Equal(t, "archive", rec.Body.String())
+}
+
+func TestExportArchive_Error(t *testing.T) {
+	svc := NewMockService(t)
+
+	svc.EXPECT().ExportArchive(mock.Anything, mock.Anything).Return(errors.New("store unavailable"))
+
+	api := &API{svc: svc}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/archive", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	api.exportArchive(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Empty(t, rec.Header().Get("Content-Disposition"))
+}
+
+func TestExportArchive_ErrorAfterWrite(t *testing.T) {
+	svc := NewMockService(t)
+
+	svc.EXPECT().ExportArchive(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, w io.Writer) error {
+		_, _ = w.Write([]byte("partial"))
+		return errors.New("store unavailable")
+	})
+
+	api := &API{svc: svc}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/archive", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() { api.exportArchive(rec, req) })
+}
+
+func TestImportArchive(t *testing.T) {
+	tests := []struct {
+		err      error
+		resp     *core.ImportResponse
+		name     string
+		wantBody string
+		wantCode int
+	}{
+		{
+			name:     "imported",
+			resp:     &core.ImportResponse{Documents: 3, Assets: 1},
+			wantCode: http.StatusOK,
+			wantBody: `"documents":3`,
+		},
+		{
+			name:     "invalid archive",
+			err:      fmt.Errorf("%w: archive is empty", core.ErrInvalidArchive),
+			wantCode: http.StatusBadRequest,
+			wantBody: "archive is empty",
+		},
+		{
+			name:     "store failure",
+			err:      errors.New("disk full"),
+			wantCode: http.StatusInternalServerError,
+			wantBody: "failed to import archive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewMockService(t)
+
+			svc.EXPECT().ImportArchive(mock.Anything, mock.Anything).Return(tt.resp, tt.err)
+
+			api := &API{svc: svc}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/archive", strings.NewReader("archive"))
+			rec := httptest.NewRecorder()
+
+			api.importArchive(rec, req)
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.wantBody)
+		})
+	}
+}
@@ -24,9 +24,20 @@ func (a *API) assetPage(w http.ResponseWriter, r *http.Request) {
 	fullRepo := owner + "/" + repo
 
 	data, err := a.svc.GetAsset(r.Context(), fullRepo, path)
+	if errors.Is(err, core.ErrNotFound) {
+		if projectRepo, rest, ok := core.SplitProject(fullRepo, path); ok && rest != "" {
+			if pData, pErr := a.svc.GetAsset(r.Context(), projectRepo, rest); !errors.Is(pErr, core.ErrNotFound) {
+				fullRepo, data, err = projectRepo, pData, pErr
+			}
+		}
+	}
+
 	if err != nil {
 		if errors.Is(err, core.ErrNotFound) {
-			http.NotFound(w, r)
+			if !a.redirectMoved(w, r, "/assets/", fullRepo, "") {
+				http.NotFound(w, r)
+			}
+
 			return
 		}
 
@@ -54,6 +54,7 @@ func TestAssetPage_NotFound(t *testing.T) {
 	assert.NoError(t, err)
 
 	svc.EXPECT().GetAsset(mock.Anything, "owner/repo", "missing.png").Return(nil, docstore.ErrNotFound)
+	svc.EXPECT().ResolveRedirect(mock.Anything, "owner/repo", "").Return("", "", false)
 
 	req := httptest.NewRequest(http.MethodGet, "/assets/owner/repo/missing.png", http.NoBody)
 	rec := httptest.NewRecorder()
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/ksysoev/omnidex/pkg/api/middleware"
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+// suggestSearch handles GET /api/v1/suggest?q= - returns the documents whose titles or
+// section headings start with the words typed in the search box, as JSON, or as the
+// dropdown of the navigation search box for HTMX requests. The optional limit query
+// parameter sets the number of suggestions. Hosts serving a site only suggest documents
+// of their repositories, so they may return fewer suggestions than the limit.
+func (a *API) suggestSearch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	if !checkQueryLen(w, query) {
+		return
+	}
+
+	// Missing or invalid limits ask for the default number of suggestions.
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	suggestions, err := a.svc.SuggestSearch(r.Context(), query, limit)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Suggest failed", "error", err, "query", query)
+		http.Error(w, "Suggest failed", http.StatusInternalServerError)
+
+		return
+	}
+
+	if site, ok := middleware.HostSite(r.Context()); ok {
+		suggestions = slices.DeleteFunc(suggestions, func(s core.SearchSuggestion) bool {
+			return !site.Serves(s.Repo)
+		})
+	}
+
+	if !isHTMXRequest(r) {
+		if suggestions == nil {
+			suggestions = []core.SearchSuggestion{}
+		}
+
+		writeJSON(w, r, http.StatusOK, map[string]any{"suggestions": suggestions})
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if err := a.viewsFor(r).RenderSuggestions(w, query, suggestions); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to render suggestions", "error", err)
+	}
+}
@@ -0,0 +1,166 @@
+//go:build !compile
+
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuggestSearch_JSON(t *testing.T) {
+	svc := NewMockService(t)
+
+	svc.EXPECT().SuggestSearch(mock.Anything, "inst", 5).Return([]core.SearchSuggestion{
+		{ID: "b3duZXIvcmVwbwBpbnN0YWxsLm1k", Repo: "owner/repo", Path: "install.md", Title: "Installation"},
+		{ID: "b3duZXIvcmVwbwBkZXBsb3kubWQ", Repo: "owner/repo", Path: "deploy.md", Title: "Deploying", Heading: "Install the Agent", Anchor: "install-the-agent"},
+	}, nil)
+
+	api := &API{svc: svc}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/suggest?q=inst&limit=5", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	api.suggestSearch(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"suggestions":[
+		{"id":"b3duZXIvcmVwbwBpbnN0YWxsLm1k","repo":"owner/repo","path":"install.md","title":"Installation"},
+		{"id":"b3duZXIvcmVwbwBkZXBsb3kubWQ","repo":"owner/repo","path":"deploy.md","title":"Deploying","heading":"Install the Agent","anchor":"install-the-agent"}
+	]}`, rec.Body.String())
+}
+
+func TestSuggestSearch_NoSuggestions(t *testing.T) {
+	svc := NewMockService(t)
+
+	svc.EXPECT().SuggestSearch(mock.Anything, "", 0).Return(nil, nil)
+
+	api := &API{svc: svc}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/suggest?limit=many", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	api.suggestSearch(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"suggestions":[]}`, rec.Body.String())
+}
+
+func TestSuggestSearch_HTMX(t *testing.T) {
+	svc := NewMockService(t)
+	views := NewMockViewRenderer(t)
+
+	suggestions := []core.SearchSuggestion{{Repo: "owner/repo", Path: "install.md", Title: "Installation"}}
+
+	svc.EXPECT().SuggestSearch(mock.Anything, "inst", 0).Return(suggestions, nil)
+	views.EXPECT().RenderSuggestions(mock.Anything, "inst", suggestions).Return(nil)
+
+	api := &API{svc: svc, views: views}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/suggest?q=inst", http.NoBody)
+	req.Header.Set("HX-Request", "true")
+
+	rec := httptest.NewRecorder()
+
+	api.suggestSearch(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+}
+
+func TestSuggestSearch_Error(t *testing.T) {
+	svc := NewMockService(t)
+
+	svc.EXPECT().SuggestSearch(mock.Anything, "inst", 0).Return(nil, errors.New("index unavailable"))
+
+	api := &API{svc: svc}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/suggest?q=inst", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	api.suggestSearch(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestSuggestSearch_HostSite(t *testing.T) {
+	svc := NewMockService(t)
+
+	api, err := New(Config{
+		Listen: ":0",
+		Hosts:  []HostConfig{{Host: "docs.team-x.example.com", Repos: []string{"team-x"}}},
+	}, svc, NewMockViewRenderer(t))
+	require.NoError(t, err)
+
+	mux, err := api.newMux()
+	require.NoError(t, err)
+
+	svc.EXPECT().SuggestSearch(mock.Anything, "run", 0).Return([]core.SearchSuggestion{
+		{ID: "dGVhbS15L3dlYgBydW4ubWQ", Repo: "team-y/web", Path: "run.md", Title: "Running"},
+		{ID: "dGVhbS14L2FwaQBydW5ib29rLm1k", Repo: "team-x/api", Path: "runbook.md", Title: "Runbook"},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/suggest?q=run", http.NoBody)
+	req.Host = "docs.team-x.example.com"
+
+	rec := httptest.NewRecorder()
+
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"suggestions":[{"id":"dGVhbS14L2FwaQBydW5ib29rLm1k","repo":"team-x/api","path":"runbook.md","title":"Runbook"}]}`, rec.Body.String())
+}
+
+func TestSuggestSearch_CORS(t *testing.T) {
+	svc := NewMockService(t)
+
+	api, err := New(Config{
+		Listen: ":0",
+		CORS:   []CORSConfig{{Routes: []string{"/api/v1/suggest"}, Origins: []string{"chrome-extension://abc"}}},
+	}, svc, NewMockViewRenderer(t))
+	require.NoError(t, err)
+
+	mux, err := api.newMux()
+	require.NoError(t, err)
+
+	// The preflight request is answered without reaching the handler.
+	req := httptest.NewRequest(http.MethodOptions, "/api/v1/suggest?q=inst", http.NoBody)
+	req.Header.Set("Origin", "chrome-extension://abc")
+	req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "chrome-extension://abc", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, HEAD", rec.Header().Get("Access-Control-Allow-Methods"))
+
+	svc.EXPECT().SuggestSearch(mock.Anything, "inst", 0).Return(nil, nil)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/suggest?q=inst", http.NoBody)
+	req.Header.Set("Origin", "chrome-extension://abc")
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "chrome-extension://abc", rec.Header().Get("Access-Control-Allow-Origin"))
+
+	// Other routes are not shared with the extension.
+	req = httptest.NewRequest(http.MethodOptions, "/raw/acme/api/README.md", http.NoBody)
+	req.Header.Set("Origin", "chrome-extension://abc")
+	req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+}
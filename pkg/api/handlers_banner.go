@@ -0,0 +1,68 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+// maxBannerBody bounds the body of a request to set the site banner.
+const maxBannerBody = 16 << 10
+
+// getBanner handles GET /api/v1/banner - returns the site banner, whether or not it is
+// currently shown.
+func (a *API) getBanner(w http.ResponseWriter, r *http.Request) {
+	b, err := a.svc.GetSiteBanner(r.Context())
+	if err != nil {
+		bannerError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, b)
+}
+
+// setBanner handles PUT /api/v1/banner - replaces the site banner shown at the top of every
+// portal page. It is accepted in every operating mode, so readers can be told about
+// maintenance while content changes are rejected.
+func (a *API) setBanner(w http.ResponseWriter, r *http.Request) {
+	var req core.SiteBanner
+
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBannerBody)).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	b, err := a.svc.SetSiteBanner(r.Context(), req)
+	if err != nil {
+		bannerError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, b)
+}
+
+// clearBanner handles DELETE /api/v1/banner - removes the site banner.
+func (a *API) clearBanner(w http.ResponseWriter, r *http.Request) {
+	if err := a.svc.ClearSiteBanner(r.Context()); err != nil {
+		bannerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// bannerError writes the response for an error reading or changing the site banner.
+func bannerError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, core.ErrInvalidPath):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, core.ErrNotFound):
+		http.Error(w, "no site banner is set", http.StatusNotFound)
+	default:
+		slog.ErrorContext(r.Context(), "Failed to handle site banner", "error", err)
+		http.Error(w, "failed to handle site banner", http.StatusInternalServerError)
+	}
+}
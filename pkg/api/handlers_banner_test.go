@@ -0,0 +1,83 @@
+//go:build !compile
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBanner(t *testing.T) {
+	_, mux, svc, _ := newTestMux(t, adminTestConfig)
+
+	svc.EXPECT().GetSiteBanner(mock.Anything).Return(&core.SiteBanner{Message: "Maintenance tonight", Severity: core.BannerWarning}, nil).Once()
+
+	rec := serveAdmin(mux, http.MethodGet, "/api/v1/banner", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"message":"Maintenance tonight","severity":"warning"`)
+
+	svc.EXPECT().GetSiteBanner(mock.Anything).Return(nil, fmt.Errorf("%w: no site banner is set", core.ErrNotFound)).Once()
+
+	assert.Equal(t, http.StatusNotFound, serveAdmin(mux, http.MethodGet, "/api/v1/banner", "").Code)
+}
+
+func TestSetBanner(t *testing.T) {
+	tests := []struct {
+		err      error
+		name     string
+		body     string
+		wantCode int
+		noCall   bool
+	}{
+		{name: "set", body: `{"message":"Maintenance tonight","severity":"warning","ends_at":"2026-03-01T12:00:00Z"}`, wantCode: http.StatusOK},
+		{name: "invalid body", body: `{`, wantCode: http.StatusBadRequest, noCall: true},
+		{name: "invalid banner", body: `{"message":""}`, err: fmt.Errorf("%w: banner message is required", core.ErrInvalidPath), wantCode: http.StatusBadRequest},
+		{name: "internal error", body: `{"message":"hi"}`, err: errors.New("disk full"), wantCode: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, mux, svc, _ := newTestMux(t, adminTestConfig)
+
+			if !tt.noCall {
+				var b *core.SiteBanner
+				if tt.err == nil {
+					b = &core.SiteBanner{Message: "Maintenance tonight", Severity: core.BannerWarning}
+				}
+
+				svc.EXPECT().SetSiteBanner(mock.Anything, mock.Anything).Return(b, tt.err)
+			}
+
+			rec := serveAdmin(mux, http.MethodPut, "/api/v1/banner", tt.body)
+			assert.Equal(t, tt.wantCode, rec.Code)
+		})
+	}
+}
+
+func TestSetBanner_ReadOnlyMode(t *testing.T) {
+	api := &API{config: Config{APIKeys: []string{"admin-key"}}, svc: NewMockService(t), views: NewMockViewRenderer(t)}
+	require.NoError(t, api.SetMode(ModeReadOnly, "storage migration"))
+
+	mux, err := api.newMux()
+	require.NoError(t, err)
+
+	rec := serveAdmin(mux, http.MethodPut, "/api/v1/banner", `{"message":"Publishing is paused","severity":"warning"}`)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(t, rec.Body.String(), "storage migration")
+
+	assert.Equal(t, http.StatusServiceUnavailable, serveAdmin(mux, http.MethodDelete, "/api/v1/banner", "").Code)
+}
+
+func TestBanner_RequiresAdminKey(t *testing.T) {
+	_, mux, _, _ := newTestMux(t, adminTestConfig)
+
+	rec := serveSavedSearch(mux, http.MethodPut, "/api/v1/banner", `{"message":"hi"}`)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"encoding/xml"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// sitemapNamespace is the XML namespace of the sitemap protocol.
+const sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// sitemapURLSet is the root element of a sitemap.
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+// sitemapURL is a page listed in a sitemap.
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// robotsTxt handles GET /robots.txt - lets crawlers index the portal, pointing them at the
+// sitemap, when anonymous readers can see it, and keeps them out otherwise.
+func (a *API) robotsTxt(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+	login := a.login()
+	if !login.Anonymous(r) {
+		_, _ = fmt.Fprint(w, "User-agent: *\nDisallow: /\n")
+		return
+	}
+
+	_, _ = fmt.Fprintf(w, "User-agent: *\nAllow: /\nSitemap: %s/sitemap.xml\n", requestOrigin(r))
+}
+
+// sitemap handles GET /sitemap.xml - lists the document pages served to the request's
+// host for crawlers, leaving drafts out. Hosts where readers must sign in have no sitemap.
+func (a *API) sitemap(w http.ResponseWriter, r *http.Request) {
+	login := a.login()
+	if !login.Anonymous(r) {
+		http.NotFound(w, r)
+		return
+	}
+
+	repos, err := a.siteRepos(r)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Failed to list repos", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	origin := requestOrigin(r)
+	set := sitemapURLSet{Xmlns: sitemapNamespace}
+
+	for _, repo := range repos {
+		docs, err := a.svc.ListDocuments(r.Context(), repo.Name)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "Failed to list documents", "repo", repo.Name, "error", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+			return
+		}
+
+		for _, doc := range docs {
+			if doc.Draft {
+				continue
+			}
+
+			u := sitemapURL{Loc: origin + "/docs/" + repo.Name + "/" + escapeDocPath(doc.Path)}
+			if !doc.UpdatedAt.IsZero() {
+				u.LastMod = doc.UpdatedAt.UTC().Format(time.RFC3339)
+			}
+
+			set.URLs = append(set.URLs, u)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+
+	_, _ = fmt.Fprint(w, xml.Header)
+
+	if err := xml.NewEncoder(w).Encode(set); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to write sitemap", "error", err)
+	}
+}
+
+// requestOrigin returns the scheme and host the request was made to, taking the scheme
+// from the X-Forwarded-Proto header set by a TLS-terminating proxy.
+func requestOrigin(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https") {
+		scheme = "https"
+	}
+
+	return scheme + "://" + r.Host
+}
+
+// escapeDocPath escapes each segment of a document path for use in a URL.
+func escapeDocPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,162 @@
+//go:build !compile
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// publicModeTestConfig configures an instance whose readers sign in through a proxy,
+// except on the public developer portal serving acme/sdk.
+var publicModeTestConfig = Config{
+	Hosts: []HostConfig{{Host: "developers.example.com", Repos: []string{"acme/sdk"}, Public: true}},
+	Login: LoginConfig{UserHeader: "X-Forwarded-User", URL: "/oauth2/start"},
+}
+
+func TestRobotsTxt(t *testing.T) {
+	tests := []struct {
+		name   string
+		host   string
+		login  LoginConfig
+		want   string
+		secure bool
+	}{
+		{
+			name: "login not required",
+			host: "docs.example.com",
+			want: "User-agent: *\nAllow: /\nSitemap: http://docs.example.com/sitemap.xml\n",
+		},
+		{
+			name:   "public host",
+			host:   "developers.example.com",
+			login:  LoginConfig{UserHeader: "X-Forwarded-User"},
+			want:   "User-agent: *\nAllow: /\nSitemap: https://developers.example.com/sitemap.xml\n",
+			secure: true,
+		},
+		{
+			name:  "login required",
+			host:  "docs.example.com",
+			login: LoginConfig{UserHeader: "X-Forwarded-User"},
+			want:  "User-agent: *\nDisallow: /\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api, err := New(Config{
+				Listen: ":0",
+				Hosts:  []HostConfig{{Host: "developers.example.com", Repos: []string{"acme/sdk"}, Public: true}},
+				Login:  tt.login,
+			}, NewMockService(t), NewMockViewRenderer(t))
+			require.NoError(t, err)
+
+			mux, err := api.newMux()
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/robots.txt", http.NoBody)
+			req.Host = tt.host
+
+			if tt.secure {
+				req.Header.Set("X-Forwarded-Proto", "https")
+			}
+
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.want, rec.Body.String())
+		})
+	}
+}
+
+func TestSitemap_PublicHost(t *testing.T) {
+	_, mux, svc, _ := newTestMux(t, publicModeTestConfig)
+
+	updated := time.Date(2025, 6, 15, 10, 0, 0, 0, time.UTC)
+
+	svc.EXPECT().ListRepos(mock.Anything).Return([]core.RepoInfo{{Name: "acme/internal"}, {Name: "acme/sdk"}}, nil)
+	svc.EXPECT().ListDocuments(mock.Anything, "acme/sdk").Return([]core.DocumentMeta{
+		{Repo: "acme/sdk", Path: "README.md", UpdatedAt: updated},
+		{Repo: "acme/sdk", Path: "guides/quick start.md"},
+		{Repo: "acme/sdk", Path: "guides/upcoming.md", Draft: true},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/sitemap.xml", http.NoBody)
+	req.Host = "developers.example.com"
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/xml; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`)
+	assert.Contains(t, rec.Body.String(), "<url><loc>http://developers.example.com/docs/acme/sdk/README.md</loc><lastmod>2025-06-15T10:00:00Z</lastmod></url>")
+	assert.Contains(t, rec.Body.String(), "<url><loc>http://developers.example.com/docs/acme/sdk/guides/quick%20start.md</loc></url>")
+	assert.NotContains(t, rec.Body.String(), "acme/internal")
+	assert.NotContains(t, rec.Body.String(), "upcoming.md", "drafts are not listed")
+}
+
+func TestSitemap_LoginRequired(t *testing.T) {
+	_, mux, _, _ := newTestMux(t, publicModeTestConfig)
+
+	req := httptest.NewRequest(http.MethodGet, "/sitemap.xml", http.NoBody)
+	req.Host = "docs.example.com"
+	req.Header.Set("X-Forwarded-User", "alice")
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestPublicMode_Portal(t *testing.T) {
+	_, mux, svc, views := newTestMux(t, publicModeTestConfig)
+
+	svc.EXPECT().ListRepos(mock.Anything).Return([]core.RepoInfo{{Name: "acme/internal"}, {Name: "acme/sdk"}}, nil)
+	views.EXPECT().RenderHome(mock.Anything, []core.RepoInfo{{Name: "acme/sdk"}}, false).Return(nil)
+
+	// The public portal is served anonymously, with the public repositories only.
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	req.Host = "developers.example.com"
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// Other repositories are not reachable through it.
+	req = httptest.NewRequest(http.MethodGet, "/raw/acme/internal/README.md", http.NoBody)
+	req.Host = "developers.example.com"
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	// Other hosts ask readers to sign in.
+	req = httptest.NewRequest(http.MethodGet, "/docs/acme/internal/README.md", http.NoBody)
+	req.Host = "docs.example.com"
+	req.Header.Set("Accept", "text/html")
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusFound, rec.Code)
+	assert.Equal(t, "/oauth2/start?rd=%2Fdocs%2Facme%2Finternal%2FREADME.md", rec.Header().Get("Location"))
+
+	req = httptest.NewRequest(http.MethodGet, "/search?q=deploy", http.NoBody)
+	req.Host = "docs.example.com"
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
@@ -0,0 +1,26 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/ksysoev/omnidex/pkg/api/middleware"
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+// docByID handles GET /api/v1/docs/{id} - redirects an encoded document ID, as returned by
+// the search endpoints, to the portal page of the document. Unlike portal URLs, IDs need
+// no escaping and tell a sub-project's documents from the documents of its repository.
+func (a *API) docByID(w http.ResponseWriter, r *http.Request) {
+	id, err := core.ParseDocumentID(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "id must be an encoded document ID", http.StatusBadRequest)
+		return
+	}
+
+	if site, ok := middleware.HostSite(r.Context()); ok && !site.Serves(id.Repo) {
+		http.NotFound(w, r)
+		return
+	}
+
+	http.Redirect(w, r, "/docs/"+id.Repo+"/"+escapeDocPath(id.Path), http.StatusFound)
+}
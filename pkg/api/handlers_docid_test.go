@@ -0,0 +1,74 @@
+//go:build !compile
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocByID(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       string
+		location string
+		code     int
+	}{
+		{
+			name:     "document",
+			id:       core.NewDocumentID("acme/api", "guides/deploy.md").Encode(),
+			code:     http.StatusFound,
+			location: "/docs/acme/api/guides/deploy.md",
+		},
+		{
+			name:     "sub-project document with reserved characters",
+			id:       core.NewDocumentID("acme/mono/web", "notes/50% off #2.md").Encode(),
+			code:     http.StatusFound,
+			location: "/docs/acme/mono/web/notes/50%25%20off%20%232.md",
+		},
+		{name: "malformed", id: "acme%2Fapi%2Fguide.md", code: http.StatusBadRequest},
+		{name: "invalid repo", id: core.NewDocumentID("../etc", "passwd").Encode(), code: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api, err := New(Config{Listen: ":0"}, NewMockService(t), NewMockViewRenderer(t))
+			require.NoError(t, err)
+
+			mux, err := api.newMux()
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/docs/"+tt.id, http.NoBody)
+			rec := httptest.NewRecorder()
+
+			mux.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.code, rec.Code)
+			assert.Equal(t, tt.location, rec.Header().Get("Location"))
+		})
+	}
+}
+
+func TestDocByID_HostSite(t *testing.T) {
+	api, err := New(Config{
+		Listen: ":0",
+		Hosts:  []HostConfig{{Host: "docs.team-x.example.com", Repos: []string{"team-x"}}},
+	}, NewMockService(t), NewMockViewRenderer(t))
+	require.NoError(t, err)
+
+	mux, err := api.newMux()
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/docs/"+core.NewDocumentID("acme/api", "guide.md").Encode(), http.NoBody)
+	req.Host = "docs.team-x.example.com"
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
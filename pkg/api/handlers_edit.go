@@ -0,0 +1,258 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/ksysoev/omnidex/pkg/api/middleware"
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+type keyEditor struct{}
+
+// lockRequest is the body of a lock request. The lock is owned by the signed-in editor.
+type lockRequest struct {
+	Repo  string `json:"repo"`
+	Path  string `json:"path"`
+	Token string `json:"token,omitempty"` // Token of a held lock to renew.
+}
+
+// lockResponse is a held document lock with the document's current source.
+type lockResponse struct {
+	Lock    *core.DocumentLock `json:"lock"`
+	Content string             `json:"content"`
+}
+
+// previewRequest is the body of an edit preview request.
+type previewRequest struct {
+	Repo    string `json:"repo"`
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// previewResponse is the rendering of edited content.
+type previewResponse struct {
+	HTML string `json:"html"`
+}
+
+// editPage handles GET /edit/{owner}/{repo}/{path...} - the web editor of a document of an
+// editable repository. The page loads the document's source through the API once the
+// editor holds the document's lock.
+func (a *API) editPage(w http.ResponseWriter, r *http.Request) {
+	repo, path, ok := editTarget(r, a.svc.Editable)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	doc, err := a.svc.GetDocumentSource(r.Context(), repo, path)
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+
+		slog.ErrorContext(r.Context(), "Failed to get document", "error", err, "repo", repo, "path", path)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if err := a.viewsFor(r).RenderEditor(w, doc); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to render editor page", "error", err)
+	}
+}
+
+// editTarget returns the repository and path of the document named by the owner, repo and
+// path values of r, and whether allowed accepts the repository. The document may belong to
+// a monorepo sub-project named by the first path segment.
+func editTarget(r *http.Request, allowed func(repo string) bool) (repo, path string, ok bool) {
+	repo = r.PathValue("owner") + "/" + r.PathValue("repo")
+	path = r.PathValue("path")
+
+	if !allowed(repo) {
+		if projectRepo, rest, split := core.SplitProject(repo, path); split {
+			repo, path = projectRepo, rest
+		}
+	}
+
+	return repo, path, path != "" && allowed(repo)
+}
+
+// withEditor lets only readers signed in through the authenticating proxy use the web
+// editor, and stores the reader's name in the request context, see editorName. API keys
+// are not accepted: they grant administrative access and would have to be kept in the
+// browser. POST requests must have a JSON body, so that other sites cannot send them from
+// a form. Editing is forbidden when readers do not sign in, and on public sites, which the
+// proxy does not front. It must run after the host routing middleware.
+func (a *API) withEditor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := a.config.Login.UserHeader
+		if site, ok := middleware.HostSite(r.Context()); header == "" || (ok && site.Public) {
+			http.Error(w, "editing requires readers to sign in", http.StatusForbidden)
+			return
+		}
+
+		user := strings.TrimSpace(r.Header.Get(header))
+		if user == "" {
+			http.Error(w, "sign in required", http.StatusUnauthorized)
+			return
+		}
+
+		if r.Method == http.MethodPost {
+			if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+				http.Error(w, "request body must be application/json", http.StatusUnsupportedMediaType)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), keyEditor{}, user)))
+	})
+}
+
+// editorName returns the signed-in editor stored by withEditor.
+func editorName(ctx context.Context) string {
+	name, _ := ctx.Value(keyEditor{}).(string)
+	return name
+}
+
+// lockDocument handles POST /api/v1/locks - claims a document of an editable repository
+// for the signed-in editor, or renews a lock they hold, and returns the document's source.
+func (a *API) lockDocument(w http.ResponseWriter, r *http.Request) {
+	var req lockRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to decode lock request", "error", err)
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+
+		return
+	}
+
+	if req.Repo == "" || req.Path == "" {
+		http.Error(w, "repo and path are required", http.StatusBadRequest)
+		return
+	}
+
+	if site, ok := middleware.HostSite(r.Context()); ok && !site.Serves(req.Repo) {
+		http.NotFound(w, r)
+		return
+	}
+
+	lock, doc, err := a.svc.LockDocument(r.Context(), req.Repo, req.Path, editorName(r.Context()), req.Token)
+	if err != nil {
+		writeEditError(w, r, err, "lock document", req.Repo, req.Path)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, lockResponse{Lock: lock, Content: doc.Content})
+}
+
+// unlockDocument handles DELETE /api/v1/locks?repo=...&path=...&token=... - releases a
+// lock held by the caller.
+func (a *API) unlockDocument(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	repo, path := q.Get("repo"), q.Get("path")
+
+	if err := a.svc.UnlockDocument(r.Context(), repo, path, q.Get("token")); err != nil {
+		writeEditError(w, r, err, "unlock document", repo, path)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// previewEdit handles POST /api/v1/preview - renders edited content of a document the way
+// the portal renders it once saved.
+func (a *API) previewEdit(w http.ResponseWriter, r *http.Request) {
+	var req previewRequest
+
+	r.Body = http.MaxBytesReader(w, r.Body, a.config.MaxIngestBodyMiB*mib)
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to decode preview request", "error", err)
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+
+		return
+	}
+
+	if site, ok := middleware.HostSite(r.Context()); ok && !site.Serves(req.Repo) {
+		http.NotFound(w, r)
+		return
+	}
+
+	html, _, err := a.svc.PreviewEdit(r.Context(), req.Repo, req.Path, req.Content)
+	if err != nil {
+		writeEditError(w, r, err, "preview edit", req.Repo, req.Path)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, previewResponse{HTML: string(html)})
+}
+
+// saveEdit handles POST /api/v1/edits - commits an edited document to its source
+// repository and publishes it. The signed-in editor must hold the document's lock.
+func (a *API) saveEdit(w http.ResponseWriter, r *http.Request) {
+	var req core.EditRequest
+
+	r.Body = http.MaxBytesReader(w, r.Body, a.config.MaxIngestBodyMiB*mib)
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to decode edit request", "error", err)
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+
+		return
+	}
+
+	if req.Repo == "" || req.Path == "" || req.Token == "" {
+		http.Error(w, "repo, path and token are required", http.StatusBadRequest)
+		return
+	}
+
+	if site, ok := middleware.HostSite(r.Context()); ok && !site.Serves(req.Repo) {
+		http.NotFound(w, r)
+		return
+	}
+
+	req.Editor = editorName(r.Context())
+
+	resp, err := a.svc.SaveEdit(r.Context(), req)
+	if err != nil {
+		writeEditError(w, r, err, "save edit", req.Repo, req.Path)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, resp)
+}
+
+// writeEditError writes the response for an error of the editor operation action, such
+// as "lock document".
+func writeEditError(w http.ResponseWriter, r *http.Request, err error, action, repo, path string) {
+	var lintErr *core.LintError
+
+	switch {
+	case errors.As(err, &lintErr):
+		writeJSON(w, r, http.StatusUnprocessableEntity, map[string]any{"error": lintErr.Error(), "lint": lintErr.Issues})
+	case errors.Is(err, core.ErrNotFound):
+		http.Error(w, "document not found", http.StatusNotFound)
+	case errors.Is(err, core.ErrInvalidPath):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, core.ErrConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, core.ErrLimitExceeded):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	case errors.Is(err, core.ErrRateLimited):
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+	case errors.Is(err, core.ErrNotSupported):
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+	default:
+		slog.ErrorContext(r.Context(), "Failed to "+action, "error", err, "repo", repo, "path", path)
+		http.Error(w, "failed to "+action, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,217 @@
+//go:build !compile
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// editTestConfig accepts the admin-key API key and limits request bodies to 1 MiB.
+var editTestConfig = Config{APIKeys: []string{"admin-key"}, MaxIngestBodyMiB: 1}
+
+// editorTestConfig also has readers sign in through a proxy, except on a public portal.
+var editorTestConfig = Config{
+	APIKeys:          []string{"admin-key"},
+	MaxIngestBodyMiB: 1,
+	Login:            LoginConfig{UserHeader: "X-Forwarded-User"},
+	Hosts:            []HostConfig{{Host: "developers.example.com", Public: true, Repos: []string{"owner"}}},
+}
+
+// serveEditor sends a JSON request to mux as the signed-in reader Alex.
+func serveEditor(mux http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("X-Forwarded-User", "Alex")
+	req.Header.Set("Content-Type", "application/json")
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestLockDocument(t *testing.T) {
+	tests := []struct {
+		err      error
+		name     string
+		body     string
+		wantCode int
+		noCall   bool
+	}{
+		{name: "locked", body: `{"repo":"owner/wiki","path":"guide.md"}`, wantCode: http.StatusOK},
+		{name: "owner chosen by the client is ignored", body: `{"repo":"owner/wiki","path":"guide.md","owner":"Sam"}`, wantCode: http.StatusOK},
+		{name: "invalid body", body: `{`, wantCode: http.StatusBadRequest, noCall: true},
+		{name: "missing path", body: `{"repo":"owner/wiki"}`, wantCode: http.StatusBadRequest, noCall: true},
+		{name: "held by another editor", body: `{"repo":"owner/wiki","path":"guide.md"}`, err: core.ErrConflict, wantCode: http.StatusConflict},
+		{name: "not found", body: `{"repo":"owner/wiki","path":"guide.md"}`, err: core.ErrNotFound, wantCode: http.StatusNotFound},
+		{name: "not editable", body: `{"repo":"owner/wiki","path":"guide.md"}`, err: core.ErrNotSupported, wantCode: http.StatusNotImplemented},
+		{name: "internal error", body: `{"repo":"owner/wiki","path":"guide.md"}`, err: errors.New("boom"), wantCode: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, mux, svc, _ := newTestMux(t, editorTestConfig)
+
+			if !tt.noCall {
+				var lock *core.DocumentLock
+				if tt.err == nil {
+					lock = &core.DocumentLock{Repo: "owner/wiki", Path: "guide.md", Owner: "Alex", Token: "tok"}
+				}
+
+				svc.EXPECT().LockDocument(mock.Anything, "owner/wiki", "guide.md", "Alex", "").
+					Return(lock, core.Document{Content: "# Guide"}, tt.err)
+			}
+
+			rec := serveEditor(mux, http.MethodPost, "/api/v1/locks", tt.body)
+			assert.Equal(t, tt.wantCode, rec.Code)
+
+			if tt.wantCode == http.StatusOK {
+				var resp lockResponse
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+				assert.Equal(t, "tok", resp.Lock.Token)
+				assert.Equal(t, "# Guide", resp.Content)
+			}
+		})
+	}
+}
+
+func TestUnlockDocument(t *testing.T) {
+	_, mux, svc, _ := newTestMux(t, editorTestConfig)
+
+	svc.EXPECT().UnlockDocument(mock.Anything, "owner/wiki", "guide.md", "tok").Return(nil).Once()
+	assert.Equal(t, http.StatusNoContent, serveEditor(mux, http.MethodDelete, "/api/v1/locks?repo=owner/wiki&path=guide.md&token=tok", "").Code)
+
+	svc.EXPECT().UnlockDocument(mock.Anything, "owner/wiki", "guide.md", "other").Return(core.ErrConflict).Once()
+	assert.Equal(t, http.StatusConflict, serveEditor(mux, http.MethodDelete, "/api/v1/locks?repo=owner/wiki&path=guide.md&token=other", "").Code)
+}
+
+func TestPreviewEdit(t *testing.T) {
+	_, mux, svc, _ := newTestMux(t, editorTestConfig)
+
+	svc.EXPECT().PreviewEdit(mock.Anything, "owner/wiki", "guide.md", "# New").
+		Return([]byte(`<h1 id="new">New</h1>`), []core.Heading{{ID: "new", Text: "New", Level: 1}}, nil).Once()
+
+	rec := serveEditor(mux, http.MethodPost, "/api/v1/preview", `{"repo":"owner/wiki","path":"guide.md","content":"# New"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"html":"<h1 id=\"new\">New</h1>"}`, rec.Body.String())
+
+	svc.EXPECT().PreviewEdit(mock.Anything, "owner/wiki", "deep.md", "> > >").
+		Return(nil, nil, core.ErrLimitExceeded).Once()
+
+	rec = serveEditor(mux, http.MethodPost, "/api/v1/preview", `{"repo":"owner/wiki","path":"deep.md","content":"> > >"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+}
+
+func TestSaveEdit(t *testing.T) {
+	_, mux, svc, _ := newTestMux(t, editorTestConfig)
+
+	req := core.EditRequest{Repo: "owner/wiki", Path: "guide.md", Content: "# New", Message: "Fix typo", Token: "tok", Editor: "Alex"}
+
+	svc.EXPECT().SaveEdit(mock.Anything, req).
+		Return(&core.EditResponse{Repo: "owner/wiki", Path: "guide.md", CommitSHA: "abc123"}, nil).Once()
+
+	rec := serveEditor(mux, http.MethodPost, "/api/v1/edits", `{"repo":"owner/wiki","path":"guide.md","content":"# New","message":"Fix typo","token":"tok"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"repo":"owner/wiki","path":"guide.md","commit_sha":"abc123"}`, rec.Body.String())
+
+	rec = serveEditor(mux, http.MethodPost, "/api/v1/edits", `{"repo":"owner/wiki","path":"guide.md","content":"# New"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "a lock token is required")
+
+	svc.EXPECT().SaveEdit(mock.Anything, mock.Anything).
+		Return(nil, &core.LintError{Issues: []core.LintIssue{{Path: "guide.md", Rule: core.LintRuleMissingH1, Message: "missing H1"}}}).Once()
+
+	rec = serveEditor(mux, http.MethodPost, "/api/v1/edits", `{"repo":"owner/wiki","path":"guide.md","content":"text","token":"tok"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"rule":"missing-h1"`)
+
+	svc.EXPECT().SaveEdit(mock.Anything, mock.Anything).Return(nil, core.ErrConflict).Once()
+
+	rec = serveEditor(mux, http.MethodPost, "/api/v1/edits", `{"repo":"owner/wiki","path":"guide.md","content":"# New","token":"tok"}`)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+}
+
+func TestEditorAuth(t *testing.T) {
+	_, mux, _, _ := newTestMux(t, editorTestConfig)
+
+	body := `{"repo":"owner/wiki","path":"guide.md","content":"# New","token":"tok"}`
+
+	tests := []struct {
+		name        string
+		host        string
+		user        string
+		auth        string
+		contentType string
+		wantCode    int
+	}{
+		{name: "not signed in", contentType: "application/json", wantCode: http.StatusUnauthorized},
+		{name: "API key", auth: "Bearer admin-key", contentType: "application/json", wantCode: http.StatusUnauthorized},
+		{name: "form post", user: "Alex", contentType: "text/plain", wantCode: http.StatusUnsupportedMediaType},
+		{name: "public host", host: "developers.example.com", user: "Alex", contentType: "application/json", wantCode: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/edits", strings.NewReader(body))
+			if tt.host != "" {
+				req.Host = tt.host
+			}
+
+			req.Header.Set("X-Forwarded-User", tt.user)
+			req.Header.Set("Authorization", tt.auth)
+			req.Header.Set("Content-Type", tt.contentType)
+
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+		})
+	}
+}
+
+func TestEditorAuth_NoLogin(t *testing.T) {
+	_, mux, _, _ := newTestMux(t, editTestConfig)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/locks", strings.NewReader(`{"repo":"owner/wiki","path":"guide.md"}`))
+	req.Header.Set("X-Forwarded-User", "Alex")
+	req.Header.Set("Content-Type", "application/json")
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code, "editing is unavailable when readers do not sign in")
+}
+
+func TestEditPage(t *testing.T) {
+	_, mux, svc, views := newTestMux(t, editTestConfig)
+
+	doc := core.Document{Repo: "owner/mono/wiki", Path: "guide.md", Title: "Guide"}
+
+	svc.EXPECT().Editable("owner/mono").Return(false)
+	svc.EXPECT().Editable("owner/mono/wiki").Return(true)
+	svc.EXPECT().GetDocumentSource(mock.Anything, "owner/mono/wiki", "guide.md").Return(doc, nil)
+	views.EXPECT().RenderEditor(mock.Anything, doc).RunAndReturn(func(w io.Writer, _ core.Document) error {
+		_, err := w.Write([]byte("<div>editor</div>"))
+		return err
+	})
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/edit/owner/mono/wiki/guide.md", http.NoBody))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "<div>editor</div>", rec.Body.String())
+
+	svc.EXPECT().Editable("owner/repo").Return(false)
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/edit/owner/repo/guide.md", http.NoBody))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/api/middleware"
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+// maxPresenceBody is the maximum size of a presence heartbeat.
+const maxPresenceBody = 4 << 10
+
+// Limits of the fields of a presence heartbeat.
+const (
+	maxViewerID      = 64
+	maxViewerName    = 64
+	maxViewerSection = 200
+)
+
+// presenceRequest is a heartbeat of a reader of an incident document.
+type presenceRequest struct {
+	Repo string `json:"repo"`
+	Path string `json:"path"`
+	core.Viewer
+	Leave bool `json:"leave,omitempty"`
+}
+
+// presenceResponse reports the incident of a document with its active viewers.
+type presenceResponse struct {
+	Incident *core.Incident `json:"incident"`
+	Viewers  []core.Viewer  `json:"viewers"`
+}
+
+// eventKeepAlive is how often a comment is sent on an idle event stream so that proxies
+// do not close the connection.
+const eventKeepAlive = 30 * time.Second
+
+// eventStream handles GET /events - streams document changes to open portal pages as
+// server-sent "document" events, so that they can offer to reload a changed page. On a
+// custom domain only changes of the site's repositories are sent.
+func (a *API) eventStream(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+
+	// The stream outlives the server's write timeout.
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		slog.ErrorContext(r.Context(), "Failed to clear write deadline of event stream", "error", err)
+		http.Error(w, "failed to open event stream", http.StatusInternalServerError)
+
+		return
+	}
+
+	site, scoped := middleware.HostSite(r.Context())
+	events := a.svc.Subscribe(r.Context())
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if err := rc.Flush(); err != nil {
+		slog.ErrorContext(r.Context(), "Event streaming is not supported by the response writer", "error", err)
+		return
+	}
+
+	keepAlive := time.NewTicker(eventKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-a.closing:
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+
+			if scoped && !site.Serves(ev.Repo) {
+				continue
+			}
+
+			data, err := json.Marshal(ev)
+			if err != nil {
+				slog.ErrorContext(r.Context(), "Failed to encode document event", "error", err)
+				continue
+			}
+
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", eventName(ev.Type), data); err != nil {
+				return
+			}
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// eventName returns the server-sent event name for a document event type.
+func eventName(eventType string) string {
+	switch eventType {
+	case core.EventIncidentStarted, core.EventIncidentEnded:
+		return "incident"
+	case core.EventPresence:
+		return "presence"
+	default:
+		return "document"
+	}
+}
+
+// getPresence handles GET /presence?repo=...&path=... - reports the incident of a flagged
+// document with its active viewers, or 404 Not Found if the document is not flagged.
+func (a *API) getPresence(w http.ResponseWriter, r *http.Request) {
+	repo, path := r.URL.Query().Get("repo"), r.URL.Query().Get("path")
+
+	if site, ok := middleware.HostSite(r.Context()); ok && !site.Serves(repo) {
+		http.NotFound(w, r)
+		return
+	}
+
+	inc, viewers, err := a.svc.Presence(r.Context(), repo, path)
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+
+		slog.ErrorContext(r.Context(), "Failed to get presence", "error", err, "repo", repo, "path", path)
+		http.Error(w, "failed to get presence", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, r, http.StatusOK, presenceResponse{Incident: inc, Viewers: viewers})
+}
+
+// updatePresence handles POST /presence - records a heartbeat of a reader of an incident
+// document: the section being read, or that the reader left.
+func (a *API) updatePresence(w http.ResponseWriter, r *http.Request) {
+	var req presenceRequest
+
+	// Browsers send the final heartbeat with navigator.sendBeacon, as text/plain.
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxPresenceBody)).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.ID == "" || len(req.ID) > maxViewerID || len(req.Name) > maxViewerName || len(req.Section) > maxViewerSection {
+		http.Error(w, fmt.Sprintf("id is required; id and name are limited to %d and section to %d bytes",
+			maxViewerID, maxViewerSection), http.StatusBadRequest)
+
+		return
+	}
+
+	if site, ok := middleware.HostSite(r.Context()); ok && !site.Serves(req.Repo) {
+		http.NotFound(w, r)
+		return
+	}
+
+	if err := a.svc.UpdatePresence(r.Context(), req.Repo, req.Path, req.Viewer, req.Leave); err != nil {
+		switch {
+		case errors.Is(err, core.ErrNotFound):
+			http.NotFound(w, r)
+		case errors.Is(err, core.ErrLimitExceeded):
+			http.Error(w, "too many viewers", http.StatusUnprocessableEntity)
+		default:
+			slog.ErrorContext(r.Context(), "Failed to update presence", "error", err, "repo", req.Repo, "path", req.Path)
+			http.Error(w, "failed to update presence", http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,134 @@
+//go:build !compile
+
+package api
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventStream(t *testing.T) {
+	svc := NewMockService(t)
+
+	api, err := New(Config{
+		Listen: ":0",
+		Hosts:  []HostConfig{{Host: "docs.team-x.example.com", Repos: []string{"team-x"}}},
+	}, svc, NewMockViewRenderer(t))
+	require.NoError(t, err)
+
+	mux, err := api.newMux()
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	events := make(chan core.DocumentEvent, 2)
+	events <- core.DocumentEvent{Type: core.EventDocumentUpdated, Repo: "team-y/web", Path: "readme.md"}
+	events <- core.DocumentEvent{Type: core.EventDocumentDeleted, Repo: "team-x/api", Path: "runbook.md"}
+
+	svc.EXPECT().Subscribe(mock.Anything).Return((<-chan core.DocumentEvent)(events))
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, srv.URL+"/events", http.NoBody)
+	require.NoError(t, err)
+
+	req.Host = "docs.team-x.example.com"
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+
+	reader := bufio.NewReader(resp.Body)
+
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "event: document\n", line)
+
+	line, err = reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(line, "data: {"), line)
+	assert.Contains(t, line, `"type":"deleted","repo":"team-x/api","path":"runbook.md"`, "events outside the site are skipped")
+
+	close(events)
+}
+
+func TestEventName(t *testing.T) {
+	assert.Equal(t, "document", eventName(core.EventDocumentUpdated))
+	assert.Equal(t, "incident", eventName(core.EventIncidentStarted))
+	assert.Equal(t, "incident", eventName(core.EventIncidentEnded))
+	assert.Equal(t, "presence", eventName(core.EventPresence))
+}
+
+func TestGetPresence(t *testing.T) {
+	svc := NewMockService(t)
+	api := &API{svc: svc, views: NewMockViewRenderer(t)}
+
+	mux, err := api.newMux()
+	require.NoError(t, err)
+
+	svc.EXPECT().Presence(mock.Anything, "owner/repo", "runbook.md").
+		Return(&core.Incident{Repo: "owner/repo", Path: "runbook.md", Name: "INC-1"}, []core.Viewer{{ID: "a", Name: "Alex", Section: "triage"}}, nil)
+	svc.EXPECT().Presence(mock.Anything, "owner/repo", "other.md").Return(nil, nil, core.ErrNotFound)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/presence?repo=owner/repo&path=runbook.md", http.NoBody))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+	assert.Contains(t, rec.Body.String(), `"viewers":[{"id":"a","name":"Alex","section":"triage"}]`)
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/presence?repo=owner/repo&path=other.md", http.NoBody))
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestUpdatePresence(t *testing.T) {
+	tests := []struct {
+		err      error
+		name     string
+		body     string
+		wantCode int
+		noCall   bool
+		leave    bool
+	}{
+		{name: "heartbeat", body: `{"repo":"owner/repo","path":"runbook.md","id":"a","name":"Alex","section":"triage"}`, wantCode: http.StatusNoContent},
+		{name: "leave", body: `{"repo":"owner/repo","path":"runbook.md","id":"a","name":"Alex","section":"triage","leave":true}`, leave: true, wantCode: http.StatusNoContent},
+		{name: "invalid body", body: `{`, wantCode: http.StatusBadRequest, noCall: true},
+		{name: "missing id", body: `{"repo":"owner/repo","path":"runbook.md"}`, wantCode: http.StatusBadRequest, noCall: true},
+		{name: "long section", body: `{"repo":"owner/repo","path":"runbook.md","id":"a","section":"` + strings.Repeat("x", maxViewerSection+1) + `"}`, wantCode: http.StatusBadRequest, noCall: true},
+		{name: "not flagged", body: `{"repo":"owner/repo","path":"runbook.md","id":"a","name":"Alex","section":"triage"}`, err: core.ErrNotFound, wantCode: http.StatusNotFound},
+		{name: "too many viewers", body: `{"repo":"owner/repo","path":"runbook.md","id":"a","name":"Alex","section":"triage"}`, err: core.ErrLimitExceeded, wantCode: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewMockService(t)
+			api := &API{svc: svc, views: NewMockViewRenderer(t)}
+
+			mux, err := api.newMux()
+			require.NoError(t, err)
+
+			if !tt.noCall {
+				svc.EXPECT().UpdatePresence(mock.Anything, "owner/repo", "runbook.md", core.Viewer{ID: "a", Name: "Alex", Section: "triage"}, tt.leave).Return(tt.err)
+			}
+
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/presence", strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/ksysoev/omnidex/pkg/api/middleware"
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+// excerpt handles GET /api/v1/excerpt?doc=owner/repo/path&anchor=... - returns the plain
+// text of one section of a document as JSON, for tools showing the relevant part of a
+// document inline, such as IDE tooltips and chat bots. The anchor may also follow the
+// path after "#", so links copied from document pages work as they are. Without an
+// anchor the introduction before the first heading is returned. The document may be
+// given instead as id=..., an encoded document ID as returned by the search endpoints,
+// which names sub-project documents and paths with "#" unambiguously.
+func (a *API) excerpt(w http.ResponseWriter, r *http.Request) {
+	doc, anchor, _ := strings.Cut(r.URL.Query().Get("doc"), "#")
+	if v := r.URL.Query().Get("anchor"); v != "" {
+		anchor = v
+	}
+
+	repo, path, byID, err := excerptDocument(r.URL.Query().Get("id"), doc)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if site, ok := middleware.HostSite(r.Context()); ok && !site.Serves(repo) {
+		http.NotFound(w, r)
+		return
+	}
+
+	ex, err := a.svc.GetExcerpt(r.Context(), repo, path, anchor)
+	if errors.Is(err, core.ErrNotFound) && !byID {
+		if projectRepo, projectPath, ok := core.SplitProject(repo, path); ok && projectPath != "" {
+			if pEx, pErr := a.svc.GetExcerpt(r.Context(), projectRepo, projectPath, anchor); !errors.Is(pErr, core.ErrNotFound) {
+				repo, ex, err = projectRepo, pEx, pErr
+			}
+		}
+	}
+
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+
+		slog.ErrorContext(r.Context(), "Failed to get excerpt", "error", err, "repo", repo, "path", path, "anchor", anchor)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	url := "/docs/" + ex.Repo + "/" + escapeDocPath(ex.Path)
+	if ex.Anchor != "" {
+		url += "#" + ex.Anchor
+	}
+
+	writeJSON(w, r, http.StatusOK, struct {
+		*core.Excerpt
+		URL string `json:"url"`
+	}{Excerpt: ex, URL: url})
+}
+
+// excerptDocument returns the repository and path of the document of an excerpt request,
+// named by an encoded document ID or else by a doc parameter of the form owner/repo/path.
+// byID reports whether the document was named by ID.
+func excerptDocument(id, doc string) (repo, path string, byID bool, err error) {
+	if id != "" {
+		docID, parseErr := core.ParseDocumentID(id)
+		if parseErr != nil {
+			return "", "", false, errors.New("id must be an encoded document ID")
+		}
+
+		return docID.Repo, docID.Path, true, nil
+	}
+
+	owner, rest, _ := strings.Cut(strings.TrimPrefix(doc, "/"), "/")
+	name, path, _ := strings.Cut(rest, "/")
+
+	if owner == "" || name == "" || path == "" {
+		return "", "", false, errors.New("doc must be owner/repo/path")
+	}
+
+	return owner + "/" + name, path, false, nil
+}
@@ -0,0 +1,157 @@
+//go:build !compile
+
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExcerpt(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+	}{
+		{name: "anchor parameter", target: "/api/v1/excerpt?doc=acme/api/guide.md&anchor=install"},
+		{name: "anchor in doc", target: "/api/v1/excerpt?doc=acme/api/guide.md%23install"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewMockService(t)
+
+			svc.EXPECT().GetExcerpt(mock.Anything, "acme/api", "guide.md", "install").Return(&core.Excerpt{
+				Repo:    "acme/api",
+				Path:    "guide.md",
+				Title:   "Guide",
+				Anchor:  "install",
+				Heading: "Install",
+				Text:    "Run the installer.",
+			}, nil)
+
+			api := &API{svc: svc}
+
+			req := httptest.NewRequest(http.MethodGet, tt.target, http.NoBody)
+			rec := httptest.NewRecorder()
+
+			api.excerpt(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.JSONEq(t, `{
+				"repo": "acme/api",
+				"path": "guide.md",
+				"title": "Guide",
+				"anchor": "install",
+				"heading": "Install",
+				"text": "Run the installer.",
+				"url": "/docs/acme/api/guide.md#install"
+			}`, rec.Body.String())
+		})
+	}
+}
+
+func TestExcerpt_ByID(t *testing.T) {
+	svc := NewMockService(t)
+
+	svc.EXPECT().GetExcerpt(mock.Anything, "acme/mono/api", "runbooks/incident #1.md", "").Return(&core.Excerpt{
+		Repo:  "acme/mono/api",
+		Path:  "runbooks/incident #1.md",
+		Title: "Incident",
+		Text:  "Page the on-call.",
+	}, nil)
+
+	api := &API{svc: svc}
+
+	id := core.NewDocumentID("acme/mono/api", "runbooks/incident #1.md").Encode()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/excerpt?id="+id, http.NoBody)
+	rec := httptest.NewRecorder()
+
+	api.excerpt(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{
+		"repo": "acme/mono/api",
+		"path": "runbooks/incident #1.md",
+		"title": "Incident",
+		"text": "Page the on-call.",
+		"url": "/docs/acme/mono/api/runbooks/incident%20%231.md"
+	}`, rec.Body.String())
+}
+
+func TestExcerpt_InvalidID(t *testing.T) {
+	api := &API{svc: NewMockService(t)}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/excerpt?id=not-an-id", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	api.excerpt(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestExcerpt_InvalidDoc(t *testing.T) {
+	api := &API{svc: NewMockService(t)}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/excerpt?doc=acme/api", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	api.excerpt(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestExcerpt_NotFound(t *testing.T) {
+	svc := NewMockService(t)
+
+	svc.EXPECT().GetExcerpt(mock.Anything, "acme/api", "guide.md", "missing").Return(nil, core.ErrNotFound)
+
+	api := &API{svc: svc}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/excerpt?doc=acme/api/guide.md&anchor=missing", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	api.excerpt(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestExcerpt_Error(t *testing.T) {
+	svc := NewMockService(t)
+
+	svc.EXPECT().GetExcerpt(mock.Anything, "acme/api", "guide.md", "").Return(nil, errors.New("store unavailable"))
+
+	api := &API{svc: svc}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/excerpt?doc=acme/api/guide.md", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	api.excerpt(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestExcerpt_HostSite(t *testing.T) {
+	api, err := New(Config{
+		Listen: ":0",
+		Hosts:  []HostConfig{{Host: "docs.team-x.example.com", Repos: []string{"team-x"}}},
+	}, NewMockService(t), NewMockViewRenderer(t))
+	require.NoError(t, err)
+
+	mux, err := api.newMux()
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/excerpt?doc=acme/api/guide.md", http.NoBody)
+	req.Host = "docs.team-x.example.com"
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
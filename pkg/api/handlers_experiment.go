@@ -0,0 +1,64 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// sessionCookie is the cookie identifying a reader's session in ranking experiments.
+	sessionCookie = "omnidex_session"
+	// sessionMaxAge is how long a reader keeps a session, and so a ranking variant.
+	sessionMaxAge = 30 * 24 * time.Hour
+	// maxSessionID bounds the length of session IDs accepted from cookies.
+	maxSessionID = 64
+)
+
+// searchSession returns the reader's session ID in the ranking experiment, issuing a
+// session cookie to readers without one. Readers get no cookie, and searches no session,
+// when no experiment runs.
+func (a *API) searchSession(w http.ResponseWriter, r *http.Request) string {
+	if a.svc.RankingExperiment() == "" {
+		return ""
+	}
+
+	if c, err := r.Cookie(sessionCookie); err == nil && c.Value != "" && len(c.Value) <= maxSessionID {
+		return c.Value
+	}
+
+	id := uuid.New().String()
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    id,
+		Path:     "/",
+		MaxAge:   int(sessionMaxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(requestOrigin(r), "https:"),
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return id
+}
+
+// searchClick handles POST /api/v1/search/click - counts a click on a search result for
+// the ranking variant of the reader's session. Search pages send it with
+// navigator.sendBeacon while a ranking experiment runs, as a form with the 1-based rank
+// of the clicked result.
+func (a *API) searchClick(w http.ResponseWriter, r *http.Request) {
+	rank, err := strconv.Atoi(r.FormValue("rank"))
+	if err != nil || rank < 1 {
+		http.Error(w, "rank must be a positive number", http.StatusBadRequest)
+		return
+	}
+
+	if c, err := r.Cookie(sessionCookie); err == nil && len(c.Value) <= maxSessionID {
+		a.svc.RecordSearchClick(c.Value, rank)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
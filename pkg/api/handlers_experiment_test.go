@@ -0,0 +1,113 @@
+//go:build !compile
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchPage_RankingExperiment(t *testing.T) {
+	svc := NewMockService(t)
+	views := NewMockViewRenderer(t)
+
+	results := &core.SearchResults{Variant: "weighted"}
+
+	svc.EXPECT().RankingExperiment().Return("fusion")
+	svc.EXPECT().SearchDocs(mock.Anything, "deploy", mock.MatchedBy(func(opts core.SearchOpts) bool {
+		return opts.Session != ""
+	})).Return(results, nil)
+	svc.EXPECT().ListRepos(mock.Anything).Return(nil, nil)
+	views.EXPECT().RenderSearch(mock.Anything, "deploy", mock.Anything, results, []core.RepoInfo(nil), false).Return(nil)
+
+	api := &API{svc: svc, views: views}
+
+	req := httptest.NewRequest(http.MethodGet, "/search?q=deploy", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	api.searchPage(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, sessionCookie, cookies[0].Name)
+	assert.True(t, cookies[0].HttpOnly)
+}
+
+func TestSearchSession(t *testing.T) {
+	svc := NewMockService(t)
+	svc.EXPECT().RankingExperiment().Return("fusion")
+
+	api := &API{svc: svc}
+
+	req := httptest.NewRequest(http.MethodGet, "/search?q=deploy", http.NoBody)
+	req.AddCookie(&http.Cookie{Name: sessionCookie, Value: "session-1"})
+
+	rec := httptest.NewRecorder()
+
+	assert.Equal(t, "session-1", api.searchSession(rec, req), "readers keep their session")
+	assert.Empty(t, rec.Result().Cookies())
+}
+
+func TestSearchSession_NoExperiment(t *testing.T) {
+	svc := NewMockService(t)
+	svc.EXPECT().RankingExperiment().Return("")
+
+	api := &API{svc: svc}
+
+	rec := httptest.NewRecorder()
+
+	assert.Empty(t, api.searchSession(rec, httptest.NewRequest(http.MethodGet, "/search?q=deploy", http.NoBody)))
+	assert.Empty(t, rec.Result().Cookies(), "readers get no cookie without an experiment")
+}
+
+func TestSearchClick(t *testing.T) {
+	svc := NewMockService(t)
+	svc.EXPECT().RecordSearchClick("session-1", 3).Return()
+
+	api := &API{svc: svc}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/search/click", strings.NewReader("rank=3"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(&http.Cookie{Name: sessionCookie, Value: "session-1"})
+
+	rec := httptest.NewRecorder()
+
+	api.searchClick(rec, req)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
+
+func TestSearchClick_InvalidRank(t *testing.T) {
+	api := &API{svc: NewMockService(t)}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/search/click", strings.NewReader("rank=0"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	rec := httptest.NewRecorder()
+
+	api.searchClick(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestSearchClick_NoSession(t *testing.T) {
+	api := &API{svc: NewMockService(t)}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/search/click", strings.NewReader("rank=1"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	rec := httptest.NewRecorder()
+
+	api.searchClick(rec, req)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code, "clicks outside the experiment are ignored")
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/ksysoev/omnidex/pkg/api/middleware"
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+// maxHighlightsBody bounds the body of a request to change a repository's highlights.
+const maxHighlightsBody = 16 << 10
+
+// getHighlights handles GET /api/v1/highlights?repo=... - returns the pinned documents and
+// announcement of a repository.
+func (a *API) getHighlights(w http.ResponseWriter, r *http.Request) {
+	repo := r.URL.Query().Get("repo")
+
+	h, err := a.svc.GetRepoHighlights(r.Context(), repo)
+	if err != nil {
+		highlightsError(w, r, err, repo)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, h)
+}
+
+// setHighlights handles PUT /api/v1/highlights - changes the pinned documents and
+// announcement of a repository. Repository tokens may only change the highlights of the
+// repository they were issued to and its projects.
+func (a *API) setHighlights(w http.ResponseWriter, r *http.Request) {
+	var req core.RepoHighlightsRequest
+
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxHighlightsBody)).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Repo == "" {
+		http.Error(w, "repo field is required", http.StatusBadRequest)
+		return
+	}
+
+	if tokenRepo, ok := middleware.TokenRepo(r.Context()); ok && !tokenAllowsRepo(tokenRepo, req.Repo) {
+		http.Error(w, fmt.Sprintf("token issued to %s cannot change %s", tokenRepo, req.Repo), http.StatusForbidden)
+		return
+	}
+
+	h, err := a.svc.SetRepoHighlights(r.Context(), req)
+	if err != nil {
+		highlightsError(w, r, err, req.Repo)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, h)
+}
+
+// highlightsError writes the response for an error reading or changing the highlights of
+// a repository.
+func highlightsError(w http.ResponseWriter, r *http.Request, err error, repo string) {
+	switch {
+	case errors.Is(err, core.ErrInvalidPath):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, core.ErrLimitExceeded):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	default:
+		slog.ErrorContext(r.Context(), "Failed to handle repository highlights", "error", err, "repo", repo)
+		http.Error(w, "failed to handle repository highlights", http.StatusInternalServerError)
+	}
+}
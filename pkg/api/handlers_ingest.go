@@ -3,9 +3,13 @@ package api
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"strings"
 
+	"github.com/ksysoev/omnidex/pkg/api/middleware"
 	"github.com/ksysoev/omnidex/pkg/core"
 )
 
@@ -21,21 +25,40 @@ func (a *API) ingestDocs(w http.ResponseWriter, r *http.Request) {
 
 	r.Body = http.MaxBytesReader(w, r.Body, maxBytes*mib)
 
-	var req core.IngestRequest
-
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	// The raw body is kept to verify its signature.
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
 		var maxBytesErr *http.MaxBytesError
 		if errors.As(err, &maxBytesErr) {
 			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
 			return
 		}
 
+		slog.ErrorContext(r.Context(), "Failed to read ingest request", "error", err)
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+
+		return
+	}
+
+	var req core.IngestRequest
+
+	if err := json.Unmarshal(body, &req); err != nil {
 		slog.ErrorContext(r.Context(), "Failed to decode ingest request", "error", err)
 		http.Error(w, "invalid request body", http.StatusBadRequest)
 
 		return
 	}
 
+	// The signing keys are bound to repositories, so the payload is decoded first to find
+	// the keys of its repository.
+	verified, err := a.verifySignature(r.Header.Get(signatureHeader), r.Header.Get(signatureTimestampHeader), req.Repo, body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	req.Provenance = withVerification(req.Provenance, req.Repo, verified)
+
 	if req.Repo == "" {
 		http.Error(w, "repo field is required", http.StatusBadRequest)
 		return
@@ -46,8 +69,40 @@ func (a *API) ingestDocs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if tokenRepo, ok := middleware.TokenRepo(r.Context()); ok && !tokenAllowsRepo(tokenRepo, req.Repo) {
+		http.Error(w, fmt.Sprintf("token issued to %s cannot publish %s", tokenRepo, req.Repo), http.StatusForbidden)
+		return
+	}
+
 	resp, err := a.svc.IngestDocuments(r.Context(), &req)
 	if err != nil {
+		var lintErr *core.LintError
+		if errors.As(err, &lintErr) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+
+			if err := json.NewEncoder(w).Encode(map[string]any{"error": lintErr.Error(), "lint": lintErr.Issues}); err != nil {
+				slog.ErrorContext(r.Context(), "Failed to encode response", "error", err)
+			}
+
+			return
+		}
+
+		if errors.Is(err, core.ErrInvalidPath) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if errors.Is(err, core.ErrLimitExceeded) {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		if errors.Is(err, core.ErrConflict) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+
 		slog.ErrorContext(r.Context(), "Failed to ingest documents", "error", err)
 		http.Error(w, "failed to process documents", http.StatusInternalServerError)
 
@@ -62,6 +117,18 @@ func (a *API) ingestDocs(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// tokenAllowsRepo reports whether a repository token issued to tokenRepo may publish repo:
+// the repository itself or one of its projects. GitHub repository names are case-insensitive.
+func tokenAllowsRepo(tokenRepo, repo string) bool {
+	if strings.EqualFold(repo, tokenRepo) {
+		return true
+	}
+
+	prefix := repo[:min(len(repo), len(tokenRepo)+1)]
+
+	return strings.EqualFold(prefix, tokenRepo+"/") && strings.Count(repo, "/") == 2
+}
+
 // listRepos handles GET /api/v1/repos - list all indexed repositories.
 func (a *API) listRepos(w http.ResponseWriter, r *http.Request) {
 	repos, err := a.svc.ListRepos(r.Context())
@@ -79,3 +146,105 @@ func (a *API) listRepos(w http.ResponseWriter, r *http.Request) {
 		slog.ErrorContext(r.Context(), "Failed to encode response", "error", err)
 	}
 }
+
+// contentTypes handles GET /api/v1/content-types - lists the content types the server
+// processes, so publishers know which files to upload and as which type.
+func (a *API) contentTypes(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, r, http.StatusOK, map[string]any{"content_types": a.svc.ContentTypes()})
+}
+
+// renameRepo handles POST /api/v1/repos/rename - moves a repository to a new identifier
+// and redirects the old URLs to it.
+func (a *API) renameRepo(w http.ResponseWriter, r *http.Request) {
+	var req core.RenameRepoRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to decode rename request", "error", err)
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+
+		return
+	}
+
+	if req.From == "" || req.To == "" {
+		http.Error(w, "from and to fields are required", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := a.svc.RenameRepo(r.Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, core.ErrInvalidPath):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, core.ErrNotFound):
+			http.Error(w, "repository not found", http.StatusNotFound)
+		case errors.Is(err, core.ErrConflict):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			slog.ErrorContext(r.Context(), "Failed to rename repo", "error", err, "from", req.From, "to", req.To)
+			http.Error(w, "failed to rename repository", http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to encode response", "error", err)
+	}
+}
+
+// lintReports handles GET /api/v1/lint - returns the latest ingest lint report of each
+// repository. The optional repo query parameter limits the result to one repository.
+func (a *API) lintReports(w http.ResponseWriter, r *http.Request) {
+	reports := a.svc.LintReports(r.Context())
+
+	if repo := r.URL.Query().Get("repo"); repo != "" {
+		filtered := make([]core.LintReport, 0, 1)
+
+		for _, report := range reports {
+			if report.Repo == repo {
+				filtered = append(filtered, report)
+			}
+		}
+
+		reports = filtered
+	}
+
+	if reports == nil {
+		reports = []core.LintReport{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(map[string]any{"reports": reports}); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to encode response", "error", err)
+	}
+}
+
+// linkReports handles GET /api/v1/links - returns the latest external link check report of
+// each repository, listing the documents with dead external links. The optional repo query
+// parameter limits the result to one repository.
+func (a *API) linkReports(w http.ResponseWriter, r *http.Request) {
+	reports := a.svc.LinkReports(r.Context())
+
+	if repo := r.URL.Query().Get("repo"); repo != "" {
+		filtered := make([]core.LinkReport, 0, 1)
+
+		for _, report := range reports {
+			if report.Repo == repo {
+				filtered = append(filtered, report)
+			}
+		}
+
+		reports = filtered
+	}
+
+	if reports == nil {
+		reports = []core.LinkReport{}
+	}
+
+	writeJSON(w, r, http.StatusOK, map[string]any{"reports": reports})
+}
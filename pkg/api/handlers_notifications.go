@@ -0,0 +1,172 @@
+package api
+
+import (
+	"log/slog"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/ksysoev/omnidex/pkg/api/middleware"
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+// notificationsResponse is the inbox of a signed-in reader.
+type notificationsResponse struct {
+	Notifications []core.Notification `json:"notifications"`
+	Unread        int                 `json:"unread"`
+}
+
+// readerName returns the reader signed in through the authenticating proxy, or an empty
+// string when readers do not sign in, the request is for a public site, which the proxy
+// does not front, or the reader has not signed in.
+func (a *API) readerName(r *http.Request) string {
+	header := a.config.Login.UserHeader
+	if site, ok := middleware.HostSite(r.Context()); header == "" || (ok && site.Public) {
+		return ""
+	}
+
+	return strings.TrimSpace(r.Header.Get(header))
+}
+
+// requireReader returns the signed-in reader, see readerName. It responds with 403 when
+// readers do not sign in or the site is public, and with 401 when the reader has not signed
+// in, and returns false.
+func (a *API) requireReader(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if reader := a.readerName(r); reader != "" {
+		return reader, true
+	}
+
+	if site, ok := middleware.HostSite(r.Context()); a.config.Login.UserHeader == "" || (ok && site.Public) {
+		http.Error(w, "notifications require readers to sign in", http.StatusForbidden)
+	} else {
+		http.Error(w, "sign in required", http.StatusUnauthorized)
+	}
+
+	return "", false
+}
+
+// inbox returns the notifications of a reader, leaving out documents of repositories the
+// request's host does not serve, and how many of them are unread.
+func (a *API) inbox(r *http.Request, reader string) ([]core.Notification, int, error) {
+	notifications, err := a.svc.Notifications(r.Context(), reader)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if site, ok := middleware.HostSite(r.Context()); ok {
+		notifications = slices.DeleteFunc(notifications, func(n core.Notification) bool {
+			return !site.Serves(n.Repo)
+		})
+	}
+
+	unread := 0
+
+	for _, n := range notifications {
+		if n.Unread {
+			unread++
+		}
+	}
+
+	return notifications, unread, nil
+}
+
+// notificationsPage handles GET /notifications - renders the inbox of the signed-in reader,
+// the documents newly published matching the searches they saved.
+func (a *API) notificationsPage(w http.ResponseWriter, r *http.Request) {
+	reader, ok := a.requireReader(w, r)
+	if !ok {
+		return
+	}
+
+	a.renderInbox(w, r, reader)
+}
+
+// renderInbox renders the inbox page of a reader.
+func (a *API) renderInbox(w http.ResponseWriter, r *http.Request, reader string) {
+	notifications, _, err := a.inbox(r, reader)
+	if err != nil {
+		savedSearchError(w, r, err, "Failed to get notifications")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if err := a.viewsFor(r).RenderNotifications(w, notifications, isHTMXRequest(r)); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to render notifications page", "error", err)
+	}
+}
+
+// notificationsLink handles GET /notifications/unread - renders the navigation link to the
+// inbox of the signed-in reader with their unread count. It responds with 204, which leaves
+// the navigation unchanged, when the reader has not signed in.
+func (a *API) notificationsLink(w http.ResponseWriter, r *http.Request) {
+	reader := a.readerName(r)
+	if reader == "" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	_, unread, err := a.inbox(r, reader)
+	if err != nil {
+		savedSearchError(w, r, err, "Failed to get notifications")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if err := a.viewsFor(r).RenderNotificationsLink(w, unread); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to render notifications link", "error", err)
+	}
+}
+
+// listNotifications handles GET /api/v1/notifications - returns the inbox of the signed-in
+// reader, newest first, with the number of unread notifications.
+func (a *API) listNotifications(w http.ResponseWriter, r *http.Request) {
+	reader, ok := a.requireReader(w, r)
+	if !ok {
+		return
+	}
+
+	notifications, unread, err := a.inbox(r, reader)
+	if err != nil {
+		savedSearchError(w, r, err, "Failed to get notifications")
+		return
+	}
+
+	if notifications == nil {
+		notifications = []core.Notification{}
+	}
+
+	writeJSON(w, r, http.StatusOK, notificationsResponse{Notifications: notifications, Unread: unread})
+}
+
+// markNotificationsRead handles POST /api/v1/notifications/read - marks every notification
+// of the signed-in reader read. Requests must be sent by htmx or have a JSON body, so that
+// other sites cannot send them from a form. htmx requests are answered with the updated
+// inbox page.
+func (a *API) markNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	reader, ok := a.requireReader(w, r)
+	if !ok {
+		return
+	}
+
+	if !isHTMXRequest(r) {
+		if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+			http.Error(w, "request body must be application/json", http.StatusUnsupportedMediaType)
+			return
+		}
+	}
+
+	if err := a.svc.MarkNotificationsRead(r.Context(), reader); err != nil {
+		savedSearchError(w, r, err, "Failed to mark notifications read")
+		return
+	}
+
+	if isHTMXRequest(r) {
+		a.renderInbox(w, r, reader)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,185 @@
+//go:build !compile
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// notificationsTestConfig has readers sign in through a proxy, except on a public portal,
+// and routes docs.team-x.example.com to the team-x repositories.
+var notificationsTestConfig = Config{
+	Login: LoginConfig{UserHeader: "X-Forwarded-User"},
+	Hosts: []HostConfig{
+		{Host: "developers.example.com", Public: true, Repos: []string{"owner"}},
+		{Host: "docs.team-x.example.com", Repos: []string{"team-x"}},
+	},
+}
+
+// serveNotifications sends a request to mux, as the signed-in reader Alex unless user is
+// empty.
+func serveNotifications(mux http.Handler, method, target, user string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader("{}"))
+	if user != "" {
+		req.Header.Set("X-Forwarded-User", user)
+	}
+
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func testNotifications() []core.Notification {
+	now := time.Now().UTC()
+
+	return []core.Notification{
+		{Time: now, SearchID: "a1", Search: "Deploys", Repo: "team-x/api", Path: "deploy.md", Unread: true},
+		{Time: now.Add(-time.Hour), SearchID: "a1", Search: "Deploys", Repo: "team-y/api", Path: "deploy.md", Unread: true},
+		{Time: now.Add(-2 * time.Hour), SearchID: "a1", Search: "Deploys", Repo: "team-x/api", Path: "rollback.md"},
+	}
+}
+
+func TestListNotifications(t *testing.T) {
+	_, mux, svc, _ := newTestMux(t, notificationsTestConfig)
+
+	svc.EXPECT().Notifications(mock.Anything, "Alex").Return(testNotifications(), nil).Twice()
+
+	rec := serveNotifications(mux, http.MethodGet, "/api/v1/notifications", "Alex", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp notificationsResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	assert.Len(t, resp.Notifications, 3)
+	assert.Equal(t, 2, resp.Unread)
+
+	// Hosts serving a site leave out the documents of repositories they do not serve.
+	rec = serveNotifications(mux, http.MethodGet, "http://docs.team-x.example.com/api/v1/notifications", "Alex", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	resp = notificationsResponse{}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	require.Len(t, resp.Notifications, 2)
+	assert.Equal(t, "team-x/api", resp.Notifications[1].Repo)
+	assert.Equal(t, 1, resp.Unread)
+}
+
+func TestListNotifications_Errors(t *testing.T) {
+	tests := []struct {
+		err      error
+		name     string
+		target   string
+		user     string
+		wantCode int
+		noCall   bool
+	}{
+		{name: "public site", target: "http://developers.example.com/api/v1/notifications", user: "Alex", wantCode: http.StatusForbidden, noCall: true},
+		{name: "not enabled", target: "/api/v1/notifications", user: "Alex", err: fmt.Errorf("%w: saved searches are not enabled", core.ErrNotSupported), wantCode: http.StatusNotImplemented},
+		{name: "internal error", target: "/api/v1/notifications", user: "Alex", err: errors.New("disk full"), wantCode: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, mux, svc, _ := newTestMux(t, notificationsTestConfig)
+
+			if !tt.noCall {
+				svc.EXPECT().Notifications(mock.Anything, tt.user).Return(nil, tt.err)
+			}
+
+			rec := serveNotifications(mux, http.MethodGet, tt.target, tt.user, nil)
+			assert.Equal(t, tt.wantCode, rec.Code)
+		})
+	}
+}
+
+func TestListNotifications_NoSignIn(t *testing.T) {
+	_, mux, _, _ := newTestMux(t, Config{})
+
+	rec := serveNotifications(mux, http.MethodGet, "/api/v1/notifications", "Alex", nil)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestMarkNotificationsRead(t *testing.T) {
+	tests := []struct {
+		header   http.Header
+		name     string
+		wantCode int
+		noCall   bool
+	}{
+		{name: "json", header: http.Header{"Content-Type": {"application/json"}}, wantCode: http.StatusNoContent},
+		{name: "htmx", header: http.Header{"Hx-Request": {"true"}}, wantCode: http.StatusOK},
+		{name: "form", header: http.Header{"Content-Type": {"application/x-www-form-urlencoded"}}, wantCode: http.StatusUnsupportedMediaType, noCall: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, mux, svc, views := newTestMux(t, notificationsTestConfig)
+
+			if !tt.noCall {
+				svc.EXPECT().MarkNotificationsRead(mock.Anything, "Alex").Return(nil)
+			}
+
+			if tt.wantCode == http.StatusOK {
+				svc.EXPECT().Notifications(mock.Anything, "Alex").Return(testNotifications(), nil)
+				views.EXPECT().RenderNotifications(mock.Anything, mock.Anything, true).Return(nil)
+			}
+
+			rec := serveNotifications(mux, http.MethodPost, "/api/v1/notifications/read", "Alex", tt.header)
+			assert.Equal(t, tt.wantCode, rec.Code)
+		})
+	}
+}
+
+func TestNotificationsPage(t *testing.T) {
+	_, mux, svc, views := newTestMux(t, notificationsTestConfig)
+
+	svc.EXPECT().Notifications(mock.Anything, "Alex").Return(testNotifications(), nil)
+	views.EXPECT().RenderNotifications(mock.Anything, mock.MatchedBy(func(n []core.Notification) bool {
+		return len(n) == 3
+	}), false).Return(nil)
+
+	rec := serveNotifications(mux, http.MethodGet, "/notifications", "Alex", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+}
+
+func TestNotificationsLink(t *testing.T) {
+	_, mux, svc, views := newTestMux(t, notificationsTestConfig)
+
+	svc.EXPECT().Notifications(mock.Anything, "Alex").Return(testNotifications(), nil)
+	views.EXPECT().RenderNotificationsLink(mock.Anything, 2).Return(nil)
+
+	rec := serveNotifications(mux, http.MethodGet, "/notifications/unread", "Alex", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// Public sites, whose readers do not sign in, have no inbox.
+	rec = serveNotifications(mux, http.MethodGet, "http://developers.example.com/notifications/unread", "", nil)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
+
+func TestSaveSearch_Owner(t *testing.T) {
+	_, mux, svc, _ := newTestMux(t, notificationsTestConfig)
+
+	svc.EXPECT().SaveSearch(mock.Anything, mock.MatchedBy(func(req core.SavedSearchRequest) bool {
+		return req.Owner == "Alex"
+	})).Return(&core.SavedSearch{ID: "a1", Query: "deploy", Owner: "Alex"}, nil)
+
+	rec := serveNotifications(mux, http.MethodPost, "/api/v1/searches", "Alex", nil)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+}
@@ -4,18 +4,100 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
 
+	"github.com/ksysoev/omnidex/pkg/api/middleware"
 	"github.com/ksysoev/omnidex/pkg/core"
 )
 
+// searchPageSize is the number of results on a search page.
+const searchPageSize = 20
+
+// maxSearchPage is the last search page served. Elasticsearch and OpenSearch reject
+// results beyond the 10,000th by default.
+const maxSearchPage = 10000 / searchPageSize
+
 // isHTMXRequest checks if the request was made by HTMX.
 func isHTMXRequest(r *http.Request) bool {
 	return r.Header.Get("HX-Request") == "true"
 }
 
-// homePage handles GET / - renders the home page with repository listing.
+// wantsPlainText reports whether a document page request asks for the plain-text rendering
+// instead of HTML: with ?format=text, or with an Accept header preferring text/plain over
+// text/html. Browsers do not list text/plain, and HTMX requests always get HTML.
+func wantsPlainText(r *http.Request) bool {
+	if isHTMXRequest(r) {
+		return false
+	}
+
+	if r.URL.Query().Get("format") == "text" {
+		return true
+	}
+
+	var plainQ, htmlQ float64
+
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+
+		q := 1.0
+
+		for _, param := range strings.Split(params, ";") {
+			if k, v, ok := strings.Cut(strings.TrimSpace(param), "="); ok && strings.TrimSpace(k) == "q" {
+				if parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+
+		switch strings.ToLower(strings.TrimSpace(mediaType)) {
+		case "text/plain":
+			plainQ = max(plainQ, q)
+		case "text/html":
+			htmlQ = max(htmlQ, q)
+		}
+	}
+
+	return plainQ > htmlQ
+}
+
+// redirectMoved redirects a request for a renamed repository or a moved document to
+// its new URL. routePrefix is the part of the URL path preceding the repository, e.g.
+// "/docs/". docPath is the document path within the repository; when empty only the
+// repository is resolved and the rest of the URL path is kept as is. HTMX requests are
+// redirected with the HX-Redirect header so that the browser address bar shows the new
+// URL. It reports whether a redirect was written.
+func (a *API) redirectMoved(w http.ResponseWriter, r *http.Request, routePrefix, repo, docPath string) bool {
+	newRepo, newPath, ok := a.svc.ResolveRedirect(r.Context(), repo, docPath)
+	if !ok {
+		return false
+	}
+
+	target := routePrefix + newRepo + strings.TrimPrefix(r.URL.Path, routePrefix+repo)
+	if docPath != "" {
+		target = routePrefix + newRepo + "/" + newPath
+	}
+
+	u := url.URL{Path: target, RawQuery: r.URL.RawQuery}
+
+	if isHTMXRequest(r) {
+		w.Header().Set("HX-Redirect", u.String())
+		w.WriteHeader(http.StatusOK)
+
+		return true
+	}
+
+	http.Redirect(w, r, u.String(), http.StatusMovedPermanently)
+
+	return true
+}
+
+// homePage handles GET / - renders the home page with repository listing. Hosts serving a
+// site list only the site's repositories.
 func (a *API) homePage(w http.ResponseWriter, r *http.Request) {
-	repos, err := a.svc.ListRepos(r.Context())
+	repos, err := a.siteRepos(r)
 	if err != nil {
 		slog.ErrorContext(r.Context(), "Failed to list repos", "error", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
@@ -25,12 +107,30 @@ func (a *API) homePage(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 
-	if err := a.views.RenderHome(w, repos, isHTMXRequest(r)); err != nil {
+	if err := a.viewsFor(r).RenderHome(w, repos, isHTMXRequest(r)); err != nil {
 		slog.ErrorContext(r.Context(), "Failed to render home page", "error", err)
 	}
 }
 
-// repoIndexPage handles GET /docs/{owner}/{repo}/ - renders the document list for a repository.
+// siteRepos lists the repositories served to the request: every repository, or only the
+// site's repositories on hosts serving a site.
+func (a *API) siteRepos(r *http.Request) ([]core.RepoInfo, error) {
+	repos, err := a.svc.ListRepos(r.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	if site, ok := middleware.HostSite(r.Context()); ok {
+		repos = slices.DeleteFunc(repos, func(repo core.RepoInfo) bool {
+			return !site.Serves(repo.Name)
+		})
+	}
+
+	return repos, nil
+}
+
+// repoIndexPage handles GET /docs/{owner}/{repo}/ - renders the repository's landing document
+// when it has one, or the document list otherwise. The tab=files parameter always shows the list.
 func (a *API) repoIndexPage(w http.ResponseWriter, r *http.Request) {
 	owner := r.PathValue("owner")
 	repo := r.PathValue("repo")
@@ -50,14 +150,63 @@ func (a *API) repoIndexPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if len(docs) == 0 && a.redirectMoved(w, r, "/docs/", fullRepo, "") {
+		return
+	}
+
+	a.renderRepoIndex(w, r, fullRepo, docs)
+}
+
+// projectIndexPage renders the index of a monorepo sub-project. It reports false, without
+// writing a response, when the sub-project has no documents.
+func (a *API) projectIndexPage(w http.ResponseWriter, r *http.Request, projectRepo string) bool {
+	docs, err := a.svc.ListDocuments(r.Context(), projectRepo)
+	if err != nil {
+		slog.WarnContext(r.Context(), "Failed to list sub-project documents", "error", err, "repo", projectRepo)
+		return false
+	}
+
+	if len(docs) == 0 {
+		return false
+	}
+
+	a.renderRepoIndex(w, r, projectRepo, docs)
+
+	return true
+}
+
+// renderRepoIndex renders the index page of a repository listing docs.
+func (a *API) renderRepoIndex(w http.ResponseWriter, r *http.Request, fullRepo string, docs []core.DocumentMeta) {
+	filesTab := r.URL.Query().Get("tab") == "files"
+
+	var landing *core.RepoLanding
+
+	if meta, ok := core.LandingDocument(docs); ok {
+		if filesTab {
+			landing = &core.RepoLanding{Doc: core.Document{Repo: fullRepo, Path: meta.Path, Title: meta.Title}}
+		} else if doc, html, headings, err := a.svc.GetDocument(r.Context(), fullRepo, meta.Path); err != nil {
+			// Fall back to the document list rather than failing the page.
+			slog.WarnContext(r.Context(), "Failed to get landing document", "error", err, "repo", fullRepo, "path", meta.Path)
+		} else {
+			landing = &core.RepoLanding{Doc: doc, HTML: html, Headings: headings}
+		}
+	}
+
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 
-	if err := a.views.RenderRepoIndex(w, fullRepo, docs, isHTMXRequest(r)); err != nil {
+	highlights := a.svc.PageHighlights(r.Context(), fullRepo, docs)
+
+	if err := a.viewsFor(r).RenderRepoIndex(w, fullRepo, docs, landing, highlights, filesTab, isHTMXRequest(r)); err != nil {
 		slog.ErrorContext(r.Context(), "Failed to render repo index page", "error", err)
 	}
 }
 
 // docPage handles GET /docs/{owner}/{repo}/{path...} - renders a document or repo index.
+// Paths under a monorepo sub-project, /docs/{owner}/{repo}/{project}/{path...}, are served
+// from the sub-project when the repository has no document at that path, and
+// /docs/{owner}/{repo}/{project}/search searches the sub-project. Requests asking
+// for plain text, see wantsPlainText, get the document's plain-text rendering as served by
+// GET /text/{owner}/{repo}/{path...}.
 func (a *API) docPage(w http.ResponseWriter, r *http.Request) {
 	owner := r.PathValue("owner")
 	repo := r.PathValue("repo")
@@ -73,12 +222,40 @@ func (a *API) docPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	w.Header().Add("Vary", "Accept")
+
+	if wantsPlainText(r) {
+		a.textDocPage(w, r)
+		return
+	}
+
 	fullRepo := owner + "/" + repo
 
 	doc, html, headings, err := a.svc.GetDocument(r.Context(), fullRepo, path)
+
+	// A document missing from the repository may belong to a monorepo sub-project named
+	// by the first path segment.
+	if errors.Is(err, core.ErrNotFound) {
+		if projectRepo, rest, ok := core.SplitProject(fullRepo, path); ok {
+			if rest == "" {
+				if a.projectIndexPage(w, r, projectRepo) {
+					return
+				}
+			} else if pDoc, pHTML, pHeadings, pErr := a.svc.GetDocument(r.Context(), projectRepo, rest); !errors.Is(pErr, core.ErrNotFound) {
+				fullRepo, doc, html, headings, err = projectRepo, pDoc, pHTML, pHeadings, pErr
+			} else if rest == "search" {
+				a.renderRepoSearch(w, r, projectRepo)
+				return
+			}
+		}
+	}
+
 	if err != nil {
 		if errors.Is(err, core.ErrNotFound) {
-			http.NotFound(w, r)
+			if !a.redirectMoved(w, r, "/docs/", fullRepo, path) {
+				http.NotFound(w, r)
+			}
+
 			return
 		}
 
@@ -96,32 +273,149 @@ func (a *API) docPage(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 
-	if err := a.views.RenderDoc(w, doc, html, headings, docs, isHTMXRequest(r)); err != nil {
+	highlights := a.svc.PageHighlights(r.Context(), fullRepo, docs)
+
+	if err := a.viewsFor(r).RenderDoc(w, doc, html, headings, docs, highlights, isHTMXRequest(r)); err != nil {
 		slog.ErrorContext(r.Context(), "Failed to render doc page", "error", err)
 	}
 }
 
 // searchPage handles GET /search?q=... - search page with results.
+// The optional code=1 parameter restricts matching to code block contents, and
+// mode=keyword, mode=semantic or mode=hybrid selects how documents are ranked when
+// semantic search is configured. The optional repo=owner/repo parameter restricts
+// results to a single repository, and repeated type parameters, e.g. type=openapi, to
+// documents of those content types.
 func (a *API) searchPage(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query().Get("q")
+	opts := a.searchOpts(w, r)
+	opts.Repo = r.URL.Query().Get("repo")
 
-	var results *core.SearchResults
+	if site, ok := middleware.HostSite(r.Context()); ok {
+		opts.Repos = site.Repos
+	}
 
-	if query != "" {
-		sr, err := a.svc.SearchDocs(r.Context(), query, core.SearchOpts{Limit: 20})
-		if err != nil {
-			slog.ErrorContext(r.Context(), "Search failed", "error", err, "query", query)
-			http.Error(w, "Search failed", http.StatusInternalServerError)
+	results, ok := a.runSearch(w, r, query, opts)
+	if !ok {
+		return
+	}
 
-			return
+	// The repository filter is left out rather than failing the search when the
+	// repositories cannot be listed.
+	repos, err := a.siteRepos(r)
+	if err != nil {
+		slog.WarnContext(r.Context(), "Failed to list repos for search filter", "error", err)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if err := a.viewsFor(r).RenderSearch(w, query, opts, results, repos, isHTMXRequest(r)); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to render search page", "error", err)
+	}
+}
+
+// repoSearchPage handles GET /docs/{owner}/{repo}/search?q=... - searches the documents of
+// one repository only, with the same parameters as the search page except repo.
+func (a *API) repoSearchPage(w http.ResponseWriter, r *http.Request) {
+	a.renderRepoSearch(w, r, r.PathValue("owner")+"/"+r.PathValue("repo"))
+}
+
+// renderRepoSearch renders the search page of repo, which may be a monorepo sub-project.
+func (a *API) renderRepoSearch(w http.ResponseWriter, r *http.Request, repo string) {
+	query := r.URL.Query().Get("q")
+	opts := a.searchOpts(w, r)
+	opts.Repo = repo
+
+	results, ok := a.runSearch(w, r, query, opts)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if err := a.viewsFor(r).RenderRepoSearch(w, repo, query, opts, results, isHTMXRequest(r)); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to render repo search page", "error", err)
+	}
+}
+
+// searchOpts returns the page, mode and filters requested with the query parameters of a
+// search page, and the reader's session in the ranking experiment.
+func (a *API) searchOpts(w http.ResponseWriter, r *http.Request) core.SearchOpts {
+	page := searchPageNumber(r)
+	opts := core.SearchOpts{
+		Limit:    searchPageSize,
+		Offset:   (page - 1) * searchPageSize,
+		CodeOnly: r.URL.Query().Get("code") == "1",
+		Mode:     core.SearchMode(r.URL.Query().Get("mode")),
+		Session:  a.searchSession(w, r),
+	}
+
+	for _, ct := range r.URL.Query()["type"] {
+		if ct != "" {
+			opts.ContentTypes = append(opts.ContentTypes, core.ContentType(ct))
 		}
+	}
+
+	return opts
+}
+
+// runSearch runs the search of a search page, returning nil results for an empty query.
+// It responds with an error and returns false if the query is too long or the search
+// fails.
+func (a *API) runSearch(w http.ResponseWriter, r *http.Request, query string, opts core.SearchOpts) (*core.SearchResults, bool) {
+	if query == "" {
+		return nil, true
+	}
+
+	if !checkQueryLen(w, query) {
+		return nil, false
+	}
+
+	results, err := a.svc.SearchDocs(r.Context(), query, opts)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Search failed", "error", err, "query", query)
+		http.Error(w, "Search failed", http.StatusInternalServerError)
+
+		return nil, false
+	}
+
+	if searchPageNumber(r) == maxSearchPage {
+		results.HasMore = false
+	}
 
-		results = sr
+	return results, true
+}
+
+// searchPageNumber returns the 1-based search page requested with the page query
+// parameter, capped at maxSearchPage. Missing or invalid values ask for the first page.
+func searchPageNumber(r *http.Request) int {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		return 1
+	}
+
+	return min(page, maxSearchPage)
+}
+
+// statsPage handles GET /stats - renders the instance statistics page. The statistics
+// cover the whole instance, so hosts serving a site do not show them.
+func (a *API) statsPage(w http.ResponseWriter, r *http.Request) {
+	if _, ok := middleware.HostSite(r.Context()); ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	stats, err := a.svc.Stats(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Failed to get stats", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
 	}
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 
-	if err := a.views.RenderSearch(w, query, results, isHTMXRequest(r)); err != nil {
-		slog.ErrorContext(r.Context(), "Failed to render search page", "error", err)
+	if err := a.viewsFor(r).RenderStats(w, stats, isHTMXRequest(r)); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to render stats page", "error", err)
 	}
 }
@@ -3,6 +3,7 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -13,6 +14,7 @@ import (
 	"github.com/ksysoev/omnidex/pkg/repo/docstore"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHomePage_Success(t *testing.T) {
@@ -88,7 +90,8 @@ func TestRepoIndexPage_Success(t *testing.T) {
 	}
 
 	svc.EXPECT().ListDocuments(mock.Anything, "owner/repo").Return(docs, nil)
-	views.EXPECT().RenderRepoIndex(mock.Anything, "owner/repo", docs, false).Return(nil)
+	svc.EXPECT().PageHighlights(mock.Anything, "owner/repo", docs).Return(nil)
+	views.EXPECT().RenderRepoIndex(mock.Anything, "owner/repo", docs, (*core.RepoLanding)(nil), (*core.PageHighlights)(nil), false, false).Return(nil)
 
 	api := &API{svc: svc, views: views}
 
@@ -104,6 +107,70 @@ func TestRepoIndexPage_Success(t *testing.T) {
 	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
 }
 
+func TestRepoIndexPage_Landing(t *testing.T) {
+	docs := []core.DocumentMeta{
+		{ID: "owner/repo/README.md", Repo: "owner/repo", Path: "README.md", Title: "Welcome", ContentType: core.ContentTypeMarkdown},
+		{ID: "owner/repo/docs/guide.md", Repo: "owner/repo", Path: "docs/guide.md", Title: "Guide", ContentType: core.ContentTypeMarkdown},
+	}
+	doc := core.Document{ID: "owner/repo/README.md", Repo: "owner/repo", Path: "README.md", Title: "Welcome"}
+	html := []byte("<h1>Welcome</h1>")
+	headings := []core.Heading{{Level: 1, Text: "Welcome", ID: "welcome"}}
+
+	tests := []struct {
+		getErr   error
+		want     *core.RepoLanding
+		name     string
+		query    string
+		filesTab bool
+		fetch    bool
+	}{
+		{
+			name:  "overview",
+			fetch: true,
+			want:  &core.RepoLanding{Doc: doc, HTML: html, Headings: headings},
+		},
+		{
+			name:     "files tab",
+			query:    "?tab=files",
+			filesTab: true,
+			want:     &core.RepoLanding{Doc: core.Document{Repo: "owner/repo", Path: "README.md", Title: "Welcome"}},
+		},
+		{
+			name:   "landing fetch fails",
+			fetch:  true,
+			getErr: errors.New("storage down"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewMockService(t)
+			views := NewMockViewRenderer(t)
+
+			svc.EXPECT().ListDocuments(mock.Anything, "owner/repo").Return(docs, nil)
+
+			if tt.fetch {
+				svc.EXPECT().GetDocument(mock.Anything, "owner/repo", "README.md").Return(doc, html, headings, tt.getErr)
+			}
+
+			svc.EXPECT().PageHighlights(mock.Anything, "owner/repo", docs).Return(nil)
+			views.EXPECT().RenderRepoIndex(mock.Anything, "owner/repo", docs, tt.want, (*core.PageHighlights)(nil), tt.filesTab, false).Return(nil)
+
+			api := &API{svc: svc, views: views}
+
+			req := httptest.NewRequest(http.MethodGet, "/docs/owner/repo/"+tt.query, http.NoBody)
+			req.SetPathValue("owner", "owner")
+			req.SetPathValue("repo", "repo")
+
+			rec := httptest.NewRecorder()
+
+			api.repoIndexPage(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+		})
+	}
+}
+
 func TestRepoIndexPage_HTMXPartial(t *testing.T) {
 	svc := NewMockService(t)
 	views := NewMockViewRenderer(t)
@@ -113,7 +180,8 @@ func TestRepoIndexPage_HTMXPartial(t *testing.T) {
 	}
 
 	svc.EXPECT().ListDocuments(mock.Anything, "owner/repo").Return(docs, nil)
-	views.EXPECT().RenderRepoIndex(mock.Anything, "owner/repo", docs, true).Return(nil)
+	svc.EXPECT().PageHighlights(mock.Anything, "owner/repo", docs).Return(nil)
+	views.EXPECT().RenderRepoIndex(mock.Anything, "owner/repo", docs, (*core.RepoLanding)(nil), (*core.PageHighlights)(nil), false, true).Return(nil)
 
 	api := &API{svc: svc, views: views}
 
@@ -189,7 +257,8 @@ func TestRepoIndexPage_RenderError(t *testing.T) {
 	}
 
 	svc.EXPECT().ListDocuments(mock.Anything, "owner/repo").Return(docs, nil)
-	views.EXPECT().RenderRepoIndex(mock.Anything, "owner/repo", docs, false).Return(fmt.Errorf("render error"))
+	svc.EXPECT().PageHighlights(mock.Anything, "owner/repo", docs).Return(nil)
+	views.EXPECT().RenderRepoIndex(mock.Anything, "owner/repo", docs, (*core.RepoLanding)(nil), (*core.PageHighlights)(nil), false, false).Return(fmt.Errorf("render error"))
 
 	api := &API{svc: svc, views: views}
 
@@ -212,7 +281,9 @@ func TestRepoIndexPage_EmptyRepo(t *testing.T) {
 	views := NewMockViewRenderer(t)
 
 	svc.EXPECT().ListDocuments(mock.Anything, "owner/repo").Return([]core.DocumentMeta{}, nil)
-	views.EXPECT().RenderRepoIndex(mock.Anything, "owner/repo", []core.DocumentMeta{}, false).Return(nil)
+	svc.EXPECT().ResolveRedirect(mock.Anything, "owner/repo", "").Return("", "", false)
+	svc.EXPECT().PageHighlights(mock.Anything, "owner/repo", []core.DocumentMeta{}).Return(nil)
+	views.EXPECT().RenderRepoIndex(mock.Anything, "owner/repo", []core.DocumentMeta{}, (*core.RepoLanding)(nil), (*core.PageHighlights)(nil), false, false).Return(nil)
 
 	api := &API{svc: svc, views: views}
 
@@ -228,6 +299,37 @@ func TestRepoIndexPage_EmptyRepo(t *testing.T) {
 	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
 }
 
+func TestWantsPlainText(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		accept string
+		htmx   bool
+		want   bool
+	}{
+		{name: "browser", target: "/docs/a/b/c.md", accept: "text/html,application/xhtml+xml,*/*;q=0.8"},
+		{name: "any", target: "/docs/a/b/c.md", accept: "*/*"},
+		{name: "plain text", target: "/docs/a/b/c.md", accept: "text/plain", want: true},
+		{name: "plain text preferred", target: "/docs/a/b/c.md", accept: "text/html;q=0.5, text/plain", want: true},
+		{name: "html preferred", target: "/docs/a/b/c.md", accept: "text/plain;q=0.5, text/html"},
+		{name: "format parameter", target: "/docs/a/b/c.md?format=text", accept: "text/html", want: true},
+		{name: "htmx", target: "/docs/a/b/c.md?format=text", accept: "text/plain", htmx: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, http.NoBody)
+			req.Header.Set("Accept", tt.accept)
+
+			if tt.htmx {
+				req.Header.Set("HX-Request", "true")
+			}
+
+			assert.Equal(t, tt.want, wantsPlainText(req))
+		})
+	}
+}
+
 func TestDocPage_EmptyPathDelegatesToRepoIndex(t *testing.T) {
 	svc := NewMockService(t)
 	views := NewMockViewRenderer(t)
@@ -237,7 +339,8 @@ func TestDocPage_EmptyPathDelegatesToRepoIndex(t *testing.T) {
 	}
 
 	svc.EXPECT().ListDocuments(mock.Anything, "owner/repo").Return(docs, nil)
-	views.EXPECT().RenderRepoIndex(mock.Anything, "owner/repo", docs, false).Return(nil)
+	svc.EXPECT().PageHighlights(mock.Anything, "owner/repo", docs).Return(nil)
+	views.EXPECT().RenderRepoIndex(mock.Anything, "owner/repo", docs, (*core.RepoLanding)(nil), (*core.PageHighlights)(nil), false, false).Return(nil)
 
 	api := &API{svc: svc, views: views}
 
@@ -274,7 +377,8 @@ func TestDocPage_Success(t *testing.T) {
 
 	svc.EXPECT().GetDocument(mock.Anything, "owner/repo", "docs/readme.md").Return(doc, htmlContent, []core.Heading(nil), nil)
 	svc.EXPECT().ListDocuments(mock.Anything, "owner/repo").Return(navDocs, nil)
-	views.EXPECT().RenderDoc(mock.Anything, doc, htmlContent, []core.Heading(nil), navDocs, false).Return(nil)
+	svc.EXPECT().PageHighlights(mock.Anything, doc.Repo, navDocs).Return(nil)
+	views.EXPECT().RenderDoc(mock.Anything, doc, htmlContent, []core.Heading(nil), navDocs, (*core.PageHighlights)(nil), false).Return(nil)
 
 	api := &API{svc: svc, views: views}
 
@@ -297,6 +401,7 @@ func TestDocPage_NotFound(t *testing.T) {
 
 	svc.EXPECT().GetDocument(mock.Anything, "owner/repo", "docs/missing.md").
 		Return(core.Document{}, nil, nil, fmt.Errorf("failed to get document: %w", docstore.ErrNotFound))
+	svc.EXPECT().ResolveRedirect(mock.Anything, "owner/repo", "docs/missing.md").Return("", "", false)
 
 	api := &API{svc: svc, views: views}
 
@@ -363,8 +468,12 @@ func TestSearchPage_WithQuery(t *testing.T) {
 		Duration: 10 * time.Millisecond,
 	}
 
+	repos := []core.RepoInfo{{Name: "owner/repo"}}
+
+	svc.EXPECT().RankingExperiment().Return("")
 	svc.EXPECT().SearchDocs(mock.Anything, "test query", core.SearchOpts{Limit: 20}).Return(results, nil)
-	views.EXPECT().RenderSearch(mock.Anything, "test query", results, false).Return(nil)
+	svc.EXPECT().ListRepos(mock.Anything).Return(repos, nil)
+	views.EXPECT().RenderSearch(mock.Anything, "test query", core.SearchOpts{Limit: 20}, results, repos, false).Return(nil)
 
 	api := &API{svc: svc, views: views}
 
@@ -377,11 +486,238 @@ func TestSearchPage_WithQuery(t *testing.T) {
 	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
 }
 
+func TestSearchPage_CodeOnly(t *testing.T) {
+	svc := NewMockService(t)
+	views := NewMockViewRenderer(t)
+
+	opts := core.SearchOpts{Limit: 20, CodeOnly: true}
+	results := &core.SearchResults{Total: 0}
+
+	svc.EXPECT().RankingExperiment().Return("")
+	svc.EXPECT().SearchDocs(mock.Anything, "http.Handler lang:go", opts).Return(results, nil)
+	svc.EXPECT().ListRepos(mock.Anything).Return(nil, nil)
+	views.EXPECT().RenderSearch(mock.Anything, "http.Handler lang:go", opts, results, []core.RepoInfo(nil), false).Return(nil)
+
+	api := &API{svc: svc, views: views}
+
+	req := httptest.NewRequest(http.MethodGet, "/search?q=http.Handler+lang%3Ago&code=1", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	api.searchPage(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestSearchPage_Mode(t *testing.T) {
+	svc := NewMockService(t)
+	views := NewMockViewRenderer(t)
+
+	opts := core.SearchOpts{Limit: 20, Mode: core.SearchModeSemantic}
+	results := &core.SearchResults{Total: 0}
+
+	svc.EXPECT().RankingExperiment().Return("")
+	svc.EXPECT().SearchDocs(mock.Anything, "ship a release", opts).Return(results, nil)
+	svc.EXPECT().ListRepos(mock.Anything).Return(nil, nil)
+	views.EXPECT().RenderSearch(mock.Anything, "ship a release", opts, results, []core.RepoInfo(nil), false).Return(nil)
+
+	api := &API{svc: svc, views: views}
+
+	req := httptest.NewRequest(http.MethodGet, "/search?q=ship+a+release&mode=semantic", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	api.searchPage(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestSearchPage_Repo(t *testing.T) {
+	svc := NewMockService(t)
+	views := NewMockViewRenderer(t)
+
+	opts := core.SearchOpts{Limit: 20, Repo: "owner/repo"}
+	results := &core.SearchResults{Total: 0}
+
+	svc.EXPECT().RankingExperiment().Return("")
+	svc.EXPECT().SearchDocs(mock.Anything, "deploy", opts).Return(results, nil)
+	svc.EXPECT().ListRepos(mock.Anything).Return(nil, errors.New("store unavailable"))
+	views.EXPECT().RenderSearch(mock.Anything, "deploy", opts, results, []core.RepoInfo(nil), false).Return(nil)
+
+	api := &API{svc: svc, views: views}
+
+	req := httptest.NewRequest(http.MethodGet, "/search?q=deploy&repo=owner%2Frepo", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	api.searchPage(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code, "the search is shown without the repository filter")
+}
+
+func TestSearchPage_ContentTypes(t *testing.T) {
+	svc := NewMockService(t)
+	views := NewMockViewRenderer(t)
+
+	opts := core.SearchOpts{Limit: 20, ContentTypes: []core.ContentType{core.ContentTypeOpenAPI, core.ContentTypeMarkdown}}
+	results := &core.SearchResults{Total: 0}
+
+	svc.EXPECT().RankingExperiment().Return("")
+	svc.EXPECT().SearchDocs(mock.Anything, "users", opts).Return(results, nil)
+	svc.EXPECT().ListRepos(mock.Anything).Return(nil, nil)
+	views.EXPECT().RenderSearch(mock.Anything, "users", opts, results, []core.RepoInfo(nil), false).Return(nil)
+
+	api := &API{svc: svc, views: views}
+
+	req := httptest.NewRequest(http.MethodGet, "/search?q=users&type=openapi&type=markdown&type=", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	api.searchPage(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestSearchPage_Page(t *testing.T) {
+	svc := NewMockService(t)
+	views := NewMockViewRenderer(t)
+
+	opts := core.SearchOpts{Limit: 20, Offset: 40}
+	results := &core.SearchResults{Total: 100, Page: 3, HasMore: true}
+
+	svc.EXPECT().RankingExperiment().Return("")
+	svc.EXPECT().SearchDocs(mock.Anything, "deploy", opts).Return(results, nil)
+	svc.EXPECT().ListRepos(mock.Anything).Return(nil, nil)
+	views.EXPECT().RenderSearch(mock.Anything, "deploy", opts, results, []core.RepoInfo(nil), false).Return(nil)
+
+	api := &API{svc: svc, views: views}
+
+	req := httptest.NewRequest(http.MethodGet, "/search?q=deploy&page=3", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	api.searchPage(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestRepoSearchPage(t *testing.T) {
+	svc := NewMockService(t)
+	views := NewMockViewRenderer(t)
+
+	opts := core.SearchOpts{Limit: 20, Repo: "owner/repo", CodeOnly: true}
+	results := &core.SearchResults{Total: 1}
+
+	svc.EXPECT().RankingExperiment().Return("")
+	svc.EXPECT().SearchDocs(mock.Anything, "deploy", opts).Return(results, nil)
+	views.EXPECT().RenderRepoSearch(mock.Anything, "owner/repo", "deploy", opts, results, false).Return(nil)
+
+	api := &API{svc: svc, views: views}
+
+	mux, err := api.newMux()
+	require.NoError(t, err)
+
+	// The repo parameter cannot widen the search beyond the repository.
+	req := httptest.NewRequest(http.MethodGet, "/docs/owner/repo/search?q=deploy&code=1&repo=owner%2Fother", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestRepoSearchPage_EmptyQuery(t *testing.T) {
+	svc := NewMockService(t)
+	views := NewMockViewRenderer(t)
+
+	opts := core.SearchOpts{Limit: 20, Repo: "owner/repo"}
+
+	svc.EXPECT().RankingExperiment().Return("")
+	views.EXPECT().RenderRepoSearch(mock.Anything, "owner/repo", "", opts, (*core.SearchResults)(nil), true).Return(nil)
+
+	api := &API{svc: svc, views: views}
+
+	mux, err := api.newMux()
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/docs/owner/repo/search", http.NoBody)
+	req.Header.Set("HX-Request", "true")
+
+	rec := httptest.NewRecorder()
+
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestRepoSearchPage_SubProject(t *testing.T) {
+	svc := NewMockService(t)
+	views := NewMockViewRenderer(t)
+
+	opts := core.SearchOpts{Limit: 20, Repo: "owner/mono/service-a"}
+	results := &core.SearchResults{Total: 0}
+
+	svc.EXPECT().RankingExperiment().Return("")
+	svc.EXPECT().GetDocument(mock.Anything, "owner/mono", "service-a/search").
+		Return(core.Document{}, nil, nil, fmt.Errorf("failed to get document: %w", core.ErrNotFound))
+	svc.EXPECT().GetDocument(mock.Anything, "owner/mono/service-a", "search").
+		Return(core.Document{}, nil, nil, fmt.Errorf("failed to get document: %w", core.ErrNotFound))
+	svc.EXPECT().SearchDocs(mock.Anything, "deploy", opts).Return(results, nil)
+	views.EXPECT().RenderRepoSearch(mock.Anything, "owner/mono/service-a", "deploy", opts, results, false).Return(nil)
+
+	api := &API{svc: svc, views: views}
+
+	mux, err := api.newMux()
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/docs/owner/mono/service-a/search?q=deploy", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestRepoSearchPage_SearchError(t *testing.T) {
+	svc := NewMockService(t)
+
+	svc.EXPECT().RankingExperiment().Return("")
+	svc.EXPECT().SearchDocs(mock.Anything, "deploy", mock.Anything).Return(nil, errors.New("index unavailable"))
+
+	api := &API{svc: svc, views: NewMockViewRenderer(t)}
+
+	mux, err := api.newMux()
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/docs/owner/repo/search?q=deploy", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestSearchPageNumber(t *testing.T) {
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{query: "", want: 1},
+		{query: "page=2", want: 2},
+		{query: "page=0", want: 1},
+		{query: "page=-3", want: 1},
+		{query: "page=two", want: 1},
+		{query: "page=100000", want: maxSearchPage},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/search?"+tt.query, http.NoBody)
+		assert.Equal(t, tt.want, searchPageNumber(req), tt.query)
+	}
+}
+
 func TestSearchPage_EmptyQuery(t *testing.T) {
 	svc := NewMockService(t)
 	views := NewMockViewRenderer(t)
 
-	views.EXPECT().RenderSearch(mock.Anything, "", (*core.SearchResults)(nil), false).Return(nil)
+	svc.EXPECT().RankingExperiment().Return("")
+	svc.EXPECT().ListRepos(mock.Anything).Return(nil, nil)
+	views.EXPECT().RenderSearch(mock.Anything, "", core.SearchOpts{Limit: 20}, (*core.SearchResults)(nil), []core.RepoInfo(nil), false).Return(nil)
 
 	api := &API{svc: svc, views: views}
 
@@ -398,6 +734,7 @@ func TestSearchPage_SearchError(t *testing.T) {
 	svc := NewMockService(t)
 	views := NewMockViewRenderer(t)
 
+	svc.EXPECT().RankingExperiment().Return("")
 	svc.EXPECT().SearchDocs(mock.Anything, "broken query", core.SearchOpts{Limit: 20}).
 		Return(nil, fmt.Errorf("search engine unavailable"))
 
@@ -412,6 +749,44 @@ func TestSearchPage_SearchError(t *testing.T) {
 	assert.Contains(t, rec.Body.String(), "Search failed")
 }
 
+func TestStatsPage(t *testing.T) {
+	svc := NewMockService(t)
+	views := NewMockViewRenderer(t)
+
+	stats := &core.Stats{Repos: 2, Documents: 7}
+
+	svc.EXPECT().Stats(mock.Anything).Return(stats, nil)
+	views.EXPECT().RenderStats(mock.Anything, stats, true).Return(nil)
+
+	api := &API{svc: svc, views: views}
+
+	req := httptest.NewRequest(http.MethodGet, "/stats", http.NoBody)
+	req.Header.Set("HX-Request", "true")
+
+	rec := httptest.NewRecorder()
+
+	api.statsPage(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+}
+
+func TestStatsPage_Error(t *testing.T) {
+	svc := NewMockService(t)
+	views := NewMockViewRenderer(t)
+
+	svc.EXPECT().Stats(mock.Anything).Return(nil, fmt.Errorf("store unavailable"))
+
+	api := &API{svc: svc, views: views}
+
+	req := httptest.NewRequest(http.MethodGet, "/stats", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	api.statsPage(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
 func TestDocPage_ServiceInternalError(t *testing.T) {
 	svc := NewMockService(t)
 	views := NewMockViewRenderer(t)
@@ -452,7 +827,8 @@ func TestDocPage_ListDocumentsError(t *testing.T) {
 	svc.EXPECT().GetDocument(mock.Anything, "owner/repo", "docs/readme.md").Return(doc, htmlContent, []core.Heading(nil), nil)
 	svc.EXPECT().ListDocuments(mock.Anything, "owner/repo").Return(nil, fmt.Errorf("nav list error"))
 	// When ListDocuments fails, docs will be nil but page still renders.
-	views.EXPECT().RenderDoc(mock.Anything, doc, htmlContent, []core.Heading(nil), []core.DocumentMeta(nil), false).Return(nil)
+	svc.EXPECT().PageHighlights(mock.Anything, doc.Repo, []core.DocumentMeta(nil)).Return(nil)
+	views.EXPECT().RenderDoc(mock.Anything, doc, htmlContent, []core.Heading(nil), []core.DocumentMeta(nil), (*core.PageHighlights)(nil), false).Return(nil)
 
 	api := &API{svc: svc, views: views}
 
@@ -489,7 +865,8 @@ func TestDocPage_HTMXPartial(t *testing.T) {
 
 	svc.EXPECT().GetDocument(mock.Anything, "owner/repo", "docs/readme.md").Return(doc, htmlContent, []core.Heading(nil), nil)
 	svc.EXPECT().ListDocuments(mock.Anything, "owner/repo").Return(navDocs, nil)
-	views.EXPECT().RenderDoc(mock.Anything, doc, htmlContent, []core.Heading(nil), navDocs, true).Return(nil)
+	svc.EXPECT().PageHighlights(mock.Anything, doc.Repo, navDocs).Return(nil)
+	views.EXPECT().RenderDoc(mock.Anything, doc, htmlContent, []core.Heading(nil), navDocs, (*core.PageHighlights)(nil), true).Return(nil)
 
 	api := &API{svc: svc, views: views}
 
@@ -506,3 +883,224 @@ func TestDocPage_HTMXPartial(t *testing.T) {
 	assert.Equal(t, http.StatusOK, rec.Code)
 	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
 }
+
+func TestDocPage_RenamedRepoRedirects(t *testing.T) {
+	tests := []struct {
+		name       string
+		wantHeader string
+		wantCode   int
+		htmx       bool
+	}{
+		{name: "full page", wantCode: http.StatusMovedPermanently, wantHeader: "Location"},
+		{name: "htmx", htmx: true, wantCode: http.StatusOK, wantHeader: "HX-Redirect"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewMockService(t)
+
+			svc.EXPECT().GetDocument(mock.Anything, "old-org/repo", "docs/guide.md").
+				Return(core.Document{}, nil, nil, fmt.Errorf("failed to get document: %w", core.ErrNotFound))
+			svc.EXPECT().ResolveRedirect(mock.Anything, "old-org/repo", "docs/guide.md").Return("new-org/repo", "docs/guide.md", true)
+
+			api := &API{svc: svc, views: NewMockViewRenderer(t)}
+
+			mux, err := api.newMux()
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/docs/old-org/repo/docs/guide.md?x=1", http.NoBody)
+			if tt.htmx {
+				req.Header.Set("HX-Request", "true")
+			}
+
+			rec := httptest.NewRecorder()
+
+			mux.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Equal(t, "/docs/new-org/repo/docs/guide.md?x=1", rec.Header().Get(tt.wantHeader))
+		})
+	}
+}
+
+func TestRepoIndexPage_RenamedRepoRedirects(t *testing.T) {
+	svc := NewMockService(t)
+
+	svc.EXPECT().ListDocuments(mock.Anything, "old-org/repo").Return(nil, nil)
+	svc.EXPECT().ResolveRedirect(mock.Anything, "old-org/repo", "").Return("new-org/repo", "", true)
+
+	api := &API{svc: svc, views: NewMockViewRenderer(t)}
+
+	mux, err := api.newMux()
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/docs/old-org/repo/", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+	assert.Equal(t, "/docs/new-org/repo/", rec.Header().Get("Location"))
+}
+
+func TestDocPage_MovedDocumentRedirects(t *testing.T) {
+	svc := NewMockService(t)
+
+	svc.EXPECT().GetDocument(mock.Anything, "owner/repo", "old/guide.md").
+		Return(core.Document{}, nil, nil, fmt.Errorf("failed to get document: %w", core.ErrNotFound))
+	svc.EXPECT().GetDocument(mock.Anything, "owner/repo/old", "guide.md").
+		Return(core.Document{}, nil, nil, fmt.Errorf("failed to get document: %w", core.ErrNotFound))
+	svc.EXPECT().ResolveRedirect(mock.Anything, "owner/repo", "old/guide.md").Return("owner/repo", "docs/guide.md", true)
+
+	api := &API{svc: svc, views: NewMockViewRenderer(t)}
+
+	mux, err := api.newMux()
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/docs/owner/repo/old/guide.md", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+	assert.Equal(t, "/docs/owner/repo/docs/guide.md", rec.Header().Get("Location"))
+}
+
+func TestDocPage_SubProject(t *testing.T) {
+	svc := NewMockService(t)
+	views := NewMockViewRenderer(t)
+
+	doc := core.Document{Repo: "owner/mono/service-a", Path: "guide.md", Title: "Guide"}
+	docs := []core.DocumentMeta{{Repo: "owner/mono/service-a", Path: "guide.md", Title: "Guide"}}
+
+	svc.EXPECT().GetDocument(mock.Anything, "owner/mono", "service-a/guide.md").
+		Return(core.Document{}, nil, nil, fmt.Errorf("failed to get document: %w", core.ErrNotFound))
+	svc.EXPECT().GetDocument(mock.Anything, "owner/mono/service-a", "guide.md").Return(doc, []byte("<p>guide</p>"), nil, nil)
+	svc.EXPECT().ListDocuments(mock.Anything, "owner/mono/service-a").Return(docs, nil)
+	svc.EXPECT().PageHighlights(mock.Anything, doc.Repo, docs).Return(nil)
+	views.EXPECT().RenderDoc(mock.Anything, doc, []byte("<p>guide</p>"), []core.Heading(nil), docs, (*core.PageHighlights)(nil), false).Return(nil)
+
+	api := &API{svc: svc, views: views}
+
+	mux, err := api.newMux()
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/docs/owner/mono/service-a/guide.md", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestDocPage_SubProjectIndex(t *testing.T) {
+	tests := []struct {
+		name     string
+		docs     []core.DocumentMeta
+		wantCode int
+	}{
+		{name: "sub-project", docs: []core.DocumentMeta{{Repo: "owner/mono/service-a", Path: "guide.md"}}, wantCode: http.StatusOK},
+		{name: "unknown sub-project", wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewMockService(t)
+			views := NewMockViewRenderer(t)
+
+			svc.EXPECT().GetDocument(mock.Anything, "owner/mono", "service-a/").
+				Return(core.Document{}, nil, nil, fmt.Errorf("failed to get document: %w", core.ErrNotFound))
+			svc.EXPECT().ListDocuments(mock.Anything, "owner/mono/service-a").Return(tt.docs, nil)
+
+			if tt.docs != nil {
+				svc.EXPECT().PageHighlights(mock.Anything, "owner/mono/service-a", tt.docs).Return(nil)
+				views.EXPECT().RenderRepoIndex(mock.Anything, "owner/mono/service-a", tt.docs, (*core.RepoLanding)(nil), (*core.PageHighlights)(nil), false, false).Return(nil)
+			} else {
+				svc.EXPECT().ResolveRedirect(mock.Anything, "owner/mono", "service-a/").Return("", "", false)
+			}
+
+			api := &API{svc: svc, views: views}
+
+			mux, err := api.newMux()
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/docs/owner/mono/service-a/", http.NoBody)
+			rec := httptest.NewRecorder()
+
+			mux.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+		})
+	}
+}
+
+func TestHostRouting(t *testing.T) {
+	svc := NewMockService(t)
+	views := NewMockViewRenderer(t)
+	teamViews := NewMockViewRenderer(t)
+
+	cfg := Config{
+		Listen: ":0",
+		Hosts:  []HostConfig{{Host: "Docs.Team-X.example.com", Name: "Team X Docs", Repos: []string{"team-x"}}},
+	}
+
+	var siteNames []string
+
+	api, err := New(cfg, svc, views, WithSiteViews(func(siteName string) ViewRenderer {
+		siteNames = append(siteNames, siteName)
+		return teamViews
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Team X Docs"}, siteNames)
+
+	mux, err := api.newMux()
+	require.NoError(t, err)
+
+	serve := func(host, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, http.NoBody)
+		req.Host = host
+
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	repos := []core.RepoInfo{{Name: "team-x/api"}, {Name: "team-y/web"}, {Name: "team-x/mono/billing"}}
+
+	t.Run("home lists the site's repositories", func(t *testing.T) {
+		svc.EXPECT().ListRepos(mock.Anything).Return(repos, nil).Once()
+		teamViews.EXPECT().RenderHome(mock.Anything, []core.RepoInfo{{Name: "team-x/api"}, {Name: "team-x/mono/billing"}}, false).Return(nil).Once()
+
+		assert.Equal(t, http.StatusOK, serve("docs.team-x.example.com:443", "/").Code)
+	})
+
+	t.Run("other hosts list every repository", func(t *testing.T) {
+		svc.EXPECT().ListRepos(mock.Anything).Return(repos, nil).Once()
+		views.EXPECT().RenderHome(mock.Anything, repos, false).Return(nil).Once()
+
+		assert.Equal(t, http.StatusOK, serve("docs.example.com", "/").Code)
+	})
+
+	t.Run("search is restricted to the site", func(t *testing.T) {
+		opts := core.SearchOpts{Limit: 20, Repos: []string{"team-x"}}
+		results := &core.SearchResults{}
+
+		svc.EXPECT().RankingExperiment().Return("").Once()
+		svc.EXPECT().SearchDocs(mock.Anything, "deploy", opts).Return(results, nil).Once()
+		svc.EXPECT().ListRepos(mock.Anything).Return(repos, nil).Once()
+		teamViews.EXPECT().RenderSearch(mock.Anything, "deploy", opts, results, []core.RepoInfo{{Name: "team-x/api"}, {Name: "team-x/mono/billing"}}, false).Return(nil).Once()
+
+		assert.Equal(t, http.StatusOK, serve("docs.team-x.example.com", "/search?q=deploy").Code)
+	})
+
+	t.Run("documents outside the site are not found", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, serve("docs.team-x.example.com", "/docs/team-y/web/readme.md").Code)
+		assert.Equal(t, http.StatusNotFound, serve("docs.team-x.example.com", "/raw/team-y/web/readme.md").Code)
+		assert.Equal(t, http.StatusNotFound, serve("docs.team-x.example.com", "/assets/team-y/web/logo.png").Code)
+	})
+
+	t.Run("instance statistics are not shown", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, serve("docs.team-x.example.com", "/stats").Code)
+	})
+}
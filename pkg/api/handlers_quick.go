@@ -0,0 +1,57 @@
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/ksysoev/omnidex/pkg/api/middleware"
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+// quickSearch handles GET /api/v1/quick?q= - returns the titles and paths of the documents
+// matching a search as JSON, for integrations such as a browser extension's search box
+// that need an answer fast. The optional limit query parameter sets the number of
+// results. Hosts serving a site only return documents of their repositories. Browsers
+// may reuse responses for as long as the service reuses the results.
+func (a *API) quickSearch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	if !checkQueryLen(w, query) {
+		return
+	}
+
+	// Missing or invalid limits ask for the default number of results.
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	var repos []string
+	if site, ok := middleware.HostSite(r.Context()); ok {
+		repos = site.Repos
+	}
+
+	results, err := a.svc.QuickSearch(r.Context(), query, repos, limit)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Quick search failed", "error", err, "query", query)
+		http.Error(w, "Search failed", http.StatusInternalServerError)
+
+		return
+	}
+
+	if results == nil {
+		results = []core.QuickResult{}
+	}
+
+	w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(core.QuickCacheTTL.Seconds())))
+	writeJSON(w, r, http.StatusOK, map[string]any{"results": results})
+}
+
+// checkQueryLen responds with 400 Bad Request and returns false if a search query is
+// longer than core.MaxQueryLen.
+func checkQueryLen(w http.ResponseWriter, query string) bool {
+	if len(query) > core.MaxQueryLen {
+		http.Error(w, fmt.Sprintf("query must be at most %d characters", core.MaxQueryLen), http.StatusBadRequest)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,114 @@
+//go:build !compile
+
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuickSearch(t *testing.T) {
+	svc := NewMockService(t)
+
+	svc.EXPECT().QuickSearch(mock.Anything, "deploy", []string(nil), 5).Return([]core.QuickResult{
+		{ID: "YWNtZS9hcGkAZGVwbG95Lm1k", Repo: "acme/api", Path: "deploy.md", Title: "Deploying"},
+	}, nil)
+
+	api := &API{svc: svc}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/quick?q=deploy&limit=5", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	api.quickSearch(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "private, max-age=30", rec.Header().Get("Cache-Control"))
+	assert.JSONEq(t, `{"results":[{"id":"YWNtZS9hcGkAZGVwbG95Lm1k","repo":"acme/api","path":"deploy.md","title":"Deploying"}]}`, rec.Body.String())
+}
+
+func TestQuickSearch_NoResults(t *testing.T) {
+	svc := NewMockService(t)
+
+	svc.EXPECT().QuickSearch(mock.Anything, "", []string(nil), 0).Return(nil, nil)
+
+	api := &API{svc: svc}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/quick", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	api.quickSearch(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"results":[]}`, rec.Body.String())
+}
+
+func TestQuickSearch_Error(t *testing.T) {
+	svc := NewMockService(t)
+
+	svc.EXPECT().QuickSearch(mock.Anything, "deploy", []string(nil), 0).Return(nil, errors.New("index unavailable"))
+
+	api := &API{svc: svc}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/quick?q=deploy", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	api.quickSearch(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestQuickSearch_HostSite(t *testing.T) {
+	svc := NewMockService(t)
+
+	api, err := New(Config{
+		Listen: ":0",
+		Hosts:  []HostConfig{{Host: "docs.team-x.example.com", Repos: []string{"team-x"}}},
+	}, svc, NewMockViewRenderer(t))
+	require.NoError(t, err)
+
+	mux, err := api.newMux()
+	require.NoError(t, err)
+
+	svc.EXPECT().QuickSearch(mock.Anything, "deploy", []string{"team-x"}, 0).Return(nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/quick?q=deploy", http.NoBody)
+	req.Host = "docs.team-x.example.com"
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestSearchHandlers_QueryTooLong(t *testing.T) {
+	svc := NewMockService(t)
+	svc.EXPECT().RankingExperiment().Return("").Maybe()
+
+	api := &API{svc: svc}
+	query := strings.Repeat("(", core.MaxQueryLen+1)
+
+	for name, handler := range map[string]http.HandlerFunc{
+		"/search":         api.searchPage,
+		"/api/v1/quick":   api.quickSearch,
+		"/api/v1/suggest": api.suggestSearch,
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, name+"?q="+url.QueryEscape(query), http.NoBody)
+			rec := httptest.NewRecorder()
+
+			handler(rec, req)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), "query must be at most 500 characters")
+		})
+	}
+}
@@ -0,0 +1,391 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+// commitSHAHeader carries the commit a served document was published from.
+const commitSHAHeader = "X-Omnidex-Commit-SHA"
+
+// rawDocPage handles GET /raw/{owner}/{repo}/{path...} - serves the unrendered source of a document.
+// Binary documents, such as PDFs, are served with the media type of their content type.
+// The ETag is the SHA-256 of the content, so a HEAD request or a conditional GET with
+// If-None-Match detects changes without downloading the document.
+func (a *API) rawDocPage(w http.ResponseWriter, r *http.Request) {
+	owner := r.PathValue("owner")
+	repo := r.PathValue("repo")
+	path := r.PathValue("path")
+
+	if owner == "" || repo == "" || path == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	fullRepo := owner + "/" + repo
+
+	doc, err := a.svc.GetDocumentSource(r.Context(), fullRepo, path)
+	if errors.Is(err, core.ErrNotFound) {
+		if projectRepo, rest, ok := core.SplitProject(fullRepo, path); ok && rest != "" {
+			if pDoc, pErr := a.svc.GetDocumentSource(r.Context(), projectRepo, rest); !errors.Is(pErr, core.ErrNotFound) {
+				fullRepo, doc, err = projectRepo, pDoc, pErr
+			}
+		}
+	}
+
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			if !a.redirectMoved(w, r, "/raw/", fullRepo, path) {
+				http.NotFound(w, r)
+			}
+
+			return
+		}
+
+		slog.ErrorContext(r.Context(), "Failed to get document source", "error", err, "repo", fullRepo, "path", path)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	contentType := a.sourceMediaType(doc.ContentType)
+
+	checksum := doc.Checksum()
+	etag := `"` + checksum.SHA256 + `"`
+
+	w.Header().Set("ETag", etag)
+
+	if checksum.CommitSHA != "" {
+		w.Header().Set(commitSHAHeader, checksum.CommitSHA)
+	}
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(checksum.Size))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	if _, err := w.Write([]byte(doc.Content)); err != nil { //nolint:gosec // Served as text or the media type of a binary content type with nosniff; never interpreted as HTML
+		slog.ErrorContext(r.Context(), "Failed to write document source", "error", err)
+	}
+}
+
+// sourceMediaType returns the media type the source of documents of a content type is
+// served with: the media type of binary content types, otherwise markdown or plain text.
+func (a *API) sourceMediaType(ct core.ContentType) string {
+	if ct == "" || ct == core.ContentTypeMarkdown {
+		return "text/markdown; charset=utf-8"
+	}
+
+	for _, t := range a.svc.ContentTypes() {
+		if t.Name == ct && t.MediaType != "" {
+			return t.MediaType
+		}
+	}
+
+	return "text/plain; charset=utf-8"
+}
+
+// htmlDocPage handles GET /html/{owner}/{repo}/{path...} - serves the rendered, sanitized HTML
+// body of a markdown document without the portal layout. Other content types have no HTML
+// rendering and return 404.
+func (a *API) htmlDocPage(w http.ResponseWriter, r *http.Request) {
+	owner := r.PathValue("owner")
+	repo := r.PathValue("repo")
+	path := r.PathValue("path")
+
+	if owner == "" || repo == "" || path == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	fullRepo := owner + "/" + repo
+
+	doc, html, _, err := a.svc.GetDocument(r.Context(), fullRepo, path)
+	if errors.Is(err, core.ErrNotFound) {
+		if projectRepo, rest, ok := core.SplitProject(fullRepo, path); ok && rest != "" {
+			if pDoc, pHTML, _, pErr := a.svc.GetDocument(r.Context(), projectRepo, rest); !errors.Is(pErr, core.ErrNotFound) {
+				fullRepo, doc, html, err = projectRepo, pDoc, pHTML, pErr
+			}
+		}
+	}
+
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			if !a.redirectMoved(w, r, "/html/", fullRepo, path) {
+				http.NotFound(w, r)
+			}
+
+			return
+		}
+
+		slog.ErrorContext(r.Context(), "Failed to get document", "error", err, "repo", fullRepo, "path", path)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	if doc.ContentType != "" && doc.ContentType != core.ContentTypeMarkdown {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	if _, err := w.Write(html); err != nil { //nolint:gosec // HTML is sanitized by the markdown renderer
+		slog.ErrorContext(r.Context(), "Failed to write document HTML", "error", err)
+	}
+}
+
+// textDocPage handles GET /text/{owner}/{repo}/{path...} - serves the plain-text rendering
+// of a document, without markup.
+func (a *API) textDocPage(w http.ResponseWriter, r *http.Request) {
+	owner := r.PathValue("owner")
+	repo := r.PathValue("repo")
+	path := r.PathValue("path")
+
+	if owner == "" || repo == "" || path == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	fullRepo := owner + "/" + repo
+
+	_, text, err := a.svc.GetDocumentText(r.Context(), fullRepo, path)
+	if errors.Is(err, core.ErrNotFound) {
+		if projectRepo, rest, ok := core.SplitProject(fullRepo, path); ok && rest != "" {
+			if _, pText, pErr := a.svc.GetDocumentText(r.Context(), projectRepo, rest); !errors.Is(pErr, core.ErrNotFound) {
+				fullRepo, text, err = projectRepo, pText, pErr
+			}
+		}
+	}
+
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			if !a.redirectMoved(w, r, "/text/", fullRepo, path) {
+				http.NotFound(w, r)
+			}
+
+			return
+		}
+
+		slog.ErrorContext(r.Context(), "Failed to get document text", "error", err, "repo", fullRepo, "path", path)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	if _, err := w.Write([]byte(text)); err != nil { //nolint:gosec // Served as text/plain with nosniff; never interpreted as HTML
+		slog.ErrorContext(r.Context(), "Failed to write document text", "error", err)
+	}
+}
+
+// metaDocPage handles GET /meta/{owner}/{repo}/{path...} - returns the checksum, size and
+// commit of a document's source as JSON, so that mirrors can detect changes cheaply.
+func (a *API) metaDocPage(w http.ResponseWriter, r *http.Request) {
+	owner := r.PathValue("owner")
+	repo := r.PathValue("repo")
+	path := r.PathValue("path")
+
+	if owner == "" || repo == "" || path == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	fullRepo := owner + "/" + repo
+
+	doc, err := a.svc.GetDocumentSource(r.Context(), fullRepo, path)
+	if errors.Is(err, core.ErrNotFound) {
+		if projectRepo, rest, ok := core.SplitProject(fullRepo, path); ok && rest != "" {
+			if pDoc, pErr := a.svc.GetDocumentSource(r.Context(), projectRepo, rest); !errors.Is(pErr, core.ErrNotFound) {
+				fullRepo, doc, err = projectRepo, pDoc, pErr
+			}
+		}
+	}
+
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			if !a.redirectMoved(w, r, "/meta/", fullRepo, path) {
+				http.NotFound(w, r)
+			}
+
+			return
+		}
+
+		slog.ErrorContext(r.Context(), "Failed to get document source", "error", err, "repo", fullRepo, "path", path)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(doc.Checksum()); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to encode response", "error", err)
+	}
+}
+
+// previewDocPage handles GET /preview/{owner}/{repo}/{path...} - serves the hover preview
+// of a document, an HTML fragment with its title and the start of its content, shown over
+// search results and navigation links.
+func (a *API) previewDocPage(w http.ResponseWriter, r *http.Request) {
+	owner := r.PathValue("owner")
+	repo := r.PathValue("repo")
+	path := r.PathValue("path")
+
+	if owner == "" || repo == "" || path == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	fullRepo := owner + "/" + repo
+
+	doc, html, _, err := a.svc.GetDocument(r.Context(), fullRepo, path)
+	if errors.Is(err, core.ErrNotFound) {
+		if projectRepo, rest, ok := core.SplitProject(fullRepo, path); ok && rest != "" {
+			if pDoc, pHTML, _, pErr := a.svc.GetDocument(r.Context(), projectRepo, rest); !errors.Is(pErr, core.ErrNotFound) {
+				fullRepo, doc, html, err = projectRepo, pDoc, pHTML, pErr
+			}
+		}
+	}
+
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			if !a.redirectMoved(w, r, "/preview/", fullRepo, path) {
+				http.NotFound(w, r)
+			}
+
+			return
+		}
+
+		slog.ErrorContext(r.Context(), "Failed to get document", "error", err, "repo", fullRepo, "path", path)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if err := a.viewsFor(r).RenderPreview(w, doc, html); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to render preview", "error", err)
+	}
+}
+
+// etagMatches reports whether an If-None-Match header value matches etag. Weak
+// comparison is used, as for If-None-Match in RFC 9110.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for candidate := range strings.SplitSeq(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// printSectionPage handles GET /print/{owner}/{repo}/{path...} - renders every markdown
+// document under the directory path, or of the whole repository when path is empty, as a
+// single printable page with a combined table of contents.
+func (a *API) printSectionPage(w http.ResponseWriter, r *http.Request) {
+	repo, dir, docs, ok := a.section(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if err := a.viewsFor(r).RenderSection(w, repo, dir, docs); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to render section", "error", err)
+	}
+}
+
+// epubExport handles GET /epub/{owner}/{repo}/{path...} - exports the markdown documents of
+// a repository, or of the directory path within it, as an EPUB e-book with one chapter per
+// document.
+func (a *API) epubExport(w http.ResponseWriter, r *http.Request) {
+	repo, _, docs, ok := a.section(w, r)
+	if !ok {
+		return
+	}
+
+	asset := func(path string) ([]byte, error) {
+		return a.svc.GetAsset(r.Context(), repo, path)
+	}
+
+	var buf bytes.Buffer
+
+	if err := a.viewsFor(r).RenderEPUB(&buf, repo, docs, asset); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to render epub", "error", err, "repo", repo)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/epub+zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+strings.ReplaceAll(repo, "/", "-")+`.epub"`)
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+
+	if _, err := buf.WriteTo(w); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to write epub", "error", err)
+	}
+}
+
+// section loads the markdown documents under the directory named by the path of a
+// /print/ or /epub/ request, falling back to a monorepo sub-project named by its first
+// segment. It writes an error response and returns false when the section cannot be loaded.
+func (a *API) section(w http.ResponseWriter, r *http.Request) (repo, dir string, docs []core.SectionDocument, ok bool) {
+	owner := r.PathValue("owner")
+	dir = strings.Trim(r.PathValue("path"), "/")
+
+	if owner == "" || r.PathValue("repo") == "" {
+		http.NotFound(w, r)
+		return "", "", nil, false
+	}
+
+	repo = owner + "/" + r.PathValue("repo")
+
+	docs, err := a.svc.GetSection(r.Context(), repo, dir)
+	if errors.Is(err, core.ErrNotFound) {
+		if projectRepo, rest, found := core.SplitProject(repo, dir); found {
+			if pDocs, pErr := a.svc.GetSection(r.Context(), projectRepo, rest); !errors.Is(pErr, core.ErrNotFound) {
+				repo, dir, docs, err = projectRepo, rest, pDocs, pErr
+			}
+		}
+	}
+
+	if err != nil {
+		switch {
+		case errors.Is(err, core.ErrNotFound):
+			http.NotFound(w, r)
+		case errors.Is(err, core.ErrLimitExceeded):
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		default:
+			slog.ErrorContext(r.Context(), "Failed to get section", "error", err, "repo", repo, "dir", dir)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+
+		return "", "", nil, false
+	}
+
+	return repo, dir, docs, true
+}
@@ -0,0 +1,484 @@
+//go:build !compile
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRawDocPage(t *testing.T) {
+	tests := []struct {
+		name            string
+		wantContentType string
+		doc             core.Document
+	}{
+		{
+			name:            "markdown",
+			doc:             core.Document{Content: "# Guide\n\n<b>bold</b>\n", ContentType: core.ContentTypeMarkdown},
+			wantContentType: "text/markdown; charset=utf-8",
+		},
+		{
+			name:            "openapi",
+			doc:             core.Document{Content: "openapi: 3.0.0\n", ContentType: core.ContentTypeOpenAPI},
+			wantContentType: "text/plain; charset=utf-8",
+		},
+		{
+			name:            "binary",
+			doc:             core.Document{Content: "%PDF-1.4\n\x00\xff", ContentType: core.ContentTypePDF},
+			wantContentType: "application/pdf",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, mux, svc, _ := newTestMux(t, Config{})
+
+			svc.EXPECT().GetDocumentSource(mock.Anything, "owner/repo", "docs/guide.md").Return(tt.doc, nil)
+			svc.EXPECT().ContentTypes().Return([]core.ContentTypeInfo{
+				{Name: core.ContentTypeOpenAPI},
+				{Name: core.ContentTypePDF, Binary: true, MediaType: "application/pdf"},
+			}).Maybe()
+
+			req := httptest.NewRequest(http.MethodGet, "/raw/owner/repo/docs/guide.md", http.NoBody)
+			rec := httptest.NewRecorder()
+
+			mux.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.wantContentType, rec.Header().Get("Content-Type"))
+			assert.Equal(t, "nosniff", rec.Header().Get("X-Content-Type-Options"))
+			assert.Equal(t, tt.doc.Content, rec.Body.String())
+		})
+	}
+}
+
+func TestRawDocPage_Errors(t *testing.T) {
+	tests := []struct {
+		err      error
+		name     string
+		wantCode int
+	}{
+		{name: "not found", err: fmt.Errorf("failed to get document: %w", core.ErrNotFound), wantCode: http.StatusNotFound},
+		{name: "internal error", err: errors.New("disk failure"), wantCode: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, mux, svc, _ := newTestMux(t, Config{})
+
+			svc.EXPECT().GetDocumentSource(mock.Anything, "owner/repo", "docs/guide.md").Return(core.Document{}, tt.err)
+
+			if errors.Is(tt.err, core.ErrNotFound) {
+				svc.EXPECT().ResolveRedirect(mock.Anything, "owner/repo", "docs/guide.md").Return("", "", false)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/raw/owner/repo/docs/guide.md", http.NoBody)
+			rec := httptest.NewRecorder()
+
+			mux.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+		})
+	}
+}
+
+func TestHTMLDocPage(t *testing.T) {
+	_, mux, svc, _ := newTestMux(t, Config{})
+
+	doc := core.Document{Repo: "owner/repo", Path: "docs/guide.md", ContentType: core.ContentTypeMarkdown}
+	html := []byte(`<h1 id="guide">Guide</h1>`)
+
+	svc.EXPECT().GetDocument(mock.Anything, "owner/repo", "docs/guide.md").Return(doc, html, []core.Heading(nil), nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/html/owner/repo/docs/guide.md", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "nosniff", rec.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, string(html), rec.Body.String())
+}
+
+func TestHTMLDocPage_NonMarkdown(t *testing.T) {
+	_, mux, svc, _ := newTestMux(t, Config{})
+
+	doc := core.Document{Repo: "owner/repo", Path: "api.yaml", ContentType: core.ContentTypeOpenAPI}
+
+	svc.EXPECT().GetDocument(mock.Anything, "owner/repo", "api.yaml").Return(doc, []byte(`{}`), []core.Heading(nil), nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/html/owner/repo/api.yaml", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestHTMLDocPage_NotFound(t *testing.T) {
+	_, mux, svc, _ := newTestMux(t, Config{})
+
+	svc.EXPECT().GetDocument(mock.Anything, "owner/repo", "missing.md").
+		Return(core.Document{}, nil, nil, fmt.Errorf("failed to get document: %w", core.ErrNotFound))
+	svc.EXPECT().ResolveRedirect(mock.Anything, "owner/repo", "missing.md").Return("", "", false)
+
+	req := httptest.NewRequest(http.MethodGet, "/html/owner/repo/missing.md", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestPreviewDocPage(t *testing.T) {
+	svc := NewMockService(t)
+	views := NewMockViewRenderer(t)
+	api := &API{svc: svc, views: views}
+
+	mux, err := api.newMux()
+	require.NoError(t, err)
+
+	doc := core.Document{Repo: "owner/repo", Path: "docs/guide.md", Title: "Guide"}
+	html := []byte(`<h1 id="guide">Guide</h1><p>Intro</p>`)
+
+	svc.EXPECT().GetDocument(mock.Anything, "owner/repo", "docs/guide.md").Return(doc, html, []core.Heading(nil), nil)
+	views.EXPECT().RenderPreview(mock.Anything, doc, html).RunAndReturn(func(w io.Writer, _ core.Document, _ []byte) error {
+		_, err := w.Write([]byte("<div>preview</div>"))
+		return err
+	})
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/preview/owner/repo/docs/guide.md", http.NoBody))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "<div>preview</div>", rec.Body.String())
+
+	svc.EXPECT().GetDocument(mock.Anything, "owner/repo", "missing.md").
+		Return(core.Document{}, nil, nil, fmt.Errorf("failed to get document: %w", core.ErrNotFound))
+	svc.EXPECT().ResolveRedirect(mock.Anything, "owner/repo", "missing.md").Return("", "", false)
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/preview/owner/repo/missing.md", http.NoBody))
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestTextDocPage(t *testing.T) {
+	_, mux, svc, _ := newTestMux(t, Config{})
+
+	doc := core.Document{Repo: "owner/repo", Path: "docs/guide.md", ContentType: core.ContentTypeMarkdown}
+
+	svc.EXPECT().GetDocumentText(mock.Anything, "owner/repo", "docs/guide.md").Return(doc, "Guide\n\nSome <text>.", nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/text/owner/repo/docs/guide.md", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/plain; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "nosniff", rec.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "Guide\n\nSome <text>.", rec.Body.String())
+}
+
+func TestDocPage_PlainText(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		accept string
+	}{
+		{name: "accept header", target: "/docs/owner/repo/guide.md", accept: "text/plain"},
+		{name: "format parameter", target: "/docs/owner/repo/guide.md?format=text", accept: "*/*"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, mux, svc, _ := newTestMux(t, Config{})
+
+			svc.EXPECT().GetDocumentText(mock.Anything, "owner/repo", "guide.md").Return(core.Document{}, "Guide\n\nSome text.", nil)
+
+			req := httptest.NewRequest(http.MethodGet, tt.target, http.NoBody)
+			req.Header.Set("Accept", tt.accept)
+
+			rec := httptest.NewRecorder()
+
+			mux.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "text/plain; charset=utf-8", rec.Header().Get("Content-Type"))
+			assert.Equal(t, "Accept", rec.Header().Get("Vary"))
+			assert.Equal(t, "Guide\n\nSome text.", rec.Body.String())
+		})
+	}
+}
+
+func TestTextDocPage_Errors(t *testing.T) {
+	tests := []struct {
+		err      error
+		name     string
+		wantCode int
+	}{
+		{name: "not found", err: fmt.Errorf("failed to get document: %w", core.ErrNotFound), wantCode: http.StatusNotFound},
+		{name: "internal error", err: errors.New("disk failure"), wantCode: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, mux, svc, _ := newTestMux(t, Config{})
+
+			svc.EXPECT().GetDocumentText(mock.Anything, "owner/repo", "guide.md").Return(core.Document{}, "", tt.err)
+
+			if errors.Is(tt.err, core.ErrNotFound) {
+				svc.EXPECT().ResolveRedirect(mock.Anything, "owner/repo", "guide.md").Return("", "", false)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/text/owner/repo/guide.md", http.NoBody)
+			rec := httptest.NewRecorder()
+
+			mux.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+		})
+	}
+}
+
+func TestRawDocPage_ETag(t *testing.T) {
+	doc := core.Document{Content: "# Guide\n", CommitSHA: "abc123", ContentType: core.ContentTypeMarkdown}
+	etag := `"` + doc.Checksum().SHA256 + `"`
+
+	tests := []struct {
+		name        string
+		method      string
+		ifNoneMatch string
+		wantBody    string
+		wantCode    int
+	}{
+		{name: "get", method: http.MethodGet, wantCode: http.StatusOK, wantBody: doc.Content},
+		{name: "head", method: http.MethodHead, wantCode: http.StatusOK},
+		{name: "unchanged", method: http.MethodGet, ifNoneMatch: `"other", W/` + etag, wantCode: http.StatusNotModified},
+		{name: "changed", method: http.MethodGet, ifNoneMatch: `"other"`, wantCode: http.StatusOK, wantBody: doc.Content},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, mux, svc, _ := newTestMux(t, Config{})
+
+			svc.EXPECT().GetDocumentSource(mock.Anything, "owner/repo", "docs/guide.md").Return(doc, nil)
+
+			req := httptest.NewRequest(tt.method, "/raw/owner/repo/docs/guide.md", http.NoBody)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+
+			rec := httptest.NewRecorder()
+
+			mux.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Equal(t, etag, rec.Header().Get("ETag"))
+			assert.Equal(t, "abc123", rec.Header().Get("X-Omnidex-Commit-SHA"))
+			assert.Equal(t, tt.wantBody, rec.Body.String())
+		})
+	}
+}
+
+func TestMetaDocPage(t *testing.T) {
+	_, mux, svc, _ := newTestMux(t, Config{})
+
+	updated := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+	doc := core.Document{
+		Repo:        "owner/repo",
+		Path:        "docs/guide.md",
+		Content:     "hello",
+		CommitSHA:   "abc123",
+		ContentType: core.ContentTypeMarkdown,
+		UpdatedAt:   updated,
+	}
+
+	svc.EXPECT().GetDocumentSource(mock.Anything, "owner/repo", "docs/guide.md").Return(doc, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/meta/owner/repo/docs/guide.md", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"repo": "owner/repo",
+		"path": "docs/guide.md",
+		"commit_sha": "abc123",
+		"content_type": "markdown",
+		"updated_at": "2025-06-15T12:00:00Z",
+		"sha256": "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+		"size": 5
+	}`, rec.Body.String())
+}
+
+func TestMetaDocPage_NotFound(t *testing.T) {
+	_, mux, svc, _ := newTestMux(t, Config{})
+
+	svc.EXPECT().GetDocumentSource(mock.Anything, "owner/repo", "missing.md").
+		Return(core.Document{}, fmt.Errorf("failed to get document: %w", core.ErrNotFound))
+	svc.EXPECT().ResolveRedirect(mock.Anything, "owner/repo", "missing.md").Return("", "", false)
+
+	req := httptest.NewRequest(http.MethodGet, "/meta/owner/repo/missing.md", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestPrintSectionPage(t *testing.T) {
+	section := []core.SectionDocument{{Doc: core.Document{Repo: "owner/mono/billing", Path: "docs/guide.md"}}}
+
+	tests := []struct {
+		setup    func(svc *MockService, views *MockViewRenderer)
+		name     string
+		url      string
+		wantCode int
+	}{
+		{
+			name: "repository section",
+			url:  "/print/owner/repo/docs/",
+			setup: func(svc *MockService, views *MockViewRenderer) {
+				svc.EXPECT().GetSection(mock.Anything, "owner/repo", "docs").Return(section, nil)
+				views.EXPECT().RenderSection(mock.Anything, "owner/repo", "docs", section).Return(nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "project section",
+			url:  "/print/owner/mono/billing/docs",
+			setup: func(svc *MockService, views *MockViewRenderer) {
+				svc.EXPECT().GetSection(mock.Anything, "owner/mono", "billing/docs").Return(nil, core.ErrNotFound)
+				svc.EXPECT().GetSection(mock.Anything, "owner/mono/billing", "docs").Return(section, nil)
+				views.EXPECT().RenderSection(mock.Anything, "owner/mono/billing", "docs", section).Return(nil)
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "not found",
+			url:  "/print/owner/repo/",
+			setup: func(svc *MockService, _ *MockViewRenderer) {
+				svc.EXPECT().GetSection(mock.Anything, "owner/repo", "").Return(nil, core.ErrNotFound)
+			},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name: "too large",
+			url:  "/print/owner/repo/",
+			setup: func(svc *MockService, _ *MockViewRenderer) {
+				svc.EXPECT().GetSection(mock.Anything, "owner/repo", "").Return(nil, fmt.Errorf("%w: too many", core.ErrLimitExceeded))
+			},
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "internal error",
+			url:  "/print/owner/repo/",
+			setup: func(svc *MockService, _ *MockViewRenderer) {
+				svc.EXPECT().GetSection(mock.Anything, "owner/repo", "").Return(nil, errors.New("disk failure"))
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewMockService(t)
+			views := NewMockViewRenderer(t)
+			tt.setup(svc, views)
+
+			mux, err := (&API{svc: svc, views: views}).newMux()
+			require.NoError(t, err)
+
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.url, http.NoBody))
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+
+			if tt.wantCode == http.StatusOK {
+				assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestEPUBExport(t *testing.T) {
+	section := []core.SectionDocument{{Doc: core.Document{Repo: "owner/repo", Path: "guide.md"}}}
+
+	t.Run("success", func(t *testing.T) {
+		svc := NewMockService(t)
+		views := NewMockViewRenderer(t)
+
+		svc.EXPECT().GetSection(mock.Anything, "owner/repo", "").Return(section, nil)
+		svc.EXPECT().GetAsset(mock.Anything, "owner/repo", "img/logo.png").Return([]byte("PNG"), nil)
+		views.EXPECT().RenderEPUB(mock.Anything, "owner/repo", section, mock.Anything).
+			RunAndReturn(func(w io.Writer, _ string, _ []core.SectionDocument, asset func(string) ([]byte, error)) error {
+				data, err := asset("img/logo.png")
+				if err != nil {
+					return err
+				}
+
+				_, err = w.Write(data)
+
+				return err
+			})
+
+		mux, err := (&API{svc: svc, views: views}).newMux()
+		require.NoError(t, err)
+
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/epub/owner/repo/", http.NoBody))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/epub+zip", rec.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="owner-repo.epub"`, rec.Header().Get("Content-Disposition"))
+		assert.Equal(t, "PNG", rec.Body.String())
+	})
+
+	t.Run("render failure", func(t *testing.T) {
+		svc := NewMockService(t)
+		views := NewMockViewRenderer(t)
+
+		svc.EXPECT().GetSection(mock.Anything, "owner/repo", "").Return(section, nil)
+		views.EXPECT().RenderEPUB(mock.Anything, "owner/repo", section, mock.Anything).Return(errors.New("zip failure"))
+
+		mux, err := (&API{svc: svc, views: views}).newMux()
+		require.NoError(t, err)
+
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/epub/owner/repo/", http.NoBody))
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Empty(t, rec.Header().Get("Content-Disposition"))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		svc := NewMockService(t)
+
+		svc.EXPECT().GetSection(mock.Anything, "owner/repo", "").Return(nil, core.ErrNotFound)
+
+		mux, err := (&API{svc: svc, views: NewMockViewRenderer(t)}).newMux()
+		require.NoError(t, err)
+
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/epub/owner/repo/", http.NoBody))
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/api/middleware"
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+// maxSavedSearchBody bounds the body of a request to save a search.
+const maxSavedSearchBody = 4 << 10
+
+// atomNamespace is the XML namespace of Atom feeds.
+const atomNamespace = "http://www.w3.org/2005/Atom"
+
+// savedSearchResponse is a saved search with the URL of its feed.
+type savedSearchResponse struct {
+	*core.SavedSearch
+	FeedURL string `json:"feed_url"`
+}
+
+// atomFeed is the root element of an Atom feed.
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	Xmlns   string      `xml:"xmlns,attr"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+// atomLink is a link of an Atom feed or entry.
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+// atomEntry is an entry of an Atom feed.
+type atomEntry struct {
+	ID      string   `xml:"id"`
+	Title   string   `xml:"title"`
+	Updated string   `xml:"updated"`
+	Summary string   `xml:"summary"`
+	Link    atomLink `xml:"link"`
+}
+
+// saveSearch handles POST /api/v1/searches - saves a search query, whose newly published
+// matches are listed in its feed and posted to its webhook. Searches saved on the hostname
+// of a site only match documents of the site's repositories, and searches saved by a
+// signed-in reader notify them in their inbox.
+func (a *API) saveSearch(w http.ResponseWriter, r *http.Request) {
+	var req core.SavedSearchRequest
+
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSavedSearchBody)).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	req.Host = middleware.Hostname(r)
+	req.Owner = a.readerName(r)
+	if site, ok := middleware.HostSite(r.Context()); ok {
+		req.Scope = site.Repos
+	}
+
+	search, err := a.svc.SaveSearch(r.Context(), req)
+	if err != nil {
+		savedSearchError(w, r, err, "Failed to save search")
+		return
+	}
+
+	writeJSON(w, r, http.StatusCreated, savedSearchResponse{SavedSearch: search, FeedURL: savedSearchFeedURL(r, search.ID)})
+}
+
+// getSavedSearch handles GET /api/v1/searches/{id} - returns a saved search with its latest
+// matches. Documents of repositories the request's host does not serve are left out.
+func (a *API) getSavedSearch(w http.ResponseWriter, r *http.Request) {
+	search, err := a.svc.GetSavedSearch(r.Context(), r.PathValue("id"))
+	if err != nil {
+		savedSearchError(w, r, err, "Failed to get saved search")
+		return
+	}
+
+	if site, ok := middleware.HostSite(r.Context()); ok {
+		search.Matches = slices.DeleteFunc(search.Matches, func(m core.SearchMatch) bool {
+			return !site.Serves(m.Repo)
+		})
+	}
+
+	writeJSON(w, r, http.StatusOK, savedSearchResponse{SavedSearch: search, FeedURL: savedSearchFeedURL(r, search.ID)})
+}
+
+// deleteSavedSearch handles DELETE /api/v1/searches/{id} - deletes a saved search, which
+// stops its alerts.
+func (a *API) deleteSavedSearch(w http.ResponseWriter, r *http.Request) {
+	if err := a.svc.DeleteSavedSearch(r.Context(), r.PathValue("id")); err != nil {
+		savedSearchError(w, r, err, "Failed to delete saved search")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// savedSearchFeed handles GET /feeds/searches/{id} - serves the latest documents matching a
+// saved search as an Atom feed. Documents of repositories the request's host does not serve
+// are left out.
+func (a *API) savedSearchFeed(w http.ResponseWriter, r *http.Request) {
+	search, err := a.svc.GetSavedSearch(r.Context(), r.PathValue("id"))
+	if err != nil {
+		savedSearchError(w, r, err, "Failed to get saved search")
+		return
+	}
+
+	origin := requestOrigin(r)
+	feedURL := savedSearchFeedURL(r, search.ID)
+	site, hosted := middleware.HostSite(r.Context())
+
+	feed := atomFeed{
+		Xmlns:   atomNamespace,
+		ID:      feedURL,
+		Title:   "Saved search: " + search.Name,
+		Updated: search.CreatedAt.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: feedURL, Rel: "self"},
+			{Href: origin + "/search?" + url.Values{"q": {search.Query}}.Encode()},
+		},
+	}
+
+	for _, m := range search.Matches {
+		if hosted && !site.Serves(m.Repo) {
+			continue
+		}
+
+		updated := m.Time.UTC().Format(time.RFC3339)
+		if len(feed.Entries) == 0 {
+			feed.Updated = updated
+		}
+
+		docURL := origin + "/docs/" + m.Repo + "/" + escapeDocPath(m.Path)
+
+		feed.Entries = append(feed.Entries, atomEntry{
+			ID:      fmt.Sprintf("%s#%s/%s@%d", feedURL, m.Repo, m.Path, m.Time.Unix()),
+			Title:   m.Title,
+			Updated: updated,
+			Summary: fmt.Sprintf("%s/%s was published matching %q", m.Repo, m.Path, search.Query),
+			Link:    atomLink{Href: docURL},
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+
+	_, _ = fmt.Fprint(w, xml.Header)
+
+	if err := xml.NewEncoder(w).Encode(feed); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to write saved search feed", "error", err)
+	}
+}
+
+// savedSearchError writes the response for an error of a saved search operation.
+func savedSearchError(w http.ResponseWriter, r *http.Request, err error, msg string) {
+	switch {
+	case errors.Is(err, core.ErrNotFound):
+		http.NotFound(w, r)
+	case errors.Is(err, core.ErrNotSupported):
+		http.Error(w, "saved searches are not enabled", http.StatusNotImplemented)
+	case errors.Is(err, core.ErrInvalidSavedSearch):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, core.ErrLimitExceeded):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	default:
+		slog.ErrorContext(r.Context(), msg, "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// savedSearchFeedURL returns the absolute URL of the feed of the saved search with the
+// given ID.
+func savedSearchFeedURL(r *http.Request, id string) string {
+	return requestOrigin(r) + "/feeds/searches/" + id
+}
@@ -0,0 +1,163 @@
+//go:build !compile
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// savedSearchTestConfig routes docs.team-x.example.com to the team-x repositories.
+var savedSearchTestConfig = Config{Hosts: []HostConfig{{Host: "docs.team-x.example.com", Repos: []string{"team-x"}}}}
+
+func serveSavedSearch(mux http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestSaveSearch(t *testing.T) {
+	tests := []struct {
+		err      error
+		name     string
+		body     string
+		wantCode int
+		noCall   bool
+	}{
+		{name: "saved", body: `{"name":"Deploys","query":"deploy"}`, wantCode: http.StatusCreated},
+		{name: "invalid body", body: `{`, wantCode: http.StatusBadRequest, noCall: true},
+		{name: "invalid query", body: `{"query":""}`, err: fmt.Errorf("%w: query must be between 1 and 500 characters", core.ErrInvalidSavedSearch), wantCode: http.StatusBadRequest},
+		{name: "too many", body: `{"query":"deploy"}`, err: fmt.Errorf("%w: too many", core.ErrLimitExceeded), wantCode: http.StatusUnprocessableEntity},
+		{name: "not enabled", body: `{"query":"deploy"}`, err: fmt.Errorf("%w: saved searches are not enabled", core.ErrNotSupported), wantCode: http.StatusNotImplemented},
+		{name: "internal error", body: `{"query":"deploy"}`, err: errors.New("disk full"), wantCode: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, mux, svc, _ := newTestMux(t, savedSearchTestConfig)
+
+			if !tt.noCall {
+				var saved *core.SavedSearch
+				if tt.err == nil {
+					saved = &core.SavedSearch{ID: "a1", Name: "Deploys", Query: "deploy"}
+				}
+
+				svc.EXPECT().SaveSearch(mock.Anything, mock.Anything).Return(saved, tt.err)
+			}
+
+			rec := serveSavedSearch(mux, http.MethodPost, "/api/v1/searches", tt.body)
+			require.Equal(t, tt.wantCode, rec.Code)
+
+			if tt.wantCode == http.StatusCreated {
+				assert.Contains(t, rec.Body.String(), `"feed_url":"http://example.com/feeds/searches/a1"`)
+				assert.Contains(t, rec.Body.String(), `"query":"deploy"`)
+			}
+		})
+	}
+}
+
+func TestGetDeleteSavedSearch(t *testing.T) {
+	_, mux, svc, _ := newTestMux(t, savedSearchTestConfig)
+
+	svc.EXPECT().GetSavedSearch(mock.Anything, "a1").Return(&core.SavedSearch{ID: "a1", Query: "deploy"}, nil).Once()
+	svc.EXPECT().GetSavedSearch(mock.Anything, "b2").Return(nil, fmt.Errorf("%w: saved search b2", core.ErrNotFound)).Once()
+	svc.EXPECT().DeleteSavedSearch(mock.Anything, "a1").Return(nil).Once()
+
+	rec := serveSavedSearch(mux, http.MethodGet, "/api/v1/searches/a1", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"id":"a1"`)
+
+	assert.Equal(t, http.StatusNotFound, serveSavedSearch(mux, http.MethodGet, "/api/v1/searches/b2", "").Code)
+	assert.Equal(t, http.StatusNoContent, serveSavedSearch(mux, http.MethodDelete, "/api/v1/searches/a1", "").Code)
+}
+
+func TestSaveSearch_Site(t *testing.T) {
+	_, mux, svc, _ := newTestMux(t, savedSearchTestConfig)
+
+	svc.EXPECT().SaveSearch(mock.Anything, core.SavedSearchRequest{
+		Query: "deploy",
+		Host:  "docs.team-x.example.com",
+		Scope: []string{"team-x"},
+	}).Return(&core.SavedSearch{ID: "a1", Query: "deploy"}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/searches", strings.NewReader(`{"query":"deploy","host":"evil.example.com"}`))
+	req.Host = "docs.team-x.example.com:8080"
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+}
+
+func TestGetSavedSearch_Site(t *testing.T) {
+	_, mux, svc, _ := newTestMux(t, savedSearchTestConfig)
+
+	svc.EXPECT().GetSavedSearch(mock.Anything, "a1").Return(&core.SavedSearch{
+		ID:    "a1",
+		Query: "deploy",
+		Matches: []core.SearchMatch{
+			{Repo: "team-y/web", Path: "deploy.md", Title: "Web deploys"},
+			{Repo: "team-x/api", Path: "guides/deploy.md", Title: "API deploys"},
+		},
+	}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/searches/a1", http.NoBody)
+	req.Host = "docs.team-x.example.com"
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "API deploys")
+	assert.NotContains(t, rec.Body.String(), "Web deploys", "documents of repositories the host does not serve are left out")
+}
+
+func TestSavedSearchFeed(t *testing.T) {
+	_, mux, svc, _ := newTestMux(t, savedSearchTestConfig)
+
+	now := time.Date(2026, 1, 5, 10, 0, 0, 0, time.UTC)
+
+	svc.EXPECT().GetSavedSearch(mock.Anything, "a1").Return(&core.SavedSearch{
+		ID:        "a1",
+		Name:      "Deploys",
+		Query:     "deploy",
+		CreatedAt: now.Add(-time.Hour),
+		Matches: []core.SearchMatch{
+			{Time: now, Repo: "team-y/web", Path: "deploy.md", Title: "Web deploys"},
+			{Time: now.Add(-time.Minute), Repo: "team-x/api", Path: "guides/deploy.md", Title: "API deploys"},
+		},
+	}, nil)
+
+	rec := serveSavedSearch(mux, http.MethodGet, "/feeds/searches/a1", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/atom+xml; charset=utf-8", rec.Header().Get("Content-Type"))
+
+	body := rec.Body.String()
+	assert.Contains(t, body, `<feed xmlns="http://www.w3.org/2005/Atom">`)
+	assert.Contains(t, body, `<title>Saved search: Deploys</title>`)
+	assert.Contains(t, body, `<link href="http://example.com/docs/team-x/api/guides/deploy.md"></link>`)
+	assert.Contains(t, body, `<link href="http://example.com/docs/team-y/web/deploy.md"></link>`)
+	assert.Contains(t, body, `<updated>2026-01-05T10:00:00Z</updated>`)
+
+	req := httptest.NewRequest(http.MethodGet, "/feeds/searches/a1", http.NoBody)
+	req.Host = "docs.team-x.example.com"
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "API deploys")
+	assert.NotContains(t, rec.Body.String(), "Web deploys", "documents of repositories the host does not serve are left out")
+}
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"io/fs"
+	"log/slog"
+	"net/http"
+)
+
+// serviceWorkerPath is the location of the service worker script within the static file system.
+const serviceWorkerPath = "js/sw.js"
+
+// serviceWorker returns a handler for GET /sw.js that serves the service worker script.
+// Browsers limit a worker's scope to the path it is served from, so it cannot be served
+// under /static/. It is revalidated on every load so that updates roll out promptly.
+func serviceWorker(staticFS fs.FS) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		data, err := fs.ReadFile(staticFS, serviceWorkerPath)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "Failed to read service worker", "error", err)
+			http.NotFound(w, r)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-cache")
+
+		if _, err := w.Write(data); err != nil {
+			slog.ErrorContext(r.Context(), "Failed to write service worker", "error", err)
+		}
+	}
+}
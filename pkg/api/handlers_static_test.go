@@ -0,0 +1,54 @@
+//go:build !compile
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceWorker(t *testing.T) {
+	api := &API{
+		svc:   NewMockService(t),
+		views: NewMockViewRenderer(t),
+		config: Config{StaticFS: fstest.MapFS{
+			"static/js/sw.js": &fstest.MapFile{Data: []byte("self.addEventListener('fetch', function() {});")},
+		}},
+	}
+
+	mux, err := api.newMux()
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/sw.js", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/javascript; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "no-cache", rec.Header().Get("Cache-Control"))
+	assert.Equal(t, "self.addEventListener('fetch', function() {});", rec.Body.String())
+}
+
+func TestServiceWorker_Missing(t *testing.T) {
+	api := &API{
+		svc:    NewMockService(t),
+		views:  NewMockViewRenderer(t),
+		config: Config{StaticFS: fstest.MapFS{"static/css/style.css": &fstest.MapFile{}}},
+	}
+
+	mux, err := api.newMux()
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/sw.js", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/ksysoev/omnidex/pkg/api/middleware"
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+// maxSuggestionAuthor is the maximum length in bytes of the name credited for a suggestion.
+const maxSuggestionAuthor = 100
+
+// suggestPage handles GET /suggest/{owner}/{repo}/{path...} - the page where readers edit
+// the source of a document of a repository accepting suggestions and propose the change
+// as a pull request.
+func (a *API) suggestPage(w http.ResponseWriter, r *http.Request) {
+	repo, path, ok := editTarget(r, a.svc.Suggestible)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	doc, err := a.svc.GetDocumentSource(r.Context(), repo, path)
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+
+		slog.ErrorContext(r.Context(), "Failed to get document", "error", err, "repo", repo, "path", path)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if err := a.viewsFor(r).RenderSuggestion(w, doc); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to render suggestion page", "error", err)
+	}
+}
+
+// previewSuggestion handles POST /suggestions/preview - renders a reader's suggested
+// content of a document the way the portal renders it once published.
+func (a *API) previewSuggestion(w http.ResponseWriter, r *http.Request) {
+	var req previewRequest
+
+	r.Body = http.MaxBytesReader(w, r.Body, a.config.MaxIngestBodyMiB*mib)
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// The preview is public, so it is limited to repositories accepting suggestions.
+	if site, ok := middleware.HostSite(r.Context()); (ok && !site.Serves(req.Repo)) || !a.svc.Suggestible(req.Repo) {
+		http.NotFound(w, r)
+		return
+	}
+
+	html, _, err := a.svc.PreviewEdit(r.Context(), req.Repo, req.Path, req.Content)
+	if err != nil {
+		writeEditError(w, r, err, "preview suggestion", req.Repo, req.Path)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, previewResponse{HTML: string(html)})
+}
+
+// submitSuggestion handles POST /suggestions - proposes a reader's edit of a document to
+// its source repository as a pull request and returns the pull request's URL.
+func (a *API) submitSuggestion(w http.ResponseWriter, r *http.Request) {
+	var req core.Suggestion
+
+	r.Body = http.MaxBytesReader(w, r.Body, a.config.MaxIngestBodyMiB*mib)
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Repo == "" || req.Path == "" || len(req.Author) > maxSuggestionAuthor {
+		http.Error(w, "repo and path are required; author is limited to 100 bytes", http.StatusBadRequest)
+		return
+	}
+
+	if site, ok := middleware.HostSite(r.Context()); ok && !site.Serves(req.Repo) {
+		http.NotFound(w, r)
+		return
+	}
+
+	resp, err := a.svc.SuggestEdit(r.Context(), req)
+	if err != nil {
+		writeEditError(w, r, err, "suggest edit", req.Repo, req.Path)
+		return
+	}
+
+	writeJSON(w, r, http.StatusCreated, resp)
+}
@@ -0,0 +1,104 @@
+//go:build !compile
+
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// servePublic serves a request without credentials, as readers send it.
+func servePublic(mux http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+
+	return rec
+}
+
+func TestSuggestPage(t *testing.T) {
+	_, mux, svc, views := newTestMux(t, editTestConfig)
+
+	doc := core.Document{Repo: "owner/wiki", Path: "guide.md", Title: "Guide", Content: "# Guide"}
+
+	svc.EXPECT().Suggestible("owner/wiki").Return(true)
+	svc.EXPECT().GetDocumentSource(mock.Anything, "owner/wiki", "guide.md").Return(doc, nil)
+	views.EXPECT().RenderSuggestion(mock.Anything, doc).RunAndReturn(func(w io.Writer, _ core.Document) error {
+		_, err := w.Write([]byte("<div>suggest</div>"))
+		return err
+	})
+
+	rec := servePublic(mux, http.MethodGet, "/suggest/owner/wiki/guide.md", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "<div>suggest</div>", rec.Body.String())
+
+	svc.EXPECT().Suggestible("owner/repo").Return(false)
+
+	assert.Equal(t, http.StatusNotFound, servePublic(mux, http.MethodGet, "/suggest/owner/repo/guide.md", "").Code)
+}
+
+func TestPreviewSuggestion(t *testing.T) {
+	_, mux, svc, _ := newTestMux(t, editTestConfig)
+
+	svc.EXPECT().Suggestible("owner/wiki").Return(true)
+	svc.EXPECT().PreviewEdit(mock.Anything, "owner/wiki", "guide.md", "# New").
+		Return([]byte(`<h1 id="new">New</h1>`), nil, nil).Once()
+
+	rec := servePublic(mux, http.MethodPost, "/suggestions/preview", `{"repo":"owner/wiki","path":"guide.md","content":"# New"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"html":"<h1 id=\"new\">New</h1>"}`, rec.Body.String())
+
+	svc.EXPECT().Suggestible("owner/editable").Return(false)
+
+	rec = servePublic(mux, http.MethodPost, "/suggestions/preview", `{"repo":"owner/editable","path":"guide.md","content":"# New"}`)
+	assert.Equal(t, http.StatusNotFound, rec.Code, "editable repositories are previewed through the authenticated API")
+}
+
+func TestSubmitSuggestion(t *testing.T) {
+	tests := []struct {
+		err      error
+		name     string
+		body     string
+		wantCode int
+		noCall   bool
+	}{
+		{name: "proposed", body: `{"repo":"owner/wiki","path":"guide.md","content":"# New","author":"Alex"}`, wantCode: http.StatusCreated},
+		{name: "invalid body", body: `{`, wantCode: http.StatusBadRequest, noCall: true},
+		{name: "missing path", body: `{"repo":"owner/wiki","content":"# New"}`, wantCode: http.StatusBadRequest, noCall: true},
+		{name: "long author", body: `{"repo":"owner/wiki","path":"guide.md","author":"` + strings.Repeat("a", maxSuggestionAuthor+1) + `"}`, wantCode: http.StatusBadRequest, noCall: true},
+		{name: "unchanged", body: `{"repo":"owner/wiki","path":"guide.md","content":"# Guide"}`, err: core.ErrConflict, wantCode: http.StatusConflict},
+		{name: "rate limited", body: `{"repo":"owner/wiki","path":"guide.md","content":"# New"}`, err: core.ErrRateLimited, wantCode: http.StatusTooManyRequests},
+		{name: "not suggestible", body: `{"repo":"owner/repo","path":"guide.md","content":"# New"}`, err: core.ErrNotSupported, wantCode: http.StatusNotImplemented},
+		{name: "internal error", body: `{"repo":"owner/wiki","path":"guide.md","content":"# New"}`, err: errors.New("boom"), wantCode: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, mux, svc, _ := newTestMux(t, editTestConfig)
+
+			if !tt.noCall {
+				var resp *core.SuggestionResponse
+				if tt.err == nil {
+					resp = &core.SuggestionResponse{URL: "https://github.com/owner/wiki/pull/7"}
+				}
+
+				svc.EXPECT().SuggestEdit(mock.Anything, mock.AnythingOfType("core.Suggestion")).Return(resp, tt.err)
+			}
+
+			rec := servePublic(mux, http.MethodPost, "/suggestions", tt.body)
+			require.Equal(t, tt.wantCode, rec.Code)
+
+			if tt.wantCode == http.StatusCreated {
+				assert.JSONEq(t, `{"url":"https://github.com/owner/wiki/pull/7"}`, rec.Body.String())
+			}
+		})
+	}
+}
@@ -23,6 +23,33 @@ func TestHealthCheck(t *testing.T) {
 	assert.Equal(t, "Ok", rec.Body.String())
 }
 
+func TestReadinessCheck(t *testing.T) {
+	api := &API{}
+
+	req := httptest.NewRequest(http.MethodGet, "/readyz", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	api.readinessCheck(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "Ok", rec.Body.String())
+
+	api.SetReady(false)
+
+	rec = httptest.NewRecorder()
+	api.readinessCheck(rec, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "Not ready", rec.Body.String())
+
+	api.SetReady(true)
+
+	rec = httptest.NewRecorder()
+	api.readinessCheck(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
 func TestNewMux_RoutesRegistered(t *testing.T) {
 	svc := NewMockService(t)
 	views := NewMockViewRenderer(t)
@@ -50,6 +77,13 @@ func TestNewMux_RoutesRegistered(t *testing.T) {
 			wantStatusNot: http.StatusNotFound,
 			description:   "health check should be registered",
 		},
+		{
+			name:          "readiness check route exists",
+			method:        http.MethodGet,
+			path:          "/readyz",
+			wantStatusNot: http.StatusNotFound,
+			description:   "readiness check should be registered",
+		},
 	}
 
 	for _, tt := range tests {
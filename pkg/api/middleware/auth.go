@@ -1,22 +1,22 @@
 package middleware
 
 import (
+	"context"
 	"crypto/subtle"
 	"net/http"
 	"strings"
 )
 
-// NewAuth creates a middleware that validates API key authentication.
-// It checks the Authorization header for a valid Bearer token against the provided list of valid keys.
-// If no valid keys are configured, all requests are rejected.
-func NewAuth(validKeys []string) func(http.Handler) http.Handler {
-	keySet := make(map[string]struct{}, len(validKeys))
+// KeyVerifier reports whether a Bearer token is a valid API key, for keys that are not
+// known when the middleware is created, such as keys managed at runtime.
+type KeyVerifier func(ctx context.Context, token string) bool
 
-	for _, k := range validKeys {
-		if k != "" {
-			keySet[k] = struct{}{}
-		}
-	}
+// NewAuth creates a middleware that validates API key authentication.
+// It checks the Authorization header for a valid Bearer token against the provided list of valid keys
+// and, when the token is not in the list, asks each of the verifiers in turn.
+// If no valid keys are configured and no verifier accepts the token, the request is rejected.
+func NewAuth(validKeys []string, verifiers ...KeyVerifier) func(http.Handler) http.Handler {
+	keySet := newKeySet(validKeys)
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -32,7 +32,7 @@ func NewAuth(validKeys []string) func(http.Handler) http.Handler {
 				return
 			}
 
-			if !isValidKey(token, keySet) {
+			if !isValidKey(token, keySet) && !verify(r.Context(), token, verifiers) {
 				http.Error(w, "invalid API key", http.StatusUnauthorized)
 				return
 			}
@@ -42,6 +42,30 @@ func NewAuth(validKeys []string) func(http.Handler) http.Handler {
 	}
 }
 
+// verify reports whether any of the verifiers accepts the token.
+func verify(ctx context.Context, token string, verifiers []KeyVerifier) bool {
+	for _, v := range verifiers {
+		if v(ctx, token) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newKeySet returns the set of the non-empty keys.
+func newKeySet(keys []string) map[string]struct{} {
+	keySet := make(map[string]struct{}, len(keys))
+
+	for _, k := range keys {
+		if k != "" {
+			keySet[k] = struct{}{}
+		}
+	}
+
+	return keySet
+}
+
 func isValidKey(token string, validKeys map[string]struct{}) bool {
 	for key := range validKeys {
 		if subtle.ConstantTimeCompare([]byte(token), []byte(key)) == 1 {
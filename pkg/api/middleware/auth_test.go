@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -108,3 +109,30 @@ func TestNewAuth_EmptyKeys(t *testing.T) {
 
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
 }
+
+func TestNewAuth_Verifier(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	managed := func(_ context.Context, token string) bool { return token == "managed-key" }
+	wrapped := NewAuth(nil, managed)(handler)
+
+	tests := []struct {
+		token    string
+		wantCode int
+	}{
+		{token: "managed-key", wantCode: http.StatusOK},
+		{token: "other-key", wantCode: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "/api/v1/docs", http.NoBody)
+		req.Header.Set("Authorization", "Bearer "+tt.token)
+
+		w := httptest.NewRecorder()
+		wrapped.ServeHTTP(w, req)
+
+		assert.Equal(t, tt.wantCode, w.Code, tt.token)
+	}
+}
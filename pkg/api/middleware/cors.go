@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSRule allows browser applications on other origins, such as internal single-page
+// apps and browser extensions, to call a set of routes.
+type CORSRule struct {
+	// Routes are the path prefixes the rule applies to, e.g. /api/v1/suggest or /raw/.
+	Routes []string
+	// Origins are the origins allowed to call the routes, e.g. https://app.example.com or
+	// chrome-extension://<id>; "*" allows any origin.
+	Origins []string
+	// Methods are the methods allowed in cross-origin requests; GET and HEAD when empty.
+	Methods []string
+	// Headers are the request headers allowed in cross-origin requests, e.g. Authorization.
+	Headers []string
+	// MaxAge is how long browsers may cache the answer to a preflight request.
+	MaxAge time.Duration
+}
+
+// matches reports whether the rule applies to path.
+func (c *CORSRule) matches(path string) bool {
+	for _, route := range c.Routes {
+		if strings.HasPrefix(path, route) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allowedOrigin returns the value of the Access-Control-Allow-Origin header for origin, and
+// false if the rule does not allow it.
+func (c *CORSRule) allowedOrigin(origin string) (string, bool) {
+	for _, o := range c.Origins {
+		if o == "*" {
+			return "*", true
+		}
+
+		if strings.EqualFold(o, origin) {
+			return origin, true
+		}
+	}
+
+	return "", false
+}
+
+// methods returns the methods allowed by the rule.
+func (c *CORSRule) methods() []string {
+	if len(c.Methods) == 0 {
+		return []string{http.MethodGet, http.MethodHead}
+	}
+
+	return c.Methods
+}
+
+// NewCORS creates a middleware that allows cross-origin requests from the origins of the
+// first rule whose routes match the request path. Preflight requests from an allowed
+// origin are answered with 204 No Content and the allowed methods and headers, without
+// calling the next handler, so they need no authentication. Requests without an Origin
+// header, or from an origin no rule allows, pass through without CORS headers, which
+// makes browsers refuse to share the response.
+func NewCORS(rules []CORSRule) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			rule := matchCORSRule(rules, r.URL.Path)
+			if rule == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Add("Vary", "Origin")
+
+			allowed, ok := rule.allowedOrigin(origin)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Origin", allowed)
+
+			if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Methods", strings.Join(rule.methods(), ", "))
+
+			if len(rule.Headers) > 0 {
+				w.Header().Set("Access-Control-Allow-Headers", strings.Join(rule.Headers, ", "))
+			}
+
+			if rule.MaxAge > 0 {
+				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(rule.MaxAge.Seconds())))
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+// matchCORSRule returns the first rule whose routes match path, or nil.
+func matchCORSRule(rules []CORSRule, path string) *CORSRule {
+	for i := range rules {
+		if rules[i].matches(path) {
+			return &rules[i]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCORS(t *testing.T) {
+	rules := []CORSRule{
+		{
+			Routes:  []string{"/api/v1/suggest", "/raw/"},
+			Origins: []string{"https://app.example.com", "chrome-extension://abc"},
+			Headers: []string{"Authorization"},
+			MaxAge:  10 * time.Minute,
+		},
+		{
+			Routes:  []string{"/api/v1/"},
+			Origins: []string{"*"},
+			Methods: []string{http.MethodGet, http.MethodPost},
+		},
+	}
+
+	tests := []struct {
+		headers     map[string]string
+		wantHeaders map[string]string
+		name        string
+		method      string
+		target      string
+		wantCode    int
+	}{
+		{
+			name:        "no origin",
+			method:      http.MethodGet,
+			target:      "/raw/acme/api/README.md",
+			wantCode:    http.StatusOK,
+			wantHeaders: map[string]string{"Access-Control-Allow-Origin": "", "Vary": ""},
+		},
+		{
+			name:        "allowed origin",
+			method:      http.MethodGet,
+			target:      "/raw/acme/api/README.md",
+			headers:     map[string]string{"Origin": "chrome-extension://abc"},
+			wantCode:    http.StatusOK,
+			wantHeaders: map[string]string{"Access-Control-Allow-Origin": "chrome-extension://abc", "Vary": "Origin"},
+		},
+		{
+			name:        "origin not allowed",
+			method:      http.MethodGet,
+			target:      "/api/v1/suggest?q=inst",
+			headers:     map[string]string{"Origin": "https://evil.example.com"},
+			wantCode:    http.StatusOK,
+			wantHeaders: map[string]string{"Access-Control-Allow-Origin": "", "Vary": "Origin"},
+		},
+		{
+			name:        "route without rule",
+			method:      http.MethodGet,
+			target:      "/docs/acme/api/README.md",
+			headers:     map[string]string{"Origin": "https://app.example.com"},
+			wantCode:    http.StatusOK,
+			wantHeaders: map[string]string{"Access-Control-Allow-Origin": "", "Vary": ""},
+		},
+		{
+			name:   "preflight",
+			method: http.MethodOptions,
+			target: "/api/v1/suggest",
+			headers: map[string]string{
+				"Origin":                         "https://app.example.com",
+				"Access-Control-Request-Method":  http.MethodGet,
+				"Access-Control-Request-Headers": "authorization",
+			},
+			wantCode: http.StatusNoContent,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":  "https://app.example.com",
+				"Access-Control-Allow-Methods": "GET, HEAD",
+				"Access-Control-Allow-Headers": "Authorization",
+				"Access-Control-Max-Age":       "600",
+			},
+		},
+		{
+			name:     "preflight for any origin",
+			method:   http.MethodOptions,
+			target:   "/api/v1/repos",
+			headers:  map[string]string{"Origin": "https://tools.example.com", "Access-Control-Request-Method": http.MethodPost},
+			wantCode: http.StatusNoContent,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":  "*",
+				"Access-Control-Allow-Methods": "GET, POST",
+				"Access-Control-Allow-Headers": "",
+				"Access-Control-Max-Age":       "",
+			},
+		},
+		{
+			name:        "preflight from origin not allowed",
+			method:      http.MethodOptions,
+			target:      "/raw/acme/api/README.md",
+			headers:     map[string]string{"Origin": "https://evil.example.com", "Access-Control-Request-Method": http.MethodGet},
+			wantCode:    http.StatusOK,
+			wantHeaders: map[string]string{"Access-Control-Allow-Origin": "", "Access-Control-Allow-Methods": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			wrapped := NewCORS(rules)(handler)
+
+			req := httptest.NewRequest(tt.method, tt.target, http.NoBody)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			w := httptest.NewRecorder()
+			wrapped.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+
+			for k, v := range tt.wantHeaders {
+				assert.Equal(t, v, w.Header().Get(k), k)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Site is the part of the portal served on its own hostname.
+type Site struct {
+	Host  string
+	Repos []string // owners ("team-x") or repositories ("team-x/api") served on the host
+	// Public sites are served to anonymous readers when the portal otherwise requires
+	// readers to sign in, see NewLogin.
+	Public bool
+}
+
+type keySite struct{}
+
+// Serves reports whether the site serves repo, which may be a monorepo sub-project such as
+// "team-x/mono/billing".
+func (s *Site) Serves(repo string) bool {
+	for _, scope := range s.Repos {
+		if repo == scope || strings.HasPrefix(repo, scope+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NewHostRouting creates a middleware that restricts requests for the hostname of a site to
+// the site's repositories. The site is stored in the request context, see HostSite, so
+// handlers listing or searching repositories can narrow their results. Requests routed by
+// {owner} and {repo} path values outside the site are answered with 404 Not Found. Requests
+// for any other hostname pass through unchanged.
+func NewHostRouting(sites []Site) func(http.Handler) http.Handler {
+	byHost := make(map[string]*Site, len(sites))
+
+	for i := range sites {
+		byHost[strings.ToLower(sites[i].Host)] = &sites[i]
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			site, ok := byHost[Hostname(r)]
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if owner, repo := r.PathValue("owner"), r.PathValue("repo"); owner != "" && repo != "" && !site.Serves(owner+"/"+repo) {
+				http.NotFound(w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), keySite{}, site)))
+		})
+	}
+}
+
+// HostSite returns the site whose hostname the request was made for, and false if the
+// request is not restricted to a site.
+func HostSite(ctx context.Context) (*Site, bool) {
+	site, ok := ctx.Value(keySite{}).(*Site)
+	return site, ok
+}
+
+// Hostname returns the lowercased hostname of the request, without port.
+func Hostname(r *http.Request) string {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	return strings.ToLower(host)
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHostRouting(t *testing.T) {
+	sites := []Site{{Host: "Docs.Team-X.example.com", Repos: []string{"team-x", "shared/handbook"}}}
+
+	tests := []struct {
+		name     string
+		host     string
+		path     string
+		wantSite string
+		wantCode int
+	}{
+		{name: "other host", host: "docs.example.com", path: "/docs/team-y/web/", wantCode: http.StatusOK},
+		{name: "site owner", host: "docs.team-x.example.com", path: "/docs/team-x/api/readme.md", wantCode: http.StatusOK, wantSite: "Docs.Team-X.example.com"},
+		{name: "site repository with port", host: "DOCS.team-x.example.com:8080", path: "/docs/shared/handbook/", wantCode: http.StatusOK, wantSite: "Docs.Team-X.example.com"},
+		{name: "site page without repository", host: "docs.team-x.example.com", path: "/search", wantCode: http.StatusOK, wantSite: "Docs.Team-X.example.com"},
+		{name: "repository outside site", host: "docs.team-x.example.com", path: "/docs/shared/other/", wantCode: http.StatusNotFound},
+		{name: "owner prefix is not an owner", host: "docs.team-x.example.com", path: "/docs/team-xyz/api/", wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotSite string
+
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if site, ok := HostSite(r.Context()); ok {
+					gotSite = site.Host
+				}
+
+				w.WriteHeader(http.StatusOK)
+			})
+
+			mux := http.NewServeMux()
+			mux.Handle("GET /docs/{owner}/{repo}/{path...}", NewHostRouting(sites)(handler))
+			mux.Handle("GET /search", NewHostRouting(sites)(handler))
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, http.NoBody)
+			req.Host = tt.host
+
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.Equal(t, tt.wantSite, gotSite)
+		})
+	}
+}
+
+func TestSite_Serves(t *testing.T) {
+	site := &Site{Repos: []string{"team-x", "shared/handbook"}}
+
+	assert.True(t, site.Serves("team-x/api"))
+	assert.True(t, site.Serves("team-x/mono/billing"))
+	assert.True(t, site.Serves("shared/handbook"))
+	assert.True(t, site.Serves("shared/handbook/ops"))
+	assert.False(t, site.Serves("shared/handbooks"))
+	assert.False(t, site.Serves("team-y/web"))
+}
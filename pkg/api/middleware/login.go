@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Login configures how readers of the portal sign in.
+type Login struct {
+	// UserHeader is the request header in which an authenticating reverse proxy passes the
+	// signed-in user, such as X-Forwarded-User. Readers are not required to sign in when
+	// it is empty.
+	UserHeader string
+	// URL is where readers who are not signed in are sent to sign in, with the page they
+	// asked for in the rd parameter. When empty, they are answered with 401 Unauthorized.
+	URL string
+}
+
+// Required reports whether readers must sign in.
+func (l *Login) Required() bool {
+	return l.UserHeader != ""
+}
+
+// Anonymous reports whether the request may be served without signing in: readers need
+// not sign in, or the request is for the hostname of a public site. It must be called
+// with the context set by the host routing middleware.
+func (l *Login) Anonymous(r *http.Request) bool {
+	if !l.Required() {
+		return true
+	}
+
+	site, ok := HostSite(r.Context())
+
+	return ok && site.Public
+}
+
+// NewLogin creates a middleware that requires readers to sign in, unless Anonymous reports
+// the request may be served without it. A reader is signed in when the authenticating
+// proxy passes a user in the login's header, or when the request carries a valid API key
+// as a Bearer token, as tools fetching documents do. Page requests of readers who are not
+// signed in are redirected to the login URL when set; other requests are answered with
+// 401 Unauthorized. It must run after the host routing middleware.
+func NewLogin(login Login, validKeys []string, verifiers ...KeyVerifier) func(http.Handler) http.Handler {
+	keySet := newKeySet(validKeys)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if login.Anonymous(r) || r.Header.Get(login.UserHeader) != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok &&
+				(isValidKey(token, keySet) || verify(r.Context(), token, verifiers)) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if login.URL != "" && isPageRequest(r) {
+				http.Redirect(w, r, loginRedirect(login.URL, r.URL.RequestURI()), http.StatusFound)
+				return
+			}
+
+			http.Error(w, "sign in required", http.StatusUnauthorized)
+		})
+	}
+}
+
+// isPageRequest reports whether the request is a browser navigating to a page, which can
+// follow a redirect to the sign-in page. HTMX requests swap responses into the current
+// page, so they are not.
+func isPageRequest(r *http.Request) bool {
+	return r.Method == http.MethodGet && r.Header.Get("HX-Request") == "" &&
+		strings.Contains(r.Header.Get("Accept"), "text/html")
+}
+
+// loginRedirect returns the login URL with the requested page in its rd parameter.
+func loginRedirect(loginURL, requestURI string) string {
+	sep := "?"
+	if strings.Contains(loginURL, "?") {
+		sep = "&"
+	}
+
+	return loginURL + sep + "rd=" + url.QueryEscape(requestURI)
+}
@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLogin(t *testing.T) {
+	sites := []Site{
+		{Host: "developers.example.com", Repos: []string{"acme/sdk"}, Public: true},
+		{Host: "team.example.com", Repos: []string{"team"}},
+	}
+	login := Login{UserHeader: "X-Forwarded-User", URL: "/oauth2/start"}
+	verifier := func(_ context.Context, token string) bool { return token == "managed-key" }
+
+	tests := []struct {
+		headers      map[string]string
+		name         string
+		host         string
+		target       string
+		wantLocation string
+		login        Login
+		wantCode     int
+	}{
+		{
+			name:     "login not required",
+			login:    Login{},
+			host:     "docs.example.com",
+			target:   "/docs/team/api/guide.md",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "public site",
+			login:    login,
+			host:     "developers.example.com",
+			target:   "/docs/acme/sdk/guide.md",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "signed in through proxy",
+			login:    login,
+			host:     "docs.example.com",
+			target:   "/search?q=deploy",
+			headers:  map[string]string{"X-Forwarded-User": "alice"},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "configured API key",
+			login:    login,
+			host:     "docs.example.com",
+			target:   "/raw/team/api/guide.md",
+			headers:  map[string]string{"Authorization": "Bearer config-key"},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "managed API key",
+			login:    login,
+			host:     "team.example.com",
+			target:   "/raw/team/api/guide.md",
+			headers:  map[string]string{"Authorization": "Bearer managed-key"},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:         "page redirected to sign in",
+			login:        login,
+			host:         "docs.example.com",
+			target:       "/docs/team/api/guide.md?tab=files",
+			headers:      map[string]string{"Accept": "text/html,application/xhtml+xml"},
+			wantCode:     http.StatusFound,
+			wantLocation: "/oauth2/start?rd=%2Fdocs%2Fteam%2Fapi%2Fguide.md%3Ftab%3Dfiles",
+		},
+		{
+			name:     "private site",
+			login:    login,
+			host:     "team.example.com",
+			target:   "/docs/team/api/guide.md",
+			headers:  map[string]string{"Accept": "text/html", "HX-Request": "true"},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "invalid API key",
+			login:    login,
+			host:     "docs.example.com",
+			target:   "/raw/team/api/guide.md",
+			headers:  map[string]string{"Authorization": "Bearer wrong"},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "no login URL",
+			login:    Login{UserHeader: "X-Forwarded-User"},
+			host:     "docs.example.com",
+			target:   "/",
+			headers:  map[string]string{"Accept": "text/html"},
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			wrapped := NewHostRouting(sites)(NewLogin(tt.login, []string{"config-key"}, verifier)(handler))
+
+			req := httptest.NewRequest(http.MethodGet, tt.target, http.NoBody)
+			req.Host = tt.host
+
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			w := httptest.NewRecorder()
+			wrapped.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.Equal(t, tt.wantLocation, w.Header().Get("Location"))
+		})
+	}
+}
+
+func TestLoginRedirect(t *testing.T) {
+	assert.Equal(t, "/oauth2/start?rd=%2Fsearch%3Fq%3Dx", loginRedirect("/oauth2/start", "/search?q=x"))
+	assert.Equal(t, "https://sso.example.com/login?app=docs&rd=%2F", loginRedirect("https://sso.example.com/login?app=docs", "/"))
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// RepoTokenVerifier verifies a Bearer token issued to a CI job, such as a GitHub Actions
+// OIDC ID token, and returns the repository it was issued to.
+type RepoTokenVerifier func(ctx context.Context, token string) (string, error)
+
+type keyTokenRepo struct{}
+
+// NewRepoTokenAuth creates a middleware that accepts repository tokens in addition to the
+// API keys checked by fallback. A Bearer token shaped like a JWT is verified with verify;
+// when it is valid the request proceeds with the token's repository stored in the context,
+// see TokenRepo. Any other token, or a JWT that fails verification, is passed to fallback.
+func NewRepoTokenAuth(verify RepoTokenVerifier, fallback func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		withFallback := fallback(next)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !found || !isJWT(token) {
+				withFallback.ServeHTTP(w, r)
+				return
+			}
+
+			repo, err := verify(r.Context(), token)
+			if err != nil {
+				slog.WarnContext(r.Context(), "Rejected repository token", "error", err)
+				withFallback.ServeHTTP(w, r)
+
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), keyTokenRepo{}, repo)))
+		})
+	}
+}
+
+// TokenRepo returns the repository of the repository token that authenticated the request,
+// and false if the request was authenticated another way.
+func TokenRepo(ctx context.Context) (string, bool) {
+	repo, ok := ctx.Value(keyTokenRepo{}).(string)
+	return repo, ok
+}
+
+// isJWT reports whether token has the shape of a JSON Web Token: three dot-separated
+// segments, the first being a base64url-encoded JSON object.
+func isJWT(token string) bool {
+	return strings.HasPrefix(token, "eyJ") && strings.Count(token, ".") == 2
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRepoTokenAuth(t *testing.T) {
+	const validJWT = "eyJhbGciOiJSUzI1NiJ9.eyJyZXBvIjoiYSJ9.sig"
+
+	verify := func(_ context.Context, token string) (string, error) {
+		if token == validJWT {
+			return "owner/repo", nil
+		}
+
+		return "", errors.New("invalid token")
+	}
+
+	tests := []struct {
+		name     string
+		auth     string
+		wantRepo string
+		wantCode int
+	}{
+		{name: "valid repository token", auth: "Bearer " + validJWT, wantCode: http.StatusOK, wantRepo: "owner/repo"},
+		{name: "invalid repository token", auth: "Bearer eyJhbGciOiJSUzI1NiJ9.eyJ9.forged", wantCode: http.StatusUnauthorized},
+		{name: "api key", auth: "Bearer test-key-123", wantCode: http.StatusOK},
+		{name: "wrong api key", auth: "Bearer wrong-key", wantCode: http.StatusUnauthorized},
+		{name: "missing header", wantCode: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotRepo string
+
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotRepo, _ = TokenRepo(r.Context())
+
+				w.WriteHeader(http.StatusOK)
+			})
+
+			wrapped := NewRepoTokenAuth(verify, NewAuth([]string{"test-key-123"}))(handler)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/docs", http.NoBody)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+
+			w := httptest.NewRecorder()
+			wrapped.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.Equal(t, tt.wantRepo, gotRepo)
+		})
+	}
+}
+
+func TestTokenRepo_Missing(t *testing.T) {
+	repo, ok := TokenRepo(context.Background())
+	assert.False(t, ok)
+	assert.Empty(t, repo)
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Mode is the operating mode of the server.
+type Mode string
+
+const (
+	// ModeNormal serves the portal and accepts content changes.
+	ModeNormal Mode = "normal"
+	// ModeReadOnly serves the portal and rejects content changes, e.g. during a migration.
+	ModeReadOnly Mode = "read_only"
+	// ModeMaintenance serves a maintenance page in place of the portal and rejects content
+	// changes. The admin API keeps working.
+	ModeMaintenance Mode = "maintenance"
+)
+
+// ModeStatus reports the operating mode of the server.
+type ModeStatus struct {
+	Since   time.Time `json:"since"`
+	Mode    Mode      `json:"mode"`
+	Message string    `json:"message,omitempty"` // Shown to readers and publishers while the mode is active.
+}
+
+// valid reports whether m is a known mode.
+func (m Mode) valid() bool {
+	switch m {
+	case ModeNormal, ModeReadOnly, ModeMaintenance:
+		return true
+	default:
+		return false
+	}
+}
+
+// SetMode switches the operating mode of the server. The message explains the mode to
+// readers and publishers; it is ignored in normal mode.
+func (a *API) SetMode(mode Mode, message string) error {
+	if !mode.valid() {
+		return fmt.Errorf("unknown mode %q, expected %s, %s or %s", mode, ModeNormal, ModeReadOnly, ModeMaintenance)
+	}
+
+	if mode == ModeNormal {
+		message = ""
+	}
+
+	a.mode.Store(&ModeStatus{Mode: mode, Message: message, Since: time.Now().UTC()})
+
+	return nil
+}
+
+// Mode returns the operating mode of the server.
+func (a *API) Mode() ModeStatus {
+	if status := a.mode.Load(); status != nil {
+		return *status
+	}
+
+	return ModeStatus{Mode: ModeNormal}
+}
+
+// withWritable rejects content changes with 503 Service Unavailable unless the server is
+// in normal mode.
+func (a *API) withWritable(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := a.Mode()
+		if status.Mode == ModeNormal {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		msg := fmt.Sprintf("server is in %s mode, content changes are rejected", status.Mode)
+		if status.Message != "" {
+			msg += ": " + status.Message
+		}
+
+		http.Error(w, msg, http.StatusServiceUnavailable)
+	})
+}
+
+// withMaintenance answers portal requests with 503 Service Unavailable while the server is
+// in maintenance mode: pages show the maintenance page and other content, such as raw
+// documents and assets, a plain text message.
+func (a *API) withMaintenance(page bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			status := a.Mode()
+			if status.Mode != ModeMaintenance {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("Cache-Control", "no-store")
+
+			if !page {
+				http.Error(w, "down for maintenance", http.StatusServiceUnavailable)
+				return
+			}
+
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			if err := a.viewsFor(r).RenderMaintenance(w, status.Message, isHTMXRequest(r)); err != nil {
+				slog.ErrorContext(r.Context(), "Failed to render maintenance page", "error", err)
+			}
+		})
+	}
+}
+
+// getMode handles GET /api/v1/mode - reports the operating mode of the server.
+func (a *API) getMode(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, r, http.StatusOK, a.Mode())
+}
+
+// setMode handles PUT /api/v1/mode - switches the server to normal, read-only or
+// maintenance mode.
+func (a *API) setMode(w http.ResponseWriter, r *http.Request) {
+	var req ModeStatus
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to decode mode request", "error", err)
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+
+		return
+	}
+
+	if err := a.SetMode(req.Mode, req.Message); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	status := a.Mode()
+
+	slog.InfoContext(r.Context(), "Server mode changed", "mode", status.Mode, "message", status.Message)
+
+	writeJSON(w, r, http.StatusOK, status)
+}
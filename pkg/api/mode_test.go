@@ -0,0 +1,121 @@
+//go:build !compile
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// modeTestConfig accepts the test-key API key sent by serveMode.
+var modeTestConfig = Config{APIKeys: []string{"test-key"}}
+
+func serveMode(mux http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer test-key")
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestSetModeEndpoint(t *testing.T) {
+	api, mux, _, _ := newTestMux(t, modeTestConfig)
+
+	rec := serveMode(mux, http.MethodGet, "/api/v1/mode", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"mode":"normal"`)
+
+	rec = serveMode(mux, http.MethodPut, "/api/v1/mode", `{"mode":"read_only","message":"Migrating storage"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var status ModeStatus
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	assert.Equal(t, ModeReadOnly, status.Mode)
+	assert.Equal(t, "Migrating storage", status.Message)
+	assert.False(t, status.Since.IsZero())
+	assert.Equal(t, ModeReadOnly, api.Mode().Mode)
+
+	rec = serveMode(mux, http.MethodPut, "/api/v1/mode", `{"mode":"normal","message":"ignored"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, ModeStatus{Mode: ModeNormal, Since: api.Mode().Since}, api.Mode())
+
+	assert.Equal(t, http.StatusBadRequest, serveMode(mux, http.MethodPut, "/api/v1/mode", `{"mode":"offline"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, serveMode(mux, http.MethodPut, "/api/v1/mode", `{`).Code)
+	assert.Equal(t, ModeNormal, api.Mode().Mode)
+}
+
+func TestReadOnlyMode(t *testing.T) {
+	api, mux, svc, views := newTestMux(t, modeTestConfig)
+
+	require.NoError(t, api.SetMode(ModeReadOnly, "Migrating storage"))
+
+	rec := serveMode(mux, http.MethodPost, "/api/v1/docs", `{"repo":"owner/repo","documents":[{"path":"a.md","content":"# A"}]}`)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(t, rec.Body.String(), "read_only mode")
+	assert.Contains(t, rec.Body.String(), "Migrating storage")
+
+	assert.Equal(t, http.StatusServiceUnavailable, serveMode(mux, http.MethodDelete, "/api/v1/repos/owner/repo", "").Code)
+	assert.Equal(t, http.StatusServiceUnavailable, serveMode(mux, http.MethodPost, "/api/v1/repos/rename", `{}`).Code)
+	assert.Equal(t, http.StatusServiceUnavailable, serveMode(mux, http.MethodPost, "/api/v1/keys", `{"name":"ci"}`).Code)
+	assert.Equal(t, http.StatusServiceUnavailable, serveMode(mux, http.MethodDelete, "/api/v1/keys/k1", "").Code)
+	assert.Equal(t, http.StatusServiceUnavailable, serveMode(mux, http.MethodPost, "/api/v1/keys/k1/rotate", `{}`).Code)
+
+	repos := []core.RepoInfo{{Name: "owner/repo"}}
+
+	svc.EXPECT().ListRepos(mock.Anything).Return(repos, nil)
+	views.EXPECT().RenderHome(mock.Anything, repos, false).Return(nil)
+
+	assert.Equal(t, http.StatusOK, serveMode(mux, http.MethodGet, "/", "").Code, "reads are still served")
+}
+
+func TestMaintenanceMode(t *testing.T) {
+	api, mux, svc, views := newTestMux(t, modeTestConfig)
+
+	require.NoError(t, api.SetMode(ModeMaintenance, "Back at 10:00 UTC"))
+
+	views.EXPECT().RenderMaintenance(mock.Anything, "Back at 10:00 UTC", false).Return(nil).Once()
+
+	rec := serveMode(mux, http.MethodGet, "/docs/owner/repo/readme.md", "")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+
+	views.EXPECT().RenderMaintenance(mock.Anything, "Back at 10:00 UTC", true).Return(nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/search?q=x", http.NoBody)
+	req.Header.Set("HX-Request", "true")
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	rec = serveMode(mux, http.MethodGet, "/raw/owner/repo/readme.md", "")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(t, rec.Body.String(), "down for maintenance")
+
+	assert.Equal(t, http.StatusServiceUnavailable, serveMode(mux, http.MethodPost, "/api/v1/docs", `{}`).Code)
+
+	svc.EXPECT().Stats(mock.Anything).Return(&core.Stats{}, nil)
+
+	assert.Equal(t, http.StatusOK, serveMode(mux, http.MethodGet, "/api/v1/stats", "").Code, "the admin API keeps working")
+	assert.Equal(t, http.StatusOK, serveMode(mux, http.MethodGet, "/livez", "").Code)
+}
+
+func TestNew_Mode(t *testing.T) {
+	api, err := New(Config{Listen: ":0", Mode: ModeMaintenance, ModeMessage: "Upgrading"}, NewMockService(t), NewMockViewRenderer(t))
+	require.NoError(t, err)
+	assert.Equal(t, ModeMaintenance, api.Mode().Mode)
+	assert.Equal(t, "Upgrading", api.Mode().Message)
+
+	_, err = New(Config{Listen: ":0", Mode: "offline"}, NewMockService(t), NewMockViewRenderer(t))
+	assert.ErrorContains(t, err, `unknown mode "offline"`)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io/fs"
 	"net/http"
+	"net/http/pprof"
 
 	"github.com/ksysoev/omnidex/pkg/api/middleware"
 )
@@ -14,14 +15,81 @@ func (a *API) newMux() (*http.ServeMux, error) {
 	mux := http.NewServeMux()
 
 	withReqID := middleware.NewReqID()
-	withAuth := middleware.NewAuth(a.config.APIKeys)
+	withHost := middleware.NewHostRouting(a.sites())
+	withPage := a.withMaintenance(true)
+	withContent := a.withMaintenance(false)
+	withRead := a.withLoadShedding(false)
+	withIngest := a.withLoadShedding(true)
+	verifiers := append([]middleware.KeyVerifier{a.svc.VerifyAPIKey}, a.verifiers...)
+	withAuth := middleware.NewAuth(a.config.APIKeys, verifiers...)
+	withLogin := middleware.NewLogin(a.login(), a.config.APIKeys, verifiers...)
+	withCORS := middleware.NewCORS(a.corsRules())
+
+	// Ingest also accepts repository tokens, such as GitHub Actions OIDC ID tokens, when configured.
+	withIngestAuth := withAuth
+	if a.config.RepoTokens != nil {
+		withIngestAuth = middleware.NewRepoTokenAuth(a.config.RepoTokens, withAuth)
+	}
+
+	// CORS preflight requests for the routes of the CORS rules.
+	if len(a.config.CORS) > 0 {
+		mux.Handle("OPTIONS /", middleware.Use(a.corsPreflight, withReqID, withCORS))
+	}
 
 	// Health check.
 	mux.Handle("GET /livez", middleware.Use(a.healthCheck, withReqID))
+	mux.Handle("GET /readyz", middleware.Use(a.readinessCheck, withReqID))
 
 	// Ingest API (authenticated).
-	mux.Handle("POST /api/v1/docs", middleware.Use(a.ingestDocs, withReqID, withAuth))
-	mux.Handle("GET /api/v1/repos", middleware.Use(a.listRepos, withReqID, withAuth))
+	mux.Handle("POST /api/v1/docs", middleware.Use(a.ingestDocs, withReqID, withCORS, withIngestAuth, a.withWritable, withIngest))
+	mux.Handle("GET /api/v1/content-types", middleware.Use(a.contentTypes, withReqID, withCORS, withIngestAuth))
+	mux.Handle("GET /api/v1/repos", middleware.Use(a.listRepos, withReqID, withCORS, withAuth))
+	mux.Handle("POST /api/v1/repos/rename", middleware.Use(a.renameRepo, withReqID, withCORS, withAuth, a.withWritable))
+	mux.Handle("GET /api/v1/lint", middleware.Use(a.lintReports, withReqID, withCORS, withAuth))
+	mux.Handle("GET /api/v1/links", middleware.Use(a.linkReports, withReqID, withCORS, withAuth))
+	mux.Handle("GET /api/v1/highlights", middleware.Use(a.getHighlights, withReqID, withCORS, withIngestAuth))
+	mux.Handle("PUT /api/v1/highlights", middleware.Use(a.setHighlights, withReqID, withCORS, withIngestAuth, a.withWritable))
+	mux.Handle("GET /api/v1/banner", middleware.Use(a.getBanner, withReqID, withCORS, withAuth))
+	mux.Handle("PUT /api/v1/banner", middleware.Use(a.setBanner, withReqID, withCORS, withAuth, a.withWritable))
+	mux.Handle("DELETE /api/v1/banner", middleware.Use(a.clearBanner, withReqID, withCORS, withAuth, a.withWritable))
+
+	// Admin API (authenticated).
+	mux.Handle("DELETE /api/v1/repos/{repo...}", middleware.Use(a.deleteRepo, withReqID, withCORS, withAuth, a.withWritable))
+	mux.Handle("POST /api/v1/repos/{owner}/{repo}/republish", middleware.Use(a.republishRepo, withReqID, withCORS, withAuth))
+	mux.Handle("GET /api/v1/keys", middleware.Use(a.listAPIKeys, withReqID, withCORS, withAuth))
+	mux.Handle("POST /api/v1/keys", middleware.Use(a.createAPIKey, withReqID, withCORS, withAuth, a.withWritable))
+	mux.Handle("DELETE /api/v1/keys/{id}", middleware.Use(a.revokeAPIKey, withReqID, withCORS, withAuth, a.withWritable))
+	mux.Handle("POST /api/v1/keys/{id}/rotate", middleware.Use(a.rotateAPIKey, withReqID, withCORS, withAuth, a.withWritable))
+	mux.Handle("POST /api/v1/reindex", middleware.Use(a.reindex, withReqID, withCORS, withAuth))
+	mux.Handle("POST /api/v1/reindex/blue-green", middleware.Use(a.startIndexRebuild, withReqID, withCORS, withAuth))
+	mux.Handle("GET /api/v1/reindex/blue-green", middleware.Use(a.indexRebuildStatus, withReqID, withCORS, withAuth))
+	mux.Handle("GET /api/v1/search/migration", middleware.Use(a.verifyMigration, withReqID, withCORS, withAuth))
+	mux.Handle("GET /api/v1/stats", middleware.Use(a.stats, withReqID, withCORS, withAuth))
+	mux.Handle("GET /api/v1/duplicates", middleware.Use(a.duplicateReport, withReqID, withCORS, withAuth))
+	mux.Handle("GET /api/v1/renders", middleware.Use(a.renderReport, withReqID, withCORS, withAuth))
+	mux.Handle("GET /api/v1/citations", middleware.Use(a.citationManifest, withReqID, withCORS, withAuth))
+	mux.Handle("GET /api/v1/archive", middleware.Use(a.exportArchive, withReqID, withCORS, withAuth))
+	mux.Handle("POST /api/v1/archive", middleware.Use(a.importArchive, withReqID, withCORS, withAuth, a.withWritable))
+	mux.Handle("GET /api/v1/incidents", middleware.Use(a.listIncidents, withReqID, withCORS, withAuth))
+	mux.Handle("POST /api/v1/incidents", middleware.Use(a.startIncident, withReqID, withCORS, withAuth))
+	mux.Handle("DELETE /api/v1/incidents", middleware.Use(a.endIncident, withReqID, withCORS, withAuth))
+	mux.Handle("GET /api/v1/mode", middleware.Use(a.getMode, withReqID, withCORS, withAuth))
+	mux.Handle("PUT /api/v1/mode", middleware.Use(a.setMode, withReqID, withCORS, withAuth))
+
+	// Editor API (signed-in readers).
+	mux.Handle("POST /api/v1/locks", middleware.Use(a.lockDocument, withReqID, withHost, a.withEditor, a.withWritable))
+	mux.Handle("DELETE /api/v1/locks", middleware.Use(a.unlockDocument, withReqID, withHost, a.withEditor))
+	mux.Handle("POST /api/v1/preview", middleware.Use(a.previewEdit, withReqID, withHost, a.withEditor))
+	mux.Handle("POST /api/v1/edits", middleware.Use(a.saveEdit, withReqID, withHost, a.withEditor, a.withWritable, withIngest))
+
+	// Profiling (authenticated, opt-in).
+	if a.config.Profiling {
+		mux.Handle("GET /debug/pprof/", middleware.Use(pprof.Index, withReqID, withAuth))
+		mux.Handle("GET /debug/pprof/cmdline", middleware.Use(pprof.Cmdline, withReqID, withAuth))
+		mux.Handle("GET /debug/pprof/symbol", middleware.Use(pprof.Symbol, withReqID, withAuth))
+		mux.Handle("GET /debug/pprof/profile", middleware.Use(pprof.Profile, withReqID, withAuth))
+		mux.Handle("GET /debug/pprof/trace", middleware.Use(pprof.Trace, withReqID, withAuth))
+	}
 
 	// Static files (embedded into the binary at build time).
 	// StaticFS may be nil in tests that do not exercise static file routes.
@@ -32,15 +100,82 @@ func (a *API) newMux() (*http.ServeMux, error) {
 		}
 
 		mux.Handle("GET /static/", http.StripPrefix("/static/", http.FileServer(http.FS(staticFS))))
+
+		// The service worker is served from the root so that its scope covers document pages.
+		mux.Handle("GET /sw.js", serviceWorker(staticFS))
 	}
 
 	// Asset serving (images, diagrams, etc. stored alongside documents).
-	mux.Handle("GET /assets/{owner}/{repo}/{path...}", middleware.Use(a.assetPage, withReqID))
+	mux.Handle("GET /assets/{owner}/{repo}/{path...}", middleware.Use(a.assetPage, withReqID, withCORS, withHost, withLogin, withContent, withRead))
 
-	// Portal routes (public).
-	mux.Handle("GET /search", middleware.Use(a.searchPage, withReqID))
-	mux.Handle("GET /docs/{owner}/{repo}/{path...}", middleware.Use(a.docPage, withReqID))
-	mux.Handle("GET /", middleware.Use(a.homePage, withReqID))
+	// Portal routes (public unless readers must sign in).
+	mux.Handle("GET /search", middleware.Use(a.searchPage, withReqID, withHost, withLogin, withPage, withRead))
+	mux.Handle("GET /api/v1/docs/{id}", middleware.Use(a.docByID, withReqID, withCORS, withHost, withLogin, withContent, withRead))
+	mux.Handle("GET /api/v1/excerpt", middleware.Use(a.excerpt, withReqID, withCORS, withHost, withLogin, withContent, withRead))
+	mux.Handle("POST /api/v1/search/click", middleware.Use(a.searchClick, withReqID, withHost, withLogin, withContent))
+	mux.Handle("GET /api/v1/quick", middleware.Use(a.quickSearch, withReqID, withCORS, withHost, withLogin, withContent, withRead))
+	mux.Handle("POST /api/v1/searches", middleware.Use(a.saveSearch, withReqID, withCORS, withHost, withLogin, withContent))
+	mux.Handle("GET /api/v1/searches/{id}", middleware.Use(a.getSavedSearch, withReqID, withCORS, withHost, withLogin, withContent))
+	mux.Handle("DELETE /api/v1/searches/{id}", middleware.Use(a.deleteSavedSearch, withReqID, withCORS, withHost, withLogin, withContent))
+	mux.Handle("GET /api/v1/notifications", middleware.Use(a.listNotifications, withReqID, withHost, withLogin, withContent))
+	mux.Handle("POST /api/v1/notifications/read", middleware.Use(a.markNotificationsRead, withReqID, withHost, withLogin, withContent))
+	mux.Handle("GET /notifications", middleware.Use(a.notificationsPage, withReqID, withHost, withLogin, withPage))
+	mux.Handle("GET /notifications/unread", middleware.Use(a.notificationsLink, withReqID, withHost, withLogin, withContent))
+	mux.Handle("GET /feeds/searches/{id}", middleware.Use(a.savedSearchFeed, withReqID, withHost, withLogin, withContent, withRead))
+	mux.Handle("GET /api/v1/suggest", middleware.Use(a.suggestSearch, withReqID, withCORS, withHost, withLogin, withContent, withRead))
+	mux.Handle("GET /stats", middleware.Use(a.statsPage, withReqID, withHost, withLogin, withPage, withRead))
+	mux.Handle("GET /docs/{owner}/{repo}/search", middleware.Use(a.repoSearchPage, withReqID, withHost, withLogin, withPage, withRead))
+	mux.Handle("GET /docs/{owner}/{repo}/{path...}", middleware.Use(a.docPage, withReqID, withHost, withLogin, withPage, withRead))
+	mux.Handle("GET /raw/{owner}/{repo}/{path...}", middleware.Use(a.rawDocPage, withReqID, withCORS, withHost, withLogin, withContent, withRead))
+	mux.Handle("GET /html/{owner}/{repo}/{path...}", middleware.Use(a.htmlDocPage, withReqID, withCORS, withHost, withLogin, withContent, withRead))
+	mux.Handle("GET /text/{owner}/{repo}/{path...}", middleware.Use(a.textDocPage, withReqID, withCORS, withHost, withLogin, withContent, withRead))
+	mux.Handle("GET /meta/{owner}/{repo}/{path...}", middleware.Use(a.metaDocPage, withReqID, withCORS, withHost, withLogin, withContent, withRead))
+	mux.Handle("GET /preview/{owner}/{repo}/{path...}", middleware.Use(a.previewDocPage, withReqID, withHost, withLogin, withContent, withRead))
+	mux.Handle("GET /print/{owner}/{repo}/{path...}", middleware.Use(a.printSectionPage, withReqID, withHost, withLogin, withContent, withRead))
+	mux.Handle("GET /epub/{owner}/{repo}/{path...}", middleware.Use(a.epubExport, withReqID, withHost, withLogin, withContent, withRead))
+	mux.Handle("GET /edit/{owner}/{repo}/{path...}", middleware.Use(a.editPage, withReqID, withHost, withLogin, withPage, withRead))
+	mux.Handle("GET /suggest/{owner}/{repo}/{path...}", middleware.Use(a.suggestPage, withReqID, withHost, withLogin, withPage, withRead))
+	mux.Handle("POST /suggestions/preview", middleware.Use(a.previewSuggestion, withReqID, withHost, withLogin, withContent, withRead))
+	mux.Handle("POST /suggestions", middleware.Use(a.submitSuggestion, withReqID, withHost, withLogin, withContent, withIngest))
+	mux.Handle("GET /events", middleware.Use(a.eventStream, withReqID, withHost, withLogin, withContent))
+	mux.Handle("GET /presence", middleware.Use(a.getPresence, withReqID, withHost, withLogin, withContent))
+	mux.Handle("POST /presence", middleware.Use(a.updatePresence, withReqID, withHost, withLogin, withContent))
+	mux.Handle("GET /robots.txt", middleware.Use(a.robotsTxt, withReqID, withHost, withContent))
+	mux.Handle("GET /sitemap.xml", middleware.Use(a.sitemap, withReqID, withHost, withContent, withRead))
+	mux.Handle("GET /", middleware.Use(a.homePage, withReqID, withHost, withLogin, withPage, withRead))
 
 	return mux, nil
 }
+
+// sites returns the sites of the configured hosts.
+func (a *API) sites() []middleware.Site {
+	sites := make([]middleware.Site, 0, len(a.config.Hosts))
+
+	for _, h := range a.config.Hosts {
+		sites = append(sites, middleware.Site{Host: h.Host, Repos: h.Repos, Public: h.Public})
+	}
+
+	return sites
+}
+
+// login returns how readers of the portal sign in.
+func (a *API) login() middleware.Login {
+	return middleware.Login{UserHeader: a.config.Login.UserHeader, URL: a.config.Login.URL}
+}
+
+// corsRules returns the configured CORS rules.
+func (a *API) corsRules() []middleware.CORSRule {
+	rules := make([]middleware.CORSRule, 0, len(a.config.CORS))
+
+	for _, c := range a.config.CORS {
+		rules = append(rules, middleware.CORSRule{
+			Routes:  c.Routes,
+			Origins: c.Origins,
+			Methods: c.Methods,
+			Headers: c.Headers,
+			MaxAge:  c.MaxAge,
+		})
+	}
+
+	return rules
+}
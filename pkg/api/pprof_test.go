@@ -0,0 +1,66 @@
+//go:build !compile
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfiling(t *testing.T) {
+	svc := NewMockService(t)
+	views := NewMockViewRenderer(t)
+
+	svc.EXPECT().VerifyAPIKey(mock.Anything, mock.Anything).Return(false).Maybe()
+
+	api, err := New(Config{Listen: ":0", APIKeys: []string{"test-key"}, Profiling: true}, svc, views)
+	require.NoError(t, err)
+
+	mux, err := api.newMux()
+	require.NoError(t, err)
+
+	srv := httptest.NewUnstartedServer(mux)
+	srv.Config.WriteTimeout = 100 * time.Millisecond
+	srv.Start()
+
+	defer srv.Close()
+
+	get := func(path, key string) *http.Response {
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, srv.URL+path, http.NoBody)
+		require.NoError(t, err)
+
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+
+		resp, err := srv.Client().Do(req)
+		require.NoError(t, err)
+
+		defer resp.Body.Close()
+
+		return resp
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, get("/debug/pprof/heap", "").StatusCode)
+	assert.Equal(t, http.StatusOK, get("/debug/pprof/heap", "test-key").StatusCode)
+
+	resp := get("/debug/pprof/profile?seconds=1", "test-key")
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "the write timeout does not apply to CPU profiles")
+	assert.Equal(t, "application/octet-stream", resp.Header.Get("Content-Type"))
+}
+
+func TestProfiling_Disabled(t *testing.T) {
+	_, mux, svc, views := newTestMux(t, modeTestConfig)
+
+	svc.EXPECT().ListRepos(mock.Anything).Return(nil, nil)
+	views.EXPECT().RenderHome(mock.Anything, mock.Anything, false).Return(nil)
+
+	rec := serveMode(mux, http.MethodGet, "/debug/pprof/heap", "")
+	assert.Empty(t, rec.Header().Get("X-Content-Type-Options"), "the path falls through to the portal")
+}
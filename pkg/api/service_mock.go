@@ -8,7 +8,12 @@ import (
 	context "context"
 
 	core "github.com/ksysoev/omnidex/pkg/core"
+
+	io "io"
+
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // MockService is an autogenerated mock type for the Service type
@@ -24,29 +29,29 @@ func (_m *MockService) EXPECT() *MockService_Expecter {
 	return &MockService_Expecter{mock: &_m.Mock}
 }
 
-// GetAsset provides a mock function with given fields: ctx, repo, path
-func (_m *MockService) GetAsset(ctx context.Context, repo string, path string) ([]byte, error) {
-	ret := _m.Called(ctx, repo, path)
+// CitationManifest provides a mock function with given fields: ctx, repo
+func (_m *MockService) CitationManifest(ctx context.Context, repo string) (*core.CitationManifest, error) {
+	ret := _m.Called(ctx, repo)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetAsset")
+		panic("no return value specified for CitationManifest")
 	}
 
-	var r0 []byte
+	var r0 *core.CitationManifest
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]byte, error)); ok {
-		return rf(ctx, repo, path)
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*core.CitationManifest, error)); ok {
+		return rf(ctx, repo)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) []byte); ok {
-		r0 = rf(ctx, repo, path)
+	if rf, ok := ret.Get(0).(func(context.Context, string) *core.CitationManifest); ok {
+		r0 = rf(ctx, repo)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]byte)
+			r0 = ret.Get(0).(*core.CitationManifest)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
-		r1 = rf(ctx, repo, path)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, repo)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -54,135 +59,151 @@ func (_m *MockService) GetAsset(ctx context.Context, repo string, path string) (
 	return r0, r1
 }
 
-// MockService_GetAsset_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAsset'
-type MockService_GetAsset_Call struct {
+// MockService_CitationManifest_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CitationManifest'
+type MockService_CitationManifest_Call struct {
 	*mock.Call
 }
 
-// GetAsset is a helper method to define mock.On call
+// CitationManifest is a helper method to define mock.On call
 //   - ctx context.Context
 //   - repo string
-//   - path string
-func (_e *MockService_Expecter) GetAsset(ctx interface{}, repo interface{}, path interface{}) *MockService_GetAsset_Call {
-	return &MockService_GetAsset_Call{Call: _e.mock.On("GetAsset", ctx, repo, path)}
+func (_e *MockService_Expecter) CitationManifest(ctx interface{}, repo interface{}) *MockService_CitationManifest_Call {
+	return &MockService_CitationManifest_Call{Call: _e.mock.On("CitationManifest", ctx, repo)}
 }
 
-func (_c *MockService_GetAsset_Call) Run(run func(ctx context.Context, repo string, path string)) *MockService_GetAsset_Call {
+func (_c *MockService_CitationManifest_Call) Run(run func(ctx context.Context, repo string)) *MockService_CitationManifest_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string), args[2].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *MockService_GetAsset_Call) Return(_a0 []byte, _a1 error) *MockService_GetAsset_Call {
+func (_c *MockService_CitationManifest_Call) Return(_a0 *core.CitationManifest, _a1 error) *MockService_CitationManifest_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockService_GetAsset_Call) RunAndReturn(run func(context.Context, string, string) ([]byte, error)) *MockService_GetAsset_Call {
+func (_c *MockService_CitationManifest_Call) RunAndReturn(run func(context.Context, string) (*core.CitationManifest, error)) *MockService_CitationManifest_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetDocument provides a mock function with given fields: ctx, repo, path
-func (_m *MockService) GetDocument(ctx context.Context, repo string, path string) (core.Document, []byte, []core.Heading, error) {
-	ret := _m.Called(ctx, repo, path)
+// ClearSiteBanner provides a mock function with given fields: ctx
+func (_m *MockService) ClearSiteBanner(ctx context.Context) error {
+	ret := _m.Called(ctx)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetDocument")
+		panic("no return value specified for ClearSiteBanner")
 	}
 
-	var r0 core.Document
-	var r1 []byte
-	var r2 []core.Heading
-	var r3 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) (core.Document, []byte, []core.Heading, error)); ok {
-		return rf(ctx, repo, path)
-	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) core.Document); ok {
-		r0 = rf(ctx, repo, path)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
 	} else {
-		r0 = ret.Get(0).(core.Document)
+		r0 = ret.Error(0)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) []byte); ok {
-		r1 = rf(ctx, repo, path)
-	} else {
-		if ret.Get(1) != nil {
-			r1 = ret.Get(1).([]byte)
-		}
-	}
+	return r0
+}
 
-	if rf, ok := ret.Get(2).(func(context.Context, string, string) []core.Heading); ok {
-		r2 = rf(ctx, repo, path)
-	} else {
-		if ret.Get(2) != nil {
-			r2 = ret.Get(2).([]core.Heading)
-		}
+// MockService_ClearSiteBanner_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ClearSiteBanner'
+type MockService_ClearSiteBanner_Call struct {
+	*mock.Call
+}
+
+// ClearSiteBanner is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockService_Expecter) ClearSiteBanner(ctx interface{}) *MockService_ClearSiteBanner_Call {
+	return &MockService_ClearSiteBanner_Call{Call: _e.mock.On("ClearSiteBanner", ctx)}
+}
+
+func (_c *MockService_ClearSiteBanner_Call) Run(run func(ctx context.Context)) *MockService_ClearSiteBanner_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockService_ClearSiteBanner_Call) Return(_a0 error) *MockService_ClearSiteBanner_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockService_ClearSiteBanner_Call) RunAndReturn(run func(context.Context) error) *MockService_ClearSiteBanner_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ContentTypes provides a mock function with given fields: 
+func (_m *MockService) ContentTypes() []core.ContentTypeInfo {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ContentTypes")
 	}
 
-	if rf, ok := ret.Get(3).(func(context.Context, string, string) error); ok {
-		r3 = rf(ctx, repo, path)
+	var r0 []core.ContentTypeInfo
+	if rf, ok := ret.Get(0).(func() []core.ContentTypeInfo); ok {
+		r0 = rf()
 	} else {
-		r3 = ret.Error(3)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]core.ContentTypeInfo)
+		}
 	}
 
-	return r0, r1, r2, r3
+	return r0
 }
 
-// MockService_GetDocument_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetDocument'
-type MockService_GetDocument_Call struct {
+// MockService_ContentTypes_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ContentTypes'
+type MockService_ContentTypes_Call struct {
 	*mock.Call
 }
 
-// GetDocument is a helper method to define mock.On call
-//   - ctx context.Context
-//   - repo string
-//   - path string
-func (_e *MockService_Expecter) GetDocument(ctx interface{}, repo interface{}, path interface{}) *MockService_GetDocument_Call {
-	return &MockService_GetDocument_Call{Call: _e.mock.On("GetDocument", ctx, repo, path)}
+// ContentTypes is a helper method to define mock.On call
+func (_e *MockService_Expecter) ContentTypes() *MockService_ContentTypes_Call {
+	return &MockService_ContentTypes_Call{Call: _e.mock.On("ContentTypes")}
 }
 
-func (_c *MockService_GetDocument_Call) Run(run func(ctx context.Context, repo string, path string)) *MockService_GetDocument_Call {
+func (_c *MockService_ContentTypes_Call) Run(run func()) *MockService_ContentTypes_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string), args[2].(string))
+		run()
 	})
 	return _c
 }
 
-func (_c *MockService_GetDocument_Call) Return(_a0 core.Document, _a1 []byte, _a2 []core.Heading, _a3 error) *MockService_GetDocument_Call {
-	_c.Call.Return(_a0, _a1, _a2, _a3)
+func (_c *MockService_ContentTypes_Call) Return(_a0 []core.ContentTypeInfo) *MockService_ContentTypes_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *MockService_GetDocument_Call) RunAndReturn(run func(context.Context, string, string) (core.Document, []byte, []core.Heading, error)) *MockService_GetDocument_Call {
+func (_c *MockService_ContentTypes_Call) RunAndReturn(run func() []core.ContentTypeInfo) *MockService_ContentTypes_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// IngestDocuments provides a mock function with given fields: ctx, req
-func (_m *MockService) IngestDocuments(ctx context.Context, req *core.IngestRequest) (*core.IngestResponse, error) {
-	ret := _m.Called(ctx, req)
+// CreateAPIKey provides a mock function with given fields: ctx, name
+func (_m *MockService) CreateAPIKey(ctx context.Context, name string) (*core.CreateAPIKeyResponse, error) {
+	ret := _m.Called(ctx, name)
 
 	if len(ret) == 0 {
-		panic("no return value specified for IngestDocuments")
+		panic("no return value specified for CreateAPIKey")
 	}
 
-	var r0 *core.IngestResponse
+	var r0 *core.CreateAPIKeyResponse
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, *core.IngestRequest) (*core.IngestResponse, error)); ok {
-		return rf(ctx, req)
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*core.CreateAPIKeyResponse, error)); ok {
+		return rf(ctx, name)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, *core.IngestRequest) *core.IngestResponse); ok {
-		r0 = rf(ctx, req)
+	if rf, ok := ret.Get(0).(func(context.Context, string) *core.CreateAPIKeyResponse); ok {
+		r0 = rf(ctx, name)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*core.IngestResponse)
+			r0 = ret.Get(0).(*core.CreateAPIKeyResponse)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, *core.IngestRequest) error); ok {
-		r1 = rf(ctx, req)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -190,53 +211,53 @@ func (_m *MockService) IngestDocuments(ctx context.Context, req *core.IngestRequ
 	return r0, r1
 }
 
-// MockService_IngestDocuments_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IngestDocuments'
-type MockService_IngestDocuments_Call struct {
+// MockService_CreateAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateAPIKey'
+type MockService_CreateAPIKey_Call struct {
 	*mock.Call
 }
 
-// IngestDocuments is a helper method to define mock.On call
+// CreateAPIKey is a helper method to define mock.On call
 //   - ctx context.Context
-//   - req *core.IngestRequest
-func (_e *MockService_Expecter) IngestDocuments(ctx interface{}, req interface{}) *MockService_IngestDocuments_Call {
-	return &MockService_IngestDocuments_Call{Call: _e.mock.On("IngestDocuments", ctx, req)}
+//   - name string
+func (_e *MockService_Expecter) CreateAPIKey(ctx interface{}, name interface{}) *MockService_CreateAPIKey_Call {
+	return &MockService_CreateAPIKey_Call{Call: _e.mock.On("CreateAPIKey", ctx, name)}
 }
 
-func (_c *MockService_IngestDocuments_Call) Run(run func(ctx context.Context, req *core.IngestRequest)) *MockService_IngestDocuments_Call {
+func (_c *MockService_CreateAPIKey_Call) Run(run func(ctx context.Context, name string)) *MockService_CreateAPIKey_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(*core.IngestRequest))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *MockService_IngestDocuments_Call) Return(_a0 *core.IngestResponse, _a1 error) *MockService_IngestDocuments_Call {
+func (_c *MockService_CreateAPIKey_Call) Return(_a0 *core.CreateAPIKeyResponse, _a1 error) *MockService_CreateAPIKey_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockService_IngestDocuments_Call) RunAndReturn(run func(context.Context, *core.IngestRequest) (*core.IngestResponse, error)) *MockService_IngestDocuments_Call {
+func (_c *MockService_CreateAPIKey_Call) RunAndReturn(run func(context.Context, string) (*core.CreateAPIKeyResponse, error)) *MockService_CreateAPIKey_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ListDocuments provides a mock function with given fields: ctx, repo
-func (_m *MockService) ListDocuments(ctx context.Context, repo string) ([]core.DocumentMeta, error) {
+// DeleteRepo provides a mock function with given fields: ctx, repo
+func (_m *MockService) DeleteRepo(ctx context.Context, repo string) (*core.DeleteRepoResponse, error) {
 	ret := _m.Called(ctx, repo)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ListDocuments")
+		panic("no return value specified for DeleteRepo")
 	}
 
-	var r0 []core.DocumentMeta
+	var r0 *core.DeleteRepoResponse
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) ([]core.DocumentMeta, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*core.DeleteRepoResponse, error)); ok {
 		return rf(ctx, repo)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) []core.DocumentMeta); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string) *core.DeleteRepoResponse); ok {
 		r0 = rf(ctx, repo)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]core.DocumentMeta)
+			r0 = ret.Get(0).(*core.DeleteRepoResponse)
 		}
 	}
 
@@ -249,53 +270,100 @@ func (_m *MockService) ListDocuments(ctx context.Context, repo string) ([]core.D
 	return r0, r1
 }
 
-// MockService_ListDocuments_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListDocuments'
-type MockService_ListDocuments_Call struct {
+// MockService_DeleteRepo_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteRepo'
+type MockService_DeleteRepo_Call struct {
 	*mock.Call
 }
 
-// ListDocuments is a helper method to define mock.On call
+// DeleteRepo is a helper method to define mock.On call
 //   - ctx context.Context
 //   - repo string
-func (_e *MockService_Expecter) ListDocuments(ctx interface{}, repo interface{}) *MockService_ListDocuments_Call {
-	return &MockService_ListDocuments_Call{Call: _e.mock.On("ListDocuments", ctx, repo)}
+func (_e *MockService_Expecter) DeleteRepo(ctx interface{}, repo interface{}) *MockService_DeleteRepo_Call {
+	return &MockService_DeleteRepo_Call{Call: _e.mock.On("DeleteRepo", ctx, repo)}
 }
 
-func (_c *MockService_ListDocuments_Call) Run(run func(ctx context.Context, repo string)) *MockService_ListDocuments_Call {
+func (_c *MockService_DeleteRepo_Call) Run(run func(ctx context.Context, repo string)) *MockService_DeleteRepo_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *MockService_ListDocuments_Call) Return(_a0 []core.DocumentMeta, _a1 error) *MockService_ListDocuments_Call {
+func (_c *MockService_DeleteRepo_Call) Return(_a0 *core.DeleteRepoResponse, _a1 error) *MockService_DeleteRepo_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockService_ListDocuments_Call) RunAndReturn(run func(context.Context, string) ([]core.DocumentMeta, error)) *MockService_ListDocuments_Call {
+func (_c *MockService_DeleteRepo_Call) RunAndReturn(run func(context.Context, string) (*core.DeleteRepoResponse, error)) *MockService_DeleteRepo_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ListRepos provides a mock function with given fields: ctx
-func (_m *MockService) ListRepos(ctx context.Context) ([]core.RepoInfo, error) {
+// DeleteSavedSearch provides a mock function with given fields: ctx, id
+func (_m *MockService) DeleteSavedSearch(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteSavedSearch")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockService_DeleteSavedSearch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteSavedSearch'
+type MockService_DeleteSavedSearch_Call struct {
+	*mock.Call
+}
+
+// DeleteSavedSearch is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *MockService_Expecter) DeleteSavedSearch(ctx interface{}, id interface{}) *MockService_DeleteSavedSearch_Call {
+	return &MockService_DeleteSavedSearch_Call{Call: _e.mock.On("DeleteSavedSearch", ctx, id)}
+}
+
+func (_c *MockService_DeleteSavedSearch_Call) Run(run func(ctx context.Context, id string)) *MockService_DeleteSavedSearch_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockService_DeleteSavedSearch_Call) Return(_a0 error) *MockService_DeleteSavedSearch_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockService_DeleteSavedSearch_Call) RunAndReturn(run func(context.Context, string) error) *MockService_DeleteSavedSearch_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DuplicateReport provides a mock function with given fields: ctx
+func (_m *MockService) DuplicateReport(ctx context.Context) ([]core.DuplicateGroup, error) {
 	ret := _m.Called(ctx)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ListRepos")
+		panic("no return value specified for DuplicateReport")
 	}
 
-	var r0 []core.RepoInfo
+	var r0 []core.DuplicateGroup
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context) ([]core.RepoInfo, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context) ([]core.DuplicateGroup, error)); ok {
 		return rf(ctx)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context) []core.RepoInfo); ok {
+	if rf, ok := ret.Get(0).(func(context.Context) []core.DuplicateGroup); ok {
 		r0 = rf(ctx)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]core.RepoInfo)
+			r0 = ret.Get(0).([]core.DuplicateGroup)
 		}
 	}
 
@@ -308,90 +376,2990 @@ func (_m *MockService) ListRepos(ctx context.Context) ([]core.RepoInfo, error) {
 	return r0, r1
 }
 
-// MockService_ListRepos_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListRepos'
-type MockService_ListRepos_Call struct {
+// MockService_DuplicateReport_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DuplicateReport'
+type MockService_DuplicateReport_Call struct {
 	*mock.Call
 }
 
-// ListRepos is a helper method to define mock.On call
+// DuplicateReport is a helper method to define mock.On call
 //   - ctx context.Context
-func (_e *MockService_Expecter) ListRepos(ctx interface{}) *MockService_ListRepos_Call {
-	return &MockService_ListRepos_Call{Call: _e.mock.On("ListRepos", ctx)}
+func (_e *MockService_Expecter) DuplicateReport(ctx interface{}) *MockService_DuplicateReport_Call {
+	return &MockService_DuplicateReport_Call{Call: _e.mock.On("DuplicateReport", ctx)}
 }
 
-func (_c *MockService_ListRepos_Call) Run(run func(ctx context.Context)) *MockService_ListRepos_Call {
+func (_c *MockService_DuplicateReport_Call) Run(run func(ctx context.Context)) *MockService_DuplicateReport_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run(args[0].(context.Context))
 	})
 	return _c
 }
 
-func (_c *MockService_ListRepos_Call) Return(_a0 []core.RepoInfo, _a1 error) *MockService_ListRepos_Call {
+func (_c *MockService_DuplicateReport_Call) Return(_a0 []core.DuplicateGroup, _a1 error) *MockService_DuplicateReport_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockService_ListRepos_Call) RunAndReturn(run func(context.Context) ([]core.RepoInfo, error)) *MockService_ListRepos_Call {
+func (_c *MockService_DuplicateReport_Call) RunAndReturn(run func(context.Context) ([]core.DuplicateGroup, error)) *MockService_DuplicateReport_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// SearchDocs provides a mock function with given fields: ctx, query, opts
-func (_m *MockService) SearchDocs(ctx context.Context, query string, opts core.SearchOpts) (*core.SearchResults, error) {
-	ret := _m.Called(ctx, query, opts)
+// Editable provides a mock function with given fields: repo
+func (_m *MockService) Editable(repo string) bool {
+	ret := _m.Called(repo)
 
 	if len(ret) == 0 {
-		panic("no return value specified for SearchDocs")
+		panic("no return value specified for Editable")
 	}
 
-	var r0 *core.SearchResults
-	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, core.SearchOpts) (*core.SearchResults, error)); ok {
-		return rf(ctx, query, opts)
-	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, core.SearchOpts) *core.SearchResults); ok {
-		r0 = rf(ctx, query, opts)
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = rf(repo)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*core.SearchResults)
-		}
+		r0 = ret.Get(0).(bool)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, core.SearchOpts) error); ok {
-		r1 = rf(ctx, query, opts)
+	return r0
+}
+
+// MockService_Editable_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Editable'
+type MockService_Editable_Call struct {
+	*mock.Call
+}
+
+// Editable is a helper method to define mock.On call
+//   - repo string
+func (_e *MockService_Expecter) Editable(repo interface{}) *MockService_Editable_Call {
+	return &MockService_Editable_Call{Call: _e.mock.On("Editable", repo)}
+}
+
+func (_c *MockService_Editable_Call) Run(run func(repo string)) *MockService_Editable_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockService_Editable_Call) Return(_a0 bool) *MockService_Editable_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockService_Editable_Call) RunAndReturn(run func(string) bool) *MockService_Editable_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// EndIncident provides a mock function with given fields: ctx, repo, path
+func (_m *MockService) EndIncident(ctx context.Context, repo string, path string) error {
+	ret := _m.Called(ctx, repo, path)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EndIncident")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, repo, path)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1
+	return r0
 }
 
-// MockService_SearchDocs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SearchDocs'
-type MockService_SearchDocs_Call struct {
+// MockService_EndIncident_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'EndIncident'
+type MockService_EndIncident_Call struct {
 	*mock.Call
 }
 
-// SearchDocs is a helper method to define mock.On call
+// EndIncident is a helper method to define mock.On call
 //   - ctx context.Context
-//   - query string
-//   - opts core.SearchOpts
-func (_e *MockService_Expecter) SearchDocs(ctx interface{}, query interface{}, opts interface{}) *MockService_SearchDocs_Call {
-	return &MockService_SearchDocs_Call{Call: _e.mock.On("SearchDocs", ctx, query, opts)}
+//   - repo string
+//   - path string
+func (_e *MockService_Expecter) EndIncident(ctx interface{}, repo interface{}, path interface{}) *MockService_EndIncident_Call {
+	return &MockService_EndIncident_Call{Call: _e.mock.On("EndIncident", ctx, repo, path)}
 }
 
-func (_c *MockService_SearchDocs_Call) Run(run func(ctx context.Context, query string, opts core.SearchOpts)) *MockService_SearchDocs_Call {
+func (_c *MockService_EndIncident_Call) Run(run func(ctx context.Context, repo string, path string)) *MockService_EndIncident_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string), args[2].(core.SearchOpts))
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
 	})
 	return _c
 }
 
-func (_c *MockService_SearchDocs_Call) Return(_a0 *core.SearchResults, _a1 error) *MockService_SearchDocs_Call {
-	_c.Call.Return(_a0, _a1)
+func (_c *MockService_EndIncident_Call) Return(_a0 error) *MockService_EndIncident_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *MockService_SearchDocs_Call) RunAndReturn(run func(context.Context, string, core.SearchOpts) (*core.SearchResults, error)) *MockService_SearchDocs_Call {
+func (_c *MockService_EndIncident_Call) RunAndReturn(run func(context.Context, string, string) error) *MockService_EndIncident_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ExportArchive provides a mock function with given fields: ctx, w
+func (_m *MockService) ExportArchive(ctx context.Context, w io.Writer) error {
+	ret := _m.Called(ctx, w)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportArchive")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, io.Writer) error); ok {
+		r0 = rf(ctx, w)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockService_ExportArchive_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExportArchive'
+type MockService_ExportArchive_Call struct {
+	*mock.Call
+}
+
+// ExportArchive is a helper method to define mock.On call
+//   - ctx context.Context
+//   - w io.Writer
+func (_e *MockService_Expecter) ExportArchive(ctx interface{}, w interface{}) *MockService_ExportArchive_Call {
+	return &MockService_ExportArchive_Call{Call: _e.mock.On("ExportArchive", ctx, w)}
+}
+
+func (_c *MockService_ExportArchive_Call) Run(run func(ctx context.Context, w io.Writer)) *MockService_ExportArchive_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(io.Writer))
+	})
+	return _c
+}
+
+func (_c *MockService_ExportArchive_Call) Return(_a0 error) *MockService_ExportArchive_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockService_ExportArchive_Call) RunAndReturn(run func(context.Context, io.Writer) error) *MockService_ExportArchive_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAsset provides a mock function with given fields: ctx, repo, path
+func (_m *MockService) GetAsset(ctx context.Context, repo string, path string) ([]byte, error) {
+	ret := _m.Called(ctx, repo, path)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAsset")
+	}
+
+	var r0 []byte
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]byte, error)); ok {
+		return rf(ctx, repo, path)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []byte); ok {
+		r0 = rf(ctx, repo, path)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, repo, path)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_GetAsset_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAsset'
+type MockService_GetAsset_Call struct {
+	*mock.Call
+}
+
+// GetAsset is a helper method to define mock.On call
+//   - ctx context.Context
+//   - repo string
+//   - path string
+func (_e *MockService_Expecter) GetAsset(ctx interface{}, repo interface{}, path interface{}) *MockService_GetAsset_Call {
+	return &MockService_GetAsset_Call{Call: _e.mock.On("GetAsset", ctx, repo, path)}
+}
+
+func (_c *MockService_GetAsset_Call) Run(run func(ctx context.Context, repo string, path string)) *MockService_GetAsset_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockService_GetAsset_Call) Return(_a0 []byte, _a1 error) *MockService_GetAsset_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_GetAsset_Call) RunAndReturn(run func(context.Context, string, string) ([]byte, error)) *MockService_GetAsset_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetDocument provides a mock function with given fields: ctx, repo, path
+func (_m *MockService) GetDocument(ctx context.Context, repo string, path string) (core.Document, []byte, []core.Heading, error) {
+	ret := _m.Called(ctx, repo, path)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDocument")
+	}
+
+	var r0 core.Document
+	var r1 []byte
+	var r2 []core.Heading
+	var r3 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (core.Document, []byte, []core.Heading, error)); ok {
+		return rf(ctx, repo, path)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) core.Document); ok {
+		r0 = rf(ctx, repo, path)
+	} else {
+		r0 = ret.Get(0).(core.Document)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) []byte); ok {
+		r1 = rf(ctx, repo, path)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string) []core.Heading); ok {
+		r2 = rf(ctx, repo, path)
+	} else {
+		if ret.Get(2) != nil {
+			r2 = ret.Get(2).([]core.Heading)
+		}
+	}
+
+	if rf, ok := ret.Get(3).(func(context.Context, string, string) error); ok {
+		r3 = rf(ctx, repo, path)
+	} else {
+		r3 = ret.Error(3)
+	}
+
+	return r0, r1, r2, r3
+}
+
+// MockService_GetDocument_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetDocument'
+type MockService_GetDocument_Call struct {
+	*mock.Call
+}
+
+// GetDocument is a helper method to define mock.On call
+//   - ctx context.Context
+//   - repo string
+//   - path string
+func (_e *MockService_Expecter) GetDocument(ctx interface{}, repo interface{}, path interface{}) *MockService_GetDocument_Call {
+	return &MockService_GetDocument_Call{Call: _e.mock.On("GetDocument", ctx, repo, path)}
+}
+
+func (_c *MockService_GetDocument_Call) Run(run func(ctx context.Context, repo string, path string)) *MockService_GetDocument_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockService_GetDocument_Call) Return(_a0 core.Document, _a1 []byte, _a2 []core.Heading, _a3 error) *MockService_GetDocument_Call {
+	_c.Call.Return(_a0, _a1, _a2, _a3)
+	return _c
+}
+
+func (_c *MockService_GetDocument_Call) RunAndReturn(run func(context.Context, string, string) (core.Document, []byte, []core.Heading, error)) *MockService_GetDocument_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetDocumentSource provides a mock function with given fields: ctx, repo, path
+func (_m *MockService) GetDocumentSource(ctx context.Context, repo string, path string) (core.Document, error) {
+	ret := _m.Called(ctx, repo, path)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDocumentSource")
+	}
+
+	var r0 core.Document
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (core.Document, error)); ok {
+		return rf(ctx, repo, path)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) core.Document); ok {
+		r0 = rf(ctx, repo, path)
+	} else {
+		r0 = ret.Get(0).(core.Document)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, repo, path)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_GetDocumentSource_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetDocumentSource'
+type MockService_GetDocumentSource_Call struct {
+	*mock.Call
+}
+
+// GetDocumentSource is a helper method to define mock.On call
+//   - ctx context.Context
+//   - repo string
+//   - path string
+func (_e *MockService_Expecter) GetDocumentSource(ctx interface{}, repo interface{}, path interface{}) *MockService_GetDocumentSource_Call {
+	return &MockService_GetDocumentSource_Call{Call: _e.mock.On("GetDocumentSource", ctx, repo, path)}
+}
+
+func (_c *MockService_GetDocumentSource_Call) Run(run func(ctx context.Context, repo string, path string)) *MockService_GetDocumentSource_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockService_GetDocumentSource_Call) Return(_a0 core.Document, _a1 error) *MockService_GetDocumentSource_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_GetDocumentSource_Call) RunAndReturn(run func(context.Context, string, string) (core.Document, error)) *MockService_GetDocumentSource_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetDocumentText provides a mock function with given fields: ctx, repo, path
+func (_m *MockService) GetDocumentText(ctx context.Context, repo string, path string) (core.Document, string, error) {
+	ret := _m.Called(ctx, repo, path)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDocumentText")
+	}
+
+	var r0 core.Document
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (core.Document, string, error)); ok {
+		return rf(ctx, repo, path)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) core.Document); ok {
+		r0 = rf(ctx, repo, path)
+	} else {
+		r0 = ret.Get(0).(core.Document)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) string); ok {
+		r1 = rf(ctx, repo, path)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string) error); ok {
+		r2 = rf(ctx, repo, path)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockService_GetDocumentText_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetDocumentText'
+type MockService_GetDocumentText_Call struct {
+	*mock.Call
+}
+
+// GetDocumentText is a helper method to define mock.On call
+//   - ctx context.Context
+//   - repo string
+//   - path string
+func (_e *MockService_Expecter) GetDocumentText(ctx interface{}, repo interface{}, path interface{}) *MockService_GetDocumentText_Call {
+	return &MockService_GetDocumentText_Call{Call: _e.mock.On("GetDocumentText", ctx, repo, path)}
+}
+
+func (_c *MockService_GetDocumentText_Call) Run(run func(ctx context.Context, repo string, path string)) *MockService_GetDocumentText_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockService_GetDocumentText_Call) Return(_a0 core.Document, _a1 string, _a2 error) *MockService_GetDocumentText_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *MockService_GetDocumentText_Call) RunAndReturn(run func(context.Context, string, string) (core.Document, string, error)) *MockService_GetDocumentText_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetExcerpt provides a mock function with given fields: ctx, repo, path, anchor
+func (_m *MockService) GetExcerpt(ctx context.Context, repo string, path string, anchor string) (*core.Excerpt, error) {
+	ret := _m.Called(ctx, repo, path, anchor)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetExcerpt")
+	}
+
+	var r0 *core.Excerpt
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (*core.Excerpt, error)); ok {
+		return rf(ctx, repo, path, anchor)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) *core.Excerpt); ok {
+		r0 = rf(ctx, repo, path, anchor)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.Excerpt)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, repo, path, anchor)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_GetExcerpt_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetExcerpt'
+type MockService_GetExcerpt_Call struct {
+	*mock.Call
+}
+
+// GetExcerpt is a helper method to define mock.On call
+//   - ctx context.Context
+//   - repo string
+//   - path string
+//   - anchor string
+func (_e *MockService_Expecter) GetExcerpt(ctx interface{}, repo interface{}, path interface{}, anchor interface{}) *MockService_GetExcerpt_Call {
+	return &MockService_GetExcerpt_Call{Call: _e.mock.On("GetExcerpt", ctx, repo, path, anchor)}
+}
+
+func (_c *MockService_GetExcerpt_Call) Run(run func(ctx context.Context, repo string, path string, anchor string)) *MockService_GetExcerpt_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockService_GetExcerpt_Call) Return(_a0 *core.Excerpt, _a1 error) *MockService_GetExcerpt_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_GetExcerpt_Call) RunAndReturn(run func(context.Context, string, string, string) (*core.Excerpt, error)) *MockService_GetExcerpt_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetRepoHighlights provides a mock function with given fields: ctx, repo
+func (_m *MockService) GetRepoHighlights(ctx context.Context, repo string) (*core.RepoHighlights, error) {
+	ret := _m.Called(ctx, repo)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRepoHighlights")
+	}
+
+	var r0 *core.RepoHighlights
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*core.RepoHighlights, error)); ok {
+		return rf(ctx, repo)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *core.RepoHighlights); ok {
+		r0 = rf(ctx, repo)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.RepoHighlights)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, repo)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_GetRepoHighlights_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetRepoHighlights'
+type MockService_GetRepoHighlights_Call struct {
+	*mock.Call
+}
+
+// GetRepoHighlights is a helper method to define mock.On call
+//   - ctx context.Context
+//   - repo string
+func (_e *MockService_Expecter) GetRepoHighlights(ctx interface{}, repo interface{}) *MockService_GetRepoHighlights_Call {
+	return &MockService_GetRepoHighlights_Call{Call: _e.mock.On("GetRepoHighlights", ctx, repo)}
+}
+
+func (_c *MockService_GetRepoHighlights_Call) Run(run func(ctx context.Context, repo string)) *MockService_GetRepoHighlights_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockService_GetRepoHighlights_Call) Return(_a0 *core.RepoHighlights, _a1 error) *MockService_GetRepoHighlights_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_GetRepoHighlights_Call) RunAndReturn(run func(context.Context, string) (*core.RepoHighlights, error)) *MockService_GetRepoHighlights_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetSavedSearch provides a mock function with given fields: ctx, id
+func (_m *MockService) GetSavedSearch(ctx context.Context, id string) (*core.SavedSearch, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSavedSearch")
+	}
+
+	var r0 *core.SavedSearch
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*core.SavedSearch, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *core.SavedSearch); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.SavedSearch)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_GetSavedSearch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSavedSearch'
+type MockService_GetSavedSearch_Call struct {
+	*mock.Call
+}
+
+// GetSavedSearch is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *MockService_Expecter) GetSavedSearch(ctx interface{}, id interface{}) *MockService_GetSavedSearch_Call {
+	return &MockService_GetSavedSearch_Call{Call: _e.mock.On("GetSavedSearch", ctx, id)}
+}
+
+func (_c *MockService_GetSavedSearch_Call) Run(run func(ctx context.Context, id string)) *MockService_GetSavedSearch_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockService_GetSavedSearch_Call) Return(_a0 *core.SavedSearch, _a1 error) *MockService_GetSavedSearch_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_GetSavedSearch_Call) RunAndReturn(run func(context.Context, string) (*core.SavedSearch, error)) *MockService_GetSavedSearch_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetSection provides a mock function with given fields: ctx, repo, dir
+func (_m *MockService) GetSection(ctx context.Context, repo string, dir string) ([]core.SectionDocument, error) {
+	ret := _m.Called(ctx, repo, dir)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSection")
+	}
+
+	var r0 []core.SectionDocument
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]core.SectionDocument, error)); ok {
+		return rf(ctx, repo, dir)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []core.SectionDocument); ok {
+		r0 = rf(ctx, repo, dir)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]core.SectionDocument)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, repo, dir)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_GetSection_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSection'
+type MockService_GetSection_Call struct {
+	*mock.Call
+}
+
+// GetSection is a helper method to define mock.On call
+//   - ctx context.Context
+//   - repo string
+//   - dir string
+func (_e *MockService_Expecter) GetSection(ctx interface{}, repo interface{}, dir interface{}) *MockService_GetSection_Call {
+	return &MockService_GetSection_Call{Call: _e.mock.On("GetSection", ctx, repo, dir)}
+}
+
+func (_c *MockService_GetSection_Call) Run(run func(ctx context.Context, repo string, dir string)) *MockService_GetSection_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockService_GetSection_Call) Return(_a0 []core.SectionDocument, _a1 error) *MockService_GetSection_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_GetSection_Call) RunAndReturn(run func(context.Context, string, string) ([]core.SectionDocument, error)) *MockService_GetSection_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetSiteBanner provides a mock function with given fields: ctx
+func (_m *MockService) GetSiteBanner(ctx context.Context) (*core.SiteBanner, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSiteBanner")
+	}
+
+	var r0 *core.SiteBanner
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*core.SiteBanner, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *core.SiteBanner); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.SiteBanner)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_GetSiteBanner_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSiteBanner'
+type MockService_GetSiteBanner_Call struct {
+	*mock.Call
+}
+
+// GetSiteBanner is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockService_Expecter) GetSiteBanner(ctx interface{}) *MockService_GetSiteBanner_Call {
+	return &MockService_GetSiteBanner_Call{Call: _e.mock.On("GetSiteBanner", ctx)}
+}
+
+func (_c *MockService_GetSiteBanner_Call) Run(run func(ctx context.Context)) *MockService_GetSiteBanner_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockService_GetSiteBanner_Call) Return(_a0 *core.SiteBanner, _a1 error) *MockService_GetSiteBanner_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_GetSiteBanner_Call) RunAndReturn(run func(context.Context) (*core.SiteBanner, error)) *MockService_GetSiteBanner_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ImportArchive provides a mock function with given fields: ctx, r
+func (_m *MockService) ImportArchive(ctx context.Context, r io.Reader) (*core.ImportResponse, error) {
+	ret := _m.Called(ctx, r)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ImportArchive")
+	}
+
+	var r0 *core.ImportResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, io.Reader) (*core.ImportResponse, error)); ok {
+		return rf(ctx, r)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, io.Reader) *core.ImportResponse); ok {
+		r0 = rf(ctx, r)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.ImportResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, io.Reader) error); ok {
+		r1 = rf(ctx, r)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_ImportArchive_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ImportArchive'
+type MockService_ImportArchive_Call struct {
+	*mock.Call
+}
+
+// ImportArchive is a helper method to define mock.On call
+//   - ctx context.Context
+//   - r io.Reader
+func (_e *MockService_Expecter) ImportArchive(ctx interface{}, r interface{}) *MockService_ImportArchive_Call {
+	return &MockService_ImportArchive_Call{Call: _e.mock.On("ImportArchive", ctx, r)}
+}
+
+func (_c *MockService_ImportArchive_Call) Run(run func(ctx context.Context, r io.Reader)) *MockService_ImportArchive_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(io.Reader))
+	})
+	return _c
+}
+
+func (_c *MockService_ImportArchive_Call) Return(_a0 *core.ImportResponse, _a1 error) *MockService_ImportArchive_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_ImportArchive_Call) RunAndReturn(run func(context.Context, io.Reader) (*core.ImportResponse, error)) *MockService_ImportArchive_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// IndexRebuildStatus provides a mock function with given fields: ctx
+func (_m *MockService) IndexRebuildStatus(ctx context.Context) (*core.IndexRebuildStatus, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IndexRebuildStatus")
+	}
+
+	var r0 *core.IndexRebuildStatus
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*core.IndexRebuildStatus, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *core.IndexRebuildStatus); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.IndexRebuildStatus)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_IndexRebuildStatus_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IndexRebuildStatus'
+type MockService_IndexRebuildStatus_Call struct {
+	*mock.Call
+}
+
+// IndexRebuildStatus is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockService_Expecter) IndexRebuildStatus(ctx interface{}) *MockService_IndexRebuildStatus_Call {
+	return &MockService_IndexRebuildStatus_Call{Call: _e.mock.On("IndexRebuildStatus", ctx)}
+}
+
+func (_c *MockService_IndexRebuildStatus_Call) Run(run func(ctx context.Context)) *MockService_IndexRebuildStatus_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockService_IndexRebuildStatus_Call) Return(_a0 *core.IndexRebuildStatus, _a1 error) *MockService_IndexRebuildStatus_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_IndexRebuildStatus_Call) RunAndReturn(run func(context.Context) (*core.IndexRebuildStatus, error)) *MockService_IndexRebuildStatus_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// IngestDocuments provides a mock function with given fields: ctx, req
+func (_m *MockService) IngestDocuments(ctx context.Context, req *core.IngestRequest) (*core.IngestResponse, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IngestDocuments")
+	}
+
+	var r0 *core.IngestResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.IngestRequest) (*core.IngestResponse, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *core.IngestRequest) *core.IngestResponse); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.IngestResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *core.IngestRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_IngestDocuments_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IngestDocuments'
+type MockService_IngestDocuments_Call struct {
+	*mock.Call
+}
+
+// IngestDocuments is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req *core.IngestRequest
+func (_e *MockService_Expecter) IngestDocuments(ctx interface{}, req interface{}) *MockService_IngestDocuments_Call {
+	return &MockService_IngestDocuments_Call{Call: _e.mock.On("IngestDocuments", ctx, req)}
+}
+
+func (_c *MockService_IngestDocuments_Call) Run(run func(ctx context.Context, req *core.IngestRequest)) *MockService_IngestDocuments_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*core.IngestRequest))
+	})
+	return _c
+}
+
+func (_c *MockService_IngestDocuments_Call) Return(_a0 *core.IngestResponse, _a1 error) *MockService_IngestDocuments_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_IngestDocuments_Call) RunAndReturn(run func(context.Context, *core.IngestRequest) (*core.IngestResponse, error)) *MockService_IngestDocuments_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LinkReports provides a mock function with given fields: ctx
+func (_m *MockService) LinkReports(ctx context.Context) []core.LinkReport {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LinkReports")
+	}
+
+	var r0 []core.LinkReport
+	if rf, ok := ret.Get(0).(func(context.Context) []core.LinkReport); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]core.LinkReport)
+		}
+	}
+
+	return r0
+}
+
+// MockService_LinkReports_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LinkReports'
+type MockService_LinkReports_Call struct {
+	*mock.Call
+}
+
+// LinkReports is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockService_Expecter) LinkReports(ctx interface{}) *MockService_LinkReports_Call {
+	return &MockService_LinkReports_Call{Call: _e.mock.On("LinkReports", ctx)}
+}
+
+func (_c *MockService_LinkReports_Call) Run(run func(ctx context.Context)) *MockService_LinkReports_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockService_LinkReports_Call) Return(_a0 []core.LinkReport) *MockService_LinkReports_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockService_LinkReports_Call) RunAndReturn(run func(context.Context) []core.LinkReport) *MockService_LinkReports_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LintReports provides a mock function with given fields: ctx
+func (_m *MockService) LintReports(ctx context.Context) []core.LintReport {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LintReports")
+	}
+
+	var r0 []core.LintReport
+	if rf, ok := ret.Get(0).(func(context.Context) []core.LintReport); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]core.LintReport)
+		}
+	}
+
+	return r0
+}
+
+// MockService_LintReports_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LintReports'
+type MockService_LintReports_Call struct {
+	*mock.Call
+}
+
+// LintReports is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockService_Expecter) LintReports(ctx interface{}) *MockService_LintReports_Call {
+	return &MockService_LintReports_Call{Call: _e.mock.On("LintReports", ctx)}
+}
+
+func (_c *MockService_LintReports_Call) Run(run func(ctx context.Context)) *MockService_LintReports_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockService_LintReports_Call) Return(_a0 []core.LintReport) *MockService_LintReports_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockService_LintReports_Call) RunAndReturn(run func(context.Context) []core.LintReport) *MockService_LintReports_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListAPIKeys provides a mock function with given fields: ctx
+func (_m *MockService) ListAPIKeys(ctx context.Context) ([]core.APIKey, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAPIKeys")
+	}
+
+	var r0 []core.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]core.APIKey, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []core.APIKey); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]core.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_ListAPIKeys_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListAPIKeys'
+type MockService_ListAPIKeys_Call struct {
+	*mock.Call
+}
+
+// ListAPIKeys is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockService_Expecter) ListAPIKeys(ctx interface{}) *MockService_ListAPIKeys_Call {
+	return &MockService_ListAPIKeys_Call{Call: _e.mock.On("ListAPIKeys", ctx)}
+}
+
+func (_c *MockService_ListAPIKeys_Call) Run(run func(ctx context.Context)) *MockService_ListAPIKeys_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockService_ListAPIKeys_Call) Return(_a0 []core.APIKey, _a1 error) *MockService_ListAPIKeys_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_ListAPIKeys_Call) RunAndReturn(run func(context.Context) ([]core.APIKey, error)) *MockService_ListAPIKeys_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListDocuments provides a mock function with given fields: ctx, repo
+func (_m *MockService) ListDocuments(ctx context.Context, repo string) ([]core.DocumentMeta, error) {
+	ret := _m.Called(ctx, repo)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListDocuments")
+	}
+
+	var r0 []core.DocumentMeta
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]core.DocumentMeta, error)); ok {
+		return rf(ctx, repo)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []core.DocumentMeta); ok {
+		r0 = rf(ctx, repo)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]core.DocumentMeta)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, repo)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_ListDocuments_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListDocuments'
+type MockService_ListDocuments_Call struct {
+	*mock.Call
+}
+
+// ListDocuments is a helper method to define mock.On call
+//   - ctx context.Context
+//   - repo string
+func (_e *MockService_Expecter) ListDocuments(ctx interface{}, repo interface{}) *MockService_ListDocuments_Call {
+	return &MockService_ListDocuments_Call{Call: _e.mock.On("ListDocuments", ctx, repo)}
+}
+
+func (_c *MockService_ListDocuments_Call) Run(run func(ctx context.Context, repo string)) *MockService_ListDocuments_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockService_ListDocuments_Call) Return(_a0 []core.DocumentMeta, _a1 error) *MockService_ListDocuments_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_ListDocuments_Call) RunAndReturn(run func(context.Context, string) ([]core.DocumentMeta, error)) *MockService_ListDocuments_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListIncidents provides a mock function with given fields: ctx
+func (_m *MockService) ListIncidents(ctx context.Context) []core.Incident {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListIncidents")
+	}
+
+	var r0 []core.Incident
+	if rf, ok := ret.Get(0).(func(context.Context) []core.Incident); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]core.Incident)
+		}
+	}
+
+	return r0
+}
+
+// MockService_ListIncidents_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListIncidents'
+type MockService_ListIncidents_Call struct {
+	*mock.Call
+}
+
+// ListIncidents is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockService_Expecter) ListIncidents(ctx interface{}) *MockService_ListIncidents_Call {
+	return &MockService_ListIncidents_Call{Call: _e.mock.On("ListIncidents", ctx)}
+}
+
+func (_c *MockService_ListIncidents_Call) Run(run func(ctx context.Context)) *MockService_ListIncidents_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockService_ListIncidents_Call) Return(_a0 []core.Incident) *MockService_ListIncidents_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockService_ListIncidents_Call) RunAndReturn(run func(context.Context) []core.Incident) *MockService_ListIncidents_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListRepos provides a mock function with given fields: ctx
+func (_m *MockService) ListRepos(ctx context.Context) ([]core.RepoInfo, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListRepos")
+	}
+
+	var r0 []core.RepoInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]core.RepoInfo, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []core.RepoInfo); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]core.RepoInfo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_ListRepos_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListRepos'
+type MockService_ListRepos_Call struct {
+	*mock.Call
+}
+
+// ListRepos is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockService_Expecter) ListRepos(ctx interface{}) *MockService_ListRepos_Call {
+	return &MockService_ListRepos_Call{Call: _e.mock.On("ListRepos", ctx)}
+}
+
+func (_c *MockService_ListRepos_Call) Run(run func(ctx context.Context)) *MockService_ListRepos_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockService_ListRepos_Call) Return(_a0 []core.RepoInfo, _a1 error) *MockService_ListRepos_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_ListRepos_Call) RunAndReturn(run func(context.Context) ([]core.RepoInfo, error)) *MockService_ListRepos_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LockDocument provides a mock function with given fields: ctx, repo, path, owner, token
+func (_m *MockService) LockDocument(ctx context.Context, repo string, path string, owner string, token string) (*core.DocumentLock, core.Document, error) {
+	ret := _m.Called(ctx, repo, path, owner, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LockDocument")
+	}
+
+	var r0 *core.DocumentLock
+	var r1 core.Document
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string) (*core.DocumentLock, core.Document, error)); ok {
+		return rf(ctx, repo, path, owner, token)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string) *core.DocumentLock); ok {
+		r0 = rf(ctx, repo, path, owner, token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.DocumentLock)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, string) core.Document); ok {
+		r1 = rf(ctx, repo, path, owner, token)
+	} else {
+		r1 = ret.Get(1).(core.Document)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, string, string) error); ok {
+		r2 = rf(ctx, repo, path, owner, token)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockService_LockDocument_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LockDocument'
+type MockService_LockDocument_Call struct {
+	*mock.Call
+}
+
+// LockDocument is a helper method to define mock.On call
+//   - ctx context.Context
+//   - repo string
+//   - path string
+//   - owner string
+//   - token string
+func (_e *MockService_Expecter) LockDocument(ctx interface{}, repo interface{}, path interface{}, owner interface{}, token interface{}) *MockService_LockDocument_Call {
+	return &MockService_LockDocument_Call{Call: _e.mock.On("LockDocument", ctx, repo, path, owner, token)}
+}
+
+func (_c *MockService_LockDocument_Call) Run(run func(ctx context.Context, repo string, path string, owner string, token string)) *MockService_LockDocument_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string), args[4].(string))
+	})
+	return _c
+}
+
+func (_c *MockService_LockDocument_Call) Return(_a0 *core.DocumentLock, _a1 core.Document, _a2 error) *MockService_LockDocument_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *MockService_LockDocument_Call) RunAndReturn(run func(context.Context, string, string, string, string) (*core.DocumentLock, core.Document, error)) *MockService_LockDocument_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MarkNotificationsRead provides a mock function with given fields: ctx, reader
+func (_m *MockService) MarkNotificationsRead(ctx context.Context, reader string) error {
+	ret := _m.Called(ctx, reader)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkNotificationsRead")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, reader)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockService_MarkNotificationsRead_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MarkNotificationsRead'
+type MockService_MarkNotificationsRead_Call struct {
+	*mock.Call
+}
+
+// MarkNotificationsRead is a helper method to define mock.On call
+//   - ctx context.Context
+//   - reader string
+func (_e *MockService_Expecter) MarkNotificationsRead(ctx interface{}, reader interface{}) *MockService_MarkNotificationsRead_Call {
+	return &MockService_MarkNotificationsRead_Call{Call: _e.mock.On("MarkNotificationsRead", ctx, reader)}
+}
+
+func (_c *MockService_MarkNotificationsRead_Call) Run(run func(ctx context.Context, reader string)) *MockService_MarkNotificationsRead_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockService_MarkNotificationsRead_Call) Return(_a0 error) *MockService_MarkNotificationsRead_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockService_MarkNotificationsRead_Call) RunAndReturn(run func(context.Context, string) error) *MockService_MarkNotificationsRead_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+
+// Notifications provides a mock function with given fields: ctx, reader
+func (_m *MockService) Notifications(ctx context.Context, reader string) ([]core.Notification, error) {
+	ret := _m.Called(ctx, reader)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Notifications")
+	}
+
+	var r0 []core.Notification
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]core.Notification, error)); ok {
+		return rf(ctx, reader)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []core.Notification); ok {
+		r0 = rf(ctx, reader)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]core.Notification)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, reader)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_Notifications_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Notifications'
+type MockService_Notifications_Call struct {
+	*mock.Call
+}
+
+// Notifications is a helper method to define mock.On call
+//   - ctx context.Context
+//   - reader string
+func (_e *MockService_Expecter) Notifications(ctx interface{}, reader interface{}) *MockService_Notifications_Call {
+	return &MockService_Notifications_Call{Call: _e.mock.On("Notifications", ctx, reader)}
+}
+
+func (_c *MockService_Notifications_Call) Run(run func(ctx context.Context, reader string)) *MockService_Notifications_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockService_Notifications_Call) Return(_a0 []core.Notification, _a1 error) *MockService_Notifications_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_Notifications_Call) RunAndReturn(run func(context.Context, string) ([]core.Notification, error)) *MockService_Notifications_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+
+// PageHighlights provides a mock function with given fields: ctx, repo, docs
+func (_m *MockService) PageHighlights(ctx context.Context, repo string, docs []core.DocumentMeta) *core.PageHighlights {
+	ret := _m.Called(ctx, repo, docs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PageHighlights")
+	}
+
+	var r0 *core.PageHighlights
+	if rf, ok := ret.Get(0).(func(context.Context, string, []core.DocumentMeta) *core.PageHighlights); ok {
+		r0 = rf(ctx, repo, docs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.PageHighlights)
+		}
+	}
+
+	return r0
+}
+
+// MockService_PageHighlights_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PageHighlights'
+type MockService_PageHighlights_Call struct {
+	*mock.Call
+}
+
+// PageHighlights is a helper method to define mock.On call
+//   - ctx context.Context
+//   - repo string
+//   - docs []core.DocumentMeta
+func (_e *MockService_Expecter) PageHighlights(ctx interface{}, repo interface{}, docs interface{}) *MockService_PageHighlights_Call {
+	return &MockService_PageHighlights_Call{Call: _e.mock.On("PageHighlights", ctx, repo, docs)}
+}
+
+func (_c *MockService_PageHighlights_Call) Run(run func(ctx context.Context, repo string, docs []core.DocumentMeta)) *MockService_PageHighlights_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].([]core.DocumentMeta))
+	})
+	return _c
+}
+
+func (_c *MockService_PageHighlights_Call) Return(_a0 *core.PageHighlights) *MockService_PageHighlights_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockService_PageHighlights_Call) RunAndReturn(run func(context.Context, string, []core.DocumentMeta) *core.PageHighlights) *MockService_PageHighlights_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Presence provides a mock function with given fields: ctx, repo, path
+func (_m *MockService) Presence(ctx context.Context, repo string, path string) (*core.Incident, []core.Viewer, error) {
+	ret := _m.Called(ctx, repo, path)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Presence")
+	}
+
+	var r0 *core.Incident
+	var r1 []core.Viewer
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*core.Incident, []core.Viewer, error)); ok {
+		return rf(ctx, repo, path)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *core.Incident); ok {
+		r0 = rf(ctx, repo, path)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.Incident)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) []core.Viewer); ok {
+		r1 = rf(ctx, repo, path)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]core.Viewer)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string) error); ok {
+		r2 = rf(ctx, repo, path)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockService_Presence_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Presence'
+type MockService_Presence_Call struct {
+	*mock.Call
+}
+
+// Presence is a helper method to define mock.On call
+//   - ctx context.Context
+//   - repo string
+//   - path string
+func (_e *MockService_Expecter) Presence(ctx interface{}, repo interface{}, path interface{}) *MockService_Presence_Call {
+	return &MockService_Presence_Call{Call: _e.mock.On("Presence", ctx, repo, path)}
+}
+
+func (_c *MockService_Presence_Call) Run(run func(ctx context.Context, repo string, path string)) *MockService_Presence_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockService_Presence_Call) Return(_a0 *core.Incident, _a1 []core.Viewer, _a2 error) *MockService_Presence_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *MockService_Presence_Call) RunAndReturn(run func(context.Context, string, string) (*core.Incident, []core.Viewer, error)) *MockService_Presence_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PreviewEdit provides a mock function with given fields: ctx, repo, path, content
+func (_m *MockService) PreviewEdit(ctx context.Context, repo string, path string, content string) ([]byte, []core.Heading, error) {
+	ret := _m.Called(ctx, repo, path, content)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PreviewEdit")
+	}
+
+	var r0 []byte
+	var r1 []core.Heading
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) ([]byte, []core.Heading, error)); ok {
+		return rf(ctx, repo, path, content)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) []byte); ok {
+		r0 = rf(ctx, repo, path, content)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) []core.Heading); ok {
+		r1 = rf(ctx, repo, path, content)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]core.Heading)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, string) error); ok {
+		r2 = rf(ctx, repo, path, content)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockService_PreviewEdit_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PreviewEdit'
+type MockService_PreviewEdit_Call struct {
+	*mock.Call
+}
+
+// PreviewEdit is a helper method to define mock.On call
+//   - ctx context.Context
+//   - repo string
+//   - path string
+//   - content string
+func (_e *MockService_Expecter) PreviewEdit(ctx interface{}, repo interface{}, path interface{}, content interface{}) *MockService_PreviewEdit_Call {
+	return &MockService_PreviewEdit_Call{Call: _e.mock.On("PreviewEdit", ctx, repo, path, content)}
+}
+
+func (_c *MockService_PreviewEdit_Call) Run(run func(ctx context.Context, repo string, path string, content string)) *MockService_PreviewEdit_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockService_PreviewEdit_Call) Return(_a0 []byte, _a1 []core.Heading, _a2 error) *MockService_PreviewEdit_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *MockService_PreviewEdit_Call) RunAndReturn(run func(context.Context, string, string, string) ([]byte, []core.Heading, error)) *MockService_PreviewEdit_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// QuickSearch provides a mock function with given fields: ctx, query, repos, limit
+func (_m *MockService) QuickSearch(ctx context.Context, query string, repos []string, limit int) ([]core.QuickResult, error) {
+	ret := _m.Called(ctx, query, repos, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for QuickSearch")
+	}
+
+	var r0 []core.QuickResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string, int) ([]core.QuickResult, error)); ok {
+		return rf(ctx, query, repos, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string, int) []core.QuickResult); ok {
+		r0 = rf(ctx, query, repos, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]core.QuickResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string, int) error); ok {
+		r1 = rf(ctx, query, repos, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_QuickSearch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'QuickSearch'
+type MockService_QuickSearch_Call struct {
+	*mock.Call
+}
+
+// QuickSearch is a helper method to define mock.On call
+//   - ctx context.Context
+//   - query string
+//   - repos []string
+//   - limit int
+func (_e *MockService_Expecter) QuickSearch(ctx interface{}, query interface{}, repos interface{}, limit interface{}) *MockService_QuickSearch_Call {
+	return &MockService_QuickSearch_Call{Call: _e.mock.On("QuickSearch", ctx, query, repos, limit)}
+}
+
+func (_c *MockService_QuickSearch_Call) Run(run func(ctx context.Context, query string, repos []string, limit int)) *MockService_QuickSearch_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].([]string), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *MockService_QuickSearch_Call) Return(_a0 []core.QuickResult, _a1 error) *MockService_QuickSearch_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_QuickSearch_Call) RunAndReturn(run func(context.Context, string, []string, int) ([]core.QuickResult, error)) *MockService_QuickSearch_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RankingExperiment provides a mock function with given fields: 
+func (_m *MockService) RankingExperiment() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for RankingExperiment")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// MockService_RankingExperiment_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RankingExperiment'
+type MockService_RankingExperiment_Call struct {
+	*mock.Call
+}
+
+// RankingExperiment is a helper method to define mock.On call
+func (_e *MockService_Expecter) RankingExperiment() *MockService_RankingExperiment_Call {
+	return &MockService_RankingExperiment_Call{Call: _e.mock.On("RankingExperiment")}
+}
+
+func (_c *MockService_RankingExperiment_Call) Run(run func()) *MockService_RankingExperiment_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockService_RankingExperiment_Call) Return(_a0 string) *MockService_RankingExperiment_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockService_RankingExperiment_Call) RunAndReturn(run func() string) *MockService_RankingExperiment_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RecordSearchClick provides a mock function with given fields: session, rank
+func (_m *MockService) RecordSearchClick(session string, rank int) {
+	_m.Called(session, rank)
+}
+
+// MockService_RecordSearchClick_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RecordSearchClick'
+type MockService_RecordSearchClick_Call struct {
+	*mock.Call
+}
+
+// RecordSearchClick is a helper method to define mock.On call
+//   - session string
+//   - rank int
+func (_e *MockService_Expecter) RecordSearchClick(session interface{}, rank interface{}) *MockService_RecordSearchClick_Call {
+	return &MockService_RecordSearchClick_Call{Call: _e.mock.On("RecordSearchClick", session, rank)}
+}
+
+func (_c *MockService_RecordSearchClick_Call) Run(run func(session string, rank int)) *MockService_RecordSearchClick_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockService_RecordSearchClick_Call) Return() *MockService_RecordSearchClick_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockService_RecordSearchClick_Call) RunAndReturn(run func(string, int)) *MockService_RecordSearchClick_Call {
+	_c.Run(run)
+	return _c
+}
+
+// RenameRepo provides a mock function with given fields: ctx, req
+func (_m *MockService) RenameRepo(ctx context.Context, req core.RenameRepoRequest) (*core.RenameRepoResponse, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RenameRepo")
+	}
+
+	var r0 *core.RenameRepoResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, core.RenameRepoRequest) (*core.RenameRepoResponse, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, core.RenameRepoRequest) *core.RenameRepoResponse); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.RenameRepoResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, core.RenameRepoRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_RenameRepo_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RenameRepo'
+type MockService_RenameRepo_Call struct {
+	*mock.Call
+}
+
+// RenameRepo is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req core.RenameRepoRequest
+func (_e *MockService_Expecter) RenameRepo(ctx interface{}, req interface{}) *MockService_RenameRepo_Call {
+	return &MockService_RenameRepo_Call{Call: _e.mock.On("RenameRepo", ctx, req)}
+}
+
+func (_c *MockService_RenameRepo_Call) Run(run func(ctx context.Context, req core.RenameRepoRequest)) *MockService_RenameRepo_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(core.RenameRepoRequest))
+	})
+	return _c
+}
+
+func (_c *MockService_RenameRepo_Call) Return(_a0 *core.RenameRepoResponse, _a1 error) *MockService_RenameRepo_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_RenameRepo_Call) RunAndReturn(run func(context.Context, core.RenameRepoRequest) (*core.RenameRepoResponse, error)) *MockService_RenameRepo_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RenderReport provides a mock function with given fields: ctx
+func (_m *MockService) RenderReport(ctx context.Context) (*core.RenderReport, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RenderReport")
+	}
+
+	var r0 *core.RenderReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*core.RenderReport, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *core.RenderReport); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.RenderReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_RenderReport_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RenderReport'
+type MockService_RenderReport_Call struct {
+	*mock.Call
+}
+
+// RenderReport is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockService_Expecter) RenderReport(ctx interface{}) *MockService_RenderReport_Call {
+	return &MockService_RenderReport_Call{Call: _e.mock.On("RenderReport", ctx)}
+}
+
+func (_c *MockService_RenderReport_Call) Run(run func(ctx context.Context)) *MockService_RenderReport_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockService_RenderReport_Call) Return(_a0 *core.RenderReport, _a1 error) *MockService_RenderReport_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_RenderReport_Call) RunAndReturn(run func(context.Context) (*core.RenderReport, error)) *MockService_RenderReport_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Republish provides a mock function with given fields: ctx, repo
+func (_m *MockService) Republish(ctx context.Context, repo string) error {
+	ret := _m.Called(ctx, repo)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Republish")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, repo)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockService_Republish_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Republish'
+type MockService_Republish_Call struct {
+	*mock.Call
+}
+
+// Republish is a helper method to define mock.On call
+//   - ctx context.Context
+//   - repo string
+func (_e *MockService_Expecter) Republish(ctx interface{}, repo interface{}) *MockService_Republish_Call {
+	return &MockService_Republish_Call{Call: _e.mock.On("Republish", ctx, repo)}
+}
+
+func (_c *MockService_Republish_Call) Run(run func(ctx context.Context, repo string)) *MockService_Republish_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockService_Republish_Call) Return(_a0 error) *MockService_Republish_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockService_Republish_Call) RunAndReturn(run func(context.Context, string) error) *MockService_Republish_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ResolveRedirect provides a mock function with given fields: ctx, repo, path
+func (_m *MockService) ResolveRedirect(ctx context.Context, repo string, path string) (string, string, bool) {
+	ret := _m.Called(ctx, repo, path)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResolveRedirect")
+	}
+
+	var r0 string
+	var r1 string
+	var r2 bool
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (string, string, bool)); ok {
+		return rf(ctx, repo, path)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) string); ok {
+		r0 = rf(ctx, repo, path)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) string); ok {
+		r1 = rf(ctx, repo, path)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string) bool); ok {
+		r2 = rf(ctx, repo, path)
+	} else {
+		r2 = ret.Get(2).(bool)
+	}
+
+	return r0, r1, r2
+}
+
+// MockService_ResolveRedirect_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResolveRedirect'
+type MockService_ResolveRedirect_Call struct {
+	*mock.Call
+}
+
+// ResolveRedirect is a helper method to define mock.On call
+//   - ctx context.Context
+//   - repo string
+//   - path string
+func (_e *MockService_Expecter) ResolveRedirect(ctx interface{}, repo interface{}, path interface{}) *MockService_ResolveRedirect_Call {
+	return &MockService_ResolveRedirect_Call{Call: _e.mock.On("ResolveRedirect", ctx, repo, path)}
+}
+
+func (_c *MockService_ResolveRedirect_Call) Run(run func(ctx context.Context, repo string, path string)) *MockService_ResolveRedirect_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockService_ResolveRedirect_Call) Return(_a0 string, _a1 string, _a2 bool) *MockService_ResolveRedirect_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *MockService_ResolveRedirect_Call) RunAndReturn(run func(context.Context, string, string) (string, string, bool)) *MockService_ResolveRedirect_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeAPIKey provides a mock function with given fields: ctx, id
+func (_m *MockService) RevokeAPIKey(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeAPIKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockService_RevokeAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeAPIKey'
+type MockService_RevokeAPIKey_Call struct {
+	*mock.Call
+}
+
+// RevokeAPIKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *MockService_Expecter) RevokeAPIKey(ctx interface{}, id interface{}) *MockService_RevokeAPIKey_Call {
+	return &MockService_RevokeAPIKey_Call{Call: _e.mock.On("RevokeAPIKey", ctx, id)}
+}
+
+func (_c *MockService_RevokeAPIKey_Call) Run(run func(ctx context.Context, id string)) *MockService_RevokeAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockService_RevokeAPIKey_Call) Return(_a0 error) *MockService_RevokeAPIKey_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockService_RevokeAPIKey_Call) RunAndReturn(run func(context.Context, string) error) *MockService_RevokeAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RotateAPIKey provides a mock function with given fields: ctx, id, overlap
+func (_m *MockService) RotateAPIKey(ctx context.Context, id string, overlap time.Duration) (*core.RotateAPIKeyResponse, error) {
+	ret := _m.Called(ctx, id, overlap)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RotateAPIKey")
+	}
+
+	var r0 *core.RotateAPIKeyResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Duration) (*core.RotateAPIKeyResponse, error)); ok {
+		return rf(ctx, id, overlap)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Duration) *core.RotateAPIKeyResponse); ok {
+		r0 = rf(ctx, id, overlap)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.RotateAPIKeyResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Duration) error); ok {
+		r1 = rf(ctx, id, overlap)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_RotateAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RotateAPIKey'
+type MockService_RotateAPIKey_Call struct {
+	*mock.Call
+}
+
+// RotateAPIKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+//   - overlap time.Duration
+func (_e *MockService_Expecter) RotateAPIKey(ctx interface{}, id interface{}, overlap interface{}) *MockService_RotateAPIKey_Call {
+	return &MockService_RotateAPIKey_Call{Call: _e.mock.On("RotateAPIKey", ctx, id, overlap)}
+}
+
+func (_c *MockService_RotateAPIKey_Call) Run(run func(ctx context.Context, id string, overlap time.Duration)) *MockService_RotateAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *MockService_RotateAPIKey_Call) Return(_a0 *core.RotateAPIKeyResponse, _a1 error) *MockService_RotateAPIKey_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_RotateAPIKey_Call) RunAndReturn(run func(context.Context, string, time.Duration) (*core.RotateAPIKeyResponse, error)) *MockService_RotateAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveEdit provides a mock function with given fields: ctx, req
+func (_m *MockService) SaveEdit(ctx context.Context, req core.EditRequest) (*core.EditResponse, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveEdit")
+	}
+
+	var r0 *core.EditResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, core.EditRequest) (*core.EditResponse, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, core.EditRequest) *core.EditResponse); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.EditResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, core.EditRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_SaveEdit_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveEdit'
+type MockService_SaveEdit_Call struct {
+	*mock.Call
+}
+
+// SaveEdit is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req core.EditRequest
+func (_e *MockService_Expecter) SaveEdit(ctx interface{}, req interface{}) *MockService_SaveEdit_Call {
+	return &MockService_SaveEdit_Call{Call: _e.mock.On("SaveEdit", ctx, req)}
+}
+
+func (_c *MockService_SaveEdit_Call) Run(run func(ctx context.Context, req core.EditRequest)) *MockService_SaveEdit_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(core.EditRequest))
+	})
+	return _c
+}
+
+func (_c *MockService_SaveEdit_Call) Return(_a0 *core.EditResponse, _a1 error) *MockService_SaveEdit_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_SaveEdit_Call) RunAndReturn(run func(context.Context, core.EditRequest) (*core.EditResponse, error)) *MockService_SaveEdit_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveSearch provides a mock function with given fields: ctx, req
+func (_m *MockService) SaveSearch(ctx context.Context, req core.SavedSearchRequest) (*core.SavedSearch, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveSearch")
+	}
+
+	var r0 *core.SavedSearch
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, core.SavedSearchRequest) (*core.SavedSearch, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, core.SavedSearchRequest) *core.SavedSearch); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.SavedSearch)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, core.SavedSearchRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_SaveSearch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveSearch'
+type MockService_SaveSearch_Call struct {
+	*mock.Call
+}
+
+// SaveSearch is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req core.SavedSearchRequest
+func (_e *MockService_Expecter) SaveSearch(ctx interface{}, req interface{}) *MockService_SaveSearch_Call {
+	return &MockService_SaveSearch_Call{Call: _e.mock.On("SaveSearch", ctx, req)}
+}
+
+func (_c *MockService_SaveSearch_Call) Run(run func(ctx context.Context, req core.SavedSearchRequest)) *MockService_SaveSearch_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(core.SavedSearchRequest))
+	})
+	return _c
+}
+
+func (_c *MockService_SaveSearch_Call) Return(_a0 *core.SavedSearch, _a1 error) *MockService_SaveSearch_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_SaveSearch_Call) RunAndReturn(run func(context.Context, core.SavedSearchRequest) (*core.SavedSearch, error)) *MockService_SaveSearch_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SearchDocs provides a mock function with given fields: ctx, query, opts
+func (_m *MockService) SearchDocs(ctx context.Context, query string, opts core.SearchOpts) (*core.SearchResults, error) {
+	ret := _m.Called(ctx, query, opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchDocs")
+	}
+
+	var r0 *core.SearchResults
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, core.SearchOpts) (*core.SearchResults, error)); ok {
+		return rf(ctx, query, opts)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, core.SearchOpts) *core.SearchResults); ok {
+		r0 = rf(ctx, query, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.SearchResults)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, core.SearchOpts) error); ok {
+		r1 = rf(ctx, query, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_SearchDocs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SearchDocs'
+type MockService_SearchDocs_Call struct {
+	*mock.Call
+}
+
+// SearchDocs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - query string
+//   - opts core.SearchOpts
+func (_e *MockService_Expecter) SearchDocs(ctx interface{}, query interface{}, opts interface{}) *MockService_SearchDocs_Call {
+	return &MockService_SearchDocs_Call{Call: _e.mock.On("SearchDocs", ctx, query, opts)}
+}
+
+func (_c *MockService_SearchDocs_Call) Run(run func(ctx context.Context, query string, opts core.SearchOpts)) *MockService_SearchDocs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(core.SearchOpts))
+	})
+	return _c
+}
+
+func (_c *MockService_SearchDocs_Call) Return(_a0 *core.SearchResults, _a1 error) *MockService_SearchDocs_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_SearchDocs_Call) RunAndReturn(run func(context.Context, string, core.SearchOpts) (*core.SearchResults, error)) *MockService_SearchDocs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetRepoHighlights provides a mock function with given fields: ctx, req
+func (_m *MockService) SetRepoHighlights(ctx context.Context, req core.RepoHighlightsRequest) (*core.RepoHighlights, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetRepoHighlights")
+	}
+
+	var r0 *core.RepoHighlights
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, core.RepoHighlightsRequest) (*core.RepoHighlights, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, core.RepoHighlightsRequest) *core.RepoHighlights); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.RepoHighlights)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, core.RepoHighlightsRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_SetRepoHighlights_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetRepoHighlights'
+type MockService_SetRepoHighlights_Call struct {
+	*mock.Call
+}
+
+// SetRepoHighlights is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req core.RepoHighlightsRequest
+func (_e *MockService_Expecter) SetRepoHighlights(ctx interface{}, req interface{}) *MockService_SetRepoHighlights_Call {
+	return &MockService_SetRepoHighlights_Call{Call: _e.mock.On("SetRepoHighlights", ctx, req)}
+}
+
+func (_c *MockService_SetRepoHighlights_Call) Run(run func(ctx context.Context, req core.RepoHighlightsRequest)) *MockService_SetRepoHighlights_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(core.RepoHighlightsRequest))
+	})
+	return _c
+}
+
+func (_c *MockService_SetRepoHighlights_Call) Return(_a0 *core.RepoHighlights, _a1 error) *MockService_SetRepoHighlights_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_SetRepoHighlights_Call) RunAndReturn(run func(context.Context, core.RepoHighlightsRequest) (*core.RepoHighlights, error)) *MockService_SetRepoHighlights_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetSiteBanner provides a mock function with given fields: ctx, banner
+func (_m *MockService) SetSiteBanner(ctx context.Context, banner core.SiteBanner) (*core.SiteBanner, error) {
+	ret := _m.Called(ctx, banner)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetSiteBanner")
+	}
+
+	var r0 *core.SiteBanner
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, core.SiteBanner) (*core.SiteBanner, error)); ok {
+		return rf(ctx, banner)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, core.SiteBanner) *core.SiteBanner); ok {
+		r0 = rf(ctx, banner)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.SiteBanner)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, core.SiteBanner) error); ok {
+		r1 = rf(ctx, banner)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_SetSiteBanner_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetSiteBanner'
+type MockService_SetSiteBanner_Call struct {
+	*mock.Call
+}
+
+// SetSiteBanner is a helper method to define mock.On call
+//   - ctx context.Context
+//   - banner core.SiteBanner
+func (_e *MockService_Expecter) SetSiteBanner(ctx interface{}, banner interface{}) *MockService_SetSiteBanner_Call {
+	return &MockService_SetSiteBanner_Call{Call: _e.mock.On("SetSiteBanner", ctx, banner)}
+}
+
+func (_c *MockService_SetSiteBanner_Call) Run(run func(ctx context.Context, banner core.SiteBanner)) *MockService_SetSiteBanner_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(core.SiteBanner))
+	})
+	return _c
+}
+
+func (_c *MockService_SetSiteBanner_Call) Return(_a0 *core.SiteBanner, _a1 error) *MockService_SetSiteBanner_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_SetSiteBanner_Call) RunAndReturn(run func(context.Context, core.SiteBanner) (*core.SiteBanner, error)) *MockService_SetSiteBanner_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// StartIncident provides a mock function with given fields: ctx, repo, path, name
+func (_m *MockService) StartIncident(ctx context.Context, repo string, path string, name string) (*core.Incident, error) {
+	ret := _m.Called(ctx, repo, path, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StartIncident")
+	}
+
+	var r0 *core.Incident
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (*core.Incident, error)); ok {
+		return rf(ctx, repo, path, name)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) *core.Incident); ok {
+		r0 = rf(ctx, repo, path, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.Incident)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, repo, path, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_StartIncident_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'StartIncident'
+type MockService_StartIncident_Call struct {
+	*mock.Call
+}
+
+// StartIncident is a helper method to define mock.On call
+//   - ctx context.Context
+//   - repo string
+//   - path string
+//   - name string
+func (_e *MockService_Expecter) StartIncident(ctx interface{}, repo interface{}, path interface{}, name interface{}) *MockService_StartIncident_Call {
+	return &MockService_StartIncident_Call{Call: _e.mock.On("StartIncident", ctx, repo, path, name)}
+}
+
+func (_c *MockService_StartIncident_Call) Run(run func(ctx context.Context, repo string, path string, name string)) *MockService_StartIncident_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockService_StartIncident_Call) Return(_a0 *core.Incident, _a1 error) *MockService_StartIncident_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_StartIncident_Call) RunAndReturn(run func(context.Context, string, string, string) (*core.Incident, error)) *MockService_StartIncident_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// StartIndexRebuild provides a mock function with given fields: ctx, req
+func (_m *MockService) StartIndexRebuild(ctx context.Context, req core.IndexRebuildRequest) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StartIndexRebuild")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, core.IndexRebuildRequest) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockService_StartIndexRebuild_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'StartIndexRebuild'
+type MockService_StartIndexRebuild_Call struct {
+	*mock.Call
+}
+
+// StartIndexRebuild is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req core.IndexRebuildRequest
+func (_e *MockService_Expecter) StartIndexRebuild(ctx interface{}, req interface{}) *MockService_StartIndexRebuild_Call {
+	return &MockService_StartIndexRebuild_Call{Call: _e.mock.On("StartIndexRebuild", ctx, req)}
+}
+
+func (_c *MockService_StartIndexRebuild_Call) Run(run func(ctx context.Context, req core.IndexRebuildRequest)) *MockService_StartIndexRebuild_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(core.IndexRebuildRequest))
+	})
+	return _c
+}
+
+func (_c *MockService_StartIndexRebuild_Call) Return(_a0 error) *MockService_StartIndexRebuild_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockService_StartIndexRebuild_Call) RunAndReturn(run func(context.Context, core.IndexRebuildRequest) error) *MockService_StartIndexRebuild_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// StartReindex provides a mock function with given fields: ctx
+func (_m *MockService) StartReindex(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StartReindex")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockService_StartReindex_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'StartReindex'
+type MockService_StartReindex_Call struct {
+	*mock.Call
+}
+
+// StartReindex is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockService_Expecter) StartReindex(ctx interface{}) *MockService_StartReindex_Call {
+	return &MockService_StartReindex_Call{Call: _e.mock.On("StartReindex", ctx)}
+}
+
+func (_c *MockService_StartReindex_Call) Run(run func(ctx context.Context)) *MockService_StartReindex_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockService_StartReindex_Call) Return(_a0 error) *MockService_StartReindex_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockService_StartReindex_Call) RunAndReturn(run func(context.Context) error) *MockService_StartReindex_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Stats provides a mock function with given fields: ctx
+func (_m *MockService) Stats(ctx context.Context) (*core.Stats, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Stats")
+	}
+
+	var r0 *core.Stats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*core.Stats, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *core.Stats); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.Stats)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_Stats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Stats'
+type MockService_Stats_Call struct {
+	*mock.Call
+}
+
+// Stats is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockService_Expecter) Stats(ctx interface{}) *MockService_Stats_Call {
+	return &MockService_Stats_Call{Call: _e.mock.On("Stats", ctx)}
+}
+
+func (_c *MockService_Stats_Call) Run(run func(ctx context.Context)) *MockService_Stats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockService_Stats_Call) Return(_a0 *core.Stats, _a1 error) *MockService_Stats_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_Stats_Call) RunAndReturn(run func(context.Context) (*core.Stats, error)) *MockService_Stats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Subscribe provides a mock function with given fields: ctx
+func (_m *MockService) Subscribe(ctx context.Context) <-chan core.DocumentEvent {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Subscribe")
+	}
+
+	var r0 <-chan core.DocumentEvent
+	if rf, ok := ret.Get(0).(func(context.Context) <-chan core.DocumentEvent); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan core.DocumentEvent)
+		}
+	}
+
+	return r0
+}
+
+// MockService_Subscribe_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Subscribe'
+type MockService_Subscribe_Call struct {
+	*mock.Call
+}
+
+// Subscribe is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockService_Expecter) Subscribe(ctx interface{}) *MockService_Subscribe_Call {
+	return &MockService_Subscribe_Call{Call: _e.mock.On("Subscribe", ctx)}
+}
+
+func (_c *MockService_Subscribe_Call) Run(run func(ctx context.Context)) *MockService_Subscribe_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockService_Subscribe_Call) Return(_a0 <-chan core.DocumentEvent) *MockService_Subscribe_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockService_Subscribe_Call) RunAndReturn(run func(context.Context) <-chan core.DocumentEvent) *MockService_Subscribe_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SuggestEdit provides a mock function with given fields: ctx, sg
+func (_m *MockService) SuggestEdit(ctx context.Context, sg core.Suggestion) (*core.SuggestionResponse, error) {
+	ret := _m.Called(ctx, sg)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SuggestEdit")
+	}
+
+	var r0 *core.SuggestionResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, core.Suggestion) (*core.SuggestionResponse, error)); ok {
+		return rf(ctx, sg)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, core.Suggestion) *core.SuggestionResponse); ok {
+		r0 = rf(ctx, sg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.SuggestionResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, core.Suggestion) error); ok {
+		r1 = rf(ctx, sg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_SuggestEdit_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SuggestEdit'
+type MockService_SuggestEdit_Call struct {
+	*mock.Call
+}
+
+// SuggestEdit is a helper method to define mock.On call
+//   - ctx context.Context
+//   - sg core.Suggestion
+func (_e *MockService_Expecter) SuggestEdit(ctx interface{}, sg interface{}) *MockService_SuggestEdit_Call {
+	return &MockService_SuggestEdit_Call{Call: _e.mock.On("SuggestEdit", ctx, sg)}
+}
+
+func (_c *MockService_SuggestEdit_Call) Run(run func(ctx context.Context, sg core.Suggestion)) *MockService_SuggestEdit_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(core.Suggestion))
+	})
+	return _c
+}
+
+func (_c *MockService_SuggestEdit_Call) Return(_a0 *core.SuggestionResponse, _a1 error) *MockService_SuggestEdit_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_SuggestEdit_Call) RunAndReturn(run func(context.Context, core.Suggestion) (*core.SuggestionResponse, error)) *MockService_SuggestEdit_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SuggestSearch provides a mock function with given fields: ctx, prefix, limit
+func (_m *MockService) SuggestSearch(ctx context.Context, prefix string, limit int) ([]core.SearchSuggestion, error) {
+	ret := _m.Called(ctx, prefix, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SuggestSearch")
+	}
+
+	var r0 []core.SearchSuggestion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) ([]core.SearchSuggestion, error)); ok {
+		return rf(ctx, prefix, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) []core.SearchSuggestion); ok {
+		r0 = rf(ctx, prefix, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]core.SearchSuggestion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, prefix, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_SuggestSearch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SuggestSearch'
+type MockService_SuggestSearch_Call struct {
+	*mock.Call
+}
+
+// SuggestSearch is a helper method to define mock.On call
+//   - ctx context.Context
+//   - prefix string
+//   - limit int
+func (_e *MockService_Expecter) SuggestSearch(ctx interface{}, prefix interface{}, limit interface{}) *MockService_SuggestSearch_Call {
+	return &MockService_SuggestSearch_Call{Call: _e.mock.On("SuggestSearch", ctx, prefix, limit)}
+}
+
+func (_c *MockService_SuggestSearch_Call) Run(run func(ctx context.Context, prefix string, limit int)) *MockService_SuggestSearch_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockService_SuggestSearch_Call) Return(_a0 []core.SearchSuggestion, _a1 error) *MockService_SuggestSearch_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_SuggestSearch_Call) RunAndReturn(run func(context.Context, string, int) ([]core.SearchSuggestion, error)) *MockService_SuggestSearch_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Suggestible provides a mock function with given fields: repo
+func (_m *MockService) Suggestible(repo string) bool {
+	ret := _m.Called(repo)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Suggestible")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = rf(repo)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// MockService_Suggestible_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Suggestible'
+type MockService_Suggestible_Call struct {
+	*mock.Call
+}
+
+// Suggestible is a helper method to define mock.On call
+//   - repo string
+func (_e *MockService_Expecter) Suggestible(repo interface{}) *MockService_Suggestible_Call {
+	return &MockService_Suggestible_Call{Call: _e.mock.On("Suggestible", repo)}
+}
+
+func (_c *MockService_Suggestible_Call) Run(run func(repo string)) *MockService_Suggestible_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockService_Suggestible_Call) Return(_a0 bool) *MockService_Suggestible_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockService_Suggestible_Call) RunAndReturn(run func(string) bool) *MockService_Suggestible_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UnlockDocument provides a mock function with given fields: ctx, repo, path, token
+func (_m *MockService) UnlockDocument(ctx context.Context, repo string, path string, token string) error {
+	ret := _m.Called(ctx, repo, path, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UnlockDocument")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, repo, path, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockService_UnlockDocument_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UnlockDocument'
+type MockService_UnlockDocument_Call struct {
+	*mock.Call
+}
+
+// UnlockDocument is a helper method to define mock.On call
+//   - ctx context.Context
+//   - repo string
+//   - path string
+//   - token string
+func (_e *MockService_Expecter) UnlockDocument(ctx interface{}, repo interface{}, path interface{}, token interface{}) *MockService_UnlockDocument_Call {
+	return &MockService_UnlockDocument_Call{Call: _e.mock.On("UnlockDocument", ctx, repo, path, token)}
+}
+
+func (_c *MockService_UnlockDocument_Call) Run(run func(ctx context.Context, repo string, path string, token string)) *MockService_UnlockDocument_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockService_UnlockDocument_Call) Return(_a0 error) *MockService_UnlockDocument_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockService_UnlockDocument_Call) RunAndReturn(run func(context.Context, string, string, string) error) *MockService_UnlockDocument_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdatePresence provides a mock function with given fields: ctx, repo, path, viewer, leave
+func (_m *MockService) UpdatePresence(ctx context.Context, repo string, path string, viewer core.Viewer, leave bool) error {
+	ret := _m.Called(ctx, repo, path, viewer, leave)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdatePresence")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, core.Viewer, bool) error); ok {
+		r0 = rf(ctx, repo, path, viewer, leave)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockService_UpdatePresence_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdatePresence'
+type MockService_UpdatePresence_Call struct {
+	*mock.Call
+}
+
+// UpdatePresence is a helper method to define mock.On call
+//   - ctx context.Context
+//   - repo string
+//   - path string
+//   - viewer core.Viewer
+//   - leave bool
+func (_e *MockService_Expecter) UpdatePresence(ctx interface{}, repo interface{}, path interface{}, viewer interface{}, leave interface{}) *MockService_UpdatePresence_Call {
+	return &MockService_UpdatePresence_Call{Call: _e.mock.On("UpdatePresence", ctx, repo, path, viewer, leave)}
+}
+
+func (_c *MockService_UpdatePresence_Call) Run(run func(ctx context.Context, repo string, path string, viewer core.Viewer, leave bool)) *MockService_UpdatePresence_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(core.Viewer), args[4].(bool))
+	})
+	return _c
+}
+
+func (_c *MockService_UpdatePresence_Call) Return(_a0 error) *MockService_UpdatePresence_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockService_UpdatePresence_Call) RunAndReturn(run func(context.Context, string, string, core.Viewer, bool) error) *MockService_UpdatePresence_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// VerifyAPIKey provides a mock function with given fields: ctx, token
+func (_m *MockService) VerifyAPIKey(ctx context.Context, token string) bool {
+	ret := _m.Called(ctx, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VerifyAPIKey")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, token)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// MockService_VerifyAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'VerifyAPIKey'
+type MockService_VerifyAPIKey_Call struct {
+	*mock.Call
+}
+
+// VerifyAPIKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - token string
+func (_e *MockService_Expecter) VerifyAPIKey(ctx interface{}, token interface{}) *MockService_VerifyAPIKey_Call {
+	return &MockService_VerifyAPIKey_Call{Call: _e.mock.On("VerifyAPIKey", ctx, token)}
+}
+
+func (_c *MockService_VerifyAPIKey_Call) Run(run func(ctx context.Context, token string)) *MockService_VerifyAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockService_VerifyAPIKey_Call) Return(_a0 bool) *MockService_VerifyAPIKey_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockService_VerifyAPIKey_Call) RunAndReturn(run func(context.Context, string) bool) *MockService_VerifyAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// VerifyMigration provides a mock function with given fields: ctx, req
+func (_m *MockService) VerifyMigration(ctx context.Context, req core.MigrationVerifyRequest) (*core.MigrationReport, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VerifyMigration")
+	}
+
+	var r0 *core.MigrationReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, core.MigrationVerifyRequest) (*core.MigrationReport, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, core.MigrationVerifyRequest) *core.MigrationReport); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.MigrationReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, core.MigrationVerifyRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_VerifyMigration_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'VerifyMigration'
+type MockService_VerifyMigration_Call struct {
+	*mock.Call
+}
+
+// VerifyMigration is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req core.MigrationVerifyRequest
+func (_e *MockService_Expecter) VerifyMigration(ctx interface{}, req interface{}) *MockService_VerifyMigration_Call {
+	return &MockService_VerifyMigration_Call{Call: _e.mock.On("VerifyMigration", ctx, req)}
+}
+
+func (_c *MockService_VerifyMigration_Call) Run(run func(ctx context.Context, req core.MigrationVerifyRequest)) *MockService_VerifyMigration_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(core.MigrationVerifyRequest))
+	})
+	return _c
+}
+
+func (_c *MockService_VerifyMigration_Call) Return(_a0 *core.MigrationReport, _a1 error) *MockService_VerifyMigration_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_VerifyMigration_Call) RunAndReturn(run func(context.Context, core.MigrationVerifyRequest) (*core.MigrationReport, error)) *MockService_VerifyMigration_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+const (
+	defaultMaxQueuedIngests   = 100
+	defaultIngestQueueTimeout = 3 * time.Second
+	// shedRetryAfter is the number of seconds clients are asked to wait after being shed.
+	shedRetryAfter = 5
+)
+
+// LoadSheddingConfig limits the number of requests handled concurrently, so a burst of
+// publishes cannot starve readers. Ingests are admitted only while ReadReserve slots remain
+// free for reads; beyond that they wait in a queue and are rejected when it is full or
+// they waited QueueTimeout. Reads are rejected only when all MaxInFlight slots are taken.
+type LoadSheddingConfig struct {
+	MaxInFlight  int           `mapstructure:"max_in_flight"` // Concurrent portal and ingest requests; 0 disables load shedding.
+	ReadReserve  int           `mapstructure:"read_reserve"`  // Slots kept for reads (default a quarter of MaxInFlight).
+	MaxQueue     int           `mapstructure:"max_queue"`     // Ingests waiting for a slot (default 100).
+	QueueTimeout time.Duration `mapstructure:"queue_timeout"` // Longest wait of a queued ingest (default 3s).
+}
+
+// loadShedder admits requests up to the configured concurrency limits, giving reads
+// priority over ingests.
+type loadShedder struct {
+	// wake is closed, and replaced, whenever a slot is released.
+	wake   chan struct{}
+	counts core.ShedCounts
+	cfg    LoadSheddingConfig
+	mu     sync.Mutex
+	// inFlight and queued are the requests being handled and the ingests waiting.
+	inFlight int
+	queued   int
+}
+
+// newLoadShedder returns a load shedder for cfg, or nil if load shedding is disabled.
+func newLoadShedder(cfg LoadSheddingConfig) *loadShedder {
+	if cfg.MaxInFlight <= 0 {
+		return nil
+	}
+
+	if cfg.ReadReserve <= 0 {
+		cfg.ReadReserve = cfg.MaxInFlight / 4
+	}
+
+	// Always admit at least one ingest at a time.
+	cfg.ReadReserve = min(cfg.ReadReserve, cfg.MaxInFlight-1)
+
+	if cfg.MaxQueue <= 0 {
+		cfg.MaxQueue = defaultMaxQueuedIngests
+	}
+
+	if cfg.QueueTimeout <= 0 {
+		cfg.QueueTimeout = defaultIngestQueueTimeout
+	}
+
+	return &loadShedder{cfg: cfg, wake: make(chan struct{})}
+}
+
+// limit returns the number of requests in flight below which a request is admitted.
+func (l *loadShedder) limit(ingest bool) int {
+	if ingest {
+		return l.cfg.MaxInFlight - l.cfg.ReadReserve
+	}
+
+	return l.cfg.MaxInFlight
+}
+
+// acquire takes a slot for a request and reports whether it was admitted. Reads are
+// admitted or rejected at once; ingests wait in the queue for a slot until ctx is done or
+// the queue timeout elapses. An admitted request must call release when done.
+func (l *loadShedder) acquire(ctx context.Context, ingest bool) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.inFlight < l.limit(ingest) {
+		l.inFlight++
+		return true
+	}
+
+	if !ingest {
+		l.counts.Reads++
+		return false
+	}
+
+	if l.queued >= l.cfg.MaxQueue {
+		l.counts.Ingests++
+		return false
+	}
+
+	l.queued++
+	l.counts.QueuedIngests++
+
+	defer func() { l.queued-- }()
+
+	timer := time.NewTimer(l.cfg.QueueTimeout)
+	defer timer.Stop()
+
+	for l.inFlight >= l.limit(true) {
+		wake := l.wake
+
+		l.mu.Unlock()
+
+		select {
+		case <-wake:
+		case <-timer.C:
+			l.mu.Lock()
+			l.counts.Ingests++
+
+			return false
+		case <-ctx.Done():
+			l.mu.Lock()
+			l.counts.Ingests++
+
+			return false
+		}
+
+		l.mu.Lock()
+	}
+
+	l.inFlight++
+
+	return true
+}
+
+// release frees the slot of an admitted request and wakes the queued ingests.
+func (l *loadShedder) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight--
+
+	close(l.wake)
+	l.wake = make(chan struct{})
+}
+
+// shedCounts returns the number of requests shed and queued so far.
+func (l *loadShedder) shedCounts() core.ShedCounts {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.counts
+}
+
+// withLoadShedding rejects requests with 503 Service Unavailable and a Retry-After header
+// when the server is saturated, see LoadSheddingConfig. Ingest requests are queued and
+// shed before reads. Without a load shedding limit, requests pass through unchanged.
+func (a *API) withLoadShedding(ingest bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if a.shedder == nil {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !a.shedder.acquire(r.Context(), ingest) {
+				w.Header().Set("Retry-After", strconv.Itoa(shedRetryAfter))
+				http.Error(w, "server is overloaded, try again later", http.StatusServiceUnavailable)
+
+				return
+			}
+
+			defer a.shedder.release()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+//go:build !compile
+
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLoadShedder(t *testing.T) {
+	assert.Nil(t, newLoadShedder(LoadSheddingConfig{}))
+
+	l := newLoadShedder(LoadSheddingConfig{MaxInFlight: 8})
+	require.NotNil(t, l)
+	assert.Equal(t, 2, l.cfg.ReadReserve)
+	assert.Equal(t, defaultMaxQueuedIngests, l.cfg.MaxQueue)
+	assert.Equal(t, defaultIngestQueueTimeout, l.cfg.QueueTimeout)
+
+	l = newLoadShedder(LoadSheddingConfig{MaxInFlight: 1, ReadReserve: 5})
+	assert.Equal(t, 1, l.limit(true), "at least one ingest is admitted")
+}
+
+func TestLoadShedder_ReadsBeforeIngests(t *testing.T) {
+	l := newLoadShedder(LoadSheddingConfig{MaxInFlight: 3, ReadReserve: 1, MaxQueue: 1, QueueTimeout: 50 * time.Millisecond})
+
+	require.True(t, l.acquire(t.Context(), true))
+	require.True(t, l.acquire(t.Context(), false))
+
+	assert.False(t, l.acquire(t.Context(), true), "the last slot is reserved for reads")
+	assert.True(t, l.acquire(t.Context(), false))
+	assert.False(t, l.acquire(t.Context(), false), "reads are shed once every slot is taken")
+
+	assert.Equal(t, core.ShedCounts{Reads: 1, Ingests: 1, QueuedIngests: 1}, l.shedCounts())
+}
+
+func TestLoadShedder_QueuedIngest(t *testing.T) {
+	l := newLoadShedder(LoadSheddingConfig{MaxInFlight: 2, ReadReserve: 1, MaxQueue: 1, QueueTimeout: time.Second})
+
+	require.True(t, l.acquire(t.Context(), true))
+
+	admitted := make(chan bool)
+
+	go func() { admitted <- l.acquire(t.Context(), true) }()
+
+	require.Eventually(t, func() bool {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		return l.queued == 1
+	}, time.Second, time.Millisecond)
+
+	assert.False(t, l.acquire(t.Context(), true), "the queue is full")
+
+	l.release()
+	assert.True(t, <-admitted, "the queued ingest takes the released slot")
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	assert.False(t, l.acquire(ctx, true), "a cancelled ingest leaves the queue")
+	assert.Equal(t, core.ShedCounts{Ingests: 2, QueuedIngests: 2}, l.shedCounts())
+}
+
+func TestWithLoadShedding(t *testing.T) {
+	svc := NewMockService(t)
+	views := NewMockViewRenderer(t)
+
+	api, err := New(Config{Listen: ":0", APIKeys: []string{"test-key"}, LoadShedding: LoadSheddingConfig{MaxInFlight: 1}}, svc, views)
+	require.NoError(t, err)
+
+	mux, err := api.newMux()
+	require.NoError(t, err)
+
+	require.True(t, api.shedder.acquire(t.Context(), false))
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/search?q=deploy", http.NoBody))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "5", rec.Header().Get("Retry-After"))
+
+	svc.EXPECT().Stats(mock.Anything).Return(&core.Stats{}, nil)
+
+	rec = serveMode(mux, http.MethodGet, "/api/v1/stats", "")
+	require.Equal(t, http.StatusOK, rec.Code, "the admin API is not limited")
+	assert.Contains(t, rec.Body.String(), `"shed":{"reads":1,"ingests":0,"queued_ingests":0}`)
+}
@@ -0,0 +1,198 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+const (
+	// signatureHeader carries the detached HMAC-SHA256 signature of an ingest payload, in
+	// the form sha256=<hex digest>. The digest covers the timestamp header and the body,
+	// joined by a dot.
+	signatureHeader = "X-Omnidex-Signature"
+	// signatureTimestampHeader carries the Unix time at which the payload was signed.
+	signatureTimestampHeader = "X-Omnidex-Timestamp"
+	// signatureWindow is how far the signing time of a payload may be from the server's
+	// clock. Signatures seen within the window are not accepted again.
+	signatureWindow = 5 * time.Minute
+)
+
+var (
+	errSignatureRequired = errors.New("payload signature required")
+	errInvalidSignature  = errors.New("invalid payload signature")
+	errSignatureExpired  = errors.New("payload signature expired")
+	errSignatureReplayed = errors.New("payload signature already used")
+)
+
+// signatureGuard remembers the signatures accepted within the signature window, keyed by
+// their decoded bytes re-encoded as lowercase hex, so that a captured payload cannot be
+// published again, whatever the case of its digest. It is held in memory and only guards
+// this instance. The zero value is ready to use.
+type signatureGuard struct {
+	seen map[string]time.Time
+	mu   sync.Mutex
+}
+
+// accept records sig, signed at signedAt, and reports whether it was not seen before.
+// Signatures older than the window are forgotten.
+func (g *signatureGuard) accept(sig string, signedAt, now time.Time) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.seen == nil {
+		g.seen = make(map[string]time.Time)
+	}
+
+	for s, t := range g.seen {
+		if now.Sub(t) > signatureWindow {
+			delete(g.seen, s)
+		}
+	}
+
+	if _, ok := g.seen[sig]; ok {
+		return false
+	}
+
+	g.seen[sig] = signedAt
+
+	return true
+}
+
+// parseSigningKey splits a signing key entry of the form scope=secret, where scope is an
+// owner or a repository. It reports false when the entry has no scope or no secret.
+func parseSigningKey(entry string) (scope, secret string, ok bool) {
+	scope, secret, ok = strings.Cut(entry, "=")
+	if !ok || scope == "" || secret == "" || strings.Count(scope, "/") > 1 {
+		return "", "", false
+	}
+
+	return scope, secret, true
+}
+
+// validateSigningKeys checks that every signing key is bound to an owner or repository.
+func validateSigningKeys(keys []string) error {
+	for i, entry := range keys {
+		if _, _, ok := parseSigningKey(entry); !ok {
+			return fmt.Errorf("signing key %d must have the form owner=secret or owner/repo=secret", i+1)
+		}
+	}
+
+	return nil
+}
+
+// scopeAllowsRepo reports whether a signing key scope covers repo: an owner scope covers
+// its repositories and a repository scope the repository and its projects.
+func scopeAllowsRepo(scope, repo string) bool {
+	if !strings.Contains(scope, "/") {
+		owner, _, _ := strings.Cut(repo, "/")
+		return strings.EqualFold(owner, scope)
+	}
+
+	return tokenAllowsRepo(scope, repo)
+}
+
+// verifySignature checks the signature headers of an ingest payload for repo against the
+// signing keys bound to it. It reports whether the payload was signed with one of them
+// within the signature window and was not seen before, and returns an error if the
+// signature is invalid, expired or replayed, or missing while signatures are required.
+func (a *API) verifySignature(header, timestamp, repo string, body []byte) (bool, error) {
+	if header == "" {
+		if a.config.RequireSignature {
+			return false, errSignatureRequired
+		}
+
+		return false, nil
+	}
+
+	digest, found := strings.CutPrefix(header, "sha256=")
+	if !found {
+		return false, errInvalidSignature
+	}
+
+	sig, err := hex.DecodeString(digest)
+	if err != nil {
+		return false, errInvalidSignature
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false, errInvalidSignature
+	}
+
+	now := time.Now()
+	signedAt := time.Unix(unix, 0)
+
+	if signedAt.Before(now.Add(-signatureWindow)) || signedAt.After(now.Add(signatureWindow)) {
+		return false, errSignatureExpired
+	}
+
+	for _, entry := range a.config.SigningKeys {
+		scope, secret, ok := parseSigningKey(entry)
+		if !ok || !scopeAllowsRepo(scope, repo) {
+			continue
+		}
+
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(timestamp + "."))
+		mac.Write(body)
+
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			continue
+		}
+
+		if !a.signatures.accept(hex.EncodeToString(sig), signedAt, now) {
+			return false, errSignatureReplayed
+		}
+
+		return true, nil
+	}
+
+	return false, errInvalidSignature
+}
+
+// withVerification returns the provenance of an ingest request for repo with Verified set
+// from the signature check, discarding any value sent by the client. A workflow or run
+// of another repository is not verified, as the signing key only vouches for repo.
+func withVerification(prov *core.Provenance, repo string, verified bool) *core.Provenance {
+	if prov == nil && !verified {
+		return nil
+	}
+
+	p := core.Provenance{Verified: verified}
+	if prov != nil {
+		p.Workflow = prov.Workflow
+		p.RunURL = prov.RunURL
+	}
+
+	if p.Verified && !provenanceInRepo(&p, repo) {
+		p.Verified = false
+	}
+
+	return &p
+}
+
+// provenanceInRepo reports whether the workflow and run of prov, when set, belong to the
+// GitHub repository of repo, the owner and repository without a project.
+func provenanceInRepo(prov *core.Provenance, repo string) bool {
+	parts := strings.SplitN(repo, "/", 3)
+	if len(parts) < 2 {
+		return false
+	}
+
+	ghRepo := strings.ToLower(parts[0] + "/" + parts[1])
+
+	if prov.Workflow != "" && !strings.HasPrefix(strings.ToLower(prov.Workflow), ghRepo+"/") {
+		return false
+	}
+
+	return prov.RunURL == "" || strings.Contains(strings.ToLower(prov.RunURL), "/"+ghRepo+"/actions/runs/")
+}
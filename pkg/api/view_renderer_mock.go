@@ -25,17 +25,17 @@ func (_m *MockViewRenderer) EXPECT() *MockViewRenderer_Expecter {
 	return &MockViewRenderer_Expecter{mock: &_m.Mock}
 }
 
-// RenderDoc provides a mock function with given fields: w, doc, html, headings, navDocs, partial
-func (_m *MockViewRenderer) RenderDoc(w io.Writer, doc core.Document, html []byte, headings []core.Heading, navDocs []core.DocumentMeta, partial bool) error {
-	ret := _m.Called(w, doc, html, headings, navDocs, partial)
+// RenderDoc provides a mock function with given fields: w, doc, html, headings, navDocs, highlights, partial
+func (_m *MockViewRenderer) RenderDoc(w io.Writer, doc core.Document, html []byte, headings []core.Heading, navDocs []core.DocumentMeta, highlights *core.PageHighlights, partial bool) error {
+	ret := _m.Called(w, doc, html, headings, navDocs, highlights, partial)
 
 	if len(ret) == 0 {
 		panic("no return value specified for RenderDoc")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(io.Writer, core.Document, []byte, []core.Heading, []core.DocumentMeta, bool) error); ok {
-		r0 = rf(w, doc, html, headings, navDocs, partial)
+	if rf, ok := ret.Get(0).(func(io.Writer, core.Document, []byte, []core.Heading, []core.DocumentMeta, *core.PageHighlights, bool) error); ok {
+		r0 = rf(w, doc, html, headings, navDocs, highlights, partial)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -54,14 +54,15 @@ type MockViewRenderer_RenderDoc_Call struct {
 //   - html []byte
 //   - headings []core.Heading
 //   - navDocs []core.DocumentMeta
+//   - highlights *core.PageHighlights
 //   - partial bool
-func (_e *MockViewRenderer_Expecter) RenderDoc(w interface{}, doc interface{}, html interface{}, headings interface{}, navDocs interface{}, partial interface{}) *MockViewRenderer_RenderDoc_Call {
-	return &MockViewRenderer_RenderDoc_Call{Call: _e.mock.On("RenderDoc", w, doc, html, headings, navDocs, partial)}
+func (_e *MockViewRenderer_Expecter) RenderDoc(w interface{}, doc interface{}, html interface{}, headings interface{}, navDocs interface{}, highlights interface{}, partial interface{}) *MockViewRenderer_RenderDoc_Call {
+	return &MockViewRenderer_RenderDoc_Call{Call: _e.mock.On("RenderDoc", w, doc, html, headings, navDocs, highlights, partial)}
 }
 
-func (_c *MockViewRenderer_RenderDoc_Call) Run(run func(w io.Writer, doc core.Document, html []byte, headings []core.Heading, navDocs []core.DocumentMeta, partial bool)) *MockViewRenderer_RenderDoc_Call {
+func (_c *MockViewRenderer_RenderDoc_Call) Run(run func(w io.Writer, doc core.Document, html []byte, headings []core.Heading, navDocs []core.DocumentMeta, highlights *core.PageHighlights, partial bool)) *MockViewRenderer_RenderDoc_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(io.Writer), args[1].(core.Document), args[2].([]byte), args[3].([]core.Heading), args[4].([]core.DocumentMeta), args[5].(bool))
+		run(args[0].(io.Writer), args[1].(core.Document), args[2].([]byte), args[3].([]core.Heading), args[4].([]core.DocumentMeta), args[5].(*core.PageHighlights), args[6].(bool))
 	})
 	return _c
 }
@@ -71,7 +72,103 @@ func (_c *MockViewRenderer_RenderDoc_Call) Return(_a0 error) *MockViewRenderer_R
 	return _c
 }
 
-func (_c *MockViewRenderer_RenderDoc_Call) RunAndReturn(run func(io.Writer, core.Document, []byte, []core.Heading, []core.DocumentMeta, bool) error) *MockViewRenderer_RenderDoc_Call {
+func (_c *MockViewRenderer_RenderDoc_Call) RunAndReturn(run func(io.Writer, core.Document, []byte, []core.Heading, []core.DocumentMeta, *core.PageHighlights, bool) error) *MockViewRenderer_RenderDoc_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RenderEPUB provides a mock function with given fields: w, repo, docs, asset
+func (_m *MockViewRenderer) RenderEPUB(w io.Writer, repo string, docs []core.SectionDocument, asset func(string) ([]byte, error)) error {
+	ret := _m.Called(w, repo, docs, asset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RenderEPUB")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(io.Writer, string, []core.SectionDocument, func(string) ([]byte, error)) error); ok {
+		r0 = rf(w, repo, docs, asset)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockViewRenderer_RenderEPUB_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RenderEPUB'
+type MockViewRenderer_RenderEPUB_Call struct {
+	*mock.Call
+}
+
+// RenderEPUB is a helper method to define mock.On call
+//   - w io.Writer
+//   - repo string
+//   - docs []core.SectionDocument
+//   - asset func(string)([]byte , error)
+func (_e *MockViewRenderer_Expecter) RenderEPUB(w interface{}, repo interface{}, docs interface{}, asset interface{}) *MockViewRenderer_RenderEPUB_Call {
+	return &MockViewRenderer_RenderEPUB_Call{Call: _e.mock.On("RenderEPUB", w, repo, docs, asset)}
+}
+
+func (_c *MockViewRenderer_RenderEPUB_Call) Run(run func(w io.Writer, repo string, docs []core.SectionDocument, asset func(string) ([]byte, error))) *MockViewRenderer_RenderEPUB_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(io.Writer), args[1].(string), args[2].([]core.SectionDocument), args[3].(func(string) ([]byte, error)))
+	})
+	return _c
+}
+
+func (_c *MockViewRenderer_RenderEPUB_Call) Return(_a0 error) *MockViewRenderer_RenderEPUB_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockViewRenderer_RenderEPUB_Call) RunAndReturn(run func(io.Writer, string, []core.SectionDocument, func(string) ([]byte, error)) error) *MockViewRenderer_RenderEPUB_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RenderEditor provides a mock function with given fields: w, doc
+func (_m *MockViewRenderer) RenderEditor(w io.Writer, doc core.Document) error {
+	ret := _m.Called(w, doc)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RenderEditor")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(io.Writer, core.Document) error); ok {
+		r0 = rf(w, doc)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockViewRenderer_RenderEditor_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RenderEditor'
+type MockViewRenderer_RenderEditor_Call struct {
+	*mock.Call
+}
+
+// RenderEditor is a helper method to define mock.On call
+//   - w io.Writer
+//   - doc core.Document
+func (_e *MockViewRenderer_Expecter) RenderEditor(w interface{}, doc interface{}) *MockViewRenderer_RenderEditor_Call {
+	return &MockViewRenderer_RenderEditor_Call{Call: _e.mock.On("RenderEditor", w, doc)}
+}
+
+func (_c *MockViewRenderer_RenderEditor_Call) Run(run func(w io.Writer, doc core.Document)) *MockViewRenderer_RenderEditor_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(io.Writer), args[1].(core.Document))
+	})
+	return _c
+}
+
+func (_c *MockViewRenderer_RenderEditor_Call) Return(_a0 error) *MockViewRenderer_RenderEditor_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockViewRenderer_RenderEditor_Call) RunAndReturn(run func(io.Writer, core.Document) error) *MockViewRenderer_RenderEditor_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -124,6 +221,54 @@ func (_c *MockViewRenderer_RenderHome_Call) RunAndReturn(run func(io.Writer, []c
 	return _c
 }
 
+// RenderMaintenance provides a mock function with given fields: w, message, partial
+func (_m *MockViewRenderer) RenderMaintenance(w io.Writer, message string, partial bool) error {
+	ret := _m.Called(w, message, partial)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RenderMaintenance")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(io.Writer, string, bool) error); ok {
+		r0 = rf(w, message, partial)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockViewRenderer_RenderMaintenance_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RenderMaintenance'
+type MockViewRenderer_RenderMaintenance_Call struct {
+	*mock.Call
+}
+
+// RenderMaintenance is a helper method to define mock.On call
+//   - w io.Writer
+//   - message string
+//   - partial bool
+func (_e *MockViewRenderer_Expecter) RenderMaintenance(w interface{}, message interface{}, partial interface{}) *MockViewRenderer_RenderMaintenance_Call {
+	return &MockViewRenderer_RenderMaintenance_Call{Call: _e.mock.On("RenderMaintenance", w, message, partial)}
+}
+
+func (_c *MockViewRenderer_RenderMaintenance_Call) Run(run func(w io.Writer, message string, partial bool)) *MockViewRenderer_RenderMaintenance_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(io.Writer), args[1].(string), args[2].(bool))
+	})
+	return _c
+}
+
+func (_c *MockViewRenderer_RenderMaintenance_Call) Return(_a0 error) *MockViewRenderer_RenderMaintenance_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockViewRenderer_RenderMaintenance_Call) RunAndReturn(run func(io.Writer, string, bool) error) *MockViewRenderer_RenderMaintenance_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // RenderNotFound provides a mock function with given fields: w
 func (_m *MockViewRenderer) RenderNotFound(w io.Writer) error {
 	ret := _m.Called(w)
@@ -170,17 +315,160 @@ func (_c *MockViewRenderer_RenderNotFound_Call) RunAndReturn(run func(io.Writer)
 	return _c
 }
 
-// RenderRepoIndex provides a mock function with given fields: w, repo, docs, partial
-func (_m *MockViewRenderer) RenderRepoIndex(w io.Writer, repo string, docs []core.DocumentMeta, partial bool) error {
-	ret := _m.Called(w, repo, docs, partial)
+// RenderNotifications provides a mock function with given fields: w, notifications, partial
+func (_m *MockViewRenderer) RenderNotifications(w io.Writer, notifications []core.Notification, partial bool) error {
+	ret := _m.Called(w, notifications, partial)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RenderNotifications")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(io.Writer, []core.Notification, bool) error); ok {
+		r0 = rf(w, notifications, partial)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockViewRenderer_RenderNotifications_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RenderNotifications'
+type MockViewRenderer_RenderNotifications_Call struct {
+	*mock.Call
+}
+
+// RenderNotifications is a helper method to define mock.On call
+//   - w io.Writer
+//   - notifications []core.Notification
+//   - partial bool
+func (_e *MockViewRenderer_Expecter) RenderNotifications(w interface{}, notifications interface{}, partial interface{}) *MockViewRenderer_RenderNotifications_Call {
+	return &MockViewRenderer_RenderNotifications_Call{Call: _e.mock.On("RenderNotifications", w, notifications, partial)}
+}
+
+func (_c *MockViewRenderer_RenderNotifications_Call) Run(run func(w io.Writer, notifications []core.Notification, partial bool)) *MockViewRenderer_RenderNotifications_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(io.Writer), args[1].([]core.Notification), args[2].(bool))
+	})
+	return _c
+}
+
+func (_c *MockViewRenderer_RenderNotifications_Call) Return(_a0 error) *MockViewRenderer_RenderNotifications_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockViewRenderer_RenderNotifications_Call) RunAndReturn(run func(io.Writer, []core.Notification, bool) error) *MockViewRenderer_RenderNotifications_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RenderNotificationsLink provides a mock function with given fields: w, unread
+func (_m *MockViewRenderer) RenderNotificationsLink(w io.Writer, unread int) error {
+	ret := _m.Called(w, unread)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RenderNotificationsLink")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(io.Writer, int) error); ok {
+		r0 = rf(w, unread)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockViewRenderer_RenderNotificationsLink_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RenderNotificationsLink'
+type MockViewRenderer_RenderNotificationsLink_Call struct {
+	*mock.Call
+}
+
+// RenderNotificationsLink is a helper method to define mock.On call
+//   - w io.Writer
+//   - unread int
+func (_e *MockViewRenderer_Expecter) RenderNotificationsLink(w interface{}, unread interface{}) *MockViewRenderer_RenderNotificationsLink_Call {
+	return &MockViewRenderer_RenderNotificationsLink_Call{Call: _e.mock.On("RenderNotificationsLink", w, unread)}
+}
+
+func (_c *MockViewRenderer_RenderNotificationsLink_Call) Run(run func(w io.Writer, unread int)) *MockViewRenderer_RenderNotificationsLink_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(io.Writer), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MockViewRenderer_RenderNotificationsLink_Call) Return(_a0 error) *MockViewRenderer_RenderNotificationsLink_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockViewRenderer_RenderNotificationsLink_Call) RunAndReturn(run func(io.Writer, int) error) *MockViewRenderer_RenderNotificationsLink_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RenderPreview provides a mock function with given fields: w, doc, html
+func (_m *MockViewRenderer) RenderPreview(w io.Writer, doc core.Document, html []byte) error {
+	ret := _m.Called(w, doc, html)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RenderPreview")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(io.Writer, core.Document, []byte) error); ok {
+		r0 = rf(w, doc, html)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockViewRenderer_RenderPreview_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RenderPreview'
+type MockViewRenderer_RenderPreview_Call struct {
+	*mock.Call
+}
+
+// RenderPreview is a helper method to define mock.On call
+//   - w io.Writer
+//   - doc core.Document
+//   - html []byte
+func (_e *MockViewRenderer_Expecter) RenderPreview(w interface{}, doc interface{}, html interface{}) *MockViewRenderer_RenderPreview_Call {
+	return &MockViewRenderer_RenderPreview_Call{Call: _e.mock.On("RenderPreview", w, doc, html)}
+}
+
+func (_c *MockViewRenderer_RenderPreview_Call) Run(run func(w io.Writer, doc core.Document, html []byte)) *MockViewRenderer_RenderPreview_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(io.Writer), args[1].(core.Document), args[2].([]byte))
+	})
+	return _c
+}
+
+func (_c *MockViewRenderer_RenderPreview_Call) Return(_a0 error) *MockViewRenderer_RenderPreview_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockViewRenderer_RenderPreview_Call) RunAndReturn(run func(io.Writer, core.Document, []byte) error) *MockViewRenderer_RenderPreview_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RenderRepoIndex provides a mock function with given fields: w, repo, docs, landing, highlights, filesTab, partial
+func (_m *MockViewRenderer) RenderRepoIndex(w io.Writer, repo string, docs []core.DocumentMeta, landing *core.RepoLanding, highlights *core.PageHighlights, filesTab bool, partial bool) error {
+	ret := _m.Called(w, repo, docs, landing, highlights, filesTab, partial)
 
 	if len(ret) == 0 {
 		panic("no return value specified for RenderRepoIndex")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(io.Writer, string, []core.DocumentMeta, bool) error); ok {
-		r0 = rf(w, repo, docs, partial)
+	if rf, ok := ret.Get(0).(func(io.Writer, string, []core.DocumentMeta, *core.RepoLanding, *core.PageHighlights, bool, bool) error); ok {
+		r0 = rf(w, repo, docs, landing, highlights, filesTab, partial)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -197,14 +485,17 @@ type MockViewRenderer_RenderRepoIndex_Call struct {
 //   - w io.Writer
 //   - repo string
 //   - docs []core.DocumentMeta
+//   - landing *core.RepoLanding
+//   - highlights *core.PageHighlights
+//   - filesTab bool
 //   - partial bool
-func (_e *MockViewRenderer_Expecter) RenderRepoIndex(w interface{}, repo interface{}, docs interface{}, partial interface{}) *MockViewRenderer_RenderRepoIndex_Call {
-	return &MockViewRenderer_RenderRepoIndex_Call{Call: _e.mock.On("RenderRepoIndex", w, repo, docs, partial)}
+func (_e *MockViewRenderer_Expecter) RenderRepoIndex(w interface{}, repo interface{}, docs interface{}, landing interface{}, highlights interface{}, filesTab interface{}, partial interface{}) *MockViewRenderer_RenderRepoIndex_Call {
+	return &MockViewRenderer_RenderRepoIndex_Call{Call: _e.mock.On("RenderRepoIndex", w, repo, docs, landing, highlights, filesTab, partial)}
 }
 
-func (_c *MockViewRenderer_RenderRepoIndex_Call) Run(run func(w io.Writer, repo string, docs []core.DocumentMeta, partial bool)) *MockViewRenderer_RenderRepoIndex_Call {
+func (_c *MockViewRenderer_RenderRepoIndex_Call) Run(run func(w io.Writer, repo string, docs []core.DocumentMeta, landing *core.RepoLanding, highlights *core.PageHighlights, filesTab bool, partial bool)) *MockViewRenderer_RenderRepoIndex_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(io.Writer), args[1].(string), args[2].([]core.DocumentMeta), args[3].(bool))
+		run(args[0].(io.Writer), args[1].(string), args[2].([]core.DocumentMeta), args[3].(*core.RepoLanding), args[4].(*core.PageHighlights), args[5].(bool), args[6].(bool))
 	})
 	return _c
 }
@@ -214,22 +505,73 @@ func (_c *MockViewRenderer_RenderRepoIndex_Call) Return(_a0 error) *MockViewRend
 	return _c
 }
 
-func (_c *MockViewRenderer_RenderRepoIndex_Call) RunAndReturn(run func(io.Writer, string, []core.DocumentMeta, bool) error) *MockViewRenderer_RenderRepoIndex_Call {
+func (_c *MockViewRenderer_RenderRepoIndex_Call) RunAndReturn(run func(io.Writer, string, []core.DocumentMeta, *core.RepoLanding, *core.PageHighlights, bool, bool) error) *MockViewRenderer_RenderRepoIndex_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// RenderSearch provides a mock function with given fields: w, query, results, partial
-func (_m *MockViewRenderer) RenderSearch(w io.Writer, query string, results *core.SearchResults, partial bool) error {
-	ret := _m.Called(w, query, results, partial)
+// RenderRepoSearch provides a mock function with given fields: w, repo, query, opts, results, partial
+func (_m *MockViewRenderer) RenderRepoSearch(w io.Writer, repo string, query string, opts core.SearchOpts, results *core.SearchResults, partial bool) error {
+	ret := _m.Called(w, repo, query, opts, results, partial)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RenderRepoSearch")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(io.Writer, string, string, core.SearchOpts, *core.SearchResults, bool) error); ok {
+		r0 = rf(w, repo, query, opts, results, partial)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockViewRenderer_RenderRepoSearch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RenderRepoSearch'
+type MockViewRenderer_RenderRepoSearch_Call struct {
+	*mock.Call
+}
+
+// RenderRepoSearch is a helper method to define mock.On call
+//   - w io.Writer
+//   - repo string
+//   - query string
+//   - opts core.SearchOpts
+//   - results *core.SearchResults
+//   - partial bool
+func (_e *MockViewRenderer_Expecter) RenderRepoSearch(w interface{}, repo interface{}, query interface{}, opts interface{}, results interface{}, partial interface{}) *MockViewRenderer_RenderRepoSearch_Call {
+	return &MockViewRenderer_RenderRepoSearch_Call{Call: _e.mock.On("RenderRepoSearch", w, repo, query, opts, results, partial)}
+}
+
+func (_c *MockViewRenderer_RenderRepoSearch_Call) Run(run func(w io.Writer, repo string, query string, opts core.SearchOpts, results *core.SearchResults, partial bool)) *MockViewRenderer_RenderRepoSearch_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(io.Writer), args[1].(string), args[2].(string), args[3].(core.SearchOpts), args[4].(*core.SearchResults), args[5].(bool))
+	})
+	return _c
+}
+
+func (_c *MockViewRenderer_RenderRepoSearch_Call) Return(_a0 error) *MockViewRenderer_RenderRepoSearch_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockViewRenderer_RenderRepoSearch_Call) RunAndReturn(run func(io.Writer, string, string, core.SearchOpts, *core.SearchResults, bool) error) *MockViewRenderer_RenderRepoSearch_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RenderSearch provides a mock function with given fields: w, query, opts, results, repos, partial
+func (_m *MockViewRenderer) RenderSearch(w io.Writer, query string, opts core.SearchOpts, results *core.SearchResults, repos []core.RepoInfo, partial bool) error {
+	ret := _m.Called(w, query, opts, results, repos, partial)
 
 	if len(ret) == 0 {
 		panic("no return value specified for RenderSearch")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(io.Writer, string, *core.SearchResults, bool) error); ok {
-		r0 = rf(w, query, results, partial)
+	if rf, ok := ret.Get(0).(func(io.Writer, string, core.SearchOpts, *core.SearchResults, []core.RepoInfo, bool) error); ok {
+		r0 = rf(w, query, opts, results, repos, partial)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -245,15 +587,17 @@ type MockViewRenderer_RenderSearch_Call struct {
 // RenderSearch is a helper method to define mock.On call
 //   - w io.Writer
 //   - query string
+//   - opts core.SearchOpts
 //   - results *core.SearchResults
+//   - repos []core.RepoInfo
 //   - partial bool
-func (_e *MockViewRenderer_Expecter) RenderSearch(w interface{}, query interface{}, results interface{}, partial interface{}) *MockViewRenderer_RenderSearch_Call {
-	return &MockViewRenderer_RenderSearch_Call{Call: _e.mock.On("RenderSearch", w, query, results, partial)}
+func (_e *MockViewRenderer_Expecter) RenderSearch(w interface{}, query interface{}, opts interface{}, results interface{}, repos interface{}, partial interface{}) *MockViewRenderer_RenderSearch_Call {
+	return &MockViewRenderer_RenderSearch_Call{Call: _e.mock.On("RenderSearch", w, query, opts, results, repos, partial)}
 }
 
-func (_c *MockViewRenderer_RenderSearch_Call) Run(run func(w io.Writer, query string, results *core.SearchResults, partial bool)) *MockViewRenderer_RenderSearch_Call {
+func (_c *MockViewRenderer_RenderSearch_Call) Run(run func(w io.Writer, query string, opts core.SearchOpts, results *core.SearchResults, repos []core.RepoInfo, partial bool)) *MockViewRenderer_RenderSearch_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(io.Writer), args[1].(string), args[2].(*core.SearchResults), args[3].(bool))
+		run(args[0].(io.Writer), args[1].(string), args[2].(core.SearchOpts), args[3].(*core.SearchResults), args[4].([]core.RepoInfo), args[5].(bool))
 	})
 	return _c
 }
@@ -263,7 +607,199 @@ func (_c *MockViewRenderer_RenderSearch_Call) Return(_a0 error) *MockViewRendere
 	return _c
 }
 
-func (_c *MockViewRenderer_RenderSearch_Call) RunAndReturn(run func(io.Writer, string, *core.SearchResults, bool) error) *MockViewRenderer_RenderSearch_Call {
+func (_c *MockViewRenderer_RenderSearch_Call) RunAndReturn(run func(io.Writer, string, core.SearchOpts, *core.SearchResults, []core.RepoInfo, bool) error) *MockViewRenderer_RenderSearch_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RenderSection provides a mock function with given fields: w, repo, dir, docs
+func (_m *MockViewRenderer) RenderSection(w io.Writer, repo string, dir string, docs []core.SectionDocument) error {
+	ret := _m.Called(w, repo, dir, docs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RenderSection")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(io.Writer, string, string, []core.SectionDocument) error); ok {
+		r0 = rf(w, repo, dir, docs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockViewRenderer_RenderSection_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RenderSection'
+type MockViewRenderer_RenderSection_Call struct {
+	*mock.Call
+}
+
+// RenderSection is a helper method to define mock.On call
+//   - w io.Writer
+//   - repo string
+//   - dir string
+//   - docs []core.SectionDocument
+func (_e *MockViewRenderer_Expecter) RenderSection(w interface{}, repo interface{}, dir interface{}, docs interface{}) *MockViewRenderer_RenderSection_Call {
+	return &MockViewRenderer_RenderSection_Call{Call: _e.mock.On("RenderSection", w, repo, dir, docs)}
+}
+
+func (_c *MockViewRenderer_RenderSection_Call) Run(run func(w io.Writer, repo string, dir string, docs []core.SectionDocument)) *MockViewRenderer_RenderSection_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(io.Writer), args[1].(string), args[2].(string), args[3].([]core.SectionDocument))
+	})
+	return _c
+}
+
+func (_c *MockViewRenderer_RenderSection_Call) Return(_a0 error) *MockViewRenderer_RenderSection_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockViewRenderer_RenderSection_Call) RunAndReturn(run func(io.Writer, string, string, []core.SectionDocument) error) *MockViewRenderer_RenderSection_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RenderStats provides a mock function with given fields: w, stats, partial
+func (_m *MockViewRenderer) RenderStats(w io.Writer, stats *core.Stats, partial bool) error {
+	ret := _m.Called(w, stats, partial)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RenderStats")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(io.Writer, *core.Stats, bool) error); ok {
+		r0 = rf(w, stats, partial)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockViewRenderer_RenderStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RenderStats'
+type MockViewRenderer_RenderStats_Call struct {
+	*mock.Call
+}
+
+// RenderStats is a helper method to define mock.On call
+//   - w io.Writer
+//   - stats *core.Stats
+//   - partial bool
+func (_e *MockViewRenderer_Expecter) RenderStats(w interface{}, stats interface{}, partial interface{}) *MockViewRenderer_RenderStats_Call {
+	return &MockViewRenderer_RenderStats_Call{Call: _e.mock.On("RenderStats", w, stats, partial)}
+}
+
+func (_c *MockViewRenderer_RenderStats_Call) Run(run func(w io.Writer, stats *core.Stats, partial bool)) *MockViewRenderer_RenderStats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(io.Writer), args[1].(*core.Stats), args[2].(bool))
+	})
+	return _c
+}
+
+func (_c *MockViewRenderer_RenderStats_Call) Return(_a0 error) *MockViewRenderer_RenderStats_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockViewRenderer_RenderStats_Call) RunAndReturn(run func(io.Writer, *core.Stats, bool) error) *MockViewRenderer_RenderStats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RenderSuggestion provides a mock function with given fields: w, doc
+func (_m *MockViewRenderer) RenderSuggestion(w io.Writer, doc core.Document) error {
+	ret := _m.Called(w, doc)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RenderSuggestion")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(io.Writer, core.Document) error); ok {
+		r0 = rf(w, doc)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockViewRenderer_RenderSuggestion_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RenderSuggestion'
+type MockViewRenderer_RenderSuggestion_Call struct {
+	*mock.Call
+}
+
+// RenderSuggestion is a helper method to define mock.On call
+//   - w io.Writer
+//   - doc core.Document
+func (_e *MockViewRenderer_Expecter) RenderSuggestion(w interface{}, doc interface{}) *MockViewRenderer_RenderSuggestion_Call {
+	return &MockViewRenderer_RenderSuggestion_Call{Call: _e.mock.On("RenderSuggestion", w, doc)}
+}
+
+func (_c *MockViewRenderer_RenderSuggestion_Call) Run(run func(w io.Writer, doc core.Document)) *MockViewRenderer_RenderSuggestion_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(io.Writer), args[1].(core.Document))
+	})
+	return _c
+}
+
+func (_c *MockViewRenderer_RenderSuggestion_Call) Return(_a0 error) *MockViewRenderer_RenderSuggestion_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockViewRenderer_RenderSuggestion_Call) RunAndReturn(run func(io.Writer, core.Document) error) *MockViewRenderer_RenderSuggestion_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RenderSuggestions provides a mock function with given fields: w, query, suggestions
+func (_m *MockViewRenderer) RenderSuggestions(w io.Writer, query string, suggestions []core.SearchSuggestion) error {
+	ret := _m.Called(w, query, suggestions)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RenderSuggestions")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(io.Writer, string, []core.SearchSuggestion) error); ok {
+		r0 = rf(w, query, suggestions)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockViewRenderer_RenderSuggestions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RenderSuggestions'
+type MockViewRenderer_RenderSuggestions_Call struct {
+	*mock.Call
+}
+
+// RenderSuggestions is a helper method to define mock.On call
+//   - w io.Writer
+//   - query string
+//   - suggestions []core.SearchSuggestion
+func (_e *MockViewRenderer_Expecter) RenderSuggestions(w interface{}, query interface{}, suggestions interface{}) *MockViewRenderer_RenderSuggestions_Call {
+	return &MockViewRenderer_RenderSuggestions_Call{Call: _e.mock.On("RenderSuggestions", w, query, suggestions)}
+}
+
+func (_c *MockViewRenderer_RenderSuggestions_Call) Run(run func(w io.Writer, query string, suggestions []core.SearchSuggestion)) *MockViewRenderer_RenderSuggestions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(io.Writer), args[1].(string), args[2].([]core.SearchSuggestion))
+	})
+	return _c
+}
+
+func (_c *MockViewRenderer_RenderSuggestions_Call) Return(_a0 error) *MockViewRenderer_RenderSuggestions_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockViewRenderer_RenderSuggestions_Call) RunAndReturn(run func(io.Writer, string, []core.SearchSuggestion) error) *MockViewRenderer_RenderSuggestions_Call {
 	_c.Call.Return(run)
 	return _c
 }
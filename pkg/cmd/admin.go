@@ -0,0 +1,1146 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/api"
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/spf13/cobra"
+)
+
+const adminTimeout = 30 * time.Second
+
+// rebuildPollInterval is how often rebuild-index polls the progress of the rebuild.
+var rebuildPollInterval = 2 * time.Second
+
+type adminFlags struct {
+	URL    string
+	APIKey string
+	Output string
+}
+
+// adminCall describes a request to the admin API and how its response is shown as text.
+type adminCall struct {
+	// render writes the response body as text. It is not called with --output json.
+	render func(w io.Writer, body []byte) error
+	// jsonBody, when set, replaces an empty response body in --output json.
+	jsonBody any
+	request  any
+	method   string
+	path     string
+}
+
+// newAdminCmd creates the admin command, whose subcommands manage an Omnidex instance
+// through its admin API.
+func newAdminCmd() *cobra.Command {
+	flags := &adminFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "admin",
+		Short: "Manage an Omnidex instance",
+		Long:  "Manage repositories, API keys and the search index of a running Omnidex instance through its admin API.",
+	}
+
+	cmd.PersistentFlags().StringVar(&flags.URL, "url", "", "base URL of the Omnidex instance")
+	cmd.PersistentFlags().StringVar(&flags.APIKey, "api-key", "", "Bearer token for authentication")
+	cmd.PersistentFlags().StringVar(&flags.Output, "output", outputText, "output format: text or json")
+
+	for flagName, envVar := range map[string]string{
+		"url":     "OMNIDEX_URL",
+		"api-key": "OMNIDEX_API_KEY",
+		"output":  "OMNIDEX_OUTPUT",
+	} {
+		if val := os.Getenv(envVar); val != "" {
+			_ = cmd.PersistentFlags().Set(flagName, val)
+		}
+	}
+
+	cmd.AddCommand(
+		newAdminSubcommand(flags, "list-repos", "List published repositories", cobra.NoArgs,
+			func([]string) adminCall {
+				return adminCall{method: http.MethodGet, path: "/api/v1/repos", render: renderRepos}
+			}),
+		newAdminSubcommand(flags, "delete-repo owner/repo", "Delete a repository with all its documents and assets", cobra.ExactArgs(1),
+			func(args []string) adminCall {
+				return adminCall{
+					method: http.MethodDelete,
+					path:   "/api/v1/repos/" + (&url.URL{Path: strings.Trim(args[0], "/")}).EscapedPath(),
+					render: renderDeletedRepo,
+				}
+			}),
+		newAdminSubcommand(flags, "republish owner/repo", "Trigger the repository's publish workflow to publish its documentation again", cobra.ExactArgs(1),
+			func(args []string) adminCall {
+				repo := strings.Trim(args[0], "/")
+
+				return adminCall{
+					method: http.MethodPost,
+					path:   "/api/v1/repos/" + (&url.URL{Path: repo}).EscapedPath() + "/republish",
+					render: func(w io.Writer, _ []byte) error {
+						_, err := fmt.Fprintf(w, "Republish of %s triggered\n", repo)
+						return err
+					},
+				}
+			}),
+		newAdminSubcommand(flags, "create-key name", "Create an API key; the token is shown only once", cobra.ExactArgs(1),
+			func(args []string) adminCall {
+				return adminCall{
+					method:  http.MethodPost,
+					path:    "/api/v1/keys",
+					request: core.CreateAPIKeyRequest{Name: args[0]},
+					render:  renderCreatedKey,
+				}
+			}),
+		newAdminSubcommand(flags, "list-keys", "List the API keys created with create-key", cobra.NoArgs,
+			func([]string) adminCall {
+				return adminCall{method: http.MethodGet, path: "/api/v1/keys", render: renderKeys}
+			}),
+		newAdminSubcommand(flags, "revoke-key id", "Revoke an API key created with create-key", cobra.ExactArgs(1),
+			func(args []string) adminCall {
+				return adminCall{
+					method:   http.MethodDelete,
+					path:     "/api/v1/keys/" + url.PathEscape(args[0]),
+					jsonBody: map[string]string{"revoked": args[0]},
+					render: func(w io.Writer, _ []byte) error {
+						_, err := fmt.Fprintf(w, "Revoked API key %s\n", args[0])
+						return err
+					},
+				}
+			}),
+		newRotateKeysCmd(flags),
+		newAdminSubcommand(flags, "reindex", "Rebuild the search index from the stored documents", cobra.NoArgs,
+			func([]string) adminCall {
+				return adminCall{
+					method: http.MethodPost,
+					path:   "/api/v1/reindex",
+					render: func(w io.Writer, _ []byte) error {
+						_, err := fmt.Fprintln(w, "Search index rebuild started; progress is logged by the server")
+						return err
+					},
+				}
+			}),
+		newRebuildIndexCmd(flags),
+		newAdminSubcommand(flags, "rebuild-status", "Show the progress and outcome of the latest blue/green index rebuild", cobra.NoArgs,
+			func([]string) adminCall {
+				return adminCall{method: http.MethodGet, path: "/api/v1/reindex/blue-green", render: renderIndexRebuild}
+			}),
+		newAdminSubcommand(flags, "verify-migration [query...]",
+			"Compare the search backends of a search migration by indexed documents and query results", cobra.ArbitraryArgs,
+			func(args []string) adminCall {
+				path := "/api/v1/search/migration"
+				if len(args) > 0 {
+					path += "?" + url.Values{"q": args}.Encode()
+				}
+
+				return adminCall{method: http.MethodGet, path: path, render: renderMigrationReport}
+			}),
+		newAdminSubcommand(flags, "start-incident owner/repo path [name]",
+			"Flag a document as in use during an incident, showing its readers which sections teammates are on",
+			cobra.RangeArgs(2, 3),
+			func(args []string) adminCall {
+				repo := strings.Trim(args[0], "/")
+
+				req := map[string]string{"repo": repo, "path": args[1]}
+				if len(args) > 2 {
+					req["name"] = args[2]
+				}
+
+				return adminCall{
+					method:  http.MethodPost,
+					path:    "/api/v1/incidents",
+					request: req,
+					render: func(w io.Writer, _ []byte) error {
+						_, err := fmt.Fprintf(w, "Incident started on %s/%s\n", repo, args[1])
+						return err
+					},
+				}
+			}),
+		newAdminSubcommand(flags, "end-incident owner/repo path", "Remove the incident flag of a document", cobra.ExactArgs(2),
+			func(args []string) adminCall {
+				repo := strings.Trim(args[0], "/")
+
+				return adminCall{
+					method:   http.MethodDelete,
+					path:     "/api/v1/incidents?" + url.Values{"repo": {repo}, "path": {args[1]}}.Encode(),
+					jsonBody: map[string]string{"ended": repo + "/" + args[1]},
+					render: func(w io.Writer, _ []byte) error {
+						_, err := fmt.Fprintf(w, "Incident ended on %s/%s\n", repo, args[1])
+						return err
+					},
+				}
+			}),
+		newAdminSubcommand(flags, "list-incidents", "List the documents flagged as in use during an incident", cobra.NoArgs,
+			func([]string) adminCall {
+				return adminCall{method: http.MethodGet, path: "/api/v1/incidents", render: renderIncidents}
+			}),
+		newAdminSubcommand(flags, "announce owner/repo [markdown]",
+			"Show a markdown announcement on every page of a repository, or remove it when none is given",
+			cobra.RangeArgs(1, 2),
+			func(args []string) adminCall {
+				repo := strings.Trim(args[0], "/")
+				announcement := ""
+
+				if len(args) > 1 {
+					announcement = args[1]
+				}
+
+				return adminCall{
+					method:  http.MethodPut,
+					path:    "/api/v1/highlights",
+					request: map[string]any{"repo": repo, "announcement": announcement},
+					render: func(w io.Writer, _ []byte) error {
+						msg := "Announcement posted on " + repo
+						if strings.TrimSpace(announcement) == "" {
+							msg = "Announcement removed from " + repo
+						}
+
+						_, err := fmt.Fprintln(w, msg)
+
+						return err
+					},
+				}
+			}),
+		newAdminSubcommand(flags, "pin owner/repo [path...]",
+			"Pin documents to the top of a repository's index in the given order, replacing the pinned ones",
+			cobra.MinimumNArgs(1),
+			func(args []string) adminCall {
+				repo := strings.Trim(args[0], "/")
+				paths := append([]string{}, args[1:]...)
+
+				return adminCall{
+					method:  http.MethodPut,
+					path:    "/api/v1/highlights",
+					request: map[string]any{"repo": repo, "pinned": paths},
+					render:  renderHighlights,
+				}
+			}),
+		newAdminSubcommand(flags, "highlights owner/repo", "Show the announcement and pinned documents of a repository", cobra.ExactArgs(1),
+			func(args []string) adminCall {
+				repo := strings.Trim(args[0], "/")
+
+				return adminCall{
+					method: http.MethodGet,
+					path:   "/api/v1/highlights?" + url.Values{"repo": {repo}}.Encode(),
+					render: renderHighlights,
+				}
+			}),
+		newSetBannerCmd(flags),
+		newAdminSubcommand(flags, "banner", "Show the banner shown at the top of every portal page", cobra.NoArgs,
+			func([]string) adminCall {
+				return adminCall{method: http.MethodGet, path: "/api/v1/banner", render: renderBanner}
+			}),
+		newAdminSubcommand(flags, "clear-banner", "Remove the banner shown at the top of every portal page", cobra.NoArgs,
+			func([]string) adminCall {
+				return adminCall{
+					method: http.MethodDelete,
+					path:   "/api/v1/banner",
+					render: func(w io.Writer, _ []byte) error {
+						_, err := fmt.Fprintln(w, "Banner removed")
+						return err
+					},
+				}
+			}),
+		newAdminSubcommand(flags, "duplicates", "List documents published identically in several repositories", cobra.NoArgs,
+			func([]string) adminCall {
+				return adminCall{method: http.MethodGet, path: "/api/v1/duplicates", render: renderDuplicates}
+			}),
+		newAdminSubcommand(flags, "renders", "List the render times and sizes of documents, those over the render budget first", cobra.NoArgs,
+			func([]string) adminCall {
+				return adminCall{method: http.MethodGet, path: "/api/v1/renders", render: renderRenders}
+			}),
+		newAdminSubcommand(flags, "citations [owner/repo]",
+			"Export the content and rendered HTML hashes of published documents as audit evidence", cobra.MaximumNArgs(1),
+			func(args []string) adminCall {
+				path := "/api/v1/citations"
+				if len(args) > 0 {
+					path += "?" + url.Values{"repo": {strings.Trim(args[0], "/")}}.Encode()
+				}
+
+				return adminCall{method: http.MethodGet, path: path, render: renderCitations}
+			}),
+		newAdminSubcommand(flags, "stats", "Show instance statistics and recent activity", cobra.NoArgs,
+			func([]string) adminCall {
+				return adminCall{method: http.MethodGet, path: "/api/v1/stats", render: renderStats}
+			}),
+		newAdminSubcommand(flags, "mode", "Show the operating mode of the server", cobra.NoArgs,
+			func([]string) adminCall {
+				return adminCall{method: http.MethodGet, path: "/api/v1/mode", render: renderMode}
+			}),
+		newAdminSubcommand(flags, "set-mode normal|read-only|maintenance [message]",
+			"Switch the server to normal, read-only or maintenance mode, with a message for readers and publishers",
+			cobra.RangeArgs(1, 2),
+			func(args []string) adminCall {
+				req := map[string]string{"mode": strings.ReplaceAll(args[0], "-", "_")}
+				if len(args) > 1 {
+					req["message"] = args[1]
+				}
+
+				return adminCall{method: http.MethodPut, path: "/api/v1/mode", request: req, render: renderMode}
+			}),
+	)
+
+	return cmd
+}
+
+// newAdminSubcommand creates an admin subcommand that performs the call built from its arguments.
+func newAdminSubcommand(
+	flags *adminFlags,
+	use, short string,
+	args cobra.PositionalArgs,
+	call func(args []string) adminCall,
+) *cobra.Command {
+	return &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  args,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAdmin(cmd.Context(), cmd.OutOrStdout(), flags, call(args))
+		},
+	}
+}
+
+// newRebuildIndexCmd creates the rebuild-index subcommand, which rebuilds the search index
+// alongside the live one and waits for the server to promote or discard it.
+func newRebuildIndexCmd(flags *adminFlags) *cobra.Command {
+	var (
+		req  core.IndexRebuildRequest
+		wait bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "rebuild-index",
+		Short: "Rebuild the search index alongside the live one and switch to it if sampled queries agree",
+		Long: "Rebuild the search index into a shadow index while the live one keeps serving, compare the top " +
+			"results of sampled queries on both, then switch to the rebuilt index atomically if they overlap " +
+			"enough, or discard it otherwise.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if req.MinOverlap < 0 || req.MinOverlap > 1 {
+				return validationError(fmt.Errorf("--min-overlap must be between 0 and 1: %v", req.MinOverlap))
+			}
+
+			start := adminCall{
+				method:  http.MethodPost,
+				path:    "/api/v1/reindex/blue-green",
+				request: req,
+				render: func(w io.Writer, _ []byte) error {
+					_, err := fmt.Fprintln(w, "Blue/green index rebuild started; check its progress with rebuild-status")
+					return err
+				},
+			}
+
+			if !wait {
+				return runAdmin(cmd.Context(), cmd.OutOrStdout(), flags, start)
+			}
+
+			return runIndexRebuild(cmd.Context(), cmd.OutOrStdout(), flags, start)
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&req.Queries, "query", nil, "query comparing the live and rebuilt index (repeatable); document titles are sampled by default")
+	cmd.Flags().Float64Var(&req.MinOverlap, "min-overlap", 0, "mean overlap of the top results, between 0 and 1, needed to switch to the rebuilt index (default 0.5)")
+	cmd.Flags().BoolVar(&req.Force, "force", false, "switch to the rebuilt index whatever the comparison shows")
+	cmd.Flags().BoolVar(&req.DryRun, "dry-run", false, "build and compare the rebuilt index, then discard it")
+	cmd.Flags().BoolVar(&wait, "wait", true, "wait for the rebuild to finish and show the comparison")
+
+	return cmd
+}
+
+// runIndexRebuild starts a blue/green index rebuild, waits for it to finish and writes its
+// outcome to w. A rebuild that fails or is discarded for lack of overlap is reported as an
+// error.
+func runIndexRebuild(ctx context.Context, w io.Writer, flags *adminFlags, start adminCall) error {
+	if err := runAdmin(ctx, io.Discard, flags, start); err != nil {
+		return err
+	}
+
+	status := adminCall{method: http.MethodGet, path: "/api/v1/reindex/blue-green", render: renderIndexRebuild}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("stopped waiting for the index rebuild: %w", ctx.Err())
+		case <-time.After(rebuildPollInterval):
+		}
+
+		body, err := sendAdminRequest(ctx, flags, status)
+		if err != nil {
+			return err
+		}
+
+		var st core.IndexRebuildStatus
+		if err := json.Unmarshal(body, &st); err != nil {
+			return &ExitError{Err: fmt.Errorf("failed to parse response: %w", err), Code: ExitCodeServer}
+		}
+
+		if !st.Done() {
+			continue
+		}
+
+		if err := writeAdminOutput(w, flags, status, body); err != nil {
+			return err
+		}
+
+		switch {
+		case st.State == core.RebuildFailed:
+			return &ExitError{Err: fmt.Errorf("index rebuild failed: %s", st.Error), Code: ExitCodeServer}
+		case st.State == core.RebuildDiscarded && !st.DryRun:
+			return fmt.Errorf("rebuilt index discarded: overlap %.2f is below %.2f", st.Overlap, st.MinOverlap)
+		}
+
+		return nil
+	}
+}
+
+// newSetBannerCmd creates the set-banner subcommand, which sets the banner shown at the top
+// of every portal page, optionally scheduled for a window of time.
+func newSetBannerCmd(flags *adminFlags) *cobra.Command {
+	var severity, starts, ends string
+
+	cmd := &cobra.Command{
+		Use:   "set-banner markdown",
+		Short: "Show a markdown banner at the top of every portal page, such as a maintenance notice",
+		Long: "Show a markdown banner at the top of every portal page, replacing the current one. With --starts " +
+			"and --ends, given as RFC 3339 times, the banner is shown only in that window.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			banner := core.SiteBanner{Message: args[0], Severity: core.BannerSeverity(severity)}
+
+			for _, t := range []struct {
+				dst  *time.Time
+				flag string
+				val  string
+			}{{&banner.StartsAt, "starts", starts}, {&banner.EndsAt, "ends", ends}} {
+				if t.val == "" {
+					continue
+				}
+
+				parsed, err := time.Parse(time.RFC3339, t.val)
+				if err != nil {
+					return validationError(fmt.Errorf("--%s must be an RFC 3339 time such as 2026-01-02T15:04:05Z: %q", t.flag, t.val))
+				}
+
+				*t.dst = parsed
+			}
+
+			return runAdmin(cmd.Context(), cmd.OutOrStdout(), flags, adminCall{
+				method:  http.MethodPut,
+				path:    "/api/v1/banner",
+				request: banner,
+				render:  renderBanner,
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&severity, "severity", string(core.BannerInfo), "banner style: info, warning or critical")
+	cmd.Flags().StringVar(&starts, "starts", "", "RFC 3339 time the banner is first shown (default now)")
+	cmd.Flags().StringVar(&ends, "ends", "", "RFC 3339 time the banner is last shown (default until cleared)")
+
+	return cmd
+}
+
+// newRotateKeysCmd creates the rotate-keys subcommand, which replaces managed API keys by
+// new ones while the old keys keep working for an overlap window.
+func newRotateKeysCmd(flags *adminFlags) *cobra.Command {
+	var overlap time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "rotate-keys [id...]",
+		Short: "Replace the API keys created with create-key, keeping the old keys valid for an overlap window",
+		Long: "Replace the API keys created with create-key, or only the given ones, by new keys with the same " +
+			"names and print their tokens. The old keys keep working for --overlap so clients can switch without " +
+			"downtime. Keys are rotated one at a time; if the command is interrupted, run it again to rotate the " +
+			"remaining keys.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if overlap < 0 {
+				return validationError(fmt.Errorf("--overlap must not be negative: %s", overlap))
+			}
+
+			return runRotateKeys(cmd.Context(), cmd.OutOrStdout(), flags, args, overlap)
+		},
+	}
+
+	cmd.Flags().DurationVar(&overlap, "overlap", core.DefaultKeyOverlap, "how long the old keys keep working")
+
+	return cmd
+}
+
+// runRotateKeys rotates the managed keys with the given IDs, or all of them, one at a time,
+// reporting each rotation as it completes. Keys rotated by an earlier, interrupted run and
+// the keys that replaced them are skipped, so running it again resumes the rotation.
+func runRotateKeys(ctx context.Context, w io.Writer, flags *adminFlags, ids []string, overlap time.Duration) error {
+	if err := validateAdminFlags(flags); err != nil {
+		return err
+	}
+
+	body, err := sendAdminRequest(ctx, flags, adminCall{method: http.MethodGet, path: "/api/v1/keys"})
+	if err != nil {
+		return err
+	}
+
+	var list struct {
+		Keys []core.APIKey `json:"keys"`
+	}
+
+	if err := json.Unmarshal(body, &list); err != nil {
+		return &ExitError{Err: fmt.Errorf("failed to parse response: %w", err), Code: ExitCodeServer}
+	}
+
+	pending, err := keysToRotate(list.Keys, ids)
+	if err != nil {
+		return err
+	}
+
+	rotated := make([]core.RotateAPIKeyResponse, 0, len(pending))
+	req := core.RotateAPIKeyRequest{Overlap: overlap.String()}
+
+	for i, k := range pending {
+		call := adminCall{method: http.MethodPost, path: "/api/v1/keys/" + url.PathEscape(k.ID) + "/rotate", request: req}
+
+		body, err := sendAdminRequest(ctx, flags, call)
+		if err != nil {
+			// Report the keys rotated so far, as their tokens cannot be shown again.
+			if werr := writeRotatedKeys(w, flags, rotated); werr != nil {
+				return werr
+			}
+
+			return fmt.Errorf("rotated %d of %d keys, run rotate-keys again to resume: %w", len(rotated), len(pending), err)
+		}
+
+		var resp core.RotateAPIKeyResponse
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return &ExitError{Err: fmt.Errorf("failed to parse response: %w", err), Code: ExitCodeServer}
+		}
+
+		rotated = append(rotated, resp)
+
+		if flags.Output == outputText {
+			_, _ = fmt.Fprintf(w, "[%d/%d] Rotated %s (%s -> %s), old key expires %s\n%s\n",
+				i+1, len(pending), resp.Name, k.ID, resp.ID, resp.Replaced.ExpiresAt.Format(time.RFC3339), resp.Token)
+		}
+	}
+
+	if flags.Output == outputText {
+		if len(pending) == 0 {
+			_, err := fmt.Fprintln(w, "No API keys to rotate")
+			return err
+		}
+
+		_, err := fmt.Fprintln(w, "\nStore the new tokens now; they cannot be shown again.")
+
+		return err
+	}
+
+	return writeRotatedKeys(w, flags, rotated)
+}
+
+// keysToRotate returns the keys with the given IDs, or all keys, except those already rotated
+// and those that replaced a key still listed. It fails if an ID is not a key.
+func keysToRotate(keys []core.APIKey, ids []string) ([]core.APIKey, error) {
+	replacements := make(map[string]bool)
+
+	for _, k := range keys {
+		if k.RotatedTo != "" {
+			replacements[k.RotatedTo] = true
+		}
+	}
+
+	pending := make([]core.APIKey, 0, len(keys))
+
+	for _, k := range keys {
+		if len(ids) > 0 && !slices.Contains(ids, k.ID) {
+			continue
+		}
+
+		if k.RotatedTo == "" && !replacements[k.ID] {
+			pending = append(pending, k)
+		}
+	}
+
+	for _, id := range ids {
+		if !slices.ContainsFunc(keys, func(k core.APIKey) bool { return k.ID == id }) {
+			return nil, validationError(fmt.Errorf("no API key with ID %s", id))
+		}
+	}
+
+	return pending, nil
+}
+
+// writeRotatedKeys writes the rotated keys as JSON when --output json is set; text output is
+// written as the keys are rotated.
+func writeRotatedKeys(w io.Writer, flags *adminFlags, rotated []core.RotateAPIKeyResponse) error {
+	if flags.Output != outputJSON {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(map[string]any{"rotated": rotated}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal output: %w", err)
+	}
+
+	if _, err := fmt.Fprintf(w, "%s\n", data); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	return nil
+}
+
+// runAdmin sends the call to the admin API and writes the response to w, as text or JSON.
+func runAdmin(ctx context.Context, w io.Writer, flags *adminFlags, call adminCall) error {
+	if err := validateAdminFlags(flags); err != nil {
+		return err
+	}
+
+	body, err := sendAdminRequest(ctx, flags, call)
+	if err != nil {
+		return err
+	}
+
+	return writeAdminOutput(w, flags, call, body)
+}
+
+// validateAdminFlags checks the flags shared by the admin subcommands.
+func validateAdminFlags(flags *adminFlags) error {
+	switch {
+	case flags.URL == "":
+		return validationError(fmt.Errorf("--url (or OMNIDEX_URL) is required"))
+	case flags.APIKey == "":
+		return validationError(fmt.Errorf("--api-key (or OMNIDEX_API_KEY) is required"))
+	case flags.Output != outputText && flags.Output != outputJSON:
+		return validationError(fmt.Errorf("--output must be %q or %q: %q", outputText, outputJSON, flags.Output))
+	}
+
+	return nil
+}
+
+// writeAdminOutput writes the response body of the call to w, as text or JSON.
+func writeAdminOutput(w io.Writer, flags *adminFlags, call adminCall, body []byte) error {
+	if flags.Output == outputText {
+		if err := call.render(w, body); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+
+		return nil
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 && call.jsonBody != nil {
+		data, err := json.Marshal(call.jsonBody)
+		if err != nil {
+			return fmt.Errorf("failed to marshal output: %w", err)
+		}
+
+		body = data
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, body, "", "  "); err != nil {
+		return &ExitError{Err: fmt.Errorf("failed to parse response: %w", err), Code: ExitCodeServer}
+	}
+
+	out.WriteByte('\n')
+
+	if _, err := out.WriteTo(w); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	return nil
+}
+
+// sendAdminRequest performs the admin API request and returns the response body. Failures
+// to reach the server and error responses are returned as server errors.
+func sendAdminRequest(ctx context.Context, flags *adminFlags, call adminCall) ([]byte, error) {
+	var reqBody io.Reader = http.NoBody
+
+	if call.request != nil {
+		data, err := json.Marshal(call.request)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+
+		reqBody = bytes.NewReader(data)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, adminTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, call.method, strings.TrimRight(flags.URL, "/")+call.path, reqBody)
+	if err != nil {
+		return nil, validationError(fmt.Errorf("failed to create request: %w", err))
+	}
+
+	req.Header.Set("Authorization", "Bearer "+flags.APIKey)
+
+	if call.request != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, &ExitError{Err: fmt.Errorf("HTTP request failed: %w", err), Code: ExitCodeServer}
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &ExitError{Err: fmt.Errorf("failed to read response body: %w", err), Code: ExitCodeServer}
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, &ExitError{
+			Err:  fmt.Errorf("server returned HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body))),
+			Code: ExitCodeServer,
+		}
+	}
+
+	return body, nil
+}
+
+// renderRepos writes the repositories of a list-repos response as a table.
+func renderRepos(w io.Writer, body []byte) error {
+	var resp struct {
+		Repos []core.RepoInfo `json:"repos"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(tw, "REPO\tDOCS\tLAST UPDATED")
+
+	for _, r := range resp.Repos {
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%s\n", r.Name, r.DocCount, r.LastUpdated.Format(time.RFC3339))
+	}
+
+	return tw.Flush()
+}
+
+// renderDeletedRepo writes the outcome of a delete-repo call.
+func renderDeletedRepo(w io.Writer, body []byte) error {
+	var resp core.DeleteRepoResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	_, err := fmt.Fprintf(w, "Deleted %s (%d documents)\n", resp.Repo, resp.Deleted)
+
+	return err
+}
+
+// renderCreatedKey writes a newly created key and its token.
+func renderCreatedKey(w io.Writer, body []byte) error {
+	var resp core.CreateAPIKeyResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	_, err := fmt.Fprintf(w, "Created API key %s (%s)\n%s\n\nStore the token now; it cannot be shown again.\n",
+		resp.ID, resp.Name, resp.Token)
+
+	return err
+}
+
+// renderKeys writes the keys of a list-keys response as a table.
+func renderKeys(w io.Writer, body []byte) error {
+	var resp struct {
+		Keys []core.APIKey `json:"keys"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(tw, "ID\tNAME\tCREATED\tEXPIRES")
+
+	for _, k := range resp.Keys {
+		expires := "-"
+		if !k.ExpiresAt.IsZero() {
+			expires = k.ExpiresAt.Format(time.RFC3339) + " (rotated to " + k.RotatedTo + ")"
+		}
+
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", k.ID, k.Name, k.CreatedAt.Format(time.RFC3339), expires)
+	}
+
+	return tw.Flush()
+}
+
+// renderIncidents writes the documents of a list-incidents response as a table.
+func renderIncidents(w io.Writer, body []byte) error {
+	var resp struct {
+		Incidents []core.Incident `json:"incidents"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(tw, "REPO\tPATH\tINCIDENT\tSTARTED")
+
+	for _, inc := range resp.Incidents {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", inc.Repo, inc.Path, inc.Name, inc.StartedAt.Format(time.RFC3339))
+	}
+
+	return tw.Flush()
+}
+
+// renderHighlights writes the announcement and pinned documents of a repository.
+func renderHighlights(w io.Writer, body []byte) error {
+	var h core.RepoHighlights
+
+	if err := json.Unmarshal(body, &h); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	announcement := h.Announcement
+	if announcement == "" {
+		announcement = "none"
+	}
+
+	_, _ = fmt.Fprintf(w, "Repository:   %s\n", h.Repo)
+	_, _ = fmt.Fprintf(w, "Announcement: %s\n", announcement)
+
+	if len(h.Pinned) == 0 {
+		_, err := fmt.Fprintln(w, "Pinned:       none")
+		return err
+	}
+
+	_, _ = fmt.Fprintln(w, "Pinned:")
+
+	for i, p := range h.Pinned {
+		_, _ = fmt.Fprintf(w, "  %d. %s\n", i+1, p)
+	}
+
+	return nil
+}
+
+// renderCitations writes the documents of a citation manifest as a table with their
+// shortened hashes. The full hashes are in the JSON output.
+func renderCitations(w io.Writer, body []byte) error {
+	const shortHashLen = 12
+
+	var manifest core.CitationManifest
+
+	if err := json.Unmarshal(body, &manifest); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(tw, "DOCUMENT\tCOMMIT\tPUBLISHED\tCONTENT SHA256\tHTML SHA256")
+
+	for _, d := range manifest.Documents {
+		_, _ = fmt.Fprintf(tw, "%s/%s\t%s\t%s\t%s\t%s\n", d.Repo, d.Path, d.CommitSHA, d.PublishedAt.Format(time.RFC3339),
+			d.ContentSHA256[:min(len(d.ContentSHA256), shortHashLen)], d.HTMLSHA256[:min(len(d.HTMLSHA256), shortHashLen)])
+	}
+
+	return tw.Flush()
+}
+
+// renderDuplicates writes the groups of a duplicates response, each canonical document
+// followed by its copies.
+func renderDuplicates(w io.Writer, body []byte) error {
+	var resp struct {
+		Groups []core.DuplicateGroup `json:"groups"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if len(resp.Groups) == 0 {
+		_, err := fmt.Fprintln(w, "No duplicate documents")
+		return err
+	}
+
+	var buf bytes.Buffer
+
+	for _, g := range resp.Groups {
+		fmt.Fprintf(&buf, "%s/%s (%d bytes, %d copies)\n", g.Canonical.Repo, g.Canonical.Path, g.Size, len(g.Copies))
+
+		for _, c := range g.Copies {
+			fmt.Fprintf(&buf, "  %s/%s\n", c.Repo, c.Path)
+		}
+	}
+
+	_, err := buf.WriteTo(w)
+
+	return err
+}
+
+// renderRenders writes the documents of a render report as a table, marking those over the
+// budget. Times not measured yet are shown as "-".
+func renderRenders(w io.Writer, body []byte) error {
+	var report core.RenderReport
+
+	if err := json.Unmarshal(body, &report); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	measured := func(d time.Duration) string {
+		if d == 0 {
+			return "-"
+		}
+
+		return d.Round(time.Millisecond).String()
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintf(tw, "Budget: %s, %d bytes\n\n", report.Budget.MaxDuration, report.Budget.MaxBytes)
+	_, _ = fmt.Fprintln(tw, "DOCUMENT\tINGEST\tVIEW\tHTML BYTES\tOVER BUDGET")
+
+	for _, d := range report.Documents {
+		over := ""
+		if d.OverBudget {
+			over = "yes"
+		}
+
+		_, _ = fmt.Fprintf(tw, "%s/%s\t%s\t%s\t%d\t%s\n", d.Repo, d.Path, measured(d.IngestRender), measured(d.ViewRender), d.HTMLBytes, over)
+	}
+
+	return tw.Flush()
+}
+
+// renderBanner writes the site banner with its schedule.
+func renderBanner(w io.Writer, body []byte) error {
+	var b core.SiteBanner
+	if err := json.Unmarshal(body, &b); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "Severity: %s\n", b.Severity)
+
+	if !b.StartsAt.IsZero() {
+		fmt.Fprintf(&buf, "Starts:   %s\n", b.StartsAt.Format(time.RFC3339))
+	}
+
+	if !b.EndsAt.IsZero() {
+		fmt.Fprintf(&buf, "Ends:     %s\n", b.EndsAt.Format(time.RFC3339))
+	}
+
+	fmt.Fprintf(&buf, "Message:  %s\n", b.Message)
+
+	_, err := buf.WriteTo(w)
+
+	return err
+}
+
+// renderMode writes the operating mode of the server.
+func renderMode(w io.Writer, body []byte) error {
+	var status api.ModeStatus
+	if err := json.Unmarshal(body, &status); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "Mode: %s", status.Mode)
+
+	if !status.Since.IsZero() {
+		fmt.Fprintf(&buf, " (since %s)", status.Since.Format(time.RFC3339))
+	}
+
+	buf.WriteByte('\n')
+
+	if status.Message != "" {
+		fmt.Fprintf(&buf, "Message: %s\n", status.Message)
+	}
+
+	_, err := buf.WriteTo(w)
+
+	return err
+}
+
+// renderIndexRebuild writes the progress and outcome of a blue/green index rebuild with the
+// comparison of each sampled query.
+func renderIndexRebuild(w io.Writer, body []byte) error {
+	var st core.IndexRebuildStatus
+	if err := json.Unmarshal(body, &st); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "State:    %s", st.State)
+
+	if st.DryRun {
+		buf.WriteString(" (dry run)")
+	}
+
+	buf.WriteByte('\n')
+	fmt.Fprintf(&buf, "Started:  %s\n", st.StartedAt.Format(time.RFC3339))
+
+	if !st.FinishedAt.IsZero() {
+		fmt.Fprintf(&buf, "Finished: %s\n", st.FinishedAt.Format(time.RFC3339))
+	}
+
+	fmt.Fprintf(&buf, "Indexed:  %d (%d failed)\n", st.Indexed, st.Failed)
+
+	if st.Error != "" {
+		fmt.Fprintf(&buf, "Error:    %s\n", st.Error)
+	}
+
+	if len(st.Comparisons) > 0 {
+		fmt.Fprintf(&buf, "Overlap:  %.2f (minimum %.2f", st.Overlap, st.MinOverlap)
+
+		if st.Force {
+			buf.WriteString(", forced")
+		}
+
+		buf.WriteString(")\n\n")
+
+		tw := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+
+		_, _ = fmt.Fprintln(tw, "QUERY\tLIVE\tREBUILT\tOVERLAP")
+
+		for _, c := range st.Comparisons {
+			_, _ = fmt.Fprintf(tw, "%s\t%d\t%d\t%.2f\n", c.Query, c.LiveTotal, c.ShadowTotal, c.Overlap)
+		}
+
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	_, err := buf.WriteTo(w)
+
+	return err
+}
+
+// renderMigrationReport writes the comparison of the search backends of a search migration:
+// the documents each one indexes per repository and the results of each compared query.
+func renderMigrationReport(w io.Writer, body []byte) error {
+	var report core.MigrationReport
+	if err := json.Unmarshal(body, &report); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	var buf bytes.Buffer
+
+	inSync := "no"
+	if report.InSync {
+		inSync = "yes"
+	}
+
+	fmt.Fprintf(&buf, "In sync:        %s\n", inSync)
+	fmt.Fprintf(&buf, "Reads from:     %s\n", report.ReadFrom)
+	fmt.Fprintf(&buf, "Write failures: %d\n", report.WriteFailures)
+
+	if report.LastError != "" {
+		fmt.Fprintf(&buf, "Last failure:   %s %s\n", report.LastFailure.Format(time.RFC3339), report.LastError)
+	}
+
+	fmt.Fprintf(&buf, "Overlap:        %.2f\n\n", report.Overlap)
+
+	tw := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(tw, "REPO\tSTORED\tSOURCE\tTARGET")
+
+	for _, r := range report.Repos {
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%d\t%d\n", r.Repo, r.Stored, r.Source, r.Target)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, r := range report.Repos {
+		for _, id := range r.Missing {
+			fmt.Fprintf(&buf, "  missing from target: %s\n", id)
+		}
+
+		for _, id := range r.Extra {
+			fmt.Fprintf(&buf, "  only in target:      %s\n", id)
+		}
+	}
+
+	if len(report.Comparisons) > 0 {
+		buf.WriteByte('\n')
+
+		tw = tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+
+		_, _ = fmt.Fprintln(tw, "QUERY\tSOURCE\tTARGET\tOVERLAP")
+
+		for _, c := range report.Comparisons {
+			_, _ = fmt.Fprintf(tw, "%s\t%d\t%d\t%.2f\n", c.Query, c.LiveTotal, c.ShadowTotal, c.Overlap)
+		}
+
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	_, err := buf.WriteTo(w)
+
+	return err
+}
+
+// renderStats writes the instance statistics. Sizes the server cannot report are omitted.
+func renderStats(w io.Writer, body []byte) error {
+	var stats core.Stats
+	if err := json.Unmarshal(body, &stats); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	var buf bytes.Buffer
+
+	line := func(label, format string, args ...any) {
+		fmt.Fprintf(&buf, "%-14s"+format+"\n", append([]any{label + ":"}, args...)...)
+	}
+
+	line("Repositories", "%d", stats.Repos)
+	line("Documents", "%d", stats.Documents)
+
+	if stats.StorageBytes != nil {
+		line("Storage", "%d bytes", *stats.StorageBytes)
+	}
+
+	if stats.IndexBytes != nil {
+		line("Search index", "%d bytes", *stats.IndexBytes)
+	}
+
+	line("Ingests", "%d (%d documents)", stats.Ingests, stats.IngestedDocuments)
+
+	searches := 0
+	for _, d := range stats.SearchesPerDay {
+		searches += d.Count
+	}
+
+	line("Searches", "%d in the last %d days", searches, len(stats.SearchesPerDay))
+
+	for _, d := range stats.SearchesPerDay {
+		fmt.Fprintf(&buf, "  %s  %d\n", d.Date, d.Count)
+	}
+
+	if rb := stats.RenderBudget; rb != nil {
+		line("Over budget", "%d of %d rendered documents", rb.OverBudgetCount, rb.Measured)
+	}
+
+	if stats.Shed != nil {
+		line("Shed", "%d reads, %d ingests (%d ingests queued)", stats.Shed.Reads, stats.Shed.Ingests, stats.Shed.QueuedIngests)
+	}
+
+	line("Counting since", "%s", stats.Since.Format(time.RFC3339))
+
+	_, err := buf.WriteTo(w)
+
+	return err
+}
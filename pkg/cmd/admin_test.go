@@ -0,0 +1,325 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newAdminServer returns a server implementing the admin API routes used by the admin command.
+func newAdminServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	var rebuild core.IndexRebuildRequest
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer admin-key" {
+			http.Error(w, "invalid API key", http.StatusUnauthorized)
+			return
+		}
+
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v1/repos":
+			_, _ = w.Write([]byte(`{"repos":[{"name":"owner/repo","doc_count":3,"last_updated":"2026-01-02T03:04:05Z"}]}`))
+		case "DELETE /api/v1/repos/owner/mono/billing":
+			_, _ = w.Write([]byte(`{"repo":"owner/mono/billing","deleted":2}`))
+		case "POST /api/v1/keys":
+			var req map[string]string
+
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"token":"omx_secret","id":"a1b2","name":"` + req["name"] + `","created_at":"2026-01-02T03:04:05Z"}`))
+		case "GET /api/v1/keys":
+			_, _ = w.Write([]byte(`{"keys":[{"id":"a1b2","name":"ci","created_at":"2026-01-02T03:04:05Z"}]}`))
+		case "POST /api/v1/keys/a1b2/rotate":
+			var req core.RotateAPIKeyRequest
+
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, "1h0m0s", req.Overlap)
+
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"token":"omx_rotated","id":"c3d4","name":"ci","created_at":"2026-01-02T03:04:05Z",` +
+				`"replaced":{"id":"a1b2","name":"ci","created_at":"2026-01-01T00:00:00Z","expires_at":"2026-01-02T04:04:05Z","rotated_to":"c3d4"}}`))
+		case "DELETE /api/v1/keys/a1b2":
+			w.WriteHeader(http.StatusNoContent)
+		case "POST /api/v1/repos/owner/repo/republish":
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(`{"repo":"owner/repo","status":"triggered"}`))
+		case "POST /api/v1/reindex":
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(`{"status":"started"}`))
+		case "POST /api/v1/reindex/blue-green":
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&rebuild))
+
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(`{"status":"started"}`))
+		case "GET /api/v1/search/migration":
+			assert.Equal(t, []string{"install"}, r.URL.Query()["q"])
+
+			_ = json.NewEncoder(w).Encode(core.MigrationReport{
+				ReadFrom:      "source",
+				WriteFailures: 1,
+				LastFailure:   time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+				LastError:     "cluster unavailable",
+				Overlap:       0.5,
+				Repos:         []core.RepoIndexComparison{{Repo: "owner/repo", Stored: 3, Source: 3, Target: 2, Missing: []string{"owner/repo/faq.md"}}},
+				Comparisons:   []core.QueryComparison{{Query: "install", LiveTotal: 2, ShadowTotal: 1, Overlap: 0.5}},
+			})
+		case "GET /api/v1/reindex/blue-green":
+			state := core.RebuildPromoted
+			if rebuild.DryRun || rebuild.MinOverlap > 0.75 {
+				state = core.RebuildDiscarded
+			}
+
+			_ = json.NewEncoder(w).Encode(core.IndexRebuildStatus{
+				StartedAt:   time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+				FinishedAt:  time.Date(2026, 1, 2, 3, 5, 0, 0, time.UTC),
+				State:       state,
+				Indexed:     7,
+				Overlap:     0.75,
+				MinOverlap:  max(rebuild.MinOverlap, 0.5),
+				DryRun:      rebuild.DryRun,
+				Comparisons: []core.QueryComparison{{Query: "Setup Guide", LiveTotal: 4, ShadowTotal: 3, Overlap: 0.75}},
+			})
+		case "POST /api/v1/incidents":
+			var req map[string]string
+
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, map[string]string{"repo": "owner/repo", "path": "runbook.md", "name": "INC-1"}, req)
+
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"repo":"owner/repo","path":"runbook.md","name":"INC-1","started_at":"2026-01-02T03:04:05Z"}`))
+		case "DELETE /api/v1/incidents":
+			assert.Equal(t, "owner/repo", r.URL.Query().Get("repo"))
+			assert.Equal(t, "runbook.md", r.URL.Query().Get("path"))
+
+			w.WriteHeader(http.StatusNoContent)
+		case "GET /api/v1/incidents":
+			_, _ = w.Write([]byte(`{"incidents":[{"repo":"owner/repo","path":"runbook.md","name":"INC-1","started_at":"2026-01-02T03:04:05Z"}]}`))
+		case "PUT /api/v1/highlights":
+			var req map[string]any
+
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, "owner/repo", req["repo"])
+
+			if pinned, ok := req["pinned"]; ok {
+				assert.Equal(t, []any{"guides/migrate.md", "faq.md"}, pinned)
+				assert.NotContains(t, req, "announcement")
+			}
+
+			_, _ = w.Write([]byte(`{"repo":"owner/repo","announcement":"v1 is deprecated","pinned":["guides/migrate.md","faq.md"]}`))
+		case "GET /api/v1/highlights":
+			assert.Equal(t, "owner/repo", r.URL.Query().Get("repo"))
+			_, _ = w.Write([]byte(`{"repo":"owner/repo","pinned":["guides/migrate.md"]}`))
+		case "PUT /api/v1/banner":
+			var req core.SiteBanner
+
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, core.BannerWarning, req.Severity)
+			assert.Equal(t, time.Date(2026, 1, 2, 5, 0, 0, 0, time.UTC), req.EndsAt)
+
+			req.UpdatedAt = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+			_ = json.NewEncoder(w).Encode(req)
+		case "GET /api/v1/banner":
+			_, _ = w.Write([]byte(`{"message":"Search is degraded","severity":"critical","starts_at":"2026-01-02T03:00:00Z"}`))
+		case "DELETE /api/v1/banner":
+			w.WriteHeader(http.StatusNoContent)
+		case "GET /api/v1/citations":
+			assert.Equal(t, "team-a/api", r.URL.Query().Get("repo"))
+			_, _ = w.Write([]byte(`{"generated_at":"2026-01-02T00:00:00Z","repo":"team-a/api","documents":[{"repo":"team-a/api","path":"setup.md",` +
+				`"commit_sha":"abc1234","published_at":"2026-01-01T00:00:00Z","content_sha256":"0123456789abcdef","html_sha256":"fedcba9876543210"}]}`))
+		case "GET /api/v1/duplicates":
+			_, _ = w.Write([]byte(`{"groups":[{"sha256":"abc","size":512,"canonical":{"repo":"team-a/api","path":"setup.md"},` +
+				`"copies":[{"repo":"team-b/web","path":"install.md"}]}]}`))
+		case "GET /api/v1/renders":
+			_, _ = w.Write([]byte(`{"budget":{"max_duration_ns":250000000,"max_bytes":1048576},"documents":[` +
+				`{"repo":"team-a/api","path":"big.md","ingest_render_ns":1500000000,"html_bytes":2048,"over_budget":true},` +
+				`{"repo":"team-a/api","path":"small.md","view_render_ns":3000000,"html_bytes":512,"over_budget":false}]}`))
+		case "GET /api/v1/stats":
+			_, _ = w.Write([]byte(`{"repos":2,"documents":7,"storage_bytes":4096,"ingests":3,"ingested_documents":12,` +
+				`"searches_per_day":[{"date":"2026-01-01","count":4},{"date":"2026-01-02","count":1}],"since":"2026-01-01T00:00:00Z",` +
+				`"shed":{"reads":1,"ingests":2,"queued_ingests":5},"render_budget":{"measured":12,"over_budget_count":1,"over_budget":[]}}`))
+		case "GET /api/v1/mode":
+			_, _ = w.Write([]byte(`{"mode":"normal","since":"0001-01-01T00:00:00Z"}`))
+		case "PUT /api/v1/mode":
+			var req map[string]string
+
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+			_, _ = w.Write([]byte(`{"mode":"` + req["mode"] + `","message":"` + req["message"] + `","since":"2026-01-02T03:04:05Z"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+// execAdmin runs the admin command with the given arguments and returns its output.
+func execAdmin(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	cmd := newAdminCmd()
+
+	var out bytes.Buffer
+
+	cmd.SetOut(&out)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs(args)
+
+	err := cmd.ExecuteContext(t.Context())
+
+	return out.String(), err
+}
+
+func TestAdminCmd_Text(t *testing.T) {
+	srv := newAdminServer(t)
+
+	rebuildPollInterval = time.Millisecond
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "list-repos", args: []string{"list-repos"}, want: []string{"REPO", "owner/repo", "2026-01-02T03:04:05Z"}},
+		{name: "delete-repo", args: []string{"delete-repo", "owner/mono/billing"}, want: []string{"Deleted owner/mono/billing (2 documents)"}},
+		{name: "republish", args: []string{"republish", "owner/repo"}, want: []string{"Republish of owner/repo triggered"}},
+		{name: "create-key", args: []string{"create-key", "ci"}, want: []string{"Created API key a1b2 (ci)", "omx_secret", "cannot be shown again"}},
+		{name: "list-keys", args: []string{"list-keys"}, want: []string{"ID", "a1b2", "ci"}},
+		{name: "rotate-keys", args: []string{"rotate-keys", "--overlap", "1h"}, want: []string{
+			"[1/1] Rotated ci (a1b2 -> c3d4), old key expires 2026-01-02T04:04:05Z", "omx_rotated", "cannot be shown again",
+		}},
+		{name: "revoke-key", args: []string{"revoke-key", "a1b2"}, want: []string{"Revoked API key a1b2"}},
+		{name: "reindex", args: []string{"reindex"}, want: []string{"rebuild started"}},
+		{name: "rebuild-index", args: []string{"rebuild-index"}, want: []string{
+			"State:    promoted", "Indexed:  7 (0 failed)", "Overlap:  0.75 (minimum 0.50)", "Setup Guide  4     3        0.75",
+		}},
+		{name: "rebuild-index dry run", args: []string{"rebuild-index", "--dry-run"}, want: []string{"State:    discarded (dry run)"}},
+		{name: "rebuild-index without waiting", args: []string{"rebuild-index", "--wait=false"}, want: []string{"rebuild started"}},
+		{name: "rebuild-status", args: []string{"rebuild-status"}, want: []string{"Finished: 2026-01-02T03:05:00Z"}},
+		{name: "verify-migration", args: []string{"verify-migration", "install"}, want: []string{
+			"In sync:        no", "Write failures: 1", "Last failure:   2026-01-02T03:04:05Z cluster unavailable",
+			"owner/repo  3       3       2", "missing from target: owner/repo/faq.md", "install  2       1       0.50",
+		}},
+		{name: "start-incident", args: []string{"start-incident", "owner/repo", "runbook.md", "INC-1"}, want: []string{"Incident started on owner/repo/runbook.md"}},
+		{name: "end-incident", args: []string{"end-incident", "owner/repo", "runbook.md"}, want: []string{"Incident ended on owner/repo/runbook.md"}},
+		{name: "list-incidents", args: []string{"list-incidents"}, want: []string{"INCIDENT", "runbook.md", "INC-1", "2026-01-02T03:04:05Z"}},
+		{name: "announce", args: []string{"announce", "owner/repo", "v1 is deprecated"}, want: []string{"Announcement posted on owner/repo"}},
+		{name: "announce removal", args: []string{"announce", "owner/repo"}, want: []string{"Announcement removed from owner/repo"}},
+		{name: "pin", args: []string{"pin", "owner/repo", "guides/migrate.md", "faq.md"}, want: []string{
+			"Announcement: v1 is deprecated", "Pinned:\n  1. guides/migrate.md\n  2. faq.md",
+		}},
+		{name: "highlights", args: []string{"highlights", "owner/repo"}, want: []string{"Announcement: none", "1. guides/migrate.md"}},
+		{name: "set-banner", args: []string{"set-banner", "Publishing is paused", "--severity", "warning", "--ends", "2026-01-02T05:00:00Z"}, want: []string{
+			"Severity: warning", "Ends:     2026-01-02T05:00:00Z", "Message:  Publishing is paused",
+		}},
+		{name: "banner", args: []string{"banner"}, want: []string{"Severity: critical", "Starts:   2026-01-02T03:00:00Z", "Message:  Search is degraded"}},
+		{name: "clear-banner", args: []string{"clear-banner"}, want: []string{"Banner removed"}},
+		{name: "citations", args: []string{"citations", "team-a/api/"}, want: []string{"team-a/api/setup.md  abc1234  2026-01-01T00:00:00Z  0123456789ab    fedcba987654"}},
+		{name: "duplicates", args: []string{"duplicates"}, want: []string{"team-a/api/setup.md (512 bytes, 1 copies)\n  team-b/web/install.md\n"}},
+		{name: "renders", args: []string{"renders"}, want: []string{
+			"Budget: 250ms, 1048576 bytes", "team-a/api/big.md    1.5s    -     2048        yes", "team-a/api/small.md  -       3ms   512",
+		}},
+		{name: "mode", args: []string{"mode"}, want: []string{"Mode: normal\n"}},
+		{name: "set-mode", args: []string{"set-mode", "read-only", "Migrating storage"}, want: []string{
+			"Mode: read_only (since 2026-01-02T03:04:05Z)", "Message: Migrating storage",
+		}},
+		{name: "stats", args: []string{"stats"}, want: []string{
+			"Repositories: 2", "Documents:    7", "Storage:      4096 bytes", "Ingests:      3 (12 documents)",
+			"Searches:     5 in the last 2 days", "  2026-01-02  1", "Shed:         1 reads, 2 ingests (5 ingests queued)",
+			"Over budget:  1 of 12 rendered documents",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := execAdmin(t, append(tt.args, "--url", srv.URL, "--api-key", "admin-key")...)
+			require.NoError(t, err)
+
+			for _, want := range tt.want {
+				assert.Contains(t, out, want)
+			}
+		})
+	}
+}
+
+func TestAdminCmd_JSON(t *testing.T) {
+	srv := newAdminServer(t)
+
+	out, err := execAdmin(t, "stats", "--url", srv.URL, "--api-key", "admin-key", "--output", "json")
+	require.NoError(t, err)
+	assert.Contains(t, out, `"storage_bytes": 4096`)
+
+	out, err = execAdmin(t, "rotate-keys", "a1b2", "--overlap", "1h", "--url", srv.URL, "--api-key", "admin-key", "--output", "json")
+	require.NoError(t, err)
+	assert.Contains(t, out, `"token": "omx_rotated"`)
+
+	out, err = execAdmin(t, "revoke-key", "a1b2", "--url", srv.URL, "--api-key", "admin-key", "--output", "json")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"revoked":"a1b2"}`, out)
+}
+
+func TestAdminCmd_Errors(t *testing.T) {
+	srv := newAdminServer(t)
+
+	rebuildPollInterval = time.Millisecond
+
+	tests := []struct {
+		name     string
+		wantErr  string
+		args     []string
+		wantCode int
+	}{
+		{name: "missing url", args: []string{"stats", "--api-key", "k"}, wantErr: "--url", wantCode: ExitCodeValidation},
+		{name: "missing api key", args: []string{"stats", "--url", srv.URL}, wantErr: "--api-key", wantCode: ExitCodeValidation},
+		{name: "invalid output", args: []string{"stats", "--url", srv.URL, "--api-key", "admin-key", "--output", "xml"}, wantErr: "--output", wantCode: ExitCodeValidation},
+		{name: "unauthorized", args: []string{"stats", "--url", srv.URL, "--api-key", "wrong"}, wantErr: "server returned HTTP 401: invalid API key", wantCode: ExitCodeServer},
+		{name: "not found", args: []string{"revoke-key", "missing", "--url", srv.URL, "--api-key", "admin-key"}, wantErr: "HTTP 404", wantCode: ExitCodeServer},
+		{name: "invalid min overlap", args: []string{"rebuild-index", "--min-overlap", "2", "--url", srv.URL, "--api-key", "admin-key"}, wantErr: "--min-overlap", wantCode: ExitCodeValidation},
+		{name: "invalid banner time", args: []string{"set-banner", "hi", "--ends", "tomorrow", "--url", srv.URL, "--api-key", "admin-key"}, wantErr: "--ends", wantCode: ExitCodeValidation},
+		{name: "rebuilt index discarded", args: []string{"rebuild-index", "--min-overlap", "0.9", "--url", srv.URL, "--api-key", "admin-key"}, wantErr: "overlap 0.75 is below 0.90", wantCode: ExitCodeError},
+		{name: "server down", args: []string{"stats", "--url", "http://localhost:1", "--api-key", "k"}, wantErr: "HTTP request failed", wantCode: ExitCodeServer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := execAdmin(t, tt.args...)
+			require.ErrorContains(t, err, tt.wantErr)
+			assert.Equal(t, tt.wantCode, ExitCode(err))
+		})
+	}
+}
+
+func TestKeysToRotate(t *testing.T) {
+	keys := []core.APIKey{
+		{ID: "a1", Name: "ci", RotatedTo: "b2"},
+		{ID: "c3", Name: "deploy"},
+		{ID: "b2", Name: "ci"},
+		{ID: "d4", Name: "bot"},
+	}
+
+	pending, err := keysToRotate(keys, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []core.APIKey{keys[1], keys[3]}, pending, "rotated keys and their replacements are skipped")
+
+	pending, err = keysToRotate(keys, []string{"d4", "a1"})
+	require.NoError(t, err)
+	assert.Equal(t, []core.APIKey{keys[3]}, pending)
+
+	_, err = keysToRotate(keys, []string{"zz"})
+	require.Error(t, err)
+}
@@ -5,39 +5,14 @@ import (
 	"log/slog"
 	"strings"
 
-	"github.com/ksysoev/omnidex/pkg/api"
-	"github.com/ksysoev/omnidex/pkg/repo/s3store"
-	"github.com/ksysoev/omnidex/pkg/repo/search"
+	omnidex "github.com/ksysoev/omnidex"
 	"github.com/spf13/viper"
 )
 
-type appConfig struct {
-	Storage StorageConfig `mapstructure:"storage"`
-	Search  SearchConfig  `mapstructure:"search"`
-	API     api.Config    `mapstructure:"api"`
-}
-
-// StorageConfig holds configuration for document storage.
-// Type selects the storage backend: "local" (default) or "s3".
-type StorageConfig struct {
-	Path string         `mapstructure:"path"`
-	Type string         `mapstructure:"type"`
-	S3   s3store.Config `mapstructure:"s3"`
-}
-
-// SearchConfig holds configuration for the search engine.
-// Type selects the search backend: "bleve" (default), "elasticsearch", or "opensearch".
-type SearchConfig struct {
-	IndexPath  string                     `mapstructure:"index_path"`
-	Type       string                     `mapstructure:"type"`
-	Elastic    search.ElasticSearchConfig `mapstructure:"elasticsearch"`
-	OpenSearch search.OpenSearchConfig    `mapstructure:"opensearch"`
-}
-
 // loadConfig loads the application configuration from the specified file path and environment variables.
 // It uses the provided args structure to determine the configuration path.
-// The function returns a pointer to the appConfig structure and an error if something goes wrong.
-func loadConfig(flags *cmdFlags) (*appConfig, error) {
+// The function returns a pointer to the server configuration and an error if something goes wrong.
+func loadConfig(flags *cmdFlags) (*omnidex.Config, error) {
 	v := viper.NewWithOptions(viper.ExperimentalBindStruct())
 
 	if flags.ConfigPath != "" {
@@ -48,7 +23,7 @@ func loadConfig(flags *cmdFlags) (*appConfig, error) {
 		}
 	}
 
-	var cfg appConfig
+	var cfg omnidex.Config
 
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	v.AutomaticEnv()
@@ -1,11 +1,21 @@
 package cmd
 
 import (
+	"bytes"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
+	omnidex "github.com/ksysoev/omnidex"
 	"github.com/ksysoev/omnidex/pkg/api"
+	"github.com/ksysoev/omnidex/pkg/prov/github"
+	"github.com/ksysoev/omnidex/pkg/prov/linkcheck"
+	"github.com/ksysoev/omnidex/pkg/prov/llm"
+	"github.com/ksysoev/omnidex/pkg/prov/oidc"
+	"github.com/ksysoev/omnidex/pkg/repo/search"
+	"github.com/ksysoev/omnidex/pkg/views"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -24,7 +34,7 @@ search:
 
 	tests := []struct {
 		envVars      map[string]string
-		expectConfig *appConfig
+		expectConfig *omnidex.Config
 		name         string
 		configData   string
 		expectError  bool
@@ -34,15 +44,291 @@ search:
 			envVars:     nil,
 			expectError: false,
 			configData:  validConfig,
-			expectConfig: &appConfig{
+			expectConfig: &omnidex.Config{
 				API: api.Config{
 					Listen:  ":8082",
 					APIKeys: []string{"testkey123"},
 				},
-				Storage: StorageConfig{
+				Storage: omnidex.StorageConfig{
 					Path: "./data/repos",
 				},
-				Search: SearchConfig{
+				Search: omnidex.SearchConfig{
+					IndexPath: "./data/search.bleve",
+				},
+			},
+		},
+		{
+			name:        "bleve tuning",
+			expectError: false,
+			configData: validConfig + `  bleve:
+    persist_interval: 500ms
+    analysis_workers: 8
+`,
+			expectConfig: &omnidex.Config{
+				API: api.Config{
+					Listen:  ":8082",
+					APIKeys: []string{"testkey123"},
+				},
+				Storage: omnidex.StorageConfig{
+					Path: "./data/repos",
+				},
+				Search: omnidex.SearchConfig{
+					IndexPath: "./data/search.bleve",
+					Bleve: search.BleveConfig{
+						PersistInterval: 500 * time.Millisecond,
+						AnalysisWorkers: 8,
+					},
+				},
+			},
+		},
+		{
+			name:        "oidc",
+			expectError: false,
+			configData: validConfig + `oidc:
+  audience: https://docs.example.com
+  owners:
+    - acme
+`,
+			expectConfig: &omnidex.Config{
+				API: api.Config{
+					Listen:  ":8082",
+					APIKeys: []string{"testkey123"},
+				},
+				Storage: omnidex.StorageConfig{
+					Path: "./data/repos",
+				},
+				Search: omnidex.SearchConfig{
+					IndexPath: "./data/search.bleve",
+				},
+				OIDC: oidc.Config{
+					Audience: "https://docs.example.com",
+					Owners:   []string{"acme"},
+				},
+			},
+		},
+		{
+			name:        "republish",
+			expectError: false,
+			configData: validConfig + `republish:
+  token: ghp_token
+  event_type: docs
+`,
+			expectConfig: &omnidex.Config{
+				API: api.Config{
+					Listen:  ":8082",
+					APIKeys: []string{"testkey123"},
+				},
+				Storage: omnidex.StorageConfig{
+					Path: "./data/repos",
+				},
+				Search: omnidex.SearchConfig{
+					IndexPath: "./data/search.bleve",
+				},
+				Republish: github.Config{
+					Token:     "ghp_token",
+					EventType: "docs",
+				},
+			},
+		},
+		{
+			name:        "link check",
+			expectError: false,
+			configData: validConfig + `link_check:
+  enabled: true
+  interval: 12h
+  host_interval: 2s
+`,
+			expectConfig: &omnidex.Config{
+				API: api.Config{
+					Listen:  ":8082",
+					APIKeys: []string{"testkey123"},
+				},
+				Storage: omnidex.StorageConfig{
+					Path: "./data/repos",
+				},
+				Search: omnidex.SearchConfig{
+					IndexPath: "./data/search.bleve",
+				},
+				LinkCheck: linkcheck.Config{
+					Enabled:      true,
+					Interval:     12 * time.Hour,
+					HostInterval: 2 * time.Second,
+				},
+			},
+		},
+		{
+			name:        "freshness",
+			expectError: false,
+			configData: validConfig + `freshness:
+  max_document_age: 4320h
+  max_repo_age: 2160h
+`,
+			expectConfig: &omnidex.Config{
+				API: api.Config{
+					Listen:  ":8082",
+					APIKeys: []string{"testkey123"},
+				},
+				Storage: omnidex.StorageConfig{
+					Path: "./data/repos",
+				},
+				Search: omnidex.SearchConfig{
+					IndexPath: "./data/search.bleve",
+				},
+				Freshness: views.FreshnessConfig{
+					MaxDocumentAge: 180 * 24 * time.Hour,
+					MaxRepoAge:     90 * 24 * time.Hour,
+				},
+			},
+		},
+		{
+			name:        "ui",
+			expectError: false,
+			configData: validConfig + `ui:
+  disable_inline_scripts: true
+  katex_url: https://assets.example.com/katex
+`,
+			expectConfig: &omnidex.Config{
+				API: api.Config{
+					Listen:  ":8082",
+					APIKeys: []string{"testkey123"},
+				},
+				Storage: omnidex.StorageConfig{
+					Path: "./data/repos",
+				},
+				Search: omnidex.SearchConfig{
+					IndexPath: "./data/search.bleve",
+				},
+				UI: omnidex.UIConfig{KaTeXURL: "https://assets.example.com/katex", DisableInlineScripts: true},
+			},
+		},
+		{
+			name:        "dedup",
+			expectError: false,
+			configData: validConfig + `dedup:
+  enabled: true
+`,
+			expectConfig: &omnidex.Config{
+				API: api.Config{
+					Listen:  ":8082",
+					APIKeys: []string{"testkey123"},
+				},
+				Storage: omnidex.StorageConfig{
+					Path: "./data/repos",
+				},
+				Search: omnidex.SearchConfig{
+					IndexPath: "./data/search.bleve",
+				},
+				Dedup: omnidex.DedupConfig{Enabled: true},
+			},
+		},
+		{
+			name:        "render budget",
+			expectError: false,
+			configData: validConfig + `render_budget:
+  enabled: true
+  max_duration: 500ms
+  max_bytes: 2097152
+`,
+			expectConfig: &omnidex.Config{
+				API: api.Config{
+					Listen:  ":8082",
+					APIKeys: []string{"testkey123"},
+				},
+				Storage: omnidex.StorageConfig{
+					Path: "./data/repos",
+				},
+				Search: omnidex.SearchConfig{
+					IndexPath: "./data/search.bleve",
+				},
+				Render: omnidex.RenderBudgetConfig{Enabled: true, MaxDuration: 500 * time.Millisecond, MaxBytes: 2 << 20},
+			},
+		},
+		{
+			name:        "summary",
+			expectError: false,
+			configData: validConfig + `summary:
+  url: https://api.openai.com/v1
+  model: gpt-4o-mini
+  max_input: 4000
+`,
+			expectConfig: &omnidex.Config{
+				API: api.Config{
+					Listen:  ":8082",
+					APIKeys: []string{"testkey123"},
+				},
+				Storage: omnidex.StorageConfig{
+					Path: "./data/repos",
+				},
+				Search: omnidex.SearchConfig{
+					IndexPath: "./data/search.bleve",
+				},
+				Summary: llm.Config{URL: "https://api.openai.com/v1", Model: "gpt-4o-mini", MaxInput: 4000},
+			},
+		},
+		{
+			name:        "load shedding",
+			expectError: false,
+			configData: `
+api:
+  listen: ":8082"
+  api_keys:
+    - testkey123
+  load_shedding:
+    max_in_flight: 64
+    read_reserve: 32
+    queue_timeout: 2s
+storage:
+  path: "./data/repos"
+search:
+  index_path: "./data/search.bleve"
+`,
+			expectConfig: &omnidex.Config{
+				API: api.Config{
+					Listen:       ":8082",
+					APIKeys:      []string{"testkey123"},
+					LoadShedding: api.LoadSheddingConfig{MaxInFlight: 64, ReadReserve: 32, QueueTimeout: 2 * time.Second},
+				},
+				Storage: omnidex.StorageConfig{
+					Path: "./data/repos",
+				},
+				Search: omnidex.SearchConfig{
+					IndexPath: "./data/search.bleve",
+				},
+			},
+		},
+		{
+			name:        "hosts",
+			expectError: false,
+			configData: `
+api:
+  listen: ":8082"
+  api_keys:
+    - testkey123
+  hosts:
+    - host: docs.team-x.example.com
+      name: Team X Docs
+      repos:
+        - team-x
+        - shared/handbook
+storage:
+  path: "./data/repos"
+search:
+  index_path: "./data/search.bleve"
+`,
+			expectConfig: &omnidex.Config{
+				API: api.Config{
+					Listen:  ":8082",
+					APIKeys: []string{"testkey123"},
+					Hosts: []api.HostConfig{{
+						Host:  "docs.team-x.example.com",
+						Name:  "Team X Docs",
+						Repos: []string{"team-x", "shared/handbook"},
+					}},
+				},
+				Storage: omnidex.StorageConfig{
+					Path: "./data/repos",
+				},
+				Search: omnidex.SearchConfig{
 					IndexPath: "./data/search.bleve",
 				},
 			},
@@ -65,15 +351,38 @@ search:
 			},
 			expectError: false,
 			configData:  validConfig,
-			expectConfig: &appConfig{
+			expectConfig: &omnidex.Config{
 				API: api.Config{
 					Listen:  ":8083",
 					APIKeys: []string{"testkey123"},
 				},
-				Storage: StorageConfig{
+				Storage: omnidex.StorageConfig{
+					Path: "./data/repos",
+				},
+				Search: omnidex.SearchConfig{
+					IndexPath: "./data/search.bleve",
+				},
+			},
+		},
+		{
+			name: "mode from environment",
+			envVars: map[string]string{
+				"API_MODE":         "maintenance",
+				"API_MODE_MESSAGE": "Back at 10:00 UTC",
+			},
+			expectError: false,
+			configData:  validConfig,
+			expectConfig: &omnidex.Config{
+				API: api.Config{
+					Listen:      ":8082",
+					APIKeys:     []string{"testkey123"},
+					Mode:        api.ModeMaintenance,
+					ModeMessage: "Back at 10:00 UTC",
+				},
+				Storage: omnidex.StorageConfig{
 					Path: "./data/repos",
 				},
-				Search: SearchConfig{
+				Search: omnidex.SearchConfig{
 					IndexPath: "./data/search.bleve",
 				},
 			},
@@ -118,3 +427,52 @@ search:
 		})
 	}
 }
+
+func TestLoadConfig_LogsSecretsRedacted(t *testing.T) {
+	const configData = `
+api:
+  api_keys: [admin-key-1]
+  signing_keys: ["acme=signing-secret-1"]
+smtp:
+  host: smtp.example.com
+  password: smtp-password-1
+republish:
+  token: github-token-1
+summary:
+  api_key: llm-key-1
+search:
+  semantic:
+    api_key: embed-key-1
+  elasticsearch:
+    password: elastic-password-1
+    api_key: elastic-key-1
+  opensearch:
+    password: opensearch-password-1
+  meilisearch:
+    api_key: meili-key-1
+`
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(configData), 0o600))
+
+	var buf bytes.Buffer
+
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	_, err := loadConfig(&cmdFlags{ConfigPath: configPath})
+	require.NoError(t, err)
+
+	logged := buf.String()
+	assert.Contains(t, logged, "smtp.example.com")
+	assert.Contains(t, logged, "acme=[REDACTED]")
+
+	for _, secret := range []string{
+		"admin-key-1", "signing-secret-1", "smtp-password-1", "github-token-1", "llm-key-1",
+		"embed-key-1", "elastic-password-1", "elastic-key-1", "opensearch-password-1", "meili-key-1",
+	} {
+		assert.NotContains(t, logged, secret)
+	}
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const exportTimeout = 5 * time.Minute
+
+type exportFlags struct {
+	URL       string
+	APIKey    string // sent as a Bearer token to instances requiring readers to sign in
+	Format    string
+	OutputDir string
+}
+
+// newExportCmd creates a cobra command that downloads repositories from an Omnidex instance
+// as e-books.
+func newExportCmd() *cobra.Command {
+	flags := &exportFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "export owner/repo [owner/repo...]",
+		Short: "Export repositories from an Omnidex instance as e-books",
+		Long: "Export the documents of each repository as an EPUB e-book, one chapter per document " +
+			"in the order of the sidebar navigation, written to owner-repo.epub in the output directory.",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runExport(cmd.Context(), cmd.OutOrStdout(), flags, args)
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.URL, "url", "http://localhost:8080", "base URL of the Omnidex instance")
+	cmd.Flags().StringVar(&flags.APIKey, "api-key", "", "Bearer token for instances requiring readers to sign in")
+	cmd.Flags().StringVar(&flags.Format, "format", "epub", "export format: epub")
+	cmd.Flags().StringVar(&flags.OutputDir, "output-dir", ".", "directory the exported files are written to")
+
+	if val := os.Getenv("OMNIDEX_URL"); val != "" {
+		_ = cmd.Flags().Set("url", val)
+	}
+
+	if val := os.Getenv("OMNIDEX_API_KEY"); val != "" {
+		_ = cmd.Flags().Set("api-key", val)
+	}
+
+	return cmd
+}
+
+// runExport downloads each repository in the requested format into the output directory and
+// reports the written files to w.
+func runExport(ctx context.Context, w io.Writer, flags *exportFlags, repos []string) error {
+	if flags.Format != "epub" {
+		return validationError(fmt.Errorf("--format must be epub: %q", flags.Format))
+	}
+
+	for i, repo := range repos {
+		repos[i] = strings.Trim(repo, "/")
+
+		if strings.Count(repos[i], "/") != 1 {
+			return validationError(fmt.Errorf("expected a repository as owner/repo, got %q", repo))
+		}
+	}
+
+	for _, repo := range repos {
+		file := filepath.Join(flags.OutputDir, strings.ReplaceAll(repo, "/", "-")+".epub")
+
+		if err := exportRepo(ctx, flags, repo, file); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(w, "Exported %s to %s\n", repo, file)
+	}
+
+	return nil
+}
+
+// exportRepo downloads the EPUB export of repo and writes it to file. A partially downloaded
+// export is never left behind.
+func exportRepo(ctx context.Context, flags *exportFlags, repo, file string) error {
+	ctx, cancel := context.WithTimeout(ctx, exportTimeout)
+	defer cancel()
+
+	endpoint := strings.TrimRight(flags.URL, "/") + "/epub/" + repo + "/"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, http.NoBody)
+	if err != nil {
+		return validationError(fmt.Errorf("failed to create request: %w", err))
+	}
+
+	if flags.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+flags.APIKey)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return &ExitError{Err: fmt.Errorf("failed to export %s: %w", repo, err), Code: ExitCodeServer}
+	}
+
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return &ExitError{Err: fmt.Errorf("repository %s not found", repo), Code: ExitCodeServer}
+	case resp.StatusCode == http.StatusUnprocessableEntity:
+		return &ExitError{Err: fmt.Errorf("repository %s is too large to export", repo), Code: ExitCodeServer}
+	case resp.StatusCode != http.StatusOK:
+		return &ExitError{Err: fmt.Errorf("server returned HTTP %d", resp.StatusCode), Code: ExitCodeServer}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(file), ".omnidex-export-*")
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, resp.Body); err != nil {
+		_ = tmp.Close()
+		return &ExitError{Err: fmt.Errorf("failed to download %s: %w", repo, err), Code: ExitCodeServer}
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), file); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunExport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/epub/owner/repo/":
+			_, _ = w.Write([]byte("EPUB repo"))
+		case "/epub/owner/docs/":
+			_, _ = w.Write([]byte("EPUB docs"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+
+	var buf bytes.Buffer
+
+	require.NoError(t, runExport(t.Context(), &buf, &exportFlags{URL: srv.URL + "/", Format: "epub", OutputDir: dir}, []string{"owner/repo", "/owner/docs/"}))
+
+	data, err := os.ReadFile(filepath.Join(dir, "owner-repo.epub"))
+	require.NoError(t, err)
+	assert.Equal(t, "EPUB repo", string(data))
+
+	data, err = os.ReadFile(filepath.Join(dir, "owner-docs.epub"))
+	require.NoError(t, err)
+	assert.Equal(t, "EPUB docs", string(data))
+
+	assert.Contains(t, buf.String(), "Exported owner/repo to "+filepath.Join(dir, "owner-repo.epub"))
+	assert.Contains(t, buf.String(), "Exported owner/docs to "+filepath.Join(dir, "owner-docs.epub"))
+}
+
+func TestRunExport_Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/epub/owner/broken/":
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		case "/epub/owner/huge/":
+			http.Error(w, "too many documents", http.StatusUnprocessableEntity)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		format   string
+		url      string
+		repo     string
+		wantErr  string
+		wantCode int
+	}{
+		{name: "invalid format", format: "pdf", url: srv.URL, repo: "owner/repo", wantErr: "--format", wantCode: ExitCodeValidation},
+		{name: "invalid repo", format: "epub", url: srv.URL, repo: "owner", wantErr: "owner/repo", wantCode: ExitCodeValidation},
+		{name: "not found", format: "epub", url: srv.URL, repo: "owner/missing", wantErr: "repository owner/missing not found", wantCode: ExitCodeServer},
+		{name: "too large", format: "epub", url: srv.URL, repo: "owner/huge", wantErr: "too large to export", wantCode: ExitCodeServer},
+		{name: "server error", format: "epub", url: srv.URL, repo: "owner/broken", wantErr: "server returned HTTP 500", wantCode: ExitCodeServer},
+		{name: "server down", format: "epub", url: "http://localhost:1", repo: "owner/repo", wantErr: "failed to export owner/repo", wantCode: ExitCodeServer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			var buf bytes.Buffer
+
+			err := runExport(t.Context(), &buf, &exportFlags{URL: tt.url, Format: tt.format, OutputDir: dir}, []string{tt.repo})
+			require.ErrorContains(t, err, tt.wantErr)
+			assert.Equal(t, tt.wantCode, ExitCode(err))
+			assert.Empty(t, buf.String())
+
+			entries, err := os.ReadDir(dir)
+			require.NoError(t, err)
+			assert.Empty(t, entries, "failed exports leave no files behind")
+		})
+	}
+}
+
+func TestNewExportCmd(t *testing.T) {
+	cmd := newExportCmd()
+
+	assert.Equal(t, "export", cmd.Name())
+	assert.Equal(t, "epub", cmd.Flags().Lookup("format").DefValue)
+	assert.Equal(t, ".", cmd.Flags().Lookup("output-dir").DefValue)
+	assert.Error(t, cmd.Args(cmd, []string{}))
+}
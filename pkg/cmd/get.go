@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const getTimeout = 30 * time.Second
+
+// getFormats maps the formats accepted by the get command to the server routes serving them.
+var getFormats = map[string]string{
+	"raw":  "/raw/",
+	"html": "/html/",
+	"text": "/text/",
+}
+
+type getFlags struct {
+	URL    string
+	APIKey string // sent as a Bearer token to instances requiring readers to sign in
+	Format string
+}
+
+// newGetCmd creates a cobra command that fetches a stored document from an Omnidex instance
+// and writes it to stdout.
+func newGetCmd() *cobra.Command {
+	flags := &getFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "get owner/repo path",
+		Short: "Fetch a published document from an Omnidex instance",
+		Long: "Fetch a document as it is published on an Omnidex instance and write it to stdout: " +
+			"the raw source, the rendered HTML body or its plain text.",
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runGet(cmd.Context(), cmd.OutOrStdout(), flags, args[0], args[1])
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.URL, "url", "http://localhost:8080", "base URL of the Omnidex instance")
+	cmd.Flags().StringVar(&flags.APIKey, "api-key", "", "Bearer token for instances requiring readers to sign in")
+	cmd.Flags().StringVar(&flags.Format, "format", "raw", "document format: raw, html or text")
+
+	if val := os.Getenv("OMNIDEX_URL"); val != "" {
+		_ = cmd.Flags().Set("url", val)
+	}
+
+	if val := os.Getenv("OMNIDEX_API_KEY"); val != "" {
+		_ = cmd.Flags().Set("api-key", val)
+	}
+
+	return cmd
+}
+
+// runGet fetches the document at path in repo in the requested format and writes it to w.
+func runGet(ctx context.Context, w io.Writer, flags *getFlags, repo, path string) error {
+	route, ok := getFormats[flags.Format]
+	if !ok {
+		return validationError(fmt.Errorf("--format must be raw, html or text: %q", flags.Format))
+	}
+
+	repo = strings.Trim(repo, "/")
+	path = strings.TrimLeft(path, "/")
+
+	if !strings.Contains(repo, "/") || path == "" {
+		return validationError(fmt.Errorf("expected a repository as owner/repo and a document path, got %q %q", repo, path))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, getTimeout)
+	defer cancel()
+
+	endpoint := strings.TrimRight(flags.URL, "/") + route + (&url.URL{Path: repo + "/" + path}).EscapedPath()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, http.NoBody)
+	if err != nil {
+		return validationError(fmt.Errorf("failed to create request: %w", err))
+	}
+
+	if flags.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+flags.APIKey)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return &ExitError{Err: fmt.Errorf("failed to fetch document: %w", err), Code: ExitCodeServer}
+	}
+
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return &ExitError{Err: fmt.Errorf("document %s/%s not found", repo, path), Code: ExitCodeServer}
+	case resp.StatusCode != http.StatusOK:
+		return &ExitError{Err: fmt.Errorf("server returned HTTP %d", resp.StatusCode), Code: ExitCodeServer}
+	}
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("failed to write document: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunGet(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/raw/owner/repo/docs/run book.md":
+			_, _ = w.Write([]byte("# Runbook"))
+		case "/html/owner/repo/docs/run book.md":
+			_, _ = w.Write([]byte("<h1>Runbook</h1>"))
+		case "/text/owner/repo/docs/run book.md":
+			_, _ = w.Write([]byte("Runbook"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: "raw", want: "# Runbook"},
+		{format: "html", want: "<h1>Runbook</h1>"},
+		{format: "text", want: "Runbook"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			require.NoError(t, runGet(t.Context(), &buf, &getFlags{URL: srv.URL + "/", Format: tt.format}, "owner/repo", "docs/run book.md"))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestRunGet_APIKey(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer reader-key" {
+			http.Error(w, "sign in required", http.StatusUnauthorized)
+			return
+		}
+
+		_, _ = w.Write([]byte("# Runbook"))
+	}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+
+	require.NoError(t, runGet(t.Context(), &buf, &getFlags{URL: srv.URL, APIKey: "reader-key", Format: "raw"}, "owner/repo", "runbook.md"))
+	assert.Equal(t, "# Runbook", buf.String())
+
+	err := runGet(t.Context(), &buf, &getFlags{URL: srv.URL, Format: "raw"}, "owner/repo", "runbook.md")
+	require.Error(t, err)
+	assert.Equal(t, ExitCodeServer, ExitCode(err))
+}
+
+func TestRunGet_Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/raw/owner/repo/broken.md" {
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		format   string
+		url      string
+		repo     string
+		path     string
+		wantErr  string
+		wantCode int
+	}{
+		{name: "invalid format", format: "pdf", url: srv.URL, repo: "owner/repo", path: "a.md", wantErr: "--format", wantCode: ExitCodeValidation},
+		{name: "invalid repo", format: "raw", url: srv.URL, repo: "owner", path: "a.md", wantErr: "owner/repo", wantCode: ExitCodeValidation},
+		{name: "not found", format: "raw", url: srv.URL, repo: "owner/repo", path: "missing.md", wantErr: "document owner/repo/missing.md not found", wantCode: ExitCodeServer},
+		{name: "server error", format: "raw", url: srv.URL, repo: "owner/repo", path: "broken.md", wantErr: "server returned HTTP 500", wantCode: ExitCodeServer},
+		{name: "server down", format: "raw", url: "http://localhost:1", repo: "owner/repo", path: "a.md", wantErr: "failed to fetch document", wantCode: ExitCodeServer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			err := runGet(t.Context(), &buf, &getFlags{URL: tt.url, Format: tt.format}, tt.repo, tt.path)
+			require.ErrorContains(t, err, tt.wantErr)
+			assert.Equal(t, tt.wantCode, ExitCode(err))
+			assert.Empty(t, buf.String())
+		})
+	}
+}
+
+func TestNewGetCmd(t *testing.T) {
+	cmd := newGetCmd()
+
+	assert.Equal(t, "get", cmd.Name())
+	assert.Equal(t, "raw", cmd.Flags().Lookup("format").DefValue)
+	assert.Equal(t, "http://localhost:8080", cmd.Flags().Lookup("url").DefValue)
+	assert.Error(t, cmd.Args(cmd, []string{"owner/repo"}))
+}
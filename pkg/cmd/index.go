@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/spf13/cobra"
+)
+
+// archiveTimeout bounds the export or import of an instance archive, which copies every
+// document and asset.
+const archiveTimeout = 30 * time.Minute
+
+// newIndexCmd creates the index command, whose subcommands copy the published content of
+// an Omnidex instance to another one through a portable archive.
+func newIndexCmd() *cobra.Command {
+	flags := &adminFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "index",
+		Short: "Export and import the content of an Omnidex instance",
+		Long: "Export the documents, assets and redirects of a running Omnidex instance as a portable archive, " +
+			"and import such an archive into another instance, for example to seed a staging environment " +
+			"with production content or to reproduce a support case. The importing instance rebuilds its " +
+			"search index from the archived documents, whatever its search backend.",
+	}
+
+	cmd.PersistentFlags().StringVar(&flags.URL, "url", "", "base URL of the Omnidex instance")
+	cmd.PersistentFlags().StringVar(&flags.APIKey, "api-key", "", "Bearer token for authentication")
+	cmd.PersistentFlags().StringVar(&flags.Output, "output", outputText, "output format: text or json")
+
+	for flagName, envVar := range map[string]string{
+		"url":     "OMNIDEX_URL",
+		"api-key": "OMNIDEX_API_KEY",
+		"output":  "OMNIDEX_OUTPUT",
+	} {
+		if val := os.Getenv(envVar); val != "" {
+			_ = cmd.PersistentFlags().Set(flagName, val)
+		}
+	}
+
+	cmd.AddCommand(newIndexExportCmd(flags), newIndexImportCmd(flags))
+
+	return cmd
+}
+
+// newIndexExportCmd creates the index export subcommand.
+func newIndexExportCmd(flags *adminFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "export file.tar.gz",
+		Short: "Export the documents, assets and redirects of the instance to an archive",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runIndexExport(cmd.Context(), cmd.OutOrStdout(), flags, args[0])
+		},
+	}
+}
+
+// newIndexImportCmd creates the index import subcommand.
+func newIndexImportCmd(flags *adminFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "import file.tar.gz",
+		Short: "Import an archive written by index export, replacing documents and assets at the same paths",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runIndexImport(cmd.Context(), cmd.OutOrStdout(), flags, args[0])
+		},
+	}
+}
+
+// runIndexExport downloads the archive of the instance to file and reports it to w. A
+// partially downloaded archive is never left behind.
+func runIndexExport(ctx context.Context, w io.Writer, flags *adminFlags, file string) error {
+	if err := validateAdminFlags(flags); err != nil {
+		return err
+	}
+
+	resp, err := sendArchiveRequest(ctx, flags, http.MethodGet, http.NoBody)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(file), ".omnidex-archive-*")
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+
+	defer os.Remove(tmp.Name())
+
+	size, err := io.Copy(tmp, resp.Body)
+	if err != nil {
+		_ = tmp.Close()
+		return &ExitError{Err: fmt.Errorf("failed to download archive: %w", err), Code: ExitCodeServer}
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), file); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+
+	body, err := json.Marshal(map[string]any{"file": file, "bytes": size})
+	if err != nil {
+		return fmt.Errorf("failed to marshal output: %w", err)
+	}
+
+	return writeAdminOutput(w, flags, adminCall{render: func(w io.Writer, _ []byte) error {
+		_, err := fmt.Fprintf(w, "Exported %d bytes to %s\n", size, file)
+		return err
+	}}, body)
+}
+
+// runIndexImport uploads the archive in file to the instance and reports what it imported
+// to w.
+func runIndexImport(ctx context.Context, w io.Writer, flags *adminFlags, file string) error {
+	if err := validateAdminFlags(flags); err != nil {
+		return err
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return validationError(fmt.Errorf("failed to open archive: %w", err))
+	}
+
+	defer f.Close()
+
+	resp, err := sendArchiveRequest(ctx, flags, http.MethodPost, f)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return &ExitError{Err: fmt.Errorf("failed to read response body: %w", err), Code: ExitCodeServer}
+	}
+
+	return writeAdminOutput(w, flags, adminCall{render: renderImport}, body)
+}
+
+// renderImport writes the outcome of an archive import as text.
+func renderImport(w io.Writer, body []byte) error {
+	var resp core.ImportResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return &ExitError{Err: fmt.Errorf("failed to parse response: %w", err), Code: ExitCodeServer}
+	}
+
+	if _, err := fmt.Fprintf(w, "Imported %d documents, %d assets and %d redirects\n",
+		resp.Documents, resp.Assets, resp.Redirects); err != nil {
+		return err
+	}
+
+	for _, issue := range resp.Lint {
+		if _, err := fmt.Fprintf(w, "  lint: %s:%d: %s: %s\n", issue.Path, issue.Line, issue.Rule, issue.Message); err != nil {
+			return err
+		}
+	}
+
+	for _, finding := range resp.Policy {
+		if _, err := fmt.Fprintf(w, "  policy: %s:%d: %s (%s)\n", finding.Path, finding.Line, finding.Rule, finding.Action); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range resp.Rejected {
+		if _, err := fmt.Fprintf(w, "  rejected: %s: %s\n", f.Path, f.Error); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range resp.Failed {
+		if _, err := fmt.Fprintf(w, "  not indexed: %s: %s\n", f.Path, f.Error); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sendArchiveRequest performs a request to the archive endpoint and returns the successful
+// response, whose body the caller must close. Failures to reach the server and error
+// responses are returned as server errors.
+func sendArchiveRequest(ctx context.Context, flags *adminFlags, method string, body io.Reader) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, archiveTimeout)
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(flags.URL, "/")+"/api/v1/archive", body)
+	if err != nil {
+		cancel()
+		return nil, validationError(fmt.Errorf("failed to create request: %w", err))
+	}
+
+	req.Header.Set("Authorization", "Bearer "+flags.APIKey)
+
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/gzip")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		cancel()
+		return nil, &ExitError{Err: fmt.Errorf("HTTP request failed: %w", err), Code: ExitCodeServer}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		cancel()
+
+		return nil, &ExitError{
+			Err:  fmt.Errorf("server returned HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(msg))),
+			Code: ExitCodeServer,
+		}
+	}
+
+	resp.Body = cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+
+	return resp, nil
+}
+
+// cancelOnClose cancels the context of a response when its body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c cancelOnClose) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newArchiveServer returns a server implementing the archive endpoint, which exports
+// archive and records the archive imported into it.
+func newArchiveServer(t *testing.T, archive []byte, imported *bytes.Buffer) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer admin-key" {
+			http.Error(w, "invalid API key", http.StatusUnauthorized)
+			return
+		}
+
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v1/archive":
+			w.Header().Set("Content-Type", "application/gzip")
+			_, _ = w.Write(archive)
+		case "POST /api/v1/archive":
+			assert.Equal(t, "application/gzip", r.Header.Get("Content-Type"))
+
+			_, _ = io.Copy(imported, r.Body)
+			_, _ = w.Write([]byte(`{"documents":3,"assets":2,"redirects":1,"failed":[{"path":"owner/repo/bad.md","error":"index unavailable"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestIndexExportImport(t *testing.T) {
+	var imported bytes.Buffer
+
+	srv := newArchiveServer(t, []byte("archive-bytes"), &imported)
+	file := filepath.Join(t.TempDir(), "prod.tar.gz")
+	flags := &adminFlags{URL: srv.URL, APIKey: "admin-key", Output: outputText}
+
+	var out bytes.Buffer
+
+	require.NoError(t, runIndexExport(t.Context(), &out, flags, file))
+	assert.Equal(t, "Exported 13 bytes to "+file+"\n", out.String())
+
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "archive-bytes", string(data))
+
+	out.Reset()
+
+	require.NoError(t, runIndexImport(t.Context(), &out, flags, file))
+	assert.Equal(t, "archive-bytes", imported.String())
+	assert.Equal(t, "Imported 3 documents, 2 assets and 1 redirects\n  not indexed: owner/repo/bad.md: index unavailable\n", out.String())
+
+	out.Reset()
+	flags.Output = outputJSON
+
+	require.NoError(t, runIndexImport(t.Context(), &out, flags, file))
+	assert.JSONEq(t, `{"documents":3,"assets":2,"redirects":1,"failed":[{"path":"owner/repo/bad.md","error":"index unavailable"}]}`, out.String())
+}
+
+func TestIndexExport_ServerError(t *testing.T) {
+	srv := newArchiveServer(t, nil, nil)
+	dir := t.TempDir()
+	flags := &adminFlags{URL: srv.URL, APIKey: "wrong-key", Output: outputText}
+
+	err := runIndexExport(t.Context(), io.Discard, flags, filepath.Join(dir, "prod.tar.gz"))
+	require.Error(t, err)
+	assert.Equal(t, ExitCodeServer, ExitCode(err))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "no partial archive is left behind")
+}
+
+func TestIndexImport_MissingFile(t *testing.T) {
+	flags := &adminFlags{URL: "http://localhost:8080", APIKey: "admin-key", Output: outputText}
+
+	err := runIndexImport(t.Context(), io.Discard, flags, filepath.Join(t.TempDir(), "missing.tar.gz"))
+	require.Error(t, err)
+	assert.Equal(t, ExitCodeValidation, ExitCode(err))
+}
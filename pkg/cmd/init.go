@@ -61,8 +61,12 @@ func InitCommand(build BuildInfo) cobra.Command {
 
 	healthCmd := newHealthCmd()
 	publishCmd := newPublishCmd(&flags)
+	getCmd := newGetCmd()
+	exportCmd := newExportCmd()
+	adminCmd := newAdminCmd()
+	indexCmd := newIndexCmd()
 
-	cmd.AddCommand(serveCmd, healthCmd, publishCmd)
+	cmd.AddCommand(serveCmd, healthCmd, publishCmd, getCmd, exportCmd, adminCmd, indexCmd)
 
 	return cmd
 }
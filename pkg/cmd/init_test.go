@@ -16,18 +16,22 @@ func TestInitCommand(t *testing.T) {
 	assert.NotEmpty(t, cmd.Short)
 	assert.NotEmpty(t, cmd.Long)
 
-	require.Len(t, cmd.Commands(), 3)
+	require.Len(t, cmd.Commands(), 7)
 
 	subCmds := cmd.Commands()
 	names := make([]string, 0, len(subCmds))
 
 	for _, sub := range subCmds {
-		names = append(names, sub.Use)
+		names = append(names, sub.Name())
 	}
 
 	assert.Contains(t, names, "serve")
 	assert.Contains(t, names, "health")
 	assert.Contains(t, names, "publish")
+	assert.Contains(t, names, "get")
+	assert.Contains(t, names, "export")
+	assert.Contains(t, names, "admin")
+	assert.Contains(t, names, "index")
 
 	assert.Equal(t, "info", cmd.PersistentFlags().Lookup("log-level").DefValue)
 	assert.Equal(t, "true", cmd.PersistentFlags().Lookup("log-text").DefValue)
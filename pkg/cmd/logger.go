@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"io"
 	"log/slog"
 	"os"
 
@@ -35,6 +36,11 @@ func (h ContextHandler) Handle(ctx context.Context, r slog.Record) error {
 // It does not take any parameters.
 // It returns an error if the logger initialization fails, although in this implementation, it always returns nil.
 func initLogger(flags *cmdFlags) error {
+	return initLoggerTo(flags, os.Stdout)
+}
+
+// initLoggerTo initializes the default logger like initLogger, writing the log records to w.
+func initLoggerTo(flags *cmdFlags, w io.Writer) error {
 	var logLevel slog.Level
 	if err := logLevel.UnmarshalText([]byte(flags.LogLevel)); err != nil {
 		return err
@@ -46,9 +52,9 @@ func initLogger(flags *cmdFlags) error {
 
 	var logHandler slog.Handler
 	if flags.TextFormat {
-		logHandler = slog.NewTextHandler(os.Stdout, options)
+		logHandler = slog.NewTextHandler(w, options)
 	} else {
-		logHandler = slog.NewJSONHandler(os.Stdout, options)
+		logHandler = slog.NewJSONHandler(w, options)
 	}
 
 	ctxHandler := &ContextHandler{
@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/ksysoev/omnidex/pkg/publisher"
+)
+
+// Exit codes returned by the CLI, so that pipelines can branch on the outcome of a command.
+const (
+	// ExitCodeError is returned for failures that do not fall into a more specific category.
+	ExitCodeError = 1
+	// ExitCodeValidation is returned when the command's input is invalid: missing or malformed
+	// flags, an invalid targets file or documentation files that cannot be read.
+	ExitCodeValidation = 2
+	// ExitCodeServer is returned when the Omnidex server could not be reached or rejected the request.
+	ExitCodeServer = 3
+	// ExitCodePartial is returned when publishing succeeded for some targets and failed for others.
+	ExitCodePartial = 4
+)
+
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// ExitError is an error that carries the process exit code the CLI should terminate with.
+type ExitError struct {
+	Err  error
+	Code int
+}
+
+// Error returns the message of the underlying error.
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the process exit code for the error returned by a command: 0 for nil,
+// the code of an ExitError and ExitCodeError for any other error.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+
+	return ExitCodeError
+}
+
+// validationError marks err as an invalid input error.
+func validationError(err error) error {
+	return &ExitError{Err: err, Code: ExitCodeValidation}
+}
+
+// publishError classifies the error of a single publish: failures reported by the server
+// are server errors, anything else is a problem with the local input.
+func publishError(err error) error {
+	var srvErr *publisher.ServerError
+	if errors.As(err, &srvErr) {
+		return &ExitError{Err: err, Code: ExitCodeServer}
+	}
+
+	return validationError(err)
+}
+
+// targetsError classifies the error of a publish to multiple targets. It is a partial
+// failure when at least one target succeeded; otherwise the targets' errors decide, as for
+// a single publish.
+func targetsError(err error, results []publisher.TargetResult) error {
+	if err == nil {
+		return nil
+	}
+
+	failed := 0
+
+	var srvErr *publisher.ServerError
+
+	for _, res := range results {
+		if res.Err == nil {
+			continue
+		}
+
+		failed++
+
+		if srvErr == nil {
+			errors.As(res.Err, &srvErr)
+		}
+	}
+
+	switch {
+	case failed > 0 && failed < len(results):
+		return &ExitError{Err: err, Code: ExitCodePartial}
+	case srvErr != nil:
+		return &ExitError{Err: err, Code: ExitCodeServer}
+	default:
+		return validationError(err)
+	}
+}
+
+// publishOutput is the machine-readable result document written by publish --output json.
+type publishOutput struct {
+	Status   string                `json:"status"`
+	Repo     string                `json:"repo,omitempty"`
+	Error    string                `json:"error,omitempty"`
+	Targets  []publishTargetOutput `json:"targets"`
+	ExitCode int                   `json:"exit_code"`
+}
+
+// publishTargetOutput is the outcome of publishing to a single target.
+type publishTargetOutput struct {
+	Name    string                `json:"name"`
+	URL     string                `json:"url"`
+	Status  string                `json:"status"`
+	Error   string                `json:"error,omitempty"`
+	Skipped []string              `json:"skipped"`
+	Lint    []core.LintIssue      `json:"lint"`
+	Policy  []core.PolicyFinding  `json:"policy"`
+	Failed  []core.FailedDocument `json:"failed"`
+	Indexed int                   `json:"indexed"`
+	Deleted int                   `json:"deleted"`
+	Moved   int                   `json:"moved"`
+}
+
+// writePublishJSON writes the result document of a publish. err is the error returned by
+// the publish, if any; its exit code decides the overall status.
+func writePublishJSON(w io.Writer, repo string, results []publisher.TargetResult, err error) error {
+	out := publishOutput{
+		Status:   "success",
+		Repo:     repo,
+		Targets:  make([]publishTargetOutput, 0, len(results)),
+		ExitCode: ExitCode(err),
+	}
+
+	switch out.ExitCode {
+	case 0:
+	case ExitCodePartial:
+		out.Status = "partial"
+	default:
+		out.Status = "failed"
+	}
+
+	if err != nil {
+		out.Error = err.Error()
+	}
+
+	for _, res := range results {
+		target := publishTargetOutput{
+			Name:    res.Target,
+			URL:     res.URL,
+			Status:  "success",
+			Skipped: []string{},
+			Lint:    []core.LintIssue{},
+			Policy:  []core.PolicyFinding{},
+			Failed:  []core.FailedDocument{},
+		}
+
+		switch {
+		case res.Err != nil:
+			target.Status = "failed"
+			target.Error = res.Err.Error()
+		case res.Result != nil:
+			target.Indexed = res.Result.Indexed
+			target.Deleted = res.Result.Deleted
+			target.Moved = res.Result.Moved
+
+			if res.Result.Skipped != nil {
+				target.Skipped = res.Result.Skipped
+			}
+
+			if res.Result.Lint != nil {
+				target.Lint = res.Result.Lint
+			}
+
+			if res.Result.Policy != nil {
+				target.Policy = res.Result.Policy
+			}
+
+			if res.Result.Failed != nil {
+				target.Failed = res.Result.Failed
+			}
+		}
+
+		out.Targets = append(out.Targets, target)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(out); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/ksysoev/omnidex/pkg/publisher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExitCode(t *testing.T) {
+	assert.Equal(t, 0, ExitCode(nil))
+	assert.Equal(t, ExitCodeError, ExitCode(errors.New("boom")))
+	assert.Equal(t, ExitCodeValidation, ExitCode(validationError(errors.New("bad flag"))))
+	assert.Equal(t, ExitCodeServer, ExitCode(fmt.Errorf("wrapped: %w", &ExitError{Err: errors.New("down"), Code: ExitCodeServer})))
+}
+
+func TestPublishError(t *testing.T) {
+	srvErr := fmt.Errorf("failed to publish documentation: %w", &publisher.ServerError{Err: errors.New("server returned HTTP 500"), StatusCode: 500})
+
+	assert.Equal(t, ExitCodeServer, ExitCode(publishError(srvErr)))
+	assert.Equal(t, ExitCodeValidation, ExitCode(publishError(errors.New("failed to collect files"))))
+}
+
+func TestTargetsError(t *testing.T) {
+	srvErr := &publisher.ServerError{Err: errors.New("server returned HTTP 500")}
+	errFailed := errors.New("failed to publish to targets")
+
+	tests := []struct {
+		name    string
+		results []publisher.TargetResult
+		want    int
+	}{
+		{
+			name:    "partial",
+			results: []publisher.TargetResult{{Err: srvErr}, {Result: &publisher.Result{IngestResponse: &core.IngestResponse{}}}},
+			want:    ExitCodePartial,
+		},
+		{
+			name:    "all failed on the server",
+			results: []publisher.TargetResult{{Err: errors.New("invalid include pattern")}, {Err: srvErr}},
+			want:    ExitCodeServer,
+		},
+		{
+			name:    "all failed locally",
+			results: []publisher.TargetResult{{Err: errors.New("invalid include pattern")}},
+			want:    ExitCodeValidation,
+		},
+		{
+			name: "files not collected",
+			want: ExitCodeValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ExitCode(targetsError(errFailed, tt.results)))
+		})
+	}
+
+	assert.NoError(t, targetsError(nil, nil))
+}
+
+func TestWritePublishJSON(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := &ExitError{Err: errors.New("failed to publish to 1 of 2 targets"), Code: ExitCodePartial}
+
+	require.NoError(t, writePublishJSON(&buf, "owner/repo", testPublishResults(), err))
+
+	var out publishOutput
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+
+	assert.Equal(t, "partial", out.Status)
+	assert.Equal(t, ExitCodePartial, out.ExitCode)
+	assert.Equal(t, "owner/repo", out.Repo)
+	assert.Equal(t, "failed to publish to 1 of 2 targets", out.Error)
+	require.Len(t, out.Targets, 2)
+
+	assert.Equal(t, "internal", out.Targets[0].Name)
+	assert.Equal(t, "success", out.Targets[0].Status)
+	assert.Equal(t, 3, out.Targets[0].Indexed)
+	assert.Equal(t, 1, out.Targets[0].Deleted)
+	assert.Equal(t, []string{"config.yaml"}, out.Targets[0].Skipped)
+	assert.Len(t, out.Targets[0].Lint, 1)
+	assert.Len(t, out.Targets[0].Policy, 1)
+	assert.Equal(t, []core.FailedDocument{{Path: "broken.md", Error: "content processing panicked: nil node"}}, out.Targets[0].Failed)
+
+	assert.Equal(t, "partner", out.Targets[1].Name)
+	assert.Equal(t, "failed", out.Targets[1].Status)
+	assert.Equal(t, "server returned HTTP 401: unauthorized", out.Targets[1].Error)
+}
+
+func TestWritePublishJSON_ValidationFailure(t *testing.T) {
+	var buf bytes.Buffer
+
+	require.NoError(t, writePublishJSON(&buf, "", nil, validationError(errors.New("--repo (or GITHUB_REPOSITORY) is required"))))
+
+	assert.JSONEq(t, `{
+		"status": "failed",
+		"error": "--repo (or GITHUB_REPOSITORY) is required",
+		"targets": [],
+		"exit_code": 2
+	}`, buf.String())
+}
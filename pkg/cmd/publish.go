@@ -2,22 +2,45 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"strings"
 
 	"github.com/ksysoev/omnidex/pkg/publisher"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
 type publishFlags struct {
-	URL         string
-	APIKey      string
-	DocsPath    string
-	FilePattern string
-	Repo        string
-	CommitSHA   string
-	Sync        bool
+	URL          string
+	APIKey       string
+	OIDCAudience string
+	SigningKey   string
+	DocsPath     string
+	FilePattern  string
+	Repo         string
+	Project      string
+	CommitSHA    string
+	Targets      string
+	Output       string
+	Concurrency  int
+	Sync         bool
+}
+
+// targetsFile is the format of the file passed with --targets. API keys and signing keys
+// are read from the environment variables named by api_key_env and signing_key_env, so the
+// file itself holds no secrets.
+type targetsFile struct {
+	Targets []struct {
+		Name          string `yaml:"name"`
+		URL           string `yaml:"url"`
+		APIKeyEnv     string `yaml:"api_key_env"`
+		SigningKeyEnv string `yaml:"signing_key_env"`
+		Include       string `yaml:"include"`
+	} `yaml:"targets"`
 }
 
 // newPublishCmd creates a cobra command that publishes documentation files to an Omnidex instance.
@@ -28,7 +51,8 @@ func newPublishCmd(flags *cmdFlags) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "publish",
 		Short: "Publish documentation files to an Omnidex instance",
-		Long:  "Walk a documentation directory, match files by glob pattern, and publish them to an Omnidex instance via the ingest API.",
+		Long: "Walk a documentation directory, match files by glob pattern, and publish them to an Omnidex instance via the ingest API. " +
+			"Without a pattern, the root README.md and CHANGELOG.md and the docs/ directory are published.",
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			return runPublish(cmd.Context(), flags, pubFlags)
 		},
@@ -36,10 +60,22 @@ func newPublishCmd(flags *cmdFlags) *cobra.Command {
 
 	cmd.Flags().StringVar(&pubFlags.URL, "url", "", "base URL of the Omnidex instance")
 	cmd.Flags().StringVar(&pubFlags.APIKey, "api-key", "", "Bearer token for authentication")
+	cmd.Flags().StringVar(&pubFlags.OIDCAudience, "oidc-audience", "",
+		"authenticate with a GitHub Actions OIDC ID token requested for this audience instead of an API key")
+	cmd.Flags().StringVar(&pubFlags.SigningKey, "signing-key", "",
+		"shared secret used to sign the ingest payload, so the server can verify its provenance")
 	cmd.Flags().StringVar(&pubFlags.DocsPath, "docs-path", ".", "path to the documentation directory")
-	cmd.Flags().StringVar(&pubFlags.FilePattern, "file-pattern", "**/*.md", "glob pattern for documentation files")
+	cmd.Flags().StringVar(&pubFlags.FilePattern, "file-pattern", "",
+		"glob pattern for documentation files (default: README.md, CHANGELOG.md and docs/**)")
 	cmd.Flags().StringVar(&pubFlags.Repo, "repo", "", "repository identifier (owner/repo)")
+	cmd.Flags().StringVar(&pubFlags.Project, "project", "",
+		"sub-project name for monorepos publishing several doc sets; published as owner/repo/project")
 	cmd.Flags().StringVar(&pubFlags.CommitSHA, "commit-sha", "", "git commit SHA")
+	cmd.Flags().StringVar(&pubFlags.Targets, "targets", "",
+		"YAML file listing several Omnidex instances to publish to; replaces --url and --api-key")
+	cmd.Flags().StringVar(&pubFlags.Output, "output", outputText,
+		"output format: text for logs only, json to also write a result document to stdout (logs go to stderr)")
+	cmd.Flags().IntVar(&pubFlags.Concurrency, "concurrency", 2, "maximum number of targets published to at the same time")
 	cmd.Flags().BoolVar(&pubFlags.Sync, "sync", true, "enable full sync mode to remove stale documents not present in this publish")
 
 	// Bind environment variables as defaults for flags that are not explicitly set.
@@ -51,13 +87,19 @@ func newPublishCmd(flags *cmdFlags) *cobra.Command {
 // bindEnvDefaults sets flag defaults from environment variables when the flags are not explicitly provided.
 func bindEnvDefaults(cmd *cobra.Command, _ *publishFlags) {
 	envBindings := map[string]string{
-		"url":          "OMNIDEX_URL",
-		"api-key":      "OMNIDEX_API_KEY",
-		"docs-path":    "DOCS_PATH",
-		"file-pattern": "FILE_PATTERN",
-		"repo":         "GITHUB_REPOSITORY",
-		"commit-sha":   "GITHUB_SHA",
-		"sync":         "OMNIDEX_SYNC",
+		"url":           "OMNIDEX_URL",
+		"api-key":       "OMNIDEX_API_KEY",
+		"oidc-audience": "OMNIDEX_OIDC_AUDIENCE",
+		"signing-key":   "OMNIDEX_SIGNING_KEY",
+		"docs-path":     "DOCS_PATH",
+		"file-pattern":  "FILE_PATTERN",
+		"repo":          "GITHUB_REPOSITORY",
+		"project":       "OMNIDEX_PROJECT",
+		"commit-sha":    "GITHUB_SHA",
+		"targets":       "OMNIDEX_TARGETS",
+		"concurrency":   "OMNIDEX_CONCURRENCY",
+		"output":        "OMNIDEX_OUTPUT",
+		"sync":          "OMNIDEX_SYNC",
 	}
 
 	for flagName, envVar := range envBindings {
@@ -70,40 +112,225 @@ func bindEnvDefaults(cmd *cobra.Command, _ *publishFlags) {
 }
 
 // runPublish validates inputs and delegates the publish workflow to the publisher package.
+// With --output json the result document is written to stdout and logs go to stderr.
+// The returned error carries the exit code matching the outcome.
 func runPublish(ctx context.Context, flags *cmdFlags, pubFlags *publishFlags) error {
-	if err := initLogger(flags); err != nil {
-		return fmt.Errorf("failed to init logger: %w", err)
+	var logOutput io.Writer = os.Stdout
+
+	switch pubFlags.Output {
+	case "", outputText:
+	case outputJSON:
+		logOutput = os.Stderr
+	default:
+		return validationError(fmt.Errorf("--output must be %q or %q: %q", outputText, outputJSON, pubFlags.Output))
 	}
 
-	if pubFlags.URL == "" {
-		return fmt.Errorf("--url (or OMNIDEX_URL) is required")
+	if err := initLoggerTo(flags, logOutput); err != nil {
+		return validationError(fmt.Errorf("failed to init logger: %w", err))
 	}
 
-	if pubFlags.APIKey == "" {
-		return fmt.Errorf("--api-key (or OMNIDEX_API_KEY) is required")
+	repo, results, err := publish(ctx, pubFlags, logOutput)
+
+	if pubFlags.Output == outputJSON {
+		if werr := writePublishJSON(os.Stdout, repo, results, err); werr != nil {
+			return errors.Join(err, werr)
+		}
+	}
+
+	return err
+}
+
+// publish validates the publish flags and publishes the documentation to a single instance
+// or, with --targets, to every listed target. It returns the repository identifier and the
+// result of each target. GitHub Actions annotations are written to annotations.
+func publish(ctx context.Context, pubFlags *publishFlags, annotations io.Writer) (string, []publisher.TargetResult, error) {
+	if pubFlags.Targets == "" {
+		if pubFlags.URL == "" {
+			return "", nil, validationError(fmt.Errorf("--url (or OMNIDEX_URL) is required"))
+		}
+
+		if pubFlags.APIKey == "" && pubFlags.OIDCAudience == "" {
+			return "", nil, validationError(fmt.Errorf("--api-key (or OMNIDEX_API_KEY) or --oidc-audience (or OMNIDEX_OIDC_AUDIENCE) is required"))
+		}
 	}
 
 	if pubFlags.Repo == "" {
-		return fmt.Errorf("--repo (or GITHUB_REPOSITORY) is required")
+		return "", nil, validationError(fmt.Errorf("--repo (or GITHUB_REPOSITORY) is required"))
+	}
+
+	repo := pubFlags.Repo
+
+	if pubFlags.Project != "" {
+		if strings.Contains(pubFlags.Project, "/") {
+			return repo, nil, validationError(fmt.Errorf("--project must be a single name without slashes: %q", pubFlags.Project))
+		}
+
+		repo += "/" + pubFlags.Project
+	}
+
+	if pubFlags.Targets != "" {
+		results, err := runPublishTargets(ctx, pubFlags, repo, annotations)
+		return repo, results, err
 	}
 
 	slog.Info("Publishing documentation",
 		"url", pubFlags.URL,
 		"docs_path", pubFlags.DocsPath,
 		"file_pattern", pubFlags.FilePattern,
-		"repo", pubFlags.Repo,
+		"repo", repo,
 		"commit_sha", pubFlags.CommitSHA,
 		"sync", pubFlags.Sync,
 	)
 
-	pub := publisher.New(pubFlags.URL, pubFlags.APIKey)
+	apiKey := pubFlags.APIKey
+
+	if apiKey == "" {
+		token, err := publisher.FetchActionsIDToken(ctx, pubFlags.OIDCAudience)
+		if err != nil {
+			return repo, nil, validationError(fmt.Errorf("failed to get OIDC ID token: %w", err))
+		}
 
-	resp, err := pub.Publish(ctx, pubFlags.DocsPath, pubFlags.FilePattern, pubFlags.Repo, pubFlags.CommitSHA, pubFlags.Sync)
+		apiKey = token
+	}
+
+	pub := publisher.New(pubFlags.URL, apiKey).WithSigningKey(pubFlags.SigningKey)
+
+	result, err := pub.Publish(ctx, pubFlags.DocsPath, pubFlags.FilePattern, repo, pubFlags.CommitSHA, pubFlags.Sync)
+
+	results := []publisher.TargetResult{
+		{Target: pubFlags.URL, URL: pubFlags.URL, Result: result, Err: err},
+	}
+
+	reportToGitHub(annotations, pubFlags.DocsPath, repo, results)
+
+	if err != nil {
+		return repo, results, publishError(err)
+	}
+
+	logPublishResult(slog.Default(), result)
+
+	return repo, results, nil
+}
+
+// runPublishTargets publishes the documentation to every target listed in the targets file
+// and logs the outcome for each of them.
+func runPublishTargets(ctx context.Context, pubFlags *publishFlags, repo string, annotations io.Writer) ([]publisher.TargetResult, error) {
+	targets, err := loadTargets(pubFlags.Targets)
 	if err != nil {
-		return err
+		return nil, validationError(err)
 	}
 
-	slog.Info("Documentation published successfully", "indexed", resp.Indexed, "deleted", resp.Deleted)
+	slog.Info("Publishing documentation to multiple targets",
+		"targets", len(targets),
+		"concurrency", pubFlags.Concurrency,
+		"docs_path", pubFlags.DocsPath,
+		"file_pattern", pubFlags.FilePattern,
+		"repo", repo,
+		"commit_sha", pubFlags.CommitSHA,
+		"sync", pubFlags.Sync,
+	)
+
+	results, err := publisher.PublishTargets(ctx, targets, pubFlags.Concurrency,
+		pubFlags.DocsPath, pubFlags.FilePattern, repo, pubFlags.CommitSHA, pubFlags.Sync)
+
+	for _, res := range results {
+		logger := slog.With("target", res.Target)
+
+		if res.Err != nil {
+			logger.Error("Failed to publish documentation", "error", res.Err)
+			continue
+		}
+
+		logPublishResult(logger, res.Result)
+	}
+
+	reportToGitHub(annotations, pubFlags.DocsPath, repo, results)
+
+	return results, targetsError(err, results)
+}
+
+// loadTargets reads and validates the targets file, resolving each target's API key
+// from its environment variable.
+func loadTargets(path string) ([]publisher.Target, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read targets file: %w", err)
+	}
+
+	var file targetsFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse targets file: %w", err)
+	}
+
+	if len(file.Targets) == 0 {
+		return nil, fmt.Errorf("targets file %s lists no targets", path)
+	}
+
+	targets := make([]publisher.Target, 0, len(file.Targets))
+	seen := make(map[string]struct{}, len(file.Targets))
+
+	for i, t := range file.Targets {
+		if t.Name == "" || t.URL == "" || t.APIKeyEnv == "" {
+			return nil, fmt.Errorf("target %d must set name, url and api_key_env", i+1)
+		}
+
+		if _, ok := seen[t.Name]; ok {
+			return nil, fmt.Errorf("duplicate target name %q", t.Name)
+		}
+
+		seen[t.Name] = struct{}{}
+
+		apiKey := os.Getenv(t.APIKeyEnv)
+		if apiKey == "" {
+			return nil, fmt.Errorf("environment variable %s for target %q is not set", t.APIKeyEnv, t.Name)
+		}
+
+		var signingKey string
+
+		if t.SigningKeyEnv != "" {
+			signingKey = os.Getenv(t.SigningKeyEnv)
+			if signingKey == "" {
+				return nil, fmt.Errorf("environment variable %s for target %q is not set", t.SigningKeyEnv, t.Name)
+			}
+		}
+
+		targets = append(targets, publisher.Target{
+			Name:       t.Name,
+			URL:        t.URL,
+			APIKey:     apiKey,
+			SigningKey: signingKey,
+			Include:    t.Include,
+		})
+	}
+
+	return targets, nil
+}
+
+// logPublishResult logs the files skipped by the publisher and the lint issues, content
+// policy matches, duplicated documents and failed documents reported by the server,
+// followed by a summary of the publish.
+func logPublishResult(logger *slog.Logger, result *publisher.Result) {
+	for _, path := range result.Skipped {
+		logger.Warn("Skipped file with unrecognized content type", "path", path)
+	}
+
+	for _, issue := range result.Lint {
+		logger.Warn("Lint issue", "path", issue.Path, "line", issue.Line, "rule", issue.Rule, "message", issue.Message)
+	}
+
+	for _, finding := range result.Policy {
+		logger.Warn("Content policy match", "path", finding.Path, "line", finding.Line, "rule", finding.Rule, "action", finding.Action)
+	}
+
+	for _, group := range result.Duplicates {
+		logger.Warn("Document duplicates another repository",
+			"canonical", group.Canonical.Repo+"/"+group.Canonical.Path, "copies", len(group.Copies))
+	}
+
+	for _, doc := range result.Failed {
+		logger.Error("Document could not be processed and was not published", "path", doc.Path, "error", doc.Error)
+	}
 
-	return nil
+	logger.Info("Documentation published successfully",
+		"indexed", result.Indexed, "skipped", len(result.Skipped), "failed", len(result.Failed), "deleted", result.Deleted, "moved", result.Moved)
 }
@@ -1,9 +1,17 @@
 package cmd
 
 import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
 
+	"github.com/ksysoev/omnidex/pkg/publisher"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRunPublish_MissingURL(t *testing.T) {
@@ -24,6 +32,39 @@ func TestRunPublish_MissingAPIKey(t *testing.T) {
 	assert.Contains(t, err.Error(), "--api-key")
 }
 
+func TestRunPublish_OIDCToken(t *testing.T) {
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "https://docs.example.com", r.URL.Query().Get("audience"))
+
+		_, _ = w.Write([]byte(`{"value":"id-token"}`))
+	}))
+	defer tokenServer.Close()
+
+	var gotAuth string
+
+	ingest := newIngestServer(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+
+		_, _ = w.Write([]byte(`{"indexed":1}`))
+	})
+	defer ingest.Close()
+
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_URL", tokenServer.URL+"?api-version=1")
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN", "request-token")
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Hello"), 0o600))
+
+	_, _, err := publish(t.Context(), &publishFlags{
+		URL:          ingest.URL,
+		OIDCAudience: "https://docs.example.com",
+		Repo:         "owner/repo",
+		DocsPath:     dir,
+	}, io.Discard)
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer id-token", gotAuth)
+}
+
 func TestRunPublish_MissingRepo(t *testing.T) {
 	cmdFlags := &cmdFlags{LogLevel: "error", TextFormat: true}
 	pubFlags := &publishFlags{
@@ -36,6 +77,20 @@ func TestRunPublish_MissingRepo(t *testing.T) {
 	assert.Contains(t, err.Error(), "--repo")
 }
 
+func TestRunPublish_InvalidProject(t *testing.T) {
+	cmdFlags := &cmdFlags{LogLevel: "error", TextFormat: true}
+	pubFlags := &publishFlags{
+		URL:     "http://localhost",
+		APIKey:  "key",
+		Repo:    "owner/repo",
+		Project: "services/a",
+	}
+
+	err := runPublish(t.Context(), cmdFlags, pubFlags)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "--project")
+}
+
 func TestNewPublishCmd(t *testing.T) {
 	flags := &cmdFlags{}
 	cmd := newPublishCmd(flags)
@@ -57,11 +112,195 @@ func TestNewPublishCmd(t *testing.T) {
 
 	filePatternFlag := cmd.Flags().Lookup("file-pattern")
 	assert.NotNil(t, filePatternFlag)
-	assert.Equal(t, "**/*.md", filePatternFlag.DefValue)
+	assert.Empty(t, filePatternFlag.DefValue)
 
 	repoFlag := cmd.Flags().Lookup("repo")
 	assert.NotNil(t, repoFlag)
 
+	projectFlag := cmd.Flags().Lookup("project")
+	assert.NotNil(t, projectFlag)
+	assert.Empty(t, projectFlag.DefValue)
+
 	commitSHAFlag := cmd.Flags().Lookup("commit-sha")
 	assert.NotNil(t, commitSHAFlag)
+
+	outputFlag := cmd.Flags().Lookup("output")
+	assert.NotNil(t, outputFlag)
+	assert.Equal(t, "text", outputFlag.DefValue)
+}
+
+func TestLoadTargets(t *testing.T) {
+	dir := t.TempDir()
+
+	write := func(content string) string {
+		path := filepath.Join(dir, "targets.yml")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+		return path
+	}
+
+	t.Setenv("INTERNAL_KEY", "internal-secret")
+	t.Setenv("PARTNER_KEY", "partner-secret")
+	t.Setenv("INTERNAL_SIGNING_KEY", "signing-secret")
+
+	targets, err := loadTargets(write(`
+targets:
+  - name: internal
+    url: https://docs.internal
+    api_key_env: INTERNAL_KEY
+    signing_key_env: INTERNAL_SIGNING_KEY
+  - name: partner
+    url: https://partner.example.com
+    api_key_env: PARTNER_KEY
+    include: "public/**"
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []publisher.Target{
+		{Name: "internal", URL: "https://docs.internal", APIKey: "internal-secret", SigningKey: "signing-secret"},
+		{Name: "partner", URL: "https://partner.example.com", APIKey: "partner-secret", Include: "public/**"},
+	}, targets)
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "no targets", content: "targets: []\n", wantErr: "lists no targets"},
+		{name: "missing url", content: "targets:\n  - name: a\n    api_key_env: INTERNAL_KEY\n", wantErr: "must set name, url and api_key_env"},
+		{name: "duplicate", content: "targets:\n  - {name: a, url: u, api_key_env: INTERNAL_KEY}\n  - {name: a, url: v, api_key_env: PARTNER_KEY}\n", wantErr: "duplicate target name"},
+		{name: "unset key", content: "targets:\n  - {name: a, url: u, api_key_env: OMNIDEX_TEST_UNSET_KEY}\n", wantErr: "OMNIDEX_TEST_UNSET_KEY"},
+		{name: "unset signing key", content: "targets:\n  - {name: a, url: u, api_key_env: INTERNAL_KEY, signing_key_env: OMNIDEX_TEST_UNSET_KEY}\n", wantErr: "OMNIDEX_TEST_UNSET_KEY"},
+		{name: "invalid yaml", content: "targets: [", wantErr: "failed to parse targets file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadTargets(write(tt.content))
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+
+	_, err = loadTargets(filepath.Join(dir, "missing.yml"))
+	assert.ErrorContains(t, err, "failed to read targets file")
+}
+
+func TestRunPublish_Targets(t *testing.T) {
+	var indexed atomic.Int32
+
+	srv := newIngestServer(func(w http.ResponseWriter, _ *http.Request) {
+		indexed.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"indexed":1}`))
+	})
+	defer srv.Close()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "doc.md"), []byte("# Doc"), 0o600))
+
+	targetsPath := filepath.Join(dir, "targets.yml")
+	require.NoError(t, os.WriteFile(targetsPath, []byte(
+		"targets:\n  - {name: a, url: "+srv.URL+", api_key_env: TARGET_KEY}\n  - {name: b, url: "+srv.URL+", api_key_env: TARGET_KEY}\n"), 0o600))
+
+	t.Setenv("TARGET_KEY", "secret")
+
+	cmdFlags := &cmdFlags{LogLevel: "error", TextFormat: true}
+	pubFlags := &publishFlags{
+		Targets:     targetsPath,
+		DocsPath:    dir,
+		FilePattern: "**/*.md",
+		Repo:        "owner/repo",
+		Concurrency: 2,
+		Sync:        true,
+	}
+
+	require.NoError(t, runPublish(t.Context(), cmdFlags, pubFlags))
+	assert.Equal(t, int32(2), indexed.Load())
+}
+
+func TestRunPublish_ExitCodes(t *testing.T) {
+	failing := newIngestServer(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	})
+	defer failing.Close()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "doc.md"), []byte("# Doc"), 0o600))
+
+	tests := []struct {
+		pubFlags *publishFlags
+		name     string
+		want     int
+	}{
+		{
+			name:     "missing url",
+			pubFlags: &publishFlags{APIKey: "key", Repo: "owner/repo"},
+			want:     ExitCodeValidation,
+		},
+		{
+			name:     "invalid output",
+			pubFlags: &publishFlags{URL: failing.URL, APIKey: "key", Repo: "owner/repo", Output: "yaml"},
+			want:     ExitCodeValidation,
+		},
+		{
+			name:     "missing docs path",
+			pubFlags: &publishFlags{URL: failing.URL, APIKey: "key", Repo: "owner/repo", DocsPath: filepath.Join(dir, "missing")},
+			want:     ExitCodeValidation,
+		},
+		{
+			name:     "server error",
+			pubFlags: &publishFlags{URL: failing.URL, APIKey: "key", Repo: "owner/repo", DocsPath: dir, FilePattern: "**/*.md"},
+			want:     ExitCodeServer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := runPublish(t.Context(), &cmdFlags{LogLevel: "error", TextFormat: true}, tt.pubFlags)
+			require.Error(t, err)
+			assert.Equal(t, tt.want, ExitCode(err))
+		})
+	}
+}
+
+func TestRunPublish_TargetsPartialFailure(t *testing.T) {
+	ok := newIngestServer(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"indexed":1}`))
+	})
+	defer ok.Close()
+
+	failing := newIngestServer(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	})
+	defer failing.Close()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "doc.md"), []byte("# Doc"), 0o600))
+
+	targetsPath := filepath.Join(dir, "targets.yml")
+	require.NoError(t, os.WriteFile(targetsPath, []byte(
+		"targets:\n  - {name: a, url: "+ok.URL+", api_key_env: TARGET_KEY}\n  - {name: b, url: "+failing.URL+", api_key_env: TARGET_KEY}\n"), 0o600))
+
+	t.Setenv("TARGET_KEY", "secret")
+
+	pubFlags := &publishFlags{
+		Targets:     targetsPath,
+		DocsPath:    dir,
+		FilePattern: "**/*.md",
+		Repo:        "owner/repo",
+		Concurrency: 2,
+	}
+
+	err := runPublish(t.Context(), &cmdFlags{LogLevel: "error", TextFormat: true}, pubFlags)
+	require.Error(t, err)
+	assert.Equal(t, ExitCodePartial, ExitCode(err))
+}
+
+// newIngestServer starts a test server serving h as the ingest endpoint. Other endpoints,
+// such as the content types, are not found, as on servers that predate them.
+func newIngestServer(h http.HandlerFunc) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.Handle("POST /api/v1/docs", h)
+
+	return httptest.NewServer(mux)
 }
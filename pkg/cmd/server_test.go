@@ -104,6 +104,34 @@ func TestRunCommand_S3StorageType(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestRunCommand_EditRequiresToken(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+
+	require.NoError(t, os.WriteFile(configPath, []byte("edit:\n  repos:\n    - repo: acme/wiki\n"), 0o600))
+
+	t.Setenv("API_LISTEN", ":0")
+	t.Setenv("STORAGE_PATH", filepath.Join(tmpDir, "docs"))
+	t.Setenv("SEARCH_INDEX_PATH", filepath.Join(tmpDir, "search.bleve"))
+
+	err := RunCommand(t.Context(), &cmdFlags{LogLevel: "info", ConfigPath: configPath})
+	assert.ErrorContains(t, err, "edit.repos requires a GitHub token")
+}
+
+func TestRunCommand_SuggestRequiresToken(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+
+	require.NoError(t, os.WriteFile(configPath, []byte("suggest:\n  repos:\n    - repo: acme/handbook\n"), 0o600))
+
+	t.Setenv("API_LISTEN", ":0")
+	t.Setenv("STORAGE_PATH", filepath.Join(tmpDir, "docs"))
+	t.Setenv("SEARCH_INDEX_PATH", filepath.Join(tmpDir, "search.bleve"))
+
+	err := RunCommand(t.Context(), &cmdFlags{LogLevel: "info", ConfigPath: configPath})
+	assert.ErrorContains(t, err, "suggest.repos requires a GitHub token")
+}
+
 // writeFile creates a regular file at the given path.
 func writeFile(path string) error {
 	f, err := os.Create(path)
@@ -113,3 +141,18 @@ func writeFile(path string) error {
 
 	return f.Close()
 }
+
+func TestRunCommand_DigestRequiresSMTP(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+
+	require.NoError(t, os.WriteFile(configPath, []byte("digest:\n  portal_url: https://docs.example.com\n  teams:\n"+
+		"    - name: api\n      emails: [api@example.com]\n      repos: [acme/api]\n"), 0o600))
+
+	t.Setenv("API_LISTEN", ":0")
+	t.Setenv("STORAGE_PATH", filepath.Join(tmpDir, "docs"))
+	t.Setenv("SEARCH_INDEX_PATH", filepath.Join(tmpDir, "search.bleve"))
+
+	err := RunCommand(t.Context(), &cmdFlags{LogLevel: "info", ConfigPath: configPath})
+	assert.ErrorContains(t, err, "digest.teams requires an SMTP server")
+}
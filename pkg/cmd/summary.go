@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/ksysoev/omnidex/pkg/publisher"
+)
+
+var (
+	// annotationDataEscaper escapes the message of a workflow command.
+	annotationDataEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	// annotationPropertyEscaper escapes the properties (file, title) of a workflow command.
+	annotationPropertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+	// tableCellEscaper keeps text inside a single Markdown table cell.
+	tableCellEscaper = strings.NewReplacer("|", `\|`, "\r", " ", "\n", " ")
+)
+
+// reportToGitHub reports the publish results to GitHub Actions when running in a workflow:
+// per-file problems become workflow annotations written to w and a Markdown job summary is
+// appended to $GITHUB_STEP_SUMMARY. Failures to write the summary are logged and never fail
+// a publish.
+func reportToGitHub(w io.Writer, docsPath, repo string, results []publisher.TargetResult) {
+	if os.Getenv("GITHUB_ACTIONS") != "true" {
+		return
+	}
+
+	writeAnnotations(w, docsPath, results)
+
+	summaryPath := os.Getenv("GITHUB_STEP_SUMMARY")
+	if summaryPath == "" {
+		return
+	}
+
+	f, err := os.OpenFile(summaryPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		slog.Warn("Failed to open job summary", "path", summaryPath, "error", err)
+		return
+	}
+
+	if err := writeSummary(f, repo, results); err != nil {
+		slog.Warn("Failed to write job summary", "path", summaryPath, "error", err)
+	}
+
+	if err := f.Close(); err != nil {
+		slog.Warn("Failed to close job summary", "path", summaryPath, "error", err)
+	}
+}
+
+// writeAnnotations emits workflow commands that annotate the published files with the
+// lint issues, content policy matches and skipped files of each target, and an error for
+// each target that failed. Titles name the target when there are several.
+func writeAnnotations(w io.Writer, docsPath string, results []publisher.TargetResult) {
+	for _, res := range results {
+		prefix := ""
+		if len(results) > 1 {
+			prefix = "[" + res.Target + "] "
+		}
+
+		if res.Err != nil {
+			writeAnnotation(w, "error", "", 0, prefix+"Omnidex publish failed", res.Err.Error())
+			continue
+		}
+
+		if res.Result == nil {
+			continue
+		}
+
+		for _, path := range res.Result.Skipped {
+			writeAnnotation(w, "notice", annotationPath(docsPath, path), 0, prefix+"Not published", "content type not recognized")
+		}
+
+		for _, doc := range res.Result.Failed {
+			writeAnnotation(w, "error", annotationPath(docsPath, doc.Path), 0, prefix+"Not published", doc.Error)
+		}
+
+		for _, issue := range res.Result.Lint {
+			writeAnnotation(w, "warning", annotationPath(docsPath, issue.Path), issue.Line, prefix+"Lint: "+issue.Rule, issue.Message)
+		}
+
+		for _, finding := range res.Result.Policy {
+			level, msg := "warning", "content matched the policy rule and was flagged"
+			if finding.Action == core.PolicyActionRedact {
+				level, msg = "notice", "content matched the policy rule and was redacted"
+			}
+
+			writeAnnotation(w, level, annotationPath(docsPath, finding.Path), finding.Line, prefix+"Content policy: "+finding.Rule, msg)
+		}
+	}
+}
+
+// writeAnnotation writes a single workflow command, e.g.
+// "::warning file=docs/a.md,line=3,title=Lint::message".
+func writeAnnotation(w io.Writer, level, file string, line int, title, message string) {
+	var props []string
+
+	if file != "" {
+		props = append(props, "file="+annotationPropertyEscaper.Replace(file))
+
+		if line > 0 {
+			props = append(props, fmt.Sprintf("line=%d", line))
+		}
+	}
+
+	props = append(props, "title="+annotationPropertyEscaper.Replace(title))
+
+	_, _ = fmt.Fprintf(w, "::%s %s::%s\n", level, strings.Join(props, ","), annotationDataEscaper.Replace(message))
+}
+
+// annotationPath returns the path of a published file relative to the workspace, which is
+// what workflow annotations expect.
+func annotationPath(docsPath, file string) string {
+	p := filepath.Join(docsPath, filepath.FromSlash(file))
+
+	if ws := os.Getenv("GITHUB_WORKSPACE"); ws != "" && filepath.IsAbs(p) {
+		if rel, err := filepath.Rel(ws, p); err == nil && !strings.HasPrefix(rel, "..") {
+			p = rel
+		}
+	}
+
+	return filepath.ToSlash(p)
+}
+
+// writeSummary writes a Markdown summary of the publish results: a table with the counts
+// and portal link of each target, followed by the warnings, skipped files and errors.
+func writeSummary(w io.Writer, repo string, results []publisher.TargetResult) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "### Omnidex publish: `%s`\n\n", repo)
+	b.WriteString("| Target | Status | Published | Skipped | Deleted | Moved | Warnings | Portal |\n")
+	b.WriteString("| --- | --- | ---: | ---: | ---: | ---: | ---: | --- |\n")
+
+	for _, res := range results {
+		portal := strings.TrimRight(res.URL, "/") + "/docs/" + repo + "/"
+
+		if res.Err != nil || res.Result == nil {
+			fmt.Fprintf(&b, "| %s | ❌ Failed | – | – | – | – | – | – |\n", tableCellEscaper.Replace(res.Target))
+			continue
+		}
+
+		r := res.Result
+		fmt.Fprintf(&b, "| %s | ✅ Published | %d | %d | %d | %d | %d | [%s](%s) |\n",
+			tableCellEscaper.Replace(res.Target), r.Indexed, len(r.Skipped), r.Deleted, r.Moved,
+			len(r.Lint)+len(r.Policy), repo, portal)
+	}
+
+	writeSummaryDetails(&b, results)
+
+	if _, err := io.WriteString(w, b.String()+"\n"); err != nil {
+		return fmt.Errorf("failed to write summary: %w", err)
+	}
+
+	return nil
+}
+
+// writeSummaryDetails appends the per-file warnings, skipped files, failed documents and
+// target errors to the summary, each as a bulleted list. Entries name the target when there
+// are several.
+func writeSummaryDetails(b *strings.Builder, results []publisher.TargetResult) {
+	var warnings, skipped, failures []string
+
+	for _, res := range results {
+		prefix := ""
+		if len(results) > 1 {
+			prefix = "**" + res.Target + "**: "
+		}
+
+		if res.Err != nil {
+			failures = append(failures, prefix+res.Err.Error())
+			continue
+		}
+
+		if res.Result == nil {
+			continue
+		}
+
+		for _, path := range res.Result.Skipped {
+			skipped = append(skipped, fmt.Sprintf("%s`%s`", prefix, path))
+		}
+
+		for _, doc := range res.Result.Failed {
+			failures = append(failures, fmt.Sprintf("%s`%s`: %s", prefix, doc.Path, doc.Error))
+		}
+
+		for _, issue := range res.Result.Lint {
+			warnings = append(warnings, fmt.Sprintf("%s`%s`%s: %s (%s)", prefix, issue.Path, lineSuffix(issue.Line), issue.Message, issue.Rule))
+		}
+
+		for _, finding := range res.Result.Policy {
+			warnings = append(warnings, fmt.Sprintf("%s`%s`%s: content policy rule %s (%s)", prefix, finding.Path, lineSuffix(finding.Line), finding.Rule, finding.Action))
+		}
+	}
+
+	for _, section := range []struct {
+		title string
+		items []string
+	}{
+		{title: "Warnings", items: warnings},
+		{title: "Skipped files", items: skipped},
+		{title: "Errors", items: failures},
+	} {
+		if len(section.items) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(b, "\n#### %s\n\n", section.title)
+
+		for _, item := range section.items {
+			b.WriteString("- " + item + "\n")
+		}
+	}
+}
+
+// lineSuffix formats an optional line number as ":N".
+func lineSuffix(line int) string {
+	if line <= 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(":%d", line)
+}
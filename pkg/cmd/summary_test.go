@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/ksysoev/omnidex/pkg/publisher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPublishResults() []publisher.TargetResult {
+	return []publisher.TargetResult{
+		{
+			Target: "internal",
+			URL:    "https://docs.example.com/",
+			Result: &publisher.Result{
+				IngestResponse: &core.IngestResponse{
+					Indexed: 3,
+					Deleted: 1,
+					Lint:    []core.LintIssue{{Path: "guide.md", Rule: core.LintRuleBrokenLink, Line: 4, Message: `link target "x.md" does not exist`}},
+					Policy:  []core.PolicyFinding{{Path: "ops.md", Rule: "email", Action: core.PolicyActionRedact, Line: 2}},
+					Failed:  []core.FailedDocument{{Path: "broken.md", Error: "content processing panicked: nil node"}},
+				},
+				Skipped: []string{"config.yaml"},
+			},
+		},
+		{Target: "partner", URL: "https://partners.example.com", Err: errors.New("server returned HTTP 401: unauthorized")},
+	}
+}
+
+func TestWriteSummary(t *testing.T) {
+	var buf bytes.Buffer
+
+	require.NoError(t, writeSummary(&buf, "owner/repo", testPublishResults()))
+
+	out := buf.String()
+	assert.Contains(t, out, "### Omnidex publish: `owner/repo`")
+	assert.Contains(t, out, "| internal | ✅ Published | 3 | 1 | 1 | 0 | 2 | [owner/repo](https://docs.example.com/docs/owner/repo/) |")
+	assert.Contains(t, out, "| partner | ❌ Failed |")
+	assert.Contains(t, out, "#### Warnings\n\n- **internal**: `guide.md`:4: link target \"x.md\" does not exist (broken-link)\n"+
+		"- **internal**: `ops.md`:2: content policy rule email (redact)\n")
+	assert.Contains(t, out, "#### Skipped files\n\n- **internal**: `config.yaml`\n")
+	assert.Contains(t, out, "#### Errors\n\n- **internal**: `broken.md`: content processing panicked: nil node\n"+
+		"- **partner**: server returned HTTP 401: unauthorized\n")
+}
+
+func TestWriteSummary_SingleTargetClean(t *testing.T) {
+	var buf bytes.Buffer
+
+	results := []publisher.TargetResult{{
+		Target: "https://docs.example.com",
+		URL:    "https://docs.example.com",
+		Result: &publisher.Result{IngestResponse: &core.IngestResponse{Indexed: 2}},
+	}}
+
+	require.NoError(t, writeSummary(&buf, "owner/repo", results))
+
+	out := buf.String()
+	assert.Contains(t, out, "| 2 | 0 | 0 | 0 | 0 |")
+	assert.NotContains(t, out, "####")
+}
+
+func TestWriteAnnotations(t *testing.T) {
+	t.Setenv("GITHUB_WORKSPACE", "/github/workspace")
+
+	var buf bytes.Buffer
+
+	writeAnnotations(&buf, "/github/workspace/docs", testPublishResults())
+
+	assert.Equal(t,
+		"::notice file=docs/config.yaml,title=[internal] Not published::content type not recognized\n"+
+			"::error file=docs/broken.md,title=[internal] Not published::content processing panicked: nil node\n"+
+			"::warning file=docs/guide.md,line=4,title=[internal] Lint%3A broken-link::link target \"x.md\" does not exist\n"+
+			"::notice file=docs/ops.md,line=2,title=[internal] Content policy%3A email::content matched the policy rule and was redacted\n"+
+			"::error title=[partner] Omnidex publish failed::server returned HTTP 401: unauthorized\n",
+		buf.String())
+}
+
+func TestWriteAnnotation_Escapes(t *testing.T) {
+	var buf bytes.Buffer
+
+	writeAnnotation(&buf, "warning", "a,b.md", 0, "T: x", "100%\nnext")
+
+	assert.Equal(t, "::warning file=a%2Cb.md,title=T%3A x::100%25%0Anext\n", buf.String())
+}
+
+func TestReportToGitHub(t *testing.T) {
+	summary := filepath.Join(t.TempDir(), "summary.md")
+
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_STEP_SUMMARY", summary)
+
+	reportToGitHub(io.Discard, "docs", "owner/repo", testPublishResults())
+
+	data, err := os.ReadFile(summary)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "### Omnidex publish: `owner/repo`")
+
+	t.Setenv("GITHUB_ACTIONS", "")
+
+	reportToGitHub(io.Discard, "docs", "owner/repo", testPublishResults())
+
+	again, err := os.ReadFile(summary)
+	require.NoError(t, err)
+	assert.Equal(t, data, again, "nothing is written outside GitHub Actions")
+}
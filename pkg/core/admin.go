@@ -0,0 +1,88 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// DeleteRepoResponse reports the outcome of deleting a repository.
+type DeleteRepoResponse struct {
+	Repo    string `json:"repo"`
+	Deleted int    `json:"deleted"`
+}
+
+// DeleteRepo removes every document and asset of a repository along with its search
+// index entries, lint report, pinned documents and announcement. Sub-projects published under the repository are kept.
+// It returns ErrNotFound if the repository has no documents.
+func (s *Service) DeleteRepo(ctx context.Context, repo string) (*DeleteRepoResponse, error) {
+	if err := validateRepoName(repo); err != nil {
+		return nil, err
+	}
+
+	docs, err := s.store.List(ctx, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents for repo %s: %w", repo, err)
+	}
+
+	if len(docs) == 0 {
+		return nil, fmt.Errorf("%w: repo %s", ErrNotFound, repo)
+	}
+
+	defer s.invalidateSearches()
+
+	// Remove the index entries first so that search never links to deleted documents.
+	if _, err := s.cleanOrphanedSearchEntries(ctx, repo, map[string]struct{}{}); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.DeleteRepo(ctx, repo); err != nil {
+		return nil, fmt.Errorf("failed to delete repo %s: %w", repo, err)
+	}
+
+	if s.lint != nil {
+		s.lint.mu.Lock()
+		delete(s.lint.reports, repo)
+		s.lint.mu.Unlock()
+	}
+
+	s.moveHighlights(ctx, repo, "")
+
+	for _, meta := range docs {
+		docID := NewDocumentID(repo, meta.Path).String()
+		s.untrackContent(docID)
+		s.forgetRender(docID)
+		s.publishEvent(EventDocumentDeleted, repo, meta.Path)
+	}
+
+	slog.InfoContext(ctx, "repository deleted", "repo", repo, "documents", len(docs))
+
+	return &DeleteRepoResponse{Repo: repo, Deleted: len(docs)}, nil
+}
+
+// StartReindex rebuilds the search index from the document store in the background, as
+// ReindexAll does. It returns ErrConflict if a reindex started this way is still running.
+// The rebuild is not tied to ctx, so it outlives the request that started it.
+func (s *Service) StartReindex(ctx context.Context) error {
+	if !s.reindexing.CompareAndSwap(false, true) {
+		return fmt.Errorf("%w: a reindex is already running", ErrConflict)
+	}
+
+	go func() {
+		defer s.reindexing.Store(false)
+
+		ctx := context.WithoutCancel(ctx)
+
+		slog.InfoContext(ctx, "rebuilding search index from document store")
+
+		indexed, failed, err := s.ReindexAll(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "search index rebuild failed", "error", err, "indexed", indexed, "failed", failed)
+			return
+		}
+
+		slog.InfoContext(ctx, "search index rebuilt", "indexed", indexed, "failed", failed)
+	}()
+
+	return nil
+}
@@ -0,0 +1,94 @@
+//go:build !compile
+
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteRepo(t *testing.T) {
+	svc, store, search, _ := newTestService(t)
+
+	store.EXPECT().List(mock.Anything, "owner/repo").Return([]DocumentMeta{
+		{Repo: "owner/repo", Path: "a.md"},
+		{Repo: "owner/repo", Path: "b.md"},
+	}, nil)
+	search.EXPECT().ListByRepo(mock.Anything, "owner/repo").Return([]string{"owner/repo/a.md", "owner/repo/b.md"}, nil)
+	search.EXPECT().Remove(mock.Anything, "owner/repo/a.md").Return(nil)
+	search.EXPECT().Remove(mock.Anything, "owner/repo/b.md").Return(nil)
+	store.EXPECT().DeleteRepo(mock.Anything, "owner/repo").Return(nil)
+
+	// The repository's highlights go with it; those of its sub-projects are kept.
+	store.EXPECT().GetHighlights(mock.Anything).Return(map[string]RepoHighlights{
+		"owner/repo":     {Repo: "owner/repo", Announcement: "Deprecated", Pinned: []string{"a.md"}},
+		"owner/repo/svc": {Repo: "owner/repo/svc", Pinned: []string{"intro.md"}},
+	}, nil)
+	store.EXPECT().SaveHighlights(mock.Anything, map[string]RepoHighlights{
+		"owner/repo/svc": {Repo: "owner/repo/svc", Pinned: []string{"intro.md"}},
+	}).Return(nil)
+
+	resp, err := svc.DeleteRepo(t.Context(), "owner/repo")
+	require.NoError(t, err)
+	assert.Equal(t, &DeleteRepoResponse{Repo: "owner/repo", Deleted: 2}, resp)
+	assert.Nil(t, svc.PageHighlights(t.Context(), "owner/repo", []DocumentMeta{{Path: "a.md"}}))
+}
+
+func TestDeleteRepo_Errors(t *testing.T) {
+	t.Run("invalid repo", func(t *testing.T) {
+		svc := newTestServiceOnly(t)
+
+		_, err := svc.DeleteRepo(t.Context(), "owner")
+		assert.ErrorIs(t, err, ErrInvalidPath)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		svc, store, _, _ := newTestService(t)
+
+		store.EXPECT().List(mock.Anything, "owner/repo").Return(nil, nil)
+
+		_, err := svc.DeleteRepo(t.Context(), "owner/repo")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("store failure", func(t *testing.T) {
+		svc, store, search, _ := newTestService(t)
+
+		store.EXPECT().List(mock.Anything, "owner/repo").Return([]DocumentMeta{{Repo: "owner/repo", Path: "a.md"}}, nil)
+		search.EXPECT().ListByRepo(mock.Anything, "owner/repo").Return(nil, nil)
+		store.EXPECT().DeleteRepo(mock.Anything, "owner/repo").Return(errors.New("disk failure"))
+
+		_, err := svc.DeleteRepo(t.Context(), "owner/repo")
+		assert.ErrorContains(t, err, "failed to delete repo owner/repo")
+	})
+}
+
+func TestStartReindex(t *testing.T) {
+	svc, store, _, _ := newTestService(t)
+
+	listed := make(chan struct{})
+	release := make(chan struct{})
+
+	store.EXPECT().ListRepos(mock.Anything).RunAndReturn(func(_ context.Context) ([]RepoInfo, error) {
+		close(listed)
+		<-release
+
+		return nil, nil
+	}).Once()
+
+	require.NoError(t, svc.StartReindex(t.Context()))
+
+	<-listed
+
+	assert.ErrorIs(t, svc.StartReindex(t.Context()), ErrConflict)
+
+	close(release)
+
+	assert.Eventually(t, func() bool { return !svc.reindexing.Load() }, time.Second, 5*time.Millisecond)
+}
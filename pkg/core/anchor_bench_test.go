@@ -0,0 +1,44 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// benchPlainText returns the plain text of a long document with the given number of
+// sections, as indexed for search, along with its headings.
+func benchPlainText(sections int) (string, []Heading) {
+	var b strings.Builder
+
+	headings := make([]Heading, 0, sections)
+
+	for i := range sections {
+		h := Heading{ID: fmt.Sprintf("section-%d", i), Text: fmt.Sprintf("Section %d", i), Level: 2}
+		headings = append(headings, h)
+
+		fmt.Fprintf(&b, "%s\n", h.Text)
+
+		for j := range 8 {
+			fmt.Fprintf(&b, "Paragraph %d of section %d explains how the workers drain the queue and retry failed payments.\n", j, i)
+		}
+	}
+
+	return b.String(), headings
+}
+
+func BenchmarkResolveAnchor(b *testing.B) {
+	plainText, headings := benchPlainText(200)
+	frag := "…ragraph 5 of section 180 explains how the <mark>workers</mark> drain the queue"
+
+	for b.Loop() {
+		idx := fragmentMatchIndex(frag, plainText)
+		if idx < 0 {
+			b.Fatal("fragment not found")
+		}
+
+		if anchor := findAnchorAtPosition(plainText, headings, idx); anchor != "section-180" {
+			b.Fatalf("unexpected anchor %q", anchor)
+		}
+	}
+}
@@ -151,6 +151,51 @@ func TestFindAnchorAtPosition(t *testing.T) {
 	}
 }
 
+func TestAnchorMap_At(t *testing.T) {
+	anchors := AnchorMap{
+		{ID: "intro", Offset: 0},
+		{ID: "install", Offset: 40},
+		{ID: "usage", Offset: 90},
+	}
+
+	assert.Equal(t, "intro", anchors.At(0))
+	assert.Equal(t, "intro", anchors.At(39))
+	assert.Equal(t, "install", anchors.At(40))
+	assert.Equal(t, "usage", anchors.At(500))
+	assert.Empty(t, AnchorMap{{ID: "late", Offset: 10}}.At(5))
+	assert.Empty(t, AnchorMap(nil).At(5))
+}
+
+// stubAnchorMapper is a ContentProcessor that maps its anchors itself.
+type stubAnchorMapper struct {
+	ContentProcessor
+
+	anchors AnchorMap
+}
+
+func (p stubAnchorMapper) MapAnchors([]byte) AnchorMap { return p.anchors }
+
+func TestMapAnchors(t *testing.T) {
+	headings := []Heading{{ID: "usage", Text: "Usage", Level: 2}, {ID: "missing", Text: "Missing", Level: 2}}
+
+	t.Run("locates headings in the plain text", func(t *testing.T) {
+		got := mapAnchors(nil, nil, "Intro\nUsage\nRun it", headings)
+
+		assert.Equal(t, AnchorMap{{ID: "usage", Text: "Usage", Offset: 6, Level: 2}}, got)
+	})
+
+	t.Run("uses the processor's anchor map", func(t *testing.T) {
+		want := AnchorMap{{ID: "usage", Text: "Usage", Offset: 3, Level: 2}}
+
+		assert.Equal(t, want, mapAnchors(stubAnchorMapper{anchors: want}, nil, "Intro\nUsage", headings))
+	})
+
+	t.Run("is not nil without headings", func(t *testing.T) {
+		assert.Equal(t, AnchorMap{}, mapAnchors(stubAnchorMapper{}, nil, "Intro", nil))
+		assert.Equal(t, AnchorMap{}, mapAnchors(nil, nil, "Intro", nil))
+	})
+}
+
 func TestSkipPartialLeadingWord(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -0,0 +1,293 @@
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// apiKeyPrefix marks tokens issued by Omnidex, so they are recognizable in secret scanners.
+	apiKeyPrefix = "omx_"
+	// apiKeyCacheTTL bounds how long a key revoked on another instance keeps working here.
+	apiKeyCacheTTL = 30 * time.Second
+	maxKeyNameLen  = 100
+	// DefaultKeyOverlap is how long a rotated key keeps working alongside its replacement
+	// when no overlap is requested.
+	DefaultKeyOverlap = 24 * time.Hour
+)
+
+// APIKey is a managed API key. Only the SHA-256 hash of the token is stored; the token
+// itself is returned once, when the key is created.
+type APIKey struct {
+	CreatedAt time.Time `json:"created_at"`
+	// ExpiresAt is set when the key was rotated; it stops working at that time.
+	ExpiresAt time.Time `json:"expires_at,omitzero"`
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Hash      string    `json:"hash,omitempty"`
+	// RotatedTo is the ID of the key replacing this one.
+	RotatedTo string `json:"rotated_to,omitempty"`
+}
+
+// expired reports whether the key stopped working at now.
+func (k *APIKey) expired(now time.Time) bool {
+	return !k.ExpiresAt.IsZero() && !now.Before(k.ExpiresAt)
+}
+
+// CreateAPIKeyRequest is the body of a request to create a managed API key.
+type CreateAPIKeyRequest struct {
+	Name string `json:"name"`
+}
+
+// CreateAPIKeyResponse holds a newly created key along with its token.
+type CreateAPIKeyResponse struct {
+	Token string `json:"token"`
+	APIKey
+}
+
+// RotateAPIKeyRequest is the body of a request to rotate a managed API key.
+type RotateAPIKeyRequest struct {
+	// Overlap is how long the old key keeps working, as a Go duration such as "24h".
+	// It defaults to DefaultKeyOverlap.
+	Overlap string `json:"overlap,omitempty"`
+}
+
+// RotateAPIKeyResponse holds the key replacing a rotated one, along with its token.
+type RotateAPIKeyResponse struct {
+	// Replaced is the rotated key, which keeps working until its ExpiresAt.
+	Replaced APIKey `json:"replaced"`
+	CreateAPIKeyResponse
+}
+
+// apiKeyCache holds the managed keys for authenticating requests without reading the store
+// each time.
+type apiKeyCache struct {
+	loadedAt time.Time
+	keys     []APIKey
+	mu       sync.Mutex
+}
+
+// CreateAPIKey issues a new managed API key with the given descriptive name. Managed keys
+// authenticate against the API like the keys in the configuration file.
+func (s *Service) CreateAPIKey(ctx context.Context, name string) (*CreateAPIKeyResponse, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxKeyNameLen {
+		return nil, fmt.Errorf("%w: key name must be between 1 and %d characters", ErrInvalidPath, maxKeyNameLen)
+	}
+
+	key, token, err := newAPIKey(name)
+	if err != nil {
+		return nil, err
+	}
+
+	s.keys.mu.Lock()
+	defer s.keys.mu.Unlock()
+
+	keys, err := s.activeAPIKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keys = append(keys, key)
+
+	if err := s.store.SaveAPIKeys(ctx, keys); err != nil {
+		return nil, fmt.Errorf("failed to save api keys: %w", err)
+	}
+
+	s.keys.set(keys)
+
+	slog.InfoContext(ctx, "api key created", "id", key.ID, "name", key.Name)
+
+	key.Hash = ""
+
+	return &CreateAPIKeyResponse{Token: token, APIKey: key}, nil
+}
+
+// RotateAPIKey replaces the managed API key with the given ID by a new key with the same
+// name. The old key keeps working for overlap, so clients can switch to the new token
+// without downtime. It returns ErrNotFound if there is no such key and ErrConflict if the
+// key was already rotated.
+func (s *Service) RotateAPIKey(ctx context.Context, id string, overlap time.Duration) (*RotateAPIKeyResponse, error) {
+	if overlap < 0 {
+		return nil, fmt.Errorf("%w: overlap must not be negative", ErrInvalidPath)
+	}
+
+	s.keys.mu.Lock()
+	defer s.keys.mu.Unlock()
+
+	keys, err := s.activeAPIKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	i := slices.IndexFunc(keys, func(k APIKey) bool { return k.ID == id })
+	if i < 0 {
+		return nil, fmt.Errorf("%w: api key %s", ErrNotFound, id)
+	}
+
+	if keys[i].RotatedTo != "" {
+		return nil, fmt.Errorf("%w: api key %s was already rotated to %s", ErrConflict, id, keys[i].RotatedTo)
+	}
+
+	key, token, err := newAPIKey(keys[i].Name)
+	if err != nil {
+		return nil, err
+	}
+
+	keys[i].ExpiresAt = key.CreatedAt.Add(overlap)
+	keys[i].RotatedTo = key.ID
+	replaced := keys[i]
+
+	keys = append(keys, key)
+
+	if err := s.store.SaveAPIKeys(ctx, keys); err != nil {
+		return nil, fmt.Errorf("failed to save api keys: %w", err)
+	}
+
+	s.keys.set(keys)
+
+	slog.InfoContext(ctx, "api key rotated", "id", id, "new_id", key.ID, "expires_at", replaced.ExpiresAt)
+
+	key.Hash, replaced.Hash = "", ""
+
+	return &RotateAPIKeyResponse{Replaced: replaced, CreateAPIKeyResponse: CreateAPIKeyResponse{Token: token, APIKey: key}}, nil
+}
+
+// ListAPIKeys returns the managed API keys without their hashes, oldest first. Rotated
+// keys are listed until they expire.
+func (s *Service) ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+	keys, err := s.activeAPIKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range keys {
+		keys[i].Hash = ""
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey deletes the managed API key with the given ID. It returns ErrNotFound if
+// there is no such key. Keys from the configuration file cannot be revoked this way.
+func (s *Service) RevokeAPIKey(ctx context.Context, id string) error {
+	s.keys.mu.Lock()
+	defer s.keys.mu.Unlock()
+
+	keys, err := s.activeAPIKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	kept := make([]APIKey, 0, len(keys))
+
+	for _, k := range keys {
+		if k.ID != id {
+			kept = append(kept, k)
+		}
+	}
+
+	if len(kept) == len(keys) {
+		return fmt.Errorf("%w: api key %s", ErrNotFound, id)
+	}
+
+	if err := s.store.SaveAPIKeys(ctx, kept); err != nil {
+		return fmt.Errorf("failed to save api keys: %w", err)
+	}
+
+	s.keys.set(kept)
+
+	slog.InfoContext(ctx, "api key revoked", "id", id)
+
+	return nil
+}
+
+// VerifyAPIKey reports whether token is a managed API key. The keys are cached for a
+// short time; if they cannot be loaded the previously cached keys are used.
+func (s *Service) VerifyAPIKey(ctx context.Context, token string) bool {
+	if !strings.HasPrefix(token, apiKeyPrefix) {
+		return false
+	}
+
+	s.keys.mu.Lock()
+	defer s.keys.mu.Unlock()
+
+	if time.Since(s.keys.loadedAt) > apiKeyCacheTTL {
+		keys, err := s.store.GetAPIKeys(ctx)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to load api keys", "error", err)
+		} else {
+			s.keys.set(keys)
+		}
+	}
+
+	hash := []byte(hashAPIKey(token))
+	now := time.Now()
+	valid := false
+
+	for i := range s.keys.keys {
+		if subtle.ConstantTimeCompare(hash, []byte(s.keys.keys[i].Hash)) == 1 && !s.keys.keys[i].expired(now) {
+			valid = true
+		}
+	}
+
+	return valid
+}
+
+// activeAPIKeys returns the stored keys that have not expired. Expired keys are dropped
+// from the store the next time the keys are saved.
+func (s *Service) activeAPIKeys(ctx context.Context) ([]APIKey, error) {
+	keys, err := s.store.GetAPIKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api keys: %w", err)
+	}
+
+	now := time.Now()
+
+	return slices.DeleteFunc(keys, func(k APIKey) bool { return k.expired(now) }), nil
+}
+
+// newAPIKey generates a key with the given name, returning it along with its token.
+func newAPIKey(name string) (APIKey, string, error) {
+	secret := make([]byte, 32)
+	id := make([]byte, 6)
+
+	if _, err := rand.Read(secret); err != nil {
+		return APIKey{}, "", fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	if _, err := rand.Read(id); err != nil {
+		return APIKey{}, "", fmt.Errorf("failed to generate key id: %w", err)
+	}
+
+	token := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	return APIKey{
+		CreatedAt: time.Now().UTC(),
+		ID:        hex.EncodeToString(id),
+		Name:      name,
+		Hash:      hashAPIKey(token),
+	}, token, nil
+}
+
+// set replaces the cached keys. The caller must hold mu.
+func (c *apiKeyCache) set(keys []APIKey) {
+	c.keys = keys
+	c.loadedAt = time.Now()
+}
+
+// hashAPIKey returns the hex-encoded SHA-256 hash of a token.
+func hashAPIKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
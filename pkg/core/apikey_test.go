@@ -0,0 +1,127 @@
+//go:build !compile
+
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeys_Lifecycle(t *testing.T) {
+	svc, store, _, _ := newTestService(t)
+
+	var saved []APIKey
+
+	store.EXPECT().GetAPIKeys(mock.Anything).RunAndReturn(func(_ context.Context) ([]APIKey, error) {
+		return append([]APIKey(nil), saved...), nil
+	})
+	store.EXPECT().SaveAPIKeys(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, keys []APIKey) error {
+		saved = keys
+		return nil
+	})
+
+	created, err := svc.CreateAPIKey(t.Context(), " ci ")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.Token, apiKeyPrefix))
+	assert.Equal(t, "ci", created.Name)
+	assert.NotEmpty(t, created.ID)
+	assert.Empty(t, created.Hash, "the hash is not returned")
+
+	require.Len(t, saved, 1)
+	assert.Equal(t, hashAPIKey(created.Token), saved[0].Hash, "only the hash is stored")
+	assert.NotContains(t, saved[0].Hash, created.Token)
+
+	assert.True(t, svc.VerifyAPIKey(t.Context(), created.Token))
+	assert.False(t, svc.VerifyAPIKey(t.Context(), apiKeyPrefix+"wrong"))
+	assert.False(t, svc.VerifyAPIKey(t.Context(), "changeme"))
+
+	keys, err := svc.ListAPIKeys(t.Context())
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, created.ID, keys[0].ID)
+	assert.Empty(t, keys[0].Hash)
+
+	require.NoError(t, svc.RevokeAPIKey(t.Context(), created.ID))
+	assert.Empty(t, saved)
+	assert.False(t, svc.VerifyAPIKey(t.Context(), created.Token))
+
+	assert.ErrorIs(t, svc.RevokeAPIKey(t.Context(), created.ID), ErrNotFound)
+}
+
+func TestCreateAPIKey_InvalidName(t *testing.T) {
+	svc := newTestServiceOnly(t)
+
+	_, err := svc.CreateAPIKey(t.Context(), "  ")
+	assert.ErrorIs(t, err, ErrInvalidPath)
+
+	_, err = svc.CreateAPIKey(t.Context(), strings.Repeat("a", maxKeyNameLen+1))
+	assert.ErrorIs(t, err, ErrInvalidPath)
+}
+
+func TestVerifyAPIKey_StoreFailure(t *testing.T) {
+	svc, store, _, _ := newTestService(t)
+
+	store.EXPECT().GetAPIKeys(mock.Anything).Return(nil, errors.New("bucket unavailable"))
+
+	assert.False(t, svc.VerifyAPIKey(t.Context(), apiKeyPrefix+"token"))
+}
+
+func TestRotateAPIKey(t *testing.T) {
+	svc, store, _, _ := newTestService(t)
+
+	var saved []APIKey
+
+	store.EXPECT().GetAPIKeys(mock.Anything).RunAndReturn(func(_ context.Context) ([]APIKey, error) {
+		return append([]APIKey(nil), saved...), nil
+	})
+	store.EXPECT().SaveAPIKeys(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, keys []APIKey) error {
+		saved = keys
+		return nil
+	})
+
+	created, err := svc.CreateAPIKey(t.Context(), "ci")
+	require.NoError(t, err)
+
+	rotated, err := svc.RotateAPIKey(t.Context(), created.ID, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, "ci", rotated.Name)
+	assert.NotEqual(t, created.ID, rotated.ID)
+	assert.Equal(t, rotated.ID, rotated.Replaced.RotatedTo)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), rotated.Replaced.ExpiresAt, time.Minute)
+	assert.Empty(t, rotated.Hash)
+	assert.Empty(t, rotated.Replaced.Hash)
+
+	assert.True(t, svc.VerifyAPIKey(t.Context(), created.Token), "the old key works during the overlap")
+	assert.True(t, svc.VerifyAPIKey(t.Context(), rotated.Token))
+
+	_, err = svc.RotateAPIKey(t.Context(), created.ID, time.Hour)
+	require.ErrorIs(t, err, ErrConflict, "a key is rotated once")
+
+	_, err = svc.RotateAPIKey(t.Context(), "missing", time.Hour)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	_, err = svc.RotateAPIKey(t.Context(), rotated.ID, -time.Second)
+	require.ErrorIs(t, err, ErrInvalidPath)
+
+	again, err := svc.RotateAPIKey(t.Context(), rotated.ID, 0)
+	require.NoError(t, err)
+
+	assert.False(t, svc.VerifyAPIKey(t.Context(), rotated.Token), "without overlap the old key stops working at once")
+	assert.True(t, svc.VerifyAPIKey(t.Context(), again.Token))
+
+	keys, err := svc.ListAPIKeys(t.Context())
+	require.NoError(t, err)
+	assert.Len(t, keys, 2, "expired keys are not listed")
+
+	saved[0].ExpiresAt = time.Now().Add(-time.Second)
+
+	require.NoError(t, svc.RevokeAPIKey(t.Context(), again.ID))
+	assert.Empty(t, saved, "expired keys are dropped when the keys are saved")
+}
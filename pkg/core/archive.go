@@ -0,0 +1,605 @@
+package core
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// archiveVersion identifies the layout of archives written by ExportArchive. Archives
+	// of other versions are refused on import.
+	archiveVersion = 1
+	// maxArchiveEntrySize is the largest document or asset accepted from an archive.
+	maxArchiveEntrySize = 64 << 20
+
+	archiveManifest  = "manifest.json"
+	archiveRedirects = "redirects.json"
+	archiveDocsDir   = "documents/"
+	archiveAssetsDir = "assets/"
+	archiveDocSuffix = ".json"
+)
+
+// ArchiveManifest describes the content of an instance archive. It is the first entry of
+// the archive.
+type ArchiveManifest struct {
+	CreatedAt time.Time `json:"created_at"`
+	Version   int       `json:"version"`
+	Repos     int       `json:"repos"`
+	Documents int       `json:"documents"`
+	Assets    int       `json:"assets"`
+}
+
+// ImportResponse is the outcome of importing an instance archive. Paths of lint issues,
+// policy findings and documents are prefixed with their repository.
+type ImportResponse struct {
+	Lint   []LintIssue     `json:"lint,omitempty"`
+	Policy []PolicyFinding `json:"policy,omitempty"`
+	// Rejected lists the documents that were not imported because linting of their
+	// repository failed in reject mode.
+	Rejected []FailedDocument `json:"rejected,omitempty"`
+	// Failed lists the documents that were stored but could not be indexed.
+	Failed    []FailedDocument `json:"failed,omitempty"`
+	Documents int              `json:"documents"`
+	Assets    int              `json:"assets"`
+	Redirects int              `json:"redirects"`
+}
+
+// archiveDocument is a document as stored in an archive.
+type archiveDocument struct {
+	UpdatedAt   time.Time   `json:"updated_at"`
+	Provenance  *Provenance `json:"provenance,omitempty"`
+	Repo        string      `json:"repo"`
+	Path        string      `json:"path"`
+	Title       string      `json:"title"`
+	Summary     string      `json:"summary,omitempty"`
+	Content     string      `json:"content"`
+	CommitSHA   string      `json:"commit_sha,omitempty"`
+	ContentType ContentType `json:"content_type"`
+	Encoding    string      `json:"encoding,omitempty"` // "base64" for content that is not UTF-8 text
+	Tags        []string    `json:"tags,omitempty"`
+	Order       int         `json:"order,omitempty"`
+	Home        bool        `json:"home,omitempty"`
+	Draft       bool        `json:"draft,omitempty"`
+}
+
+// ExportArchive writes every stored document and asset, and the repository redirects, to
+// w as a gzip-compressed tar archive that ImportArchive loads into another instance, for
+// example to seed a staging environment with production content. The search index is not
+// copied as such: it is derived from the documents and rebuilt by the importing instance,
+// whatever its search backend. API keys are left out.
+func (s *Service) ExportArchive(ctx context.Context, w io.Writer) error {
+	repos, err := s.store.ListRepos(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list repos: %w", err)
+	}
+
+	docs := make(map[string][]DocumentMeta, len(repos))
+	assets := make(map[string][]string, len(repos))
+	manifest := ArchiveManifest{Version: archiveVersion, CreatedAt: time.Now().UTC(), Repos: len(repos)}
+
+	for _, repo := range repos {
+		if docs[repo.Name], err = s.store.List(ctx, repo.Name); err != nil {
+			return fmt.Errorf("failed to list documents for repo %s: %w", repo.Name, err)
+		}
+
+		stored, err := s.store.ListAssets(ctx, repo.Name)
+		if err != nil {
+			return fmt.Errorf("failed to list assets for repo %s: %w", repo.Name, err)
+		}
+
+		// Image variants are generated again when the archive is imported.
+		for _, assetPath := range stored {
+			if !isVariantPath(assetPath) {
+				assets[repo.Name] = append(assets[repo.Name], assetPath)
+			}
+		}
+
+		manifest.Documents += len(docs[repo.Name])
+		manifest.Assets += len(assets[repo.Name])
+	}
+
+	redirects, err := s.store.GetRedirects(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load redirects: %w", err)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	if err := writeArchiveJSON(tw, archiveManifest, manifest); err != nil {
+		return err
+	}
+
+	for _, repo := range repos {
+		for _, meta := range docs[repo.Name] {
+			if err := ctx.Err(); err != nil {
+				return fmt.Errorf("export cancelled: %w", err)
+			}
+
+			doc, err := s.store.Get(ctx, repo.Name, meta.Path)
+			if err != nil {
+				return fmt.Errorf("failed to get document %s: %w", meta.ID, err)
+			}
+
+			entry := archiveDocument{
+				UpdatedAt:   doc.UpdatedAt,
+				Provenance:  doc.Provenance,
+				Repo:        doc.Repo,
+				Path:        doc.Path,
+				Title:       doc.Title,
+				Summary:     doc.Summary,
+				Content:     doc.Content,
+				CommitSHA:   doc.CommitSHA,
+				ContentType: doc.ContentType,
+				Tags:        doc.Tags,
+				Order:       doc.Order,
+				Home:        doc.Home,
+				Draft:       doc.Draft,
+			}
+
+			// JSON strings hold text only, so binary documents are archived base64-encoded.
+			if !utf8.ValidString(entry.Content) {
+				entry.Content = base64.StdEncoding.EncodeToString([]byte(entry.Content))
+				entry.Encoding = ContentEncodingBase64
+			}
+
+			if err := writeArchiveJSON(tw, archiveEntryName(archiveDocsDir, repo.Name, meta.Path)+archiveDocSuffix, entry); err != nil {
+				return err
+			}
+		}
+
+		for _, assetPath := range assets[repo.Name] {
+			data, err := s.store.GetAsset(ctx, repo.Name, assetPath)
+			if err != nil {
+				return fmt.Errorf("failed to read asset %s/%s: %w", repo.Name, assetPath, err)
+			}
+
+			if err := writeArchiveFile(tw, archiveEntryName(archiveAssetsDir, repo.Name, assetPath), data); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := writeArchiveJSON(tw, archiveRedirects, redirects); err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+
+	return nil
+}
+
+// ImportArchive loads an archive written by ExportArchive: it stores and indexes its
+// documents, stores its assets and adds its redirects. Documents and assets already
+// stored at the same paths are replaced; other content of the instance is kept. Documents
+// go through the same checks as at ingest: they are linted by repository once the whole
+// archive has been read, the content policy is applied, and their provenance is no longer
+// marked as verified, as the signature it was verified by is not part of the archive.
+// Documents that fail to index are stored nonetheless and reported in the response, like
+// at ingest. It returns an error wrapping ErrInvalidArchive when the archive is malformed,
+// in which case the assets and redirects read before the malformed entry have been
+// imported, but none of the documents.
+func (s *Service) ImportArchive(ctx context.Context, r io.Reader) (*ImportResponse, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+	}
+
+	defer gz.Close()
+
+	defer s.invalidateSearches()
+
+	tr := tar.NewReader(gz)
+	resp := &ImportResponse{}
+	manifest := false
+
+	var docs []Document
+
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return resp, fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+		}
+
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			return resp, fmt.Errorf("import cancelled: %w", err)
+		}
+
+		data, err := readArchiveEntry(tr, hdr)
+		if err != nil {
+			return resp, err
+		}
+
+		if !manifest {
+			if err := checkArchiveManifest(hdr.Name, data); err != nil {
+				return resp, err
+			}
+
+			manifest = true
+
+			continue
+		}
+
+		switch {
+		case hdr.Name == archiveRedirects:
+			if resp.Redirects, err = s.importRedirects(ctx, data); err != nil {
+				return resp, err
+			}
+		case strings.HasPrefix(hdr.Name, archiveDocsDir):
+			doc, err := parseArchiveDocument(data)
+			if err != nil {
+				return resp, err
+			}
+
+			docs = append(docs, doc)
+		case strings.HasPrefix(hdr.Name, archiveAssetsDir):
+			stored, err := s.importAsset(ctx, hdr.Name, data)
+			if err != nil {
+				return resp, err
+			}
+
+			if stored {
+				resp.Assets++
+			}
+		default:
+			slog.WarnContext(ctx, "import: skipping unknown archive entry", "name", hdr.Name)
+		}
+	}
+
+	if !manifest {
+		return resp, fmt.Errorf("%w: archive is empty", ErrInvalidArchive)
+	}
+
+	if err := s.importDocuments(ctx, docs, resp); err != nil {
+		return resp, err
+	}
+
+	s.resetContentIndex()
+
+	return resp, nil
+}
+
+// importDocuments lints, stores and indexes the documents read from an archive,
+// repository by repository, as an ingest request of the repository would. Links are
+// resolved against the stored documents and assets, which include those of the archive.
+func (s *Service) importDocuments(ctx context.Context, docs []Document, resp *ImportResponse) error {
+	var repos []string
+
+	byRepo := make(map[string][]Document)
+
+	for i := range docs {
+		if _, ok := byRepo[docs[i].Repo]; !ok {
+			repos = append(repos, docs[i].Repo)
+		}
+
+		byRepo[docs[i].Repo] = append(byRepo[docs[i].Repo], docs[i])
+	}
+
+	for _, repo := range repos {
+		if err := s.importRepoDocuments(ctx, repo, byRepo[repo], resp); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// importRepoDocuments lints, stores and indexes the documents of a repository read from
+// an archive. When linting rejects them, none of them are stored.
+func (s *Service) importRepoDocuments(ctx context.Context, repo string, docs []Document, resp *ImportResponse) error {
+	req := &IngestRequest{Repo: repo, Documents: make([]IngestDocument, 0, len(docs))}
+
+	for i := range docs {
+		req.Documents = append(req.Documents, IngestDocument{
+			Path:        docs[i].Path,
+			Content:     docs[i].Content,
+			ContentType: docs[i].ContentType,
+			Action:      actionUpsert,
+		})
+	}
+
+	issues, err := s.lintRequest(ctx, req)
+
+	var lintErr *LintError
+	if errors.As(err, &lintErr) {
+		issues = lintErr.Issues
+	} else if err != nil {
+		return err
+	}
+
+	for _, issue := range issues {
+		issue.Path = repo + "/" + issue.Path
+		resp.Lint = append(resp.Lint, issue)
+	}
+
+	if lintErr != nil {
+		for i := range docs {
+			resp.Rejected = append(resp.Rejected, FailedDocument{Path: docs[i].ID, Error: lintErr.Error()})
+		}
+
+		return nil
+	}
+
+	for i := range docs {
+		if err := s.importDocument(ctx, &docs[i], resp); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// importDocument applies the content policy to a document read from an archive, then
+// stores and indexes it.
+func (s *Service) importDocument(ctx context.Context, doc *Document, resp *ImportResponse) error {
+	if !s.binaryContent(doc.ContentType) {
+		var findings []PolicyFinding
+
+		doc.Content, findings = s.applyPolicy(ctx, doc.Repo, doc.ID, doc.Content)
+		resp.Policy = append(resp.Policy, findings...)
+	}
+
+	// Assets are imported before documents, so the variants of their images are known.
+	if !s.binaryContent(doc.ContentType) {
+		doc.Images = s.documentImages(ctx, doc.Repo, doc.Path, doc.Content)
+	}
+
+	if err := s.store.Save(ctx, *doc); err != nil {
+		return fmt.Errorf("failed to save document %s: %w", doc.ID, err)
+	}
+
+	resp.Documents++
+
+	plainText, code, err := s.indexDocument(ctx, s.search, doc)
+	if err != nil {
+		slog.WarnContext(ctx, "import: failed to index document", "repo", doc.Repo, "path", doc.Path, "error", err)
+
+		resp.Failed = append(resp.Failed, FailedDocument{Path: doc.ID, Error: err.Error()})
+
+		return nil
+	}
+
+	s.embedDocument(ctx, doc, plainText, code)
+	s.publishEvent(EventDocumentUpdated, doc.Repo, doc.Path)
+
+	return nil
+}
+
+// parseArchiveDocument decodes and validates a document entry of an archive. The
+// provenance is kept for reference, but no longer marked as verified.
+func parseArchiveDocument(data []byte) (Document, error) {
+	var entry archiveDocument
+
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return Document{}, fmt.Errorf("%w: invalid document: %w", ErrInvalidArchive, err)
+	}
+
+	if err := validateRepoName(entry.Repo); err != nil {
+		return Document{}, fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+	}
+
+	docPath, err := normalizePath(entry.Path)
+	if err != nil {
+		return Document{}, fmt.Errorf("%w: document %s: %w", ErrInvalidArchive, entry.Path, err)
+	}
+
+	if entry.ContentType == "" {
+		entry.ContentType = ContentTypeMarkdown
+	}
+
+	switch entry.Encoding {
+	case "":
+	case ContentEncodingBase64:
+		content, err := base64.StdEncoding.DecodeString(entry.Content)
+		if err != nil {
+			return Document{}, fmt.Errorf("%w: document %s: invalid base64 content: %w", ErrInvalidArchive, entry.Path, err)
+		}
+
+		entry.Content = string(content)
+	default:
+		return Document{}, fmt.Errorf("%w: document %s: unknown content encoding %q", ErrInvalidArchive, entry.Path, entry.Encoding)
+	}
+
+	if entry.Provenance != nil {
+		prov := *entry.Provenance
+		prov.Verified = false
+		entry.Provenance = &prov
+	}
+
+	return Document{
+		UpdatedAt:   entry.UpdatedAt,
+		Provenance:  entry.Provenance,
+		ID:          NewDocumentID(entry.Repo, docPath).String(),
+		Repo:        entry.Repo,
+		Path:        docPath,
+		Title:       entry.Title,
+		Summary:     entry.Summary,
+		Content:     entry.Content,
+		CommitSHA:   entry.CommitSHA,
+		ContentType: entry.ContentType,
+		Tags:        entry.Tags,
+		Order:       entry.Order,
+		Home:        entry.Home,
+		Draft:       entry.Draft,
+	}, nil
+}
+
+// importAsset stores an asset read from the archive entry with the given name, together
+// with its image variants. Entries under the prefix reserved for image variants, which
+// older archives hold, are skipped since the variants are generated again; it reports
+// whether the asset was stored.
+func (s *Service) importAsset(ctx context.Context, name string, data []byte) (bool, error) {
+	repo, assetPath, err := parseArchiveEntryName(strings.TrimPrefix(name, archiveAssetsDir))
+	if err != nil {
+		return false, err
+	}
+
+	if isVariantPath(assetPath) {
+		slog.DebugContext(ctx, "import: skipping image variant", "name", name)
+		return false, nil
+	}
+
+	if err := s.store.SaveAsset(ctx, repo, assetPath, data); err != nil {
+		return false, fmt.Errorf("failed to save asset %s/%s: %w", repo, assetPath, err)
+	}
+
+	if isRasterImage(assetPath) {
+		s.storeImageVariants(ctx, repo, assetPath, data)
+	}
+
+	return true, nil
+}
+
+// importRedirects adds the redirects read from an archive to the stored ones, replacing
+// stored redirects from the same identifiers, and returns their number.
+func (s *Service) importRedirects(ctx context.Context, data []byte) (int, error) {
+	var imported map[string]string
+
+	if err := json.Unmarshal(data, &imported); err != nil {
+		return 0, fmt.Errorf("%w: invalid redirects: %w", ErrInvalidArchive, err)
+	}
+
+	if len(imported) == 0 {
+		return 0, nil
+	}
+
+	redirects, err := s.store.GetRedirects(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load redirects: %w", err)
+	}
+
+	if redirects == nil {
+		redirects = make(map[string]string, len(imported))
+	}
+
+	for from, to := range imported {
+		redirects[from] = to
+	}
+
+	if err := s.store.SaveRedirects(ctx, redirects); err != nil {
+		return 0, fmt.Errorf("failed to save redirects: %w", err)
+	}
+
+	return len(imported), nil
+}
+
+// checkArchiveManifest reports whether the first entry of an archive is a manifest of a
+// supported version.
+func checkArchiveManifest(name string, data []byte) error {
+	if name != archiveManifest {
+		return fmt.Errorf("%w: archive does not start with %s", ErrInvalidArchive, archiveManifest)
+	}
+
+	var m ArchiveManifest
+
+	if err := json.Unmarshal(data, &m); err != nil {
+		return fmt.Errorf("%w: invalid manifest: %w", ErrInvalidArchive, err)
+	}
+
+	if m.Version != archiveVersion {
+		return fmt.Errorf("%w: unsupported archive version %d", ErrInvalidArchive, m.Version)
+	}
+
+	return nil
+}
+
+// archiveEntryName returns the name of the archive entry of a repository file under dir.
+// The repository is escaped into a single segment, as it may have a project segment.
+func archiveEntryName(dir, repo, filePath string) string {
+	return dir + url.PathEscape(repo) + "/" + filePath
+}
+
+// parseArchiveEntryName returns the repository and path of an archive entry name relative
+// to its directory.
+func parseArchiveEntryName(name string) (repo, filePath string, err error) {
+	escaped, rest, ok := strings.Cut(name, "/")
+	if !ok {
+		return "", "", fmt.Errorf("%w: entry %s has no repository", ErrInvalidArchive, name)
+	}
+
+	if repo, err = url.PathUnescape(escaped); err != nil {
+		return "", "", fmt.Errorf("%w: entry %s: %w", ErrInvalidArchive, name, err)
+	}
+
+	if err := validateRepoName(repo); err != nil {
+		return "", "", fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+	}
+
+	if filePath, err = normalizePath(rest); err != nil {
+		return "", "", fmt.Errorf("%w: entry %s: %w", ErrInvalidArchive, name, err)
+	}
+
+	return repo, filePath, nil
+}
+
+// readArchiveEntry reads the content of the current archive entry, refusing entries over
+// maxArchiveEntrySize.
+func readArchiveEntry(tr *tar.Reader, hdr *tar.Header) ([]byte, error) {
+	if hdr.Size > maxArchiveEntrySize {
+		return nil, fmt.Errorf("%w: entry %s is larger than %d bytes", ErrInvalidArchive, hdr.Name, maxArchiveEntrySize)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(tr, maxArchiveEntrySize))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read entry %s: %w", ErrInvalidArchive, hdr.Name, err)
+	}
+
+	return data, nil
+}
+
+// writeArchiveJSON writes v as a JSON file entry of the archive.
+func writeArchiveJSON(tw *tar.Writer, name string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", name, err)
+	}
+
+	return writeArchiveFile(tw, name, data)
+}
+
+// writeArchiveFile writes data as a file entry of the archive.
+func writeArchiveFile(tw *tar.Writer, name string, data []byte) error {
+	hdr := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0o644,
+		Size:     int64(len(data)),
+		ModTime:  time.Now().UTC(),
+		Format:   tar.FormatPAX,
+	}
+
+	if err := tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("failed to write archive entry %s: %w", name, err)
+	}
+
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write archive entry %s: %w", name, err)
+	}
+
+	return nil
+}
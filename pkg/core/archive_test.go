@@ -0,0 +1,198 @@
+//go:build !compile
+
+package core
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportImportArchive(t *testing.T) {
+	svc, store, _, _ := newTestService(t)
+
+	updated := time.Date(2025, 6, 15, 10, 0, 0, 0, time.UTC)
+	guide := Document{
+		ID: "owner/repo/docs/guide.md", Repo: "owner/repo", Path: "docs/guide.md", Title: "Guide",
+		Content: "# Guide", CommitSHA: "abc123", ContentType: ContentTypeMarkdown, UpdatedAt: updated, Home: true,
+	}
+	api := Document{
+		ID: "owner/mono/api/spec.yaml", Repo: "owner/mono/api", Path: "spec.yaml", Title: "API",
+		Content: "openapi: 3.0.0", ContentType: ContentTypeOpenAPI, UpdatedAt: updated,
+	}
+
+	store.EXPECT().ListRepos(mock.Anything).Return([]RepoInfo{{Name: "owner/repo"}, {Name: "owner/mono/api"}}, nil)
+	store.EXPECT().List(mock.Anything, "owner/repo").Return([]DocumentMeta{{ID: guide.ID, Path: guide.Path}}, nil)
+	store.EXPECT().List(mock.Anything, "owner/mono/api").Return([]DocumentMeta{{ID: api.ID, Path: api.Path}}, nil)
+	store.EXPECT().ListAssets(mock.Anything, "owner/repo").Return([]string{
+		"images/logo.png",
+		"_variants/480/images/logo.png",
+		"_variants/images/logo.png.json",
+	}, nil)
+	store.EXPECT().ListAssets(mock.Anything, "owner/mono/api").Return(nil, nil)
+	store.EXPECT().Get(mock.Anything, "owner/repo", "docs/guide.md").Return(guide, nil)
+	store.EXPECT().Get(mock.Anything, "owner/mono/api", "spec.yaml").Return(api, nil)
+	store.EXPECT().GetAsset(mock.Anything, "owner/repo", "images/logo.png").Return([]byte{0x89, 'P', 'N', 'G'}, nil)
+	store.EXPECT().GetRedirects(mock.Anything).Return(map[string]string{"old/repo": "owner/repo"}, nil)
+
+	var archive bytes.Buffer
+
+	require.NoError(t, svc.ExportArchive(t.Context(), &archive))
+
+	target, targetStore, targetSearch, processor := newTestService(t)
+	openapi := NewMockContentProcessor(t)
+	target.processors[ContentTypeOpenAPI] = openapi
+
+	targetStore.EXPECT().Save(mock.Anything, guide).Return(nil)
+	targetStore.EXPECT().Save(mock.Anything, api).Return(nil)
+	targetStore.EXPECT().SaveAsset(mock.Anything, "owner/repo", "images/logo.png", []byte{0x89, 'P', 'N', 'G'}).Return(nil)
+	expectImageVariantsDeleted(targetStore, "owner/repo", "images/logo.png")
+	targetStore.EXPECT().GetRedirects(mock.Anything).Return(map[string]string{"other/repo": "owner/other"}, nil)
+	targetStore.EXPECT().SaveRedirects(mock.Anything, map[string]string{
+		"other/repo": "owner/other",
+		"old/repo":   "owner/repo",
+	}).Return(nil)
+
+	processor.EXPECT().ToPlainText([]byte("# Guide")).Return("Guide")
+	processor.EXPECT().ExtractCodeBlocks([]byte("# Guide")).Return(nil)
+	processor.EXPECT().ExtractHeadings([]byte("# Guide")).Return(nil)
+	openapi.EXPECT().ToPlainText([]byte("openapi: 3.0.0")).Return("API")
+	openapi.EXPECT().ExtractCodeBlocks([]byte("openapi: 3.0.0")).Return(nil)
+	openapi.EXPECT().ExtractHeadings([]byte("openapi: 3.0.0")).Return(nil)
+
+	indexedGuide, indexedAPI := guide, api
+	indexedGuide.Anchors, indexedAPI.Anchors = AnchorMap{}, AnchorMap{}
+	targetSearch.EXPECT().Index(mock.Anything, indexedGuide, "Guide", []CodeBlock(nil), []Heading(nil)).Return(nil)
+	targetSearch.EXPECT().Index(mock.Anything, indexedAPI, "API", []CodeBlock(nil), []Heading(nil)).Return(errors.New("index unavailable"))
+
+	resp, err := target.ImportArchive(t.Context(), &archive)
+	require.NoError(t, err)
+	assert.Equal(t, 2, resp.Documents)
+	assert.Equal(t, 1, resp.Assets)
+	assert.Equal(t, 1, resp.Redirects)
+	require.Len(t, resp.Failed, 1)
+	assert.Equal(t, "owner/mono/api/spec.yaml", resp.Failed[0].Path)
+}
+
+func TestImportArchive_Invalid(t *testing.T) {
+	entries := func(files ...[2]string) []byte {
+		var buf bytes.Buffer
+
+		gz := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gz)
+
+		for _, f := range files {
+			require.NoError(t, writeArchiveFile(tw, f[0], []byte(f[1])))
+		}
+
+		require.NoError(t, tw.Close())
+		require.NoError(t, gz.Close())
+
+		return buf.Bytes()
+	}
+
+	manifest := [2]string{archiveManifest, `{"version":1}`}
+
+	tests := []struct {
+		name    string
+		archive []byte
+	}{
+		{name: "not gzip", archive: []byte("plain text")},
+		{name: "empty", archive: entries()},
+		{name: "no manifest", archive: entries([2]string{archiveRedirects, `{}`})},
+		{name: "unsupported version", archive: entries([2]string{archiveManifest, `{"version":99}`})},
+		{name: "invalid repo", archive: entries(manifest, [2]string{"documents/x/a.md.json", `{"repo":"../etc","path":"a.md"}`})},
+		{name: "escaping path", archive: entries(manifest, [2]string{"documents/x/a.md.json", `{"repo":"owner/repo","path":"../a.md"}`})},
+		{name: "escaping asset", archive: entries(manifest, [2]string{"assets/owner%2Frepo/../../etc/passwd", "x"})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newTestServiceOnly(t)
+
+			_, err := svc.ImportArchive(t.Context(), bytes.NewReader(tt.archive))
+			require.ErrorIs(t, err, ErrInvalidArchive)
+		})
+	}
+}
+
+func TestImportArchive_SkipsImageVariants(t *testing.T) {
+	svc, store, _, _ := newTestService(t)
+
+	var archive bytes.Buffer
+
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+
+	require.NoError(t, writeArchiveJSON(tw, archiveManifest, ArchiveManifest{Version: archiveVersion}))
+	require.NoError(t, writeArchiveFile(tw, archiveEntryName(archiveAssetsDir, "owner/repo", "logo.svg"), []byte("<svg/>")))
+	require.NoError(t, writeArchiveFile(tw, archiveEntryName(archiveAssetsDir, "owner/repo", "_variants/480/photo.png"), []byte("png")))
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+
+	store.EXPECT().SaveAsset(mock.Anything, "owner/repo", "logo.svg", []byte("<svg/>")).Return(nil)
+
+	resp, err := svc.ImportArchive(t.Context(), &archive)
+	require.NoError(t, err)
+	assert.Equal(t, 1, resp.Assets)
+}
+
+func TestImportArchive_Checks(t *testing.T) {
+	store := NewMockdocStore(t)
+	search := NewMocksearchEngine(t)
+	processor := NewMockContentProcessor(t)
+	svc := New(store, search, map[ContentType]ContentProcessor{
+		ContentTypeMarkdown: processor,
+	}, WithContentPolicy(secretPolicy{}), WithLint(LintConfig{Mode: LintModeReject}))
+
+	var archive bytes.Buffer
+
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+
+	require.NoError(t, writeArchiveJSON(tw, archiveManifest, ArchiveManifest{Version: archiveVersion}))
+	require.NoError(t, writeArchiveJSON(tw, archiveEntryName(archiveDocsDir, "owner/repo", "plan.md")+archiveDocSuffix, archiveDocument{
+		Repo: "owner/repo", Path: "plan.md", Content: "# The secret plan", ContentType: ContentTypeMarkdown,
+		Provenance: &Provenance{Workflow: "owner/repo/.github/workflows/docs.yml@refs/heads/main", Verified: true},
+	}))
+	require.NoError(t, writeArchiveJSON(tw, archiveEntryName(archiveDocsDir, "owner/bad", "notes.md")+archiveDocSuffix, archiveDocument{
+		Repo: "owner/bad", Path: "notes.md", Content: "no heading", ContentType: ContentTypeMarkdown,
+	}))
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+
+	redacted := []byte("# The [REDACTED] plan")
+
+	for _, repo := range []string{"owner/repo", "owner/bad"} {
+		store.EXPECT().List(mock.Anything, repo).Return(nil, nil)
+		store.EXPECT().ListAssets(mock.Anything, repo).Return(nil, nil)
+	}
+
+	processor.EXPECT().ExtractHeadings([]byte("# The secret plan")).Return([]Heading{{Level: 1, Text: "The secret plan"}})
+	processor.EXPECT().ExtractHeadings([]byte("no heading")).Return(nil)
+	processor.EXPECT().ToPlainText(redacted).Return("The [REDACTED] plan")
+	processor.EXPECT().ExtractCodeBlocks(redacted).Return(nil)
+	processor.EXPECT().ExtractHeadings(redacted).Return(nil)
+	store.EXPECT().Save(mock.Anything, mock.MatchedBy(func(doc Document) bool {
+		return doc.Path == "plan.md" && doc.Content == string(redacted) &&
+			doc.Provenance != nil && doc.Provenance.Workflow != "" && !doc.Provenance.Verified
+	})).Return(nil)
+	search.EXPECT().Index(mock.Anything, mock.Anything, "The [REDACTED] plan", []CodeBlock(nil), []Heading(nil)).Return(nil)
+
+	resp, err := svc.ImportArchive(t.Context(), &archive)
+	require.NoError(t, err)
+	assert.Equal(t, 1, resp.Documents)
+	assert.Equal(t, []PolicyFinding{{Path: "owner/repo/plan.md", Rule: "secret", Action: PolicyActionRedact, Line: 1}}, resp.Policy)
+	require.Len(t, resp.Lint, 1)
+	assert.Equal(t, "owner/bad/notes.md", resp.Lint[0].Path)
+	assert.Equal(t, LintRuleMissingH1, resp.Lint[0].Rule)
+	require.Len(t, resp.Rejected, 1)
+	assert.Equal(t, "owner/bad/notes.md", resp.Rejected[0].Path)
+}
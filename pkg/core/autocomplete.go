@@ -0,0 +1,50 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Search-as-you-type suggestion limits.
+const (
+	DefaultSuggestLimit = 8
+	MaxSuggestLimit     = 20
+)
+
+// SearchSuggestion is a document whose title, or one of whose section headings, starts
+// with the words typed in the search box.
+type SearchSuggestion struct {
+	ID      string `json:"id"` // encoded DocumentID of the document
+	Repo    string `json:"repo"`
+	Path    string `json:"path"`
+	Title   string `json:"title"`
+	Heading string `json:"heading,omitempty"` // matching section heading; empty when the title matches
+	Anchor  string `json:"anchor,omitempty"`  // anchor ID of Heading
+}
+
+// SuggestSearch returns up to limit documents whose titles or section headings match
+// prefix, where every word but the last must match a whole word and the last may be the
+// start of one. The limit defaults to DefaultSuggestLimit and is capped at MaxSuggestLimit.
+// A blank prefix has no suggestions.
+func (s *Service) SuggestSearch(ctx context.Context, prefix string, limit int) ([]SearchSuggestion, error) {
+	prefix = strings.TrimSpace(prefix)
+	if prefix == "" {
+		return nil, nil
+	}
+
+	if limit <= 0 {
+		limit = DefaultSuggestLimit
+	}
+
+	suggestions, err := s.search.Suggest(ctx, prefix, min(limit, MaxSuggestLimit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest: %w", err)
+	}
+
+	for i := range suggestions {
+		suggestions[i].ID = NewDocumentID(suggestions[i].Repo, suggestions[i].Path).Encode()
+	}
+
+	return suggestions, nil
+}
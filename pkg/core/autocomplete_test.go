@@ -0,0 +1,59 @@
+//go:build !compile
+
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuggestSearch(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		want   string
+		limit  int
+		engine int
+	}{
+		{name: "default limit", prefix: "inst", want: "inst", limit: 0, engine: DefaultSuggestLimit},
+		{name: "requested limit", prefix: " inst ", want: "inst", limit: 3, engine: 3},
+		{name: "capped limit", prefix: "inst", want: "inst", limit: 100, engine: MaxSuggestLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _, search, _ := newTestService(t)
+
+			found := []SearchSuggestion{{Repo: "owner/repo", Path: "install.md", Title: "Installation"}}
+			search.EXPECT().Suggest(mock.Anything, tt.want, tt.engine).Return(found, nil)
+
+			suggestions, err := svc.SuggestSearch(t.Context(), tt.prefix, tt.limit)
+			require.NoError(t, err)
+			assert.Equal(t, []SearchSuggestion{{
+				ID:   NewDocumentID("owner/repo", "install.md").Encode(),
+				Repo: "owner/repo", Path: "install.md", Title: "Installation",
+			}}, suggestions)
+		})
+	}
+}
+
+func TestSuggestSearch_BlankPrefix(t *testing.T) {
+	svc := newTestServiceOnly(t)
+
+	suggestions, err := svc.SuggestSearch(t.Context(), "   ", 5)
+	require.NoError(t, err)
+	assert.Empty(t, suggestions)
+}
+
+func TestSuggestSearch_Error(t *testing.T) {
+	svc, _, search, _ := newTestService(t)
+
+	search.EXPECT().Suggest(mock.Anything, "inst", DefaultSuggestLimit).Return(nil, errors.New("index unavailable"))
+
+	_, err := svc.SuggestSearch(t.Context(), "inst", 0)
+	require.ErrorContains(t, err, "failed to suggest")
+}
@@ -0,0 +1,189 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// bannerCacheTTL bounds how long a site banner changed on another instance takes to show here.
+const bannerCacheTTL = 30 * time.Second
+
+// BannerSeverity is how prominently a site banner is styled.
+type BannerSeverity string
+
+const (
+	// BannerInfo styles a banner as a notice, such as a documentation event.
+	BannerInfo BannerSeverity = "info"
+	// BannerWarning styles a banner as a warning, such as upcoming maintenance.
+	BannerWarning BannerSeverity = "warning"
+	// BannerCritical styles a banner as an alert, such as an ongoing outage.
+	BannerCritical BannerSeverity = "critical"
+)
+
+// valid reports whether s is a known severity.
+func (s BannerSeverity) valid() bool {
+	switch s {
+	case BannerInfo, BannerWarning, BannerCritical:
+		return true
+	default:
+		return false
+	}
+}
+
+// SiteBanner is a markdown message shown at the top of every portal page, such as a
+// maintenance notice. It is shown from StartsAt until EndsAt; a zero time leaves that end
+// of the window open.
+type SiteBanner struct {
+	StartsAt  time.Time      `json:"starts_at,omitzero"`
+	EndsAt    time.Time      `json:"ends_at,omitzero"`
+	UpdatedAt time.Time      `json:"updated_at,omitzero"`
+	Message   string         `json:"message"`
+	Severity  BannerSeverity `json:"severity"`
+}
+
+// activeAt reports whether the banner is shown at t.
+func (b *SiteBanner) activeAt(t time.Time) bool {
+	return (b.StartsAt.IsZero() || !t.Before(b.StartsAt)) && (b.EndsAt.IsZero() || t.Before(b.EndsAt))
+}
+
+// PageBanner is the site banner prepared for portal pages.
+type PageBanner struct {
+	Severity BannerSeverity
+	HTML     []byte
+}
+
+// bannerCache holds the stored site banner with its rendered message, reloaded once it is
+// older than bannerCacheTTL.
+type bannerCache struct {
+	loadedAt time.Time
+	banner   *SiteBanner
+	html     []byte
+	mu       sync.Mutex
+}
+
+// GetSiteBanner returns the site banner, whether or not it is currently shown. It returns
+// ErrNotFound when no banner is set.
+func (s *Service) GetSiteBanner(ctx context.Context) (*SiteBanner, error) {
+	b, err := s.store.GetSiteBanner(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get site banner: %w", err)
+	}
+
+	if b == nil {
+		return nil, fmt.Errorf("%w: no site banner is set", ErrNotFound)
+	}
+
+	return b, nil
+}
+
+// SetSiteBanner replaces the site banner. The severity defaults to info. It returns
+// ErrInvalidPath for an empty or too long message, an unknown severity, or a window that
+// ends before it starts.
+func (s *Service) SetSiteBanner(ctx context.Context, banner SiteBanner) (*SiteBanner, error) {
+	banner.Message = strings.TrimSpace(banner.Message)
+
+	if banner.Message == "" {
+		return nil, fmt.Errorf("%w: banner message is required", ErrInvalidPath)
+	}
+
+	if utf8.RuneCountInString(banner.Message) > maxAnnouncementLen {
+		return nil, fmt.Errorf("%w: banner message must be at most %d characters", ErrInvalidPath, maxAnnouncementLen)
+	}
+
+	if banner.Severity == "" {
+		banner.Severity = BannerInfo
+	}
+
+	if !banner.Severity.valid() {
+		return nil, fmt.Errorf("%w: unknown severity %q, expected %s, %s or %s", ErrInvalidPath, banner.Severity, BannerInfo, BannerWarning, BannerCritical)
+	}
+
+	if !banner.StartsAt.IsZero() && !banner.EndsAt.IsZero() && !banner.EndsAt.After(banner.StartsAt) {
+		return nil, fmt.Errorf("%w: banner must end after it starts", ErrInvalidPath)
+	}
+
+	banner.StartsAt = banner.StartsAt.UTC()
+	banner.EndsAt = banner.EndsAt.UTC()
+	banner.UpdatedAt = time.Now().UTC()
+
+	if err := s.store.SaveSiteBanner(ctx, &banner); err != nil {
+		return nil, fmt.Errorf("failed to save site banner: %w", err)
+	}
+
+	s.banner.mu.Lock()
+	s.banner.set(s.renderBanner(ctx, &banner), &banner)
+	s.banner.mu.Unlock()
+
+	slog.InfoContext(ctx, "site banner set", "severity", banner.Severity, "starts_at", banner.StartsAt, "ends_at", banner.EndsAt)
+
+	return &banner, nil
+}
+
+// ClearSiteBanner removes the site banner.
+func (s *Service) ClearSiteBanner(ctx context.Context) error {
+	if err := s.store.SaveSiteBanner(ctx, nil); err != nil {
+		return fmt.Errorf("failed to clear site banner: %w", err)
+	}
+
+	s.banner.mu.Lock()
+	s.banner.set(nil, nil)
+	s.banner.mu.Unlock()
+
+	slog.InfoContext(ctx, "site banner cleared")
+
+	return nil
+}
+
+// ActiveSiteBanner returns the site banner prepared for portal pages, or nil when none is
+// shown now. The banner is cached for a short time; if it cannot be loaded the previously
+// cached one is used.
+func (s *Service) ActiveSiteBanner(ctx context.Context) *PageBanner {
+	s.banner.mu.Lock()
+	defer s.banner.mu.Unlock()
+
+	if time.Since(s.banner.loadedAt) > bannerCacheTTL {
+		b, err := s.store.GetSiteBanner(ctx)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to load site banner", "error", err)
+
+			// Failures are retried after the TTL rather than on every page view.
+			s.banner.loadedAt = time.Now()
+		} else {
+			s.banner.set(s.renderBanner(ctx, b), b)
+		}
+	}
+
+	if s.banner.banner == nil || len(s.banner.html) == 0 || !s.banner.banner.activeAt(time.Now()) {
+		return nil
+	}
+
+	return &PageBanner{Severity: s.banner.banner.Severity, HTML: s.banner.html}
+}
+
+// renderBanner renders the message of a banner with the markdown processor. It returns nil
+// for a nil banner or when rendering fails.
+func (s *Service) renderBanner(ctx context.Context, b *SiteBanner) []byte {
+	if b == nil {
+		return nil
+	}
+
+	html, _, err := renderHTML(ctx, s.getProcessor(ContentTypeMarkdown, ""), []byte(b.Message))
+	if err != nil {
+		slog.WarnContext(ctx, "failed to render site banner", "error", err)
+		return nil
+	}
+
+	return html
+}
+
+// set replaces the cached banner. The caller must hold c.mu.
+func (c *bannerCache) set(html []byte, b *SiteBanner) {
+	c.banner = b
+	c.html = html
+	c.loadedAt = time.Now()
+}
@@ -0,0 +1,117 @@
+//go:build !compile
+
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetSiteBanner(t *testing.T) {
+	svc, store, _, processor := newTestService(t)
+
+	var saved *SiteBanner
+
+	store.EXPECT().SaveSiteBanner(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, b *SiteBanner) error {
+		saved = b
+		return nil
+	})
+	processor.EXPECT().RenderHTML([]byte("Maintenance **tonight**")).Return([]byte("<p>Maintenance <strong>tonight</strong></p>"), nil, nil).Once()
+
+	b, err := svc.SetSiteBanner(t.Context(), SiteBanner{Message: "  Maintenance **tonight**  "})
+	require.NoError(t, err)
+	assert.Equal(t, "Maintenance **tonight**", b.Message)
+	assert.Equal(t, BannerInfo, b.Severity, "severity defaults to info")
+	assert.False(t, b.UpdatedAt.IsZero())
+	assert.Equal(t, b, saved)
+
+	page := svc.ActiveSiteBanner(t.Context())
+	require.NotNil(t, page)
+	assert.Equal(t, BannerInfo, page.Severity)
+	assert.Equal(t, "<p>Maintenance <strong>tonight</strong></p>", string(page.HTML))
+
+	require.NoError(t, svc.ClearSiteBanner(t.Context()))
+	assert.Nil(t, saved)
+	assert.Nil(t, svc.ActiveSiteBanner(t.Context()))
+}
+
+func TestSetSiteBanner_Invalid(t *testing.T) {
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		banner SiteBanner
+	}{
+		{name: "empty message", banner: SiteBanner{Message: "  "}},
+		{name: "long message", banner: SiteBanner{Message: string(make([]rune, maxAnnouncementLen+1))}},
+		{name: "unknown severity", banner: SiteBanner{Message: "hi", Severity: "urgent"}},
+		{name: "ends before start", banner: SiteBanner{Message: "hi", StartsAt: start, EndsAt: start.Add(-time.Hour)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _, _, _ := newTestService(t)
+
+			_, err := svc.SetSiteBanner(t.Context(), tt.banner)
+			assert.ErrorIs(t, err, ErrInvalidPath)
+		})
+	}
+}
+
+func TestActiveSiteBanner_Window(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		banner *SiteBanner
+		name   string
+		active bool
+	}{
+		{name: "none", banner: nil},
+		{name: "open window", banner: &SiteBanner{Message: "hi", Severity: BannerWarning}, active: true},
+		{name: "started", banner: &SiteBanner{Message: "hi", Severity: BannerWarning, StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour)}, active: true},
+		{name: "scheduled", banner: &SiteBanner{Message: "hi", Severity: BannerWarning, StartsAt: now.Add(time.Hour)}},
+		{name: "ended", banner: &SiteBanner{Message: "hi", Severity: BannerWarning, EndsAt: now.Add(-time.Minute)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, store, _, processor := newTestService(t)
+
+			store.EXPECT().GetSiteBanner(mock.Anything).Return(tt.banner, nil).Once()
+			processor.EXPECT().RenderHTML([]byte("hi")).Return([]byte("<p>hi</p>"), nil, nil).Maybe()
+
+			page := svc.ActiveSiteBanner(t.Context())
+			if !tt.active {
+				assert.Nil(t, page)
+				return
+			}
+
+			require.NotNil(t, page)
+			assert.Equal(t, BannerWarning, page.Severity)
+		})
+	}
+}
+
+func TestActiveSiteBanner_LoadError(t *testing.T) {
+	svc, store, _, _ := newTestService(t)
+
+	store.EXPECT().GetSiteBanner(mock.Anything).Return(nil, errors.New("bucket unavailable")).Once()
+
+	assert.Nil(t, svc.ActiveSiteBanner(t.Context()))
+	assert.Nil(t, svc.ActiveSiteBanner(t.Context()), "a failed load is not retried before the cache expires")
+}
+
+func TestGetSiteBanner_NotSet(t *testing.T) {
+	svc, store, _, _ := newTestService(t)
+
+	store.EXPECT().GetSiteBanner(mock.Anything).Return(nil, nil).Once()
+
+	_, err := svc.GetSiteBanner(t.Context())
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,46 @@
+package core
+
+import (
+	"encoding/base64"
+	"fmt"
+)
+
+// decodeRequestContent decodes the content of the documents of req sent base64-encoded,
+// so that binary documents are processed and stored like text ones from then on. It
+// returns an error wrapping ErrInvalidPath for an unknown encoding or content that is not
+// valid base64.
+func decodeRequestContent(req *IngestRequest) error {
+	for i := range req.Documents {
+		doc := &req.Documents[i]
+
+		switch doc.Encoding {
+		case "":
+			continue
+		case ContentEncodingBase64:
+		default:
+			return fmt.Errorf("%w: document %s: unknown content encoding %q", ErrInvalidPath, doc.Path, doc.Encoding)
+		}
+
+		data, err := base64.StdEncoding.DecodeString(doc.Content)
+		if err != nil {
+			return fmt.Errorf("%w: document %s: invalid base64 content: %w", ErrInvalidPath, doc.Path, err)
+		}
+
+		doc.Content = string(data)
+		doc.Encoding = ""
+	}
+
+	return nil
+}
+
+// binaryContent reports whether documents of the content type hold binary content, which
+// content policies do not apply to.
+func (s *Service) binaryContent(ct ContentType) bool {
+	for _, t := range s.contentTypes {
+		if t.Name == ct {
+			return t.Binary
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,86 @@
+//go:build !compile
+
+package core
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// binaryProcessor is a content processor of a binary content type.
+type binaryProcessor struct {
+	*MockContentProcessor
+}
+
+func (binaryProcessor) ContentTypeInfo() ContentTypeInfo {
+	return ContentTypeInfo{Name: ContentTypePDF, Extensions: []string{".pdf"}, Binary: true, MediaType: "application/pdf"}
+}
+
+func TestDecodeRequestContent(t *testing.T) {
+	binary := "%PDF-1.7\n\xff\x00secret"
+
+	req := &IngestRequest{Documents: []IngestDocument{
+		{Path: "guide.md", Content: "# Guide", Action: "upsert"},
+		{Path: "design.pdf", Content: base64.StdEncoding.EncodeToString([]byte(binary)), Action: "upsert", Encoding: ContentEncodingBase64},
+	}}
+
+	require.NoError(t, decodeRequestContent(req))
+	assert.Equal(t, "# Guide", req.Documents[0].Content)
+	assert.Equal(t, binary, req.Documents[1].Content)
+	assert.Empty(t, req.Documents[1].Encoding)
+
+	for _, doc := range []IngestDocument{
+		{Path: "design.pdf", Content: "not base64!", Action: "upsert", Encoding: ContentEncodingBase64},
+		{Path: "design.pdf", Content: "JVBERg==", Action: "upsert", Encoding: "gzip"},
+	} {
+		err := decodeRequestContent(&IngestRequest{Documents: []IngestDocument{doc}})
+		assert.ErrorIs(t, err, ErrInvalidPath, "encoding %q", doc.Encoding)
+	}
+}
+
+func TestIngestDocuments_BinaryDocument(t *testing.T) {
+	store := NewMockdocStore(t)
+	search := NewMocksearchEngine(t)
+	pdf := binaryProcessor{NewMockContentProcessor(t)}
+	svc := New(store, search, map[ContentType]ContentProcessor{
+		ContentTypeMarkdown: NewMockContentProcessor(t),
+		ContentTypePDF:      pdf,
+	}, WithContentPolicy(secretPolicy{}))
+
+	// Content policies do not apply to binary content, which they would corrupt.
+	content := []byte("%PDF-1.7\n\xff\x00secret")
+
+	pdf.EXPECT().ExtractTitle(content).Return("Design")
+	pdf.EXPECT().ToPlainText(content).Return("Design secret")
+	pdf.EXPECT().ExtractCodeBlocks(content).Return(nil)
+	pdf.EXPECT().ExtractHeadings(content).Return(nil)
+	store.EXPECT().Save(mock.Anything, mock.MatchedBy(func(doc Document) bool {
+		return doc.Content == string(content) && doc.ContentType == ContentTypePDF
+	})).Return(nil)
+	search.EXPECT().Index(mock.Anything, mock.Anything, "Design secret", []CodeBlock(nil), []Heading(nil)).Return(nil)
+
+	resp, err := svc.IngestDocuments(t.Context(), &IngestRequest{
+		Repo: "owner/repo",
+		Documents: []IngestDocument{{
+			Path:        "design.pdf",
+			Content:     base64.StdEncoding.EncodeToString(content),
+			Action:      "upsert",
+			ContentType: ContentTypePDF,
+			Encoding:    ContentEncodingBase64,
+		}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, resp.Indexed)
+	assert.Empty(t, resp.Policy)
+
+	store.EXPECT().Get(mock.Anything, "owner/repo", "design.pdf").
+		Return(Document{Content: string(content), ContentType: ContentTypePDF}, nil)
+
+	doc, err := svc.GetDocumentSource(t.Context(), "owner/repo", "design.pdf")
+	require.NoError(t, err)
+	assert.Equal(t, string(content), doc.Content)
+}
@@ -0,0 +1,122 @@
+package core
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// CitationEntry is the audit record of a published document: what was published, from
+// which commit and when, with hashes of the source and of the rendering readers see.
+type CitationEntry struct {
+	PublishedAt   time.Time   `json:"published_at"`
+	Provenance    *Provenance `json:"provenance,omitempty"`
+	Repo          string      `json:"repo"`
+	Path          string      `json:"path"`
+	Title         string      `json:"title,omitempty"`
+	CommitSHA     string      `json:"commit_sha,omitempty"`
+	ContentType   ContentType `json:"content_type"`
+	ContentSHA256 string      `json:"content_sha256"` // hex-encoded SHA-256 of the content served at /raw
+	HTMLSHA256    string      `json:"html_sha256"`    // hex-encoded SHA-256 of the rendering served at /html
+	Size          int         `json:"size"`
+}
+
+// CitationManifest lists the audit records of the published documents of a repository, or
+// of every repository when Repo is empty, as of GeneratedAt.
+type CitationManifest struct {
+	GeneratedAt time.Time       `json:"generated_at"`
+	Repo        string          `json:"repo,omitempty"`
+	Documents   []CitationEntry `json:"documents"`
+}
+
+// CitationManifest returns the audit records of the documents of repo, or of every
+// repository when repo is empty, ordered by repository and path. Every document is read
+// and rendered, so the manifest reflects exactly what the portal serves. It returns
+// ErrInvalidPath if repo is malformed and ErrNotFound if it has no documents.
+func (s *Service) CitationManifest(ctx context.Context, repo string) (*CitationManifest, error) {
+	repos := []string{repo}
+
+	if repo == "" {
+		infos, err := s.store.ListRepos(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list repos: %w", err)
+		}
+
+		repos = repos[:0]
+		for _, info := range infos {
+			repos = append(repos, info.Name)
+		}
+	} else if err := validateRepoName(repo); err != nil {
+		return nil, err
+	}
+
+	manifest := &CitationManifest{
+		GeneratedAt: time.Now().UTC(),
+		Repo:        repo,
+		Documents:   make([]CitationEntry, 0),
+	}
+
+	for _, name := range repos {
+		entries, err := s.citeRepo(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		manifest.Documents = append(manifest.Documents, entries...)
+	}
+
+	if repo != "" && len(manifest.Documents) == 0 {
+		return nil, fmt.Errorf("%w: no documents in repository %s", ErrNotFound, repo)
+	}
+
+	slices.SortFunc(manifest.Documents, func(a, b CitationEntry) int {
+		return cmp.Or(cmp.Compare(a.Repo, b.Repo), cmp.Compare(a.Path, b.Path))
+	})
+
+	return manifest, nil
+}
+
+// citeRepo returns the audit records of the documents of a repository. Documents deleted
+// while the records are built are left out.
+func (s *Service) citeRepo(ctx context.Context, repo string) ([]CitationEntry, error) {
+	metas, err := s.store.List(ctx, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents of %s: %w", repo, err)
+	}
+
+	entries := make([]CitationEntry, 0, len(metas))
+
+	for _, meta := range metas {
+		doc, html, _, err := s.GetDocument(ctx, repo, meta.Path)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to cite %s/%s: %w", repo, meta.Path, err)
+		}
+
+		checksum := doc.Checksum()
+		htmlSum := sha256.Sum256(html)
+
+		entries = append(entries, CitationEntry{
+			Repo:          repo,
+			Path:          doc.Path,
+			Title:         doc.Title,
+			CommitSHA:     doc.CommitSHA,
+			Provenance:    doc.Provenance,
+			PublishedAt:   doc.UpdatedAt,
+			ContentType:   doc.ContentType,
+			ContentSHA256: checksum.SHA256,
+			HTMLSHA256:    hex.EncodeToString(htmlSum[:]),
+			Size:          checksum.Size,
+		})
+	}
+
+	return entries, nil
+}
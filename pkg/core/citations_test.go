@@ -0,0 +1,71 @@
+//go:build !compile
+
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCitationManifest(t *testing.T) {
+	svc, store, _, processor := newTestService(t)
+
+	published := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	store.EXPECT().ListRepos(mock.Anything).Return([]RepoInfo{{Name: "owner/web"}, {Name: "owner/api"}}, nil)
+	store.EXPECT().List(mock.Anything, "owner/api").Return([]DocumentMeta{{Path: "b.md"}, {Path: "a.md"}, {Path: "gone.md"}}, nil)
+	store.EXPECT().List(mock.Anything, "owner/web").Return([]DocumentMeta{{Path: "index.md"}}, nil)
+
+	for _, d := range []Document{
+		{Repo: "owner/api", Path: "a.md", Content: "# A", CommitSHA: "abc", UpdatedAt: published},
+		{Repo: "owner/api", Path: "b.md", Content: "# B"},
+		{Repo: "owner/web", Path: "index.md", Content: "# Web"},
+	} {
+		store.EXPECT().Get(mock.Anything, d.Repo, d.Path).Return(d, nil)
+	}
+
+	store.EXPECT().Get(mock.Anything, "owner/api", "gone.md").Return(Document{}, ErrNotFound)
+	processor.EXPECT().RenderHTML(mock.Anything).RunAndReturn(func(src []byte) ([]byte, []Heading, error) {
+		return append([]byte("<h1>"), src[2:]...), nil, nil
+	})
+
+	manifest, err := svc.CitationManifest(t.Context(), "")
+	require.NoError(t, err)
+
+	require.Len(t, manifest.Documents, 3, "deleted documents are left out")
+	assert.Empty(t, manifest.Repo)
+	assert.False(t, manifest.GeneratedAt.IsZero())
+
+	contentSum := sha256.Sum256([]byte("# A"))
+	htmlSum := sha256.Sum256([]byte("<h1>A"))
+
+	assert.Equal(t, CitationEntry{
+		Repo:          "owner/api",
+		Path:          "a.md",
+		CommitSHA:     "abc",
+		PublishedAt:   published,
+		ContentSHA256: hex.EncodeToString(contentSum[:]),
+		HTMLSHA256:    hex.EncodeToString(htmlSum[:]),
+		Size:          3,
+	}, manifest.Documents[0])
+	assert.Equal(t, "owner/api/b.md", manifest.Documents[1].Repo+"/"+manifest.Documents[1].Path)
+	assert.Equal(t, "owner/web/index.md", manifest.Documents[2].Repo+"/"+manifest.Documents[2].Path)
+}
+
+func TestCitationManifest_Repo(t *testing.T) {
+	svc, store, _, _ := newTestService(t)
+
+	store.EXPECT().List(mock.Anything, "owner/empty").Return(nil, nil)
+
+	_, err := svc.CitationManifest(t.Context(), "owner/empty")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	_, err = svc.CitationManifest(t.Context(), "../etc")
+	require.ErrorIs(t, err, ErrInvalidPath)
+}
@@ -19,6 +19,54 @@ func (_m *MockContentProcessor) EXPECT() *MockContentProcessor_Expecter {
 	return &MockContentProcessor_Expecter{mock: &_m.Mock}
 }
 
+// ExtractCodeBlocks provides a mock function with given fields: src
+func (_m *MockContentProcessor) ExtractCodeBlocks(src []byte) []CodeBlock {
+	ret := _m.Called(src)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExtractCodeBlocks")
+	}
+
+	var r0 []CodeBlock
+	if rf, ok := ret.Get(0).(func([]byte) []CodeBlock); ok {
+		r0 = rf(src)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]CodeBlock)
+		}
+	}
+
+	return r0
+}
+
+// MockContentProcessor_ExtractCodeBlocks_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExtractCodeBlocks'
+type MockContentProcessor_ExtractCodeBlocks_Call struct {
+	*mock.Call
+}
+
+// ExtractCodeBlocks is a helper method to define mock.On call
+//   - src []byte
+func (_e *MockContentProcessor_Expecter) ExtractCodeBlocks(src interface{}) *MockContentProcessor_ExtractCodeBlocks_Call {
+	return &MockContentProcessor_ExtractCodeBlocks_Call{Call: _e.mock.On("ExtractCodeBlocks", src)}
+}
+
+func (_c *MockContentProcessor_ExtractCodeBlocks_Call) Run(run func(src []byte)) *MockContentProcessor_ExtractCodeBlocks_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].([]byte))
+	})
+	return _c
+}
+
+func (_c *MockContentProcessor_ExtractCodeBlocks_Call) Return(_a0 []CodeBlock) *MockContentProcessor_ExtractCodeBlocks_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockContentProcessor_ExtractCodeBlocks_Call) RunAndReturn(run func([]byte) []CodeBlock) *MockContentProcessor_ExtractCodeBlocks_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // ExtractHeadings provides a mock function with given fields: src
 func (_m *MockContentProcessor) ExtractHeadings(src []byte) []Heading {
 	ret := _m.Called(src)
@@ -3,85 +3,241 @@ package core
 import (
 	"encoding/json"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
-// openAPIExtensions lists file extensions commonly used for OpenAPI specs.
-var openAPIExtensions = map[string]bool{
-	".yaml": true,
-	".yml":  true,
-	".json": true,
+// ContentTypeInfo describes a content type the server can process: how readers see it and
+// which files publishers upload as documents of that type.
+type ContentTypeInfo struct {
+	Name        ContentType `json:"name"`
+	DisplayName string      `json:"display_name"`
+	Icon        string      `json:"icon,omitempty"` // name of the icon shown next to documents of the type
+	// MediaType is the media type the source of the type's documents is served with, or
+	// empty for text.
+	MediaType string `json:"media_type,omitempty"`
+	// Extensions are the lowercase file extensions, with the leading dot, of the type's files.
+	Extensions []string `json:"extensions"`
+	// Markers are top-level YAML or JSON keys that identify the type's files among others
+	// sharing their extensions, such as "openapi" for OpenAPI specs. Types without markers
+	// claim every file with their extensions that no type with markers matched.
+	Markers []string `json:"markers,omitempty"`
+	// Suffixes are lowercase file name endings, such as ".schema.json", that identify the
+	// type's files whatever their content.
+	Suffixes []string `json:"suffixes,omitempty"`
+	// GenericMarkers marks types whose markers the files of other types may carry too,
+	// such as the "$schema" key editors use to validate OpenAPI specs. Their markers are
+	// tried after those of the other types.
+	GenericMarkers bool `json:"generic_markers,omitempty"`
+	// Fallback marks the type of files whose extension no type lists.
+	Fallback bool `json:"fallback,omitempty"`
+	// Binary marks types whose files are not text. Publishers send their content
+	// base64-encoded, and the server serves it as is rather than through content policies.
+	Binary bool `json:"binary,omitempty"`
 }
 
-// DetectContentType determines the content type of a document based on its
-// file path and content. It uses file extension as a fast pre-filter and then
-// inspects the content for OpenAPI-specific markers (the "openapi" or "swagger"
-// top-level keys). Files with non-YAML/JSON extensions are treated as markdown.
-// YAML/JSON files that do not match OpenAPI heuristics return an empty ContentType
-// to signal that they should be skipped (not treated as documentation).
+// ContentTypeDescriber is optionally implemented by a ContentProcessor to describe the
+// content type it processes. Processors that do not implement it are listed under the
+// content type they are registered for, without extensions.
+type ContentTypeDescriber interface {
+	ContentTypeInfo() ContentTypeInfo
+}
+
+// DefaultContentTypes returns the content types of a server that does not list its own:
+// markdown, OpenAPI and AsyncAPI. Publishers use them against servers that predate
+// GET /api/v1/content-types.
+func DefaultContentTypes() []ContentTypeInfo {
+	specExt := []string{".yaml", ".yml", ".json"}
+
+	return []ContentTypeInfo{
+		{Name: ContentTypeMarkdown, DisplayName: "Markdown", Icon: "document", Extensions: []string{".md", ".markdown"}, Fallback: true},
+		{Name: ContentTypeAsyncAPI, DisplayName: "AsyncAPI", Icon: "events", Extensions: specExt, Markers: []string{"asyncapi"}},
+		{Name: ContentTypeOpenAPI, DisplayName: "OpenAPI", Icon: "api", Extensions: specExt, Markers: []string{"openapi", "swagger"}},
+	}
+}
+
+// ContentTypes returns the content types the service processes, markdown first and the
+// others ordered by name.
+func (s *Service) ContentTypes() []ContentTypeInfo {
+	return slices.Clone(s.contentTypes)
+}
+
+// describeContentTypes returns the content types of the registered processors, markdown
+// first and the others ordered by name.
+func describeContentTypes(processors map[ContentType]ContentProcessor) []ContentTypeInfo {
+	types := make([]ContentTypeInfo, 0, len(processors))
+
+	for ct, p := range processors {
+		var info ContentTypeInfo
+		if d, ok := p.(ContentTypeDescriber); ok {
+			info = d.ContentTypeInfo()
+		}
+
+		info.Name = ct
+
+		if info.DisplayName == "" {
+			info.DisplayName = string(ct)
+		}
+
+		types = append(types, info)
+	}
+
+	slices.SortFunc(types, func(a, b ContentTypeInfo) int {
+		switch {
+		case a.Name == b.Name:
+			return 0
+		case a.Name == ContentTypeMarkdown:
+			return -1
+		case b.Name == ContentTypeMarkdown:
+			return 1
+		default:
+			return strings.Compare(string(a.Name), string(b.Name))
+		}
+	})
+
+	return types
+}
+
+// DetectContentType determines the content type of a document among the default content
+// types based on its file path and content. See DetectContentTypeIn.
 func DetectContentType(path string, content []byte) ContentType {
-	ext := strings.ToLower(filepath.Ext(path))
+	return DetectContentTypeIn(DefaultContentTypes(), path, content)
+}
+
+// DetectContentTypeIn determines the content type of a document among types based on its
+// file path and content. Files whose name ends with one of a type's suffixes are of that
+// type. Otherwise the file extension is used as a fast pre-filter, then the content of
+// YAML and JSON files is inspected for the markers of the types listing the extension,
+// such as the "openapi" top-level key of OpenAPI specs. Files whose extension no type
+// lists get the fallback type. Files whose extension is listed only by types with
+// markers that the content does not match return an empty ContentType to signal that
+// they should be skipped (not treated as documentation).
+func DetectContentTypeIn(types []ContentTypeInfo, path string, content []byte) ContentType {
+	name := strings.ToLower(filepath.Base(path))
+
+	for _, t := range types {
+		if slices.ContainsFunc(t.Suffixes, func(s string) bool { return strings.HasSuffix(name, s) }) {
+			return t.Name
+		}
+	}
+
+	ext := filepath.Ext(name)
+
+	var (
+		marked  []ContentTypeInfo
+		plain   ContentType
+		claimed bool
+	)
+
+	for _, t := range types {
+		if !slices.Contains(t.Extensions, ext) {
+			continue
+		}
+
+		claimed = true
+
+		switch {
+		case len(t.Markers) > 0:
+			marked = append(marked, t)
+		case plain == "":
+			plain = t.Name
+		}
+	}
+
+	if len(marked) > 0 {
+		slices.SortStableFunc(marked, func(a, b ContentTypeInfo) int {
+			switch {
+			case a.GenericMarkers == b.GenericMarkers:
+				return 0
+			case a.GenericMarkers:
+				return 1
+			default:
+				return -1
+			}
+		})
+
+		if ct := detectByMarkers(marked, content, ext); ct != "" {
+			return ct
+		}
+	}
 
-	// Only YAML/JSON files can be OpenAPI specs.
-	if !openAPIExtensions[ext] {
-		return ContentTypeMarkdown
+	if plain != "" || claimed {
+		return plain
 	}
 
-	if looksLikeOpenAPI(content, ext) {
-		return ContentTypeOpenAPI
+	for _, t := range types {
+		if t.Fallback {
+			return t.Name
+		}
 	}
 
-	// Arbitrary YAML/JSON files that are not OpenAPI specs should not be
-	// treated as documentation. Return empty to signal the caller to skip.
 	return ""
 }
 
-// looksLikeOpenAPI checks whether the content contains an "openapi" (OAS 3.x)
-// or "swagger" (OAS 2.0) top-level key. It supports both JSON and YAML formats.
-func looksLikeOpenAPI(content []byte, ext string) bool {
+// detectByMarkers returns the first of types whose markers are among the top-level keys
+// of the content, or an empty ContentType when none matches. It supports both JSON and
+// YAML formats.
+func detectByMarkers(types []ContentTypeInfo, content []byte, ext string) ContentType {
 	// For .json files, only attempt JSON-based detection.
 	if ext == ".json" {
-		return looksLikeOpenAPIJSON(content)
+		return matchMarkers(types, keysFromJSON(content))
 	}
 
 	// For YAML files, content may still start with '{' (YAML flow mapping).
 	// In that case, try JSON heuristics first, but fall back to YAML detection
-	// if JSON parsing does not detect an OpenAPI document.
+	// if JSON parsing does not match a type.
 	if len(content) > 0 && content[0] == '{' {
-		if looksLikeOpenAPIJSON(content) {
-			return true
+		if ct := matchMarkers(types, keysFromJSON(content)); ct != "" {
+			return ct
 		}
 	}
 
-	return looksLikeOpenAPIYAML(content)
+	return matchMarkers(types, keysFromYAML(content))
 }
 
-// looksLikeOpenAPIJSON performs a lightweight check for the "openapi" or "swagger" key in JSON content.
-func looksLikeOpenAPIJSON(content []byte) bool {
+// keysFromJSON returns the top-level keys of JSON content, or nil when it is not a JSON
+// object.
+func keysFromJSON(content []byte) map[string]bool {
 	var doc map[string]json.RawMessage
 
 	if err := json.Unmarshal(content, &doc); err != nil {
-		return false
+		return nil
 	}
 
-	_, hasOpenAPI := doc["openapi"]
-	_, hasSwagger := doc["swagger"]
+	keys := make(map[string]bool, len(doc))
+	for k := range doc {
+		keys[k] = true
+	}
 
-	return hasOpenAPI || hasSwagger
+	return keys
 }
 
-// looksLikeOpenAPIYAML performs a lightweight check for the "openapi" or "swagger" key in YAML content.
-func looksLikeOpenAPIYAML(content []byte) bool {
+// keysFromYAML returns the top-level keys of YAML content, or nil when it is not a YAML
+// mapping.
+func keysFromYAML(content []byte) map[string]bool {
 	var doc map[string]any
 
 	if err := yaml.Unmarshal(content, &doc); err != nil {
-		return false
+		return nil
 	}
 
-	_, hasOpenAPI := doc["openapi"]
-	_, hasSwagger := doc["swagger"]
+	keys := make(map[string]bool, len(doc))
+	for k := range doc {
+		keys[k] = true
+	}
 
-	return hasOpenAPI || hasSwagger
+	return keys
+}
+
+// matchMarkers returns the first of types with a marker among keys.
+func matchMarkers(types []ContentTypeInfo, keys map[string]bool) ContentType {
+	for _, t := range types {
+		if slices.ContainsFunc(t.Markers, func(m string) bool { return keys[m] }) {
+			return t.Name
+		}
+	}
+
+	return ""
 }
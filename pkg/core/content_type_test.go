@@ -126,6 +126,22 @@ info:
 			content:  `{name: my-app, version: "1.0.0"}`,
 			expected: "",
 		},
+		{
+			name: "AsyncAPI YAML spec",
+			path: "api/events.yaml",
+			content: `asyncapi: 3.0.0
+info:
+  title: Account Events
+  version: "1.0.0"
+channels: {}`,
+			expected: ContentTypeAsyncAPI,
+		},
+		{
+			name:     "AsyncAPI JSON spec",
+			path:     "api/events.json",
+			content:  `{"asyncapi": "2.6.0", "info": {"title": "Account Events", "version": "1.0.0"}, "channels": {}}`,
+			expected: ContentTypeAsyncAPI,
+		},
 	}
 
 	for _, tt := range tests {
@@ -135,3 +151,57 @@ info:
 		})
 	}
 }
+
+func TestDetectContentTypeIn(t *testing.T) {
+	types := append(DefaultContentTypes(),
+		ContentTypeInfo{Name: "graphql", Extensions: []string{".graphql", ".graphqls"}},
+		ContentTypeInfo{Name: "jsonschema", Extensions: []string{".json"}, Markers: []string{"$schema"}, GenericMarkers: true, Suffixes: []string{".schema.json"}},
+	)
+
+	tests := []struct {
+		name     string
+		path     string
+		content  string
+		expected ContentType
+	}{
+		{name: "extension of a type without markers", path: "api/schema.GRAPHQL", content: "type Query { ping: String }", expected: "graphql"},
+		{name: "marker of a registered type", path: "schemas/user.json", content: `{"$schema": "https://json-schema.org/draft/2020-12/schema"}`, expected: "jsonschema"},
+		{name: "built-in marker", path: "api/petstore.json", content: `{"openapi": "3.0.3"}`, expected: ContentTypeOpenAPI},
+		{name: "specific marker before generic one", path: "api/petstore.json", content: `{"$schema": "https://spec.openapis.org/oas/3.1/schema", "openapi": "3.1.0"}`, expected: ContentTypeOpenAPI},
+		{name: "listed extension without a matching marker", path: "package.json", content: `{"name": "app"}`, expected: ""},
+		{name: "suffix whatever the content", path: "schemas/Order.Schema.JSON", content: `{"type": "object"}`, expected: "jsonschema"},
+		{name: "unlisted extension falls back", path: "docs/notes.txt", content: "Notes", expected: ContentTypeMarkdown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, DetectContentTypeIn(types, tt.path, []byte(tt.content)))
+		})
+	}
+
+	assert.Empty(t, DetectContentTypeIn(types[1:], "docs/notes.txt", []byte("Notes")), "no fallback type")
+}
+
+// describedProcessor is a ContentProcessor describing its content type.
+type describedProcessor struct {
+	ContentProcessor
+	info ContentTypeInfo
+}
+
+func (p describedProcessor) ContentTypeInfo() ContentTypeInfo { return p.info }
+
+func TestDescribeContentTypes(t *testing.T) {
+	types := describeContentTypes(map[ContentType]ContentProcessor{
+		ContentTypeOpenAPI:  describedProcessor{info: ContentTypeInfo{Name: "ignored", DisplayName: "OpenAPI", Markers: []string{"openapi"}}},
+		"graphql":           describedProcessor{},
+		ContentTypeMarkdown: describedProcessor{info: ContentTypeInfo{DisplayName: "Markdown", Fallback: true}},
+		"asciidoc":          nil,
+	})
+
+	assert.Equal(t, []ContentTypeInfo{
+		{Name: ContentTypeMarkdown, DisplayName: "Markdown", Fallback: true},
+		{Name: "asciidoc", DisplayName: "asciidoc"},
+		{Name: "graphql", DisplayName: "graphql"},
+		{Name: ContentTypeOpenAPI, DisplayName: "OpenAPI", Markers: []string{"openapi"}},
+	}, types, "processors are listed under the content type they are registered for, markdown first")
+}
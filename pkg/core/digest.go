@@ -0,0 +1,357 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DigestPeriod is the period covered by a digest, which is sent weekly.
+const DigestPeriod = 7 * 24 * time.Hour
+
+// DigestConfig configures the weekly email digests of documentation changes.
+type DigestConfig struct {
+	PortalURL string       `mapstructure:"portal_url"` // Base URL of the portal, for links to the documents.
+	Weekday   string       `mapstructure:"weekday"`    // Day the digests are sent (default monday).
+	At        string       `mapstructure:"at"`         // UTC time of day the digests are sent, as HH:MM (default 09:00).
+	Teams     []DigestTeam `mapstructure:"teams"`
+}
+
+// DigestTeam is a team receiving a digest of the changes in the repositories it owns or
+// follows.
+type DigestTeam struct {
+	Name   string   `mapstructure:"name"`
+	Emails []string `mapstructure:"emails"`
+	Repos  []string `mapstructure:"repos"` // Owners, repositories or monorepo projects, e.g. acme or acme/api.
+}
+
+// Follows reports whether repo is one of the team's owners or repositories, or one of
+// their monorepo projects.
+func (t DigestTeam) Follows(repo string) bool {
+	for _, r := range t.Repos {
+		if repo == r || strings.HasPrefix(repo, r+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Enabled reports whether any team receives digests.
+func (c DigestConfig) Enabled() bool {
+	return len(c.Teams) > 0
+}
+
+// Schedule returns the weekday and the UTC time of day, as an offset from midnight, the
+// digests are sent.
+func (c DigestConfig) Schedule() (time.Weekday, time.Duration, error) {
+	day := time.Monday
+
+	if c.Weekday != "" {
+		found := false
+
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			if strings.EqualFold(c.Weekday, d.String()) {
+				day, found = d, true
+				break
+			}
+		}
+
+		if !found {
+			return 0, 0, fmt.Errorf("unknown digest weekday %q", c.Weekday)
+		}
+	}
+
+	at := "09:00"
+	if c.At != "" {
+		at = c.At
+	}
+
+	t, err := time.Parse("15:04", at)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid digest time %q: must be HH:MM", at)
+	}
+
+	return day, time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Validate checks the schedule and that every team has a name, recipients and repositories.
+func (c DigestConfig) Validate() error {
+	if _, _, err := c.Schedule(); err != nil {
+		return err
+	}
+
+	if c.Enabled() && c.PortalURL == "" {
+		return errors.New("portal_url is required for the links in digests")
+	}
+
+	for _, t := range c.Teams {
+		if t.Name == "" || len(t.Emails) == 0 || len(t.Repos) == 0 {
+			return fmt.Errorf("digest team %q must have a name, emails and repos", t.Name)
+		}
+	}
+
+	return nil
+}
+
+// Email is a plain-text email message.
+type Email struct {
+	Subject string
+	Body    string
+	To      []string
+}
+
+// Mailer sends emails.
+type Mailer interface {
+	Send(ctx context.Context, e Email) error
+}
+
+// DigestDocument is a changed document listed in a digest. Deleted documents have no URL.
+type DigestDocument struct {
+	Title string
+	Path  string
+	URL   string
+}
+
+// DigestRepo lists the changed documents of a repository.
+type DigestRepo struct {
+	Repo    string
+	Added   []DigestDocument
+	Updated []DigestDocument
+	Deleted []DigestDocument
+}
+
+// Digest summarizes the documents changed in the repositories of a team over a period.
+type Digest struct {
+	Since time.Time
+	Until time.Time
+	Team  string
+	Repos []DigestRepo
+}
+
+// digester holds the teams receiving digests and the mailer sending them.
+type digester struct {
+	mailer Mailer
+	cfg    DigestConfig
+}
+
+// WithDigests emails each configured team a digest of the documents changed in its
+// repositories, as recorded by the change journal, through the given mailer.
+func WithDigests(cfg DigestConfig, m Mailer) Option {
+	return func(s *Service) {
+		if !cfg.Enabled() {
+			return
+		}
+
+		cfg.PortalURL = strings.TrimSuffix(cfg.PortalURL, "/")
+		s.digests = &digester{cfg: cfg, mailer: m}
+	}
+}
+
+// BuildDigest returns the documents changed in the repositories of team between since and
+// until, as recorded by the change journal. A document changed several times is listed
+// once: as deleted if it was deleted last, as added if it was added in the period, and as
+// updated otherwise. Documents both added and deleted in the period are left out. It
+// returns an error wrapping ErrNotSupported if there is no change journal.
+func (s *Service) BuildDigest(ctx context.Context, team DigestTeam, since, until time.Time) (*Digest, error) {
+	if s.journal == nil {
+		return nil, fmt.Errorf("%w: change journal is not configured", ErrNotSupported)
+	}
+
+	changes, err := s.journal.Since(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read change journal: %w", err)
+	}
+
+	type docState struct {
+		last  string
+		added bool
+	}
+
+	byRepo := make(map[string]map[string]*docState)
+
+	for _, c := range changes {
+		if !c.Time.Before(until) || !team.Follows(c.Repo) {
+			continue
+		}
+
+		docs := byRepo[c.Repo]
+		if docs == nil {
+			docs = make(map[string]*docState)
+			byRepo[c.Repo] = docs
+		}
+
+		st := docs[c.Path]
+		if st == nil {
+			st = &docState{}
+			docs[c.Path] = st
+		}
+
+		st.added = st.added || c.Action == ChangeAdded
+		st.last = c.Action
+	}
+
+	digest := &Digest{Team: team.Name, Since: since, Until: until}
+
+	repos := make([]string, 0, len(byRepo))
+	for repo := range byRepo {
+		repos = append(repos, repo)
+	}
+
+	sort.Strings(repos)
+
+	baseURL := ""
+	if s.digests != nil {
+		baseURL = s.digests.cfg.PortalURL
+	}
+
+	for _, repo := range repos {
+		titles := s.documentTitles(ctx, repo)
+		r := DigestRepo{Repo: repo}
+
+		paths := make([]string, 0, len(byRepo[repo]))
+		for p := range byRepo[repo] {
+			paths = append(paths, p)
+		}
+
+		sort.Strings(paths)
+
+		for _, p := range paths {
+			st := byRepo[repo][p]
+
+			switch {
+			case st.last == ChangeDeleted && st.added:
+				continue
+			case st.last == ChangeDeleted:
+				r.Deleted = append(r.Deleted, DigestDocument{Title: p, Path: p})
+				continue
+			}
+
+			doc := DigestDocument{Title: titles[p], Path: p, URL: documentURL(baseURL, repo, p)}
+			if doc.Title == "" {
+				doc.Title = p
+			}
+
+			if st.added {
+				r.Added = append(r.Added, doc)
+			} else {
+				r.Updated = append(r.Updated, doc)
+			}
+		}
+
+		if len(r.Added)+len(r.Updated)+len(r.Deleted) > 0 {
+			digest.Repos = append(digest.Repos, r)
+		}
+	}
+
+	return digest, nil
+}
+
+// documentTitles returns the titles of the stored documents of repo by path. Titles that
+// cannot be listed are left out, and the documents are listed by path instead.
+func (s *Service) documentTitles(ctx context.Context, repo string) map[string]string {
+	docs, err := s.store.List(ctx, repo)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to list documents for digest", "repo", repo, "error", err)
+		return nil
+	}
+
+	titles := make(map[string]string, len(docs))
+	for _, d := range docs {
+		titles[d.Path] = d.Title
+	}
+
+	return titles
+}
+
+// documentURL returns the portal URL of a document.
+func documentURL(baseURL, repo, docPath string) string {
+	segments := strings.Split(docPath, "/")
+	for i, seg := range segments {
+		segments[i] = url.PathEscape(seg)
+	}
+
+	return baseURL + "/docs/" + repo + "/" + strings.Join(segments, "/")
+}
+
+// SendDigests emails each team a digest of the documents changed in its repositories
+// between since and until. Teams without changes are not emailed. Teams whose digest fails
+// are logged and skipped. It returns the number of digests sent, or an error wrapping
+// ErrNotSupported if digests or the change journal are not configured.
+func (s *Service) SendDigests(ctx context.Context, since, until time.Time) (int, error) {
+	if s.digests == nil || s.journal == nil {
+		return 0, fmt.Errorf("%w: digests are not configured", ErrNotSupported)
+	}
+
+	sent := 0
+
+	for _, team := range s.digests.cfg.Teams {
+		digest, err := s.BuildDigest(ctx, team, since, until)
+		if err != nil {
+			return sent, err
+		}
+
+		if len(digest.Repos) == 0 {
+			continue
+		}
+
+		if err := s.digests.mailer.Send(ctx, digestEmail(team, digest)); err != nil {
+			slog.WarnContext(ctx, "failed to send digest", "team", team.Name, "error", err)
+			continue
+		}
+
+		sent++
+	}
+
+	return sent, nil
+}
+
+// digestEmail formats a digest as a plain-text email to the team.
+func digestEmail(team DigestTeam, d *Digest) Email {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Documentation changes for %s from %s to %s.\n", d.Team, d.Since.Format(time.DateOnly), d.Until.Format(time.DateOnly))
+
+	count := 0
+
+	for _, r := range d.Repos {
+		fmt.Fprintf(&b, "\n%s\n", r.Repo)
+
+		for _, section := range []struct {
+			label string
+			docs  []DigestDocument
+		}{{"New", r.Added}, {"Updated", r.Updated}, {"Removed", r.Deleted}} {
+			if len(section.docs) == 0 {
+				continue
+			}
+
+			fmt.Fprintf(&b, "\n  %s:\n", section.label)
+
+			for _, doc := range section.docs {
+				if doc.URL != "" {
+					fmt.Fprintf(&b, "  - %s\n    %s\n", doc.Title, doc.URL)
+				} else {
+					fmt.Fprintf(&b, "  - %s\n", doc.Title)
+				}
+			}
+
+			count += len(section.docs)
+		}
+	}
+
+	noun := "documents"
+	if count == 1 {
+		noun = "document"
+	}
+
+	return Email{
+		To:      team.Emails,
+		Subject: fmt.Sprintf("Documentation digest for %s: %d changed %s", d.Team, count, noun),
+		Body:    b.String(),
+	}
+}
@@ -0,0 +1,154 @@
+//go:build !compile
+
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// mailFunc adapts a function to the Mailer interface.
+type mailFunc func(ctx context.Context, e Email) error
+
+func (f mailFunc) Send(ctx context.Context, e Email) error {
+	return f(ctx, e)
+}
+
+func TestDigestConfig_Schedule(t *testing.T) {
+	day, at, err := DigestConfig{}.Schedule()
+	require.NoError(t, err)
+	assert.Equal(t, time.Monday, day)
+	assert.Equal(t, 9*time.Hour, at)
+
+	day, at, err = DigestConfig{Weekday: "Friday", At: "16:30"}.Schedule()
+	require.NoError(t, err)
+	assert.Equal(t, time.Friday, day)
+	assert.Equal(t, 16*time.Hour+30*time.Minute, at)
+
+	_, _, err = DigestConfig{Weekday: "someday"}.Schedule()
+	assert.Error(t, err)
+
+	_, _, err = DigestConfig{At: "9am"}.Schedule()
+	assert.Error(t, err)
+}
+
+func TestDigestConfig_Validate(t *testing.T) {
+	team := DigestTeam{Name: "platform", Emails: []string{"platform@example.com"}, Repos: []string{"acme"}}
+
+	assert.NoError(t, DigestConfig{}.Validate())
+	assert.NoError(t, DigestConfig{PortalURL: "https://docs.example.com", Teams: []DigestTeam{team}}.Validate())
+	assert.ErrorContains(t, DigestConfig{Teams: []DigestTeam{team}}.Validate(), "portal_url")
+	assert.Error(t, DigestConfig{PortalURL: "https://docs.example.com", Teams: []DigestTeam{{Name: "empty"}}}.Validate())
+}
+
+func TestDigestTeam_Follows(t *testing.T) {
+	team := DigestTeam{Repos: []string{"acme", "other/api"}}
+
+	assert.True(t, team.Follows("acme/api"))
+	assert.True(t, team.Follows("acme/mono/payments"))
+	assert.True(t, team.Follows("other/api"))
+	assert.False(t, team.Follows("other/web"))
+	assert.False(t, team.Follows("acmecorp/api"))
+}
+
+func TestBuildDigest(t *testing.T) {
+	svc, store, _, _ := newTestService(t)
+
+	since := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	until := since.Add(DigestPeriod)
+	at := since.Add(time.Hour)
+
+	journal := &memJournal{changes: []Change{
+		{Time: since.Add(-time.Hour), Repo: "acme/api", Path: "before.md", Action: ChangeUpdated},
+		{Time: at, Repo: "acme/api", Path: "guide.md", Action: ChangeAdded},
+		{Time: at, Repo: "acme/api", Path: "guide.md", Action: ChangeUpdated},
+		{Time: at, Repo: "acme/api", Path: "ref/a b.md", Action: ChangeUpdated},
+		{Time: at, Repo: "acme/api", Path: "old.md", Action: ChangeDeleted},
+		{Time: at, Repo: "acme/api", Path: "draft.md", Action: ChangeAdded},
+		{Time: at, Repo: "acme/api", Path: "draft.md", Action: ChangeDeleted},
+		{Time: at, Repo: "other/web", Path: "index.md", Action: ChangeUpdated},
+		{Time: until, Repo: "acme/api", Path: "after.md", Action: ChangeUpdated},
+	}}
+	WithChangeJournal(journal)(svc)
+	WithDigests(DigestConfig{PortalURL: "https://docs.example.com/", Teams: []DigestTeam{{Name: "api"}}}, nil)(svc)
+
+	store.EXPECT().List(mock.Anything, "acme/api").Return([]DocumentMeta{{Path: "guide.md", Title: "Guide"}, {Path: "ref/a b.md"}}, nil)
+
+	digest, err := svc.BuildDigest(t.Context(), DigestTeam{Name: "api", Repos: []string{"acme"}}, since, until)
+	require.NoError(t, err)
+
+	assert.Equal(t, &Digest{
+		Team:  "api",
+		Since: since,
+		Until: until,
+		Repos: []DigestRepo{{
+			Repo:    "acme/api",
+			Added:   []DigestDocument{{Title: "Guide", Path: "guide.md", URL: "https://docs.example.com/docs/acme/api/guide.md"}},
+			Updated: []DigestDocument{{Title: "ref/a b.md", Path: "ref/a b.md", URL: "https://docs.example.com/docs/acme/api/ref/a%20b.md"}},
+			Deleted: []DigestDocument{{Title: "old.md", Path: "old.md"}},
+		}},
+	}, digest)
+}
+
+func TestBuildDigest_NoJournal(t *testing.T) {
+	svc := newTestServiceOnly(t)
+
+	_, err := svc.BuildDigest(t.Context(), DigestTeam{}, time.Time{}, time.Now())
+	assert.ErrorIs(t, err, ErrNotSupported)
+}
+
+func TestSendDigests(t *testing.T) {
+	svc, store, _, _ := newTestService(t)
+
+	since := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	until := since.Add(DigestPeriod)
+
+	var sent []Email
+
+	WithChangeJournal(&memJournal{changes: []Change{
+		{Time: since, Repo: "acme/api", Path: "guide.md", Action: ChangeUpdated},
+	}})(svc)
+	WithDigests(DigestConfig{
+		PortalURL: "https://docs.example.com",
+		Teams: []DigestTeam{
+			{Name: "api", Emails: []string{"api@example.com"}, Repos: []string{"acme/api"}},
+			{Name: "web", Emails: []string{"web@example.com"}, Repos: []string{"acme/web"}},
+			{Name: "broken", Emails: []string{"broken@example.com"}, Repos: []string{"acme"}},
+		},
+	}, mailFunc(func(_ context.Context, e Email) error {
+		if e.To[0] == "broken@example.com" {
+			return errors.New("mailbox unavailable")
+		}
+
+		sent = append(sent, e)
+
+		return nil
+	}))(svc)
+
+	store.EXPECT().List(mock.Anything, "acme/api").Return([]DocumentMeta{{Path: "guide.md", Title: "Guide"}}, nil)
+
+	n, err := svc.SendDigests(t.Context(), since, until)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n, "teams without changes are not emailed and failures are skipped")
+
+	require.Len(t, sent, 1)
+	assert.Equal(t, []string{"api@example.com"}, sent[0].To)
+	assert.Equal(t, "Documentation digest for api: 1 changed document", sent[0].Subject)
+	assert.Equal(t, "Documentation changes for api from 2026-01-05 to 2026-01-12.\n"+
+		"\nacme/api\n"+
+		"\n  Updated:\n"+
+		"  - Guide\n    https://docs.example.com/docs/acme/api/guide.md\n", sent[0].Body)
+}
+
+func TestSendDigests_NotConfigured(t *testing.T) {
+	svc := newTestServiceOnly(t)
+
+	_, err := svc.SendDigests(t.Context(), time.Time{}, time.Now())
+	assert.ErrorIs(t, err, ErrNotSupported)
+}
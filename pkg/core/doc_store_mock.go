@@ -119,6 +119,53 @@ func (_c *MockdocStore_DeleteAsset_Call) RunAndReturn(run func(context.Context,
 	return _c
 }
 
+// DeleteRepo provides a mock function with given fields: ctx, repo
+func (_m *MockdocStore) DeleteRepo(ctx context.Context, repo string) error {
+	ret := _m.Called(ctx, repo)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteRepo")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, repo)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockdocStore_DeleteRepo_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteRepo'
+type MockdocStore_DeleteRepo_Call struct {
+	*mock.Call
+}
+
+// DeleteRepo is a helper method to define mock.On call
+//   - ctx context.Context
+//   - repo string
+func (_e *MockdocStore_Expecter) DeleteRepo(ctx interface{}, repo interface{}) *MockdocStore_DeleteRepo_Call {
+	return &MockdocStore_DeleteRepo_Call{Call: _e.mock.On("DeleteRepo", ctx, repo)}
+}
+
+func (_c *MockdocStore_DeleteRepo_Call) Run(run func(ctx context.Context, repo string)) *MockdocStore_DeleteRepo_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockdocStore_DeleteRepo_Call) Return(_a0 error) *MockdocStore_DeleteRepo_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockdocStore_DeleteRepo_Call) RunAndReturn(run func(context.Context, string) error) *MockdocStore_DeleteRepo_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Get provides a mock function with given fields: ctx, repo, path
 func (_m *MockdocStore) Get(ctx context.Context, repo string, path string) (Document, error) {
 	ret := _m.Called(ctx, repo, path)
@@ -177,6 +224,64 @@ func (_c *MockdocStore_Get_Call) RunAndReturn(run func(context.Context, string,
 	return _c
 }
 
+// GetAPIKeys provides a mock function with given fields: ctx
+func (_m *MockdocStore) GetAPIKeys(ctx context.Context) ([]APIKey, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAPIKeys")
+	}
+
+	var r0 []APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]APIKey, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []APIKey); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockdocStore_GetAPIKeys_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAPIKeys'
+type MockdocStore_GetAPIKeys_Call struct {
+	*mock.Call
+}
+
+// GetAPIKeys is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockdocStore_Expecter) GetAPIKeys(ctx interface{}) *MockdocStore_GetAPIKeys_Call {
+	return &MockdocStore_GetAPIKeys_Call{Call: _e.mock.On("GetAPIKeys", ctx)}
+}
+
+func (_c *MockdocStore_GetAPIKeys_Call) Run(run func(ctx context.Context)) *MockdocStore_GetAPIKeys_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockdocStore_GetAPIKeys_Call) Return(_a0 []APIKey, _a1 error) *MockdocStore_GetAPIKeys_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockdocStore_GetAPIKeys_Call) RunAndReturn(run func(context.Context) ([]APIKey, error)) *MockdocStore_GetAPIKeys_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetAsset provides a mock function with given fields: ctx, repo, path
 func (_m *MockdocStore) GetAsset(ctx context.Context, repo string, path string) ([]byte, error) {
 	ret := _m.Called(ctx, repo, path)
@@ -237,6 +342,180 @@ func (_c *MockdocStore_GetAsset_Call) RunAndReturn(run func(context.Context, str
 	return _c
 }
 
+// GetHighlights provides a mock function with given fields: ctx
+func (_m *MockdocStore) GetHighlights(ctx context.Context) (map[string]RepoHighlights, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetHighlights")
+	}
+
+	var r0 map[string]RepoHighlights
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (map[string]RepoHighlights, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) map[string]RepoHighlights); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]RepoHighlights)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockdocStore_GetHighlights_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetHighlights'
+type MockdocStore_GetHighlights_Call struct {
+	*mock.Call
+}
+
+// GetHighlights is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockdocStore_Expecter) GetHighlights(ctx interface{}) *MockdocStore_GetHighlights_Call {
+	return &MockdocStore_GetHighlights_Call{Call: _e.mock.On("GetHighlights", ctx)}
+}
+
+func (_c *MockdocStore_GetHighlights_Call) Run(run func(ctx context.Context)) *MockdocStore_GetHighlights_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockdocStore_GetHighlights_Call) Return(_a0 map[string]RepoHighlights, _a1 error) *MockdocStore_GetHighlights_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockdocStore_GetHighlights_Call) RunAndReturn(run func(context.Context) (map[string]RepoHighlights, error)) *MockdocStore_GetHighlights_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetRedirects provides a mock function with given fields: ctx
+func (_m *MockdocStore) GetRedirects(ctx context.Context) (map[string]string, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRedirects")
+	}
+
+	var r0 map[string]string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (map[string]string, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) map[string]string); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockdocStore_GetRedirects_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetRedirects'
+type MockdocStore_GetRedirects_Call struct {
+	*mock.Call
+}
+
+// GetRedirects is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockdocStore_Expecter) GetRedirects(ctx interface{}) *MockdocStore_GetRedirects_Call {
+	return &MockdocStore_GetRedirects_Call{Call: _e.mock.On("GetRedirects", ctx)}
+}
+
+func (_c *MockdocStore_GetRedirects_Call) Run(run func(ctx context.Context)) *MockdocStore_GetRedirects_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockdocStore_GetRedirects_Call) Return(_a0 map[string]string, _a1 error) *MockdocStore_GetRedirects_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockdocStore_GetRedirects_Call) RunAndReturn(run func(context.Context) (map[string]string, error)) *MockdocStore_GetRedirects_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetSiteBanner provides a mock function with given fields: ctx
+func (_m *MockdocStore) GetSiteBanner(ctx context.Context) (*SiteBanner, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSiteBanner")
+	}
+
+	var r0 *SiteBanner
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*SiteBanner, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *SiteBanner); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*SiteBanner)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockdocStore_GetSiteBanner_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSiteBanner'
+type MockdocStore_GetSiteBanner_Call struct {
+	*mock.Call
+}
+
+// GetSiteBanner is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockdocStore_Expecter) GetSiteBanner(ctx interface{}) *MockdocStore_GetSiteBanner_Call {
+	return &MockdocStore_GetSiteBanner_Call{Call: _e.mock.On("GetSiteBanner", ctx)}
+}
+
+func (_c *MockdocStore_GetSiteBanner_Call) Run(run func(ctx context.Context)) *MockdocStore_GetSiteBanner_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockdocStore_GetSiteBanner_Call) Return(_a0 *SiteBanner, _a1 error) *MockdocStore_GetSiteBanner_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockdocStore_GetSiteBanner_Call) RunAndReturn(run func(context.Context) (*SiteBanner, error)) *MockdocStore_GetSiteBanner_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // List provides a mock function with given fields: ctx, repo
 func (_m *MockdocStore) List(ctx context.Context, repo string) ([]DocumentMeta, error) {
 	ret := _m.Called(ctx, repo)
@@ -460,6 +739,53 @@ func (_c *MockdocStore_Save_Call) RunAndReturn(run func(context.Context, Documen
 	return _c
 }
 
+// SaveAPIKeys provides a mock function with given fields: ctx, keys
+func (_m *MockdocStore) SaveAPIKeys(ctx context.Context, keys []APIKey) error {
+	ret := _m.Called(ctx, keys)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveAPIKeys")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []APIKey) error); ok {
+		r0 = rf(ctx, keys)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockdocStore_SaveAPIKeys_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveAPIKeys'
+type MockdocStore_SaveAPIKeys_Call struct {
+	*mock.Call
+}
+
+// SaveAPIKeys is a helper method to define mock.On call
+//   - ctx context.Context
+//   - keys []APIKey
+func (_e *MockdocStore_Expecter) SaveAPIKeys(ctx interface{}, keys interface{}) *MockdocStore_SaveAPIKeys_Call {
+	return &MockdocStore_SaveAPIKeys_Call{Call: _e.mock.On("SaveAPIKeys", ctx, keys)}
+}
+
+func (_c *MockdocStore_SaveAPIKeys_Call) Run(run func(ctx context.Context, keys []APIKey)) *MockdocStore_SaveAPIKeys_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]APIKey))
+	})
+	return _c
+}
+
+func (_c *MockdocStore_SaveAPIKeys_Call) Return(_a0 error) *MockdocStore_SaveAPIKeys_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockdocStore_SaveAPIKeys_Call) RunAndReturn(run func(context.Context, []APIKey) error) *MockdocStore_SaveAPIKeys_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // SaveAsset provides a mock function with given fields: ctx, repo, path, data
 func (_m *MockdocStore) SaveAsset(ctx context.Context, repo string, path string, data []byte) error {
 	ret := _m.Called(ctx, repo, path, data)
@@ -509,6 +835,147 @@ func (_c *MockdocStore_SaveAsset_Call) RunAndReturn(run func(context.Context, st
 	return _c
 }
 
+// SaveHighlights provides a mock function with given fields: ctx, highlights
+func (_m *MockdocStore) SaveHighlights(ctx context.Context, highlights map[string]RepoHighlights) error {
+	ret := _m.Called(ctx, highlights)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveHighlights")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, map[string]RepoHighlights) error); ok {
+		r0 = rf(ctx, highlights)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockdocStore_SaveHighlights_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveHighlights'
+type MockdocStore_SaveHighlights_Call struct {
+	*mock.Call
+}
+
+// SaveHighlights is a helper method to define mock.On call
+//   - ctx context.Context
+//   - highlights map[string]RepoHighlights
+func (_e *MockdocStore_Expecter) SaveHighlights(ctx interface{}, highlights interface{}) *MockdocStore_SaveHighlights_Call {
+	return &MockdocStore_SaveHighlights_Call{Call: _e.mock.On("SaveHighlights", ctx, highlights)}
+}
+
+func (_c *MockdocStore_SaveHighlights_Call) Run(run func(ctx context.Context, highlights map[string]RepoHighlights)) *MockdocStore_SaveHighlights_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(map[string]RepoHighlights))
+	})
+	return _c
+}
+
+func (_c *MockdocStore_SaveHighlights_Call) Return(_a0 error) *MockdocStore_SaveHighlights_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockdocStore_SaveHighlights_Call) RunAndReturn(run func(context.Context, map[string]RepoHighlights) error) *MockdocStore_SaveHighlights_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveRedirects provides a mock function with given fields: ctx, redirects
+func (_m *MockdocStore) SaveRedirects(ctx context.Context, redirects map[string]string) error {
+	ret := _m.Called(ctx, redirects)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveRedirects")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, map[string]string) error); ok {
+		r0 = rf(ctx, redirects)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockdocStore_SaveRedirects_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveRedirects'
+type MockdocStore_SaveRedirects_Call struct {
+	*mock.Call
+}
+
+// SaveRedirects is a helper method to define mock.On call
+//   - ctx context.Context
+//   - redirects map[string]string
+func (_e *MockdocStore_Expecter) SaveRedirects(ctx interface{}, redirects interface{}) *MockdocStore_SaveRedirects_Call {
+	return &MockdocStore_SaveRedirects_Call{Call: _e.mock.On("SaveRedirects", ctx, redirects)}
+}
+
+func (_c *MockdocStore_SaveRedirects_Call) Run(run func(ctx context.Context, redirects map[string]string)) *MockdocStore_SaveRedirects_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(map[string]string))
+	})
+	return _c
+}
+
+func (_c *MockdocStore_SaveRedirects_Call) Return(_a0 error) *MockdocStore_SaveRedirects_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockdocStore_SaveRedirects_Call) RunAndReturn(run func(context.Context, map[string]string) error) *MockdocStore_SaveRedirects_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveSiteBanner provides a mock function with given fields: ctx, banner
+func (_m *MockdocStore) SaveSiteBanner(ctx context.Context, banner *SiteBanner) error {
+	ret := _m.Called(ctx, banner)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveSiteBanner")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *SiteBanner) error); ok {
+		r0 = rf(ctx, banner)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockdocStore_SaveSiteBanner_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveSiteBanner'
+type MockdocStore_SaveSiteBanner_Call struct {
+	*mock.Call
+}
+
+// SaveSiteBanner is a helper method to define mock.On call
+//   - ctx context.Context
+//   - banner *SiteBanner
+func (_e *MockdocStore_Expecter) SaveSiteBanner(ctx interface{}, banner interface{}) *MockdocStore_SaveSiteBanner_Call {
+	return &MockdocStore_SaveSiteBanner_Call{Call: _e.mock.On("SaveSiteBanner", ctx, banner)}
+}
+
+func (_c *MockdocStore_SaveSiteBanner_Call) Run(run func(ctx context.Context, banner *SiteBanner)) *MockdocStore_SaveSiteBanner_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*SiteBanner))
+	})
+	return _c
+}
+
+func (_c *MockdocStore_SaveSiteBanner_Call) Return(_a0 error) *MockdocStore_SaveSiteBanner_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockdocStore_SaveSiteBanner_Call) RunAndReturn(run func(context.Context, *SiteBanner) error) *MockdocStore_SaveSiteBanner_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMockdocStore creates a new instance of MockdocStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockdocStore(t interface {
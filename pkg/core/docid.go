@@ -0,0 +1,58 @@
+package core
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// docIDSeparator separates the repository from the path in an encoded document ID. Paths
+// cannot contain control characters and repository names are validated, so it occurs in
+// neither.
+const docIDSeparator = "\x00"
+
+// DocumentID identifies a document by its repository and path.
+//
+// Its String form, "repo/path", is the key documents are stored and indexed under. That
+// form cannot always be split back into repository and path, since repositories of
+// monorepo sub-projects have more than two segments, and paths may hold characters such
+// as "#", "%" or spaces that are not safe in URLs. The Encode form is an opaque URL-safe
+// token that round-trips both through ParseDocumentID, and is the one the API exposes.
+// Ingest rejects documents whose String form is already the key of a document of another
+// repository, see checkProjectCollisions.
+type DocumentID struct {
+	Repo string
+	Path string
+}
+
+// NewDocumentID returns the ID of the document at path in repo.
+func NewDocumentID(repo, path string) DocumentID {
+	return DocumentID{Repo: repo, Path: path}
+}
+
+// String returns the "repo/path" key of the document.
+func (id DocumentID) String() string {
+	return id.Repo + "/" + id.Path
+}
+
+// Encode returns the document ID as an unpadded base64url token, which is safe in URL
+// paths and query strings and in the document keys of search engines.
+func (id DocumentID) Encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(id.Repo + docIDSeparator + id.Path))
+}
+
+// ParseDocumentID decodes a document ID returned by Encode. It returns ErrInvalidPath
+// when token is not an encoded document ID.
+func ParseDocumentID(token string) (DocumentID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return DocumentID{}, fmt.Errorf("%w: malformed document ID", ErrInvalidPath)
+	}
+
+	repo, path, ok := strings.Cut(string(raw), docIDSeparator)
+	if !ok || validateRepoName(repo) != nil || path == "" {
+		return DocumentID{}, fmt.Errorf("%w: malformed document ID", ErrInvalidPath)
+	}
+
+	return NewDocumentID(repo, path), nil
+}
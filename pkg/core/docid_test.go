@@ -0,0 +1,62 @@
+//go:build !compile
+
+package core
+
+import (
+	"encoding/base64"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocumentID_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		id   DocumentID
+		key  string
+	}{
+		{name: "plain", id: NewDocumentID("owner/repo", "guide.md"), key: "owner/repo/guide.md"},
+		{name: "sub-project", id: NewDocumentID("owner/mono/api", "docs/intro.md"), key: "owner/mono/api/docs/intro.md"},
+		{name: "reserved characters", id: NewDocumentID("owner/repo", "notes/50% off #2 & more+.md"), key: "owner/repo/notes/50% off #2 & more+.md"},
+		{name: "unicode", id: NewDocumentID("owner/repo", "guías/café.md"), key: "owner/repo/guías/café.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.key, tt.id.String())
+
+			token := tt.id.Encode()
+			assert.Equal(t, url.PathEscape(token), token, "encoded ID must not need escaping in URLs")
+			assert.Equal(t, url.QueryEscape(token), token, "encoded ID must not need escaping in query strings")
+
+			parsed, err := ParseDocumentID(token)
+			require.NoError(t, err)
+			assert.Equal(t, tt.id, parsed)
+		})
+	}
+}
+
+func TestParseDocumentID_Invalid(t *testing.T) {
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "empty", token: ""},
+		{name: "not base64", token: "owner/repo/guide.md"},
+		{name: "padded", token: base64.URLEncoding.EncodeToString([]byte("owner/repo\x00ab.md"))},
+		{name: "no separator", token: encode("owner/repo/guide.md")},
+		{name: "invalid repo", token: encode("../etc\x00passwd")},
+		{name: "empty path", token: encode("owner/repo\x00")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseDocumentID(tt.token)
+			assert.ErrorIs(t, err, ErrInvalidPath)
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// maxPathLen and maxPathSegmentLen bound document and asset paths, in bytes, so they
+	// can be stored on every supported filesystem.
+	maxPathLen        = 1024
+	maxPathSegmentLen = 255
+	// windowsInvalidChars are the characters Windows does not allow in file names.
+	windowsInvalidChars = `<>:"|?*`
+)
+
+// windowsReservedNames are device names Windows reserves regardless of extension,
+// such as "con.md" or "nul".
+var windowsReservedNames = map[string]struct{}{
+	"con": {}, "prn": {}, "aux": {}, "nul": {},
+	"com0": {}, "com1": {}, "com2": {}, "com3": {}, "com4": {}, "com5": {}, "com6": {}, "com7": {}, "com8": {}, "com9": {},
+	"lpt0": {}, "lpt1": {}, "lpt2": {}, "lpt3": {}, "lpt4": {}, "lpt5": {}, "lpt6": {}, "lpt7": {}, "lpt8": {}, "lpt9": {},
+}
+
+// normalizePath returns the canonical form of a document or asset path: backslashes
+// become slashes, Unicode is composed (NFC) so that the same name typed on macOS and
+// Linux is the same path, and "." segments and repeated slashes are removed. It returns
+// ErrInvalidPath for paths that could not be stored on every supported platform: empty,
+// absolute or escaping paths, control characters, characters or device names reserved
+// by Windows, segments ending in a dot or space, and overlong paths.
+func normalizePath(p string) (string, error) {
+	p = norm.NFC.String(strings.ReplaceAll(p, `\`, "/"))
+
+	if p == "" {
+		return "", fmt.Errorf("%w: path must not be empty", ErrInvalidPath)
+	}
+
+	if strings.HasPrefix(p, "/") {
+		return "", fmt.Errorf("%w: path must be relative", ErrInvalidPath)
+	}
+
+	clean := path.Clean(p)
+
+	if clean == "." || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("%w: path escapes the repository", ErrInvalidPath)
+	}
+
+	if len(clean) > maxPathLen {
+		return "", fmt.Errorf("%w: path is longer than %d bytes", ErrInvalidPath, maxPathLen)
+	}
+
+	for _, seg := range strings.Split(clean, "/") {
+		if err := validatePathSegment(seg); err != nil {
+			return "", fmt.Errorf("%w: %s", ErrInvalidPath, err)
+		}
+	}
+
+	return clean, nil
+}
+
+// validatePathSegment reports why a single path segment cannot be stored portably.
+func validatePathSegment(seg string) error {
+	if len(seg) > maxPathSegmentLen {
+		return fmt.Errorf("a name is longer than %d bytes", maxPathSegmentLen)
+	}
+
+	for _, r := range seg {
+		if r < 0x20 || r == 0x7f {
+			return fmt.Errorf("name %q contains a control character", seg)
+		}
+
+		if strings.ContainsRune(windowsInvalidChars, r) {
+			return fmt.Errorf("name %q contains %q, which Windows does not allow", seg, r)
+		}
+	}
+
+	if strings.HasSuffix(seg, ".") || strings.HasSuffix(seg, " ") {
+		return fmt.Errorf("name %q ends with a dot or space", seg)
+	}
+
+	base, _, _ := strings.Cut(seg, ".")
+	if _, ok := windowsReservedNames[strings.ToLower(strings.TrimRight(base, " "))]; ok {
+		return fmt.Errorf("name %q is reserved by Windows", seg)
+	}
+
+	return nil
+}
+
+// normalizeRequestPaths rewrites the document and asset paths of req to their canonical
+// form, see normalizePath. It returns ErrInvalidPath if a path is not portable, if two
+// different paths have the same canonical form, e.g. the same name in composed and
+// decomposed Unicode, or if two upserted documents, or two upserted assets, have paths
+// that differ only in case: such paths name the same file on case-insensitive filesystems.
+// Asset paths under the prefix reserved for image variants are refused as well.
+func normalizeRequestPaths(req *IngestRequest) error {
+	var (
+		upserted = make(map[string]string, len(req.Documents))
+		raw      = make(map[string]string, len(req.Documents))
+	)
+
+	for i := range req.Documents {
+		doc := &req.Documents[i]
+
+		p, err := normalizePath(doc.Path)
+		if err != nil {
+			return fmt.Errorf("document %s: %w", doc.Path, err)
+		}
+
+		if err := checkNormalizationCollision(raw, doc.Path, p); err != nil {
+			return err
+		}
+
+		doc.Path = p
+
+		if doc.Action == actionUpsert {
+			if err := checkCaseCollision(upserted, p); err != nil {
+				return err
+			}
+		}
+	}
+
+	if req.Assets == nil {
+		return nil
+	}
+
+	assets := *req.Assets
+	upserted = make(map[string]string, len(assets))
+	raw = make(map[string]string, len(assets))
+
+	for i := range assets {
+		asset := &assets[i]
+
+		p, err := normalizePath(asset.Path)
+		if err != nil {
+			return fmt.Errorf("asset %s: %w", asset.Path, err)
+		}
+
+		if err := checkNormalizationCollision(raw, asset.Path, p); err != nil {
+			return err
+		}
+
+		if isVariantPath(p) {
+			return fmt.Errorf("asset %s: %w: the %s prefix is reserved for image variants", asset.Path, ErrInvalidPath, variantPrefix)
+		}
+
+		asset.Path = p
+
+		if asset.Action == actionUpsert {
+			if err := checkCaseCollision(upserted, p); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkNormalizationCollision records the path rawPath normalized to in seen and returns
+// ErrInvalidPath if a different raw path normalized to the same path before, as one of
+// the two would silently replace the other.
+func checkNormalizationCollision(seen map[string]string, rawPath, normalized string) error {
+	if prev, ok := seen[normalized]; ok && prev != rawPath {
+		return fmt.Errorf("%w: paths %q and %q both normalize to %q", ErrInvalidPath, prev, rawPath, normalized)
+	}
+
+	seen[normalized] = rawPath
+
+	return nil
+}
+
+// checkCaseCollision records p in seen, keyed by its case-folded form, and returns
+// ErrInvalidPath if a different path with the same folded form was recorded before.
+func checkCaseCollision(seen map[string]string, p string) error {
+	key := strings.ToLower(p)
+
+	if prev, ok := seen[key]; ok && prev != p {
+		return fmt.Errorf("%w: paths %q and %q differ only in case", ErrInvalidPath, prev, p)
+	}
+
+	seen[key] = p
+
+	return nil
+}
+
+// caseInsensitiveStore is optionally implemented by a document store that keeps documents
+// on a case-insensitive filesystem, where paths that differ only in case name the same file.
+type caseInsensitiveStore interface {
+	CaseInsensitive() bool
+}
+
+// checkStoredCaseCollisions returns ErrConflict if the store is case-insensitive and a
+// document or asset upserted by req would overwrite a stored one whose path differs only
+// in case, unless the request deletes the stored one first. Sync requests are not checked:
+// they remove stale documents and restore the ones that were overwritten.
+func (s *Service) checkStoredCaseCollisions(ctx context.Context, req *IngestRequest) error {
+	cs, ok := s.store.(caseInsensitiveStore)
+	if !ok || !cs.CaseInsensitive() || req.Sync {
+		return nil
+	}
+
+	metas, err := s.store.List(ctx, req.Repo)
+	if err != nil {
+		return fmt.Errorf("failed to list documents: %w", err)
+	}
+
+	stored := make([]string, 0, len(metas))
+	for _, m := range metas {
+		stored = append(stored, m.Path)
+	}
+
+	if p, prev, ok := findCaseCollision(stored, req.Documents, func(d IngestDocument) (string, string) { return d.Path, d.Action }); ok {
+		return fmt.Errorf("%w: document %s would overwrite %s, whose path differs only in case; delete %s first or publish with sync",
+			ErrConflict, p, prev, prev)
+	}
+
+	if req.Assets == nil {
+		return nil
+	}
+
+	storedAssets, err := s.store.ListAssets(ctx, req.Repo)
+	if err != nil {
+		return fmt.Errorf("failed to list assets: %w", err)
+	}
+
+	if p, prev, ok := findCaseCollision(storedAssets, *req.Assets, func(a IngestAsset) (string, string) { return a.Path, a.Action }); ok {
+		return fmt.Errorf("%w: asset %s would overwrite %s, whose path differs only in case; delete %s first or publish with sync",
+			ErrConflict, p, prev, prev)
+	}
+
+	return nil
+}
+
+// findCaseCollision returns the first upserted path of entries that differs only in case
+// from a stored path, together with that stored path. Stored paths deleted by an earlier
+// entry are skipped.
+func findCaseCollision[T any](stored []string, entries []T, pathAction func(T) (string, string)) (path, prev string, found bool) {
+	folded := make(map[string]string, len(stored))
+	for _, p := range stored {
+		folded[strings.ToLower(p)] = p
+	}
+
+	for _, e := range entries {
+		p, action := pathAction(e)
+		key := strings.ToLower(p)
+
+		switch action {
+		case actionDelete:
+			if folded[key] == p {
+				delete(folded, key)
+			}
+		case actionUpsert:
+			if prev, ok := folded[key]; ok && prev != p {
+				return p, prev, true
+			}
+		}
+	}
+
+	return "", "", false
+}
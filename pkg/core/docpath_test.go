@@ -0,0 +1,256 @@
+//go:build !compile
+
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizePath(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "plain", path: "guides/setup.md", want: "guides/setup.md"},
+		{name: "windows separators", path: `guides\setup.md`, want: "guides/setup.md"},
+		{name: "dot segments", path: "./guides//./setup.md", want: "guides/setup.md"},
+		{name: "inner parent", path: "guides/old/../setup.md", want: "guides/setup.md"},
+		{name: "spaces and unicode", path: "Guías de uso/Über uns.md", want: "Guías de uso/Über uns.md"},
+		{name: "decomposed unicode", path: "U\u0308ber.md", want: "\u00dcber.md"},
+		{name: "url characters", path: "c#/100% done.md", want: "c#/100% done.md"},
+		{name: "reserved name as part", path: "console.md", want: "console.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizePath(tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNormalizePath_Invalid(t *testing.T) {
+	for _, p := range []string{
+		"",
+		".",
+		"/etc/passwd",
+		`\\server\share\doc.md`,
+		"../outside.md",
+		"guides/../../outside.md",
+		"guides/con.md",
+		"NUL",
+		"lpt1.txt",
+		"aux .md",
+		"what?.md",
+		"a:b.md",
+		"notes./a.md",
+		"trailing .md ",
+		"tab\there.md",
+		strings.Repeat("a", 256) + ".md",
+		strings.Repeat("dir/", 300) + "a.md",
+	} {
+		_, err := normalizePath(p)
+		assert.ErrorIs(t, err, ErrInvalidPath, "path %q", p)
+	}
+}
+
+func TestNormalizeRequestPaths(t *testing.T) {
+	req := &IngestRequest{
+		Documents: []IngestDocument{
+			{Path: `guides\setup.md`, Action: "upsert"},
+			{Path: "./Guides/old.md", Action: "delete"},
+		},
+		Assets: &[]IngestAsset{{Path: `img\logo.png`, Action: "upsert"}},
+	}
+
+	require.NoError(t, normalizeRequestPaths(req))
+	assert.Equal(t, "guides/setup.md", req.Documents[0].Path)
+	assert.Equal(t, "Guides/old.md", req.Documents[1].Path)
+	assert.Equal(t, "img/logo.png", (*req.Assets)[0].Path)
+
+	err := normalizeRequestPaths(&IngestRequest{Documents: []IngestDocument{
+		{Path: "README.md", Action: "upsert"},
+		{Path: "readme.md", Action: "upsert"},
+	}})
+	assert.ErrorIs(t, err, ErrInvalidPath)
+	assert.ErrorContains(t, err, "differ only in case")
+
+	err = normalizeRequestPaths(&IngestRequest{Assets: &[]IngestAsset{
+		{Path: "img/Logo.png", Action: "upsert"},
+		{Path: "img/logo.png", Action: "upsert"},
+	}})
+	assert.ErrorIs(t, err, ErrInvalidPath)
+
+	err = normalizeRequestPaths(&IngestRequest{Documents: []IngestDocument{{Path: "con.md", Action: "upsert"}}})
+	assert.ErrorIs(t, err, ErrInvalidPath)
+	assert.ErrorContains(t, err, "document con.md")
+
+	err = normalizeRequestPaths(&IngestRequest{Documents: []IngestDocument{
+		{Path: "\u00dcber.md", Action: "upsert"},
+		{Path: "U\u0308ber.md", Action: "delete"},
+	}})
+	assert.ErrorIs(t, err, ErrInvalidPath)
+	assert.ErrorContains(t, err, "both normalize to")
+
+	err = normalizeRequestPaths(&IngestRequest{Assets: &[]IngestAsset{
+		{Path: `img\logo.png`, Action: "upsert"},
+		{Path: "img/logo.png", Action: "upsert"},
+	}})
+	assert.ErrorIs(t, err, ErrInvalidPath)
+
+	require.NoError(t, normalizeRequestPaths(&IngestRequest{Documents: []IngestDocument{
+		{Path: "guide.md", Action: "delete"},
+		{Path: "guide.md", Action: "upsert"},
+	}}), "the same path may appear twice")
+}
+
+// caseInsensitiveDocStore is a document store on a case-insensitive filesystem.
+type caseInsensitiveDocStore struct {
+	*MockdocStore
+}
+
+func (caseInsensitiveDocStore) CaseInsensitive() bool { return true }
+
+func TestIngestDocuments_StoredCaseCollision(t *testing.T) {
+	store := NewMockdocStore(t)
+	svc := New(caseInsensitiveDocStore{store}, NewMocksearchEngine(t), map[ContentType]ContentProcessor{
+		ContentTypeMarkdown: NewMockContentProcessor(t),
+	})
+
+	store.EXPECT().List(mock.Anything, "owner/repo").Return([]DocumentMeta{{Path: "README.md"}}, nil)
+
+	_, err := svc.IngestDocuments(t.Context(), &IngestRequest{
+		Repo:      "owner/repo",
+		Documents: []IngestDocument{{Path: "readme.md", Content: "# Readme", Action: "upsert"}},
+	})
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.ErrorContains(t, err, "document readme.md would overwrite README.md")
+
+	store.EXPECT().ListAssets(mock.Anything, "owner/repo").Return([]string{"img/Logo.png"}, nil)
+
+	_, err = svc.IngestDocuments(t.Context(), &IngestRequest{
+		Repo:   "owner/repo",
+		Assets: &[]IngestAsset{{Path: "img/logo.png", Content: "ZGF0YQ==", Action: "upsert"}},
+	})
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.ErrorContains(t, err, "asset img/logo.png would overwrite img/Logo.png")
+}
+
+func TestFindCaseCollision(t *testing.T) {
+	pathAction := func(d IngestDocument) (string, string) { return d.Path, d.Action }
+
+	p, prev, ok := findCaseCollision([]string{"README.md", "guide.md"}, []IngestDocument{
+		{Path: "guide.md", Action: "upsert"},
+		{Path: "readme.md", Action: "upsert"},
+	}, pathAction)
+	assert.True(t, ok)
+	assert.Equal(t, "readme.md", p)
+	assert.Equal(t, "README.md", prev)
+
+	_, _, ok = findCaseCollision([]string{"README.md"}, []IngestDocument{
+		{Path: "README.md", Action: "delete"},
+		{Path: "readme.md", Action: "upsert"},
+	}, pathAction)
+	assert.False(t, ok, "a stored path deleted first does not collide")
+
+	_, _, ok = findCaseCollision([]string{"README.md"}, []IngestDocument{
+		{Path: "readme.md", Action: "upsert"},
+		{Path: "README.md", Action: "delete"},
+	}, pathAction)
+	assert.True(t, ok, "deleting the stored path afterwards would remove the upserted document")
+}
+
+func TestIngestDocuments_NormalizesPaths(t *testing.T) {
+	svc, store, search, renderer := newTestService(t)
+
+	content := "# Setup"
+
+	renderer.EXPECT().ExtractTitle([]byte(content)).Return("Setup")
+	renderer.EXPECT().ToPlainText([]byte(content)).Return("Setup")
+	renderer.EXPECT().ExtractCodeBlocks([]byte(content)).Return(nil)
+	renderer.EXPECT().ExtractHeadings([]byte(content)).Return(nil)
+	store.EXPECT().List(mock.Anything, "owner/repo/guides").Return(nil, nil)
+	store.EXPECT().Save(mock.Anything, mock.MatchedBy(func(doc Document) bool {
+		return doc.Path == "guides/setup.md" && doc.ID == "owner/repo/guides/setup.md"
+	})).Return(nil)
+	search.EXPECT().Index(mock.Anything, mock.Anything, "Setup", []CodeBlock(nil), []Heading(nil)).Return(nil)
+
+	resp, err := svc.IngestDocuments(t.Context(), &IngestRequest{
+		Repo:      "owner/repo",
+		Documents: []IngestDocument{{Path: `guides\setup.md`, Content: content, Action: "upsert"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, resp.Indexed)
+
+	_, err = svc.IngestDocuments(t.Context(), &IngestRequest{
+		Repo:      "owner/repo",
+		Documents: []IngestDocument{{Path: "guides/prn.md", Content: content, Action: "upsert"}},
+	})
+	assert.ErrorIs(t, err, ErrInvalidPath)
+}
+
+func TestIngestDocuments_SyncCaseRename(t *testing.T) {
+	svc, store, search, renderer := newTestService(t)
+
+	content := "# Guide"
+	saved := Document{ID: "owner/repo/guide.md", Repo: "owner/repo", Path: "guide.md", Content: content}
+
+	renderer.EXPECT().ExtractTitle([]byte(content)).Return("Guide")
+	renderer.EXPECT().ToPlainText([]byte(content)).Return("Guide")
+	renderer.EXPECT().ExtractCodeBlocks([]byte(content)).Return(nil)
+	renderer.EXPECT().ExtractHeadings([]byte(content)).Return(nil)
+	store.EXPECT().Save(mock.Anything, mock.Anything).Return(nil).Once()
+	search.EXPECT().Index(mock.Anything, mock.Anything, "Guide", []CodeBlock(nil), []Heading(nil)).Return(nil)
+
+	// On a case-insensitive filesystem the upserted document is stored in the file of
+	// the stale path, which is listed under its old name.
+	store.EXPECT().List(mock.Anything, "owner/repo").Return([]DocumentMeta{{Path: "Guide.md"}}, nil)
+	store.EXPECT().Get(mock.Anything, "owner/repo", "Guide.md").Return(Document{Content: content}, nil)
+	store.EXPECT().Get(mock.Anything, "owner/repo", "guide.md").Return(saved, nil).Once()
+	search.EXPECT().Remove(mock.Anything, "owner/repo/Guide.md").Return(nil)
+	store.EXPECT().Delete(mock.Anything, "owner/repo", "Guide.md").Return(nil)
+
+	// Removing the stale path removed the new document too, so it is saved again.
+	store.EXPECT().Get(mock.Anything, "owner/repo", "guide.md").Return(Document{}, ErrNotFound).Once()
+	store.EXPECT().Save(mock.Anything, saved).Return(nil).Once()
+
+	store.EXPECT().GetRedirects(mock.Anything).Return(map[string]string{}, nil)
+	store.EXPECT().SaveRedirects(mock.Anything, map[string]string{"owner/repo/Guide.md": "owner/repo/guide.md"}).Return(nil)
+	search.EXPECT().ListByRepo(mock.Anything, "owner/repo").Return([]string{"owner/repo/guide.md"}, nil)
+
+	resp, err := svc.IngestDocuments(t.Context(), &IngestRequest{
+		Repo:      "owner/repo",
+		Sync:      true,
+		Documents: []IngestDocument{{Path: "guide.md", Content: content, Action: "upsert"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, resp.Deleted)
+	assert.Equal(t, 1, resp.Moved)
+}
+
+func TestIngestDocuments_SyncCaseRenameAsset(t *testing.T) {
+	svc, store, search, _ := newTestService(t)
+
+	store.EXPECT().List(mock.Anything, "owner/repo").Return(nil, nil)
+	search.EXPECT().ListByRepo(mock.Anything, "owner/repo").Return(nil, nil)
+	store.EXPECT().ListAssets(mock.Anything, "owner/repo").Return([]string{"img/Logo.svg"}, nil)
+	store.EXPECT().SaveAsset(mock.Anything, "owner/repo", "img/logo.svg", []byte("data")).Return(nil).Twice()
+	store.EXPECT().GetAsset(mock.Anything, "owner/repo", "img/logo.svg").Return([]byte("data"), nil).Once()
+	store.EXPECT().DeleteAsset(mock.Anything, "owner/repo", "img/Logo.svg").Return(nil)
+	store.EXPECT().GetAsset(mock.Anything, "owner/repo", "img/logo.svg").Return(nil, ErrNotFound).Once()
+
+	resp, err := svc.IngestDocuments(t.Context(), &IngestRequest{
+		Repo:   "owner/repo",
+		Sync:   true,
+		Assets: &[]IngestAsset{{Path: "img/logo.svg", Content: "ZGF0YQ==", Action: "upsert"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, resp.AssetsDeleted)
+}
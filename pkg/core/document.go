@@ -1,6 +1,11 @@
 package core
 
-import "time"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"time"
+)
 
 // ContentType identifies the format of a document's content.
 type ContentType string
@@ -10,18 +15,110 @@ const (
 	ContentTypeMarkdown ContentType = "markdown"
 	// ContentTypeOpenAPI represents OpenAPI specification documents.
 	ContentTypeOpenAPI ContentType = "openapi"
+	// ContentTypeAsyncAPI represents AsyncAPI specification documents.
+	ContentTypeAsyncAPI ContentType = "asyncapi"
+	// ContentTypeGraphQL represents GraphQL schema (SDL) documents.
+	ContentTypeGraphQL ContentType = "graphql"
+	// ContentTypeJSONSchema represents JSON Schema documents.
+	ContentTypeJSONSchema ContentType = "jsonschema"
+	// ContentTypeAsciiDoc represents AsciiDoc documents.
+	ContentTypeAsciiDoc ContentType = "asciidoc"
+	// ContentTypePDF represents PDF documents, stored as binary content.
+	ContentTypePDF ContentType = "pdf"
 )
 
+// ContentEncodingBase64 marks an ingested document whose content is base64-encoded, as
+// binary documents such as PDFs are sent.
+const ContentEncodingBase64 = "base64"
+
 // Document represents a documentation file from a repository.
 type Document struct {
 	UpdatedAt   time.Time
+	Provenance  *Provenance // CI workflow run that published the document; nil when unknown
 	ID          string
 	Repo        string
 	Path        string
 	Title       string
+	Summary     string // short description generated at ingest time
 	Content     string
 	CommitSHA   string
 	ContentType ContentType
+	// Anchors maps the plain text of the content to its heading anchors, mapped at ingest
+	// time; nil for documents stored without one.
+	Anchors AnchorMap
+	// Text is the plain text and headings extracted from the content at ingest time; nil
+	// for documents stored without them.
+	Text *DocumentText
+	// Images holds the resized variants of the images the content embeds, keyed by asset
+	// path, as they were when the document was published; nil when it embeds none.
+	Images map[string]ImageVariants
+	Tags   []string // keywords declared by the document, normalized
+	Order  int      // position among the documents of its folder; 0 when unset
+	Home   bool     // set when the document is the repository's designated landing page
+	Draft  bool     // set when the document is left out of search
+}
+
+// DocumentText is the plain text and headings of a document's content, stored with the
+// document so that they are not extracted from the content again. It is keyed by the
+// checksum of the content it was extracted from and by the processor that extracted it,
+// so it is ignored once the content changes or the processor is configured differently.
+type DocumentText struct {
+	SHA256    string    `json:"sha256"`              // hex-encoded SHA-256 of the content
+	Processor string    `json:"processor,omitempty"` // fingerprint of the processor, see processorFingerprint
+	PlainText string    `json:"plain_text"`
+	Headings  []Heading `json:"headings,omitempty"`
+}
+
+// newDocumentText returns the text extracted from content by processor.
+func newDocumentText(content string, processor ContentProcessor, plainText string, headings []Heading) *DocumentText {
+	return &DocumentText{
+		SHA256:    contentSHA256(content),
+		Processor: processorFingerprint(processor),
+		PlainText: plainText,
+		Headings:  headings,
+	}
+}
+
+// cachedText returns the stored text of the document, or nil when it has none or the text
+// was extracted from other content or by a processor other than processor.
+func (d *Document) cachedText(processor ContentProcessor) *DocumentText {
+	if d.Text == nil || d.Text.SHA256 != contentSHA256(d.Content) || d.Text.Processor != processorFingerprint(processor) {
+		return nil
+	}
+
+	return d.Text
+}
+
+// contentSHA256 returns the hex-encoded SHA-256 of content.
+func contentSHA256(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// Checksum returns the checksum of the document's content, along with the metadata that
+// identifies the published version.
+func (d *Document) Checksum() DocumentChecksum {
+	return DocumentChecksum{
+		Repo:        d.Repo,
+		Path:        d.Path,
+		CommitSHA:   d.CommitSHA,
+		ContentType: d.ContentType,
+		UpdatedAt:   d.UpdatedAt,
+		SHA256:      contentSHA256(d.Content),
+		Size:        len(d.Content),
+	}
+}
+
+// DocumentChecksum describes the stored version of a document, so that mirrors and caches
+// can detect changes without downloading its content.
+type DocumentChecksum struct {
+	UpdatedAt   time.Time   `json:"updated_at"`
+	Repo        string      `json:"repo"`
+	Path        string      `json:"path"`
+	CommitSHA   string      `json:"commit_sha,omitempty"`
+	ContentType ContentType `json:"content_type"`
+	SHA256      string      `json:"sha256"` // hex-encoded SHA-256 of the content served at /raw
+	Size        int         `json:"size"`   // content length in bytes
 }
 
 // DocumentMeta contains metadata about a document without its full content.
@@ -31,7 +128,12 @@ type DocumentMeta struct {
 	Repo        string
 	Path        string
 	Title       string
+	Summary     string
 	ContentType ContentType
+	Tags        []string
+	Order       int
+	Home        bool
+	Draft       bool
 }
 
 // RepoInfo contains metadata about an indexed repository.
@@ -47,23 +149,72 @@ type SearchResult struct {
 	Repo             string
 	Path             string
 	Title            string
-	Anchor           string   // heading anchor ID to deep-link into the document (may be empty)
-	TitleFragments   []string // highlighted fragments from the title field
-	ContentFragments []string // highlighted fragments from the content field
-	Score            float64
+	ContentType      ContentType // content type of the document (may be empty)
+	Anchor           string      // heading anchor ID to deep-link into the document (may be empty)
+	Summary          string      // document summary, set for results without content fragments
+	TitleFragments   []string    // highlighted fragments from the title field
+	ContentFragments []string    // highlighted fragments from the content field
+	// Anchors is the anchor map of the document and PlainText the indexed plain text it
+	// maps, returned by search engines that store them with the index so that Anchor is
+	// resolved without loading the document. Both are cleared once Anchor is resolved.
+	PlainText string
+	Anchors   AnchorMap
+	Score     float64
 }
 
 // SearchResults holds the response from a search query.
 type SearchResults struct {
-	Hits     []SearchResult
-	Total    uint64
-	Duration time.Duration
+	Suggestion string // corrected query offered when the search found nothing
+	// Variant is the ranking variant the search was ranked with, or empty when it is not
+	// part of a ranking experiment.
+	Variant      string
+	Hits         []SearchResult
+	Langs        []FacetCount // code block languages among the matching documents
+	ContentTypes []FacetCount // content types of the matching documents
+	Total        uint64
+	Duration     time.Duration
+	Page         int  // 1-based page of the hits, or 0 when the search has no limit
+	HasMore      bool // more hits follow this page
+}
+
+// paginate fills in the page metadata of the results of a search run with opts.
+func (r *SearchResults) paginate(opts SearchOpts) {
+	if opts.Limit <= 0 {
+		return
+	}
+
+	offset := max(opts.Offset, 0)
+
+	r.Page = offset/opts.Limit + 1
+	r.HasMore = uint64(offset+len(r.Hits)) < r.Total
+}
+
+// FacetCount is the number of matching documents for a single facet value.
+type FacetCount struct {
+	Value string
+	Count int
 }
 
 // SearchOpts configures search behavior.
 type SearchOpts struct {
-	Limit  int
-	Offset int
+	Lang         string        // restrict results to documents with code blocks in this language
+	Mode         SearchMode    // keyword, semantic or hybrid; hybrid by default when semantic search is configured
+	Repo         string        // restrict results to this repository
+	Session      string        // reader's session, deciding the ranking variant while an experiment runs; engines ignore it
+	Repos        []string      // restrict results to these owners or repositories and their projects
+	ContentTypes []ContentType // restrict results to documents of these content types
+	Limit        int
+	Offset       int
+	CodeOnly     bool // match the query against code block contents only
+	// Quick asks for the titles and paths of the results only, without highlighted
+	// fragments or facet counts, which lets engines answer faster.
+	Quick bool
+}
+
+// CodeBlock is a fenced code block extracted from a document for code search.
+type CodeBlock struct {
+	Lang string // lowercase language from the info string (may be empty)
+	Code string
 }
 
 // IngestRequest represents a batch document ingest request from a GitHub Action.
@@ -73,19 +224,34 @@ type SearchOpts struct {
 // and a newer client that explicitly sends an empty list (non-nil pointer with
 // length zero → run cleanup, which will delete all stored assets for the repo).
 type IngestRequest struct {
-	Assets    *[]IngestAsset   `json:"assets,omitempty"`
-	Repo      string           `json:"repo"`
-	CommitSHA string           `json:"commit_sha"`
-	Documents []IngestDocument `json:"documents"`
-	Sync      bool             `json:"sync,omitempty"`
+	Assets     *[]IngestAsset   `json:"assets,omitempty"`
+	Provenance *Provenance      `json:"provenance,omitempty"`
+	Repo       string           `json:"repo"`
+	CommitSHA  string           `json:"commit_sha"`
+	Documents  []IngestDocument `json:"documents"`
+	Sync       bool             `json:"sync,omitempty"`
+}
+
+// Provenance identifies the CI workflow run that published a document.
+type Provenance struct {
+	// Workflow is the workflow identity, e.g. owner/repo/.github/workflows/docs.yml@refs/heads/main.
+	Workflow string `json:"workflow,omitempty"`
+	// RunURL links to the workflow run.
+	RunURL string `json:"run_url,omitempty"`
+	// Verified is set by the server when the ingest payload carried a valid signature.
+	// Clients cannot set it; the value sent in a request is ignored.
+	Verified bool `json:"verified,omitempty"`
 }
 
 // IngestDocument represents a single document in an ingest request.
+// Binary content, such as a PDF document, is base64-encoded with Encoding set to
+// ContentEncodingBase64; the server decodes it before the document is processed.
 type IngestDocument struct {
 	Path        string      `json:"path"`
 	Content     string      `json:"content,omitempty"`
 	Action      string      `json:"action"`                 // "upsert" or "delete"
 	ContentType ContentType `json:"content_type,omitempty"` // defaults to "markdown" when empty
+	Encoding    string      `json:"encoding,omitempty"`     // "base64" for binary content, empty for text
 }
 
 // IngestAsset represents a binary asset (image, diagram, etc.) in an ingest request.
@@ -98,10 +264,32 @@ type IngestAsset struct {
 
 // IngestResponse is returned after processing an ingest request.
 type IngestResponse struct {
-	Indexed       int `json:"indexed"`
-	Deleted       int `json:"deleted"`
-	AssetsStored  int `json:"assets_stored,omitempty"`
-	AssetsDeleted int `json:"assets_deleted,omitempty"`
+	Lint   []LintIssue     `json:"lint,omitempty"`
+	Policy []PolicyFinding `json:"policy,omitempty"`
+	// Duplicates lists the published documents whose content is identical to documents of
+	// other repositories, when duplicate detection is enabled.
+	Duplicates []DuplicateGroup `json:"duplicates,omitempty"`
+	// Failed lists the documents that could not be processed and were skipped.
+	Failed        []FailedDocument `json:"failed,omitempty"`
+	Indexed       int              `json:"indexed"`
+	Deleted       int              `json:"deleted"`
+	Moved         int              `json:"moved,omitempty"`
+	AssetsStored  int              `json:"assets_stored,omitempty"`
+	AssetsDeleted int              `json:"assets_deleted,omitempty"`
+}
+
+// RenameRepoRequest asks to move a repository to a new identifier.
+type RenameRepoRequest struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// RenameRepoResponse is returned after a repository has been renamed.
+type RenameRepoResponse struct {
+	From        string `json:"from"`
+	To          string `json:"to"`
+	DocsMoved   int    `json:"docs_moved"`
+	AssetsMoved int    `json:"assets_moved"`
 }
 
 // Heading represents a heading extracted from a document for table of contents navigation.
@@ -110,3 +298,28 @@ type Heading struct {
 	Text  string
 	Level int
 }
+
+// Anchor is the heading anchor of a section that starts at a byte offset of a document's
+// plain text.
+type Anchor struct {
+	ID     string `json:"id"`
+	Text   string `json:"text"`
+	Offset int    `json:"offset"` // byte offset of the heading line in the plain text
+	Level  int    `json:"level"`
+}
+
+// AnchorMap maps byte offsets of a document's plain text to the heading anchors of its
+// sections, in document order. It lets search results and excerpts find the section of a
+// position in the plain text without parsing the document again.
+type AnchorMap []Anchor
+
+// At returns the ID of the anchor of the section containing the byte at offset, or an
+// empty string when offset falls before the first section.
+func (m AnchorMap) At(offset int) string {
+	i := sort.Search(len(m), func(i int) bool { return m[i].Offset > offset })
+	if i == 0 {
+		return ""
+	}
+
+	return m[i-1].ID
+}
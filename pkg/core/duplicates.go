@@ -0,0 +1,288 @@
+package core
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// minDuplicateSize is the smallest content, in bytes, checked for duplicates. Shorter
+// documents, such as stubs and placeholders, are identical too often to be worth reporting.
+const minDuplicateSize = 256
+
+// DuplicateDocument is a copy of content published identically in several repositories.
+type DuplicateDocument struct {
+	UpdatedAt time.Time `json:"updated_at"`
+	Repo      string    `json:"repo"`
+	Path      string    `json:"path"`
+	Title     string    `json:"title,omitempty"`
+	URL       string    `json:"url"` // portal page of the document
+}
+
+// DuplicateGroup is a set of documents with identical content in different repositories.
+// Canonical is the copy published first, which the others likely copied; publishing it
+// again makes it the most recent copy, so check the history before removing the others.
+type DuplicateGroup struct {
+	SHA256    string              `json:"sha256"`
+	Canonical DuplicateDocument   `json:"canonical"`
+	Copies    []DuplicateDocument `json:"copies"`
+	Size      int                 `json:"size"`
+}
+
+// contentEntry is a document tracked by the content index.
+type contentEntry struct {
+	hash string
+	DuplicateDocument
+	size int
+}
+
+// contentIndex tracks the content hashes of the stored documents to find documents
+// published identically in several repositories. It is built from the store on first use
+// and kept up to date as documents are published and deleted.
+type contentIndex struct {
+	docs   map[string]contentEntry        // by document ID
+	hashes map[string]map[string]struct{} // document IDs by content hash
+	mu     sync.Mutex
+	loaded bool
+}
+
+// WithDuplicateDetection enables finding documents whose content was published identically
+// in several repositories, reported in ingest responses and by DuplicateReport. The content
+// of every stored document is read once, the first time duplicates are looked up.
+func WithDuplicateDetection() Option {
+	return func(s *Service) {
+		s.dedup = &contentIndex{}
+	}
+}
+
+// DuplicateReport returns the groups of documents with identical content in different
+// repositories, largest groups first. It returns ErrNotSupported if duplicate detection is
+// not enabled.
+func (s *Service) DuplicateReport(ctx context.Context) ([]DuplicateGroup, error) {
+	if s.dedup == nil {
+		return nil, fmt.Errorf("%w: duplicate detection is not enabled", ErrNotSupported)
+	}
+
+	s.dedup.mu.Lock()
+	defer s.dedup.mu.Unlock()
+
+	if err := s.loadContentIndex(ctx); err != nil {
+		return nil, err
+	}
+
+	groups := make([]DuplicateGroup, 0)
+
+	for hash := range s.dedup.hashes {
+		if g, ok := s.dedup.group(hash); ok {
+			groups = append(groups, g)
+		}
+	}
+
+	slices.SortFunc(groups, func(a, b DuplicateGroup) int {
+		if c := cmp.Compare(len(b.Copies), len(a.Copies)); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.Canonical.Repo+"/"+a.Canonical.Path, b.Canonical.Repo+"/"+b.Canonical.Path)
+	})
+
+	return groups, nil
+}
+
+// ingestDuplicates returns the duplicate groups of the documents published to repo at
+// paths. Failing to build the content index only loses the report, so it is logged.
+func (s *Service) ingestDuplicates(ctx context.Context, repo string, paths []string) []DuplicateGroup {
+	if s.dedup == nil || len(paths) == 0 {
+		return nil
+	}
+
+	s.dedup.mu.Lock()
+	defer s.dedup.mu.Unlock()
+
+	if err := s.loadContentIndex(ctx); err != nil {
+		slog.WarnContext(ctx, "failed to check for duplicate documents", "repo", repo, "error", err)
+		return nil
+	}
+
+	var groups []DuplicateGroup
+
+	seen := make(map[string]bool)
+
+	for _, path := range paths {
+		entry, ok := s.dedup.docs[NewDocumentID(repo, path).String()]
+		if !ok || seen[entry.hash] {
+			continue
+		}
+
+		seen[entry.hash] = true
+
+		if g, ok := s.dedup.group(entry.hash); ok {
+			groups = append(groups, g)
+		}
+	}
+
+	return groups
+}
+
+// trackContent records the content of a stored document. Until the index is loaded, the
+// document is picked up from the store when it is.
+func (s *Service) trackContent(doc *Document) {
+	if s.dedup == nil {
+		return
+	}
+
+	s.dedup.mu.Lock()
+	defer s.dedup.mu.Unlock()
+
+	if s.dedup.loaded {
+		s.dedup.add(doc)
+	}
+}
+
+// untrackContent forgets the content of a deleted document.
+func (s *Service) untrackContent(docID string) {
+	if s.dedup == nil {
+		return
+	}
+
+	s.dedup.mu.Lock()
+	defer s.dedup.mu.Unlock()
+
+	s.dedup.remove(docID)
+}
+
+// resetContentIndex discards the content index, which is rebuilt from the store on next
+// use, after changes to many documents at once such as deleting or renaming a repository.
+func (s *Service) resetContentIndex() {
+	if s.dedup == nil {
+		return
+	}
+
+	s.dedup.mu.Lock()
+	defer s.dedup.mu.Unlock()
+
+	s.dedup.docs, s.dedup.hashes, s.dedup.loaded = nil, nil, false
+}
+
+// loadContentIndex builds the content index from the store unless it is loaded. The caller
+// must hold s.dedup.mu.
+func (s *Service) loadContentIndex(ctx context.Context) error {
+	if s.dedup.loaded {
+		return nil
+	}
+
+	repos, err := s.store.ListRepos(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list repos: %w", err)
+	}
+
+	s.dedup.docs = make(map[string]contentEntry)
+	s.dedup.hashes = make(map[string]map[string]struct{})
+
+	for _, repo := range repos {
+		metas, err := s.store.List(ctx, repo.Name)
+		if err != nil {
+			return fmt.Errorf("failed to list documents of %s: %w", repo.Name, err)
+		}
+
+		for _, meta := range metas {
+			doc, err := s.store.Get(ctx, repo.Name, meta.Path)
+			if err != nil {
+				return fmt.Errorf("failed to get document %s: %w", meta.ID, err)
+			}
+
+			s.dedup.add(&doc)
+		}
+	}
+
+	s.dedup.loaded = true
+
+	slog.InfoContext(ctx, "content index built", "documents", len(s.dedup.docs))
+
+	return nil
+}
+
+// add records the content of doc, replacing its previous content.
+func (idx *contentIndex) add(doc *Document) {
+	docID := NewDocumentID(doc.Repo, doc.Path).String()
+
+	idx.remove(docID)
+
+	if len(doc.Content) < minDuplicateSize {
+		return
+	}
+
+	sum := sha256.Sum256([]byte(doc.Content))
+	hash := hex.EncodeToString(sum[:])
+
+	idx.docs[docID] = contentEntry{
+		hash: hash,
+		size: len(doc.Content),
+		DuplicateDocument: DuplicateDocument{
+			Repo:      doc.Repo,
+			Path:      doc.Path,
+			Title:     doc.Title,
+			UpdatedAt: doc.UpdatedAt,
+			URL:       "/docs/" + (&url.URL{Path: docID}).EscapedPath(),
+		},
+	}
+
+	if idx.hashes[hash] == nil {
+		idx.hashes[hash] = make(map[string]struct{})
+	}
+
+	idx.hashes[hash][docID] = struct{}{}
+}
+
+// remove forgets the content of the document with the given ID.
+func (idx *contentIndex) remove(docID string) {
+	entry, ok := idx.docs[docID]
+	if !ok {
+		return
+	}
+
+	delete(idx.docs, docID)
+	delete(idx.hashes[entry.hash], docID)
+
+	if len(idx.hashes[entry.hash]) == 0 {
+		delete(idx.hashes, entry.hash)
+	}
+}
+
+// group returns the documents with the given content hash, if they span more than one
+// repository.
+func (idx *contentIndex) group(hash string) (DuplicateGroup, bool) {
+	docs := make([]DuplicateDocument, 0, len(idx.hashes[hash]))
+	repos := make(map[string]struct{})
+
+	var size int
+
+	for docID := range idx.hashes[hash] {
+		entry := idx.docs[docID]
+		docs = append(docs, entry.DuplicateDocument)
+		repos[entry.Repo] = struct{}{}
+		size = entry.size
+	}
+
+	if len(repos) < 2 {
+		return DuplicateGroup{}, false
+	}
+
+	slices.SortFunc(docs, func(a, b DuplicateDocument) int {
+		if c := a.UpdatedAt.Compare(b.UpdatedAt); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.URL, b.URL)
+	})
+
+	return DuplicateGroup{SHA256: hash, Size: size, Canonical: docs[0], Copies: docs[1:]}, true
+}
@@ -0,0 +1,96 @@
+//go:build !compile
+
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDuplicateDetection(t *testing.T) {
+	svc, store, search, processor := newTestService(t)
+	WithDuplicateDetection()(svc)
+
+	content := "# Setup\n\n" + strings.Repeat("Install the agent and restart the service. ", 10)
+	published := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	docs := map[string]Document{
+		"team-a/api/setup.md":   {Repo: "team-a/api", Path: "setup.md", Title: "Setup", Content: content, UpdatedAt: published},
+		"team-a/api/stub.md":    {Repo: "team-a/api", Path: "stub.md", Content: "# TODO"},
+		"team-b/web/install.md": {Repo: "team-b/web", Path: "install.md", Content: "# Install\n\n" + strings.Repeat("Other. ", 50)},
+	}
+
+	store.EXPECT().ListRepos(mock.Anything).RunAndReturn(func(context.Context) ([]RepoInfo, error) {
+		return []RepoInfo{{Name: "team-a/api"}, {Name: "team-b/web"}}, nil
+	})
+	store.EXPECT().List(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, repo string) ([]DocumentMeta, error) {
+		var metas []DocumentMeta
+
+		for id, d := range docs {
+			if d.Repo == repo {
+				metas = append(metas, DocumentMeta{ID: id, Repo: d.Repo, Path: d.Path})
+			}
+		}
+
+		return metas, nil
+	})
+	store.EXPECT().Get(mock.Anything, mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, repo, path string) (Document, error) {
+		return docs[repo+"/"+path], nil
+	})
+	store.EXPECT().Save(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, doc Document) error {
+		docs[doc.ID] = doc
+		return nil
+	})
+	processor.EXPECT().ExtractTitle(mock.Anything).Return("Setup")
+	processor.EXPECT().ToPlainText(mock.Anything).Return("")
+	processor.EXPECT().ExtractCodeBlocks(mock.Anything).Return(nil)
+	processor.EXPECT().ExtractHeadings(mock.Anything).Return(nil)
+	search.EXPECT().Index(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	groups, err := svc.DuplicateReport(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, groups)
+
+	resp, err := svc.IngestDocuments(t.Context(), &IngestRequest{
+		Repo: "team-b/web",
+		Documents: []IngestDocument{
+			{Path: "setup.md", Content: content, Action: actionUpsert},
+			{Path: "todo.md", Content: "# TODO", Action: actionUpsert},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Duplicates, 1, "short documents are not compared")
+
+	group := resp.Duplicates[0]
+	assert.Equal(t, "team-a/api", group.Canonical.Repo, "the copy published first is canonical")
+	assert.Equal(t, "/docs/team-a/api/setup.md", group.Canonical.URL)
+	require.Len(t, group.Copies, 1)
+	assert.Equal(t, "team-b/web", group.Copies[0].Repo)
+	assert.Equal(t, len(content), group.Size)
+
+	groups, err = svc.DuplicateReport(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, []DuplicateGroup{group}, groups)
+
+	store.EXPECT().Delete(mock.Anything, "team-b/web", "setup.md").Return(nil)
+	search.EXPECT().Remove(mock.Anything, "team-b/web/setup.md").Return(nil)
+
+	require.NoError(t, svc.deleteDocument(t.Context(), "team-b/web", "setup.md"))
+
+	groups, err = svc.DuplicateReport(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, groups, "deleted copies are forgotten")
+}
+
+func TestDuplicateReport_Disabled(t *testing.T) {
+	svc := newTestServiceOnly(t)
+
+	_, err := svc.DuplicateReport(t.Context())
+	require.ErrorIs(t, err, ErrNotSupported)
+}
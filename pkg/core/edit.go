@@ -0,0 +1,362 @@
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultLockTTL is how long an edit lock is held without being renewed.
+const defaultLockTTL = 15 * time.Minute
+
+// EditConfig configures the web editor of wiki-style repositories, whose documents can be
+// edited in the portal and committed back to their source repository.
+type EditConfig struct {
+	Repos   []EditRepo    `mapstructure:"repos"`
+	LockTTL time.Duration `mapstructure:"lock_ttl"` // How long a lock lasts without renewal (default 15m).
+}
+
+// EditRepo designates a repository, or monorepo project, as editable in the portal.
+type EditRepo struct {
+	Repo   string `mapstructure:"repo"`   // Repository or project, e.g. acme/wiki.
+	Branch string `mapstructure:"branch"` // Branch edits are committed to (default: the repository's default branch).
+	Dir    string `mapstructure:"dir"`    // Directory of the published documents in the repository, the publish docs path (default: root).
+}
+
+// sourcePath returns the path in the source repository of the document at docPath.
+func (r EditRepo) sourcePath(docPath string) string {
+	return path.Join(strings.Trim(r.Dir, "/"), docPath)
+}
+
+// FileCommit is a change of a single file committed to a source repository.
+type FileCommit struct {
+	Repo    string // Repository or monorepo project the document belongs to.
+	Branch  string // Branch to commit to; empty for the default branch.
+	BaseRef string // Commit the edit is based on; empty if unknown.
+	Path    string // Path of the file in the repository.
+	Message string
+	Content string
+}
+
+// Committer commits edited documents back to their source repository.
+type Committer interface {
+	// CommitFile commits the file and returns the SHA of the new commit. It returns an
+	// error wrapping ErrConflict if the file changed on the branch since BaseRef.
+	CommitFile(ctx context.Context, c FileCommit) (string, error)
+}
+
+// DocumentLock claims a document for editing. While a lock is held, other editors
+// cannot lock or save the document.
+type DocumentLock struct {
+	AcquiredAt time.Time `json:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Repo       string    `json:"repo"`
+	Path       string    `json:"path"`
+	Owner      string    `json:"owner"`
+	// Token identifies the holder of the lock. It is only returned to the holder, who
+	// presents it to renew or release the lock and to save the document.
+	Token string `json:"token,omitempty"`
+}
+
+// EditRequest saves an edited document.
+type EditRequest struct {
+	Repo    string `json:"repo"`
+	Path    string `json:"path"`
+	Content string `json:"content"`
+	Message string `json:"message,omitempty"` // Commit message (default "Update <path>").
+	Token   string `json:"token"`             // Token of the lock held on the document.
+	Editor  string `json:"-"`                 // Signed-in user saving the edit, who must own the lock.
+}
+
+// EditResponse is the outcome of a saved edit.
+type EditResponse struct {
+	Repo      string `json:"repo"`
+	Path      string `json:"path"`
+	CommitSHA string `json:"commit_sha"`
+}
+
+// editor holds the editable repositories and the document locks by document ID. Locks
+// are kept in memory and are lost on restart.
+type editor struct {
+	committer Committer
+	repos     map[string]EditRepo
+	locks     map[string]*DocumentLock
+	ttl       time.Duration
+	mu        sync.Mutex
+}
+
+// WithEditor enables editing the documents of the configured repositories in the portal,
+// committing the edits through the given committer.
+func WithEditor(cfg EditConfig, c Committer) Option {
+	return func(s *Service) {
+		if len(cfg.Repos) == 0 {
+			return
+		}
+
+		e := &editor{
+			committer: c,
+			repos:     make(map[string]EditRepo, len(cfg.Repos)),
+			locks:     make(map[string]*DocumentLock),
+			ttl:       cfg.LockTTL,
+		}
+
+		if e.ttl <= 0 {
+			e.ttl = defaultLockTTL
+		}
+
+		for _, r := range cfg.Repos {
+			e.repos[r.Repo] = r
+		}
+
+		s.editor = e
+	}
+}
+
+// Editable reports whether the documents of repo can be edited in the portal.
+func (s *Service) Editable(repo string) bool {
+	if s.editor == nil {
+		return false
+	}
+
+	_, ok := s.editor.repos[repo]
+
+	return ok
+}
+
+// LockDocument claims a document for editing by owner, the signed-in user, and returns the
+// lock with the document's current content. Presenting the token of a lock owner holds on
+// the document renews it. It returns an error wrapping ErrNotSupported if the repository is not editable,
+// ErrNotFound if the document does not exist and ErrConflict if another editor holds the
+// lock.
+func (s *Service) LockDocument(ctx context.Context, repo, path, owner, token string) (*DocumentLock, Document, error) {
+	if !s.Editable(repo) {
+		return nil, Document{}, fmt.Errorf("%w: repository %s is not editable", ErrNotSupported, repo)
+	}
+
+	doc, err := s.store.Get(ctx, repo, path)
+	if err != nil {
+		return nil, Document{}, fmt.Errorf("failed to get document: %w", err)
+	}
+
+	docID := NewDocumentID(repo, path).String()
+	now := time.Now().UTC()
+
+	s.editor.mu.Lock()
+	defer s.editor.mu.Unlock()
+
+	lock, ok := s.editor.locks[docID]
+	if ok && now.Before(lock.ExpiresAt) && (lock.Token != token || lock.Owner != owner) {
+		return nil, Document{}, fmt.Errorf("%w: document %s is being edited by %s until %s",
+			ErrConflict, docID, lock.Owner, lock.ExpiresAt.Format(time.RFC3339))
+	}
+
+	if !ok || !now.Before(lock.ExpiresAt) {
+		newToken, err := newLockToken()
+		if err != nil {
+			return nil, Document{}, err
+		}
+
+		lock = &DocumentLock{Repo: repo, Path: path, Owner: owner, Token: newToken, AcquiredAt: now}
+		s.editor.locks[docID] = lock
+
+		slog.InfoContext(ctx, "document locked for editing", "repo", repo, "path", path, "owner", owner)
+	}
+
+	lock.ExpiresAt = now.Add(s.editor.ttl)
+	held := *lock
+
+	return &held, doc, nil
+}
+
+// UnlockDocument releases the lock held on a document with the given token. Releasing a
+// lock that expired is not an error. It returns an error wrapping ErrConflict if the lock
+// is held by another editor.
+func (s *Service) UnlockDocument(ctx context.Context, repo, path, token string) error {
+	if s.editor == nil {
+		return fmt.Errorf("%w: no repository is editable", ErrNotSupported)
+	}
+
+	docID := NewDocumentID(repo, path).String()
+
+	s.editor.mu.Lock()
+	defer s.editor.mu.Unlock()
+
+	lock, ok := s.editor.locks[docID]
+	if !ok {
+		return nil
+	}
+
+	if err := s.editor.checkLock(docID, token); err != nil && time.Now().Before(lock.ExpiresAt) {
+		return err
+	}
+
+	delete(s.editor.locks, docID)
+
+	slog.InfoContext(ctx, "document unlocked", "repo", repo, "path", path)
+
+	return nil
+}
+
+// PreviewEdit renders edited content of a document of an editable repository, or one
+// accepting suggestions, the way the portal renders the document once it is saved. It
+// returns an error wrapping ErrLimitExceeded if the content exceeds the limits of its
+// content processor, without rendering it.
+func (s *Service) PreviewEdit(ctx context.Context, repo, path, content string) ([]byte, []Heading, error) {
+	if !s.Editable(repo) && !s.Suggestible(repo) {
+		return nil, nil, fmt.Errorf("%w: repository %s is not editable", ErrNotSupported, repo)
+	}
+
+	doc, err := s.store.Get(ctx, repo, path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get document: %w", err)
+	}
+
+	err = s.validateContent(ctx, &IngestRequest{
+		Repo:      repo,
+		Documents: []IngestDocument{{Path: path, Content: content, Action: actionUpsert, ContentType: doc.ContentType}},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	processor := s.getProcessor(doc.ContentType, repo)
+
+	var (
+		html     []byte
+		headings []Heading
+	)
+
+	err = recoverDocument(ctx, repo, path, func() (err error) {
+		html, headings, err = renderHTML(ctx, processor, []byte(content))
+		return err
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to render document: %w", err)
+	}
+
+	return RewriteImageURLs(html, repo, path), headings, nil
+}
+
+// SaveEdit commits an edited document to its source repository and publishes it at the
+// new commit, then releases the lock held on it. The edit is based on the commit the
+// document was last published from, so the commit fails with ErrConflict if the file
+// changed in the repository since. It also returns an error wrapping ErrConflict if the
+// request's token does not hold the document's lock or its editor does not own it,
+// ErrNotSupported if the repository
+// is not editable and ErrLimitExceeded if the content cannot be rendered safely. Content
+// rejected by linting is not committed and a *LintError is returned.
+func (s *Service) SaveEdit(ctx context.Context, req EditRequest) (*EditResponse, error) {
+	if !s.Editable(req.Repo) {
+		return nil, fmt.Errorf("%w: repository %s is not editable", ErrNotSupported, req.Repo)
+	}
+
+	docID := NewDocumentID(req.Repo, req.Path).String()
+
+	s.editor.mu.Lock()
+
+	err := s.editor.checkLock(docID, req.Token)
+	if err != nil {
+		s.editor.mu.Unlock()
+		return nil, err
+	}
+
+	lock := s.editor.locks[docID]
+	owner := lock.Owner
+
+	if owner != req.Editor {
+		s.editor.mu.Unlock()
+		return nil, fmt.Errorf("%w: document %s is being edited by %s", ErrConflict, docID, owner)
+	}
+
+	s.editor.mu.Unlock()
+
+	doc, err := s.store.Get(ctx, req.Repo, req.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document: %w", err)
+	}
+
+	ingest := &IngestRequest{
+		Repo: req.Repo,
+		Documents: []IngestDocument{{
+			Path:        req.Path,
+			Content:     req.Content,
+			Action:      actionUpsert,
+			ContentType: doc.ContentType,
+		}},
+	}
+
+	if err := s.validateContent(ctx, ingest); err != nil {
+		return nil, err
+	}
+
+	// Lint before committing, so that an edit the publish would reject is not committed.
+	if _, err := s.lintRequest(ctx, ingest); err != nil {
+		return nil, err
+	}
+
+	cfg := s.editor.repos[req.Repo]
+
+	message := req.Message
+	if message == "" {
+		message = "Update " + req.Path
+	}
+
+	sha, err := s.editor.committer.CommitFile(ctx, FileCommit{
+		Repo:    req.Repo,
+		Branch:  cfg.Branch,
+		BaseRef: doc.CommitSHA,
+		Path:    cfg.sourcePath(req.Path),
+		Message: message + "\n\nEdited in the documentation portal by " + owner + ".",
+		Content: req.Content,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit document: %w", err)
+	}
+
+	ingest.CommitSHA = sha
+
+	if _, err := s.IngestDocuments(ctx, ingest); err != nil {
+		return nil, fmt.Errorf("failed to publish committed document: %w", err)
+	}
+
+	s.editor.mu.Lock()
+	if s.editor.locks[docID] == lock {
+		delete(s.editor.locks, docID)
+	}
+	s.editor.mu.Unlock()
+
+	slog.InfoContext(ctx, "document edited", "repo", req.Repo, "path", req.Path, "owner", owner, "commit", sha)
+
+	return &EditResponse{Repo: req.Repo, Path: req.Path, CommitSHA: sha}, nil
+}
+
+// checkLock returns an error wrapping ErrConflict if the document is not locked with
+// token, including when its lock expired. The caller must hold mu.
+func (e *editor) checkLock(docID, token string) error {
+	lock, ok := e.locks[docID]
+	if !ok || !time.Now().Before(lock.ExpiresAt) {
+		return fmt.Errorf("%w: the lock on document %s expired; lock it again to save", ErrConflict, docID)
+	}
+
+	if lock.Token != token {
+		return fmt.Errorf("%w: document %s is being edited by %s", ErrConflict, docID, lock.Owner)
+	}
+
+	return nil
+}
+
+// newLockToken generates a random lock token.
+func newLockToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate lock token: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,185 @@
+//go:build !compile
+
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// commitFunc adapts a function to the Committer interface.
+type commitFunc func(ctx context.Context, c FileCommit) (string, error)
+
+func (f commitFunc) CommitFile(ctx context.Context, c FileCommit) (string, error) {
+	return f(ctx, c)
+}
+
+// testEditConfig makes the owner/wiki repository of test services editable.
+var testEditConfig = EditConfig{Repos: []EditRepo{{Repo: "owner/wiki", Branch: "main", Dir: "docs/"}}}
+
+func TestLockDocument(t *testing.T) {
+	svc, store, _, _ := newTestService(t, WithEditor(testEditConfig, commitFunc(nil)))
+
+	doc := Document{Repo: "owner/wiki", Path: "guide.md", Content: "# Guide"}
+	store.EXPECT().Get(mock.Anything, "owner/wiki", "guide.md").Return(doc, nil)
+	store.EXPECT().Get(mock.Anything, "owner/wiki", "missing.md").Return(Document{}, ErrNotFound)
+
+	lock, got, err := svc.LockDocument(t.Context(), "owner/wiki", "guide.md", "Alex", "")
+	require.NoError(t, err)
+	assert.Equal(t, doc, got)
+	assert.Equal(t, "Alex", lock.Owner)
+	assert.NotEmpty(t, lock.Token)
+	assert.Equal(t, defaultLockTTL, lock.ExpiresAt.Sub(lock.AcquiredAt))
+
+	_, _, err = svc.LockDocument(t.Context(), "owner/wiki", "guide.md", "Sam", "")
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.ErrorContains(t, err, "being edited by Alex")
+
+	_, _, err = svc.LockDocument(t.Context(), "owner/wiki", "guide.md", "Sam", lock.Token)
+	assert.ErrorIs(t, err, ErrConflict, "the token only renews the lock of its owner")
+
+	renewed, _, err := svc.LockDocument(t.Context(), "owner/wiki", "guide.md", "Alex", lock.Token)
+	require.NoError(t, err)
+	assert.Equal(t, lock.Token, renewed.Token, "presenting the token renews the lock")
+	assert.Equal(t, lock.AcquiredAt, renewed.AcquiredAt)
+
+	assert.ErrorIs(t, svc.UnlockDocument(t.Context(), "owner/wiki", "guide.md", "wrong"), ErrConflict)
+	require.NoError(t, svc.UnlockDocument(t.Context(), "owner/wiki", "guide.md", lock.Token))
+	require.NoError(t, svc.UnlockDocument(t.Context(), "owner/wiki", "guide.md", lock.Token), "releasing twice is not an error")
+
+	other, _, err := svc.LockDocument(t.Context(), "owner/wiki", "guide.md", "Sam", "")
+	require.NoError(t, err)
+	assert.NotEqual(t, lock.Token, other.Token)
+
+	_, _, err = svc.LockDocument(t.Context(), "owner/wiki", "missing.md", "Sam", "")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, _, err = svc.LockDocument(t.Context(), "owner/repo", "guide.md", "Sam", "")
+	assert.ErrorIs(t, err, ErrNotSupported)
+}
+
+func TestLockDocument_Expired(t *testing.T) {
+	svc, store, _, _ := newTestService(t, WithEditor(testEditConfig, commitFunc(nil)))
+
+	store.EXPECT().Get(mock.Anything, "owner/wiki", "guide.md").Return(Document{Content: "# Guide"}, nil)
+
+	lock, _, err := svc.LockDocument(t.Context(), "owner/wiki", "guide.md", "Alex", "")
+	require.NoError(t, err)
+
+	svc.editor.locks["owner/wiki/guide.md"].ExpiresAt = time.Now().Add(-time.Second)
+
+	taken, _, err := svc.LockDocument(t.Context(), "owner/wiki", "guide.md", "Sam", "")
+	require.NoError(t, err, "an expired lock can be taken by another editor")
+	assert.Equal(t, "Sam", taken.Owner)
+
+	_, err = svc.SaveEdit(t.Context(), EditRequest{Repo: "owner/wiki", Path: "guide.md", Token: lock.Token, Editor: "Alex"})
+	assert.ErrorIs(t, err, ErrConflict)
+}
+
+func TestPreviewEdit(t *testing.T) {
+	svc, store, _, processor := newTestService(t, WithEditor(testEditConfig, commitFunc(nil)))
+
+	store.EXPECT().Get(mock.Anything, "owner/wiki", "guide.md").Return(Document{Content: "# Guide"}, nil)
+	processor.EXPECT().RenderHTML([]byte("# New\n\n![logo](img/logo.png)")).
+		Return([]byte(`<h1 id="new">New</h1><img src="img/logo.png">`), []Heading{{ID: "new", Text: "New", Level: 1}}, nil)
+
+	html, headings, err := svc.PreviewEdit(t.Context(), "owner/wiki", "guide.md", "# New\n\n![logo](img/logo.png)")
+	require.NoError(t, err)
+	assert.Contains(t, string(html), `src="/assets/owner/wiki/img/logo.png"`)
+	assert.Equal(t, []Heading{{ID: "new", Text: "New", Level: 1}}, headings)
+
+	_, _, err = svc.PreviewEdit(t.Context(), "owner/repo", "guide.md", "# New")
+	assert.ErrorIs(t, err, ErrNotSupported)
+}
+
+func TestPreviewEdit_LimitExceeded(t *testing.T) {
+	store := NewMockdocStore(t)
+	svc := New(store, NewMocksearchEngine(t), map[ContentType]ContentProcessor{
+		ContentTypeMarkdown: limitedProcessor{MockContentProcessor: NewMockContentProcessor(t), max: 10},
+	}, WithEditor(testEditConfig, commitFunc(nil)))
+
+	store.EXPECT().Get(mock.Anything, "owner/wiki", "guide.md").Return(Document{Content: "# Guide"}, nil)
+
+	html, headings, err := svc.PreviewEdit(t.Context(), "owner/wiki", "guide.md", "# Much longer document")
+	require.ErrorIs(t, err, ErrLimitExceeded)
+	assert.ErrorContains(t, err, "document guide.md")
+	assert.Nil(t, html)
+	assert.Nil(t, headings)
+}
+
+func TestSaveEdit(t *testing.T) {
+	var commit FileCommit
+
+	svc, store, search, processor := newTestService(t, WithEditor(testEditConfig, commitFunc(func(_ context.Context, c FileCommit) (string, error) {
+		commit = c
+		return "abc123", nil
+	})))
+
+	content := "# Guide\n\nUpdated."
+
+	store.EXPECT().Get(mock.Anything, "owner/wiki", "guide.md").Return(Document{Content: "# Guide", CommitSHA: "def456"}, nil)
+	processor.EXPECT().ExtractTitle([]byte(content)).Return("Guide")
+	processor.EXPECT().ToPlainText([]byte(content)).Return("Guide Updated.")
+	processor.EXPECT().ExtractCodeBlocks([]byte(content)).Return(nil)
+	processor.EXPECT().ExtractHeadings([]byte(content)).Return(nil)
+	store.EXPECT().Save(mock.Anything, mock.MatchedBy(func(doc Document) bool {
+		return doc.ID == "owner/wiki/guide.md" && doc.Content == content && doc.CommitSHA == "abc123"
+	})).Return(nil)
+	search.EXPECT().Index(mock.Anything, mock.Anything, "Guide Updated.", []CodeBlock(nil), []Heading(nil)).Return(nil)
+
+	lock, _, err := svc.LockDocument(t.Context(), "owner/wiki", "guide.md", "Alex", "")
+	require.NoError(t, err)
+
+	_, err = svc.SaveEdit(t.Context(), EditRequest{Repo: "owner/wiki", Path: "guide.md", Content: content, Token: "wrong", Editor: "Alex"})
+	assert.ErrorIs(t, err, ErrConflict)
+
+	_, err = svc.SaveEdit(t.Context(), EditRequest{Repo: "owner/wiki", Path: "guide.md", Content: content, Token: lock.Token, Editor: "Sam"})
+	assert.ErrorIs(t, err, ErrConflict, "only the owner of the lock can save")
+
+	resp, err := svc.SaveEdit(t.Context(), EditRequest{Repo: "owner/wiki", Path: "guide.md", Content: content, Token: lock.Token, Editor: "Alex"})
+	require.NoError(t, err)
+	assert.Equal(t, &EditResponse{Repo: "owner/wiki", Path: "guide.md", CommitSHA: "abc123"}, resp)
+
+	assert.Equal(t, FileCommit{
+		Repo:    "owner/wiki",
+		Branch:  "main",
+		BaseRef: "def456",
+		Path:    "docs/guide.md",
+		Message: "Update guide.md\n\nEdited in the documentation portal by Alex.",
+		Content: content,
+	}, commit)
+
+	_, err = svc.SaveEdit(t.Context(), EditRequest{Repo: "owner/wiki", Path: "guide.md", Content: content, Token: lock.Token, Editor: "Alex"})
+	assert.ErrorIs(t, err, ErrConflict, "saving releases the lock")
+}
+
+func TestSaveEdit_CommitConflict(t *testing.T) {
+	svc, store, _, _ := newTestService(t, WithEditor(testEditConfig, commitFunc(func(context.Context, FileCommit) (string, error) {
+		return "", ErrConflict
+	})))
+
+	store.EXPECT().Get(mock.Anything, "owner/wiki", "guide.md").Return(Document{Content: "# Guide"}, nil)
+
+	lock, _, err := svc.LockDocument(t.Context(), "owner/wiki", "guide.md", "Alex", "")
+	require.NoError(t, err)
+
+	_, err = svc.SaveEdit(t.Context(), EditRequest{Repo: "owner/wiki", Path: "guide.md", Content: "# New", Token: lock.Token, Editor: "Alex"})
+	assert.ErrorIs(t, err, ErrConflict)
+
+	_, err = svc.SaveEdit(t.Context(), EditRequest{Repo: "owner/repo", Path: "guide.md", Content: "# New"})
+	assert.ErrorIs(t, err, ErrNotSupported)
+}
+
+func TestEditable(t *testing.T) {
+	svc, _, _, _ := newTestService(t, WithEditor(testEditConfig, commitFunc(nil)))
+
+	assert.True(t, svc.Editable("owner/wiki"))
+	assert.False(t, svc.Editable("owner/repo"))
+	assert.False(t, newTestServiceOnly(t).Editable("owner/wiki"))
+	assert.ErrorIs(t, newTestServiceOnly(t).UnlockDocument(t.Context(), "owner/wiki", "guide.md", ""), ErrNotSupported)
+}
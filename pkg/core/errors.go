@@ -10,3 +10,32 @@ var ErrNotFound = errors.New("not found")
 // path is empty, absolute, or attempts directory traversal. API handlers check
 // this sentinel to return HTTP 400.
 var ErrInvalidPath = errors.New("invalid path: directory traversal not allowed")
+
+// ErrConflict is returned when an operation would overwrite existing data, such as
+// renaming a repository to an identifier that is already in use. API handlers check
+// this sentinel to return HTTP 409.
+var ErrConflict = errors.New("conflict")
+
+// ErrLimitExceeded is returned when content exceeds the size or complexity a content
+// processor accepts, such as a markdown document nested too deeply to render safely.
+// API handlers check this sentinel to return HTTP 422.
+var ErrLimitExceeded = errors.New("limit exceeded")
+
+// ErrNotSupported is returned when an operation needs an integration the instance is not
+// configured with, such as triggering a republish without a republish trigger. API
+// handlers check this sentinel to return HTTP 501.
+var ErrNotSupported = errors.New("not supported")
+
+// ErrRateLimited is returned when a client makes more requests than allowed, such as
+// readers submitting more edit suggestions than the instance accepts per hour. API
+// handlers check this sentinel to return HTTP 429.
+var ErrRateLimited = errors.New("rate limited")
+
+// ErrInvalidArchive is returned when an imported instance archive is malformed, of an
+// unsupported version, or holds invalid repositories or paths. API handlers check this
+// sentinel to return HTTP 400.
+var ErrInvalidArchive = errors.New("invalid archive")
+
+// ErrInvalidSavedSearch is returned when a search to save has an invalid query, name or
+// webhook. API handlers check this sentinel to return HTTP 400.
+var ErrInvalidSavedSearch = errors.New("invalid saved search")
@@ -0,0 +1,87 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Document event types reported by Subscribe.
+const (
+	EventDocumentUpdated = "updated"
+	EventDocumentDeleted = "deleted"
+	EventIncidentStarted = "incident_started"
+	EventIncidentEnded   = "incident_ended"
+	EventPresence        = "presence"
+)
+
+// eventBuffer is the number of events buffered per subscriber. Events published while the
+// buffer of a subscriber is full are dropped for that subscriber.
+const eventBuffer = 64
+
+// DocumentEvent reports a change of a published document, of its incident flag or of the
+// readers viewing it during an incident.
+type DocumentEvent struct {
+	At       time.Time `json:"at"`
+	Type     string    `json:"type"`
+	Repo     string    `json:"repo"`
+	Path     string    `json:"path"`
+	Incident string    `json:"incident,omitempty"` // name of the incident, for EventIncidentStarted
+	Viewers  []Viewer  `json:"viewers,omitempty"`  // active viewers, for EventPresence
+}
+
+// eventHub fans document events out to subscribers. Events are only delivered to
+// subscribers of the instance that made the change.
+type eventHub struct {
+	subs map[chan DocumentEvent]struct{}
+	mu   sync.Mutex
+}
+
+// Subscribe returns a channel receiving an event for every document published, moved or
+// deleted through this instance, and for incident and presence changes, until ctx is done,
+// when the channel is closed. Events are dropped rather than delaying publishing if the
+// subscriber falls behind.
+func (s *Service) Subscribe(ctx context.Context) <-chan DocumentEvent {
+	ch := make(chan DocumentEvent, eventBuffer)
+
+	s.events.mu.Lock()
+
+	if s.events.subs == nil {
+		s.events.subs = make(map[chan DocumentEvent]struct{})
+	}
+
+	s.events.subs[ch] = struct{}{}
+	s.events.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		s.events.mu.Lock()
+		delete(s.events.subs, ch)
+		s.events.mu.Unlock()
+
+		close(ch)
+	}()
+
+	return ch
+}
+
+// publishEvent sends a document event of the given type to every subscriber.
+func (s *Service) publishEvent(eventType, repo, path string) {
+	s.broadcast(DocumentEvent{Type: eventType, Repo: repo, Path: path})
+}
+
+// broadcast sends ev to every subscriber, stamped with the current time.
+func (s *Service) broadcast(ev DocumentEvent) {
+	ev.At = time.Now().UTC()
+
+	s.events.mu.Lock()
+	defer s.events.mu.Unlock()
+
+	for ch := range s.events.subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
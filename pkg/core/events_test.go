@@ -0,0 +1,52 @@
+//go:build !compile
+
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscribe(t *testing.T) {
+	svc, store, search, _ := newTestService(t)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	events := svc.Subscribe(ctx)
+
+	store.EXPECT().List(mock.Anything, "owner/repo").Return([]DocumentMeta{{Repo: "owner/repo", Path: "a.md"}}, nil)
+	search.EXPECT().ListByRepo(mock.Anything, "owner/repo").Return(nil, nil)
+	store.EXPECT().DeleteRepo(mock.Anything, "owner/repo").Return(nil)
+	store.EXPECT().GetHighlights(mock.Anything).Return(nil, nil)
+
+	_, err := svc.DeleteRepo(t.Context(), "owner/repo")
+	require.NoError(t, err)
+
+	ev := <-events
+	assert.Equal(t, EventDocumentDeleted, ev.Type)
+	assert.Equal(t, "owner/repo", ev.Repo)
+	assert.Equal(t, "a.md", ev.Path)
+	assert.False(t, ev.At.IsZero())
+
+	cancel()
+
+	_, open := <-events
+	assert.False(t, open, "the channel is closed when the subscriber's context is done")
+
+	svc.publishEvent(EventDocumentUpdated, "owner/repo", "a.md")
+}
+
+func TestPublishEvent_SlowSubscriber(t *testing.T) {
+	svc := newTestServiceOnly(t)
+
+	events := svc.Subscribe(t.Context())
+
+	for range eventBuffer + 10 {
+		svc.publishEvent(EventDocumentUpdated, "owner/repo", "a.md")
+	}
+
+	assert.Len(t, events, eventBuffer, "events beyond the buffer are dropped instead of blocking publishing")
+}
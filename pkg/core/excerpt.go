@@ -0,0 +1,86 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Excerpt is the plain text of one section of a document, for tools that show the
+// relevant part of a document inline, such as IDE tooltips and chat bots.
+type Excerpt struct {
+	Repo    string `json:"repo"`
+	Path    string `json:"path"`
+	Title   string `json:"title"`
+	Anchor  string `json:"anchor,omitempty"`  // anchor ID of the section's heading; empty for the introduction
+	Heading string `json:"heading,omitempty"` // text of the section's heading
+	Text    string `json:"text"`              // plain text of the section, without its heading
+}
+
+// GetExcerpt returns the plain text of the section of a document whose heading has the
+// anchor ID anchor, including its subsections, up to the next heading of the same or a
+// higher level. An empty anchor asks for the introduction before the first heading, or
+// the whole document when it has no headings. It returns ErrNotFound if the document or
+// the section does not exist.
+func (s *Service) GetExcerpt(ctx context.Context, repo, path, anchor string) (*Excerpt, error) {
+	doc, plainText, err := s.GetDocumentText(ctx, repo, path)
+	if err != nil {
+		return nil, err
+	}
+
+	excerpt := &Excerpt{Repo: repo, Path: path, Title: doc.Title, Anchor: anchor}
+
+	processor := s.getProcessor(doc.ContentType, repo)
+
+	anchors := storedAnchors(&doc, processor)
+	if anchors == nil {
+		anchors = findSectionBoundaries(plainText, documentHeadings(&doc, processor))
+	}
+
+	if anchor == "" {
+		end := len(plainText)
+		if len(anchors) > 0 {
+			end = min(anchors[0].Offset, end)
+		}
+
+		excerpt.Text = strings.TrimSpace(plainText[:end])
+
+		return excerpt, nil
+	}
+
+	for i, a := range anchors {
+		if a.ID != anchor {
+			continue
+		}
+
+		end := len(plainText)
+
+		for _, next := range anchors[i+1:] {
+			if next.Level <= a.Level {
+				end = min(next.Offset, end)
+				break
+			}
+		}
+
+		excerpt.Heading = a.Text
+		excerpt.Text = strings.TrimSpace(plainText[sectionStart(plainText, a.Offset, end):end])
+
+		return excerpt, nil
+	}
+
+	return nil, fmt.Errorf("%w: no section %q in %s/%s", ErrNotFound, anchor, repo, path)
+}
+
+// sectionStart returns the byte offset of the text of a section whose heading line starts
+// at offset in plainText and whose text ends at end, just past the heading line.
+func sectionStart(plainText string, offset, end int) int {
+	if offset >= end {
+		return end
+	}
+
+	if i := strings.IndexByte(plainText[offset:end], '\n'); i >= 0 {
+		return offset + i + 1
+	}
+
+	return end
+}
@@ -0,0 +1,119 @@
+//go:build !compile
+
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetExcerpt(t *testing.T) {
+	const plainText = "Guide\nWelcome to the guide.\nInstall\nRun the installer.\nLinux\nUse the package.\nConfigure\nEdit the file."
+
+	headings := []Heading{
+		{ID: "guide", Text: "Guide", Level: 1},
+		{ID: "install", Text: "Install", Level: 2},
+		{ID: "linux", Text: "Linux", Level: 3},
+		{ID: "configure", Text: "Configure", Level: 2},
+	}
+
+	tests := []struct {
+		name    string
+		anchor  string
+		heading string
+		text    string
+	}{
+		{name: "section with subsections", anchor: "install", heading: "Install", text: "Run the installer.\nLinux\nUse the package."},
+		{name: "subsection", anchor: "linux", heading: "Linux", text: "Use the package."},
+		{name: "last section", anchor: "configure", heading: "Configure", text: "Edit the file."},
+		{name: "introduction", anchor: "", text: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, store, _, processor := newTestService(t)
+
+			doc := Document{Repo: "owner/repo", Path: "guide.md", Title: "Guide", Content: "# Guide"}
+
+			store.EXPECT().Get(mock.Anything, "owner/repo", "guide.md").Return(doc, nil)
+			processor.EXPECT().ToPlainText([]byte(doc.Content)).Return(plainText)
+			processor.EXPECT().ExtractHeadings([]byte(doc.Content)).Return(headings)
+
+			ex, err := svc.GetExcerpt(t.Context(), "owner/repo", "guide.md", tt.anchor)
+			require.NoError(t, err)
+
+			assert.Equal(t, &Excerpt{
+				Repo:    "owner/repo",
+				Path:    "guide.md",
+				Title:   "Guide",
+				Anchor:  tt.anchor,
+				Heading: tt.heading,
+				Text:    tt.text,
+			}, ex)
+		})
+	}
+}
+
+func TestGetExcerpt_StoredAnchors(t *testing.T) {
+	svc, store, _, processor := newTestService(t)
+
+	// The heading line holds inline code, so its text differs from the heading's.
+	doc := Document{
+		Repo:    "owner/repo",
+		Path:    "guide.md",
+		Title:   "Guide",
+		Content: "# Guide",
+		Anchors: AnchorMap{
+			{ID: "run", Text: "Run make", Offset: 6, Level: 2},
+			{ID: "next", Text: "Next", Offset: 27, Level: 2},
+		},
+	}
+	doc.Text = newDocumentText(doc.Content, processor, "Intro\nRun `make`\nBuild it.\nNext\nDone.", nil)
+
+	store.EXPECT().Get(mock.Anything, "owner/repo", "guide.md").Return(doc, nil)
+
+	ex, err := svc.GetExcerpt(t.Context(), "owner/repo", "guide.md", "run")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Run make", ex.Heading)
+	assert.Equal(t, "Build it.", ex.Text)
+}
+
+func TestGetExcerpt_NoHeadings(t *testing.T) {
+	svc, store, _, processor := newTestService(t)
+
+	doc := Document{Repo: "owner/repo", Path: "notes.md", Content: "Just notes."}
+
+	store.EXPECT().Get(mock.Anything, "owner/repo", "notes.md").Return(doc, nil)
+	processor.EXPECT().ToPlainText([]byte(doc.Content)).Return("Just notes.\n")
+	processor.EXPECT().ExtractHeadings([]byte(doc.Content)).Return(nil)
+
+	ex, err := svc.GetExcerpt(t.Context(), "owner/repo", "notes.md", "")
+	require.NoError(t, err)
+	assert.Equal(t, "Just notes.", ex.Text)
+}
+
+func TestGetExcerpt_UnknownAnchor(t *testing.T) {
+	svc, store, _, processor := newTestService(t)
+
+	doc := Document{Repo: "owner/repo", Path: "guide.md", Content: "# Guide"}
+
+	store.EXPECT().Get(mock.Anything, "owner/repo", "guide.md").Return(doc, nil)
+	processor.EXPECT().ToPlainText([]byte(doc.Content)).Return("Guide\n")
+	processor.EXPECT().ExtractHeadings([]byte(doc.Content)).Return([]Heading{{ID: "guide", Text: "Guide", Level: 1}})
+
+	_, err := svc.GetExcerpt(t.Context(), "owner/repo", "guide.md", "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestGetExcerpt_DocumentNotFound(t *testing.T) {
+	svc, store, _, _ := newTestService(t)
+
+	store.EXPECT().Get(mock.Anything, "owner/repo", "missing.md").Return(Document{}, ErrNotFound)
+
+	_, err := svc.GetExcerpt(t.Context(), "owner/repo", "missing.md", "guide")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,220 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+)
+
+// RankingVariant is a ranking configuration tried in a ranking experiment.
+type RankingVariant struct {
+	Name string `mapstructure:"name"`
+	// Mode is the mode of searches that do not ask for one; empty keeps the default.
+	Mode SearchMode `mapstructure:"mode"`
+	// Hybrid sets how hybrid searches merge the keyword and semantic rankings; when it is
+	// not set the instance's configuration applies.
+	Hybrid HybridConfig `mapstructure:"hybrid"`
+	// Weight is the share of sessions assigned to the variant, relative to the weights of
+	// the other variants (default 1).
+	Weight int `mapstructure:"weight"`
+}
+
+// ExperimentConfig holds the configuration of a ranking experiment, which assigns each
+// reader's session to one of its variants and compares how often readers click the
+// results each variant ranks.
+type ExperimentConfig struct {
+	Name     string           `mapstructure:"name"`
+	Variants []RankingVariant `mapstructure:"variants"`
+}
+
+// Enabled reports whether an experiment is configured.
+func (c ExperimentConfig) Enabled() bool {
+	return len(c.Variants) > 0
+}
+
+// Validate checks that the experiment is named and has at least two uniquely named
+// variants with valid ranking configurations.
+func (c ExperimentConfig) Validate() error {
+	if c.Name == "" {
+		return errors.New("ranking experiment must have a name")
+	}
+
+	if len(c.Variants) < 2 {
+		return fmt.Errorf("ranking experiment %q must have at least two variants", c.Name)
+	}
+
+	names := make(map[string]bool, len(c.Variants))
+
+	for _, v := range c.Variants {
+		if v.Name == "" || names[v.Name] {
+			return fmt.Errorf("variants of ranking experiment %q must have unique names", c.Name)
+		}
+
+		names[v.Name] = true
+
+		switch v.Mode {
+		case "", SearchModeKeyword, SearchModeSemantic, SearchModeHybrid:
+		default:
+			return fmt.Errorf("variant %q has unknown search mode %q", v.Name, v.Mode)
+		}
+
+		if v.Weight < 0 {
+			return fmt.Errorf("variant %q weight %d must not be negative", v.Name, v.Weight)
+		}
+
+		if err := v.Hybrid.Validate(); err != nil {
+			return fmt.Errorf("variant %q: %w", v.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// WithRankingExperiment runs a ranking experiment: searches made in a session, see
+// SearchOpts.Session, are ranked with the variant the session is assigned to, and their
+// clicks are counted per variant in the statistics.
+func WithRankingExperiment(cfg ExperimentConfig) Option {
+	return func(s *Service) {
+		s.experiment = newExperiment(cfg)
+	}
+}
+
+// ExperimentStats are the searches and result clicks counted for each variant of the
+// ranking experiment since the instance started.
+type ExperimentStats struct {
+	Name     string         `json:"name"`
+	Variants []VariantStats `json:"variants"`
+}
+
+// VariantStats are the searches and result clicks counted for a ranking variant.
+type VariantStats struct {
+	Name     string `json:"name"`
+	Searches int    `json:"searches"`
+	Clicks   int    `json:"clicks"`
+	// ClickThroughRate is the number of clicks per search.
+	ClickThroughRate float64 `json:"click_through_rate"`
+	// MeanClickRank is the average 1-based position of the clicked results; the lower, the
+	// better the variant ranks the results readers want.
+	MeanClickRank float64 `json:"mean_click_rank"`
+}
+
+// variantCounts counts the activity of a ranking variant.
+type variantCounts struct {
+	searches  int
+	clicks    int
+	rankTotal int
+}
+
+// experiment assigns sessions to the variants of a ranking experiment and counts their
+// searches and clicks.
+type experiment struct {
+	counts map[string]*variantCounts
+	cfg    ExperimentConfig
+	total  int
+	mu     sync.Mutex
+}
+
+// newExperiment creates an experiment running the variants of cfg, with variants without
+// a weight counting as weight 1.
+func newExperiment(cfg ExperimentConfig) *experiment {
+	e := &experiment{cfg: cfg, counts: make(map[string]*variantCounts, len(cfg.Variants))}
+
+	for i := range e.cfg.Variants {
+		if e.cfg.Variants[i].Weight == 0 {
+			e.cfg.Variants[i].Weight = 1
+		}
+
+		e.total += e.cfg.Variants[i].Weight
+		e.counts[e.cfg.Variants[i].Name] = &variantCounts{}
+	}
+
+	return e
+}
+
+// assign returns the variant of session, or nil when no experiment runs or the search
+// has no session. A session keeps its variant for as long as the experiment is not
+// changed.
+func (e *experiment) assign(session string) *RankingVariant {
+	if e == nil || session == "" || e.total == 0 {
+		return nil
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(e.cfg.Name + "\x00" + session))
+
+	n := int(h.Sum64() % uint64(e.total)) //nolint:gosec // total is a small positive sum of weights
+
+	for i := range e.cfg.Variants {
+		if n < e.cfg.Variants[i].Weight {
+			return &e.cfg.Variants[i]
+		}
+
+		n -= e.cfg.Variants[i].Weight
+	}
+
+	return nil
+}
+
+// recordSearch counts a search ranked with variant.
+func (e *experiment) recordSearch(variant string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.counts[variant].searches++
+}
+
+// recordClick counts a click on the result at the 1-based rank of a search ranked with
+// variant.
+func (e *experiment) recordClick(variant string, rank int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	c := e.counts[variant]
+	c.clicks++
+	c.rankTotal += rank
+}
+
+// stats returns the counts of each variant.
+func (e *experiment) stats() *ExperimentStats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	stats := &ExperimentStats{Name: e.cfg.Name, Variants: make([]VariantStats, 0, len(e.cfg.Variants))}
+
+	for _, v := range e.cfg.Variants {
+		c := e.counts[v.Name]
+		vs := VariantStats{Name: v.Name, Searches: c.searches, Clicks: c.clicks}
+
+		if c.searches > 0 {
+			vs.ClickThroughRate = float64(c.clicks) / float64(c.searches)
+		}
+
+		if c.clicks > 0 {
+			vs.MeanClickRank = float64(c.rankTotal) / float64(c.clicks)
+		}
+
+		stats.Variants = append(stats.Variants, vs)
+	}
+
+	return stats
+}
+
+// RankingExperiment returns the name of the ranking experiment, or an empty string when
+// none runs. Readers' sessions need to be identified only while one runs.
+func (s *Service) RankingExperiment() string {
+	if s.experiment == nil {
+		return ""
+	}
+
+	return s.experiment.cfg.Name
+}
+
+// RecordSearchClick counts a click on the search result at the 1-based rank, on the
+// results of a search made in session, for the ranking variant of the session. It does
+// nothing when no experiment runs or the click has no session.
+func (s *Service) RecordSearchClick(session string, rank int) {
+	if v := s.experiment.assign(session); v != nil && rank > 0 {
+		s.experiment.recordClick(v.Name, rank)
+	}
+}
@@ -0,0 +1,143 @@
+//go:build !compile
+
+package core
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func testExperiment() ExperimentConfig {
+	return ExperimentConfig{
+		Name: "fusion",
+		Variants: []RankingVariant{
+			{Name: "control"},
+			{Name: "weighted", Mode: SearchModeKeyword, Hybrid: HybridConfig{Fusion: FusionWeighted, SemanticWeight: 0.7}},
+		},
+	}
+}
+
+func TestExperimentConfig_Validate(t *testing.T) {
+	assert.NoError(t, testExperiment().Validate())
+
+	tests := []struct {
+		name string
+		cfg  ExperimentConfig
+	}{
+		{name: "no name", cfg: ExperimentConfig{Variants: []RankingVariant{{Name: "a"}, {Name: "b"}}}},
+		{name: "single variant", cfg: ExperimentConfig{Name: "x", Variants: []RankingVariant{{Name: "a"}}}},
+		{name: "duplicate names", cfg: ExperimentConfig{Name: "x", Variants: []RankingVariant{{Name: "a"}, {Name: "a"}}}},
+		{name: "unnamed variant", cfg: ExperimentConfig{Name: "x", Variants: []RankingVariant{{Name: "a"}, {}}}},
+		{name: "unknown mode", cfg: ExperimentConfig{Name: "x", Variants: []RankingVariant{{Name: "a"}, {Name: "b", Mode: "fuzzy"}}}},
+		{name: "negative weight", cfg: ExperimentConfig{Name: "x", Variants: []RankingVariant{{Name: "a"}, {Name: "b", Weight: -1}}}},
+		{name: "invalid hybrid", cfg: ExperimentConfig{Name: "x", Variants: []RankingVariant{{Name: "a"}, {Name: "b", Hybrid: HybridConfig{Fusion: "max"}}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, tt.cfg.Validate())
+		})
+	}
+}
+
+func TestExperiment_Assign(t *testing.T) {
+	e := newExperiment(ExperimentConfig{
+		Name:     "fusion",
+		Variants: []RankingVariant{{Name: "control", Weight: 3}, {Name: "weighted"}},
+	})
+
+	counts := make(map[string]int)
+
+	for i := range 1000 {
+		session := fmt.Sprintf("session-%d", i)
+
+		v := e.assign(session)
+		require.NotNil(t, v)
+		assert.Equal(t, v.Name, e.assign(session).Name, "a session keeps its variant")
+
+		counts[v.Name]++
+	}
+
+	assert.InDelta(t, 750, counts["control"], 75, "sessions are split by weight")
+	assert.InDelta(t, 250, counts["weighted"], 75)
+
+	assert.Nil(t, e.assign(""), "searches without a session are not part of the experiment")
+
+	var none *experiment
+	assert.Nil(t, none.assign("session-1"))
+}
+
+// sessionOf returns a session assigned to the variant of e named name.
+func sessionOf(t *testing.T, e *experiment, name string) string {
+	t.Helper()
+
+	for i := range 100 {
+		session := fmt.Sprintf("session-%d", i)
+		if e.assign(session).Name == name {
+			return session
+		}
+	}
+
+	t.Fatalf("no session assigned to variant %q", name)
+
+	return ""
+}
+
+func TestSearchDocs_RankingExperiment(t *testing.T) {
+	store := NewMockdocStore(t)
+	search := NewMocksearchEngine(t)
+	svc := New(store, search, map[ContentType]ContentProcessor{
+		ContentTypeMarkdown: NewMockContentProcessor(t),
+	}, WithRankingExperiment(testExperiment()))
+
+	assert.Equal(t, "fusion", svc.RankingExperiment())
+
+	session := sessionOf(t, svc.experiment, "weighted")
+
+	search.EXPECT().Search(mock.Anything, "deploy", SearchOpts{Limit: 20, Mode: SearchModeKeyword, Session: session}).
+		Return(&SearchResults{Total: 1}, nil)
+	search.EXPECT().Search(mock.Anything, "deploy", SearchOpts{Limit: 20}).
+		Return(&SearchResults{Total: 1}, nil)
+
+	results, err := svc.SearchDocs(t.Context(), "deploy", SearchOpts{Limit: 20, Session: session})
+	require.NoError(t, err)
+	assert.Equal(t, "weighted", results.Variant)
+
+	results, err = svc.SearchDocs(t.Context(), "deploy", SearchOpts{Limit: 20})
+	require.NoError(t, err)
+	assert.Empty(t, results.Variant, "searches without a session are not part of the experiment")
+
+	svc.RecordSearchClick(session, 1)
+	svc.RecordSearchClick(session, 4)
+	svc.RecordSearchClick(session, 0)
+	svc.RecordSearchClick("", 1)
+
+	assert.Equal(t, &ExperimentStats{
+		Name: "fusion",
+		Variants: []VariantStats{
+			{Name: "control"},
+			{Name: "weighted", Searches: 1, Clicks: 2, ClickThroughRate: 2, MeanClickRank: 2.5},
+		},
+	}, svc.experiment.stats())
+}
+
+func TestStats_RankingExperiment(t *testing.T) {
+	store := NewMockdocStore(t)
+	svc := New(store, NewMocksearchEngine(t), map[ContentType]ContentProcessor{
+		ContentTypeMarkdown: NewMockContentProcessor(t),
+	}, WithRankingExperiment(testExperiment()))
+
+	store.EXPECT().ListRepos(mock.Anything).Return(nil, nil)
+
+	stats, err := svc.Stats(t.Context())
+	require.NoError(t, err)
+	require.NotNil(t, stats.Experiment)
+	assert.Equal(t, "fusion", stats.Experiment.Name)
+	assert.Len(t, stats.Experiment.Variants, 2)
+
+	assert.Empty(t, newTestServiceOnly(t).RankingExperiment())
+}
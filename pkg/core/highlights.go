@@ -0,0 +1,271 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// maxPinnedDocs caps the documents pinned to the top of a repository's index.
+	maxPinnedDocs = 10
+	// maxAnnouncementLen caps the length, in characters, of a repository's announcement.
+	maxAnnouncementLen = 2000
+	// highlightsCacheTTL bounds how long highlights changed on another instance take to
+	// show here.
+	highlightsCacheTTL = 30 * time.Second
+)
+
+// RepoHighlights is what a repository's owners put forward on its pages: documents pinned
+// to the top of its index and a markdown announcement shown on every page, such as a
+// deprecation notice or an incident update.
+type RepoHighlights struct {
+	UpdatedAt    time.Time `json:"updated_at,omitzero"`
+	Repo         string    `json:"repo"`
+	Announcement string    `json:"announcement,omitempty"`
+	Pinned       []string  `json:"pinned,omitempty"` // document paths, in display order
+}
+
+// empty reports whether the highlights neither pin documents nor announce anything.
+func (h *RepoHighlights) empty() bool {
+	return h.Announcement == "" && len(h.Pinned) == 0
+}
+
+// RepoHighlightsRequest is the body of a request to change a repository's highlights.
+// Fields left out keep their current value; an empty announcement or pinned list clears it.
+type RepoHighlightsRequest struct {
+	Announcement *string   `json:"announcement,omitempty"`
+	Pinned       *[]string `json:"pinned,omitempty"`
+	Repo         string    `json:"repo"`
+}
+
+// PageHighlights are a repository's highlights prepared for its pages: the announcement
+// rendered to HTML and the pinned documents that still exist.
+type PageHighlights struct {
+	AnnouncementHTML []byte
+	Pinned           []DocumentMeta
+}
+
+// highlightsCache holds the stored highlights of every repository, reloaded once they are
+// older than highlightsCacheTTL.
+type highlightsCache struct {
+	loadedAt time.Time
+	repos    map[string]RepoHighlights
+	mu       sync.Mutex
+}
+
+// GetRepoHighlights returns the highlights of a repository. A repository without
+// highlights gets empty ones.
+func (s *Service) GetRepoHighlights(ctx context.Context, repo string) (*RepoHighlights, error) {
+	if err := validateRepoName(repo); err != nil {
+		return nil, err
+	}
+
+	all, err := s.store.GetHighlights(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get highlights: %w", err)
+	}
+
+	h, ok := all[repo]
+	if !ok {
+		h = RepoHighlights{Repo: repo}
+	}
+
+	return &h, nil
+}
+
+// SetRepoHighlights changes the pinned documents and announcement of a repository. Pinned
+// paths must name documents of the repository. It returns ErrInvalidPath for an unknown
+// document or an announcement that is too long, and ErrLimitExceeded when too many
+// documents are pinned.
+func (s *Service) SetRepoHighlights(ctx context.Context, req RepoHighlightsRequest) (*RepoHighlights, error) {
+	if err := validateRepoName(req.Repo); err != nil {
+		return nil, err
+	}
+
+	var pinned []string
+
+	if req.Pinned != nil {
+		var err error
+
+		if pinned, err = s.validatePins(ctx, req.Repo, *req.Pinned); err != nil {
+			return nil, err
+		}
+	}
+
+	var announcement string
+
+	if req.Announcement != nil {
+		announcement = strings.TrimSpace(*req.Announcement)
+
+		if utf8.RuneCountInString(announcement) > maxAnnouncementLen {
+			return nil, fmt.Errorf("%w: announcement must be at most %d characters", ErrInvalidPath, maxAnnouncementLen)
+		}
+	}
+
+	s.highlights.mu.Lock()
+	defer s.highlights.mu.Unlock()
+
+	all, err := s.store.GetHighlights(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get highlights: %w", err)
+	}
+
+	if all == nil {
+		all = make(map[string]RepoHighlights)
+	}
+
+	h := all[req.Repo]
+	h.Repo = req.Repo
+	h.UpdatedAt = time.Now().UTC()
+
+	if req.Pinned != nil {
+		h.Pinned = pinned
+	}
+
+	if req.Announcement != nil {
+		h.Announcement = announcement
+	}
+
+	if h.empty() {
+		delete(all, req.Repo)
+	} else {
+		all[req.Repo] = h
+	}
+
+	if err := s.store.SaveHighlights(ctx, all); err != nil {
+		return nil, fmt.Errorf("failed to save highlights: %w", err)
+	}
+
+	s.highlights.repos = all
+	s.highlights.loadedAt = time.Now()
+
+	slog.InfoContext(ctx, "repository highlights changed", "repo", req.Repo, "pinned", len(h.Pinned), "announcement", h.Announcement != "")
+
+	return &h, nil
+}
+
+// moveHighlights moves the highlights of repository from to repository to as it is
+// renamed, or deletes them when to is empty as it is deleted. Failures are logged, since
+// the repository has already changed.
+func (s *Service) moveHighlights(ctx context.Context, from, to string) {
+	s.highlights.mu.Lock()
+	defer s.highlights.mu.Unlock()
+
+	all, err := s.store.GetHighlights(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to get highlights", "repo", from, "error", err)
+		return
+	}
+
+	h, ok := all[from]
+	if !ok {
+		return
+	}
+
+	delete(all, from)
+
+	if to != "" {
+		h.Repo = to
+		all[to] = h
+	}
+
+	if err := s.store.SaveHighlights(ctx, all); err != nil {
+		slog.WarnContext(ctx, "failed to save highlights", "repo", from, "error", err)
+		return
+	}
+
+	s.highlights.repos = all
+	s.highlights.loadedAt = time.Now()
+}
+
+// validatePins returns the normalized pinned paths without duplicates, checking that each
+// names a document of the repository.
+func (s *Service) validatePins(ctx context.Context, repo string, paths []string) ([]string, error) {
+	if len(paths) > maxPinnedDocs {
+		return nil, fmt.Errorf("%w: at most %d documents can be pinned", ErrLimitExceeded, maxPinnedDocs)
+	}
+
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	docs, err := s.store.List(ctx, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+
+	pinned := make([]string, 0, len(paths))
+
+	for _, p := range paths {
+		clean, err := normalizePath(p)
+		if err != nil {
+			return nil, err
+		}
+
+		if !slices.ContainsFunc(docs, func(d DocumentMeta) bool { return d.Path == clean }) {
+			return nil, fmt.Errorf("%w: document %s not found in %s", ErrInvalidPath, clean, repo)
+		}
+
+		if !slices.Contains(pinned, clean) {
+			pinned = append(pinned, clean)
+		}
+	}
+
+	return pinned, nil
+}
+
+// PageHighlights returns the highlights of a repository prepared for its pages, resolving
+// the pinned documents among docs. It returns nil when the repository has none. Highlights
+// are cached for a short time; if they cannot be loaded the previously cached ones are used.
+func (s *Service) PageHighlights(ctx context.Context, repo string, docs []DocumentMeta) *PageHighlights {
+	s.highlights.mu.Lock()
+
+	if time.Since(s.highlights.loadedAt) > highlightsCacheTTL {
+		all, err := s.store.GetHighlights(ctx)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to load repository highlights", "error", err)
+		} else {
+			s.highlights.repos = all
+		}
+
+		// Failures are retried after the TTL rather than on every page view.
+		s.highlights.loadedAt = time.Now()
+	}
+
+	h, ok := s.highlights.repos[repo]
+
+	s.highlights.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	page := &PageHighlights{}
+
+	for _, p := range h.Pinned {
+		if i := slices.IndexFunc(docs, func(d DocumentMeta) bool { return d.Path == p }); i >= 0 {
+			page.Pinned = append(page.Pinned, docs[i])
+		}
+	}
+
+	if h.Announcement != "" {
+		html, _, err := renderHTML(ctx, s.getProcessor(ContentTypeMarkdown, repo), []byte(h.Announcement))
+		if err != nil {
+			slog.WarnContext(ctx, "failed to render announcement", "repo", repo, "error", err)
+		} else {
+			page.AnnouncementHTML = html
+		}
+	}
+
+	if len(page.Pinned) == 0 && len(page.AnnouncementHTML) == 0 {
+		return nil
+	}
+
+	return page
+}
@@ -0,0 +1,120 @@
+//go:build !compile
+
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// expectHighlightsStore backs the highlights of store with an in-memory map.
+func expectHighlightsStore(store *MockdocStore, stored map[string]RepoHighlights) {
+	store.EXPECT().GetHighlights(mock.Anything).RunAndReturn(func(_ context.Context) (map[string]RepoHighlights, error) {
+		copied := make(map[string]RepoHighlights, len(stored))
+		for k, v := range stored {
+			copied[k] = v
+		}
+
+		return copied, nil
+	}).Maybe()
+	store.EXPECT().SaveHighlights(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, h map[string]RepoHighlights) error {
+		clear(stored)
+
+		for k, v := range h {
+			stored[k] = v
+		}
+
+		return nil
+	}).Maybe()
+}
+
+func TestSetRepoHighlights(t *testing.T) {
+	svc, store, _, processor := newTestService(t)
+
+	stored := map[string]RepoHighlights{}
+	expectHighlightsStore(store, stored)
+
+	docs := []DocumentMeta{{Repo: "acme/api", Path: "guide.md", Title: "Guide"}, {Repo: "acme/api", Path: "guides/migrate.md", Title: "Migrate"}}
+	store.EXPECT().List(mock.Anything, "acme/api").Return(docs, nil)
+
+	announcement := "  **v1 is deprecated**  "
+	pinned := []string{"guides/migrate.md", "./guides/migrate.md", "guide.md"}
+
+	h, err := svc.SetRepoHighlights(t.Context(), RepoHighlightsRequest{Repo: "acme/api", Announcement: &announcement, Pinned: &pinned})
+	require.NoError(t, err)
+	assert.Equal(t, "**v1 is deprecated**", h.Announcement)
+	assert.Equal(t, []string{"guides/migrate.md", "guide.md"}, h.Pinned, "paths are normalized and deduplicated in order")
+	assert.False(t, h.UpdatedAt.IsZero())
+
+	processor.EXPECT().RenderHTML([]byte("**v1 is deprecated**")).Return([]byte("<p><strong>v1 is deprecated</strong></p>"), nil, nil)
+
+	page := svc.PageHighlights(t.Context(), "acme/api", docs[:1])
+	require.NotNil(t, page)
+	assert.Equal(t, "<p><strong>v1 is deprecated</strong></p>", string(page.AnnouncementHTML))
+	assert.Equal(t, docs[:1], page.Pinned, "pinned documents missing from docs are skipped")
+
+	// Leaving out the pinned documents keeps them; clearing the announcement keeps the pins.
+	empty := ""
+
+	h, err = svc.SetRepoHighlights(t.Context(), RepoHighlightsRequest{Repo: "acme/api", Announcement: &empty})
+	require.NoError(t, err)
+	assert.Empty(t, h.Announcement)
+	assert.Equal(t, []string{"guides/migrate.md", "guide.md"}, h.Pinned)
+
+	// Clearing everything removes the repository's entry.
+	none := []string{}
+
+	_, err = svc.SetRepoHighlights(t.Context(), RepoHighlightsRequest{Repo: "acme/api", Pinned: &none})
+	require.NoError(t, err)
+	assert.Empty(t, stored)
+	assert.Nil(t, svc.PageHighlights(t.Context(), "acme/api", docs))
+
+	got, err := svc.GetRepoHighlights(t.Context(), "acme/api")
+	require.NoError(t, err)
+	assert.Equal(t, &RepoHighlights{Repo: "acme/api"}, got)
+}
+
+func TestSetRepoHighlights_Invalid(t *testing.T) {
+	long := strings.Repeat("a", maxAnnouncementLen+1)
+	unknown := []string{"missing.md"}
+	escaping := []string{"../other/secret.md"}
+	tooMany := make([]string, maxPinnedDocs+1)
+
+	tests := []struct {
+		wantErr error
+		name    string
+		req     RepoHighlightsRequest
+	}{
+		{name: "invalid repo", req: RepoHighlightsRequest{Repo: "acme"}, wantErr: ErrInvalidPath},
+		{name: "long announcement", req: RepoHighlightsRequest{Repo: "acme/api", Announcement: &long}, wantErr: ErrInvalidPath},
+		{name: "unknown document", req: RepoHighlightsRequest{Repo: "acme/api", Pinned: &unknown}, wantErr: ErrInvalidPath},
+		{name: "escaping path", req: RepoHighlightsRequest{Repo: "acme/api", Pinned: &escaping}, wantErr: ErrInvalidPath},
+		{name: "too many pins", req: RepoHighlightsRequest{Repo: "acme/api", Pinned: &tooMany}, wantErr: ErrLimitExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, store, _, _ := newTestService(t)
+
+			store.EXPECT().List(mock.Anything, "acme/api").Return([]DocumentMeta{{Repo: "acme/api", Path: "guide.md"}}, nil).Maybe()
+
+			_, err := svc.SetRepoHighlights(t.Context(), tt.req)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestPageHighlights_LoadError(t *testing.T) {
+	svc, store, _, _ := newTestService(t)
+
+	store.EXPECT().GetHighlights(mock.Anything).Return(nil, errors.New("bucket unavailable")).Once()
+
+	assert.Nil(t, svc.PageHighlights(t.Context(), "acme/api", nil))
+	assert.Nil(t, svc.PageHighlights(t.Context(), "acme/api", nil), "a failed load is not retried before the cache expires")
+}
@@ -0,0 +1,222 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// SearchMode selects how documents are matched and ranked.
+type SearchMode string
+
+// Search modes. Semantic and hybrid searches need semantic search to be configured; without
+// it every search is a keyword search.
+const (
+	// SearchModeKeyword matches the terms of the query with the search engine.
+	SearchModeKeyword SearchMode = "keyword"
+	// SearchModeSemantic ranks documents by closeness in meaning to the query.
+	SearchModeSemantic SearchMode = "semantic"
+	// SearchModeHybrid merges the keyword and semantic rankings. It is the default when
+	// semantic search is configured.
+	SearchModeHybrid SearchMode = "hybrid"
+)
+
+// Fusion methods merge the keyword and semantic rankings of a hybrid search.
+const (
+	// FusionRRF ranks documents by reciprocal-rank fusion, which only looks at the position
+	// of a document in each ranking. It is the default.
+	FusionRRF = "rrf"
+	// FusionWeighted ranks documents by a weighted sum of their scores in each ranking,
+	// each scaled by the best score of its ranking.
+	FusionWeighted = "weighted"
+
+	defaultRRFK           = 60
+	defaultSemanticWeight = 0.5
+	defaultSearchLimit    = 20
+)
+
+// HybridConfig holds configuration for merging keyword and semantic results.
+type HybridConfig struct {
+	Fusion         string  `mapstructure:"fusion"`          // "rrf" (default) or "weighted".
+	SemanticWeight float64 `mapstructure:"semantic_weight"` // Weight of the semantic score with weighted fusion, 0 to 1 (default 0.5).
+	RRFK           int     `mapstructure:"rrf_k"`           // Rank constant of reciprocal-rank fusion (default 60).
+}
+
+// Validate checks that the fusion method is known and the semantic weight is in range.
+func (c HybridConfig) Validate() error {
+	switch c.Fusion {
+	case "", FusionRRF, FusionWeighted:
+	default:
+		return fmt.Errorf("unknown fusion method %q: must be %q or %q", c.Fusion, FusionRRF, FusionWeighted)
+	}
+
+	if c.SemanticWeight < 0 || c.SemanticWeight > 1 {
+		return fmt.Errorf("semantic weight %v must be between 0 and 1", c.SemanticWeight)
+	}
+
+	if c.RRFK < 0 {
+		return fmt.Errorf("rrf_k %d must not be negative", c.RRFK)
+	}
+
+	return nil
+}
+
+// WithHybridRanking configures how hybrid searches merge the keyword and semantic rankings.
+// Without it, hybrid searches use reciprocal-rank fusion.
+func WithHybridRanking(cfg HybridConfig) Option {
+	return func(s *Service) {
+		s.hybrid = cfg
+	}
+}
+
+// EffectiveMode returns the mode a search with these options runs in, depending on whether
+// semantic search is configured. Language and content type filters and code-only search
+// apply to keyword matches only, so hybrid searches using them run as keyword searches.
+func (o SearchOpts) EffectiveMode(semantic bool) SearchMode {
+	if !semantic {
+		return SearchModeKeyword
+	}
+
+	switch o.Mode {
+	case SearchModeKeyword, SearchModeSemantic:
+		return o.Mode
+	}
+
+	if o.CodeOnly || o.Lang != "" || len(o.ContentTypes) > 0 {
+		return SearchModeKeyword
+	}
+
+	return SearchModeHybrid
+}
+
+// searchHybrid runs the query as a keyword and a semantic search and merges their
+// rankings. Both are searched to the depth of the requested page, so that the page is
+// taken from the merged ranking. If the semantic search fails, for example because the
+// embedding model is unavailable, the keyword ranking is returned alone. The rankings are
+// merged as configured by ranking.
+func (s *Service) searchHybrid(ctx context.Context, query string, opts SearchOpts, ranking HybridConfig) (*SearchResults, error) {
+	start := time.Now()
+
+	if opts.Limit <= 0 {
+		opts.Limit = defaultSearchLimit
+	}
+
+	depth := opts
+	depth.Offset = 0
+	depth.Limit = max(opts.Offset, 0) + opts.Limit
+
+	lexical, err := s.search.Search(ctx, query, depth)
+	if err != nil {
+		return nil, err
+	}
+
+	semantic, err := s.searchSemantic(ctx, query, depth)
+	if err != nil {
+		slog.WarnContext(ctx, "semantic search failed, returning keyword results", "error", err)
+
+		semantic = &SearchResults{}
+	}
+
+	var hits []SearchResult
+	if ranking.Fusion == FusionWeighted {
+		weight := ranking.SemanticWeight
+		if weight == 0 {
+			weight = defaultSemanticWeight
+		}
+
+		hits = fuseWeighted(lexical.Hits, semantic.Hits, weight)
+	} else {
+		k := ranking.RRFK
+		if k == 0 {
+			k = defaultRRFK
+		}
+
+		hits = fuseRRF(lexical.Hits, semantic.Hits, k)
+	}
+
+	from := min(max(opts.Offset, 0), len(hits))
+	to := min(from+opts.Limit, len(hits))
+
+	return &SearchResults{
+		Hits:         hits[from:to],
+		Langs:        lexical.Langs,
+		ContentTypes: lexical.ContentTypes,
+		Total:        max(lexical.Total, semantic.Total, uint64(len(hits))),
+		Duration:     time.Since(start),
+	}, nil
+}
+
+// fuseRRF merges two rankings by reciprocal-rank fusion: each document scores the sum of
+// 1/(k+rank) over the rankings it appears in.
+func fuseRRF(lexical, semantic []SearchResult, k int) []SearchResult {
+	rrf := func(rank int, _ float64) float64 {
+		return 1 / float64(k+rank+1)
+	}
+
+	return fuse(lexical, semantic, rrf, rrf)
+}
+
+// fuseWeighted merges two rankings by the weighted sum of each document's scores, each
+// divided by the best score of its ranking so that both rankings score from 0 to 1.
+func fuseWeighted(lexical, semantic []SearchResult, semanticWeight float64) []SearchResult {
+	lexicalMax, semanticMax := maxScore(lexical), maxScore(semantic)
+
+	return fuse(lexical, semantic, func(_ int, score float64) float64 {
+		return (1 - semanticWeight) * score / lexicalMax
+	}, func(_ int, score float64) float64 {
+		return semanticWeight * score / semanticMax
+	})
+}
+
+// fuse merges two rankings, scoring each document by the sum of its contributions to
+// either ranking. Keyword hits are kept over semantic hits of the same document, since
+// they carry the highlighted fragments. The result is sorted by score, then ID.
+func fuse(lexical, semantic []SearchResult, lexicalScore, semanticScore func(rank int, score float64) float64) []SearchResult {
+	merged := make([]SearchResult, 0, len(lexical)+len(semantic))
+	index := make(map[string]int, len(lexical)+len(semantic))
+
+	for rank, hit := range lexical {
+		index[hit.ID] = len(merged)
+		hit.Score = lexicalScore(rank, hit.Score)
+		merged = append(merged, hit)
+	}
+
+	for rank, hit := range semantic {
+		score := semanticScore(rank, hit.Score)
+
+		if i, ok := index[hit.ID]; ok {
+			merged[i].Score += score
+			continue
+		}
+
+		index[hit.ID] = len(merged)
+		hit.Score = score
+		merged = append(merged, hit)
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		if merged[i].Score != merged[j].Score {
+			return merged[i].Score > merged[j].Score
+		}
+
+		return merged[i].ID < merged[j].ID
+	})
+
+	return merged
+}
+
+// maxScore returns the best score of a ranking, or 1 if no hit has a positive score.
+func maxScore(hits []SearchResult) float64 {
+	best := 0.0
+	for _, hit := range hits {
+		best = max(best, hit.Score)
+	}
+
+	if best <= 0 {
+		return 1
+	}
+
+	return best
+}
@@ -0,0 +1,122 @@
+//go:build !compile
+
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func hitIDs(hits []SearchResult) []string {
+	ids := make([]string, len(hits))
+	for i, h := range hits {
+		ids[i] = h.ID
+	}
+
+	return ids
+}
+
+func TestHybridConfig_Validate(t *testing.T) {
+	assert.NoError(t, HybridConfig{}.Validate())
+	assert.NoError(t, HybridConfig{Fusion: FusionWeighted, SemanticWeight: 0.7}.Validate())
+	assert.Error(t, HybridConfig{Fusion: "max"}.Validate())
+	assert.Error(t, HybridConfig{SemanticWeight: 1.5}.Validate())
+	assert.Error(t, HybridConfig{RRFK: -1}.Validate())
+}
+
+func TestSearchOpts_EffectiveMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		want     SearchMode
+		opts     SearchOpts
+		semantic bool
+	}{
+		{name: "no semantic search", opts: SearchOpts{Mode: SearchModeSemantic}, want: SearchModeKeyword},
+		{name: "default", opts: SearchOpts{}, semantic: true, want: SearchModeHybrid},
+		{name: "unknown mode", opts: SearchOpts{Mode: "fuzzy"}, semantic: true, want: SearchModeHybrid},
+		{name: "keyword", opts: SearchOpts{Mode: SearchModeKeyword}, semantic: true, want: SearchModeKeyword},
+		{name: "semantic", opts: SearchOpts{Mode: SearchModeSemantic, Lang: "go"}, semantic: true, want: SearchModeSemantic},
+		{name: "hybrid with lang", opts: SearchOpts{Mode: SearchModeHybrid, Lang: "go"}, semantic: true, want: SearchModeKeyword},
+		{name: "default code only", opts: SearchOpts{CodeOnly: true}, semantic: true, want: SearchModeKeyword},
+		{name: "default content types", opts: SearchOpts{ContentTypes: []ContentType{ContentTypeOpenAPI}}, semantic: true, want: SearchModeKeyword},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.opts.EffectiveMode(tt.semantic))
+		})
+	}
+}
+
+func TestFuseRRF(t *testing.T) {
+	lexical := []SearchResult{{ID: "a", Score: 9, ContentFragments: []string{"<mark>a</mark>"}}, {ID: "b", Score: 5}}
+	semantic := []SearchResult{{ID: "c", Score: 0.9}, {ID: "a", Score: 0.8}}
+
+	hits := fuseRRF(lexical, semantic, 60)
+
+	assert.Equal(t, []string{"a", "c", "b"}, hitIDs(hits))
+	assert.InDelta(t, 1.0/61+1.0/62, hits[0].Score, 1e-9)
+	assert.Equal(t, []string{"<mark>a</mark>"}, hits[0].ContentFragments, "keyword hits keep their fragments")
+}
+
+func TestFuseWeighted(t *testing.T) {
+	lexical := []SearchResult{{ID: "a", Score: 10}, {ID: "b", Score: 2}}
+	semantic := []SearchResult{{ID: "b", Score: 0.9}, {ID: "c", Score: 0.45}}
+
+	hits := fuseWeighted(lexical, semantic, 0.8)
+
+	// a: 0.2*1, b: 0.2*0.2 + 0.8*1, c: 0.8*0.5
+	assert.Equal(t, []string{"b", "c", "a"}, hitIDs(hits))
+	assert.InDelta(t, 0.84, hits[0].Score, 1e-9)
+	assert.InDelta(t, 0.4, hits[1].Score, 1e-9)
+	assert.InDelta(t, 0.2, hits[2].Score, 1e-9)
+}
+
+func TestSearchDocs_Hybrid(t *testing.T) {
+	index := NewMockVectorIndex(t)
+	svc, store, search, _ := newTestService(t, WithSemanticSearch(embedFunc(func(context.Context, []string) ([][]float32, error) {
+		return [][]float32{{1, 0}}, nil
+	}), index))
+
+	depth := SearchOpts{Limit: 2}
+
+	search.EXPECT().Search(mock.Anything, "deploy", depth).Return(&SearchResults{
+		Hits:  []SearchResult{{ID: "o/r/a.md", Repo: "o/r", Path: "a.md", Score: 3}, {ID: "o/r/b.md", Repo: "o/r", Path: "b.md", Score: 1}},
+		Langs: []FacetCount{{Value: "go", Count: 1}},
+		Total: 5,
+	}, nil)
+	index.EXPECT().Nearest(mock.Anything, "deploy", []float32{1, 0}, depth).Return(&SearchResults{
+		Hits:  []SearchResult{{ID: "o/r/c.md", Repo: "o/r", Path: "c.md", Score: 0.9}, {ID: "o/r/b.md", Repo: "o/r", Path: "b.md", Score: 0.5}},
+		Total: 8,
+	}, nil)
+	store.EXPECT().Get(mock.Anything, "o/r", mock.Anything).Return(Document{}, nil)
+
+	// The second page of one result is taken from the merged ranking b, a, c.
+	results, err := svc.SearchDocs(t.Context(), "deploy", SearchOpts{Limit: 1, Offset: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"o/r/a.md"}, hitIDs(results.Hits))
+	assert.Equal(t, uint64(8), results.Total)
+	assert.Equal(t, []FacetCount{{Value: "go", Count: 1}}, results.Langs)
+}
+
+func TestSearchDocs_HybridSemanticFailure(t *testing.T) {
+	svc, store, search, _ := newTestService(t, WithSemanticSearch(embedFunc(func(context.Context, []string) ([][]float32, error) {
+		return nil, errors.New("model unavailable")
+	}), NewMockVectorIndex(t)))
+
+	search.EXPECT().Search(mock.Anything, "deploy", SearchOpts{Limit: 20}).Return(&SearchResults{
+		Hits:  []SearchResult{{ID: "o/r/a.md", Repo: "o/r", Path: "a.md", Score: 3}, {ID: "o/r/b.md", Repo: "o/r", Path: "b.md", Score: 1}},
+		Total: 2,
+	}, nil)
+	store.EXPECT().Get(mock.Anything, "o/r", mock.Anything).Return(Document{}, nil)
+
+	results, err := svc.SearchDocs(t.Context(), "deploy", SearchOpts{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"o/r/a.md", "o/r/b.md"}, hitIDs(results.Hits), "keyword ranking is kept")
+	assert.Equal(t, uint64(2), results.Total)
+}
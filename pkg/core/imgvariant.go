@@ -0,0 +1,419 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"log/slog"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// variantPrefix is the asset path prefix under which resized image variants are stored.
+	// Variants live at {variantPrefix}{width}/{original path} and a JSON manifest describing
+	// the available widths lives at {variantPrefix}{original path}.json.
+	variantPrefix = "_variants/"
+
+	variantJPEGQuality = 82
+
+	// maxVariantPixels is the largest image, in pixels, that variants are generated for.
+	// Images are checked against it before they are decoded, so that a small upload
+	// cannot expand into gigabytes of pixels.
+	maxVariantPixels = 40_000_000
+)
+
+// variantWidths lists the target widths, in pixels, of the resized variants generated for
+// raster images. Only widths smaller than the original image are produced.
+var variantWidths = []int{480, 960, 1600}
+
+// ImageVariants describes the resized variants of an image asset: the widths they were
+// generated at and the width of the original, so that srcset descriptors can be emitted
+// without decoding the image. It is stored as a manifest alongside the variants, and
+// recorded with the documents referencing the image when they are published.
+type ImageVariants struct {
+	Widths []int `json:"widths"`
+	Width  int   `json:"width"`
+}
+
+// localImgRe matches <img> tags whose src points at the asset route, capturing the tag
+// prefix, the src value, and the remainder of the tag.
+var localImgRe = regexp.MustCompile(`<img\s([^>]*?)src="(/assets/[^"]+)"([^>]*)>`)
+
+// isRasterImage reports whether the asset path has an extension for which resized
+// variants can be generated. GIFs are excluded to avoid flattening animations.
+func isRasterImage(p string) bool {
+	switch strings.ToLower(path.Ext(p)) {
+	case ".png", ".jpg", ".jpeg":
+		return true
+	default:
+		return false
+	}
+}
+
+// isVariantPath reports whether p is, or lies under, the asset path prefix reserved for
+// image variants. Case is ignored, as some stores do not tell paths apart by case.
+func isVariantPath(p string) bool {
+	return strings.HasPrefix(strings.ToLower(p)+"/", variantPrefix)
+}
+
+// variantPath returns the asset path of the variant of p resized to width pixels.
+func variantPath(p string, width int) string {
+	return variantPrefix + strconv.Itoa(width) + "/" + p
+}
+
+// variantManifestPath returns the asset path of the variant manifest for p.
+func variantManifestPath(p string) string {
+	return variantPrefix + p + ".json"
+}
+
+// variantOrigin returns the original asset path a variant or manifest path belongs to.
+// The second return value is false if p is not a variant path.
+func variantOrigin(p string) (string, bool) {
+	rest, ok := strings.CutPrefix(p, variantPrefix)
+	if !ok {
+		return "", false
+	}
+
+	if width, origin, found := strings.Cut(rest, "/"); found {
+		if _, err := strconv.Atoi(width); err == nil {
+			return origin, true
+		}
+	}
+
+	if origin, found := strings.CutSuffix(rest, ".json"); found {
+		return origin, true
+	}
+
+	return "", false
+}
+
+// generateImageVariants decodes a PNG or JPEG image and returns it downscaled to each of
+// the variant widths smaller than the original, encoded in the source format. Go's
+// standard library has no WebP encoder, so variants keep the format of the original.
+// It returns the original width alongside the variants; no variants are returned when
+// the data cannot be decoded, the image has more than maxVariantPixels pixels or it is
+// already small.
+func generateImageVariants(data []byte) (map[int][]byte, int, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width > maxVariantPixels/cfg.Height {
+		return nil, 0, fmt.Errorf("image of %dx%d pixels exceeds the limit of %d pixels", cfg.Width, cfg.Height, maxVariantPixels)
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	src := toRGBA(img)
+	width := src.Bounds().Dx()
+	variants := make(map[int][]byte)
+
+	for _, w := range variantWidths {
+		if w >= width {
+			continue
+		}
+
+		resized := resizeImage(src, w)
+
+		var buf bytes.Buffer
+
+		switch format {
+		case "jpeg":
+			err = jpeg.Encode(&buf, resized, &jpeg.Options{Quality: variantJPEGQuality})
+		default:
+			err = png.Encode(&buf, resized)
+		}
+
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to encode %dpx variant: %w", w, err)
+		}
+
+		variants[w] = buf.Bytes()
+	}
+
+	return variants, width, nil
+}
+
+// toRGBA returns img as an RGBA image whose bounds start at the origin, converting it
+// unless it already is one.
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Rect.Min == (image.Point{}) {
+		return rgba
+	}
+
+	b := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, b.Min, draw.Src)
+
+	return rgba
+}
+
+// resizeImage downscales src, whose bounds start at the origin, to the given width,
+// preserving the aspect ratio. Each destination pixel is the average of the source
+// pixels it covers (box filter), which gives good quality for the reduction ratios used
+// by image variants.
+func resizeImage(src *image.RGBA, width int) *image.RGBA {
+	srcW, srcH := src.Rect.Dx(), src.Rect.Dy()
+	height := max(1, srcH*width/srcW)
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := range height {
+		y0 := y * srcH / height
+		y1 := max(y0+1, (y+1)*srcH/height)
+
+		for x := range width {
+			x0 := x * srcW / width
+			x1 := max(x0+1, (x+1)*srcW/width)
+
+			var r, g, bl, a, n uint32
+
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+
+				for sx := x0; sx < x1; sx++ {
+					px := row[sx*4 : sx*4+4]
+					r += uint32(px[0])
+					g += uint32(px[1])
+					bl += uint32(px[2])
+					a += uint32(px[3])
+					n++
+				}
+			}
+
+			off := y*dst.Stride + x*4
+			dst.Pix[off] = uint8(r / n)    //nolint:gosec // average of uint8 values fits in uint8
+			dst.Pix[off+1] = uint8(g / n)  //nolint:gosec // average of uint8 values fits in uint8
+			dst.Pix[off+2] = uint8(bl / n) //nolint:gosec // average of uint8 values fits in uint8
+			dst.Pix[off+3] = uint8(a / n)  //nolint:gosec // average of uint8 values fits in uint8
+		}
+	}
+
+	return dst
+}
+
+// storeImageVariants generates and stores resized variants of a raster image asset
+// together with their manifest. Variants left over from a previous, larger version of
+// the image are removed. Failures are logged rather than returned: variants are an
+// optimization and the original asset has already been stored.
+func (s *Service) storeImageVariants(ctx context.Context, repo, assetPath string, data []byte) {
+	variants, width, err := generateImageVariants(data)
+	if err != nil {
+		slog.DebugContext(ctx, "skipping image variants", "repo", repo, "path", assetPath, "error", err)
+	}
+
+	manifest := ImageVariants{Width: width}
+
+	for _, w := range variantWidths {
+		vp := variantPath(assetPath, w)
+
+		variant, ok := variants[w]
+		if !ok {
+			if err := s.store.DeleteAsset(ctx, repo, vp); err != nil {
+				slog.WarnContext(ctx, "failed to delete stale image variant", "repo", repo, "path", vp, "error", err)
+			}
+
+			continue
+		}
+
+		if err := s.store.SaveAsset(ctx, repo, vp, variant); err != nil {
+			slog.WarnContext(ctx, "failed to save image variant", "repo", repo, "path", vp, "error", err)
+			continue
+		}
+
+		manifest.Widths = append(manifest.Widths, w)
+	}
+
+	mp := variantManifestPath(assetPath)
+
+	if len(manifest.Widths) == 0 {
+		if err := s.store.DeleteAsset(ctx, repo, mp); err != nil {
+			slog.WarnContext(ctx, "failed to delete image variant manifest", "repo", repo, "path", mp, "error", err)
+		}
+
+		return
+	}
+
+	raw, err := json.Marshal(manifest)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to encode image variant manifest", "repo", repo, "path", mp, "error", err)
+		return
+	}
+
+	if err := s.store.SaveAsset(ctx, repo, mp, raw); err != nil {
+		slog.WarnContext(ctx, "failed to save image variant manifest", "repo", repo, "path", mp, "error", err)
+	}
+}
+
+// deleteImageVariants removes all variants and the manifest of a raster image asset.
+func (s *Service) deleteImageVariants(ctx context.Context, repo, assetPath string) error {
+	paths := make([]string, 0, len(variantWidths)+1)
+	for _, w := range variantWidths {
+		paths = append(paths, variantPath(assetPath, w))
+	}
+
+	paths = append(paths, variantManifestPath(assetPath))
+
+	for _, p := range paths {
+		if err := s.store.DeleteAsset(ctx, repo, p); err != nil {
+			return fmt.Errorf("failed to delete image variant %s: %w", p, err)
+		}
+	}
+
+	return nil
+}
+
+// lookupImageVariants loads the variant manifest of an asset. It returns false when the
+// asset has no variants.
+func (s *Service) lookupImageVariants(ctx context.Context, repo, assetPath string) (ImageVariants, bool) {
+	raw, err := s.store.GetAsset(ctx, repo, variantManifestPath(assetPath))
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			slog.WarnContext(ctx, "failed to load image variant manifest", "repo", repo, "path", assetPath, "error", err)
+		}
+
+		return ImageVariants{}, false
+	}
+
+	var manifest ImageVariants
+	if err := json.Unmarshal(raw, &manifest); err != nil || len(manifest.Widths) == 0 {
+		return ImageVariants{}, false
+	}
+
+	return manifest, true
+}
+
+// imageRefs returns the asset paths of the raster images a document at docPath embeds,
+// through Markdown images or <img> tags, in order of first appearance. Images inside
+// fenced code blocks and images outside the repository are left out.
+func imageRefs(docPath, content string) []string {
+	var (
+		refs []string
+		seen = make(map[string]struct{})
+	)
+
+	add := func(dest string) {
+		target, ok := resolveLinkTarget(docPath, dest)
+		if !ok || !isRasterImage(target) {
+			return
+		}
+
+		if _, dup := seen[target]; !dup {
+			seen[target] = struct{}{}
+			refs = append(refs, target)
+		}
+	}
+
+	scanProse(content, func(_ int, line string) {
+		for _, m := range mdLinkRe.FindAllStringSubmatch(line, -1) {
+			if strings.HasPrefix(m[0], "!") {
+				add(m[1])
+			}
+		}
+
+		for _, m := range imgSrcRe.FindAllStringSubmatch(line, -1) {
+			add(m[2])
+		}
+	})
+
+	return refs
+}
+
+// documentImages returns the variants of the raster images a document embeds, keyed by
+// asset path, for the images that have variants. They are recorded with the document so
+// that rendering it does not look them up.
+func (s *Service) documentImages(ctx context.Context, repo, docPath, content string) map[string]ImageVariants {
+	var images map[string]ImageVariants
+
+	for _, ref := range imageRefs(docPath, content) {
+		manifest, ok := s.lookupImageVariants(ctx, repo, ref)
+		if !ok {
+			continue
+		}
+
+		if images == nil {
+			images = make(map[string]ImageVariants)
+		}
+
+		images[ref] = manifest
+	}
+
+	return images
+}
+
+// addResponsiveImages enhances <img> tags that point at the repository's assets: every
+// local image gets native lazy loading, and images recorded in images, the variants of
+// the document's images, get a srcset listing the variant widths plus a sizes hint that
+// keeps the original display size. It must run after RewriteImageURLs so that src
+// values are already asset routes.
+func addResponsiveImages(repo string, html []byte, images map[string]ImageVariants) []byte {
+	assetBase := "/assets/" + repo + "/"
+
+	return localImgRe.ReplaceAllFunc(html, func(match []byte) []byte {
+		sub := localImgRe.FindSubmatch(match)
+		if len(sub) < 4 {
+			return match
+		}
+
+		attrs := string(sub[1]) + string(sub[3])
+		if strings.Contains(attrs, "loading=") || strings.Contains(attrs, "srcset=") {
+			return match
+		}
+
+		src := string(sub[2])
+
+		var b strings.Builder
+
+		b.WriteString(`<img loading="lazy" decoding="async" `)
+
+		if u, err := url.Parse(src); err == nil && u.RawQuery == "" && u.Fragment == "" {
+			if assetPath, ok := strings.CutPrefix(u.Path, assetBase); ok {
+				if manifest, ok := images[assetPath]; ok && len(manifest.Widths) > 0 {
+					b.WriteString(buildSrcset(src, assetPath, assetBase, manifest))
+				}
+			}
+		}
+
+		b.Write(sub[1])
+		b.WriteString(`src="`)
+		b.WriteString(src)
+		b.WriteString(`"`)
+		b.Write(sub[3])
+		b.WriteString(">")
+
+		return []byte(b.String())
+	})
+}
+
+// buildSrcset returns the srcset and sizes attributes for an image with stored variants.
+func buildSrcset(srcPath, assetPath, assetBase string, manifest ImageVariants) string {
+	candidates := make([]string, 0, len(manifest.Widths)+1)
+
+	for _, w := range manifest.Widths {
+		u, err := url.JoinPath(assetBase, variantPath(assetPath, w))
+		if err != nil {
+			continue
+		}
+
+		candidates = append(candidates, fmt.Sprintf("%s %dw", u, w))
+	}
+
+	candidates = append(candidates, fmt.Sprintf("%s %dw", srcPath, manifest.Width))
+
+	return fmt.Sprintf(`srcset="%s" sizes="(max-width: %dpx) 100vw, %dpx" `,
+		strings.Join(candidates, ", "), manifest.Width, manifest.Width)
+}
@@ -0,0 +1,203 @@
+//go:build !compile
+
+package core
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// testPNG returns a PNG-encoded image of the given size.
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			img.Set(x, y, color.RGBA{R: uint8(x % 256), G: uint8(y % 256), B: 128, A: 255}) //nolint:gosec // test pattern
+		}
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+
+	return buf.Bytes()
+}
+
+func TestVariantOrigin(t *testing.T) {
+	tests := []struct {
+		path       string
+		wantOrigin string
+		wantOK     bool
+	}{
+		{path: "images/a.png"},
+		{path: "_variants/480/images/a.png", wantOrigin: "images/a.png", wantOK: true},
+		{path: "_variants/images/a.png.json", wantOrigin: "images/a.png", wantOK: true},
+		{path: "_variants/readme.txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			origin, ok := variantOrigin(tt.path)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantOrigin, origin)
+		})
+	}
+}
+
+func TestGenerateImageVariants(t *testing.T) {
+	variants, width, err := generateImageVariants(testPNG(t, 1000, 500))
+	require.NoError(t, err)
+
+	assert.Equal(t, 1000, width)
+	require.Len(t, variants, 2)
+
+	for _, w := range []int{480, 960} {
+		cfg, format, err := image.DecodeConfig(bytes.NewReader(variants[w]))
+		require.NoError(t, err)
+		assert.Equal(t, "png", format)
+		assert.Equal(t, w, cfg.Width)
+		assert.Equal(t, w/2, cfg.Height)
+	}
+
+	_, _, err = generateImageVariants([]byte("not an image"))
+	assert.Error(t, err)
+}
+
+func TestGenerateImageVariants_PixelLimit(t *testing.T) {
+	// A PNG header declaring a huge image is refused before its pixels are decoded.
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1))))
+
+	// Patch the width and height of the IHDR chunk to 16384x16384 and its checksum.
+	data := buf.Bytes()
+	copy(data[16:24], []byte{0, 0, 0x40, 0, 0, 0, 0x40, 0})
+	binary.BigEndian.PutUint32(data[29:33], crc32.ChecksumIEEE(data[12:29]))
+
+	_, _, err := generateImageVariants(data)
+	assert.ErrorContains(t, err, "exceeds the limit")
+}
+
+func TestIngestDocuments_UpsertImageStoresVariants(t *testing.T) {
+	svc, store, _, _ := newTestService(t)
+
+	data := testPNG(t, 600, 300)
+
+	store.EXPECT().SaveAsset(mock.Anything, "owner/repo", "img/shot.png", data).Return(nil)
+	store.EXPECT().SaveAsset(mock.Anything, "owner/repo", "_variants/480/img/shot.png", mock.Anything).Return(nil)
+	store.EXPECT().DeleteAsset(mock.Anything, "owner/repo", "_variants/960/img/shot.png").Return(nil)
+	store.EXPECT().DeleteAsset(mock.Anything, "owner/repo", "_variants/1600/img/shot.png").Return(nil)
+	store.EXPECT().SaveAsset(mock.Anything, "owner/repo", "_variants/img/shot.png.json", []byte(`{"widths":[480],"width":600}`)).Return(nil)
+
+	resp, err := svc.IngestDocuments(t.Context(), &IngestRequest{
+		Repo: "owner/repo",
+		Assets: &[]IngestAsset{
+			{Path: "img/shot.png", Content: base64.StdEncoding.EncodeToString(data), Action: "upsert"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, resp.AssetsStored)
+}
+
+func TestIngestDocuments_SyncKeepsVariantsOfKeptAssets(t *testing.T) {
+	svc, store, search, _ := newTestService(t)
+
+	store.EXPECT().List(mock.Anything, "owner/repo").Return(nil, nil)
+	search.EXPECT().ListByRepo(mock.Anything, "owner/repo").Return(nil, nil)
+	store.EXPECT().SaveAsset(mock.Anything, "owner/repo", "keep.svg", []byte("data")).Return(nil)
+	store.EXPECT().ListAssets(mock.Anything, "owner/repo").Return([]string{
+		"keep.svg",
+		"_variants/480/keep.svg",
+		"_variants/480/gone.png",
+		"_variants/gone.png.json",
+	}, nil)
+	store.EXPECT().DeleteAsset(mock.Anything, "owner/repo", "_variants/480/gone.png").Return(nil)
+	store.EXPECT().DeleteAsset(mock.Anything, "owner/repo", "_variants/gone.png.json").Return(nil)
+
+	resp, err := svc.IngestDocuments(t.Context(), &IngestRequest{
+		Repo: "owner/repo",
+		Sync: true,
+		Assets: &[]IngestAsset{
+			{Path: "keep.svg", Content: "ZGF0YQ==", Action: "upsert"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0, resp.AssetsDeleted)
+}
+
+func TestAddResponsiveImages(t *testing.T) {
+	images := map[string]ImageVariants{
+		"docs/big.png": {Widths: []int{480, 960}, Width: 1200},
+	}
+
+	html := []byte(`<img src="/assets/owner/repo/docs/big.png" alt="big">` +
+		`<img src="/assets/owner/repo/docs/small.png" alt="small">` +
+		`<img src="/assets/owner/repo/docs/icon.svg" alt="icon">` +
+		`<img src="https://example.com/x.png" alt="remote">`)
+
+	got := string(addResponsiveImages("owner/repo", html, images))
+
+	assert.Contains(t, got, `<img loading="lazy" decoding="async" srcset="/assets/owner/repo/_variants/480/docs/big.png 480w, `+
+		`/assets/owner/repo/_variants/960/docs/big.png 960w, /assets/owner/repo/docs/big.png 1200w" `+
+		`sizes="(max-width: 1200px) 100vw, 1200px" src="/assets/owner/repo/docs/big.png" alt="big">`)
+	assert.Contains(t, got, `<img loading="lazy" decoding="async" src="/assets/owner/repo/docs/small.png" alt="small">`)
+	assert.Contains(t, got, `<img loading="lazy" decoding="async" src="/assets/owner/repo/docs/icon.svg" alt="icon">`)
+	assert.Contains(t, got, `<img src="https://example.com/x.png" alt="remote">`)
+}
+
+func TestImageRefs(t *testing.T) {
+	content := "# Guide\n" +
+		"![arch](images/arch.png \"Architecture\") and ![again](./images/arch.png)\n" +
+		"[a link](images/linked.png), ![icon](icon.svg), ![remote](https://example.com/x.png)\n" +
+		"<img src=\"../shared/photo.JPG\" alt=\"photo\">\n" +
+		"```\n![code](images/code.png)\n```\n" +
+		"![outside](../../escape.png)\n"
+
+	assert.Equal(t, []string{"docs/images/arch.png", "shared/photo.JPG"}, imageRefs("docs/guide.md", content))
+}
+
+func TestIngestDocuments_RecordsImageVariants(t *testing.T) {
+	svc, store, search, processor := newTestService(t)
+
+	content := "# Guide\n![big](img/big.png) ![small](img/small.png)"
+
+	processor.EXPECT().ExtractTitle([]byte(content)).Return("Guide")
+	processor.EXPECT().ToPlainText([]byte(content)).Return("Guide")
+	processor.EXPECT().ExtractHeadings([]byte(content)).Return(nil)
+	processor.EXPECT().ExtractCodeBlocks([]byte(content)).Return(nil)
+	store.EXPECT().GetAsset(mock.Anything, "owner/repo", "_variants/img/big.png.json").
+		Return([]byte(`{"widths":[480,960],"width":1200}`), nil)
+	store.EXPECT().GetAsset(mock.Anything, "owner/repo", "_variants/img/small.png.json").Return(nil, ErrNotFound)
+	store.EXPECT().Save(mock.Anything, mock.MatchedBy(func(doc Document) bool {
+		return assert.Equal(t, map[string]ImageVariants{"img/big.png": {Widths: []int{480, 960}, Width: 1200}}, doc.Images)
+	})).Return(nil)
+	search.EXPECT().Index(mock.Anything, mock.Anything, "Guide", mock.Anything, mock.Anything).Return(nil)
+
+	_, err := svc.IngestDocuments(t.Context(), &IngestRequest{
+		Repo:      "owner/repo",
+		Documents: []IngestDocument{{Path: "guide.md", Content: content, Action: "upsert"}},
+	})
+	require.NoError(t, err)
+}
+
+func TestIngestDocuments_RejectsVariantAssetPaths(t *testing.T) {
+	svc, _, _, _ := newTestService(t)
+
+	for _, p := range []string{"_variants/480/img/a.png", "_Variants/img/a.png.json", "_variants"} {
+		_, err := svc.IngestDocuments(t.Context(), &IngestRequest{
+			Repo:   "owner/repo",
+			Assets: &[]IngestAsset{{Path: p, Content: "ZGF0YQ==", Action: "upsert"}},
+		})
+		assert.ErrorIs(t, err, ErrInvalidPath, p)
+	}
+}
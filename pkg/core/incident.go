@@ -0,0 +1,213 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// presenceTTL is how long a viewer counts as active after its last heartbeat.
+const presenceTTL = 45 * time.Second
+
+// maxViewers caps the number of active viewers tracked per incident document.
+const maxViewers = 100
+
+// Incident flags a document, typically a runbook, as in use during an incident. Readers of
+// a flagged document see who else is reading it and which section they are on.
+type Incident struct {
+	StartedAt time.Time `json:"started_at"`
+	Repo      string    `json:"repo"`
+	Path      string    `json:"path"`
+	Name      string    `json:"name,omitempty"`
+}
+
+// Viewer is a reader of an incident document.
+type Viewer struct {
+	seen    time.Time
+	ID      string `json:"id"`
+	Name    string `json:"name,omitempty"`
+	Section string `json:"section,omitempty"` // heading ID of the section being read
+}
+
+// activeIncident is a flagged document with its active viewers by viewer ID.
+type activeIncident struct {
+	viewers map[string]Viewer
+	Incident
+}
+
+// incidents holds the flagged documents by document ID. They are kept in memory and are
+// lost on restart.
+type incidents struct {
+	active map[string]*activeIncident
+	mu     sync.Mutex
+}
+
+// StartIncident flags a document as in use during the named incident. Flagging a document
+// again renames the incident. It returns ErrNotFound if the document does not exist.
+func (s *Service) StartIncident(ctx context.Context, repo, path, name string) (*Incident, error) {
+	if _, err := s.store.Get(ctx, repo, path); err != nil {
+		return nil, fmt.Errorf("failed to get document: %w", err)
+	}
+
+	docID := NewDocumentID(repo, path).String()
+
+	s.incidents.mu.Lock()
+
+	if s.incidents.active == nil {
+		s.incidents.active = make(map[string]*activeIncident)
+	}
+
+	inc, ok := s.incidents.active[docID]
+	if !ok {
+		inc = &activeIncident{
+			Incident: Incident{Repo: repo, Path: path, StartedAt: time.Now().UTC()},
+			viewers:  make(map[string]Viewer),
+		}
+		s.incidents.active[docID] = inc
+	}
+
+	inc.Name = name
+	started := inc.Incident
+
+	s.incidents.mu.Unlock()
+
+	slog.InfoContext(ctx, "incident started", "repo", repo, "path", path, "name", name)
+	s.broadcast(DocumentEvent{Type: EventIncidentStarted, Repo: repo, Path: path, Incident: name})
+
+	return &started, nil
+}
+
+// EndIncident removes the incident flag of a document. It returns ErrNotFound if the
+// document is not flagged.
+func (s *Service) EndIncident(ctx context.Context, repo, path string) error {
+	docID := NewDocumentID(repo, path).String()
+
+	s.incidents.mu.Lock()
+	_, ok := s.incidents.active[docID]
+	delete(s.incidents.active, docID)
+	s.incidents.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("%w: no incident for document %s", ErrNotFound, docID)
+	}
+
+	slog.InfoContext(ctx, "incident ended", "repo", repo, "path", path)
+	s.publishEvent(EventIncidentEnded, repo, path)
+
+	return nil
+}
+
+// ListIncidents returns the flagged documents, oldest incident first.
+func (s *Service) ListIncidents(_ context.Context) []Incident {
+	s.incidents.mu.Lock()
+	defer s.incidents.mu.Unlock()
+
+	list := make([]Incident, 0, len(s.incidents.active))
+	for _, inc := range s.incidents.active {
+		list = append(list, inc.Incident)
+	}
+
+	slices.SortFunc(list, func(a, b Incident) int {
+		return a.StartedAt.Compare(b.StartedAt)
+	})
+
+	return list
+}
+
+// Presence returns the incident of a flagged document with its active viewers. It returns
+// ErrNotFound if the document is not flagged.
+func (s *Service) Presence(_ context.Context, repo, path string) (*Incident, []Viewer, error) {
+	s.incidents.mu.Lock()
+	defer s.incidents.mu.Unlock()
+
+	inc, ok := s.incidents.active[NewDocumentID(repo, path).String()]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: no incident for document %s/%s", ErrNotFound, repo, path)
+	}
+
+	inc.prune(time.Now())
+	incident := inc.Incident
+
+	return &incident, inc.list(), nil
+}
+
+// UpdatePresence records a heartbeat of a viewer of a flagged document, or its departure
+// when leave is set, and notifies subscribers when the viewers or their sections changed.
+// It returns ErrNotFound if the document is not flagged and ErrLimitExceeded if the
+// document already has the maximum number of viewers.
+func (s *Service) UpdatePresence(_ context.Context, repo, path string, viewer Viewer, leave bool) error {
+	s.incidents.mu.Lock()
+
+	inc, ok := s.incidents.active[NewDocumentID(repo, path).String()]
+	if !ok {
+		s.incidents.mu.Unlock()
+		return fmt.Errorf("%w: no incident for document %s/%s", ErrNotFound, repo, path)
+	}
+
+	now := time.Now()
+	changed := inc.prune(now)
+	prev, known := inc.viewers[viewer.ID]
+
+	switch {
+	case leave:
+		delete(inc.viewers, viewer.ID)
+
+		changed = changed || known
+	case !known && len(inc.viewers) >= maxViewers:
+		s.incidents.mu.Unlock()
+		return fmt.Errorf("%w: document %s/%s has %d viewers", ErrLimitExceeded, repo, path, maxViewers)
+	default:
+		viewer.seen = now
+		inc.viewers[viewer.ID] = viewer
+
+		changed = changed || !known || prev.Name != viewer.Name || prev.Section != viewer.Section
+	}
+
+	viewers := inc.list()
+
+	s.incidents.mu.Unlock()
+
+	if changed {
+		s.broadcast(DocumentEvent{Type: EventPresence, Repo: repo, Path: path, Viewers: viewers})
+	}
+
+	return nil
+}
+
+// prune drops viewers whose last heartbeat is older than presenceTTL and reports whether
+// any were dropped.
+func (inc *activeIncident) prune(now time.Time) bool {
+	pruned := false
+
+	for id, v := range inc.viewers {
+		if now.Sub(v.seen) > presenceTTL {
+			delete(inc.viewers, id)
+
+			pruned = true
+		}
+	}
+
+	return pruned
+}
+
+// list returns the viewers ordered by name, then ID.
+func (inc *activeIncident) list() []Viewer {
+	viewers := make([]Viewer, 0, len(inc.viewers))
+	for _, v := range inc.viewers {
+		viewers = append(viewers, v)
+	}
+
+	slices.SortFunc(viewers, func(a, b Viewer) int {
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	return viewers
+}
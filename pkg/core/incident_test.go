@@ -0,0 +1,118 @@
+//go:build !compile
+
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIncidentLifecycle(t *testing.T) {
+	svc, store, _, _ := newTestService(t)
+
+	events := svc.Subscribe(t.Context())
+
+	store.EXPECT().Get(mock.Anything, "owner/repo", "runbook.md").Return(Document{Repo: "owner/repo", Path: "runbook.md"}, nil)
+	store.EXPECT().Get(mock.Anything, "owner/repo", "missing.md").Return(Document{}, ErrNotFound)
+
+	_, err := svc.StartIncident(t.Context(), "owner/repo", "missing.md", "INC-1")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	inc, err := svc.StartIncident(t.Context(), "owner/repo", "runbook.md", "INC-1")
+	require.NoError(t, err)
+	assert.Equal(t, "INC-1", inc.Name)
+	assert.False(t, inc.StartedAt.IsZero())
+
+	ev := <-events
+	assert.Equal(t, DocumentEvent{At: ev.At, Type: EventIncidentStarted, Repo: "owner/repo", Path: "runbook.md", Incident: "INC-1"}, ev)
+
+	renamed, err := svc.StartIncident(t.Context(), "owner/repo", "runbook.md", "INC-2")
+	require.NoError(t, err)
+	assert.Equal(t, inc.StartedAt, renamed.StartedAt, "flagging again keeps the incident")
+	<-events
+
+	assert.Equal(t, []Incident{*renamed}, svc.ListIncidents(t.Context()))
+
+	require.NoError(t, svc.EndIncident(t.Context(), "owner/repo", "runbook.md"))
+	assert.Equal(t, EventIncidentEnded, (<-events).Type)
+	assert.Empty(t, svc.ListIncidents(t.Context()))
+
+	require.ErrorIs(t, svc.EndIncident(t.Context(), "owner/repo", "runbook.md"), ErrNotFound)
+}
+
+func TestUpdatePresence(t *testing.T) {
+	svc, store, _, _ := newTestService(t)
+
+	store.EXPECT().Get(mock.Anything, "owner/repo", "runbook.md").Return(Document{Repo: "owner/repo", Path: "runbook.md"}, nil)
+
+	require.ErrorIs(t, svc.UpdatePresence(t.Context(), "owner/repo", "runbook.md", Viewer{ID: "a"}, false), ErrNotFound)
+
+	_, err := svc.StartIncident(t.Context(), "owner/repo", "runbook.md", "INC-1")
+	require.NoError(t, err)
+
+	events := svc.Subscribe(t.Context())
+
+	require.NoError(t, svc.UpdatePresence(t.Context(), "owner/repo", "runbook.md", Viewer{ID: "b", Name: "Sam", Section: "restart"}, false))
+	require.NoError(t, svc.UpdatePresence(t.Context(), "owner/repo", "runbook.md", Viewer{ID: "a", Name: "Alex", Section: "triage"}, false))
+
+	ev := <-events
+	assert.Equal(t, EventPresence, ev.Type)
+	assert.Len(t, ev.Viewers, 1)
+
+	ev = <-events
+	require.Len(t, ev.Viewers, 2)
+	assert.Equal(t, "Alex", ev.Viewers[0].Name, "viewers are ordered by name")
+	assert.Equal(t, "triage", ev.Viewers[0].Section)
+
+	require.NoError(t, svc.UpdatePresence(t.Context(), "owner/repo", "runbook.md", Viewer{ID: "a", Name: "Alex", Section: "triage"}, false))
+	assert.Empty(t, events, "heartbeats without changes are not broadcast")
+
+	require.NoError(t, svc.UpdatePresence(t.Context(), "owner/repo", "runbook.md", Viewer{ID: "b"}, true))
+
+	ev = <-events
+	assert.Equal(t, []string{"a"}, viewerIDs(ev.Viewers))
+
+	svc.incidents.mu.Lock()
+	inc := svc.incidents.active["owner/repo/runbook.md"]
+	v := inc.viewers["a"]
+	v.seen = time.Now().Add(-2 * presenceTTL)
+	inc.viewers["a"] = v
+	svc.incidents.mu.Unlock()
+
+	_, viewers, err := svc.Presence(t.Context(), "owner/repo", "runbook.md")
+	require.NoError(t, err)
+	assert.Empty(t, viewers, "viewers without recent heartbeats expire")
+
+	_, _, err = svc.Presence(t.Context(), "owner/repo", "other.md")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestUpdatePresence_Limit(t *testing.T) {
+	svc, store, _, _ := newTestService(t)
+
+	store.EXPECT().Get(mock.Anything, "owner/repo", "runbook.md").Return(Document{Repo: "owner/repo", Path: "runbook.md"}, nil)
+
+	_, err := svc.StartIncident(t.Context(), "owner/repo", "runbook.md", "")
+	require.NoError(t, err)
+
+	for i := range maxViewers {
+		require.NoError(t, svc.UpdatePresence(t.Context(), "owner/repo", "runbook.md", Viewer{ID: string(rune('A' + i))}, false))
+	}
+
+	require.ErrorIs(t, svc.UpdatePresence(t.Context(), "owner/repo", "runbook.md", Viewer{ID: "late"}, false), ErrLimitExceeded)
+	require.NoError(t, svc.UpdatePresence(t.Context(), "owner/repo", "runbook.md", Viewer{ID: "A", Section: "step-2"}, false), "known viewers keep updating")
+}
+
+// viewerIDs returns the IDs of the viewers.
+func viewerIDs(viewers []Viewer) []string {
+	ids := make([]string, 0, len(viewers))
+	for _, v := range viewers {
+		ids = append(ids, v.ID)
+	}
+
+	return ids
+}
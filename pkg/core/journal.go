@@ -0,0 +1,114 @@
+package core
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// Change actions recorded in the change journal.
+const (
+	ChangeAdded   = "added"
+	ChangeUpdated = "updated"
+	ChangeDeleted = "deleted"
+)
+
+// Change is a document added, updated or deleted by an ingest request.
+type Change struct {
+	Time   time.Time `json:"time"`
+	Repo   string    `json:"repo"`
+	Path   string    `json:"path"`
+	Action string    `json:"action"`
+}
+
+// ChangeJournal records the document changes made by ingest requests.
+type ChangeJournal interface {
+	Append(ctx context.Context, changes []Change) error
+	// Since returns the changes recorded at or after t, oldest first.
+	Since(ctx context.Context, t time.Time) ([]Change, error)
+}
+
+// WithChangeJournal records the documents added, updated and deleted by each ingest request
+// in the given journal.
+func WithChangeJournal(j ChangeJournal) Option {
+	return func(s *Service) {
+		s.journal = j
+	}
+}
+
+// journalSnapshot returns the paths of the stored documents of repo before an ingest
+// request, to tell added from updated documents afterwards. It returns nil if there is no
+// journal or the documents cannot be listed, in which case the request is not journaled.
+func (s *Service) journalSnapshot(ctx context.Context, repo string) map[string]struct{} {
+	if s.journal == nil {
+		return nil
+	}
+
+	docs, err := s.store.List(ctx, repo)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to list documents for change journal", "repo", repo, "error", err)
+		return nil
+	}
+
+	paths := make(map[string]struct{}, len(docs))
+	for _, d := range docs {
+		paths[d.Path] = struct{}{}
+	}
+
+	return paths
+}
+
+// recordChanges journals the documents an ingest request upserted, as added or updated
+// depending on whether they were stored before, and the documents it deleted, explicitly
+// or by sync. Failures are logged, since the documents are already published.
+func (s *Service) recordChanges(ctx context.Context, repo string, before map[string]struct{}, upserted []string) {
+	if s.journal == nil || before == nil {
+		return
+	}
+
+	docs, err := s.store.List(ctx, repo)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to list documents for change journal", "repo", repo, "error", err)
+		return
+	}
+
+	now := time.Now().UTC()
+	changes := make([]Change, 0, len(upserted))
+
+	for _, p := range upserted {
+		action := ChangeUpdated
+		if _, ok := before[p]; !ok {
+			action = ChangeAdded
+		}
+
+		changes = append(changes, Change{Time: now, Repo: repo, Path: p, Action: action})
+	}
+
+	after := make(map[string]struct{}, len(docs))
+	for _, d := range docs {
+		after[d.Path] = struct{}{}
+	}
+
+	var deleted []string
+
+	for p := range before {
+		if _, ok := after[p]; !ok {
+			deleted = append(deleted, p)
+		}
+	}
+
+	sort.Strings(deleted)
+
+	for _, p := range deleted {
+		changes = append(changes, Change{Time: now, Repo: repo, Path: p, Action: ChangeDeleted})
+	}
+
+	if len(changes) == 0 {
+		return
+	}
+
+	if err := s.journal.Append(ctx, changes); err != nil {
+		slog.WarnContext(ctx, "failed to record document changes", "repo", repo, "error", err)
+	}
+}
@@ -0,0 +1,80 @@
+//go:build !compile
+
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// memJournal is an in-memory ChangeJournal.
+type memJournal struct {
+	changes []Change
+}
+
+func (j *memJournal) Append(_ context.Context, changes []Change) error {
+	j.changes = append(j.changes, changes...)
+	return nil
+}
+
+func (j *memJournal) Since(_ context.Context, t time.Time) ([]Change, error) {
+	var changes []Change
+
+	for _, c := range j.changes {
+		if !c.Time.Before(t) {
+			changes = append(changes, c)
+		}
+	}
+
+	return changes, nil
+}
+
+func TestIngestDocuments_RecordsChanges(t *testing.T) {
+	svc, store, search, processor := newTestService(t)
+
+	journal := &memJournal{}
+	WithChangeJournal(journal)(svc)
+
+	store.EXPECT().List(mock.Anything, "owner/repo").Return([]DocumentMeta{{Path: "guide.md"}, {Path: "old.md"}}, nil).Once()
+	store.EXPECT().List(mock.Anything, "owner/repo").Return([]DocumentMeta{{Path: "guide.md"}, {Path: "new.md"}}, nil).Once()
+
+	processor.EXPECT().ExtractTitle(mock.Anything).Return("Title")
+	processor.EXPECT().ToPlainText(mock.Anything).Return("text")
+	processor.EXPECT().ExtractCodeBlocks(mock.Anything).Return(nil)
+	processor.EXPECT().ExtractHeadings(mock.Anything).Return(nil)
+	store.EXPECT().Save(mock.Anything, mock.Anything).Return(nil)
+	search.EXPECT().Index(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	search.EXPECT().Remove(mock.Anything, "owner/repo/old.md").Return(nil)
+	store.EXPECT().Delete(mock.Anything, "owner/repo", "old.md").Return(nil)
+
+	_, err := svc.IngestDocuments(t.Context(), &IngestRequest{
+		Repo: "owner/repo",
+		Documents: []IngestDocument{
+			{Path: "guide.md", Content: "# Guide", Action: actionUpsert},
+			{Path: "new.md", Content: "# New", Action: actionUpsert},
+			{Path: "old.md", Action: actionDelete},
+		},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, journal.changes, 3)
+
+	actions := make(map[string]string, len(journal.changes))
+	for _, c := range journal.changes {
+		assert.Equal(t, "owner/repo", c.Repo)
+		assert.False(t, c.Time.IsZero())
+
+		actions[c.Path] = c.Action
+	}
+
+	assert.Equal(t, map[string]string{
+		"guide.md": ChangeUpdated,
+		"new.md":   ChangeAdded,
+		"old.md":   ChangeDeleted,
+	}, actions)
+}
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"strings"
+)
+
+// readmeFileName is the root document used as a repository's landing page when no
+// document declares itself as the home page.
+const readmeFileName = "readme.md"
+
+// RepoLanding is the rendered landing document shown on a repository's index page.
+type RepoLanding struct {
+	HTML     []byte
+	Headings []Heading
+	Doc      Document
+}
+
+// isHomeDocument reports whether markdown content declares "home: true" in its YAML
+// frontmatter.
+func isHomeDocument(content string) bool {
+	home, _ := parseFrontmatter(content)["home"].(bool)
+
+	return home
+}
+
+// LandingDocument picks the document rendered at a repository's root URL: a markdown
+// document whose frontmatter sets "home: true", or otherwise the root README.md. When
+// several documents are marked as home, the one closest to the root wins, ties broken
+// by path. The second return value is false if the repository has no landing document.
+func LandingDocument(docs []DocumentMeta) (DocumentMeta, bool) {
+	var (
+		landing DocumentMeta
+		found   bool
+	)
+
+	for _, doc := range docs {
+		if !doc.Home || doc.ContentType != ContentTypeMarkdown {
+			continue
+		}
+
+		if !found || closerToRoot(doc.Path, landing.Path) {
+			landing, found = doc, true
+		}
+	}
+
+	if found {
+		return landing, true
+	}
+
+	for _, doc := range docs {
+		if strings.EqualFold(doc.Path, readmeFileName) && doc.ContentType == ContentTypeMarkdown {
+			return doc, true
+		}
+	}
+
+	return DocumentMeta{}, false
+}
+
+// closerToRoot reports whether path a is nested less deeply than b, or equally deep and
+// sorted first.
+func closerToRoot(a, b string) bool {
+	da, db := strings.Count(a, "/"), strings.Count(b, "/")
+	if da != db {
+		return da < db
+	}
+
+	return a < b
+}
@@ -0,0 +1,48 @@
+//go:build !compile
+
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsHomeDocument(t *testing.T) {
+	assert.True(t, isHomeDocument("---\nhome: true\n---\n# Welcome\n"))
+	assert.False(t, isHomeDocument("---\nhome: false\n---\n# Welcome\n"))
+	assert.False(t, isHomeDocument("---\nhome: \"yes\"\n---\n# Welcome\n"))
+	assert.False(t, isHomeDocument("# Welcome\n"))
+}
+
+func TestLandingDocument(t *testing.T) {
+	readme := DocumentMeta{Path: "README.md", ContentType: ContentTypeMarkdown}
+	nestedReadme := DocumentMeta{Path: "docs/readme.md", ContentType: ContentTypeMarkdown}
+	guide := DocumentMeta{Path: "docs/guide.md", ContentType: ContentTypeMarkdown}
+	homeDeep := DocumentMeta{Path: "docs/intro/start.md", ContentType: ContentTypeMarkdown, Home: true}
+	homeB := DocumentMeta{Path: "docs/b.md", ContentType: ContentTypeMarkdown, Home: true}
+	homeA := DocumentMeta{Path: "docs/a.md", ContentType: ContentTypeMarkdown, Home: true}
+	homeSpec := DocumentMeta{Path: "api.yaml", ContentType: ContentTypeOpenAPI, Home: true}
+
+	tests := []struct {
+		name   string
+		docs   []DocumentMeta
+		want   DocumentMeta
+		wantOK bool
+	}{
+		{name: "root readme", docs: []DocumentMeta{guide, readme}, want: readme, wantOK: true},
+		{name: "nested readme ignored", docs: []DocumentMeta{guide, nestedReadme}},
+		{name: "home wins over readme", docs: []DocumentMeta{readme, homeDeep}, want: homeDeep, wantOK: true},
+		{name: "closest home wins", docs: []DocumentMeta{homeDeep, homeB, homeA}, want: homeA, wantOK: true},
+		{name: "non-markdown home ignored", docs: []DocumentMeta{homeSpec, readme}, want: readme, wantOK: true},
+		{name: "none"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := LandingDocument(tt.docs)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
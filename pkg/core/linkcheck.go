@@ -0,0 +1,173 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LinkChecker checks whether an external URL still resolves. CheckLink returns an error
+// describing why the link is dead, or nil if it is alive or its state is unknown, for
+// example because the site rate limited the check.
+type LinkChecker interface {
+	CheckLink(ctx context.Context, rawURL string) error
+}
+
+// DeadLink is an external link of a document that failed its last check.
+type DeadLink struct {
+	Path  string `json:"path"`
+	URL   string `json:"url"`
+	Error string `json:"error"`
+	Line  int    `json:"line"`
+}
+
+// LinkReport is the outcome of the latest external link check of a repository.
+type LinkReport struct {
+	CheckedAt time.Time  `json:"checked_at"`
+	Repo      string     `json:"repo"`
+	DeadLinks []DeadLink `json:"dead_links"`
+	Checked   int        `json:"checked"`
+}
+
+// linkChecks holds the external link checker and the latest report per repository.
+// Reports are kept in memory and are lost on restart.
+type linkChecks struct {
+	checker LinkChecker
+	reports map[string]LinkReport
+	mu      sync.Mutex
+}
+
+// WithLinkChecker enables CheckExternalLinks using the given checker.
+func WithLinkChecker(c LinkChecker) Option {
+	return func(s *Service) {
+		s.links = &linkChecks{checker: c, reports: make(map[string]LinkReport)}
+	}
+}
+
+// autolinkRe matches markdown autolinks to http(s) URLs, capturing the URL.
+var autolinkRe = regexp.MustCompile(`<(https?://[^>\s]+)>`)
+
+// externalLink is an http(s) link found in a document.
+type externalLink struct {
+	url  string
+	line int
+}
+
+// CheckExternalLinks checks the external http(s) links of every stored markdown document
+// and records a report per repository. Each distinct URL is checked once per run, however
+// many documents link to it. It returns an error wrapping ErrNotSupported if no link
+// checker is configured.
+func (s *Service) CheckExternalLinks(ctx context.Context) error {
+	if s.links == nil {
+		return fmt.Errorf("%w: no link checker is configured", ErrNotSupported)
+	}
+
+	repos, err := s.store.ListRepos(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list repos: %w", err)
+	}
+
+	results := make(map[string]error)
+
+	for _, repo := range repos {
+		report, err := s.checkRepoLinks(ctx, repo.Name, results)
+		if err != nil {
+			return err
+		}
+
+		s.links.mu.Lock()
+		s.links.reports[repo.Name] = report
+		s.links.mu.Unlock()
+	}
+
+	return nil
+}
+
+// checkRepoLinks checks the external links of the markdown documents of a repository.
+// Results are shared through results, keyed by URL, so that a URL is checked only once.
+func (s *Service) checkRepoLinks(ctx context.Context, repo string, results map[string]error) (LinkReport, error) {
+	docs, err := s.store.List(ctx, repo)
+	if err != nil {
+		return LinkReport{}, fmt.Errorf("failed to list documents for repo %s: %w", repo, err)
+	}
+
+	report := LinkReport{Repo: repo, DeadLinks: []DeadLink{}}
+
+	for _, meta := range docs {
+		if meta.ContentType != "" && meta.ContentType != ContentTypeMarkdown {
+			continue
+		}
+
+		doc, err := s.store.Get(ctx, repo, meta.Path)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to load document for link check", "repo", repo, "path", meta.Path, "error", err)
+			continue
+		}
+
+		for _, link := range externalLinks(doc.Content) {
+			if err := ctx.Err(); err != nil {
+				return LinkReport{}, fmt.Errorf("link check cancelled: %w", err)
+			}
+
+			checkErr, ok := results[link.url]
+			if !ok {
+				checkErr = s.links.checker.CheckLink(ctx, link.url)
+				results[link.url] = checkErr
+			}
+
+			report.Checked++
+
+			if checkErr != nil {
+				report.DeadLinks = append(report.DeadLinks, DeadLink{Path: meta.Path, URL: link.url, Line: link.line, Error: checkErr.Error()})
+			}
+		}
+	}
+
+	report.CheckedAt = time.Now().UTC()
+
+	return report, nil
+}
+
+// externalLinks returns the http(s) links of markdown content outside code blocks.
+func externalLinks(content string) []externalLink {
+	var links []externalLink
+
+	scanProse(content, func(lineNo int, line string) {
+		for _, m := range mdLinkRe.FindAllStringSubmatch(line, -1) {
+			if strings.HasPrefix(m[1], "http://") || strings.HasPrefix(m[1], "https://") {
+				links = append(links, externalLink{url: m[1], line: lineNo})
+			}
+		}
+
+		for _, m := range autolinkRe.FindAllStringSubmatch(line, -1) {
+			links = append(links, externalLink{url: m[1], line: lineNo})
+		}
+	})
+
+	return links
+}
+
+// LinkReports returns the latest external link report of every repository checked since
+// the server started, ordered by repository. It returns nil when link checking is disabled.
+func (s *Service) LinkReports(_ context.Context) []LinkReport {
+	if s.links == nil {
+		return nil
+	}
+
+	s.links.mu.Lock()
+	defer s.links.mu.Unlock()
+
+	reports := make([]LinkReport, 0, len(s.links.reports))
+	for _, r := range s.links.reports {
+		reports = append(reports, r)
+	}
+
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Repo < reports[j].Repo })
+
+	return reports
+}
@@ -0,0 +1,85 @@
+//go:build !compile
+
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// linkCheckFunc adapts a function to the LinkChecker interface.
+type linkCheckFunc func(ctx context.Context, rawURL string) error
+
+func (f linkCheckFunc) CheckLink(ctx context.Context, rawURL string) error {
+	return f(ctx, rawURL)
+}
+
+func TestExternalLinks(t *testing.T) {
+	content := "# Title\n\nSee [docs](https://example.com/docs) and <http://example.org>.\n\n" +
+		"```\n[skipped](https://example.com/code)\n```\n\n[relative](other.md) [mail](mailto:a@example.com)\n"
+
+	assert.Equal(t, []externalLink{
+		{url: "https://example.com/docs", line: 3},
+		{url: "http://example.org", line: 3},
+	}, externalLinks(content))
+}
+
+func TestCheckExternalLinks(t *testing.T) {
+	store := NewMockdocStore(t)
+
+	checked := make(map[string]int)
+
+	svc := New(store, NewMocksearchEngine(t), map[ContentType]ContentProcessor{
+		ContentTypeMarkdown: NewMockContentProcessor(t),
+	}, WithLinkChecker(linkCheckFunc(func(_ context.Context, rawURL string) error {
+		checked[rawURL]++
+
+		if rawURL == "https://example.com/gone" {
+			return errors.New("HTTP 404")
+		}
+
+		return nil
+	})))
+
+	assert.Empty(t, svc.LinkReports(t.Context()))
+
+	store.EXPECT().ListRepos(mock.Anything).Return([]RepoInfo{{Name: "owner/a"}, {Name: "owner/b"}}, nil)
+	store.EXPECT().List(mock.Anything, "owner/a").Return([]DocumentMeta{
+		{Path: "guide.md", ContentType: ContentTypeMarkdown},
+		{Path: "api.yaml", ContentType: ContentTypeOpenAPI},
+	}, nil)
+	store.EXPECT().List(mock.Anything, "owner/b").Return([]DocumentMeta{{Path: "readme.md"}}, nil)
+	store.EXPECT().Get(mock.Anything, "owner/a", "guide.md").Return(Document{
+		Content: "[ok](https://example.com/ok)\n\n[gone](https://example.com/gone)\n",
+	}, nil)
+	store.EXPECT().Get(mock.Anything, "owner/b", "readme.md").Return(Document{
+		Content: "[gone again](https://example.com/gone)\n",
+	}, nil)
+
+	require.NoError(t, svc.CheckExternalLinks(t.Context()))
+
+	assert.Equal(t, map[string]int{"https://example.com/ok": 1, "https://example.com/gone": 1}, checked, "each URL is checked once per run")
+
+	reports := svc.LinkReports(t.Context())
+	require.Len(t, reports, 2)
+
+	assert.Equal(t, "owner/a", reports[0].Repo)
+	assert.Equal(t, 2, reports[0].Checked)
+	assert.Equal(t, []DeadLink{{Path: "guide.md", URL: "https://example.com/gone", Line: 3, Error: "HTTP 404"}}, reports[0].DeadLinks)
+	assert.False(t, reports[0].CheckedAt.IsZero())
+
+	assert.Equal(t, "owner/b", reports[1].Repo)
+	assert.Equal(t, []DeadLink{{Path: "readme.md", URL: "https://example.com/gone", Line: 1, Error: "HTTP 404"}}, reports[1].DeadLinks)
+}
+
+func TestCheckExternalLinks_NotConfigured(t *testing.T) {
+	svc := newTestServiceOnly(t)
+
+	assert.ErrorIs(t, svc.CheckExternalLinks(t.Context()), ErrNotSupported)
+	assert.Nil(t, svc.LinkReports(t.Context()))
+}
@@ -0,0 +1,383 @@
+package core
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Lint modes select how issues found by ingest-time linting are handled.
+const (
+	// LintModeOff disables linting. It is the default.
+	LintModeOff = "off"
+	// LintModeWarn reports issues in the ingest response but stores the documents.
+	LintModeWarn = "warn"
+	// LintModeReject rejects the whole ingest request when any document has issues.
+	LintModeReject = "reject"
+)
+
+// Lint rule identifiers reported in LintIssue.Rule.
+const (
+	LintRuleBrokenLink         = "broken-link"
+	LintRuleMissingH1          = "missing-h1"
+	LintRuleLongCodeLine       = "long-code-line"
+	LintRuleDisallowedWord     = "disallowed-word"
+	LintRuleMissingFrontmatter = "missing-frontmatter"
+)
+
+// LintConfig holds configuration for ingest-time linting of markdown documents.
+// Broken relative links and a missing H1 heading are always checked when linting is
+// enabled; the remaining rules are enabled by their settings.
+type LintConfig struct {
+	Mode                string   `mapstructure:"mode"` // "off" (default), "warn" or "reject".
+	DisallowedWords     []string `mapstructure:"disallowed_words"`
+	RequiredFrontmatter []string `mapstructure:"required_frontmatter"`
+	MaxCodeLineLength   int      `mapstructure:"max_code_line_length"` // 0 disables the check.
+}
+
+// Validate checks that the lint mode is known.
+func (c LintConfig) Validate() error {
+	switch c.Mode {
+	case "", LintModeOff, LintModeWarn, LintModeReject:
+		return nil
+	default:
+		return fmt.Errorf("unknown lint mode %q: must be %q, %q or %q", c.Mode, LintModeOff, LintModeWarn, LintModeReject)
+	}
+}
+
+// LintIssue describes a single problem found in a document during ingest.
+type LintIssue struct {
+	Path    string `json:"path"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+	Line    int    `json:"line,omitempty"`
+}
+
+// LintReport is the outcome of linting the most recent ingest request of a repository.
+type LintReport struct {
+	CheckedAt time.Time   `json:"checked_at"`
+	Repo      string      `json:"repo"`
+	Issues    []LintIssue `json:"issues"`
+	Rejected  bool        `json:"rejected"`
+}
+
+// LintError is returned by IngestDocuments when linting runs in reject mode and the
+// request has issues. No documents are stored in that case.
+type LintError struct {
+	Issues []LintIssue
+}
+
+// Error implements the error interface.
+func (e *LintError) Error() string {
+	return fmt.Sprintf("lint failed with %d issue(s)", len(e.Issues))
+}
+
+// linter holds the lint configuration and the latest report per repository.
+// Reports are kept in memory and are lost on restart.
+type linter struct {
+	disallowed *regexp.Regexp
+	reports    map[string]LintReport
+	cfg        LintConfig
+	mu         sync.Mutex
+}
+
+// WithLint enables ingest-time linting with the given configuration. Linting is
+// disabled when the mode is empty or "off".
+func WithLint(cfg LintConfig) Option {
+	return func(s *Service) {
+		if cfg.Mode == "" || cfg.Mode == LintModeOff {
+			return
+		}
+
+		l := &linter{cfg: cfg, reports: make(map[string]LintReport)}
+
+		words := make([]string, 0, len(cfg.DisallowedWords))
+		for _, w := range cfg.DisallowedWords {
+			if w = strings.TrimSpace(w); w != "" {
+				words = append(words, regexp.QuoteMeta(w))
+			}
+		}
+
+		if len(words) > 0 {
+			l.disallowed = regexp.MustCompile(`(?i)\b(` + strings.Join(words, "|") + `)\b`)
+		}
+
+		s.lint = l
+	}
+}
+
+// mdLinkRe matches inline markdown links and images, capturing the destination.
+var mdLinkRe = regexp.MustCompile(`!?\[[^\]]*\]\(\s*<?([^)\s>]+)>?(?:\s+"[^"]*")?\s*\)`)
+
+// lintRequest lints the markdown documents upserted by an ingest request and records
+// the report for the repository. In reject mode it returns a *LintError when any issue
+// is found. It returns no issues when linting is disabled.
+func (s *Service) lintRequest(ctx context.Context, req *IngestRequest) ([]LintIssue, error) {
+	if s.lint == nil {
+		return nil, nil
+	}
+
+	known, err := s.knownPaths(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	processor := s.getProcessor(ContentTypeMarkdown, req.Repo)
+
+	var issues []LintIssue
+
+	for _, doc := range req.Documents {
+		if doc.Action != actionUpsert || (doc.ContentType != "" && doc.ContentType != ContentTypeMarkdown) {
+			continue
+		}
+
+		// A document that crashes the linter is reported as failed by the upsert.
+		_ = recoverDocument(ctx, req.Repo, doc.Path, func() error {
+			issues = append(issues, s.lint.lintDocument(processor, doc, known)...)
+			return nil
+		})
+	}
+
+	rejected := s.lint.cfg.Mode == LintModeReject && len(issues) > 0
+
+	s.lint.mu.Lock()
+	s.lint.reports[req.Repo] = LintReport{
+		Repo:      req.Repo,
+		CheckedAt: time.Now().UTC(),
+		Issues:    issues,
+		Rejected:  rejected,
+	}
+	s.lint.mu.Unlock()
+
+	if rejected {
+		return nil, &LintError{Issues: issues}
+	}
+
+	return issues, nil
+}
+
+// knownPaths returns the set of document and asset paths that will exist in the
+// repository once the ingest request has been applied, used to resolve relative links.
+func (s *Service) knownPaths(ctx context.Context, req *IngestRequest) (map[string]struct{}, error) {
+	known := make(map[string]struct{})
+
+	// A sync request replaces the repository contents, so only the request matters.
+	if !req.Sync {
+		docs, err := s.store.List(ctx, req.Repo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list documents for repo %s: %w", req.Repo, err)
+		}
+
+		for _, doc := range docs {
+			known[doc.Path] = struct{}{}
+		}
+
+		assets, err := s.store.ListAssets(ctx, req.Repo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list assets for repo %s: %w", req.Repo, err)
+		}
+
+		for _, asset := range assets {
+			known[asset] = struct{}{}
+		}
+	}
+
+	for _, doc := range req.Documents {
+		switch doc.Action {
+		case actionUpsert:
+			known[doc.Path] = struct{}{}
+		case actionDelete:
+			delete(known, doc.Path)
+		}
+	}
+
+	if req.Assets != nil {
+		for _, asset := range *req.Assets {
+			switch asset.Action {
+			case actionUpsert:
+				known[asset.Path] = struct{}{}
+			case actionDelete:
+				delete(known, asset.Path)
+			}
+		}
+	}
+
+	return known, nil
+}
+
+// lintDocument runs the configured rules against a single markdown document.
+func (l *linter) lintDocument(processor ContentProcessor, doc IngestDocument, known map[string]struct{}) []LintIssue {
+	var issues []LintIssue
+
+	add := func(rule string, line int, format string, args ...any) {
+		issues = append(issues, LintIssue{Path: doc.Path, Rule: rule, Line: line, Message: fmt.Sprintf(format, args...)})
+	}
+
+	src := []byte(doc.Content)
+
+	hasH1 := false
+
+	for _, h := range processor.ExtractHeadings(src) {
+		if h.Level == 1 {
+			hasH1 = true
+			break
+		}
+	}
+
+	if !hasH1 {
+		add(LintRuleMissingH1, 0, "document has no top-level heading")
+	}
+
+	if len(l.cfg.RequiredFrontmatter) > 0 {
+		fields := parseFrontmatter(doc.Content)
+
+		for _, name := range l.cfg.RequiredFrontmatter {
+			if _, ok := fields[name]; !ok {
+				add(LintRuleMissingFrontmatter, 1, "frontmatter field %q is missing", name)
+			}
+		}
+	}
+
+	if l.cfg.MaxCodeLineLength > 0 {
+		for _, block := range processor.ExtractCodeBlocks(src) {
+			for _, line := range strings.Split(block.Code, "\n") {
+				if n := len([]rune(line)); n > l.cfg.MaxCodeLineLength {
+					add(LintRuleLongCodeLine, 0, "code line is %d characters long, limit is %d", n, l.cfg.MaxCodeLineLength)
+				}
+			}
+		}
+	}
+
+	scanProse(doc.Content, func(lineNo int, line string) {
+		if l.disallowed != nil {
+			for _, word := range l.disallowed.FindAllString(line, -1) {
+				add(LintRuleDisallowedWord, lineNo, "disallowed word %q", word)
+			}
+		}
+
+		for _, m := range mdLinkRe.FindAllStringSubmatch(line, -1) {
+			if target, ok := resolveLinkTarget(doc.Path, m[1]); ok && !pathExists(known, target) {
+				add(LintRuleBrokenLink, lineNo, "link target %q does not exist", m[1])
+			}
+		}
+	})
+
+	return issues
+}
+
+// scanProse calls fn with every line of markdown content outside fenced code blocks,
+// numbered from 1.
+func scanProse(content string, fn func(lineNo int, line string)) {
+	inFence := false
+	lineNo := 0
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(content)+1)
+
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+
+		if !inFence {
+			fn(lineNo, line)
+		}
+	}
+}
+
+// resolveLinkTarget resolves a relative link destination against the directory of the
+// linking document. It returns false for links that are not checked: absolute URLs,
+// other schemes, in-page anchors, and links that escape the repository root.
+func resolveLinkTarget(docPath, dest string) (string, bool) {
+	if isAbsoluteURL(dest) || strings.HasPrefix(dest, "#") {
+		return "", false
+	}
+
+	u, err := url.Parse(dest)
+	if err != nil || u.Scheme != "" || u.Path == "" {
+		return "", false
+	}
+
+	resolved := path.Clean(path.Join(path.Dir(docPath), u.Path))
+	if resolved == ".." || strings.HasPrefix(resolved, "../") {
+		return "", false
+	}
+
+	return resolved, true
+}
+
+// pathExists reports whether p is a known file or a directory containing known files.
+func pathExists(known map[string]struct{}, p string) bool {
+	if _, ok := known[p]; ok {
+		return true
+	}
+
+	prefix := p + "/"
+	if p == "." {
+		prefix = ""
+	}
+
+	for k := range known {
+		if strings.HasPrefix(k, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseFrontmatter returns the top-level fields of a YAML frontmatter block delimited
+// by "---" lines at the start of the content. It returns nil when there is none or it
+// cannot be parsed.
+func parseFrontmatter(content string) map[string]any {
+	rest, ok := strings.CutPrefix(strings.TrimPrefix(content, "\ufeff"), "---\n")
+	if !ok {
+		return nil
+	}
+
+	block, _, ok := strings.Cut("\n"+rest, "\n---")
+	if !ok {
+		return nil
+	}
+
+	var fields map[string]any
+	if err := yaml.Unmarshal([]byte(block), &fields); err != nil {
+		return nil
+	}
+
+	return fields
+}
+
+// LintReports returns the latest lint report of every repository that has been linted
+// since the server started, ordered by repository. It returns nil when linting is
+// disabled.
+func (s *Service) LintReports(_ context.Context) []LintReport {
+	if s.lint == nil {
+		return nil
+	}
+
+	s.lint.mu.Lock()
+	defer s.lint.mu.Unlock()
+
+	reports := make([]LintReport, 0, len(s.lint.reports))
+	for _, r := range s.lint.reports {
+		reports = append(reports, r)
+	}
+
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Repo < reports[j].Repo })
+
+	return reports
+}
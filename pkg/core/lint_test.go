@@ -0,0 +1,158 @@
+//go:build !compile
+
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintConfig_Validate(t *testing.T) {
+	for _, mode := range []string{"", LintModeOff, LintModeWarn, LintModeReject} {
+		assert.NoError(t, LintConfig{Mode: mode}.Validate(), mode)
+	}
+
+	assert.ErrorContains(t, LintConfig{Mode: "strict"}.Validate(), "unknown lint mode")
+}
+
+func TestWithLint_Off(t *testing.T) {
+	svc, _, _, _ := newTestService(t, WithLint(LintConfig{Mode: LintModeOff}))
+
+	assert.Nil(t, svc.lint)
+	assert.Nil(t, svc.LintReports(t.Context()))
+}
+
+func TestIngestDocuments_LintWarn(t *testing.T) {
+	svc, store, search, processor := newTestService(t, WithLint(LintConfig{
+		Mode:                LintModeWarn,
+		DisallowedWords:     []string{"simply"},
+		RequiredFrontmatter: []string{"owner"},
+		MaxCodeLineLength:   10,
+	}))
+
+	content := "---\ntitle: Guide\n---\n## Guide\n\nSimply see [setup](setup.md), [api](../api/) and [home](https://example.com).\n" +
+		"\n```sh\nsimply run a long command\n```\n"
+
+	store.EXPECT().List(mock.Anything, "owner/repo").Return([]DocumentMeta{{Path: "api/index.md"}}, nil)
+	store.EXPECT().List(mock.Anything, "owner/repo/guides").Return(nil, nil)
+	store.EXPECT().ListAssets(mock.Anything, "owner/repo").Return(nil, nil)
+	processor.EXPECT().ExtractHeadings([]byte(content)).Return([]Heading{{Level: 2, Text: "Guide", ID: "guide"}})
+	processor.EXPECT().ExtractCodeBlocks([]byte(content)).Return([]CodeBlock{{Lang: "sh", Code: "simply run a long command"}})
+
+	processor.EXPECT().ExtractTitle([]byte(content)).Return("Guide")
+	processor.EXPECT().ToPlainText([]byte(content)).Return("Guide")
+	store.EXPECT().Save(mock.Anything, mock.Anything).Return(nil)
+	search.EXPECT().Index(mock.Anything, mock.Anything, "Guide", mock.Anything, mock.Anything).Return(nil)
+
+	resp, err := svc.IngestDocuments(t.Context(), &IngestRequest{
+		Repo: "owner/repo",
+		Documents: []IngestDocument{
+			{Path: "guides/guide.md", Content: content, Action: "upsert"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, resp.Indexed)
+
+	assert.Equal(t, []LintIssue{
+		{Path: "guides/guide.md", Rule: LintRuleMissingH1, Message: "document has no top-level heading"},
+		{Path: "guides/guide.md", Rule: LintRuleMissingFrontmatter, Line: 1, Message: `frontmatter field "owner" is missing`},
+		{Path: "guides/guide.md", Rule: LintRuleLongCodeLine, Message: "code line is 25 characters long, limit is 10"},
+		{Path: "guides/guide.md", Rule: LintRuleDisallowedWord, Line: 6, Message: `disallowed word "Simply"`},
+		{Path: "guides/guide.md", Rule: LintRuleBrokenLink, Line: 6, Message: `link target "setup.md" does not exist`},
+	}, resp.Lint)
+
+	reports := svc.LintReports(t.Context())
+	require.Len(t, reports, 1)
+	assert.Equal(t, "owner/repo", reports[0].Repo)
+	assert.False(t, reports[0].Rejected)
+	assert.Len(t, reports[0].Issues, 5)
+}
+
+func TestIngestDocuments_LintReject(t *testing.T) {
+	svc, _, _, processor := newTestService(t, WithLint(LintConfig{Mode: LintModeReject}))
+
+	content := "No heading, see [img](img/missing.png)"
+
+	processor.EXPECT().ExtractHeadings([]byte(content)).Return(nil)
+
+	_, err := svc.IngestDocuments(t.Context(), &IngestRequest{
+		Repo: "owner/repo",
+		Sync: true,
+		Documents: []IngestDocument{
+			{Path: "readme.md", Content: content, Action: "upsert"},
+			{Path: "api.yaml", Content: "openapi: 3.0.0", Action: "upsert", ContentType: ContentTypeOpenAPI},
+		},
+	})
+
+	var lintErr *LintError
+	require.ErrorAs(t, err, &lintErr)
+	assert.Len(t, lintErr.Issues, 2)
+	assert.EqualError(t, err, "lint failed with 2 issue(s)")
+
+	reports := svc.LintReports(t.Context())
+	require.Len(t, reports, 1)
+	assert.True(t, reports[0].Rejected)
+}
+
+func TestIngestDocuments_LintKnowsRequestAssets(t *testing.T) {
+	svc, store, _, processor := newTestService(t, WithLint(LintConfig{Mode: LintModeReject}))
+
+	content := "# Title\n\n![diagram](img/arch.svg) [gone](old.md)\n"
+
+	processor.EXPECT().ExtractHeadings([]byte(content)).Return([]Heading{{Level: 1, Text: "Title", ID: "title"}})
+
+	store.EXPECT().List(mock.Anything, "owner/repo").Return([]DocumentMeta{{Path: "old.md"}}, nil)
+	store.EXPECT().ListAssets(mock.Anything, "owner/repo").Return(nil, nil)
+
+	_, err := svc.IngestDocuments(t.Context(), &IngestRequest{
+		Repo: "owner/repo",
+		Documents: []IngestDocument{
+			{Path: "readme.md", Content: content, Action: "upsert"},
+			{Path: "old.md", Action: "delete"},
+		},
+		Assets: &[]IngestAsset{{Path: "img/arch.svg", Content: "PHN2Zy8+", Action: "upsert"}},
+	})
+
+	var lintErr *LintError
+	require.ErrorAs(t, err, &lintErr)
+	assert.Equal(t, []LintIssue{
+		{Path: "readme.md", Rule: LintRuleBrokenLink, Line: 3, Message: `link target "old.md" does not exist`},
+	}, lintErr.Issues)
+}
+
+func TestResolveLinkTarget(t *testing.T) {
+	tests := []struct {
+		dest   string
+		want   string
+		wantOK bool
+	}{
+		{dest: "other.md", want: "docs/other.md", wantOK: true},
+		{dest: "../readme.md#intro", want: "readme.md", wantOK: true},
+		{dest: "img/a.png?raw=1", want: "docs/img/a.png", wantOK: true},
+		{dest: "#section"},
+		{dest: "https://example.com/x.md"},
+		{dest: "/absolute.md"},
+		{dest: "mailto:someone@example.com"},
+		{dest: "../../outside.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dest, func(t *testing.T) {
+			got, ok := resolveLinkTarget("docs/guide.md", tt.dest)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseFrontmatter(t *testing.T) {
+	assert.Equal(t, map[string]any{"title": "Guide", "tags": []any{"a"}},
+		parseFrontmatter("---\ntitle: Guide\ntags: [a]\n---\n# Guide\n"))
+	assert.Nil(t, parseFrontmatter("---\n---\n"))
+	assert.Nil(t, parseFrontmatter("# No frontmatter\n"))
+	assert.Nil(t, parseFrontmatter("---\ntitle: unterminated\n"))
+	assert.Nil(t, parseFrontmatter("---\n: [broken\n---\n"))
+}
@@ -0,0 +1,67 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// DocumentMetadata is the metadata a document declares about itself, such as the fields
+// of markdown frontmatter.
+type DocumentMetadata struct {
+	Description string   // summary of the document, used instead of the generated one
+	Tags        []string // keywords the document is filed under
+	Order       int      // position among the documents of its folder; 0 when unset
+	Draft       bool     // the document is published but left out of search
+}
+
+// MetadataExtractor is optionally implemented by a ContentProcessor whose documents can
+// declare their own metadata. Documents of processors that do not implement it have none.
+type MetadataExtractor interface {
+	ExtractMetadata(src []byte) DocumentMetadata
+}
+
+// extractMetadata returns the metadata declared by a document's content, with its tags
+// normalized, see normalizeTags.
+func extractMetadata(processor ContentProcessor, content string) DocumentMetadata {
+	ext, ok := processor.(MetadataExtractor)
+	if !ok {
+		return DocumentMetadata{}
+	}
+
+	meta := ext.ExtractMetadata([]byte(content))
+	meta.Tags = normalizeTags(meta.Tags)
+
+	return meta
+}
+
+// normalizeTags lowercases and trims tags and drops empty and repeated ones, keeping the
+// order in which they were declared, so that tags match regardless of how they are
+// written.
+func normalizeTags(tags []string) []string {
+	var normalized []string
+
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.Join(strings.Fields(tag), " "))
+		if tag != "" && !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+
+	return normalized
+}
+
+// indexSearchable adds a document to the given search index, or removes it from the index
+// when it is a draft, so that a document turned into a draft stops being found.
+func indexSearchable(ctx context.Context, index searchEngine, doc *Document, plainText string, code []CodeBlock, headings []Heading) error {
+	if doc.Draft {
+		if err := index.Remove(ctx, doc.ID); err != nil {
+			return fmt.Errorf("failed to remove draft from index: %w", err)
+		}
+
+		return nil
+	}
+
+	return index.Index(ctx, *doc, plainText, code, headings)
+}
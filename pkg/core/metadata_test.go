@@ -0,0 +1,84 @@
+//go:build !compile
+
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// metadataProcessor is a content processor whose documents declare fixed metadata.
+type metadataProcessor struct {
+	*MockContentProcessor
+	meta DocumentMetadata
+}
+
+func (p metadataProcessor) ExtractMetadata([]byte) DocumentMetadata {
+	return p.meta
+}
+
+// withMetadata makes the markdown processor of a test service declare meta for every
+// document.
+func withMetadata(meta DocumentMetadata) Option {
+	return func(s *Service) {
+		processor, _ := s.processors[ContentTypeMarkdown].(*MockContentProcessor)
+		s.processors[ContentTypeMarkdown] = metadataProcessor{MockContentProcessor: processor, meta: meta}
+	}
+}
+
+// expectDeployDocument registers the processing of the deploy.md document of the metadata
+// tests.
+func expectDeployDocument(processor *MockContentProcessor) {
+	processor.EXPECT().ExtractTitle(mock.Anything).Return("Deploy")
+	processor.EXPECT().ToPlainText(mock.Anything).Return("Deploy\n\nRoll out a release.")
+	processor.EXPECT().ExtractCodeBlocks(mock.Anything).Return(nil)
+	processor.EXPECT().ExtractHeadings(mock.Anything).Return(nil)
+}
+
+func TestIngestDocuments_Metadata(t *testing.T) {
+	svc, store, search, processor := newTestService(t, withMetadata(DocumentMetadata{
+		Description: " How releases\nreach production. ",
+		Tags:        []string{"Ops", " getting   started ", "ops", ""},
+		Order:       2,
+	}))
+
+	expectDeployDocument(processor)
+
+	store.EXPECT().Save(mock.Anything, mock.MatchedBy(func(doc Document) bool {
+		return doc.Summary == "How releases reach production." &&
+			assert.ObjectsAreEqual([]string{"ops", "getting started"}, doc.Tags) &&
+			doc.Order == 2 && !doc.Draft
+	})).Return(nil)
+	search.EXPECT().Index(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	_, err := svc.IngestDocuments(t.Context(), &IngestRequest{
+		Repo:      "owner/repo",
+		Documents: []IngestDocument{{Path: "deploy.md", Content: "# Deploy\n\nRoll out a release.", Action: actionUpsert}},
+	})
+	require.NoError(t, err)
+}
+
+func TestIngestDocuments_Draft(t *testing.T) {
+	svc, store, search, processor := newTestService(t, withMetadata(DocumentMetadata{Draft: true}))
+
+	expectDeployDocument(processor)
+
+	store.EXPECT().Save(mock.Anything, mock.MatchedBy(func(doc Document) bool {
+		return doc.Draft && doc.Summary == "Roll out a release."
+	})).Return(nil)
+	search.EXPECT().Remove(mock.Anything, "owner/repo/deploy.md").Return(nil)
+
+	_, err := svc.IngestDocuments(t.Context(), &IngestRequest{
+		Repo:      "owner/repo",
+		Documents: []IngestDocument{{Path: "deploy.md", Content: "# Deploy\n\nRoll out a release.", Action: actionUpsert}},
+	})
+	require.NoError(t, err)
+}
+
+func TestNormalizeTags(t *testing.T) {
+	assert.Equal(t, []string{"ops", "getting started"}, normalizeTags([]string{" Ops", "getting\tStarted", "OPS", " "}))
+	assert.Nil(t, normalizeTags(nil))
+}
@@ -0,0 +1,280 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// maxMigrationMismatches bounds the document IDs listed per repository in a migration
+// report.
+const maxMigrationMismatches = 20
+
+// WithSearchMigration migrates the search index to target without downtime: every change
+// is written to the live search engine and to target, while searches are served by the
+// live engine, or by target when readTarget is set. The live engine stays authoritative:
+// changes target fails to apply are logged and counted in the migration report rather than
+// failing the publish, and are repaired by a reindex. Blue/green rebuilds are not
+// available while migrating.
+func WithSearchMigration(target searchEngine, readTarget bool) Option {
+	return func(s *Service) {
+		s.migration = &dualWrite{source: s.search, target: target, readTarget: readTarget}
+		s.search = s.migration
+	}
+}
+
+// dualWrite is a search engine writing every change to the engine migrated from and the
+// engine migrated to.
+type dualWrite struct {
+	source      searchEngine
+	target      searchEngine
+	lastFailure time.Time
+	lastError   string
+	failures    int
+	mu          sync.Mutex
+	readTarget  bool
+}
+
+// Index indexes the document in both engines.
+func (d *dualWrite) Index(ctx context.Context, doc Document, plainText string, code []CodeBlock, headings []Heading) error {
+	if err := d.source.Index(ctx, doc, plainText, code, headings); err != nil {
+		return err
+	}
+
+	d.recordTargetError(ctx, doc.ID, d.target.Index(ctx, doc, plainText, code, headings))
+
+	return nil
+}
+
+// Remove removes the document from both engines.
+func (d *dualWrite) Remove(ctx context.Context, docID string) error {
+	if err := d.source.Remove(ctx, docID); err != nil {
+		return err
+	}
+
+	d.recordTargetError(ctx, docID, d.target.Remove(ctx, docID))
+
+	return nil
+}
+
+// Search searches the engine reads are served from.
+func (d *dualWrite) Search(ctx context.Context, query string, opts SearchOpts) (*SearchResults, error) {
+	return d.reader().Search(ctx, query, opts)
+}
+
+// Suggest suggests titles from the engine reads are served from.
+func (d *dualWrite) Suggest(ctx context.Context, prefix string, limit int) ([]SearchSuggestion, error) {
+	return d.reader().Suggest(ctx, prefix, limit)
+}
+
+// ListByRepo lists the documents of a repository in the engine reads are served from.
+func (d *dualWrite) ListByRepo(ctx context.Context, repo string) ([]string, error) {
+	return d.reader().ListByRepo(ctx, repo)
+}
+
+// CorrectQuery corrects the query with the engine reads are served from, when it is a
+// SpellChecker, and returns no correction otherwise.
+func (d *dualWrite) CorrectQuery(ctx context.Context, query string) (string, error) {
+	checker, ok := d.reader().(SpellChecker)
+	if !ok {
+		return "", nil
+	}
+
+	return checker.CorrectQuery(ctx, query)
+}
+
+// Size returns the size of the engine migrated from, when it can report it.
+func (d *dualWrite) Size(ctx context.Context) (int64, error) {
+	sz, ok := d.source.(sizer)
+	if !ok {
+		return 0, fmt.Errorf("%w: the search engine cannot report its size", ErrNotSupported)
+	}
+
+	return sz.Size(ctx)
+}
+
+// reader returns the engine searches are served from.
+func (d *dualWrite) reader() searchEngine {
+	if d.readTarget {
+		return d.target
+	}
+
+	return d.source
+}
+
+// recordTargetError logs and counts err, a failure of the target to apply a change to the
+// document docID. It does nothing when err is nil.
+func (d *dualWrite) recordTargetError(ctx context.Context, docID string, err error) {
+	if err == nil {
+		return
+	}
+
+	slog.WarnContext(ctx, "failed to apply change to search migration target", "doc_id", docID, "error", err)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.failures++
+	d.lastError = err.Error()
+	d.lastFailure = time.Now().UTC()
+}
+
+// MigrationVerifyRequest configures the verification of a search migration.
+type MigrationVerifyRequest struct {
+	// Queries compare the results of both engines. When empty, titles of stored documents
+	// are sampled.
+	Queries []string `json:"queries,omitempty"`
+}
+
+// RepoIndexComparison compares the documents of a repository indexed by the engines of a
+// search migration.
+type RepoIndexComparison struct {
+	Repo string `json:"repo"`
+	// Missing lists up to 20 documents indexed by the source but not by the target.
+	Missing []string `json:"missing,omitempty"`
+	// Extra lists up to 20 documents indexed by the target but not by the source.
+	Extra  []string `json:"extra,omitempty"`
+	Stored int      `json:"stored"` // documents in the document store
+	Source int      `json:"source"` // documents indexed by the engine migrated from
+	Target int      `json:"target"` // documents indexed by the engine migrated to
+}
+
+// MigrationReport compares the engines of a search migration, showing whether the engine
+// migrated to is ready to serve searches.
+type MigrationReport struct {
+	CheckedAt   time.Time             `json:"checked_at"`
+	LastFailure time.Time             `json:"last_failure,omitzero"`
+	LastError   string                `json:"last_error,omitempty"`
+	ReadFrom    string                `json:"read_from"` // "source" or "target"
+	Repos       []RepoIndexComparison `json:"repos"`
+	// Comparisons compare the results of the engine migrated from, as the live index,
+	// with those of the engine migrated to, as the shadow index.
+	Comparisons []QueryComparison `json:"comparisons"`
+	// WriteFailures is the number of changes the target failed to apply since the
+	// instance started.
+	WriteFailures int     `json:"write_failures"`
+	Overlap       float64 `json:"overlap"` // mean overlap of the comparisons
+	// InSync reports whether both engines index the same documents and return the same top
+	// results for every compared query.
+	InSync bool `json:"in_sync"`
+}
+
+// VerifyMigration compares the engines of the search migration: the documents each one
+// indexes per repository, and the top results of sampled queries. It returns an error
+// wrapping ErrNotSupported if no migration is configured.
+func (s *Service) VerifyMigration(ctx context.Context, req MigrationVerifyRequest) (*MigrationReport, error) {
+	if s.migration == nil {
+		return nil, fmt.Errorf("%w: no search migration is configured", ErrNotSupported)
+	}
+
+	repos, err := s.store.ListRepos(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repos: %w", err)
+	}
+
+	report := &MigrationReport{
+		CheckedAt: time.Now().UTC(),
+		ReadFrom:  "source",
+		Repos:     make([]RepoIndexComparison, 0, len(repos)),
+		InSync:    true,
+	}
+
+	if s.migration.readTarget {
+		report.ReadFrom = "target"
+	}
+
+	var titles []string
+
+	for _, repo := range repos {
+		docs, err := s.store.List(ctx, repo.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list documents for repo %s: %w", repo.Name, err)
+		}
+
+		for _, meta := range docs {
+			if meta.Title != "" {
+				titles = append(titles, meta.Title)
+			}
+		}
+
+		c, err := s.compareRepoIndexes(ctx, repo.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		c.Stored = len(docs)
+		report.InSync = report.InSync && len(c.Missing) == 0 && len(c.Extra) == 0
+		report.Repos = append(report.Repos, c)
+	}
+
+	queries := req.Queries
+	if len(queries) == 0 {
+		queries = sampleQueries(titles, rebuildSampleSize)
+	}
+
+	report.Comparisons, report.Overlap, err = compareIndexes(ctx, s.migration.source, s.migration.target, queries)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range report.Comparisons {
+		report.InSync = report.InSync && c.LiveTotal == c.ShadowTotal && c.Overlap == 1
+	}
+
+	s.migration.mu.Lock()
+	report.WriteFailures = s.migration.failures
+	report.LastError = s.migration.lastError
+	report.LastFailure = s.migration.lastFailure
+	s.migration.mu.Unlock()
+
+	return report, nil
+}
+
+// compareRepoIndexes compares the documents of repo indexed by the engines of the search
+// migration.
+func (s *Service) compareRepoIndexes(ctx context.Context, repo string) (RepoIndexComparison, error) {
+	source, err := s.migration.source.ListByRepo(ctx, repo)
+	if err != nil {
+		return RepoIndexComparison{}, fmt.Errorf("failed to list source index entries for repo %s: %w", repo, err)
+	}
+
+	target, err := s.migration.target.ListByRepo(ctx, repo)
+	if err != nil {
+		return RepoIndexComparison{}, fmt.Errorf("failed to list target index entries for repo %s: %w", repo, err)
+	}
+
+	return RepoIndexComparison{
+		Repo:    repo,
+		Source:  len(source),
+		Target:  len(target),
+		Missing: missingIDs(source, target),
+		Extra:   missingIDs(target, source),
+	}, nil
+}
+
+// missingIDs returns up to maxMigrationMismatches sorted IDs of ids missing from other.
+func missingIDs(ids, other []string) []string {
+	present := make(map[string]struct{}, len(other))
+	for _, id := range other {
+		present[id] = struct{}{}
+	}
+
+	var missing []string
+
+	for _, id := range ids {
+		if _, ok := present[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+
+	slices.Sort(missing)
+
+	if len(missing) > maxMigrationMismatches {
+		missing = missing[:maxMigrationMismatches]
+	}
+
+	return missing
+}
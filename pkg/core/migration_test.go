@@ -0,0 +1,142 @@
+//go:build !compile
+
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDualWrite_Writes(t *testing.T) {
+	target := NewMocksearchEngine(t)
+	svc, _, source, _ := newTestService(t, WithSearchMigration(target, false))
+
+	doc := Document{ID: "owner/repo/setup.md"}
+
+	source.EXPECT().Index(mock.Anything, doc, "text", []CodeBlock(nil), []Heading(nil)).Return(nil).Once()
+	target.EXPECT().Index(mock.Anything, doc, "text", []CodeBlock(nil), []Heading(nil)).Return(nil).Once()
+	require.NoError(t, svc.search.Index(t.Context(), doc, "text", nil, nil))
+
+	source.EXPECT().Remove(mock.Anything, "owner/repo/setup.md").Return(nil).Once()
+	target.EXPECT().Remove(mock.Anything, "owner/repo/setup.md").Return(errors.New("cluster unavailable")).Once()
+	require.NoError(t, svc.search.Remove(t.Context(), "owner/repo/setup.md"), "target failures must not fail the change")
+
+	source.EXPECT().Remove(mock.Anything, "owner/repo/faq.md").Return(errors.New("index closed")).Once()
+	assert.Error(t, svc.search.Remove(t.Context(), "owner/repo/faq.md"))
+
+	assert.Equal(t, 1, svc.migration.failures)
+	assert.Equal(t, "cluster unavailable", svc.migration.lastError)
+}
+
+func TestDualWrite_Reads(t *testing.T) {
+	results := &SearchResults{Total: 1}
+
+	t.Run("source", func(t *testing.T) {
+		svc, _, source, _ := newTestService(t, WithSearchMigration(NewMocksearchEngine(t), false))
+
+		source.EXPECT().Search(mock.Anything, "install", SearchOpts{}).Return(results, nil).Once()
+
+		got, err := svc.search.Search(t.Context(), "install", SearchOpts{})
+		require.NoError(t, err)
+		assert.Same(t, results, got)
+
+		correction, err := svc.search.(SpellChecker).CorrectQuery(t.Context(), "instal")
+		require.NoError(t, err)
+		assert.Empty(t, correction)
+	})
+
+	t.Run("target", func(t *testing.T) {
+		target := NewMocksearchEngine(t)
+		svc, _, _, _ := newTestService(t, WithSearchMigration(target, true))
+
+		target.EXPECT().Search(mock.Anything, "install", SearchOpts{}).Return(results, nil).Once()
+		target.EXPECT().Suggest(mock.Anything, "ins", 5).Return([]SearchSuggestion{{Title: "Install"}}, nil).Once()
+
+		got, err := svc.search.Search(t.Context(), "install", SearchOpts{})
+		require.NoError(t, err)
+		assert.Same(t, results, got)
+
+		suggestions, err := svc.search.Suggest(t.Context(), "ins", 5)
+		require.NoError(t, err)
+		assert.Len(t, suggestions, 1)
+	})
+}
+
+func TestVerifyMigration(t *testing.T) {
+	target := NewMocksearchEngine(t)
+	svc, store, source, _ := newTestService(t, WithSearchMigration(target, true))
+
+	store.EXPECT().ListRepos(mock.Anything).Return([]RepoInfo{{Name: "owner/repo"}}, nil)
+	store.EXPECT().List(mock.Anything, "owner/repo").Return([]DocumentMeta{
+		{Repo: "owner/repo", Path: "setup.md", Title: "Setup Guide"},
+		{Repo: "owner/repo", Path: "faq.md", Title: "FAQ"},
+	}, nil)
+
+	source.EXPECT().ListByRepo(mock.Anything, "owner/repo").Return([]string{"owner/repo/setup.md", "owner/repo/faq.md"}, nil)
+	target.EXPECT().ListByRepo(mock.Anything, "owner/repo").Return([]string{"owner/repo/setup.md", "owner/repo/old.md"}, nil)
+
+	hits := &SearchResults{Hits: []SearchResult{{ID: "owner/repo/setup.md"}}, Total: 1}
+
+	for _, query := range []string{"Setup Guide", "FAQ"} {
+		source.EXPECT().Search(mock.Anything, query, SearchOpts{Limit: rebuildTopHits}).Return(hits, nil).Once()
+		target.EXPECT().Search(mock.Anything, query, SearchOpts{Limit: rebuildTopHits}).Return(hits, nil).Once()
+	}
+
+	svc.migration.failures = 2
+
+	report, err := svc.VerifyMigration(t.Context(), MigrationVerifyRequest{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "target", report.ReadFrom)
+	assert.Equal(t, []RepoIndexComparison{{
+		Repo:    "owner/repo",
+		Stored:  2,
+		Source:  2,
+		Target:  2,
+		Missing: []string{"owner/repo/faq.md"},
+		Extra:   []string{"owner/repo/old.md"},
+	}}, report.Repos)
+	assert.Len(t, report.Comparisons, 2)
+	assert.InDelta(t, 1.0, report.Overlap, 0.001)
+	assert.Equal(t, 2, report.WriteFailures)
+	assert.False(t, report.InSync)
+}
+
+func TestVerifyMigration_Queries(t *testing.T) {
+	target := NewMocksearchEngine(t)
+	svc, store, source, _ := newTestService(t, WithSearchMigration(target, false))
+
+	store.EXPECT().ListRepos(mock.Anything).Return(nil, nil)
+
+	source.EXPECT().Search(mock.Anything, "install", mock.Anything).Return(&SearchResults{
+		Hits:  []SearchResult{{ID: "owner/repo/setup.md"}, {ID: "owner/repo/faq.md"}},
+		Total: 2,
+	}, nil).Once()
+	target.EXPECT().Search(mock.Anything, "install", mock.Anything).Return(&SearchResults{
+		Hits:  []SearchResult{{ID: "owner/repo/setup.md"}},
+		Total: 1,
+	}, nil).Once()
+
+	report, err := svc.VerifyMigration(t.Context(), MigrationVerifyRequest{Queries: []string{"install"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, "source", report.ReadFrom)
+	assert.Equal(t, []QueryComparison{{Query: "install", LiveTotal: 2, ShadowTotal: 1, Overlap: 0.5}}, report.Comparisons)
+	assert.False(t, report.InSync)
+}
+
+func TestVerifyMigration_NotConfigured(t *testing.T) {
+	svc := newTestServiceOnly(t)
+
+	_, err := svc.VerifyMigration(t.Context(), MigrationVerifyRequest{})
+	assert.ErrorIs(t, err, ErrNotSupported)
+}
+
+func TestMissingIDs(t *testing.T) {
+	assert.Empty(t, missingIDs([]string{"a", "b"}, []string{"b", "a"}))
+	assert.Equal(t, []string{"a", "c"}, missingIDs([]string{"c", "b", "a"}, []string{"b"}))
+}
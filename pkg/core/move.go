@@ -0,0 +1,50 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"log/slog"
+)
+
+// detectMoves pairs stale documents with upserted documents of the same request that
+// have identical content, treating each pair as a file rename rather than an unrelated
+// delete and add. It returns a map from stale path to new path. A stale document whose
+// content cannot be read is treated as deleted.
+func (s *Service) detectMoves(ctx context.Context, repo string, stale []DocumentMeta, docs []IngestDocument) map[string]string {
+	if len(stale) == 0 {
+		return nil
+	}
+
+	byHash := make(map[[sha256.Size]byte]string)
+
+	for _, doc := range docs {
+		if doc.Action != actionUpsert {
+			continue
+		}
+
+		h := sha256.Sum256([]byte(doc.Content))
+		if _, ok := byHash[h]; !ok {
+			byHash[h] = doc.Path
+		}
+	}
+
+	if len(byHash) == 0 {
+		return nil
+	}
+
+	moves := make(map[string]string)
+
+	for _, meta := range stale {
+		doc, err := s.store.Get(ctx, repo, meta.Path)
+		if err != nil {
+			slog.DebugContext(ctx, "sync: skipping move detection", "repo", repo, "path", meta.Path, "error", err)
+			continue
+		}
+
+		if newPath, ok := byHash[sha256.Sum256([]byte(doc.Content))]; ok {
+			moves[meta.Path] = newPath
+		}
+	}
+
+	return moves
+}
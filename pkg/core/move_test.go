@@ -0,0 +1,77 @@
+//go:build !compile
+
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIngestDocuments_SyncDetectsMovedDocuments(t *testing.T) {
+	svc, store, search, renderer := newTestService(t)
+
+	content := "# Guide"
+
+	renderer.EXPECT().ExtractTitle([]byte(content)).Return("Guide")
+	renderer.EXPECT().ToPlainText([]byte(content)).Return("Guide")
+	renderer.EXPECT().ExtractCodeBlocks([]byte(content)).Return(nil)
+	renderer.EXPECT().ExtractHeadings([]byte(content)).Return(nil)
+	store.EXPECT().Save(mock.Anything, mock.Anything).Return(nil)
+	search.EXPECT().Index(mock.Anything, mock.Anything, "Guide", []CodeBlock(nil), []Heading(nil)).Return(nil)
+
+	store.EXPECT().List(mock.Anything, "owner/repo").Return([]DocumentMeta{
+		{ID: "owner/repo/docs/guide.md", Path: "docs/guide.md"},
+		{ID: "owner/repo/guide.md", Path: "guide.md"},
+		{ID: "owner/repo/old.md", Path: "old.md"},
+	}, nil)
+	store.EXPECT().Get(mock.Anything, "owner/repo", "guide.md").Return(Document{Content: content}, nil)
+	store.EXPECT().Get(mock.Anything, "owner/repo", "old.md").Return(Document{Content: "# Old"}, nil)
+
+	for _, path := range []string{"guide.md", "old.md"} {
+		search.EXPECT().Remove(mock.Anything, "owner/repo/"+path).Return(nil)
+		store.EXPECT().Delete(mock.Anything, "owner/repo", path).Return(nil)
+	}
+
+	store.EXPECT().GetRedirects(mock.Anything).Return(map[string]string{
+		"owner/repo/intro.md": "owner/repo/guide.md",
+	}, nil)
+	store.EXPECT().SaveRedirects(mock.Anything, map[string]string{
+		"owner/repo/intro.md": "owner/repo/docs/guide.md",
+		"owner/repo/guide.md": "owner/repo/docs/guide.md",
+	}).Return(nil)
+	search.EXPECT().ListByRepo(mock.Anything, "owner/repo").Return([]string{"owner/repo/docs/guide.md"}, nil)
+
+	resp, err := svc.IngestDocuments(t.Context(), &IngestRequest{
+		Repo: "owner/repo",
+		Sync: true,
+		Documents: []IngestDocument{
+			{Path: "docs/guide.md", Content: content, Action: "upsert"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, resp.Deleted)
+	assert.Equal(t, 1, resp.Moved)
+}
+
+func TestDetectMoves(t *testing.T) {
+	svc, store, _, _ := newTestService(t)
+
+	store.EXPECT().Get(mock.Anything, "owner/repo", "a.md").Return(Document{Content: "same"}, nil)
+	store.EXPECT().Get(mock.Anything, "owner/repo", "b.md").Return(Document{}, errors.New("disk failure"))
+
+	moves := svc.detectMoves(t.Context(), "owner/repo",
+		[]DocumentMeta{{Path: "a.md"}, {Path: "b.md"}},
+		[]IngestDocument{
+			{Path: "gone.md", Action: "delete"},
+			{Path: "new/a.md", Content: "same", Action: "upsert"},
+			{Path: "copy/a.md", Content: "same", Action: "upsert"},
+		})
+
+	assert.Equal(t, map[string]string{"a.md": "new/a.md"}, moves)
+
+	assert.Nil(t, svc.detectMoves(t.Context(), "owner/repo", []DocumentMeta{{Path: "a.md"}}, nil))
+}
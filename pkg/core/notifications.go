@@ -0,0 +1,99 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// maxNotifications bounds the notifications listed in a reader's inbox.
+const maxNotifications = 100
+
+// Notification is an entry of a signed-in reader's inbox: a published document that
+// matched one of the searches the reader saved.
+type Notification struct {
+	Time     time.Time `json:"time"`
+	SearchID string    `json:"search_id"`
+	Search   string    `json:"search"` // name of the saved search
+	Repo     string    `json:"repo"`
+	Path     string    `json:"path"`
+	Title    string    `json:"title"`
+	Unread   bool      `json:"unread"`
+}
+
+// Notifications returns the inbox of a signed-in reader, the latest matches of the
+// searches they saved, newest first. Matches recorded since the reader last marked their
+// notifications read are unread. It returns an error wrapping ErrNotSupported if saved
+// searches are not enabled.
+func (s *Service) Notifications(ctx context.Context, reader string) ([]Notification, error) {
+	if s.saved == nil {
+		return nil, fmt.Errorf("%w: saved searches are not enabled", ErrNotSupported)
+	}
+
+	searches, err := s.saved.store.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list saved searches: %w", err)
+	}
+
+	var inbox []Notification
+
+	for i := range searches {
+		search := &searches[i]
+		if reader == "" || search.Owner != reader {
+			continue
+		}
+
+		for _, m := range search.Matches {
+			inbox = append(inbox, Notification{
+				Time:     m.Time,
+				SearchID: search.ID,
+				Search:   search.Name,
+				Repo:     m.Repo,
+				Path:     m.Path,
+				Title:    m.Title,
+				Unread:   m.Time.After(search.ReadAt),
+			})
+		}
+	}
+
+	slices.SortStableFunc(inbox, func(a, b Notification) int { return b.Time.Compare(a.Time) })
+
+	if len(inbox) > maxNotifications {
+		inbox = inbox[:maxNotifications]
+	}
+
+	return inbox, nil
+}
+
+// MarkNotificationsRead marks every notification of a signed-in reader read. It returns an
+// error wrapping ErrNotSupported if saved searches are not enabled.
+func (s *Service) MarkNotificationsRead(ctx context.Context, reader string) error {
+	if s.saved == nil {
+		return fmt.Errorf("%w: saved searches are not enabled", ErrNotSupported)
+	}
+
+	s.saved.mu.Lock()
+	defer s.saved.mu.Unlock()
+
+	searches, err := s.saved.store.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list saved searches: %w", err)
+	}
+
+	now := time.Now().UTC()
+
+	for i := range searches {
+		if reader == "" || searches[i].Owner != reader {
+			continue
+		}
+
+		searches[i].ReadAt = now
+
+		if err := s.saved.store.Save(ctx, searches[i]); err != nil {
+			return fmt.Errorf("failed to save saved search: %w", err)
+		}
+	}
+
+	return nil
+}
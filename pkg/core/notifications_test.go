@@ -0,0 +1,70 @@
+//go:build !compile
+
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifications(t *testing.T) {
+	saved := NewMockSavedSearchStore(t)
+	svc, _, _, _ := newTestService(t, WithSavedSearches(saved, NewMockAlertNotifier(t), nil))
+
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+
+	saved.EXPECT().List(mock.Anything).Return([]SavedSearch{
+		{ID: "s1", Name: "Deploys", Owner: "alex", ReadAt: day(2), Matches: []SearchMatch{
+			{Time: day(3), Repo: "acme/api", Path: "deploy.md", Title: "Deploying"},
+			{Time: day(1), Repo: "acme/api", Path: "rollback.md", Title: "Rolling back"},
+		}},
+		{ID: "s2", Name: "Nomad", Owner: "sam", Matches: []SearchMatch{
+			{Time: day(4), Repo: "acme/ops", Path: "nomad.md", Title: "Nomad"},
+		}},
+		{ID: "s3", Name: "Incidents", Owner: "alex", Matches: []SearchMatch{
+			{Time: day(2), Repo: "acme/ops", Path: "incidents.md", Title: "Incidents"},
+		}},
+		{ID: "s4", Name: "Anonymous", Matches: []SearchMatch{
+			{Time: day(5), Repo: "acme/ops", Path: "faq.md", Title: "FAQ"},
+		}},
+	}, nil)
+
+	inbox, err := svc.Notifications(t.Context(), "alex")
+	require.NoError(t, err)
+	assert.Equal(t, []Notification{
+		{Time: day(3), SearchID: "s1", Search: "Deploys", Repo: "acme/api", Path: "deploy.md", Title: "Deploying", Unread: true},
+		{Time: day(2), SearchID: "s3", Search: "Incidents", Repo: "acme/ops", Path: "incidents.md", Title: "Incidents", Unread: true},
+		{Time: day(1), SearchID: "s1", Search: "Deploys", Repo: "acme/api", Path: "rollback.md", Title: "Rolling back"},
+	}, inbox)
+
+	inbox, err = svc.Notifications(t.Context(), "")
+	require.NoError(t, err)
+	assert.Empty(t, inbox, "searches saved without signing in belong to nobody")
+}
+
+func TestMarkNotificationsRead(t *testing.T) {
+	saved := NewMockSavedSearchStore(t)
+	svc, _, _, _ := newTestService(t, WithSavedSearches(saved, NewMockAlertNotifier(t), nil))
+
+	saved.EXPECT().List(mock.Anything).Return([]SavedSearch{
+		{ID: "s1", Owner: "alex"},
+		{ID: "s2", Owner: "sam"},
+	}, nil)
+	saved.EXPECT().Save(mock.Anything, mock.MatchedBy(func(s SavedSearch) bool {
+		return s.ID == "s1" && time.Since(s.ReadAt) < time.Minute
+	})).Return(nil).Once()
+
+	require.NoError(t, svc.MarkNotificationsRead(t.Context(), "alex"))
+}
+
+func TestNotifications_NotEnabled(t *testing.T) {
+	svc := newTestServiceOnly(t)
+
+	_, err := svc.Notifications(t.Context(), "alex")
+	assert.ErrorIs(t, err, ErrNotSupported)
+	assert.ErrorIs(t, svc.MarkNotificationsRead(t.Context(), "alex"), ErrNotSupported)
+}
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Content policy actions reported in PolicyFinding.Action.
+const (
+	// PolicyActionRedact replaces matched content before it is stored, indexed or served.
+	PolicyActionRedact = "redact"
+	// PolicyActionFlag reports matched content without changing it.
+	PolicyActionFlag = "flag"
+)
+
+// ContentPolicy inspects document content for sensitive data such as email addresses,
+// phone numbers or internal hostnames, according to the policy of the repository.
+// Apply returns the content with matches of redacting rules replaced, together with a
+// finding for every match. Findings never include the matched text.
+type ContentPolicy interface {
+	Apply(repo string, content []byte) ([]byte, []PolicyFinding)
+}
+
+// PolicyFinding describes a match of a content policy rule in a document.
+type PolicyFinding struct {
+	Path   string `json:"path,omitempty"`
+	Rule   string `json:"rule"`
+	Action string `json:"action"`
+	Line   int    `json:"line"`
+}
+
+// WithContentPolicy applies the given content policy to documents during ingest and
+// again when they are rendered, so that content stored before a policy change is also
+// redacted when served.
+func WithContentPolicy(p ContentPolicy) Option {
+	return func(s *Service) {
+		s.policy = p
+	}
+}
+
+// applyPolicy runs the content policy over a document being ingested and returns the
+// content to store together with the findings, attributed to the document path.
+func (s *Service) applyPolicy(ctx context.Context, repo, path, content string) (string, []PolicyFinding) {
+	if s.policy == nil {
+		return content, nil
+	}
+
+	out, findings := s.policy.Apply(repo, []byte(content))
+
+	for i := range findings {
+		findings[i].Path = path
+	}
+
+	if len(findings) > 0 {
+		slog.InfoContext(ctx, "content policy matched", "repo", repo, "path", path, "findings", len(findings))
+	}
+
+	return string(out), findings
+}
+
+// redactDocument applies the content policy to a stored document before it is served.
+// Binary documents are served as stored.
+func (s *Service) redactDocument(repo string, doc Document) Document {
+	if s.policy == nil || s.binaryContent(doc.ContentType) {
+		return doc
+	}
+
+	out, _ := s.policy.Apply(repo, []byte(doc.Content))
+	if string(out) != doc.Content {
+		// The anchor map was recorded for the plain text of the original content.
+		doc.Anchors = nil
+	}
+
+	doc.Content = string(out)
+
+	return doc
+}
@@ -0,0 +1,77 @@
+//go:build !compile
+
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// secretPolicy redacts the word "secret" and flags the word "internal".
+type secretPolicy struct{}
+
+func (secretPolicy) Apply(_ string, content []byte) ([]byte, []PolicyFinding) {
+	var findings []PolicyFinding
+
+	s := string(content)
+
+	if strings.Contains(s, "secret") {
+		findings = append(findings, PolicyFinding{Rule: "secret", Action: PolicyActionRedact, Line: 1})
+		s = strings.ReplaceAll(s, "secret", "[REDACTED]")
+	}
+
+	if strings.Contains(s, "internal") {
+		findings = append(findings, PolicyFinding{Rule: "internal", Action: PolicyActionFlag, Line: 1})
+	}
+
+	return []byte(s), findings
+}
+
+func TestIngestDocuments_ContentPolicy(t *testing.T) {
+	svc, store, search, processor := newTestService(t, WithContentPolicy(secretPolicy{}))
+
+	redacted := []byte("# The [REDACTED] internal plan")
+
+	processor.EXPECT().ExtractTitle(redacted).Return("The [REDACTED] internal plan")
+	processor.EXPECT().ToPlainText(redacted).Return("The [REDACTED] internal plan")
+	processor.EXPECT().ExtractCodeBlocks(redacted).Return(nil)
+	processor.EXPECT().ExtractHeadings(redacted).Return(nil)
+	store.EXPECT().Save(mock.Anything, mock.MatchedBy(func(doc Document) bool {
+		return doc.Content == string(redacted)
+	})).Return(nil)
+	search.EXPECT().Index(mock.Anything, mock.Anything, "The [REDACTED] internal plan", []CodeBlock(nil), []Heading(nil)).Return(nil)
+
+	resp, err := svc.IngestDocuments(t.Context(), &IngestRequest{
+		Repo: "owner/repo",
+		Documents: []IngestDocument{
+			{Path: "plan.md", Content: "# The secret internal plan", Action: "upsert"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []PolicyFinding{
+		{Path: "plan.md", Rule: "secret", Action: PolicyActionRedact, Line: 1},
+		{Path: "plan.md", Rule: "internal", Action: PolicyActionFlag, Line: 1},
+	}, resp.Policy)
+}
+
+func TestGetDocument_ContentPolicy(t *testing.T) {
+	svc, store, _, processor := newTestService(t, WithContentPolicy(secretPolicy{}))
+
+	store.EXPECT().Get(mock.Anything, "owner/repo", "old.md").
+		Return(Document{Content: "ingested before the secret policy"}, nil).Twice()
+	processor.EXPECT().RenderHTML([]byte("ingested before the [REDACTED] policy")).
+		Return([]byte("<p>ingested before the [REDACTED] policy</p>"), nil, nil)
+
+	doc, html, _, err := svc.GetDocument(t.Context(), "owner/repo", "old.md")
+	require.NoError(t, err)
+	assert.Equal(t, "ingested before the [REDACTED] policy", doc.Content)
+	assert.Equal(t, "<p>ingested before the [REDACTED] policy</p>", string(html))
+
+	src, err := svc.GetDocumentSource(t.Context(), "owner/repo", "old.md")
+	require.NoError(t, err)
+	assert.Equal(t, "ingested before the [REDACTED] policy", src.Content)
+}
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// reservedProjectNames cannot name a sub-project because the stores keep a repository's
+// documents, assets and document extras in directories with these names, next to its
+// sub-projects.
+var reservedProjectNames = map[string]struct{}{
+	"docs":    {},
+	"assets":  {},
+	"docmeta": {},
+}
+
+// validProjectName reports whether name can be used as a sub-project name.
+func validProjectName(name string) bool {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return false
+	}
+
+	_, reserved := reservedProjectNames[strings.ToLower(name)]
+
+	return !reserved
+}
+
+// SplitProject interprets the first segment of a path within repo as a sub-project of a
+// monorepo. It returns the sub-project's repository identifier and the path within the
+// sub-project, which is empty for the sub-project's index ("project/"). It returns false
+// when repo is already a sub-project or the path has no segment that can name one.
+func SplitProject(repo, path string) (projectRepo, rest string, ok bool) {
+	if strings.Count(repo, "/") != 1 {
+		return "", "", false
+	}
+
+	project, rest, found := strings.Cut(path, "/")
+	if !found || !validProjectName(project) {
+		return "", "", false
+	}
+
+	return repo + "/" + project, rest, true
+}
+
+// checkProjectCollisions rejects upserts whose "repo/path" key, under which documents are
+// indexed, is the key of a document of another repository: the document "x.md" of the
+// sub-project "owner/repo/svc" and the document "svc/x.md" of the repository "owner/repo"
+// share the key "owner/repo/svc/x.md". It returns an error wrapping ErrConflict naming the
+// document to delete first.
+func (s *Service) checkProjectCollisions(ctx context.Context, req *IngestRequest) error {
+	if strings.Count(req.Repo, "/") == 2 {
+		i := strings.LastIndex(req.Repo, "/")
+		parent, project := req.Repo[:i], req.Repo[i+1:]
+
+		metas, err := s.store.List(ctx, parent)
+		if err != nil {
+			return fmt.Errorf("failed to list documents: %w", err)
+		}
+
+		taken := make(map[string]struct{}, len(metas))
+		for _, m := range metas {
+			if rest, ok := strings.CutPrefix(m.Path, project+"/"); ok {
+				taken[rest] = struct{}{}
+			}
+		}
+
+		for _, d := range req.Documents {
+			if _, ok := taken[d.Path]; ok && d.Action == actionUpsert {
+				return fmt.Errorf("%w: document %s has the same ID as %s/%s in %s; delete it first", ErrConflict, d.Path, project, d.Path, parent)
+			}
+		}
+
+		return nil
+	}
+
+	byProject := make(map[string][]string)
+
+	for _, d := range req.Documents {
+		if d.Action != actionUpsert {
+			continue
+		}
+
+		if projectRepo, rest, ok := SplitProject(req.Repo, d.Path); ok && rest != "" {
+			byProject[projectRepo] = append(byProject[projectRepo], rest)
+		}
+	}
+
+	for projectRepo, paths := range byProject {
+		metas, err := s.store.List(ctx, projectRepo)
+		if err != nil {
+			return fmt.Errorf("failed to list documents: %w", err)
+		}
+
+		taken := make(map[string]struct{}, len(metas))
+		for _, m := range metas {
+			taken[m.Path] = struct{}{}
+		}
+
+		for _, p := range paths {
+			if _, ok := taken[p]; ok {
+				return fmt.Errorf("%w: document %s has the same ID as %s in %s; delete it first", ErrConflict,
+					strings.TrimPrefix(projectRepo, req.Repo+"/")+"/"+p, p, projectRepo)
+			}
+		}
+	}
+
+	return nil
+}
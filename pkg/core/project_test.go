@@ -0,0 +1,78 @@
+//go:build !compile
+
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitProject(t *testing.T) {
+	tests := []struct {
+		repo     string
+		path     string
+		wantRepo string
+		wantRest string
+		wantOK   bool
+	}{
+		{repo: "owner/repo", path: "service-a/guide/intro.md", wantRepo: "owner/repo/service-a", wantRest: "guide/intro.md", wantOK: true},
+		{repo: "owner/repo", path: "service-a/", wantRepo: "owner/repo/service-a", wantOK: true},
+		{repo: "owner/repo", path: "service-a"},
+		{repo: "owner/repo", path: "docs/guide.md"},
+		{repo: "owner/repo", path: "../guide.md"},
+		{repo: "owner/repo", path: "/guide.md"},
+		{repo: "owner/repo/service-a", path: "nested/guide.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.repo+"/"+tt.path, func(t *testing.T) {
+			gotRepo, gotRest, ok := SplitProject(tt.repo, tt.path)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantRepo, gotRepo)
+			assert.Equal(t, tt.wantRest, gotRest)
+		})
+	}
+}
+
+func TestIngestDocuments_InvalidRepo(t *testing.T) {
+	svc := New(NewMockdocStore(t), NewMocksearchEngine(t), map[ContentType]ContentProcessor{
+		ContentTypeMarkdown: NewMockContentProcessor(t),
+	})
+
+	_, err := svc.IngestDocuments(t.Context(), &IngestRequest{
+		Repo:      "owner/repo/docs",
+		Documents: []IngestDocument{{Path: "guide.md", Content: "# Guide", Action: "upsert"}},
+	})
+	assert.ErrorIs(t, err, ErrInvalidPath)
+}
+
+func TestIngestDocuments_ProjectIDCollision(t *testing.T) {
+	t.Run("sub-project document taken by its repository", func(t *testing.T) {
+		svc, store, _, _ := newTestService(t)
+
+		store.EXPECT().List(mock.Anything, "owner/repo").Return([]DocumentMeta{{Path: "svc/x.md"}, {Path: "y.md"}}, nil)
+
+		_, err := svc.IngestDocuments(t.Context(), &IngestRequest{
+			Repo:      "owner/repo/svc",
+			Documents: []IngestDocument{{Path: "x.md", Content: "# X", Action: actionUpsert}},
+		})
+		require.ErrorIs(t, err, ErrConflict)
+		assert.ErrorContains(t, err, "svc/x.md in owner/repo")
+	})
+
+	t.Run("repository document taken by a sub-project", func(t *testing.T) {
+		svc, store, _, _ := newTestService(t)
+
+		store.EXPECT().List(mock.Anything, "owner/repo/svc").Return([]DocumentMeta{{Path: "x.md"}}, nil)
+
+		_, err := svc.IngestDocuments(t.Context(), &IngestRequest{
+			Repo:      "owner/repo",
+			Documents: []IngestDocument{{Path: "svc/x.md", Content: "# X", Action: actionUpsert}},
+		})
+		require.ErrorIs(t, err, ErrConflict)
+		assert.ErrorContains(t, err, "x.md in owner/repo/svc")
+	})
+}
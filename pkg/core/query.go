@@ -0,0 +1,110 @@
+package core
+
+import "strings"
+
+const (
+	// MaxQueryLen bounds the length of a search query in bytes, for searches and saved
+	// searches alike. Longer queries are rejected rather than parsed.
+	MaxQueryLen = 500
+	// langFilterPrefix introduces a code language filter in a search query, e.g. "lang:go".
+	langFilterPrefix = "lang:"
+)
+
+// ParseLangFilter removes "lang:<name>" tokens from a search query and returns the
+// remaining query text together with the lowercased language. Tokens inside quoted
+// phrases are left untouched. When several languages are given, the last one wins.
+func ParseLangFilter(query string) (text, lang string) {
+	fields := strings.Fields(query)
+	kept := make([]string, 0, len(fields))
+	inPhrase := false
+
+	for _, f := range fields {
+		if !inPhrase {
+			if name, ok := strings.CutPrefix(strings.ToLower(f), langFilterPrefix); ok && name != "" {
+				lang = name
+				continue
+			}
+		}
+
+		if strings.Count(f, `"`)%2 == 1 {
+			inPhrase = !inPhrase
+		}
+
+		kept = append(kept, f)
+	}
+
+	if lang == "" {
+		return query, ""
+	}
+
+	return strings.Join(kept, " "), lang
+}
+
+// narrowingQualifiers are the qualifiers of a search query that narrow the results, e.g.
+// "repo:acme/api", rather than describe what to find.
+var narrowingQualifiers = []string{"repo:", "path:", "tag:"}
+
+// semanticQueryText returns the words of a search query to embed for semantic search: the
+// query without repo:, path: and tag: qualifiers, excluded terms and groups, OR and AND
+// operators, parentheses and quotes. title: qualified words are kept as plain words.
+func semanticQueryText(query string) string {
+	var (
+		kept     []string
+		excluded int  // depth of the excluded group being skipped
+		skipping bool // inside a quoted phrase being skipped
+		inPhrase bool // inside a quoted phrase being kept
+	)
+
+	for _, f := range strings.Fields(query) {
+		oddQuotes := strings.Count(f, `"`)%2 == 1
+
+		switch {
+		case inPhrase:
+			inPhrase = !oddQuotes
+			kept = append(kept, strings.Trim(f, `()"`))
+
+			continue
+		case skipping:
+			skipping = !oddQuotes
+			continue
+		case excluded > 0:
+			excluded = max(excluded+strings.Count(f, "(")-strings.Count(f, ")"), 0)
+			continue
+		case strings.HasPrefix(f, "-("):
+			excluded = max(strings.Count(f, "(")-strings.Count(f, ")"), 0)
+			continue
+		case len(f) > 1 && f[0] == '-', isNarrowingQualifier(strings.TrimLeft(f, "(")):
+			skipping = oddQuotes
+			continue
+		case f == "OR" || f == "AND":
+			continue
+		}
+
+		word := strings.TrimLeft(f, "(")
+		if len(word) > len("title:") && strings.EqualFold(word[:len("title:")], "title:") {
+			word = word[len("title:"):]
+		}
+
+		inPhrase = oddQuotes
+
+		if word = strings.Trim(word, `()"`); word != "" {
+			kept = append(kept, word)
+		}
+	}
+
+	return strings.Join(kept, " ")
+}
+
+// isNarrowingQualifier reports whether a word of a search query is a repo:, path: or tag:
+// qualifier with a value.
+func isNarrowingQualifier(word string) bool {
+	lower := strings.ToLower(word)
+
+	for _, prefix := range narrowingQualifiers {
+		if value, ok := strings.CutPrefix(lower, prefix); ok && value != "" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,38 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func FuzzParseLangFilter(f *testing.F) {
+	for _, seed := range []string{
+		"lang:go retry",
+		`"lang:go in a phrase" lang:Python`,
+		`unbalanced "quote lang:rust`,
+		"lang: LANG:sql\tlang:\u00e9",
+		"",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, query string) {
+		text, lang := ParseLangFilter(query)
+
+		if lang == "" {
+			if text != query {
+				t.Fatalf("query without a language filter changed: %q -> %q", query, text)
+			}
+
+			return
+		}
+
+		if lang != strings.ToLower(lang) {
+			t.Fatalf("language %q is not lowercased", lang)
+		}
+
+		if len(text) > len(query) {
+			t.Fatalf("text %q is longer than query %q", text, query)
+		}
+	})
+}
@@ -0,0 +1,57 @@
+//go:build !compile
+
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLangFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		wantText string
+		wantLang string
+	}{
+		{name: "no filter", query: "getting  started", wantText: "getting  started"},
+		{name: "filter only", query: "lang:go", wantText: "", wantLang: "go"},
+		{name: "filter with text", query: "http handler LANG:Go", wantText: "http handler", wantLang: "go"},
+		{name: "last filter wins", query: "lang:go lang:rust client", wantText: "client", wantLang: "rust"},
+		{name: "empty language ignored", query: "lang: client", wantText: "lang: client"},
+		{name: "filter inside phrase kept", query: `"use lang:go here" lang:python`, wantText: `"use lang:go here"`, wantLang: "python"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, lang := ParseLangFilter(tt.query)
+			assert.Equal(t, tt.wantText, text)
+			assert.Equal(t, tt.wantLang, lang)
+		})
+	}
+}
+
+func TestSemanticQueryText(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "plain words", query: "how to  deploy", want: "how to deploy"},
+		{name: "qualifiers removed", query: "repo:acme/ops deploy Path:docs/** -tag:legacy tag:ci", want: "deploy"},
+		{name: "quoted qualifier removed", query: `deploy repo:"acme/ops"`, want: "deploy"},
+		{name: "excluded terms removed", query: `deploy -kubernetes -"helm chart" nomad`, want: "deploy nomad"},
+		{name: "excluded group removed", query: "deploy -(kubernetes OR (helm charts)) nomad", want: "deploy nomad"},
+		{name: "operators and groups", query: "deploy (kubernetes OR nomad) AND rollback", want: "deploy kubernetes nomad rollback"},
+		{name: "phrase kept", query: `"rolling -back OR repo:x" now`, want: "rolling -back OR repo:x now"},
+		{name: "title words kept", query: "title:getting started", want: "getting started"},
+		{name: "qualifiers only", query: "repo:acme tag:ci", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, semanticQueryText(tt.query))
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Quick search limits.
+const (
+	DefaultQuickLimit = 8
+	MaxQuickLimit     = 20
+	// QuickCacheTTL is how long quick search results are reused, so publishes show up in
+	// them after up to this long.
+	QuickCacheTTL = 30 * time.Second
+	// maxQuickCacheEntries bounds the number of cached quick searches; the cache is
+	// emptied when it is full.
+	maxQuickCacheEntries = 1000
+)
+
+// QuickResult is a document found by a quick search.
+type QuickResult struct {
+	ID    string `json:"id"` // encoded DocumentID of the document
+	Repo  string `json:"repo"`
+	Path  string `json:"path"`
+	Title string `json:"title"`
+}
+
+// quickKey identifies a cached quick search.
+type quickKey struct {
+	query string
+	repos string
+	limit int
+}
+
+// quickEntry is a cached quick search.
+type quickEntry struct {
+	expires time.Time
+	results []QuickResult
+}
+
+// quickCache holds recent quick search results.
+type quickCache struct {
+	entries map[quickKey]quickEntry
+	mu      sync.Mutex
+}
+
+// get returns the cached results of a search, and false if they are missing or expired.
+func (c *quickCache) get(key quickKey, now time.Time) ([]QuickResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok || !now.Before(e.expires) {
+		return nil, false
+	}
+
+	return e.results, true
+}
+
+// put caches the results of a search until now plus QuickCacheTTL.
+func (c *quickCache) put(key quickKey, results []QuickResult, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil || len(c.entries) >= maxQuickCacheEntries {
+		c.entries = make(map[quickKey]quickEntry)
+	}
+
+	c.entries[key] = quickEntry{results: results, expires: now.Add(QuickCacheTTL)}
+}
+
+// QuickSearch returns the titles and paths of up to limit documents matching query,
+// restricted to the given owners or repositories when repos is not empty. It is meant for
+// integrations such as a browser extension's search box, which need an answer fast: it
+// uses keyword search only, skips highlighting, facets, spelling suggestions and
+// summaries, and reuses the results of the same search for QuickCacheTTL. The limit
+// defaults to DefaultQuickLimit and is capped at MaxQuickLimit. A blank query has no
+// results.
+func (s *Service) QuickSearch(ctx context.Context, query string, repos []string, limit int) ([]QuickResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, nil
+	}
+
+	if limit <= 0 {
+		limit = DefaultQuickLimit
+	}
+
+	limit = min(limit, MaxQuickLimit)
+
+	now := time.Now()
+	s.activity.recordSearch(now)
+
+	key := quickKey{query: query, repos: strings.Join(slices.Sorted(slices.Values(repos)), "\n"), limit: limit}
+	if results, ok := s.quick.get(key, now); ok {
+		return results, nil
+	}
+
+	text, lang := ParseLangFilter(query)
+
+	results, err := s.search.Search(ctx, text, SearchOpts{Lang: lang, Repos: repos, Limit: limit, Quick: true})
+	if err != nil {
+		return nil, fmt.Errorf("quick search failed: %w", err)
+	}
+
+	quick := make([]QuickResult, 0, len(results.Hits))
+	for _, hit := range results.Hits {
+		quick = append(quick, QuickResult{ID: NewDocumentID(hit.Repo, hit.Path).Encode(), Repo: hit.Repo, Path: hit.Path, Title: hit.Title})
+	}
+
+	s.quick.put(key, quick, now)
+
+	return quick, nil
+}
@@ -0,0 +1,89 @@
+//go:build !compile
+
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuickSearch(t *testing.T) {
+	svc, _, search, _ := newTestService(t)
+
+	search.EXPECT().Search(mock.Anything, "deploy", SearchOpts{Lang: "go", Repos: []string{"acme"}, Limit: 5, Quick: true}).
+		Return(&SearchResults{
+			Hits: []SearchResult{{ID: "acme/api/deploy.md", Repo: "acme/api", Path: "deploy.md", Title: "Deploying", Score: 1.5}},
+		}, nil).Once()
+
+	want := []QuickResult{{ID: NewDocumentID("acme/api", "deploy.md").Encode(), Repo: "acme/api", Path: "deploy.md", Title: "Deploying"}}
+
+	results, err := svc.QuickSearch(t.Context(), " deploy lang:go ", []string{"acme"}, 5)
+	require.NoError(t, err)
+	assert.Equal(t, want, results)
+
+	// The same search is answered from the cache.
+	results, err = svc.QuickSearch(t.Context(), "deploy lang:go", []string{"acme"}, 5)
+	require.NoError(t, err)
+	assert.Equal(t, want, results)
+}
+
+func TestQuickSearch_Limits(t *testing.T) {
+	tests := []struct {
+		name   string
+		limit  int
+		engine int
+	}{
+		{name: "default limit", limit: 0, engine: DefaultQuickLimit},
+		{name: "capped limit", limit: 100, engine: MaxQuickLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _, search, _ := newTestService(t)
+
+			search.EXPECT().Search(mock.Anything, "deploy", SearchOpts{Limit: tt.engine, Quick: true}).
+				Return(&SearchResults{}, nil)
+
+			results, err := svc.QuickSearch(t.Context(), "deploy", nil, tt.limit)
+			require.NoError(t, err)
+			assert.Empty(t, results)
+		})
+	}
+}
+
+func TestQuickSearch_BlankQuery(t *testing.T) {
+	svc := newTestServiceOnly(t)
+
+	results, err := svc.QuickSearch(t.Context(), "  ", nil, 5)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestQuickSearch_Error(t *testing.T) {
+	svc, _, search, _ := newTestService(t)
+
+	search.EXPECT().Search(mock.Anything, "deploy", mock.Anything).Return(nil, errors.New("index unavailable"))
+
+	_, err := svc.QuickSearch(t.Context(), "deploy", nil, 0)
+	require.ErrorContains(t, err, "quick search failed")
+}
+
+func TestQuickCache_Expiry(t *testing.T) {
+	var c quickCache
+
+	now := time.Now()
+	key := quickKey{query: "deploy", limit: 8}
+
+	c.put(key, []QuickResult{{Repo: "acme/api", Path: "deploy.md"}}, now)
+
+	_, ok := c.get(key, now.Add(QuickCacheTTL-time.Second))
+	assert.True(t, ok)
+
+	_, ok = c.get(key, now.Add(QuickCacheTTL))
+	assert.False(t, ok)
+}
@@ -0,0 +1,396 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ShadowIndex is a search index built alongside the live one, for example after a change
+// of mapping or analyzers, while the live index keeps serving. Until it is promoted or
+// discarded, changes made to the live index are applied to the shadow index as well.
+type ShadowIndex interface {
+	searchEngine
+	// Promote atomically replaces the live index with the shadow index.
+	Promote(ctx context.Context) error
+	// Discard deletes the shadow index and keeps the live one.
+	Discard(ctx context.Context) error
+}
+
+// ShadowIndexer is implemented by search engines that can build a shadow index.
+type ShadowIndexer interface {
+	CreateShadow(ctx context.Context) (ShadowIndex, error)
+}
+
+// Index rebuild states reported by IndexRebuildStatus.
+const (
+	RebuildBuilding  = "building"
+	RebuildComparing = "comparing"
+	RebuildPromoted  = "promoted"
+	RebuildDiscarded = "discarded"
+	RebuildFailed    = "failed"
+)
+
+// defaultMinOverlap is the mean overlap of the top results below which a rebuilt index is
+// not promoted, unless forced.
+const defaultMinOverlap = 0.5
+
+// rebuildSampleSize is the number of document titles sampled as comparison queries when
+// none are given.
+const rebuildSampleSize = 20
+
+// rebuildTopHits is the number of top results compared per query.
+const rebuildTopHits = 10
+
+// IndexRebuildRequest configures a blue/green rebuild of the search index.
+type IndexRebuildRequest struct {
+	// Queries compare the live and rebuilt index. When empty, titles of stored documents
+	// are sampled.
+	Queries []string `json:"queries,omitempty"`
+	// MinOverlap is the mean overlap of the top results, between 0 and 1, the rebuilt index
+	// needs to be promoted. Zero uses the default of 0.5.
+	MinOverlap float64 `json:"min_overlap,omitempty"`
+	// Force promotes the rebuilt index whatever the comparison shows.
+	Force bool `json:"force,omitempty"`
+	// DryRun builds and compares the rebuilt index, then discards it.
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// QueryComparison compares the results of a query on the live index and on a rebuilt index
+// or the engine a search migration writes to.
+type QueryComparison struct {
+	Query       string  `json:"query"`
+	LiveTotal   uint64  `json:"live_total"`
+	ShadowTotal uint64  `json:"shadow_total"`
+	Overlap     float64 `json:"overlap"` // share of the top results found by both indexes
+}
+
+// IndexRebuildStatus reports the progress and outcome of a blue/green index rebuild.
+type IndexRebuildStatus struct {
+	StartedAt   time.Time         `json:"started_at"`
+	FinishedAt  time.Time         `json:"finished_at,omitzero"`
+	State       string            `json:"state"`
+	Error       string            `json:"error,omitempty"`
+	Comparisons []QueryComparison `json:"comparisons,omitempty"`
+	Indexed     int               `json:"indexed"`
+	Failed      int               `json:"failed"`
+	Overlap     float64           `json:"overlap"` // mean overlap of the comparisons
+	MinOverlap  float64           `json:"min_overlap"`
+	Force       bool              `json:"force,omitempty"`
+	DryRun      bool              `json:"dry_run,omitempty"`
+}
+
+// Done reports whether the rebuild has finished.
+func (st *IndexRebuildStatus) Done() bool {
+	return st.State == RebuildPromoted || st.State == RebuildDiscarded || st.State == RebuildFailed
+}
+
+// indexRebuild holds the status of the latest blue/green rebuild. It is kept in memory and
+// is lost on restart.
+type indexRebuild struct {
+	status *IndexRebuildStatus
+	mu     sync.Mutex
+}
+
+// StartIndexRebuild rebuilds the search index in the background without taking the live
+// index offline: a shadow index is built from the document store while the live one keeps
+// serving, the results of sampled queries are compared on both, and the shadow index
+// replaces the live one if the results overlap enough, or is discarded otherwise. It
+// returns an error wrapping ErrNotSupported if the search engine cannot build a shadow
+// index, and ErrConflict if a reindex is already running.
+func (s *Service) StartIndexRebuild(ctx context.Context, req IndexRebuildRequest) error {
+	indexer, ok := s.search.(ShadowIndexer)
+	if !ok {
+		return fmt.Errorf("%w: the search engine cannot rebuild its index alongside the live one", ErrNotSupported)
+	}
+
+	if !s.reindexing.CompareAndSwap(false, true) {
+		return fmt.Errorf("%w: a reindex is already running", ErrConflict)
+	}
+
+	if req.MinOverlap == 0 {
+		req.MinOverlap = defaultMinOverlap
+	}
+
+	s.rebuild.set(&IndexRebuildStatus{
+		StartedAt:  time.Now().UTC(),
+		State:      RebuildBuilding,
+		MinOverlap: req.MinOverlap,
+		Force:      req.Force,
+		DryRun:     req.DryRun,
+	})
+
+	go func() {
+		defer s.reindexing.Store(false)
+
+		ctx := context.WithoutCancel(ctx)
+
+		slog.InfoContext(ctx, "rebuilding search index alongside the live index")
+
+		if err := s.rebuildIndex(ctx, indexer, req); err != nil {
+			slog.ErrorContext(ctx, "blue/green index rebuild failed", "error", err)
+
+			s.rebuild.update(func(st *IndexRebuildStatus) {
+				st.State = RebuildFailed
+				st.Error = err.Error()
+			})
+		}
+
+		s.rebuild.update(func(st *IndexRebuildStatus) {
+			st.FinishedAt = time.Now().UTC()
+		})
+	}()
+
+	return nil
+}
+
+// IndexRebuildStatus returns the status of the latest blue/green index rebuild. It returns
+// ErrNotFound if no rebuild was started since the server started.
+func (s *Service) IndexRebuildStatus(_ context.Context) (*IndexRebuildStatus, error) {
+	s.rebuild.mu.Lock()
+	defer s.rebuild.mu.Unlock()
+
+	if s.rebuild.status == nil {
+		return nil, fmt.Errorf("%w: no index rebuild was started", ErrNotFound)
+	}
+
+	status := *s.rebuild.status
+	status.Comparisons = append([]QueryComparison(nil), status.Comparisons...)
+
+	return &status, nil
+}
+
+// rebuildIndex builds, compares and then promotes or discards a shadow index.
+func (s *Service) rebuildIndex(ctx context.Context, indexer ShadowIndexer, req IndexRebuildRequest) error {
+	shadow, err := indexer.CreateShadow(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create shadow index: %w", err)
+	}
+
+	queries, err := s.fillShadow(ctx, shadow, req.Queries)
+	if err != nil {
+		return discardShadow(ctx, shadow, err)
+	}
+
+	s.rebuild.update(func(st *IndexRebuildStatus) { st.State = RebuildComparing })
+
+	comparisons, overlap, err := compareIndexes(ctx, s.search, shadow, queries)
+	if err != nil {
+		return discardShadow(ctx, shadow, err)
+	}
+
+	s.rebuild.update(func(st *IndexRebuildStatus) {
+		st.Comparisons = comparisons
+		st.Overlap = overlap
+	})
+
+	if req.DryRun || (overlap < req.MinOverlap && !req.Force) {
+		if err := shadow.Discard(ctx); err != nil {
+			return fmt.Errorf("failed to discard shadow index: %w", err)
+		}
+
+		slog.InfoContext(ctx, "rebuilt search index discarded", "overlap", overlap, "min_overlap", req.MinOverlap, "dry_run", req.DryRun)
+		s.rebuild.update(func(st *IndexRebuildStatus) { st.State = RebuildDiscarded })
+
+		return nil
+	}
+
+	if err := shadow.Promote(ctx); err != nil {
+		return fmt.Errorf("failed to promote shadow index: %w", err)
+	}
+
+	s.invalidateSearches()
+
+	slog.InfoContext(ctx, "rebuilt search index promoted", "overlap", overlap, "min_overlap", req.MinOverlap, "forced", req.Force)
+	s.rebuild.update(func(st *IndexRebuildStatus) { st.State = RebuildPromoted })
+
+	return nil
+}
+
+// fillShadow indexes every stored document into the shadow index and removes entries of
+// documents deleted while it was being built. It returns the comparison queries, sampling
+// document titles when queries is empty.
+func (s *Service) fillShadow(ctx context.Context, shadow ShadowIndex, queries []string) ([]string, error) {
+	repos, err := s.store.ListRepos(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repos: %w", err)
+	}
+
+	var titles []string
+
+	for _, repo := range repos {
+		docs, err := s.store.List(ctx, repo.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list documents for repo %s: %w", repo.Name, err)
+		}
+
+		for _, meta := range docs {
+			if err := s.reindexDocument(ctx, shadow, repo.Name, meta.Path); err != nil {
+				slog.WarnContext(ctx, "failed to index document into shadow index", "repo", repo.Name, "path", meta.Path, "error", err)
+				s.rebuild.update(func(st *IndexRebuildStatus) { st.Failed++ })
+
+				continue
+			}
+
+			s.rebuild.update(func(st *IndexRebuildStatus) { st.Indexed++ })
+
+			if meta.Title != "" {
+				titles = append(titles, meta.Title)
+			}
+		}
+
+		if err := s.pruneShadow(ctx, shadow, repo.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(queries) > 0 {
+		return queries, nil
+	}
+
+	return sampleQueries(titles, rebuildSampleSize), nil
+}
+
+// pruneShadow removes shadow index entries of a repository whose documents are no longer
+// stored, such as documents deleted after they were read for the rebuild.
+func (s *Service) pruneShadow(ctx context.Context, shadow ShadowIndex, repo string) error {
+	docs, err := s.store.List(ctx, repo)
+	if err != nil {
+		return fmt.Errorf("failed to list documents for repo %s: %w", repo, err)
+	}
+
+	stored := make(map[string]struct{}, len(docs))
+	for _, meta := range docs {
+		stored[NewDocumentID(repo, meta.Path).String()] = struct{}{}
+	}
+
+	indexed, err := shadow.ListByRepo(ctx, repo)
+	if err != nil {
+		return fmt.Errorf("failed to list shadow index entries for repo %s: %w", repo, err)
+	}
+
+	for _, docID := range indexed {
+		if _, ok := stored[docID]; ok {
+			continue
+		}
+
+		if err := shadow.Remove(ctx, docID); err != nil {
+			return fmt.Errorf("failed to remove stale shadow index entry %s: %w", docID, err)
+		}
+	}
+
+	return nil
+}
+
+// compareIndexes runs the queries on the live and the other index and returns the
+// comparison of each query with the mean overlap of their top results. Without queries,
+// for example for an empty store, the overlap is 1.
+func compareIndexes(ctx context.Context, live, other searchEngine, queries []string) ([]QueryComparison, float64, error) {
+	comparisons := make([]QueryComparison, 0, len(queries))
+
+	var sum float64
+
+	for _, query := range queries {
+		opts := SearchOpts{Limit: rebuildTopHits}
+
+		liveResults, err := live.Search(ctx, query, opts)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to search live index for %q: %w", query, err)
+		}
+
+		otherResults, err := other.Search(ctx, query, opts)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to search compared index for %q: %w", query, err)
+		}
+
+		c := QueryComparison{
+			Query:       query,
+			LiveTotal:   liveResults.Total,
+			ShadowTotal: otherResults.Total,
+			Overlap:     hitOverlap(liveResults.Hits, otherResults.Hits),
+		}
+
+		comparisons = append(comparisons, c)
+		sum += c.Overlap
+	}
+
+	if len(comparisons) == 0 {
+		return comparisons, 1, nil
+	}
+
+	return comparisons, sum / float64(len(comparisons)), nil
+}
+
+// discardShadow discards the shadow index after the rebuild failed with err.
+func discardShadow(ctx context.Context, shadow ShadowIndex, err error) error {
+	if discardErr := shadow.Discard(ctx); discardErr != nil {
+		slog.ErrorContext(ctx, "failed to discard shadow index", "error", discardErr)
+	}
+
+	return err
+}
+
+// hitOverlap returns the share of the hits found by both searches, relative to the longer
+// list. Two empty lists overlap fully.
+func hitOverlap(a, b []SearchResult) float64 {
+	longest := max(len(a), len(b))
+	if longest == 0 {
+		return 1
+	}
+
+	ids := make(map[string]struct{}, len(a))
+	for _, hit := range a {
+		ids[hit.ID] = struct{}{}
+	}
+
+	var common int
+
+	for _, hit := range b {
+		if _, ok := ids[hit.ID]; ok {
+			common++
+		}
+	}
+
+	return float64(common) / float64(longest)
+}
+
+// sampleQueries picks up to n distinct titles spread evenly over titles.
+func sampleQueries(titles []string, n int) []string {
+	seen := make(map[string]struct{}, n)
+	queries := make([]string, 0, n)
+
+	step := max(len(titles)/n, 1)
+
+	for i := 0; i < len(titles) && len(queries) < n; i += step {
+		title := strings.TrimSpace(titles[i])
+		if _, ok := seen[title]; ok || title == "" {
+			continue
+		}
+
+		seen[title] = struct{}{}
+		queries = append(queries, title)
+	}
+
+	return queries
+}
+
+// set replaces the status with st.
+func (r *indexRebuild) set(st *IndexRebuildStatus) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.status = st
+}
+
+// update applies fn to the status.
+func (r *indexRebuild) update(fn func(st *IndexRebuildStatus)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.status != nil {
+		fn(r.status)
+	}
+}
@@ -0,0 +1,172 @@
+//go:build !compile
+
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// shadowingEngine is a search engine that can build shadow indexes.
+type shadowingEngine struct {
+	*MocksearchEngine
+	*MockShadowIndexer
+}
+
+// withShadowIndexer makes the search engine of a test service build shadow indexes with
+// indexer.
+func withShadowIndexer(indexer *MockShadowIndexer) Option {
+	return func(s *Service) {
+		search, _ := s.search.(*MocksearchEngine)
+		s.search = shadowingEngine{MocksearchEngine: search, MockShadowIndexer: indexer}
+	}
+}
+
+// expectRebuild registers the calls of a rebuild of the index of owner/repo, holding
+// setup.md and faq.md and once gone.md, into a shadow index made by indexer, and returns
+// the shadow index.
+func expectRebuild(t *testing.T, store *MockdocStore, processor *MockContentProcessor, indexer *MockShadowIndexer) *MockShadowIndex {
+	t.Helper()
+
+	shadow := NewMockShadowIndex(t)
+
+	indexer.EXPECT().CreateShadow(mock.Anything).Return(shadow, nil).Once()
+	processor.EXPECT().ToPlainText(mock.Anything).Return("text").Maybe()
+	processor.EXPECT().ExtractCodeBlocks(mock.Anything).Return(nil).Maybe()
+	processor.EXPECT().ExtractHeadings(mock.Anything).Return(nil).Maybe()
+
+	store.EXPECT().ListRepos(mock.Anything).Return([]RepoInfo{{Name: "owner/repo"}}, nil)
+	store.EXPECT().List(mock.Anything, "owner/repo").Return([]DocumentMeta{
+		{Repo: "owner/repo", Path: "setup.md", Title: "Setup Guide"},
+		{Repo: "owner/repo", Path: "faq.md", Title: "FAQ"},
+	}, nil)
+	store.EXPECT().Get(mock.Anything, "owner/repo", "setup.md").Return(Document{ID: "owner/repo/setup.md", Repo: "owner/repo", Path: "setup.md"}, nil)
+	store.EXPECT().Get(mock.Anything, "owner/repo", "faq.md").Return(Document{ID: "owner/repo/faq.md", Repo: "owner/repo", Path: "faq.md"}, nil)
+
+	shadow.EXPECT().Index(mock.Anything, mock.Anything, "text", []CodeBlock(nil), []Heading(nil)).Return(nil).Twice()
+	shadow.EXPECT().ListByRepo(mock.Anything, "owner/repo").Return([]string{"owner/repo/setup.md", "owner/repo/faq.md", "owner/repo/gone.md"}, nil)
+	shadow.EXPECT().Remove(mock.Anything, "owner/repo/gone.md").Return(nil).Once()
+
+	return shadow
+}
+
+// waitForRebuild waits for the index rebuild to finish and returns its status.
+func waitForRebuild(t *testing.T, svc *Service) *IndexRebuildStatus {
+	t.Helper()
+
+	var status *IndexRebuildStatus
+
+	require.Eventually(t, func() bool {
+		st, err := svc.IndexRebuildStatus(t.Context())
+		require.NoError(t, err)
+
+		status = st
+
+		return st.Done() && !st.FinishedAt.IsZero() && !svc.reindexing.Load()
+	}, time.Second, 5*time.Millisecond)
+
+	return status
+}
+
+func TestStartIndexRebuild_Promote(t *testing.T) {
+	indexer := NewMockShadowIndexer(t)
+	svc, store, search, processor := newTestService(t, withShadowIndexer(indexer))
+	shadow := expectRebuild(t, store, processor, indexer)
+
+	hits := &SearchResults{Hits: []SearchResult{{ID: "owner/repo/setup.md"}}, Total: 1}
+
+	for _, query := range []string{"Setup Guide", "FAQ"} {
+		search.EXPECT().Search(mock.Anything, query, SearchOpts{Limit: rebuildTopHits}).Return(hits, nil).Once()
+		shadow.EXPECT().Search(mock.Anything, query, SearchOpts{Limit: rebuildTopHits}).Return(hits, nil).Once()
+	}
+
+	shadow.EXPECT().Promote(mock.Anything).Return(nil).Once()
+
+	require.NoError(t, svc.StartIndexRebuild(t.Context(), IndexRebuildRequest{}))
+
+	status := waitForRebuild(t, svc)
+	assert.Equal(t, RebuildPromoted, status.State)
+	assert.Equal(t, 2, status.Indexed)
+	assert.InDelta(t, 1.0, status.Overlap, 0.001)
+	assert.InDelta(t, defaultMinOverlap, status.MinOverlap, 0.001)
+	assert.Len(t, status.Comparisons, 2)
+}
+
+func TestStartIndexRebuild_Discard(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		req  IndexRebuildRequest
+	}{
+		{name: "results differ", req: IndexRebuildRequest{Queries: []string{"install"}, MinOverlap: 0.8}, want: RebuildDiscarded},
+		{name: "dry run", req: IndexRebuildRequest{Queries: []string{"install"}, Force: true, DryRun: true}, want: RebuildDiscarded},
+		{name: "forced", req: IndexRebuildRequest{Queries: []string{"install"}, Force: true}, want: RebuildPromoted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			indexer := NewMockShadowIndexer(t)
+			svc, store, search, processor := newTestService(t, withShadowIndexer(indexer))
+			shadow := expectRebuild(t, store, processor, indexer)
+
+			search.EXPECT().Search(mock.Anything, "install", mock.Anything).Return(&SearchResults{
+				Hits:  []SearchResult{{ID: "owner/repo/setup.md"}, {ID: "owner/repo/faq.md"}},
+				Total: 2,
+			}, nil).Once()
+			shadow.EXPECT().Search(mock.Anything, "install", mock.Anything).Return(&SearchResults{
+				Hits:  []SearchResult{{ID: "owner/repo/setup.md"}},
+				Total: 1,
+			}, nil).Once()
+
+			if tt.want == RebuildPromoted {
+				shadow.EXPECT().Promote(mock.Anything).Return(nil).Once()
+			} else {
+				shadow.EXPECT().Discard(mock.Anything).Return(nil).Once()
+			}
+
+			require.NoError(t, svc.StartIndexRebuild(t.Context(), tt.req))
+
+			status := waitForRebuild(t, svc)
+			assert.Equal(t, tt.want, status.State)
+			assert.InDelta(t, 0.5, status.Overlap, 0.001)
+			assert.Equal(t, []QueryComparison{{Query: "install", LiveTotal: 2, ShadowTotal: 1, Overlap: 0.5}}, status.Comparisons)
+		})
+	}
+}
+
+func TestStartIndexRebuild_Errors(t *testing.T) {
+	t.Run("not supported", func(t *testing.T) {
+		svc := newTestServiceOnly(t)
+
+		assert.ErrorIs(t, svc.StartIndexRebuild(t.Context(), IndexRebuildRequest{}), ErrNotSupported)
+
+		_, err := svc.IndexRebuildStatus(t.Context())
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("reindex running", func(t *testing.T) {
+		svc, _, _, _ := newTestService(t, withShadowIndexer(NewMockShadowIndexer(t)))
+		svc.reindexing.Store(true)
+
+		assert.ErrorIs(t, svc.StartIndexRebuild(t.Context(), IndexRebuildRequest{}), ErrConflict)
+	})
+}
+
+func TestHitOverlap(t *testing.T) {
+	a := []SearchResult{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}}
+
+	assert.InDelta(t, 1.0, hitOverlap(nil, nil), 0.001)
+	assert.InDelta(t, 0.0, hitOverlap(a, nil), 0.001)
+	assert.InDelta(t, 0.5, hitOverlap(a, []SearchResult{{ID: "d"}, {ID: "a"}, {ID: "x"}}), 0.001)
+	assert.InDelta(t, 1.0, hitOverlap(a, []SearchResult{{ID: "d"}, {ID: "c"}, {ID: "b"}, {ID: "a"}}), 0.001)
+}
+
+func TestSampleQueries(t *testing.T) {
+	assert.Equal(t, []string{"A", "B"}, sampleQueries([]string{"A", " B ", "A", ""}, 5))
+	assert.Equal(t, []string{"t0", "t3", "t6"}, sampleQueries([]string{"t0", "t1", "t2", "t3", "t4", "t5", "t6", "t7", "t8", "t9"}, 3))
+	assert.Empty(t, sampleQueries(nil, 3))
+}
@@ -0,0 +1,35 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+)
+
+// errProcessingPanic marks the error of a document whose processing panicked.
+var errProcessingPanic = errors.New("content processing panicked")
+
+// FailedDocument is a document of an ingest request that could not be processed, such as
+// malformed content that crashed its content processor. Its new content is not indexed.
+type FailedDocument struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// recoverDocument runs fn, the processing of a single document, and turns a panic into an
+// error wrapping errProcessingPanic, so that one malformed document cannot abort a whole
+// batch or crash the server. The panic is logged with its stack trace to help reproduce it.
+func recoverDocument(ctx context.Context, repo, path string, fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.ErrorContext(ctx, "panic while processing document",
+				"repo", repo, "path", path, "panic", r, "stack", string(debug.Stack()))
+
+			err = fmt.Errorf("%w: %v", errProcessingPanic, r)
+		}
+	}()
+
+	return fn()
+}
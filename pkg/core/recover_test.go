@@ -0,0 +1,58 @@
+//go:build !compile
+
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoverDocument(t *testing.T) {
+	assert.NoError(t, recoverDocument(t.Context(), "owner/repo", "a.md", func() error { return nil }))
+
+	errBoom := errors.New("boom")
+	assert.ErrorIs(t, recoverDocument(t.Context(), "owner/repo", "a.md", func() error { return errBoom }), errBoom)
+
+	err := recoverDocument(t.Context(), "owner/repo", "a.md", func() error { panic("index out of range") })
+	require.ErrorIs(t, err, errProcessingPanic)
+	assert.ErrorContains(t, err, "index out of range")
+}
+
+func TestIngestDocuments_PanicIsolated(t *testing.T) {
+	svc, store, search, processor := newTestService(t)
+
+	processor.EXPECT().ExtractTitle([]byte("# Bad")).Panic("malformed table")
+	processor.EXPECT().ExtractTitle([]byte("# Good")).Return("Good")
+	processor.EXPECT().ToPlainText(mock.Anything).Return("Good")
+	processor.EXPECT().ExtractCodeBlocks(mock.Anything).Return(nil)
+	processor.EXPECT().ExtractHeadings(mock.Anything).Return(nil)
+	store.EXPECT().Save(mock.Anything, mock.MatchedBy(func(doc Document) bool { return doc.Path == "good.md" })).Return(nil)
+	search.EXPECT().Index(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	resp, err := svc.IngestDocuments(t.Context(), &IngestRequest{
+		Repo: "owner/repo",
+		Documents: []IngestDocument{
+			{Path: "bad.md", Content: "# Bad", Action: actionUpsert},
+			{Path: "good.md", Content: "# Good", Action: actionUpsert},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, resp.Indexed)
+	require.Len(t, resp.Failed, 1)
+	assert.Equal(t, "bad.md", resp.Failed[0].Path)
+	assert.Contains(t, resp.Failed[0].Error, "malformed table")
+}
+
+func TestGetDocument_RenderPanic(t *testing.T) {
+	svc, store, _, processor := newTestService(t)
+
+	store.EXPECT().Get(mock.Anything, "owner/repo", "bad.md").Return(Document{Content: "# Bad"}, nil)
+	processor.EXPECT().RenderHTML(mock.Anything).Panic("nil node")
+
+	_, _, _, err := svc.GetDocument(t.Context(), "owner/repo", "bad.md")
+	assert.ErrorIs(t, err, errProcessingPanic)
+}
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// ReindexAll rebuilds the search index from the document store by re-indexing every
+// stored document. It is used after the search index has been recreated, for example
+// when its schema changed. Documents that fail to load or index are logged and skipped
+// so that a single bad document does not leave the rest of the index empty. It returns
+// the number of documents indexed and the number that failed.
+func (s *Service) ReindexAll(ctx context.Context) (indexed, failed int, err error) {
+	defer s.invalidateSearches()
+
+	repos, err := s.store.ListRepos(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to list repos: %w", err)
+	}
+
+	for _, repo := range repos {
+		docs, err := s.store.List(ctx, repo.Name)
+		if err != nil {
+			return indexed, failed, fmt.Errorf("failed to list documents for repo %s: %w", repo.Name, err)
+		}
+
+		for _, meta := range docs {
+			if err := ctx.Err(); err != nil {
+				return indexed, failed, fmt.Errorf("reindex cancelled: %w", err)
+			}
+
+			if err := s.reindexDocument(ctx, s.search, repo.Name, meta.Path); err != nil {
+				slog.WarnContext(ctx, "failed to reindex document", "repo", repo.Name, "path", meta.Path, "error", err)
+
+				failed++
+
+				continue
+			}
+
+			indexed++
+		}
+	}
+
+	return indexed, failed, nil
+}
+
+// reindexDocument loads a stored document and adds it to the given search index.
+func (s *Service) reindexDocument(ctx context.Context, index searchEngine, repo, path string) error {
+	doc, err := s.store.Get(ctx, repo, path)
+	if err != nil {
+		return fmt.Errorf("failed to get document: %w", err)
+	}
+
+	_, _, err = s.indexDocument(ctx, index, &doc)
+
+	return err
+}
+
+// indexDocument adds a document to the given search index, unless it is a draft, and
+// returns its plain text and code blocks. The anchor map indexed with it is mapped again when the one
+// stored with the document is not current.
+func (s *Service) indexDocument(ctx context.Context, index searchEngine, doc *Document) (string, []CodeBlock, error) {
+	var (
+		plainText string
+		code      []CodeBlock
+		headings  []Heading
+	)
+
+	err := recoverDocument(ctx, doc.Repo, doc.Path, func() error {
+		processor := s.getProcessor(doc.ContentType, doc.Repo)
+		plainText = documentPlainText(doc, processor)
+		code = processor.ExtractCodeBlocks([]byte(doc.Content))
+		headings = documentHeadings(doc, processor)
+		doc.Anchors = currentAnchors(doc, processor, plainText, headings)
+
+		return nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err := indexSearchable(ctx, index, doc, plainText, code, headings); err != nil {
+		return "", nil, fmt.Errorf("failed to index document: %w", err)
+	}
+
+	return plainText, code, nil
+}
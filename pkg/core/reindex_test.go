@@ -0,0 +1,111 @@
+//go:build !compile
+
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReindexAll(t *testing.T) {
+	svc, store, search, processor := newTestService(t)
+
+	guide := Document{ID: "owner/repo/guide.md", Repo: "owner/repo", Path: "guide.md", Content: "# Guide"}
+
+	store.EXPECT().ListRepos(mock.Anything).Return([]RepoInfo{{Name: "owner/repo"}}, nil)
+	store.EXPECT().List(mock.Anything, "owner/repo").Return([]DocumentMeta{
+		{Repo: "owner/repo", Path: "guide.md"},
+		{Repo: "owner/repo", Path: "broken.md"},
+	}, nil)
+	store.EXPECT().Get(mock.Anything, "owner/repo", "guide.md").Return(guide, nil)
+	store.EXPECT().Get(mock.Anything, "owner/repo", "broken.md").Return(Document{}, errors.New("disk failure"))
+	processor.EXPECT().ToPlainText([]byte("# Guide")).Return("Guide")
+	processor.EXPECT().ExtractCodeBlocks([]byte("# Guide")).Return(nil)
+	processor.EXPECT().ExtractHeadings([]byte("# Guide")).Return(nil)
+
+	// The document is indexed with the anchor map of its text.
+	indexedGuide := guide
+	indexedGuide.Anchors = AnchorMap{}
+	search.EXPECT().Index(mock.Anything, indexedGuide, "Guide", []CodeBlock(nil), []Heading(nil)).Return(nil)
+
+	indexed, failed, err := svc.ReindexAll(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 1, indexed)
+	assert.Equal(t, 1, failed)
+}
+
+func TestReindexAll_StoredText(t *testing.T) {
+	svc, store, search, processor := newTestService(t)
+
+	headings := []Heading{{ID: "guide", Text: "Guide", Level: 1}}
+	guide := Document{Repo: "owner/repo", Path: "guide.md", Content: "# Guide", Anchors: AnchorMap{{ID: "guide", Text: "Guide", Level: 1}}}
+	guide.Text = newDocumentText(guide.Content, processor, "Guide", headings)
+
+	stale := Document{Repo: "owner/repo", Path: "stale.md", Content: "# Changed"}
+	stale.Text = newDocumentText("# Stale", processor, "Stale", nil)
+
+	reconfigured := Document{Repo: "owner/repo", Path: "setup.md", Content: "# Setup", Anchors: AnchorMap{{ID: "old-setup", Text: "Setup", Level: 1}}}
+	reconfigured.Text = newDocumentText(reconfigured.Content, processor, "Setup", nil)
+	reconfigured.Text.Processor = "*markdown.Renderer:previous-options"
+
+	store.EXPECT().ListRepos(mock.Anything).Return([]RepoInfo{{Name: "owner/repo"}}, nil)
+	store.EXPECT().List(mock.Anything, "owner/repo").Return([]DocumentMeta{
+		{Repo: "owner/repo", Path: "guide.md"},
+		{Repo: "owner/repo", Path: "stale.md"},
+		{Repo: "owner/repo", Path: "setup.md"},
+	}, nil)
+	store.EXPECT().Get(mock.Anything, "owner/repo", "guide.md").Return(guide, nil)
+	store.EXPECT().Get(mock.Anything, "owner/repo", "stale.md").Return(stale, nil)
+	store.EXPECT().Get(mock.Anything, "owner/repo", "setup.md").Return(reconfigured, nil)
+
+	// Only the documents whose content changed since their text was stored, or whose text
+	// was extracted by a processor configured differently, are converted.
+	processor.EXPECT().ExtractCodeBlocks(mock.Anything).Return(nil)
+	processor.EXPECT().ToPlainText([]byte("# Changed")).Return("Changed")
+	processor.EXPECT().ExtractHeadings([]byte("# Changed")).Return(nil)
+	processor.EXPECT().ToPlainText([]byte("# Setup")).Return("Setup")
+	processor.EXPECT().ExtractHeadings([]byte("# Setup")).Return([]Heading{{ID: "setup", Text: "Setup", Level: 1}})
+
+	// The stored anchor map is indexed while current, and mapped again otherwise.
+	indexedStale := stale
+	indexedStale.Anchors = AnchorMap{}
+	indexedReconfigured := reconfigured
+	indexedReconfigured.Anchors = AnchorMap{{ID: "setup", Text: "Setup", Level: 1}}
+
+	search.EXPECT().Index(mock.Anything, guide, "Guide", []CodeBlock(nil), headings).Return(nil)
+	search.EXPECT().Index(mock.Anything, indexedStale, "Changed", []CodeBlock(nil), []Heading(nil)).Return(nil)
+	search.EXPECT().Index(mock.Anything, indexedReconfigured, "Setup", []CodeBlock(nil), []Heading{{ID: "setup", Text: "Setup", Level: 1}}).Return(nil)
+
+	indexed, failed, err := svc.ReindexAll(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 3, indexed)
+	assert.Zero(t, failed)
+}
+
+func TestReindexAll_ListReposError(t *testing.T) {
+	svc, store, _, _ := newTestService(t)
+
+	store.EXPECT().ListRepos(mock.Anything).Return(nil, errors.New("disk failure"))
+
+	_, _, err := svc.ReindexAll(t.Context())
+	assert.ErrorContains(t, err, "failed to list repos")
+}
+
+func TestReindexAll_Cancelled(t *testing.T) {
+	svc, store, _, _ := newTestService(t)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	store.EXPECT().ListRepos(mock.Anything).Return([]RepoInfo{{Name: "owner/repo"}}, nil)
+	store.EXPECT().List(mock.Anything, "owner/repo").Return([]DocumentMeta{{Repo: "owner/repo", Path: "guide.md"}}, nil)
+
+	indexed, _, err := svc.ReindexAll(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Zero(t, indexed)
+}
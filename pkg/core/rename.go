@@ -0,0 +1,257 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// repoNameRe matches a repository identifier of the form owner/repo, optionally followed
+// by a sub-project name when a monorepo publishes several doc sets.
+var repoNameRe = regexp.MustCompile(`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+(?:/[A-Za-z0-9_.-]+)?$`)
+
+// validateRepoName reports whether repo is a well-formed owner/repo or
+// owner/repo/project identifier.
+func validateRepoName(repo string) error {
+	segments := strings.Split(repo, "/")
+	valid := repoNameRe.MatchString(repo)
+
+	for _, seg := range segments {
+		if seg == "." || seg == ".." {
+			valid = false
+		}
+	}
+
+	if len(segments) == 3 && !validProjectName(segments[2]) {
+		valid = false
+	}
+
+	if !valid {
+		return fmt.Errorf("%w: repo must be of the form owner/repo or owner/repo/project: %q", ErrInvalidPath, repo)
+	}
+
+	return nil
+}
+
+// RenameRepo moves every document and asset of a repository to a new identifier, for
+// example after the repository moved to another GitHub organization. Documents are
+// copied to the new identifier and indexed before the originals are removed, so a
+// failure part way through leaves the original repository intact. Once the move is
+// complete its pinned documents and announcement move along, a redirect from the old
+// identifier is recorded, and existing redirects that pointed at the old identifier or at
+// its documents are updated to the new one.
+// It returns ErrNotFound if the source repository has no documents and ErrConflict if
+// the target identifier already holds documents.
+func (s *Service) RenameRepo(ctx context.Context, req RenameRepoRequest) (*RenameRepoResponse, error) {
+	if err := validateRepoName(req.From); err != nil {
+		return nil, err
+	}
+
+	if err := validateRepoName(req.To); err != nil {
+		return nil, err
+	}
+
+	if req.From == req.To {
+		return nil, fmt.Errorf("%w: source and target repo are the same", ErrConflict)
+	}
+
+	docs, err := s.store.List(ctx, req.From)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents for repo %s: %w", req.From, err)
+	}
+
+	if len(docs) == 0 {
+		return nil, fmt.Errorf("%w: repo %s", ErrNotFound, req.From)
+	}
+
+	existing, err := s.store.List(ctx, req.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents for repo %s: %w", req.To, err)
+	}
+
+	if len(existing) > 0 {
+		return nil, fmt.Errorf("%w: repo %s already has documents", ErrConflict, req.To)
+	}
+
+	assets, err := s.store.ListAssets(ctx, req.From)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list assets for repo %s: %w", req.From, err)
+	}
+
+	defer s.invalidateSearches()
+
+	// Copy everything to the new identifier first.
+	for _, meta := range docs {
+		if err := s.copyDocument(ctx, req.From, req.To, meta.Path); err != nil {
+			return nil, fmt.Errorf("failed to move document %s: %w", meta.Path, err)
+		}
+	}
+
+	for _, assetPath := range assets {
+		data, err := s.store.GetAsset(ctx, req.From, assetPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read asset %s: %w", assetPath, err)
+		}
+
+		if err := s.store.SaveAsset(ctx, req.To, assetPath, data); err != nil {
+			return nil, fmt.Errorf("failed to save asset %s: %w", assetPath, err)
+		}
+	}
+
+	// Then drop the originals. The copy is complete, so failures here only leave
+	// stale data behind under the old identifier and are logged rather than returned.
+	for _, meta := range docs {
+		if err := s.search.Remove(ctx, meta.ID); err != nil {
+			slog.WarnContext(ctx, "rename: failed to remove old document from index", "id", meta.ID, "error", err)
+		}
+
+		s.removeEmbedding(ctx, meta.ID)
+	}
+
+	if err := s.store.DeleteRepo(ctx, req.From); err != nil {
+		slog.WarnContext(ctx, "rename: failed to delete old repo", "repo", req.From, "error", err)
+	}
+
+	s.moveHighlights(ctx, req.From, req.To)
+
+	if err := s.addRedirects(ctx, map[string]string{req.From: req.To}); err != nil {
+		return nil, err
+	}
+
+	s.resetContentIndex()
+
+	// Readers of the old identifier are redirected on reload, so moves count as updates.
+	for _, meta := range docs {
+		s.publishEvent(EventDocumentUpdated, req.From, meta.Path)
+		s.publishEvent(EventDocumentUpdated, req.To, meta.Path)
+	}
+
+	return &RenameRepoResponse{
+		From:        req.From,
+		To:          req.To,
+		DocsMoved:   len(docs),
+		AssetsMoved: len(assets),
+	}, nil
+}
+
+// copyDocument stores and indexes a copy of a document under another repository,
+// keeping its commit SHA and update time.
+func (s *Service) copyDocument(ctx context.Context, from, to, path string) error {
+	doc, err := s.store.Get(ctx, from, path)
+	if err != nil {
+		return fmt.Errorf("failed to get document: %w", err)
+	}
+
+	doc.Repo = to
+	doc.ID = NewDocumentID(to, path).String()
+
+	// The processor of the new repository may be configured differently, for example to
+	// generate other heading IDs, so the text stored with the document is extracted again.
+	processor := s.getProcessor(doc.ContentType, to)
+	plainText := processor.ToPlainText([]byte(doc.Content))
+	code := processor.ExtractCodeBlocks([]byte(doc.Content))
+	headings := processor.ExtractHeadings([]byte(doc.Content))
+
+	doc.Anchors = mapAnchors(processor, []byte(doc.Content), plainText, headings)
+	doc.Text = newDocumentText(doc.Content, processor, plainText, headings)
+
+	if err := s.store.Save(ctx, doc); err != nil {
+		return fmt.Errorf("failed to save document: %w", err)
+	}
+
+	if err := indexSearchable(ctx, s.search, &doc, plainText, code, headings); err != nil {
+		return fmt.Errorf("failed to index document: %w", err)
+	}
+
+	s.embedDocument(ctx, &doc, plainText, code)
+
+	return nil
+}
+
+// addRedirects records redirects from old to new identifiers. Keys are either
+// repository identifiers or document IDs. Redirects that pointed at an old identifier,
+// or at a document inside it, are retargeted so that chains of moves resolve in a
+// single hop, and any redirect away from a new identifier is dropped since it is now
+// live again.
+func (s *Service) addRedirects(ctx context.Context, moves map[string]string) error {
+	redirects, err := s.store.GetRedirects(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load redirects: %w", err)
+	}
+
+	if redirects == nil {
+		redirects = make(map[string]string)
+	}
+
+	for from, to := range moves {
+		updated := make(map[string]string, len(redirects)+1)
+
+		for old, target := range redirects {
+			if t, ok := retarget(target, from, to); ok {
+				target = t
+			}
+
+			if k, ok := retarget(old, from, to); ok && old != from {
+				old = k
+			}
+
+			updated[old] = target
+		}
+
+		delete(updated, to)
+		updated[from] = to
+		redirects = updated
+	}
+
+	if err := s.store.SaveRedirects(ctx, redirects); err != nil {
+		return fmt.Errorf("failed to save redirects: %w", err)
+	}
+
+	return nil
+}
+
+// retarget rewrites id if it equals from or lies under from, replacing that prefix
+// with to. The second return value is false if id is unaffected by the move.
+func retarget(id, from, to string) (string, bool) {
+	if id == from {
+		return to, true
+	}
+
+	if rest, ok := strings.CutPrefix(id, from+"/"); ok {
+		return to + "/" + rest, true
+	}
+
+	return "", false
+}
+
+// ResolveRedirect returns the location a moved repository or document now lives at.
+// A renamed repository is resolved first, then a moved document within it. An empty
+// path resolves only the repository. The last return value is false if neither has
+// been moved.
+func (s *Service) ResolveRedirect(ctx context.Context, repo, path string) (newRepo, newPath string, moved bool) {
+	redirects, err := s.store.GetRedirects(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to load redirects", "error", err)
+		return "", "", false
+	}
+
+	newRepo, newPath = repo, path
+
+	if target, ok := redirects[repo]; ok {
+		newRepo, moved = target, true
+	}
+
+	if path == "" {
+		return newRepo, newPath, moved
+	}
+
+	if target, ok := redirects[newRepo+"/"+path]; ok {
+		if p, ok := strings.CutPrefix(target, newRepo+"/"); ok {
+			newPath, moved = p, true
+		}
+	}
+
+	return newRepo, newPath, moved
+}
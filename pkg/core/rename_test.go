@@ -0,0 +1,201 @@
+//go:build !compile
+
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRepoName(t *testing.T) {
+	tests := []struct {
+		repo    string
+		wantErr bool
+	}{
+		{repo: "owner/repo"},
+		{repo: "my-org/my.repo_2"},
+		{repo: "owner", wantErr: true},
+		{repo: "owner/repo/service-a"},
+		{repo: "owner/repo/extra/more", wantErr: true},
+		{repo: "owner/repo/docs", wantErr: true},
+		{repo: "owner/repo/Assets", wantErr: true},
+		{repo: "owner/repo/..", wantErr: true},
+		{repo: "../repo", wantErr: true},
+		{repo: "owner/..", wantErr: true},
+		{repo: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.repo, func(t *testing.T) {
+			err := validateRepoName(tt.repo)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidPath)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRenameRepo(t *testing.T) {
+	svc, store, search, processor := newTestService(t)
+
+	updated := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	doc := Document{
+		ID:        "old-org/repo/guide.md",
+		Repo:      "old-org/repo",
+		Path:      "guide.md",
+		Title:     "Guide",
+		Content:   "# Guide",
+		CommitSHA: "abc123",
+		UpdatedAt: updated,
+	}
+	moved := doc
+	moved.ID = "new-org/repo/guide.md"
+	moved.Repo = "new-org/repo"
+	moved.Anchors = AnchorMap{}
+	moved.Text = newDocumentText("# Guide", processor, "Guide", nil)
+
+	store.EXPECT().List(mock.Anything, "old-org/repo").Return([]DocumentMeta{{ID: doc.ID, Repo: doc.Repo, Path: doc.Path}}, nil)
+	store.EXPECT().List(mock.Anything, "new-org/repo").Return(nil, nil)
+	store.EXPECT().ListAssets(mock.Anything, "old-org/repo").Return([]string{"img/a.png"}, nil)
+	store.EXPECT().Get(mock.Anything, "old-org/repo", "guide.md").Return(doc, nil)
+	store.EXPECT().Save(mock.Anything, moved).Return(nil)
+	processor.EXPECT().ToPlainText([]byte("# Guide")).Return("Guide")
+	processor.EXPECT().ExtractCodeBlocks([]byte("# Guide")).Return(nil)
+	processor.EXPECT().ExtractHeadings([]byte("# Guide")).Return(nil)
+	search.EXPECT().Index(mock.Anything, moved, "Guide", []CodeBlock(nil), []Heading(nil)).Return(nil)
+	store.EXPECT().GetAsset(mock.Anything, "old-org/repo", "img/a.png").Return([]byte("png"), nil)
+	store.EXPECT().SaveAsset(mock.Anything, "new-org/repo", "img/a.png", []byte("png")).Return(nil)
+	search.EXPECT().Remove(mock.Anything, "old-org/repo/guide.md").Return(nil)
+	store.EXPECT().DeleteRepo(mock.Anything, "old-org/repo").Return(nil)
+	store.EXPECT().GetHighlights(mock.Anything).Return(map[string]RepoHighlights{
+		"old-org/repo": {Repo: "old-org/repo", Announcement: "Moving soon", Pinned: []string{"guide.md"}},
+		"other/repo":   {Repo: "other/repo", Pinned: []string{"intro.md"}},
+	}, nil)
+	store.EXPECT().SaveHighlights(mock.Anything, map[string]RepoHighlights{
+		"new-org/repo": {Repo: "new-org/repo", Announcement: "Moving soon", Pinned: []string{"guide.md"}},
+		"other/repo":   {Repo: "other/repo", Pinned: []string{"intro.md"}},
+	}).Return(nil)
+	store.EXPECT().GetRedirects(mock.Anything).Return(map[string]string{
+		"older-org/repo": "old-org/repo",
+		"new-org/repo":   "elsewhere/repo",
+		"other/repo":     "other/renamed",
+	}, nil)
+	store.EXPECT().SaveRedirects(mock.Anything, map[string]string{
+		"older-org/repo": "new-org/repo",
+		"old-org/repo":   "new-org/repo",
+		"other/repo":     "other/renamed",
+	}).Return(nil)
+
+	resp, err := svc.RenameRepo(t.Context(), RenameRepoRequest{From: "old-org/repo", To: "new-org/repo"})
+	require.NoError(t, err)
+	assert.Equal(t, &RenameRepoResponse{From: "old-org/repo", To: "new-org/repo", DocsMoved: 1, AssetsMoved: 1}, resp)
+}
+
+func TestRenameRepo_Errors(t *testing.T) {
+	t.Run("invalid name", func(t *testing.T) {
+		svc, _, _, _ := newTestService(t)
+
+		_, err := svc.RenameRepo(t.Context(), RenameRepoRequest{From: "old-org/repo", To: "new-org"})
+		assert.ErrorIs(t, err, ErrInvalidPath)
+	})
+
+	t.Run("same name", func(t *testing.T) {
+		svc, _, _, _ := newTestService(t)
+
+		_, err := svc.RenameRepo(t.Context(), RenameRepoRequest{From: "old-org/repo", To: "old-org/repo"})
+		assert.ErrorIs(t, err, ErrConflict)
+	})
+
+	t.Run("source not found", func(t *testing.T) {
+		svc, store, _, _ := newTestService(t)
+
+		store.EXPECT().List(mock.Anything, "old-org/repo").Return(nil, nil)
+
+		_, err := svc.RenameRepo(t.Context(), RenameRepoRequest{From: "old-org/repo", To: "new-org/repo"})
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("target has documents", func(t *testing.T) {
+		svc, store, _, _ := newTestService(t)
+
+		store.EXPECT().List(mock.Anything, "old-org/repo").Return([]DocumentMeta{{Path: "a.md"}}, nil)
+		store.EXPECT().List(mock.Anything, "new-org/repo").Return([]DocumentMeta{{Path: "b.md"}}, nil)
+
+		_, err := svc.RenameRepo(t.Context(), RenameRepoRequest{From: "old-org/repo", To: "new-org/repo"})
+		assert.ErrorIs(t, err, ErrConflict)
+	})
+
+	t.Run("copy failure keeps the original", func(t *testing.T) {
+		svc, store, _, _ := newTestService(t)
+
+		store.EXPECT().List(mock.Anything, "old-org/repo").Return([]DocumentMeta{{Path: "a.md"}}, nil)
+		store.EXPECT().List(mock.Anything, "new-org/repo").Return(nil, nil)
+		store.EXPECT().ListAssets(mock.Anything, "old-org/repo").Return(nil, nil)
+		store.EXPECT().Get(mock.Anything, "old-org/repo", "a.md").Return(Document{}, errors.New("disk failure"))
+
+		_, err := svc.RenameRepo(t.Context(), RenameRepoRequest{From: "old-org/repo", To: "new-org/repo"})
+		assert.ErrorContains(t, err, "failed to move document a.md")
+	})
+}
+
+func TestResolveRedirect(t *testing.T) {
+	svc, store, _, _ := newTestService(t)
+
+	store.EXPECT().GetRedirects(mock.Anything).Return(map[string]string{
+		"old-org/repo":          "new-org/repo",
+		"new-org/repo/old.md":   "new-org/repo/docs/new.md",
+		"other/repo/a.md":       "other/repo/b.md",
+		"other/repo/outside.md": "elsewhere/repo/outside.md",
+	}, nil)
+
+	tests := []struct {
+		name      string
+		repo      string
+		path      string
+		wantRepo  string
+		wantPath  string
+		wantMoved bool
+	}{
+		{name: "renamed repo", repo: "old-org/repo", wantRepo: "new-org/repo", wantMoved: true},
+		{name: "document in renamed repo", repo: "old-org/repo", path: "a.md", wantRepo: "new-org/repo", wantPath: "a.md", wantMoved: true},
+		{name: "moved document in renamed repo", repo: "old-org/repo", path: "old.md", wantRepo: "new-org/repo", wantPath: "docs/new.md", wantMoved: true},
+		{name: "moved document", repo: "other/repo", path: "a.md", wantRepo: "other/repo", wantPath: "b.md", wantMoved: true},
+		{name: "target in another repo", repo: "other/repo", path: "outside.md", wantRepo: "other/repo", wantPath: "outside.md"},
+		{name: "not moved", repo: "other/repo", path: "c.md", wantRepo: "other/repo", wantPath: "c.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, path, moved := svc.ResolveRedirect(t.Context(), tt.repo, tt.path)
+			assert.Equal(t, tt.wantMoved, moved)
+			assert.Equal(t, tt.wantRepo, repo)
+			assert.Equal(t, tt.wantPath, path)
+		})
+	}
+}
+
+func TestAddRedirects(t *testing.T) {
+	svc, store, _, _ := newTestService(t)
+
+	store.EXPECT().GetRedirects(mock.Anything).Return(map[string]string{
+		"older-org/repo":      "old-org/repo",
+		"old-org/repo/a.md":   "old-org/repo/b.md",
+		"new-org/repo":        "somewhere/repo",
+		"unrelated/repo/x.md": "unrelated/repo/y.md",
+	}, nil)
+	store.EXPECT().SaveRedirects(mock.Anything, map[string]string{
+		"older-org/repo":      "new-org/repo",
+		"old-org/repo":        "new-org/repo",
+		"new-org/repo/a.md":   "new-org/repo/b.md",
+		"unrelated/repo/x.md": "unrelated/repo/y.md",
+	}).Return(nil)
+
+	require.NoError(t, svc.addRedirects(t.Context(), map[string]string{"old-org/repo": "new-org/repo"}))
+}
@@ -0,0 +1,236 @@
+package core
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Default render budgets.
+const (
+	defaultRenderMaxDuration = 250 * time.Millisecond
+	defaultRenderMaxBytes    = 1 << 20
+)
+
+// statsOverBudgetLimit is the number of documents over the render budget listed in Stats.
+const statsOverBudgetLimit = 10
+
+// RenderBudget is the render time and rendered HTML size a document is expected to stay
+// within. Zero values use the defaults of 250ms and 1 MiB.
+type RenderBudget struct {
+	MaxDuration time.Duration `json:"max_duration_ns"`
+	MaxBytes    int           `json:"max_bytes"`
+}
+
+// withDefaults returns the budget with zero values replaced by the defaults.
+func (b RenderBudget) withDefaults() RenderBudget {
+	if b.MaxDuration <= 0 {
+		b.MaxDuration = defaultRenderMaxDuration
+	}
+
+	if b.MaxBytes <= 0 {
+		b.MaxBytes = defaultRenderMaxBytes
+	}
+
+	return b
+}
+
+// RenderStat is the rendering cost of the published version of a document: the time taken
+// to render it to HTML when it was published and when it was first viewed since, and the
+// size of the HTML. Either time is zero until measured; documents published before the
+// server started are only measured when viewed.
+type RenderStat struct {
+	MeasuredAt   time.Time     `json:"measured_at"`
+	Repo         string        `json:"repo"`
+	Path         string        `json:"path"`
+	IngestRender time.Duration `json:"ingest_render_ns,omitempty"`
+	ViewRender   time.Duration `json:"view_render_ns,omitempty"`
+	HTMLBytes    int           `json:"html_bytes"`
+	OverBudget   bool          `json:"over_budget"`
+}
+
+// Slowest returns the longer of the measured render times.
+func (r *RenderStat) Slowest() time.Duration {
+	return max(r.IngestRender, r.ViewRender)
+}
+
+// RenderReport lists the rendering costs of the measured documents against the budget.
+type RenderReport struct {
+	Documents []RenderStat `json:"documents"`
+	Budget    RenderBudget `json:"budget"`
+}
+
+// RenderBudgetStats summarizes the rendering costs of the measured documents.
+type RenderBudgetStats struct {
+	// OverBudget lists the documents exceeding the budget, slowest first, at most ten.
+	OverBudget      []RenderStat `json:"over_budget"`
+	Budget          RenderBudget `json:"budget"`
+	Measured        int          `json:"measured"`
+	OverBudgetCount int          `json:"over_budget_count"`
+}
+
+// renderTracker records the rendering costs of documents since the server started.
+type renderTracker struct {
+	stats  map[string]*RenderStat // by document ID
+	budget RenderBudget
+	mu     sync.Mutex
+}
+
+// WithRenderBudget enables measuring the time taken to render each document to HTML and the
+// size of the HTML, when it is published and when it is first viewed since. Documents
+// exceeding the budget are logged and flagged in RenderReport and Stats.
+func WithRenderBudget(budget RenderBudget) Option {
+	return func(s *Service) {
+		s.renders = &renderTracker{budget: budget.withDefaults(), stats: make(map[string]*RenderStat)}
+	}
+}
+
+// measureIngestRender renders a published document to measure its rendering cost. Render
+// failures are logged; the document is rendered again, and the failure reported, on view.
+func (s *Service) measureIngestRender(ctx context.Context, doc *Document, processor ContentProcessor) {
+	if s.renders == nil {
+		return
+	}
+
+	start := time.Now()
+
+	html, _, err := renderHTML(ctx, processor, []byte(doc.Content))
+	if err != nil {
+		slog.WarnContext(ctx, "failed to render document to measure its render cost", "repo", doc.Repo, "path", doc.Path, "error", err)
+		return
+	}
+
+	s.renders.record(ctx, doc, time.Since(start), len(html), false)
+}
+
+// recordViewRender records the rendering cost of a viewed document, if it is the first view
+// of the published version.
+func (s *Service) recordViewRender(ctx context.Context, doc *Document, took time.Duration, size int) {
+	if s.renders == nil {
+		return
+	}
+
+	s.renders.record(ctx, doc, took, size, true)
+}
+
+// forgetRender discards the rendering cost of a deleted document.
+func (s *Service) forgetRender(docID string) {
+	if s.renders == nil {
+		return
+	}
+
+	s.renders.mu.Lock()
+	defer s.renders.mu.Unlock()
+
+	delete(s.renders.stats, docID)
+}
+
+// record stores a measurement of a document. A publish measurement replaces the previous
+// version's; a view measurement is only kept for the first view. Documents newly over the
+// budget are logged.
+func (t *renderTracker) record(ctx context.Context, doc *Document, took time.Duration, size int, view bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	id := NewDocumentID(doc.Repo, doc.Path).String()
+
+	stat, ok := t.stats[id]
+
+	switch {
+	case view && ok && stat.ViewRender > 0:
+		return
+	case view && ok:
+		stat.ViewRender = took
+		stat.HTMLBytes = max(stat.HTMLBytes, size)
+	case view:
+		stat = &RenderStat{Repo: doc.Repo, Path: doc.Path, ViewRender: took, HTMLBytes: size}
+	default:
+		stat = &RenderStat{Repo: doc.Repo, Path: doc.Path, IngestRender: took, HTMLBytes: size}
+	}
+
+	wasOver := stat.OverBudget
+
+	stat.MeasuredAt = time.Now()
+	stat.OverBudget = stat.Slowest() > t.budget.MaxDuration || stat.HTMLBytes > t.budget.MaxBytes
+	t.stats[id] = stat
+
+	if stat.OverBudget && !wasOver {
+		slog.WarnContext(ctx, "document exceeds render budget",
+			"repo", doc.Repo,
+			"path", doc.Path,
+			"render", stat.Slowest(),
+			"html_bytes", stat.HTMLBytes,
+		)
+	}
+}
+
+// sorted returns the measurements over budget first, then slowest first.
+func (t *renderTracker) sorted() []RenderStat {
+	stats := make([]RenderStat, 0, len(t.stats))
+	for _, stat := range t.stats {
+		stats = append(stats, *stat)
+	}
+
+	slices.SortFunc(stats, func(a, b RenderStat) int {
+		if a.OverBudget != b.OverBudget {
+			if a.OverBudget {
+				return -1
+			}
+
+			return 1
+		}
+
+		return cmp.Or(
+			cmp.Compare(b.Slowest(), a.Slowest()),
+			cmp.Compare(b.HTMLBytes, a.HTMLBytes),
+			cmp.Compare(a.Repo+"/"+a.Path, b.Repo+"/"+b.Path),
+		)
+	})
+
+	return stats
+}
+
+// RenderReport returns the rendering costs of the documents measured since the server
+// started, those over budget first, then slowest first. It returns ErrNotSupported if
+// render budgets are not enabled.
+func (s *Service) RenderReport(_ context.Context) (*RenderReport, error) {
+	if s.renders == nil {
+		return nil, fmt.Errorf("%w: render budgets are not enabled", ErrNotSupported)
+	}
+
+	s.renders.mu.Lock()
+	defer s.renders.mu.Unlock()
+
+	return &RenderReport{Documents: s.renders.sorted(), Budget: s.renders.budget}, nil
+}
+
+// fillRenderStats adds the summary of the rendering costs to stats when render budgets are
+// enabled.
+func (s *Service) fillRenderStats(stats *Stats) {
+	if s.renders == nil {
+		return
+	}
+
+	s.renders.mu.Lock()
+	defer s.renders.mu.Unlock()
+
+	summary := &RenderBudgetStats{Budget: s.renders.budget, Measured: len(s.renders.stats), OverBudget: []RenderStat{}}
+
+	for _, stat := range s.renders.sorted() {
+		if !stat.OverBudget {
+			break
+		}
+
+		summary.OverBudgetCount++
+
+		if len(summary.OverBudget) < statsOverBudgetLimit {
+			summary.OverBudget = append(summary.OverBudget, stat)
+		}
+	}
+
+	stats.RenderBudget = summary
+}
@@ -0,0 +1,110 @@
+//go:build !compile
+
+package core
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderBudget(t *testing.T) {
+	svc, store, search, processor := newTestService(t)
+	WithRenderBudget(RenderBudget{MaxBytes: 100})(svc)
+
+	big := "# Big\n\n" + strings.Repeat("| a | b |\n", 50)
+
+	processor.EXPECT().ExtractTitle(mock.Anything).Return("Title")
+	processor.EXPECT().ToPlainText(mock.Anything).Return("text")
+	processor.EXPECT().ExtractCodeBlocks(mock.Anything).Return(nil)
+	processor.EXPECT().ExtractHeadings(mock.Anything).Return(nil)
+	processor.EXPECT().RenderHTML([]byte(big)).Return([]byte("<table>"+strings.Repeat("<tr><td>a</td></tr>", 50)+"</table>"), nil, nil)
+	processor.EXPECT().RenderHTML([]byte("# Small")).Return([]byte("<h1>Small</h1>"), nil, nil)
+	store.EXPECT().Save(mock.Anything, mock.Anything).Return(nil)
+	search.EXPECT().Index(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	_, err := svc.IngestDocuments(t.Context(), &IngestRequest{
+		Repo: "owner/repo",
+		Documents: []IngestDocument{
+			{Path: "small.md", Content: "# Small", Action: actionUpsert},
+			{Path: "big.md", Content: big, Action: actionUpsert},
+		},
+	})
+	require.NoError(t, err)
+
+	report, err := svc.RenderReport(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, RenderBudget{MaxDuration: defaultRenderMaxDuration, MaxBytes: 100}, report.Budget)
+	require.Len(t, report.Documents, 2)
+	assert.Equal(t, "big.md", report.Documents[0].Path, "documents over budget come first")
+	assert.True(t, report.Documents[0].OverBudget)
+	assert.Positive(t, report.Documents[0].IngestRender)
+	assert.Zero(t, report.Documents[0].ViewRender)
+	assert.Equal(t, "small.md", report.Documents[1].Path)
+	assert.False(t, report.Documents[1].OverBudget)
+
+	store.EXPECT().Get(mock.Anything, "owner/repo", "small.md").Return(Document{Repo: "owner/repo", Path: "small.md", Content: "# Small"}, nil)
+
+	for range 2 {
+		_, _, _, err = svc.GetDocument(t.Context(), "owner/repo", "small.md")
+		require.NoError(t, err)
+	}
+
+	report, err = svc.RenderReport(t.Context())
+	require.NoError(t, err)
+	assert.Positive(t, report.Documents[1].ViewRender, "the first view is measured")
+
+	store.EXPECT().ListRepos(mock.Anything).Return([]RepoInfo{{Name: "owner/repo", DocCount: 2}}, nil)
+
+	stats, err := svc.Stats(t.Context())
+	require.NoError(t, err)
+	require.NotNil(t, stats.RenderBudget)
+	assert.Equal(t, 2, stats.RenderBudget.Measured)
+	assert.Equal(t, 1, stats.RenderBudget.OverBudgetCount)
+	require.Len(t, stats.RenderBudget.OverBudget, 1)
+	assert.Equal(t, "big.md", stats.RenderBudget.OverBudget[0].Path)
+
+	search.EXPECT().Remove(mock.Anything, "owner/repo/big.md").Return(nil)
+	store.EXPECT().Delete(mock.Anything, "owner/repo", "big.md").Return(nil)
+
+	_, err = svc.IngestDocuments(t.Context(), &IngestRequest{
+		Repo:      "owner/repo",
+		Documents: []IngestDocument{{Path: "big.md", Action: actionDelete}},
+	})
+	require.NoError(t, err)
+
+	report, err = svc.RenderReport(t.Context())
+	require.NoError(t, err)
+	require.Len(t, report.Documents, 1, "deleted documents are forgotten")
+	assert.Equal(t, "small.md", report.Documents[0].Path)
+}
+
+func TestRenderBudget_ViewOnly(t *testing.T) {
+	tracker := &renderTracker{budget: RenderBudget{}.withDefaults(), stats: make(map[string]*RenderStat)}
+	doc := &Document{Repo: "owner/repo", Path: "slow.md"}
+
+	tracker.record(t.Context(), doc, time.Second, 10, true)
+	tracker.record(t.Context(), doc, time.Millisecond, 10, true)
+
+	stats := tracker.sorted()
+	require.Len(t, stats, 1)
+	assert.Equal(t, time.Second, stats[0].ViewRender, "only the first view is recorded")
+	assert.True(t, stats[0].OverBudget)
+
+	tracker.record(t.Context(), doc, time.Millisecond, 10, false)
+
+	stats = tracker.sorted()
+	assert.False(t, stats[0].OverBudget, "publishing a new version resets the measurements")
+	assert.Zero(t, stats[0].ViewRender)
+}
+
+func TestRenderReport_NotEnabled(t *testing.T) {
+	svc := newTestServiceOnly(t)
+
+	_, err := svc.RenderReport(t.Context())
+	assert.ErrorIs(t, err, ErrNotSupported)
+}
@@ -0,0 +1,39 @@
+package core
+
+import (
+	"context"
+	"fmt"
+)
+
+// Republisher triggers a fresh publish of a repository's documentation from its source,
+// for example by dispatching the repository's publish workflow.
+type Republisher interface {
+	Republish(ctx context.Context, repo string) error
+}
+
+// WithRepublisher enables Republish using the given trigger.
+func WithRepublisher(r Republisher) Option {
+	return func(s *Service) {
+		s.republisher = r
+	}
+}
+
+// Republish asks the repository's source to publish its documentation again, for when the
+// published documents look stale. The publish itself happens asynchronously through the
+// ingest API. It returns an error wrapping ErrNotSupported if no republish trigger is
+// configured.
+func (s *Service) Republish(ctx context.Context, repo string) error {
+	if s.republisher == nil {
+		return fmt.Errorf("%w: no republish trigger is configured", ErrNotSupported)
+	}
+
+	if err := validateRepoName(repo); err != nil {
+		return err
+	}
+
+	if err := s.republisher.Republish(ctx, repo); err != nil {
+		return fmt.Errorf("failed to trigger republish: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+//go:build !compile
+
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// republishFunc adapts a function to the Republisher interface.
+type republishFunc func(ctx context.Context, repo string) error
+
+func (f republishFunc) Republish(ctx context.Context, repo string) error {
+	return f(ctx, repo)
+}
+
+func TestRepublish(t *testing.T) {
+	var got []string
+
+	svc := New(NewMockdocStore(t), NewMocksearchEngine(t), map[ContentType]ContentProcessor{
+		ContentTypeMarkdown: NewMockContentProcessor(t),
+	}, WithRepublisher(republishFunc(func(_ context.Context, repo string) error {
+		got = append(got, repo)
+
+		if repo == "owner/broken" {
+			return errors.New("dispatch failed")
+		}
+
+		return nil
+	})))
+
+	assert.NoError(t, svc.Republish(t.Context(), "owner/repo"))
+	assert.Equal(t, []string{"owner/repo"}, got)
+
+	assert.ErrorIs(t, svc.Republish(t.Context(), "../etc"), ErrInvalidPath)
+	assert.ErrorContains(t, svc.Republish(t.Context(), "owner/broken"), "failed to trigger republish: dispatch failed")
+}
+
+func TestRepublish_NotConfigured(t *testing.T) {
+	svc := newTestServiceOnly(t)
+
+	assert.ErrorIs(t, svc.Republish(t.Context(), "owner/repo"), ErrNotSupported)
+}
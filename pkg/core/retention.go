@@ -0,0 +1,63 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Pruner is storage of data that accumulates over time, such as the version history of a
+// document store or a change journal, that can discard what was recorded before a time.
+type Pruner interface {
+	// Prune discards the data recorded before t and returns how many records it dropped.
+	Prune(ctx context.Context, t time.Time) (int, error)
+}
+
+// RetentionPolicy keeps the data of a Pruner for Period. Class names the kind of data,
+// such as "history", in logs.
+type RetentionPolicy struct {
+	Pruner Pruner
+	Class  string
+	Period time.Duration
+}
+
+// WithRetention enforces the given retention policies when EnforceRetention is called.
+// Policies with a zero Period keep their data forever and are skipped.
+func WithRetention(policies ...RetentionPolicy) Option {
+	return func(s *Service) {
+		for _, p := range policies {
+			if p.Period > 0 {
+				s.retention = append(s.retention, p)
+			}
+		}
+	}
+}
+
+// EnforceRetention discards the data each retention policy no longer keeps. A failing
+// policy does not stop the others; their errors are returned joined. It returns an error
+// wrapping ErrNotSupported if no retention policy is configured.
+func (s *Service) EnforceRetention(ctx context.Context) error {
+	if len(s.retention) == 0 {
+		return fmt.Errorf("%w: no retention policy is configured", ErrNotSupported)
+	}
+
+	var errs []error
+
+	now := time.Now()
+
+	for _, p := range s.retention {
+		n, err := p.Pruner.Prune(ctx, now.Add(-p.Period))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to prune %s: %w", p.Class, err))
+			continue
+		}
+
+		if n > 0 {
+			slog.InfoContext(ctx, "pruned data past its retention", "class", p.Class, "pruned", n, "retention", p.Period)
+		}
+	}
+
+	return errors.Join(errs...)
+}
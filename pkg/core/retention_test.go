@@ -0,0 +1,52 @@
+//go:build !compile
+
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pruneFunc is a Pruner calling a function.
+type pruneFunc func(ctx context.Context, t time.Time) (int, error)
+
+func (f pruneFunc) Prune(ctx context.Context, t time.Time) (int, error) {
+	return f(ctx, t)
+}
+
+func TestEnforceRetention(t *testing.T) {
+	var historyBefore, journalBefore time.Time
+
+	svc := New(NewMockdocStore(t), NewMocksearchEngine(t), map[ContentType]ContentProcessor{
+		ContentTypeMarkdown: NewMockContentProcessor(t),
+	}, WithRetention(
+		RetentionPolicy{Class: "history", Period: 30 * 24 * time.Hour, Pruner: pruneFunc(func(_ context.Context, t time.Time) (int, error) {
+			historyBefore = t
+			return 0, errors.New("disk full")
+		})},
+		RetentionPolicy{Class: "journal", Period: 90 * 24 * time.Hour, Pruner: pruneFunc(func(_ context.Context, t time.Time) (int, error) {
+			journalBefore = t
+			return 3, nil
+		})},
+		RetentionPolicy{Class: "forever", Pruner: pruneFunc(func(context.Context, time.Time) (int, error) {
+			panic("a policy without a period keeps its data")
+		})},
+	))
+
+	err := svc.EnforceRetention(t.Context())
+	require.ErrorContains(t, err, "failed to prune history: disk full")
+
+	assert.WithinDuration(t, time.Now().Add(-30*24*time.Hour), historyBefore, time.Minute)
+	assert.WithinDuration(t, time.Now().Add(-90*24*time.Hour), journalBefore, time.Minute, "a failing policy does not stop the others")
+}
+
+func TestEnforceRetention_NotConfigured(t *testing.T) {
+	svc := newTestServiceOnly(t)
+
+	assert.ErrorIs(t, svc.EnforceRetention(t.Context()), ErrNotSupported)
+}
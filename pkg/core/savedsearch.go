@@ -0,0 +1,355 @@
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxSavedSearches bounds the number of saved searches of an instance.
+	maxSavedSearches = 1000
+	// maxHostSavedSearches bounds the number of saved searches made on one hostname, so the
+	// readers of one site cannot use up the saved searches of the instance.
+	maxHostSavedSearches = 100
+	// maxSavedMatches is the number of recent matches kept per saved search for its feed.
+	maxSavedMatches = 50
+	// maxAlertHits bounds the results of a saved search checked against each ingest.
+	maxAlertHits          = 100
+	maxSavedSearchNameLen = 100
+)
+
+// SavedSearch is a search query readers subscribe to, through a web feed or a webhook, to
+// learn about newly published documents matching it. Its ID is random and unguessable, so
+// it serves as the secret of the feed URL.
+type SavedSearch struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Query     string    `json:"query"`
+	// Webhook is the URL notified with a POST request when published documents match.
+	Webhook string `json:"webhook,omitempty"`
+	// Host is the hostname the search was saved on.
+	Host string `json:"host,omitempty"`
+	// Owner is the signed-in reader who saved the search, whose notification inbox lists
+	// its matches; empty for searches saved without signing in.
+	Owner string `json:"owner,omitempty"`
+	// ReadAt is when the owner last read their notifications; matches recorded since are
+	// unread.
+	ReadAt time.Time `json:"read_at,omitzero"`
+	// Scope lists the owners or repositories whose documents the search matches, those of
+	// the site it was saved on; empty matches documents of every repository.
+	Scope []string `json:"scope,omitempty"`
+	// Matches are the latest documents that matched the query when they were published,
+	// newest first.
+	Matches []SearchMatch `json:"matches,omitempty"`
+}
+
+// SearchMatch is a published document that matched a saved search.
+type SearchMatch struct {
+	Time  time.Time `json:"time"`
+	Repo  string    `json:"repo"`
+	Path  string    `json:"path"`
+	Title string    `json:"title"`
+}
+
+// SavedSearchRequest is the body of a request to save a search. Host and Scope are set by
+// the server from the hostname the request was made for, and Owner from the signed-in
+// reader, see SavedSearch.
+type SavedSearchRequest struct {
+	Name    string   `json:"name"` // defaults to the query
+	Query   string   `json:"query"`
+	Webhook string   `json:"webhook,omitempty"`
+	Host    string   `json:"-"`
+	Owner   string   `json:"-"`
+	Scope   []string `json:"-"`
+}
+
+// SavedSearchStore persists saved searches.
+type SavedSearchStore interface {
+	List(ctx context.Context) ([]SavedSearch, error)
+	// Get returns the saved search with the given ID, or an error wrapping ErrNotFound.
+	Get(ctx context.Context, id string) (SavedSearch, error)
+	// Save creates or replaces the saved search with the ID of search.
+	Save(ctx context.Context, search SavedSearch) error
+	// Delete deletes the saved search with the given ID, or returns an error wrapping
+	// ErrNotFound.
+	Delete(ctx context.Context, id string) error
+}
+
+// AlertNotifier notifies the webhook of a saved search of the documents newly matching it.
+type AlertNotifier interface {
+	Notify(ctx context.Context, search SavedSearch, matches []SearchMatch) error
+}
+
+// savedSearches holds the saved searches configuration.
+type savedSearches struct {
+	store        SavedSearchStore
+	notifier     AlertNotifier
+	webhookHosts []string
+	// mu serializes updates of the matches of saved searches by concurrent ingests.
+	mu sync.Mutex
+}
+
+// WithSavedSearches lets readers save searches in store and be alerted of documents
+// matching them as they are published. Webhooks are notified through notifier, and only
+// on the given hosts; without hosts, saved searches cannot have webhooks and are followed
+// through their feed.
+func WithSavedSearches(store SavedSearchStore, notifier AlertNotifier, webhookHosts []string) Option {
+	return func(s *Service) {
+		s.saved = &savedSearches{store: store, notifier: notifier, webhookHosts: webhookHosts}
+	}
+}
+
+// SaveSearch saves a search query. It returns an error wrapping ErrNotSupported if saved
+// searches are not enabled, ErrInvalidSavedSearch if the query, name or webhook is invalid,
+// and ErrLimitExceeded if the instance or the host of the request has as many saved
+// searches as it accepts.
+func (s *Service) SaveSearch(ctx context.Context, req SavedSearchRequest) (*SavedSearch, error) {
+	if s.saved == nil {
+		return nil, fmt.Errorf("%w: saved searches are not enabled", ErrNotSupported)
+	}
+
+	query := strings.TrimSpace(req.Query)
+	if query == "" || len(query) > MaxQueryLen {
+		return nil, fmt.Errorf("%w: query must be between 1 and %d characters", ErrInvalidSavedSearch, MaxQueryLen)
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = query
+	}
+
+	if len(name) > maxSavedSearchNameLen {
+		return nil, fmt.Errorf("%w: name must be at most %d characters", ErrInvalidSavedSearch, maxSavedSearchNameLen)
+	}
+
+	if req.Webhook != "" {
+		if err := s.saved.checkWebhook(req.Webhook); err != nil {
+			return nil, err
+		}
+	}
+
+	existing, err := s.saved.store.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list saved searches: %w", err)
+	}
+
+	if len(existing) >= maxSavedSearches {
+		return nil, fmt.Errorf("%w: the instance has %d saved searches", ErrLimitExceeded, maxSavedSearches)
+	}
+
+	sameHost := 0
+
+	for i := range existing {
+		if existing[i].Host == req.Host {
+			sameHost++
+		}
+	}
+
+	if sameHost >= maxHostSavedSearches {
+		return nil, fmt.Errorf("%w: the host has %d saved searches", ErrLimitExceeded, maxHostSavedSearches)
+	}
+
+	id, err := newSavedSearchID()
+	if err != nil {
+		return nil, err
+	}
+
+	search := SavedSearch{
+		ID:        id,
+		Name:      name,
+		Query:     query,
+		Webhook:   req.Webhook,
+		Host:      req.Host,
+		Owner:     req.Owner,
+		Scope:     slices.Clone(req.Scope),
+		CreatedAt: time.Now().UTC(),
+	}
+
+	if err := s.saved.store.Save(ctx, search); err != nil {
+		return nil, fmt.Errorf("failed to save search: %w", err)
+	}
+
+	return &search, nil
+}
+
+// GetSavedSearch returns the saved search with the given ID and its latest matches. It
+// returns an error wrapping ErrNotSupported if saved searches are not enabled and
+// ErrNotFound if there is no such search.
+func (s *Service) GetSavedSearch(ctx context.Context, id string) (*SavedSearch, error) {
+	if s.saved == nil {
+		return nil, fmt.Errorf("%w: saved searches are not enabled", ErrNotSupported)
+	}
+
+	search, err := s.saved.store.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saved search: %w", err)
+	}
+
+	return &search, nil
+}
+
+// DeleteSavedSearch deletes the saved search with the given ID. It returns an error
+// wrapping ErrNotSupported if saved searches are not enabled and ErrNotFound if there is
+// no such search.
+func (s *Service) DeleteSavedSearch(ctx context.Context, id string) error {
+	if s.saved == nil {
+		return fmt.Errorf("%w: saved searches are not enabled", ErrNotSupported)
+	}
+
+	s.saved.mu.Lock()
+	defer s.saved.mu.Unlock()
+
+	if err := s.saved.store.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete saved search: %w", err)
+	}
+
+	return nil
+}
+
+// startAlerts matches the documents of repo upserted by an ingest request against the
+// saved searches in the background, so alerts do not delay publishing.
+func (s *Service) startAlerts(ctx context.Context, repo string, upserted []string) {
+	if s.saved == nil || len(upserted) == 0 {
+		return
+	}
+
+	go s.matchSavedSearches(context.WithoutCancel(ctx), repo, upserted)
+}
+
+// matchSavedSearches records the documents of repo upserted by an ingest request that
+// match each saved search, and notifies the webhooks of the searches they match. Searches
+// whose scope leaves out repo are skipped. Failures are logged, since the documents are
+// already published.
+func (s *Service) matchSavedSearches(ctx context.Context, repo string, upserted []string) {
+	searches, err := s.saved.store.List(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to list saved searches", "error", err)
+		return
+	}
+
+	paths := make(map[string]struct{}, len(upserted))
+	for _, p := range upserted {
+		paths[p] = struct{}{}
+	}
+
+	now := time.Now().UTC()
+
+	for _, search := range searches {
+		if !inScope(repo, search.Scope) {
+			continue
+		}
+
+		text, lang := ParseLangFilter(search.Query)
+
+		results, err := s.search.Search(ctx, text, SearchOpts{Lang: lang, Repos: []string{repo}, Limit: maxAlertHits, Quick: true})
+		if err != nil {
+			slog.WarnContext(ctx, "failed to run saved search", "id", search.ID, "error", err)
+			continue
+		}
+
+		var matches []SearchMatch
+
+		for _, hit := range results.Hits {
+			if _, ok := paths[hit.Path]; ok && hit.Repo == repo {
+				matches = append(matches, SearchMatch{Time: now, Repo: hit.Repo, Path: hit.Path, Title: hit.Title})
+			}
+		}
+
+		if len(matches) == 0 {
+			continue
+		}
+
+		updated, err := s.recordMatches(ctx, search.ID, matches)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to record saved search matches", "id", search.ID, "error", err)
+			continue
+		}
+
+		if updated.Webhook == "" || s.saved.notifier == nil {
+			continue
+		}
+
+		if err := s.saved.notifier.Notify(ctx, updated, matches); err != nil {
+			slog.WarnContext(ctx, "failed to notify saved search webhook", "id", search.ID, "error", err)
+		}
+	}
+}
+
+// recordMatches adds matches to the latest matches of the saved search with the given ID
+// and returns the updated search.
+func (s *Service) recordMatches(ctx context.Context, id string, matches []SearchMatch) (SavedSearch, error) {
+	s.saved.mu.Lock()
+	defer s.saved.mu.Unlock()
+
+	search, err := s.saved.store.Get(ctx, id)
+	if err != nil {
+		return SavedSearch{}, err
+	}
+
+	search.Matches = append(slices.Clone(matches), search.Matches...)
+	if len(search.Matches) > maxSavedMatches {
+		search.Matches = search.Matches[:maxSavedMatches]
+	}
+
+	if err := s.saved.store.Save(ctx, search); err != nil {
+		return SavedSearch{}, err
+	}
+
+	return search, nil
+}
+
+// inScope reports whether repo, which may be a monorepo sub-project, belongs to one of the
+// owners or repositories of scope. An empty scope holds every repository.
+func inScope(repo string, scope []string) bool {
+	if len(scope) == 0 {
+		return true
+	}
+
+	for _, s := range scope {
+		if repo == s || strings.HasPrefix(repo, s+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkWebhook checks that raw is an HTTP or HTTPS URL on one of the allowed webhook
+// hosts, so saved searches cannot make the instance call arbitrary internal services.
+func (ss *savedSearches) checkWebhook(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: webhook must be an http or https URL", ErrInvalidSavedSearch)
+	}
+
+	for _, host := range ss.webhookHosts {
+		if strings.EqualFold(u.Hostname(), host) {
+			return nil
+		}
+	}
+
+	if len(ss.webhookHosts) == 0 {
+		return fmt.Errorf("%w: webhooks are not enabled on this instance", ErrInvalidSavedSearch)
+	}
+
+	return fmt.Errorf("%w: webhook host %s is not allowed", ErrInvalidSavedSearch, u.Hostname())
+}
+
+// newSavedSearchID generates a random saved search ID.
+func newSavedSearchID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate saved search ID: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
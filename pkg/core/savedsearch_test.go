@@ -0,0 +1,208 @@
+//go:build !compile
+
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// testWebhookHosts are the hosts saved searches of test services may post webhooks to.
+var testWebhookHosts = []string{"hooks.example.com"}
+
+func TestSaveSearch(t *testing.T) {
+	saved := NewMockSavedSearchStore(t)
+	svc, _, _, _ := newTestService(t, WithSavedSearches(saved, NewMockAlertNotifier(t), testWebhookHosts))
+
+	saved.EXPECT().List(mock.Anything).Return(nil, nil)
+	saved.EXPECT().Save(mock.Anything, mock.MatchedBy(func(s SavedSearch) bool {
+		return s.Name == "deploy lang:go" && s.Query == "deploy lang:go" && len(s.ID) == 32 && !s.CreatedAt.IsZero()
+	})).Return(nil).Once()
+	saved.EXPECT().Save(mock.Anything, mock.MatchedBy(func(s SavedSearch) bool {
+		return s.Name == "Deploys" && s.Webhook == "https://HOOKS.example.com/docs" && s.Owner == "alex"
+	})).Return(nil).Once()
+
+	search, err := svc.SaveSearch(t.Context(), SavedSearchRequest{Query: "  deploy lang:go "})
+	require.NoError(t, err)
+	assert.Equal(t, "deploy lang:go", search.Name, "the name defaults to the query")
+
+	_, err = svc.SaveSearch(t.Context(), SavedSearchRequest{Name: "Deploys", Query: "deploy", Webhook: "https://HOOKS.example.com/docs", Owner: "alex"})
+	require.NoError(t, err)
+}
+
+func TestSaveSearch_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		req  SavedSearchRequest
+	}{
+		{name: "empty query", req: SavedSearchRequest{Query: " "}, want: "query must be"},
+		{name: "long query", req: SavedSearchRequest{Query: strings.Repeat("a", MaxQueryLen+1)}, want: "query must be"},
+		{name: "long name", req: SavedSearchRequest{Query: "deploy", Name: strings.Repeat("a", maxSavedSearchNameLen+1)}, want: "name must be"},
+		{name: "webhook scheme", req: SavedSearchRequest{Query: "deploy", Webhook: "ftp://hooks.example.com"}, want: "http or https URL"},
+		{name: "webhook host", req: SavedSearchRequest{Query: "deploy", Webhook: "http://169.254.169.254/latest"}, want: "host 169.254.169.254 is not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _, _, _ := newTestService(t, WithSavedSearches(NewMockSavedSearchStore(t), NewMockAlertNotifier(t), testWebhookHosts))
+
+			_, err := svc.SaveSearch(t.Context(), tt.req)
+			require.ErrorIs(t, err, ErrInvalidSavedSearch)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+
+	t.Run("webhooks disabled", func(t *testing.T) {
+		svc, _, _, _ := newTestService(t, WithSavedSearches(NewMockSavedSearchStore(t), nil, nil))
+
+		_, err := svc.SaveSearch(t.Context(), SavedSearchRequest{Query: "deploy", Webhook: "https://hooks.example.com"})
+		assert.ErrorContains(t, err, "webhooks are not enabled")
+	})
+}
+
+func TestSaveSearch_Limit(t *testing.T) {
+	saved := NewMockSavedSearchStore(t)
+	svc, _, _, _ := newTestService(t, WithSavedSearches(saved, NewMockAlertNotifier(t), testWebhookHosts))
+
+	saved.EXPECT().List(mock.Anything).Return(make([]SavedSearch, maxSavedSearches), nil)
+
+	_, err := svc.SaveSearch(t.Context(), SavedSearchRequest{Query: "deploy"})
+	assert.ErrorIs(t, err, ErrLimitExceeded)
+}
+
+func TestSaveSearch_HostLimit(t *testing.T) {
+	saved := NewMockSavedSearchStore(t)
+	svc, _, _, _ := newTestService(t, WithSavedSearches(saved, NewMockAlertNotifier(t), testWebhookHosts))
+
+	existing := make([]SavedSearch, maxHostSavedSearches)
+	for i := range existing {
+		existing[i].Host = "docs.team-x.example.com"
+	}
+
+	saved.EXPECT().List(mock.Anything).Return(existing, nil)
+	saved.EXPECT().Save(mock.Anything, mock.MatchedBy(func(s SavedSearch) bool {
+		return s.Host == "docs.example.com" && s.Scope == nil
+	})).Return(nil).Once()
+
+	_, err := svc.SaveSearch(t.Context(), SavedSearchRequest{Query: "deploy", Host: "docs.team-x.example.com", Scope: []string{"team-x"}})
+	require.ErrorIs(t, err, ErrLimitExceeded)
+	assert.ErrorContains(t, err, "the host has")
+
+	// The searches of one host leave room for the searches of the others.
+	_, err = svc.SaveSearch(t.Context(), SavedSearchRequest{Query: "deploy", Host: "docs.example.com"})
+	require.NoError(t, err)
+}
+
+func TestSavedSearches_NotEnabled(t *testing.T) {
+	svc := newTestServiceOnly(t)
+
+	_, err := svc.SaveSearch(t.Context(), SavedSearchRequest{Query: "deploy"})
+	require.ErrorIs(t, err, ErrNotSupported)
+
+	_, err = svc.GetSavedSearch(t.Context(), "a1")
+	require.ErrorIs(t, err, ErrNotSupported)
+
+	require.ErrorIs(t, svc.DeleteSavedSearch(t.Context(), "a1"), ErrNotSupported)
+}
+
+func TestGetDeleteSavedSearch(t *testing.T) {
+	saved := NewMockSavedSearchStore(t)
+	svc, _, _, _ := newTestService(t, WithSavedSearches(saved, NewMockAlertNotifier(t), testWebhookHosts))
+
+	saved.EXPECT().Get(mock.Anything, "a1").Return(SavedSearch{ID: "a1", Query: "deploy"}, nil).Once()
+	saved.EXPECT().Get(mock.Anything, "b2").Return(SavedSearch{}, fmt.Errorf("%w: saved search b2", ErrNotFound)).Once()
+	saved.EXPECT().Delete(mock.Anything, "a1").Return(nil).Once()
+
+	search, err := svc.GetSavedSearch(t.Context(), "a1")
+	require.NoError(t, err)
+	assert.Equal(t, "deploy", search.Query)
+
+	_, err = svc.GetSavedSearch(t.Context(), "b2")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, svc.DeleteSavedSearch(t.Context(), "a1"))
+}
+
+func TestMatchSavedSearches(t *testing.T) {
+	saved := NewMockSavedSearchStore(t)
+	notifier := NewMockAlertNotifier(t)
+	svc, _, search, _ := newTestService(t, WithSavedSearches(saved, notifier, testWebhookHosts))
+
+	previous := SearchMatch{Repo: "acme/api", Path: "old.md", Title: "Old"}
+	deploys := SavedSearch{ID: "a1", Query: "deploy lang:go", Webhook: "https://hooks.example.com/docs", Matches: []SearchMatch{previous}}
+	oncall := SavedSearch{ID: "b2", Query: "on-call"}
+	broken := SavedSearch{ID: "c3", Query: "broken"}
+
+	saved.EXPECT().List(mock.Anything).Return([]SavedSearch{deploys, oncall, broken}, nil)
+
+	search.EXPECT().Search(mock.Anything, "deploy", SearchOpts{Lang: "go", Repos: []string{"acme/api"}, Limit: maxAlertHits, Quick: true}).
+		Return(&SearchResults{Hits: []SearchResult{
+			{Repo: "acme/api", Path: "deploy.md", Title: "Deploying"},
+			{Repo: "acme/api", Path: "untouched.md", Title: "Not published now"},
+		}}, nil)
+	search.EXPECT().Search(mock.Anything, "on-call", mock.Anything).Return(&SearchResults{}, nil)
+	search.EXPECT().Search(mock.Anything, "broken", mock.Anything).Return(nil, errors.New("index closed"))
+
+	saved.EXPECT().Get(mock.Anything, "a1").Return(deploys, nil)
+
+	var stored SavedSearch
+
+	saved.EXPECT().Save(mock.Anything, mock.Anything).Run(func(_ context.Context, s SavedSearch) { stored = s }).Return(nil).Once()
+	notifier.EXPECT().Notify(mock.Anything, mock.Anything, mock.Anything).Return(errors.New("hook unavailable")).Once()
+
+	svc.matchSavedSearches(t.Context(), "acme/api", []string{"deploy.md", "setup.md"})
+
+	require.Len(t, stored.Matches, 2)
+	assert.Equal(t, "deploy.md", stored.Matches[0].Path, "new matches come first")
+	assert.Equal(t, "Deploying", stored.Matches[0].Title)
+	assert.Equal(t, previous, stored.Matches[1])
+}
+
+func TestMatchSavedSearches_Scope(t *testing.T) {
+	saved := NewMockSavedSearchStore(t)
+	notifier := NewMockAlertNotifier(t)
+	svc, _, search, _ := newTestService(t, WithSavedSearches(saved, notifier, testWebhookHosts))
+
+	teamX := SavedSearch{ID: "a1", Query: "deploy", Webhook: "https://hooks.example.com/x", Scope: []string{"team-x"}}
+	teamY := SavedSearch{ID: "b2", Query: "deploy", Webhook: "https://hooks.example.com/y", Scope: []string{"team-y/web"}}
+
+	saved.EXPECT().List(mock.Anything).Return([]SavedSearch{teamX, teamY}, nil)
+
+	// Only the search saved on the site serving the repository is run, recorded and notified.
+	search.EXPECT().Search(mock.Anything, "deploy", mock.Anything).
+		Return(&SearchResults{Hits: []SearchResult{{Repo: "team-x/api", Path: "deploy.md", Title: "Deploying"}}}, nil).Once()
+	saved.EXPECT().Get(mock.Anything, "a1").Return(teamX, nil).Once()
+	saved.EXPECT().Save(mock.Anything, mock.Anything).Return(nil).Once()
+	notifier.EXPECT().Notify(mock.Anything, mock.MatchedBy(func(s SavedSearch) bool { return s.ID == "a1" }), mock.Anything).Return(nil).Once()
+
+	svc.matchSavedSearches(t.Context(), "team-x/api", []string{"deploy.md"})
+}
+
+func TestInScope(t *testing.T) {
+	assert.True(t, inScope("team-x/api", nil))
+	assert.True(t, inScope("team-x/api", []string{"team-x"}))
+	assert.True(t, inScope("team-x/mono/billing", []string{"team-x/mono"}))
+	assert.False(t, inScope("team-xy/api", []string{"team-x"}))
+	assert.False(t, inScope("team-y/api", []string{"team-x", "team-z/api"}))
+}
+
+func TestRecordMatches_KeepsLatest(t *testing.T) {
+	saved := NewMockSavedSearchStore(t)
+	svc, _, _, _ := newTestService(t, WithSavedSearches(saved, NewMockAlertNotifier(t), testWebhookHosts))
+
+	saved.EXPECT().Get(mock.Anything, "a1").Return(SavedSearch{ID: "a1", Matches: make([]SearchMatch, maxSavedMatches)}, nil)
+	saved.EXPECT().Save(mock.Anything, mock.Anything).Return(nil)
+
+	search, err := svc.recordMatches(t.Context(), "a1", []SearchMatch{{Path: "new.md"}})
+	require.NoError(t, err)
+	assert.Len(t, search.Matches, maxSavedMatches)
+	assert.Equal(t, "new.md", search.Matches[0].Path)
+}
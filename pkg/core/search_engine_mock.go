@@ -23,17 +23,17 @@ func (_m *MocksearchEngine) EXPECT() *MocksearchEngine_Expecter {
 	return &MocksearchEngine_Expecter{mock: &_m.Mock}
 }
 
-// Index provides a mock function with given fields: ctx, doc, plainText
-func (_m *MocksearchEngine) Index(ctx context.Context, doc Document, plainText string) error {
-	ret := _m.Called(ctx, doc, plainText)
+// Index provides a mock function with given fields: ctx, doc, plainText, code, headings
+func (_m *MocksearchEngine) Index(ctx context.Context, doc Document, plainText string, code []CodeBlock, headings []Heading) error {
+	ret := _m.Called(ctx, doc, plainText, code, headings)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Index")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, Document, string) error); ok {
-		r0 = rf(ctx, doc, plainText)
+	if rf, ok := ret.Get(0).(func(context.Context, Document, string, []CodeBlock, []Heading) error); ok {
+		r0 = rf(ctx, doc, plainText, code, headings)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -50,13 +50,15 @@ type MocksearchEngine_Index_Call struct {
 //   - ctx context.Context
 //   - doc Document
 //   - plainText string
-func (_e *MocksearchEngine_Expecter) Index(ctx interface{}, doc interface{}, plainText interface{}) *MocksearchEngine_Index_Call {
-	return &MocksearchEngine_Index_Call{Call: _e.mock.On("Index", ctx, doc, plainText)}
+//   - code []CodeBlock
+//   - headings []Heading
+func (_e *MocksearchEngine_Expecter) Index(ctx interface{}, doc interface{}, plainText interface{}, code interface{}, headings interface{}) *MocksearchEngine_Index_Call {
+	return &MocksearchEngine_Index_Call{Call: _e.mock.On("Index", ctx, doc, plainText, code, headings)}
 }
 
-func (_c *MocksearchEngine_Index_Call) Run(run func(ctx context.Context, doc Document, plainText string)) *MocksearchEngine_Index_Call {
+func (_c *MocksearchEngine_Index_Call) Run(run func(ctx context.Context, doc Document, plainText string, code []CodeBlock, headings []Heading)) *MocksearchEngine_Index_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(Document), args[2].(string))
+		run(args[0].(context.Context), args[1].(Document), args[2].(string), args[3].([]CodeBlock), args[4].([]Heading))
 	})
 	return _c
 }
@@ -66,7 +68,7 @@ func (_c *MocksearchEngine_Index_Call) Return(_a0 error) *MocksearchEngine_Index
 	return _c
 }
 
-func (_c *MocksearchEngine_Index_Call) RunAndReturn(run func(context.Context, Document, string) error) *MocksearchEngine_Index_Call {
+func (_c *MocksearchEngine_Index_Call) RunAndReturn(run func(context.Context, Document, string, []CodeBlock, []Heading) error) *MocksearchEngine_Index_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -237,6 +239,66 @@ func (_c *MocksearchEngine_Search_Call) RunAndReturn(run func(context.Context, s
 	return _c
 }
 
+// Suggest provides a mock function with given fields: ctx, prefix, limit
+func (_m *MocksearchEngine) Suggest(ctx context.Context, prefix string, limit int) ([]SearchSuggestion, error) {
+	ret := _m.Called(ctx, prefix, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Suggest")
+	}
+
+	var r0 []SearchSuggestion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) ([]SearchSuggestion, error)); ok {
+		return rf(ctx, prefix, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) []SearchSuggestion); ok {
+		r0 = rf(ctx, prefix, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]SearchSuggestion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, prefix, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MocksearchEngine_Suggest_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Suggest'
+type MocksearchEngine_Suggest_Call struct {
+	*mock.Call
+}
+
+// Suggest is a helper method to define mock.On call
+//   - ctx context.Context
+//   - prefix string
+//   - limit int
+func (_e *MocksearchEngine_Expecter) Suggest(ctx interface{}, prefix interface{}, limit interface{}) *MocksearchEngine_Suggest_Call {
+	return &MocksearchEngine_Suggest_Call{Call: _e.mock.On("Suggest", ctx, prefix, limit)}
+}
+
+func (_c *MocksearchEngine_Suggest_Call) Run(run func(ctx context.Context, prefix string, limit int)) *MocksearchEngine_Suggest_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MocksearchEngine_Suggest_Call) Return(_a0 []SearchSuggestion, _a1 error) *MocksearchEngine_Suggest_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MocksearchEngine_Suggest_Call) RunAndReturn(run func(context.Context, string, int) ([]SearchSuggestion, error)) *MocksearchEngine_Suggest_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMocksearchEngine creates a new instance of MocksearchEngine. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMocksearchEngine(t interface {
@@ -0,0 +1,168 @@
+package core
+
+import (
+	"container/list"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// searchCacheTTL is how long the results of a search are reused. Search-as-you-type
+	// repeats the same query while the reader pauses; the short lifetime bounds how stale
+	// results can get should a change of the index escape invalidation.
+	searchCacheTTL = 10 * time.Second
+	// maxSearchCacheEntries bounds the number of cached searches; the least recently used
+	// one is evicted when the cache is full.
+	maxSearchCacheEntries = 256
+)
+
+// searchKey identifies a cached search: its query, options and ranking. The session of
+// the options is left out, as it only decides the ranking.
+type searchKey struct {
+	query        string
+	lang         string
+	mode         SearchMode
+	repo         string
+	repos        string
+	contentTypes string
+	ranking      HybridConfig
+	limit        int
+	offset       int
+	codeOnly     bool
+	quick        bool
+}
+
+// newSearchKey returns the key of a search. Repositories and content types are sorted,
+// so that the same filters given in another order share an entry.
+func newSearchKey(query string, opts SearchOpts, ranking HybridConfig) searchKey {
+	types := make([]string, 0, len(opts.ContentTypes))
+	for _, ct := range opts.ContentTypes {
+		types = append(types, string(ct))
+	}
+
+	return searchKey{
+		query:        query,
+		lang:         opts.Lang,
+		mode:         opts.Mode,
+		repo:         opts.Repo,
+		repos:        strings.Join(slices.Sorted(slices.Values(opts.Repos)), "\n"),
+		contentTypes: strings.Join(slices.Sorted(slices.Values(types)), "\n"),
+		ranking:      ranking,
+		limit:        opts.Limit,
+		offset:       opts.Offset,
+		codeOnly:     opts.CodeOnly,
+		quick:        opts.Quick,
+	}
+}
+
+// searchCacheEntry is a cached search.
+type searchCacheEntry struct {
+	expires time.Time
+	results *SearchResults
+	key     searchKey
+}
+
+// searchCache is a size-bounded LRU cache of recent search results. Changes of the search
+// index empty it, see invalidate; the generation it counts lets searches that ran while
+// the index changed be left out of the cache.
+type searchCache struct {
+	items      map[searchKey]*list.Element
+	order      *list.List
+	mu         sync.Mutex
+	generation uint64
+}
+
+// get returns a copy of the cached results of a search, and false if they are missing or
+// expired.
+func (c *searchCache) get(key searchKey, now time.Time) (*SearchResults, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry, _ := el.Value.(*searchCacheEntry)
+	if !now.Before(entry.expires) {
+		c.order.Remove(el)
+		delete(c.items, key)
+
+		return nil, false
+	}
+
+	c.order.MoveToFront(el)
+
+	return entry.results.clone(), true
+}
+
+// current returns the generation of the cache, to be passed to put once the search has
+// run.
+func (c *searchCache) current() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.generation
+}
+
+// put caches a copy of the results of a search until now plus searchCacheTTL, unless the
+// cache was invalidated since generation was read, in which case the results may predate
+// the change.
+func (c *searchCache) put(key searchKey, results *SearchResults, generation uint64, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+
+	if c.items == nil {
+		c.items = make(map[searchKey]*list.Element)
+		c.order = list.New()
+	}
+
+	entry := &searchCacheEntry{key: key, results: results.clone(), expires: now.Add(searchCacheTTL)}
+
+	if el, ok := c.items[key]; ok {
+		el.Value = entry
+		c.order.MoveToFront(el)
+
+		return
+	}
+
+	c.items[key] = c.order.PushFront(entry)
+
+	for c.order.Len() > maxSearchCacheEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+
+		evicted, _ := oldest.Value.(*searchCacheEntry)
+		delete(c.items, evicted.key)
+	}
+}
+
+// invalidate empties the cache and starts a new generation.
+func (c *searchCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items = nil
+	c.order = nil
+	c.generation++
+}
+
+// clone returns a copy of the results whose hits can be changed without affecting r.
+func (r *SearchResults) clone() *SearchResults {
+	c := *r
+	c.Hits = slices.Clone(r.Hits)
+
+	return &c
+}
+
+// invalidateSearches drops the cached search results. It is called once the search index
+// changed.
+func (s *Service) invalidateSearches() {
+	s.searches.invalidate()
+}
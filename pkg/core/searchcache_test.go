@@ -0,0 +1,126 @@
+//go:build !compile
+
+package core
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchDocs_CachesIdenticalSearches(t *testing.T) {
+	svc, store, search, _ := newTestService(t)
+
+	opts := SearchOpts{Repos: []string{"acme/b", "acme/a"}, Limit: 10}
+	hits := []SearchResult{{ID: "acme/a/deploy.md", Repo: "acme/a", Path: "deploy.md", Title: "Deploying"}}
+
+	search.EXPECT().Search(mock.Anything, "deploy", opts).Return(&SearchResults{Hits: hits, Total: 1}, nil).Once()
+	store.EXPECT().Get(mock.Anything, "acme/a", "deploy.md").Return(Document{}, nil).Once()
+
+	first, err := svc.SearchDocs(t.Context(), "deploy", opts)
+	require.NoError(t, err)
+
+	first.Hits[0].Title = "changed by the caller"
+
+	// The same search, with its repositories in another order and in another session, is
+	// answered from the cache, unaffected by changes to the results returned before.
+	second, err := svc.SearchDocs(t.Context(), "deploy", SearchOpts{Repos: []string{"acme/a", "acme/b"}, Limit: 10, Session: "s1"})
+	require.NoError(t, err)
+	assert.Equal(t, "Deploying", second.Hits[0].Title)
+	assert.Equal(t, 1, second.Page)
+
+	// Another page is another search.
+	search.EXPECT().Search(mock.Anything, "deploy", SearchOpts{Repos: opts.Repos, Limit: 10, Offset: 10}).
+		Return(&SearchResults{Total: 1}, nil).Once()
+
+	_, err = svc.SearchDocs(t.Context(), "deploy", SearchOpts{Repos: opts.Repos, Limit: 10, Offset: 10})
+	require.NoError(t, err)
+}
+
+func TestSearchDocs_CacheKeyedByRepo(t *testing.T) {
+	svc, _, search, _ := newTestService(t)
+
+	search.EXPECT().Search(mock.Anything, "deploy", SearchOpts{Repo: "acme/a"}).Return(&SearchResults{}, nil).Once()
+	search.EXPECT().Search(mock.Anything, "deploy", SearchOpts{Repo: "acme/b"}).Return(&SearchResults{}, nil).Once()
+
+	// Searches restricted to different repositories are not answered from each other's entries.
+	_, err := svc.SearchDocs(t.Context(), "deploy", SearchOpts{Repo: "acme/a"})
+	require.NoError(t, err)
+
+	_, err = svc.SearchDocs(t.Context(), "deploy", SearchOpts{Repo: "acme/b"})
+	require.NoError(t, err)
+}
+
+func TestSearchDocs_IngestInvalidatesCache(t *testing.T) {
+	svc, _, search, _ := newTestService(t)
+
+	search.EXPECT().Search(mock.Anything, "deploy", SearchOpts{}).Return(&SearchResults{}, nil).Twice()
+
+	_, err := svc.SearchDocs(t.Context(), "deploy", SearchOpts{})
+	require.NoError(t, err)
+
+	// Even an ingest that fails may have changed documents, so the cache is dropped.
+	_, err = svc.IngestDocuments(t.Context(), &IngestRequest{
+		Repo:      "owner/repo",
+		Documents: []IngestDocument{{Path: "../escape.md", Action: actionUpsert}},
+	})
+	require.ErrorIs(t, err, ErrInvalidPath)
+
+	_, err = svc.SearchDocs(t.Context(), "deploy", SearchOpts{})
+	require.NoError(t, err)
+}
+
+func TestSearchCache(t *testing.T) {
+	now := time.Date(2025, 6, 15, 10, 0, 0, 0, time.UTC)
+	key := newSearchKey("deploy", SearchOpts{}, HybridConfig{})
+	results := &SearchResults{Total: 3}
+
+	t.Run("expires after the TTL", func(t *testing.T) {
+		var c searchCache
+
+		c.put(key, results, c.current(), now)
+
+		got, ok := c.get(key, now.Add(searchCacheTTL-time.Second))
+		require.True(t, ok)
+		assert.Equal(t, results, got)
+		assert.NotSame(t, results, got)
+
+		_, ok = c.get(key, now.Add(searchCacheTTL))
+		assert.False(t, ok)
+	})
+
+	t.Run("skips results of an invalidated generation", func(t *testing.T) {
+		var c searchCache
+
+		generation := c.current()
+		c.invalidate()
+		c.put(key, results, generation, now)
+
+		_, ok := c.get(key, now)
+		assert.False(t, ok)
+	})
+
+	t.Run("evicts the least recently used search", func(t *testing.T) {
+		var c searchCache
+
+		for i := range maxSearchCacheEntries {
+			c.put(newSearchKey(fmt.Sprintf("q%d", i), SearchOpts{}, HybridConfig{}), results, 0, now)
+		}
+
+		_, ok := c.get(newSearchKey("q0", SearchOpts{}, HybridConfig{}), now)
+		require.True(t, ok)
+
+		c.put(key, results, 0, now)
+
+		_, ok = c.get(newSearchKey("q0", SearchOpts{}, HybridConfig{}), now)
+		assert.True(t, ok, "recently used")
+		_, ok = c.get(newSearchKey("q1", SearchOpts{}, HybridConfig{}), now)
+		assert.False(t, ok, "evicted")
+		_, ok = c.get(key, now)
+		assert.True(t, ok)
+	})
+}
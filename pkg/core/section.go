@@ -0,0 +1,66 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxSectionDocuments bounds the number of documents rendered into one section bundle.
+const maxSectionDocuments = 500
+
+// SectionDocument is a rendered document of a repository section.
+type SectionDocument struct {
+	HTML     []byte
+	Headings []Heading
+	Doc      Document
+}
+
+// GetSection renders the markdown documents of repo under the directory dir, or of the
+// whole repository when dir is empty, for combining them into one printable bundle.
+// Documents of other content types are skipped. The documents are returned in path
+// order. It returns ErrNotFound if the section has no markdown documents and
+// ErrLimitExceeded if it has more than can be bundled at once.
+func (s *Service) GetSection(ctx context.Context, repo, dir string) ([]SectionDocument, error) {
+	dir = strings.Trim(dir, "/")
+
+	metas, err := s.store.List(ctx, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+
+	var paths []string
+
+	for _, meta := range metas {
+		if meta.ContentType != "" && meta.ContentType != ContentTypeMarkdown {
+			continue
+		}
+
+		if dir == "" || strings.HasPrefix(meta.Path, dir+"/") {
+			paths = append(paths, meta.Path)
+		}
+	}
+
+	switch {
+	case len(paths) == 0:
+		return nil, fmt.Errorf("%w: no documents under %q in %s", ErrNotFound, dir, repo)
+	case len(paths) > maxSectionDocuments:
+		return nil, fmt.Errorf("%w: section has %d documents, limit is %d", ErrLimitExceeded, len(paths), maxSectionDocuments)
+	}
+
+	sort.Strings(paths)
+
+	section := make([]SectionDocument, 0, len(paths))
+
+	for _, path := range paths {
+		doc, html, headings, err := s.GetDocument(ctx, repo, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", path, err)
+		}
+
+		section = append(section, SectionDocument{Doc: doc, HTML: html, Headings: headings})
+	}
+
+	return section, nil
+}
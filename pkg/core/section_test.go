@@ -0,0 +1,59 @@
+//go:build !compile
+
+package core
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSection(t *testing.T) {
+	svc, store, _, processor := newTestService(t)
+
+	store.EXPECT().List(mock.Anything, "owner/repo").Return([]DocumentMeta{
+		{Path: "ops/runbook.md", ContentType: ContentTypeMarkdown},
+		{Path: "ops/api.yaml", ContentType: ContentTypeOpenAPI},
+		{Path: "ops/alerts/disk.md"},
+		{Path: "operations.md"},
+		{Path: "readme.md"},
+	}, nil)
+
+	for _, path := range []string{"ops/runbook.md", "ops/alerts/disk.md"} {
+		content := "# " + path
+		store.EXPECT().Get(mock.Anything, "owner/repo", path).Return(Document{Repo: "owner/repo", Path: path, Content: content}, nil)
+		processor.EXPECT().RenderHTML([]byte(content)).Return([]byte("<h1>"+path+"</h1>"), []Heading{{Level: 1, Text: path, ID: "h"}}, nil)
+	}
+
+	section, err := svc.GetSection(t.Context(), "owner/repo", "/ops/")
+	require.NoError(t, err)
+	require.Len(t, section, 2)
+
+	assert.Equal(t, "ops/alerts/disk.md", section[0].Doc.Path)
+	assert.Equal(t, "<h1>ops/alerts/disk.md</h1>", string(section[0].HTML))
+	assert.Equal(t, "ops/runbook.md", section[1].Doc.Path)
+	assert.Equal(t, []Heading{{Level: 1, Text: "ops/runbook.md", ID: "h"}}, section[1].Headings)
+}
+
+func TestGetSection_Errors(t *testing.T) {
+	svc, store, _, _ := newTestService(t)
+
+	many := make([]DocumentMeta, maxSectionDocuments+1)
+	for i := range many {
+		many[i] = DocumentMeta{Path: fmt.Sprintf("big/doc%d.md", i)}
+	}
+
+	store.EXPECT().List(mock.Anything, "owner/repo").Return(append(many, DocumentMeta{Path: "specs/api.yaml", ContentType: ContentTypeOpenAPI}), nil)
+
+	_, err := svc.GetSection(t.Context(), "owner/repo", "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = svc.GetSection(t.Context(), "owner/repo", "specs")
+	assert.ErrorIs(t, err, ErrNotFound, "sections without markdown documents are not bundled")
+
+	_, err = svc.GetSection(t.Context(), "owner/repo", "big")
+	assert.ErrorIs(t, err, ErrLimitExceeded)
+}
@@ -0,0 +1,268 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Embedder converts text into embedding vectors, whose distance reflects how close the
+// texts are in meaning.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// VectorEntry is the embedding of a document in a vector index.
+type VectorEntry struct {
+	ID          string
+	Repo        string
+	Path        string
+	Title       string
+	ContentType ContentType
+	Checksum    string   // SHA-256 of the embedded text and the filtered fields, to skip unchanged documents
+	Tags        []string // tags of the document, matched by tag: qualifiers
+	Langs       []string // distinct languages of the document's code blocks, matched by lang: filters
+	Vector      []float32
+	Code        bool // the document has code blocks, so code-only searches find it
+}
+
+// VectorIndex stores document embeddings and finds the documents nearest to a query
+// embedding.
+type VectorIndex interface {
+	Upsert(ctx context.Context, entry VectorEntry) error
+	Remove(ctx context.Context, docID string) error
+	// Checksum returns the checksum of the text embedded for a document, or an empty string
+	// if the document has no embedding.
+	Checksum(ctx context.Context, docID string) (string, error)
+	// Nearest returns the documents passing the filters of opts and the repo:, path: and
+	// tag: qualifiers of query, ranked by the cosine similarity of their embedding to
+	// vector, the embedding of the query's text.
+	Nearest(ctx context.Context, query string, vector []float32, opts SearchOpts) (*SearchResults, error)
+}
+
+// semanticSearch holds the embedder and vector index of semantic search.
+type semanticSearch struct {
+	embedder Embedder
+	index    VectorIndex
+}
+
+// WithSemanticSearch embeds documents with the given embedder when they are ingested and
+// keeps their embeddings in index, so that semantic and hybrid searches rank documents by
+// how close they are in meaning to the query.
+func WithSemanticSearch(e Embedder, index VectorIndex) Option {
+	return func(s *Service) {
+		s.semantic = &semanticSearch{embedder: e, index: index}
+	}
+}
+
+// embeddingText returns the text embedded for a document and its checksum. The checksum
+// also covers the tags and code languages stored with the embedding, so that documents
+// whose filtered fields changed are stored again.
+func embeddingText(doc *Document, plainText string, code []CodeBlock) (text, checksum string) {
+	text = doc.Title + "\n\n" + plainText
+
+	h := sha256.New()
+	h.Write([]byte(text))
+	h.Write([]byte{0})
+	h.Write([]byte(strings.Join(doc.Tags, "\x00")))
+	h.Write([]byte{0})
+	h.Write([]byte(strings.Join(codeLangs(code), "\x00")))
+
+	if len(code) > 0 {
+		h.Write([]byte{0, 1})
+	}
+
+	return text, hex.EncodeToString(h.Sum(nil))
+}
+
+// codeLangs returns the distinct languages of code blocks, in order of first appearance.
+func codeLangs(code []CodeBlock) []string {
+	var langs []string
+
+	for _, block := range code {
+		if block.Lang != "" && !slices.Contains(langs, block.Lang) {
+			langs = append(langs, block.Lang)
+		}
+	}
+
+	return langs
+}
+
+// embedDocument stores the embedding of a document unless its text is unchanged since it
+// was last embedded. Failures are logged rather than returned, so that documents are
+// published, and found by keyword search, while the embedding model is unavailable.
+func (s *Service) embedDocument(ctx context.Context, doc *Document, plainText string, code []CodeBlock) {
+	if s.semantic == nil {
+		return
+	}
+
+	if err := s.updateEmbedding(ctx, doc, plainText, code); err != nil {
+		slog.WarnContext(ctx, "failed to embed document", "repo", doc.Repo, "path", doc.Path, "error", err)
+	}
+}
+
+// updateEmbedding stores the embedding of a document unless its text is unchanged since it
+// was last embedded. Drafts are left out of semantic search, so their embedding is removed
+// instead.
+func (s *Service) updateEmbedding(ctx context.Context, doc *Document, plainText string, code []CodeBlock) error {
+	if doc.Draft {
+		if err := s.semantic.index.Remove(ctx, doc.ID); err != nil {
+			return fmt.Errorf("failed to remove draft embedding: %w", err)
+		}
+
+		return nil
+	}
+
+	text, checksum := embeddingText(doc, plainText, code)
+
+	current, err := s.semantic.index.Checksum(ctx, doc.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get embedding checksum: %w", err)
+	}
+
+	if current == checksum {
+		return nil
+	}
+
+	vectors, err := s.semantic.embedder.Embed(ctx, []string{text})
+	if err != nil {
+		return fmt.Errorf("failed to embed document: %w", err)
+	}
+
+	if len(vectors) != 1 {
+		return fmt.Errorf("embedder returned %d embeddings for one document", len(vectors))
+	}
+
+	err = s.semantic.index.Upsert(ctx, VectorEntry{
+		ID:          doc.ID,
+		Repo:        doc.Repo,
+		Path:        doc.Path,
+		Title:       doc.Title,
+		ContentType: doc.ContentType,
+		Checksum:    checksum,
+		Tags:        doc.Tags,
+		Langs:       codeLangs(code),
+		Code:        len(code) > 0,
+		Vector:      vectors[0],
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store embedding: %w", err)
+	}
+
+	return nil
+}
+
+// removeEmbedding removes the embedding of a deleted document. Failures are logged, since
+// the document is gone from the store and searches skip results they cannot load.
+func (s *Service) removeEmbedding(ctx context.Context, docID string) {
+	if s.semantic == nil {
+		return
+	}
+
+	if err := s.semantic.index.Remove(ctx, docID); err != nil {
+		slog.WarnContext(ctx, "failed to remove document embedding", "id", docID, "error", err)
+	}
+}
+
+// EmbedAll embeds every stored document whose text changed since it was last embedded, or
+// that has no embedding, such as documents published before semantic search was enabled or
+// embedded by another model. Documents that fail are logged and skipped. It returns the
+// number of documents embedded or already up to date and the number that failed.
+func (s *Service) EmbedAll(ctx context.Context) (embedded, failed int, err error) {
+	if s.semantic == nil {
+		return 0, 0, fmt.Errorf("%w: semantic search is not configured", ErrNotSupported)
+	}
+
+	repos, err := s.store.ListRepos(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to list repos: %w", err)
+	}
+
+	for _, repo := range repos {
+		docs, err := s.store.List(ctx, repo.Name)
+		if err != nil {
+			return embedded, failed, fmt.Errorf("failed to list documents for repo %s: %w", repo.Name, err)
+		}
+
+		for _, meta := range docs {
+			if err := ctx.Err(); err != nil {
+				return embedded, failed, fmt.Errorf("embedding cancelled: %w", err)
+			}
+
+			if err := s.embedStoredDocument(ctx, repo.Name, meta.Path); err != nil {
+				slog.WarnContext(ctx, "failed to embed document", "repo", repo.Name, "path", meta.Path, "error", err)
+
+				failed++
+
+				continue
+			}
+
+			embedded++
+		}
+	}
+
+	return embedded, failed, nil
+}
+
+// embedStoredDocument loads a stored document and updates its embedding.
+func (s *Service) embedStoredDocument(ctx context.Context, repo, path string) error {
+	doc, err := s.store.Get(ctx, repo, path)
+	if err != nil {
+		return fmt.Errorf("failed to get document: %w", err)
+	}
+
+	var (
+		plainText string
+		code      []CodeBlock
+	)
+
+	err = recoverDocument(ctx, repo, path, func() error {
+		processor := s.getProcessor(doc.ContentType, repo)
+		plainText = processor.ToPlainText([]byte(doc.Content))
+		code = processor.ExtractCodeBlocks([]byte(doc.Content))
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.updateEmbedding(ctx, &doc, plainText, code)
+}
+
+// searchSemantic returns the documents closest in meaning to the query. Only the words of
+// the query are embedded, see semanticQueryText; its qualifiers and the filters of opts
+// restrict the documents ranked. Since documents are embedded as a whole, language filters
+// and code-only search keep documents with code in the language, or with any code, rather
+// than ranking their code alone.
+func (s *Service) searchSemantic(ctx context.Context, query string, opts SearchOpts) (*SearchResults, error) {
+	start := time.Now()
+
+	text := semanticQueryText(query)
+	if text == "" {
+		return &SearchResults{}, nil
+	}
+
+	vectors, err := s.semantic.embedder.Embed(ctx, []string{text})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("embedder returned %d embeddings for one query", len(vectors))
+	}
+
+	results, err := s.semantic.index.Nearest(ctx, query, vectors[0], opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search embeddings: %w", err)
+	}
+
+	results.Duration = time.Since(start)
+
+	return results, nil
+}
@@ -0,0 +1,190 @@
+//go:build !compile
+
+package core
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// embedFunc adapts a function to the Embedder interface.
+type embedFunc func(ctx context.Context, texts []string) ([][]float32, error)
+
+func (f embedFunc) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return f(ctx, texts)
+}
+
+func TestSearchDocs_Semantic(t *testing.T) {
+	var embedded []string
+
+	index := NewMockVectorIndex(t)
+	svc, store, search, _ := newTestService(t, WithSemanticSearch(embedFunc(func(_ context.Context, texts []string) ([][]float32, error) {
+		embedded = append(embedded, texts...)
+		return [][]float32{{1, 0}}, nil
+	}), index))
+
+	opts := SearchOpts{Mode: SearchModeSemantic, Repos: []string{"owner"}}
+	index.EXPECT().Nearest(mock.Anything, "how do I ship a new version", []float32{1, 0}, opts).Return(&SearchResults{
+		Hits:  []SearchResult{{ID: "owner/repo/deploy.md", Repo: "owner/repo", Path: "deploy.md", Title: "Deploy", Score: 0.9}},
+		Total: 1,
+	}, nil)
+	store.EXPECT().Get(mock.Anything, "owner/repo", "deploy.md").Return(Document{Summary: "Roll out a release."}, nil)
+
+	results, err := svc.SearchDocs(t.Context(), "how do I ship a new version", opts)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"how do I ship a new version"}, embedded)
+	require.Len(t, results.Hits, 1)
+	assert.Equal(t, "Roll out a release.", results.Hits[0].Summary)
+
+	// Keyword searches do not touch the vector index.
+	search.EXPECT().Search(mock.Anything, "deploy", SearchOpts{Mode: SearchModeKeyword}).Return(&SearchResults{}, nil)
+
+	_, err = svc.SearchDocs(t.Context(), "deploy", SearchOpts{Mode: SearchModeKeyword})
+	require.NoError(t, err)
+	assert.Len(t, embedded, 1)
+}
+
+func TestSearchDocs_SemanticQualifiers(t *testing.T) {
+	var embedded []string
+
+	index := NewMockVectorIndex(t)
+	svc, _, _, _ := newTestService(t, WithSemanticSearch(embedFunc(func(_ context.Context, texts []string) ([][]float32, error) {
+		embedded = append(embedded, texts...)
+		return [][]float32{{1, 0}}, nil
+	}), index))
+
+	opts := SearchOpts{Mode: SearchModeSemantic, Lang: "go"}
+	index.EXPECT().Nearest(mock.Anything, "repo:acme/ops rollback -helm tag:ci", []float32{1, 0}, opts).Return(&SearchResults{}, nil)
+
+	_, err := svc.SearchDocs(t.Context(), "repo:acme/ops rollback -helm tag:ci lang:go", SearchOpts{Mode: SearchModeSemantic})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"rollback"}, embedded, "qualifiers and excluded terms are not embedded")
+
+	// A query of qualifiers only has nothing to embed.
+	_, err = svc.SearchDocs(t.Context(), "repo:acme/ops", SearchOpts{Mode: SearchModeSemantic})
+	require.NoError(t, err)
+	assert.Len(t, embedded, 1)
+}
+
+func TestSearchDocs_SemanticNotConfigured(t *testing.T) {
+	svc, _, search, _ := newTestService(t)
+
+	search.EXPECT().Search(mock.Anything, "deploy", SearchOpts{Mode: SearchModeSemantic}).Return(&SearchResults{}, nil)
+
+	_, err := svc.SearchDocs(t.Context(), "deploy", SearchOpts{Mode: SearchModeSemantic})
+	require.NoError(t, err)
+}
+
+func TestSearchDocs_SemanticEmbedError(t *testing.T) {
+	svc, _, _, _ := newTestService(t, WithSemanticSearch(embedFunc(func(context.Context, []string) ([][]float32, error) {
+		return nil, errors.New("model unavailable")
+	}), NewMockVectorIndex(t)))
+
+	_, err := svc.SearchDocs(t.Context(), "deploy", SearchOpts{Mode: SearchModeSemantic})
+	assert.ErrorContains(t, err, "model unavailable")
+}
+
+func TestIngestDocuments_Embedding(t *testing.T) {
+	calls := 0
+	index := NewMockVectorIndex(t)
+	svc, store, search, processor := newTestService(t, WithSemanticSearch(embedFunc(func(_ context.Context, texts []string) ([][]float32, error) {
+		calls++
+		assert.Equal(t, []string{"Deploy\n\nRoll out a release."}, texts)
+
+		return [][]float32{{0.5, 0.5}}, nil
+	}), index))
+
+	processor.EXPECT().ExtractTitle(mock.Anything).Return("Deploy")
+	processor.EXPECT().ToPlainText(mock.Anything).Return("Roll out a release.")
+	processor.EXPECT().ExtractCodeBlocks(mock.Anything).Return(nil)
+	processor.EXPECT().ExtractHeadings(mock.Anything).Return(nil)
+	store.EXPECT().Save(mock.Anything, mock.Anything).Return(nil)
+	search.EXPECT().Index(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	_, checksum := embeddingText(&Document{Title: "Deploy"}, "Roll out a release.", nil)
+
+	index.EXPECT().Checksum(mock.Anything, "owner/repo/deploy.md").Return("", nil).Once()
+	index.EXPECT().Upsert(mock.Anything, VectorEntry{
+		ID:          "owner/repo/deploy.md",
+		Repo:        "owner/repo",
+		Path:        "deploy.md",
+		Title:       "Deploy",
+		ContentType: ContentTypeMarkdown,
+		Checksum:    checksum,
+		Vector:      []float32{0.5, 0.5},
+	}).Return(nil).Once()
+
+	req := &IngestRequest{
+		Repo:      "owner/repo",
+		Documents: []IngestDocument{{Path: "deploy.md", Content: "# Deploy\n\nRoll out a release.", Action: actionUpsert}},
+	}
+
+	_, err := svc.IngestDocuments(t.Context(), req)
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+
+	// Unchanged text is not embedded again.
+	index.EXPECT().Checksum(mock.Anything, "owner/repo/deploy.md").Return(checksum, nil).Once()
+
+	_, err = svc.IngestDocuments(t.Context(), req)
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestIngestDocuments_EmbeddingFailureDoesNotFailIngest(t *testing.T) {
+	index := NewMockVectorIndex(t)
+	svc, store, search, processor := newTestService(t, WithSemanticSearch(embedFunc(func(context.Context, []string) ([][]float32, error) {
+		return nil, errors.New("model unavailable")
+	}), index))
+
+	processor.EXPECT().ExtractTitle(mock.Anything).Return("Deploy")
+	processor.EXPECT().ToPlainText(mock.Anything).Return("Roll out a release.")
+	processor.EXPECT().ExtractCodeBlocks(mock.Anything).Return(nil)
+	processor.EXPECT().ExtractHeadings(mock.Anything).Return(nil)
+	store.EXPECT().Save(mock.Anything, mock.Anything).Return(nil)
+	search.EXPECT().Index(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	index.EXPECT().Checksum(mock.Anything, "owner/repo/deploy.md").Return("", nil)
+
+	resp, err := svc.IngestDocuments(t.Context(), &IngestRequest{
+		Repo:      "owner/repo",
+		Documents: []IngestDocument{{Path: "deploy.md", Content: "# Deploy", Action: actionUpsert}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, resp.Indexed)
+}
+
+func TestEmbedAll(t *testing.T) {
+	index := NewMockVectorIndex(t)
+	svc, store, _, processor := newTestService(t, WithSemanticSearch(embedFunc(func(_ context.Context, texts []string) ([][]float32, error) {
+		return [][]float32{{1}}, nil
+	}), index))
+
+	store.EXPECT().ListRepos(mock.Anything).Return([]RepoInfo{{Name: "owner/repo"}}, nil)
+	store.EXPECT().List(mock.Anything, "owner/repo").Return([]DocumentMeta{{Path: "a.md"}, {Path: "gone.md"}}, nil)
+	store.EXPECT().Get(mock.Anything, "owner/repo", "a.md").Return(Document{ID: "owner/repo/a.md", Repo: "owner/repo", Path: "a.md", Content: "# A"}, nil)
+	store.EXPECT().Get(mock.Anything, "owner/repo", "gone.md").Return(Document{}, ErrNotFound)
+	processor.EXPECT().ToPlainText([]byte("# A")).Return("A")
+	processor.EXPECT().ExtractCodeBlocks([]byte("# A")).Return([]CodeBlock{{Lang: "go"}, {}, {Lang: "go"}})
+	index.EXPECT().Checksum(mock.Anything, "owner/repo/a.md").Return("", nil)
+	index.EXPECT().Upsert(mock.Anything, mock.MatchedBy(func(e VectorEntry) bool {
+		return e.ID == "owner/repo/a.md" && slices.Equal(e.Langs, []string{"go"}) && e.Code
+	})).Return(nil)
+
+	embedded, failed, err := svc.EmbedAll(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 1, embedded)
+	assert.Equal(t, 1, failed)
+}
+
+func TestEmbedAll_NotConfigured(t *testing.T) {
+	svc := newTestServiceOnly(t)
+
+	_, _, err := svc.EmbedAll(t.Context())
+	assert.ErrorIs(t, err, ErrNotSupported)
+}
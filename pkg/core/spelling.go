@@ -0,0 +1,51 @@
+package core
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+)
+
+// SpellChecker is implemented by search engines that can correct misspelled queries from
+// the terms of their index.
+type SpellChecker interface {
+	// CorrectQuery returns query with each word missing from the index replaced by the
+	// closest indexed term. Quoted phrases and filters such as "lang:go" are kept as they
+	// are. It returns an empty string when no word needs or has a correction.
+	CorrectQuery(ctx context.Context, query string) (string, error)
+}
+
+// suggestCorrection returns a corrected query for a search that found nothing, or an
+// empty string when the search engine cannot correct queries or the correction finds
+// nothing either. Failures are logged, as a missing suggestion does not fail the search.
+func (s *Service) suggestCorrection(ctx context.Context, query string, opts SearchOpts) string {
+	checker, ok := s.search.(SpellChecker)
+	if !ok || strings.TrimSpace(query) == "" {
+		return ""
+	}
+
+	corrected, err := checker.CorrectQuery(ctx, query)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to correct search query", "query", query, "error", err)
+		return ""
+	}
+
+	if corrected == "" || strings.EqualFold(corrected, query) {
+		return ""
+	}
+
+	// Only offer corrections that lead somewhere.
+	opts.Limit, opts.Offset = 1, 0
+
+	results, err := s.search.Search(ctx, corrected, opts)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to search corrected query", "query", corrected, "error", err)
+		return ""
+	}
+
+	if results.Total == 0 {
+		return ""
+	}
+
+	return corrected
+}
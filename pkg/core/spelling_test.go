@@ -0,0 +1,96 @@
+//go:build !compile
+
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// spellCheckingEngine is a search engine that can correct misspelled queries.
+type spellCheckingEngine struct {
+	*MocksearchEngine
+	*MockSpellChecker
+}
+
+// withSpellChecker makes the search engine of a test service correct misspelled queries
+// with checker.
+func withSpellChecker(checker *MockSpellChecker) Option {
+	return func(s *Service) {
+		search, _ := s.search.(*MocksearchEngine)
+		s.search = spellCheckingEngine{MocksearchEngine: search, MockSpellChecker: checker}
+	}
+}
+
+func TestSearchDocs_Suggestion(t *testing.T) {
+	checker := NewMockSpellChecker(t)
+	svc, _, search, _ := newTestService(t, withSpellChecker(checker))
+
+	search.EXPECT().Search(mock.Anything, "kubernets deploy", SearchOpts{Lang: "go", Limit: 20}).Return(&SearchResults{}, nil)
+	checker.EXPECT().CorrectQuery(mock.Anything, "kubernets deploy").Return("kubernetes deploy", nil)
+	search.EXPECT().Search(mock.Anything, "kubernetes deploy", SearchOpts{Lang: "go", Limit: 1}).Return(&SearchResults{Total: 3}, nil)
+
+	results, err := svc.SearchDocs(t.Context(), "kubernets deploy lang:go", SearchOpts{Limit: 20})
+	require.NoError(t, err)
+	assert.Equal(t, "kubernetes deploy", results.Suggestion)
+}
+
+func TestSearchDocs_NoSuggestion(t *testing.T) {
+	tests := []struct {
+		setup func(search *MocksearchEngine, checker *MockSpellChecker)
+		name  string
+		opts  SearchOpts
+	}{
+		{
+			name: "results found",
+			setup: func(search *MocksearchEngine, _ *MockSpellChecker) {
+				search.EXPECT().Search(mock.Anything, "deploy", mock.Anything).Return(&SearchResults{Total: 1}, nil)
+			},
+		},
+		{
+			name: "later page",
+			opts: SearchOpts{Limit: 10, Offset: 10},
+			setup: func(search *MocksearchEngine, _ *MockSpellChecker) {
+				search.EXPECT().Search(mock.Anything, "deploy", mock.Anything).Return(&SearchResults{}, nil)
+			},
+		},
+		{
+			name: "nothing to correct",
+			setup: func(search *MocksearchEngine, checker *MockSpellChecker) {
+				search.EXPECT().Search(mock.Anything, "deploy", mock.Anything).Return(&SearchResults{}, nil)
+				checker.EXPECT().CorrectQuery(mock.Anything, "deploy").Return("", nil)
+			},
+		},
+		{
+			name: "correction finds nothing",
+			setup: func(search *MocksearchEngine, checker *MockSpellChecker) {
+				search.EXPECT().Search(mock.Anything, "deploy", mock.Anything).Return(&SearchResults{}, nil)
+				checker.EXPECT().CorrectQuery(mock.Anything, "deploy").Return("deploys", nil)
+				search.EXPECT().Search(mock.Anything, "deploys", mock.Anything).Return(&SearchResults{}, nil)
+			},
+		},
+		{
+			name: "correction fails",
+			setup: func(search *MocksearchEngine, checker *MockSpellChecker) {
+				search.EXPECT().Search(mock.Anything, "deploy", mock.Anything).Return(&SearchResults{}, nil)
+				checker.EXPECT().CorrectQuery(mock.Anything, "deploy").Return("", errors.New("index closed"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewMockSpellChecker(t)
+			svc, _, search, _ := newTestService(t, withSpellChecker(checker))
+			tt.setup(search, checker)
+
+			results, err := svc.SearchDocs(t.Context(), "deploy", tt.opts)
+			require.NoError(t, err)
+			assert.Empty(t, results.Suggestion)
+		})
+	}
+}
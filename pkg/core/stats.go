@@ -0,0 +1,164 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// statsDays is the number of days, including today, covered by the daily search counts.
+const statsDays = 7
+
+// Stats is a summary of the content served by the instance and of its recent activity.
+// Activity counters are kept in memory by each instance and restart from zero with it.
+type Stats struct {
+	// Since is when this instance started counting activity.
+	Since time.Time `json:"since"`
+	// IndexBytes is the size of the search index, or nil when the search engine cannot report it.
+	IndexBytes *int64 `json:"index_bytes,omitempty"`
+	// RenderBudget summarizes the rendering costs of documents, or is nil when render budgets
+	// are disabled.
+	RenderBudget *RenderBudgetStats `json:"render_budget,omitempty"`
+	// Experiment holds the searches and result clicks of each variant of the ranking
+	// experiment, or is nil when none runs.
+	Experiment *ExperimentStats `json:"experiment,omitempty"`
+	// Shed counts the requests rejected because the server was saturated, or is nil when
+	// load shedding is disabled.
+	Shed *ShedCounts `json:"shed,omitempty"`
+	// StorageBytes is the size of the stored content, or nil when the store cannot report it.
+	StorageBytes *int64 `json:"storage_bytes,omitempty"`
+	// SearchesPerDay holds the number of searches of each of the last seven days, oldest first.
+	SearchesPerDay []DayCount `json:"searches_per_day"`
+	Repos          int        `json:"repos"`
+	Documents      int        `json:"documents"`
+	// Ingests is the number of successful ingest requests.
+	Ingests int `json:"ingests"`
+	// IngestedDocuments is the number of documents upserted by those requests.
+	IngestedDocuments int `json:"ingested_documents"`
+}
+
+// DayCount is a count of events on a single UTC day.
+type DayCount struct {
+	Date  string `json:"date"`
+	Count int    `json:"count"`
+}
+
+// ShedCounts counts the requests an instance rejected or delayed by load shedding since it
+// started. It is filled in by the API server.
+type ShedCounts struct {
+	Reads         int `json:"reads"`          // Portal requests rejected.
+	Ingests       int `json:"ingests"`        // Ingest requests rejected.
+	QueuedIngests int `json:"queued_ingests"` // Ingest requests that had to wait for a slot.
+}
+
+// sizer is optionally implemented by a document store or search engine that can report
+// how many bytes it occupies.
+type sizer interface {
+	Size(ctx context.Context) (int64, error)
+}
+
+// activity counts searches and ingests handled by the instance.
+type activity struct {
+	started      time.Time
+	searches     map[string]int
+	mu           sync.Mutex
+	ingests      int
+	ingestedDocs int
+}
+
+// recordSearch counts a search made at now. Counts older than statsDays are discarded.
+func (a *activity) recordSearch(now time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.searches == nil {
+		a.searches = make(map[string]int)
+	}
+
+	a.searches[dayKey(now)]++
+
+	oldest := dayKey(now.AddDate(0, 0, 1-statsDays))
+
+	for day := range a.searches {
+		if day < oldest {
+			delete(a.searches, day)
+		}
+	}
+}
+
+// recordIngest counts a successful ingest request that upserted docs documents.
+func (a *activity) recordIngest(docs int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.ingests++
+	a.ingestedDocs += docs
+}
+
+// fill copies the activity counters into stats, with one search count for each of the
+// statsDays days up to now.
+func (a *activity) fill(stats *Stats, now time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	stats.Since = a.started
+	stats.Ingests = a.ingests
+	stats.IngestedDocuments = a.ingestedDocs
+	stats.SearchesPerDay = make([]DayCount, 0, statsDays)
+
+	for i := statsDays - 1; i >= 0; i-- {
+		day := dayKey(now.AddDate(0, 0, -i))
+		stats.SearchesPerDay = append(stats.SearchesPerDay, DayCount{Date: day, Count: a.searches[day]})
+	}
+}
+
+// dayKey returns the UTC date of t as YYYY-MM-DD.
+func dayKey(t time.Time) string {
+	return t.UTC().Format(time.DateOnly)
+}
+
+// Stats returns the number of repositories and documents stored, the size of the store
+// and search index when they can report it, and the activity counters of this instance.
+func (s *Service) Stats(ctx context.Context) (*Stats, error) {
+	repos, err := s.store.ListRepos(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repos: %w", err)
+	}
+
+	stats := &Stats{Repos: len(repos)}
+
+	for _, repo := range repos {
+		stats.Documents += repo.DocCount
+	}
+
+	stats.StorageBytes = componentSize(ctx, s.store, "storage")
+	stats.IndexBytes = componentSize(ctx, s.search, "search index")
+
+	s.activity.fill(stats, time.Now())
+	s.fillRenderStats(stats)
+
+	if s.experiment != nil {
+		stats.Experiment = s.experiment.stats()
+	}
+
+	return stats, nil
+}
+
+// componentSize returns the size reported by c, or nil if c does not implement sizer or
+// fails to report it. Failures are logged rather than failing the whole summary.
+func componentSize(ctx context.Context, c any, name string) *int64 {
+	sz, ok := c.(sizer)
+	if !ok {
+		return nil
+	}
+
+	size, err := sz.Size(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to get "+name+" size", "error", err)
+		return nil
+	}
+
+	return &size
+}
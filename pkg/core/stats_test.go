@@ -0,0 +1,136 @@
+//go:build !compile
+
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// sizedStore is a document store that reports its size.
+type sizedStore struct {
+	*MockdocStore
+	err  error
+	size int64
+}
+
+func (s sizedStore) Size(context.Context) (int64, error) {
+	return s.size, s.err
+}
+
+func TestStats(t *testing.T) {
+	svc, store, _, _ := newTestService(t)
+
+	store.EXPECT().ListRepos(mock.Anything).Return([]RepoInfo{
+		{Name: "owner/a", DocCount: 3},
+		{Name: "owner/b", DocCount: 4},
+	}, nil)
+
+	stats, err := svc.Stats(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.Repos)
+	assert.Equal(t, 7, stats.Documents)
+	assert.Nil(t, stats.StorageBytes)
+	assert.Nil(t, stats.IndexBytes)
+	assert.Equal(t, svc.activity.started, stats.Since)
+	assert.Len(t, stats.SearchesPerDay, statsDays)
+	assert.Equal(t, dayKey(time.Now()), stats.SearchesPerDay[statsDays-1].Date)
+}
+
+func TestStats_Sizes(t *testing.T) {
+	tests := []struct {
+		want *int64
+		err  error
+		name string
+	}{
+		{name: "reported", want: new(int64(2048))},
+		{name: "failed", err: errors.New("disk unavailable")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMockdocStore(t)
+			svc := New(sizedStore{MockdocStore: store, size: 2048, err: tt.err}, NewMocksearchEngine(t), map[ContentType]ContentProcessor{
+				ContentTypeMarkdown: NewMockContentProcessor(t),
+			})
+
+			store.EXPECT().ListRepos(mock.Anything).Return(nil, nil)
+
+			stats, err := svc.Stats(t.Context())
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, stats.StorageBytes)
+			assert.Nil(t, stats.IndexBytes)
+		})
+	}
+}
+
+func TestStats_StoreError(t *testing.T) {
+	svc, store, _, _ := newTestService(t)
+
+	store.EXPECT().ListRepos(mock.Anything).Return(nil, errors.New("store unavailable"))
+
+	_, err := svc.Stats(t.Context())
+	assert.ErrorContains(t, err, "failed to list repos")
+}
+
+func TestStats_Activity(t *testing.T) {
+	svc, store, search, processor := newTestService(t)
+
+	search.EXPECT().Search(mock.Anything, "query", SearchOpts{}).Return(&SearchResults{}, nil)
+	processor.EXPECT().ExtractTitle([]byte("# Doc")).Return("Doc")
+	processor.EXPECT().ToPlainText([]byte("# Doc")).Return("Doc")
+	processor.EXPECT().ExtractCodeBlocks([]byte("# Doc")).Return(nil)
+	processor.EXPECT().ExtractHeadings([]byte("# Doc")).Return(nil)
+	store.EXPECT().Save(mock.Anything, mock.Anything).Return(nil)
+	search.EXPECT().Index(mock.Anything, mock.Anything, "Doc", []CodeBlock(nil), []Heading(nil)).Return(nil)
+	store.EXPECT().ListRepos(mock.Anything).Return(nil, nil)
+
+	_, err := svc.SearchDocs(t.Context(), "query", SearchOpts{})
+	require.NoError(t, err)
+
+	_, err = svc.IngestDocuments(t.Context(), &IngestRequest{
+		Repo:      "owner/repo",
+		Documents: []IngestDocument{{Path: "doc.md", Content: "# Doc", Action: actionUpsert}},
+	})
+	require.NoError(t, err)
+
+	stats, err := svc.Stats(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Ingests)
+	assert.Equal(t, 1, stats.IngestedDocuments)
+	assert.Equal(t, 1, stats.SearchesPerDay[statsDays-1].Count)
+}
+
+func TestActivity_RecordSearch(t *testing.T) {
+	var a activity
+
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	a.recordSearch(now.AddDate(0, 0, -10))
+	a.recordSearch(now.AddDate(0, 0, -6))
+	a.recordSearch(now.AddDate(0, 0, -2))
+	a.recordSearch(now)
+	a.recordSearch(now)
+
+	assert.Len(t, a.searches, 3, "counts older than the reported days are discarded")
+
+	var stats Stats
+
+	a.fill(&stats, now)
+
+	assert.Equal(t, []DayCount{
+		{Date: "2025-06-09", Count: 1},
+		{Date: "2025-06-10", Count: 0},
+		{Date: "2025-06-11", Count: 0},
+		{Date: "2025-06-12", Count: 0},
+		{Date: "2025-06-13", Count: 1},
+		{Date: "2025-06-14", Count: 0},
+		{Date: "2025-06-15", Count: 2},
+	}, stats.SearchesPerDay)
+}
@@ -0,0 +1,202 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultSuggestionsPerHour is the number of edit suggestions accepted per hour when
+// no limit is configured.
+const defaultSuggestionsPerHour = 20
+
+// SuggestConfig configures edit suggestions, which readers make in the portal and which
+// are proposed to the source repository as pull requests.
+type SuggestConfig struct {
+	// Repos are the repositories accepting suggestions. Branch is the base branch of the
+	// pull requests and Dir the directory of the published documents.
+	Repos      []EditRepo `mapstructure:"repos"`
+	MaxPerHour int        `mapstructure:"max_per_hour"` // Suggestions accepted per hour from all readers (default 20).
+}
+
+// PullRequest proposes a change of a single file to a source repository.
+type PullRequest struct {
+	Repo    string // Repository or monorepo project the document belongs to.
+	Base    string // Branch the change is proposed to; empty for the default branch.
+	BaseRef string // Commit the change is based on; empty for the head of Base.
+	Path    string // Path of the file in the repository.
+	Title   string
+	Body    string
+	Content string
+}
+
+// PullRequester opens pull requests on source repositories.
+type PullRequester interface {
+	// OpenPullRequest commits the change to a new branch, opens a pull request from it
+	// and returns the pull request's URL.
+	OpenPullRequest(ctx context.Context, pr PullRequest) (string, error)
+}
+
+// Suggestion is an edit of a document proposed by a reader.
+type Suggestion struct {
+	Repo        string `json:"repo"`
+	Path        string `json:"path"`
+	Content     string `json:"content"`
+	Title       string `json:"title,omitempty"`       // Pull request title (default "Update <path>").
+	Description string `json:"description,omitempty"` // Why the change is proposed.
+	Author      string `json:"author,omitempty"`      // Name of the reader, credited in the pull request.
+}
+
+// SuggestionResponse is the outcome of a submitted suggestion.
+type SuggestionResponse struct {
+	URL string `json:"url"` // URL of the opened pull request.
+}
+
+// suggester holds the repositories accepting suggestions and the times of the suggestions
+// accepted in the last hour.
+type suggester struct {
+	requester PullRequester
+	repos     map[string]EditRepo
+	recent    []time.Time
+	limit     int
+	mu        sync.Mutex
+}
+
+// WithSuggestions lets readers suggest edits to the documents of the configured
+// repositories, proposed through pull requests opened by the given requester.
+func WithSuggestions(cfg SuggestConfig, pr PullRequester) Option {
+	return func(s *Service) {
+		if len(cfg.Repos) == 0 {
+			return
+		}
+
+		sg := &suggester{
+			requester: pr,
+			repos:     make(map[string]EditRepo, len(cfg.Repos)),
+			limit:     cfg.MaxPerHour,
+		}
+
+		if sg.limit <= 0 {
+			sg.limit = defaultSuggestionsPerHour
+		}
+
+		for _, r := range cfg.Repos {
+			sg.repos[r.Repo] = r
+		}
+
+		s.suggest = sg
+	}
+}
+
+// Suggestible reports whether readers can suggest edits to the documents of repo.
+func (s *Service) Suggestible(repo string) bool {
+	if s.suggest == nil {
+		return false
+	}
+
+	_, ok := s.suggest.repos[repo]
+
+	return ok
+}
+
+// SuggestEdit proposes a reader's edit of a document to its source repository by opening
+// a pull request based on the commit the document was last published from. It returns an
+// error wrapping ErrNotSupported if the repository does not accept suggestions or the
+// served document is redacted by the content policy, ErrNotFound if the document does not
+// exist, ErrConflict if the suggestion does not change the document, ErrLimitExceeded if
+// the content cannot be rendered safely and ErrRateLimited once the hourly limit of
+// suggestions is reached.
+func (s *Service) SuggestEdit(ctx context.Context, sg Suggestion) (*SuggestionResponse, error) { //nolint:gocritic // Suggestion is passed by value like EditRequest
+	if !s.Suggestible(sg.Repo) {
+		return nil, fmt.Errorf("%w: repository %s does not accept suggestions", ErrNotSupported, sg.Repo)
+	}
+
+	doc, err := s.store.Get(ctx, sg.Repo, sg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document: %w", err)
+	}
+
+	// Readers edit the served document, so a redacted document would be proposed with its
+	// redactions.
+	if s.redactDocument(sg.Repo, doc).Content != doc.Content {
+		return nil, fmt.Errorf("%w: document %s/%s has redacted content", ErrNotSupported, sg.Repo, sg.Path)
+	}
+
+	if sg.Content == doc.Content {
+		return nil, fmt.Errorf("%w: the suggestion does not change document %s/%s", ErrConflict, sg.Repo, sg.Path)
+	}
+
+	err = s.validateContent(ctx, &IngestRequest{
+		Repo:      sg.Repo,
+		Documents: []IngestDocument{{Path: sg.Path, Content: sg.Content, Action: actionUpsert, ContentType: doc.ContentType}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.suggest.allow(time.Now()) {
+		return nil, fmt.Errorf("%w: no more than %d suggestions are accepted per hour", ErrRateLimited, s.suggest.limit)
+	}
+
+	title := strings.TrimSpace(sg.Title)
+	if title == "" {
+		title = "Update " + sg.Path
+	}
+
+	author := strings.TrimSpace(sg.Author)
+	if author == "" {
+		author = "a reader"
+	}
+
+	body := strings.TrimSpace(sg.Description)
+	if body != "" {
+		body += "\n\n"
+	}
+
+	body += "Suggested by " + author + " in the documentation portal."
+
+	cfg := s.suggest.repos[sg.Repo]
+
+	url, err := s.suggest.requester.OpenPullRequest(ctx, PullRequest{
+		Repo:    sg.Repo,
+		Base:    cfg.Branch,
+		BaseRef: doc.CommitSHA,
+		Path:    cfg.sourcePath(sg.Path),
+		Title:   title,
+		Body:    body,
+		Content: sg.Content,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pull request: %w", err)
+	}
+
+	slog.InfoContext(ctx, "edit suggested", "repo", sg.Repo, "path", sg.Path, "author", author, "url", url)
+
+	return &SuggestionResponse{URL: url}, nil
+}
+
+// allow reports whether another suggestion is accepted at now, recording it if so.
+func (sg *suggester) allow(now time.Time) bool {
+	sg.mu.Lock()
+	defer sg.mu.Unlock()
+
+	cutoff := now.Add(-time.Hour)
+
+	i := 0
+	for i < len(sg.recent) && !sg.recent[i].After(cutoff) {
+		i++
+	}
+
+	sg.recent = sg.recent[i:]
+
+	if len(sg.recent) >= sg.limit {
+		return false
+	}
+
+	sg.recent = append(sg.recent, now)
+
+	return true
+}
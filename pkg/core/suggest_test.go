@@ -0,0 +1,116 @@
+//go:build !compile
+
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// pullRequestFunc adapts a function to the PullRequester interface.
+type pullRequestFunc func(ctx context.Context, pr PullRequest) (string, error)
+
+func (f pullRequestFunc) OpenPullRequest(ctx context.Context, pr PullRequest) (string, error) {
+	return f(ctx, pr)
+}
+
+// testSuggestConfig makes the owner/wiki repository of test services accept suggestions.
+var testSuggestConfig = SuggestConfig{Repos: []EditRepo{{Repo: "owner/wiki", Branch: "main", Dir: "/docs"}}}
+
+func TestSuggestEdit(t *testing.T) {
+	var got PullRequest
+
+	svc, store, _, _ := newTestService(t, WithSuggestions(testSuggestConfig, pullRequestFunc(func(_ context.Context, pr PullRequest) (string, error) {
+		got = pr
+		return "https://github.com/owner/wiki/pull/7", nil
+	})))
+
+	store.EXPECT().Get(mock.Anything, "owner/wiki", "guide.md").
+		Return(Document{Repo: "owner/wiki", Path: "guide.md", Content: "# Guide", CommitSHA: "def456"}, nil)
+
+	resp, err := svc.SuggestEdit(t.Context(), Suggestion{
+		Repo:        "owner/wiki",
+		Path:        "guide.md",
+		Content:     "# Guide\n\nFixed.",
+		Description: "The guide was missing a step.",
+		Author:      "Alex",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/owner/wiki/pull/7", resp.URL)
+	assert.Equal(t, PullRequest{
+		Repo:    "owner/wiki",
+		Base:    "main",
+		BaseRef: "def456",
+		Path:    "docs/guide.md",
+		Title:   "Update guide.md",
+		Body:    "The guide was missing a step.\n\nSuggested by Alex in the documentation portal.",
+		Content: "# Guide\n\nFixed.",
+	}, got)
+
+	_, err = svc.SuggestEdit(t.Context(), Suggestion{Repo: "owner/wiki", Path: "guide.md", Content: "# Guide"})
+	assert.ErrorIs(t, err, ErrConflict, "an unchanged document is not proposed")
+}
+
+func TestSuggestEdit_Unsupported(t *testing.T) {
+	svc, store, _, _ := newTestService(t, WithSuggestions(testSuggestConfig, pullRequestFunc(nil)), WithContentPolicy(secretPolicy{}))
+
+	_, err := svc.SuggestEdit(t.Context(), Suggestion{Repo: "owner/repo", Path: "guide.md", Content: "# New"})
+	assert.ErrorIs(t, err, ErrNotSupported)
+
+	store.EXPECT().Get(mock.Anything, "owner/wiki", "secret.md").Return(Document{Content: "# The secret"}, nil)
+
+	_, err = svc.SuggestEdit(t.Context(), Suggestion{Repo: "owner/wiki", Path: "secret.md", Content: "# New"})
+	assert.ErrorIs(t, err, ErrNotSupported, "redacted documents are not proposed with their redactions")
+
+	store.EXPECT().Get(mock.Anything, "owner/wiki", "missing.md").Return(Document{}, ErrNotFound)
+
+	_, err = svc.SuggestEdit(t.Context(), Suggestion{Repo: "owner/wiki", Path: "missing.md", Content: "# New"})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestSuggestEdit_RateLimited(t *testing.T) {
+	opened := 0
+
+	svc, store, _, _ := newTestService(t, WithSuggestions(testSuggestConfig, pullRequestFunc(func(context.Context, PullRequest) (string, error) {
+		opened++
+		return "https://github.com/owner/wiki/pull/1", nil
+	})))
+	svc.suggest.limit = 2
+
+	store.EXPECT().Get(mock.Anything, "owner/wiki", "guide.md").Return(Document{Content: "# Guide"}, nil)
+
+	for range 2 {
+		_, err := svc.SuggestEdit(t.Context(), Suggestion{Repo: "owner/wiki", Path: "guide.md", Content: "# New"})
+		require.NoError(t, err)
+	}
+
+	_, err := svc.SuggestEdit(t.Context(), Suggestion{Repo: "owner/wiki", Path: "guide.md", Content: "# New"})
+	assert.ErrorIs(t, err, ErrRateLimited)
+	assert.Equal(t, 2, opened)
+}
+
+func TestSuggester_Allow(t *testing.T) {
+	sg := &suggester{limit: 1}
+	now := time.Now()
+
+	assert.True(t, sg.allow(now))
+	assert.False(t, sg.allow(now.Add(30*time.Minute)))
+	assert.True(t, sg.allow(now.Add(time.Hour)), "suggestions older than an hour no longer count")
+}
+
+func TestPreviewEdit_Suggestible(t *testing.T) {
+	svc, store, _, processor := newTestService(t, WithSuggestions(testSuggestConfig, pullRequestFunc(nil)))
+
+	store.EXPECT().Get(mock.Anything, "owner/wiki", "guide.md").Return(Document{Content: "# Guide"}, nil)
+	processor.EXPECT().RenderHTML([]byte("# New")).
+		Return([]byte(`<h1 id="new">New</h1>`), nil, nil)
+
+	html, _, err := svc.PreviewEdit(t.Context(), "owner/wiki", "guide.md", "# New")
+	require.NoError(t, err)
+	assert.Contains(t, string(html), "<h1")
+}
@@ -0,0 +1,105 @@
+package core
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxSummaryLen is the maximum length of a document summary in runes. Summaries are shown
+// in place of search snippets and in link previews, which cut off longer descriptions.
+const maxSummaryLen = 200
+
+// SummaryExtractor is optionally implemented by a ContentProcessor that can pick a short
+// description from the content, such as its first paragraph. Documents of processors that
+// do not implement it are summarized from the start of their plain text.
+type SummaryExtractor interface {
+	ExtractSummary(src []byte) string
+}
+
+// Summarizer generates the summary of a document, for example with a language model. It
+// is given the document and its plain text, and its result is shortened to fit a summary.
+type Summarizer interface {
+	Summarize(ctx context.Context, doc *Document, plainText string) (string, error)
+}
+
+// WithSummarizer generates document summaries at ingest time with the given summarizer
+// instead of the first paragraph. Documents fall back to the first paragraph when it fails.
+func WithSummarizer(sum Summarizer) Option {
+	return func(s *Service) {
+		s.summarizer = sum
+	}
+}
+
+// summarize returns the summary of a document being ingested: the summarizer's, when one
+// is configured, otherwise the extract picked by the processor, or the first line of the
+// plain text that is not the title.
+func (s *Service) summarize(ctx context.Context, doc *Document, processor ContentProcessor, plainText string) string {
+	if s.summarizer != nil {
+		summary, err := s.summarizer.Summarize(ctx, doc, plainText)
+
+		switch {
+		case err != nil:
+			slog.WarnContext(ctx, "summary generation failed, using the first paragraph",
+				"repo", doc.Repo, "path", doc.Path, "err", err)
+		case strings.TrimSpace(summary) != "":
+			return truncateSummary(summary)
+		}
+	}
+
+	if ext, ok := processor.(SummaryExtractor); ok {
+		return truncateSummary(ext.ExtractSummary([]byte(doc.Content)))
+	}
+
+	for line := range strings.Lines(plainText) {
+		line = strings.TrimSpace(line)
+		if line != "" && line != doc.Title {
+			return truncateSummary(line)
+		}
+	}
+
+	return ""
+}
+
+// fillSummaries sets the Summary of the search results without content fragments, so
+// that results matched on their title still describe the document. Documents that cannot
+// be read are left without a summary.
+func (s *Service) fillSummaries(ctx context.Context, results *SearchResults) {
+	if results == nil {
+		return
+	}
+
+	for i := range results.Hits {
+		hit := &results.Hits[i]
+		if len(hit.ContentFragments) > 0 {
+			continue
+		}
+
+		doc, err := s.store.Get(ctx, hit.Repo, hit.Path)
+		if err != nil {
+			slog.DebugContext(ctx, "summary lookup skipped", "docID", hit.ID, "err", err)
+			continue
+		}
+
+		hit.Summary = doc.Summary
+	}
+}
+
+// truncateSummary collapses the whitespace of a summary and shortens it to maxSummaryLen
+// runes, cutting at a word boundary and marking the cut with an ellipsis.
+func truncateSummary(summary string) string {
+	summary = strings.Join(strings.Fields(summary), " ")
+	if utf8.RuneCountInString(summary) <= maxSummaryLen {
+		return summary
+	}
+
+	runes := []rune(summary)
+	cut := string(runes[:maxSummaryLen-1])
+
+	if i := strings.LastIndexByte(cut, ' '); i > 0 {
+		cut = cut[:i]
+	}
+
+	return strings.TrimRight(cut, " ,;:.-") + "…"
+}
@@ -0,0 +1,97 @@
+//go:build !compile
+
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// summarizeFunc adapts a function to the Summarizer interface.
+type summarizeFunc func(ctx context.Context, doc *Document, plainText string) (string, error)
+
+func (f summarizeFunc) Summarize(ctx context.Context, doc *Document, plainText string) (string, error) {
+	return f(ctx, doc, plainText)
+}
+
+// summaryProcessor is a content processor that extracts a fixed summary.
+type summaryProcessor struct {
+	*MockContentProcessor
+	summary string
+}
+
+func (p summaryProcessor) ExtractSummary([]byte) string {
+	return p.summary
+}
+
+func TestIngestDocuments_Summary(t *testing.T) {
+	svc, store, search, processor := newTestService(t)
+
+	processor.EXPECT().ExtractTitle(mock.Anything).Return("Deploy")
+	processor.EXPECT().ToPlainText(mock.Anything).Return("Deploy\n\nRoll out   a release.\nMore text.")
+	processor.EXPECT().ExtractCodeBlocks(mock.Anything).Return(nil)
+	processor.EXPECT().ExtractHeadings(mock.Anything).Return(nil)
+	store.EXPECT().Save(mock.Anything, mock.MatchedBy(func(doc Document) bool {
+		return doc.Summary == "Roll out a release."
+	})).Return(nil)
+	search.EXPECT().Index(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	_, err := svc.IngestDocuments(t.Context(), &IngestRequest{
+		Repo:      "owner/repo",
+		Documents: []IngestDocument{{Path: "deploy.md", Content: "# Deploy\n\nRoll out a release.", Action: actionUpsert}},
+	})
+	require.NoError(t, err)
+}
+
+func TestSummarize(t *testing.T) {
+	doc := &Document{Repo: "owner/repo", Path: "deploy.md", Title: "Deploy"}
+	processor := summaryProcessor{MockContentProcessor: NewMockContentProcessor(t), summary: "From the first paragraph."}
+
+	svc := newTestServiceOnly(t)
+	assert.Equal(t, "From the first paragraph.", svc.summarize(t.Context(), doc, processor, "Deploy\nText"))
+	assert.Equal(t, "Text", svc.summarize(t.Context(), doc, processor.MockContentProcessor, "Deploy\n\nText"))
+	assert.Empty(t, svc.summarize(t.Context(), doc, processor.MockContentProcessor, "Deploy"))
+
+	svc.summarizer = summarizeFunc(func(_ context.Context, got *Document, plainText string) (string, error) {
+		assert.Same(t, doc, got)
+		return "  Generated\nsummary. ", nil
+	})
+	assert.Equal(t, "Generated summary.", svc.summarize(t.Context(), doc, processor, "Deploy\nText"))
+
+	svc.summarizer = summarizeFunc(func(context.Context, *Document, string) (string, error) {
+		return "", errors.New("model unavailable")
+	})
+	assert.Equal(t, "From the first paragraph.", svc.summarize(t.Context(), doc, processor, "Deploy\nText"), "failures fall back to the extract")
+}
+
+func TestSearchDocs_Summary(t *testing.T) {
+	svc, store, search, _ := newTestService(t)
+
+	search.EXPECT().Search(mock.Anything, "deploy", mock.Anything).Return(&SearchResults{Hits: []SearchResult{
+		{ID: "owner/repo/deploy.md", Repo: "owner/repo", Path: "deploy.md", TitleFragments: []string{"<mark>Deploy</mark>"}},
+		{ID: "owner/repo/gone.md", Repo: "owner/repo", Path: "gone.md"},
+	}}, nil)
+	store.EXPECT().Get(mock.Anything, "owner/repo", "deploy.md").Return(Document{Summary: "Roll out a release."}, nil)
+	store.EXPECT().Get(mock.Anything, "owner/repo", "gone.md").Return(Document{}, ErrNotFound)
+
+	results, err := svc.SearchDocs(t.Context(), "deploy", SearchOpts{})
+	require.NoError(t, err)
+	assert.Equal(t, "Roll out a release.", results.Hits[0].Summary)
+	assert.Empty(t, results.Hits[1].Summary)
+}
+
+func TestTruncateSummary(t *testing.T) {
+	assert.Equal(t, "Short summary.", truncateSummary(" Short\n summary. "))
+
+	long := strings.Repeat("word ", 60)
+	got := truncateSummary(long)
+
+	assert.LessOrEqual(t, len([]rune(got)), maxSummaryLen)
+	assert.True(t, strings.HasSuffix(got, "word…"), got)
+}
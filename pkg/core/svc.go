@@ -4,10 +4,12 @@ package core
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"log/slog"
 	"regexp"
 	"strings"
+	"sync/atomic"
 	"time"
 	"unicode/utf8"
 )
@@ -30,13 +32,23 @@ type docStore interface {
 	GetAsset(ctx context.Context, repo, path string) ([]byte, error)
 	DeleteAsset(ctx context.Context, repo, path string) error
 	ListAssets(ctx context.Context, repo string) ([]string, error)
+	DeleteRepo(ctx context.Context, repo string) error
+	GetRedirects(ctx context.Context) (map[string]string, error)
+	SaveRedirects(ctx context.Context, redirects map[string]string) error
+	GetAPIKeys(ctx context.Context) ([]APIKey, error)
+	SaveAPIKeys(ctx context.Context, keys []APIKey) error
+	GetHighlights(ctx context.Context) (map[string]RepoHighlights, error)
+	SaveHighlights(ctx context.Context, highlights map[string]RepoHighlights) error
+	GetSiteBanner(ctx context.Context) (*SiteBanner, error)
+	SaveSiteBanner(ctx context.Context, banner *SiteBanner) error
 }
 
 // searchEngine defines the interface for full-text search operations.
 type searchEngine interface {
-	Index(ctx context.Context, doc Document, plainText string) error
+	Index(ctx context.Context, doc Document, plainText string, code []CodeBlock, headings []Heading) error
 	Remove(ctx context.Context, docID string) error
 	Search(ctx context.Context, query string, opts SearchOpts) (*SearchResults, error)
+	Suggest(ctx context.Context, prefix string, limit int) ([]SearchSuggestion, error)
 	ListByRepo(ctx context.Context, repo string) ([]string, error)
 }
 
@@ -53,20 +65,112 @@ type ContentProcessor interface {
 	// anchor IDs, used to resolve search result deep-links. Returns nil when
 	// the content type does not support heading-based navigation.
 	ExtractHeadings(src []byte) []Heading
+	// ExtractCodeBlocks returns the fenced code blocks of the content for code
+	// search. Returns nil when the content type has no code blocks.
+	ExtractCodeBlocks(src []byte) []CodeBlock
+}
+
+// RepoContentProcessor is optionally implemented by a ContentProcessor whose behavior
+// can be configured per repository. ForRepo returns the processor to use for documents
+// of the given repository.
+type RepoContentProcessor interface {
+	ForRepo(repo string) ContentProcessor
+}
+
+// ContentValidator is optionally implemented by a ContentProcessor that refuses content it
+// cannot process within its resource limits. Validate returns an error wrapping
+// ErrLimitExceeded for such content.
+type ContentValidator interface {
+	Validate(src []byte) error
+}
+
+// AnchorMapper is optionally implemented by a ContentProcessor that knows where the
+// headings of the content start in its plain text. Documents of processors that do not
+// implement it have their headings located in the plain text as whole lines.
+type AnchorMapper interface {
+	MapAnchors(src []byte) AnchorMap
+}
+
+// TextFingerprinter is optionally implemented by a ContentProcessor whose plain text,
+// headings or anchors depend on its configuration, such as the scheme of its heading IDs.
+// TextFingerprint identifies that configuration, so that the text stored with a document
+// is extracted again once it changes.
+type TextFingerprinter interface {
+	TextFingerprint() string
+}
+
+// processorFingerprint identifies the processor that extracts the text of a document: its
+// type and, for processors implementing TextFingerprinter, its configuration.
+func processorFingerprint(processor ContentProcessor) string {
+	fingerprint := fmt.Sprintf("%T", processor)
+	if f, ok := processor.(TextFingerprinter); ok {
+		fingerprint += ":" + f.TextFingerprint()
+	}
+
+	return fingerprint
+}
+
+// ContextRenderer is optionally implemented by a ContentProcessor whose rendering does
+// work that should stop with the request, such as compiling diagrams with an external
+// tool. RenderHTMLContext behaves as RenderHTML.
+type ContextRenderer interface {
+	RenderHTMLContext(ctx context.Context, src []byte) ([]byte, []Heading, error)
+}
+
+// renderHTML renders src with processor, passing ctx to processors that accept one.
+func renderHTML(ctx context.Context, processor ContentProcessor, src []byte) ([]byte, []Heading, error) {
+	if r, ok := processor.(ContextRenderer); ok {
+		return r.RenderHTMLContext(ctx, src)
+	}
+
+	return processor.RenderHTML(src)
 }
 
 // Service encapsulates core business logic and dependencies.
 type Service struct {
-	store      docStore
-	search     searchEngine
-	processors map[ContentType]ContentProcessor
+	store        docStore
+	search       searchEngine
+	policy       ContentPolicy
+	republisher  Republisher
+	processors   map[ContentType]ContentProcessor
+	contentTypes []ContentTypeInfo
+	lint         *linter
+	links        *linkChecks
+	rebuild      indexRebuild
+	events       eventHub
+	incidents    incidents
+	editor       *editor
+	suggest      *suggester
+	semantic     *semanticSearch
+	journal      ChangeJournal
+	retention    []RetentionPolicy
+	digests      *digester
+	hybrid       HybridConfig
+	experiment   *experiment
+	migration    *dualWrite
+	saved        *savedSearches
+	dedup        *contentIndex
+	renders      *renderTracker
+	summarizer   Summarizer
+	keys         apiKeyCache
+	highlights   highlightsCache
+	banner       bannerCache
+	quick        quickCache
+	searches     searchCache
+	activity     activity
+	reindexing   atomic.Bool
 }
 
+// Option configures optional Service behavior.
+type Option func(*Service)
+
 // New creates a new Service instance with the provided dependencies.
-// The processors map must contain at least a ContentTypeMarkdown entry.
+// The processors map registers a processor per content type, described to clients by
+// processors implementing ContentTypeDescriber, and must contain at least a
+// ContentTypeMarkdown entry.
 // It panics if processors is nil or does not contain a markdown processor,
 // since markdown is the default fallback for unknown content types.
-func New(store docStore, search searchEngine, processors map[ContentType]ContentProcessor) *Service {
+func New(store docStore, search searchEngine, processors map[ContentType]ContentProcessor, opts ...Option) *Service {
 	if processors == nil {
 		panic("processors map must not be nil")
 	}
@@ -75,25 +179,40 @@ func New(store docStore, search searchEngine, processors map[ContentType]Content
 		panic("processors map must contain a ContentTypeMarkdown entry")
 	}
 
-	return &Service{
-		store:      store,
-		search:     search,
-		processors: processors,
+	s := &Service{
+		store:        store,
+		search:       search,
+		processors:   processors,
+		contentTypes: describeContentTypes(processors),
+	}
+
+	s.activity.started = time.Now().UTC()
+
+	for _, opt := range opts {
+		opt(s)
 	}
+
+	return s
 }
 
-// getProcessor returns the ContentProcessor for the given content type.
+// getProcessor returns the ContentProcessor for the given content type and repository.
 // It falls back to the markdown processor when the content type is empty or unknown.
-func (s *Service) getProcessor(ct ContentType) ContentProcessor {
+// Processors implementing RepoContentProcessor are resolved for the repository.
+func (s *Service) getProcessor(ct ContentType, repo string) ContentProcessor {
 	if ct == "" {
 		ct = ContentTypeMarkdown
 	}
 
-	if p, ok := s.processors[ct]; ok {
-		return p
+	p, ok := s.processors[ct]
+	if !ok {
+		p = s.processors[ContentTypeMarkdown]
+	}
+
+	if rp, ok := p.(RepoContentProcessor); ok {
+		return rp.ForRepo(repo)
 	}
 
-	return s.processors[ContentTypeMarkdown]
+	return p
 }
 
 // IngestDocuments processes a batch of document upserts and deletes from a repository.
@@ -101,29 +220,58 @@ func (s *Service) getProcessor(ct ContentType) ContentProcessor {
 // document set as the complete truth for the repo and removes any stored documents
 // whose paths are not present in the request. Assets (images, etc.) bundled in the
 // request are stored alongside documents and participate in sync cleanup.
+// A monorepo publishes each doc set as a sub-project (owner/repo/project), which is
+// stored and synced independently of the repository. Document and asset paths are
+// normalized first, see normalizePath, and base64-encoded content is decoded. It returns
+// ErrInvalidPath if the repository identifier, a path or encoded content is malformed,
+// and ErrConflict if an upsert would overwrite a stored document on a case-insensitive
+// store, see checkStoredCaseCollisions.
 func (s *Service) IngestDocuments(ctx context.Context, req *IngestRequest) (*IngestResponse, error) {
-	var indexed, deleted int
+	if err := validateRepoName(req.Repo); err != nil {
+		return nil, err
+	}
 
-	for _, ingestDoc := range req.Documents {
-		switch ingestDoc.Action {
-		case actionUpsert:
-			if err := s.upsertDocument(ctx, req.Repo, req.CommitSHA, ingestDoc); err != nil {
-				return nil, fmt.Errorf("failed to upsert document %s: %w", ingestDoc.Path, err)
-			}
+	// Drop cached searches whatever the outcome: a failed ingest may have changed some
+	// documents already.
+	defer s.invalidateSearches()
 
-			indexed++
-		case actionDelete:
-			if err := s.deleteDocument(ctx, req.Repo, ingestDoc.Path); err != nil {
-				return nil, fmt.Errorf("failed to delete document %s: %w", ingestDoc.Path, err)
-			}
+	if err := normalizeRequestPaths(req); err != nil {
+		return nil, err
+	}
 
-			deleted++
-		default:
-			slog.WarnContext(ctx, "unknown document action", "action", ingestDoc.Action, "path", ingestDoc.Path)
-		}
+	if err := decodeRequestContent(req); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkStoredCaseCollisions(ctx, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkProjectCollisions(ctx, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.validateContent(ctx, req); err != nil {
+		return nil, err
+	}
+
+	var indexed, deleted, moved int
+
+	issues, err := s.lintRequest(ctx, req)
+	if err != nil {
+		return nil, err
 	}
 
-	// Process assets (images, diagrams, etc.).
+	var (
+		findings []PolicyFinding
+		upserted []string
+		failed   []FailedDocument
+	)
+
+	before := s.journalSnapshot(ctx, req.Repo)
+
+	// Process assets (images, diagrams, etc.) first, so that documents record the
+	// variants of the images published with them.
 	var assetsStored, assetsDeleted int
 
 	if req.Assets != nil {
@@ -140,6 +288,12 @@ func (s *Service) IngestDocuments(ctx context.Context, req *IngestRequest) (*Ing
 					return nil, fmt.Errorf("failed to delete asset %s: %w", asset.Path, err)
 				}
 
+				if isRasterImage(asset.Path) {
+					if err := s.deleteImageVariants(ctx, req.Repo, asset.Path); err != nil {
+						return nil, fmt.Errorf("failed to delete asset %s: %w", asset.Path, err)
+					}
+				}
+
 				assetsDeleted++
 			default:
 				slog.WarnContext(ctx, "unknown asset action", "action", asset.Action, "path", asset.Path)
@@ -147,13 +301,49 @@ func (s *Service) IngestDocuments(ctx context.Context, req *IngestRequest) (*Ing
 		}
 	}
 
+	for _, ingestDoc := range req.Documents {
+		switch ingestDoc.Action {
+		case actionUpsert:
+			if !s.binaryContent(ingestDoc.ContentType) {
+				var docFindings []PolicyFinding
+
+				ingestDoc.Content, docFindings = s.applyPolicy(ctx, req.Repo, ingestDoc.Path, ingestDoc.Content)
+				findings = append(findings, docFindings...)
+			}
+
+			err := recoverDocument(ctx, req.Repo, ingestDoc.Path, func() error {
+				return s.upsertDocument(ctx, req, ingestDoc)
+			})
+			if errors.Is(err, errProcessingPanic) {
+				failed = append(failed, FailedDocument{Path: ingestDoc.Path, Error: err.Error()})
+				continue
+			}
+
+			if err != nil {
+				return nil, fmt.Errorf("failed to upsert document %s: %w", ingestDoc.Path, err)
+			}
+
+			upserted = append(upserted, ingestDoc.Path)
+			indexed++
+		case actionDelete:
+			if err := s.deleteDocument(ctx, req.Repo, ingestDoc.Path); err != nil {
+				return nil, fmt.Errorf("failed to delete document %s: %w", ingestDoc.Path, err)
+			}
+
+			deleted++
+		default:
+			slog.WarnContext(ctx, "unknown document action", "action", ingestDoc.Action, "path", ingestDoc.Path)
+		}
+	}
+
 	if req.Sync {
-		syncDeleted, err := s.syncDeleteStale(ctx, req)
+		syncDeleted, syncMoved, err := s.syncDeleteStale(ctx, req)
 		if err != nil {
 			return nil, fmt.Errorf("failed to sync stale documents: %w", err)
 		}
 
 		deleted += syncDeleted
+		moved = syncMoved
 
 		// Only sync-delete stale assets when the request explicitly includes an
 		// assets field. A nil Assets pointer means the field was absent from the
@@ -171,21 +361,56 @@ func (s *Service) IngestDocuments(ctx context.Context, req *IngestRequest) (*Ing
 		}
 	}
 
+	s.recordChanges(ctx, req.Repo, before, upserted)
+	s.startAlerts(ctx, req.Repo, upserted)
+	s.activity.recordIngest(indexed)
+
 	return &IngestResponse{
 		Indexed:       indexed,
 		Deleted:       deleted,
+		Moved:         moved,
+		Lint:          issues,
+		Policy:        findings,
+		Duplicates:    s.ingestDuplicates(ctx, req.Repo, upserted),
+		Failed:        failed,
 		AssetsStored:  assetsStored,
 		AssetsDeleted: assetsDeleted,
 	}, nil
 }
 
+// validateContent checks every upserted document against the limits of its content
+// processor before any of them is stored, so that a rejected request changes nothing.
+// A document whose validation panics is left to the upsert, which reports it as failed.
+func (s *Service) validateContent(ctx context.Context, req *IngestRequest) error {
+	for _, doc := range req.Documents {
+		if doc.Action != actionUpsert {
+			continue
+		}
+
+		v, ok := s.getProcessor(doc.ContentType, req.Repo).(ContentValidator)
+		if !ok {
+			continue
+		}
+
+		err := recoverDocument(ctx, req.Repo, doc.Path, func() error { return v.Validate([]byte(doc.Content)) })
+		if err != nil && !errors.Is(err, errProcessingPanic) {
+			return fmt.Errorf("document %s: %w", doc.Path, err)
+		}
+	}
+
+	return nil
+}
+
 // syncDeleteStale removes stored documents that are not present in the ingest request.
-// It also cleans up orphaned entries in the search index that may have been left behind
-// by previous partial failures. It returns the total number of documents removed.
-func (s *Service) syncDeleteStale(ctx context.Context, req *IngestRequest) (int, error) {
+// A removed document whose content matches a document upserted by the same request is
+// treated as moved, and a redirect from its old path is recorded. It also cleans up
+// orphaned entries in the search index that may have been left behind by previous
+// partial failures. It returns the total number of documents removed and how many of
+// them were moves.
+func (s *Service) syncDeleteStale(ctx context.Context, req *IngestRequest) (deleted, moved int, err error) {
 	stored, err := s.store.List(ctx, req.Repo)
 	if err != nil {
-		return 0, fmt.Errorf("failed to list stored documents for repo %s: %w", req.Repo, err)
+		return 0, 0, fmt.Errorf("failed to list stored documents for repo %s: %w", req.Repo, err)
 	}
 
 	// Build a set of upserted document paths from the request.
@@ -198,17 +423,38 @@ func (s *Service) syncDeleteStale(ctx context.Context, req *IngestRequest) (int,
 		}
 	}
 
-	var deleted int
+	var stale []DocumentMeta
 
 	for _, doc := range stored {
-		if _, exists := requestPaths[doc.Path]; exists {
-			continue
+		if _, exists := requestPaths[doc.Path]; !exists {
+			stale = append(stale, doc)
 		}
+	}
+
+	moves := s.detectMoves(ctx, req.Repo, stale, req.Documents)
+	folded := foldPaths(requestPaths)
 
+	for _, doc := range stale {
 		slog.DebugContext(ctx, "sync: removing stale document", "repo", req.Repo, "path", doc.Path)
 
+		// A document renamed only in case shares its file with the stale path on a
+		// case-insensitive filesystem, so removing the stale path removes it too.
+		var renamed *Document
+
+		if newPath, ok := folded[strings.ToLower(doc.Path)]; ok {
+			if current, err := s.store.Get(ctx, req.Repo, newPath); err == nil {
+				renamed = &current
+			}
+		}
+
 		if err := s.deleteDocument(ctx, req.Repo, doc.Path); err != nil {
-			return deleted, fmt.Errorf("failed to delete stale document %s: %w", doc.Path, err)
+			return deleted, 0, fmt.Errorf("failed to delete stale document %s: %w", doc.Path, err)
+		}
+
+		if renamed != nil {
+			if err := s.restoreDocument(ctx, renamed); err != nil {
+				return deleted, 0, err
+			}
 		}
 
 		deleted++
@@ -218,6 +464,22 @@ func (s *Service) syncDeleteStale(ctx context.Context, req *IngestRequest) (int,
 		slog.InfoContext(ctx, "sync: stale document cleanup complete", "repo", req.Repo, "deleted", deleted)
 	}
 
+	if len(moves) > 0 {
+		redirects := make(map[string]string, len(moves))
+		for from, to := range moves {
+			redirects[req.Repo+"/"+from] = req.Repo + "/" + to
+		}
+
+		// The documents are already in place at their new paths, so a failure to
+		// record the redirects only loses the old URLs and is not fatal to the sync.
+		if err := s.addRedirects(ctx, redirects); err != nil {
+			slog.WarnContext(ctx, "sync: failed to record moved documents", "repo", req.Repo, "error", err)
+		} else {
+			moved = len(moves)
+			slog.InfoContext(ctx, "sync: detected moved documents", "repo", req.Repo, "moved", moved)
+		}
+	}
+
 	// Clean up orphaned entries in the search index. These can exist when a
 	// previous deletion removed a document from the docstore but failed to
 	// remove it from the search index.
@@ -225,10 +487,49 @@ func (s *Service) syncDeleteStale(ctx context.Context, req *IngestRequest) (int,
 	deleted += orphaned
 
 	if err != nil {
-		return deleted, err
+		return deleted, moved, err
 	}
 
-	return deleted, nil
+	return deleted, moved, nil
+}
+
+// foldPaths maps the case-folded form of each path to the path.
+func foldPaths(paths map[string]struct{}) map[string]string {
+	folded := make(map[string]string, len(paths))
+	for p := range paths {
+		folded[strings.ToLower(p)] = p
+	}
+
+	return folded
+}
+
+// restoreDocument saves doc again if it is no longer in the store, after the removal of
+// a stale path it shares a file with on a case-insensitive filesystem.
+func (s *Service) restoreDocument(ctx context.Context, doc *Document) error {
+	_, err := s.store.Get(ctx, doc.Repo, doc.Path)
+	if !errors.Is(err, ErrNotFound) {
+		return nil
+	}
+
+	if err := s.store.Save(ctx, *doc); err != nil {
+		return fmt.Errorf("failed to restore document %s: %w", doc.Path, err)
+	}
+
+	return nil
+}
+
+// restoreAsset is the counterpart of restoreDocument for assets.
+func (s *Service) restoreAsset(ctx context.Context, repo, path string, data []byte) error {
+	_, err := s.store.GetAsset(ctx, repo, path)
+	if !errors.Is(err, ErrNotFound) {
+		return nil
+	}
+
+	if err := s.store.SaveAsset(ctx, repo, path, data); err != nil {
+		return fmt.Errorf("failed to restore asset %s: %w", path, err)
+	}
+
+	return nil
 }
 
 // cleanOrphanedSearchEntries removes search index entries for the given repo
@@ -257,6 +558,8 @@ func (s *Service) cleanOrphanedSearchEntries(ctx context.Context, repo string, v
 			return cleaned, fmt.Errorf("failed to remove orphaned search entry %s: %w", docID, err)
 		}
 
+		s.removeEmbedding(ctx, docID)
+
 		cleaned++
 	}
 
@@ -276,9 +579,21 @@ func (s *Service) GetDocument(ctx context.Context, repo, path string) (Document,
 		return Document{}, nil, nil, fmt.Errorf("failed to get document: %w", err)
 	}
 
-	processor := s.getProcessor(doc.ContentType)
+	doc = s.redactDocument(repo, doc)
+
+	processor := s.getProcessor(doc.ContentType, repo)
+
+	var (
+		html     []byte
+		headings []Heading
+	)
+
+	start := time.Now()
 
-	html, headings, err := processor.RenderHTML([]byte(doc.Content))
+	err = recoverDocument(ctx, repo, path, func() (err error) {
+		html, headings, err = renderHTML(ctx, processor, []byte(doc.Content))
+		return err
+	})
 	if err != nil {
 		return Document{}, nil, nil, fmt.Errorf("failed to render document: %w", err)
 	}
@@ -286,6 +601,9 @@ func (s *Service) GetDocument(ctx context.Context, repo, path string) (Document,
 	// Rewrite relative image URLs so the browser can resolve them through
 	// the /assets/{owner}/{repo}/{path} route.
 	html = RewriteImageURLs(html, repo, path)
+	html = addResponsiveImages(repo, html, doc.Images)
+
+	s.recordViewRender(ctx, &doc, time.Since(start), len(html))
 
 	return doc, html, headings, nil
 }
@@ -300,18 +618,155 @@ func (s *Service) GetAsset(ctx context.Context, repo, path string) ([]byte, erro
 	return data, nil
 }
 
+// GetDocumentSource retrieves a document with its raw, unrendered content.
+func (s *Service) GetDocumentSource(ctx context.Context, repo, path string) (Document, error) {
+	doc, err := s.store.Get(ctx, repo, path)
+	if err != nil {
+		return Document{}, fmt.Errorf("failed to get document: %w", err)
+	}
+
+	return s.redactDocument(repo, doc), nil
+}
+
+// GetDocumentText retrieves a document with its content converted to plain text by the
+// processor for its content type, the same text that is indexed for search.
+func (s *Service) GetDocumentText(ctx context.Context, repo, path string) (Document, string, error) {
+	doc, err := s.GetDocumentSource(ctx, repo, path)
+	if err != nil {
+		return Document{}, "", err
+	}
+
+	return doc, documentPlainText(&doc, s.getProcessor(doc.ContentType, repo)), nil
+}
+
+// documentPlainText returns the plain text of a stored document, reusing the text
+// extracted at ingest time while its content and processor are unchanged.
+func documentPlainText(doc *Document, processor ContentProcessor) string {
+	if text := doc.cachedText(processor); text != nil {
+		return text.PlainText
+	}
+
+	return processor.ToPlainText([]byte(doc.Content))
+}
+
+// documentHeadings returns the headings of a stored document, reusing the headings
+// extracted at ingest time while its content and processor are unchanged.
+func documentHeadings(doc *Document, processor ContentProcessor) []Heading {
+	if text := doc.cachedText(processor); text != nil {
+		return text.Headings
+	}
+
+	return processor.ExtractHeadings([]byte(doc.Content))
+}
+
+// storedAnchors returns the anchor map recorded with a stored document at ingest time, or
+// nil when it has none or the text it was mapped from is no longer current.
+func storedAnchors(doc *Document, processor ContentProcessor) AnchorMap {
+	if doc.cachedText(processor) == nil {
+		return nil
+	}
+
+	return doc.Anchors
+}
+
+// currentAnchors returns the anchor map of a stored document whose plain text and
+// headings are given: the map recorded at ingest time while it is current, or the map of
+// the text otherwise.
+func currentAnchors(doc *Document, processor ContentProcessor, plainText string, headings []Heading) AnchorMap {
+	if anchors := storedAnchors(doc, processor); anchors != nil {
+		return anchors
+	}
+
+	return mapAnchors(processor, []byte(doc.Content), plainText, headings)
+}
+
 // SearchDocs performs a full-text search across all indexed documents.
 // After retrieving results from the search engine it attempts to resolve a
 // heading anchor for each hit so that the result link can scroll directly to
 // the matching section. Anchor resolution is best-effort; failures are logged
 // and do not prevent results from being returned.
+// A "lang:<name>" token in the query restricts results to documents with code
+// blocks in that language, as if opts.Lang had been set.
+// When the first page of results is empty and the search engine implements
+// SpellChecker, a corrected query that finds documents is offered in
+// SearchResults.Suggestion.
+// Each call is counted in the daily searches reported by Stats.
+// While a ranking experiment runs, searches made in a session are ranked with the
+// session's variant, which is reported in SearchResults.Variant.
+// Identical searches made within searchCacheTTL are answered from a cache, which
+// changes of the search index, such as ingests, invalidate.
 func (s *Service) SearchDocs(ctx context.Context, query string, opts SearchOpts) (*SearchResults, error) {
-	results, err := s.search.Search(ctx, query, opts)
+	s.activity.recordSearch(time.Now())
+
+	ranking := s.hybrid
+
+	variant := s.experiment.assign(opts.Session)
+	if variant != nil {
+		if opts.Mode == "" {
+			opts.Mode = variant.Mode
+		}
+
+		if variant.Hybrid != (HybridConfig{}) {
+			ranking = variant.Hybrid
+		}
+	}
+
+	results, err := s.searchDocs(ctx, query, opts, ranking)
+	if err != nil || variant == nil {
+		return results, err
+	}
+
+	s.experiment.recordSearch(variant.Name)
+	results.Variant = variant.Name
+
+	return results, nil
+}
+
+// searchDocs runs a search as SearchDocs does, without counting it, merging the rankings
+// of hybrid searches as configured by ranking. The results of the same search are reused
+// for searchCacheTTL, until the search index changes.
+func (s *Service) searchDocs(ctx context.Context, query string, opts SearchOpts, ranking HybridConfig) (*SearchResults, error) {
+	now := time.Now()
+	key := newSearchKey(query, opts, ranking)
+
+	if results, ok := s.searches.get(key, now); ok {
+		return results, nil
+	}
+
+	generation := s.searches.current()
+
+	query, lang := ParseLangFilter(query)
+	if lang != "" {
+		opts.Lang = lang
+	}
+
+	var (
+		results *SearchResults
+		err     error
+	)
+
+	switch opts.EffectiveMode(s.semantic != nil) {
+	case SearchModeSemantic:
+		results, err = s.searchSemantic(ctx, query, opts)
+	case SearchModeHybrid:
+		results, err = s.searchHybrid(ctx, query, opts, ranking)
+	default:
+		results, err = s.search.Search(ctx, query, opts)
+	}
+
 	if err != nil {
 		return nil, fmt.Errorf("search failed: %w", err)
 	}
 
+	if results.Total == 0 && opts.Offset <= 0 {
+		results.Suggestion = s.suggestCorrection(ctx, query, opts)
+	}
+
+	results.paginate(opts)
 	s.resolveAnchors(ctx, results)
+	s.fillSummaries(ctx, results)
+
+	s.searches.put(key, results, generation, now)
 
 	return results, nil
 }
@@ -336,7 +791,9 @@ func (s *Service) ListDocuments(ctx context.Context, repo string) ([]DocumentMet
 	return docs, nil
 }
 
-func (s *Service) upsertDocument(ctx context.Context, repo, commitSHA string, ingestDoc IngestDocument) error {
+func (s *Service) upsertDocument(ctx context.Context, req *IngestRequest, ingestDoc IngestDocument) error {
+	repo := req.Repo
+
 	ct := ingestDoc.ContentType
 	if ct == "" {
 		ct = ContentTypeMarkdown
@@ -349,39 +806,65 @@ func (s *Service) upsertDocument(ctx context.Context, repo, commitSHA string, in
 		ct = ContentTypeMarkdown
 	}
 
-	processor := s.getProcessor(ct)
+	processor := s.getProcessor(ct, repo)
 
 	title := processor.ExtractTitle([]byte(ingestDoc.Content))
 	if title == "" {
 		title = ingestDoc.Path
 	}
 
+	plainText := processor.ToPlainText([]byte(ingestDoc.Content))
+	headings := processor.ExtractHeadings([]byte(ingestDoc.Content))
+	meta := extractMetadata(processor, ingestDoc.Content)
+
 	doc := Document{
-		ID:          repo + "/" + ingestDoc.Path,
+		ID:          NewDocumentID(repo, ingestDoc.Path).String(),
 		Repo:        repo,
 		Path:        ingestDoc.Path,
 		Title:       title,
 		Content:     ingestDoc.Content,
-		CommitSHA:   commitSHA,
+		CommitSHA:   req.CommitSHA,
+		Provenance:  req.Provenance,
 		UpdatedAt:   time.Now(),
 		ContentType: ct,
+		Home:        ct == ContentTypeMarkdown && isHomeDocument(ingestDoc.Content),
+		Tags:        meta.Tags,
+		Order:       meta.Order,
+		Draft:       meta.Draft,
+		Anchors:     mapAnchors(processor, []byte(ingestDoc.Content), plainText, headings),
+		Text:        newDocumentText(ingestDoc.Content, processor, plainText, headings),
+	}
+
+	if !s.binaryContent(ct) {
+		doc.Images = s.documentImages(ctx, repo, ingestDoc.Path, ingestDoc.Content)
+	}
+
+	// A description declared by the document is its summary.
+	if doc.Summary = truncateSummary(meta.Description); doc.Summary == "" {
+		doc.Summary = s.summarize(ctx, &doc, processor, plainText)
 	}
 
 	if err := s.store.Save(ctx, doc); err != nil {
 		return fmt.Errorf("failed to save document: %w", err)
 	}
 
-	plainText := processor.ToPlainText([]byte(ingestDoc.Content))
+	s.trackContent(&doc)
+	s.measureIngestRender(ctx, &doc, processor)
 
-	if err := s.search.Index(ctx, doc, plainText); err != nil {
+	code := processor.ExtractCodeBlocks([]byte(ingestDoc.Content))
+
+	if err := indexSearchable(ctx, s.search, &doc, plainText, code, headings); err != nil {
 		return fmt.Errorf("failed to index document: %w", err)
 	}
 
+	s.embedDocument(ctx, &doc, plainText, code)
+	s.publishEvent(EventDocumentUpdated, repo, doc.Path)
+
 	return nil
 }
 
 func (s *Service) deleteDocument(ctx context.Context, repo, path string) error {
-	docID := repo + "/" + path
+	docID := NewDocumentID(repo, path).String()
 
 	// Remove from search index first. If this fails the document remains in the
 	// docstore, so syncDeleteStale can discover and retry on the next sync run.
@@ -397,6 +880,11 @@ func (s *Service) deleteDocument(ctx context.Context, repo, path string) error {
 		return fmt.Errorf("failed to delete document: %w", err)
 	}
 
+	s.untrackContent(docID)
+	s.forgetRender(docID)
+	s.removeEmbedding(ctx, docID)
+	s.publishEvent(EventDocumentDeleted, repo, path)
+
 	return nil
 }
 
@@ -418,10 +906,13 @@ func (s *Service) reindexForCompensation(ctx context.Context, repo, path string,
 		return
 	}
 
-	processor := s.getProcessor(doc.ContentType)
-	plainText := processor.ToPlainText([]byte(doc.Content))
+	processor := s.getProcessor(doc.ContentType, repo)
+	plainText := documentPlainText(&doc, processor)
+	code := processor.ExtractCodeBlocks([]byte(doc.Content))
+	headings := documentHeadings(&doc, processor)
+	doc.Anchors = currentAnchors(&doc, processor, plainText, headings)
 
-	if err := s.search.Index(ctx, doc, plainText); err != nil {
+	if err := indexSearchable(ctx, s.search, &doc, plainText, code, headings); err != nil {
 		slog.Warn("compensating re-index: failed to re-index document",
 			"repo", repo,
 			"path", path,
@@ -458,6 +949,10 @@ func (s *Service) upsertAsset(ctx context.Context, repo string, asset IngestAsse
 		return fmt.Errorf("failed to save asset: %w", err)
 	}
 
+	if isRasterImage(asset.Path) {
+		s.storeImageVariants(ctx, repo, asset.Path, data)
+	}
+
 	return nil
 }
 
@@ -480,18 +975,44 @@ func (s *Service) syncDeleteStaleAssets(ctx context.Context, req *IngestRequest)
 
 	var deleted int
 
+	folded := foldPaths(requestPaths)
+
 	for _, path := range stored {
-		if _, exists := requestPaths[path]; exists {
+		// Image variants are derived from their original asset: they are kept while the
+		// original is part of the request and removed, without being counted, otherwise.
+		origin, isVariant := variantOrigin(path)
+		if isVariant {
+			if _, exists := requestPaths[origin]; exists {
+				continue
+			}
+		} else if _, exists := requestPaths[path]; exists {
 			continue
 		}
 
 		slog.DebugContext(ctx, "sync: removing stale asset", "repo", req.Repo, "path", path)
 
+		// As with documents, an asset renamed only in case may share its file with the
+		// stale path.
+		var renamed []byte
+
+		newPath, caseRenamed := folded[strings.ToLower(path)]
+		if caseRenamed {
+			renamed, _ = s.store.GetAsset(ctx, req.Repo, newPath)
+		}
+
 		if err := s.store.DeleteAsset(ctx, req.Repo, path); err != nil {
 			return deleted, fmt.Errorf("failed to delete stale asset %s: %w", path, err)
 		}
 
-		deleted++
+		if renamed != nil {
+			if err := s.restoreAsset(ctx, req.Repo, newPath, renamed); err != nil {
+				return deleted, err
+			}
+		}
+
+		if !isVariant {
+			deleted++
+		}
 	}
 
 	if deleted > 0 {
@@ -514,12 +1035,15 @@ func stripMarkTags(fragment string) string {
 // resolveAnchors enriches each SearchResult with a heading Anchor so that
 // result links can deep-link directly to the matching section of a document.
 // It works by:
-//  1. Fetching the source document from the store.
-//  2. Extracting headings (anchor IDs + text) via the content processor.
-//  3. Converting the document to the same plain text that was indexed.
-//  4. Stripping <mark> tags from the first content fragment to get raw text.
-//  5. Finding that text in the plain text and determining which heading section
-//     it falls under.
+//  1. Taking the anchor map and plain text returned with the hit by the search
+//     engine, or else fetching the source document from the store, along with
+//     the anchor map and plain text recorded when it was ingested.
+//  2. Stripping <mark> tags from the first content fragment to get raw text.
+//  3. Finding that text in the plain text and looking up the section it falls
+//     under in the anchor map.
+//
+// Documents stored without an anchor map have their headings extracted and
+// located in the plain text instead.
 //
 // Resolution is best-effort: failures are logged and do not affect other hits.
 // Results with no content fragments (title-only matches) are skipped.
@@ -531,11 +1055,19 @@ func (s *Service) resolveAnchors(ctx context.Context, results *SearchResults) {
 	for i := range results.Hits {
 		hit := &results.Hits[i]
 
+		indexed, ok := indexedAnchor(hit)
+		hit.Anchors, hit.PlainText = nil, ""
+
 		if len(hit.ContentFragments) == 0 {
 			// Title-only match -- no content position to map; link to page top.
 			continue
 		}
 
+		if ok {
+			hit.Anchor = indexed
+			continue
+		}
+
 		anchor, err := s.resolveAnchor(ctx, hit)
 		if err != nil {
 			slog.DebugContext(ctx, "anchor resolution skipped",
@@ -550,6 +1082,21 @@ func (s *Service) resolveAnchors(ctx context.Context, results *SearchResults) {
 	}
 }
 
+// indexedAnchor resolves the heading anchor of a hit from the anchor map and plain text
+// returned with it by the search engine. It reports false when the engine returned none.
+func indexedAnchor(hit *SearchResult) (string, bool) {
+	if hit.Anchors == nil || hit.PlainText == "" || len(hit.ContentFragments) == 0 {
+		return "", false
+	}
+
+	fragIdx := fragmentMatchIndex(hit.ContentFragments[0], hit.PlainText)
+	if fragIdx < 0 {
+		return "", true
+	}
+
+	return hit.Anchors.At(fragIdx), true
+}
+
 // resolveAnchor resolves the heading anchor for a single SearchResult.
 // It returns the heading ID of the section that contains the first content
 // fragment, or an empty string when the match falls before the first heading.
@@ -559,15 +1106,23 @@ func (s *Service) resolveAnchor(ctx context.Context, hit *SearchResult) (string,
 		return "", fmt.Errorf("get document: %w", err)
 	}
 
-	processor := s.getProcessor(doc.ContentType)
+	processor := s.getProcessor(doc.ContentType, hit.Repo)
+	anchors := storedAnchors(&doc, processor)
 
-	headings := processor.ExtractHeadings([]byte(doc.Content))
-	if len(headings) == 0 {
+	var headings []Heading
+
+	if anchors == nil {
+		// Stored before anchor maps were recorded; locate the headings below.
+		headings = documentHeadings(&doc, processor)
+		if len(headings) == 0 {
+			return "", nil
+		}
+	} else if len(anchors) == 0 {
 		// No headings available; link to page top.
 		return "", nil
 	}
 
-	plainText := processor.ToPlainText([]byte(doc.Content))
+	plainText := documentPlainText(&doc, processor)
 
 	// Locate the matched term's byte offset in the plain text.
 	// fragmentMatchIndex handles Bleve's ellipsis padding and mid-word cuts.
@@ -576,7 +1131,11 @@ func (s *Service) resolveAnchor(ctx context.Context, hit *SearchResult) (string,
 		return "", nil
 	}
 
-	return findAnchorAtPosition(plainText, headings, fragIdx), nil
+	if anchors == nil {
+		return findAnchorAtPosition(plainText, headings, fragIdx), nil
+	}
+
+	return anchors.At(fragIdx), nil
 }
 
 // findHeadingLine returns the byte offset of the first occurrence of heading h
@@ -611,20 +1170,26 @@ func findHeadingLine(plainText, h string, fromByte int) int {
 	return -1
 }
 
-// findAnchorAtPosition returns the ID of the heading whose section contains
-// the character at fragIdx in plainText. It builds section boundaries by
-// locating each heading's text in document order as whole lines, then returns
-// the last boundary whose offset is ≤ fragIdx.
-//
-// Returns an empty string when fragIdx falls before the first heading or no
-// valid boundaries can be established.
-func findAnchorAtPosition(plainText string, headings []Heading, fragIdx int) string {
-	type sectionBoundary struct {
-		id     string
-		offset int
+// mapAnchors returns the anchor map of content src, whose plain text and headings have
+// already been extracted by processor. It is never nil, so that a document without
+// headings is told apart from one stored without an anchor map.
+func mapAnchors(processor ContentProcessor, src []byte, plainText string, headings []Heading) AnchorMap {
+	if mapper, ok := processor.(AnchorMapper); ok {
+		if anchors := mapper.MapAnchors(src); anchors != nil {
+			return anchors
+		}
+
+		return AnchorMap{}
 	}
 
-	boundaries := make([]sectionBoundary, 0, len(headings))
+	return findSectionBoundaries(plainText, headings)
+}
+
+// findSectionBoundaries locates each heading's text in plainText in document order as
+// whole lines. Headings that cannot be found are left out (can happen when heading text
+// contains characters stripped during plain-text conversion).
+func findSectionBoundaries(plainText string, headings []Heading) AnchorMap {
+	boundaries := make(AnchorMap, 0, len(headings))
 	searchFrom := 0
 
 	for _, h := range headings {
@@ -634,31 +1199,25 @@ func findAnchorAtPosition(plainText string, headings []Heading, fragIdx int) str
 
 		abs := findHeadingLine(plainText, h.Text, searchFrom)
 		if abs < 0 {
-			// Heading not found as a complete line (can happen when heading text
-			// contains characters stripped during plain-text conversion).
 			continue
 		}
 
-		boundaries = append(boundaries, sectionBoundary{offset: abs, id: h.ID})
+		boundaries = append(boundaries, Anchor{ID: h.ID, Text: h.Text, Offset: abs, Level: h.Level})
 		searchFrom = abs + len(h.Text)
 	}
 
-	if len(boundaries) == 0 {
-		return ""
-	}
-
-	// Find the last section boundary that starts at or before the fragment.
-	anchor := ""
-
-	for _, b := range boundaries {
-		if b.offset > fragIdx {
-			break
-		}
-
-		anchor = b.id
-	}
+	return boundaries
+}
 
-	return anchor
+// findAnchorAtPosition returns the ID of the heading whose section contains
+// the character at fragIdx in plainText. It builds section boundaries by
+// locating each heading's text in document order as whole lines, then returns
+// the last boundary whose offset is ≤ fragIdx.
+//
+// Returns an empty string when fragIdx falls before the first heading or no
+// valid boundaries can be established.
+func findAnchorAtPosition(plainText string, headings []Heading, fragIdx int) string {
+	return findSectionBoundaries(plainText, headings).At(fragIdx)
 }
 
 // bleveEllipsis is the Unicode ellipsis character (U+2026) that Bleve's
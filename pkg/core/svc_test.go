@@ -4,6 +4,7 @@ package core
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -12,8 +13,8 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-// newTestService creates a Service with fresh mocks for each test.
-func newTestService(t *testing.T) (*Service, *MockdocStore, *MocksearchEngine, *MockContentProcessor) {
+// newTestService creates a Service with fresh mocks for each test, configured with opts.
+func newTestService(t *testing.T, opts ...Option) (*Service, *MockdocStore, *MocksearchEngine, *MockContentProcessor) {
 	t.Helper()
 
 	store := NewMockdocStore(t)
@@ -21,7 +22,7 @@ func newTestService(t *testing.T) (*Service, *MockdocStore, *MocksearchEngine, *
 	processor := NewMockContentProcessor(t)
 	svc := New(store, search, map[ContentType]ContentProcessor{
 		ContentTypeMarkdown: processor,
-	})
+	}, opts...)
 
 	return svc, store, search, processor
 }
@@ -39,10 +40,75 @@ func newTestServiceOnly(t *testing.T) *Service {
 	})
 }
 
+// expectImageVariantsDeleted registers the DeleteAsset calls made when an image asset has
+// no resized variants (e.g. it cannot be decoded) or is deleted.
+func expectImageVariantsDeleted(store *MockdocStore, repo, path string) {
+	for _, w := range variantWidths {
+		store.EXPECT().DeleteAsset(mock.Anything, repo, variantPath(path, w)).Return(nil)
+	}
+
+	store.EXPECT().DeleteAsset(mock.Anything, repo, variantManifestPath(path)).Return(nil)
+}
+
+func TestIngestDocuments_Provenance(t *testing.T) {
+	svc, store, search, processor := newTestService(t)
+
+	prov := &Provenance{Workflow: "owner/repo/.github/workflows/docs.yml@refs/heads/main", Verified: true}
+
+	processor.EXPECT().ExtractTitle([]byte("# Doc")).Return("Doc")
+	processor.EXPECT().ToPlainText([]byte("# Doc")).Return("Doc")
+	processor.EXPECT().ExtractCodeBlocks([]byte("# Doc")).Return(nil)
+	processor.EXPECT().ExtractHeadings([]byte("# Doc")).Return(nil)
+	store.EXPECT().Save(mock.Anything, mock.MatchedBy(func(doc Document) bool {
+		return doc.Provenance == prov && doc.CommitSHA == "abc123"
+	})).Return(nil)
+	search.EXPECT().Index(mock.Anything, mock.Anything, "Doc", []CodeBlock(nil), []Heading(nil)).Return(nil)
+
+	_, err := svc.IngestDocuments(t.Context(), &IngestRequest{
+		Repo:       "owner/repo",
+		CommitSHA:  "abc123",
+		Provenance: prov,
+		Documents:  []IngestDocument{{Path: "doc.md", Content: "# Doc", Action: actionUpsert}},
+	})
+	require.NoError(t, err)
+}
+
+func TestIngestDocuments_StoresTextAndAnchors(t *testing.T) {
+	svc, store, search, processor := newTestService(t)
+
+	const content = "# Guide\nIntro\n## Install\nSteps"
+
+	headings := []Heading{{ID: "guide", Text: "Guide", Level: 1}, {ID: "install", Text: "Install", Level: 2}}
+
+	processor.EXPECT().ExtractTitle([]byte(content)).Return("Guide")
+	processor.EXPECT().ToPlainText([]byte(content)).Return("Guide\nIntro\nInstall\nSteps")
+	processor.EXPECT().ExtractCodeBlocks([]byte(content)).Return(nil)
+	processor.EXPECT().ExtractHeadings([]byte(content)).Return(headings)
+	store.EXPECT().Save(mock.Anything, mock.MatchedBy(func(doc Document) bool {
+		return assert.ObjectsAreEqual(AnchorMap{
+			{ID: "guide", Text: "Guide", Offset: 0, Level: 1},
+			{ID: "install", Text: "Install", Offset: 12, Level: 2},
+		}, doc.Anchors) && assert.ObjectsAreEqual(&DocumentText{
+			SHA256:    contentSHA256(content),
+			Processor: processorFingerprint(processor),
+			PlainText: "Guide\nIntro\nInstall\nSteps",
+			Headings:  headings,
+		}, doc.Text)
+	})).Return(nil)
+	search.EXPECT().Index(mock.Anything, mock.Anything, mock.Anything, []CodeBlock(nil), headings).Return(nil)
+
+	_, err := svc.IngestDocuments(t.Context(), &IngestRequest{
+		Repo:      "owner/repo",
+		Documents: []IngestDocument{{Path: "guide.md", Content: content, Action: actionUpsert}},
+	})
+	require.NoError(t, err)
+}
+
 func TestIngestDocuments_UpsertAsset(t *testing.T) {
 	svc, store, _, _ := newTestService(t)
 
 	store.EXPECT().SaveAsset(mock.Anything, "owner/repo", "images/arch.png", []byte("png-data")).Return(nil)
+	expectImageVariantsDeleted(store, "owner/repo", "images/arch.png")
 
 	req := IngestRequest{
 		Repo:      "owner/repo",
@@ -62,6 +128,7 @@ func TestIngestDocuments_DeleteAsset(t *testing.T) {
 	svc, store, _, _ := newTestService(t)
 
 	store.EXPECT().DeleteAsset(mock.Anything, "owner/repo", "images/old.png").Return(nil)
+	expectImageVariantsDeleted(store, "owner/repo", "images/old.png")
 
 	req := IngestRequest{
 		Repo:      "owner/repo",
@@ -102,8 +169,10 @@ func TestIngestDocuments_SyncDeletesStaleAssets(t *testing.T) {
 
 	renderer.EXPECT().ExtractTitle([]byte(content)).Return("Doc")
 	renderer.EXPECT().ToPlainText([]byte(content)).Return("Doc")
+	renderer.EXPECT().ExtractCodeBlocks([]byte(content)).Return(nil)
+	renderer.EXPECT().ExtractHeadings([]byte(content)).Return(nil)
 	store.EXPECT().Save(mock.Anything, mock.Anything).Return(nil)
-	search.EXPECT().Index(mock.Anything, mock.Anything, "Doc").Return(nil)
+	search.EXPECT().Index(mock.Anything, mock.Anything, "Doc", []CodeBlock(nil), []Heading(nil)).Return(nil)
 
 	// No stale documents.
 	store.EXPECT().List(mock.Anything, "owner/repo").Return([]DocumentMeta{
@@ -114,6 +183,7 @@ func TestIngestDocuments_SyncDeletesStaleAssets(t *testing.T) {
 	// Stale asset should be deleted.
 	store.EXPECT().ListAssets(mock.Anything, "owner/repo").Return([]string{"images/keep.png", "images/stale.png"}, nil)
 	store.EXPECT().SaveAsset(mock.Anything, "owner/repo", "images/keep.png", []byte("data")).Return(nil)
+	expectImageVariantsDeleted(store, "owner/repo", "images/keep.png")
 	store.EXPECT().DeleteAsset(mock.Anything, "owner/repo", "images/stale.png").Return(nil)
 
 	req := IngestRequest{
@@ -145,8 +215,10 @@ func TestIngestDocuments_SyncWithNoAssetsDoesNotDeleteStoredAssets(t *testing.T)
 
 	renderer.EXPECT().ExtractTitle([]byte(content)).Return("Doc")
 	renderer.EXPECT().ToPlainText([]byte(content)).Return("Doc")
+	renderer.EXPECT().ExtractCodeBlocks([]byte(content)).Return(nil)
+	renderer.EXPECT().ExtractHeadings([]byte(content)).Return(nil)
 	store.EXPECT().Save(mock.Anything, mock.Anything).Return(nil)
-	search.EXPECT().Index(mock.Anything, mock.Anything, "Doc").Return(nil)
+	search.EXPECT().Index(mock.Anything, mock.Anything, "Doc", []CodeBlock(nil), []Heading(nil)).Return(nil)
 
 	store.EXPECT().List(mock.Anything, "owner/repo").Return([]DocumentMeta{
 		{ID: "owner/repo/doc.md", Repo: "owner/repo", Path: "doc.md"},
@@ -180,8 +252,10 @@ func TestIngestDocuments_SyncWithEmptyAssetsDeletesAllStoredAssets(t *testing.T)
 
 	renderer.EXPECT().ExtractTitle([]byte(content)).Return("Doc")
 	renderer.EXPECT().ToPlainText([]byte(content)).Return("Doc")
+	renderer.EXPECT().ExtractCodeBlocks([]byte(content)).Return(nil)
+	renderer.EXPECT().ExtractHeadings([]byte(content)).Return(nil)
 	store.EXPECT().Save(mock.Anything, mock.Anything).Return(nil)
-	search.EXPECT().Index(mock.Anything, mock.Anything, "Doc").Return(nil)
+	search.EXPECT().Index(mock.Anything, mock.Anything, "Doc", []CodeBlock(nil), []Heading(nil)).Return(nil)
 
 	store.EXPECT().List(mock.Anything, "owner/repo").Return([]DocumentMeta{
 		{ID: "owner/repo/doc.md", Repo: "owner/repo", Path: "doc.md"},
@@ -226,6 +300,39 @@ func TestIngestDocuments_UpsertAssetEmptyPath(t *testing.T) {
 	assert.ErrorContains(t, err, "path must not be empty")
 }
 
+// limitedProcessor is a content processor that refuses documents longer than max bytes.
+type limitedProcessor struct {
+	*MockContentProcessor
+	max int
+}
+
+func (p limitedProcessor) Validate(src []byte) error {
+	if len(src) > p.max {
+		return fmt.Errorf("%w: too long", ErrLimitExceeded)
+	}
+
+	return nil
+}
+
+func TestIngestDocuments_ContentLimitExceeded(t *testing.T) {
+	store := NewMockdocStore(t)
+	svc := New(store, NewMocksearchEngine(t), map[ContentType]ContentProcessor{
+		ContentTypeMarkdown: limitedProcessor{MockContentProcessor: NewMockContentProcessor(t), max: 10},
+	})
+
+	resp, err := svc.IngestDocuments(t.Context(), &IngestRequest{
+		Repo: "owner/repo",
+		Documents: []IngestDocument{
+			{Path: "small.md", Content: "# Small", Action: actionUpsert},
+			{Path: "big.md", Content: "# Much longer document", Action: actionUpsert},
+			{Path: "gone.md", Action: actionDelete},
+		},
+	})
+	require.ErrorIs(t, err, ErrLimitExceeded)
+	assert.ErrorContains(t, err, "document big.md")
+	assert.Nil(t, resp)
+}
+
 func TestIngestDocuments_UpsertAssetEmptyContent(t *testing.T) {
 	svc := newTestServiceOnly(t)
 
@@ -296,6 +403,8 @@ func TestIngestDocuments_UpsertVerifiesDocFields(t *testing.T) {
 
 	renderer.EXPECT().ExtractTitle([]byte(content)).Return("My Title")
 	renderer.EXPECT().ToPlainText([]byte(content)).Return("My Title Some body")
+	renderer.EXPECT().ExtractCodeBlocks([]byte(content)).Return(nil)
+	renderer.EXPECT().ExtractHeadings([]byte(content)).Return(nil)
 
 	store.EXPECT().Save(mock.Anything, mock.MatchedBy(func(doc Document) bool {
 		return doc.ID == "owner/repo/docs/readme.md" &&
@@ -307,7 +416,7 @@ func TestIngestDocuments_UpsertVerifiesDocFields(t *testing.T) {
 			!doc.UpdatedAt.IsZero()
 	})).Return(nil)
 
-	search.EXPECT().Index(mock.Anything, mock.Anything, "My Title Some body").Return(nil)
+	search.EXPECT().Index(mock.Anything, mock.Anything, "My Title Some body", []CodeBlock(nil), []Heading(nil)).Return(nil)
 
 	req := IngestRequest{
 		Repo:      "owner/repo",
@@ -330,12 +439,14 @@ func TestIngestDocuments_UpsertEmptyTitleFallsBackToPath(t *testing.T) {
 
 	renderer.EXPECT().ExtractTitle([]byte(content)).Return("")
 	renderer.EXPECT().ToPlainText([]byte(content)).Return("no heading here")
+	renderer.EXPECT().ExtractCodeBlocks([]byte(content)).Return(nil)
+	renderer.EXPECT().ExtractHeadings([]byte(content)).Return(nil)
 
 	store.EXPECT().Save(mock.Anything, mock.MatchedBy(func(doc Document) bool {
 		return doc.Title == "docs/untitled.md"
 	})).Return(nil)
 
-	search.EXPECT().Index(mock.Anything, mock.Anything, "no heading here").Return(nil)
+	search.EXPECT().Index(mock.Anything, mock.Anything, "no heading here", []CodeBlock(nil), []Heading(nil)).Return(nil)
 
 	req := IngestRequest{
 		Repo:      "owner/repo",
@@ -379,8 +490,10 @@ func TestIngestDocuments_MixedActions(t *testing.T) {
 
 	renderer.EXPECT().ExtractTitle([]byte(content)).Return("Doc")
 	renderer.EXPECT().ToPlainText([]byte(content)).Return("Doc")
+	renderer.EXPECT().ExtractCodeBlocks([]byte(content)).Return(nil)
+	renderer.EXPECT().ExtractHeadings([]byte(content)).Return(nil)
 	store.EXPECT().Save(mock.Anything, mock.Anything).Return(nil)
-	search.EXPECT().Index(mock.Anything, mock.Anything, "Doc").Return(nil)
+	search.EXPECT().Index(mock.Anything, mock.Anything, "Doc", []CodeBlock(nil), []Heading(nil)).Return(nil)
 
 	search.EXPECT().Remove(mock.Anything, "owner/repo/old.md").Return(nil)
 	store.EXPECT().Delete(mock.Anything, "owner/repo", "old.md").Return(nil)
@@ -444,6 +557,8 @@ func TestIngestDocuments_UpsertErrors(t *testing.T) {
 			name: "store save error propagates",
 			setupMocks: func(store *MockdocStore, _ *MocksearchEngine, renderer *MockContentProcessor) {
 				renderer.EXPECT().ExtractTitle(mock.Anything).Return("Title")
+				renderer.EXPECT().ToPlainText(mock.Anything).Return("plain")
+				renderer.EXPECT().ExtractHeadings(mock.Anything).Return(nil)
 				store.EXPECT().Save(mock.Anything, mock.Anything).Return(errors.New("db connection lost"))
 			},
 			wantErrMsg: "db connection lost",
@@ -453,8 +568,10 @@ func TestIngestDocuments_UpsertErrors(t *testing.T) {
 			setupMocks: func(store *MockdocStore, search *MocksearchEngine, renderer *MockContentProcessor) {
 				renderer.EXPECT().ExtractTitle(mock.Anything).Return("Title")
 				renderer.EXPECT().ToPlainText(mock.Anything).Return("plain")
+				renderer.EXPECT().ExtractCodeBlocks(mock.Anything).Return(nil)
+				renderer.EXPECT().ExtractHeadings(mock.Anything).Return(nil)
 				store.EXPECT().Save(mock.Anything, mock.Anything).Return(nil)
-				search.EXPECT().Index(mock.Anything, mock.Anything, "plain").Return(errors.New("index unavailable"))
+				search.EXPECT().Index(mock.Anything, mock.Anything, "plain", []CodeBlock(nil), []Heading(nil)).Return(errors.New("index unavailable"))
 			},
 			wantErrMsg: "index unavailable",
 		},
@@ -506,7 +623,9 @@ func TestIngestDocuments_DeleteErrors(t *testing.T) {
 					Content: "# Gone", Title: "Gone",
 				}, nil)
 				renderer.EXPECT().ToPlainText([]byte("# Gone")).Return("Gone")
-				search.EXPECT().Index(mock.Anything, mock.Anything, "Gone").Return(nil)
+				renderer.EXPECT().ExtractCodeBlocks([]byte("# Gone")).Return(nil)
+				renderer.EXPECT().ExtractHeadings([]byte("# Gone")).Return(nil)
+				search.EXPECT().Index(mock.Anything, mock.Anything, "Gone", []CodeBlock(nil), []Heading(nil)).Return(nil)
 			},
 			wantErrMsg: "delete failed",
 		},
@@ -543,8 +662,10 @@ func TestIngestDocuments_SyncDeletesStaleDocuments(t *testing.T) {
 	// Mock the upsert for the document in the request.
 	renderer.EXPECT().ExtractTitle([]byte(content)).Return("Keep")
 	renderer.EXPECT().ToPlainText([]byte(content)).Return("Keep")
+	renderer.EXPECT().ExtractCodeBlocks([]byte(content)).Return(nil)
+	renderer.EXPECT().ExtractHeadings([]byte(content)).Return(nil)
 	store.EXPECT().Save(mock.Anything, mock.Anything).Return(nil)
-	search.EXPECT().Index(mock.Anything, mock.Anything, "Keep").Return(nil)
+	search.EXPECT().Index(mock.Anything, mock.Anything, "Keep", []CodeBlock(nil), []Heading(nil)).Return(nil)
 
 	// Mock store.List returning both the kept doc and a stale doc.
 	now := time.Now()
@@ -553,6 +674,9 @@ func TestIngestDocuments_SyncDeletesStaleDocuments(t *testing.T) {
 		{ID: "owner/repo/stale.md", Repo: "owner/repo", Path: "stale.md", Title: "Stale", UpdatedAt: now},
 	}, nil)
 
+	// The stale document's content differs from the upserted one, so it is not a move.
+	store.EXPECT().Get(mock.Anything, "owner/repo", "stale.md").Return(Document{Content: "# Stale"}, nil)
+
 	// Mock deletion of the stale document (search first, then store).
 	search.EXPECT().Remove(mock.Anything, "owner/repo/stale.md").Return(nil)
 	store.EXPECT().Delete(mock.Anything, "owner/repo", "stale.md").Return(nil)
@@ -585,8 +709,10 @@ func TestIngestDocuments_SyncNoStaleDocuments(t *testing.T) {
 
 	renderer.EXPECT().ExtractTitle([]byte(content)).Return("Doc")
 	renderer.EXPECT().ToPlainText([]byte(content)).Return("Doc")
+	renderer.EXPECT().ExtractCodeBlocks([]byte(content)).Return(nil)
+	renderer.EXPECT().ExtractHeadings([]byte(content)).Return(nil)
 	store.EXPECT().Save(mock.Anything, mock.Anything).Return(nil)
-	search.EXPECT().Index(mock.Anything, mock.Anything, "Doc").Return(nil)
+	search.EXPECT().Index(mock.Anything, mock.Anything, "Doc", []CodeBlock(nil), []Heading(nil)).Return(nil)
 
 	// All stored documents match the request — nothing to delete.
 	now := time.Now()
@@ -622,8 +748,10 @@ func TestIngestDocuments_SyncDisabledDoesNotDelete(t *testing.T) {
 
 	renderer.EXPECT().ExtractTitle([]byte(content)).Return("Doc")
 	renderer.EXPECT().ToPlainText([]byte(content)).Return("Doc")
+	renderer.EXPECT().ExtractCodeBlocks([]byte(content)).Return(nil)
+	renderer.EXPECT().ExtractHeadings([]byte(content)).Return(nil)
 	store.EXPECT().Save(mock.Anything, mock.Anything).Return(nil)
-	search.EXPECT().Index(mock.Anything, mock.Anything, "Doc").Return(nil)
+	search.EXPECT().Index(mock.Anything, mock.Anything, "Doc", []CodeBlock(nil), []Heading(nil)).Return(nil)
 
 	// store.List should NOT be called when sync is disabled.
 
@@ -681,7 +809,9 @@ func TestIngestDocuments_SyncErrors(t *testing.T) {
 					Content: "# Stale", Title: "Stale",
 				}, nil)
 				renderer.EXPECT().ToPlainText([]byte("# Stale")).Return("Stale")
-				search.EXPECT().Index(mock.Anything, mock.Anything, "Stale").Return(nil)
+				renderer.EXPECT().ExtractCodeBlocks([]byte("# Stale")).Return(nil)
+				renderer.EXPECT().ExtractHeadings([]byte("# Stale")).Return(nil)
+				search.EXPECT().Index(mock.Anything, mock.Anything, "Stale", []CodeBlock(nil), []Heading(nil)).Return(nil)
 			},
 			wantErrMsg: "delete failed",
 		},
@@ -760,8 +890,10 @@ func TestIngestDocuments_SyncOrphanCleanupSkipsValidDocs(t *testing.T) {
 
 	renderer.EXPECT().ExtractTitle([]byte(content)).Return("Keep")
 	renderer.EXPECT().ToPlainText([]byte(content)).Return("Keep")
+	renderer.EXPECT().ExtractCodeBlocks([]byte(content)).Return(nil)
+	renderer.EXPECT().ExtractHeadings([]byte(content)).Return(nil)
 	store.EXPECT().Save(mock.Anything, mock.Anything).Return(nil)
-	search.EXPECT().Index(mock.Anything, mock.Anything, "Keep").Return(nil)
+	search.EXPECT().Index(mock.Anything, mock.Anything, "Keep", []CodeBlock(nil), []Heading(nil)).Return(nil)
 
 	now := time.Now()
 	store.EXPECT().List(mock.Anything, "owner/repo").Return([]DocumentMeta{
@@ -830,7 +962,22 @@ func TestDeleteDocument_CompensatingReindexOnStoreFailure(t *testing.T) {
 					Content: "# Doc", Title: "Doc",
 				}, nil)
 				renderer.EXPECT().ToPlainText([]byte("# Doc")).Return("Doc")
-				search.EXPECT().Index(mock.Anything, mock.Anything, "Doc").Return(nil)
+				renderer.EXPECT().ExtractCodeBlocks([]byte("# Doc")).Return(nil)
+				renderer.EXPECT().ExtractHeadings([]byte("# Doc")).Return(nil)
+				search.EXPECT().Index(mock.Anything, mock.Anything, "Doc", []CodeBlock(nil), []Heading(nil)).Return(nil)
+			},
+		},
+		{
+			name: "compensating re-index with stored text",
+			setupMocks: func(store *MockdocStore, search *MocksearchEngine, renderer *MockContentProcessor) {
+				search.EXPECT().Remove(mock.Anything, "owner/repo/docs/doc.md").Return(nil)
+				store.EXPECT().Delete(mock.Anything, "owner/repo", "docs/doc.md").Return(errors.New("disk full"))
+				store.EXPECT().Get(mock.Anything, "owner/repo", "docs/doc.md").Return(Document{
+					ID: "owner/repo/docs/doc.md", Repo: "owner/repo", Path: "docs/doc.md",
+					Content: "# Doc", Title: "Doc", Text: newDocumentText("# Doc", renderer, "Doc", nil),
+				}, nil)
+				renderer.EXPECT().ExtractCodeBlocks([]byte("# Doc")).Return(nil)
+				search.EXPECT().Index(mock.Anything, mock.Anything, "Doc", []CodeBlock(nil), []Heading(nil)).Return(nil)
 			},
 		},
 		{
@@ -851,7 +998,9 @@ func TestDeleteDocument_CompensatingReindexOnStoreFailure(t *testing.T) {
 					Content: "# Doc", Title: "Doc",
 				}, nil)
 				renderer.EXPECT().ToPlainText([]byte("# Doc")).Return("Doc")
-				search.EXPECT().Index(mock.Anything, mock.Anything, "Doc").Return(errors.New("index broken"))
+				renderer.EXPECT().ExtractCodeBlocks([]byte("# Doc")).Return(nil)
+				renderer.EXPECT().ExtractHeadings([]byte("# Doc")).Return(nil)
+				search.EXPECT().Index(mock.Anything, mock.Anything, "Doc", []CodeBlock(nil), []Heading(nil)).Return(errors.New("index broken"))
 			},
 		},
 	}
@@ -902,7 +1051,7 @@ func TestSyncDeleteStale_PartialOrphanCleanupPreservesCount(t *testing.T) {
 		Documents: nil,
 	}
 
-	deleted, err := svc.syncDeleteStale(ctx, &req)
+	deleted, _, err := svc.syncDeleteStale(ctx, &req)
 	require.Error(t, err)
 	assert.ErrorContains(t, err, "remove failed")
 	// The one successful orphan removal must be reflected in the count.
@@ -913,12 +1062,12 @@ func TestGetDocument(t *testing.T) {
 	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
 
 	tests := []struct {
-		wantDoc      Document
 		setupMocks   func(*MockdocStore, *MockContentProcessor)
 		name         string
 		wantErr      string
 		wantHTML     []byte
 		wantHeadings []Heading
+		wantDoc      Document
 	}{
 		{
 			name: "success",
@@ -1006,6 +1155,40 @@ func TestGetDocument(t *testing.T) {
 	}
 }
 
+func TestGetDocumentSource(t *testing.T) {
+	svc, store, _, _ := newTestService(t)
+
+	doc := Document{Repo: "owner/repo", Path: "docs/guide.md", Content: "# Guide"}
+
+	store.EXPECT().Get(mock.Anything, "owner/repo", "docs/guide.md").Return(doc, nil)
+	store.EXPECT().Get(mock.Anything, "owner/repo", "missing.md").Return(Document{}, ErrNotFound)
+
+	got, err := svc.GetDocumentSource(t.Context(), "owner/repo", "docs/guide.md")
+	require.NoError(t, err)
+	assert.Equal(t, doc, got)
+
+	_, err = svc.GetDocumentSource(t.Context(), "owner/repo", "missing.md")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestGetDocumentText(t *testing.T) {
+	svc, store, _, renderer := newTestService(t)
+
+	doc := Document{Repo: "owner/repo", Path: "docs/guide.md", Content: "# Guide\n\nSome *text*."}
+
+	store.EXPECT().Get(mock.Anything, "owner/repo", "docs/guide.md").Return(doc, nil)
+	store.EXPECT().Get(mock.Anything, "owner/repo", "missing.md").Return(Document{}, ErrNotFound)
+	renderer.EXPECT().ToPlainText([]byte(doc.Content)).Return("Guide\n\nSome text.")
+
+	got, text, err := svc.GetDocumentText(t.Context(), "owner/repo", "docs/guide.md")
+	require.NoError(t, err)
+	assert.Equal(t, doc, got)
+	assert.Equal(t, "Guide\n\nSome text.", text)
+
+	_, _, err = svc.GetDocumentText(t.Context(), "owner/repo", "missing.md")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
 func TestNew_PanicsOnNilProcessors(t *testing.T) {
 	store := NewMockdocStore(t)
 	search := NewMocksearchEngine(t)
@@ -1032,13 +1215,15 @@ func TestIngestDocuments_UnknownContentTypeNormalizesToMarkdown(t *testing.T) {
 
 	renderer.EXPECT().ExtractTitle([]byte(content)).Return("Hello")
 	renderer.EXPECT().ToPlainText([]byte(content)).Return("Hello")
+	renderer.EXPECT().ExtractCodeBlocks([]byte(content)).Return(nil)
+	renderer.EXPECT().ExtractHeadings([]byte(content)).Return(nil)
 
 	store.EXPECT().Save(mock.Anything, mock.MatchedBy(func(doc Document) bool {
 		// The unknown content type should be normalized to markdown before persisting.
 		return doc.ContentType == ContentTypeMarkdown
 	})).Return(nil)
 
-	search.EXPECT().Index(mock.Anything, mock.Anything, "Hello").Return(nil)
+	search.EXPECT().Index(mock.Anything, mock.Anything, "Hello", []CodeBlock(nil), []Heading(nil)).Return(nil)
 
 	req := IngestRequest{
 		Repo:      "owner/repo",
@@ -1066,7 +1251,7 @@ func TestSearchDocs(t *testing.T) {
 			name:  "title-only match skips anchor resolution",
 			query: "hello",
 			opts:  SearchOpts{Limit: 10, Offset: 0},
-			setupMocks: func(_ *MockdocStore, search *MocksearchEngine, _ *MockContentProcessor) {
+			setupMocks: func(store *MockdocStore, search *MocksearchEngine, _ *MockContentProcessor) {
 				results := &SearchResults{
 					Hits: []SearchResult{
 						{
@@ -1082,6 +1267,8 @@ func TestSearchDocs(t *testing.T) {
 					Duration: 5 * time.Millisecond,
 				}
 				search.EXPECT().Search(mock.Anything, "hello", SearchOpts{Limit: 10, Offset: 0}).Return(results, nil)
+				// The document is read for its summary only; it has none.
+				store.EXPECT().Get(mock.Anything, "owner/repo", "docs/hello.md").Return(Document{}, nil)
 			},
 			wantResults: &SearchResults{
 				Hits: []SearchResult{
@@ -1096,6 +1283,51 @@ func TestSearchDocs(t *testing.T) {
 				},
 				Total:    1,
 				Duration: 5 * time.Millisecond,
+				Page:     1,
+			},
+		},
+		{
+			name:  "content match resolves anchor from the index",
+			query: "world",
+			opts:  SearchOpts{Limit: 10, Offset: 0},
+			setupMocks: func(_ *MockdocStore, search *MocksearchEngine, _ *MockContentProcessor) {
+				results := &SearchResults{
+					Hits: []SearchResult{
+						{
+							ID:               "owner/repo/docs/hello.md",
+							Repo:             "owner/repo",
+							Path:             "docs/hello.md",
+							Title:            "Hello",
+							ContentFragments: []string{"<mark>world</mark> content"},
+							Anchors: AnchorMap{
+								{ID: "hello", Text: "Hello", Offset: 0, Level: 1},
+								{ID: "details", Text: "Details", Offset: 17, Level: 2},
+							},
+							PlainText: "Hello\nIntro text\nDetails\nworld content here",
+							Score:     1.5,
+						},
+					},
+					Total:    1,
+					Duration: 5 * time.Millisecond,
+				}
+				// The document is not loaded: the engine returned its anchor map.
+				search.EXPECT().Search(mock.Anything, "world", SearchOpts{Limit: 10, Offset: 0}).Return(results, nil)
+			},
+			wantResults: &SearchResults{
+				Hits: []SearchResult{
+					{
+						ID:               "owner/repo/docs/hello.md",
+						Repo:             "owner/repo",
+						Path:             "docs/hello.md",
+						Title:            "Hello",
+						Anchor:           "details",
+						ContentFragments: []string{"<mark>world</mark> content"},
+						Score:            1.5,
+					},
+				},
+				Total:    1,
+				Duration: 5 * time.Millisecond,
+				Page:     1,
 			},
 		},
 		{
@@ -1147,7 +1379,18 @@ func TestSearchDocs(t *testing.T) {
 				},
 				Total:    1,
 				Duration: 5 * time.Millisecond,
+				Page:     1,
+			},
+		},
+		{
+			name:  "lang filter is extracted from the query",
+			query: "handler lang:Go",
+			opts:  SearchOpts{Limit: 10, CodeOnly: true},
+			setupMocks: func(_ *MockdocStore, search *MocksearchEngine, _ *MockContentProcessor) {
+				search.EXPECT().Search(mock.Anything, "handler", SearchOpts{Limit: 10, Lang: "go", CodeOnly: true}).
+					Return(&SearchResults{Langs: []FacetCount{{Value: "go", Count: 1}}}, nil)
 			},
+			wantResults: &SearchResults{Langs: []FacetCount{{Value: "go", Count: 1}}, Page: 1},
 		},
 		{
 			name:  "anchor resolution skipped when store.Get fails",
@@ -1184,6 +1427,25 @@ func TestSearchDocs(t *testing.T) {
 				},
 				Total:    1,
 				Duration: 5 * time.Millisecond,
+				Page:     1,
+			},
+		},
+		{
+			name:  "page metadata follows the offset",
+			query: "guide",
+			opts:  SearchOpts{Limit: 1, Offset: 1},
+			setupMocks: func(store *MockdocStore, search *MocksearchEngine, _ *MockContentProcessor) {
+				search.EXPECT().Search(mock.Anything, "guide", SearchOpts{Limit: 1, Offset: 1}).Return(&SearchResults{
+					Hits:  []SearchResult{{ID: "owner/repo/guide.md", Repo: "owner/repo", Path: "guide.md", Title: "Guide"}},
+					Total: 3,
+				}, nil)
+				store.EXPECT().Get(mock.Anything, "owner/repo", "guide.md").Return(Document{}, nil)
+			},
+			wantResults: &SearchResults{
+				Hits:    []SearchResult{{ID: "owner/repo/guide.md", Repo: "owner/repo", Path: "guide.md", Title: "Guide"}},
+				Total:   3,
+				Page:    2,
+				HasMore: true,
 			},
 		},
 		{
@@ -1376,6 +1638,76 @@ func TestResolveAnchor_NoHeadings(t *testing.T) {
 	assert.Equal(t, "", anchor)
 }
 
+func TestResolveAnchor_StoredAnchorsOfOtherProcessor(t *testing.T) {
+	svc, store, _, renderer := newTestService(t)
+
+	const plainText = "Intro\nhello\nUsage\nrun the tool"
+
+	doc := Document{
+		ID:      "owner/repo/doc.md",
+		Repo:    "owner/repo",
+		Path:    "doc.md",
+		Content: "# Intro\n\nhello\n\n## Usage\n\nrun the tool",
+		Anchors: AnchorMap{
+			{ID: "old-intro", Text: "Intro", Offset: 0, Level: 1},
+			{ID: "old-usage", Text: "Usage", Offset: 12, Level: 2},
+		},
+	}
+	doc.Text = newDocumentText(doc.Content, renderer, plainText, nil)
+	doc.Text.Processor = "*markdown.Renderer:heading-ids-changed"
+
+	// The text and anchors were extracted by a processor configured differently, so the
+	// headings are extracted again.
+	store.EXPECT().Get(mock.Anything, "owner/repo", "doc.md").Return(doc, nil)
+	renderer.EXPECT().ToPlainText([]byte(doc.Content)).Return(plainText)
+	renderer.EXPECT().ExtractHeadings([]byte(doc.Content)).Return([]Heading{
+		{ID: "intro", Text: "Intro", Level: 1},
+		{ID: "usage", Text: "Usage", Level: 2},
+	})
+
+	hit := &SearchResult{Repo: "owner/repo", Path: "doc.md", ContentFragments: []string{"<mark>run</mark> the tool"}}
+
+	anchor, err := svc.resolveAnchor(t.Context(), hit)
+	require.NoError(t, err)
+	assert.Equal(t, "usage", anchor)
+}
+
+func TestResolveAnchor_StoredText(t *testing.T) {
+	svc, store, _, processor := newTestService(t)
+
+	doc := Document{
+		Repo:    "owner/repo",
+		Path:    "doc.md",
+		Content: "# Intro\n\nhello\n\n## Usage\n\nrun the tool",
+		Anchors: AnchorMap{{ID: "usage", Text: "Usage", Offset: 12, Level: 2}},
+	}
+	doc.Text = newDocumentText(doc.Content, processor, "Intro\nhello\nUsage\nrun the tool", nil)
+
+	// Nothing is converted: the plain text stored at ingest time is used.
+	store.EXPECT().Get(mock.Anything, "owner/repo", "doc.md").Return(doc, nil)
+
+	hit := &SearchResult{Repo: "owner/repo", Path: "doc.md", ContentFragments: []string{"<mark>run</mark> the tool"}}
+
+	anchor, err := svc.resolveAnchor(t.Context(), hit)
+	require.NoError(t, err)
+	assert.Equal(t, "usage", anchor)
+}
+
+func TestResolveAnchor_StoredWithoutHeadings(t *testing.T) {
+	svc, store, _, processor := newTestService(t)
+
+	doc := Document{Repo: "owner/repo", Path: "doc.md", Content: "plain", Anchors: AnchorMap{}}
+	doc.Text = newDocumentText(doc.Content, processor, "plain", nil)
+
+	store.EXPECT().Get(mock.Anything, "owner/repo", "doc.md").Return(doc, nil)
+
+	hit := &SearchResult{Repo: "owner/repo", Path: "doc.md", ContentFragments: []string{"<mark>plain</mark>"}}
+
+	anchor, err := svc.resolveAnchor(t.Context(), hit)
+	require.NoError(t, err)
+	assert.Empty(t, anchor)
+}
+
 func TestResolveAnchor_FragmentNotFoundInPlainText(t *testing.T) {
 	svc, store, _, renderer := newTestService(t)
 	ctx := t.Context()
@@ -1418,18 +1750,47 @@ func TestGetProcessor_UnknownTypeFallsBackToMarkdown(t *testing.T) {
 	})
 
 	// An unknown content type should fall back to the markdown processor.
-	got := svc.getProcessor("unknown-type")
+	got := svc.getProcessor("unknown-type", "owner/repo")
 	assert.Equal(t, mdProcessor, got)
 
 	// Empty content type also falls back.
-	got = svc.getProcessor("")
+	got = svc.getProcessor("", "owner/repo")
 	assert.Equal(t, mdProcessor, got)
 
 	// Known type returns that processor.
-	got = svc.getProcessor("openapi")
+	got = svc.getProcessor("openapi", "owner/repo")
 	assert.Equal(t, otherProcessor, got)
 }
 
+type repoProcessor struct {
+	ContentProcessor
+	repos map[string]ContentProcessor
+}
+
+func (p *repoProcessor) ForRepo(repo string) ContentProcessor {
+	if rp, ok := p.repos[repo]; ok {
+		return rp
+	}
+
+	return p
+}
+
+func TestGetProcessor_ResolvesPerRepo(t *testing.T) {
+	override := NewMockContentProcessor(t)
+	md := &repoProcessor{
+		ContentProcessor: NewMockContentProcessor(t),
+		repos:            map[string]ContentProcessor{"owner/special": override},
+	}
+
+	svc := New(NewMockdocStore(t), NewMocksearchEngine(t), map[ContentType]ContentProcessor{
+		ContentTypeMarkdown: md,
+	})
+
+	assert.Equal(t, override, svc.getProcessor(ContentTypeMarkdown, "owner/special"))
+	assert.Equal(t, md, svc.getProcessor(ContentTypeMarkdown, "owner/repo"))
+	assert.Equal(t, override, svc.getProcessor("unknown-type", "owner/special"))
+}
+
 func TestListDocuments(t *testing.T) {
 	now := time.Date(2025, 3, 10, 8, 30, 0, 0, time.UTC)
 
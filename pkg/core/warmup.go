@@ -0,0 +1,63 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// Warmup exercises the document store, the search index and the content renderers so
+// that the first user requests after startup do not pay for cold caches. For every
+// repository it lists the documents and renders up to docsPerRepo of them, then runs
+// each of the given search queries. When queries is empty, the titles of the rendered
+// documents are used as representative queries. Individual failures are logged and do
+// not stop the warm-up; an error is returned only when ctx is done before it finishes.
+func (s *Service) Warmup(ctx context.Context, queries []string, docsPerRepo int) error {
+	repos, err := s.store.ListRepos(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "warm-up: failed to list repos", "error", err)
+	}
+
+	var titles []string
+
+	for _, repo := range repos {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("warm-up interrupted: %w", err)
+		}
+
+		docs, err := s.store.List(ctx, repo.Name)
+		if err != nil {
+			slog.WarnContext(ctx, "warm-up: failed to list documents", "repo", repo.Name, "error", err)
+			continue
+		}
+
+		for _, meta := range docs[:min(docsPerRepo, len(docs))] {
+			if err := ctx.Err(); err != nil {
+				return fmt.Errorf("warm-up interrupted: %w", err)
+			}
+
+			if _, _, _, err := s.GetDocument(ctx, repo.Name, meta.Path); err != nil {
+				slog.WarnContext(ctx, "warm-up: failed to render document", "repo", repo.Name, "path", meta.Path, "error", err)
+				continue
+			}
+
+			titles = append(titles, meta.Title)
+		}
+	}
+
+	if len(queries) == 0 {
+		queries = titles
+	}
+
+	for _, q := range queries {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("warm-up interrupted: %w", err)
+		}
+
+		if _, err := s.searchDocs(ctx, q, SearchOpts{}, s.hybrid); err != nil {
+			slog.WarnContext(ctx, "warm-up: search query failed", "query", q, "error", err)
+		}
+	}
+
+	return nil
+}
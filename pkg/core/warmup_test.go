@@ -0,0 +1,55 @@
+//go:build !compile
+
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWarmup(t *testing.T) {
+	svc, store, search, processor := newTestService(t)
+
+	guide := Document{Repo: "owner/repo", Path: "guide.md", Content: "# Guide"}
+
+	store.EXPECT().ListRepos(mock.Anything).Return([]RepoInfo{{Name: "owner/repo"}, {Name: "owner/broken"}}, nil)
+	store.EXPECT().List(mock.Anything, "owner/repo").Return([]DocumentMeta{
+		{Path: "guide.md", Title: "Guide"},
+		{Path: "missing.md", Title: "Missing"},
+		{Path: "skipped.md", Title: "Skipped"},
+	}, nil)
+	store.EXPECT().List(mock.Anything, "owner/broken").Return(nil, errors.New("disk failure"))
+	store.EXPECT().Get(mock.Anything, "owner/repo", "guide.md").Return(guide, nil)
+	store.EXPECT().Get(mock.Anything, "owner/repo", "missing.md").Return(Document{}, ErrNotFound)
+	processor.EXPECT().RenderHTML([]byte("# Guide")).Return([]byte("<h1>Guide</h1>"), nil, nil)
+	search.EXPECT().Search(mock.Anything, "Guide", SearchOpts{}).Return(&SearchResults{}, nil)
+
+	require.NoError(t, svc.Warmup(t.Context(), nil, 2))
+}
+
+func TestWarmup_ConfiguredQueries(t *testing.T) {
+	svc, store, search, _ := newTestService(t)
+
+	store.EXPECT().ListRepos(mock.Anything).Return(nil, nil)
+	search.EXPECT().Search(mock.Anything, "install", SearchOpts{}).Return(&SearchResults{}, nil)
+	search.EXPECT().Search(mock.Anything, "", SearchOpts{Lang: "go"}).Return(nil, errors.New("search failed"))
+
+	require.NoError(t, svc.Warmup(t.Context(), []string{"install", "lang:go"}, 5))
+}
+
+func TestWarmup_Interrupted(t *testing.T) {
+	svc, store, _, _ := newTestService(t)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	store.EXPECT().ListRepos(mock.Anything).Return([]RepoInfo{{Name: "owner/repo"}}, nil)
+
+	err := svc.Warmup(ctx, []string{"install"}, 5)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,23 @@
+package asciidoc
+
+import (
+	"testing"
+)
+
+func FuzzProcessor(f *testing.F) {
+	f.Add([]byte(guideDoc))
+	f.Add([]byte("* a\n** b\n*** c\n. d\n+\n----\ncode\n----\n\nterm:: def\n+\n====\nnested\n===="))
+	f.Add([]byte("[cols=\"3*\",%header]\n|===\n|a |b\n|c\\|d\n|===\n\n<<x,*y*>> `+**+` __a__ #b# ^c^ ~d~"))
+	f.Add([]byte(":a: {b}\n:b: {a}\n\n{a} link:x[`y`] https://e.com/a_b_c. pass:[<i>x</i>]"))
+
+	p := New(Config{})
+
+	f.Fuzz(func(_ *testing.T, src []byte) {
+		_, _, _ = p.RenderHTML(src)
+		p.ExtractTitle(src)
+		p.ExtractSummary(src)
+		p.ToPlainText(src)
+		p.ExtractHeadings(src)
+		p.ExtractCodeBlocks(src)
+	})
+}
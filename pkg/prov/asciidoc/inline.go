@@ -0,0 +1,271 @@
+package asciidoc
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	rePassthrough = regexp.MustCompile(`\+\+\+(.+?)\+\+\+|pass:\[(.*?)\]`)
+	reMonospace   = regexp.MustCompile("`\\+(.+?)\\+`|`([^`\\s](?:[^`]*?[^`\\s])?)`")
+	reXrefAngle   = regexp.MustCompile(`<<([^\s,<>][^,<>]*?)(?:,[ \t]*([^<>]+?))?>>`)
+	reXrefMacro   = regexp.MustCompile(`xref:([^\s\[]+)\[([^\]]*)\]`)
+	reInlineAnch  = regexp.MustCompile(`\[\[[\pL_:][\pL\pN_:.-]*(?:,[^\]]*)?\]\]|anchor:[\pL_:][\pL\pN_:.-]*\[[^\]]*\]`)
+	reImageInline = regexp.MustCompile(`image:([^\s:\[][^\s\[]*)\[([^\]]*)\]`)
+	reLinkMacro   = regexp.MustCompile(`link:([^\s\[]+)\[([^\]]*)\]`)
+	reMailto      = regexp.MustCompile(`mailto:([^\s\[@]+@[^\s\[]+)\[([^\]]*)\]`)
+	reURL         = regexp.MustCompile(`(?:https?|ftp|irc)://[^\s\[\]<>"]+(?:\[([^\]]*)\])?`)
+	reStrong      = regexp.MustCompile(`\*\*(.+?)\*\*`)
+	reEmphasis    = regexp.MustCompile(`__(.+?)__`)
+	reMark        = regexp.MustCompile(`##(.+?)##`)
+	reSuperscript = regexp.MustCompile(`\^(\S+?)\^`)
+	reSubscript   = regexp.MustCompile(`~(\S+?)~`)
+	reHardBreak   = regexp.MustCompile(`(?m)[ \t]\+$`)
+	reStash       = regexp.MustCompile("\x00([0-9]+)\x00")
+	reTag         = regexp.MustCompile(`<[^>]*>`)
+)
+
+// escaper escapes the characters that are special in HTML text and attribute values. It
+// leaves apostrophes alone, as their numeric entity would start a mark span.
+var escaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+// inliner converts the inline markup of text to HTML. Markup whose content must not be
+// formatted further, such as monospace text and links, is converted first and stashed
+// behind a placeholder until the rest of the text is formatted.
+type inliner struct {
+	titles map[string]string // titles of the sections by ID, for cross references
+	stash  []string
+}
+
+// toHTML returns the HTML of text: its passthroughs, monospace text, cross references,
+// images, links and anchors, then its strong, emphasized, marked, superscript and
+// subscript spans and hard line breaks.
+func (in *inliner) toHTML(text string) string {
+	in.stash = in.stash[:0]
+	text = strings.ReplaceAll(text, "\x00", "")
+
+	text = rePassthrough.ReplaceAllStringFunc(text, func(s string) string {
+		m := rePassthrough.FindStringSubmatch(s)
+		return in.hide(m[1] + m[2])
+	})
+
+	text = reMonospace.ReplaceAllStringFunc(text, func(s string) string {
+		m := reMonospace.FindStringSubmatch(s)
+		return in.hide("<code>" + escaper.Replace(m[1]+m[2]) + "</code>")
+	})
+
+	text = reXrefAngle.ReplaceAllStringFunc(text, func(s string) string {
+		m := reXrefAngle.FindStringSubmatch(s)
+		return in.hide(in.xref(strings.TrimSpace(m[1]), m[2]))
+	})
+
+	text = reXrefMacro.ReplaceAllStringFunc(text, func(s string) string {
+		m := reXrefMacro.FindStringSubmatch(s)
+		return in.hide(in.xref(m[1], m[2]))
+	})
+
+	text = reInlineAnch.ReplaceAllString(text, "")
+
+	text = reImageInline.ReplaceAllStringFunc(text, func(s string) string {
+		m := reImageInline.FindStringSubmatch(s)
+		alt, _, _ := strings.Cut(m[2], ",")
+
+		return in.hide(`<img src="` + escaper.Replace(m[1]) + `" alt="` + escaper.Replace(unquote(strings.TrimSpace(alt))) + `">`)
+	})
+
+	text = reLinkMacro.ReplaceAllStringFunc(text, func(s string) string {
+		m := reLinkMacro.FindStringSubmatch(s)
+		return in.hide(link(m[1], m[2]))
+	})
+
+	text = reMailto.ReplaceAllStringFunc(text, func(s string) string {
+		m := reMailto.FindStringSubmatch(s)
+		return in.hide(link("mailto:"+m[1], linkText(m[2], m[1])))
+	})
+
+	text = reURL.ReplaceAllStringFunc(text, func(s string) string {
+		if strings.HasSuffix(s, "]") {
+			url, label, _ := strings.Cut(s, "[")
+			return in.hide(link(url, strings.TrimSuffix(label, "]")))
+		}
+
+		// Punctuation ending a sentence is not part of a bare URL.
+		url := strings.TrimRight(s, ".,;:!?)'")
+
+		return in.hide(link(url, "")) + s[len(url):]
+	})
+
+	text = formatQuotes(escaper.Replace(text))
+	text = reHardBreak.ReplaceAllString(text, "<br>")
+
+	return in.restore(text)
+}
+
+// restore replaces the placeholders in s with the HTML they stand for. Stashed HTML only
+// holds placeholders stashed before it, such as monospace text in a link's text.
+func (in *inliner) restore(s string) string {
+	if !strings.Contains(s, "\x00") {
+		return s
+	}
+
+	return reStash.ReplaceAllStringFunc(s, func(ph string) string {
+		i, err := strconv.Atoi(ph[1 : len(ph)-1])
+		if err != nil || i >= len(in.stash) {
+			return ""
+		}
+
+		return in.restore(in.stash[i])
+	})
+}
+
+// toText returns text without its inline markup.
+func (in *inliner) toText(text string) string {
+	return strings.TrimSpace(html.UnescapeString(reTag.ReplaceAllString(in.toHTML(text), "")))
+}
+
+// hide stashes the converted HTML s, returning its placeholder.
+func (in *inliner) hide(s string) string {
+	in.stash = append(in.stash, s)
+	return "\x00" + strconv.Itoa(len(in.stash)-1) + "\x00"
+}
+
+// xref returns the link of a cross reference to target, an ID in the document or a
+// document path with an optional fragment, as in "install.adoc#linux". A reference
+// without text shows the title of the referenced section, or the target.
+func (in *inliner) xref(target, text string) string {
+	path, fragment, hasFragment := strings.Cut(target, "#")
+
+	if !hasFragment && !strings.HasSuffix(path, ".adoc") {
+		path, fragment = "", path
+	}
+
+	if path != "" && !strings.Contains(path, ".") {
+		path += ".adoc"
+	}
+
+	href := path
+	if fragment != "" {
+		href += "#" + fragment
+	}
+
+	if text == "" {
+		text = in.titles[fragment]
+	}
+
+	if text == "" {
+		text = target
+	}
+
+	return `<a href="` + escaper.Replace(href) + `">` + formatQuotes(escaper.Replace(text)) + `</a>`
+}
+
+// link returns the HTML of a link to url labelled by the text in its brackets, or by url
+// when the brackets are empty.
+func link(url, label string) string {
+	return `<a href="` + escaper.Replace(url) + `">` + formatQuotes(escaper.Replace(linkText(label, url))) + `</a>`
+}
+
+// linkText returns the text of a link macro's attribute list, without the "^" asking for a
+// new window, or fallback when it is empty.
+func linkText(attrs, fallback string) string {
+	text := attrs
+	if strings.Contains(attrs, "=") {
+		text = splitAttrs(attrs)[0]
+		if strings.Contains(text, "=") {
+			text = ""
+		}
+	}
+
+	text = strings.TrimSuffix(unquote(strings.TrimSpace(text)), "^")
+	if text == "" {
+		return fallback
+	}
+
+	return text
+}
+
+// formatQuotes converts the strong, emphasized, marked, superscript and subscript spans of
+// escaped text to HTML. Double markers may appear within words; single markers must
+// enclose whole words.
+func formatQuotes(text string) string {
+	if !strings.ContainsAny(text, "*_#^~") {
+		return text
+	}
+
+	text = reStrong.ReplaceAllString(text, "<strong>$1</strong>")
+	text = constrained(text, '*', "strong")
+	text = reEmphasis.ReplaceAllString(text, "<em>$1</em>")
+	text = constrained(text, '_', "em")
+	text = reMark.ReplaceAllString(text, "<mark>$1</mark>")
+	text = constrained(text, '#', "mark")
+	text = reSuperscript.ReplaceAllString(text, "<sup>$1</sup>")
+
+	return reSubscript.ReplaceAllString(text, "<sub>$1</sub>")
+}
+
+// constrained wraps the spans of text enclosed by single markers in tag. A span opens at
+// a marker that does not follow a word character and precedes a non-space, and closes at
+// the next marker that follows a non-space and does not precede a word character.
+func constrained(text string, marker byte, tag string) string {
+	if strings.IndexByte(text, marker) < 0 {
+		return text
+	}
+
+	var b strings.Builder
+
+	for i := 0; i < len(text); i++ {
+		if text[i] == marker && opensSpan(text, i) {
+			end := closesSpan(text, i+1, marker)
+			if end < 0 {
+				// No marker after this one closes a span either.
+				b.WriteString(text[i:])
+				break
+			}
+
+			b.WriteString("<" + tag + ">" + text[i+1:end] + "</" + tag + ">")
+			i = end
+
+			continue
+		}
+
+		b.WriteByte(text[i])
+	}
+
+	return b.String()
+}
+
+// opensSpan reports whether the marker at text[i] can open a constrained span.
+func opensSpan(text string, i int) bool {
+	if i > 0 {
+		if r, _ := utf8.DecodeLastRuneInString(text[:i]); isWord(r) || r == rune(text[i]) {
+			return false
+		}
+	}
+
+	return i+1 < len(text) && text[i+1] != text[i] && !unicode.IsSpace(rune(text[i+1]))
+}
+
+// closesSpan returns the index of the marker closing a constrained span whose content
+// starts at text[start], or -1 if the span is not closed.
+func closesSpan(text string, start int, marker byte) int {
+	for j := start + 1; j < len(text); j++ {
+		if text[j] != marker || unicode.IsSpace(rune(text[j-1])) {
+			continue
+		}
+
+		if r, _ := utf8.DecodeRuneInString(text[j+1:]); j+1 == len(text) || !isWord(r) && r != rune(marker) {
+			return j
+		}
+	}
+
+	return -1
+}
+
+// isWord reports whether r is a letter, a digit or an underscore.
+func isWord(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,59 @@
+package asciidoc
+
+import (
+	"fmt"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+const (
+	defaultMaxDocumentKiB  = 2048
+	defaultMaxAttributeKiB = 64
+	defaultMaxExpandedKiB  = 16384
+)
+
+// Config holds configuration for the AsciiDoc processor.
+type Config struct {
+	Limits Limits `mapstructure:"limits"`
+}
+
+// Limits bounds the work spent on a single AsciiDoc document, protecting the server from
+// documents crafted to grow without bound as their attribute references are expanded, such
+// as attributes defined as two references to the previous one. Zero values use the
+// defaults.
+type Limits struct {
+	MaxDocumentKiB  int `mapstructure:"max_document_kib"`  // Largest document accepted (default 2048).
+	MaxAttributeKiB int `mapstructure:"max_attribute_kib"` // Largest expanded attribute value (default 64).
+	MaxExpandedKiB  int `mapstructure:"max_expanded_kib"`  // Most text inserted by attribute references (default 16384).
+}
+
+// withDefaults returns the limits with zero values replaced by the defaults.
+func (l Limits) withDefaults() Limits {
+	if l.MaxDocumentKiB <= 0 {
+		l.MaxDocumentKiB = defaultMaxDocumentKiB
+	}
+
+	if l.MaxAttributeKiB <= 0 {
+		l.MaxAttributeKiB = defaultMaxAttributeKiB
+	}
+
+	if l.MaxExpandedKiB <= 0 {
+		l.MaxExpandedKiB = defaultMaxExpandedKiB
+	}
+
+	return l
+}
+
+// Validate reports an error wrapping core.ErrLimitExceeded if src is larger than the
+// processor accepts or its attribute references expand beyond the limits. It is called for
+// every published document, so that such documents are rejected at ingest.
+func (p *Processor) Validate(src []byte) error {
+	if len(src) > p.limits.MaxDocumentKiB<<10 {
+		return fmt.Errorf("%w: document is %d KiB, the maximum is %d KiB",
+			core.ErrLimitExceeded, len(src)>>10, p.limits.MaxDocumentKiB)
+	}
+
+	_, err := parse(src, p.limits)
+
+	return err
+}
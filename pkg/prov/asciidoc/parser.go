@@ -0,0 +1,908 @@
+package asciidoc
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+// maxDepth bounds how deeply delimited blocks, list continuations and nested lists are
+// parsed. Deeper content is kept as literal text.
+const maxDepth = 32
+
+// blockKind identifies the kind of a parsed block.
+type blockKind int
+
+const (
+	kindParagraph blockKind = iota
+	kindSection
+	kindListing
+	kindLiteral
+	kindQuote
+	kindExample
+	kindSidebar
+	kindOpen
+	kindAdmonition
+	kindPass
+	kindList
+	kindTable
+	kindImage
+	kindRule
+)
+
+// admonitionLabels are the admonition styles, by the name AsciiDoc uses for them.
+var admonitionLabels = map[string]string{
+	"NOTE":      "Note",
+	"TIP":       "Tip",
+	"IMPORTANT": "Important",
+	"WARNING":   "Warning",
+	"CAUTION":   "Caution",
+}
+
+var (
+	reDocTitle    = regexp.MustCompile(`^=[ \t]+(\S.*?)[ \t]*$`)
+	reSection     = regexp.MustCompile(`^(={1,6})[ \t]+(\S.*?)(?:[ \t]+=+)?[ \t]*$`)
+	reAttrEntry   = regexp.MustCompile(`^:(!?)([\w][\w-]*)(!?):(?:[ \t]+(.*?))?[ \t]*$`)
+	reBlockAnchor = regexp.MustCompile(`^\[\[([\pL_:][\pL\pN_:.-]*)(?:,[ \t]*(.*))?\]\]$`)
+	reBlockAttrs  = regexp.MustCompile(`^\[(.*)\]$`)
+	reBlockTitle  = regexp.MustCompile(`^\.([^.\s].*)$`)
+	reBlockImage  = regexp.MustCompile(`^image::([^\s\[]+)\[(.*)\]$`)
+	reDirective   = regexp.MustCompile(`^(?:include|toc|ifdef|ifndef|ifeval|endif)::.*\[.*\]$`)
+	reAdmonition  = regexp.MustCompile(`^(NOTE|TIP|IMPORTANT|WARNING|CAUTION):[ \t]+(.*)$`)
+	reUnordered   = regexp.MustCompile(`^[ \t]*(\*{1,5}|-)[ \t]+(\S.*)$`)
+	reOrdered     = regexp.MustCompile(`^[ \t]*(\.{1,5}|\d{1,9}\.)[ \t]+(\S.*)$`)
+	reDescription = regexp.MustCompile(`^[ \t]*(\S.*?)(:{2,4}|;;)(?:[ \t]+(.*))?$`)
+	reAttrRef     = regexp.MustCompile(`\{([\w][\w-]*)\}`)
+)
+
+// intrinsicAttrs are the attributes every document defines.
+var intrinsicAttrs = map[string]string{
+	"empty": "",
+	"sp":    " ",
+	"nbsp":  "\u00a0",
+	"zwsp":  "\u200b",
+}
+
+// document is a parsed AsciiDoc document.
+type document struct {
+	title  string
+	attrs  map[string]string
+	blocks []*block
+}
+
+// block is a parsed block of a document.
+type block struct {
+	id          string // anchor ID of a section
+	title       string // section title, or the title given to a block with ".Title"
+	style       string // admonition name, or list kind ("ul", "ol" or "dl")
+	lang        string // source language of a listing
+	attribution string // author and citation of a quote
+	target      string // image location
+	alt         string // image alternative text
+	lines       []string
+	children    []*block // content of compound blocks
+	items       []*listItem
+	rows        [][]string
+	kind        blockKind
+	level       int  // section level, 1 for "==" sections
+	header      bool // whether the first table row is a header
+}
+
+// listItem is an item of a list, with the blocks attached to it by list continuations and
+// its nested lists.
+type listItem struct {
+	term   string // term of a description list item
+	text   []string
+	blocks []*block
+}
+
+// blockMeta holds the attributes, anchor and title given to the next block.
+type blockMeta struct {
+	id         string
+	title      string
+	style      string
+	named      map[string]string
+	options    map[string]bool
+	positional []string
+}
+
+// parser parses the blocks of a document, resolving attribute references in document
+// order. Once the expansion of attribute references exceeds the limits, err is set and
+// references are no longer expanded.
+type parser struct {
+	attrs    map[string]string
+	err      error
+	limits   Limits
+	expanded int // bytes inserted by attribute references so far
+}
+
+// parse parses an AsciiDoc document: its header, made of the document title and the
+// attribute entries following it, then its blocks. Section titles are only recognized
+// outside of delimited blocks and lists. It returns an error wrapping
+// core.ErrLimitExceeded along with the document if an attribute value or the text inserted
+// by attribute references exceeds the limits; the references from there on are left
+// unexpanded.
+func parse(src []byte, limits Limits) (*document, error) {
+	text := strings.TrimPrefix(string(src), "\ufeff")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	lines := strings.Split(text, "\n")
+
+	p := &parser{attrs: map[string]string{"idprefix": "_", "idseparator": "_"}, limits: limits}
+	doc := &document{attrs: p.attrs}
+
+	i := 0
+	for i < len(lines) && (strings.TrimSpace(lines[i]) == "" || isLineComment(lines[i])) {
+		i++
+	}
+
+	if i < len(lines) {
+		if m := reDocTitle.FindStringSubmatch(lines[i]); m != nil {
+			doc.title = p.subst(m[1])
+			i++
+
+			// The header ends at the first blank line: author and revision lines are skipped.
+			for ; i < len(lines) && strings.TrimSpace(lines[i]) != ""; i++ {
+				p.attrEntry(lines[i])
+			}
+		}
+	}
+
+	doc.blocks = p.parseBlocks(lines[i:], 0)
+
+	return doc, p.err
+}
+
+// attrEntry sets or unsets the attribute defined by an attribute entry line, reporting
+// whether line is one.
+func (p *parser) attrEntry(line string) bool {
+	m := reAttrEntry.FindStringSubmatch(line)
+	if m == nil {
+		return false
+	}
+
+	if m[1] == "!" || m[3] == "!" {
+		delete(p.attrs, m[2])
+		return true
+	}
+
+	value := p.subst(m[4])
+	if len(value) > p.limits.MaxAttributeKiB<<10 {
+		p.fail(fmt.Errorf("%w: attribute %s expands to more than %d KiB",
+			core.ErrLimitExceeded, m[2], p.limits.MaxAttributeKiB))
+
+		return true
+	}
+
+	p.attrs[m[2]] = value
+
+	return true
+}
+
+// subst replaces the references to defined attributes in s with their values. References
+// to undefined attributes are kept, and so are all references once the text inserted by
+// them exceeds the limit.
+func (p *parser) subst(s string) string {
+	if p.err != nil || !strings.Contains(s, "{") {
+		return s
+	}
+
+	return reAttrRef.ReplaceAllStringFunc(s, func(ref string) string {
+		name := ref[1 : len(ref)-1]
+
+		v, ok := p.attrs[name]
+		if !ok {
+			v, ok = intrinsicAttrs[name]
+		}
+
+		if !ok || p.err != nil {
+			return ref
+		}
+
+		if p.expanded += len(v); p.expanded > p.limits.MaxExpandedKiB<<10 {
+			p.fail(fmt.Errorf("%w: attribute references expand to more than %d KiB",
+				core.ErrLimitExceeded, p.limits.MaxExpandedKiB))
+
+			return ref
+		}
+
+		return v
+	})
+}
+
+// fail records the first limit exceeded while parsing.
+func (p *parser) fail(err error) {
+	if p.err == nil {
+		p.err = err
+	}
+}
+
+// parseBlocks parses lines into blocks. depth is the nesting depth of lines within the
+// document; sections are only recognized at depth 0.
+func (p *parser) parseBlocks(lines []string, depth int) []*block {
+	var (
+		blocks []*block
+		meta   blockMeta
+	)
+
+	add := func(b *block) {
+		if b.title == "" && b.kind != kindSection {
+			b.title = meta.title
+		}
+
+		blocks = append(blocks, b)
+		meta = blockMeta{}
+	}
+
+	for i := 0; i < len(lines); {
+		line := strings.TrimRight(lines[i], " \t")
+
+		switch {
+		case line == "":
+			i++
+			continue
+		case isDelimiter(line):
+			end := closingDelimiter(lines, i)
+			if b := p.delimitedBlock(line, lines[i+1:end], &meta, depth); b != nil {
+				add(b)
+			} else {
+				meta = blockMeta{}
+			}
+
+			i = min(end+1, len(lines))
+
+			continue
+		case isLineComment(line), reDirective.MatchString(line), line == "<<<":
+			i++
+			continue
+		case p.attrEntry(line):
+			i++
+			continue
+		}
+
+		if m := reBlockAnchor.FindStringSubmatch(line); m != nil {
+			meta.id = m[1]
+			i++
+
+			continue
+		}
+
+		if m := reBlockAttrs.FindStringSubmatch(line); m != nil {
+			meta.parseAttrList(m[1])
+			i++
+
+			continue
+		}
+
+		if m := reBlockTitle.FindStringSubmatch(line); m != nil {
+			meta.title = p.subst(m[1])
+			i++
+
+			continue
+		}
+
+		if m := reSection.FindStringSubmatch(line); m != nil && depth == 0 {
+			add(&block{kind: kindSection, level: len(m[1]) - 1, id: meta.id, title: p.subst(m[2])})
+			i++
+
+			continue
+		}
+
+		if line == "'''" || line == "---" || line == "***" {
+			add(&block{kind: kindRule})
+			i++
+
+			continue
+		}
+
+		if m := reBlockImage.FindStringSubmatch(line); m != nil {
+			alt, _, _ := strings.Cut(m[2], ",")
+			add(&block{kind: kindImage, target: p.subst(m[1]), alt: unquote(strings.TrimSpace(alt))})
+			i++
+
+			continue
+		}
+
+		if isListItem(line) {
+			var b *block
+
+			b, i = p.parseList(lines, i, depth)
+			add(b)
+
+			continue
+		}
+
+		if line[0] == ' ' || line[0] == '\t' {
+			end := paragraphEnd(lines, i)
+			add(&block{kind: kindLiteral, lines: dedent(lines[i:end])})
+			i = end
+
+			continue
+		}
+
+		end := paragraphEnd(lines, i)
+		add(p.paragraph(lines[i:end], &meta))
+		i = end
+	}
+
+	return blocks
+}
+
+// paragraph returns the block of a paragraph, styled by the attributes given to it.
+func (p *parser) paragraph(lines []string, meta *blockMeta) *block {
+	text := make([]string, 0, len(lines))
+
+	for _, l := range lines {
+		if !isLineComment(l) {
+			text = append(text, strings.TrimSpace(l))
+		}
+	}
+
+	switch style := meta.style; {
+	case style == "source" || style == "listing":
+		return &block{kind: kindListing, lang: meta.sourceLang(p.attrs), lines: lines}
+	case style == "literal":
+		return &block{kind: kindLiteral, lines: lines}
+	case style == "pass":
+		return &block{kind: kindPass, lines: lines}
+	case style == "quote" || style == "verse":
+		return &block{
+			kind:        kindQuote,
+			attribution: meta.attribution(),
+			children:    []*block{{kind: kindParagraph, lines: p.substAll(text)}},
+		}
+	case admonitionLabels[style] != "":
+		return &block{kind: kindAdmonition, style: style, children: []*block{{kind: kindParagraph, lines: p.substAll(text)}}}
+	}
+
+	if len(text) > 0 {
+		if m := reAdmonition.FindStringSubmatch(text[0]); m != nil {
+			text[0] = m[2]
+			return &block{kind: kindAdmonition, style: m[1], children: []*block{{kind: kindParagraph, lines: p.substAll(text)}}}
+		}
+	}
+
+	return &block{kind: kindParagraph, lines: p.substAll(text)}
+}
+
+// delimitedBlock returns the block enclosed by the delimiter line, or nil for comment
+// blocks. Compound blocks deeper than maxDepth are kept as literal text.
+func (p *parser) delimitedBlock(delim string, inner []string, meta *blockMeta, depth int) *block {
+	switch {
+	case strings.HasPrefix(delim, "```"):
+		return &block{kind: kindListing, lang: strings.TrimSpace(strings.TrimLeft(delim, "`")), lines: inner}
+	case strings.HasPrefix(delim, "|==="):
+		return p.table(inner, meta)
+	}
+
+	switch delim[0] {
+	case '/':
+		return nil
+	case '-':
+		if delim != "--" || meta.style == "source" || meta.style == "listing" {
+			return &block{kind: kindListing, lang: meta.sourceLang(p.attrs), lines: inner}
+		}
+	case '.':
+		if meta.style == "source" {
+			return &block{kind: kindListing, lang: meta.sourceLang(p.attrs), lines: inner}
+		}
+
+		return &block{kind: kindLiteral, lines: inner}
+	case '+':
+		return &block{kind: kindPass, lines: inner}
+	}
+
+	if depth >= maxDepth {
+		return &block{kind: kindLiteral, lines: inner}
+	}
+
+	b := &block{children: p.parseBlocks(inner, depth+1)}
+
+	switch {
+	case admonitionLabels[meta.style] != "":
+		b.kind, b.style = kindAdmonition, meta.style
+	case delim[0] == '=':
+		b.kind = kindExample
+	case delim[0] == '*':
+		b.kind = kindSidebar
+	case delim[0] == '_' || meta.style == "quote" || meta.style == "verse":
+		b.kind, b.attribution = kindQuote, meta.attribution()
+
+		if meta.style == "verse" {
+			b.children = []*block{{kind: kindLiteral, lines: inner}}
+		}
+	default:
+		b.kind = kindOpen
+	}
+
+	return b
+}
+
+// table returns the block of a table: its cells are split on "|" and grouped into rows of
+// the number of columns given by the cols attribute or the cells of its first line. The
+// first row is a header when the header option is set, or when the first line is followed
+// by a blank line.
+func (p *parser) table(inner []string, meta *blockMeta) *block {
+	var (
+		cells     []string
+		firstLine = -1
+		firstLen  int
+		implicit  bool
+	)
+
+	for i, line := range inner {
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			if i == firstLine+1 && firstLine == 0 {
+				implicit = true
+			}
+		case strings.HasPrefix(trimmed, "|"):
+			row := splitCells(trimmed[1:])
+			cells = append(cells, row...)
+
+			if firstLine < 0 {
+				firstLine, firstLen = i, len(row)
+			}
+		case len(cells) > 0:
+			cells[len(cells)-1] += "\n" + trimmed
+		}
+	}
+
+	cols := meta.columns()
+	if cols == 0 {
+		cols = max(firstLen, 1)
+	}
+
+	b := &block{kind: kindTable}
+
+	for start := 0; start < len(cells); start += cols {
+		row := make([]string, cols)
+
+		for j := range cols {
+			if start+j < len(cells) {
+				row[j] = p.subst(strings.TrimSpace(cells[start+j]))
+			}
+		}
+
+		b.rows = append(b.rows, row)
+	}
+
+	b.header = len(b.rows) > 0 && !meta.options["noheader"] &&
+		(meta.options["header"] || implicit && firstLen == cols)
+
+	return b
+}
+
+// listMatch is a list item line: the kind of its list, its marker and its text.
+type listMatch struct {
+	kind   string
+	marker string
+	term   string
+	text   string
+}
+
+// matchListItem returns the list item starting at line, if any.
+func matchListItem(line string) (listMatch, bool) {
+	if m := reUnordered.FindStringSubmatch(line); m != nil {
+		return listMatch{kind: "ul", marker: m[1], text: m[2]}, true
+	}
+
+	if m := reOrdered.FindStringSubmatch(line); m != nil {
+		marker := m[1]
+		if marker[0] != '.' {
+			marker = "."
+		}
+
+		return listMatch{kind: "ol", marker: marker, text: m[2]}, true
+	}
+
+	if m := reDescription.FindStringSubmatch(line); m != nil && !strings.HasSuffix(m[1], ":") {
+		return listMatch{kind: "dl", marker: m[2], term: m[1], text: m[3]}, true
+	}
+
+	return listMatch{}, false
+}
+
+// isListItem reports whether line starts a list item.
+func isListItem(line string) bool {
+	_, ok := matchListItem(line)
+	return ok
+}
+
+// parseList parses the list starting at lines[i], returning it with the index of the line
+// after it. Items are nested by their markers: an item whose marker is not used by the
+// list or one of the lists it is nested in starts a list nested in the previous item.
+func (p *parser) parseList(lines []string, i, depth int) (*block, int) {
+	type flatItem struct {
+		item *listItem
+		kind string
+		key  string
+	}
+
+	var items []flatItem
+
+scan:
+	for i < len(lines) {
+		line := strings.TrimRight(lines[i], " \t")
+
+		if m, ok := matchListItem(line); ok {
+			it := &listItem{term: p.subst(m.term)}
+			if m.text != "" {
+				it.text = []string{p.subst(m.text)}
+			}
+
+			items = append(items, flatItem{item: it, kind: m.kind, key: m.kind + m.marker})
+			i++
+
+			continue
+		}
+
+		last := items[len(items)-1].item
+
+		switch trimmed := strings.TrimSpace(line); {
+		case trimmed == "":
+			j := i
+			for j < len(lines) && strings.TrimSpace(lines[j]) == "" {
+				j++
+			}
+
+			if j == len(lines) || !isListItem(lines[j]) {
+				break scan
+			}
+
+			i = j
+		case trimmed == "+":
+			end := blockEnd(lines, i+1)
+			if depth < maxDepth {
+				last.blocks = append(last.blocks, p.parseBlocks(lines[i+1:end], depth+1)...)
+			}
+
+			i = end
+		case isDelimiter(trimmed), isLineComment(trimmed):
+			break scan
+		default:
+			last.text = append(last.text, p.subst(trimmed))
+			i++
+		}
+	}
+
+	type level struct {
+		list *block
+		key  string
+	}
+
+	root := &block{kind: kindList, style: items[0].kind}
+	stack := []level{{list: root, key: items[0].key}}
+
+	for _, it := range items {
+		found := -1
+
+		for k := len(stack) - 1; k >= 0; k-- {
+			if stack[k].key == it.key {
+				found = k
+				break
+			}
+		}
+
+		if found >= 0 {
+			stack = stack[:found+1]
+		} else if parent := stack[len(stack)-1].list; len(stack) < maxDepth-depth && len(parent.items) > 0 {
+			nested := &block{kind: kindList, style: it.kind}
+			prev := parent.items[len(parent.items)-1]
+			prev.blocks = append(prev.blocks, nested)
+			stack = append(stack, level{list: nested, key: it.key})
+		}
+
+		top := stack[len(stack)-1].list
+		top.items = append(top.items, it.item)
+	}
+
+	return root, i
+}
+
+// substAll resolves the attribute references of each line.
+func (p *parser) substAll(lines []string) []string {
+	for i, l := range lines {
+		lines[i] = p.subst(l)
+	}
+
+	return lines
+}
+
+// parseAttrList parses a block attribute list such as "source,go" or
+// "#intro.lead%collapsible,cols=2" into the metadata of the next block.
+func (m *blockMeta) parseAttrList(list string) {
+	if m.named == nil {
+		m.named = make(map[string]string)
+		m.options = make(map[string]bool)
+	}
+
+	for i, attr := range splitAttrs(list) {
+		if name, value, ok := strings.Cut(attr, "="); ok && isAttrName(strings.TrimSpace(name)) {
+			name, value = strings.TrimSpace(name), unquote(strings.TrimSpace(value))
+
+			switch name {
+			case "id":
+				m.id = value
+			case "options", "opts":
+				for _, o := range strings.Split(value, ",") {
+					m.options[strings.TrimSpace(o)] = true
+				}
+			default:
+				m.named[name] = value
+			}
+
+			continue
+		}
+
+		attr = unquote(strings.TrimSpace(attr))
+
+		if i == 0 {
+			attr = m.shorthand(attr)
+			m.style = attr
+		}
+
+		m.positional = append(m.positional, attr)
+	}
+}
+
+// shorthand applies the ID and options of the first positional attribute, as in
+// "quote#intro%collapsible", returning the style it names.
+func (m *blockMeta) shorthand(attr string) string {
+	end := strings.IndexAny(attr, "#.%")
+	if end < 0 {
+		return attr
+	}
+
+	style, rest := attr[:end], attr[end:]
+
+	for rest != "" {
+		kind := rest[0]
+
+		next := strings.IndexAny(rest[1:], "#.%")
+		if next < 0 {
+			next = len(rest)
+		} else {
+			next++
+		}
+
+		value := rest[1:next]
+		rest = rest[next:]
+
+		switch kind {
+		case '#':
+			m.id = value
+		case '%':
+			m.options[value] = true
+		}
+	}
+
+	return style
+}
+
+// sourceLang returns the language of a source block: its second positional attribute, or
+// the document's source-language attribute.
+func (m *blockMeta) sourceLang(attrs map[string]string) string {
+	if len(m.positional) > 1 && m.positional[0] == "source" {
+		return m.positional[1]
+	}
+
+	if lang, ok := m.named["language"]; ok {
+		return lang
+	}
+
+	return attrs["source-language"]
+}
+
+// attribution returns the author and citation of a quote, given as its second and third
+// positional attributes.
+func (m *blockMeta) attribution() string {
+	var parts []string
+
+	for _, p := range m.positional[min(1, len(m.positional)):] {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// columns returns the number of table columns given by the cols attribute, as a number
+// ("3"), a repeated specifier ("3*") or a list of specifiers ("1,2a,1"), or 0 if unset.
+func (m *blockMeta) columns() int {
+	cols, ok := m.named["cols"]
+	if !ok || cols == "" {
+		return 0
+	}
+
+	if n, err := strconv.Atoi(cols); err == nil && n > 0 && n <= 1000 {
+		return n
+	}
+
+	if count, _, ok := strings.Cut(cols, "*"); ok {
+		if n, err := strconv.Atoi(count); err == nil && n > 0 && n <= 1000 {
+			return n
+		}
+	}
+
+	return len(strings.FieldsFunc(cols, func(r rune) bool { return r == ',' || r == ';' }))
+}
+
+// isDelimiter reports whether line opens or closes a delimited block.
+func isDelimiter(line string) bool {
+	if line == "--" || strings.HasPrefix(line, "```") || strings.HasPrefix(line, "|===") && strings.Trim(line, "=|") == "" {
+		return true
+	}
+
+	if len(line) < 4 {
+		return false
+	}
+
+	switch line[0] {
+	case '-', '.', '=', '*', '_', '+', '/':
+		return strings.Count(line, line[:1]) == len(line)
+	}
+
+	return false
+}
+
+// closingDelimiter returns the index of the line closing the delimited block opened at
+// lines[open], or len(lines) if the block is not closed.
+func closingDelimiter(lines []string, open int) int {
+	delim := strings.TrimRight(lines[open], " \t")
+	if strings.HasPrefix(delim, "```") {
+		delim = "```"
+	}
+
+	for j := open + 1; j < len(lines); j++ {
+		if strings.TrimRight(lines[j], " \t") == delim {
+			return j
+		}
+	}
+
+	return len(lines)
+}
+
+// blockEnd returns the index of the line after the block starting at lines[i], after the
+// lines giving its attributes or title: after its closing delimiter for a delimited
+// block, or at the next blank line.
+func blockEnd(lines []string, i int) int {
+	for i < len(lines) {
+		line := strings.TrimRight(lines[i], " \t")
+		if !reBlockAttrs.MatchString(line) && !reBlockTitle.MatchString(line) {
+			break
+		}
+
+		i++
+	}
+
+	if i < len(lines) && isDelimiter(strings.TrimRight(lines[i], " \t")) {
+		return min(closingDelimiter(lines, i)+1, len(lines))
+	}
+
+	return paragraphEnd(lines, i)
+}
+
+// paragraphEnd returns the index of the blank or delimiter line ending the paragraph
+// starting at lines[i].
+func paragraphEnd(lines []string, i int) int {
+	for j := i; j < len(lines); j++ {
+		line := strings.TrimRight(lines[j], " \t")
+		if line == "" || j > i && isDelimiter(line) {
+			return j
+		}
+	}
+
+	return len(lines)
+}
+
+// isLineComment reports whether line is a single-line comment.
+func isLineComment(line string) bool {
+	return strings.HasPrefix(line, "//") && !strings.HasPrefix(line, "///")
+}
+
+// dedent removes the indentation common to the non-blank lines.
+func dedent(lines []string) []string {
+	indent := -1
+
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+
+		n := len(l) - len(strings.TrimLeft(l, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+
+	out := make([]string, len(lines))
+
+	for i, l := range lines {
+		if len(l) >= indent && indent > 0 {
+			l = l[indent:]
+		}
+
+		out[i] = strings.TrimRight(l, " \t")
+	}
+
+	return out
+}
+
+// splitCells splits a table line on the "|" separators that are not escaped.
+func splitCells(line string) []string {
+	var (
+		cells []string
+		cell  strings.Builder
+	)
+
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, cell.String())
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+
+	return append(cells, cell.String())
+}
+
+// splitAttrs splits an attribute list on the commas outside of quotes.
+func splitAttrs(list string) []string {
+	var (
+		attrs []string
+		quote rune
+		start int
+	)
+
+	for i, r := range list {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ',':
+			attrs = append(attrs, list[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(attrs, list[start:])
+}
+
+// isAttrName reports whether s is a valid named attribute name.
+func isAttrName(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, r := range s {
+		if r != '-' && r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// unquote removes the double or single quotes enclosing s.
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+
+	return s
+}
@@ -0,0 +1,523 @@
+// Package asciidoc provides an AsciiDoc content processor, which parses AsciiDoc
+// documents itself and renders them as sanitized HTML. The processor covers the
+// AsciiDoc syntax documentation commonly uses: the document header and attributes,
+// sections, paragraphs, lists, tables, admonitions, source listings and the other
+// delimited blocks, and inline formatting, links and cross references.
+package asciidoc
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/microcosm-cc/bluemonday"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/ksysoev/omnidex/pkg/prov/internal/mdwrite"
+)
+
+// chromaClassPattern matches the CSS class names emitted by the Chroma syntax highlighter,
+// like the markdown processor allows them.
+var chromaClassPattern = regexp.MustCompile(`^(chroma|bg|line|lnt|ln|hl|lnlinks|lntable|lntd|[a-z]{1,3})$`)
+
+// blockClassPattern matches the class names given to admonitions, block titles and
+// example and sidebar blocks.
+var blockClassPattern = regexp.MustCompile(`^(admonition admonition-(note|tip|important|warning|caution)|admonition-title|block-title|example-block|sidebar-block)$`)
+
+// textPolicy strips all HTML of passthrough blocks, including the content of scripts and
+// styles, for search indexing.
+var textPolicy = bluemonday.StrictPolicy()
+
+// Processor implements core.ContentProcessor for AsciiDoc documents.
+// HTML output is sanitized using bluemonday, as passthrough blocks may hold raw HTML.
+type Processor struct {
+	sanitize *bluemonday.Policy
+	limits   Limits
+}
+
+// New creates a new AsciiDoc Processor.
+func New(cfg Config) *Processor {
+	policy := bluemonday.UGCPolicy()
+	policy.AllowAttrs("id").OnElements("h1", "h2", "h3", "h4", "h5", "h6")
+	policy.AllowElements("span", "aside", "figure", "figcaption", "footer", "mark")
+	policy.AllowAttrs("class").Matching(chromaClassPattern).OnElements("span", "code", "pre")
+	policy.AllowAttrs("class").Matching(blockClassPattern).OnElements("div", "p", "aside")
+
+	return &Processor{sanitize: policy, limits: cfg.Limits.withDefaults()}
+}
+
+// ContentTypeInfo describes AsciiDoc documents: .adoc, .asciidoc and .asc files.
+func (p *Processor) ContentTypeInfo() core.ContentTypeInfo {
+	return core.ContentTypeInfo{
+		Name:        core.ContentTypeAsciiDoc,
+		DisplayName: "AsciiDoc",
+		Icon:        "document",
+		Extensions:  []string{".adoc", ".asciidoc", ".asc"},
+	}
+}
+
+// RenderHTML renders the document as sanitized HTML and returns its H1-H3 headings: the
+// document title and the sections up to level 2 ("==="). Source listings with a known
+// language are highlighted like markdown code blocks. Documents whose attribute references
+// expand beyond the limits are not rendered.
+func (p *Processor) RenderHTML(src []byte) ([]byte, []core.Heading, error) {
+	doc, err := parse(src, p.limits)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	in := &inliner{}
+	headings := doc.outline(in)
+
+	r := &renderer{in: in}
+
+	if doc.title != "" {
+		fmt.Fprintf(&r.buf, "<h1 id=\"%s\">%s</h1>\n", html.EscapeString(headings[0].ID), in.toHTML(doc.title))
+	}
+
+	r.blocks(doc.blocks)
+
+	return p.sanitize.SanitizeBytes(r.buf.Bytes()), topHeadings(headings), nil
+}
+
+// ExtractTitle returns the document title, or an empty string if the document has none.
+func (p *Processor) ExtractTitle(src []byte) string {
+	doc := p.document(src)
+	if doc.title == "" {
+		return ""
+	}
+
+	return (&inliner{}).toText(doc.title)
+}
+
+// ExtractSummary returns the text of the first top-level paragraph as a single line,
+// skipping paragraphs made only of images, such as badges. It returns an empty string
+// when the document has no such paragraph.
+func (p *Processor) ExtractSummary(src []byte) string {
+	doc := p.document(src)
+	in := &inliner{}
+	doc.outline(in)
+
+	for _, b := range doc.blocks {
+		if b.kind != kindParagraph {
+			continue
+		}
+
+		if summary := strings.Join(strings.Fields(in.toText(strings.Join(b.lines, "\n"))), " "); summary != "" {
+			return summary
+		}
+	}
+
+	return ""
+}
+
+// ToPlainText returns the text of the document for search indexing: the document title,
+// then each block in document order with section titles on their own line, list items
+// and table rows on a line each, and the content of listings as is.
+func (p *Processor) ToPlainText(src []byte) string {
+	doc := p.document(src)
+	in := &inliner{}
+	doc.outline(in)
+
+	var buf bytes.Buffer
+
+	if doc.title != "" {
+		mdwrite.WriteLine(&buf, in.toText(doc.title))
+	}
+
+	writeText(&buf, in, doc.blocks)
+
+	return strings.TrimSpace(buf.String())
+}
+
+// ExtractHeadings returns the document title and the sections up to level 2 ("===") with
+// the anchor IDs RenderHTML gives them. Sections without an explicit ID get one like
+// Asciidoctor generates it, "_getting_started" for "Getting Started", following the
+// idprefix and idseparator attributes, so existing cross references keep working.
+func (p *Processor) ExtractHeadings(src []byte) []core.Heading {
+	doc := p.document(src)
+
+	return topHeadings(doc.outline(&inliner{}))
+}
+
+// ExtractCodeBlocks returns the content of the source and listing blocks together with
+// their lowercased language, for code search.
+func (p *Processor) ExtractCodeBlocks(src []byte) []core.CodeBlock {
+	var blocks []core.CodeBlock
+
+	walk(p.document(src).blocks, func(b *block) {
+		if b.kind != kindListing {
+			return
+		}
+
+		if code := strings.TrimRight(strings.Join(b.lines, "\n"), "\n"); strings.TrimSpace(code) != "" {
+			blocks = append(blocks, core.CodeBlock{Lang: strings.ToLower(b.lang), Code: code})
+		}
+	})
+
+	return blocks
+}
+
+// document parses src for text extraction. Attribute references beyond the limits are
+// left unexpanded, so a document exceeding them is indexed with the text it has.
+func (p *Processor) document(src []byte) *document {
+	doc, _ := parse(src, p.limits)
+
+	return doc
+}
+
+// outline assigns the anchor IDs of the sections without an explicit one and returns the
+// headings of the document title and all sections. The titles of the sections are given
+// to in, for the text of cross references.
+func (d *document) outline(in *inliner) []core.Heading {
+	taken := make(map[string]bool)
+
+	for _, b := range d.blocks {
+		if b.kind == kindSection && b.id != "" {
+			taken[b.id] = true
+		}
+	}
+
+	var headings []core.Heading
+
+	if d.title != "" {
+		text := in.toText(d.title)
+		headings = append(headings, core.Heading{Level: 1, ID: d.sectionID(text, taken), Text: text})
+	}
+
+	in.titles = make(map[string]string)
+
+	for _, b := range d.blocks {
+		if b.kind != kindSection {
+			continue
+		}
+
+		text := in.toText(b.title)
+		if b.id == "" {
+			b.id = d.sectionID(text, taken)
+		}
+
+		in.titles[b.id] = text
+		headings = append(headings, core.Heading{Level: min(b.level+1, 6), ID: b.id, Text: text})
+	}
+
+	return headings
+}
+
+// sectionID returns an unused ID generated from a section title like Asciidoctor does:
+// the idprefix followed by the lowercased title, keeping letters, digits and underscores
+// and joining words with the idseparator. Repeated IDs get a "_2", "_3" suffix.
+func (d *document) sectionID(title string, taken map[string]bool) string {
+	prefix, sep := d.attrs["idprefix"], d.attrs["idseparator"]
+
+	var (
+		b       strings.Builder
+		pending bool
+	)
+
+	for _, r := range strings.ToLower(title) {
+		switch {
+		case r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			if pending && b.Len() > 0 {
+				b.WriteString(sep)
+			}
+
+			pending = false
+
+			b.WriteRune(r)
+		case r == ' ' || r == '.' || r == '-':
+			pending = true
+		}
+	}
+
+	if b.Len() == 0 {
+		b.WriteString("section")
+	}
+
+	id := prefix + b.String()
+
+	if sep == "" {
+		sep = "_"
+	}
+
+	unique := id
+	for n := 2; taken[unique]; n++ {
+		unique = id + sep + strconv.Itoa(n)
+	}
+
+	taken[unique] = true
+
+	return unique
+}
+
+// topHeadings returns the H1-H3 headings.
+func topHeadings(headings []core.Heading) []core.Heading {
+	var top []core.Heading
+
+	for _, h := range headings {
+		if h.Level <= 3 {
+			top = append(top, h)
+		}
+	}
+
+	return top
+}
+
+// renderer writes the HTML of blocks.
+type renderer struct {
+	in  *inliner
+	buf bytes.Buffer
+}
+
+// blocks writes the HTML of each block.
+func (r *renderer) blocks(blocks []*block) {
+	for _, b := range blocks {
+		r.block(b)
+	}
+}
+
+// block writes the HTML of a block, preceded by its title.
+func (r *renderer) block(b *block) {
+	switch b.kind {
+	case kindSection:
+		level := min(b.level+1, 6)
+		fmt.Fprintf(&r.buf, "<h%d id=\"%s\">%s</h%d>\n", level, html.EscapeString(b.id), r.in.toHTML(b.title), level)
+
+		return
+	case kindTable:
+		r.table(b)
+		return
+	case kindImage:
+		r.image(b)
+		return
+	}
+
+	if b.title != "" {
+		fmt.Fprintf(&r.buf, "<p class=\"block-title\">%s</p>\n", r.in.toHTML(b.title))
+	}
+
+	switch b.kind {
+	case kindParagraph:
+		fmt.Fprintf(&r.buf, "<p>%s</p>\n", r.in.toHTML(strings.Join(b.lines, "\n")))
+	case kindListing:
+		r.code(b)
+	case kindLiteral:
+		fmt.Fprintf(&r.buf, "<pre>%s</pre>\n", html.EscapeString(strings.Join(b.lines, "\n")))
+	case kindPass:
+		r.buf.WriteString(strings.Join(b.lines, "\n"))
+		r.buf.WriteByte('\n')
+	case kindQuote:
+		r.buf.WriteString("<blockquote>\n")
+		r.blocks(b.children)
+
+		if b.attribution != "" {
+			fmt.Fprintf(&r.buf, "<footer>— %s</footer>\n", html.EscapeString(b.attribution))
+		}
+
+		r.buf.WriteString("</blockquote>\n")
+	case kindExample:
+		r.buf.WriteString("<div class=\"example-block\">\n")
+		r.blocks(b.children)
+		r.buf.WriteString("</div>\n")
+	case kindSidebar:
+		r.buf.WriteString("<aside class=\"sidebar-block\">\n")
+		r.blocks(b.children)
+		r.buf.WriteString("</aside>\n")
+	case kindAdmonition:
+		name := strings.ToLower(b.style)
+		fmt.Fprintf(&r.buf, "<div class=\"admonition admonition-%s\">\n<p class=\"admonition-title\">%s</p>\n", name, admonitionLabels[b.style])
+		r.blocks(b.children)
+		r.buf.WriteString("</div>\n")
+	case kindOpen:
+		r.blocks(b.children)
+	case kindList:
+		r.list(b)
+	case kindRule:
+		r.buf.WriteString("<hr>\n")
+	}
+}
+
+// code writes a source listing, highlighted when its language is known to Chroma.
+func (r *renderer) code(b *block) {
+	code := strings.Join(b.lines, "\n")
+
+	if b.lang != "" {
+		if lexer := lexers.Get(b.lang); lexer != nil {
+			iterator, err := chroma.Coalesce(lexer).Tokenise(nil, code+"\n")
+			if err == nil {
+				formatter := chromahtml.New(chromahtml.WithClasses(true), chromahtml.WithAllClasses(true))
+
+				var buf bytes.Buffer
+				if err := formatter.Format(&buf, styles.Get("github-dark"), iterator); err == nil {
+					r.buf.Write(buf.Bytes())
+					r.buf.WriteByte('\n')
+
+					return
+				}
+			}
+		}
+	}
+
+	fmt.Fprintf(&r.buf, "<pre><code>%s</code></pre>\n", html.EscapeString(code))
+}
+
+// list writes an unordered, ordered or description list. Checklist items ("[x]" or
+// "[ ]") are shown with a check mark or an empty box.
+func (r *renderer) list(b *block) {
+	tag := b.style
+
+	fmt.Fprintf(&r.buf, "<%s>\n", tag)
+
+	for _, it := range b.items {
+		text := strings.Join(it.text, "\n")
+
+		if tag == "dl" {
+			fmt.Fprintf(&r.buf, "<dt>%s</dt>\n", r.in.toHTML(it.term))
+
+			if text == "" && len(it.blocks) == 0 {
+				continue
+			}
+
+			r.buf.WriteString("<dd>")
+		} else {
+			r.buf.WriteString("<li>")
+
+			switch {
+			case strings.HasPrefix(text, "[x] "), strings.HasPrefix(text, "[*] "):
+				text = "✓ " + text[4:]
+			case strings.HasPrefix(text, "[ ] "):
+				text = "❏ " + text[4:]
+			}
+		}
+
+		r.buf.WriteString(r.in.toHTML(text))
+
+		if len(it.blocks) > 0 {
+			r.buf.WriteByte('\n')
+			r.blocks(it.blocks)
+		}
+
+		if tag == "dl" {
+			r.buf.WriteString("</dd>\n")
+		} else {
+			r.buf.WriteString("</li>\n")
+		}
+	}
+
+	fmt.Fprintf(&r.buf, "</%s>\n", tag)
+}
+
+// table writes a table with its title as caption.
+func (r *renderer) table(b *block) {
+	r.buf.WriteString("<table>\n")
+
+	if b.title != "" {
+		fmt.Fprintf(&r.buf, "<caption>%s</caption>\n", r.in.toHTML(b.title))
+	}
+
+	rows := b.rows
+	if b.header {
+		r.row(rows[0], "th", "thead")
+		rows = rows[1:]
+	}
+
+	if len(rows) > 0 {
+		r.buf.WriteString("<tbody>\n")
+
+		for _, row := range rows {
+			r.row(row, "td", "")
+		}
+
+		r.buf.WriteString("</tbody>\n")
+	}
+
+	r.buf.WriteString("</table>\n")
+}
+
+// row writes a table row of cell elements, wrapped in section when it is not empty.
+func (r *renderer) row(cells []string, cell, section string) {
+	if section != "" {
+		fmt.Fprintf(&r.buf, "<%s>\n", section)
+	}
+
+	r.buf.WriteString("<tr>")
+
+	for _, c := range cells {
+		fmt.Fprintf(&r.buf, "<%s>%s</%s>", cell, strings.ReplaceAll(r.in.toHTML(c), "\n", "<br>\n"), cell)
+	}
+
+	r.buf.WriteString("</tr>\n")
+
+	if section != "" {
+		fmt.Fprintf(&r.buf, "</%s>\n", section)
+	}
+}
+
+// image writes a block image, as a figure captioned by its title if it has one.
+func (r *renderer) image(b *block) {
+	img := fmt.Sprintf("<img src=\"%s\" alt=\"%s\">", html.EscapeString(b.target), html.EscapeString(b.alt))
+
+	if b.title == "" {
+		fmt.Fprintf(&r.buf, "<p>%s</p>\n", img)
+		return
+	}
+
+	fmt.Fprintf(&r.buf, "<figure>%s<figcaption>%s</figcaption></figure>\n", img, r.in.toHTML(b.title))
+}
+
+// writeText writes the searchable text of blocks.
+func writeText(buf *bytes.Buffer, in *inliner, blocks []*block) {
+	for _, b := range blocks {
+		if b.title != "" {
+			mdwrite.WriteLine(buf, in.toText(b.title))
+		}
+
+		switch b.kind {
+		case kindParagraph:
+			mdwrite.WriteLine(buf, in.toText(strings.Join(b.lines, "\n")))
+		case kindListing, kindLiteral:
+			mdwrite.WriteLine(buf, strings.TrimSpace(strings.Join(b.lines, "\n")))
+		case kindPass:
+			mdwrite.WriteLine(buf, strings.TrimSpace(html.UnescapeString(textPolicy.Sanitize(strings.Join(b.lines, "\n")))))
+		case kindQuote, kindExample, kindSidebar, kindAdmonition, kindOpen:
+			writeText(buf, in, b.children)
+		case kindList:
+			for _, it := range b.items {
+				mdwrite.WriteLine(buf, in.toText(it.term))
+				mdwrite.WriteLine(buf, in.toText(strings.Join(it.text, "\n")))
+				writeText(buf, in, it.blocks)
+			}
+		case kindTable:
+			for _, row := range b.rows {
+				cells := make([]string, len(row))
+				for i, c := range row {
+					cells[i] = strings.ReplaceAll(in.toText(c), "\n", " ")
+				}
+
+				mdwrite.WriteLine(buf, strings.TrimSpace(strings.Join(cells, "\t")))
+			}
+		case kindImage:
+			mdwrite.WriteLine(buf, b.alt)
+		}
+	}
+}
+
+// walk calls fn for each block of blocks and the blocks nested in them, in document order.
+func walk(blocks []*block, fn func(*block)) {
+	for _, b := range blocks {
+		fn(b)
+		walk(b.children, fn)
+
+		for _, it := range b.items {
+			walk(it.blocks, fn)
+		}
+	}
+}
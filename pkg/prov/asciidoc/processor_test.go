@@ -0,0 +1,286 @@
+package asciidoc
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const guideDoc = `= User Guide
+Jane Doe <jane@example.com>
+:product: Omnidex
+
+Welcome to *{product}*, the _docs_ hub. See <<install>> and https://example.com[the site].
+
+[[install]]
+== Installation
+
+NOTE: Requires Go ` + "`1.26`" + `.
+
+.Build it
+[source,go]
+----
+func main() {}
+----
+
+* first
+** nested
+* second
++
+continued paragraph
+
+=== Config Options
+
+[cols="1,2",options="header"]
+|===
+|Name |Description
+|port |Port to listen on
+|===
+
+== Getting Started
+
+[WARNING]
+====
+Danger *zone*.
+====
+
+==== Deep Section
+`
+
+func TestProcessor_ContentTypeInfo(t *testing.T) {
+	info := New(Config{}).ContentTypeInfo()
+
+	assert.Equal(t, core.ContentTypeAsciiDoc, info.Name)
+	assert.Equal(t, "AsciiDoc", info.DisplayName)
+	assert.Equal(t, []string{".adoc", ".asciidoc", ".asc"}, info.Extensions)
+	assert.Empty(t, info.Markers)
+	assert.False(t, info.Fallback)
+}
+
+func TestProcessor_DetectsAdocFiles(t *testing.T) {
+	types := []core.ContentTypeInfo{New(Config{}).ContentTypeInfo()}
+	types = append(types, core.DefaultContentTypes()...)
+
+	assert.Equal(t, core.ContentTypeAsciiDoc, core.DetectContentTypeIn(types, "docs/guide.adoc", []byte(guideDoc)))
+	assert.Equal(t, core.ContentTypeMarkdown, core.DetectContentTypeIn(types, "docs/guide.md", []byte("# Guide")))
+}
+
+func TestProcessor_RenderHTML(t *testing.T) {
+	p := New(Config{})
+
+	out, headings, err := p.RenderHTML([]byte(guideDoc))
+	require.NoError(t, err)
+
+	html := string(out)
+
+	assert.Contains(t, html, `<h1 id="_user_guide">User Guide</h1>`)
+	assert.Contains(t, html, `<p>Welcome to <strong>Omnidex</strong>, the <em>docs</em> hub.`)
+	assert.Contains(t, html, `<a href="#install" rel="nofollow">Installation</a>`)
+	assert.Contains(t, html, `<a href="https://example.com" rel="nofollow">the site</a>`)
+	assert.Contains(t, html, `<h2 id="install">Installation</h2>`)
+	assert.Contains(t, html, `<div class="admonition admonition-note">`)
+	assert.Contains(t, html, `<p>Requires Go <code>1.26</code>.</p>`)
+	assert.Contains(t, html, `<p class="block-title">Build it</p>`)
+	assert.Contains(t, html, `<pre class="chroma">`)
+	assert.Contains(t, html, "<li>first\n<ul>\n<li>nested</li>\n</ul>\n</li>")
+	assert.Contains(t, html, "<li>second\n<p>continued paragraph</p>\n</li>")
+	assert.Contains(t, html, `<h3 id="_config_options">Config Options</h3>`)
+	assert.Contains(t, html, "<thead>\n<tr><th>Name</th><th>Description</th></tr>")
+	assert.Contains(t, html, "<tr><td>port</td><td>Port to listen on</td></tr>")
+	assert.Contains(t, html, `<div class="admonition admonition-warning">`)
+	assert.Contains(t, html, `<h4 id="_deep_section">Deep Section</h4>`)
+
+	// Headings are limited to H1-H3, with the IDs rendered.
+	assert.Equal(t, []core.Heading{
+		{Level: 1, ID: "_user_guide", Text: "User Guide"},
+		{Level: 2, ID: "install", Text: "Installation"},
+		{Level: 3, ID: "_config_options", Text: "Config Options"},
+		{Level: 2, ID: "_getting_started", Text: "Getting Started"},
+	}, headings)
+}
+
+func TestProcessor_RenderHTML_Sanitizes(t *testing.T) {
+	src := "++++\n<b onclick=\"steal()\">bold</b><script>alert(1)</script>\n++++\n\nlink:javascript:alert(1)[click] and <b>not markup</b>"
+
+	out, _, err := New(Config{}).RenderHTML([]byte(src))
+	require.NoError(t, err)
+
+	html := string(out)
+
+	assert.Contains(t, html, "<b>bold</b>")
+	assert.NotContains(t, html, "onclick")
+	assert.NotContains(t, html, "<script")
+	assert.NotContains(t, html, "javascript:")
+	assert.Contains(t, html, "&lt;b&gt;not markup&lt;/b&gt;")
+}
+
+func TestProcessor_RenderHTML_Blocks(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{name: "literal paragraph", src: "  indented\n  text", want: "<pre>indented\ntext</pre>"},
+		{name: "literal block", src: "....\n<kbd>\n....", want: "<pre>&lt;kbd&gt;</pre>"},
+		{name: "fenced code", src: "```text\nplain\n```", want: "plain"},
+		{name: "unknown language", src: "[source,nope]\n----\nx < y\n----", want: "<pre><code>x &lt; y</code></pre>"},
+		{name: "quote", src: "[quote, Ada Lovelace, Notes]\n____\nQuoted\n____", want: "<blockquote>\n<p>Quoted</p>\n<footer>— Ada Lovelace, Notes</footer>\n</blockquote>"},
+		{name: "sidebar", src: "****\nAside\n****", want: "<aside class=\"sidebar-block\">\n<p>Aside</p>\n</aside>"},
+		{name: "example", src: "====\nSample\n====", want: "<div class=\"example-block\">\n<p>Sample</p>\n</div>"},
+		{name: "ordered list", src: ". one\n. two", want: "<ol>\n<li>one</li>\n<li>two</li>\n</ol>"},
+		{name: "description list", src: "CPU:: The brain\nRAM::\nMemory", want: "<dl>\n<dt>CPU</dt>\n<dd>The brain</dd>\n<dt>RAM</dt>\n<dd>Memory</dd>\n</dl>"},
+		{name: "checklist", src: "* [x] done\n* [ ] todo", want: "<li>✓ done</li>\n<li>❏ todo</li>"},
+		{name: "implicit table header", src: "|===\n|A |B\n\n|1 |2\n|===", want: "<thead>\n<tr><th>A</th><th>B</th></tr>"},
+		{name: "figure", src: ".Architecture\nimage::arch.png[Overview]", want: `<figure><img src="arch.png" alt="Overview"><figcaption>Architecture</figcaption></figure>`},
+		{name: "rule", src: "'''", want: "<hr>"},
+		{name: "comment", src: "// hidden\n////\nhidden too\n////\nshown", want: "<p>shown</p>"},
+		{name: "hard break", src: "one +\ntwo", want: "<p>one<br>\ntwo</p>"},
+		{name: "inline formatting", src: "#marked# 2^10^ H~2~O **un**constrained snake_case_name `+*lit*+`", want: "<p><mark>marked</mark> 2<sup>10</sup> H<sub>2</sub>O <strong>un</strong>constrained snake_case_name <code>*lit*</code></p>"},
+		{name: "document xref", src: "xref:install.adoc#linux[Linux] and <<setup#proxy,proxy>>", want: `<a href="install.adoc#linux" rel="nofollow">Linux</a> and <a href="setup.adoc#proxy" rel="nofollow">proxy</a>`},
+	}
+
+	p := New(Config{})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, _, err := p.RenderHTML([]byte(tt.src))
+			require.NoError(t, err)
+
+			assert.Contains(t, string(out), tt.want)
+			assert.NotContains(t, string(out), "hidden")
+		})
+	}
+}
+
+func TestProcessor_ExtractHeadings(t *testing.T) {
+	t.Run("matches the plain text lines in order", func(t *testing.T) {
+		p := New(Config{})
+		headings := p.ExtractHeadings([]byte(guideDoc))
+		text := p.ToPlainText([]byte(guideDoc))
+
+		require.Len(t, headings, 4)
+
+		lines := strings.Split(text, "\n")
+		next := 0
+
+		for _, h := range headings {
+			found := false
+
+			for next < len(lines) {
+				next++
+
+				if lines[next-1] == h.Text {
+					found = true
+					break
+				}
+			}
+
+			assert.True(t, found, "heading %q not found in order", h.Text)
+		}
+	})
+
+	t.Run("follows idprefix and idseparator and de-duplicates", func(t *testing.T) {
+		headings := New(Config{}).ExtractHeadings([]byte("= Doc\n:idprefix:\n:idseparator: -\n\n== A Title!\n\n== A Title!\n\n[#custom]\n== Custom"))
+
+		assert.Equal(t, []core.Heading{
+			{Level: 1, ID: "doc", Text: "Doc"},
+			{Level: 2, ID: "a-title", Text: "A Title!"},
+			{Level: 2, ID: "a-title-2", Text: "A Title!"},
+			{Level: 2, ID: "custom", Text: "Custom"},
+		}, headings)
+	})
+
+	t.Run("sections inside blocks are not headings", func(t *testing.T) {
+		assert.Empty(t, New(Config{}).ExtractHeadings([]byte("====\n== Not a section\n====")))
+	})
+}
+
+func TestProcessor_ToPlainText(t *testing.T) {
+	text := New(Config{}).ToPlainText([]byte(guideDoc))
+
+	assert.True(t, strings.HasPrefix(text, "User Guide\nWelcome to Omnidex, the docs hub. See Installation and the site.\n"))
+	assert.Contains(t, text, "Requires Go 1.26.")
+	assert.Contains(t, text, "Build it\nfunc main() {}")
+	assert.Contains(t, text, "first\nnested\nsecond\ncontinued paragraph")
+	assert.Contains(t, text, "Name\tDescription\nport\tPort to listen on")
+	assert.Contains(t, text, "Danger zone.")
+	assert.NotContains(t, text, "Jane Doe")
+	assert.NotContains(t, text, ":product:")
+
+	t.Run("passthrough text without scripts", func(t *testing.T) {
+		text := New(Config{}).ToPlainText([]byte("++++\n<b>bold</b><script>alert(1)</script>\n++++"))
+
+		assert.Equal(t, "bold", text)
+	})
+}
+
+func TestProcessor_ExtractTitle(t *testing.T) {
+	p := New(Config{})
+
+	assert.Equal(t, "User Guide", p.ExtractTitle([]byte(guideDoc)))
+	assert.Equal(t, "Omnidex Guide", p.ExtractTitle([]byte("// comment\n\n= *Omnidex* Guide")))
+	assert.Empty(t, p.ExtractTitle([]byte("== Section only")))
+}
+
+func TestProcessor_ExtractSummary(t *testing.T) {
+	p := New(Config{})
+
+	assert.Equal(t, "Welcome to Omnidex, the docs hub. See Installation and the site.", p.ExtractSummary([]byte(guideDoc)))
+	assert.Equal(t, "Second paragraph on two lines.", p.ExtractSummary([]byte("image:badge.svg[Build]\n\nSecond paragraph\non two lines.")))
+	assert.Empty(t, p.ExtractSummary([]byte("= Title\n\n----\ncode\n----")))
+}
+
+func TestProcessor_ExtractCodeBlocks(t *testing.T) {
+	blocks := New(Config{}).ExtractCodeBlocks([]byte(guideDoc + "\n====\n```Shell\nmake\n```\n====\n\n....\nliteral\n...."))
+
+	assert.Equal(t, []core.CodeBlock{
+		{Lang: "go", Code: "func main() {}"},
+		{Lang: "shell", Code: "make"},
+	}, blocks)
+}
+
+func TestProcessor_Limits(t *testing.T) {
+	// Each attribute doubles the previous one, so unbounded expansion would grow the
+	// document exponentially.
+	doubling := func(lines int) []byte {
+		src := ":x: a\n" + strings.Repeat(":x: {x}{x}\n", lines) + "\n{x}\n"
+		return []byte(src)
+	}
+
+	p := New(Config{})
+
+	t.Run("attribute value", func(t *testing.T) {
+		src := doubling(40)
+
+		_, _, err := p.RenderHTML(src)
+		require.ErrorIs(t, err, core.ErrLimitExceeded)
+		assert.ErrorContains(t, err, "attribute x expands to more than 64 KiB")
+		require.ErrorIs(t, p.Validate(src), core.ErrLimitExceeded)
+		assert.Less(t, len(p.ToPlainText(src)), 1<<20, "text extraction stops expanding at the limit")
+	})
+
+	t.Run("expanded text", func(t *testing.T) {
+		src := append(doubling(15), strings.Repeat("{x}", 1000)...)
+
+		_, _, err := p.RenderHTML(src)
+		require.ErrorIs(t, err, core.ErrLimitExceeded)
+		assert.ErrorContains(t, err, "attribute references expand to more than 16384 KiB")
+	})
+
+	t.Run("document size", func(t *testing.T) {
+		small := New(Config{Limits: Limits{MaxDocumentKiB: 1}})
+
+		require.ErrorIs(t, small.Validate(bytes.Repeat([]byte("a"), 2<<10)), core.ErrLimitExceeded)
+		assert.NoError(t, small.Validate([]byte(guideDoc)))
+	})
+
+	t.Run("within limits", func(t *testing.T) {
+		out, _, err := p.RenderHTML(doubling(10))
+		require.NoError(t, err)
+		assert.Contains(t, string(out), strings.Repeat("a", 1024))
+	})
+}
@@ -0,0 +1,22 @@
+package asyncapi
+
+import (
+	"testing"
+)
+
+func FuzzProcessor(f *testing.F) {
+	f.Add([]byte(v2SpecYAML))
+	f.Add([]byte(v3SpecJSON))
+	f.Add([]byte("asyncapi: 3.0.0\noperations:\n  x:\n    channel: null\n"))
+	f.Add([]byte(`{"asyncapi":"2.0.0","channels":{"a":{"subscribe":{"message":{"oneOf":[{"oneOf":[]}]}}}}}`))
+
+	p := New()
+
+	f.Fuzz(func(_ *testing.T, src []byte) {
+		_, _, _ = p.RenderHTML(src)
+		p.ExtractTitle(src)
+		p.ExtractSummary(src)
+		p.ToPlainText(src)
+		p.ExtractHeadings(src)
+	})
+}
@@ -0,0 +1,418 @@
+// Package asyncapi provides an AsyncAPI specification content processor.
+// AsyncAPI 2.x and 3.x specs, in YAML or JSON, are rendered server-side as an overview
+// of their servers, channels and operations, with a section per channel and operation
+// that search results and the table of contents link to.
+package asyncapi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/ksysoev/omnidex/pkg/prov/internal/mdwrite"
+	"gopkg.in/yaml.v3"
+)
+
+// errNotAsyncAPI is returned for documents without the top-level "asyncapi" version key.
+var errNotAsyncAPI = errors.New("document has no asyncapi version")
+
+// spec holds the parts of an AsyncAPI document the processor indexes and renders.
+// Channels carry their operations in AsyncAPI 2.x; AsyncAPI 3.x lists them in Operations
+// and refers to their channel.
+type spec struct {
+	Servers    map[string]server    `yaml:"servers"`
+	Channels   map[string]channel   `yaml:"channels"`
+	Operations map[string]operation `yaml:"operations"`
+	AsyncAPI   string               `yaml:"asyncapi"`
+	Info       info                 `yaml:"info"`
+}
+
+type info struct {
+	Title       string `yaml:"title"`
+	Version     string `yaml:"version"`
+	Description string `yaml:"description"`
+}
+
+type server struct {
+	Host        string `yaml:"host"`
+	URL         string `yaml:"url"` // AsyncAPI 2.x
+	Protocol    string `yaml:"protocol"`
+	Description string `yaml:"description"`
+}
+
+type channel struct {
+	Messages    map[string]message `yaml:"messages"`  // AsyncAPI 3.x
+	Publish     *operation         `yaml:"publish"`   // AsyncAPI 2.x
+	Subscribe   *operation         `yaml:"subscribe"` // AsyncAPI 2.x
+	Address     string             `yaml:"address"`   // AsyncAPI 3.x
+	Title       string             `yaml:"title"`
+	Summary     string             `yaml:"summary"`
+	Description string             `yaml:"description"`
+}
+
+type operation struct {
+	Message     *message  `yaml:"message"` // AsyncAPI 2.x
+	Channel     reference `yaml:"channel"` // AsyncAPI 3.x
+	OperationID string    `yaml:"operationId"`
+	Action      string    `yaml:"action"` // AsyncAPI 3.x: "send" or "receive"
+	Title       string    `yaml:"title"`
+	Summary     string    `yaml:"summary"`
+	Description string    `yaml:"description"`
+	Messages    []message `yaml:"messages"` // AsyncAPI 3.x
+}
+
+type message struct {
+	Ref   string    `yaml:"$ref"`
+	Name  string    `yaml:"name"`
+	Title string    `yaml:"title"`
+	OneOf []message `yaml:"oneOf"`
+}
+
+type reference struct {
+	Ref string `yaml:"$ref"`
+}
+
+// section is a channel or operation of the spec in the order it is indexed and rendered.
+type section struct {
+	heading     core.Heading
+	summary     string
+	description string
+	messages    []string
+}
+
+// Processor implements core.ContentProcessor for AsyncAPI specifications. It renders
+// channels and operations as plain HTML, so pages need no client-side viewer.
+type Processor struct{}
+
+// New creates a new AsyncAPI Processor.
+func New() *Processor {
+	return &Processor{}
+}
+
+// ContentTypeInfo describes AsyncAPI specs: YAML or JSON files with an "asyncapi"
+// top-level key.
+func (p *Processor) ContentTypeInfo() core.ContentTypeInfo {
+	return core.ContentTypeInfo{
+		Name:        core.ContentTypeAsyncAPI,
+		DisplayName: "AsyncAPI",
+		Icon:        "events",
+		Extensions:  []string{".yaml", ".yml", ".json"},
+		Markers:     []string{"asyncapi"},
+	}
+}
+
+// RenderHTML renders the spec as HTML: the API title and description, its servers, and
+// a section per channel followed by its operations. Descriptions are shown as plain text
+// paragraphs; all values taken from the spec are escaped.
+func (p *Processor) RenderHTML(src []byte) ([]byte, []core.Heading, error) {
+	s, err := parseSpec(src)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse AsyncAPI spec: %w", err)
+	}
+
+	var buf bytes.Buffer
+
+	if s.Info.Title != "" {
+		fmt.Fprintf(&buf, "<h1>%s</h1>\n", html.EscapeString(s.Info.Title))
+	}
+
+	meta := "AsyncAPI " + s.AsyncAPI
+	if s.Info.Version != "" {
+		meta = "Version " + s.Info.Version + " · " + meta
+	}
+
+	fmt.Fprintf(&buf, "<p><em>%s</em></p>\n", html.EscapeString(meta))
+	mdwrite.WriteParagraphs(&buf, s.Info.Description)
+
+	sections := s.sections()
+	headings := make([]core.Heading, 0, len(sections))
+
+	for _, sec := range sections {
+		headings = append(headings, sec.heading)
+
+		fmt.Fprintf(&buf, "<h%d id=\"%s\">%s</h%d>\n", sec.heading.Level, html.EscapeString(sec.heading.ID), html.EscapeString(sec.heading.Text), sec.heading.Level)
+
+		if sec.summary != "" {
+			fmt.Fprintf(&buf, "<p><strong>%s</strong></p>\n", html.EscapeString(sec.summary))
+		}
+
+		mdwrite.WriteParagraphs(&buf, sec.description)
+
+		if len(sec.messages) > 0 {
+			buf.WriteString("<p>Messages: ")
+
+			for i, m := range sec.messages {
+				if i > 0 {
+					buf.WriteString(", ")
+				}
+
+				fmt.Fprintf(&buf, "<code>%s</code>", html.EscapeString(m))
+			}
+
+			buf.WriteString("</p>\n")
+		}
+
+		if sec.heading.ID == serversAnchor {
+			writeServers(&buf, s.Servers)
+		}
+	}
+
+	return buf.Bytes(), headings, nil
+}
+
+// ExtractTitle returns the API title from the AsyncAPI info section.
+// Falls back to an empty string if the spec cannot be parsed or has no title.
+func (p *Processor) ExtractTitle(src []byte) string {
+	s, err := parseSpec(src)
+	if err != nil {
+		return ""
+	}
+
+	return s.Info.Title
+}
+
+// ExtractSummary returns the first paragraph of the API description from the AsyncAPI
+// info section. It returns an empty string if the spec cannot be parsed or has no description.
+func (p *Processor) ExtractSummary(src []byte) string {
+	s, err := parseSpec(src)
+	if err != nil {
+		return ""
+	}
+
+	para, _, _ := strings.Cut(strings.TrimSpace(s.Info.Description), "\n\n")
+
+	return strings.TrimSpace(para)
+}
+
+// ToPlainText extracts searchable plain text from an AsyncAPI spec: the API title and
+// description, then each section's heading line followed by its summary, description and
+// message names. Sections are emitted in the same order as ExtractHeadings returns them,
+// enabling accurate fragment-to-anchor mapping during search result deep-linking.
+func (p *Processor) ToPlainText(src []byte) string {
+	s, err := parseSpec(src)
+	if err != nil {
+		return ""
+	}
+
+	var buf bytes.Buffer
+
+	mdwrite.WriteLine(&buf, s.Info.Title)
+	mdwrite.WriteLine(&buf, s.Info.Description)
+
+	for _, sec := range s.sections() {
+		mdwrite.WriteLine(&buf, sec.heading.Text)
+
+		if sec.heading.ID == serversAnchor {
+			for _, name := range mdwrite.SortedKeys(s.Servers) {
+				srv := s.Servers[name]
+				mdwrite.WriteLine(&buf, strings.TrimSpace(name+" "+srv.address()+" "+srv.Protocol))
+				mdwrite.WriteLine(&buf, srv.Description)
+			}
+		}
+
+		mdwrite.WriteLine(&buf, sec.summary)
+		mdwrite.WriteLine(&buf, sec.description)
+		mdwrite.WriteLine(&buf, strings.Join(sec.messages, " "))
+	}
+
+	return strings.TrimSpace(buf.String())
+}
+
+// ExtractHeadings returns the headings of the rendered spec: "Servers", one per channel
+// and one per operation, with the anchor IDs RenderHTML gives them.
+func (p *Processor) ExtractHeadings(src []byte) []core.Heading {
+	s, err := parseSpec(src)
+	if err != nil {
+		return nil
+	}
+
+	sections := s.sections()
+	if len(sections) == 0 {
+		return nil
+	}
+
+	headings := make([]core.Heading, 0, len(sections))
+	for _, sec := range sections {
+		headings = append(headings, sec.heading)
+	}
+
+	return headings
+}
+
+// ExtractCodeBlocks returns nil: AsyncAPI specs have no fenced code blocks to index
+// for code search.
+func (p *Processor) ExtractCodeBlocks(_ []byte) []core.CodeBlock {
+	return nil
+}
+
+// serversAnchor is the anchor ID of the servers section.
+const serversAnchor = "servers"
+
+// sections returns the servers section, when the spec has servers, followed by each
+// channel (sorted by name) and its operations. AsyncAPI 3.x operations are placed under
+// the channel they reference; operations of an unknown channel come last.
+func (s *spec) sections() []section {
+	var sections []section
+
+	if len(s.Servers) > 0 {
+		sections = append(sections, section{heading: core.Heading{ID: serversAnchor, Text: "Servers", Level: 2}})
+	}
+
+	ids := mdwrite.NewAnchorSet()
+
+	// AsyncAPI 3.x operations grouped by the channel they reference.
+	byChannel := make(map[string][]string)
+
+	var orphans []string
+
+	for _, id := range mdwrite.SortedKeys(s.Operations) {
+		ch := strings.TrimPrefix(s.Operations[id].Channel.Ref, "#/channels/")
+		if _, ok := s.Channels[ch]; ok {
+			byChannel[ch] = append(byChannel[ch], id)
+		} else {
+			orphans = append(orphans, id)
+		}
+	}
+
+	for _, name := range mdwrite.SortedKeys(s.Channels) {
+		ch := s.Channels[name]
+
+		text := name
+		if ch.Address != "" {
+			text = ch.Address
+		}
+
+		sec := section{
+			heading:     core.Heading{ID: ids.Unique("channel-" + mdwrite.Slug(name)), Text: text, Level: 2},
+			summary:     mdwrite.FirstNonEmpty(ch.Summary, ch.Title),
+			description: ch.Description,
+		}
+
+		for _, id := range mdwrite.SortedKeys(ch.Messages) {
+			sec.messages = append(sec.messages, ch.Messages[id].names(id)...)
+		}
+
+		sections = append(sections, sec)
+
+		if ch.Publish != nil {
+			sections = append(sections, ch.Publish.section(ids, "publish", text))
+		}
+
+		if ch.Subscribe != nil {
+			sections = append(sections, ch.Subscribe.section(ids, "subscribe", text))
+		}
+
+		for _, id := range byChannel[name] {
+			op := s.Operations[id]
+			op.OperationID = mdwrite.FirstNonEmpty(op.OperationID, id)
+			sections = append(sections, op.section(ids, op.Action, text))
+		}
+	}
+
+	for _, id := range orphans {
+		op := s.Operations[id]
+		op.OperationID = mdwrite.FirstNonEmpty(op.OperationID, id)
+		sections = append(sections, op.section(ids, op.Action, strings.TrimPrefix(op.Channel.Ref, "#/channels/")))
+	}
+
+	return sections
+}
+
+// section returns the section of an operation performing action on the channel shown as
+// channelText. The heading reads "{ACTION} {operationId}", or "{ACTION} {channel}" for an
+// operation without an ID.
+func (op *operation) section(ids mdwrite.AnchorSet, action, channelText string) section {
+	action = strings.ToUpper(action)
+	name := mdwrite.FirstNonEmpty(op.OperationID, channelText)
+
+	sec := section{
+		heading:     core.Heading{ID: ids.Unique("operation-" + mdwrite.Slug(action+" "+name)), Text: strings.TrimSpace(action + " " + name), Level: 3},
+		summary:     mdwrite.FirstNonEmpty(op.Summary, op.Title),
+		description: op.Description,
+	}
+
+	if op.Message != nil {
+		sec.messages = op.Message.names("")
+	}
+
+	for _, m := range op.Messages {
+		sec.messages = append(sec.messages, m.names("")...)
+	}
+
+	return sec
+}
+
+// names returns the names of a message, or of each message of a oneOf, falling back to
+// the last element of its $ref and then to fallback.
+func (m message) names(fallback string) []string {
+	if len(m.OneOf) > 0 {
+		var names []string
+		for _, alt := range m.OneOf {
+			names = append(names, alt.names("")...)
+		}
+
+		return names
+	}
+
+	name := mdwrite.FirstNonEmpty(m.Name, m.Title)
+	if name == "" && m.Ref != "" {
+		name = m.Ref[strings.LastIndex(m.Ref, "/")+1:]
+	}
+
+	if name = mdwrite.FirstNonEmpty(name, fallback); name == "" {
+		return nil
+	}
+
+	return []string{name}
+}
+
+// address returns where the server is reached: its host, or its URL in AsyncAPI 2.x.
+func (s *server) address() string {
+	return mdwrite.FirstNonEmpty(s.Host, s.URL)
+}
+
+// parseSpec parses an AsyncAPI spec from raw bytes (YAML or JSON, which YAML parsing
+// accepts). Only the fields the processor uses are decoded; the spec is not validated.
+func parseSpec(src []byte) (*spec, error) {
+	var s spec
+
+	if err := yaml.Unmarshal(src, &s); err != nil {
+		return nil, fmt.Errorf("failed to load AsyncAPI spec: %w", err)
+	}
+
+	if s.AsyncAPI == "" {
+		return nil, errNotAsyncAPI
+	}
+
+	return &s, nil
+}
+
+// writeServers writes the servers of the spec, sorted by name, as a list.
+func writeServers(buf *bytes.Buffer, servers map[string]server) {
+	buf.WriteString("<ul>\n")
+
+	for _, name := range mdwrite.SortedKeys(servers) {
+		srv := servers[name]
+
+		fmt.Fprintf(buf, "<li><strong>%s</strong>", html.EscapeString(name))
+
+		if addr := srv.address(); addr != "" {
+			fmt.Fprintf(buf, " <code>%s</code>", html.EscapeString(addr))
+		}
+
+		if srv.Protocol != "" {
+			fmt.Fprintf(buf, " (%s)", html.EscapeString(srv.Protocol))
+		}
+
+		if srv.Description != "" {
+			fmt.Fprintf(buf, " — %s", html.EscapeString(srv.Description))
+		}
+
+		buf.WriteString("</li>\n")
+	}
+
+	buf.WriteString("</ul>\n")
+}
@@ -0,0 +1,158 @@
+package asyncapi
+
+import (
+	"testing"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const v2SpecYAML = `asyncapi: 2.6.0
+info:
+  title: Account Service
+  version: 1.0.0
+  description: |
+    Publishes account lifecycle events.
+
+    Consumers must be idempotent.
+servers:
+  production:
+    url: broker.example.com:9092
+    protocol: kafka
+    description: Production cluster
+channels:
+  user/signedup:
+    description: A user created an account.
+    subscribe:
+      operationId: onUserSignedUp
+      summary: Receive sign-up events
+      message:
+        $ref: '#/components/messages/UserSignedUp'
+  user/deleted:
+    publish:
+      message:
+        oneOf:
+          - name: UserDeleted
+          - name: UserPurged
+`
+
+const v3SpecJSON = `{
+  "asyncapi": "3.0.0",
+  "info": {"title": "Orders", "version": "2.1.0"},
+  "channels": {
+    "orderCreated": {
+      "address": "orders.created",
+      "summary": "New orders",
+      "messages": {"order": {"name": "OrderCreated"}}
+    }
+  },
+  "operations": {
+    "sendOrderCreated": {
+      "action": "send",
+      "channel": {"$ref": "#/channels/orderCreated"},
+      "description": "Emitted after checkout <b>completes</b>."
+    },
+    "auditOrders": {
+      "action": "receive",
+      "channel": {"$ref": "#/channels/audit"}
+    }
+  }
+}`
+
+func TestProcessor_ExtractTitleAndSummary(t *testing.T) {
+	p := New()
+
+	assert.Equal(t, "Account Service", p.ExtractTitle([]byte(v2SpecYAML)))
+	assert.Equal(t, "Publishes account lifecycle events.", p.ExtractSummary([]byte(v2SpecYAML)))
+	assert.Equal(t, "Orders", p.ExtractTitle([]byte(v3SpecJSON)))
+	assert.Empty(t, p.ExtractSummary([]byte(v3SpecJSON)))
+	assert.Empty(t, p.ExtractTitle([]byte("openapi: 3.0.0\ninfo:\n  title: REST\n")), "documents without an asyncapi version are not AsyncAPI specs")
+}
+
+func TestProcessor_ExtractHeadings_V2(t *testing.T) {
+	headings := New().ExtractHeadings([]byte(v2SpecYAML))
+
+	assert.Equal(t, []core.Heading{
+		{ID: "servers", Text: "Servers", Level: 2},
+		{ID: "channel-user-deleted", Text: "user/deleted", Level: 2},
+		{ID: "operation-publish-user-deleted", Text: "PUBLISH user/deleted", Level: 3},
+		{ID: "channel-user-signedup", Text: "user/signedup", Level: 2},
+		{ID: "operation-subscribe-onusersignedup", Text: "SUBSCRIBE onUserSignedUp", Level: 3},
+	}, headings)
+}
+
+func TestProcessor_ExtractHeadings_V3(t *testing.T) {
+	headings := New().ExtractHeadings([]byte(v3SpecJSON))
+
+	assert.Equal(t, []core.Heading{
+		{ID: "channel-ordercreated", Text: "orders.created", Level: 2},
+		{ID: "operation-send-sendordercreated", Text: "SEND sendOrderCreated", Level: 3},
+		{ID: "operation-receive-auditorders", Text: "RECEIVE auditOrders", Level: 3},
+	}, headings, "operations of an unknown channel come last")
+}
+
+func TestProcessor_ToPlainText(t *testing.T) {
+	p := New()
+
+	text := p.ToPlainText([]byte(v2SpecYAML))
+	assert.Contains(t, text, "production broker.example.com:9092 kafka")
+	assert.Contains(t, text, "Receive sign-up events")
+	assert.Contains(t, text, "UserDeleted UserPurged")
+	assert.Contains(t, text, "UserSignedUp")
+
+	// Headings appear in the plain text in the order they are returned.
+	pos := 0
+
+	for _, h := range p.ExtractHeadings([]byte(v2SpecYAML)) {
+		idx := indexFrom(text, h.Text, pos)
+		require.GreaterOrEqual(t, idx, 0, "heading %q", h.Text)
+
+		pos = idx + len(h.Text)
+	}
+
+	assert.Empty(t, p.ToPlainText([]byte("not: a spec")))
+}
+
+func TestProcessor_RenderHTML(t *testing.T) {
+	out, headings, err := New().RenderHTML([]byte(v3SpecJSON))
+	require.NoError(t, err)
+
+	html := string(out)
+	assert.Contains(t, html, "<h1>Orders</h1>")
+	assert.Contains(t, html, "<p><em>Version 2.1.0 · AsyncAPI 3.0.0</em></p>")
+	assert.Contains(t, html, `<h2 id="channel-ordercreated">orders.created</h2>`)
+	assert.Contains(t, html, `<h3 id="operation-send-sendordercreated">SEND sendOrderCreated</h3>`)
+	assert.Contains(t, html, "<code>OrderCreated</code>")
+	assert.Contains(t, html, "Emitted after checkout &lt;b&gt;completes&lt;/b&gt;.", "values from the spec are escaped")
+	assert.Len(t, headings, 3)
+
+	_, _, err = New().RenderHTML([]byte("asyncapi: [unterminated"))
+	assert.Error(t, err)
+}
+
+func TestProcessor_DuplicateAnchors(t *testing.T) {
+	src := `asyncapi: 2.6.0
+channels:
+  a.b:
+    publish: {}
+  a/b:
+    publish: {}
+`
+
+	headings := New().ExtractHeadings([]byte(src))
+	require.Len(t, headings, 4)
+	assert.Equal(t, "channel-a-b", headings[0].ID)
+	assert.Equal(t, "channel-a-b-1", headings[2].ID)
+	assert.Equal(t, "operation-publish-a-b-1", headings[3].ID)
+}
+
+func indexFrom(s, substr string, from int) int {
+	for i := from; i+len(substr) <= len(s); i++ {
+		if s[i:i+len(substr)] == substr {
+			return i
+		}
+	}
+
+	return -1
+}
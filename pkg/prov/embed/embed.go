@@ -0,0 +1,224 @@
+// Package embed generates text embeddings for semantic search with a model served through
+// an OpenAI-compatible embeddings API or by Ollama.
+package embed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// ProviderOpenAI selects an OpenAI-compatible embeddings API, such as OpenAI's or one
+	// served by llama.cpp, LocalAI or vLLM for local models.
+	ProviderOpenAI = "openai"
+	// ProviderOllama selects the embeddings API of an Ollama server.
+	ProviderOllama = "ollama"
+
+	defaultOpenAIURL = "https://api.openai.com/v1"
+	defaultOllamaURL = "http://localhost:11434"
+
+	requestTimeout = 60 * time.Second
+	// defaultMaxInput is the default number of bytes of text embedded per document.
+	defaultMaxInput = 8000
+	// maxErrorBody bounds the part of an error response included in the returned error.
+	maxErrorBody = 512
+)
+
+// Config configures the embedding model. Semantic search is enabled when Provider and
+// Model are set.
+type Config struct {
+	// Provider is "openai" for an OpenAI-compatible API or "ollama".
+	Provider string `mapstructure:"provider"`
+	// URL is the base URL of the API (default https://api.openai.com/v1 for openai and
+	// http://localhost:11434 for ollama).
+	URL string `mapstructure:"url"`
+	// Model is the embedding model, e.g. text-embedding-3-small or nomic-embed-text.
+	Model string `mapstructure:"model"`
+	// APIKey is sent as a bearer token when set.
+	APIKey string `mapstructure:"api_key" redact:"true"`
+	// Dimensions is the length of the vectors. Models that support shortening their
+	// embeddings are asked for this length; other models must produce it.
+	Dimensions int `mapstructure:"dimensions"`
+	// MaxInput caps the bytes of text embedded per document (default 8000).
+	MaxInput int `mapstructure:"max_input"`
+}
+
+// Enabled reports whether an embedding model is configured.
+func (c Config) Enabled() bool {
+	return c.Provider != "" && c.Model != ""
+}
+
+// Embedder implements core.Embedder with an embeddings API.
+type Embedder struct {
+	httpClient *http.Client
+	cfg        Config
+}
+
+// New creates an Embedder for the given configuration. It returns an error if the
+// provider is unknown.
+func New(cfg Config) (*Embedder, error) {
+	switch cfg.Provider {
+	case ProviderOpenAI:
+		if cfg.URL == "" {
+			cfg.URL = defaultOpenAIURL
+		}
+	case ProviderOllama:
+		if cfg.URL == "" {
+			cfg.URL = defaultOllamaURL
+		}
+	default:
+		return nil, fmt.Errorf("unknown embedding provider %q: must be %q or %q", cfg.Provider, ProviderOpenAI, ProviderOllama)
+	}
+
+	if cfg.MaxInput <= 0 {
+		cfg.MaxInput = defaultMaxInput
+	}
+
+	cfg.URL = strings.TrimSuffix(cfg.URL, "/")
+
+	return &Embedder{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: requestTimeout},
+	}, nil
+}
+
+// Model identifies the model and vector length, so that vectors produced by another model
+// are not compared with this model's.
+func (e *Embedder) Model() string {
+	return fmt.Sprintf("%s/%s/%d", e.cfg.Provider, e.cfg.Model, e.cfg.Dimensions)
+}
+
+// embedRequest is the body of an embedding request, which both providers accept.
+type embedRequest struct {
+	Model      string   `json:"model"`
+	Input      []string `json:"input"`
+	Dimensions int      `json:"dimensions,omitempty"`
+}
+
+type openAIResponse struct {
+	Data []struct {
+		Embedding []float32 `json:"embedding"`
+		Index     int       `json:"index"`
+	} `json:"data"`
+}
+
+type ollamaResponse struct {
+	Embeddings [][]float32 `json:"embeddings"`
+}
+
+// Embed returns the embeddings of the given texts, in order. Texts longer than the
+// configured maximum input are truncated.
+func (e *Embedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	input := make([]string, len(texts))
+	for i, t := range texts {
+		if len(t) > e.cfg.MaxInput {
+			t = strings.ToValidUTF8(t[:e.cfg.MaxInput], "")
+		}
+
+		input[i] = t
+	}
+
+	var (
+		vectors [][]float32
+		err     error
+	)
+
+	if e.cfg.Provider == ProviderOllama {
+		vectors, err = e.embedOllama(ctx, input)
+	} else {
+		vectors, err = e.embedOpenAI(ctx, input)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("embedding API returned %d embeddings for %d texts", len(vectors), len(texts))
+	}
+
+	for _, v := range vectors {
+		if e.cfg.Dimensions > 0 && len(v) != e.cfg.Dimensions {
+			return nil, fmt.Errorf("model %s returned %d dimensions, configured %d", e.cfg.Model, len(v), e.cfg.Dimensions)
+		}
+	}
+
+	return vectors, nil
+}
+
+// embedOpenAI calls the embeddings endpoint of an OpenAI-compatible API.
+func (e *Embedder) embedOpenAI(ctx context.Context, input []string) ([][]float32, error) {
+	var resp openAIResponse
+
+	req := embedRequest{Model: e.cfg.Model, Input: input, Dimensions: e.cfg.Dimensions}
+	if err := e.post(ctx, "/embeddings", req, &resp); err != nil {
+		return nil, err
+	}
+
+	vectors := make([][]float32, len(resp.Data))
+
+	for _, d := range resp.Data {
+		if d.Index < 0 || d.Index >= len(vectors) {
+			return nil, fmt.Errorf("embedding API returned embedding %d of %d", d.Index, len(vectors))
+		}
+
+		vectors[d.Index] = d.Embedding
+	}
+
+	return vectors, nil
+}
+
+// embedOllama calls the embed endpoint of an Ollama server.
+func (e *Embedder) embedOllama(ctx context.Context, input []string) ([][]float32, error) {
+	var resp ollamaResponse
+
+	req := embedRequest{Model: e.cfg.Model, Input: input, Dimensions: e.cfg.Dimensions}
+	if err := e.post(ctx, "/api/embed", req, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Embeddings, nil
+}
+
+// post sends in as JSON to endpoint and decodes the response into out.
+func (e *Embedder) post(ctx context.Context, endpoint string, in, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("failed to marshal embedding request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.cfg.URL+endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create embedding request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if e.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.cfg.APIKey)
+	}
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send embedding request: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("embedding API returned HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode embedding response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package embed
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Enabled(t *testing.T) {
+	assert.False(t, Config{}.Enabled())
+	assert.False(t, Config{Provider: ProviderOpenAI}.Enabled())
+	assert.True(t, Config{Provider: ProviderOllama, Model: "nomic-embed-text"}.Enabled())
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{Provider: "cohere", Model: "embed"})
+	assert.ErrorContains(t, err, `unknown embedding provider "cohere"`)
+
+	e, err := New(Config{Provider: ProviderOllama, Model: "nomic-embed-text"})
+	require.NoError(t, err)
+	assert.Equal(t, defaultOllamaURL, e.cfg.URL)
+	assert.Equal(t, "ollama/nomic-embed-text/0", e.Model())
+}
+
+func TestEmbedder_EmbedOpenAI(t *testing.T) {
+	var got embedRequest
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/embeddings", r.URL.Path)
+		assert.Equal(t, "Bearer sk-test", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+
+		if got.Model == "broken" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error":"rate limited"}`))
+
+			return
+		}
+
+		// Embeddings may be returned out of order; index identifies their input.
+		_, _ = w.Write([]byte(`{"data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}]}`))
+	}))
+	defer srv.Close()
+
+	e, err := New(Config{Provider: ProviderOpenAI, URL: srv.URL + "/v1/", Model: "small", APIKey: "sk-test", Dimensions: 2, MaxInput: 5})
+	require.NoError(t, err)
+
+	vectors, err := e.Embed(t.Context(), []string{"first document", "second"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float32{{1, 0}, {0, 1}}, vectors)
+	assert.Equal(t, embedRequest{Model: "small", Input: []string{"first", "secon"}, Dimensions: 2}, got, "texts are capped at MaxInput")
+
+	e3, _ := New(Config{Provider: ProviderOpenAI, URL: srv.URL + "/v1", Model: "small", APIKey: "sk-test", Dimensions: 3})
+	_, err = e3.Embed(t.Context(), []string{"a", "b"})
+	assert.ErrorContains(t, err, "returned 2 dimensions, configured 3")
+
+	broken, _ := New(Config{Provider: ProviderOpenAI, URL: srv.URL + "/v1", Model: "broken", APIKey: "sk-test"})
+	_, err = broken.Embed(t.Context(), []string{"a"})
+	assert.ErrorContains(t, err, "embedding API returned HTTP 429: {\"error\":\"rate limited\"}")
+}
+
+func TestEmbedder_EmbedOllama(t *testing.T) {
+	var got embedRequest
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/embed", r.URL.Path)
+		assert.Empty(t, r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+
+		_, _ = w.Write([]byte(`{"embeddings":[[0.5,0.5,0]]}`))
+	}))
+	defer srv.Close()
+
+	e, err := New(Config{Provider: ProviderOllama, URL: srv.URL, Model: "nomic-embed-text"})
+	require.NoError(t, err)
+
+	vectors, err := e.Embed(t.Context(), []string{"How do I deploy?"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float32{{0.5, 0.5, 0}}, vectors)
+	assert.Equal(t, "nomic-embed-text", got.Model)
+
+	_, err = e.Embed(t.Context(), []string{"one", "two"})
+	assert.ErrorContains(t, err, "returned 1 embeddings for 2 texts")
+
+}
@@ -0,0 +1,150 @@
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+// Committer commits documents edited in the portal to their GitHub repository through the
+// contents API and proposes edits suggested by readers as pull requests.
+type Committer struct {
+	httpClient *http.Client
+	cfg        Config
+}
+
+// NewCommitter creates a Committer for the given configuration.
+func NewCommitter(cfg Config) *Committer {
+	return &Committer{
+		cfg:        cfg.withDefaults(),
+		httpClient: &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// CommitFile commits a file to the GitHub repository of the commit's repo, which for a
+// monorepo project is owner/repo. The file is replaced as of fc.BaseRef, so GitHub rejects
+// the commit, and CommitFile returns an error wrapping core.ErrConflict, if the file changed
+// on the branch since. It returns an error wrapping core.ErrNotFound if the repository or
+// branch does not exist or the token cannot access it.
+func (c *Committer) CommitFile(ctx context.Context, fc core.FileCommit) (string, error) { //nolint:gocritic // FileCommit is passed by value like the Committer interface
+	segments := strings.SplitN(fc.Repo, "/", 3)
+	if len(segments) < 2 {
+		return "", fmt.Errorf("%w: repo must be of the form owner/repo: %q", core.ErrInvalidPath, fc.Repo)
+	}
+
+	endpoint := "/repos/" + segments[0] + "/" + segments[1] + "/contents/" + escapePath(fc.Path)
+
+	ref := fc.BaseRef
+	if ref == "" {
+		ref = fc.Branch
+	}
+
+	sha, err := c.blobSHA(ctx, endpoint, ref)
+	if err != nil {
+		return "", err
+	}
+
+	payload := map[string]string{
+		"message": fc.Message,
+		"content": base64.StdEncoding.EncodeToString([]byte(fc.Content)),
+	}
+
+	if sha != "" {
+		payload["sha"] = sha
+	}
+
+	if fc.Branch != "" {
+		payload["branch"] = fc.Branch
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal commit: %w", err)
+	}
+
+	req, err := c.cfg.newRequest(ctx, http.MethodPut, endpoint, body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create commit request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send commit request: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusConflict:
+		return "", fmt.Errorf("%w: %s changed in %s/%s since it was published", core.ErrConflict, fc.Path, segments[0], segments[1])
+	case resp.StatusCode == http.StatusNotFound:
+		return "", fmt.Errorf("%w: GitHub repository %s/%s", core.ErrNotFound, segments[0], segments[1])
+	case resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices:
+		return "", responseError(resp)
+	}
+
+	var result struct {
+		Commit struct {
+			SHA string `json:"sha"`
+		} `json:"commit"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode commit response: %w", err)
+	}
+
+	return result.Commit.SHA, nil
+}
+
+// blobSHA returns the blob SHA of the file at endpoint as of ref, or an empty string if the
+// file does not exist.
+func (c *Committer) blobSHA(ctx context.Context, endpoint, ref string) (string, error) {
+	if ref != "" {
+		endpoint += "?ref=" + url.QueryEscape(ref)
+	}
+
+	req, err := c.cfg.newRequest(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create contents request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send contents request: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return "", nil
+	case resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices:
+		return "", responseError(resp)
+	}
+
+	var file struct {
+		SHA string `json:"sha"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&file); err != nil {
+		return "", fmt.Errorf("failed to decode contents response: %w", err)
+	}
+
+	return file.SHA, nil
+}
+
+// escapePath escapes each segment of a repository file path for use in a URL.
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,112 @@
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitter_CommitFile(t *testing.T) {
+	var (
+		gotRef string
+		got    map[string]string
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer ghp_token", r.Header.Get("Authorization"))
+		assert.Equal(t, "/repos/owner/wiki/contents/docs/c%23%20notes/guide.md", r.URL.EscapedPath())
+
+		switch r.Method {
+		case http.MethodGet:
+			gotRef = r.URL.Query().Get("ref")
+			_, _ = w.Write([]byte(`{"sha":"blob1","path":"docs/c# notes/guide.md"}`))
+		case http.MethodPut:
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"content":{"sha":"blob2"},"commit":{"sha":"commit2"}}`))
+		}
+	}))
+	defer srv.Close()
+
+	c := NewCommitter(Config{Token: "ghp_token", APIURL: srv.URL + "/"})
+
+	sha, err := c.CommitFile(t.Context(), core.FileCommit{
+		Repo:    "owner/wiki/handbook",
+		Branch:  "main",
+		BaseRef: "commit1",
+		Path:    "docs/c# notes/guide.md",
+		Message: "Update guide.md",
+		Content: "# Guide",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "commit2", sha)
+	assert.Equal(t, "commit1", gotRef, "the file is replaced as of the published commit")
+	assert.Equal(t, map[string]string{
+		"message": "Update guide.md",
+		"content": "IyBHdWlkZQ==",
+		"sha":     "blob1",
+		"branch":  "main",
+	}, got)
+}
+
+func TestCommitter_CommitFileNew(t *testing.T) {
+	var got map[string]string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			assert.Empty(t, r.URL.Query().Get("ref"))
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"commit":{"sha":"commit1"}}`))
+	}))
+	defer srv.Close()
+
+	sha, err := NewCommitter(Config{Token: "ghp_token", APIURL: srv.URL}).CommitFile(t.Context(), core.FileCommit{
+		Repo: "owner/wiki", Path: "new.md", Message: "Add new.md", Content: "# New",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "commit1", sha)
+	assert.NotContains(t, got, "sha")
+	assert.NotContains(t, got, "branch")
+}
+
+func TestCommitter_CommitFileErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(`{"sha":"blob1"}`))
+			return
+		}
+
+		switch r.URL.Path {
+		case "/repos/owner/changed/contents/guide.md":
+			w.WriteHeader(http.StatusConflict)
+		case "/repos/owner/missing/contents/guide.md":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"message":"Resource not accessible by personal access token"}`))
+		}
+	}))
+	defer srv.Close()
+
+	c := NewCommitter(Config{Token: "ghp_token", APIURL: srv.URL})
+	commit := func(repo string) error {
+		_, err := c.CommitFile(t.Context(), core.FileCommit{Repo: repo, Path: "guide.md", Content: "# Guide"})
+		return err
+	}
+
+	assert.ErrorIs(t, commit("owner/changed"), core.ErrConflict)
+	assert.ErrorIs(t, commit("owner/missing"), core.ErrNotFound)
+	assert.ErrorContains(t, commit("owner/locked"), "HTTP 403: {\"message\":\"Resource not accessible by personal access token\"}")
+	assert.ErrorIs(t, commit("owner"), core.ErrInvalidPath)
+}
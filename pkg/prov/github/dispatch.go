@@ -0,0 +1,147 @@
+// Package github triggers documentation republishes, commits documents edited in the
+// portal and opens pull requests for suggested edits through the GitHub API.
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+const (
+	defaultAPIURL    = "https://api.github.com"
+	defaultEventType = "omnidex-republish"
+	requestTimeout   = 10 * time.Second
+	// maxErrorBody bounds the part of an error response included in the returned error.
+	maxErrorBody = 512
+)
+
+// Config configures republish triggers. A republish sends a repository_dispatch event to
+// the repository, which runs every workflow listening for the event type, such as the
+// workflow that publishes the documentation.
+type Config struct {
+	// Token is a GitHub token allowed to create dispatch events in the repositories, for
+	// example a fine-grained token with the Contents write permission, which also allows
+	// committing documents edited in the portal. Republish is enabled when it is set.
+	Token string `mapstructure:"token" redact:"true"`
+	// EventType is the repository_dispatch event type sent (default omnidex-republish).
+	EventType string `mapstructure:"event_type"`
+	// APIURL is the GitHub API base URL, for GitHub Enterprise Server (default https://api.github.com).
+	APIURL string `mapstructure:"api_url"`
+}
+
+// Enabled reports whether republish triggers are configured.
+func (c Config) Enabled() bool {
+	return c.Token != ""
+}
+
+// Dispatcher triggers republishes with repository_dispatch events.
+type Dispatcher struct {
+	httpClient *http.Client
+	cfg        Config
+}
+
+// New creates a Dispatcher for the given configuration.
+func New(cfg Config) *Dispatcher {
+	return &Dispatcher{
+		cfg:        cfg.withDefaults(),
+		httpClient: &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// withDefaults returns the configuration with defaults applied.
+func (c Config) withDefaults() Config {
+	if c.EventType == "" {
+		c.EventType = defaultEventType
+	}
+
+	if c.APIURL == "" {
+		c.APIURL = defaultAPIURL
+	}
+
+	c.APIURL = strings.TrimSuffix(c.APIURL, "/")
+
+	return c
+}
+
+// newRequest creates a GitHub API request authenticated with the configured token.
+func (c Config) newRequest(ctx context.Context, method, endpoint string, body []byte) (*http.Request, error) {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.APIURL+endpoint, r)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return req, nil
+}
+
+// responseError returns an error describing an unsuccessful response.
+func responseError(resp *http.Response) error {
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	return fmt.Errorf("GitHub returned HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+}
+
+// Republish sends a repository_dispatch event to the GitHub repository of repo. For a
+// monorepo project, given as owner/repo/project, the event is sent to owner/repo with the
+// project in the client payload, so the workflow can publish just that project. It returns
+// an error wrapping core.ErrNotFound if the repository does not exist or the token cannot
+// access it.
+func (d *Dispatcher) Republish(ctx context.Context, repo string) error {
+	segments := strings.SplitN(repo, "/", 3)
+	if len(segments) < 2 {
+		return fmt.Errorf("%w: repo must be of the form owner/repo: %q", core.ErrInvalidPath, repo)
+	}
+
+	payload := map[string]string{"repo": repo}
+	if len(segments) == 3 {
+		payload["project"] = segments[2]
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"event_type":     d.cfg.EventType,
+		"client_payload": payload,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal dispatch event: %w", err)
+	}
+
+	req, err := d.cfg.newRequest(ctx, http.MethodPost, "/repos/"+segments[0]+"/"+segments[1]+"/dispatches", body)
+	if err != nil {
+		return fmt.Errorf("failed to create dispatch request: %w", err)
+	}
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send dispatch request: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%w: GitHub repository %s/%s", core.ErrNotFound, segments[0], segments[1])
+	case resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices:
+		return responseError(resp)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Enabled(t *testing.T) {
+	assert.False(t, Config{}.Enabled())
+	assert.True(t, Config{Token: "ghp_token"}.Enabled())
+}
+
+func TestDispatcher_Republish(t *testing.T) {
+	type dispatch struct {
+		ClientPayload map[string]string `json:"client_payload"`
+		EventType     string            `json:"event_type"`
+	}
+
+	var (
+		gotPath string
+		got     dispatch
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "Bearer ghp_token", r.Header.Get("Authorization"))
+
+		gotPath = r.URL.Path
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+
+		switch r.URL.Path {
+		case "/repos/owner/missing/dispatches":
+			w.WriteHeader(http.StatusNotFound)
+		case "/repos/owner/locked/dispatches":
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"message":"Resource not accessible by personal access token"}`))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	d := New(Config{Token: "ghp_token", APIURL: srv.URL + "/"})
+
+	require.NoError(t, d.Republish(t.Context(), "owner/repo"))
+	assert.Equal(t, "/repos/owner/repo/dispatches", gotPath)
+	assert.Equal(t, dispatch{EventType: "omnidex-republish", ClientPayload: map[string]string{"repo": "owner/repo"}}, got)
+
+	got = dispatch{}
+
+	require.NoError(t, New(Config{Token: "ghp_token", APIURL: srv.URL, EventType: "docs"}).Republish(t.Context(), "owner/repo/billing"))
+	assert.Equal(t, "/repos/owner/repo/dispatches", gotPath)
+	assert.Equal(t, dispatch{EventType: "docs", ClientPayload: map[string]string{"repo": "owner/repo/billing", "project": "billing"}}, got)
+
+	assert.ErrorIs(t, d.Republish(t.Context(), "owner/missing"), core.ErrNotFound)
+	assert.ErrorContains(t, d.Republish(t.Context(), "owner/locked"), "GitHub returned HTTP 403: {\"message\":\"Resource not accessible")
+	assert.ErrorIs(t, d.Republish(t.Context(), "owner"), core.ErrInvalidPath)
+}
@@ -0,0 +1,149 @@
+package github
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+// suggestionBranchPrefix prefixes the names of the branches pull requests are opened from.
+const suggestionBranchPrefix = "omnidex/suggestion-"
+
+// OpenPullRequest proposes a change of a file to the GitHub repository of the pull
+// request's repo, which for a monorepo project is owner/repo. The change is committed to a
+// new branch created at pr.BaseRef, or the head of the base branch, and a pull request is
+// opened from it to pr.Base, or the repository's default branch. It returns the pull
+// request's URL, or an error wrapping core.ErrNotFound if the repository or base does not
+// exist or the token cannot access it.
+func (c *Committer) OpenPullRequest(ctx context.Context, pr core.PullRequest) (string, error) { //nolint:gocritic // PullRequest is passed by value like the PullRequester interface
+	segments := strings.SplitN(pr.Repo, "/", 3)
+	if len(segments) < 2 {
+		return "", fmt.Errorf("%w: repo must be of the form owner/repo: %q", core.ErrInvalidPath, pr.Repo)
+	}
+
+	repoPath := "/repos/" + segments[0] + "/" + segments[1]
+
+	base := pr.Base
+	if base == "" {
+		var repo struct {
+			DefaultBranch string `json:"default_branch"`
+		}
+
+		if err := c.call(ctx, http.MethodGet, repoPath, nil, &repo); err != nil {
+			return "", fmt.Errorf("failed to get repository: %w", err)
+		}
+
+		base = repo.DefaultBranch
+	}
+
+	sha := pr.BaseRef
+	if sha == "" {
+		var ref struct {
+			Object struct {
+				SHA string `json:"sha"`
+			} `json:"object"`
+		}
+
+		if err := c.call(ctx, http.MethodGet, repoPath+"/git/ref/heads/"+escapePath(base), nil, &ref); err != nil {
+			return "", fmt.Errorf("failed to get branch %s: %w", base, err)
+		}
+
+		sha = ref.Object.SHA
+	}
+
+	branch, err := newBranchName()
+	if err != nil {
+		return "", err
+	}
+
+	err = c.call(ctx, http.MethodPost, repoPath+"/git/refs", map[string]string{"ref": "refs/heads/" + branch, "sha": sha}, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create branch %s: %w", branch, err)
+	}
+
+	_, err = c.CommitFile(ctx, core.FileCommit{
+		Repo:    pr.Repo,
+		Branch:  branch,
+		BaseRef: sha,
+		Path:    pr.Path,
+		Message: pr.Title,
+		Content: pr.Content,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var pull struct {
+		HTMLURL string `json:"html_url"`
+	}
+
+	err = c.call(ctx, http.MethodPost, repoPath+"/pulls", map[string]string{
+		"title": pr.Title,
+		"head":  branch,
+		"base":  base,
+		"body":  pr.Body,
+	}, &pull)
+	if err != nil {
+		return "", fmt.Errorf("failed to create pull request: %w", err)
+	}
+
+	return pull.HTMLURL, nil
+}
+
+// call sends a GitHub API request with in, if any, as the JSON body and decodes the
+// response into out, if any. It returns an error wrapping core.ErrNotFound for HTTP 404.
+func (c *Committer) call(ctx context.Context, method, endpoint string, in, out any) error {
+	var body []byte
+
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+	}
+
+	req, err := c.cfg.newRequest(ctx, method, endpoint, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%w: GitHub %s", core.ErrNotFound, endpoint)
+	case resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices:
+		return responseError(resp)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// newBranchName returns a unique name for a suggestion branch.
+func newBranchName() (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate branch name: %w", err)
+	}
+
+	return suggestionBranchPrefix + hex.EncodeToString(b), nil
+}
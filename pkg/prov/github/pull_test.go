@@ -0,0 +1,121 @@
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitter_OpenPullRequest(t *testing.T) {
+	var ref, commit, pull map[string]string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer ghp_token", r.Header.Get("Authorization"))
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/owner/wiki":
+			_, _ = w.Write([]byte(`{"default_branch":"trunk"}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/owner/wiki/git/refs":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&ref))
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/owner/wiki/contents/docs/guide.md":
+			assert.Equal(t, "commit1", r.URL.Query().Get("ref"))
+			_, _ = w.Write([]byte(`{"sha":"blob1"}`))
+		case r.Method == http.MethodPut && r.URL.Path == "/repos/owner/wiki/contents/docs/guide.md":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&commit))
+			_, _ = w.Write([]byte(`{"commit":{"sha":"commit2"}}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/owner/wiki/pulls":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&pull))
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"html_url":"https://github.com/owner/wiki/pull/7"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	url, err := NewCommitter(Config{Token: "ghp_token", APIURL: srv.URL}).OpenPullRequest(t.Context(), core.PullRequest{
+		Repo:    "owner/wiki",
+		BaseRef: "commit1",
+		Path:    "docs/guide.md",
+		Title:   "Fix typo",
+		Body:    "Suggested by Alex in the documentation portal.",
+		Content: "# Guide",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/owner/wiki/pull/7", url)
+
+	assert.Equal(t, "commit1", ref["sha"], "the branch starts at the published commit")
+	assert.True(t, strings.HasPrefix(ref["ref"], "refs/heads/"+suggestionBranchPrefix))
+
+	branch := strings.TrimPrefix(ref["ref"], "refs/heads/")
+	assert.Equal(t, branch, commit["branch"])
+	assert.Equal(t, "blob1", commit["sha"])
+	assert.Equal(t, "Fix typo", commit["message"])
+
+	assert.Equal(t, map[string]string{
+		"title": "Fix typo",
+		"head":  branch,
+		"base":  "trunk",
+		"body":  "Suggested by Alex in the documentation portal.",
+	}, pull)
+}
+
+func TestCommitter_OpenPullRequestBranchHead(t *testing.T) {
+	var ref map[string]string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/owner/wiki/git/ref/heads/main":
+			_, _ = w.Write([]byte(`{"object":{"sha":"head1"}}`))
+		case r.URL.Path == "/repos/owner/wiki/git/refs":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&ref))
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodGet:
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodPut:
+			_, _ = w.Write([]byte(`{"commit":{"sha":"commit2"}}`))
+		default:
+			_, _ = w.Write([]byte(`{"html_url":"https://github.com/owner/wiki/pull/8"}`))
+		}
+	}))
+	defer srv.Close()
+
+	url, err := NewCommitter(Config{Token: "ghp_token", APIURL: srv.URL}).OpenPullRequest(t.Context(), core.PullRequest{
+		Repo: "owner/wiki", Base: "main", Path: "new.md", Title: "Add new.md", Content: "# New",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/owner/wiki/pull/8", url)
+	assert.Equal(t, "head1", ref["sha"])
+}
+
+func TestCommitter_OpenPullRequestErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/missing/git/ref/heads/main":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"message":"Object does not exist"}`))
+		}
+	}))
+	defer srv.Close()
+
+	c := NewCommitter(Config{Token: "ghp_token", APIURL: srv.URL})
+	open := func(repo, baseRef string) error {
+		_, err := c.OpenPullRequest(t.Context(), core.PullRequest{Repo: repo, Base: "main", BaseRef: baseRef, Path: "guide.md"})
+		return err
+	}
+
+	assert.ErrorIs(t, open("owner/missing", ""), core.ErrNotFound)
+	assert.ErrorContains(t, open("owner/wiki", "gone"), "HTTP 422: {\"message\":\"Object does not exist\"}")
+	assert.ErrorIs(t, open("owner", ""), core.ErrInvalidPath)
+}
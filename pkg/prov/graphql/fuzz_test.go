@@ -0,0 +1,22 @@
+package graphql
+
+import (
+	"testing"
+)
+
+func FuzzProcessor(f *testing.F) {
+	f.Add([]byte(storeSchema))
+	f.Add([]byte(`type Query { a(b: [[Int!]] = [[1], [2]], c: In = {d: {e: $f}}): A! @x(y: "é") }`))
+	f.Add([]byte(`extend schema { query: Q } "d" extend enum E { A @deprecated(reason: """r""") }`))
+	f.Add([]byte(`union U = A | | B`))
+
+	p := New()
+
+	f.Fuzz(func(_ *testing.T, src []byte) {
+		_, _, _ = p.RenderHTML(src)
+		p.ExtractTitle(src)
+		p.ExtractSummary(src)
+		p.ToPlainText(src)
+		p.ExtractHeadings(src)
+	})
+}
@@ -0,0 +1,767 @@
+package graphql
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ksysoev/omnidex/pkg/prov/internal/mdwrite"
+)
+
+// maxDepth bounds the nesting of list types and values so that hostile input cannot
+// exhaust the stack.
+const maxDepth = 64
+
+// errTooDeep is returned for types or values nested deeper than maxDepth.
+var errTooDeep = errors.New("nesting too deep")
+
+// schema holds the parts of a GraphQL schema the processor indexes and renders.
+type schema struct {
+	types       map[string]*typeDef
+	roots       map[string]string // operation ("query", "mutation", "subscription") to type name
+	description string
+	directives  []*directiveDef
+}
+
+// typeDef is a named type of the schema. Kind is the SDL keyword defining it: "type",
+// "interface", "union", "enum", "input" or "scalar".
+type typeDef struct {
+	kind        string
+	name        string
+	description string
+	interfaces  []string
+	fields      []field     // type, interface and input
+	values      []enumValue // enum
+	members     []string    // union
+}
+
+// field is a field of an object, interface or input type, or an argument of a field or
+// directive.
+type field struct {
+	name         string
+	description  string
+	typ          string // as written in SDL, e.g. "[User!]!"
+	defaultValue string
+	deprecation  string // reason; "No longer supported" when @deprecated has none
+	args         []field
+	deprecated   bool
+}
+
+type enumValue struct {
+	name        string
+	description string
+	deprecation string
+	deprecated  bool
+}
+
+type directiveDef struct {
+	name        string
+	description string
+	args        []field
+	locations   []string
+	repeatable  bool
+}
+
+// defaultDeprecation is the reason of a @deprecated directive without one.
+const defaultDeprecation = "No longer supported"
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokName
+	tokString
+	tokNumber
+	tokPunct
+)
+
+type token struct {
+	value string
+	kind  tokenKind
+	pos   int
+}
+
+// parseSchema parses a GraphQL schema written in the schema definition language. Type
+// extensions are merged into the types they extend. The schema is not validated: types
+// may refer to types that are not defined.
+func parseSchema(src []byte) (*schema, error) {
+	tokens, err := tokenize(string(src))
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{src: string(src), tokens: tokens, s: &schema{types: make(map[string]*typeDef), roots: make(map[string]string)}}
+
+	for p.peek().kind != tokEOF {
+		if err := p.definition(); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(p.s.types) == 0 && len(p.s.directives) == 0 && len(p.s.roots) == 0 {
+		return nil, errors.New("schema defines no types")
+	}
+
+	for _, op := range []string{"query", "mutation", "subscription"} {
+		if _, ok := p.s.roots[op]; ok {
+			continue
+		}
+
+		// Without a schema definition, the root types go by their conventional names.
+		name := strings.ToUpper(op[:1]) + op[1:]
+		if _, ok := p.s.types[name]; ok {
+			p.s.roots[op] = name
+		}
+	}
+
+	return p.s, nil
+}
+
+// tokenize splits SDL source into tokens, skipping whitespace, commas and comments.
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(src); {
+		c := src[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case strings.HasPrefix(src[i:], "\ufeff"): // byte order mark
+			i += len("\ufeff")
+		case c == '#':
+			for i < len(src) && src[i] != '\n' && src[i] != '\r' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "..."):
+			tokens = append(tokens, token{kind: tokPunct, value: "...", pos: i})
+			i += 3
+		case strings.IndexByte("!$&():=@[]{|}", c) >= 0:
+			tokens = append(tokens, token{kind: tokPunct, value: string(c), pos: i})
+			i++
+		case c == '_' || isLetter(c):
+			start := i
+			for i < len(src) && (src[i] == '_' || isLetter(src[i]) || isDigit(src[i])) {
+				i++
+			}
+
+			tokens = append(tokens, token{kind: tokName, value: src[start:i], pos: start})
+		case c == '-' || isDigit(c):
+			start := i
+			i++
+
+			for i < len(src) && (isDigit(src[i]) || strings.IndexByte(".eE+-", src[i]) >= 0) {
+				i++
+			}
+
+			tokens = append(tokens, token{kind: tokNumber, value: src[start:i], pos: start})
+		case strings.HasPrefix(src[i:], `"""`):
+			end := blockStringEnd(src, i+3)
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated block string", lineOf(src, i))
+			}
+
+			raw := strings.ReplaceAll(src[i+3:end], `\"""`, `"""`)
+			tokens = append(tokens, token{kind: tokString, value: blockStringValue(raw), pos: i})
+			i = end + 3
+		case c == '"':
+			value, end, err := stringValue(src, i+1)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineOf(src, i), err)
+			}
+
+			tokens = append(tokens, token{kind: tokString, value: value, pos: i})
+			i = end
+		default:
+			r, _ := utf8.DecodeRuneInString(src[i:])
+			return nil, fmt.Errorf("line %d: unexpected character %q", lineOf(src, i), r)
+		}
+	}
+
+	return append(tokens, token{kind: tokEOF, pos: len(src)}), nil
+}
+
+// blockStringEnd returns the offset of the """ closing the block string whose content
+// starts at i, or -1 when it is not closed.
+func blockStringEnd(src string, i int) int {
+	for i < len(src) {
+		switch {
+		case strings.HasPrefix(src[i:], `\"""`):
+			i += 4
+		case strings.HasPrefix(src[i:], `"""`):
+			return i
+		default:
+			i++
+		}
+	}
+
+	return -1
+}
+
+// blockStringValue removes the common indentation of a block string's lines, except the
+// first, and its leading and trailing blank lines.
+func blockStringValue(raw string) string {
+	lines := strings.Split(strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(raw), "\n")
+
+	indent := -1
+
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+
+		if n := len(line) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+
+	if indent > 0 {
+		for i := 1; i < len(lines); i++ {
+			lines[i] = lines[i][min(indent, len(lines[i])):]
+		}
+	}
+
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// stringValue decodes the single-line string whose content starts at i, returning its
+// value and the offset after the closing quote.
+func stringValue(src string, i int) (string, int, error) {
+	var buf strings.Builder
+
+	for i < len(src) {
+		c := src[i]
+
+		switch {
+		case c == '"':
+			return buf.String(), i + 1, nil
+		case c == '\n' || c == '\r':
+			return "", 0, errors.New("unterminated string")
+		case c == '\\' && i+1 < len(src):
+			esc := src[i+1]
+			i += 2
+
+			switch esc {
+			case 'n':
+				buf.WriteByte('\n')
+			case 't':
+				buf.WriteByte('\t')
+			case 'r':
+				buf.WriteByte('\r')
+			case 'b':
+				buf.WriteByte('\b')
+			case 'f':
+				buf.WriteByte('\f')
+			case 'u':
+				if i+4 > len(src) {
+					return "", 0, errors.New("invalid unicode escape")
+				}
+
+				code, err := strconv.ParseUint(src[i:i+4], 16, 32)
+				if err != nil {
+					return "", 0, errors.New("invalid unicode escape")
+				}
+
+				buf.WriteRune(rune(code))
+				i += 4
+			default: // \" \\ \/
+				buf.WriteByte(esc)
+			}
+		default:
+			buf.WriteByte(c)
+			i++
+		}
+	}
+
+	return "", 0, errors.New("unterminated string")
+}
+
+func isLetter(c byte) bool { return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' }
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+// lineOf returns the 1-based line number of offset pos in src.
+func lineOf(src string, pos int) int {
+	return strings.Count(src[:min(pos, len(src))], "\n") + 1
+}
+
+// parser builds a schema from SDL tokens.
+type parser struct {
+	s      *schema
+	src    string
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) advance() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+
+	return t
+}
+
+// accept consumes the next token when it is the punctuator or keyword value.
+func (p *parser) accept(value string) bool {
+	if t := p.peek(); (t.kind == tokPunct || t.kind == tokName) && t.value == value {
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+func (p *parser) expect(value string) error {
+	if !p.accept(value) {
+		return p.errorf("expected %q", value)
+	}
+
+	return nil
+}
+
+func (p *parser) name() (string, error) {
+	t := p.peek()
+	if t.kind != tokName {
+		return "", p.errorf("expected a name")
+	}
+
+	p.pos++
+
+	return t.value, nil
+}
+
+// errorf returns a parse error at the next token.
+func (p *parser) errorf(format string, args ...any) error {
+	t := p.peek()
+
+	found := t.value
+	if t.kind == tokEOF {
+		found = "end of file"
+	}
+
+	return fmt.Errorf("line %d: %s, found %q", lineOf(p.src, t.pos), fmt.Sprintf(format, args...), found)
+}
+
+// description consumes the description string preceding a definition, if any.
+func (p *parser) description() string {
+	if t := p.peek(); t.kind == tokString {
+		p.pos++
+		return t.value
+	}
+
+	return ""
+}
+
+// definition parses a schema, type or directive definition, or an extension of one.
+func (p *parser) definition() error {
+	desc := p.description()
+	extend := p.accept("extend")
+
+	keyword, err := p.name()
+	if err != nil {
+		return err
+	}
+
+	switch keyword {
+	case "schema":
+		return p.schemaDefinition(desc)
+	case "directive":
+		return p.directiveDefinition(desc)
+	case "type", "interface", "union", "enum", "input", "scalar":
+		return p.typeDefinition(keyword, desc, extend)
+	default:
+		p.pos--
+		return p.errorf("expected a type system definition")
+	}
+}
+
+func (p *parser) schemaDefinition(desc string) error {
+	if desc != "" {
+		p.s.description = desc
+	}
+
+	if _, _, err := p.directives(); err != nil {
+		return err
+	}
+
+	if !p.accept("{") {
+		return nil
+	}
+
+	for !p.accept("}") {
+		op, err := p.name()
+		if err != nil {
+			return err
+		}
+
+		if err := p.expect(":"); err != nil {
+			return err
+		}
+
+		typ, err := p.name()
+		if err != nil {
+			return err
+		}
+
+		p.s.roots[op] = typ
+	}
+
+	return nil
+}
+
+func (p *parser) directiveDefinition(desc string) error {
+	if err := p.expect("@"); err != nil {
+		return err
+	}
+
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+
+	d := &directiveDef{name: name, description: desc}
+
+	if d.args, err = p.arguments(); err != nil {
+		return err
+	}
+
+	d.repeatable = p.accept("repeatable")
+
+	if err := p.expect("on"); err != nil {
+		return err
+	}
+
+	p.accept("|")
+
+	for {
+		loc, err := p.name()
+		if err != nil {
+			return err
+		}
+
+		d.locations = append(d.locations, loc)
+
+		if !p.accept("|") {
+			break
+		}
+	}
+
+	p.s.directives = append(p.s.directives, d)
+
+	return nil
+}
+
+func (p *parser) typeDefinition(kind, desc string, extend bool) error {
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+
+	t, ok := p.s.types[name]
+	if !ok {
+		t = &typeDef{kind: kind, name: name}
+		p.s.types[name] = t
+	}
+
+	if !extend || t.description == "" {
+		t.description = mdwrite.FirstNonEmpty(desc, t.description)
+	}
+
+	if p.accept("implements") {
+		p.accept("&")
+
+		for {
+			iface, err := p.name()
+			if err != nil {
+				return err
+			}
+
+			t.interfaces = append(t.interfaces, iface)
+
+			if !p.accept("&") {
+				break
+			}
+		}
+	}
+
+	if _, _, err := p.directives(); err != nil {
+		return err
+	}
+
+	switch kind {
+	case "union":
+		return p.unionMembers(t)
+	case "enum":
+		return p.enumValues(t)
+	case "scalar":
+		return nil
+	default:
+		return p.fields(t)
+	}
+}
+
+func (p *parser) unionMembers(t *typeDef) error {
+	if !p.accept("=") {
+		return nil
+	}
+
+	p.accept("|")
+
+	for {
+		member, err := p.name()
+		if err != nil {
+			return err
+		}
+
+		t.members = append(t.members, member)
+
+		if !p.accept("|") {
+			return nil
+		}
+	}
+}
+
+func (p *parser) enumValues(t *typeDef) error {
+	if !p.accept("{") {
+		return nil
+	}
+
+	for !p.accept("}") {
+		desc := p.description()
+
+		name, err := p.name()
+		if err != nil {
+			return err
+		}
+
+		deprecated, reason, err := p.directives()
+		if err != nil {
+			return err
+		}
+
+		t.values = append(t.values, enumValue{name: name, description: desc, deprecated: deprecated, deprecation: reason})
+	}
+
+	return nil
+}
+
+func (p *parser) fields(t *typeDef) error {
+	if !p.accept("{") {
+		return nil
+	}
+
+	for !p.accept("}") {
+		f, err := p.field()
+		if err != nil {
+			return err
+		}
+
+		t.fields = append(t.fields, f)
+	}
+
+	return nil
+}
+
+// field parses a field or input value definition: its description, name, arguments,
+// type, default value and directives.
+func (p *parser) field() (field, error) {
+	f := field{description: p.description()}
+
+	var err error
+
+	if f.name, err = p.name(); err != nil {
+		return f, err
+	}
+
+	if f.args, err = p.arguments(); err != nil {
+		return f, err
+	}
+
+	if err := p.expect(":"); err != nil {
+		return f, err
+	}
+
+	if f.typ, err = p.typeRef(0); err != nil {
+		return f, err
+	}
+
+	if p.accept("=") {
+		if f.defaultValue, err = p.value(0); err != nil {
+			return f, err
+		}
+	}
+
+	f.deprecated, f.deprecation, err = p.directives()
+
+	return f, err
+}
+
+// arguments parses the parenthesized argument definitions of a field or directive.
+func (p *parser) arguments() ([]field, error) {
+	if !p.accept("(") {
+		return nil, nil
+	}
+
+	var args []field
+
+	for !p.accept(")") {
+		arg, err := p.field()
+		if err != nil {
+			return nil, err
+		}
+
+		args = append(args, arg)
+	}
+
+	return args, nil
+}
+
+// typeRef parses a type reference such as "[User!]!" and returns it as written.
+func (p *parser) typeRef(depth int) (string, error) {
+	if depth > maxDepth {
+		return "", errTooDeep
+	}
+
+	var typ string
+
+	if p.accept("[") {
+		inner, err := p.typeRef(depth + 1)
+		if err != nil {
+			return "", err
+		}
+
+		if err := p.expect("]"); err != nil {
+			return "", err
+		}
+
+		typ = "[" + inner + "]"
+	} else {
+		name, err := p.name()
+		if err != nil {
+			return "", err
+		}
+
+		typ = name
+	}
+
+	if p.accept("!") {
+		typ += "!"
+	}
+
+	return typ, nil
+}
+
+// directives parses the directives applied to a definition, reporting whether one of
+// them is @deprecated and with which reason.
+func (p *parser) directives() (deprecated bool, reason string, err error) {
+	for p.accept("@") {
+		name, err := p.name()
+		if err != nil {
+			return false, "", err
+		}
+
+		args := map[string]string{}
+
+		if p.accept("(") {
+			for !p.accept(")") {
+				arg, err := p.name()
+				if err != nil {
+					return false, "", err
+				}
+
+				if err := p.expect(":"); err != nil {
+					return false, "", err
+				}
+
+				if t := p.peek(); t.kind == tokString {
+					args[arg] = t.value
+				}
+
+				if _, err := p.value(0); err != nil {
+					return false, "", err
+				}
+			}
+		}
+
+		if name == "deprecated" {
+			deprecated = true
+			reason = mdwrite.FirstNonEmpty(args["reason"], defaultDeprecation)
+		}
+	}
+
+	return deprecated, reason, nil
+}
+
+// value parses a constant or variable value and returns it formatted as GraphQL.
+func (p *parser) value(depth int) (string, error) {
+	if depth > maxDepth {
+		return "", errTooDeep
+	}
+
+	t := p.peek()
+
+	switch {
+	case t.kind == tokString:
+		p.pos++
+		return strconv.Quote(t.value), nil
+	case t.kind == tokName || t.kind == tokNumber:
+		p.pos++
+		return t.value, nil
+	case p.accept("$"):
+		name, err := p.name()
+		return "$" + name, err
+	case p.accept("["):
+		var items []string
+
+		for !p.accept("]") {
+			item, err := p.value(depth + 1)
+			if err != nil {
+				return "", err
+			}
+
+			items = append(items, item)
+		}
+
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case p.accept("{"):
+		var fields []string
+
+		for !p.accept("}") {
+			name, err := p.name()
+			if err != nil {
+				return "", err
+			}
+
+			if err := p.expect(":"); err != nil {
+				return "", err
+			}
+
+			v, err := p.value(depth + 1)
+			if err != nil {
+				return "", err
+			}
+
+			fields = append(fields, name+": "+v)
+		}
+
+		return "{" + strings.Join(fields, ", ") + "}", nil
+	default:
+		return "", p.errorf("expected a value")
+	}
+}
@@ -0,0 +1,416 @@
+// Package graphql provides a GraphQL schema content processor. Schemas written in the
+// schema definition language (SDL) are parsed without a GraphQL library and shown as a
+// reference of their queries, mutations, subscriptions and types, each of which is a
+// searchable section.
+package graphql
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/ksysoev/omnidex/pkg/prov/internal/mdwrite"
+)
+
+// section is a heading of the schema reference with what is documented under it, in the
+// order it is indexed and rendered.
+type section struct {
+	heading     core.Heading
+	kind        string // what the section documents, e.g. "Object type implementing Node"
+	description string
+	deprecation string
+	returns     string // type returned by a root operation field
+	listTitle   string
+	items       []item
+}
+
+// item is a field, argument, enum value or union member listed in a section.
+type item struct {
+	name         string
+	typ          string
+	defaultValue string
+	description  string
+	deprecation  string
+}
+
+// rootOperations are the root operation types, in the order their sections are rendered.
+var rootOperations = []struct {
+	op     string
+	title  string
+	prefix string
+}{
+	{op: "query", title: "Queries", prefix: "query"},
+	{op: "mutation", title: "Mutations", prefix: "mutation"},
+	{op: "subscription", title: "Subscriptions", prefix: "subscription"},
+}
+
+// typeCategories group the named types by kind, in the order their sections are rendered.
+var typeCategories = []struct {
+	kind  string
+	title string
+}{
+	{kind: "type", title: "Objects"},
+	{kind: "interface", title: "Interfaces"},
+	{kind: "union", title: "Unions"},
+	{kind: "enum", title: "Enums"},
+	{kind: "input", title: "Input Objects"},
+	{kind: "scalar", title: "Scalars"},
+}
+
+// Processor implements core.ContentProcessor for GraphQL schemas. It renders the root
+// operations and the types of a schema as plain HTML, so pages need no client-side viewer.
+type Processor struct{}
+
+// New creates a new GraphQL Processor.
+func New() *Processor {
+	return &Processor{}
+}
+
+// ContentTypeInfo describes GraphQL schemas: .graphql and .graphqls files in the schema
+// definition language.
+func (p *Processor) ContentTypeInfo() core.ContentTypeInfo {
+	return core.ContentTypeInfo{
+		Name:        core.ContentTypeGraphQL,
+		DisplayName: "GraphQL",
+		Icon:        "graph",
+		Extensions:  []string{".graphql", ".graphqls"},
+	}
+}
+
+// RenderHTML renders the schema as a reference: its description, a section per root
+// operation type listing its fields, then the other types grouped by kind and the custom
+// directives. References to types defined in the schema link to their section.
+// Descriptions are shown as plain text paragraphs; all values taken from the schema are
+// escaped.
+func (p *Processor) RenderHTML(src []byte) ([]byte, []core.Heading, error) {
+	s, err := parseSchema(src)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse GraphQL schema: %w", err)
+	}
+
+	var buf bytes.Buffer
+
+	mdwrite.WriteParagraphs(&buf, s.description)
+
+	sections, typeIDs := s.sections()
+	headings := make([]core.Heading, 0, len(sections))
+
+	for _, sec := range sections {
+		headings = append(headings, sec.heading)
+
+		fmt.Fprintf(&buf, "<h%d id=\"%s\">%s</h%d>\n", sec.heading.Level, html.EscapeString(sec.heading.ID), html.EscapeString(sec.heading.Text), sec.heading.Level)
+
+		if sec.kind != "" {
+			fmt.Fprintf(&buf, "<p><em>%s</em></p>\n", html.EscapeString(sec.kind))
+		}
+
+		if sec.deprecation != "" {
+			fmt.Fprintf(&buf, "<p><strong>Deprecated:</strong> %s</p>\n", html.EscapeString(sec.deprecation))
+		}
+
+		mdwrite.WriteParagraphs(&buf, sec.description)
+
+		if sec.returns != "" {
+			fmt.Fprintf(&buf, "<p>Returns %s</p>\n", typeLink(sec.returns, typeIDs))
+		}
+
+		writeItems(&buf, sec.listTitle, sec.items, typeIDs)
+	}
+
+	return buf.Bytes(), headings, nil
+}
+
+// ExtractTitle returns an empty string: GraphQL schemas have no title, so documents are
+// listed under their path.
+func (p *Processor) ExtractTitle(_ []byte) string {
+	return ""
+}
+
+// ExtractSummary returns the first paragraph of the schema description. It returns an
+// empty string if the schema cannot be parsed or has no description.
+func (p *Processor) ExtractSummary(src []byte) string {
+	s, err := parseSchema(src)
+	if err != nil {
+		return ""
+	}
+
+	para, _, _ := strings.Cut(strings.TrimSpace(s.description), "\n\n")
+
+	return strings.TrimSpace(para)
+}
+
+// ToPlainText extracts searchable plain text from a GraphQL schema: its description, then
+// each section's heading line followed by its description and the names, types and
+// descriptions of its items. Sections are emitted in the same order as ExtractHeadings
+// returns them, enabling accurate fragment-to-anchor mapping during search result
+// deep-linking.
+func (p *Processor) ToPlainText(src []byte) string {
+	s, err := parseSchema(src)
+	if err != nil {
+		return ""
+	}
+
+	var buf bytes.Buffer
+
+	mdwrite.WriteLine(&buf, s.description)
+
+	sections, _ := s.sections()
+
+	for _, sec := range sections {
+		mdwrite.WriteLine(&buf, sec.heading.Text)
+		mdwrite.WriteLine(&buf, sec.deprecation)
+		mdwrite.WriteLine(&buf, sec.description)
+		mdwrite.WriteLine(&buf, sec.returns)
+
+		for _, it := range sec.items {
+			mdwrite.WriteLine(&buf, strings.TrimSpace(it.name+" "+it.typ))
+			mdwrite.WriteLine(&buf, it.description)
+		}
+	}
+
+	return strings.TrimSpace(buf.String())
+}
+
+// ExtractHeadings returns the headings of the rendered schema: one per root operation
+// type, type kind and the directives, and one per root field, type and directive, with
+// the anchor IDs RenderHTML gives them.
+func (p *Processor) ExtractHeadings(src []byte) []core.Heading {
+	s, err := parseSchema(src)
+	if err != nil {
+		return nil
+	}
+
+	sections, _ := s.sections()
+	if len(sections) == 0 {
+		return nil
+	}
+
+	headings := make([]core.Heading, 0, len(sections))
+	for _, sec := range sections {
+		headings = append(headings, sec.heading)
+	}
+
+	return headings
+}
+
+// ExtractCodeBlocks returns nil: GraphQL schemas have no fenced code blocks to index
+// for code search.
+func (p *Processor) ExtractCodeBlocks(_ []byte) []core.CodeBlock {
+	return nil
+}
+
+// sections returns the sections of the schema reference and the anchor ID of each type
+// that references link to. Root operation types are documented by their fields under
+// "Queries", "Mutations" and "Subscriptions"; the other types follow grouped by kind and
+// sorted by name, then the directives.
+func (s *schema) sections() ([]section, map[string]string) {
+	ids := mdwrite.NewAnchorSet()
+	typeIDs := make(map[string]string, len(s.types))
+	roots := make(map[string]bool, len(s.roots))
+
+	for _, name := range s.roots {
+		roots[name] = true
+	}
+
+	names := mdwrite.SortedKeys(s.types)
+
+	// Types get their anchors first so that they do not depend on the sections before them.
+	for _, name := range names {
+		if !roots[name] {
+			typeIDs[name] = ids.Unique("type-" + mdwrite.Slug(name))
+		}
+	}
+
+	var sections []section
+
+	for _, root := range rootOperations {
+		t, ok := s.types[s.roots[root.op]]
+		if !ok {
+			continue
+		}
+
+		id := ids.Unique(mdwrite.Slug(root.title))
+		typeIDs[t.name] = id
+
+		sections = append(sections, section{
+			heading:     core.Heading{Level: 2, ID: id, Text: root.title},
+			description: t.description,
+		})
+
+		for _, f := range t.fields {
+			sections = append(sections, section{
+				heading:     core.Heading{Level: 3, ID: ids.Unique(root.prefix + "-" + mdwrite.Slug(f.name)), Text: f.name},
+				description: f.description,
+				deprecation: f.deprecation,
+				returns:     f.typ,
+				listTitle:   "Arguments",
+				items:       fieldItems(f.args),
+			})
+		}
+	}
+
+	for _, category := range typeCategories {
+		var types []section
+
+		for _, name := range names {
+			if t := s.types[name]; t.kind == category.kind && !roots[name] {
+				types = append(types, t.section(typeIDs[name]))
+			}
+		}
+
+		if len(types) > 0 {
+			sections = append(sections, section{heading: core.Heading{Level: 2, ID: ids.Unique(mdwrite.Slug(category.title)), Text: category.title}})
+			sections = append(sections, types...)
+		}
+	}
+
+	if len(s.directives) > 0 {
+		sections = append(sections, section{heading: core.Heading{Level: 2, ID: ids.Unique("directives"), Text: "Directives"}})
+
+		for _, d := range s.directives {
+			kind := "Directive on " + strings.Join(d.locations, " | ")
+			if d.repeatable {
+				kind += ", repeatable"
+			}
+
+			sections = append(sections, section{
+				heading:     core.Heading{Level: 3, ID: ids.Unique("directive-" + mdwrite.Slug(d.name)), Text: "@" + d.name},
+				kind:        kind,
+				description: d.description,
+				listTitle:   "Arguments",
+				items:       fieldItems(d.args),
+			})
+		}
+	}
+
+	return sections, typeIDs
+}
+
+// section returns the section documenting the type under the given anchor ID.
+func (t *typeDef) section(id string) section {
+	sec := section{
+		heading:     core.Heading{Level: 3, ID: id, Text: t.name},
+		description: t.description,
+	}
+
+	switch t.kind {
+	case "type", "interface":
+		sec.kind = "Object type"
+		if t.kind == "interface" {
+			sec.kind = "Interface"
+		}
+
+		if len(t.interfaces) > 0 {
+			sec.kind += " implementing " + strings.Join(t.interfaces, " & ")
+		}
+
+		sec.listTitle = "Fields"
+		sec.items = fieldItems(t.fields)
+	case "input":
+		sec.kind = "Input object"
+		sec.listTitle = "Fields"
+		sec.items = fieldItems(t.fields)
+	case "enum":
+		sec.kind = "Enum"
+		sec.listTitle = "Values"
+
+		for _, v := range t.values {
+			sec.items = append(sec.items, item{name: v.name, description: v.description, deprecation: v.deprecation})
+		}
+	case "union":
+		sec.kind = "Union"
+		sec.listTitle = "Possible types"
+
+		for _, m := range t.members {
+			sec.items = append(sec.items, item{typ: m})
+		}
+	case "scalar":
+		sec.kind = "Scalar"
+	}
+
+	return sec
+}
+
+// fieldItems returns the items listing fields or arguments. Fields taking arguments are
+// named with their argument list, e.g. "users(first: Int = 10)".
+func fieldItems(fields []field) []item {
+	items := make([]item, 0, len(fields))
+
+	for _, f := range fields {
+		name := f.name
+
+		if len(f.args) > 0 {
+			args := make([]string, 0, len(f.args))
+
+			for _, a := range f.args {
+				arg := a.name + ": " + a.typ
+				if a.defaultValue != "" {
+					arg += " = " + a.defaultValue
+				}
+
+				args = append(args, arg)
+			}
+
+			name += "(" + strings.Join(args, ", ") + ")"
+		}
+
+		items = append(items, item{name: name, typ: f.typ, defaultValue: f.defaultValue, description: f.description, deprecation: f.deprecation})
+	}
+
+	return items
+}
+
+// writeItems writes items as a list under title, linking their types to their section.
+func writeItems(buf *bytes.Buffer, title string, items []item, typeIDs map[string]string) {
+	if len(items) == 0 {
+		return
+	}
+
+	fmt.Fprintf(buf, "<p><strong>%s</strong></p>\n<ul>\n", html.EscapeString(title))
+
+	for _, it := range items {
+		buf.WriteString("<li>")
+
+		if it.name != "" {
+			fmt.Fprintf(buf, "<code>%s</code>", html.EscapeString(it.name))
+		}
+
+		if it.typ != "" {
+			if it.name != "" {
+				buf.WriteString(": ")
+			}
+
+			buf.WriteString(typeLink(it.typ, typeIDs))
+		}
+
+		if it.defaultValue != "" {
+			fmt.Fprintf(buf, " = <code>%s</code>", html.EscapeString(it.defaultValue))
+		}
+
+		if it.description != "" {
+			fmt.Fprintf(buf, " — %s", html.EscapeString(it.description))
+		}
+
+		if it.deprecation != "" {
+			fmt.Fprintf(buf, " <em>Deprecated: %s</em>", html.EscapeString(it.deprecation))
+		}
+
+		buf.WriteString("</li>\n")
+	}
+
+	buf.WriteString("</ul>\n")
+}
+
+// typeLink returns a type reference as HTML code, linked to the section of the named type
+// when the schema defines it.
+func typeLink(typ string, typeIDs map[string]string) string {
+	code := "<code>" + html.EscapeString(typ) + "</code>"
+
+	if id, ok := typeIDs[strings.Trim(typ, "[]!")]; ok {
+		return "<a href=\"#" + html.EscapeString(id) + "\">" + code + "</a>"
+	}
+
+	return code
+}
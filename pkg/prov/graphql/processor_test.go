@@ -0,0 +1,207 @@
+package graphql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const storeSchema = `"""
+The storefront API.
+
+Requires an API token.
+"""
+schema {
+  query: Root
+  mutation: Mutation
+}
+
+# Root query type.
+type Root {
+  "Looks up a product by its ID."
+  product(id: ID!): Product
+  products(first: Int = 10, filter: ProductFilter): [Product!]!
+  legacyProducts: [Product] @deprecated(reason: "Use products.")
+}
+
+type Mutation {
+  addToCart(input: CartInput!): Cart
+}
+
+"""
+Anything that can be bought.
+"""
+interface Node {
+  id: ID!
+}
+
+type Product implements Node & Priced @key(fields: "id") {
+  id: ID!
+  name: String!
+  "Price in <cents>."
+  price: Int!
+}
+
+type Cart {
+  items: [Product!]!
+}
+
+extend type Cart {
+  total: Int!
+}
+
+union SearchResult = | Product | Cart
+
+enum Currency {
+  EUR
+  "US dollars"
+  USD
+  GBP @deprecated
+}
+
+input ProductFilter {
+  currency: Currency = EUR
+  tags: [String!] = ["new", "sale"]
+}
+
+input CartInput {
+  productId: ID!
+}
+
+scalar DateTime @specifiedBy(url: "https://tools.ietf.org/html/rfc3339")
+
+"Caches the field for the given time."
+directive @cached(ttl: Int = 60) repeatable on FIELD_DEFINITION | OBJECT
+`
+
+func TestProcessor_ExtractTitleAndSummary(t *testing.T) {
+	p := New()
+
+	assert.Empty(t, p.ExtractTitle([]byte(storeSchema)), "documents are listed under their path")
+	assert.Equal(t, "The storefront API.", p.ExtractSummary([]byte(storeSchema)))
+	assert.Empty(t, p.ExtractSummary([]byte("type Query { ping: String }")))
+	assert.Empty(t, p.ExtractSummary([]byte("type Query {")))
+}
+
+func TestProcessor_ExtractHeadings(t *testing.T) {
+	headings := New().ExtractHeadings([]byte(storeSchema))
+
+	assert.Equal(t, []core.Heading{
+		{ID: "queries", Text: "Queries", Level: 2},
+		{ID: "query-product", Text: "product", Level: 3},
+		{ID: "query-products", Text: "products", Level: 3},
+		{ID: "query-legacyproducts", Text: "legacyProducts", Level: 3},
+		{ID: "mutations", Text: "Mutations", Level: 2},
+		{ID: "mutation-addtocart", Text: "addToCart", Level: 3},
+		{ID: "objects", Text: "Objects", Level: 2},
+		{ID: "type-cart", Text: "Cart", Level: 3},
+		{ID: "type-product", Text: "Product", Level: 3},
+		{ID: "interfaces", Text: "Interfaces", Level: 2},
+		{ID: "type-node", Text: "Node", Level: 3},
+		{ID: "unions", Text: "Unions", Level: 2},
+		{ID: "type-searchresult", Text: "SearchResult", Level: 3},
+		{ID: "enums", Text: "Enums", Level: 2},
+		{ID: "type-currency", Text: "Currency", Level: 3},
+		{ID: "input-objects", Text: "Input Objects", Level: 2},
+		{ID: "type-cartinput", Text: "CartInput", Level: 3},
+		{ID: "type-productfilter", Text: "ProductFilter", Level: 3},
+		{ID: "scalars", Text: "Scalars", Level: 2},
+		{ID: "type-datetime", Text: "DateTime", Level: 3},
+		{ID: "directives", Text: "Directives", Level: 2},
+		{ID: "directive-cached", Text: "@cached", Level: 3},
+	}, headings)
+}
+
+func TestProcessor_ToPlainText(t *testing.T) {
+	p := New()
+
+	text := p.ToPlainText([]byte(storeSchema))
+	assert.Contains(t, text, "The storefront API.")
+	assert.Contains(t, text, "Looks up a product by its ID.")
+	assert.Contains(t, text, "products\n[Product!]!\nfirst Int\nfilter ProductFilter")
+	assert.Contains(t, text, "total Int!", "type extensions are merged into the type")
+	assert.Contains(t, text, "US dollars")
+
+	// Headings appear in the plain text in the order they are returned.
+	pos := 0
+
+	for _, h := range p.ExtractHeadings([]byte(storeSchema)) {
+		idx := strings.Index(text[pos:], h.Text)
+		require.GreaterOrEqual(t, idx, 0, "heading %q", h.Text)
+
+		pos += idx + len(h.Text)
+	}
+
+	assert.Empty(t, p.ToPlainText([]byte("# just a comment")))
+}
+
+func TestProcessor_RenderHTML(t *testing.T) {
+	out, headings, err := New().RenderHTML([]byte(storeSchema))
+	require.NoError(t, err)
+
+	html := string(out)
+	assert.True(t, strings.HasPrefix(html, "<p>The storefront API.</p>\n<p>Requires an API token.</p>\n"))
+	assert.Contains(t, html, `<h3 id="query-product">product</h3>`)
+	assert.Contains(t, html, `<p>Returns <a href="#type-product"><code>Product</code></a></p>`)
+	assert.Contains(t, html, `<li><code>id</code>: <code>ID!</code></li>`, "built-in scalars are not linked")
+	assert.Contains(t, html, `<li><code>filter</code>: <a href="#type-productfilter"><code>ProductFilter</code></a></li>`)
+	assert.Contains(t, html, `<p><strong>Deprecated:</strong> Use products.</p>`)
+	assert.Contains(t, html, `<p><em>Object type implementing Node &amp; Priced</em></p>`)
+	assert.Contains(t, html, `<li><code>price</code>: <code>Int!</code> — Price in &lt;cents&gt;.</li>`, "values from the schema are escaped")
+	assert.Contains(t, html, `<li><code>GBP</code> <em>Deprecated: No longer supported</em></li>`)
+	assert.Contains(t, html, `<li><a href="#type-product"><code>Product</code></a></li>`, "union members link to their type")
+	assert.Contains(t, html, `<li><code>tags</code>: <code>[String!]</code> = <code>[&#34;new&#34;, &#34;sale&#34;]</code></li>`)
+	assert.Contains(t, html, `<p><em>Directive on FIELD_DEFINITION | OBJECT, repeatable</em></p>`)
+	assert.Len(t, headings, 22)
+
+	_, _, err = New().RenderHTML([]byte("type Query { ping: }"))
+	assert.ErrorContains(t, err, `line 1: expected a name, found "}"`)
+}
+
+func TestProcessor_DefaultRootTypes(t *testing.T) {
+	src := `type Query { me: User }
+type Subscription { userChanged: User }
+type User { id: ID! }
+`
+
+	headings := New().ExtractHeadings([]byte(src))
+	assert.Equal(t, []core.Heading{
+		{ID: "queries", Text: "Queries", Level: 2},
+		{ID: "query-me", Text: "me", Level: 3},
+		{ID: "subscriptions", Text: "Subscriptions", Level: 2},
+		{ID: "subscription-userchanged", Text: "userChanged", Level: 3},
+		{ID: "objects", Text: "Objects", Level: 2},
+		{ID: "type-user", Text: "User", Level: 3},
+	}, headings)
+}
+
+func TestParseSchema_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantErr string
+	}{
+		{name: "empty", src: "  # nothing here\n", wantErr: "schema defines no types"},
+		{name: "unknown definition", src: "query { me }", wantErr: "expected a type system definition"},
+		{name: "unterminated string", src: "\"open\ntype A { b: C }", wantErr: "unterminated string"},
+		{name: "unterminated block string", src: `"""open`, wantErr: "unterminated block string"},
+		{name: "unexpected character", src: "type A { b: C% }", wantErr: "unexpected character"},
+		{name: "unclosed type", src: "type A {\n  b: C\n", wantErr: `line 3: expected a name, found "end of file"`},
+		{name: "deep list", src: "type A { b: " + strings.Repeat("[", 100) + "C }", wantErr: "nesting too deep"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseSchema([]byte(tt.src))
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestBlockStringValue(t *testing.T) {
+	assert.Equal(t, "Hello,\n  World!\n\nYours,\n  GraphQL.", blockStringValue("\n    Hello,\n      World!\n\n    Yours,\n      GraphQL.\n  "))
+	assert.Equal(t, "first\nsecond", blockStringValue("first\n  second"))
+}
@@ -0,0 +1,97 @@
+// Package mdwrite holds the helpers the structured content processors share to write
+// the HTML and plain text of a document and to give its sections anchor IDs.
+package mdwrite
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// WriteParagraphs writes text as HTML paragraphs, one per blank-line separated block.
+func WriteParagraphs(buf *bytes.Buffer, text string) {
+	for _, para := range strings.Split(strings.TrimSpace(text), "\n\n") {
+		if para = strings.TrimSpace(para); para != "" {
+			fmt.Fprintf(buf, "<p>%s</p>\n", html.EscapeString(para))
+		}
+	}
+}
+
+// WriteLine writes s followed by a newline, skipping empty strings.
+func WriteLine(buf *bytes.Buffer, s string) {
+	if s == "" {
+		return
+	}
+
+	buf.WriteString(s)
+	buf.WriteByte('\n')
+}
+
+// FirstNonEmpty returns the first of values that is not empty.
+func FirstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
+
+// SortedKeys returns the keys of m in ascending order.
+func SortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Slug converts s to a URL-safe anchor ID: lowercase letters and digits, with runs of
+// other characters replaced by a single hyphen.
+func Slug(s string) string {
+	var buf strings.Builder
+
+	prevHyphen := true // start true to avoid a leading hyphen
+
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			buf.WriteRune(r)
+
+			prevHyphen = false
+		} else if !prevHyphen {
+			buf.WriteRune('-')
+
+			prevHyphen = true
+		}
+	}
+
+	return strings.TrimRight(buf.String(), "-")
+}
+
+// AnchorSet tracks the anchor IDs given out for a document so that each is unique.
+type AnchorSet map[string]int
+
+// NewAnchorSet returns an empty AnchorSet.
+func NewAnchorSet() AnchorSet {
+	return make(AnchorSet)
+}
+
+// Unique returns id, suffixed with a counter when it was already given out.
+func (a AnchorSet) Unique(id string) string {
+	n := a[id]
+	a[id] = n + 1
+
+	if n == 0 {
+		return id
+	}
+
+	return id + "-" + strconv.Itoa(n)
+}
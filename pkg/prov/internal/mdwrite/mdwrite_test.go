@@ -0,0 +1,51 @@
+//go:build !compile
+
+package mdwrite
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlug(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "User Signed Up", want: "user-signed-up"},
+		{in: "  /users/{id} ", want: "users-id"},
+		{in: "Größe", want: "größe"},
+		{in: "---", want: ""},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Slug(tt.in), tt.in)
+	}
+}
+
+func TestAnchorSet_Unique(t *testing.T) {
+	ids := NewAnchorSet()
+
+	assert.Equal(t, "type-user", ids.Unique("type-user"))
+	assert.Equal(t, "type-user-1", ids.Unique("type-user"))
+	assert.Equal(t, "type-user-2", ids.Unique("type-user"))
+	assert.Equal(t, "query", ids.Unique("query"))
+}
+
+func TestWriteParagraphs(t *testing.T) {
+	var buf bytes.Buffer
+
+	WriteParagraphs(&buf, "\nFirst <line>\n\n\n  Second  \n")
+	WriteLine(&buf, "")
+	WriteLine(&buf, "tail")
+
+	assert.Equal(t, "<p>First &lt;line&gt;</p>\n<p>Second</p>\ntail\n", buf.String())
+}
+
+func TestFirstNonEmpty_SortedKeys(t *testing.T) {
+	assert.Equal(t, "b", FirstNonEmpty("", "b", "c"))
+	assert.Empty(t, FirstNonEmpty())
+	assert.Equal(t, []string{"a", "b", "c"}, SortedKeys(map[string]int{"c": 1, "a": 2, "b": 3}))
+}
@@ -0,0 +1,22 @@
+package jsonschema
+
+import (
+	"testing"
+)
+
+func FuzzProcessor(f *testing.F) {
+	f.Add([]byte(orderSchema))
+	f.Add([]byte(legacySchemaYAML))
+	f.Add([]byte(`{"items": [true, {"items": {"items": false}}], "prefixItems": [{"$ref": "#/$defs/a~1b"}], "$defs": {"a/b": {}}}`))
+	f.Add([]byte(`{"type": ["string", 1], "enum": [{"a": [1, null]}], "allOf": [{"anyOf": [{}]}]}`))
+
+	p := New()
+
+	f.Fuzz(func(_ *testing.T, src []byte) {
+		_, _, _ = p.RenderHTML(src)
+		p.ExtractTitle(src)
+		p.ExtractSummary(src)
+		p.ToPlainText(src)
+		p.ExtractHeadings(src)
+	})
+}
@@ -0,0 +1,769 @@
+// Package jsonschema provides a JSON Schema content processor. Schemas, in YAML or
+// JSON, are shown as a collapsible tree of their properties and definitions, and their
+// property names, titles and descriptions are indexed for search.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/ksysoev/omnidex/pkg/prov/internal/mdwrite"
+	"gopkg.in/yaml.v3"
+)
+
+// maxDepth bounds how deeply nested schemas are rendered and indexed.
+const maxDepth = 32
+
+var (
+	// errNotSchema is returned for documents that are not a JSON Schema object.
+	errNotSchema = errors.New("document is not a JSON Schema object")
+	// errAlias is returned for YAML documents with aliases, which would be expanded into
+	// a copy of the aliased schema at every use.
+	errAlias = errors.New("YAML aliases are not supported in JSON Schema documents")
+)
+
+// schema holds the keywords of a JSON Schema the processor indexes and renders. Boolean
+// schemas decode with only boolean set.
+type schema struct {
+	boolean     *bool
+	Default     any          `yaml:"default"`
+	Const       any          `yaml:"const"`
+	Items       *schema      `yaml:"items"`
+	Ref         string       `yaml:"$ref"`
+	Schema      string       `yaml:"$schema"`
+	ID          string       `yaml:"$id"`
+	Title       string       `yaml:"title"`
+	Description string       `yaml:"description"`
+	Format      string       `yaml:"format"`
+	Properties  namedSchemas `yaml:"properties"`
+	Defs        namedSchemas `yaml:"$defs"`
+	Definitions namedSchemas `yaml:"definitions"` // before draft 2019-09
+	PrefixItems []*schema    `yaml:"prefixItems"`
+	tuple       []*schema    // array form of items, before draft 2020-12
+	AllOf       []*schema    `yaml:"allOf"`
+	AnyOf       []*schema    `yaml:"anyOf"`
+	OneOf       []*schema    `yaml:"oneOf"`
+	Enum        []any        `yaml:"enum"`
+	Examples    []any        `yaml:"examples"`
+	Required    []string     `yaml:"required"`
+	Type        typeList     `yaml:"type"`
+	Deprecated  bool         `yaml:"deprecated"`
+	ReadOnly    bool         `yaml:"readOnly"`
+	WriteOnly   bool         `yaml:"writeOnly"`
+}
+
+// UnmarshalYAML decodes a schema object, a boolean schema, or the array form of items.
+func (s *schema) UnmarshalYAML(n *yaml.Node) error {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		var b bool
+		if err := n.Decode(&b); err != nil {
+			return fmt.Errorf("line %d: schema must be an object or a boolean", n.Line)
+		}
+
+		s.boolean = &b
+
+		return nil
+	case yaml.SequenceNode:
+		return n.Decode(&s.tuple)
+	}
+
+	type plain schema
+
+	return n.Decode((*plain)(s))
+}
+
+// namedSchema is a property or definition of a schema.
+type namedSchema struct {
+	schema *schema
+	name   string
+}
+
+// namedSchemas are the properties or definitions of a schema in document order.
+type namedSchemas []namedSchema
+
+// UnmarshalYAML decodes a mapping of names to schemas, keeping their order.
+func (ns *namedSchemas) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: expected a mapping of names to schemas", n.Line)
+	}
+
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		var s schema
+		if err := n.Content[i+1].Decode(&s); err != nil {
+			return err
+		}
+
+		*ns = append(*ns, namedSchema{name: n.Content[i].Value, schema: &s})
+	}
+
+	return nil
+}
+
+// typeList is the type keyword, a single type name or a list of them.
+type typeList []string
+
+// UnmarshalYAML decodes a type name or a list of type names.
+func (t *typeList) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		*t = typeList{n.Value}
+		return nil
+	}
+
+	var types []string
+	if err := n.Decode(&types); err != nil {
+		return err
+	}
+
+	*t = types
+
+	return nil
+}
+
+// section is a part of the rendered schema with a heading: the root's properties or
+// items, and each definition.
+type section struct {
+	schema  *schema
+	heading core.Heading
+}
+
+// Processor implements core.ContentProcessor for JSON Schema documents. It renders the
+// properties of the schema and its definitions as nested lists that readers can
+// collapse, so pages need no client-side viewer.
+type Processor struct{}
+
+// New creates a new JSON Schema Processor.
+func New() *Processor {
+	return &Processor{}
+}
+
+// ContentTypeInfo describes JSON Schema documents: YAML or JSON files with a "$schema"
+// top-level key, or named like "order.schema.json". Specs of other types that declare
+// a "$schema" for editor validation keep their own type.
+func (p *Processor) ContentTypeInfo() core.ContentTypeInfo {
+	return core.ContentTypeInfo{
+		Name:           core.ContentTypeJSONSchema,
+		DisplayName:    "JSON Schema",
+		Icon:           "schema",
+		Extensions:     []string{".yaml", ".yml", ".json"},
+		Markers:        []string{"$schema"},
+		GenericMarkers: true,
+		Suffixes:       []string{".schema.json", ".schema.yaml", ".schema.yml"},
+	}
+}
+
+// RenderHTML renders the schema as HTML: its title, dialect and description, then its
+// properties or items as a tree and a section per definition. References to definitions
+// link to their section; all values taken from the schema are escaped.
+func (p *Processor) RenderHTML(src []byte) ([]byte, []core.Heading, error) {
+	s, err := parseSchema(src)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse JSON Schema: %w", err)
+	}
+
+	sections := s.sections()
+	r := renderer{defs: definitionAnchors(sections)}
+
+	var buf bytes.Buffer
+
+	if s.Title != "" {
+		fmt.Fprintf(&buf, "<h1>%s</h1>\n", html.EscapeString(s.Title))
+	}
+
+	fmt.Fprintf(&buf, "<p><em>%s</em>", html.EscapeString(dialect(s.Schema)))
+
+	if s.ID != "" {
+		fmt.Fprintf(&buf, " <code>%s</code>", html.EscapeString(s.ID))
+	}
+
+	buf.WriteString("</p>\n")
+	mdwrite.WriteParagraphs(&buf, s.Description)
+	r.writeDetails(&buf, s, 0)
+
+	headings := make([]core.Heading, 0, len(sections))
+
+	for _, sec := range sections {
+		headings = append(headings, sec.heading)
+
+		fmt.Fprintf(&buf, "<h%d id=\"%s\">%s</h%d>\n", sec.heading.Level, html.EscapeString(sec.heading.ID), html.EscapeString(sec.heading.Text), sec.heading.Level)
+
+		switch {
+		case sec.schema == nil:
+		case sec.heading.ID == propertiesAnchor:
+			r.writeProperties(&buf, s, 0)
+		case sec.heading.ID == itemsAnchor:
+			r.writeSchema(&buf, sec.schema, 0)
+		default:
+			fmt.Fprintf(&buf, "<p><em>%s</em></p>\n", r.typeLabel(sec.schema, 0))
+			r.writeSchema(&buf, sec.schema, 0)
+		}
+	}
+
+	return buf.Bytes(), headings, nil
+}
+
+// ExtractTitle returns the title of the schema.
+// Falls back to an empty string if the schema cannot be parsed or has no title.
+func (p *Processor) ExtractTitle(src []byte) string {
+	s, err := parseSchema(src)
+	if err != nil {
+		return ""
+	}
+
+	return s.Title
+}
+
+// ExtractSummary returns the first paragraph of the schema description. It returns an
+// empty string if the schema cannot be parsed or has no description.
+func (p *Processor) ExtractSummary(src []byte) string {
+	s, err := parseSchema(src)
+	if err != nil {
+		return ""
+	}
+
+	para, _, _ := strings.Cut(strings.TrimSpace(s.Description), "\n\n")
+
+	return strings.TrimSpace(para)
+}
+
+// ToPlainText extracts searchable plain text from a JSON Schema: its title and
+// description, then each section's heading followed by the names, titles, descriptions
+// and allowed values of its properties. Sections are emitted in the same order as
+// ExtractHeadings returns them, enabling accurate fragment-to-anchor mapping during
+// search result deep-linking.
+func (p *Processor) ToPlainText(src []byte) string {
+	s, err := parseSchema(src)
+	if err != nil {
+		return ""
+	}
+
+	var buf bytes.Buffer
+
+	mdwrite.WriteLine(&buf, s.Title)
+	mdwrite.WriteLine(&buf, s.Description)
+	writeText(&buf, s.variants(), 0)
+
+	for _, sec := range s.sections() {
+		mdwrite.WriteLine(&buf, sec.heading.Text)
+
+		switch {
+		case sec.schema == nil:
+		case sec.heading.ID == propertiesAnchor:
+			writePropertiesText(&buf, s, 0)
+		case sec.heading.ID == itemsAnchor:
+			writeSchemaText(&buf, sec.schema, 0)
+		default:
+			mdwrite.WriteLine(&buf, sec.schema.Title)
+			mdwrite.WriteLine(&buf, sec.schema.Description)
+			writeSchemaText(&buf, sec.schema, 0)
+		}
+	}
+
+	return strings.TrimSpace(buf.String())
+}
+
+// ExtractHeadings returns the headings of the rendered schema: "Properties" or "Items"
+// for the root schema, then "Definitions" and one per definition, with the anchor IDs
+// RenderHTML gives them.
+func (p *Processor) ExtractHeadings(src []byte) []core.Heading {
+	s, err := parseSchema(src)
+	if err != nil {
+		return nil
+	}
+
+	sections := s.sections()
+	if len(sections) == 0 {
+		return nil
+	}
+
+	headings := make([]core.Heading, 0, len(sections))
+	for _, sec := range sections {
+		headings = append(headings, sec.heading)
+	}
+
+	return headings
+}
+
+// ExtractCodeBlocks returns nil: JSON Schema documents have no fenced code blocks to
+// index for code search.
+func (p *Processor) ExtractCodeBlocks(_ []byte) []core.CodeBlock {
+	return nil
+}
+
+const (
+	// propertiesAnchor is the anchor ID of the root schema's properties.
+	propertiesAnchor = "properties"
+	// itemsAnchor is the anchor ID of the root schema's items.
+	itemsAnchor = "items"
+	// definitionsAnchor is the anchor ID of the definitions section.
+	definitionsAnchor = "definitions"
+)
+
+// sections returns the root schema's properties section, or its items section for an
+// array schema, followed by the definitions section and a section per definition: those
+// of $defs, then those of definitions.
+func (s *schema) sections() []section {
+	var sections []section
+
+	if len(s.Properties) > 0 {
+		sections = append(sections, section{schema: s, heading: core.Heading{ID: propertiesAnchor, Text: "Properties", Level: 2}})
+	} else if s.Items != nil && s.Items.hasChildren() {
+		sections = append(sections, section{schema: s.Items, heading: core.Heading{ID: itemsAnchor, Text: "Items", Level: 2}})
+	}
+
+	defs := append(append(namedSchemas{}, s.Defs...), s.Definitions...)
+	if len(defs) == 0 {
+		return sections
+	}
+
+	sections = append(sections, section{heading: core.Heading{ID: definitionsAnchor, Text: "Definitions", Level: 2}})
+
+	ids := mdwrite.NewAnchorSet()
+	for _, d := range defs {
+		sections = append(sections, section{schema: d.schema, heading: core.Heading{ID: ids.Unique("def-" + mdwrite.Slug(d.name)), Text: d.name, Level: 3}})
+	}
+
+	return sections
+}
+
+// variants returns the labelled subschemas of allOf, anyOf and oneOf.
+func (s *schema) variants() []variantGroup {
+	var groups []variantGroup
+
+	for _, g := range []variantGroup{{label: "All of", schemas: present(s.AllOf)}, {label: "Any of", schemas: present(s.AnyOf)}, {label: "One of", schemas: present(s.OneOf)}} {
+		if len(g.schemas) > 0 {
+			groups = append(groups, g)
+		}
+	}
+
+	return groups
+}
+
+// variantGroup is the list of subschemas of an allOf, anyOf or oneOf keyword.
+type variantGroup struct {
+	label   string
+	schemas []*schema
+}
+
+// items returns the schemas of an array's items: the positional ones first.
+func (s *schema) items() []*schema {
+	items := append(append([]*schema{}, s.PrefixItems...), s.tuple...)
+	if s.Items != nil {
+		if s.Items.tuple != nil {
+			return present(append(items, s.Items.tuple...))
+		}
+
+		items = append(items, s.Items)
+	}
+
+	return present(items)
+}
+
+// present returns schemas without the nil entries YAML nulls decode to.
+func present(schemas []*schema) []*schema {
+	return slices.DeleteFunc(slices.Clone(schemas), func(s *schema) bool { return s == nil })
+}
+
+// hasChildren reports whether the schema has nested schemas shown under it in the tree.
+func (s *schema) hasChildren() bool {
+	if s == nil {
+		return false
+	}
+
+	if len(s.Properties) > 0 || len(s.variants()) > 0 {
+		return true
+	}
+
+	for _, item := range s.items() {
+		if item.hasChildren() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isRequired reports whether the schema requires the named property.
+func (s *schema) isRequired(name string) bool {
+	return slices.Contains(s.Required, name)
+}
+
+// renderer writes the HTML of schemas, linking references to the definitions' sections.
+type renderer struct {
+	defs map[string]string // anchor IDs by definition reference, such as "#/$defs/Address"
+}
+
+// definitionAnchors returns the anchor IDs of the definition sections by the references
+// that point to them.
+func definitionAnchors(sections []section) map[string]string {
+	defs := make(map[string]string)
+
+	for _, sec := range sections {
+		if sec.heading.Level != 3 {
+			continue
+		}
+
+		for _, prefix := range []string{"#/$defs/", "#/definitions/"} {
+			ref := prefix + escapePointer(sec.heading.Text)
+			if _, ok := defs[ref]; !ok {
+				defs[ref] = sec.heading.ID
+			}
+		}
+	}
+
+	return defs
+}
+
+// writeSchema writes the description, keywords and nested schemas of s.
+func (r renderer) writeSchema(buf *bytes.Buffer, s *schema, depth int) {
+	mdwrite.WriteParagraphs(buf, s.Description)
+	r.writeDetails(buf, s, depth)
+	r.writeProperties(buf, s, depth)
+	r.writeItems(buf, s, depth)
+}
+
+// writeDetails writes the allowed values, default and examples of s and its allOf,
+// anyOf and oneOf subschemas.
+func (r renderer) writeDetails(buf *bytes.Buffer, s *schema, depth int) {
+	if len(s.Enum) > 0 {
+		writeValues(buf, "Allowed values", s.Enum)
+	}
+
+	if s.Const != nil {
+		writeValues(buf, "Constant", []any{s.Const})
+	}
+
+	if s.Default != nil {
+		writeValues(buf, "Default", []any{s.Default})
+	}
+
+	if len(s.Examples) > 0 {
+		writeValues(buf, "Examples", s.Examples)
+	}
+
+	if depth >= maxDepth {
+		return
+	}
+
+	for _, g := range s.variants() {
+		fmt.Fprintf(buf, "<p><strong>%s</strong></p>\n<ul class=\"schema-tree\">\n", g.label)
+
+		for i, v := range g.schemas {
+			r.writeNode(buf, "", strconv.Itoa(i+1), v, false, depth+1)
+		}
+
+		buf.WriteString("</ul>\n")
+	}
+}
+
+// writeProperties writes the properties of s as a list.
+func (r renderer) writeProperties(buf *bytes.Buffer, s *schema, depth int) {
+	if len(s.Properties) == 0 || depth >= maxDepth {
+		return
+	}
+
+	buf.WriteString("<ul class=\"schema-tree\">\n")
+
+	for _, prop := range s.Properties {
+		r.writeNode(buf, prop.name, "", prop.schema, s.isRequired(prop.name), depth+1)
+	}
+
+	buf.WriteString("</ul>\n")
+}
+
+// writeItems writes the item schemas of an array with nested schemas as a list.
+func (r renderer) writeItems(buf *bytes.Buffer, s *schema, depth int) {
+	items := s.items()
+	if depth >= maxDepth || !slicesContainChildren(items) {
+		return
+	}
+
+	buf.WriteString("<p><strong>Items</strong></p>\n<ul class=\"schema-tree\">\n")
+
+	for i, item := range items {
+		label := ""
+		if len(items) > 1 {
+			label = strconv.Itoa(i + 1)
+		}
+
+		r.writeNode(buf, "", label, item, false, depth+1)
+	}
+
+	buf.WriteString("</ul>\n")
+}
+
+// writeNode writes a list item for the property name, or the unnamed subschema labelled
+// label, with its type and badges. Schemas with nested schemas are collapsible, open at
+// the first level only.
+func (r renderer) writeNode(buf *bytes.Buffer, name, label string, s *schema, required bool, depth int) {
+	var line strings.Builder
+
+	switch {
+	case name != "":
+		fmt.Fprintf(&line, "<code>%s</code> ", html.EscapeString(name))
+	case label != "":
+		fmt.Fprintf(&line, "%s. ", html.EscapeString(label))
+	}
+
+	fmt.Fprintf(&line, "<em>%s</em>", r.typeLabel(s, 0))
+
+	for _, b := range s.badges(required) {
+		fmt.Fprintf(&line, " <span class=\"schema-badge schema-badge-%s\">%s</span>", b, b)
+	}
+
+	if s.Title != "" && s.Title != name {
+		fmt.Fprintf(&line, " — %s", html.EscapeString(s.Title))
+	}
+
+	if !s.hasChildren() {
+		fmt.Fprintf(buf, "<li>%s\n", line.String())
+		mdwrite.WriteParagraphs(buf, s.Description)
+		r.writeDetails(buf, s, depth)
+		buf.WriteString("</li>\n")
+
+		return
+	}
+
+	open := ""
+	if depth == 1 {
+		open = " open"
+	}
+
+	fmt.Fprintf(buf, "<li><details%s><summary>%s</summary>\n", open, line.String())
+	r.writeSchema(buf, s, depth)
+	buf.WriteString("</details></li>\n")
+}
+
+// badges returns the badges of a property: "required", "deprecated", "read-only",
+// "write-only" and "enum" for properties restricted to a list of values.
+func (s *schema) badges(required bool) []string {
+	var badges []string
+
+	for _, b := range []struct {
+		name string
+		set  bool
+	}{
+		{"required", required},
+		{"deprecated", s.Deprecated},
+		{"read-only", s.ReadOnly},
+		{"write-only", s.WriteOnly},
+		{"enum", len(s.Enum) > 0},
+	} {
+		if b.set {
+			badges = append(badges, b.name)
+		}
+	}
+
+	return badges
+}
+
+// typeLabel returns the escaped HTML naming the type of s, such as "string (date-time)",
+// "array of integer" or a link to the referenced definition.
+func (r renderer) typeLabel(s *schema, depth int) string {
+	switch {
+	case s.boolean != nil && *s.boolean:
+		return "any"
+	case s.boolean != nil:
+		return "never"
+	case s.Ref != "":
+		if id, ok := r.defs[s.Ref]; ok {
+			return fmt.Sprintf("<a href=\"#%s\">%s</a>", html.EscapeString(id), html.EscapeString(refName(s.Ref)))
+		}
+
+		return "<code>" + html.EscapeString(s.Ref) + "</code>"
+	}
+
+	types := s.Type
+	if len(types) == 0 {
+		switch {
+		case len(s.Properties) > 0:
+			types = typeList{"object"}
+		case len(s.items()) > 0:
+			types = typeList{"array"}
+		}
+	}
+
+	label := html.EscapeString(strings.Join(types, " | "))
+
+	if len(types) == 1 && types[0] == "array" && s.Items != nil && s.Items.tuple == nil && depth < maxDepth {
+		label += " of " + r.typeLabel(s.Items, depth+1)
+	}
+
+	if s.Format != "" {
+		label += " (" + html.EscapeString(s.Format) + ")"
+	}
+
+	if label == "" {
+		switch g := s.variants(); {
+		case len(g) > 0:
+			return strings.ToLower(g[0].label)
+		case len(s.Enum) > 0:
+			return "enum"
+		case s.Const != nil:
+			return "constant"
+		default:
+			return "any"
+		}
+	}
+
+	return label
+}
+
+// writePropertiesText writes the plain text of the properties of s.
+func writePropertiesText(buf *bytes.Buffer, s *schema, depth int) {
+	if depth >= maxDepth {
+		return
+	}
+
+	for _, prop := range s.Properties {
+		mdwrite.WriteLine(buf, prop.name)
+		mdwrite.WriteLine(buf, prop.schema.Title)
+		mdwrite.WriteLine(buf, prop.schema.Description)
+		writeSchemaText(buf, prop.schema, depth+1)
+	}
+}
+
+// writeSchemaText writes the plain text of the allowed values and nested schemas of s.
+func writeSchemaText(buf *bytes.Buffer, s *schema, depth int) {
+	if len(s.Enum) > 0 {
+		mdwrite.WriteLine(buf, strings.Join(formatValues(s.Enum), " "))
+	}
+
+	if depth >= maxDepth {
+		return
+	}
+
+	writeText(buf, s.variants(), depth)
+	writePropertiesText(buf, s, depth)
+
+	for _, item := range s.items() {
+		mdwrite.WriteLine(buf, item.Title)
+		mdwrite.WriteLine(buf, item.Description)
+		writeSchemaText(buf, item, depth+1)
+	}
+}
+
+// writeText writes the plain text of the subschemas of groups.
+func writeText(buf *bytes.Buffer, groups []variantGroup, depth int) {
+	for _, g := range groups {
+		for _, v := range g.schemas {
+			mdwrite.WriteLine(buf, v.Title)
+			mdwrite.WriteLine(buf, v.Description)
+			writeSchemaText(buf, v, depth+1)
+		}
+	}
+}
+
+// parseSchema parses a JSON Schema from raw bytes (YAML or JSON, which YAML parsing
+// accepts). Only the keywords the processor uses are decoded; the schema is not
+// validated.
+func parseSchema(src []byte) (*schema, error) {
+	var doc yaml.Node
+
+	if err := yaml.Unmarshal(src, &doc); err != nil {
+		return nil, fmt.Errorf("failed to load JSON Schema: %w", err)
+	}
+
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, errNotSchema
+	}
+
+	if hasAlias(&doc) {
+		return nil, errAlias
+	}
+
+	var s schema
+	if err := doc.Content[0].Decode(&s); err != nil {
+		return nil, fmt.Errorf("failed to load JSON Schema: %w", err)
+	}
+
+	return &s, nil
+}
+
+// hasAlias reports whether the YAML tree rooted at n contains an alias.
+func hasAlias(n *yaml.Node) bool {
+	if n.Kind == yaml.AliasNode {
+		return true
+	}
+
+	for _, c := range n.Content {
+		if hasAlias(c) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// dialect names the JSON Schema draft identified by a $schema URI.
+func dialect(uri string) string {
+	for _, d := range []string{"2020-12", "2019-09", "draft-07", "draft-06", "draft-04"} {
+		if strings.Contains(uri, d) {
+			return "JSON Schema " + strings.TrimPrefix(d, "draft-")
+		}
+	}
+
+	return "JSON Schema"
+}
+
+// refName returns the last segment of a JSON pointer reference.
+func refName(ref string) string {
+	name := ref[strings.LastIndex(ref, "/")+1:]
+
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(name)
+}
+
+// escapePointer escapes a definition name for use in a JSON pointer.
+func escapePointer(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
+
+// slicesContainChildren reports whether any of schemas has nested schemas.
+func slicesContainChildren(schemas []*schema) bool {
+	for _, s := range schemas {
+		if s.hasChildren() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeValues writes values as a labelled list of code spans.
+func writeValues(buf *bytes.Buffer, label string, values []any) {
+	fmt.Fprintf(buf, "<p>%s: ", label)
+
+	for i, v := range formatValues(values) {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+
+		fmt.Fprintf(buf, "<code>%s</code>", html.EscapeString(v))
+	}
+
+	buf.WriteString("</p>\n")
+}
+
+// formatValues formats schema values as JSON.
+func formatValues(values []any) []string {
+	formatted := make([]string, 0, len(values))
+
+	for _, v := range values {
+		data, err := json.Marshal(v)
+		if err != nil {
+			formatted = append(formatted, fmt.Sprint(v))
+			continue
+		}
+
+		formatted = append(formatted, string(data))
+	}
+
+	return formatted
+}
@@ -0,0 +1,159 @@
+package jsonschema
+
+import (
+	"testing"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const orderSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "$id": "https://example.com/order.schema.json",
+  "title": "Order",
+  "description": "An order placed in the <b>shop</b>.\n\nOrders are immutable once paid.",
+  "type": "object",
+  "required": ["id", "status"],
+  "properties": {
+    "id": {"type": "string", "format": "uuid", "description": "Unique order ID."},
+    "status": {"type": "string", "enum": ["pending", "paid"], "default": "pending"},
+    "shipping": {"$ref": "#/$defs/Address"},
+    "lines": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "properties": {
+          "sku": {"type": "string", "title": "Stock keeping unit"},
+          "quantity": {"type": "integer", "deprecated": true}
+        }
+      }
+    },
+    "note": true
+  },
+  "$defs": {
+    "Address": {
+      "type": "object",
+      "description": "A postal address.",
+      "properties": {"city": {"type": "string"}}
+    }
+  }
+}`
+
+const legacySchemaYAML = `$schema: http://json-schema.org/draft-07/schema#
+type: array
+items:
+  oneOf:
+    - $ref: '#/definitions/Point'
+    - type: string
+definitions:
+  Point:
+    type: object
+    properties:
+      x: {type: number}
+  Point 2:
+    type: [number, "null"]
+`
+
+func TestProcessor_ContentTypeInfo(t *testing.T) {
+	info := New().ContentTypeInfo()
+
+	assert.Equal(t, core.ContentTypeJSONSchema, info.Name)
+	assert.Equal(t, core.ContentTypeJSONSchema, core.DetectContentTypeIn([]core.ContentTypeInfo{info}, "schemas/order.json", []byte(orderSchema)))
+	assert.Equal(t, core.ContentTypeJSONSchema, core.DetectContentTypeIn([]core.ContentTypeInfo{info}, "schemas/point.schema.yaml", []byte("type: object")))
+}
+
+func TestProcessor_ExtractTitleAndSummary(t *testing.T) {
+	p := New()
+
+	assert.Equal(t, "Order", p.ExtractTitle([]byte(orderSchema)))
+	assert.Equal(t, "An order placed in the <b>shop</b>.", p.ExtractSummary([]byte(orderSchema)))
+	assert.Empty(t, p.ExtractTitle([]byte(legacySchemaYAML)))
+	assert.Empty(t, p.ExtractTitle([]byte("[1, 2]")))
+}
+
+func TestProcessor_ExtractHeadings(t *testing.T) {
+	p := New()
+
+	assert.Equal(t, []core.Heading{
+		{ID: "properties", Text: "Properties", Level: 2},
+		{ID: "definitions", Text: "Definitions", Level: 2},
+		{ID: "def-address", Text: "Address", Level: 3},
+	}, p.ExtractHeadings([]byte(orderSchema)))
+
+	assert.Equal(t, []core.Heading{
+		{ID: "items", Text: "Items", Level: 2},
+		{ID: "definitions", Text: "Definitions", Level: 2},
+		{ID: "def-point", Text: "Point", Level: 3},
+		{ID: "def-point-2", Text: "Point 2", Level: 3},
+	}, p.ExtractHeadings([]byte(legacySchemaYAML)))
+
+	assert.Nil(t, p.ExtractHeadings([]byte(`{"$schema": "https://json-schema.org/draft/2020-12/schema", "type": "string"}`)))
+}
+
+func TestProcessor_RenderHTML(t *testing.T) {
+	p := New()
+
+	out, headings, err := p.RenderHTML([]byte(orderSchema))
+	require.NoError(t, err)
+	assert.Len(t, headings, 3)
+
+	html := string(out)
+	assert.Contains(t, html, "<h1>Order</h1>")
+	assert.Contains(t, html, "<p><em>JSON Schema 2020-12</em> <code>https://example.com/order.schema.json</code></p>")
+	assert.Contains(t, html, "<p>An order placed in the &lt;b&gt;shop&lt;/b&gt;.</p>")
+	assert.Contains(t, html, `<li><code>id</code> <em>string (uuid)</em> <span class="schema-badge schema-badge-required">required</span>`)
+	assert.Contains(t, html, `<span class="schema-badge schema-badge-enum">enum</span>`)
+	assert.Contains(t, html, "<p>Allowed values: <code>&#34;pending&#34;</code>, <code>&#34;paid&#34;</code></p>")
+	assert.Contains(t, html, `<li><code>shipping</code> <em><a href="#def-address">Address</a></em>`)
+	assert.Contains(t, html, `<li><details open><summary><code>lines</code> <em>array of object</em></summary>`)
+	assert.Contains(t, html, `<span class="schema-badge schema-badge-deprecated">deprecated</span>`)
+	assert.Contains(t, html, "<code>sku</code> <em>string</em> — Stock keeping unit")
+	assert.Contains(t, html, "<li><code>note</code> <em>any</em>")
+	assert.Contains(t, html, `<h3 id="def-address">Address</h3>`)
+
+	out, _, err = p.RenderHTML([]byte(legacySchemaYAML))
+	require.NoError(t, err)
+
+	html = string(out)
+	assert.Contains(t, html, "<p><em>JSON Schema 07</em></p>")
+	assert.Contains(t, html, "<p><strong>One of</strong></p>")
+	assert.Contains(t, html, `<li>1. <em><a href="#def-point">Point</a></em>`)
+	assert.Contains(t, html, "<em>number | null</em>")
+
+	_, _, err = p.RenderHTML([]byte("- not a schema"))
+	assert.Error(t, err)
+}
+
+func TestProcessor_ToPlainText(t *testing.T) {
+	text := New().ToPlainText([]byte(orderSchema))
+
+	assert.Contains(t, text, "Order\nAn order placed")
+	assert.Contains(t, text, "Properties\nid\nUnique order ID.\nstatus\n\"pending\" \"paid\"\n")
+	assert.Contains(t, text, "sku\nStock keeping unit\nquantity\n")
+	assert.Contains(t, text, "Definitions\nAddress\nA postal address.\ncity")
+}
+
+func TestParseSchema_Invalid(t *testing.T) {
+	tests := []struct {
+		wantErr error
+		name    string
+		src     string
+	}{
+		{name: "not a mapping", src: "- a\n- b\n", wantErr: errNotSchema},
+		{name: "empty", src: "", wantErr: errNotSchema},
+		{name: "alias", src: "a: &a {type: string}\nproperties:\n  b: *a\n", wantErr: errAlias},
+		{name: "invalid subschema", src: `{"properties": {"a": "text"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseSchema([]byte(tt.src))
+			require.Error(t, err)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+// Package linkcheck checks external links of published documents over HTTP.
+package linkcheck
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCacheTTL     = 6 * time.Hour
+	defaultHostInterval = time.Second
+	defaultTimeout      = 10 * time.Second
+	userAgent           = "omnidex-linkcheck"
+)
+
+// Config configures scheduled checks of external links.
+type Config struct {
+	// Interval is the time between two checks of every published document (default 24h).
+	Interval time.Duration `mapstructure:"interval"`
+	// CacheTTL is how long the result of checking a URL is reused (default 6h).
+	CacheTTL time.Duration `mapstructure:"cache_ttl"`
+	// HostInterval is the minimum time between two requests to the same host (default 1s).
+	HostInterval time.Duration `mapstructure:"host_interval"`
+	// Timeout bounds a single link check (default 10s).
+	Timeout time.Duration `mapstructure:"timeout"`
+	Enabled bool          `mapstructure:"enabled"`
+}
+
+// cachedResult is the outcome of a link check and when it expires.
+type cachedResult struct {
+	expires time.Time
+	err     error
+}
+
+// Checker checks external links with HEAD requests, falling back to GET for servers that
+// do not support HEAD. Requests to the same host are spaced by the configured host
+// interval and results are cached.
+type Checker struct {
+	httpClient *http.Client
+	now        func() time.Time
+	cache      map[string]cachedResult
+	nextSlot   map[string]time.Time
+	cfg        Config
+	mu         sync.Mutex
+}
+
+// New creates a Checker for the given configuration.
+func New(cfg Config) *Checker {
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = defaultCacheTTL
+	}
+
+	if cfg.HostInterval <= 0 {
+		cfg.HostInterval = defaultHostInterval
+	}
+
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+
+	return &Checker{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+		now:        time.Now,
+		cache:      make(map[string]cachedResult),
+		nextSlot:   make(map[string]time.Time),
+	}
+}
+
+// CheckLink reports whether rawURL is dead. It returns an error for links that cannot be
+// reached or respond with a client or server error. Responses that do not tell whether
+// the page exists, such as 401, 403 and 429, count as alive.
+func (c *Checker) CheckLink(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid URL %q", rawURL)
+	}
+
+	c.mu.Lock()
+	cached, ok := c.cache[rawURL]
+	c.mu.Unlock()
+
+	if ok && c.now().Before(cached.expires) {
+		return cached.err
+	}
+
+	checkErr := c.check(ctx, u)
+	if ctx.Err() != nil {
+		// An aborted check says nothing about the link.
+		return checkErr
+	}
+
+	c.mu.Lock()
+	c.cache[rawURL] = cachedResult{err: checkErr, expires: c.now().Add(c.cfg.CacheTTL)}
+	c.mu.Unlock()
+
+	return checkErr
+}
+
+// check requests u and classifies the response.
+func (c *Checker) check(ctx context.Context, u *url.URL) error {
+	status, err := c.request(ctx, http.MethodHead, u)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		status, err = c.request(ctx, http.MethodGet, u)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case status == http.StatusUnauthorized, status == http.StatusForbidden, status == http.StatusTooManyRequests:
+		return nil
+	case status >= http.StatusBadRequest:
+		return fmt.Errorf("HTTP %d", status)
+	}
+
+	return nil
+}
+
+// request sends a single request once the host's rate limit allows it and returns the
+// response status.
+func (c *Checker) request(ctx context.Context, method string, u *url.URL) (int, error) {
+	if err := c.waitForHost(ctx, strings.ToLower(u.Host)); err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), http.NoBody)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("request failed: %w", err)
+	}
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1))
+	_ = resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
+
+// waitForHost blocks until the next request slot of host, so that requests to the same
+// host are at least the host interval apart.
+func (c *Checker) waitForHost(ctx context.Context, host string) error {
+	c.mu.Lock()
+	now := c.now()
+
+	slot := c.nextSlot[host]
+	if slot.Before(now) {
+		slot = now
+	}
+
+	c.nextSlot[host] = slot.Add(c.cfg.HostInterval)
+	c.mu.Unlock()
+
+	wait := slot.Sub(now)
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("link check cancelled: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,116 @@
+package linkcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newLinkServer returns a server whose paths answer with fixed statuses and counts the
+// requests it receives per method and path.
+func newLinkServer(t *testing.T) (*httptest.Server, func(key string) int) {
+	t.Helper()
+
+	var (
+		mu    sync.Mutex
+		calls = make(map[string]int)
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls[r.Method+" "+r.URL.Path]++
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/ok":
+			w.WriteHeader(http.StatusOK)
+		case "/gone":
+			w.WriteHeader(http.StatusNotFound)
+		case "/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/private":
+			w.WriteHeader(http.StatusForbidden)
+		case "/no-head":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+
+	t.Cleanup(srv.Close)
+
+	return srv, func(key string) int {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return calls[key]
+	}
+}
+
+func TestChecker_CheckLink(t *testing.T) {
+	srv, calls := newLinkServer(t)
+
+	c := New(Config{HostInterval: time.Nanosecond})
+
+	assert.NoError(t, c.CheckLink(t.Context(), srv.URL+"/ok"))
+	assert.EqualError(t, c.CheckLink(t.Context(), srv.URL+"/gone"), "HTTP 404")
+	assert.EqualError(t, c.CheckLink(t.Context(), srv.URL+"/broken"), "HTTP 500")
+	assert.NoError(t, c.CheckLink(t.Context(), srv.URL+"/private"), "a forbidden page may exist")
+
+	assert.NoError(t, c.CheckLink(t.Context(), srv.URL+"/no-head"))
+	assert.Equal(t, 1, calls("GET /no-head"), "falls back to GET when HEAD is not allowed")
+
+	assert.ErrorContains(t, c.CheckLink(t.Context(), "ftp://example.com/file"), "invalid URL")
+
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+
+	assert.ErrorContains(t, c.CheckLink(t.Context(), unreachable.URL+"/page"), "request failed")
+}
+
+func TestChecker_Cache(t *testing.T) {
+	srv, calls := newLinkServer(t)
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	c := New(Config{CacheTTL: time.Hour, HostInterval: time.Nanosecond})
+	c.now = func() time.Time { return now }
+
+	require.EqualError(t, c.CheckLink(t.Context(), srv.URL+"/gone"), "HTTP 404")
+	require.EqualError(t, c.CheckLink(t.Context(), srv.URL+"/gone"), "HTTP 404")
+	assert.Equal(t, 1, calls("HEAD /gone"), "the cached result is reused")
+
+	now = now.Add(time.Hour)
+
+	require.EqualError(t, c.CheckLink(t.Context(), srv.URL+"/gone"), "HTTP 404")
+	assert.Equal(t, 2, calls("HEAD /gone"), "an expired result is checked again")
+}
+
+func TestChecker_HostInterval(t *testing.T) {
+	srv, _ := newLinkServer(t)
+
+	c := New(Config{HostInterval: 50 * time.Millisecond})
+
+	start := time.Now()
+
+	require.NoError(t, c.CheckLink(t.Context(), srv.URL+"/ok"))
+	require.NoError(t, c.CheckLink(t.Context(), srv.URL+"/private"))
+	require.NoError(t, c.CheckLink(t.Context(), srv.URL+"/no-head"))
+
+	assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond, "four requests to one host are spaced by the interval")
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	assert.ErrorContains(t, c.CheckLink(ctx, srv.URL+"/gone"), "link check cancelled")
+	assert.NotContains(t, c.cache, srv.URL+"/gone", "a cancelled check is not cached")
+}
@@ -0,0 +1,134 @@
+// Package llm generates document summaries with a language model served through an
+// OpenAI-compatible chat completions API.
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+const (
+	requestTimeout = 30 * time.Second
+	// defaultMaxInput is the default number of bytes of plain text sent to the model.
+	defaultMaxInput = 8000
+	// maxErrorBody bounds the part of an error response included in the returned error.
+	maxErrorBody = 512
+
+	systemPrompt = "You write the description shown for a documentation page in search results and link previews. " +
+		"Reply with one or two plain sentences, at most 200 characters, saying what the page covers. " +
+		"Do not use markdown and do not start with \"This document\"."
+)
+
+// Config configures summary generation. Summaries are generated when URL and Model are set.
+type Config struct {
+	// URL is the base URL of the OpenAI-compatible API, e.g. https://api.openai.com/v1.
+	URL string `mapstructure:"url"`
+	// Model is the model used to generate summaries.
+	Model string `mapstructure:"model"`
+	// APIKey is sent as a bearer token when set.
+	APIKey string `mapstructure:"api_key" redact:"true"`
+	// MaxInput caps the bytes of document text sent to the model (default 8000).
+	MaxInput int `mapstructure:"max_input"`
+}
+
+// Enabled reports whether summary generation is configured.
+func (c Config) Enabled() bool {
+	return c.URL != "" && c.Model != ""
+}
+
+// Summarizer implements core.Summarizer with a chat completions API.
+type Summarizer struct {
+	httpClient *http.Client
+	cfg        Config
+}
+
+// New creates a Summarizer for the given configuration.
+func New(cfg Config) *Summarizer {
+	if cfg.MaxInput <= 0 {
+		cfg.MaxInput = defaultMaxInput
+	}
+
+	cfg.URL = strings.TrimSuffix(cfg.URL, "/")
+
+	return &Summarizer{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: requestTimeout},
+	}
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// Summarize asks the model for a short description of the document from its title and the
+// start of its plain text.
+func (s *Summarizer) Summarize(ctx context.Context, doc *core.Document, plainText string) (string, error) {
+	if len(plainText) > s.cfg.MaxInput {
+		plainText = strings.ToValidUTF8(plainText[:s.cfg.MaxInput], "")
+	}
+
+	body, err := json.Marshal(chatRequest{
+		Model: s.cfg.Model,
+		Messages: []chatMessage{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: "Title: " + doc.Title + "\n\n" + plainText},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal completion request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.URL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create completion request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if s.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.cfg.APIKey)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send completion request: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return "", fmt.Errorf("model API returned HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var completion chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&completion); err != nil {
+		return "", fmt.Errorf("failed to decode completion response: %w", err)
+	}
+
+	if len(completion.Choices) == 0 {
+		return "", fmt.Errorf("model API returned no choices")
+	}
+
+	return strings.TrimSpace(completion.Choices[0].Message.Content), nil
+}
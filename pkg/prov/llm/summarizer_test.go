@@ -0,0 +1,52 @@
+package llm
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Enabled(t *testing.T) {
+	assert.False(t, Config{}.Enabled())
+	assert.False(t, Config{URL: "https://api.example.com/v1"}.Enabled())
+	assert.True(t, Config{URL: "https://api.example.com/v1", Model: "small"}.Enabled())
+}
+
+func TestSummarizer_Summarize(t *testing.T) {
+	var got chatRequest
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer sk-test", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+
+		if got.Model == "broken" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error":"rate limited"}`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":" Explains how to deploy the billing service. "}}]}`))
+	}))
+	defer srv.Close()
+
+	s := New(Config{URL: srv.URL + "/v1/", Model: "small", APIKey: "sk-test", MaxInput: 10})
+
+	summary, err := s.Summarize(t.Context(), &core.Document{Title: "Deploy"}, strings.Repeat("x", 20))
+	require.NoError(t, err)
+	assert.Equal(t, "Explains how to deploy the billing service.", summary)
+
+	require.Len(t, got.Messages, 2)
+	assert.Equal(t, "small", got.Model)
+	assert.Equal(t, "Title: Deploy\n\n"+strings.Repeat("x", 10), got.Messages[1].Content, "the text is capped at MaxInput")
+
+	_, err = New(Config{URL: srv.URL + "/v1", Model: "broken", APIKey: "sk-test"}).Summarize(t.Context(), &core.Document{}, "text")
+	assert.ErrorContains(t, err, "model API returned HTTP 429: {\"error\":\"rate limited\"}")
+}
@@ -0,0 +1,102 @@
+// Package mail sends emails through an SMTP server.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+// defaultPort is the SMTP submission port, on which the connection is upgraded with
+// STARTTLS when the server supports it.
+const defaultPort = 587
+
+// Config configures the SMTP server emails are sent through. Sending is enabled when Host
+// and From are set.
+type Config struct {
+	Host     string `mapstructure:"host"`
+	Username string `mapstructure:"username"` // Authenticates with PLAIN auth when set.
+	Password string `mapstructure:"password" redact:"true"`
+	From     string `mapstructure:"from"` // Sender address, e.g. Omnidex <docs@example.com>.
+	Port     int    `mapstructure:"port"` // Default 587.
+}
+
+// Enabled reports whether an SMTP server is configured.
+func (c Config) Enabled() bool {
+	return c.Host != "" && c.From != ""
+}
+
+// Sender implements core.Mailer with an SMTP server.
+type Sender struct {
+	cfg Config
+}
+
+// New creates a Sender for the given configuration.
+func New(cfg Config) *Sender {
+	if cfg.Port == 0 {
+		cfg.Port = defaultPort
+	}
+
+	return &Sender{cfg: cfg}
+}
+
+// Send sends a plain-text email. The connection is upgraded with STARTTLS when the server
+// supports it, which PLAIN authentication requires for servers other than localhost.
+func (s *Sender) Send(_ context.Context, e core.Email) error {
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+	}
+
+	from, err := envelopeAddress(s.cfg.From)
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+
+	if err := smtp.SendMail(addr, auth, from, e.To, message(s.cfg.From, e, time.Now())); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
+
+// envelopeAddress returns the bare address of a sender such as "Omnidex <docs@example.com>".
+func envelopeAddress(from string) (string, error) {
+	if i := strings.LastIndex(from, "<"); i >= 0 {
+		addr := strings.TrimSuffix(strings.TrimSpace(from[i+1:]), ">")
+		if addr == "" {
+			return "", fmt.Errorf("invalid sender address %q", from)
+		}
+
+		return addr, nil
+	}
+
+	return strings.TrimSpace(from), nil
+}
+
+// message formats a plain-text email with its headers.
+func message(from string, e core.Email, now time.Time) []byte {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(e.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", e.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(e.Body, "\r\n", "\n"), "\n", "\r\n"))
+
+	return b.Bytes()
+}
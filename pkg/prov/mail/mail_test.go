@@ -0,0 +1,54 @@
+package mail
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Enabled(t *testing.T) {
+	assert.False(t, Config{}.Enabled())
+	assert.False(t, Config{Host: "smtp.example.com"}.Enabled())
+	assert.True(t, Config{Host: "smtp.example.com", From: "docs@example.com"}.Enabled())
+}
+
+func TestNew_DefaultPort(t *testing.T) {
+	assert.Equal(t, 587, New(Config{Host: "smtp.example.com"}).cfg.Port)
+	assert.Equal(t, 25, New(Config{Host: "smtp.example.com", Port: 25}).cfg.Port)
+}
+
+func TestEnvelopeAddress(t *testing.T) {
+	addr, err := envelopeAddress("Omnidex <docs@example.com>")
+	require.NoError(t, err)
+	assert.Equal(t, "docs@example.com", addr)
+
+	addr, err = envelopeAddress(" docs@example.com ")
+	require.NoError(t, err)
+	assert.Equal(t, "docs@example.com", addr)
+
+	_, err = envelopeAddress("Omnidex <>")
+	assert.Error(t, err)
+}
+
+func TestMessage(t *testing.T) {
+	now := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+
+	msg := message("Omnidex <docs@example.com>", core.Email{
+		To:      []string{"a@example.com", "b@example.com"},
+		Subject: "Digest – 2 changes",
+		Body:    "Line one\nLine two\n",
+	}, now)
+
+	assert.Equal(t, "From: Omnidex <docs@example.com>\r\n"+
+		"To: a@example.com, b@example.com\r\n"+
+		"Subject: =?utf-8?q?Digest_=E2=80=93_2_changes?=\r\n"+
+		"Date: Mon, 05 Jan 2026 09:00:00 +0000\r\n"+
+		"MIME-Version: 1.0\r\n"+
+		"Content-Type: text/plain; charset=utf-8\r\n"+
+		"Content-Transfer-Encoding: 8bit\r\n"+
+		"\r\n"+
+		"Line one\r\nLine two\r\n", string(msg))
+}
@@ -0,0 +1,77 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// benchDocument returns a markdown document resembling a long runbook: sections with
+// paragraphs, lists, tables, code blocks, links and emphasis.
+func benchDocument(sections int) []byte {
+	var b strings.Builder
+
+	b.WriteString("# Payments Service Runbook\n\n[[TOC]]\n\n")
+	b.WriteString("This runbook covers the **payments service**: deployment, monitoring and incident response.\n\n")
+
+	for i := range sections {
+		fmt.Fprintf(&b, "## Section %d: Handling failure mode %d\n\n", i, i)
+		fmt.Fprintf(&b, "When the `worker-%d` queue backs up, check the [dashboard](https://grafana.example.com/d/%d) first. ", i, i)
+		b.WriteString("Latency above the *p99 budget* usually means the database is saturated, see [scaling](./scaling.md#database).\n\n")
+
+		fmt.Fprintf(&b, "### Diagnosis %d\n\n", i)
+		b.WriteString("1. Confirm the alert in the on-call channel.\n2. Check recent deploys with `kubectl rollout history`.\n")
+		b.WriteString("3. Inspect the error budget:\n   - burn rate over one hour\n   - burn rate over six hours\n\n")
+
+		b.WriteString("| Metric | Threshold | Action |\n|--------|-----------|--------|\n")
+		b.WriteString("| queue depth | 10000 | scale workers |\n| error rate | 1% | roll back |\n\n")
+
+		b.WriteString("```bash\nkubectl -n payments scale deployment/worker --replicas=12\nkubectl -n payments rollout status deployment/worker\n```\n\n")
+		b.WriteString("> Never drain the queue manually: messages carry idempotency keys that expire after one hour.\n\n")
+	}
+
+	return []byte(b.String())
+}
+
+func BenchmarkRenderer_RenderHTML(b *testing.B) {
+	r, err := New(Config{})
+	require.NoError(b, err)
+
+	src := benchDocument(40)
+
+	b.SetBytes(int64(len(src)))
+
+	for b.Loop() {
+		if _, _, err := r.RenderHTML(src); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRenderer_ToPlainText(b *testing.B) {
+	r, err := New(Config{})
+	require.NoError(b, err)
+
+	src := benchDocument(40)
+
+	b.SetBytes(int64(len(src)))
+
+	for b.Loop() {
+		r.ToPlainText(src)
+	}
+}
+
+func BenchmarkRenderer_ExtractHeadings(b *testing.B) {
+	r, err := New(Config{})
+	require.NoError(b, err)
+
+	src := benchDocument(40)
+
+	b.SetBytes(int64(len(src)))
+
+	for b.Loop() {
+		r.ExtractHeadings(src)
+	}
+}
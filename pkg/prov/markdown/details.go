@@ -0,0 +1,160 @@
+package markdown
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+const (
+	// rawHTMLOmitted is the placeholder goldmark writes in place of raw HTML in safe mode.
+	rawHTMLOmitted = "<!-- raw HTML omitted -->"
+
+	// detailsRendererPriority runs ahead of goldmark's default HTML renderer (priority 1000)
+	// so raw HTML nodes are handled by the details filter.
+	detailsRendererPriority = 500
+)
+
+// htmlTagRe matches a single HTML tag.
+var htmlTagRe = regexp.MustCompile(`<[^>]*>`)
+
+// detailsTagRe matches the only raw HTML tags passed through in safe mode: opening and
+// closing <details> and <summary> tags. Attributes are captured so that the "open"
+// attribute can be carried over; all other attributes are dropped.
+var detailsTagRe = regexp.MustCompile(`(?i)^<(/?)(details|summary)(\s[^>]*)?>$`)
+
+// openAttrRe matches a bare or valued "open" attribute.
+var openAttrRe = regexp.MustCompile(`(?i)(^|\s)open(\s|=|/|$)`)
+
+// detailsRenderer renders raw HTML nodes, letting collapsible <details>/<summary> sections
+// through while omitting any other raw HTML, as goldmark does in safe mode. Other tags
+// mixed into a details block are dropped and their text is escaped. The output still goes
+// through the bluemonday policy.
+type detailsRenderer struct{}
+
+// RegisterFuncs registers the raw HTML renderers with goldmark.
+func (d *detailsRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindHTMLBlock, d.renderHTMLBlock)
+	reg.Register(ast.KindRawHTML, d.renderRawHTML)
+}
+
+func (d *detailsRenderer) renderHTMLBlock(w util.BufWriter, src []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	block, ok := node.(*ast.HTMLBlock)
+	if !ok {
+		return ast.WalkContinue, nil
+	}
+
+	if out, ok := filterDetailsHTML(htmlBlockSource(block, src)); ok {
+		_, _ = w.Write(out)
+	} else {
+		_, _ = w.WriteString(rawHTMLOmitted + "\n")
+	}
+
+	return ast.WalkSkipChildren, nil
+}
+
+func (d *detailsRenderer) renderRawHTML(w util.BufWriter, src []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkSkipChildren, nil
+	}
+
+	raw, ok := node.(*ast.RawHTML)
+	if !ok {
+		return ast.WalkContinue, nil
+	}
+
+	var buf bytes.Buffer
+
+	for i := range raw.Segments.Len() {
+		seg := raw.Segments.At(i)
+		buf.Write(seg.Value(src))
+	}
+
+	if out, ok := filterDetailsHTML(buf.Bytes()); ok {
+		_, _ = w.Write(out)
+	} else {
+		_, _ = w.WriteString(rawHTMLOmitted)
+	}
+
+	return ast.WalkSkipChildren, nil
+}
+
+// htmlBlockSource returns the raw content of an HTML block, including its closure line.
+func htmlBlockSource(block *ast.HTMLBlock, src []byte) []byte {
+	var buf bytes.Buffer
+
+	lines := block.Lines()
+	for i := range lines.Len() {
+		line := lines.At(i)
+		buf.Write(line.Value(src))
+	}
+
+	if block.HasClosure() {
+		buf.Write(block.ClosureLine.Value(src))
+	}
+
+	return buf.Bytes()
+}
+
+// filterDetailsHTML re-emits raw HTML that contains <details>/<summary> tags, normalizing
+// those tags, dropping any other tag and escaping the text. It returns false if the raw
+// HTML has no details or summary tag at all.
+func filterDetailsHTML(raw []byte) ([]byte, bool) {
+	var (
+		out   bytes.Buffer
+		found bool
+	)
+
+	last := 0
+
+	for _, loc := range htmlTagRe.FindAllIndex(raw, -1) {
+		out.WriteString(html.EscapeString(string(raw[last:loc[0]])))
+		last = loc[1]
+
+		m := detailsTagRe.FindSubmatch(raw[loc[0]:loc[1]])
+		if m == nil {
+			continue
+		}
+
+		found = true
+
+		out.WriteByte('<')
+		out.Write(m[1])
+
+		name := strings.ToLower(string(m[2]))
+		out.WriteString(name)
+
+		if len(m[1]) == 0 && name == "details" && openAttrRe.Match(m[3]) {
+			out.WriteString(" open")
+		}
+
+		out.WriteByte('>')
+	}
+
+	if !found {
+		return nil, false
+	}
+
+	out.WriteString(html.EscapeString(string(raw[last:])))
+
+	return out.Bytes(), true
+}
+
+// detailsText returns the text content of raw HTML that passes the details filter, such
+// as <summary> titles, so it can be included in plain-text extraction.
+func detailsText(raw []byte) (string, bool) {
+	if _, ok := filterDetailsHTML(raw); !ok {
+		return "", false
+	}
+
+	return strings.TrimSpace(htmlTagRe.ReplaceAllString(string(raw), " ")), true
+}
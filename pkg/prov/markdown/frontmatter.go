@@ -0,0 +1,119 @@
+package markdown
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"gopkg.in/yaml.v3"
+)
+
+// frontmatter holds the fields of a document's YAML frontmatter the renderer uses. Other
+// fields, such as "home", are ignored here.
+type frontmatter struct {
+	Title       string  `yaml:"title"`
+	Description string  `yaml:"description"`
+	Tags        tagList `yaml:"tags"`
+	Order       int     `yaml:"order"`
+	Draft       bool    `yaml:"draft"`
+}
+
+// tagList is the tags field of the frontmatter, given either as a YAML sequence or as a
+// single comma-separated string.
+type tagList []string
+
+// UnmarshalYAML decodes a sequence of tags or a comma-separated string of them.
+func (t *tagList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*t = nil
+
+		for tag := range strings.SplitSeq(node.Value, ",") {
+			*t = append(*t, strings.TrimSpace(tag))
+		}
+
+		return nil
+	}
+
+	var tags []string
+	if err := node.Decode(&tags); err != nil {
+		return err
+	}
+
+	*t = tags
+
+	return nil
+}
+
+// splitFrontmatter separates a YAML frontmatter block, delimited by "---" lines at the start
+// of src, from the markdown body following it. Fields of the wrong type are left at their
+// zero value. When src does not start with a block whose content is a YAML mapping, it is
+// returned unchanged as the body, so that a leading thematic break is not mistaken for
+// frontmatter.
+func splitFrontmatter(src []byte) (frontmatter, []byte) {
+	var fm frontmatter
+
+	rest, ok := bytes.CutPrefix(bytes.TrimPrefix(src, []byte("\ufeff")), []byte("---"))
+	if !ok {
+		return fm, src
+	}
+
+	rest, ok = cutLineEnd(rest)
+	if !ok {
+		return fm, src
+	}
+
+	block := rest
+
+	for len(rest) > 0 {
+		line, next, _ := bytes.Cut(rest, []byte("\n"))
+
+		if delim := string(bytes.TrimRight(line, " \t\r")); delim == "---" || delim == "..." {
+			var node yaml.Node
+			if err := yaml.Unmarshal(block[:len(block)-len(rest)], &node); err != nil ||
+				len(node.Content) != 1 || node.Content[0].Kind != yaml.MappingNode {
+				return frontmatter{}, src
+			}
+
+			// A type error still decodes the fields that have the expected type.
+			_ = node.Content[0].Decode(&fm)
+
+			return fm, next
+		}
+
+		rest = next
+	}
+
+	return fm, src
+}
+
+// cutLineEnd returns the text after the line break that ends the line at the start of b,
+// and false if that line has other text.
+func cutLineEnd(b []byte) ([]byte, bool) {
+	line, rest, found := bytes.Cut(b, []byte("\n"))
+	if !found || len(bytes.TrimRight(line, " \t\r")) > 0 {
+		return nil, false
+	}
+
+	return rest, true
+}
+
+// body returns the markdown of src without its frontmatter, cut to the document size
+// limit; see truncate.
+func (r *Renderer) body(src []byte) []byte {
+	_, body := splitFrontmatter(r.truncate(src))
+
+	return body
+}
+
+// ExtractMetadata returns the description, tags, sidebar order and draft status declared
+// in the frontmatter of the markdown content.
+func (r *Renderer) ExtractMetadata(src []byte) core.DocumentMetadata {
+	fm, _ := splitFrontmatter(r.truncate(src))
+
+	return core.DocumentMetadata{
+		Description: strings.TrimSpace(fm.Description),
+		Tags:        fm.Tags,
+		Order:       fm.Order,
+		Draft:       fm.Draft,
+	}
+}
@@ -0,0 +1,112 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const frontmatterDoc = `---
+title: Deploying to production
+description: How releases reach production.
+tags: [Ops, ci]
+order: 2
+draft: true
+home: false
+---
+
+# Deploy
+
+Ship it.
+`
+
+func TestRenderer_Frontmatter(t *testing.T) {
+	r, err := New(Config{})
+	require.NoError(t, err)
+
+	src := []byte(frontmatterDoc)
+
+	assert.Equal(t, "Deploying to production", r.ExtractTitle(src))
+	assert.Equal(t, core.DocumentMetadata{
+		Description: "How releases reach production.",
+		Tags:        []string{"Ops", "ci"},
+		Order:       2,
+		Draft:       true,
+	}, r.ExtractMetadata(src))
+
+	// The frontmatter is neither rendered nor indexed.
+	html, headings, err := r.RenderHTML(src)
+	require.NoError(t, err)
+	assert.Equal(t, "<h1 id=\"deploy\">Deploy</h1>\n<p>Ship it.</p>\n", string(html))
+	assert.Equal(t, []core.Heading{{ID: "deploy", Text: "Deploy", Level: 1}}, headings)
+	assert.Equal(t, "Deploy\nShip it.", r.ToPlainText(src))
+	assert.Equal(t, "Ship it.", r.ExtractSummary(src))
+	assert.Equal(t, 0, r.MapAnchors(src)[0].Offset)
+}
+
+func TestRenderer_FrontmatterFields(t *testing.T) {
+	r, err := New(Config{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		src   string
+		title string
+		meta  core.DocumentMetadata
+	}{
+		{
+			name:  "no frontmatter",
+			src:   "# Title\n\nBody",
+			title: "Title",
+		},
+		{
+			name:  "title falls back to the first H1",
+			src:   "---\ntags: ops\n---\n# Title\n",
+			title: "Title",
+			meta:  core.DocumentMetadata{Tags: []string{"ops"}},
+		},
+		{
+			name: "comma-separated tags",
+			src:  "---\ntags: ops, getting started\n---\n",
+			meta: core.DocumentMetadata{Tags: []string{"ops", "getting started"}},
+		},
+		{
+			name:  "fields of the wrong type are ignored",
+			src:   "---\ntitle: Title\norder: first\ndraft: true\n---\n",
+			title: "Title",
+			meta:  core.DocumentMetadata{Draft: true},
+		},
+		{
+			name:  "CRLF line endings and a BOM",
+			src:   "\ufeff---\r\ntitle: Title\r\norder: 3\r\n...\r\nBody\r\n",
+			title: "Title",
+			meta:  core.DocumentMetadata{Order: 3},
+		},
+		{
+			name: "leading thematic break",
+			src:  "---\nNot frontmatter\n---\n",
+		},
+		{
+			name: "unclosed block",
+			src:  "---\ntitle: Title\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.title, r.ExtractTitle([]byte(tt.src)))
+			assert.Equal(t, tt.meta, r.ExtractMetadata([]byte(tt.src)))
+		})
+	}
+}
+
+func TestRenderer_FrontmatterThematicBreakRendered(t *testing.T) {
+	r, err := New(Config{})
+	require.NoError(t, err)
+
+	html, err := r.ToHTML([]byte("---\nNot frontmatter\n---\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "<hr>\n<h2 id=\"not-frontmatter\">Not frontmatter</h2>\n", string(html))
+}
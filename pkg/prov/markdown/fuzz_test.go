@@ -0,0 +1,44 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func FuzzRenderer(f *testing.F) {
+	for _, seed := range []string{
+		"# Title\n\nParagraph with **bold**, `code` and a [link](./other.md#setup).",
+		"[[TOC]]\n\n## A {#custom-id}\n\n### B\n\n> quote\n> - item",
+		"| a | b |\n|---|:-:|\n| 1 | 2 |\n\nTerm\n: Definition[^1]\n\n[^1]: Footnote.",
+		"```mermaid\ngraph TD; A-->B\n```\n\n```go\nfunc main() {}\n```",
+		"<details><summary>More</summary>\n\n- [ ] task\n- [x] done\n\n</details>",
+		"![diagram](img/a.png \"title\")\n\n<img src=x onerror=alert(1)>",
+		"* * *\n1. a\n   1. b\n      1. c\n\n\t\tindented\n\n[ref]: https://example.com",
+		"---\ntitle: T\ntags: [a, b]\norder: 1\n---\n# Body",
+		"Inline $a_1 < b$ and $$x^2$$, $5 and \\$6\n\n$$\n\\frac{1}{2}\n$$\n\n> $$ y $$",
+	} {
+		f.Add([]byte(seed))
+	}
+
+	r, err := New(Config{})
+	require.NoError(f, err)
+
+	f.Fuzz(func(t *testing.T, src []byte) {
+		// The server refuses content beyond the limits before processing it.
+		if r.Validate(src) != nil {
+			return
+		}
+
+		if _, _, err := r.RenderHTML(src); err != nil {
+			t.Skip()
+		}
+
+		r.ExtractTitle(src)
+		r.ExtractSummary(src)
+		r.ToPlainText(src)
+		r.ExtractHeadings(src)
+		r.ExtractCodeBlocks(src)
+		r.ExtractMetadata(src)
+	})
+}
@@ -0,0 +1,142 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/util"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Heading ID modes select how IDs are generated for headings without an explicit ID.
+const (
+	// HeadingIDsASCII keeps only ASCII letters and digits, so "Über uns" becomes
+	// "ber-uns" and headings in non-Latin scripts become "heading", "heading-1" and so on.
+	// It is the default, as it matches the IDs of documents rendered before the option.
+	HeadingIDsASCII = "ascii"
+	// HeadingIDsUnicode keeps letters and digits of every script, lowercased and
+	// NFC-normalized, like GitHub: "Über uns" becomes "über-uns".
+	HeadingIDsUnicode = "unicode"
+	// HeadingIDsTransliterate is HeadingIDsUnicode with accented Latin letters folded to
+	// ASCII: "Über uns" becomes "uber-uns". Letters of other scripts are kept.
+	HeadingIDsTransliterate = "transliterate"
+)
+
+// latinFolds maps Latin letters that do not decompose into an ASCII base letter and a
+// combining mark to their usual ASCII transliteration.
+var latinFolds = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'đ': "d", 'ð': "d", 'ħ': "h", 'ı': "i", 'ł': "l", 'þ': "th",
+}
+
+// validateHeadingIDs returns an error if mode is not a known heading ID mode.
+func validateHeadingIDs(mode string) error {
+	switch mode {
+	case "", HeadingIDsASCII, HeadingIDsUnicode, HeadingIDsTransliterate:
+		return nil
+	default:
+		return fmt.Errorf("unknown heading_ids mode %q: must be %q, %q or %q",
+			mode, HeadingIDsASCII, HeadingIDsUnicode, HeadingIDsTransliterate)
+	}
+}
+
+// headingIDs generates heading IDs for the Unicode and transliterate modes. Like goldmark's
+// default generator it de-duplicates IDs within a document by appending "-1", "-2" and so
+// on, and never reuses an explicit ID.
+type headingIDs struct {
+	values        map[string]struct{}
+	transliterate bool
+}
+
+// newHeadingIDs returns the ID generator for mode, or nil for the ASCII mode, which uses
+// goldmark's default generator.
+func newHeadingIDs(mode string) parser.IDs {
+	switch mode {
+	case HeadingIDsUnicode:
+		return &headingIDs{values: make(map[string]struct{})}
+	case HeadingIDsTransliterate:
+		return &headingIDs{values: make(map[string]struct{}), transliterate: true}
+	default:
+		return nil
+	}
+}
+
+// Generate implements parser.IDs.
+func (g *headingIDs) Generate(value []byte, kind ast.NodeKind) []byte {
+	id := slugify(string(util.TrimRightSpace(util.TrimLeftSpace(value))), g.transliterate)
+	if id == "" {
+		id = "id"
+		if kind == ast.KindHeading {
+			id = "heading"
+		}
+	}
+
+	unique := id
+	for i := 1; ; i++ {
+		if _, ok := g.values[unique]; !ok {
+			break
+		}
+
+		unique = fmt.Sprintf("%s-%d", id, i)
+	}
+
+	g.values[unique] = struct{}{}
+
+	return []byte(unique)
+}
+
+// Put implements parser.IDs.
+func (g *headingIDs) Put(value []byte) {
+	g.values[string(value)] = struct{}{}
+}
+
+// slugify lowercases s and keeps its letters, digits and combining marks, turning spaces,
+// hyphens and underscores into hyphens and dropping other characters. s is NFC-normalized
+// first, so that the same heading typed on different systems gets the same ID.
+func slugify(s string, transliterate bool) string {
+	s = norm.NFC.String(s)
+	if transliterate {
+		s = foldLatin(s)
+	}
+
+	var b strings.Builder
+
+	for _, r := range s {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r):
+			b.WriteRune(unicode.ToLower(r))
+		case unicode.IsSpace(r) || r == '-' || r == '_':
+			b.WriteByte('-')
+		}
+	}
+
+	return b.String()
+}
+
+// foldLatin replaces accented Latin letters in s by their ASCII base letters, keeping the
+// combining marks of other scripts, which are part of their letters.
+func foldLatin(s string) string {
+	var (
+		b         strings.Builder
+		prevLatin bool
+	)
+
+	for _, r := range norm.NFD.String(s) {
+		if unicode.Is(unicode.Mn, r) && prevLatin {
+			continue
+		}
+
+		prevLatin = unicode.Is(unicode.Latin, r)
+
+		if fold, ok := latinFolds[unicode.ToLower(r)]; ok {
+			b.WriteString(fold)
+			continue
+		}
+
+		b.WriteRune(r)
+	}
+
+	return norm.NFC.String(b.String())
+}
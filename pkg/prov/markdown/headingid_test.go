@@ -0,0 +1,68 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderer_HeadingIDs(t *testing.T) {
+	src := []byte("# Über uns\n\n## Установка\n\n## Установка\n\n## Straße & Café\n\n## हिन्दी\n\n## Setup {#ber-uns}\n\n## Ber uns\n")
+
+	tests := []struct {
+		mode string
+		want []string
+	}{
+		{mode: "", want: []string{"ber-uns", "heading", "heading-1", "strae--caf", "heading-2", "ber-uns", "ber-uns-1"}},
+		{mode: HeadingIDsUnicode, want: []string{"über-uns", "установка", "установка-1", "straße--café", "हिन्दी", "ber-uns", "ber-uns-1"}},
+		{mode: HeadingIDsTransliterate, want: []string{"uber-uns", "установка", "установка-1", "strasse--cafe", "हिन्दी", "ber-uns", "ber-uns-1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			r, err := New(Config{Options: Options{HeadingIDs: tt.mode}})
+			require.NoError(t, err)
+
+			var ids []string
+			for _, h := range r.ExtractHeadings(src) {
+				ids = append(ids, h.ID)
+			}
+
+			assert.Equal(t, tt.want, ids)
+
+			html, headings, err := r.RenderHTML(src)
+			require.NoError(t, err)
+			assert.Equal(t, r.ExtractHeadings(src), headings)
+			assert.Contains(t, string(html), `id="`+tt.want[0]+`"`)
+		})
+	}
+}
+
+func TestRenderer_HeadingIDsNormalized(t *testing.T) {
+	r, err := New(Config{Options: Options{HeadingIDs: HeadingIDsUnicode}})
+	require.NoError(t, err)
+
+	composed := r.ExtractHeadings([]byte("## \u00dcber"))
+	decomposed := r.ExtractHeadings([]byte("## U\u0308ber"))
+
+	assert.Equal(t, []core.Heading{{ID: "über", Text: "Über", Level: 2}}, composed)
+	assert.Equal(t, composed[0].ID, decomposed[0].ID)
+}
+
+func TestRenderer_HeadingIDsPerRepo(t *testing.T) {
+	r, err := New(Config{Repos: []RepoOptions{{Repo: "owner/intl", Options: Options{HeadingIDs: HeadingIDsTransliterate}}}})
+	require.NoError(t, err)
+
+	assert.Equal(t, "ber-uns", r.ExtractHeadings([]byte("# Über uns"))[0].ID)
+	assert.Equal(t, "uber-uns", r.ForRepo("owner/intl").ExtractHeadings([]byte("# Über uns"))[0].ID)
+}
+
+func TestNew_InvalidHeadingIDs(t *testing.T) {
+	_, err := New(Config{Options: Options{HeadingIDs: "emoji"}})
+	assert.ErrorContains(t, err, "unknown heading_ids mode")
+
+	_, err = New(Config{Repos: []RepoOptions{{Repo: "owner/repo", Options: Options{HeadingIDs: "emoji"}}}})
+	assert.ErrorContains(t, err, "owner/repo")
+}
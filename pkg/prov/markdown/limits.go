@@ -0,0 +1,121 @@
+package markdown
+
+import (
+	"fmt"
+	"html"
+	"unicode/utf8"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/yuin/goldmark/ast"
+)
+
+const (
+	defaultMaxDocumentKiB  = 2048
+	defaultMaxNestingDepth = 64
+	defaultMaxHTMLKiB      = 16384
+)
+
+// Limits bounds the work spent on a single markdown document, protecting the server from
+// documents crafted to make parsing, rendering or sanitization slow. Zero values use the
+// defaults.
+type Limits struct {
+	MaxDocumentKiB  int `mapstructure:"max_document_kib"`  // Largest document accepted (default 2048).
+	MaxNestingDepth int `mapstructure:"max_nesting_depth"` // Deepest nesting of block and inline elements (default 64).
+	MaxHTMLKiB      int `mapstructure:"max_html_kib"`      // Largest rendered HTML passed to the sanitizer (default 16384).
+}
+
+// withDefaults returns the limits with zero values replaced by the defaults.
+func (l Limits) withDefaults() Limits {
+	if l.MaxDocumentKiB <= 0 {
+		l.MaxDocumentKiB = defaultMaxDocumentKiB
+	}
+
+	if l.MaxNestingDepth <= 0 {
+		l.MaxNestingDepth = defaultMaxNestingDepth
+	}
+
+	if l.MaxHTMLKiB <= 0 {
+		l.MaxHTMLKiB = defaultMaxHTMLKiB
+	}
+
+	return l
+}
+
+// Validate reports an error wrapping core.ErrLimitExceeded if src is larger or more deeply
+// nested than the renderer accepts. It is called for every published document, so that
+// such documents are rejected at ingest rather than served as a notice.
+func (r *Renderer) Validate(src []byte) error {
+	if err := r.checkSize(src); err != nil {
+		return err
+	}
+
+	_, body := splitFrontmatter(src)
+
+	return r.checkDepth(r.parse(body))
+}
+
+// checkSize returns an error if src exceeds the document size limit.
+func (r *Renderer) checkSize(src []byte) error {
+	if len(src) > r.limits.MaxDocumentKiB<<10 {
+		return fmt.Errorf("%w: document is %d KiB, the maximum is %d KiB",
+			core.ErrLimitExceeded, len(src)>>10, r.limits.MaxDocumentKiB)
+	}
+
+	return nil
+}
+
+// checkDepth returns an error if the parsed document nests elements deeper than the limit.
+func (r *Renderer) checkDepth(doc ast.Node) error {
+	if exceedsDepth(doc, r.limits.MaxNestingDepth) {
+		return fmt.Errorf("%w: elements are nested more than %d levels deep",
+			core.ErrLimitExceeded, r.limits.MaxNestingDepth)
+	}
+
+	return nil
+}
+
+// exceedsDepth reports whether the subtree of n is more than limit levels deep. It stops
+// descending at the limit, so its cost is bounded regardless of the actual depth.
+func exceedsDepth(n ast.Node, limit int) bool {
+	if limit < 0 {
+		return true
+	}
+
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		if exceedsDepth(child, limit-1) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// truncate returns src cut to the document size limit at a UTF-8 boundary. Text
+// extraction uses it so that an oversized document that is already stored is indexed in
+// part rather than parsed in full.
+func (r *Renderer) truncate(src []byte) []byte {
+	maxLen := r.limits.MaxDocumentKiB << 10
+	if len(src) <= maxLen {
+		return src
+	}
+
+	src = src[:maxLen]
+
+	// Drop the bytes of a multi-byte character cut in half.
+	for range utf8.UTFMax - 1 {
+		if r, size := utf8.DecodeLastRune(src); r != utf8.RuneError || size != 1 {
+			break
+		}
+
+		src = src[:len(src)-1]
+	}
+
+	return src
+}
+
+// limitNotice returns the HTML shown in place of a document that exceeds a limit: the
+// reason followed by the escaped source, truncated to the document size limit.
+func (r *Renderer) limitNotice(src []byte, err error) []byte {
+	return fmt.Appendf(nil, "<p><strong>This document is too large or complex to render: %s.</strong> Its source is shown instead.</p>\n<pre><code>%s</code></pre>\n",
+		html.EscapeString(err.Error()), html.EscapeString(string(r.truncate(src))))
+}
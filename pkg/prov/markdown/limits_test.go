@@ -0,0 +1,95 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderer_Validate(t *testing.T) {
+	r, err := New(Config{Limits: Limits{MaxDocumentKiB: 1, MaxNestingDepth: 10}})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		src     string
+		wantErr string
+	}{
+		{name: "within limits", src: "# Title\n\n- item\n  - nested\n"},
+		{name: "too large", src: strings.Repeat("a", 2048), wantErr: "document is 2 KiB, the maximum is 1 KiB"},
+		{name: "too deep", src: strings.Repeat(">", 20) + " quote", wantErr: "nested more than 10 levels deep"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := r.Validate([]byte(tt.src))
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorIs(t, err, core.ErrLimitExceeded)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestRenderer_Validate_ForRepo(t *testing.T) {
+	r, err := New(Config{
+		Limits: Limits{MaxNestingDepth: 5},
+		Repos:  []RepoOptions{{Repo: "owner/repo", Options: Options{HardWraps: true}}},
+	})
+	require.NoError(t, err)
+
+	v, ok := r.ForRepo("owner/repo").(core.ContentValidator)
+	require.True(t, ok)
+	assert.ErrorIs(t, v.Validate([]byte(strings.Repeat(">", 10)+" quote")), core.ErrLimitExceeded)
+}
+
+func TestRenderer_RenderHTML_LimitNotice(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    string
+		want   string
+		limits Limits
+	}{
+		{name: "too large", limits: Limits{MaxDocumentKiB: 1}, src: "<b>" + strings.Repeat("a", 2048), want: "document is 2 KiB"},
+		{name: "too deep", limits: Limits{MaxNestingDepth: 10}, src: strings.Repeat(">", 20) + " <b>quote</b>", want: "nested more than 10 levels deep"},
+		{name: "html too large", limits: Limits{MaxHTMLKiB: 1}, src: strings.Repeat("<b>x</b> ", 300), want: "rendered HTML is"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := New(Config{Limits: tt.limits})
+			require.NoError(t, err)
+
+			html, headings, err := r.RenderHTML([]byte(tt.src))
+			require.NoError(t, err)
+			assert.Nil(t, headings)
+			assert.Contains(t, string(html), "too large or complex to render")
+			assert.Contains(t, string(html), tt.want)
+			assert.Contains(t, string(html), "&lt;b&gt;")
+			assert.NotContains(t, string(html), "<b>")
+		})
+	}
+}
+
+func TestRenderer_Truncate(t *testing.T) {
+	r, err := New(Config{Limits: Limits{MaxDocumentKiB: 1}})
+	require.NoError(t, err)
+
+	short := []byte("# Title")
+	assert.Equal(t, short, r.truncate(short))
+
+	// A three-byte character straddles the 1 KiB boundary and must be dropped whole.
+	src := []byte(strings.Repeat("a", 1023) + "€ tail")
+	got := r.truncate(src)
+	assert.Len(t, got, 1023)
+	assert.True(t, utf8.Valid(got))
+
+	assert.Equal(t, "Title", r.ExtractTitle([]byte("# Title\n\n"+strings.Repeat("a", 4096))))
+}
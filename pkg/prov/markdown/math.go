@@ -0,0 +1,264 @@
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+const (
+	// mathParserPriority places the math parsers among goldmark's own. No other parser is
+	// triggered by "$", and formulas are consumed whole, so "_" and "*" in them are not
+	// taken for emphasis.
+	mathParserPriority = 90
+
+	// mathRendererPriority matches goldmark's default HTML renderer; the math nodes are
+	// not rendered by anything else.
+	mathRendererPriority = 1000
+)
+
+// mathClassPattern matches the classes the math renderer emits on formulas, which the
+// portal typesets with KaTeX.
+var mathClassPattern = regexp.MustCompile(`^math math-(inline|display)$`)
+
+var (
+	// kindMath is the kind of an inline formula.
+	kindMath = ast.NewNodeKind("Math")
+	// kindMathBlock is the kind of a display formula standing as its own block.
+	kindMathBlock = ast.NewNodeKind("MathBlock")
+)
+
+// mathInline is a formula within a paragraph, written between "$" or, to be typeset in
+// display mode, "$$". Its TeX source is held in raw text children, as with code spans.
+type mathInline struct {
+	ast.BaseInline
+	display bool
+}
+
+// Kind implements ast.Node.
+func (n *mathInline) Kind() ast.NodeKind {
+	return kindMath
+}
+
+// Dump implements ast.Node.
+func (n *mathInline) Dump(src []byte, level int) {
+	ast.DumpHelper(n, src, level, nil, nil)
+}
+
+// mathBlock is a display formula whose TeX source is held in lines between "$$" lines.
+type mathBlock struct {
+	ast.BaseBlock
+	closed bool
+}
+
+// Kind implements ast.Node.
+func (n *mathBlock) Kind() ast.NodeKind {
+	return kindMathBlock
+}
+
+// IsRaw implements ast.Node; the lines of a formula are not parsed as markdown.
+func (n *mathBlock) IsRaw() bool {
+	return true
+}
+
+// Dump implements ast.Node.
+func (n *mathBlock) Dump(src []byte, level int) {
+	ast.DumpHelper(n, src, level, nil, nil)
+}
+
+// mathExtension adds LaTeX math to markdown: "$...$" inline formulas, "$$...$$" display
+// formulas, and display formulas between "$$" lines. Formulas are rendered as their
+// escaped TeX source in elements the portal typesets with KaTeX, so they remain readable
+// where scripts do not run.
+type mathExtension struct{}
+
+// Extend registers the math parsers and renderer with goldmark.
+func (mathExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithBlockParsers(util.Prioritized(mathBlockParser{}, mathParserPriority)),
+		parser.WithInlineParsers(util.Prioritized(mathInlineParser{}, mathParserPriority)),
+	)
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(mathRenderer{}, mathRendererPriority)))
+}
+
+// mathInlineParser parses "$...$" and "$$...$$" formulas within a line. Like Pandoc, it
+// requires the opening "$" to be followed, and the closing one preceded, by a non-space
+// character, and the closing one not to be followed by a digit, so that amounts such as
+// "$5 and $10" stay text. Escaped dollars, "\$", are never delimiters.
+type mathInlineParser struct{}
+
+// Trigger implements parser.InlineParser.
+func (mathInlineParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+// Parse implements parser.InlineParser.
+func (mathInlineParser) Parse(_ ast.Node, block text.Reader, _ parser.Context) ast.Node {
+	line, segment := block.PeekLine()
+
+	opener := 1
+	if len(line) > 1 && line[1] == '$' {
+		opener = 2
+	}
+
+	if opener >= len(line) || util.IsSpace(line[opener]) || line[opener] == '$' {
+		return nil
+	}
+
+	for i := opener; i < len(line); i++ {
+		switch {
+		case line[i] == '\\':
+			i++
+		case line[i] != '$':
+		case !bytes.HasPrefix(line[i:], line[:opener]) || util.IsSpace(line[i-1]):
+			return nil
+		default:
+			if end := i + opener; end < len(line) && (line[end] == '$' || opener == 1 && isDigit(line[end])) {
+				return nil
+			}
+
+			node := &mathInline{display: opener == 2}
+			node.AppendChild(node, ast.NewRawTextSegment(text.NewSegment(segment.Start+opener, segment.Start+i)))
+			block.Advance(i + opener)
+
+			return node
+		}
+	}
+
+	return nil
+}
+
+// isDigit reports whether c is an ASCII digit.
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// mathBlockParser parses display formulas between lines holding only "$$", or written on
+// a single line as "$$...$$". A formula missing its closing line runs to the end of its
+// container, as an unclosed code fence does.
+type mathBlockParser struct{}
+
+// Trigger implements parser.BlockParser.
+func (mathBlockParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+// Open implements parser.BlockParser.
+func (mathBlockParser) Open(_ ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	pos := pc.BlockOffset()
+
+	if pos < 0 || !bytes.HasPrefix(line[pos:], []byte("$$")) {
+		return nil, parser.NoChildren
+	}
+
+	rest := util.TrimRightSpace(line[pos+2:])
+	node := &mathBlock{}
+
+	switch {
+	case len(rest) == 0:
+	case len(rest) > 2 && bytes.HasSuffix(rest, []byte("$$")):
+		start := segment.Start - segment.Padding + pos + 2
+
+		node.Lines().Append(text.NewSegment(start, start+len(rest)-2))
+		node.closed = true
+	default:
+		// Text after the opening "$$" makes the line a paragraph, with inline formulas.
+		return nil, parser.NoChildren
+	}
+
+	reader.AdvanceToEOL()
+
+	return node, parser.NoChildren
+}
+
+// Continue implements parser.BlockParser.
+func (mathBlockParser) Continue(node ast.Node, reader text.Reader, _ parser.Context) parser.State {
+	block, ok := node.(*mathBlock)
+	if !ok || block.closed {
+		return parser.Close
+	}
+
+	line, segment := reader.PeekLine()
+
+	if w, pos := util.IndentWidth(line, reader.LineOffset()); w < 4 && string(util.TrimRightSpace(line[pos:])) == "$$" {
+		reader.AdvanceToEOL()
+
+		return parser.Close
+	}
+
+	block.Lines().Append(segment)
+	reader.AdvanceToEOL()
+
+	return parser.Continue | parser.NoChildren
+}
+
+// Close implements parser.BlockParser.
+func (mathBlockParser) Close(ast.Node, text.Reader, parser.Context) {}
+
+// CanInterruptParagraph implements parser.BlockParser.
+func (mathBlockParser) CanInterruptParagraph() bool {
+	return true
+}
+
+// CanAcceptIndentedLine implements parser.BlockParser.
+func (mathBlockParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// mathRenderer renders formulas as their escaped TeX source in a <span> or <div> with the
+// "math" class, and "math-inline" or "math-display" for the typesetting mode.
+type mathRenderer struct{}
+
+// RegisterFuncs implements renderer.NodeRenderer.
+func (r mathRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindMath, r.renderMath)
+	reg.Register(kindMathBlock, r.renderMathBlock)
+}
+
+func (mathRenderer) renderMath(w util.BufWriter, src []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	class := "math math-inline"
+	if n, ok := node.(*mathInline); ok && n.display {
+		class = "math math-display"
+	}
+
+	_, _ = w.WriteString(`<span class="` + class + `">`)
+
+	for c := node.FirstChild(); c != nil; c = c.NextSibling() {
+		if t, ok := c.(*ast.Text); ok {
+			_, _ = w.Write(util.EscapeHTML(t.Segment.Value(src)))
+		}
+	}
+
+	_, _ = w.WriteString("</span>")
+
+	return ast.WalkSkipChildren, nil
+}
+
+func (mathRenderer) renderMathBlock(w util.BufWriter, src []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	_, _ = w.WriteString(`<div class="math math-display">`)
+
+	lines := node.Lines()
+	for i := range lines.Len() {
+		line := lines.At(i)
+		_, _ = w.Write(util.EscapeHTML(line.Value(src)))
+	}
+
+	_, _ = w.WriteString("</div>\n")
+
+	return ast.WalkSkipChildren, nil
+}
@@ -0,0 +1,84 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderer_Math(t *testing.T) {
+	r, err := New(Config{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		src  string
+		html string
+		text string
+	}{
+		{
+			name: "inline formula",
+			src:  "Energy is $E = mc^2$ here.",
+			html: "<p>Energy is <span class=\"math math-inline\">E = mc^2</span> here.</p>\n",
+			text: "Energy is E = mc^2 here.",
+		},
+		{
+			name: "underscores and asterisks are not emphasis",
+			src:  "$a_1 * b_2 * c_3$",
+			html: "<p><span class=\"math math-inline\">a_1 * b_2 * c_3</span></p>\n",
+			text: "a_1 * b_2 * c_3",
+		},
+		{
+			name: "amounts stay text",
+			src:  "It costs $5 or $10, and $ x$ is not math.",
+			html: "<p>It costs $5 or $10, and $ x$ is not math.</p>\n",
+			text: "It costs $5 or $10, and $ x$ is not math.",
+		},
+		{
+			name: "escaped dollars and code spans",
+			src:  "\\$x\\$ and `$x$`",
+			html: "<p>$x$ and <code>$x$</code></p>\n",
+			text: "\\$x\\$ and $x$",
+		},
+		{
+			name: "inline display formula",
+			src:  "See $$\\sum_i x_i$$ below.",
+			html: "<p>See <span class=\"math math-display\">\\sum_i x_i</span> below.</p>\n",
+			text: "See \\sum_i x_i below.",
+		},
+		{
+			name: "display block",
+			src:  "Intro\n$$\n\\frac{a}{b} < c\n$$\nAfter",
+			html: "<p>Intro</p>\n<div class=\"math math-display\">\\frac{a}{b} &lt; c\n</div>\n<p>After</p>\n",
+			text: "Intro\n\\frac{a}{b} < c\n\nAfter",
+		},
+		{
+			name: "single-line display block",
+			src:  "$$ x^2 $$",
+			html: "<div class=\"math math-display\"> x^2 </div>\n",
+			text: "x^2",
+		},
+		{
+			name: "display block in a blockquote",
+			src:  "> $$\n> x\n> $$",
+			html: "<blockquote>\n<div class=\"math math-display\">x\n</div>\n</blockquote>\n",
+			text: "x",
+		},
+		{
+			name: "markup is escaped",
+			src:  "$<script>alert(1)</script>$",
+			html: "<p><span class=\"math math-inline\">&lt;script&gt;alert(1)&lt;/script&gt;</span></p>\n",
+			text: "<script>alert(1)</script>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html, err := r.ToHTML([]byte(tt.src))
+			require.NoError(t, err)
+			assert.Equal(t, tt.html, string(html))
+			assert.Equal(t, tt.text, r.ToPlainText([]byte(tt.src)))
+		})
+	}
+}
@@ -0,0 +1,341 @@
+package markdown
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+	gmm "go.abhg.dev/goldmark/mermaid"
+)
+
+const (
+	// MermaidModeClient leaves Mermaid diagrams as <pre class="mermaid"> blocks rendered in the browser.
+	MermaidModeClient = "client"
+	// MermaidModeServer pre-renders Mermaid diagrams to static SVG using the Mermaid CLI.
+	MermaidModeServer = "server"
+
+	defaultMermaidCLI       = "mmdc"
+	defaultMermaidTimeout   = 30 * time.Second
+	defaultMermaidCacheSize = 256
+
+	// mermaidRendererPriority is lower than the priority used by the goldmark-mermaid extender,
+	// so the server-side diagram renderer overrides its client renderer for Mermaid blocks.
+	mermaidRendererPriority = 50
+
+	// placeholderAttr is the AST attribute carrying the placeholder token from prepare to the node renderer.
+	placeholderAttr = "data-omnidex-mermaid"
+)
+
+// svgPolicy is the allowlist applied to compiled diagrams, which are inserted into the
+// HTML after it has been sanitized: the SVG elements Mermaid emits and the HTML of its
+// labels, with their presentation attributes. Scripts, event handler attributes and links
+// other than http, https and mailto are removed. The contents of <style> elements are kept
+// as they are, see checkSVGStyles.
+var svgPolicy = func() *bluemonday.Policy {
+	elements := []string{
+		"svg", "g", "defs", "marker", "style", "title", "desc", "path", "rect", "circle", "ellipse",
+		"line", "polyline", "polygon", "text", "tspan", "foreignobject", "lineargradient",
+		"radialgradient", "stop", "clippath", "pattern",
+		"div", "span", "p", "br", "b", "strong", "i", "em", "code",
+	}
+
+	p := bluemonday.NewPolicy()
+	p.AllowElements(elements...)
+	p.AllowNoAttrs().OnElements(elements...)
+	p.AllowAttrs(
+		"id", "class", "style", "xmlns", "version", "role", "aria-roledescription", "aria-labelledby",
+		"aria-describedby", "viewbox", "preserveaspectratio", "width", "height", "transform",
+		"x", "y", "x1", "y1", "x2", "y2", "cx", "cy", "r", "rx", "ry", "dx", "dy", "d", "points",
+		"fill", "fill-opacity", "fill-rule", "stroke", "stroke-width", "stroke-opacity",
+		"stroke-dasharray", "stroke-linecap", "stroke-linejoin", "opacity", "clip-path",
+		"marker-start", "marker-mid", "marker-end", "markerwidth", "markerheight", "markerunits",
+		"refx", "refy", "orient", "text-anchor", "dominant-baseline", "alignment-baseline",
+		"font-size", "font-family", "font-weight", "font-style", "offset", "stop-color",
+		"stop-opacity", "gradientunits", "patternunits",
+	).Globally()
+	p.AllowDataAttributes()
+	p.AllowElements("a")
+	p.AllowAttrs("href").OnElements("a")
+	p.AllowStandardURLs()
+	// Keeps the contents of <style> elements, which carry the diagram theme.
+	p.AllowUnsafe(true)
+
+	return p
+}()
+
+// svgStyleRe matches the contents of the <style> elements of an SVG.
+var svgStyleRe = regexp.MustCompile(`(?is)<style[^>]*>(.*?)</style`)
+
+// checkSVGStyles refuses style sheets containing markup. The sanitizer tokenizes <style>
+// contents as raw text, as in HTML, but inside <svg> browsers parse them as elements, so
+// markup there would reach the page unsanitized.
+func checkSVGStyles(svg string) error {
+	for _, m := range svgStyleRe.FindAllStringSubmatch(svg, -1) {
+		if strings.Contains(m[1], "<") {
+			return errors.New("compiled diagram contains markup in a style sheet")
+		}
+	}
+
+	return nil
+}
+
+// MermaidConfig controls how Mermaid diagrams are rendered.
+// Mode selects the rendering strategy: "client" (default) or "server".
+// In server mode diagrams are compiled to SVG by the Mermaid CLI (mmdc) at render time
+// and cached by the hash of their source. Diagrams that fail to compile fall back to
+// client-side rendering.
+type MermaidConfig struct {
+	Mode      string        `mapstructure:"mode"`
+	CLIPath   string        `mapstructure:"cli_path"`
+	Theme     string        `mapstructure:"theme"`
+	Timeout   time.Duration `mapstructure:"timeout"`
+	CacheSize int           `mapstructure:"cache_size"`
+}
+
+// diagramRenderer pre-renders Mermaid diagrams server-side. Compiled SVGs are kept out of
+// the sanitized HTML: the node renderer writes an opaque placeholder token that survives
+// sanitization and is replaced with the SVG afterwards.
+type diagramRenderer struct {
+	compiler gmm.Compiler
+	cache    *svgCache
+	timeout  time.Duration
+}
+
+// newDiagramRenderer creates a diagramRenderer from the given configuration.
+// It returns an error if the Mermaid CLI cannot be found.
+func newDiagramRenderer(cfg MermaidConfig) (*diagramRenderer, error) {
+	cliPath := cfg.CLIPath
+	if cliPath == "" {
+		cliPath = defaultMermaidCLI
+	}
+
+	resolved, err := exec.LookPath(cliPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find mermaid CLI %q: %w", cliPath, err)
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultMermaidTimeout
+	}
+
+	cacheSize := cfg.CacheSize
+	if cacheSize <= 0 {
+		cacheSize = defaultMermaidCacheSize
+	}
+
+	return &diagramRenderer{
+		compiler: &gmm.CLICompiler{CLI: gmm.MMDC(resolved), Theme: cfg.Theme},
+		cache:    newSVGCache(cacheSize),
+		timeout:  timeout,
+	}, nil
+}
+
+// renderState holds the SVGs compiled for a single render call, keyed by their placeholder token.
+type renderState struct {
+	svgs  map[string]string
+	nonce string
+}
+
+// newRenderState creates a renderState with a random nonce so placeholder tokens cannot be
+// forged by document content.
+func newRenderState() (*renderState, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return nil, fmt.Errorf("failed to generate placeholder nonce: %w", err)
+	}
+
+	return &renderState{nonce: hex.EncodeToString(b[:]), svgs: make(map[string]string)}, nil
+}
+
+// prepare walks the document AST, compiles every Mermaid block and tags successfully compiled
+// blocks with a placeholder token. Blocks that fail to compile are left untouched so they are
+// rendered client-side.
+func (d *diagramRenderer) prepare(ctx context.Context, doc ast.Node, src []byte, state *renderState) {
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		block, ok := n.(*gmm.Block)
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+
+		source := blockSource(block, src)
+		if strings.TrimSpace(source) == "" {
+			return ast.WalkSkipChildren, nil
+		}
+
+		svg, err := d.compile(ctx, source)
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to pre-render mermaid diagram, falling back to client rendering", "error", err)
+			return ast.WalkSkipChildren, nil
+		}
+
+		token := fmt.Sprintf("omnidex-mermaid-%s-%d", state.nonce, len(state.svgs))
+		state.svgs[token] = svg
+		block.SetAttributeString(placeholderAttr, []byte(token))
+
+		return ast.WalkSkipChildren, nil
+	})
+}
+
+// compile returns the sanitized SVG for the diagram source, serving repeated diagrams from
+// the cache. Compiling stops when ctx is done or the configured timeout elapses.
+func (d *diagramRenderer) compile(ctx context.Context, source string) (string, error) {
+	sum := sha256.Sum256([]byte(source))
+	key := hex.EncodeToString(sum[:])
+
+	if svg, ok := d.cache.get(key); ok {
+		return svg, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+
+	resp, err := d.compiler.Compile(ctx, &gmm.CompileRequest{Source: source})
+	if err != nil {
+		return "", fmt.Errorf("failed to compile diagram: %w", err)
+	}
+
+	// The SVG is embedded after the HTML has been sanitized, so it goes through its own
+	// allowlist rather than trusting the compiler output, which reflects the diagram source.
+	if err := checkSVGStyles(resp.SVG); err != nil {
+		return "", err
+	}
+
+	svg := svgPolicy.Sanitize(resp.SVG)
+
+	d.cache.put(key, svg)
+
+	return svg, nil
+}
+
+// RegisterFuncs registers the Mermaid block renderer with goldmark.
+func (d *diagramRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(gmm.Kind, d.render)
+}
+
+// render writes a placeholder token for pre-rendered blocks, or a client-side
+// <pre class="mermaid"> block when the diagram was not compiled.
+func (d *diagramRenderer) render(w util.BufWriter, src []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	block, ok := node.(*gmm.Block)
+	if !ok {
+		return ast.WalkContinue, nil
+	}
+
+	if token, ok := block.AttributeString(placeholderAttr); ok {
+		if b, ok := token.([]byte); ok {
+			_, _ = w.Write(b)
+
+			return ast.WalkSkipChildren, nil
+		}
+	}
+
+	_, _ = w.WriteString(`<pre class="mermaid">`)
+	template.HTMLEscape(w, []byte(blockSource(block, src)))
+	_, _ = w.WriteString("</pre>")
+
+	return ast.WalkSkipChildren, nil
+}
+
+// substitute replaces placeholder tokens in sanitized HTML with the compiled SVG diagrams.
+func (s *renderState) substitute(html []byte) []byte {
+	for token, svg := range s.svgs {
+		html = bytes.Replace(html, []byte(token), []byte(`<div class="mermaid-static">`+svg+`</div>`), 1)
+	}
+
+	return html
+}
+
+// blockSource concatenates the raw lines of a Mermaid block.
+func blockSource(block *gmm.Block, src []byte) string {
+	var buf bytes.Buffer
+
+	lines := block.Lines()
+	for i := range lines.Len() {
+		line := lines.At(i)
+		buf.Write(line.Value(src))
+	}
+
+	return buf.String()
+}
+
+// svgCache is a size-bounded LRU cache of compiled diagrams keyed by source hash.
+type svgCache struct {
+	items map[string]*list.Element
+	order *list.List
+	mu    sync.Mutex
+	size  int
+}
+
+type svgCacheEntry struct {
+	key string
+	svg string
+}
+
+func newSVGCache(size int) *svgCache {
+	return &svgCache{
+		items: make(map[string]*list.Element),
+		order: list.New(),
+		size:  size,
+	}
+}
+
+func (c *svgCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return "", false
+	}
+
+	c.order.MoveToFront(el)
+
+	entry, _ := el.Value.(*svgCacheEntry)
+
+	return entry.svg, true
+}
+
+func (c *svgCache) put(key, svg string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.order.MoveToFront(el)
+
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&svgCacheEntry{key: key, svg: svg})
+
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+
+		entry, _ := oldest.Value.(*svgCacheEntry)
+		delete(c.items, entry.key)
+	}
+}
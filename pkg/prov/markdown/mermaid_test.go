@@ -0,0 +1,170 @@
+package markdown
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gmm "go.abhg.dev/goldmark/mermaid"
+)
+
+type fakeCompiler struct {
+	err   error
+	ctx   context.Context
+	svg   string
+	calls int
+	mu    sync.Mutex
+}
+
+func (f *fakeCompiler) Compile(ctx context.Context, _ *gmm.CompileRequest) (*gmm.CompileResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls++
+	f.ctx = ctx
+
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	return &gmm.CompileResponse{SVG: f.svg}, nil
+}
+
+func newTestServerRenderer(compiler gmm.Compiler) *Renderer {
+	return newRenderer(Options{}, Limits{}.withDefaults(), &diagramRenderer{
+		compiler: compiler,
+		cache:    newSVGCache(8),
+		timeout:  time.Second,
+	})
+}
+
+const diagramSrc = "# Title\n\n```mermaid\ngraph TD;\n    A-->B;\n```\n"
+
+func TestNew_MermaidMode(t *testing.T) {
+	_, err := New(Config{Mermaid: MermaidConfig{Mode: "unknown"}})
+	assert.ErrorContains(t, err, "unknown mermaid mode")
+
+	_, err = New(Config{Mermaid: MermaidConfig{Mode: MermaidModeServer, CLIPath: "/nonexistent/mmdc"}})
+	assert.ErrorContains(t, err, "failed to find mermaid CLI")
+
+	r, err := New(Config{Mermaid: MermaidConfig{Mode: MermaidModeClient}})
+	require.NoError(t, err)
+	assert.Nil(t, r.diagrams)
+}
+
+func TestRenderer_ServerMermaid(t *testing.T) {
+	compiler := &fakeCompiler{svg: `<svg id="d"><g><text>A</text></g></svg>`}
+	r := newTestServerRenderer(compiler)
+
+	html, _, err := r.RenderHTML([]byte(diagramSrc))
+	require.NoError(t, err)
+
+	out := string(html)
+	assert.Contains(t, out, `<div class="mermaid-static"><svg id="d"><g><text>A</text></g></svg></div>`)
+	assert.NotContains(t, out, `<pre class="mermaid">`)
+	assert.NotContains(t, out, "omnidex-mermaid-")
+	assert.Contains(t, out, `<h1 id="title">Title</h1>`)
+}
+
+func TestRenderer_ServerMermaid_CachesByHash(t *testing.T) {
+	compiler := &fakeCompiler{svg: `<svg></svg>`}
+	r := newTestServerRenderer(compiler)
+
+	src := diagramSrc + "\n```mermaid\ngraph TD;\n    A-->B;\n```\n"
+
+	html, err := r.ToHTML([]byte(src))
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(html), `<div class="mermaid-static">`))
+
+	_, err = r.ToHTML([]byte(diagramSrc))
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, compiler.calls)
+}
+
+func TestRenderer_ServerMermaid_FallsBackToClient(t *testing.T) {
+	tests := []struct {
+		compiler *fakeCompiler
+		name     string
+	}{
+		{name: "compile error", compiler: &fakeCompiler{err: errors.New("mmdc failed")}},
+		{name: "markup in style", compiler: &fakeCompiler{svg: `<svg><style><img src=x onerror=alert(1)></style></svg>`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestServerRenderer(tt.compiler)
+
+			html, err := r.ToHTML([]byte(diagramSrc))
+			require.NoError(t, err)
+
+			out := string(html)
+			assert.Contains(t, out, `<pre class="mermaid">graph TD;`)
+			assert.Contains(t, out, "A--&gt;B;")
+			assert.NotContains(t, out, "<script")
+			assert.NotContains(t, out, "mermaid-static")
+		})
+	}
+}
+
+func TestSVGCache_Evicts(t *testing.T) {
+	c := newSVGCache(2)
+	c.put("a", "1")
+	c.put("b", "2")
+
+	_, ok := c.get("a")
+	require.True(t, ok)
+
+	c.put("c", "3")
+
+	_, ok = c.get("b")
+	assert.False(t, ok)
+
+	svg, ok := c.get("a")
+	assert.True(t, ok)
+	assert.Equal(t, "1", svg)
+}
+
+func TestRenderer_ServerMermaid_SanitizesSVG(t *testing.T) {
+	svg := `<svg viewBox="0 0 10 10" onload="alert(1)"><script>alert(2)</script>` +
+		`<style>.node rect { fill: #eee; }</style>` +
+		`<g class="node"><rect width="5" height="5" onclick="alert(3)"></rect>` +
+		`<foreignObject width="5" height="5"><div xmlns="http://www.w3.org/1999/xhtml"><span class="nodeLabel">A</span>` +
+		`<img src="x" onerror="alert(4)"></div></foreignObject>` +
+		`<a href="javascript:alert(5)"><text>link</text></a><a href="https://example.com"><text>ok</text></a></g></svg>`
+
+	r := newTestServerRenderer(&fakeCompiler{svg: svg})
+
+	html, err := r.ToHTML([]byte(diagramSrc))
+	require.NoError(t, err)
+
+	out := string(html)
+	assert.Contains(t, out, `<div class="mermaid-static"><svg viewbox="0 0 10 10">`)
+	assert.Contains(t, out, `<style>.node rect { fill: #eee; }</style>`)
+	assert.Contains(t, out, `<span class="nodeLabel">A</span>`)
+	assert.Contains(t, out, `<a href="https://example.com"`)
+	assert.NotContains(t, out, "alert")
+	assert.NotContains(t, out, "<img")
+}
+
+func TestRenderer_ServerMermaid_UsesRequestContext(t *testing.T) {
+	compiler := &fakeCompiler{svg: `<svg></svg>`}
+	r := newTestServerRenderer(compiler)
+
+	type ctxKey struct{}
+
+	ctx := context.WithValue(t.Context(), ctxKey{}, "request")
+
+	_, _, err := r.RenderHTMLContext(ctx, []byte(diagramSrc))
+	require.NoError(t, err)
+	require.NotNil(t, compiler.ctx)
+	assert.Equal(t, "request", compiler.ctx.Value(ctxKey{}))
+
+	_, hasDeadline := compiler.ctx.Deadline()
+	assert.True(t, hasDeadline, "compiling is bounded by the configured timeout")
+}
@@ -3,9 +3,14 @@ package markdown
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
+	"unicode"
 
 	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
 	"github.com/ksysoev/omnidex/pkg/core"
@@ -16,7 +21,10 @@ import (
 	"github.com/yuin/goldmark/extension"
 	east "github.com/yuin/goldmark/extension/ast"
 	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
 	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
 	gmm "go.abhg.dev/goldmark/mermaid"
 )
 
@@ -32,55 +40,269 @@ var mermaidClassPattern = regexp.MustCompile(`^mermaid$`)
 // <pre> elements — scripts, event handlers, and inline styles are still stripped regardless.
 var chromaClassPattern = regexp.MustCompile(`^(chroma|bg|line|lnt|ln|hl|lnlinks|lntable|lntd|[a-z]{1,3})$`)
 
+// footnoteClassPattern matches the class names goldmark's footnote extension emits on
+// reference links, back-links and the footnote list container.
+var footnoteClassPattern = regexp.MustCompile(`^(footnote-ref|footnote-backref|footnotes)$`)
+
+// footnoteRolePattern matches the ARIA roles goldmark's footnote extension emits.
+var footnoteRolePattern = regexp.MustCompile(`^doc-(noteref|backlink|endnotes)$`)
+
 // Renderer converts markdown content to HTML, extracts titles, and strips markdown to plain text.
 // HTML output is sanitized using bluemonday to prevent XSS attacks from user-submitted markdown.
 type Renderer struct {
-	md       goldmark.Markdown
-	sanitize *bluemonday.Policy
+	md          goldmark.Markdown
+	sanitize    *bluemonday.Policy
+	diagrams    *diagramRenderer
+	repos       map[string]*Renderer
+	headingIDs  string
+	fingerprint string
+	limits      Limits
+}
+
+// Config holds configuration for the markdown renderer.
+// The embedded Options apply to every repository unless a matching entry in Repos
+// overrides them. Limits apply to every repository.
+type Config struct {
+	Repos   []RepoOptions `mapstructure:"repos"`
+	Options `mapstructure:",squash"`
+	Mermaid MermaidConfig `mapstructure:"mermaid"`
+	Limits  Limits        `mapstructure:"limits"`
+}
+
+// Options controls goldmark rendering behavior.
+// Typographer converts straight quotes, dashes and ellipses to their typographic forms.
+// HardWraps renders soft line breaks as <br>. UnsafeHTML passes raw HTML embedded in
+// markdown through to the sanitizer instead of omitting it; the output is still
+// sanitized, so scripts and event handlers are removed regardless. HeadingIDs selects
+// how heading IDs are generated: "ascii" (default), "unicode" or "transliterate".
+type Options struct {
+	HeadingIDs  string `mapstructure:"heading_ids"`
+	Typographer bool   `mapstructure:"typographer"`
+	HardWraps   bool   `mapstructure:"hard_wraps"`
+	UnsafeHTML  bool   `mapstructure:"unsafe_html"`
+}
+
+// RepoOptions overrides the rendering options for a single repository.
+type RepoOptions struct {
+	Repo    string `mapstructure:"repo"`
+	Options `mapstructure:",squash"`
+}
+
+// New creates a new Renderer with goldmark configuration and HTML sanitization.
+// It returns an error if the configuration is invalid, for example when server-side
+// Mermaid rendering is requested but the Mermaid CLI is not available.
+func New(cfg Config) (*Renderer, error) {
+	var diagrams *diagramRenderer
+
+	switch cfg.Mermaid.Mode {
+	case "", MermaidModeClient:
+	case MermaidModeServer:
+		d, err := newDiagramRenderer(cfg.Mermaid)
+		if err != nil {
+			return nil, fmt.Errorf("failed to init mermaid server rendering: %w", err)
+		}
+
+		diagrams = d
+	default:
+		return nil, fmt.Errorf("unknown mermaid mode %q: must be \"client\" or \"server\"", cfg.Mermaid.Mode)
+	}
+
+	if err := validateHeadingIDs(cfg.HeadingIDs); err != nil {
+		return nil, err
+	}
+
+	limits := cfg.Limits.withDefaults()
+	r := newRenderer(cfg.Options, limits, diagrams)
+
+	for _, override := range cfg.Repos {
+		if override.Repo == "" {
+			return nil, fmt.Errorf("markdown repo options must specify a repo")
+		}
+
+		if err := validateHeadingIDs(override.HeadingIDs); err != nil {
+			return nil, fmt.Errorf("markdown repo options for %s: %w", override.Repo, err)
+		}
+
+		if r.repos == nil {
+			r.repos = make(map[string]*Renderer, len(cfg.Repos))
+		}
+
+		r.repos[strings.ToLower(override.Repo)] = newRenderer(override.Options, limits, diagrams)
+	}
+
+	return r, nil
 }
 
-// New creates a new Renderer with default goldmark configuration and HTML sanitization.
-func New() *Renderer {
-	md := goldmark.New(
+// ForRepo returns the renderer configured for the given repository, falling back to the
+// default renderer when the repository has no option overrides.
+func (r *Renderer) ForRepo(repo string) core.ContentProcessor {
+	if rr, ok := r.repos[strings.ToLower(repo)]; ok {
+		return rr
+	}
+
+	return r
+}
+
+// TextFingerprint identifies the options and limits of the renderer, which decide the
+// plain text, headings and anchors it extracts, so that the text stored with documents is
+// extracted again once the configuration of their repository changes.
+func (r *Renderer) TextFingerprint() string {
+	return r.fingerprint
+}
+
+// ContentTypeInfo describes markdown documents. Markdown is also the content type of files
+// whose extension no other content type lists, such as README without an extension.
+func (r *Renderer) ContentTypeInfo() core.ContentTypeInfo {
+	return core.ContentTypeInfo{
+		Name:        core.ContentTypeMarkdown,
+		DisplayName: "Markdown",
+		Icon:        "document",
+		Extensions:  []string{".md", ".markdown"},
+		Fallback:    true,
+	}
+}
+
+// newRenderer builds the goldmark pipeline and sanitization policy for the given options.
+// When diagrams is non-nil, it overrides client-side rendering of Mermaid blocks.
+func newRenderer(o Options, limits Limits, diagrams *diagramRenderer) *Renderer {
+	extensions := []goldmark.Extender{
+		extension.GFM,
+		extension.Footnote,
+		extension.DefinitionList,
+		mathExtension{},
+		&gmm.Extender{
+			RenderMode: gmm.RenderModeClient,
+			NoScript:   true,
+		},
+		highlighting.NewHighlighting(
+			highlighting.WithStyle("github-dark"),
+			highlighting.WithFormatOptions(
+				chromahtml.WithClasses(true),
+				chromahtml.WithAllClasses(true),
+			),
+		),
+	}
+
+	if o.Typographer {
+		extensions = append(extensions, extension.Typographer)
+	}
+
+	var htmlOpts []renderer.Option
+
+	if o.HardWraps {
+		htmlOpts = append(htmlOpts, html.WithHardWraps())
+	}
+
+	if o.UnsafeHTML {
+		htmlOpts = append(htmlOpts, html.WithUnsafe())
+	} else {
+		// In safe mode only collapsible <details>/<summary> sections survive as raw HTML.
+		htmlOpts = append(htmlOpts,
+			renderer.WithNodeRenderers(util.Prioritized(&detailsRenderer{}, detailsRendererPriority)))
+	}
+
+	opts := []goldmark.Option{
 		goldmark.WithParserOptions(
 			parser.WithAutoHeadingID(),
+			// Explicit heading IDs ("## Setup {#setup-prod}") keep deep links stable when
+			// heading text is reworded. Other attributes are stripped by the sanitizer.
+			parser.WithAttribute(),
 		),
-		goldmark.WithExtensions(
-			extension.GFM,
-			&gmm.Extender{
-				RenderMode: gmm.RenderModeClient,
-				NoScript:   true,
-			},
-			highlighting.NewHighlighting(
-				highlighting.WithStyle("github-dark"),
-				highlighting.WithFormatOptions(
-					chromahtml.WithClasses(true),
-					chromahtml.WithAllClasses(true),
-				),
-			),
-		),
-	)
+		goldmark.WithExtensions(extensions...),
+		goldmark.WithRendererOptions(htmlOpts...),
+	}
+
+	if diagrams != nil {
+		opts = append(opts, goldmark.WithRendererOptions(
+			renderer.WithNodeRenderers(util.Prioritized(diagrams, mermaidRendererPriority)),
+		))
+	}
+
+	md := goldmark.New(opts...)
 
 	policy := bluemonday.UGCPolicy()
 	policy.AllowAttrs("class").Matching(mermaidClassPattern).OnElements("pre")
 	policy.AllowAttrs("id").OnElements("h1", "h2", "h3", "h4", "h5", "h6")
 	policy.AllowElements("span")
 	policy.AllowAttrs("class").Matching(chromaClassPattern).OnElements("span", "code", "pre")
+	policy.AllowAttrs("class").Matching(footnoteClassPattern).OnElements("a", "div")
+	policy.AllowAttrs("role").Matching(footnoteRolePattern).OnElements("a", "div")
+	policy.AllowAttrs("class").Matching(tocClassPattern).OnElements("ul")
+	policy.AllowAttrs("class").Matching(mathClassPattern).OnElements("span", "div")
+
+	return &Renderer{
+		md:          md,
+		sanitize:    policy,
+		diagrams:    diagrams,
+		headingIDs:  o.HeadingIDs,
+		fingerprint: optionsFingerprint(o, limits),
+		limits:      limits,
+	}
+}
 
-	return &Renderer{md: md, sanitize: policy}
+// optionsFingerprint returns a short digest of the options and limits of a renderer.
+func optionsFingerprint(o Options, limits Limits) string {
+	sum := sha256.Sum256(fmt.Appendf(nil, "%+v %+v", o, limits))
+	return hex.EncodeToString(sum[:8])
+}
+
+// parse parses markdown source, generating the IDs of headings without an explicit ID
+// as configured by the HeadingIDs option.
+func (r *Renderer) parse(src []byte) ast.Node {
+	ids := newHeadingIDs(r.headingIDs)
+	if ids == nil {
+		return r.md.Parser().Parse(text.NewReader(src))
+	}
+
+	return r.md.Parser().Parse(text.NewReader(src), parser.WithContext(parser.NewContext(parser.WithIDs(ids))))
 }
 
 // ToHTML converts markdown source to sanitized HTML.
 // The output is sanitized to prevent XSS from crafted markdown inputs.
+// A document exceeding the renderer limits is converted to a notice showing its source.
 func (r *Renderer) ToHTML(src []byte) ([]byte, error) {
+	html, _, err := r.RenderHTML(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert markdown to HTML: %w", err)
+	}
+
+	return html, nil
+}
+
+// render renders a parsed document to sanitized HTML. [[TOC]] markers are expanded into
+// an in-content table of contents. When server-side Mermaid rendering is enabled, diagrams
+// are compiled before rendering and their SVGs are inserted after sanitization.
+func (r *Renderer) render(ctx context.Context, doc ast.Node, src []byte) ([]byte, error) {
+	expandTOCMarkers(doc, src, collectHeadings(doc, src))
+
+	var state *renderState
+
+	if r.diagrams != nil {
+		s, err := newRenderState()
+		if err != nil {
+			return nil, err
+		}
+
+		r.diagrams.prepare(ctx, doc, src, s)
+		state = s
+	}
+
 	var buf bytes.Buffer
+	if err := r.md.Renderer().Render(&buf, src, doc); err != nil {
+		return nil, err
+	}
 
-	if err := r.md.Convert(src, &buf); err != nil {
-		return nil, fmt.Errorf("failed to convert markdown to HTML: %w", err)
+	if buf.Len() > r.limits.MaxHTMLKiB<<10 {
+		return nil, fmt.Errorf("%w: rendered HTML is %d KiB, the maximum is %d KiB",
+			core.ErrLimitExceeded, buf.Len()>>10, r.limits.MaxHTMLKiB)
 	}
 
 	sanitized := r.sanitize.SanitizeBytes(buf.Bytes())
 
+	if state != nil {
+		sanitized = state.substitute(sanitized)
+	}
+
 	return sanitized, nil
 }
 
@@ -108,11 +330,15 @@ func extractNodeText(n ast.Node, src []byte) string {
 	return buf.String()
 }
 
-// ExtractTitle extracts the title from the first H1 heading in the markdown content.
-// If no H1 is found, it returns an empty string.
+// ExtractTitle returns the title set in the frontmatter of the markdown content, or else
+// the text of its first H1 heading. If there is neither, it returns an empty string.
 func (r *Renderer) ExtractTitle(src []byte) string {
-	reader := text.NewReader(src)
-	doc := r.md.Parser().Parse(reader)
+	fm, src := splitFrontmatter(r.truncate(src))
+	if title := strings.TrimSpace(fm.Title); title != "" {
+		return title
+	}
+
+	doc := r.parse(src)
 
 	var title string
 
@@ -134,11 +360,91 @@ func (r *Renderer) ExtractTitle(src []byte) string {
 	return title
 }
 
+// ExtractSummary returns the text of the first top-level paragraph of the markdown content,
+// skipping table of contents markers and paragraphs made only of images, such as badges.
+// It returns an empty string when the content has no such paragraph.
+func (r *Renderer) ExtractSummary(src []byte) string {
+	src = r.body(src)
+	doc := r.parse(src)
+
+	for n := doc.FirstChild(); n != nil; n = n.NextSibling() {
+		if _, ok := n.(*ast.Paragraph); !ok || isTOCMarker(n, src) {
+			continue
+		}
+
+		if summary := strings.TrimSpace(paragraphText(n, src)); summary != "" {
+			return summary
+		}
+	}
+
+	return ""
+}
+
+// paragraphText returns the text of a paragraph as a single line, without the alt text of
+// its images.
+func paragraphText(n ast.Node, src []byte) string {
+	var buf bytes.Buffer
+
+	_ = ast.Walk(n, func(child ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch node := child.(type) {
+		case *ast.Image:
+			return ast.WalkSkipChildren, nil
+		case *ast.Text:
+			buf.Write(node.Segment.Value(src))
+
+			if node.SoftLineBreak() || node.HardLineBreak() {
+				buf.WriteByte(' ')
+			}
+		case *ast.String:
+			buf.Write(node.Value)
+		}
+
+		return ast.WalkContinue, nil
+	})
+
+	return buf.String()
+}
+
 // ToPlainText strips markdown formatting and returns plain text content suitable for search indexing.
 func (r *Renderer) ToPlainText(src []byte) string {
-	reader := text.NewReader(src)
-	doc := r.md.Parser().Parse(reader)
+	src = r.body(src)
+	doc := r.parse(src)
+
+	return plainText(doc, src, nil)
+}
+
+// MapAnchors returns the anchors of the H1-H3 headings at the offsets where their lines
+// start in the plain text returned by ToPlainText. Unlike headings located by their text,
+// headings with inline markup or repeated text always map to their own line.
+func (r *Renderer) MapAnchors(src []byte) core.AnchorMap {
+	src = r.body(src)
+	doc := r.parse(src)
+
+	anchors := core.AnchorMap{}
+
+	text := plainText(doc, src, func(heading *ast.Heading, offset int) {
+		if h, ok := headingOf(heading, src); ok && h.Text != "" {
+			anchors = append(anchors, core.Anchor{ID: h.ID, Text: h.Text, Offset: offset, Level: h.Level})
+		}
+	})
 
+	// The offsets were taken before the leading whitespace of the plain text was trimmed.
+	trimmed := len(text) - len(strings.TrimLeftFunc(text, unicode.IsSpace))
+	for i := range anchors {
+		anchors[i].Offset = max(anchors[i].Offset-trimmed, 0)
+	}
+
+	return anchors
+}
+
+// plainText returns the plain text of a parsed document, with its leading and trailing
+// whitespace trimmed unless onHeading is set. onHeading is called with each heading and
+// the byte offset where its line starts.
+func plainText(doc ast.Node, src []byte, onHeading func(heading *ast.Heading, offset int)) string {
 	var buf bytes.Buffer
 
 	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
@@ -173,7 +479,37 @@ func (r *Renderer) ToPlainText(src []byte) string {
 			}
 
 			return ast.WalkSkipChildren, nil
-		case *ast.Paragraph, *ast.Heading, *ast.ListItem:
+		case *mathBlock:
+			if buf.Len() > 0 && buf.Bytes()[buf.Len()-1] != '\n' {
+				buf.WriteByte('\n')
+			}
+
+			lines := node.Lines()
+			for i := range lines.Len() {
+				line := lines.At(i)
+				buf.Write(line.Value(src))
+			}
+
+			buf.WriteByte('\n')
+
+			return ast.WalkSkipChildren, nil
+		case *ast.Paragraph:
+			if isTOCMarker(node, src) {
+				return ast.WalkSkipChildren, nil
+			}
+
+			if buf.Len() > 0 && buf.Bytes()[buf.Len()-1] != '\n' {
+				buf.WriteByte('\n')
+			}
+		case *ast.Heading:
+			if buf.Len() > 0 && buf.Bytes()[buf.Len()-1] != '\n' {
+				buf.WriteByte('\n')
+			}
+
+			if onHeading != nil {
+				onHeading(node, buf.Len())
+			}
+		case *ast.ListItem, *east.DefinitionTerm, *east.DefinitionDescription, *east.Footnote:
 			if buf.Len() > 0 && buf.Bytes()[buf.Len()-1] != '\n' {
 				buf.WriteByte('\n')
 			}
@@ -185,6 +521,17 @@ func (r *Renderer) ToPlainText(src []byte) string {
 			if buf.Len() > 0 && buf.Bytes()[buf.Len()-1] != '\n' {
 				buf.WriteByte('\n')
 			}
+		case *ast.HTMLBlock:
+			if text, ok := detailsText(htmlBlockSource(node, src)); ok && text != "" {
+				if buf.Len() > 0 && buf.Bytes()[buf.Len()-1] != '\n' {
+					buf.WriteByte('\n')
+				}
+
+				buf.WriteString(text)
+				buf.WriteByte('\n')
+			}
+
+			return ast.WalkSkipChildren, nil
 		case *east.TableCell:
 			if node.PreviousSibling() != nil {
 				buf.WriteByte('\t')
@@ -194,71 +541,142 @@ func (r *Renderer) ToPlainText(src []byte) string {
 		return ast.WalkContinue, nil
 	})
 
+	if onHeading != nil {
+		return buf.String()
+	}
+
 	return strings.TrimSpace(buf.String())
 }
 
 // RenderHTML parses the markdown source once, extracts H1-H3 headings from
 // the AST for table of contents rendering, then renders the AST to sanitized HTML.
 // This avoids the cost of parsing the same source twice compared to calling ToHTML
-// and ExtractHeadings separately.
+// and ExtractHeadings separately. A document exceeding the renderer limits, e.g. one
+// stored before the limits were lowered, is rendered as a notice showing its source.
 func (r *Renderer) RenderHTML(src []byte) ([]byte, []core.Heading, error) {
-	reader := text.NewReader(src)
-	doc := r.md.Parser().Parse(reader)
+	return r.RenderHTMLContext(context.Background(), src)
+}
+
+// RenderHTMLContext is RenderHTML for a request: server-side Mermaid diagrams stop
+// compiling when ctx is done.
+func (r *Renderer) RenderHTMLContext(ctx context.Context, src []byte) ([]byte, []core.Heading, error) {
+	if err := r.checkSize(src); err != nil {
+		return r.limitNotice(src, err), nil, nil
+	}
+
+	_, src = splitFrontmatter(src)
+	doc := r.parse(src)
+
+	if err := r.checkDepth(doc); err != nil {
+		return r.limitNotice(src, err), nil, nil
+	}
 
 	headings := collectHeadings(doc, src)
 
-	var buf bytes.Buffer
-	if err := r.md.Renderer().Render(&buf, src, doc); err != nil {
+	html, err := r.render(ctx, doc, src)
+	if err != nil {
+		if errors.Is(err, core.ErrLimitExceeded) {
+			return r.limitNotice(src, err), nil, nil
+		}
+
 		return nil, nil, fmt.Errorf("failed to render markdown to HTML: %w", err)
 	}
 
-	sanitized := r.sanitize.SanitizeBytes(buf.Bytes())
-
-	return sanitized, headings, nil
+	return html, headings, nil
 }
 
 // ExtractHeadings walks the Goldmark AST and extracts H1-H3 headings with their
 // auto-generated IDs and text content, suitable for table of contents rendering.
 func (r *Renderer) ExtractHeadings(src []byte) []core.Heading {
-	reader := text.NewReader(src)
-	doc := r.md.Parser().Parse(reader)
+	src = r.body(src)
+	doc := r.parse(src)
 
 	return collectHeadings(doc, src)
 }
 
-// collectHeadings walks a parsed AST and extracts H1-H3 headings with their
-// auto-generated IDs and text content.
-func collectHeadings(doc ast.Node, src []byte) []core.Heading {
-	var headings []core.Heading
+// ExtractCodeBlocks returns the contents of fenced code blocks together with their
+// lowercased language, for code search. Mermaid diagrams are not code and are skipped.
+func (r *Renderer) ExtractCodeBlocks(src []byte) []core.CodeBlock {
+	src = r.body(src)
+	doc := r.parse(src)
+
+	var blocks []core.CodeBlock
 
 	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
 		if !entering {
 			return ast.WalkContinue, nil
 		}
 
-		heading, ok := n.(*ast.Heading)
-		if !ok || heading.Level > 3 {
+		node, ok := n.(*ast.FencedCodeBlock)
+		if !ok {
 			return ast.WalkContinue, nil
 		}
 
-		idAttr, ok := heading.AttributeString("id")
-		if !ok {
-			return ast.WalkContinue, nil
+		lang := strings.ToLower(string(node.Language(src)))
+		if lang == "mermaid" {
+			return ast.WalkSkipChildren, nil
 		}
 
-		idBytes, ok := idAttr.([]byte)
-		if !ok {
+		var buf bytes.Buffer
+
+		lines := node.Lines()
+		for i := range lines.Len() {
+			line := lines.At(i)
+			buf.Write(line.Value(src))
+		}
+
+		if code := strings.TrimRight(buf.String(), "\n"); code != "" {
+			blocks = append(blocks, core.CodeBlock{Lang: lang, Code: code})
+		}
+
+		return ast.WalkSkipChildren, nil
+	})
+
+	return blocks
+}
+
+// collectHeadings walks a parsed AST and extracts H1-H3 headings with their
+// auto-generated IDs and text content.
+func collectHeadings(doc ast.Node, src []byte) []core.Heading {
+	var headings []core.Heading
+
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
 			return ast.WalkContinue, nil
 		}
 
-		headings = append(headings, core.Heading{
-			Level: heading.Level,
-			ID:    string(idBytes),
-			Text:  extractNodeText(heading, src),
-		})
+		if heading, ok := n.(*ast.Heading); ok {
+			if h, ok := headingOf(heading, src); ok {
+				headings = append(headings, h)
+			}
+		}
 
 		return ast.WalkContinue, nil
 	})
 
 	return headings
 }
+
+// headingOf returns an H1-H3 heading with its auto-generated ID and text content, and
+// false for deeper headings and headings without an ID.
+func headingOf(heading *ast.Heading, src []byte) (core.Heading, bool) {
+	if heading.Level > 3 {
+		return core.Heading{}, false
+	}
+
+	idAttr, ok := heading.AttributeString("id")
+	if !ok {
+		return core.Heading{}, false
+	}
+
+	idBytes, ok := idAttr.([]byte)
+	if !ok {
+		return core.Heading{}, false
+	}
+
+	return core.Heading{
+		Level: heading.Level,
+		ID:    string(idBytes),
+		Text:  extractNodeText(heading, src),
+	}, true
+}
@@ -2,19 +2,23 @@ package markdown
 
 import (
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/ksysoev/omnidex/pkg/core"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNew(t *testing.T) {
-	r := New()
+	r, err := New(Config{})
+	require.NoError(t, err)
 	assert.NotNil(t, r)
 }
 
 func TestRenderer_ToHTML(t *testing.T) {
-	r := New()
+	r, err := New(Config{})
+	require.NoError(t, err)
 
 	tests := []struct {
 		name     string
@@ -83,7 +87,8 @@ func TestRenderer_ToHTML(t *testing.T) {
 }
 
 func TestRenderer_ToHTML_TaskListSanitized(t *testing.T) {
-	r := New()
+	r, err := New(Config{})
+	require.NoError(t, err)
 
 	// GFM task lists produce <input type="checkbox"> elements, but
 	// bluemonday.UGCPolicy() strips them for security. Verify that
@@ -98,7 +103,8 @@ func TestRenderer_ToHTML_TaskListSanitized(t *testing.T) {
 }
 
 func TestRenderer_ExtractTitle(t *testing.T) {
-	r := New()
+	r, err := New(Config{})
+	require.NoError(t, err)
 
 	tests := []struct {
 		name  string
@@ -135,8 +141,47 @@ func TestRenderer_ExtractTitle(t *testing.T) {
 	}
 }
 
+func TestRenderer_ExtractSummary(t *testing.T) {
+	r, err := New(Config{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "first paragraph",
+			input: "# Guide\n\nDeploy the **service** with\n[Helm](https://helm.sh).\n\nSecond paragraph.",
+			want:  "Deploy the service with Helm.",
+		},
+		{
+			name:  "skips badges and TOC marker",
+			input: "# Guide\n\n[![Build](https://ci/badge.svg)](https://ci)\n\n[[toc]]\n\nThe guide.",
+			want:  "The guide.",
+		},
+		{
+			name:  "skips nested paragraphs",
+			input: "# Guide\n\n- list item\n\n> quoted\n\nBody text.",
+			want:  "Body text.",
+		},
+		{
+			name:  "no paragraph",
+			input: "# Guide\n\n## Section",
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, r.ExtractSummary([]byte(tt.input)))
+		})
+	}
+}
+
 func TestRenderer_ToHTML_Sanitization(t *testing.T) {
-	r := New()
+	r, err := New(Config{})
+	require.NoError(t, err)
 
 	tests := []struct {
 		name     string
@@ -170,7 +215,8 @@ func TestRenderer_ToHTML_Sanitization(t *testing.T) {
 }
 
 func TestRenderer_ToPlainText(t *testing.T) {
-	r := New()
+	r, err := New(Config{})
+	require.NoError(t, err)
 
 	tests := []struct {
 		name     string
@@ -232,8 +278,40 @@ func TestRenderer_ToPlainText(t *testing.T) {
 	}
 }
 
+func TestRenderer_ToHTML_FootnotesAndDefinitionLists(t *testing.T) {
+	r, err := New(Config{})
+	require.NoError(t, err)
+
+	input := "Omnidex indexes docs.[^1]\n\n[^1]: Markdown and OpenAPI.\n\nTerm\n: Definition of the term.\n"
+
+	result, err := r.ToHTML([]byte(input))
+	require.NoError(t, err)
+
+	html := string(result)
+	assert.Contains(t, html, `<sup id="fnref:1"><a href="#fn:1" class="footnote-ref" role="doc-noteref" rel="nofollow">1</a></sup>`)
+	assert.Contains(t, html, `<div class="footnotes" role="doc-endnotes">`)
+	assert.Contains(t, html, `<li id="fn:1">`)
+	assert.Contains(t, html, `class="footnote-backref" role="doc-backlink"`)
+	assert.Contains(t, html, "<dl>\n<dt>Term</dt>\n<dd>Definition of the term.</dd>\n</dl>")
+}
+
+func TestRenderer_ToPlainText_FootnotesAndDefinitionLists(t *testing.T) {
+	r, err := New(Config{})
+	require.NoError(t, err)
+
+	input := "Omnidex indexes docs.[^note]\n\n[^note]: Markdown and OpenAPI.\n\nTerm\n: Definition of the term.\n"
+
+	result := r.ToPlainText([]byte(input))
+
+	assert.Contains(t, result, "Omnidex indexes docs.")
+	assert.Contains(t, result, "Markdown and OpenAPI.")
+	assert.Contains(t, result, "Term\nDefinition of the term.")
+	assert.NotContains(t, result, "[^note]")
+}
+
 func TestRenderer_ToPlainText_MultipleBlocks(t *testing.T) {
-	r := New()
+	r, err := New(Config{})
+	require.NoError(t, err)
 
 	input := "# Title\n\nFirst paragraph.\n\n## Subtitle\n\nSecond paragraph with **bold** and *italic*.\n\n- Item one\n- Item two\n\n```go\nfmt.Println(\"hello\")\n```"
 
@@ -251,7 +329,8 @@ func TestRenderer_ToPlainText_MultipleBlocks(t *testing.T) {
 }
 
 func TestRenderer_ToPlainText_Table(t *testing.T) {
-	r := New()
+	r, err := New(Config{})
+	require.NoError(t, err)
 
 	input := "# Title\n\n| Name | Age |\n|------|-----|\n| Alice | 30 |\n| Bob | 25 |\n\nAfter table."
 
@@ -270,7 +349,8 @@ func TestRenderer_ToPlainText_Table(t *testing.T) {
 }
 
 func TestRenderer_ExtractTitle_FormattedH1(t *testing.T) {
-	r := New()
+	r, err := New(Config{})
+	require.NoError(t, err)
 
 	tests := []struct {
 		name  string
@@ -318,7 +398,8 @@ func TestRenderer_ExtractTitle_FormattedH1(t *testing.T) {
 }
 
 func TestRenderer_ToHTML_MermaidBlock(t *testing.T) {
-	r := New()
+	r, err := New(Config{})
+	require.NoError(t, err)
 
 	input := "```mermaid\ngraph TD;\n    A-->B;\n```"
 
@@ -332,7 +413,8 @@ func TestRenderer_ToHTML_MermaidBlock(t *testing.T) {
 }
 
 func TestRenderer_ToHTML_MermaidClassSurvivesSanitization(t *testing.T) {
-	r := New()
+	r, err := New(Config{})
+	require.NoError(t, err)
 
 	input := "```mermaid\ngraph LR;\n    Start-->End;\n```"
 
@@ -345,7 +427,8 @@ func TestRenderer_ToHTML_MermaidClassSurvivesSanitization(t *testing.T) {
 }
 
 func TestRenderer_ToPlainText_MermaidExcluded(t *testing.T) {
-	r := New()
+	r, err := New(Config{})
+	require.NoError(t, err)
 
 	input := "# Title\n\nSome text.\n\n```mermaid\ngraph TD;\n    A-->B;\n    C-->D;\n```\n\nAfter diagram."
 
@@ -359,7 +442,8 @@ func TestRenderer_ToPlainText_MermaidExcluded(t *testing.T) {
 }
 
 func TestRenderer_ToHTML_MermaidComplexSyntaxSurvivesSanitization(t *testing.T) {
-	r := New()
+	r, err := New(Config{})
+	require.NoError(t, err)
 
 	input := "```mermaid\ngraph TD;\n    A[\"Label with <b>HTML</b> & more\"]-->B;\n```"
 
@@ -379,7 +463,8 @@ func TestRenderer_ToHTML_MermaidComplexSyntaxSurvivesSanitization(t *testing.T)
 }
 
 func TestRenderer_ToPlainText_NonMermaidCodePreserved(t *testing.T) {
-	r := New()
+	r, err := New(Config{})
+	require.NoError(t, err)
 
 	input := "```go\nfmt.Println(\"hello\")\n```\n\n```mermaid\ngraph TD;\n    A-->B;\n```"
 
@@ -392,7 +477,8 @@ func TestRenderer_ToPlainText_NonMermaidCodePreserved(t *testing.T) {
 }
 
 func TestRenderer_ExtractHeadings(t *testing.T) {
-	r := New()
+	r, err := New(Config{})
+	require.NoError(t, err)
 
 	tests := []struct {
 		name  string
@@ -446,6 +532,15 @@ func TestRenderer_ExtractHeadings(t *testing.T) {
 				{Level: 3, ID: "the-linkhttpsexamplecom-section", Text: "The Link Section"},
 			},
 		},
+		{
+			name:  "explicit heading IDs",
+			input: "## Setup {#setup-prod}\n\n## Setup\n\n### Deploy to *prod* {#deploy}\n",
+			want: []core.Heading{
+				{Level: 2, ID: "setup-prod", Text: "Setup"},
+				{Level: 2, ID: "setup", Text: "Setup"},
+				{Level: 3, ID: "deploy", Text: "Deploy to prod"},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -456,8 +551,31 @@ func TestRenderer_ExtractHeadings(t *testing.T) {
 	}
 }
 
+func TestRenderer_MapAnchors(t *testing.T) {
+	r, err := New(Config{})
+	require.NoError(t, err)
+
+	src := []byte("# Guide\n\nIntro text\n\n## Run `make`\n\nBuild.\n\n## Run make\n\nAgain.\n\n#### Deep\n")
+
+	anchors := r.MapAnchors(src)
+	text := r.ToPlainText(src)
+
+	assert.Equal(t, core.AnchorMap{
+		{ID: "guide", Text: "Guide", Offset: 0, Level: 1},
+		{ID: "run-make", Text: "Run make", Offset: 17, Level: 2},
+		{ID: "run-make-1", Text: "Run make", Offset: 33, Level: 2},
+	}, anchors)
+
+	for _, a := range anchors {
+		assert.True(t, strings.HasPrefix(text[a.Offset:], a.Text+"\n"), "anchor %q is not at its heading line", a.ID)
+	}
+
+	assert.Equal(t, core.AnchorMap{}, r.MapAnchors([]byte("No headings here.")))
+}
+
 func TestRenderer_ToHTML_HeadingIDSurvivesSanitization(t *testing.T) {
-	r := New()
+	r, err := New(Config{})
+	require.NoError(t, err)
 
 	input := "# Hello World\n\n## Getting Started\n"
 
@@ -469,8 +587,22 @@ func TestRenderer_ToHTML_HeadingIDSurvivesSanitization(t *testing.T) {
 	assert.Contains(t, html, `<h2 id="getting-started">`)
 }
 
+func TestRenderer_ToHTML_ExplicitHeadingID(t *testing.T) {
+	r, err := New(Config{})
+	require.NoError(t, err)
+
+	result, err := r.ToHTML([]byte("## Setup {#setup-prod .evil onclick=\"alert(1)\"}\n"))
+	require.NoError(t, err)
+
+	html := string(result)
+	assert.Contains(t, html, `<h2 id="setup-prod">Setup</h2>`)
+	assert.NotContains(t, html, "onclick")
+	assert.NotContains(t, html, "{#setup-prod")
+}
+
 func TestRenderer_RenderHTML(t *testing.T) {
-	r := New()
+	r, err := New(Config{})
+	require.NoError(t, err)
 
 	input := "# Introduction\n\nSome content.\n\n## Getting Started\n\n### Installation\n\nMore content.\n"
 
@@ -488,7 +620,8 @@ func TestRenderer_RenderHTML(t *testing.T) {
 }
 
 func TestRenderer_RenderHTML_EmptyInput(t *testing.T) {
-	r := New()
+	r, err := New(Config{})
+	require.NoError(t, err)
 
 	html, headings, err := r.RenderHTML([]byte(""))
 	assert.NoError(t, err)
@@ -497,7 +630,8 @@ func TestRenderer_RenderHTML_EmptyInput(t *testing.T) {
 }
 
 func TestRenderer_RenderHTML_NoHeadings(t *testing.T) {
-	r := New()
+	r, err := New(Config{})
+	require.NoError(t, err)
 
 	html, headings, err := r.RenderHTML([]byte("Just a paragraph.\n"))
 	assert.NoError(t, err)
@@ -506,7 +640,8 @@ func TestRenderer_RenderHTML_NoHeadings(t *testing.T) {
 }
 
 func TestRenderer_RenderHTML_InlineFormatting(t *testing.T) {
-	r := New()
+	r, err := New(Config{})
+	require.NoError(t, err)
 
 	input := "# **Bold Title**\n\n## Install `foo`\n\n### The [Link](https://example.com) Section\n"
 
@@ -522,7 +657,8 @@ func TestRenderer_RenderHTML_InlineFormatting(t *testing.T) {
 }
 
 func TestRenderer_RenderHTML_SanitizesOutput(t *testing.T) {
-	r := New()
+	r, err := New(Config{})
+	require.NoError(t, err)
 
 	input := "# Title\n\n<script>alert('xss')</script>\n"
 
@@ -535,7 +671,8 @@ func TestRenderer_RenderHTML_SanitizesOutput(t *testing.T) {
 }
 
 func TestRenderer_ToHTML_ChromaHighlighting(t *testing.T) {
-	r := New()
+	r, err := New(Config{})
+	require.NoError(t, err)
 
 	// A language-tagged fenced block must produce Chroma markup.
 	input := "```go\npackage main\n```\n"
@@ -552,7 +689,8 @@ func TestRenderer_ToHTML_ChromaHighlighting(t *testing.T) {
 }
 
 func TestRenderer_ToHTML_ChromaClassesSurviveSanitization(t *testing.T) {
-	r := New()
+	r, err := New(Config{})
+	require.NoError(t, err)
 
 	input := "```go\npackage main\n\nfunc main() {}\n```\n"
 
@@ -571,7 +709,8 @@ func TestRenderer_ToHTML_ChromaClassesSurviveSanitization(t *testing.T) {
 }
 
 func TestRenderer_ToHTML_PlainFencedBlockNotHighlighted(t *testing.T) {
-	r := New()
+	r, err := New(Config{})
+	require.NoError(t, err)
 
 	// A fenced block without a language tag must NOT produce Chroma markup.
 	input := "```\nplain text\n```\n"
@@ -583,3 +722,230 @@ func TestRenderer_ToHTML_PlainFencedBlockNotHighlighted(t *testing.T) {
 	assert.NotContains(t, html, `class="chroma"`)
 	assert.Contains(t, html, "plain text")
 }
+
+func TestRenderer_RenderHTML_TOCMarker(t *testing.T) {
+	r, err := New(Config{})
+	require.NoError(t, err)
+
+	input := "# Guide\n\n[[TOC]]\n\n## Install\n\n### Linux\n\n## Usage\n"
+
+	html, headings, err := r.RenderHTML([]byte(input))
+	require.NoError(t, err)
+	require.Len(t, headings, 4)
+
+	want := `<ul class="toc">
+<li><a href="#guide" rel="nofollow">Guide</a>
+<ul>
+<li><a href="#install" rel="nofollow">Install</a>
+<ul>
+<li><a href="#linux" rel="nofollow">Linux</a></li>
+</ul>
+</li>
+<li><a href="#usage" rel="nofollow">Usage</a></li>
+</ul>
+</li>
+</ul>`
+
+	assert.Contains(t, string(html), want)
+	assert.NotContains(t, string(html), "[[TOC]]")
+}
+
+func TestRenderer_TOCMarker_NoHeadingsAndPlainText(t *testing.T) {
+	r, err := New(Config{})
+	require.NoError(t, err)
+
+	html, err := r.ToHTML([]byte("[[toc]]\n\nJust text.\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "<p>Just text.</p>\n", string(html))
+
+	text := r.ToPlainText([]byte("# Title\n\n[[TOC]]\n\nBody.\n"))
+	assert.Equal(t, "Title\nBody.", text)
+}
+
+func TestRenderer_ToHTML_DetailsSummary(t *testing.T) {
+	r, err := New(Config{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		input    string
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "collapsible section with markdown body",
+			input:    "<details>\n<summary>Advanced <b>options</b></summary>\n\n## Tuning\n\nHidden *text*.\n\n</details>\n",
+			contains: []string{"<details>", "<summary>Advanced options</summary>", "</details>", `<h2 id="tuning">Tuning</h2>`, "<em>text</em>"},
+			excludes: []string{"<b>"},
+		},
+		{
+			name:     "event handlers are dropped",
+			input:    "<details onclick=\"alert(1)\" class=\"x\">\n<summary>Title</summary>\n\nBody\n\n</details>\n",
+			contains: []string{"<details>\n<summary>Title</summary>"},
+			excludes: []string{"onclick", "alert", "class"},
+		},
+		{
+			name:     "open details",
+			input:    "<details open>\n<summary>Title</summary>\n\nBody\n\n</details>\n",
+			contains: []string{`<details open="">`, "<summary>Title</summary>"},
+		},
+		{
+			name:     "other raw HTML is omitted",
+			input:    "<div><script>alert(1)</script></div>\n",
+			excludes: []string{"<div>", "<script", "alert"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := r.ToHTML([]byte(tt.input))
+			require.NoError(t, err)
+
+			for _, c := range tt.contains {
+				assert.Contains(t, string(result), c)
+			}
+
+			for _, e := range tt.excludes {
+				assert.NotContains(t, string(result), e)
+			}
+		})
+	}
+}
+
+func TestRenderer_ToPlainText_DetailsSummary(t *testing.T) {
+	r, err := New(Config{})
+	require.NoError(t, err)
+
+	text := r.ToPlainText([]byte("Intro.\n\n<details>\n<summary>Advanced options</summary>\n\nHidden body.\n\n</details>\n"))
+
+	assert.Equal(t, "Intro.\nAdvanced options\nHidden body.", text)
+}
+
+func TestRenderer_Options(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		contains    string
+		notContains string
+		opts        Options
+	}{
+		{
+			name:        "typographer disabled",
+			input:       `"quoted" -- text...`,
+			contains:    "&#34;quoted&#34; -- text...",
+			notContains: "&ldquo;",
+		},
+		{
+			name:     "typographer enabled",
+			input:    `"quoted" -- text...`,
+			opts:     Options{Typographer: true},
+			contains: "“quoted” – text…",
+		},
+		{
+			name:        "soft line breaks",
+			input:       "first\nsecond",
+			contains:    "first\nsecond",
+			notContains: "<br",
+		},
+		{
+			name:     "hard wraps",
+			input:    "first\nsecond",
+			opts:     Options{HardWraps: true},
+			contains: "first<br>\nsecond",
+		},
+		{
+			name:        "unsafe html disabled",
+			input:       "H<sub>2</sub>O",
+			contains:    "H2O",
+			notContains: "<sub>",
+		},
+		{
+			name:     "unsafe html enabled",
+			input:    "H<sub>2</sub>O",
+			opts:     Options{UnsafeHTML: true},
+			contains: "H<sub>2</sub>O",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := New(Config{Options: tt.opts})
+			require.NoError(t, err)
+
+			html, err := r.ToHTML([]byte(tt.input))
+			require.NoError(t, err)
+			assert.Contains(t, string(html), tt.contains)
+
+			if tt.notContains != "" {
+				assert.NotContains(t, string(html), tt.notContains)
+			}
+		})
+	}
+}
+
+func TestRenderer_UnsafeHTMLStillSanitized(t *testing.T) {
+	r, err := New(Config{Options: Options{UnsafeHTML: true}})
+	require.NoError(t, err)
+
+	html, err := r.ToHTML([]byte("<div onclick=\"alert(1)\">hi</div>\n\n<script>alert(1)</script>\n"))
+	require.NoError(t, err)
+
+	out := string(html)
+	assert.Contains(t, out, "<div>hi</div>")
+	assert.NotContains(t, out, "onclick")
+	assert.NotContains(t, out, "<script")
+}
+
+func TestRenderer_ForRepo(t *testing.T) {
+	_, err := New(Config{Repos: []RepoOptions{{Options: Options{HardWraps: true}}}})
+	assert.ErrorContains(t, err, "must specify a repo")
+
+	r, err := New(Config{Repos: []RepoOptions{{Repo: "Owner/Repo", Options: Options{HardWraps: true}}}})
+	require.NoError(t, err)
+
+	assert.Same(t, r, r.ForRepo("other/repo"))
+
+	override := r.ForRepo("owner/repo")
+	require.NotSame(t, r, override)
+
+	html, _, err := override.RenderHTML([]byte("first\nsecond"))
+	require.NoError(t, err)
+	assert.Contains(t, string(html), "first<br>")
+
+	html, err = r.ToHTML([]byte("first\nsecond"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(html), "<br")
+}
+
+func TestRenderer_TextFingerprint(t *testing.T) {
+	r, err := New(Config{Repos: []RepoOptions{{Repo: "owner/intl", Options: Options{HeadingIDs: "unicode"}}}})
+	require.NoError(t, err)
+
+	same, err := New(Config{})
+	require.NoError(t, err)
+
+	assert.Equal(t, same.TextFingerprint(), r.TextFingerprint())
+
+	override, ok := r.ForRepo("owner/intl").(core.TextFingerprinter)
+	require.True(t, ok)
+	assert.NotEqual(t, r.TextFingerprint(), override.TextFingerprint())
+
+	limited, err := New(Config{Limits: Limits{MaxDocumentKiB: 1}})
+	require.NoError(t, err)
+	assert.NotEqual(t, r.TextFingerprint(), limited.TextFingerprint())
+}
+
+func TestRenderer_ExtractCodeBlocks(t *testing.T) {
+	r, err := New(Config{})
+	require.NoError(t, err)
+
+	src := "# Title\n\n```Go\nfunc main() {}\n```\n\n```mermaid\ngraph TD;\n```\n\n" +
+		"    indented code\n\n```\nplain block\n```\n\n```bash\n```\n"
+
+	blocks := r.ExtractCodeBlocks([]byte(src))
+
+	assert.Equal(t, []core.CodeBlock{
+		{Lang: "go", Code: "func main() {}"},
+		{Lang: "", Code: "plain block"},
+	}, blocks)
+}
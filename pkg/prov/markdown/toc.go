@@ -0,0 +1,103 @@
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/yuin/goldmark/ast"
+)
+
+// tocMarker is the paragraph content replaced with an in-content table of contents.
+var tocMarker = []byte("[[TOC]]")
+
+// tocClassPattern matches the class set on generated in-content table of contents lists.
+var tocClassPattern = regexp.MustCompile(`^toc$`)
+
+// isTOCMarker reports whether n is a paragraph consisting solely of the [[TOC]] marker.
+func isTOCMarker(n ast.Node, src []byte) bool {
+	para, ok := n.(*ast.Paragraph)
+	if !ok {
+		return false
+	}
+
+	var buf bytes.Buffer
+
+	lines := para.Lines()
+	for i := range lines.Len() {
+		line := lines.At(i)
+		buf.Write(line.Value(src))
+	}
+
+	return bytes.EqualFold(bytes.TrimSpace(buf.Bytes()), tocMarker)
+}
+
+// expandTOCMarkers replaces every [[TOC]] marker paragraph in the document with a nested
+// list of links to the given headings. Markers are removed when there are no headings.
+func expandTOCMarkers(doc ast.Node, src []byte, headings []core.Heading) {
+	var markers []ast.Node
+
+	for n := doc.FirstChild(); n != nil; n = n.NextSibling() {
+		if isTOCMarker(n, src) {
+			markers = append(markers, n)
+		}
+	}
+
+	for _, marker := range markers {
+		if len(headings) > 0 {
+			doc.InsertBefore(doc, marker, buildTOCList(headings))
+		}
+
+		doc.RemoveChild(doc, marker)
+	}
+}
+
+// buildTOCList builds a nested bullet list mirroring the heading hierarchy. A heading that
+// skips levels is nested directly under the closest shallower heading.
+func buildTOCList(headings []core.Heading) *ast.List {
+	root := newTOCList()
+	root.SetAttributeString("class", []byte("toc"))
+
+	type level struct {
+		list  *ast.List
+		depth int
+	}
+
+	stack := []level{{list: root, depth: headings[0].Level}}
+
+	for _, h := range headings {
+		for len(stack) > 1 && h.Level < stack[len(stack)-1].depth {
+			stack = stack[:len(stack)-1]
+		}
+
+		top := stack[len(stack)-1]
+
+		if h.Level > top.depth && top.list.LastChild() != nil {
+			nested := newTOCList()
+			top.list.LastChild().AppendChild(top.list.LastChild(), nested)
+			stack = append(stack, level{list: nested, depth: h.Level})
+			top = stack[len(stack)-1]
+		}
+
+		link := ast.NewLink()
+		link.Destination = []byte("#" + h.ID)
+		link.AppendChild(link, ast.NewString([]byte(h.Text)))
+
+		block := ast.NewTextBlock()
+		block.AppendChild(block, link)
+
+		item := ast.NewListItem(2)
+		item.AppendChild(item, block)
+		top.list.AppendChild(top.list, item)
+	}
+
+	return root
+}
+
+// newTOCList creates a tight bullet list node.
+func newTOCList() *ast.List {
+	list := ast.NewList('-')
+	list.IsTight = true
+
+	return list
+}
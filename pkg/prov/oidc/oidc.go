@@ -0,0 +1,366 @@
+// Package oidc verifies GitHub Actions OIDC ID tokens, so that CI jobs can publish
+// documentation without a long-lived API key.
+package oidc
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultIssuer is the issuer of the ID tokens of GitHub Actions jobs.
+	DefaultIssuer = "https://token.actions.githubusercontent.com"
+
+	fetchTimeout = 10 * time.Second
+	// keysTTL is how long fetched signing keys are used before being fetched again.
+	keysTTL = time.Hour
+	// refreshInterval is the minimum time between fetches triggered by an unknown key ID,
+	// so that tokens with made-up key IDs cannot make the server hammer the issuer.
+	refreshInterval = time.Minute
+	// leeway is the clock skew tolerated when checking the token lifetime.
+	leeway = time.Minute
+)
+
+// ErrInvalidToken is returned when an ID token is malformed, has an invalid signature or
+// does not satisfy the configured claims.
+var ErrInvalidToken = errors.New("invalid ID token")
+
+// Config configures the acceptance of GitHub Actions ID tokens as ingest credentials.
+type Config struct {
+	// Issuer is the token issuer; its OpenID configuration provides the signing keys.
+	Issuer string `mapstructure:"issuer"`
+	// Audience is the audience tokens must be requested for. Tokens are accepted only
+	// when it is set.
+	Audience string `mapstructure:"audience"`
+	// Owners lists the GitHub users or organizations whose repositories may publish with
+	// an ID token. It is required, as any GitHub Actions workflow can request a token for
+	// any audience.
+	Owners []string `mapstructure:"owners"`
+}
+
+// Enabled reports whether ID tokens are accepted.
+func (c Config) Enabled() bool {
+	return c.Audience != ""
+}
+
+// Validate checks that accepted tokens are limited to some owners when an audience is set.
+func (c Config) Validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+
+	if !slices.ContainsFunc(c.Owners, func(o string) bool { return o != "" }) {
+		return errors.New("owners is required when audience is set")
+	}
+
+	return nil
+}
+
+// Verifier verifies ID tokens against the signing keys published by the issuer.
+type Verifier struct {
+	fetched    time.Time
+	httpClient *http.Client
+	now        func() time.Time
+	keys       map[string]*rsa.PublicKey
+	cfg        Config
+	mu         sync.Mutex
+	// fetchMu serializes key fetches, so that tokens with unknown key IDs wait for the
+	// fetch in progress instead of starting their own.
+	fetchMu sync.Mutex
+}
+
+// New creates a Verifier for the given configuration. Signing keys are fetched on first use.
+func New(cfg Config) *Verifier {
+	if cfg.Issuer == "" {
+		cfg.Issuer = DefaultIssuer
+	}
+
+	cfg.Issuer = strings.TrimSuffix(cfg.Issuer, "/")
+
+	return &Verifier{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: fetchTimeout},
+		now:        time.Now,
+	}
+}
+
+// claims holds the ID token claims checked by the verifier.
+type claims struct {
+	Issuer     string          `json:"iss"`
+	Repository string          `json:"repository"`
+	Audience   json.RawMessage `json:"aud"`
+	Expiry     int64           `json:"exp"`
+	NotBefore  int64           `json:"nbf"`
+}
+
+// VerifyRepoToken verifies the signature and claims of an ID token and returns the
+// repository, as owner/repo, of the workflow it was issued to.
+func (v *Verifier) VerifyRepoToken(ctx context.Context, token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("%w: token must have three parts", ErrInvalidToken)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return "", fmt.Errorf("%w: failed to decode header: %w", ErrInvalidToken, err)
+	}
+
+	if header.Alg != "RS256" {
+		return "", fmt.Errorf("%w: unsupported signing algorithm %q", ErrInvalidToken, header.Alg)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to decode signature: %w", ErrInvalidToken, err)
+	}
+
+	key, err := v.key(ctx, header.Kid)
+	if err != nil {
+		return "", err
+	}
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig); err != nil {
+		return "", fmt.Errorf("%w: signature verification failed", ErrInvalidToken)
+	}
+
+	var c claims
+	if err := decodeSegment(parts[1], &c); err != nil {
+		return "", fmt.Errorf("%w: failed to decode claims: %w", ErrInvalidToken, err)
+	}
+
+	if err := v.checkClaims(&c); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	return c.Repository, nil
+}
+
+// checkClaims checks the issuer, audience, lifetime and repository claims of a token.
+func (v *Verifier) checkClaims(c *claims) error {
+	if strings.TrimSuffix(c.Issuer, "/") != v.cfg.Issuer {
+		return fmt.Errorf("unexpected issuer %q", c.Issuer)
+	}
+
+	if !hasAudience(c.Audience, v.cfg.Audience) {
+		return fmt.Errorf("token is not issued for audience %q", v.cfg.Audience)
+	}
+
+	now := v.now()
+
+	if c.Expiry == 0 || now.After(time.Unix(c.Expiry, 0).Add(leeway)) {
+		return errors.New("token has expired")
+	}
+
+	if c.NotBefore != 0 && now.Before(time.Unix(c.NotBefore, 0).Add(-leeway)) {
+		return errors.New("token is not valid yet")
+	}
+
+	owner, _, ok := strings.Cut(c.Repository, "/")
+	if !ok || owner == "" {
+		return fmt.Errorf("invalid repository claim %q", c.Repository)
+	}
+
+	if !slices.ContainsFunc(v.cfg.Owners, func(o string) bool { return strings.EqualFold(o, owner) }) {
+		return fmt.Errorf("repository owner %q is not allowed", owner)
+	}
+
+	return nil
+}
+
+// hasAudience reports whether the aud claim, a string or an array of strings, contains want.
+func hasAudience(aud json.RawMessage, want string) bool {
+	var single string
+	if err := json.Unmarshal(aud, &single); err == nil {
+		return single == want
+	}
+
+	var list []string
+	if err := json.Unmarshal(aud, &list); err == nil {
+		return slices.Contains(list, want)
+	}
+
+	return false
+}
+
+// decodeSegment decodes a base64url-encoded JSON token segment into v.
+func decodeSegment(seg string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+
+	return json.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// key returns the signing key with the given ID. Keys are fetched from the issuer when
+// none are cached, when the cache is older than keysTTL, or when the ID is unknown and
+// the keys were not fetched in the last refreshInterval, to pick up rotated keys. Tokens
+// signed with a cached key are verified while a fetch is in progress.
+func (v *Verifier) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	key, ok, stale := v.cachedKey(kid)
+	if stale {
+		v.fetchMu.Lock()
+		defer v.fetchMu.Unlock()
+
+		// Another caller may have fetched the keys while this one waited.
+		key, ok, stale = v.cachedKey(kid)
+	}
+
+	if stale {
+		keys, err := v.fetchKeys(ctx)
+
+		v.mu.Lock()
+
+		switch {
+		case err == nil:
+			v.keys = keys
+			v.fetched = v.now()
+			key, ok = keys[kid]
+		case !ok:
+			v.mu.Unlock()
+			return nil, err
+		default:
+			// Keep using a cached key while the issuer is unreachable.
+			v.fetched = v.now()
+		}
+
+		v.mu.Unlock()
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown signing key %q", ErrInvalidToken, kid)
+	}
+
+	return key, nil
+}
+
+// cachedKey returns the cached signing key with the given ID, whether it is cached, and
+// whether the keys must be fetched again.
+func (v *Verifier) cachedKey(kid string) (key *rsa.PublicKey, ok, stale bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	now := v.now()
+	key, ok = v.keys[kid]
+
+	stale = v.keys == nil || now.Sub(v.fetched) > keysTTL
+	if !stale && !ok && now.Sub(v.fetched) > refreshInterval {
+		stale = true
+	}
+
+	return key, ok, stale
+}
+
+// fetchKeys fetches the RSA signing keys listed in the JWKS document referenced by the
+// issuer's OpenID configuration. Malformed keys are skipped, so that one bad entry does
+// not prevent verifying tokens signed with the others.
+func (v *Verifier) fetchKeys(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	var discovery struct {
+		JWKSURI string `json:"jwks_uri"`
+	}
+
+	if err := v.getJSON(ctx, v.cfg.Issuer+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, fmt.Errorf("failed to fetch OpenID configuration: %w", err)
+	}
+
+	if discovery.JWKSURI == "" {
+		return nil, errors.New("OpenID configuration has no jwks_uri")
+	}
+
+	var jwks struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+
+	if err := v.getJSON(ctx, discovery.JWKSURI, &jwks); err != nil {
+		return nil, fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(jwks.Keys))
+
+	for _, k := range jwks.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+
+		key, err := parseRSAKey(k.N, k.E)
+		if err != nil {
+			slog.WarnContext(ctx, "skipping invalid OIDC signing key", "kid", k.Kid, "error", err)
+			continue
+		}
+
+		keys[k.Kid] = key
+	}
+
+	return keys, nil
+}
+
+// parseRSAKey builds an RSA public key from the base64url-encoded modulus and exponent
+// of a JWK.
+func parseRSAKey(modulus, exponent string) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(modulus)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode modulus: %w", err)
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(exponent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode exponent: %w", err)
+	}
+
+	exp := new(big.Int).SetBytes(e)
+	if !exp.IsInt64() || exp.Int64() < 2 || exp.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid exponent")
+	}
+
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}, nil
+}
+
+// getJSON fetches url and decodes the JSON response body into dst.
+func (v *Verifier) getJSON(ctx context.Context, url string, dst any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, url)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(dst); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,294 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAudience = "https://docs.example.com"
+
+var testOwners = []string{"owner"}
+
+// testIssuer is an OpenID issuer serving a single valid RSA signing key, next to a
+// malformed one.
+type testIssuer struct {
+	server  *httptest.Server
+	key     *rsa.PrivateKey
+	hold    atomic.Pointer[chan struct{}]
+	fetches atomic.Int32
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	iss := &testIssuer{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"jwks_uri": iss.server.URL + "/jwks"})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, _ *http.Request) {
+		iss.fetches.Add(1)
+
+		// Hold the response until the test releases it.
+		if hold := iss.hold.Load(); hold != nil {
+			<-*hold
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{
+			{"kty": "RSA", "kid": "broken", "n": "!", "e": "AQAB"},
+			{
+				"kty": "RSA",
+				"kid": "key-1",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			},
+		}})
+	})
+
+	iss.server = httptest.NewServer(mux)
+	t.Cleanup(iss.server.Close)
+
+	return iss
+}
+
+// sign returns an RS256 token with the given key ID and claims.
+func (iss *testIssuer) sign(t *testing.T, kid string, claims map[string]any) string {
+	t.Helper()
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "kid": kid, "typ": "JWT"})
+	require.NoError(t, err)
+
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	sig, err := rsa.SignPKCS1v15(rand.Reader, iss.key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+// claims returns valid claims for owner/repo, with the given overrides applied.
+func (iss *testIssuer) claims(overrides map[string]any) map[string]any {
+	c := map[string]any{
+		"iss":              iss.server.URL,
+		"aud":              testAudience,
+		"exp":              time.Now().Add(5 * time.Minute).Unix(),
+		"nbf":              time.Now().Add(-time.Minute).Unix(),
+		"repository":       "owner/repo",
+		"repository_owner": "owner",
+	}
+
+	for k, v := range overrides {
+		c[k] = v
+	}
+
+	return c
+}
+
+func TestConfig_Enabled(t *testing.T) {
+	assert.False(t, Config{}.Enabled())
+	assert.False(t, Config{Owners: []string{"owner"}}.Enabled())
+	assert.True(t, Config{Audience: testAudience}.Enabled())
+}
+
+func TestConfig_Validate(t *testing.T) {
+	require.NoError(t, Config{}.Validate())
+	require.NoError(t, Config{Audience: testAudience, Owners: testOwners}.Validate())
+	assert.ErrorContains(t, Config{Audience: testAudience}.Validate(), "owners is required")
+	assert.ErrorContains(t, Config{Audience: testAudience, Owners: []string{""}}.Validate(), "owners is required")
+}
+
+func TestNew_DefaultIssuer(t *testing.T) {
+	assert.Equal(t, DefaultIssuer, New(Config{Audience: testAudience}).cfg.Issuer)
+	assert.Equal(t, "https://issuer.example.com", New(Config{Issuer: "https://issuer.example.com/"}).cfg.Issuer)
+}
+
+func TestVerifier_VerifyRepoToken(t *testing.T) {
+	iss := newTestIssuer(t)
+
+	tests := []struct {
+		overrides map[string]any
+		name      string
+		kid       string
+		wantErr   string
+		owners    []string
+	}{
+		{name: "valid", kid: "key-1", owners: testOwners},
+		{name: "no owners", kid: "key-1", wantErr: `repository owner "owner" is not allowed`},
+		{name: "audience list", kid: "key-1", owners: testOwners, overrides: map[string]any{"aud": []string{"other", testAudience}}},
+		{name: "allowed owner", kid: "key-1", owners: []string{"other", "OWNER"}},
+		{name: "owner not allowed", kid: "key-1", owners: []string{"other"}, wantErr: `repository owner "owner" is not allowed`},
+		{name: "wrong audience", kid: "key-1", owners: testOwners, overrides: map[string]any{"aud": "https://other.example.com"}, wantErr: "not issued for audience"},
+		{name: "wrong issuer", kid: "key-1", owners: testOwners, overrides: map[string]any{"iss": "https://evil.example.com"}, wantErr: "unexpected issuer"},
+		{name: "expired", kid: "key-1", owners: testOwners, overrides: map[string]any{"exp": time.Now().Add(-time.Hour).Unix()}, wantErr: "token has expired"},
+		{name: "not yet valid", kid: "key-1", owners: testOwners, overrides: map[string]any{"nbf": time.Now().Add(time.Hour).Unix()}, wantErr: "not valid yet"},
+		{name: "no repository", kid: "key-1", owners: testOwners, overrides: map[string]any{"repository": ""}, wantErr: "invalid repository claim"},
+		{name: "unknown key", kid: "key-2", owners: testOwners, wantErr: `unknown signing key "key-2"`},
+		{name: "malformed key", kid: "broken", owners: testOwners, wantErr: `unknown signing key "broken"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := New(Config{Issuer: iss.server.URL, Audience: testAudience, Owners: tt.owners})
+
+			repo, err := v.VerifyRepoToken(t.Context(), iss.sign(t, tt.kid, iss.claims(tt.overrides)))
+			if tt.wantErr != "" {
+				assert.ErrorIs(t, err, ErrInvalidToken)
+				assert.ErrorContains(t, err, tt.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "owner/repo", repo)
+		})
+	}
+}
+
+func TestVerifier_VerifyRepoToken_Malformed(t *testing.T) {
+	iss := newTestIssuer(t)
+	v := New(Config{Issuer: iss.server.URL, Audience: testAudience, Owners: testOwners})
+
+	token := iss.sign(t, "key-1", iss.claims(nil))
+	parts := strings.Split(token, ".")
+
+	forged, err := json.Marshal(iss.claims(map[string]any{"repository": "victim/repo"}))
+	require.NoError(t, err)
+
+	none, err := json.Marshal(map[string]string{"alg": "none", "kid": "key-1"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr string
+	}{
+		{name: "two parts", token: parts[0] + "." + parts[1], wantErr: "three parts"},
+		{name: "bad header", token: "!." + parts[1] + "." + parts[2], wantErr: "failed to decode header"},
+		{name: "alg none", token: base64.RawURLEncoding.EncodeToString(none) + "." + parts[1] + ".", wantErr: "unsupported signing algorithm"},
+		{name: "tampered claims", token: parts[0] + "." + base64.RawURLEncoding.EncodeToString(forged) + "." + parts[2], wantErr: "signature verification failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.VerifyRepoToken(t.Context(), tt.token)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestVerifier_KeyCache(t *testing.T) {
+	iss := newTestIssuer(t)
+	v := New(Config{Issuer: iss.server.URL, Audience: testAudience, Owners: testOwners})
+
+	now := time.Now()
+	v.now = func() time.Time { return now }
+
+	token := iss.sign(t, "key-1", iss.claims(nil))
+
+	for range 3 {
+		_, err := v.VerifyRepoToken(t.Context(), token)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, int32(1), iss.fetches.Load(), "keys are cached")
+
+	// Unknown key IDs trigger a refresh at most once per refreshInterval.
+	unknown := iss.sign(t, "key-2", iss.claims(nil))
+
+	now = now.Add(2 * refreshInterval)
+
+	_, err := v.VerifyRepoToken(t.Context(), unknown)
+	require.Error(t, err)
+
+	_, err = v.VerifyRepoToken(t.Context(), unknown)
+	require.Error(t, err)
+
+	assert.Equal(t, int32(2), iss.fetches.Load())
+
+	now = now.Add(keysTTL + time.Second)
+	token = iss.sign(t, "key-1", iss.claims(map[string]any{"exp": now.Add(time.Minute).Unix()}))
+
+	_, err = v.VerifyRepoToken(t.Context(), token)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), iss.fetches.Load(), "expired keys are fetched again")
+}
+
+func TestVerifier_CachedKeyDuringFetch(t *testing.T) {
+	iss := newTestIssuer(t)
+	v := New(Config{Issuer: iss.server.URL, Audience: testAudience, Owners: testOwners})
+
+	token := iss.sign(t, "key-1", iss.claims(nil))
+
+	_, err := v.VerifyRepoToken(t.Context(), token)
+	require.NoError(t, err)
+
+	now := time.Now().Add(2 * refreshInterval)
+	v.now = func() time.Time { return now }
+
+	hold := make(chan struct{})
+	iss.hold.Store(&hold)
+
+	unknown := iss.sign(t, "key-2", iss.claims(nil))
+	done := make(chan error, 1)
+
+	go func() {
+		_, err := v.VerifyRepoToken(t.Context(), unknown)
+		done <- err
+	}()
+
+	require.Eventually(t, func() bool { return iss.fetches.Load() == 2 }, time.Second, time.Millisecond)
+
+	// A token signed with a cached key is verified while keys are being fetched.
+	_, err = v.VerifyRepoToken(t.Context(), token)
+	require.NoError(t, err)
+
+	close(hold)
+	assert.ErrorContains(t, <-done, `unknown signing key "key-2"`)
+}
+
+func TestVerifier_IssuerUnavailable(t *testing.T) {
+	iss := newTestIssuer(t)
+	v := New(Config{Issuer: iss.server.URL, Audience: testAudience, Owners: testOwners})
+
+	token := iss.sign(t, "key-1", iss.claims(nil))
+
+	_, err := v.VerifyRepoToken(t.Context(), token)
+	require.NoError(t, err)
+
+	iss.server.Close()
+
+	now := time.Now().Add(keysTTL + time.Minute)
+	v.now = func() time.Time { return now }
+
+	// The cached key is used while the issuer cannot be reached.
+	token = iss.sign(t, "key-1", iss.claims(map[string]any{"exp": now.Add(time.Minute).Unix()}))
+
+	_, err = v.VerifyRepoToken(t.Context(), token)
+	require.NoError(t, err)
+
+	_, err = New(Config{Issuer: iss.server.URL, Audience: testAudience, Owners: testOwners}).VerifyRepoToken(t.Context(), token)
+	assert.ErrorContains(t, err, "failed to fetch OpenID configuration")
+}
@@ -0,0 +1,23 @@
+package openapi
+
+import (
+	"testing"
+)
+
+func FuzzProcessor(f *testing.F) {
+	f.Add([]byte(minimalSpecYAML))
+	f.Add([]byte(`{"openapi":"3.0.3","info":{"title":"T","version":"1"},"paths":{"/a/{id}":{"parameters":[{"$ref":"#/components/parameters/id"}]}}}`))
+	f.Add([]byte(`{"openapi":"3.1.0","info":{"title":"T","version":"1"},"paths":{"/a":{"get":{"operationId":"a","tags":["x"]}}}}`))
+	f.Add([]byte("openapi: 3.0.0\npaths:\n  /x:\n    get: null\n"))
+	f.Add([]byte("swagger: \"2.0\""))
+
+	p := New()
+
+	f.Fuzz(func(_ *testing.T, src []byte) {
+		_, _, _ = p.RenderHTML(src)
+		p.ExtractTitle(src)
+		p.ExtractSummary(src)
+		p.ToPlainText(src)
+		p.ExtractHeadings(src)
+	})
+}
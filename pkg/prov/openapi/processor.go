@@ -13,6 +13,7 @@ import (
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/ksysoev/omnidex/pkg/prov/internal/mdwrite"
 )
 
 // methodOperation pairs an HTTP method name (lowercase) with its operation.
@@ -32,6 +33,18 @@ func New() *Processor {
 	return &Processor{}
 }
 
+// ContentTypeInfo describes OpenAPI specs: YAML or JSON files with an "openapi" or, for
+// Swagger 2.0, a "swagger" top-level key.
+func (p *Processor) ContentTypeInfo() core.ContentTypeInfo {
+	return core.ContentTypeInfo{
+		Name:        core.ContentTypeOpenAPI,
+		DisplayName: "OpenAPI",
+		Icon:        "api",
+		Extensions:  []string{".yaml", ".yml", ".json"},
+		Markers:     []string{"openapi", "swagger"},
+	}
+}
+
 // RenderHTML returns the raw OpenAPI spec as HTML-safe content for Scalar API Reference rendering.
 // The view layer is responsible for embedding this into a Scalar API Reference container.
 // Headings are not extracted here because Scalar API Reference manages its own
@@ -66,11 +79,27 @@ func (p *Processor) ExtractTitle(src []byte) string {
 	return ""
 }
 
+// ExtractSummary returns the first paragraph of the API description from the OpenAPI info
+// section. It returns an empty string if the spec cannot be parsed or has no description.
+func (p *Processor) ExtractSummary(src []byte) string {
+	spec, err := parseSpec(src)
+	if err != nil || spec.Info == nil {
+		return ""
+	}
+
+	para, _, _ := strings.Cut(strings.TrimSpace(spec.Info.Description), "\n\n")
+
+	return strings.TrimSpace(para)
+}
+
 // ToPlainText extracts searchable plain text from an OpenAPI spec.
 // It collects the API title, description, tag names and descriptions, then
 // for each path (sorted alphabetically) and each HTTP method (in a fixed
 // canonical order), emits "{METHOD} {path}" followed by the operation summary
-// and description. This deterministic ordering ensures that ExtractHeadings and
+// and description, its parameters, and the properties of its request and
+// response schemas, so that searching for a field name finds the operations
+// using it. The component schemas follow, each as a line with its name and then
+// its properties. This deterministic ordering ensures that ExtractHeadings and
 // ToPlainText iterate the spec in the same sequence, enabling accurate
 // fragment-to-anchor mapping during search result deep-linking.
 func (p *Processor) ToPlainText(src []byte) string {
@@ -142,7 +171,23 @@ func (p *Processor) ToPlainText(src []byte) string {
 					buf.WriteString(mop.op.Description)
 					buf.WriteByte('\n')
 				}
+
+				writeOperationSchemas(&buf, pathItem, mop.op)
+			}
+		}
+	}
+
+	// Component schemas (sorted by name), matching the model headings.
+	if spec.Components != nil {
+		for _, name := range mdwrite.SortedKeys(spec.Components.Schemas) {
+			buf.WriteString(name)
+			buf.WriteByte('\n')
+
+			if ref := spec.Components.Schemas[name]; ref != nil && ref.Value != nil {
+				mdwrite.WriteLine(&buf, ref.Value.Description)
 			}
+
+			writeSchema(&buf, spec.Components.Schemas[name], make(map[*openapi3.Schema]bool), 0)
 		}
 	}
 
@@ -155,9 +200,10 @@ func (p *Processor) ToPlainText(src []byte) string {
 // operation or tag section in the rendered Scalar UI.
 //
 // The anchor format mirrors Scalar's default generateId logic:
-//   - Tags:       "tag/{github-slug(tagName)}"
+//   - Tags:       "tag/{github-mdwrite.Slug(tagName)}"
 //   - Operations: "tag/{tagSlug}/{METHOD}{path}"  (when the op has at least one tag)
 //   - Untagged:   "{METHOD}{path}"
+//   - Schemas:    "model/{github-mdwrite.Slug(schemaName)}"
 //
 // Headings are returned in the same order that ToPlainText emits their
 // corresponding text, so that byte offsets from fragmentMatchIndex map
@@ -182,36 +228,149 @@ func (p *Processor) ExtractHeadings(src []byte) []core.Heading {
 		})
 	}
 
-	if spec.Paths == nil {
-		return headings
+	// 2. Operation headings (paths sorted alphabetically, methods in canonical order).
+	if spec.Paths != nil {
+		pathsMap := spec.Paths.Map()
+
+		for _, path := range mdwrite.SortedKeys(pathsMap) {
+			pathItem := pathsMap[path]
+			if pathItem == nil {
+				continue
+			}
+
+			for _, mop := range collectMethodOperations(pathItem) {
+				method := strings.ToUpper(mop.method)
+				// Heading text must match the line emitted by ToPlainText.
+				text := method + " " + path
+				id := operationAnchorID(mop.op, method, path)
+				headings = append(headings, core.Heading{Text: text, ID: id})
+			}
+		}
 	}
 
-	// 2. Operation headings (paths sorted alphabetically, methods in canonical order).
-	pathsMap := spec.Paths.Map()
-	paths := make([]string, 0, len(pathsMap))
+	// 3. Component schema headings (sorted by name).
+	if spec.Components != nil {
+		for _, name := range mdwrite.SortedKeys(spec.Components.Schemas) {
+			headings = append(headings, core.Heading{Text: name, ID: "model/" + githubSlug(name)})
+		}
+	}
+
+	return headings
+}
+
+// maxSchemaDepth bounds how deeply nested schemas are indexed.
+const maxSchemaDepth = 16
+
+// writeOperationSchemas writes the searchable text of an operation's parameters, those
+// of its path included, and of the schemas of its request body and responses. A schema
+// referenced several times is written once per operation.
+func writeOperationSchemas(buf *bytes.Buffer, item *openapi3.PathItem, op *openapi3.Operation) {
+	seen := make(map[*openapi3.Schema]bool)
+
+	for _, params := range []openapi3.Parameters{item.Parameters, op.Parameters} {
+		for _, ref := range params {
+			if ref == nil || ref.Value == nil {
+				continue
+			}
+
+			param := ref.Value
+
+			mdwrite.WriteLine(buf, strings.TrimSpace(param.Name+" ("+param.In+")"))
+			mdwrite.WriteLine(buf, param.Description)
+			writeSchema(buf, param.Schema, seen, 0)
+		}
+	}
 
-	for path := range pathsMap {
-		paths = append(paths, path)
+	if op.RequestBody != nil && op.RequestBody.Value != nil {
+		mdwrite.WriteLine(buf, op.RequestBody.Value.Description)
+		writeContent(buf, op.RequestBody.Value.Content, seen)
 	}
 
-	sort.Strings(paths)
+	if op.Responses == nil {
+		return
+	}
 
-	for _, path := range paths {
-		pathItem := pathsMap[path]
-		if pathItem == nil {
-			continue
+	responses := op.Responses.Map()
+
+	for _, code := range mdwrite.SortedKeys(responses) {
+		if ref := responses[code]; ref != nil && ref.Value != nil {
+			writeContent(buf, ref.Value.Content, seen)
 		}
+	}
+}
 
-		for _, mop := range collectMethodOperations(pathItem) {
-			method := strings.ToUpper(mop.method)
-			// Heading text must match the line emitted by ToPlainText.
-			text := method + " " + path
-			id := operationAnchorID(mop.op, method, path)
-			headings = append(headings, core.Heading{Text: text, ID: id})
+// writeContent writes the searchable text of the schemas of each media type, sorted by
+// media type.
+func writeContent(buf *bytes.Buffer, content openapi3.Content, seen map[*openapi3.Schema]bool) {
+	for _, mediaType := range mdwrite.SortedKeys(content) {
+		if mt := content[mediaType]; mt != nil {
+			writeSchema(buf, mt.Schema, seen, 0)
 		}
 	}
+}
 
-	return headings
+// writeSchema writes the searchable text of a schema: its allowed values, then a line
+// per property (sorted by name) with its type, followed by its title, description and
+// nested schema, and the schemas of its items, additional properties and variants.
+// Schemas in seen are skipped, which also stops at recursive references.
+func writeSchema(buf *bytes.Buffer, ref *openapi3.SchemaRef, seen map[*openapi3.Schema]bool, depth int) {
+	if ref == nil || ref.Value == nil || seen[ref.Value] || depth > maxSchemaDepth {
+		return
+	}
+
+	s := ref.Value
+	seen[s] = true
+
+	writeEnum(buf, s.Enum)
+
+	for _, name := range mdwrite.SortedKeys(s.Properties) {
+		prop := s.Properties[name]
+
+		// The type keeps property lines from matching heading lines of the same text.
+		line := name
+		if prop != nil && prop.Value != nil {
+			if types := prop.Value.Type.Slice(); len(types) > 0 {
+				line += " (" + strings.Join(types, ", ") + ")"
+			}
+		}
+
+		mdwrite.WriteLine(buf, line)
+
+		if prop != nil && prop.Value != nil {
+			mdwrite.WriteLine(buf, prop.Value.Title)
+			mdwrite.WriteLine(buf, prop.Value.Description)
+		}
+
+		writeSchema(buf, prop, seen, depth+1)
+	}
+
+	writeSchema(buf, s.Items, seen, depth+1)
+	writeSchema(buf, s.AdditionalProperties.Schema, seen, depth+1)
+
+	for _, variants := range []openapi3.SchemaRefs{s.AllOf, s.AnyOf, s.OneOf} {
+		for _, v := range variants {
+			if v != nil && v.Value != nil && !seen[v.Value] {
+				mdwrite.WriteLine(buf, v.Value.Title)
+				mdwrite.WriteLine(buf, v.Value.Description)
+			}
+
+			writeSchema(buf, v, seen, depth+1)
+		}
+	}
+}
+
+// writeEnum writes the allowed values of a schema on one line.
+func writeEnum(buf *bytes.Buffer, values []any) {
+	if len(values) == 0 {
+		return
+	}
+
+	formatted := make([]string, 0, len(values))
+	for _, v := range values {
+		formatted = append(formatted, fmt.Sprint(v))
+	}
+
+	mdwrite.WriteLine(buf, strings.Join(formatted, " "))
 }
 
 // operationAnchorID builds the Scalar-compatible anchor ID for an operation.
@@ -304,3 +463,9 @@ func collectMethodOperations(item *openapi3.PathItem) []methodOperation {
 
 	return result
 }
+
+// ExtractCodeBlocks returns nil: OpenAPI specs have no fenced code blocks to index
+// for code search.
+func (p *Processor) ExtractCodeBlocks(_ []byte) []core.CodeBlock {
+	return nil
+}
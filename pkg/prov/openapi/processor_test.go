@@ -2,8 +2,10 @@ package openapi
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
+	"github.com/ksysoev/omnidex/pkg/core"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -133,6 +135,22 @@ paths:
 	})
 }
 
+func TestProcessor_ExtractSummary(t *testing.T) {
+	p := New()
+
+	assert.Equal(t, "A sample API for pets", p.ExtractSummary([]byte(minimalSpecYAML)))
+	assert.Equal(t, "Manages orders.", p.ExtractSummary([]byte(`openapi: "3.0.3"
+info:
+  title: Orders
+  description: |
+    Manages orders.
+
+    ## Authentication
+  version: "1.0.0"
+paths: {}`)))
+	assert.Empty(t, p.ExtractSummary([]byte("not a spec")))
+}
+
 func TestProcessor_ExtractTitle(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -447,3 +465,105 @@ paths: {}`))
 		assert.Contains(t, text, "Empty API")
 	})
 }
+
+const schemaSpecYAML = `openapi: "3.0.3"
+info:
+  title: Payments API
+  version: "1.0.0"
+paths:
+  /payments:
+    post:
+      summary: Create a payment
+      parameters:
+        - name: Idempotency-Key
+          in: header
+          description: Deduplicates retried requests
+          schema:
+            type: string
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: "#/components/schemas/Payment"
+      responses:
+        "201":
+          description: Payment created
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  payment_id:
+                    type: string
+                    description: Identifier of the created payment
+components:
+  schemas:
+    Payment:
+      type: object
+      description: A payment request
+      properties:
+        idempotency_key:
+          type: string
+          description: Key used to retry the payment safely
+        status:
+          type: string
+          enum: [pending, settled]
+        parent:
+          $ref: "#/components/schemas/Payment"
+        items:
+          type: array
+          items:
+            $ref: "#/components/schemas/LineItem"
+    LineItem:
+      type: object
+      properties:
+        sku:
+          type: string
+`
+
+func TestProcessor_ToPlainText_Schemas(t *testing.T) {
+	p := New()
+	text := p.ToPlainText([]byte(schemaSpecYAML))
+
+	// Parameters of the operation.
+	assert.Contains(t, text, "Idempotency-Key (header)")
+	assert.Contains(t, text, "Deduplicates retried requests")
+
+	// Request body properties, nested items and enums.
+	assert.Contains(t, text, "idempotency_key (string)")
+	assert.Contains(t, text, "Key used to retry the payment safely")
+	assert.Contains(t, text, "pending settled")
+	assert.Contains(t, text, "sku (string)")
+
+	// Response properties.
+	assert.Contains(t, text, "payment_id (string)")
+	assert.Contains(t, text, "Identifier of the created payment")
+
+	// Schema text follows the operation that uses it.
+	assert.Less(t, strings.Index(text, "POST /payments"), strings.Index(text, "idempotency_key (string)"))
+
+	// Component schemas follow the operations as heading lines.
+	assert.Contains(t, text, "\nPayment\nA payment request\n")
+	assert.Contains(t, text, "\nLineItem\n")
+}
+
+func TestProcessor_ExtractHeadings_Schemas(t *testing.T) {
+	p := New()
+	headings := p.ExtractHeadings([]byte(schemaSpecYAML))
+
+	require.Len(t, headings, 3)
+	assert.Equal(t, "POST /payments", headings[0].Text)
+	assert.Equal(t, core.Heading{Text: "LineItem", ID: "model/lineitem"}, headings[1])
+	assert.Equal(t, core.Heading{Text: "Payment", ID: "model/payment"}, headings[2])
+
+	// Every heading appears as a line of the plain text, in order.
+	text := p.ToPlainText([]byte(schemaSpecYAML))
+	offset := 0
+
+	for _, h := range headings {
+		idx := strings.Index(text[offset:], h.Text+"\n")
+		require.GreaterOrEqual(t, idx, 0, "heading %q not found in order", h.Text)
+
+		offset += idx + len(h.Text)
+	}
+}
@@ -0,0 +1,22 @@
+package pdf
+
+import (
+	"testing"
+)
+
+func FuzzProcessor(f *testing.F) {
+	f.Add(manualPDF("Manual"))
+	f.Add([]byte("%PDF-1.4\n1 0 obj << /Type /Page /Contents 2 0 R >> endobj\n2 0 obj << /Length 99 >> stream\nBT (a) Tj\nendstream endobj"))
+	f.Add([]byte("%PDF-1.5\n1 0 obj << /Type /ObjStm /N 2 /First 8 /Length 20 >> stream\n2 0 3 4 << /A [[[ >>\nendstream endobj"))
+	f.Add([]byte("%PDF-1.7\n1 0 obj << /Kids [1 0 R] /Type /Pages >> endobj trailer << /Root 1 0 R /Info 1 0 R >>"))
+
+	p := New()
+
+	f.Fuzz(func(_ *testing.T, src []byte) {
+		_, _, _ = p.RenderHTML(src)
+		p.ExtractTitle(src)
+		p.ToPlainText(src)
+		p.ExtractHeadings(src)
+		p.MapAnchors(src)
+	})
+}
@@ -0,0 +1,783 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+)
+
+const (
+	// maxNesting bounds how deeply arrays and dictionaries nest, so that crafted files
+	// cannot exhaust the stack.
+	maxNesting = 64
+	// maxObjects bounds the number of objects read from a file.
+	maxObjects = 200_000
+	// maxDecoded bounds the total size of the streams decompressed from a file, so that a
+	// small file cannot expand into gigabytes of content.
+	maxDecoded = 64 << 20
+	// maxPages bounds the number of pages whose text is extracted.
+	maxPages = 10_000
+	// maxRefHops bounds the chain of references followed to resolve an object.
+	maxRefHops = 32
+)
+
+// objectHeaderRE matches the "12 0 obj" header of an indirect object.
+var objectHeaderRE = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+
+// errUnsupportedFilter is returned for streams compressed with a filter other than
+// FlateDecode, which hold images rather than text.
+var errUnsupportedFilter = errors.New("unsupported stream filter")
+
+// The types of the PDF objects. Strings decode to Go strings holding their bytes,
+// numbers to float64, booleans to bool and null to nil.
+type (
+	name    string
+	keyword string
+	array   []any
+	dict    map[name]any
+	ref     struct{ num, gen int }
+	stream  struct {
+		hdr  dict
+		data []byte // encoded content
+	}
+)
+
+// lexer reads the tokens and objects of PDF syntax from src.
+type lexer struct {
+	src []byte
+	pos int
+}
+
+// isSpace reports whether c is PDF white space.
+func isSpace(c byte) bool {
+	switch c {
+	case 0, '\t', '\n', '\f', '\r', ' ':
+		return true
+	}
+
+	return false
+}
+
+// isDelim reports whether c is a PDF delimiter.
+func isDelim(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+
+	return false
+}
+
+// skipSpace skips white space and comments.
+func (l *lexer) skipSpace() {
+	for l.pos < len(l.src) {
+		switch c := l.src[l.pos]; {
+		case isSpace(c):
+			l.pos++
+		case c == '%':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' && l.src[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// token returns the next token: a number, string, name, or keyword, which includes the
+// array and dictionary brackets. It returns false at the end of the input.
+func (l *lexer) token() (any, bool) {
+	l.skipSpace()
+
+	if l.pos >= len(l.src) {
+		return nil, false
+	}
+
+	switch c := l.src[l.pos]; c {
+	case '(':
+		l.pos++
+		return l.literalString(), true
+	case '<':
+		if l.pos+1 < len(l.src) && l.src[l.pos+1] == '<' {
+			l.pos += 2
+			return keyword("<<"), true
+		}
+
+		l.pos++
+
+		return l.hexString(), true
+	case '>':
+		if l.pos+1 < len(l.src) && l.src[l.pos+1] == '>' {
+			l.pos += 2
+			return keyword(">>"), true
+		}
+
+		l.pos++
+
+		return keyword(">"), true
+	case '[', ']', '{', '}', ')':
+		l.pos++
+		return keyword(l.src[l.pos-1 : l.pos]), true
+	case '/':
+		l.pos++
+		return l.name(), true
+	}
+
+	start := l.pos
+	for l.pos < len(l.src) && !isSpace(l.src[l.pos]) && !isDelim(l.src[l.pos]) {
+		l.pos++
+	}
+
+	word := string(l.src[start:l.pos])
+
+	if c := word[0]; c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9') {
+		if n, err := strconv.ParseFloat(word, 64); err == nil {
+			return n, true
+		}
+	}
+
+	return keyword(word), true
+}
+
+// literalString reads a parenthesized string after its opening parenthesis.
+func (l *lexer) literalString() string {
+	var buf []byte
+
+	depth := 1
+
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		l.pos++
+
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return string(buf)
+			}
+		case '\\':
+			if l.pos >= len(l.src) {
+				return string(buf)
+			}
+
+			c = l.src[l.pos]
+			l.pos++
+
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if l.pos < len(l.src) && l.src[l.pos] == '\n' {
+					l.pos++
+				}
+
+				continue
+			case '\n':
+				continue
+			default:
+				if c >= '0' && c <= '7' {
+					v := int(c - '0')
+
+					for i := 0; i < 2 && l.pos < len(l.src) && l.src[l.pos] >= '0' && l.src[l.pos] <= '7'; i++ {
+						v = v*8 + int(l.src[l.pos]-'0')
+						l.pos++
+					}
+
+					c = byte(v)
+				}
+			}
+		}
+
+		buf = append(buf, c)
+	}
+
+	return string(buf)
+}
+
+// hexString reads a hexadecimal string after its opening angle bracket. A missing last
+// digit is taken as zero.
+func (l *lexer) hexString() string {
+	var (
+		buf  []byte
+		hi   byte
+		half bool
+	)
+
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		l.pos++
+
+		if c == '>' {
+			break
+		}
+
+		v, ok := hexValue(c)
+		if !ok {
+			continue
+		}
+
+		if half {
+			buf = append(buf, hi<<4|v)
+		} else {
+			hi = v
+		}
+
+		half = !half
+	}
+
+	if half {
+		buf = append(buf, hi<<4)
+	}
+
+	return string(buf)
+}
+
+// name reads a name after its slash, decoding #xx escapes.
+func (l *lexer) name() name {
+	var buf []byte
+
+	for l.pos < len(l.src) && !isSpace(l.src[l.pos]) && !isDelim(l.src[l.pos]) {
+		c := l.src[l.pos]
+		l.pos++
+
+		if c == '#' && l.pos+1 < len(l.src) {
+			hi, ok1 := hexValue(l.src[l.pos])
+			lo, ok2 := hexValue(l.src[l.pos+1])
+
+			if ok1 && ok2 {
+				c = hi<<4 | lo
+				l.pos += 2
+			}
+		}
+
+		buf = append(buf, c)
+	}
+
+	return name(buf)
+}
+
+// hexValue returns the value of a hexadecimal digit.
+func hexValue(c byte) (byte, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	}
+
+	return 0, false
+}
+
+// object reads the next object, combining "12 0 R" into a reference. Keywords other than
+// true, false and null are returned as such, so that content streams can be read with
+// their operators. It returns false at the end of the input.
+func (l *lexer) object(depth int) (any, bool) {
+	tok, ok := l.token()
+	if !ok {
+		return nil, false
+	}
+
+	switch t := tok.(type) {
+	case keyword:
+		switch t {
+		case "[":
+			return l.array(depth + 1)
+		case "<<":
+			return l.dict(depth + 1)
+		case "true":
+			return true, true
+		case "false":
+			return false, true
+		case "null":
+			return nil, true
+		}
+	case float64:
+		if r, ok := l.reference(t); ok {
+			return r, true
+		}
+	}
+
+	return tok, true
+}
+
+// reference reads the rest of a "12 0 R" reference starting with num, leaving the lexer
+// unchanged when the following tokens do not make one.
+func (l *lexer) reference(num float64) (ref, bool) {
+	start := l.pos
+
+	gen, ok := l.token()
+	if g, isNum := gen.(float64); ok && isNum && isIndex(num) && isIndex(g) {
+		if kw, ok := l.token(); ok && kw == keyword("R") {
+			return ref{num: int(num), gen: int(g)}, true
+		}
+	}
+
+	l.pos = start
+
+	return ref{}, false
+}
+
+// isIndex reports whether n is a valid object or generation number.
+func isIndex(n float64) bool {
+	return n >= 0 && n <= 1<<31 && n == float64(int(n))
+}
+
+// array reads the elements of an array after its opening bracket.
+func (l *lexer) array(depth int) (any, bool) {
+	if depth > maxNesting {
+		return nil, false
+	}
+
+	var arr array
+
+	for {
+		obj, ok := l.object(depth)
+		if !ok {
+			return arr, true
+		}
+
+		if obj == keyword("]") {
+			return arr, true
+		}
+
+		arr = append(arr, obj)
+	}
+}
+
+// dict reads the entries of a dictionary after its opening brackets. Entries whose key
+// is not a name are dropped.
+func (l *lexer) dict(depth int) (any, bool) {
+	if depth > maxNesting {
+		return nil, false
+	}
+
+	d := dict{}
+
+	for {
+		key, ok := l.object(depth)
+		if !ok || key == keyword(">>") {
+			return d, true
+		}
+
+		k, isName := key.(name)
+		if !isName {
+			continue
+		}
+
+		val, ok := l.object(depth)
+		if !ok {
+			return d, true
+		}
+
+		if val == keyword(">>") {
+			return d, true
+		}
+
+		d[k] = val
+	}
+}
+
+// document is the object graph of a PDF file, read without its cross-reference table
+// by scanning for object headers, which also recovers files with a damaged table.
+type document struct {
+	objects map[int]any
+	trailer dict
+	fonts   map[ref]*font
+	decoded int // bytes decompressed so far
+}
+
+// parseDocument reads the objects of a PDF file, including those packed in object
+// streams. Later definitions of an object replace earlier ones, as with incremental
+// updates.
+func parseDocument(src []byte) *document {
+	d := &document{objects: map[int]any{}, fonts: map[ref]*font{}}
+
+	end := 0
+
+	for _, m := range objectHeaderRE.FindAllSubmatchIndex(src, maxObjects) {
+		// Skip headers matched inside the data of the previous object's stream.
+		if m[0] < end {
+			continue
+		}
+
+		num, err := strconv.Atoi(string(src[m[2]:m[3]]))
+		if err != nil {
+			continue
+		}
+
+		l := &lexer{src: src, pos: m[1]}
+
+		obj, ok := l.object(0)
+		if !ok {
+			break
+		}
+
+		if hdr, isDict := obj.(dict); isDict {
+			if s, ok := l.stream(hdr); ok {
+				obj = s
+			}
+		}
+
+		d.objects[num] = obj
+		end = l.pos
+	}
+
+	d.trailer = d.findTrailer(src)
+	d.unpackObjectStreams()
+
+	return d
+}
+
+// stream reads the data of the stream with the dictionary hdr, when the stream keyword
+// follows. The length is taken from the dictionary when it is direct and ends where the
+// endstream keyword is, otherwise the data extends to the keyword.
+func (l *lexer) stream(hdr dict) (*stream, bool) {
+	l.skipSpace()
+
+	if !bytes.HasPrefix(l.src[l.pos:], []byte("stream")) {
+		return nil, false
+	}
+
+	start := l.pos + len("stream")
+	if bytes.HasPrefix(l.src[start:], []byte("\r\n")) {
+		start += 2
+	} else if start < len(l.src) && (l.src[start] == '\n' || l.src[start] == '\r') {
+		start++
+	}
+
+	if n, ok := hdr["Length"].(float64); ok && n >= 0 && start+int(n) <= len(l.src) {
+		stop := start + int(n)
+		rest := bytes.TrimLeft(l.src[stop:], "\r\n\t ")
+
+		if bytes.HasPrefix(rest, []byte("endstream")) {
+			l.pos = len(l.src) - len(rest) + len("endstream")
+			return &stream{hdr: hdr, data: l.src[start:stop]}, true
+		}
+	}
+
+	i := bytes.Index(l.src[start:], []byte("endstream"))
+	if i < 0 {
+		l.pos = len(l.src)
+		return &stream{hdr: hdr, data: l.src[start:]}, true
+	}
+
+	l.pos = start + i + len("endstream")
+
+	return &stream{hdr: hdr, data: bytes.TrimRight(l.src[start:start+i], "\r\n")}, true
+}
+
+// findTrailer returns the last trailer dictionary of the file, or the dictionary of its
+// cross-reference stream, which replaces the trailer in compressed files.
+func (d *document) findTrailer(src []byte) dict {
+	if i := bytes.LastIndex(src, []byte("trailer")); i >= 0 {
+		l := &lexer{src: src, pos: i + len("trailer")}
+		if t, ok := l.object(0); ok {
+			if td, ok := t.(dict); ok {
+				return td
+			}
+		}
+	}
+
+	var (
+		trailer dict
+		last    = -1
+	)
+
+	for num, obj := range d.objects {
+		if s, ok := obj.(*stream); ok && s.hdr["Type"] == name("XRef") && num > last {
+			trailer, last = s.hdr, num
+		}
+	}
+
+	return trailer
+}
+
+// unpackObjectStreams adds the objects packed in object streams, unless defined directly.
+func (d *document) unpackObjectStreams() {
+	nums := make([]int, 0, len(d.objects))
+
+	for num, obj := range d.objects {
+		if s, ok := obj.(*stream); ok && s.hdr["Type"] == name("ObjStm") {
+			nums = append(nums, num)
+		}
+	}
+
+	slices.Sort(nums)
+
+	for _, num := range nums {
+		s, _ := d.objects[num].(*stream)
+
+		data, err := d.decode(s)
+		if err != nil {
+			continue
+		}
+
+		n, _ := d.resolve(s.hdr["N"]).(float64)
+		first, _ := d.resolve(s.hdr["First"]).(float64)
+		l := &lexer{src: data}
+
+		for i := 0; i < int(n) && len(d.objects) < maxObjects; i++ {
+			objNum, ok1 := l.token()
+			offset, ok2 := l.token()
+
+			on, isNum1 := objNum.(float64)
+			off, isNum2 := offset.(float64)
+
+			if !ok1 || !ok2 || !isNum1 || !isNum2 || !isIndex(on) || !isIndex(off) {
+				break
+			}
+
+			if _, defined := d.objects[int(on)]; defined {
+				continue
+			}
+
+			pos := int(first) + int(off)
+			if first < 0 || pos > len(data) {
+				continue
+			}
+
+			ol := &lexer{src: data, pos: pos}
+			if obj, ok := ol.object(0); ok {
+				d.objects[int(on)] = obj
+			}
+		}
+	}
+}
+
+// resolve follows references until it reaches a direct object. Missing objects resolve
+// to nil.
+func (d *document) resolve(v any) any {
+	for range maxRefHops {
+		r, ok := v.(ref)
+		if !ok {
+			return v
+		}
+
+		v = d.objects[r.num]
+	}
+
+	return nil
+}
+
+// dictOf resolves v to a dictionary, or the dictionary of a stream.
+func (d *document) dictOf(v any) dict {
+	switch t := d.resolve(v).(type) {
+	case dict:
+		return t
+	case *stream:
+		return t.hdr
+	}
+
+	return nil
+}
+
+// decode returns the decompressed data of a stream. Only the FlateDecode filter without
+// a predictor is supported; the data of damaged streams is returned up to the damage.
+func (d *document) decode(s *stream) ([]byte, error) {
+	var filters []any
+
+	switch f := d.resolve(s.hdr["Filter"]).(type) {
+	case nil:
+	case name:
+		filters = []any{f}
+	case array:
+		filters = f
+	default:
+		return nil, errUnsupportedFilter
+	}
+
+	data := s.data
+
+	for i, f := range filters {
+		if f := d.resolve(f); f != name("FlateDecode") && f != name("Fl") {
+			return nil, errUnsupportedFilter
+		}
+
+		if predictor(d.decodeParams(s.hdr, i)) > 1 {
+			return nil, errUnsupportedFilter
+		}
+
+		out, err := d.inflate(data)
+		if err != nil {
+			return nil, err
+		}
+
+		data = out
+	}
+
+	return data, nil
+}
+
+// decodeParams returns the parameters of the i-th filter of a stream.
+func (d *document) decodeParams(hdr dict, i int) dict {
+	switch p := d.resolve(hdr["DecodeParms"]).(type) {
+	case dict:
+		return p
+	case array:
+		if i < len(p) {
+			return d.dictOf(p[i])
+		}
+	}
+
+	return nil
+}
+
+// predictor returns the predictor of filter parameters.
+func predictor(params dict) float64 {
+	p, _ := params["Predictor"].(float64)
+	return p
+}
+
+// inflate decompresses zlib data within what is left of the document's budget.
+func (d *document) inflate(data []byte) ([]byte, error) {
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	defer zr.Close()
+
+	budget := maxDecoded - d.decoded
+	if budget <= 0 {
+		return nil, io.ErrShortBuffer
+	}
+
+	out, err := io.ReadAll(io.LimitReader(zr, int64(budget)))
+	d.decoded += len(out)
+
+	if err != nil && len(out) == 0 {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// page is a page of the document with the resources it inherits.
+type page struct {
+	dict      dict
+	resources dict
+}
+
+// pages returns the pages of the document in order, walking the page tree from the
+// catalog. Files without a readable page tree have their page objects listed in object
+// number order.
+func (d *document) pages() []page {
+	var pages []page
+
+	if root := d.dictOf(d.dictOf(d.trailer["Root"])["Pages"]); root != nil {
+		d.walkPages(root, nil, map[any]bool{}, &pages)
+	}
+
+	if len(pages) > 0 {
+		return pages
+	}
+
+	nums := make([]int, 0)
+
+	for num, obj := range d.objects {
+		if pd, ok := obj.(dict); ok && pd["Type"] == name("Page") {
+			nums = append(nums, num)
+		}
+	}
+
+	slices.Sort(nums)
+
+	for _, num := range nums[:min(len(nums), maxPages)] {
+		pd, _ := d.objects[num].(dict)
+		pages = append(pages, page{dict: pd, resources: d.inheritedResources(pd)})
+	}
+
+	return pages
+}
+
+// walkPages appends the pages below a node of the page tree, skipping nodes already
+// visited so that cycles end.
+func (d *document) walkPages(node dict, resources dict, seen map[any]bool, pages *[]page) {
+	if r := d.dictOf(node["Resources"]); r != nil {
+		resources = r
+	}
+
+	kids, isTree := d.resolve(node["Kids"]).(array)
+	if !isTree {
+		if len(*pages) < maxPages {
+			*pages = append(*pages, page{dict: node, resources: resources})
+		}
+
+		return
+	}
+
+	for _, kid := range kids {
+		r, isRef := kid.(ref)
+		if !isRef || seen[r] || len(*pages) >= maxPages {
+			continue
+		}
+
+		seen[r] = true
+
+		if kd := d.dictOf(r); kd != nil {
+			d.walkPages(kd, resources, seen, pages)
+		}
+	}
+}
+
+// inheritedResources returns the resources of a page, looked up through its parents when
+// the page has none.
+func (d *document) inheritedResources(pd dict) dict {
+	for range maxNesting {
+		if pd == nil {
+			return nil
+		}
+
+		if r := d.dictOf(pd["Resources"]); r != nil {
+			return r
+		}
+
+		pd = d.dictOf(pd["Parent"])
+	}
+
+	return nil
+}
+
+// contents returns the decompressed content streams of a page, concatenated.
+func (d *document) contents(pd dict) []byte {
+	var streams []any
+
+	switch c := d.resolve(pd["Contents"]).(type) {
+	case *stream:
+		streams = []any{c}
+	case array:
+		streams = c
+	}
+
+	var buf bytes.Buffer
+
+	for _, v := range streams {
+		s, ok := d.resolve(v).(*stream)
+		if !ok {
+			continue
+		}
+
+		if data, err := d.decode(s); err == nil {
+			buf.Write(data)
+			buf.WriteByte('\n')
+		}
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,204 @@
+// Package pdf provides a PDF content processor. The text of PDF documents is extracted
+// server-side from their pages so that it can be indexed and searched. The document itself
+// is served as is for the inline viewer of the portal; the processor renders the
+// extracted text, page by page, to show below the viewer.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+const (
+	// maxSize is the size, in bytes, of the largest PDF document the processor accepts.
+	maxSize = 32 << 20
+	// maxTitleLen is the length, in runes, from which a first line of text is too long to
+	// be taken as the title of a document without one in its metadata.
+	maxTitleLen = 120
+)
+
+// pdfHeader starts every PDF file.
+var pdfHeader = []byte("%PDF-")
+
+// Processor implements core.ContentProcessor for PDF documents. Text is extracted from the
+// content streams of the pages, decoding fonts through their ToUnicode maps where they
+// have one. Scanned pages without a text layer have no text.
+type Processor struct{}
+
+// New creates a new PDF Processor.
+func New() *Processor {
+	return &Processor{}
+}
+
+// ContentTypeInfo describes PDF documents: .pdf files, published as binary content.
+func (p *Processor) ContentTypeInfo() core.ContentTypeInfo {
+	return core.ContentTypeInfo{
+		Name:        core.ContentTypePDF,
+		DisplayName: "PDF",
+		Icon:        "document",
+		Extensions:  []string{".pdf"},
+		Binary:      true,
+		MediaType:   "application/pdf",
+	}
+}
+
+// Validate refuses documents larger than the processor extracts text from.
+func (p *Processor) Validate(src []byte) error {
+	if len(src) > maxSize {
+		return fmt.Errorf("%w: PDF document is larger than %d bytes", core.ErrLimitExceeded, maxSize)
+	}
+
+	return nil
+}
+
+// RenderHTML renders the text of each page as a section under a "Page N" heading, and
+// returns the headings of the pages with text.
+func (p *Processor) RenderHTML(src []byte) ([]byte, []core.Heading, error) {
+	pages := extractPages(src)
+
+	var buf bytes.Buffer
+
+	for i, text := range pages {
+		if text == "" {
+			continue
+		}
+
+		h := pageHeading(i)
+
+		fmt.Fprintf(&buf, "<section class=\"pdf-page\"><h2 id=\"%s\">%s</h2><p>", h.ID, h.Text)
+
+		for j, line := range strings.Split(text, "\n") {
+			if j > 0 {
+				buf.WriteString("<br>\n")
+			}
+
+			buf.WriteString(html.EscapeString(line))
+		}
+
+		buf.WriteString("</p></section>\n")
+	}
+
+	return buf.Bytes(), pageHeadings(pages), nil
+}
+
+// ExtractTitle returns the title from the document information dictionary, or the first
+// line of text when it is short enough to be a title.
+func (p *Processor) ExtractTitle(src []byte) string {
+	if !bytes.HasPrefix(src, pdfHeader) {
+		return ""
+	}
+
+	d := parseDocument(src)
+
+	if title, ok := d.resolve(d.dictOf(d.trailer["Info"])["Title"]).(string); ok {
+		if title = strings.Join(strings.FieldsFunc(textString(title), isBlank), " "); title != "" {
+			return title
+		}
+	}
+
+	for _, pg := range d.pages() {
+		text := d.extractText(pg)
+		if text == "" {
+			continue
+		}
+
+		line, _, _ := strings.Cut(text, "\n")
+		if len([]rune(line)) <= maxTitleLen {
+			return line
+		}
+
+		return ""
+	}
+
+	return ""
+}
+
+// ToPlainText returns the text of the pages, separated by blank lines.
+func (p *Processor) ToPlainText(src []byte) string {
+	text, _ := plainText(extractPages(src))
+	return text
+}
+
+// ExtractHeadings returns a heading for each page with text, linking to its section.
+func (p *Processor) ExtractHeadings(src []byte) []core.Heading {
+	return pageHeadings(extractPages(src))
+}
+
+// ExtractCodeBlocks returns nil: PDF documents have no code blocks.
+func (p *Processor) ExtractCodeBlocks(_ []byte) []core.CodeBlock {
+	return nil
+}
+
+// MapAnchors maps the start of the text of each page in the plain text to the heading of
+// the page, which is not part of the plain text.
+func (p *Processor) MapAnchors(src []byte) core.AnchorMap {
+	_, anchors := plainText(extractPages(src))
+	return anchors
+}
+
+// extractPages returns the text of each page of a PDF document, or nil for content that is
+// not a PDF document.
+func extractPages(src []byte) []string {
+	if !bytes.HasPrefix(src, pdfHeader) {
+		return nil
+	}
+
+	d := parseDocument(src)
+	pages := d.pages()
+	texts := make([]string, len(pages))
+
+	for i, pg := range pages {
+		texts[i] = d.extractText(pg)
+	}
+
+	return texts
+}
+
+// plainText joins the text of the pages and maps where each starts.
+func plainText(pages []string) (string, core.AnchorMap) {
+	var (
+		buf     strings.Builder
+		anchors = core.AnchorMap{}
+	)
+
+	for i, text := range pages {
+		if text == "" {
+			continue
+		}
+
+		if buf.Len() > 0 {
+			buf.WriteString("\n\n")
+		}
+
+		h := pageHeading(i)
+		anchors = append(anchors, core.Anchor{ID: h.ID, Text: h.Text, Offset: buf.Len(), Level: h.Level})
+
+		buf.WriteString(text)
+	}
+
+	return buf.String(), anchors
+}
+
+// pageHeadings returns the headings of the pages with text.
+func pageHeadings(pages []string) []core.Heading {
+	var headings []core.Heading
+
+	for i, text := range pages {
+		if text != "" {
+			headings = append(headings, pageHeading(i))
+		}
+	}
+
+	return headings
+}
+
+// pageHeading returns the heading of the page at index i.
+func pageHeading(i int) core.Heading {
+	n := strconv.Itoa(i + 1)
+	return core.Heading{ID: "page-" + n, Text: "Page " + n, Level: 2}
+}
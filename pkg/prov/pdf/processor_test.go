@@ -0,0 +1,206 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildPDF assembles a PDF file from the bodies of its objects, numbered from 1, with a
+// cross-reference table and a trailer pointing at the catalog (object 1) and, when it
+// has one, the information dictionary.
+func buildPDF(objects []string, info int) []byte {
+	var buf bytes.Buffer
+
+	buf.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+
+	offsets := make([]int, len(objects))
+
+	for i, body := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, body)
+	}
+
+	xref := buf.Len()
+
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R", len(objects)+1)
+
+	if info > 0 {
+		fmt.Fprintf(&buf, " /Info %d 0 R", info)
+	}
+
+	fmt.Fprintf(&buf, " >>\nstartxref\n%d\n%%%%EOF\n", xref)
+
+	return buf.Bytes()
+}
+
+// streamObject returns the body of a stream object with data, compressed with
+// FlateDecode when flate is set.
+func streamObject(data string, flate bool) string {
+	if !flate {
+		return fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(data), data)
+	}
+
+	var buf bytes.Buffer
+
+	zw := zlib.NewWriter(&buf)
+	_, _ = zw.Write([]byte(data))
+	_ = zw.Close()
+
+	return fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", buf.Len(), buf.String())
+}
+
+const toUnicode = `/CIDInit /ProcSet findresource begin
+12 dict begin
+begincmap
+1 begincodespacerange
+<0000> <FFFF>
+endcodespacerange
+2 beginbfchar
+<0001> <00DC>
+<0002> <0020>
+endbfchar
+1 beginbfrange
+<0003> <0005> <0061>
+endbfrange
+endcmap
+end end`
+
+// manualPDF returns a two-page document: the first page in a simple font with a
+// compressed content stream, the second in a composite font decoded through its
+// ToUnicode CMap.
+func manualPDF(title string) []byte {
+	info := 0
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 /Resources << /Font << /F1 5 0 R /F2 8 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 6 0 R >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 7 0 R >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		streamObject("BT /F1 18 Tf 72 720 Td (Getting <Started>) Tj 0 -24 Td /F1 12 Tf [(Hello) -300 (world) -50 (!)] TJ\n"+
+			"0 -14 Td (Caf\\351 \\(open\\)) Tj ET", true),
+		streamObject("BT /F2 12 Tf 72 720 Td <0001000300040005> Tj 0 -14 Td <000300020004> Tj ET", false),
+		"<< /Type /Font /Subtype /Type0 /BaseFont /Custom /Encoding /Identity-H /ToUnicode 9 0 R >>",
+		streamObject(toUnicode, true),
+	}
+
+	if title != "" {
+		objects = append(objects, "<< /Title ("+title+") /Producer (test) >>")
+		info = len(objects)
+	}
+
+	return buildPDF(objects, info)
+}
+
+func TestProcessor_ContentTypeInfo(t *testing.T) {
+	info := New().ContentTypeInfo()
+
+	assert.Equal(t, core.ContentTypePDF, info.Name)
+	assert.Equal(t, []string{".pdf"}, info.Extensions)
+	assert.True(t, info.Binary)
+	assert.Equal(t, "application/pdf", info.MediaType)
+}
+
+func TestProcessor_ToPlainText(t *testing.T) {
+	text := New().ToPlainText(manualPDF(""))
+
+	assert.Equal(t, "Getting <Started>\nHello world!\nCafé (open)\n\nÜabc\na b", text)
+}
+
+func TestProcessor_ExtractTitle(t *testing.T) {
+	p := New()
+
+	assert.Equal(t, "Omnidex Manual", p.ExtractTitle(manualPDF("Omnidex  Manual")))
+	assert.Equal(t, "Getting <Started>", p.ExtractTitle(manualPDF("")), "falls back to the first line")
+	assert.Equal(t, "Getting <Started>", p.ExtractTitle(manualPDF(" ")), "ignores a blank title")
+
+	long := buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+		streamObject("BT 72 720 Td ("+strings.Repeat("word ", 30)+") Tj ET", false),
+	}, 0)
+
+	assert.Empty(t, p.ExtractTitle(long), "a long first line is not a title")
+}
+
+func TestProcessor_ExtractHeadingsAndAnchors(t *testing.T) {
+	p := New()
+	src := manualPDF("")
+
+	assert.Equal(t, []core.Heading{
+		{ID: "page-1", Text: "Page 1", Level: 2},
+		{ID: "page-2", Text: "Page 2", Level: 2},
+	}, p.ExtractHeadings(src))
+
+	text := p.ToPlainText(src)
+	anchors := p.MapAnchors(src)
+
+	require.Len(t, anchors, 2)
+	assert.Equal(t, 0, anchors[0].Offset)
+	assert.Equal(t, "page-2", anchors[1].ID)
+	assert.True(t, strings.HasPrefix(text[anchors[1].Offset:], "Üabc"))
+}
+
+func TestProcessor_RenderHTML(t *testing.T) {
+	out, headings, err := New().RenderHTML(manualPDF(""))
+	require.NoError(t, err)
+
+	html := string(out)
+
+	assert.Contains(t, html, `<section class="pdf-page"><h2 id="page-1">Page 1</h2><p>Getting &lt;Started&gt;<br>`)
+	assert.Contains(t, html, `<h2 id="page-2">Page 2</h2><p>Üabc<br>`)
+	assert.NotContains(t, html, "<Started>")
+	assert.Len(t, headings, 2)
+}
+
+func TestProcessor_SkipsPagesWithoutText(t *testing.T) {
+	src := buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 5 0 R >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 6 0 R >>",
+		streamObject("q 100 0 0 100 0 0 cm /Im1 Do Q", false),
+		streamObject("BT 72 720 Td (Appendix) Tj ET", false),
+	}, 0)
+
+	p := New()
+
+	assert.Equal(t, "Appendix", p.ToPlainText(src))
+	assert.Equal(t, []core.Heading{{ID: "page-2", Text: "Page 2", Level: 2}}, p.ExtractHeadings(src))
+}
+
+func TestProcessor_NotPDF(t *testing.T) {
+	p := New()
+	src := []byte("# Markdown\n\nnot a PDF")
+
+	out, headings, err := p.RenderHTML(src)
+	require.NoError(t, err)
+	assert.Empty(t, out)
+	assert.Empty(t, headings)
+	assert.Empty(t, p.ExtractTitle(src))
+	assert.Empty(t, p.ToPlainText(src))
+	assert.Nil(t, p.ExtractCodeBlocks(src))
+}
+
+func TestProcessor_Validate(t *testing.T) {
+	p := New()
+
+	require.NoError(t, p.Validate(manualPDF("")))
+
+	err := p.Validate(make([]byte, maxSize+1))
+	assert.True(t, errors.Is(err, core.ErrLimitExceeded))
+}
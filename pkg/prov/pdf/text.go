@@ -0,0 +1,530 @@
+package pdf
+
+import (
+	"bytes"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+const (
+	// maxFormDepth bounds how deeply form XObjects drawn by content streams are followed.
+	maxFormDepth = 8
+	// maxOperands bounds the operands kept before an operator, so that malformed content
+	// cannot grow the operand stack without end.
+	maxOperands = 64
+	// maxRangeSize bounds the number of codes a single bfrange of a ToUnicode CMap maps.
+	maxRangeSize = 1 << 16
+	// wordGap is the TJ adjustment, in thousandths of a text space unit, from which a gap
+	// between two strings is taken as a space between words.
+	wordGap = 200
+)
+
+// font decodes the strings shown with a font into text.
+type font struct {
+	cmap      *cmap
+	encoding  *charmap.Charmap
+	composite bool // two-byte codes without a ToUnicode CMap; their text is unknown
+}
+
+// decode returns the text of a string shown with the font.
+func (f *font) decode(s string) string {
+	switch {
+	case f.cmap != nil:
+		return f.cmap.decode(s)
+	case f.composite:
+		return ""
+	}
+
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		b.WriteRune(f.encoding.DecodeByte(s[i]))
+	}
+
+	return b.String()
+}
+
+// code is a character code of a CMap with its length in bytes.
+type code struct {
+	v uint32
+	n int
+}
+
+// cmapRange maps a range of codes of the same length to consecutive text, or to the
+// text listed for each code.
+type cmapRange struct {
+	dst    []rune
+	list   []string
+	lo, hi uint32
+	n      int
+}
+
+// cmap is a ToUnicode CMap, mapping the character codes of a font to text.
+type cmap struct {
+	chars  map[code]string
+	lens   []int // lengths of the codes of the codespace ranges, ascending
+	ranges []cmapRange
+}
+
+// parseCMap reads the codespace ranges and bfchar and bfrange mappings of a CMap.
+func parseCMap(data []byte) *cmap {
+	m := &cmap{chars: map[code]string{}}
+	l := &lexer{src: data}
+
+	for {
+		tok, ok := l.token()
+		if !ok {
+			break
+		}
+
+		switch tok {
+		case keyword("begincodespacerange"):
+			for _, pair := range readEntries(l, "endcodespacerange", 2) {
+				if lo, ok := pair[0].(string); ok && len(lo) > 0 && len(lo) <= 4 && !slices.Contains(m.lens, len(lo)) {
+					m.lens = append(m.lens, len(lo))
+				}
+			}
+		case keyword("beginbfchar"):
+			for _, pair := range readEntries(l, "endbfchar", 2) {
+				src, ok1 := pair[0].(string)
+				dst, ok2 := pair[1].(string)
+
+				if ok1 && ok2 && len(src) > 0 && len(src) <= 4 {
+					m.chars[code{v: codeValue(src), n: len(src)}] = utf16Text(dst)
+				}
+			}
+		case keyword("beginbfrange"):
+			for _, entry := range readEntries(l, "endbfrange", 3) {
+				m.addRange(entry)
+			}
+		}
+	}
+
+	slices.Sort(m.lens)
+
+	if len(m.lens) == 0 {
+		m.lens = []int{1}
+	}
+
+	return m
+}
+
+// readEntries reads the groups of size operands listed before the end keyword.
+func readEntries(l *lexer, end keyword, size int) [][]any {
+	var (
+		entries [][]any
+		cur     []any
+	)
+
+	for {
+		obj, ok := l.object(0)
+		if !ok || obj == end {
+			return entries
+		}
+
+		cur = append(cur, obj)
+		if len(cur) == size {
+			entries = append(entries, cur)
+			cur = nil
+		}
+	}
+}
+
+// addRange adds a bfrange entry: the first and last code of the range, then the text of
+// the first code, incremented for the following ones, or an array of text per code.
+func (m *cmap) addRange(entry []any) {
+	lo, ok1 := entry[0].(string)
+	hi, ok2 := entry[1].(string)
+
+	if !ok1 || !ok2 || len(lo) != len(hi) || len(lo) == 0 || len(lo) > 4 {
+		return
+	}
+
+	r := cmapRange{lo: codeValue(lo), hi: codeValue(hi), n: len(lo)}
+	if r.hi < r.lo || r.hi-r.lo >= maxRangeSize {
+		return
+	}
+
+	switch dst := entry[2].(type) {
+	case string:
+		r.dst = []rune(utf16Text(dst))
+		if len(r.dst) == 0 {
+			return
+		}
+	case array:
+		for _, v := range dst {
+			s, _ := v.(string)
+			r.list = append(r.list, utf16Text(s))
+		}
+	default:
+		return
+	}
+
+	m.ranges = append(m.ranges, r)
+}
+
+// lookup returns the text of a code.
+func (m *cmap) lookup(c code) (string, bool) {
+	if s, ok := m.chars[c]; ok {
+		return s, true
+	}
+
+	for _, r := range m.ranges {
+		if r.n != c.n || c.v < r.lo || c.v > r.hi {
+			continue
+		}
+
+		i := c.v - r.lo
+
+		if r.list != nil {
+			if int(i) < len(r.list) {
+				return r.list[i], true
+			}
+
+			return "", true
+		}
+
+		dst := slices.Clone(r.dst)
+		dst[len(dst)-1] += rune(i)
+
+		return string(dst), true
+	}
+
+	return "", false
+}
+
+// decode returns the text of a string of codes. Codes without a mapping are dropped.
+func (m *cmap) decode(s string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(s); {
+		matched := false
+
+		for _, n := range m.lens {
+			if i+n > len(s) {
+				break
+			}
+
+			if text, ok := m.lookup(code{v: codeValue(s[i : i+n]), n: n}); ok {
+				b.WriteString(text)
+
+				i += n
+				matched = true
+
+				break
+			}
+		}
+
+		if !matched {
+			i += m.lens[0]
+		}
+	}
+
+	return b.String()
+}
+
+// codeValue returns the big-endian value of the bytes of a code.
+func codeValue(s string) uint32 {
+	var v uint32
+	for i := 0; i < len(s); i++ {
+		v = v<<8 | uint32(s[i])
+	}
+
+	return v
+}
+
+// utf16Text decodes UTF-16BE text, the encoding of CMap destinations and of text strings
+// starting with a byte order mark.
+func utf16Text(s string) string {
+	if len(s)%2 != 0 {
+		return latin1(s)
+	}
+
+	units := make([]uint16, 0, len(s)/2)
+	for i := 0; i+1 < len(s); i += 2 {
+		units = append(units, uint16(s[i])<<8|uint16(s[i+1]))
+	}
+
+	return string(utf16.Decode(units))
+}
+
+// latin1 decodes single-byte text.
+func latin1(s string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		b.WriteRune(charmap.Windows1252.DecodeByte(s[i]))
+	}
+
+	return b.String()
+}
+
+// textString decodes a PDF text string, such as the document title: UTF-16BE when it
+// starts with a byte order mark, otherwise a single-byte encoding.
+func textString(s string) string {
+	if strings.HasPrefix(s, "\xfe\xff") {
+		return utf16Text(s[2:])
+	}
+
+	if utf8.ValidString(s) {
+		return s
+	}
+
+	return latin1(s)
+}
+
+// loadFont returns the font of a font dictionary, cached by the reference it is found
+// at.
+func (d *document) loadFont(v any) *font {
+	r, isRef := v.(ref)
+	if isRef {
+		if f, ok := d.fonts[r]; ok {
+			return f
+		}
+	}
+
+	fd := d.dictOf(v)
+	f := &font{encoding: charmap.Windows1252, composite: fd["Subtype"] == name("Type0")}
+
+	if fd["Encoding"] == name("MacRomanEncoding") {
+		f.encoding = charmap.Macintosh
+	}
+
+	if s, ok := d.resolve(fd["ToUnicode"]).(*stream); ok {
+		if data, err := d.decode(s); err == nil {
+			f.cmap = parseCMap(data)
+		}
+	}
+
+	if isRef {
+		d.fonts[r] = f
+	}
+
+	return f
+}
+
+// textWriter collects the text shown by content streams, breaking lines where the text
+// position moves to another line.
+type textWriter struct {
+	buf      strings.Builder
+	lastY    float64
+	shown    bool // text was shown since the start of the page
+	spaceDue bool // a space is due before the next text shown on the same line
+}
+
+// show writes text shown at the vertical position y.
+func (w *textWriter) show(text string, y float64) {
+	if text == "" {
+		return
+	}
+
+	switch {
+	case w.shown && math.Abs(y-w.lastY) > 0.5:
+		w.buf.WriteByte('\n')
+	case w.spaceDue:
+		w.buf.WriteByte(' ')
+	}
+
+	w.buf.WriteString(text)
+	w.lastY = y
+	w.shown = true
+	w.spaceDue = false
+}
+
+// text returns the collected text with white space collapsed within lines and empty
+// lines dropped.
+func (w *textWriter) text() string {
+	var lines []string
+
+	for line := range strings.Lines(w.buf.String()) {
+		line = strings.Join(strings.FieldsFunc(line, isBlank), " ")
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// isBlank reports whether r is white space or a control character.
+func isBlank(r rune) bool {
+	return unicode.IsSpace(r) || unicode.IsControl(r) || r == utf8.RuneError
+}
+
+// textState is the text positioning state of a content stream: the vertical position of
+// the current line, the leading and the font.
+type textState struct {
+	font    *font
+	y       float64
+	leading float64
+}
+
+// extractText returns the text shown by the content streams of a page.
+func (d *document) extractText(p page) string {
+	w := &textWriter{}
+	d.interpret(d.contents(p.dict), p.resources, w, 0)
+
+	return w.text()
+}
+
+// interpret runs the text operators of a content stream, writing the text it shows to w.
+// Form XObjects drawn by the stream are interpreted with their own resources.
+func (d *document) interpret(content []byte, resources dict, w *textWriter, depth int) {
+	var (
+		st  textState
+		ops []any
+	)
+
+	fonts := d.dictOf(resources["Font"])
+	l := &lexer{src: content}
+
+	for {
+		obj, ok := l.object(0)
+		if !ok {
+			return
+		}
+
+		op, isOp := obj.(keyword)
+		if !isOp {
+			if len(ops) < maxOperands {
+				ops = append(ops, obj)
+			}
+
+			continue
+		}
+
+		switch op {
+		case "BT":
+			st.y = 0
+		case "Tf":
+			if len(ops) >= 2 {
+				if n, ok := ops[len(ops)-2].(name); ok {
+					st.font = d.loadFont(fonts[n])
+				}
+			}
+		case "TL":
+			st.leading = number(ops, 0)
+		case "Td", "TD":
+			ty := number(ops, 1)
+			if op == "TD" {
+				st.leading = -ty
+			}
+
+			st.y += ty
+			w.spaceDue = true
+		case "Tm":
+			st.y = number(ops, 5)
+			w.spaceDue = true
+		case "T*":
+			st.y -= st.leading
+		case "Tj":
+			w.show(st.decode(lastString(ops)), st.y)
+		case "'", "\"":
+			st.y -= st.leading
+			w.show(st.decode(lastString(ops)), st.y)
+		case "TJ":
+			if len(ops) > 0 {
+				arr, _ := ops[len(ops)-1].(array)
+				st.showArray(arr, w)
+			}
+		case "Do":
+			if len(ops) > 0 && depth < maxFormDepth {
+				if n, ok := ops[len(ops)-1].(name); ok {
+					d.drawForm(d.dictOf(resources["XObject"])[n], resources, w, depth)
+				}
+			}
+		case "BI":
+			l.skipInlineImage()
+		}
+
+		ops = ops[:0]
+	}
+}
+
+// showArray writes the strings of a TJ array, with a space where the adjustment between
+// two strings is wide enough to separate words.
+func (st *textState) showArray(arr array, w *textWriter) {
+	for _, v := range arr {
+		switch t := v.(type) {
+		case string:
+			w.show(st.decode(t), st.y)
+		case float64:
+			if -t > wordGap {
+				w.spaceDue = true
+			}
+		}
+	}
+}
+
+// decode returns the text of a string shown with the current font.
+func (st *textState) decode(s string) string {
+	if st.font == nil {
+		return latin1(s)
+	}
+
+	return st.font.decode(s)
+}
+
+// drawForm interprets the content of a form XObject.
+func (d *document) drawForm(v any, resources dict, w *textWriter, depth int) {
+	s, ok := d.resolve(v).(*stream)
+	if !ok || s.hdr["Subtype"] != name("Form") {
+		return
+	}
+
+	data, err := d.decode(s)
+	if err != nil {
+		return
+	}
+
+	if r := d.dictOf(s.hdr["Resources"]); r != nil {
+		resources = r
+	}
+
+	d.interpret(data, resources, w, depth+1)
+}
+
+// skipInlineImage skips the data of an inline image up to its EI operator.
+func (l *lexer) skipInlineImage() {
+	for l.pos < len(l.src) {
+		i := bytes.Index(l.src[l.pos:], []byte("EI"))
+		if i < 0 {
+			l.pos = len(l.src)
+			return
+		}
+
+		at := l.pos + i
+		l.pos = at + 2
+
+		if at > 0 && isSpace(l.src[at-1]) && (l.pos == len(l.src) || isSpace(l.src[l.pos])) {
+			return
+		}
+	}
+}
+
+// number returns the i-th operand as a number, or zero.
+func number(ops []any, i int) float64 {
+	if i < len(ops) {
+		n, _ := ops[i].(float64)
+		return n
+	}
+
+	return 0
+}
+
+// lastString returns the last operand as a string, or an empty string.
+func lastString(ops []any) string {
+	if len(ops) == 0 {
+		return ""
+	}
+
+	s, _ := ops[len(ops)-1].(string)
+
+	return s
+}
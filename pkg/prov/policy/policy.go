@@ -0,0 +1,172 @@
+// Package policy provides a regular-expression based content policy that redacts or
+// flags sensitive data, such as email addresses, phone numbers and internal hostnames,
+// in ingested documents.
+package policy
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+const defaultReplacement = "[REDACTED]"
+
+// builtinPatterns are the rule names that can be used without a pattern.
+var builtinPatterns = map[string]string{
+	"email":             `[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`,
+	"phone":             `(?:\+\d{1,3}[\s.-]?)?(?:\(\d{2,4}\)|\b\d{2,4})[\s.-]\d{3,4}[\s.-]\d{3,4}\b`,
+	"internal_hostname": `(?i)\b[a-z0-9](?:[a-z0-9-]*[a-z0-9])?(?:\.[a-z0-9](?:[a-z0-9-]*[a-z0-9])?)*\.(?:internal|corp|intranet|lan|local)\b`,
+}
+
+// Config holds the content policy configuration. Rules apply to every repository
+// unless a matching entry in Repos replaces them.
+type Config struct {
+	Rules []Rule       `mapstructure:"rules"`
+	Repos []RepoPolicy `mapstructure:"repos"`
+}
+
+// RepoPolicy replaces the default rules for a single repository. An entry with no
+// rules disables the policy for that repository.
+type RepoPolicy struct {
+	Repo  string `mapstructure:"repo"`
+	Rules []Rule `mapstructure:"rules"`
+}
+
+// Rule matches content to redact or flag. Name is either one of the built-in rules
+// ("email", "phone", "internal_hostname"), in which case Pattern may be omitted, or a
+// custom name for the regular expression in Pattern. Action is "redact" (default) or
+// "flag". Replacement defaults to "[REDACTED]".
+type Rule struct {
+	Name        string `mapstructure:"name"`
+	Pattern     string `mapstructure:"pattern"`
+	Action      string `mapstructure:"action"`
+	Replacement string `mapstructure:"replacement"`
+}
+
+// Enabled reports whether the configuration defines any rules.
+func (c Config) Enabled() bool {
+	return len(c.Rules) > 0 || len(c.Repos) > 0
+}
+
+// Policy applies the configured rules to document content.
+type Policy struct {
+	repos    map[string][]compiledRule
+	defaults []compiledRule
+}
+
+type compiledRule struct {
+	re          *regexp.Regexp
+	name        string
+	action      string
+	replacement []byte
+}
+
+// New compiles the rules of the configuration. It returns an error if a rule has no
+// usable pattern, an invalid pattern or an unknown action.
+func New(cfg Config) (*Policy, error) {
+	defaults, err := compileRules(cfg.Rules)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Policy{defaults: defaults}
+
+	for _, override := range cfg.Repos {
+		if override.Repo == "" {
+			return nil, fmt.Errorf("policy repo entries must specify a repo")
+		}
+
+		rules, err := compileRules(override.Rules)
+		if err != nil {
+			return nil, fmt.Errorf("invalid policy for repo %s: %w", override.Repo, err)
+		}
+
+		if p.repos == nil {
+			p.repos = make(map[string][]compiledRule, len(cfg.Repos))
+		}
+
+		p.repos[strings.ToLower(override.Repo)] = rules
+	}
+
+	return p, nil
+}
+
+// compileRules validates and compiles a list of rules.
+func compileRules(rules []Rule) ([]compiledRule, error) {
+	compiled := make([]compiledRule, 0, len(rules))
+
+	for _, r := range rules {
+		pattern := r.Pattern
+		if pattern == "" {
+			pattern = builtinPatterns[r.Name]
+		}
+
+		if pattern == "" {
+			return nil, fmt.Errorf("policy rule %q has no pattern and is not a built-in rule", r.Name)
+		}
+
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern for policy rule %q: %w", r.Name, err)
+		}
+
+		action := r.Action
+		switch action {
+		case "":
+			action = core.PolicyActionRedact
+		case core.PolicyActionRedact, core.PolicyActionFlag:
+		default:
+			return nil, fmt.Errorf("unknown action %q for policy rule %q: must be %q or %q",
+				action, r.Name, core.PolicyActionRedact, core.PolicyActionFlag)
+		}
+
+		replacement := r.Replacement
+		if replacement == "" {
+			replacement = defaultReplacement
+		}
+
+		compiled = append(compiled, compiledRule{
+			re:          re,
+			name:        r.Name,
+			action:      action,
+			replacement: []byte(replacement),
+		})
+	}
+
+	return compiled, nil
+}
+
+// Apply redacts and flags content according to the rules of the repository. Rules run
+// in configuration order, each on the output of the previous one.
+func (p *Policy) Apply(repo string, content []byte) ([]byte, []core.PolicyFinding) {
+	rules, ok := p.repos[strings.ToLower(repo)]
+	if !ok {
+		rules = p.defaults
+	}
+
+	var findings []core.PolicyFinding
+
+	for _, rule := range rules {
+		matches := rule.re.FindAllIndex(content, -1)
+		if len(matches) == 0 {
+			continue
+		}
+
+		for _, m := range matches {
+			findings = append(findings, core.PolicyFinding{
+				Rule:   rule.name,
+				Action: rule.action,
+				Line:   bytes.Count(content[:m[0]], []byte("\n")) + 1,
+			})
+		}
+
+		if rule.action == core.PolicyActionRedact {
+			content = rule.re.ReplaceAllLiteral(content, rule.replacement)
+		}
+	}
+
+	return content, findings
+}
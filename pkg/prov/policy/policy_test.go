@@ -0,0 +1,88 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr string
+		cfg     Config
+	}{
+		{name: "unknown rule without pattern", cfg: Config{Rules: []Rule{{Name: "ssn"}}}, wantErr: "not a built-in rule"},
+		{name: "invalid pattern", cfg: Config{Rules: []Rule{{Name: "x", Pattern: "("}}}, wantErr: "invalid pattern"},
+		{name: "unknown action", cfg: Config{Rules: []Rule{{Name: "email", Action: "drop"}}}, wantErr: "unknown action"},
+		{name: "repo without name", cfg: Config{Repos: []RepoPolicy{{}}}, wantErr: "must specify a repo"},
+		{
+			name:    "invalid repo rule",
+			cfg:     Config{Repos: []RepoPolicy{{Repo: "owner/repo", Rules: []Rule{{Name: "x"}}}}},
+			wantErr: "invalid policy for repo owner/repo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.cfg)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestConfig_Enabled(t *testing.T) {
+	assert.False(t, Config{}.Enabled())
+	assert.True(t, Config{Rules: []Rule{{Name: "email"}}}.Enabled())
+	assert.True(t, Config{Repos: []RepoPolicy{{Repo: "owner/repo"}}}.Enabled())
+}
+
+func TestPolicy_Apply(t *testing.T) {
+	p, err := New(Config{
+		Rules: []Rule{
+			{Name: "email"},
+			{Name: "phone", Replacement: "[PHONE]"},
+			{Name: "internal_hostname", Action: core.PolicyActionFlag},
+		},
+	})
+	require.NoError(t, err)
+
+	content := "# Contacts\n\nMail jane.doe@example.com or call +1 555-123-4567.\n" +
+		"Dashboards live at grafana.prod.internal, released 2024-01-15 as v1.2.3.\n"
+
+	out, findings := p.Apply("owner/repo", []byte(content))
+
+	assert.Equal(t, "# Contacts\n\nMail [REDACTED] or call [PHONE].\n"+
+		"Dashboards live at grafana.prod.internal, released 2024-01-15 as v1.2.3.\n", string(out))
+	assert.Equal(t, []core.PolicyFinding{
+		{Rule: "email", Action: core.PolicyActionRedact, Line: 3},
+		{Rule: "phone", Action: core.PolicyActionRedact, Line: 3},
+		{Rule: "internal_hostname", Action: core.PolicyActionFlag, Line: 4},
+	}, findings)
+}
+
+func TestPolicy_Apply_RepoOverride(t *testing.T) {
+	p, err := New(Config{
+		Rules: []Rule{{Name: "email"}},
+		Repos: []RepoPolicy{
+			{Repo: "Owner/Internal"},
+			{Repo: "owner/public", Rules: []Rule{{Name: "codename", Pattern: `(?i)project\s+falcon`}}},
+		},
+	})
+	require.NoError(t, err)
+
+	content := []byte("Ask ops@example.com about Project Falcon.")
+
+	out, findings := p.Apply("owner/internal", content)
+	assert.Equal(t, string(content), string(out))
+	assert.Empty(t, findings)
+
+	out, findings = p.Apply("owner/public", content)
+	assert.Equal(t, "Ask ops@example.com about [REDACTED].", string(out))
+	assert.Len(t, findings, 1)
+
+	out, _ = p.Apply("owner/other", content)
+	assert.Equal(t, "Ask [REDACTED] about Project Falcon.", string(out))
+}
@@ -0,0 +1,108 @@
+// Package webhook delivers saved search alerts to webhooks.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+const (
+	requestTimeout = 10 * time.Second
+	// maxErrorBody bounds the part of an error response included in the returned error.
+	maxErrorBody = 512
+)
+
+// Notifier implements core.AlertNotifier by posting the documents matching a saved search
+// as JSON to its webhook.
+type Notifier struct {
+	httpClient *http.Client
+	portalURL  string
+}
+
+// New creates a Notifier. Documents are linked on the portal at portalURL when it is set.
+func New(portalURL string) *Notifier {
+	return &Notifier{
+		portalURL: strings.TrimSuffix(portalURL, "/"),
+		httpClient: &http.Client{
+			Timeout:       requestTimeout,
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+	}
+}
+
+// alert is the body posted to a webhook.
+type alert struct {
+	Search  alertSearch  `json:"search"`
+	Matches []alertMatch `json:"matches"`
+}
+
+// alertSearch identifies the saved search of an alert.
+type alertSearch struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Query string `json:"query"`
+}
+
+// alertMatch is a document matching the saved search of an alert.
+type alertMatch struct {
+	Time  time.Time `json:"time"`
+	ID    string    `json:"id"` // encoded core.DocumentID of the document
+	Repo  string    `json:"repo"`
+	Path  string    `json:"path"`
+	Title string    `json:"title"`
+	URL   string    `json:"url,omitempty"`
+}
+
+// Notify posts the matches of search to its webhook. Redirects are not followed, and any
+// status other than 2xx is an error.
+func (n *Notifier) Notify(ctx context.Context, search core.SavedSearch, matches []core.SearchMatch) error { //nolint:gocritic // SavedSearch is passed by value like the AlertNotifier interface
+	body := alert{
+		Search:  alertSearch{ID: search.ID, Name: search.Name, Query: search.Query},
+		Matches: make([]alertMatch, 0, len(matches)),
+	}
+
+	for _, m := range matches {
+		am := alertMatch{Time: m.Time, ID: core.NewDocumentID(m.Repo, m.Path).Encode(), Repo: m.Repo, Path: m.Path, Title: m.Title}
+		if n.portalURL != "" {
+			am.URL = n.portalURL + "/docs/" + m.Repo + "/" + (&url.URL{Path: m.Path}).EscapedPath()
+		}
+
+		body.Matches = append(body.Matches, am)
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, search.Webhook, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Omnidex")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook request: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("webhook returned HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifier_Notify(t *testing.T) {
+	var got alert
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		switch r.URL.Path {
+		case "/hooks/broken":
+			http.Error(w, "no such hook", http.StatusNotFound)
+		case "/hooks/moved":
+			http.Redirect(w, r, "/hooks/docs", http.StatusFound)
+		default:
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	now := time.Date(2026, 1, 5, 10, 0, 0, 0, time.UTC)
+	search := core.SavedSearch{ID: "a1", Name: "Deploys", Query: "deploy", Webhook: srv.URL + "/hooks/docs"}
+	matches := []core.SearchMatch{{Time: now, Repo: "acme/api", Path: "guides/deploy now.md", Title: "Deploying"}}
+
+	require.NoError(t, New("https://docs.example.com/").Notify(t.Context(), search, matches))
+	assert.Equal(t, alert{
+		Search: alertSearch{ID: "a1", Name: "Deploys", Query: "deploy"},
+		Matches: []alertMatch{{
+			Time: now, ID: "YWNtZS9hcGkAZ3VpZGVzL2RlcGxveSBub3cubWQ", Repo: "acme/api", Path: "guides/deploy now.md", Title: "Deploying",
+			URL: "https://docs.example.com/docs/acme/api/guides/deploy%20now.md",
+		}},
+	}, got)
+
+	search.Webhook = srv.URL + "/hooks/broken"
+	assert.ErrorContains(t, New("").Notify(t.Context(), search, matches), "webhook returned HTTP 404: no such hook")
+
+	search.Webhook = srv.URL + "/hooks/moved"
+	assert.ErrorContains(t, New("").Notify(t.Context(), search, matches), "webhook returned HTTP 302", "redirects are not followed")
+}
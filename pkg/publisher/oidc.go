@@ -0,0 +1,65 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// FetchActionsIDToken requests a GitHub Actions OIDC ID token for the given audience from
+// the job's token endpoint. The workflow must grant the id-token: write permission, which
+// makes GitHub set the ACTIONS_ID_TOKEN_REQUEST_URL and ACTIONS_ID_TOKEN_REQUEST_TOKEN
+// environment variables.
+func FetchActionsIDToken(ctx context.Context, audience string) (string, error) {
+	requestURL := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_URL")
+	requestToken := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN")
+
+	if requestURL == "" || requestToken == "" {
+		return "", errors.New("ID token request is not available: the workflow must run in GitHub Actions with the id-token: write permission")
+	}
+
+	u, err := url.Parse(requestURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse ID token request URL: %w", err)
+	}
+
+	q := u.Query()
+	q.Set("audience", audience)
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), http.NoBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to create ID token request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+requestToken)
+
+	resp, err := (&http.Client{Timeout: requestTimeout}).Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to request ID token: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("ID token request failed with status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Value string `json:"value"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode ID token response: %w", err)
+	}
+
+	if body.Value == "" {
+		return "", errors.New("ID token response has no token")
+	}
+
+	return body.Value, nil
+}
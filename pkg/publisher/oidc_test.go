@@ -0,0 +1,43 @@
+package publisher
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchActionsIDToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer request-token", r.Header.Get("Authorization"))
+		assert.Equal(t, "1", r.URL.Query().Get("api-version"))
+
+		if r.URL.Query().Get("audience") != "https://docs.example.com" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"count":1,"value":"id-token"}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_URL", server.URL+"?api-version=1")
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN", "request-token")
+
+	token, err := FetchActionsIDToken(t.Context(), "https://docs.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "id-token", token)
+
+	_, err = FetchActionsIDToken(t.Context(), "https://other.example.com")
+	assert.ErrorContains(t, err, "status 400")
+}
+
+func TestFetchActionsIDToken_NotAvailable(t *testing.T) {
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_URL", "")
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN", "")
+
+	_, err := FetchActionsIDToken(t.Context(), "https://docs.example.com")
+	assert.ErrorContains(t, err, "id-token: write")
+}
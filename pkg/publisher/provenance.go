@@ -0,0 +1,58 @@
+package publisher
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"strings"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+// The headers carrying the detached HMAC-SHA256 signature of the ingest payload and the
+// Unix time it was signed at. They match the headers checked by the Omnidex server, which
+// refuses signatures that are too old or already used.
+const (
+	signatureHeader          = "X-Omnidex-Signature"
+	signatureTimestampHeader = "X-Omnidex-Timestamp"
+)
+
+// WithSigningKey makes the publisher sign every ingest payload with key, so that the
+// server can verify the payload and mark the published documents as having verified
+// provenance. An empty key disables signing.
+func (p *Publisher) WithSigningKey(key string) *Publisher {
+	p.signingKey = key
+	return p
+}
+
+// sign returns the signature header value for body signed at timestamp, a Unix time.
+func sign(key, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// ActionsProvenance returns the identity of the GitHub Actions workflow run the publish
+// runs in, read from the environment GitHub sets for every job, or nil outside GitHub Actions.
+func ActionsProvenance() *core.Provenance {
+	workflow := os.Getenv("GITHUB_WORKFLOW_REF")
+	if workflow == "" {
+		return nil
+	}
+
+	prov := &core.Provenance{Workflow: workflow}
+
+	server, repo, runID := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"), os.Getenv("GITHUB_RUN_ID")
+	if server != "" && repo != "" && runID != "" {
+		prov.RunURL = strings.TrimRight(server, "/") + "/" + repo + "/actions/runs/" + runID
+
+		if attempt := os.Getenv("GITHUB_RUN_ATTEMPT"); attempt != "" {
+			prov.RunURL += "/attempts/" + attempt
+		}
+	}
+
+	return prov
+}
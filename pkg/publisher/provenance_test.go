@@ -0,0 +1,68 @@
+package publisher
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendIngestRequest_Signed(t *testing.T) {
+	var signature, got, timestamp string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		timestamp = r.Header.Get("X-Omnidex-Timestamp")
+
+		mac := hmac.New(sha256.New, []byte("signing-secret"))
+		mac.Write([]byte(timestamp + "."))
+		mac.Write(body)
+		signature = "sha256=" + hex.EncodeToString(mac.Sum(nil))
+		got = r.Header.Get("X-Omnidex-Signature")
+
+		_, _ = w.Write([]byte(`{"indexed":1}`))
+	}))
+	defer srv.Close()
+
+	req := core.IngestRequest{Repo: "owner/repo", Documents: []core.IngestDocument{{Path: "doc.md", Content: "# Doc", Action: "upsert"}}}
+
+	_, err := New(srv.URL, "test-key").WithSigningKey("signing-secret").SendIngestRequest(t.Context(), &req)
+	require.NoError(t, err)
+	assert.Equal(t, signature, got, "the signature covers the timestamp and the exact payload sent")
+
+	signedAt, err := strconv.ParseInt(timestamp, 10, 64)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), time.Unix(signedAt, 0), time.Minute)
+
+	_, err = New(srv.URL, "test-key").SendIngestRequest(t.Context(), &req)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestActionsProvenance(t *testing.T) {
+	t.Setenv("GITHUB_WORKFLOW_REF", "")
+
+	assert.Nil(t, ActionsProvenance())
+
+	t.Setenv("GITHUB_WORKFLOW_REF", "owner/repo/.github/workflows/docs.yml@refs/heads/main")
+	t.Setenv("GITHUB_SERVER_URL", "https://github.com")
+	t.Setenv("GITHUB_REPOSITORY", "owner/repo")
+	t.Setenv("GITHUB_RUN_ID", "42")
+	t.Setenv("GITHUB_RUN_ATTEMPT", "2")
+
+	assert.Equal(t, &core.Provenance{
+		Workflow: "owner/repo/.github/workflows/docs.yml@refs/heads/main",
+		RunURL:   "https://github.com/owner/repo/actions/runs/42/attempts/2",
+	}, ActionsProvenance())
+}
@@ -16,7 +16,9 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -32,11 +34,41 @@ const requestTimeout = 30 * time.Second
 // actionUpsert is the ingest action used to add or update documents and assets.
 const actionUpsert = "upsert"
 
+// DefaultFilePattern is used when no file pattern is configured. It follows common
+// repository conventions: the root README.md and CHANGELOG.md plus documentation files
+// under docs/. The root README.md becomes the repository's landing page. Publish narrows
+// or widens the files under docs/ to the extensions the server processes; see
+// DefaultFilePatternFor.
+const DefaultFilePattern = "{README.md,CHANGELOG.md,docs/**/*.{md,yaml,yml,json}}"
+
+// DefaultFilePatternFor returns the default file pattern for a server processing the
+// given content types: the root README.md and CHANGELOG.md plus the files under docs/
+// with an extension of one of the types.
+func DefaultFilePatternFor(types []core.ContentTypeInfo) string {
+	var exts []string
+
+	for _, t := range types {
+		for _, ext := range t.Extensions {
+			ext = strings.TrimPrefix(ext, ".")
+			if ext != "" && !slices.Contains(exts, ext) {
+				exts = append(exts, ext)
+			}
+		}
+	}
+
+	if len(exts) == 0 {
+		return DefaultFilePattern
+	}
+
+	return "{README.md,CHANGELOG.md,docs/**/*.{" + strings.Join(exts, ",") + "}}"
+}
+
 // Publisher handles publishing documentation to an Omnidex instance.
 type Publisher struct {
 	httpClient *http.Client
 	baseURL    string
 	apiKey     string
+	signingKey string
 }
 
 // New creates a new Publisher configured with the given base URL and API key.
@@ -48,12 +80,56 @@ func New(baseURL, apiKey string) *Publisher {
 	}
 }
 
+// ServerError reports that the Omnidex server could not be reached or rejected an ingest
+// request, as opposed to a problem with the local files. StatusCode is zero when no
+// response was received.
+type ServerError struct {
+	Err        error
+	StatusCode int
+}
+
+// Error returns the message of the underlying error.
+func (e *ServerError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *ServerError) Unwrap() error {
+	return e.Err
+}
+
+// Result is the outcome of a publish: the server response, along with the collected files
+// that were not published because their content type could not be determined.
+type Result struct {
+	*core.IngestResponse
+	Skipped []string
+}
+
 // Publish collects documentation files from docsPath matching filePattern,
 // builds an ingest request, and sends it to the Omnidex server.
+// The content types the server processes decide which files are uploaded and as which type.
+// An empty filePattern selects the default file pattern for those content types.
 // When sync is true, the server will remove any stored documents not present in this publish.
 // Referenced images are automatically detected in markdown files and bundled as assets.
-// It returns the server response or an error if any step fails.
-func (p *Publisher) Publish(ctx context.Context, docsPath, filePattern, repo, commitSHA string, sync bool) (*core.IngestResponse, error) {
+// It returns the publish result or an error if any step fails.
+func (p *Publisher) Publish(ctx context.Context, docsPath, filePattern, repo, commitSHA string, sync bool) (*Result, error) {
+	// Check the local input before contacting the server for the default pattern.
+	if _, err := os.Stat(docsPath); err != nil {
+		return nil, fmt.Errorf("failed to collect files: %w", err)
+	}
+
+	var types []core.ContentTypeInfo
+
+	if filePattern == "" {
+		var err error
+
+		if types, err = p.ContentTypes(ctx); err != nil {
+			return nil, fmt.Errorf("failed to get content types: %w", err)
+		}
+
+		filePattern = DefaultFilePatternFor(types)
+	}
+
 	files, err := CollectFiles(docsPath, filePattern)
 	if err != nil {
 		return nil, fmt.Errorf("failed to collect files: %w", err)
@@ -61,12 +137,18 @@ func (p *Publisher) Publish(ctx context.Context, docsPath, filePattern, repo, co
 
 	if len(files) == 0 {
 		slog.Warn("No files matched the pattern", "path", docsPath, "pattern", filePattern)
-		return &core.IngestResponse{}, nil
+		return &Result{IngestResponse: &core.IngestResponse{}}, nil
 	}
 
 	slog.Info("Collected documentation files", "count", len(files))
 
-	assets, err := CollectAssets(docsPath, files)
+	if types == nil {
+		if types, err = p.ContentTypes(ctx); err != nil {
+			return nil, fmt.Errorf("failed to get content types: %w", err)
+		}
+	}
+
+	assets, err := CollectAssets(types, docsPath, files)
 	if err != nil {
 		return nil, fmt.Errorf("failed to collect assets: %w", err)
 	}
@@ -75,20 +157,46 @@ func (p *Publisher) Publish(ctx context.Context, docsPath, filePattern, repo, co
 		slog.Info("Collected referenced assets", "count", len(assets))
 	}
 
-	req := BuildIngestRequest(repo, commitSHA, files, assets, sync)
+	req := BuildIngestRequest(types, repo, commitSHA, files, assets, sync)
+	req.Provenance = ActionsProvenance()
 
 	resp, err := p.SendIngestRequest(ctx, &req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to publish documentation: %w", err)
 	}
 
-	return resp, nil
+	return &Result{IngestResponse: resp, Skipped: skippedFiles(files, &req)}, nil
+}
+
+// skippedFiles returns the sorted paths of collected files that BuildIngestRequest left out
+// of the request.
+func skippedFiles(files map[string]string, req *core.IngestRequest) []string {
+	included := make(map[string]struct{}, len(req.Documents))
+	for _, doc := range req.Documents {
+		included[doc.Path] = struct{}{}
+	}
+
+	var skipped []string
+
+	for p := range files {
+		if _, ok := included[p]; !ok {
+			skipped = append(skipped, p)
+		}
+	}
+
+	sort.Strings(skipped)
+
+	return skipped
 }
 
 // CollectFiles walks the directory at docsPath and returns the content of all files
 // matching the given glob pattern. The returned map keys are relative paths from docsPath
-// using forward slashes.
+// using forward slashes. An empty filePattern selects DefaultFilePattern.
 func CollectFiles(docsPath, filePattern string) (map[string]string, error) {
+	if filePattern == "" {
+		filePattern = DefaultFilePattern
+	}
+
 	info, err := os.Stat(docsPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to stat docs path %s: %w", docsPath, err)
@@ -147,11 +255,32 @@ func CollectFiles(docsPath, filePattern string) (map[string]string, error) {
 }
 
 // BuildIngestRequest constructs an IngestRequest from the collected file contents and assets.
+// The content type of each file is detected among types, or the default content types when
+// types is nil; files of no content type are left out. Files of binary content types are
+// sent base64-encoded.
 // All documents and assets are set to action "upsert". Entries are sorted by path for deterministic ordering.
 // When sync is true, the server will treat this as the complete document set and remove stale entries.
-func BuildIngestRequest(repo, commitSHA string, files map[string]string, assets map[string][]byte, sync bool) core.IngestRequest {
+func BuildIngestRequest(
+	types []core.ContentTypeInfo,
+	repo, commitSHA string,
+	files map[string]string,
+	assets map[string][]byte,
+	sync bool,
+) core.IngestRequest {
+	if types == nil {
+		types = core.DefaultContentTypes()
+	}
+
 	documents := make([]core.IngestDocument, 0, len(files))
 
+	binary := make(map[core.ContentType]bool)
+
+	for _, t := range types {
+		if t.Binary {
+			binary[t.Name] = true
+		}
+	}
+
 	// Sort keys for deterministic ordering.
 	paths := make([]string, 0, len(files))
 	for p := range files {
@@ -161,21 +290,29 @@ func BuildIngestRequest(repo, commitSHA string, files map[string]string, assets
 	sort.Strings(paths)
 
 	for _, p := range paths {
-		ct := core.DetectContentType(p, []byte(files[p]))
+		ct := core.DetectContentTypeIn(types, p, []byte(files[p]))
 
 		// Skip files whose content type could not be determined (e.g. arbitrary
-		// YAML/JSON that is not an OpenAPI spec).
+		// YAML/JSON that is not an API spec).
 		if ct == "" {
 			slog.Debug("skipping file with unrecognized content type", "path", p)
 			continue
 		}
 
-		documents = append(documents, core.IngestDocument{
+		doc := core.IngestDocument{
 			Path:        p,
 			Content:     files[p],
 			Action:      actionUpsert,
 			ContentType: ct,
-		})
+		}
+
+		// Binary documents, such as PDFs, do not fit in JSON strings as they are.
+		if binary[ct] {
+			doc.Content = base64.StdEncoding.EncodeToString([]byte(files[p]))
+			doc.Encoding = core.ContentEncodingBase64
+		}
+
+		documents = append(documents, doc)
 	}
 
 	// Build asset entries with base64 encoding.
@@ -254,12 +391,17 @@ func ExtractImageRefs(content string) []string {
 // CollectAssets scans markdown documents for relative image references, reads the
 // referenced files from disk, and returns a map of resolved asset paths to their binary content.
 // Paths are resolved relative to each markdown file's directory within docsPath.
-// References that escape the docsPath boundary are logged and skipped.
-func CollectAssets(docsPath string, docs map[string]string) (map[string][]byte, error) {
+// References that escape the docsPath boundary are logged and skipped. Markdown documents
+// are told apart among types, or the default content types when types is nil.
+func CollectAssets(types []core.ContentTypeInfo, docsPath string, docs map[string]string) (map[string][]byte, error) {
+	if types == nil {
+		types = core.DefaultContentTypes()
+	}
+
 	assets := make(map[string][]byte)
 
 	for docRelPath, content := range docs {
-		ct := core.DetectContentType(docRelPath, []byte(content))
+		ct := core.DetectContentTypeIn(types, docRelPath, []byte(content))
 		if ct != core.ContentTypeMarkdown {
 			continue
 		}
@@ -318,6 +460,56 @@ func CollectAssets(docsPath string, docs map[string]string) (map[string][]byte,
 	return assets, nil
 }
 
+// ContentTypes returns the content types the Omnidex server processes. Servers that predate
+// GET /api/v1/content-types are assumed to process the default content types.
+func (p *Publisher) ContentTypes(ctx context.Context) ([]core.ContentTypeInfo, error) {
+	endpoint := strings.TrimRight(p.baseURL, "/") + "/api/v1/content-types"
+
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+
+	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	resp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, &ServerError{Err: fmt.Errorf("HTTP request failed: %w", err)}
+	}
+
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &ServerError{Err: fmt.Errorf("failed to read response body: %w", err), StatusCode: resp.StatusCode}
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		slog.Debug("server does not list its content types, using the defaults", "url", p.baseURL)
+		return core.DefaultContentTypes(), nil
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, &ServerError{
+			Err:        fmt.Errorf("server returned HTTP %d: %s", resp.StatusCode, string(respBody)),
+			StatusCode: resp.StatusCode,
+		}
+	}
+
+	var result struct {
+		ContentTypes []core.ContentTypeInfo `json:"content_types"`
+	}
+
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, &ServerError{Err: fmt.Errorf("failed to parse response: %w", err), StatusCode: resp.StatusCode}
+	}
+
+	return result.ContentTypes, nil
+}
+
 // SendIngestRequest POSTs the IngestRequest to the Omnidex server's ingest API endpoint.
 // It returns the parsed IngestResponse or an error if the request fails or the server returns a non-2xx status.
 func (p *Publisher) SendIngestRequest(ctx context.Context, req *core.IngestRequest) (*core.IngestResponse, error) {
@@ -339,25 +531,35 @@ func (p *Publisher) SendIngestRequest(ctx context.Context, req *core.IngestReque
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
 
+	if p.signingKey != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+		httpReq.Header.Set(signatureTimestampHeader, timestamp)
+		httpReq.Header.Set(signatureHeader, sign(p.signingKey, timestamp, body))
+	}
+
 	resp, err := p.httpClient.Do(httpReq)
 	if err != nil {
-		return nil, fmt.Errorf("HTTP request failed: %w", err)
+		return nil, &ServerError{Err: fmt.Errorf("HTTP request failed: %w", err)}
 	}
 
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, &ServerError{Err: fmt.Errorf("failed to read response body: %w", err), StatusCode: resp.StatusCode}
 	}
 
 	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
-		return nil, fmt.Errorf("server returned HTTP %d: %s", resp.StatusCode, string(respBody))
+		return nil, &ServerError{
+			Err:        fmt.Errorf("server returned HTTP %d: %s", resp.StatusCode, string(respBody)),
+			StatusCode: resp.StatusCode,
+		}
 	}
 
 	var ingestResp core.IngestResponse
 	if err := json.Unmarshal(respBody, &ingestResp); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+		return nil, &ServerError{Err: fmt.Errorf("failed to parse response: %w", err), StatusCode: resp.StatusCode}
 	}
 
 	return &ingestResp, nil
@@ -2,6 +2,7 @@ package publisher
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -84,13 +85,37 @@ func TestCollectFiles_CustomPattern(t *testing.T) {
 	assert.Equal(t, "guide", files["docs/guide.md"])
 }
 
+func TestCollectFiles_DefaultPattern(t *testing.T) {
+	dir := t.TempDir()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "docs", "api"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "internal"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Service"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "CHANGELOG.md"), []byte("# Changelog"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "CONTRIBUTING.md"), []byte("# Contributing"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docs", "guide.md"), []byte("guide"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docs", "api", "openapi.yaml"), []byte("openapi: 3.0.0"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docs", "logo.png"), []byte("png"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "internal", "notes.md"), []byte("notes"), 0o600))
+
+	files, err := CollectFiles(dir, "")
+	require.NoError(t, err)
+
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+
+	assert.ElementsMatch(t, []string{"README.md", "CHANGELOG.md", "docs/guide.md", "docs/api/openapi.yaml"}, paths)
+}
+
 func TestBuildIngestRequest(t *testing.T) {
 	files := map[string]string{
 		"guide.md":      "# Guide",
 		"api/readme.md": "# API",
 	}
 
-	req := BuildIngestRequest("owner/repo", "abc123", files, nil, true)
+	req := BuildIngestRequest(nil, "owner/repo", "abc123", files, nil, true)
 
 	assert.Equal(t, "owner/repo", req.Repo)
 	assert.Equal(t, "abc123", req.CommitSHA)
@@ -114,7 +139,7 @@ func TestBuildIngestRequest_SyncFalse(t *testing.T) {
 		"readme.md": "# Hello",
 	}
 
-	req := BuildIngestRequest("owner/repo", "sha", files, nil, false)
+	req := BuildIngestRequest(nil, "owner/repo", "sha", files, nil, false)
 
 	assert.Equal(t, "owner/repo", req.Repo)
 	assert.False(t, req.Sync)
@@ -122,7 +147,7 @@ func TestBuildIngestRequest_SyncFalse(t *testing.T) {
 }
 
 func TestBuildIngestRequest_Empty(t *testing.T) {
-	req := BuildIngestRequest("owner/repo", "sha", map[string]string{}, nil, true)
+	req := BuildIngestRequest(nil, "owner/repo", "sha", map[string]string{}, nil, true)
 
 	assert.Equal(t, "owner/repo", req.Repo)
 	assert.True(t, req.Sync)
@@ -140,7 +165,7 @@ paths: {}`,
 		"config.yaml":    "name: my-app\nversion: 1.0.0",
 	}
 
-	req := BuildIngestRequest("owner/repo", "sha", files, nil, false)
+	req := BuildIngestRequest(nil, "owner/repo", "sha", files, nil, false)
 
 	// config.yaml is not OpenAPI, so it should be skipped entirely.
 	assert.Len(t, req.Documents, 2)
@@ -164,7 +189,7 @@ paths: {}`,
 		"docs/readme.md": "# Hello",
 	}
 
-	req := BuildIngestRequest("owner/repo", "sha", files, nil, false)
+	req := BuildIngestRequest(nil, "owner/repo", "sha", files, nil, false)
 
 	assert.Len(t, req.Documents, 2)
 
@@ -183,7 +208,7 @@ func TestBuildIngestRequest_SkipsNonOpenAPIYAML(t *testing.T) {
 		"docker-compose.yml": "version: '3'\nservices: {}",
 	}
 
-	req := BuildIngestRequest("owner/repo", "sha", files, nil, false)
+	req := BuildIngestRequest(nil, "owner/repo", "sha", files, nil, false)
 
 	// Only the markdown file should remain; all YAML/JSON without OpenAPI keys are skipped.
 	assert.Len(t, req.Documents, 1)
@@ -191,6 +216,27 @@ func TestBuildIngestRequest_SkipsNonOpenAPIYAML(t *testing.T) {
 	assert.Equal(t, core.ContentTypeMarkdown, req.Documents[0].ContentType)
 }
 
+func TestBuildIngestRequest_EncodesBinaryDocuments(t *testing.T) {
+	types := append(core.DefaultContentTypes(), core.ContentTypeInfo{Name: core.ContentTypePDF, Extensions: []string{".pdf"}, Binary: true})
+	files := map[string]string{
+		"guide.md":   "# Guide",
+		"design.pdf": "%PDF-1.7\n\xff\x00",
+	}
+
+	req := BuildIngestRequest(types, "owner/repo", "sha", files, nil, false)
+
+	require.Len(t, req.Documents, 2)
+	assert.Equal(t, core.IngestDocument{
+		Path:        "design.pdf",
+		Content:     base64.StdEncoding.EncodeToString([]byte(files["design.pdf"])),
+		Action:      "upsert",
+		ContentType: core.ContentTypePDF,
+		Encoding:    core.ContentEncodingBase64,
+	}, req.Documents[0])
+	assert.Equal(t, "# Guide", req.Documents[1].Content)
+	assert.Empty(t, req.Documents[1].Encoding)
+}
+
 func TestSendIngestRequest_Success(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, http.MethodPost, r.Method)
@@ -246,6 +292,10 @@ func TestSendIngestRequest_Non2xxStatus(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, resp)
 	assert.Contains(t, err.Error(), "server returned HTTP 401")
+
+	var srvErr *ServerError
+	require.ErrorAs(t, err, &srvErr)
+	assert.Equal(t, http.StatusUnauthorized, srvErr.StatusCode)
 }
 
 func TestSendIngestRequest_ServerDown(t *testing.T) {
@@ -257,6 +307,10 @@ func TestSendIngestRequest_ServerDown(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, resp)
 	assert.Contains(t, err.Error(), "HTTP request failed")
+
+	var srvErr *ServerError
+	require.ErrorAs(t, err, &srvErr)
+	assert.Zero(t, srvErr.StatusCode)
 }
 
 func TestSendIngestRequest_InvalidURL(t *testing.T) {
@@ -300,7 +354,7 @@ func TestSendIngestRequest_InvalidJSON(t *testing.T) {
 }
 
 func TestPublish_EndToEnd(t *testing.T) {
-	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	srv := newIngestOnlyServer(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
 
 		body, err := io.ReadAll(r.Body)
@@ -317,7 +371,7 @@ func TestPublish_EndToEnd(t *testing.T) {
 
 		w.Header().Set("Content-Type", "application/json")
 		require.NoError(t, json.NewEncoder(w).Encode(resp))
-	}))
+	})
 	defer srv.Close()
 
 	dir := t.TempDir()
@@ -347,10 +401,10 @@ func TestPublish_NoFiles(t *testing.T) {
 }
 
 func TestPublish_ServerError(t *testing.T) {
-	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+	srv := newIngestOnlyServer(func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
 		_, _ = w.Write([]byte("internal error"))
-	}))
+	})
 	defer srv.Close()
 
 	dir := t.TempDir()
@@ -450,7 +504,7 @@ info:
 paths: {}`,
 	}
 
-	assets, err := CollectAssets(dir, files)
+	assets, err := CollectAssets(nil, dir, files)
 	require.NoError(t, err)
 
 	// Should have 2 unique assets (deduplication).
@@ -466,7 +520,7 @@ func TestCollectAssets_SkipsTraversalOutsideRoot(t *testing.T) {
 		"guide.md": "![escaped](../../etc/passwd)",
 	}
 
-	assets, err := CollectAssets(dir, files)
+	assets, err := CollectAssets(nil, dir, files)
 	require.NoError(t, err)
 	assert.Empty(t, assets)
 }
@@ -486,7 +540,7 @@ func TestCollectAssets_AllowsDoubleDotDirName(t *testing.T) {
 		"guide.md": "![logo](..images/logo.png)",
 	}
 
-	assets, err := CollectAssets(dir, files)
+	assets, err := CollectAssets(nil, dir, files)
 	require.NoError(t, err)
 	assert.Len(t, assets, 1)
 	assert.Equal(t, []byte("png"), assets["..images/logo.png"])
@@ -499,7 +553,7 @@ func TestCollectAssets_SkipsMissingFiles(t *testing.T) {
 		"guide.md": "![missing](nonexistent.png)",
 	}
 
-	assets, err := CollectAssets(dir, files)
+	assets, err := CollectAssets(nil, dir, files)
 	require.NoError(t, err)
 	assert.Empty(t, assets)
 }
@@ -511,7 +565,7 @@ func TestCollectAssets_NoMarkdownFiles(t *testing.T) {
 		"config.yaml": "name: test",
 	}
 
-	assets, err := CollectAssets(dir, files)
+	assets, err := CollectAssets(nil, dir, files)
 	require.NoError(t, err)
 	assert.Empty(t, assets)
 }
@@ -525,7 +579,7 @@ func TestBuildIngestRequest_WithAssets(t *testing.T) {
 		"images/arch.png": {0x89, 0x50, 0x4E, 0x47},
 	}
 
-	req := BuildIngestRequest("owner/repo", "sha", files, assets, true)
+	req := BuildIngestRequest(nil, "owner/repo", "sha", files, assets, true)
 
 	assert.Equal(t, "owner/repo", req.Repo)
 	assert.Equal(t, "sha", req.CommitSHA)
@@ -547,7 +601,7 @@ func TestBuildIngestRequest_NilAssets(t *testing.T) {
 		"docs/readme.md": "# Hello",
 	}
 
-	req := BuildIngestRequest("owner/repo", "sha", files, nil, false)
+	req := BuildIngestRequest(nil, "owner/repo", "sha", files, nil, false)
 
 	assert.Len(t, req.Documents, 1)
 	require.NotNil(t, req.Assets)
@@ -565,7 +619,7 @@ func TestBuildIngestRequest_AssetsSorted(t *testing.T) {
 		"images/b.png": []byte("b"),
 	}
 
-	req := BuildIngestRequest("owner/repo", "sha", files, assets, false)
+	req := BuildIngestRequest(nil, "owner/repo", "sha", files, assets, false)
 
 	require.NotNil(t, req.Assets)
 	require.Len(t, *req.Assets, 3)
@@ -608,7 +662,7 @@ func TestCollectAssets_FragmentAndQueryStrippedBeforeFileLookup(t *testing.T) {
 		"guide.md": "![icon](sprite.svg#icon)\n\n![raw](img.png?raw=1)",
 	}
 
-	assets, err := CollectAssets(dir, files)
+	assets, err := CollectAssets(nil, dir, files)
 	require.NoError(t, err)
 
 	// Both assets should be collected; the fragment/query must not be part of
@@ -617,3 +671,92 @@ func TestCollectAssets_FragmentAndQueryStrippedBeforeFileLookup(t *testing.T) {
 	assert.Equal(t, []byte("<svg/>"), assets["sprite.svg"])
 	assert.Equal(t, []byte("png-data"), assets["img.png"])
 }
+
+// newIngestOnlyServer starts a test server serving h as the ingest endpoint. Other endpoints,
+// such as the content types, are not found, as on servers that predate them.
+func newIngestOnlyServer(h http.HandlerFunc) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.Handle("POST /api/v1/docs", h)
+
+	return httptest.NewServer(mux)
+}
+
+func TestPublisher_ContentTypes(t *testing.T) {
+	graphql := core.ContentTypeInfo{Name: "graphql", DisplayName: "GraphQL", Extensions: []string{".graphql"}}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/content-types", r.URL.Path)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]any{"content_types": []core.ContentTypeInfo{graphql}}))
+	}))
+	defer srv.Close()
+
+	types, err := New(srv.URL+"/", "secret").ContentTypes(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, []core.ContentTypeInfo{graphql}, types)
+
+	legacy := newIngestOnlyServer(func(http.ResponseWriter, *http.Request) {})
+	defer legacy.Close()
+
+	types, err = New(legacy.URL, "secret").ContentTypes(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, core.DefaultContentTypes(), types, "servers without the endpoint process the default content types")
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	}))
+	defer failing.Close()
+
+	_, err = New(failing.URL, "secret").ContentTypes(t.Context())
+
+	var srvErr *ServerError
+
+	require.ErrorAs(t, err, &srvErr)
+	assert.Equal(t, http.StatusUnauthorized, srvErr.StatusCode)
+}
+
+func TestDefaultFilePatternFor(t *testing.T) {
+	types := append(core.DefaultContentTypes(), core.ContentTypeInfo{Name: "graphql", Extensions: []string{".graphql", ".graphqls"}})
+
+	assert.Equal(t, "{README.md,CHANGELOG.md,docs/**/*.{md,markdown,yaml,yml,json,graphql,graphqls}}", DefaultFilePatternFor(types))
+	assert.Equal(t, DefaultFilePattern, DefaultFilePatternFor(nil))
+}
+
+func TestPublish_ServerContentTypes(t *testing.T) {
+	types := []core.ContentTypeInfo{
+		{Name: core.ContentTypeMarkdown, Extensions: []string{".md"}, Fallback: true},
+		{Name: "graphql", Extensions: []string{".graphql"}},
+	}
+
+	var ingestReq core.IngestRequest
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/content-types", func(w http.ResponseWriter, _ *http.Request) {
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]any{"content_types": types}))
+	})
+	mux.HandleFunc("POST /api/v1/docs", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&ingestReq))
+		require.NoError(t, json.NewEncoder(w).Encode(core.IngestResponse{Indexed: len(ingestReq.Documents)}))
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	dir := t.TempDir()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "docs"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Readme"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docs", "schema.graphql"), []byte("type Query { ping: String }"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docs", "openapi.yaml"), []byte("openapi: 3.0.3"), 0o600))
+
+	resp, err := New(srv.URL, "secret").Publish(t.Context(), dir, "", "owner/repo", "sha", true)
+	require.NoError(t, err)
+	assert.Equal(t, 2, resp.Indexed)
+	assert.Empty(t, resp.Skipped, "files of extensions the server does not process are not collected")
+
+	require.Len(t, ingestReq.Documents, 2)
+	assert.Equal(t, "README.md", ingestReq.Documents[0].Path)
+	assert.Equal(t, core.ContentType("graphql"), ingestReq.Documents[1].ContentType)
+}
@@ -0,0 +1,215 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+// Target is an Omnidex instance that documentation is published to, such as an internal
+// portal and a partner portal serving different audiences.
+type Target struct {
+	Name   string
+	URL    string
+	APIKey string
+	// SigningKey, when set, signs the payloads sent to this target; see Publisher.WithSigningKey.
+	SigningKey string
+	// Include optionally restricts the files published to this target to those matching
+	// the glob pattern, relative to the docs path. Empty publishes every collected file.
+	Include string
+}
+
+// TargetResult is the outcome of publishing to a single target.
+type TargetResult struct {
+	Err    error
+	Result *Result
+	Target string
+	URL    string
+	Files  int
+}
+
+// PublishTargets collects documentation files from docsPath once and publishes them to
+// every target, running at most concurrency uploads at a time (one per target when
+// concurrency is not positive). Each target receives only the files matching its include
+// filter and, when fullSync is true, treats them as its complete document set. The content
+// types each target processes decide which of its files are uploaded and as which type; an
+// empty filePattern selects the default file pattern for the content types of all targets.
+// Results are returned in target order. A failure to publish to one target does not stop
+// the others; the returned error is non-nil if files could not be collected or any
+// target failed.
+func PublishTargets(
+	ctx context.Context,
+	targets []Target,
+	concurrency int,
+	docsPath, filePattern, repo, commitSHA string,
+	fullSync bool,
+) ([]TargetResult, error) {
+	// Check the local input before contacting the targets for the default pattern.
+	if _, err := os.Stat(docsPath); err != nil {
+		return nil, fmt.Errorf("failed to collect files: %w", err)
+	}
+
+	results := make([]TargetResult, len(targets))
+	types := make([][]core.ContentTypeInfo, len(targets))
+
+	if filePattern == "" {
+		var all []core.ContentTypeInfo
+
+		for i, target := range targets {
+			t, err := newTargetPublisher(target).ContentTypes(ctx)
+			if err != nil {
+				results[i] = TargetResult{Target: target.Name, URL: target.URL, Err: contentTypesError(target, err)}
+				continue
+			}
+
+			types[i] = t
+			all = append(all, t...)
+		}
+
+		filePattern = DefaultFilePatternFor(all)
+	}
+
+	files, err := CollectFiles(docsPath, filePattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect files: %w", err)
+	}
+
+	slog.Info("Collected documentation files", "count", len(files), "targets", len(targets))
+
+	if concurrency <= 0 || concurrency > len(targets) {
+		concurrency = len(targets)
+	}
+
+	sem := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+
+	for i, target := range targets {
+		if results[i].Err != nil {
+			continue
+		}
+
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i] = publishTarget(ctx, target, types[i], docsPath, files, repo, commitSHA, fullSync)
+		})
+	}
+
+	wg.Wait()
+
+	failed := 0
+
+	for _, res := range results {
+		if res.Err != nil {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return results, fmt.Errorf("failed to publish to %d of %d targets", failed, len(targets))
+	}
+
+	return results, nil
+}
+
+// newTargetPublisher returns the Publisher sending requests to the target.
+func newTargetPublisher(target Target) *Publisher {
+	return New(target.URL, target.APIKey).WithSigningKey(target.SigningKey)
+}
+
+// contentTypesError wraps the error getting the content types the target processes.
+func contentTypesError(target Target, err error) error {
+	return fmt.Errorf("failed to get content types of %s: %w", target.Name, err)
+}
+
+// publishTarget publishes the files selected by the target's include filter, detecting
+// their content types among types, the types the target processes. They are requested
+// from the target when types is nil.
+func publishTarget(
+	ctx context.Context,
+	target Target,
+	types []core.ContentTypeInfo,
+	docsPath string,
+	files map[string]string,
+	repo, commitSHA string,
+	fullSync bool,
+) TargetResult {
+	res := TargetResult{Target: target.Name, URL: target.URL}
+
+	selected, err := filterFiles(files, target.Include)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+
+	res.Files = len(selected)
+
+	if len(selected) == 0 {
+		slog.Warn("No files matched the target include filter", "target", target.Name, "include", target.Include)
+
+		res.Result = &Result{IngestResponse: &core.IngestResponse{}}
+
+		return res
+	}
+
+	pub := newTargetPublisher(target)
+
+	if types == nil {
+		if types, err = pub.ContentTypes(ctx); err != nil {
+			res.Err = contentTypesError(target, err)
+			return res
+		}
+	}
+
+	assets, err := CollectAssets(types, docsPath, selected)
+	if err != nil {
+		res.Err = fmt.Errorf("failed to collect assets: %w", err)
+		return res
+	}
+
+	req := BuildIngestRequest(types, repo, commitSHA, selected, assets, fullSync)
+	req.Provenance = ActionsProvenance()
+
+	resp, err := pub.SendIngestRequest(ctx, &req)
+	if err != nil {
+		res.Err = fmt.Errorf("failed to publish documentation to %s: %w", target.Name, err)
+		return res
+	}
+
+	res.Result = &Result{IngestResponse: resp, Skipped: skippedFiles(selected, &req)}
+
+	return res
+}
+
+// filterFiles returns the files whose path matches the include glob pattern. An empty
+// pattern selects every file.
+func filterFiles(files map[string]string, include string) (map[string]string, error) {
+	if include == "" {
+		return files, nil
+	}
+
+	include = filepath.ToSlash(include)
+
+	selected := make(map[string]string)
+
+	for p, content := range files {
+		matched, err := doublestar.Match(include, p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid include pattern %q: %w", include, err)
+		}
+
+		if matched {
+			selected[p] = content
+		}
+	}
+
+	return selected, nil
+}
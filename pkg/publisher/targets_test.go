@@ -0,0 +1,137 @@
+package publisher
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newIngestServer returns a server that records the document paths of the ingest
+// request and responds with the number of documents indexed.
+func newIngestServer(t *testing.T, apiKey string, paths *[]string) *httptest.Server {
+	t.Helper()
+
+	srv := newIngestOnlyServer(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer "+apiKey, r.Header.Get("Authorization"))
+
+		var req core.IngestRequest
+		if !assert.NoError(t, json.NewDecoder(r.Body).Decode(&req)) {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+
+		for _, doc := range req.Documents {
+			*paths = append(*paths, doc.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(core.IngestResponse{Indexed: len(req.Documents)})
+	})
+
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestPublishTargets(t *testing.T) {
+	dir := t.TempDir()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "public"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "internal.md"), []byte("# Internal"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "public", "guide.md"), []byte("# Guide"), 0o600))
+
+	var internalPaths, partnerPaths []string
+
+	internal := newIngestServer(t, "internal-key", &internalPaths)
+	partner := newIngestServer(t, "partner-key", &partnerPaths)
+
+	failing := newIngestOnlyServer(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	})
+	t.Cleanup(failing.Close)
+
+	targets := []Target{
+		{Name: "internal", URL: internal.URL, APIKey: "internal-key"},
+		{Name: "partner", URL: partner.URL, APIKey: "partner-key", Include: "public/**"},
+		{Name: "broken", URL: failing.URL, APIKey: "key"},
+		{Name: "empty", URL: failing.URL, APIKey: "key", Include: "none/**"},
+	}
+
+	results, err := PublishTargets(t.Context(), targets, 2, dir, "**/*.md", "owner/repo", "abc", true)
+	require.EqualError(t, err, "failed to publish to 1 of 4 targets")
+	require.Len(t, results, 4)
+
+	assert.Equal(t, "internal", results[0].Target)
+	require.NoError(t, results[0].Err)
+	assert.Equal(t, 2, results[0].Files)
+	assert.Equal(t, 2, results[0].Result.Indexed)
+
+	sort.Strings(internalPaths)
+	assert.Equal(t, []string{"internal.md", "public/guide.md"}, internalPaths)
+
+	require.NoError(t, results[1].Err)
+	assert.Equal(t, 1, results[1].Result.Indexed)
+	assert.Equal(t, []string{"public/guide.md"}, partnerPaths)
+
+	assert.ErrorContains(t, results[2].Err, "failed to publish documentation to broken")
+
+	require.NoError(t, results[3].Err)
+	assert.Equal(t, 0, results[3].Files)
+	assert.Equal(t, &Result{IngestResponse: &core.IngestResponse{}}, results[3].Result)
+}
+
+func TestPublishTargets_InvalidInclude(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "doc.md"), []byte("# Doc"), 0o600))
+
+	results, err := PublishTargets(t.Context(), []Target{{Name: "bad", Include: "[unclosed"}}, 0, dir, "**/*.md", "owner/repo", "", true)
+	require.Error(t, err)
+	assert.ErrorContains(t, results[0].Err, "invalid include pattern")
+}
+
+func TestPublishTargets_CollectError(t *testing.T) {
+	results, err := PublishTargets(t.Context(), []Target{{Name: "a"}}, 1, "/nonexistent/path/12345", "**/*.md", "owner/repo", "", true)
+	assert.ErrorContains(t, err, "failed to collect files")
+	assert.Nil(t, results)
+}
+
+func TestPublishTargets_DefaultPattern(t *testing.T) {
+	dir := t.TempDir()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "docs"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Readme"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docs", "guide.md"), []byte("# Guide"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docs", "notes.txt"), []byte("Notes"), 0o600))
+
+	var paths []string
+
+	legacy := newIngestServer(t, "key", &paths)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	t.Cleanup(failing.Close)
+
+	targets := []Target{
+		{Name: "legacy", URL: legacy.URL, APIKey: "key"},
+		{Name: "broken", URL: failing.URL, APIKey: "key"},
+	}
+
+	results, err := PublishTargets(t.Context(), targets, 0, dir, "", "owner/repo", "", true)
+	require.EqualError(t, err, "failed to publish to 1 of 2 targets")
+
+	require.NoError(t, results[0].Err)
+	sort.Strings(paths)
+	assert.Equal(t, []string{"README.md", "docs/guide.md"}, paths, "the default pattern covers the extensions of the targets' content types")
+
+	assert.Equal(t, "broken", results[1].Target)
+	assert.ErrorContains(t, results[1].Err, "failed to get content types of broken")
+}
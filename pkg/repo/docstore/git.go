@@ -0,0 +1,474 @@
+package docstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	stdpath "path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+// gitAuthor is the author of the commits recording changes to the store.
+var gitAuthor = object.Signature{Name: "Omnidex", Email: "omnidex@localhost"}
+
+// GitStore is a filesystem-based document store that keeps the history of every document
+// and asset in a git repository initialized in the storage directory. Each Save and Delete
+// is committed, so earlier versions of a document can be read with GetAt until Prune drops
+// them. Changes to the redirects, highlights and site banner are committed too. API keys are stored as by Store
+// but are not committed, so the hashes of revoked keys do not stay in the history.
+type GitStore struct {
+	*Store
+	git *git.Repository
+	now func() time.Time
+	// mu serializes changes with their commits, so each commit records exactly one change.
+	mu sync.RWMutex
+}
+
+// NewGit creates a git-backed document store rooted at basePath, initializing a git
+// repository there if it does not hold one yet. Documents already in the directory are
+// committed with their next change.
+func NewGit(basePath string) (*GitStore, error) {
+	s, err := New(basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := git.PlainOpen(s.basePath)
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		repo, err = git.PlainInit(s.basePath, false)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	return &GitStore{Store: s, git: repo, now: time.Now}, nil
+}
+
+// gitPath returns the slash-separated path, relative to the storage directory, of a file
+// of a repository.
+func gitPath(repo, dir, path string) string {
+	return stdpath.Join(filepath.ToSlash(repo), dir, filepath.ToSlash(path))
+}
+
+// Save persists a document and commits it. The commit message records the document's
+// CommitSHA, by which GetAt finds the version later.
+func (s *GitStore) Save(ctx context.Context, doc core.Document) error { //nolint:gocritic // Document is passed by value for immutability
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.Store.Save(ctx, doc); err != nil {
+		return err
+	}
+
+	docPath := gitPath(doc.Repo, docsDir, doc.Path)
+	msg := fmt.Sprintf("Save %s/%s\n\nCommit-SHA: %s\n", doc.Repo, doc.Path, doc.CommitSHA)
+
+	return s.record(msg, docPath, docPath+".meta.json", gitPath(doc.Repo, "", metaFileName))
+}
+
+// Delete removes a document and commits the removal.
+func (s *GitStore) Delete(ctx context.Context, repo, path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.Store.Delete(ctx, repo, path); err != nil {
+		return err
+	}
+
+	docPath := gitPath(repo, docsDir, path)
+
+	return s.record(fmt.Sprintf("Delete %s/%s\n", repo, path), docPath, docPath+".meta.json")
+}
+
+// SaveAsset writes a binary asset and commits it.
+func (s *GitStore) SaveAsset(ctx context.Context, repo, path string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.Store.SaveAsset(ctx, repo, path, data); err != nil {
+		return err
+	}
+
+	return s.record(fmt.Sprintf("Save asset %s/%s\n", repo, path), gitPath(repo, assetsDir, path))
+}
+
+// DeleteAsset removes a binary asset and commits the removal.
+func (s *GitStore) DeleteAsset(ctx context.Context, repo, path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.Store.DeleteAsset(ctx, repo, path); err != nil {
+		return err
+	}
+
+	return s.record(fmt.Sprintf("Delete asset %s/%s\n", repo, path), gitPath(repo, assetsDir, path))
+}
+
+// DeleteRepo removes a repository with its documents, assets and metadata, keeping its
+// sub-projects, and commits the removal. The history of the repository is kept.
+func (s *GitStore) DeleteRepo(ctx context.Context, repo string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.Store.DeleteRepo(ctx, repo); err != nil {
+		return err
+	}
+
+	idx, err := s.git.Storer.Index()
+	if err != nil {
+		return fmt.Errorf("failed to read git index: %w", err)
+	}
+
+	docsPrefix := gitPath(repo, docsDir, "") + "/"
+	assetsPrefix := gitPath(repo, assetsDir, "") + "/"
+	repoMetaPath := gitPath(repo, "", metaFileName)
+
+	var paths []string
+
+	for _, e := range idx.Entries {
+		if strings.HasPrefix(e.Name, docsPrefix) || strings.HasPrefix(e.Name, assetsPrefix) || e.Name == repoMetaPath {
+			paths = append(paths, e.Name)
+		}
+	}
+
+	return s.record(fmt.Sprintf("Delete repository %s\n", repo), paths...)
+}
+
+// SaveRedirects replaces the redirects and commits them.
+func (s *GitStore) SaveRedirects(ctx context.Context, redirects map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.Store.SaveRedirects(ctx, redirects); err != nil {
+		return err
+	}
+
+	return s.record("Save redirects\n", redirectsFileName)
+}
+
+// SaveHighlights replaces the highlights of every repository and commits them.
+func (s *GitStore) SaveHighlights(ctx context.Context, highlights map[string]core.RepoHighlights) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.Store.SaveHighlights(ctx, highlights); err != nil {
+		return err
+	}
+
+	return s.record("Save highlights\n", highlightsFileName)
+}
+
+// SaveSiteBanner replaces the site banner and commits it; a nil banner clears it.
+func (s *GitStore) SaveSiteBanner(ctx context.Context, banner *core.SiteBanner) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.Store.SaveSiteBanner(ctx, banner); err != nil {
+		return err
+	}
+
+	return s.record("Save site banner\n", bannerFileName)
+}
+
+// SaveAPIKeys replaces the managed API keys. Unlike other changes it is not committed:
+// the history is kept until pruned, and the hashes of revoked keys must not outlive them.
+func (s *GitStore) SaveAPIKeys(ctx context.Context, keys []core.APIKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.Store.SaveAPIKeys(ctx, keys)
+}
+
+// Prune drops the history committed before t and returns the number of commits dropped.
+// The newest commit made before t becomes the first commit of the history, so the versions
+// current at t stay readable with GetAt along with every version committed since. The
+// commits kept are rewritten onto it and get new hashes, which GetAt does not depend on:
+// it finds versions by the CommitSHA they were published from.
+func (s *GitStore) Prune(ctx context.Context, t time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	head, err := s.git.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	c, err := s.git.CommitObject(head.Hash())
+	if err != nil {
+		return 0, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	// The history is linear, as every change is committed on top of the previous one.
+	var kept []*object.Commit
+
+	for !c.Committer.When.Before(t) {
+		if c.NumParents() == 0 {
+			return 0, nil
+		}
+
+		kept = append(kept, c)
+
+		if c, err = c.Parent(0); err != nil {
+			return 0, fmt.Errorf("failed to read history: %w", err)
+		}
+	}
+
+	dropped, err := countAncestors(ctx, c)
+	if err != nil || dropped == 0 {
+		return 0, err
+	}
+
+	msg := fmt.Sprintf("Prune history before %s\n", t.UTC().Format(time.RFC3339))
+
+	parent, err := s.rewrite(c, plumbing.ZeroHash, msg)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := len(kept) - 1; i >= 0; i-- {
+		if parent, err = s.rewrite(kept[i], parent, kept[i].Message); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := s.git.Storer.SetReference(plumbing.NewHashReference(head.Name(), parent)); err != nil {
+		return 0, fmt.Errorf("failed to update history: %w", err)
+	}
+
+	// Delete the objects only the dropped commits referenced. Objects packed by git gc
+	// outside Omnidex are left for the next git gc to remove.
+	if err := s.git.Prune(git.PruneOptions{Handler: s.git.DeleteObject}); err != nil {
+		return 0, fmt.Errorf("failed to delete pruned objects: %w", err)
+	}
+
+	return dropped, nil
+}
+
+// countAncestors returns the number of commits before c in its linear history.
+func countAncestors(ctx context.Context, c *object.Commit) (int, error) {
+	n := 0
+
+	for c.NumParents() > 0 {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+
+		parent, err := c.Parent(0)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read history: %w", err)
+		}
+
+		c = parent
+		n++
+	}
+
+	return n, nil
+}
+
+// rewrite commits the tree of c again with msg, on top of parent or as a first commit when
+// parent is zero, and returns the hash of the new commit.
+func (s *GitStore) rewrite(c *object.Commit, parent plumbing.Hash, msg string) (plumbing.Hash, error) {
+	rewritten := &object.Commit{
+		Author:    c.Author,
+		Committer: c.Committer,
+		Message:   msg,
+		TreeHash:  c.TreeHash,
+	}
+
+	if !parent.IsZero() {
+		rewritten.ParentHashes = []plumbing.Hash{parent}
+	}
+
+	obj := s.git.Storer.NewEncodedObject()
+	if err := rewritten.Encode(obj); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to encode commit: %w", err)
+	}
+
+	hash, err := s.git.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to write commit: %w", err)
+	}
+
+	return hash, nil
+}
+
+// record stages the current state of the given files, as paths relative to the storage
+// directory, and commits them with msg. Files that exist are added and missing ones are
+// removed. Nothing is committed when the files are unchanged.
+func (s *GitStore) record(msg string, paths ...string) error {
+	wt, err := s.git.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to open git worktree: %w", err)
+	}
+
+	for _, path := range paths {
+		if _, err := os.Lstat(filepath.Join(s.basePath, filepath.FromSlash(path))); err == nil {
+			if err := wt.AddWithOptions(&git.AddOptions{Path: path, SkipStatus: true}); err != nil {
+				return fmt.Errorf("failed to stage %s: %w", path, err)
+			}
+
+			continue
+		}
+
+		if err := s.unstage(path); err != nil {
+			return err
+		}
+	}
+
+	author := gitAuthor
+	author.When = s.now()
+
+	_, err = wt.Commit(msg, &git.CommitOptions{Author: &author})
+	if err != nil && !errors.Is(err, git.ErrEmptyCommit) {
+		return fmt.Errorf("failed to commit change: %w", err)
+	}
+
+	return nil
+}
+
+// unstage removes a deleted file from the git index. Files that were never committed are
+// ignored.
+func (s *GitStore) unstage(path string) error {
+	idx, err := s.git.Storer.Index()
+	if err != nil {
+		return fmt.Errorf("failed to read git index: %w", err)
+	}
+
+	if _, err := idx.Remove(path); err != nil {
+		if errors.Is(err, index.ErrEntryNotFound) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to unstage %s: %w", path, err)
+	}
+
+	if err := s.git.Storer.SetIndex(idx); err != nil {
+		return fmt.Errorf("failed to write git index: %w", err)
+	}
+
+	return nil
+}
+
+// GetAt returns the version of a document that was saved with the given CommitSHA. If the
+// document was saved several times with the same CommitSHA, the latest of those versions is
+// returned. It returns ErrNotFound if no such version was committed.
+func (s *GitStore) GetAt(ctx context.Context, repo, path, sha string) (core.Document, error) {
+	if err := s.validatePath(repo, docsDir, path); err != nil {
+		return core.Document{}, err
+	}
+
+	if sha == "" {
+		return core.Document{}, fmt.Errorf("%w: commit sha must not be empty", ErrInvalidPath)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	docPath := gitPath(repo, docsDir, path)
+	metaPath := docPath + ".meta.json"
+
+	commits, err := s.git.Log(&git.LogOptions{FileName: &metaPath})
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return core.Document{}, fmt.Errorf("%w: %s/%s at %s", ErrNotFound, repo, path, sha)
+	}
+
+	if err != nil {
+		return core.Document{}, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	defer commits.Close()
+
+	var (
+		doc   core.Document
+		found bool
+	)
+
+	err = commits.ForEach(func(c *object.Commit) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		meta, err := readCommittedMeta(c, metaPath)
+		if errors.Is(err, object.ErrFileNotFound) || (err == nil && meta.CommitSHA != sha) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		content, err := readCommittedFile(c, docPath)
+		if err != nil {
+			return err
+		}
+
+		doc, found = newDocument(repo, path, content, meta), true
+
+		return storer.ErrStop
+	})
+	if err != nil {
+		return core.Document{}, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	if !found {
+		return core.Document{}, fmt.Errorf("%w: %s/%s at %s", ErrNotFound, repo, path, sha)
+	}
+
+	return doc, nil
+}
+
+// readCommittedMeta decodes the document metadata stored at path in commit c.
+func readCommittedMeta(c *object.Commit, path string) (*docMeta, error) {
+	data, err := readCommittedFile(c, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var meta docMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal document metadata: %w", err)
+	}
+
+	return &meta, nil
+}
+
+// readCommittedFile returns the contents of the file at path in commit c.
+func readCommittedFile(c *object.Commit, path string) ([]byte, error) {
+	f, err := c.File(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := f.Reader()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,240 @@
+package docstore
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func commitMessages(t *testing.T, s *GitStore) []string {
+	t.Helper()
+
+	commits, err := s.git.Log(&git.LogOptions{})
+	require.NoError(t, err)
+
+	var msgs []string
+
+	require.NoError(t, commits.ForEach(func(c *object.Commit) error {
+		msgs = append(msgs, c.Message)
+		return nil
+	}))
+
+	return msgs
+}
+
+func TestGitStore_GetAt(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, err := NewGit(tmpDir)
+	require.NoError(t, err)
+
+	for i, sha := range []string{"aaa111", "bbb222", "ccc333"} {
+		doc := core.Document{
+			Repo:      "owner/repo",
+			Path:      "guides/setup.md",
+			Title:     "Setup",
+			Content:   "# Setup v" + strconv.Itoa(i+1),
+			CommitSHA: sha,
+			UpdatedAt: time.Date(2026, 1, 1+i, 0, 0, 0, 0, time.UTC),
+		}
+		require.NoError(t, store.Save(t.Context(), doc))
+	}
+
+	doc, err := store.GetAt(t.Context(), "owner/repo", "guides/setup.md", "bbb222")
+	require.NoError(t, err)
+	assert.Equal(t, "# Setup v2", doc.Content)
+	assert.Equal(t, "bbb222", doc.CommitSHA)
+	assert.Equal(t, "owner/repo/guides/setup.md", doc.ID)
+	assert.Equal(t, core.ContentTypeMarkdown, doc.ContentType)
+	assert.True(t, doc.UpdatedAt.Equal(time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)))
+
+	current, err := store.Get(t.Context(), "owner/repo", "guides/setup.md")
+	require.NoError(t, err)
+	assert.Equal(t, "# Setup v3", current.Content)
+
+	_, err = store.GetAt(t.Context(), "owner/repo", "guides/setup.md", "unknown")
+	assert.ErrorIs(t, err, core.ErrNotFound)
+
+	_, err = store.GetAt(t.Context(), "owner/repo", "guides/other.md", "aaa111")
+	assert.ErrorIs(t, err, core.ErrNotFound)
+
+	_, err = store.GetAt(t.Context(), "owner/repo", "../../../../etc/passwd", "aaa111")
+	assert.ErrorIs(t, err, core.ErrInvalidPath)
+
+	_, err = store.GetAt(t.Context(), "owner/repo", "guides/setup.md", "")
+	assert.ErrorIs(t, err, core.ErrInvalidPath)
+}
+
+func TestGitStore_GetAtEmptyHistory(t *testing.T) {
+	store, err := NewGit(t.TempDir())
+	require.NoError(t, err)
+
+	_, err = store.GetAt(t.Context(), "owner/repo", "guide.md", "aaa111")
+	assert.ErrorIs(t, err, core.ErrNotFound)
+}
+
+func TestGitStore_DeleteKeepsHistory(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, err := NewGit(tmpDir)
+	require.NoError(t, err)
+
+	doc := core.Document{Repo: "owner/repo", Path: "guide.md", Title: "Guide", Content: "# Guide", CommitSHA: "aaa111", UpdatedAt: time.Now()}
+	require.NoError(t, store.Save(t.Context(), doc))
+	require.NoError(t, store.SaveAsset(t.Context(), "owner/repo", "img/a.png", []byte("png")))
+	require.NoError(t, store.Delete(t.Context(), "owner/repo", "guide.md"))
+	require.NoError(t, store.Delete(t.Context(), "owner/repo", "missing.md"))
+
+	_, err = store.Get(t.Context(), "owner/repo", "guide.md")
+	assert.ErrorIs(t, err, core.ErrNotFound)
+
+	old, err := store.GetAt(t.Context(), "owner/repo", "guide.md", "aaa111")
+	require.NoError(t, err)
+	assert.Equal(t, "# Guide", old.Content)
+
+	require.NoError(t, store.DeleteAsset(t.Context(), "owner/repo", "img/a.png"))
+
+	assert.Equal(t, []string{
+		"Delete asset owner/repo/img/a.png\n",
+		"Delete owner/repo/guide.md\n",
+		"Save asset owner/repo/img/a.png\n",
+		"Save owner/repo/guide.md\n\nCommit-SHA: aaa111\n",
+	}, commitMessages(t, store))
+}
+
+func TestGitStore_Prune(t *testing.T) {
+	store, err := NewGit(t.TempDir())
+	require.NoError(t, err)
+
+	n, err := store.Prune(t.Context(), time.Now())
+	require.NoError(t, err)
+	assert.Zero(t, n, "an empty history has nothing to prune")
+
+	day := func(d int) time.Time { return time.Date(2026, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	for i, sha := range []string{"aaa111", "bbb222", "ccc333", "ddd444"} {
+		store.now = func() time.Time { return day(i + 1) }
+
+		doc := core.Document{Repo: "owner/repo", Path: "guide.md", Title: "Guide", Content: "# Guide v" + strconv.Itoa(i+1), CommitSHA: sha}
+		require.NoError(t, store.Save(t.Context(), doc))
+	}
+
+	n, err = store.Prune(t.Context(), day(1))
+	require.NoError(t, err)
+	assert.Zero(t, n, "no commit was made before the first one")
+
+	n, err = store.Prune(t.Context(), day(3).Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	assert.Equal(t, []string{
+		"Save owner/repo/guide.md\n\nCommit-SHA: ddd444\n",
+		"Prune history before 2026-01-03T01:00:00Z\n",
+	}, commitMessages(t, store))
+
+	for _, sha := range []string{"aaa111", "bbb222"} {
+		_, err = store.GetAt(t.Context(), "owner/repo", "guide.md", sha)
+		assert.ErrorIs(t, err, core.ErrNotFound, sha)
+	}
+
+	for i, sha := range []string{"ccc333", "ddd444"} {
+		doc, err := store.GetAt(t.Context(), "owner/repo", "guide.md", sha)
+		require.NoError(t, err, sha)
+		assert.Equal(t, "# Guide v"+strconv.Itoa(i+3), doc.Content)
+	}
+
+	// The contents only the dropped commits referenced are deleted.
+	_, err = store.git.Storer.EncodedObject(plumbing.BlobObject, plumbing.ComputeHash(plumbing.BlobObject, []byte("# Guide v1")))
+	assert.ErrorIs(t, err, plumbing.ErrObjectNotFound)
+
+	// Changes keep being committed on top of the pruned history.
+	require.NoError(t, store.Delete(t.Context(), "owner/repo", "guide.md"))
+	assert.Len(t, commitMessages(t, store), 3)
+}
+
+func TestGitStore_DeleteRepo(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, err := NewGit(tmpDir)
+	require.NoError(t, err)
+
+	for _, repo := range []string{"owner/mono", "owner/mono/service"} {
+		doc := core.Document{Repo: repo, Path: "guide.md", Title: "Guide", Content: "# Guide", CommitSHA: "aaa111", UpdatedAt: time.Now()}
+		require.NoError(t, store.Save(t.Context(), doc))
+		require.NoError(t, store.SaveAsset(t.Context(), repo, "img.png", []byte("png")))
+	}
+
+	require.NoError(t, store.DeleteRepo(t.Context(), "owner/mono"))
+
+	idx, err := store.git.Storer.Index()
+	require.NoError(t, err)
+
+	var tracked []string
+	for _, e := range idx.Entries {
+		tracked = append(tracked, e.Name)
+	}
+
+	assert.ElementsMatch(t, []string{
+		"owner/mono/service/docs/guide.md",
+		"owner/mono/service/docs/guide.md.meta.json",
+		"owner/mono/service/assets/img.png",
+		"owner/mono/service/meta.json",
+	}, tracked)
+
+	_, err = store.GetAt(t.Context(), "owner/mono", "guide.md", "aaa111")
+	assert.NoError(t, err, "history of a deleted repository is kept")
+
+	repos, err := store.ListRepos(t.Context())
+	require.NoError(t, err)
+	require.Len(t, repos, 1)
+	assert.Equal(t, "owner/mono/service", repos[0].Name)
+}
+
+func TestNewGit_ReopensRepository(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, err := NewGit(tmpDir)
+	require.NoError(t, err)
+
+	doc := core.Document{Repo: "owner/repo", Path: "guide.md", Title: "Guide", Content: "# Guide", CommitSHA: "aaa111", UpdatedAt: time.Now()}
+	require.NoError(t, store.Save(t.Context(), doc))
+
+	store, err = NewGit(tmpDir)
+	require.NoError(t, err)
+
+	_, err = store.GetAt(t.Context(), "owner/repo", "guide.md", "aaa111")
+	assert.NoError(t, err)
+}
+
+func TestGitStore_CommitsSiteState(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, err := NewGit(tmpDir)
+	require.NoError(t, err)
+
+	require.NoError(t, store.SaveRedirects(t.Context(), map[string]string{"owner/old": "owner/new"}))
+	require.NoError(t, store.SaveHighlights(t.Context(), map[string]core.RepoHighlights{"owner/repo": {}}))
+	require.NoError(t, store.SaveSiteBanner(t.Context(), &core.SiteBanner{Message: "Maintenance tonight"}))
+	require.NoError(t, store.SaveSiteBanner(t.Context(), nil))
+	require.NoError(t, store.SaveAPIKeys(t.Context(), []core.APIKey{{ID: "key1", Name: "ci", Hash: "abc"}}))
+
+	assert.Equal(t, []string{
+		"Save site banner\n",
+		"Save site banner\n",
+		"Save highlights\n",
+		"Save redirects\n",
+	}, commitMessages(t, store))
+
+	idx, err := store.git.Storer.Index()
+	require.NoError(t, err)
+
+	for _, e := range idx.Entries {
+		assert.NotEqual(t, apiKeysFileName, e.Name, "api keys must not be committed")
+	}
+
+	keys, err := store.GetAPIKeys(t.Context())
+	require.NoError(t, err)
+	assert.Len(t, keys, 1)
+}
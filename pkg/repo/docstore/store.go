@@ -4,7 +4,9 @@ package docstore
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"sort"
@@ -16,9 +18,13 @@ import (
 )
 
 const (
-	metaFileName = "meta.json"
-	docsDir      = "docs"
-	assetsDir    = "assets"
+	metaFileName       = "meta.json"
+	redirectsFileName  = "redirects.json"
+	apiKeysFileName    = "api_keys.json"
+	highlightsFileName = "highlights.json"
+	bannerFileName     = "banner.json"
+	docsDir            = "docs"
+	assetsDir          = "assets"
 )
 
 // ErrNotFound is an alias for core.ErrNotFound for backward compatibility.
@@ -37,17 +43,30 @@ type repoMeta struct {
 
 // docMeta holds metadata about a single document stored on disk.
 type docMeta struct {
-	UpdatedAt   time.Time `json:"updated_at"`
-	Title       string    `json:"title"`
-	CommitSHA   string    `json:"commit_sha"`
-	ContentType string    `json:"content_type,omitempty"` // defaults to "markdown" when empty
+	UpdatedAt   time.Time        `json:"updated_at"`
+	Provenance  *core.Provenance `json:"provenance,omitempty"`
+	Title       string           `json:"title"`
+	Summary     string           `json:"summary,omitempty"`
+	CommitSHA   string           `json:"commit_sha"`
+	ContentType string           `json:"content_type,omitempty"` // defaults to "markdown" when empty
+	// Anchors is null for documents saved without an anchor map, and empty for documents
+	// without headings.
+	Anchors core.AnchorMap                `json:"anchors"`
+	Text    *core.DocumentText            `json:"text,omitempty"`
+	Images  map[string]core.ImageVariants `json:"images,omitempty"`
+	Tags    []string                      `json:"tags,omitempty"`
+	Order   int                           `json:"order,omitempty"`
+	Home    bool                          `json:"home,omitempty"`
+	Draft   bool                          `json:"draft,omitempty"`
 }
 
 // Store implements filesystem-based document storage.
 // Documents are stored in a directory tree: {basePath}/{owner}/{repo}/docs/{path}.
+// Monorepo sub-projects are stored in the same layout under {basePath}/{owner}/{repo}/{project}.
 type Store struct {
-	basePath string
-	mu       sync.RWMutex
+	basePath        string
+	mu              sync.RWMutex
+	caseInsensitive bool
 }
 
 // New creates a new filesystem-based document store rooted at basePath.
@@ -61,7 +80,43 @@ func New(basePath string) (*Store, error) {
 		return nil, fmt.Errorf("failed to create storage directory: %w", err)
 	}
 
-	return &Store{basePath: absBase}, nil
+	caseInsensitive, err := isCaseInsensitive(absBase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to probe storage filesystem: %w", err)
+	}
+
+	return &Store{basePath: absBase, caseInsensitive: caseInsensitive}, nil
+}
+
+// isCaseInsensitive reports whether the filesystem holding dir treats file names that
+// differ only in case as the same file, as the default filesystems of macOS and Windows do.
+func isCaseInsensitive(dir string) (bool, error) {
+	f, err := os.CreateTemp(dir, ".case-probe-")
+	if err != nil {
+		return false, err
+	}
+
+	name := f.Name()
+
+	_ = f.Close()
+	defer os.Remove(name)
+
+	_, err = os.Stat(filepath.Join(dir, strings.ToUpper(filepath.Base(name))))
+
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, fs.ErrNotExist):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// CaseInsensitive reports whether documents are stored on a case-insensitive filesystem,
+// where paths that differ only in case name the same file.
+func (s *Store) CaseInsensitive() bool {
+	return s.caseInsensitive
 }
 
 // validatePath ensures the given segments, when joined to the base path,
@@ -107,9 +162,18 @@ func (s *Store) Save(_ context.Context, doc core.Document) error { //nolint:gocr
 	// Write document metadata alongside the content.
 	meta := docMeta{
 		Title:       doc.Title,
+		Summary:     doc.Summary,
 		CommitSHA:   doc.CommitSHA,
+		Provenance:  doc.Provenance,
 		UpdatedAt:   doc.UpdatedAt,
 		ContentType: string(doc.ContentType),
+		Tags:        doc.Tags,
+		Order:       doc.Order,
+		Home:        doc.Home,
+		Draft:       doc.Draft,
+		Anchors:     doc.Anchors,
+		Text:        doc.Text,
+		Images:      doc.Images,
 	}
 
 	metaPath := docPath + ".meta.json"
@@ -152,21 +216,35 @@ func (s *Store) Get(_ context.Context, repo, path string) (core.Document, error)
 		return core.Document{}, err
 	}
 
+	return newDocument(repo, path, content, meta), nil
+}
+
+// newDocument assembles a document from its stored content and metadata.
+func newDocument(repo, path string, content []byte, meta *docMeta) core.Document {
 	ct := core.ContentType(meta.ContentType)
 	if ct == "" {
 		ct = core.ContentTypeMarkdown
 	}
 
 	return core.Document{
-		ID:          repo + "/" + path,
+		ID:          core.NewDocumentID(repo, path).String(),
 		Repo:        repo,
 		Path:        path,
 		Title:       meta.Title,
+		Summary:     meta.Summary,
 		Content:     string(content),
 		CommitSHA:   meta.CommitSHA,
+		Provenance:  meta.Provenance,
 		UpdatedAt:   meta.UpdatedAt,
 		ContentType: ct,
-	}, nil
+		Tags:        meta.Tags,
+		Order:       meta.Order,
+		Home:        meta.Home,
+		Draft:       meta.Draft,
+		Anchors:     meta.Anchors,
+		Text:        meta.Text,
+		Images:      meta.Images,
+	}
 }
 
 // Delete removes a document from the filesystem.
@@ -221,6 +299,9 @@ func (s *Store) List(_ context.Context, repo string) ([]core.DocumentMeta, error
 			return fmt.Errorf("failed to compute relative path: %w", err)
 		}
 
+		// Document paths use forward slashes on every platform.
+		relPath = filepath.ToSlash(relPath)
+
 		meta, err := s.readDocMeta(path)
 		if err != nil {
 			// If no metadata file, use file info.
@@ -236,12 +317,17 @@ func (s *Store) List(_ context.Context, repo string) ([]core.DocumentMeta, error
 		}
 
 		docs = append(docs, core.DocumentMeta{
-			ID:          repo + "/" + relPath,
+			ID:          core.NewDocumentID(repo, relPath).String(),
 			Repo:        repo,
 			Path:        relPath,
 			Title:       meta.Title,
+			Summary:     meta.Summary,
 			UpdatedAt:   meta.UpdatedAt,
 			ContentType: ct,
+			Tags:        meta.Tags,
+			Order:       meta.Order,
+			Home:        meta.Home,
+			Draft:       meta.Draft,
 		})
 
 		return nil
@@ -288,21 +374,27 @@ func (s *Store) ListRepos(_ context.Context) ([]core.RepoInfo, error) {
 				continue
 			}
 
-			repoName := owner.Name() + "/" + repoEntry.Name()
-			repoDir := filepath.Join(s.basePath, repoName)
+			repoDir := filepath.Join(s.basePath, owner.Name(), repoEntry.Name())
+
+			if info, ok := s.repoInfo(repoDir); ok {
+				repos = append(repos, info)
+			}
 
-			meta, err := s.readRepoMeta(repoDir)
+			// Sub-projects of a monorepo are stored next to the repository's docs and assets.
+			projects, err := os.ReadDir(repoDir)
 			if err != nil {
 				continue
 			}
 
-			docCount := s.countDocs(filepath.Join(repoDir, docsDir))
+			for _, project := range projects {
+				if !project.IsDir() || project.Name() == docsDir || project.Name() == assetsDir {
+					continue
+				}
 
-			repos = append(repos, core.RepoInfo{
-				Name:        meta.Name,
-				DocCount:    docCount,
-				LastUpdated: meta.LastUpdated,
-			})
+				if info, ok := s.repoInfo(filepath.Join(repoDir, project.Name())); ok {
+					repos = append(repos, info)
+				}
+			}
 		}
 	}
 
@@ -313,6 +405,21 @@ func (s *Store) ListRepos(_ context.Context) ([]core.RepoInfo, error) {
 	return repos, nil
 }
 
+// repoInfo returns the metadata of the repository stored in repoDir. It returns false
+// when the directory holds no repository metadata.
+func (s *Store) repoInfo(repoDir string) (core.RepoInfo, bool) {
+	meta, err := s.readRepoMeta(repoDir)
+	if err != nil {
+		return core.RepoInfo{}, false
+	}
+
+	return core.RepoInfo{
+		Name:        meta.Name,
+		DocCount:    s.countDocs(filepath.Join(repoDir, docsDir)),
+		LastUpdated: meta.LastUpdated,
+	}, true
+}
+
 func (s *Store) updateRepoMeta(repoDir, repoName string, updatedAt time.Time) error {
 	meta := repoMeta{
 		Name:        repoName,
@@ -545,3 +652,216 @@ func (s *Store) ListAssets(_ context.Context, repo string) ([]string, error) {
 
 	return paths, nil
 }
+
+// DeleteRepo removes a repository with all of its documents, assets and metadata.
+// Sub-projects of the repository are kept.
+func (s *Store) DeleteRepo(_ context.Context, repo string) error {
+	if err := s.validatePath(repo); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	repoDir := filepath.Join(s.basePath, repo)
+
+	// Only the repository's own files are removed; sub-projects stored in the same
+	// directory are independent repositories.
+	for _, name := range []string{docsDir, assetsDir, metaFileName} {
+		if err := os.RemoveAll(filepath.Join(repoDir, name)); err != nil {
+			return fmt.Errorf("failed to delete repo: %w", err)
+		}
+	}
+
+	s.cleanEmptyDirs(repoDir, s.basePath)
+
+	return nil
+}
+
+// GetRedirects returns the recorded repository redirects, keyed by old identifier.
+// It returns an empty map when no redirects have been recorded.
+func (s *Store) GetRedirects(_ context.Context) (map[string]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, err := os.ReadFile(filepath.Join(s.basePath, redirectsFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil
+		}
+
+		return nil, fmt.Errorf("failed to read redirects: %w", err)
+	}
+
+	redirects := make(map[string]string)
+	if err := json.Unmarshal(data, &redirects); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal redirects: %w", err)
+	}
+
+	return redirects, nil
+}
+
+// SaveRedirects replaces the recorded repository redirects. They are stored in a file at
+// the root of the storage directory, which ListRepos skips since it only reads directories.
+func (s *Store) SaveRedirects(_ context.Context, redirects map[string]string) error {
+	data, err := json.Marshal(redirects)
+	if err != nil {
+		return fmt.Errorf("failed to marshal redirects: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.WriteFile(filepath.Join(s.basePath, redirectsFileName), data, 0o600); err != nil {
+		return fmt.Errorf("failed to write redirects: %w", err)
+	}
+
+	return nil
+}
+
+// GetAPIKeys returns the managed API keys. It returns an empty list when no keys have
+// been created.
+func (s *Store) GetAPIKeys(_ context.Context) ([]core.APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, err := os.ReadFile(filepath.Join(s.basePath, apiKeysFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []core.APIKey{}, nil
+		}
+
+		return nil, fmt.Errorf("failed to read api keys: %w", err)
+	}
+
+	var keys []core.APIKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal api keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// SaveAPIKeys replaces the managed API keys. Like redirects, they are stored in a file at
+// the root of the storage directory.
+func (s *Store) SaveAPIKeys(_ context.Context, keys []core.APIKey) error {
+	data, err := json.Marshal(keys)
+	if err != nil {
+		return fmt.Errorf("failed to marshal api keys: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.WriteFile(filepath.Join(s.basePath, apiKeysFileName), data, 0o600); err != nil {
+		return fmt.Errorf("failed to write api keys: %w", err)
+	}
+
+	return nil
+}
+
+// GetHighlights returns the highlights of every repository, keyed by repository. It
+// returns an empty map when no repository has highlights.
+func (s *Store) GetHighlights(_ context.Context) (map[string]core.RepoHighlights, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, err := os.ReadFile(filepath.Join(s.basePath, highlightsFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]core.RepoHighlights{}, nil
+		}
+
+		return nil, fmt.Errorf("failed to read highlights: %w", err)
+	}
+
+	highlights := make(map[string]core.RepoHighlights)
+	if err := json.Unmarshal(data, &highlights); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal highlights: %w", err)
+	}
+
+	return highlights, nil
+}
+
+// SaveHighlights replaces the highlights of every repository. Like redirects, they are
+// stored in a file at the root of the storage directory.
+func (s *Store) SaveHighlights(_ context.Context, highlights map[string]core.RepoHighlights) error {
+	data, err := json.Marshal(highlights)
+	if err != nil {
+		return fmt.Errorf("failed to marshal highlights: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.WriteFile(filepath.Join(s.basePath, highlightsFileName), data, 0o600); err != nil {
+		return fmt.Errorf("failed to write highlights: %w", err)
+	}
+
+	return nil
+}
+
+// GetSiteBanner returns the site banner, or nil when none is set.
+func (s *Store) GetSiteBanner(_ context.Context) (*core.SiteBanner, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, err := os.ReadFile(filepath.Join(s.basePath, bannerFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to read site banner: %w", err)
+	}
+
+	var banner *core.SiteBanner
+	if err := json.Unmarshal(data, &banner); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal site banner: %w", err)
+	}
+
+	return banner, nil
+}
+
+// SaveSiteBanner replaces the site banner; a nil banner clears it. Like redirects, it is
+// stored in a file at the root of the storage directory.
+func (s *Store) SaveSiteBanner(_ context.Context, banner *core.SiteBanner) error {
+	data, err := json.Marshal(banner)
+	if err != nil {
+		return fmt.Errorf("failed to marshal site banner: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.WriteFile(filepath.Join(s.basePath, bannerFileName), data, 0o600); err != nil {
+		return fmt.Errorf("failed to write site banner: %w", err)
+	}
+
+	return nil
+}
+
+// Size returns the number of bytes occupied by the files of the storage directory.
+func (s *Store) Size(_ context.Context) (int64, error) {
+	var size int64
+
+	err := filepath.WalkDir(s.basePath, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		size += info.Size()
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get storage size: %w", err)
+	}
+
+	return size, nil
+}
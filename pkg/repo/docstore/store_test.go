@@ -21,6 +21,27 @@ func TestNew(t *testing.T) {
 	assert.NotNil(t, store)
 }
 
+func TestIsCaseInsensitive(t *testing.T) {
+	dir := t.TempDir()
+
+	upper := filepath.Join(dir, "PROBE")
+	require.NoError(t, os.WriteFile(upper, nil, 0o600))
+
+	_, err := os.Stat(filepath.Join(dir, "probe"))
+	want := err == nil
+
+	got, err := isCaseInsensitive(dir)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "the probe file is removed")
+
+	_, err = isCaseInsensitive(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
+
 func TestStore_SaveAndGet(t *testing.T) {
 	tmpDir := t.TempDir()
 	store, err := New(tmpDir)
@@ -50,6 +71,116 @@ func TestStore_SaveAndGet(t *testing.T) {
 	assert.Equal(t, doc.CommitSHA, got.CommitSHA)
 }
 
+func TestStore_SummaryRoundTrip(t *testing.T) {
+	store, err := New(t.TempDir())
+	require.NoError(t, err)
+
+	doc := core.Document{
+		Repo:    "owner/repo",
+		Path:    "guide.md",
+		Title:   "Guide",
+		Summary: "How to deploy the service.",
+		Content: "# Guide\n\nHow to deploy the service.",
+	}
+
+	require.NoError(t, store.Save(t.Context(), doc))
+
+	got, err := store.Get(t.Context(), "owner/repo", "guide.md")
+	require.NoError(t, err)
+	assert.Equal(t, doc.Summary, got.Summary)
+
+	list, err := store.List(t.Context(), "owner/repo")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, doc.Summary, list[0].Summary)
+}
+
+func TestStore_ProvenanceRoundTrip(t *testing.T) {
+	store, err := New(t.TempDir())
+	require.NoError(t, err)
+
+	prov := &core.Provenance{
+		Workflow: "owner/repo/.github/workflows/docs.yml@refs/heads/main",
+		RunURL:   "https://github.com/owner/repo/actions/runs/42",
+		Verified: true,
+	}
+
+	require.NoError(t, store.Save(t.Context(), core.Document{Repo: "owner/repo", Path: "signed.md", Content: "# Signed", Provenance: prov}))
+	require.NoError(t, store.Save(t.Context(), core.Document{Repo: "owner/repo", Path: "plain.md", Content: "# Plain"}))
+
+	got, err := store.Get(t.Context(), "owner/repo", "signed.md")
+	require.NoError(t, err)
+	assert.Equal(t, prov, got.Provenance)
+
+	got, err = store.Get(t.Context(), "owner/repo", "plain.md")
+	require.NoError(t, err)
+	assert.Nil(t, got.Provenance)
+}
+
+func TestStore_AnchorsRoundTrip(t *testing.T) {
+	store, err := New(t.TempDir())
+	require.NoError(t, err)
+
+	anchors := core.AnchorMap{{ID: "usage", Text: "Usage", Offset: 12, Level: 2}}
+
+	require.NoError(t, store.Save(t.Context(), core.Document{Repo: "owner/repo", Path: "guide.md", Content: "# Guide", Anchors: anchors}))
+	require.NoError(t, store.Save(t.Context(), core.Document{Repo: "owner/repo", Path: "flat.md", Content: "Flat", Anchors: core.AnchorMap{}}))
+	require.NoError(t, store.Save(t.Context(), core.Document{Repo: "owner/repo", Path: "old.md", Content: "# Old"}))
+
+	got, err := store.Get(t.Context(), "owner/repo", "guide.md")
+	require.NoError(t, err)
+	assert.Equal(t, anchors, got.Anchors)
+
+	// A document without headings keeps its empty anchor map, unlike one saved without it.
+	got, err = store.Get(t.Context(), "owner/repo", "flat.md")
+	require.NoError(t, err)
+	assert.Equal(t, core.AnchorMap{}, got.Anchors)
+
+	got, err = store.Get(t.Context(), "owner/repo", "old.md")
+	require.NoError(t, err)
+	assert.Nil(t, got.Anchors)
+}
+
+func TestStore_TextRoundTrip(t *testing.T) {
+	store, err := New(t.TempDir())
+	require.NoError(t, err)
+
+	text := &core.DocumentText{
+		SHA256:    "0f1e",
+		PlainText: "Guide\nUsage",
+		Headings:  []core.Heading{{ID: "usage", Text: "Usage", Level: 2}},
+	}
+
+	require.NoError(t, store.Save(t.Context(), core.Document{Repo: "owner/repo", Path: "guide.md", Content: "# Guide", Text: text}))
+	require.NoError(t, store.Save(t.Context(), core.Document{Repo: "owner/repo", Path: "old.md", Content: "# Old"}))
+
+	got, err := store.Get(t.Context(), "owner/repo", "guide.md")
+	require.NoError(t, err)
+	assert.Equal(t, text, got.Text)
+
+	got, err = store.Get(t.Context(), "owner/repo", "old.md")
+	require.NoError(t, err)
+	assert.Nil(t, got.Text)
+}
+
+func TestStore_ImagesRoundTrip(t *testing.T) {
+	store, err := New(t.TempDir())
+	require.NoError(t, err)
+
+	images := map[string]core.ImageVariants{"img/shot.png": {Widths: []int{480, 960}, Width: 1200}}
+
+	require.NoError(t, store.Save(t.Context(), core.Document{Repo: "owner/repo", Path: "guide.md", Content: "# Guide", Images: images}))
+	require.NoError(t, store.Save(t.Context(), core.Document{Repo: "owner/repo", Path: "old.md", Content: "# Old"}))
+
+	got, err := store.Get(t.Context(), "owner/repo", "guide.md")
+	require.NoError(t, err)
+	assert.Equal(t, images, got.Images)
+
+	got, err = store.Get(t.Context(), "owner/repo", "old.md")
+	require.NoError(t, err)
+	assert.Nil(t, got.Images)
+}
+
 func TestStore_GetNotFound(t *testing.T) {
 	tmpDir := t.TempDir()
 	store, err := New(tmpDir)
@@ -121,6 +252,64 @@ func TestStore_List(t *testing.T) {
 	assert.Len(t, list, 2)
 }
 
+func TestStore_HomeRoundTrip(t *testing.T) {
+	store, err := New(t.TempDir())
+	require.NoError(t, err)
+
+	doc := core.Document{
+		ID:        "owner/repo/docs/start.md",
+		Repo:      "owner/repo",
+		Path:      "docs/start.md",
+		Title:     "Start",
+		Content:   "---\nhome: true\n---\n# Start",
+		UpdatedAt: time.Now(),
+		Home:      true,
+	}
+
+	require.NoError(t, store.Save(t.Context(), doc))
+
+	got, err := store.Get(t.Context(), "owner/repo", "docs/start.md")
+	require.NoError(t, err)
+	assert.True(t, got.Home)
+
+	list, err := store.List(t.Context(), "owner/repo")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.True(t, list[0].Home)
+}
+
+func TestStore_MetadataRoundTrip(t *testing.T) {
+	store, err := New(t.TempDir())
+	require.NoError(t, err)
+
+	doc := core.Document{
+		ID:        "owner/repo/docs/deploy.md",
+		Repo:      "owner/repo",
+		Path:      "docs/deploy.md",
+		Title:     "Deploy",
+		Content:   "---\ntags: [ops, getting started]\norder: 2\ndraft: true\n---\n# Deploy",
+		UpdatedAt: time.Now(),
+		Tags:      []string{"ops", "getting started"},
+		Order:     2,
+		Draft:     true,
+	}
+
+	require.NoError(t, store.Save(t.Context(), doc))
+
+	got, err := store.Get(t.Context(), "owner/repo", "docs/deploy.md")
+	require.NoError(t, err)
+	assert.Equal(t, doc.Tags, got.Tags)
+	assert.Equal(t, 2, got.Order)
+	assert.True(t, got.Draft)
+
+	list, err := store.List(t.Context(), "owner/repo")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, doc.Tags, list[0].Tags)
+	assert.Equal(t, 2, list[0].Order)
+	assert.True(t, list[0].Draft)
+}
+
 func TestStore_ListRepos(t *testing.T) {
 	tmpDir := t.TempDir()
 	store, err := New(tmpDir)
@@ -879,3 +1068,179 @@ func TestStore_UpdateRepoMeta_WriteFailure(t *testing.T) {
 	err = store.Save(t.Context(), doc)
 	assert.Error(t, err)
 }
+
+func TestStore_DeleteRepo(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, err := New(tmpDir)
+	require.NoError(t, err)
+
+	doc := core.Document{Repo: "owner/repo", Path: "guide.md", Title: "Guide", Content: "# Guide", UpdatedAt: time.Now()}
+	require.NoError(t, store.Save(t.Context(), doc))
+	require.NoError(t, store.SaveAsset(t.Context(), "owner/repo", "img/a.png", []byte("png")))
+
+	other := core.Document{Repo: "other/repo", Path: "guide.md", Title: "Guide", Content: "# Guide", UpdatedAt: time.Now()}
+	require.NoError(t, store.Save(t.Context(), other))
+
+	require.NoError(t, store.DeleteRepo(t.Context(), "owner/repo"))
+
+	repos, err := store.ListRepos(t.Context())
+	require.NoError(t, err)
+	require.Len(t, repos, 1)
+	assert.Equal(t, "other/repo", repos[0].Name)
+
+	_, err = os.Stat(filepath.Join(tmpDir, "owner"))
+	assert.True(t, os.IsNotExist(err), "empty owner directory should be removed")
+
+	err = store.DeleteRepo(t.Context(), "../outside")
+	assert.ErrorIs(t, err, core.ErrInvalidPath)
+}
+
+func TestStore_SubProjects(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, err := New(tmpDir)
+	require.NoError(t, err)
+
+	for _, repo := range []string{"owner/mono", "owner/mono/service-a", "owner/mono/service-b"} {
+		doc := core.Document{Repo: repo, Path: "guide.md", Title: "Guide", Content: "# Guide", UpdatedAt: time.Now()}
+		require.NoError(t, store.Save(t.Context(), doc))
+	}
+
+	repos, err := store.ListRepos(t.Context())
+	require.NoError(t, err)
+	require.Len(t, repos, 3)
+	assert.Equal(t, "owner/mono", repos[0].Name)
+	assert.Equal(t, "owner/mono/service-a", repos[1].Name)
+	assert.Equal(t, 1, repos[1].DocCount)
+
+	list, err := store.List(t.Context(), "owner/mono")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "guide.md", list[0].Path)
+
+	require.NoError(t, store.DeleteRepo(t.Context(), "owner/mono"))
+
+	repos, err = store.ListRepos(t.Context())
+	require.NoError(t, err)
+	require.Len(t, repos, 2)
+	assert.Equal(t, "owner/mono/service-a", repos[0].Name)
+
+	require.NoError(t, store.DeleteRepo(t.Context(), "owner/mono/service-a"))
+	require.NoError(t, store.DeleteRepo(t.Context(), "owner/mono/service-b"))
+
+	_, err = os.Stat(filepath.Join(tmpDir, "owner"))
+	assert.True(t, os.IsNotExist(err), "empty owner directory should be removed")
+}
+
+func TestStore_Redirects(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, err := New(tmpDir)
+	require.NoError(t, err)
+
+	redirects, err := store.GetRedirects(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, redirects)
+
+	require.NoError(t, store.SaveRedirects(t.Context(), map[string]string{"old/repo": "new/repo"}))
+
+	redirects, err = store.GetRedirects(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"old/repo": "new/repo"}, redirects)
+
+	repos, err := store.ListRepos(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, repos)
+}
+
+func TestStore_APIKeys(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, err := New(tmpDir)
+	require.NoError(t, err)
+
+	keys, err := store.GetAPIKeys(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	want := []core.APIKey{{ID: "a1b2c3", Name: "ci", Hash: "deadbeef", CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}}
+	require.NoError(t, store.SaveAPIKeys(t.Context(), want))
+
+	keys, err = store.GetAPIKeys(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, want, keys)
+
+	repos, err := store.ListRepos(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, repos)
+}
+
+func TestStore_Highlights(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, err := New(tmpDir)
+	require.NoError(t, err)
+
+	highlights, err := store.GetHighlights(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, highlights)
+
+	want := map[string]core.RepoHighlights{"acme/api": {
+		Repo:         "acme/api",
+		Announcement: "**v1 is deprecated**",
+		Pinned:       []string{"guides/migrate.md"},
+		UpdatedAt:    time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}}
+	require.NoError(t, store.SaveHighlights(t.Context(), want))
+
+	highlights, err = store.GetHighlights(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, want, highlights)
+
+	repos, err := store.ListRepos(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, repos)
+}
+
+func TestStore_SiteBanner(t *testing.T) {
+	store, err := New(t.TempDir())
+	require.NoError(t, err)
+
+	banner, err := store.GetSiteBanner(t.Context())
+	require.NoError(t, err)
+	assert.Nil(t, banner)
+
+	want := &core.SiteBanner{
+		Message:   "Publishing is paused during the storage migration.",
+		Severity:  core.BannerWarning,
+		StartsAt:  time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC),
+		EndsAt:    time.Date(2026, 1, 2, 5, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, store.SaveSiteBanner(t.Context(), want))
+
+	banner, err = store.GetSiteBanner(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, want, banner)
+
+	repos, err := store.ListRepos(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, repos)
+
+	require.NoError(t, store.SaveSiteBanner(t.Context(), nil))
+
+	banner, err = store.GetSiteBanner(t.Context())
+	require.NoError(t, err)
+	assert.Nil(t, banner)
+}
+
+func TestStore_Size(t *testing.T) {
+	store, err := New(t.TempDir())
+	require.NoError(t, err)
+
+	size, err := store.Size(t.Context())
+	require.NoError(t, err)
+	assert.Zero(t, size)
+
+	require.NoError(t, store.SaveAsset(t.Context(), "owner/repo", "img.png", make([]byte, 1000)))
+
+	size, err = store.Size(t.Context())
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, size, int64(1000))
+}
@@ -0,0 +1,179 @@
+// Package journal provides a change journal backed by an append-only JSON lines file.
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+// maxLineSize bounds the length of a journal line read back from the file.
+const maxLineSize = 64 * 1024
+
+// Journal implements core.ChangeJournal. Each change is a JSON line appended to the file,
+// so a crash loses at most the line being written.
+type Journal struct {
+	path string
+	mu   sync.Mutex
+}
+
+// New opens the journal at path, creating its directory if needed. Changes older than
+// retention are discarded; a zero retention keeps every change.
+func New(path string, retention time.Duration) (*Journal, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create journal directory: %w", err)
+	}
+
+	j := &Journal{path: path}
+
+	if retention > 0 {
+		if _, err := j.Prune(context.Background(), time.Now().Add(-retention)); err != nil {
+			return nil, err
+		}
+	}
+
+	return j, nil
+}
+
+// Append writes the changes to the end of the journal.
+func (j *Journal) Append(_ context.Context, changes []core.Change) error {
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	for _, c := range changes {
+		if err := enc.Encode(c); err != nil {
+			return fmt.Errorf("failed to encode change: %w", err)
+		}
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	f, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open journal: %w", err)
+	}
+
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close journal: %w", err)
+	}
+
+	return nil
+}
+
+// Since returns the changes recorded at or after t, oldest first.
+func (j *Journal) Since(_ context.Context, t time.Time) ([]core.Change, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	all, err := j.read()
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []core.Change
+
+	for _, c := range all {
+		if !c.Time.Before(t) {
+			changes = append(changes, c)
+		}
+	}
+
+	return changes, nil
+}
+
+// read returns every change in the journal. Lines that cannot be decoded, such as a line
+// cut short by a crash, are logged and skipped.
+func (j *Journal) read() ([]core.Change, error) {
+	f, err := os.Open(j.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+
+	defer func() { _ = f.Close() }()
+
+	var changes []core.Change
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 4096), maxLineSize)
+
+	for line := 1; scanner.Scan(); line++ {
+		var c core.Change
+		if err := json.Unmarshal(scanner.Bytes(), &c); err != nil {
+			slog.Warn("skipping malformed journal line", "path", j.path, "line", line, "error", err)
+			continue
+		}
+
+		changes = append(changes, c)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	return changes, nil
+}
+
+// Prune rewrites the journal without the changes recorded before t and returns the number
+// of changes dropped.
+func (j *Journal) Prune(_ context.Context, t time.Time) (int, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	all, err := j.read()
+	if err != nil {
+		return 0, err
+	}
+
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	kept := 0
+
+	for _, c := range all {
+		if c.Time.Before(t) {
+			continue
+		}
+
+		if err := enc.Encode(c); err != nil {
+			return 0, fmt.Errorf("failed to encode change: %w", err)
+		}
+
+		kept++
+	}
+
+	if kept == len(all) {
+		return 0, nil
+	}
+
+	tmp := j.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o600); err != nil {
+		return 0, fmt.Errorf("failed to write journal: %w", err)
+	}
+
+	if err := os.Rename(tmp, j.path); err != nil {
+		return 0, fmt.Errorf("failed to replace journal: %w", err)
+	}
+
+	return len(all) - kept, nil
+}
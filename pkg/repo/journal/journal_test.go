@@ -0,0 +1,97 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournal_AppendSince(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "changes.jsonl")
+
+	j, err := New(path, 0)
+	require.NoError(t, err)
+
+	changes, err := j.Since(t.Context(), time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, changes, "a missing journal is empty")
+
+	now := time.Now().UTC().Truncate(time.Second)
+	old := core.Change{Time: now.Add(-time.Hour), Repo: "acme/api", Path: "a.md", Action: core.ChangeAdded}
+	recent := core.Change{Time: now, Repo: "acme/api", Path: "a.md", Action: core.ChangeDeleted}
+
+	require.NoError(t, j.Append(t.Context(), []core.Change{old}))
+	require.NoError(t, j.Append(t.Context(), []core.Change{recent}))
+
+	changes, err = j.Since(t.Context(), now.Add(-2*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, []core.Change{old, recent}, changes)
+
+	changes, err = j.Since(t.Context(), now)
+	require.NoError(t, err)
+	assert.Equal(t, []core.Change{recent}, changes)
+}
+
+func TestJournal_SkipsMalformedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "changes.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(`{"time":"2026-01-05T10:00:00Z","repo":"acme/api","path":"a.md","action":"updated"}`+"\n"+`{"time":"2026-01`), 0o600))
+
+	j, err := New(path, 0)
+	require.NoError(t, err)
+
+	changes, err := j.Since(t.Context(), time.Time{})
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "a.md", changes[0].Path)
+}
+
+func TestNew_PrunesOldChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "changes.jsonl")
+
+	j, err := New(path, 0)
+	require.NoError(t, err)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, j.Append(t.Context(), []core.Change{
+		{Time: now.Add(-48 * time.Hour), Repo: "acme/api", Path: "old.md", Action: core.ChangeUpdated},
+		{Time: now, Repo: "acme/api", Path: "new.md", Action: core.ChangeUpdated},
+	}))
+
+	j, err = New(path, 24*time.Hour)
+	require.NoError(t, err)
+
+	changes, err := j.Since(t.Context(), time.Time{})
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "new.md", changes[0].Path)
+}
+
+func TestJournal_Prune(t *testing.T) {
+	j, err := New(filepath.Join(t.TempDir(), "changes.jsonl"), 0)
+	require.NoError(t, err)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, j.Append(t.Context(), []core.Change{
+		{Time: now.Add(-72 * time.Hour), Repo: "acme/api", Path: "oldest.md", Action: core.ChangeAdded},
+		{Time: now.Add(-48 * time.Hour), Repo: "acme/api", Path: "old.md", Action: core.ChangeUpdated},
+		{Time: now, Repo: "acme/api", Path: "new.md", Action: core.ChangeUpdated},
+	}))
+
+	n, err := j.Prune(t.Context(), now.Add(-24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	n, err = j.Prune(t.Context(), now.Add(-24*time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	changes, err := j.Since(t.Context(), time.Time{})
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "new.md", changes[0].Path)
+}
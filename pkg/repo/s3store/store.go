@@ -9,6 +9,11 @@
 //	  docs/{relative/path/to/doc}       – document content; per-document metadata
 //	                                       stored as x-amz-meta-* object headers
 //	  assets/{relative/path/to/file}    – binary asset body (no metadata headers)
+//	  docmeta/{relative/path/to/doc}.json – document fields too large for object
+//	                                       headers, such as its extracted text (JSON)
+//	  {project}/                        – monorepo sub-project, same layout as a repo
+//	redirects.json                      – repository redirects after renames (JSON)
+//	api_keys.json                       – hashes of the managed API keys (JSON)
 //
 // Document metadata fields stored as S3 custom object metadata headers:
 //
@@ -16,6 +21,7 @@
 //	x-amz-meta-updated-at   – RFC3339 timestamp of last update
 //	x-amz-meta-commit-sha   – VCS commit SHA at ingest time
 //	x-amz-meta-content-type – content type string (e.g. "markdown", "openapi")
+//	x-amz-meta-home         – "true" when the document is the repo's landing page
 //
 // AWS credentials are never stored in configuration; they are sourced via the
 // standard AWS SDK credential chain (environment variables →
@@ -32,6 +38,7 @@ import (
 	"log/slog"
 	stdpath "path"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -49,12 +56,35 @@ const (
 	metaFileName = "meta.json"
 	docsPrefix   = "docs/"
 	assetsPrefix = "assets/"
+	// docMetaPrefix holds a JSON object per document with the fields that do not fit in
+	// object metadata headers, see docExtras.
+	docMetaPrefix = "docmeta/"
+
+	// redirectsKey is the object holding repository redirects. It lives at the bucket
+	// root, outside any {owner}/ prefix, so ListRepos never treats it as a repository.
+	redirectsKey = "redirects.json"
+	// apiKeysKey is the object holding the managed API keys, stored at the root like redirectsKey.
+	apiKeysKey = "api_keys.json"
+	// highlightsKey is the object holding the highlights of every repository, stored at the
+	// root like redirectsKey.
+	highlightsKey = "highlights.json"
+	// bannerKey is the object holding the site banner, stored at the root like redirectsKey.
+	bannerKey = "banner.json"
 
 	// S3 custom metadata header keys (lowercased; the SDK adds the x-amz-meta- prefix).
 	metaKeyTitle       = "title"
+	metaKeySummary     = "summary"
 	metaKeyUpdatedAt   = "updated-at"
 	metaKeyCommitSHA   = "commit-sha"
 	metaKeyContentType = "content-type"
+	metaKeyHome        = "home"
+	metaKeyTags        = "tags" // comma-separated
+	metaKeyOrder       = "order"
+	metaKeyDraft       = "draft"
+
+	metaKeyProvenanceWorkflow = "provenance-workflow"
+	metaKeyProvenanceRunURL   = "provenance-run-url"
+	metaKeyProvenanceVerified = "provenance-verified"
 )
 
 // Config holds configuration for the S3-backed document store.
@@ -83,6 +113,22 @@ type repoMeta struct {
 	Name        string    `json:"name"`
 }
 
+// docExtras holds the fields of a document that are too large for object metadata
+// headers, persisted next to the document under docmeta/. They are derived from the
+// content at ingest time, so a document whose extras are missing is still served.
+type docExtras struct {
+	Text   *core.DocumentText            `json:"text,omitempty"`
+	Images map[string]core.ImageVariants `json:"images,omitempty"`
+	// Anchors is null for documents saved without an anchor map, and empty for documents
+	// whose anchor map has no entries.
+	Anchors core.AnchorMap `json:"anchors"`
+}
+
+// empty reports whether the document has no extras to store.
+func (e *docExtras) empty() bool {
+	return e.Text == nil && e.Anchors == nil && len(e.Images) == 0
+}
+
 // Store implements S3-backed document storage.
 type Store struct {
 	client s3Client
@@ -189,6 +235,11 @@ func assetKey(repo, path string) string {
 	return repo + "/" + assetsPrefix + path
 }
 
+// docMetaKey returns the S3 object key for the extras of a document.
+func docMetaKey(repo, path string) string {
+	return repo + "/" + docMetaPrefix + path + ".json"
+}
+
 // repoMetaKey returns the S3 object key for repo-level metadata.
 func repoMetaKey(repo string) string {
 	return repo + "/" + metaFileName
@@ -227,7 +278,8 @@ func isNotFound(err error) bool {
 }
 
 // Save persists a document to S3. The content body is uploaded as the object
-// body; per-document metadata is stored as x-amz-meta-* object headers.
+// body; per-document metadata is stored as x-amz-meta-* object headers and the
+// fields too large for them in a docmeta/ object, see docExtras.
 // Repo-level metadata (meta.json) is updated after the document upload.
 func (s *Store) Save(ctx context.Context, doc core.Document) error { //nolint:gocritic // Document is passed by value for immutability
 	if err := validateRelPath(doc.Repo); err != nil {
@@ -245,6 +297,32 @@ func (s *Store) Save(ctx context.Context, doc core.Document) error { //nolint:go
 		metaKeyContentType: string(doc.ContentType),
 	}
 
+	if doc.Home {
+		metadata[metaKeyHome] = "true"
+	}
+
+	if len(doc.Tags) > 0 {
+		metadata[metaKeyTags] = strings.Join(doc.Tags, ",")
+	}
+
+	if doc.Order != 0 {
+		metadata[metaKeyOrder] = strconv.Itoa(doc.Order)
+	}
+
+	if doc.Draft {
+		metadata[metaKeyDraft] = "true"
+	}
+
+	if doc.Summary != "" {
+		metadata[metaKeySummary] = doc.Summary
+	}
+
+	if p := doc.Provenance; p != nil {
+		metadata[metaKeyProvenanceWorkflow] = p.Workflow
+		metadata[metaKeyProvenanceRunURL] = p.RunURL
+		metadata[metaKeyProvenanceVerified] = strconv.FormatBool(p.Verified)
+	}
+
 	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
 		Bucket:   aws.String(s.bucket),
 		Key:      aws.String(docKey(doc.Repo, doc.Path)),
@@ -255,6 +333,8 @@ func (s *Store) Save(ctx context.Context, doc core.Document) error { //nolint:go
 		return fmt.Errorf("failed to upload document: %w", err)
 	}
 
+	s.saveExtras(ctx, doc.Repo, doc.Path, &docExtras{Text: doc.Text, Anchors: doc.Anchors, Images: doc.Images})
+
 	if err := s.updateRepoMeta(ctx, doc.Repo, doc.UpdatedAt); err != nil {
 		return fmt.Errorf("failed to update repo metadata: %w", err)
 	}
@@ -302,18 +382,121 @@ func (s *Store) Get(ctx context.Context, repo, path string) (core.Document, erro
 		ct = core.ContentTypeMarkdown
 	}
 
+	extras := s.getExtras(ctx, repo, path)
+
 	return core.Document{
-		ID:          repo + "/" + path,
+		ID:          core.NewDocumentID(repo, path).String(),
 		Repo:        repo,
 		Path:        path,
 		Title:       meta[metaKeyTitle],
+		Summary:     meta[metaKeySummary],
 		Content:     string(body),
 		CommitSHA:   meta[metaKeyCommitSHA],
 		UpdatedAt:   updatedAt,
 		ContentType: ct,
+		Home:        meta[metaKeyHome] == "true",
+		Tags:        parseTags(meta[metaKeyTags]),
+		Order:       parseOrder(meta[metaKeyOrder]),
+		Draft:       meta[metaKeyDraft] == "true",
+		Provenance:  parseProvenance(meta),
+		Text:        extras.Text,
+		Anchors:     extras.Anchors,
+		Images:      extras.Images,
 	}, nil
 }
 
+// saveExtras stores the extras of a document, or removes them when it has none. The
+// extras are derived from the content, so failures are logged rather than returned: the
+// document is served without them until it is saved again.
+func (s *Store) saveExtras(ctx context.Context, repo, path string, extras *docExtras) {
+	key := docMetaKey(repo, path)
+
+	if extras.empty() {
+		_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+			Bucket: aws.String(s.bucket),
+			Key:    aws.String(key),
+		})
+		if err != nil && !isNotFound(err) {
+			slog.WarnContext(ctx, "s3store: failed to delete document extras", "repo", repo, "path", path, "err", err)
+		}
+
+		return
+	}
+
+	data, err := json.Marshal(extras)
+	if err == nil {
+		_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
+			Bucket: aws.String(s.bucket),
+			Key:    aws.String(key),
+			Body:   bytes.NewReader(data),
+		})
+	}
+
+	if err != nil {
+		slog.WarnContext(ctx, "s3store: failed to save document extras", "repo", repo, "path", path, "err", err)
+	}
+}
+
+// getExtras returns the extras stored with a document, or none when they are missing or
+// cannot be read.
+func (s *Store) getExtras(ctx context.Context, repo, path string) docExtras {
+	var extras docExtras
+
+	resp, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(docMetaKey(repo, path)),
+	})
+	if err != nil {
+		if !isNotFound(err) {
+			slog.WarnContext(ctx, "s3store: failed to get document extras", "repo", repo, "path", path, "err", err)
+		}
+
+		return extras
+	}
+
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(&extras); err != nil {
+		slog.WarnContext(ctx, "s3store: failed to decode document extras", "repo", repo, "path", path, "err", err)
+		return docExtras{}
+	}
+
+	return extras
+}
+
+// parseTags returns the tags recorded in the object metadata, or nil if the document was
+// saved without tags.
+func parseTags(value string) []string {
+	if value == "" {
+		return nil
+	}
+
+	return strings.Split(value, ",")
+}
+
+// parseOrder returns the sidebar position recorded in the object metadata, or 0 if the
+// document was saved without one.
+func parseOrder(value string) int {
+	order, _ := strconv.Atoi(value)
+
+	return order
+}
+
+// parseProvenance returns the provenance recorded in the object metadata, or nil if the
+// document was saved without one.
+func parseProvenance(meta map[string]string) *core.Provenance {
+	verified, ok := meta[metaKeyProvenanceVerified]
+	if !ok {
+		return nil
+	}
+
+	return &core.Provenance{
+		Workflow: meta[metaKeyProvenanceWorkflow],
+		RunURL:   meta[metaKeyProvenanceRunURL],
+		Verified: verified == "true",
+	}
+}
+
 // Delete removes a document from S3. Missing objects are silently ignored
 // (idempotent behaviour matching the local docstore).
 func (s *Store) Delete(ctx context.Context, repo, path string) error {
@@ -333,6 +516,8 @@ func (s *Store) Delete(ctx context.Context, repo, path string) error {
 		return fmt.Errorf("failed to delete document: %w", err)
 	}
 
+	s.saveExtras(ctx, repo, path, &docExtras{})
+
 	return nil
 }
 
@@ -391,12 +576,17 @@ func (s *Store) List(ctx context.Context, repo string) ([]core.DocumentMeta, err
 			}
 
 			docs = append(docs, core.DocumentMeta{
-				ID:          repo + "/" + relPath,
+				ID:          core.NewDocumentID(repo, relPath).String(),
 				Repo:        repo,
 				Path:        relPath,
 				Title:       title,
+				Summary:     meta[metaKeySummary],
 				UpdatedAt:   updatedAt,
 				ContentType: ct,
+				Home:        meta[metaKeyHome] == "true",
+				Tags:        parseTags(meta[metaKeyTags]),
+				Order:       parseOrder(meta[metaKeyOrder]),
+				Draft:       meta[metaKeyDraft] == "true",
 			})
 		}
 	}
@@ -409,9 +599,9 @@ func (s *Store) List(ctx context.Context, repo string) ([]core.DocumentMeta, err
 }
 
 // ListRepos returns metadata for all repositories discovered in the bucket.
-// It uses two-level delimiter-based listing (owner/ then owner/repo/) to avoid
-// scanning every object and scales proportionally to the number of repos rather
-// than the total number of objects in the bucket.
+// It uses delimiter-based listing (owner/, then owner/repo/, then the sub-projects
+// under owner/repo/) to avoid scanning every object and scales proportionally to the
+// number of repos rather than the total number of objects in the bucket.
 func (s *Store) ListRepos(ctx context.Context) ([]core.RepoInfo, error) {
 	var repos []core.RepoInfo
 
@@ -460,24 +650,21 @@ func (s *Store) ListRepos(ctx context.Context) ([]core.RepoInfo, error) {
 						continue
 					}
 
-					meta, err := s.readRepoMeta(ctx, repoName)
-					if err != nil {
-						slog.WarnContext(ctx, "s3store: failed to read repo meta; skipping", "repo", repoName, "err", err)
-						continue
+					if info, ok := s.repoInfo(ctx, repoName); ok {
+						repos = append(repos, info)
 					}
 
-					docCount, err := s.countDocs(ctx, repoName)
+					// Third level: monorepo sub-projects stored under {owner}/{repo}/{project}/.
+					projects, err := s.listProjects(ctx, repoName)
 					if err != nil {
-						slog.WarnContext(ctx, "s3store: failed to count docs; using 0", "repo", repoName, "err", err)
-
-						docCount = 0
+						return nil, err
 					}
 
-					repos = append(repos, core.RepoInfo{
-						Name:        meta.Name,
-						DocCount:    docCount,
-						LastUpdated: meta.LastUpdated,
-					})
+					for _, project := range projects {
+						if info, ok := s.repoInfo(ctx, project); ok {
+							repos = append(repos, info)
+						}
+					}
 				}
 			}
 		}
@@ -490,6 +677,65 @@ func (s *Store) ListRepos(ctx context.Context) ([]core.RepoInfo, error) {
 	return repos, nil
 }
 
+// repoInfo returns the metadata of a repository. It returns false when the repository
+// metadata is missing or cannot be read, for example for a repository that only holds
+// sub-projects.
+func (s *Store) repoInfo(ctx context.Context, repo string) (core.RepoInfo, bool) {
+	meta, err := s.readRepoMeta(ctx, repo)
+	if err != nil {
+		if !isNotFound(err) {
+			slog.WarnContext(ctx, "s3store: failed to read repo meta; skipping", "repo", repo, "err", err)
+		}
+
+		return core.RepoInfo{}, false
+	}
+
+	docCount, err := s.countDocs(ctx, repo)
+	if err != nil {
+		slog.WarnContext(ctx, "s3store: failed to count docs; using 0", "repo", repo, "err", err)
+
+		docCount = 0
+	}
+
+	return core.RepoInfo{
+		Name:        meta.Name,
+		DocCount:    docCount,
+		LastUpdated: meta.LastUpdated,
+	}, true
+}
+
+// listProjects returns the identifiers of the monorepo sub-projects stored under a
+// repository prefix: its common prefixes other than docs/, assets/ and docmeta/.
+func (s *Store) listProjects(ctx context.Context, repo string) ([]string, error) {
+	prefix := repo + "/"
+
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket:    aws.String(s.bucket),
+		Prefix:    aws.String(prefix),
+		Delimiter: aws.String("/"),
+	})
+
+	var projects []string
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list sub-projects for repo %q: %w", repo, err)
+		}
+
+		for _, cp := range page.CommonPrefixes {
+			rel := strings.TrimPrefix(aws.ToString(cp.Prefix), prefix)
+			if rel == "" || rel == docsPrefix || rel == assetsPrefix || rel == docMetaPrefix {
+				continue
+			}
+
+			projects = append(projects, prefix+strings.TrimSuffix(rel, "/"))
+		}
+	}
+
+	return projects, nil
+}
+
 // SaveAsset writes a binary asset to S3.
 func (s *Store) SaveAsset(ctx context.Context, repo, path string, data []byte) error {
 	if err := validateRelPath(repo); err != nil {
@@ -668,3 +914,239 @@ func (s *Store) countDocs(ctx context.Context, repo string) (int, error) {
 
 	return count, nil
 }
+
+// DeleteRepo removes the documents, their extras, assets and meta.json stored under the
+// repository prefix. Sub-projects of the repository are kept.
+func (s *Store) DeleteRepo(ctx context.Context, repo string) error {
+	if err := validateRelPath(repo); err != nil {
+		return err
+	}
+
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(repo + "/"),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list repo objects: %w", err)
+		}
+
+		for _, obj := range page.Contents {
+			// Sub-projects stored under the repository prefix are independent repositories.
+			rel := strings.TrimPrefix(aws.ToString(obj.Key), repo+"/")
+			if rel != metaFileName && !strings.HasPrefix(rel, docsPrefix) && !strings.HasPrefix(rel, assetsPrefix) &&
+				!strings.HasPrefix(rel, docMetaPrefix) {
+				continue
+			}
+
+			_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+				Bucket: aws.String(s.bucket),
+				Key:    obj.Key,
+			})
+			if err != nil && !isNotFound(err) {
+				return fmt.Errorf("failed to delete object %s: %w", aws.ToString(obj.Key), err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetRedirects returns the recorded repository redirects, keyed by old identifier.
+// It returns an empty map when no redirects have been recorded.
+func (s *Store) GetRedirects(ctx context.Context) (map[string]string, error) {
+	resp, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(redirectsKey),
+	})
+	if err != nil {
+		if isNotFound(err) {
+			return map[string]string{}, nil
+		}
+
+		return nil, fmt.Errorf("failed to get redirects: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	redirects := make(map[string]string)
+	if err := json.NewDecoder(resp.Body).Decode(&redirects); err != nil {
+		return nil, fmt.Errorf("failed to decode redirects: %w", err)
+	}
+
+	return redirects, nil
+}
+
+// SaveRedirects replaces the recorded repository redirects.
+func (s *Store) SaveRedirects(ctx context.Context, redirects map[string]string) error {
+	data, err := json.Marshal(redirects)
+	if err != nil {
+		return fmt.Errorf("failed to marshal redirects: %w", err)
+	}
+
+	_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(redirectsKey),
+		Body:   bytes.NewReader(data),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload redirects: %w", err)
+	}
+
+	return nil
+}
+
+// GetAPIKeys returns the managed API keys. It returns an empty list when no keys have
+// been created.
+func (s *Store) GetAPIKeys(ctx context.Context) ([]core.APIKey, error) {
+	resp, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(apiKeysKey),
+	})
+	if err != nil {
+		if isNotFound(err) {
+			return []core.APIKey{}, nil
+		}
+
+		return nil, fmt.Errorf("failed to get api keys: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	var keys []core.APIKey
+	if err := json.NewDecoder(resp.Body).Decode(&keys); err != nil {
+		return nil, fmt.Errorf("failed to decode api keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// SaveAPIKeys replaces the managed API keys.
+func (s *Store) SaveAPIKeys(ctx context.Context, keys []core.APIKey) error {
+	data, err := json.Marshal(keys)
+	if err != nil {
+		return fmt.Errorf("failed to marshal api keys: %w", err)
+	}
+
+	_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(apiKeysKey),
+		Body:   bytes.NewReader(data),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload api keys: %w", err)
+	}
+
+	return nil
+}
+
+// GetHighlights returns the highlights of every repository, keyed by repository. It
+// returns an empty map when no repository has highlights.
+func (s *Store) GetHighlights(ctx context.Context) (map[string]core.RepoHighlights, error) {
+	resp, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(highlightsKey),
+	})
+	if err != nil {
+		if isNotFound(err) {
+			return map[string]core.RepoHighlights{}, nil
+		}
+
+		return nil, fmt.Errorf("failed to get highlights: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	highlights := make(map[string]core.RepoHighlights)
+	if err := json.NewDecoder(resp.Body).Decode(&highlights); err != nil {
+		return nil, fmt.Errorf("failed to decode highlights: %w", err)
+	}
+
+	return highlights, nil
+}
+
+// SaveHighlights replaces the highlights of every repository.
+func (s *Store) SaveHighlights(ctx context.Context, highlights map[string]core.RepoHighlights) error {
+	data, err := json.Marshal(highlights)
+	if err != nil {
+		return fmt.Errorf("failed to marshal highlights: %w", err)
+	}
+
+	_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(highlightsKey),
+		Body:   bytes.NewReader(data),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload highlights: %w", err)
+	}
+
+	return nil
+}
+
+// GetSiteBanner returns the site banner, or nil when none is set.
+func (s *Store) GetSiteBanner(ctx context.Context) (*core.SiteBanner, error) {
+	resp, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(bannerKey),
+	})
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to get site banner: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	var banner *core.SiteBanner
+	if err := json.NewDecoder(resp.Body).Decode(&banner); err != nil {
+		return nil, fmt.Errorf("failed to decode site banner: %w", err)
+	}
+
+	return banner, nil
+}
+
+// SaveSiteBanner replaces the site banner; a nil banner clears it.
+func (s *Store) SaveSiteBanner(ctx context.Context, banner *core.SiteBanner) error {
+	data, err := json.Marshal(banner)
+	if err != nil {
+		return fmt.Errorf("failed to marshal site banner: %w", err)
+	}
+
+	_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(bannerKey),
+		Body:   bytes.NewReader(data),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload site banner: %w", err)
+	}
+
+	return nil
+}
+
+// Size returns the total size of the objects in the bucket.
+func (s *Store) Size(ctx context.Context) (int64, error) {
+	var size int64
+
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("failed to list objects: %w", err)
+		}
+
+		for _, obj := range page.Contents {
+			size += aws.ToInt64(obj.Size)
+		}
+	}
+
+	return size, nil
+}
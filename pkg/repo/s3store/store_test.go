@@ -363,6 +363,164 @@ func TestStore_ContentTypeRoundTrip(t *testing.T) {
 	assert.Equal(t, core.ContentTypeOpenAPI, got.ContentType)
 }
 
+func TestStore_HomeRoundTrip(t *testing.T) {
+	store := newTestStore(t)
+
+	doc := core.Document{
+		ID:        "owner/repo/docs/start.md",
+		Repo:      "owner/repo",
+		Path:      "docs/start.md",
+		Title:     "Start",
+		Content:   "---\nhome: true\n---\n# Start",
+		UpdatedAt: time.Now().UTC(),
+		Home:      true,
+	}
+
+	require.NoError(t, store.Save(t.Context(), doc))
+
+	got, err := store.Get(t.Context(), "owner/repo", "docs/start.md")
+	require.NoError(t, err)
+	assert.True(t, got.Home)
+
+	list, err := store.List(t.Context(), "owner/repo")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.True(t, list[0].Home)
+}
+
+func TestStore_MetadataRoundTrip(t *testing.T) {
+	store := newTestStore(t)
+
+	doc := core.Document{
+		ID:        "owner/repo/docs/deploy.md",
+		Repo:      "owner/repo",
+		Path:      "docs/deploy.md",
+		Title:     "Deploy",
+		Content:   "---\ntags: [ops, getting started]\norder: 2\ndraft: true\n---\n# Deploy",
+		UpdatedAt: time.Now().UTC(),
+		Tags:      []string{"ops", "getting started"},
+		Order:     2,
+		Draft:     true,
+	}
+
+	require.NoError(t, store.Save(t.Context(), doc))
+
+	got, err := store.Get(t.Context(), "owner/repo", "docs/deploy.md")
+	require.NoError(t, err)
+	assert.Equal(t, doc.Tags, got.Tags)
+	assert.Equal(t, 2, got.Order)
+	assert.True(t, got.Draft)
+
+	list, err := store.List(t.Context(), "owner/repo")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, doc.Tags, list[0].Tags)
+	assert.Equal(t, 2, list[0].Order)
+	assert.True(t, list[0].Draft)
+}
+
+func TestStore_SummaryRoundTrip(t *testing.T) {
+	store := newTestStore(t)
+
+	doc := core.Document{
+		Repo:    "owner/repo",
+		Path:    "guide.md",
+		Title:   "Guide",
+		Summary: "How to deploy the service.",
+		Content: "# Guide\n\nHow to deploy the service.",
+	}
+
+	require.NoError(t, store.Save(t.Context(), doc))
+
+	got, err := store.Get(t.Context(), "owner/repo", "guide.md")
+	require.NoError(t, err)
+	assert.Equal(t, doc.Summary, got.Summary)
+
+	list, err := store.List(t.Context(), "owner/repo")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, doc.Summary, list[0].Summary)
+}
+
+func TestStore_ProvenanceRoundTrip(t *testing.T) {
+	store := newTestStore(t)
+
+	prov := &core.Provenance{
+		Workflow: "owner/repo/.github/workflows/docs.yml@refs/heads/main",
+		RunURL:   "https://github.com/owner/repo/actions/runs/42",
+		Verified: true,
+	}
+
+	require.NoError(t, store.Save(t.Context(), core.Document{Repo: "owner/repo", Path: "signed.md", Content: "# Signed", Provenance: prov}))
+	require.NoError(t, store.Save(t.Context(), core.Document{Repo: "owner/repo", Path: "plain.md", Content: "# Plain"}))
+
+	got, err := store.Get(t.Context(), "owner/repo", "signed.md")
+	require.NoError(t, err)
+	assert.Equal(t, prov, got.Provenance)
+
+	got, err = store.Get(t.Context(), "owner/repo", "plain.md")
+	require.NoError(t, err)
+	assert.Nil(t, got.Provenance)
+}
+
+func TestStore_ExtrasRoundTrip(t *testing.T) {
+	store := newTestStore(t)
+
+	text := &core.DocumentText{
+		SHA256:    "abc",
+		Processor: "*markdown.Renderer:1f2e",
+		PlainText: "Guide\nIntro",
+		Headings:  []core.Heading{{ID: "guide", Text: "Guide", Level: 1}},
+	}
+
+	anchors := core.AnchorMap{{ID: "guide", Text: "Guide", Level: 1}}
+	images := map[string]core.ImageVariants{"shot.png": {Widths: []int{480}, Width: 600}}
+
+	doc := core.Document{Repo: "owner/repo", Path: "guide.md", Content: "# Guide\nIntro", Text: text, Anchors: anchors, Images: images}
+	require.NoError(t, store.Save(t.Context(), doc))
+
+	got, err := store.Get(t.Context(), "owner/repo", "guide.md")
+	require.NoError(t, err)
+	assert.Equal(t, text, got.Text)
+	assert.Equal(t, anchors, got.Anchors)
+	assert.Equal(t, images, got.Images)
+
+	// The extras are not mistaken for a sub-project.
+	repos, err := store.ListRepos(t.Context())
+	require.NoError(t, err)
+	require.Len(t, repos, 1)
+	assert.Equal(t, "owner/repo", repos[0].Name)
+
+	// An empty anchor map is kept apart from a missing one.
+	doc.Anchors = core.AnchorMap{}
+	require.NoError(t, store.Save(t.Context(), doc))
+
+	got, err = store.Get(t.Context(), "owner/repo", "guide.md")
+	require.NoError(t, err)
+	assert.Equal(t, core.AnchorMap{}, got.Anchors)
+
+	// Saving the document without text, anchors and images drops the stored extras.
+	doc.Text, doc.Anchors, doc.Images = nil, nil, nil
+	require.NoError(t, store.Save(t.Context(), doc))
+
+	got, err = store.Get(t.Context(), "owner/repo", "guide.md")
+	require.NoError(t, err)
+	assert.Nil(t, got.Text)
+	assert.Nil(t, got.Anchors)
+	assert.Nil(t, got.Images)
+
+	// Deleting the document removes its extras.
+	doc.Text = text
+	require.NoError(t, store.Save(t.Context(), doc))
+	require.NoError(t, store.Delete(t.Context(), "owner/repo", "guide.md"))
+
+	_, err = store.client.GetObject(t.Context(), &s3.GetObjectInput{
+		Bucket: aws.String(testBucket),
+		Key:    aws.String(docMetaKey("owner/repo", "guide.md")),
+	})
+	assert.True(t, isNotFound(err))
+}
+
 func TestStore_GetDefaultsToMarkdownContentType(t *testing.T) {
 	store := newTestStore(t)
 
@@ -1271,3 +1429,161 @@ func (e *emptyRepoPrefixListClient) ListObjectsV2(_ context.Context, _ *s3.ListO
 func (e *emptyRepoPrefixListClient) HeadObject(_ context.Context, _ *s3.HeadObjectInput, _ ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
 	return nil, errors.New("not called")
 }
+
+func TestStore_DeleteRepo(t *testing.T) {
+	store := newTestStore(t)
+
+	doc := core.Document{Repo: "owner/repo", Path: "guide.md", Title: "Guide", Content: "# Guide", UpdatedAt: time.Now().UTC()}
+	require.NoError(t, store.Save(t.Context(), doc))
+	require.NoError(t, store.SaveAsset(t.Context(), "owner/repo", "img/a.png", []byte("png")))
+
+	other := core.Document{Repo: "owner/repo-other", Path: "guide.md", Title: "Guide", Content: "# Guide", UpdatedAt: time.Now().UTC()}
+	require.NoError(t, store.Save(t.Context(), other))
+
+	require.NoError(t, store.DeleteRepo(t.Context(), "owner/repo"))
+
+	repos, err := store.ListRepos(t.Context())
+	require.NoError(t, err)
+	require.Len(t, repos, 1)
+	assert.Equal(t, "owner/repo-other", repos[0].Name)
+
+	assets, err := store.ListAssets(t.Context(), "owner/repo")
+	require.NoError(t, err)
+	assert.Empty(t, assets)
+}
+
+func TestStore_SubProjects(t *testing.T) {
+	store := newTestStore(t)
+
+	for _, repo := range []string{"owner/mono", "owner/mono/service-a", "owner/mono/service-b"} {
+		doc := core.Document{Repo: repo, Path: "guide.md", Title: "Guide", Content: "# Guide", UpdatedAt: time.Now().UTC()}
+		require.NoError(t, store.Save(t.Context(), doc))
+	}
+
+	repos, err := store.ListRepos(t.Context())
+	require.NoError(t, err)
+	require.Len(t, repos, 3)
+	assert.Equal(t, "owner/mono", repos[0].Name)
+	assert.Equal(t, "owner/mono/service-a", repos[1].Name)
+	assert.Equal(t, 1, repos[1].DocCount)
+
+	list, err := store.List(t.Context(), "owner/mono")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "guide.md", list[0].Path)
+
+	require.NoError(t, store.DeleteRepo(t.Context(), "owner/mono"))
+
+	repos, err = store.ListRepos(t.Context())
+	require.NoError(t, err)
+	require.Len(t, repos, 2)
+	assert.Equal(t, "owner/mono/service-a", repos[0].Name)
+	assert.Equal(t, "owner/mono/service-b", repos[1].Name)
+}
+
+func TestStore_Redirects(t *testing.T) {
+	store := newTestStore(t)
+
+	redirects, err := store.GetRedirects(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, redirects)
+
+	require.NoError(t, store.SaveRedirects(t.Context(), map[string]string{"old/repo": "new/repo"}))
+
+	redirects, err = store.GetRedirects(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"old/repo": "new/repo"}, redirects)
+
+	repos, err := store.ListRepos(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, repos)
+}
+
+func TestStore_APIKeys(t *testing.T) {
+	store := newTestStore(t)
+
+	keys, err := store.GetAPIKeys(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	want := []core.APIKey{{ID: "a1b2c3", Name: "ci", Hash: "deadbeef", CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}}
+	require.NoError(t, store.SaveAPIKeys(t.Context(), want))
+
+	keys, err = store.GetAPIKeys(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, want, keys)
+
+	repos, err := store.ListRepos(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, repos)
+}
+
+func TestStore_Highlights(t *testing.T) {
+	store := newTestStore(t)
+
+	highlights, err := store.GetHighlights(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, highlights)
+
+	want := map[string]core.RepoHighlights{"acme/api": {
+		Repo:         "acme/api",
+		Announcement: "**v1 is deprecated**",
+		Pinned:       []string{"guides/migrate.md"},
+		UpdatedAt:    time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}}
+	require.NoError(t, store.SaveHighlights(t.Context(), want))
+
+	highlights, err = store.GetHighlights(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, want, highlights)
+
+	repos, err := store.ListRepos(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, repos)
+}
+
+func TestStore_SiteBanner(t *testing.T) {
+	store := newTestStore(t)
+
+	banner, err := store.GetSiteBanner(t.Context())
+	require.NoError(t, err)
+	assert.Nil(t, banner)
+
+	want := &core.SiteBanner{
+		Message:   "Publishing is paused during the storage migration.",
+		Severity:  core.BannerWarning,
+		StartsAt:  time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC),
+		EndsAt:    time.Date(2026, 1, 2, 5, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, store.SaveSiteBanner(t.Context(), want))
+
+	banner, err = store.GetSiteBanner(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, want, banner)
+
+	repos, err := store.ListRepos(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, repos)
+
+	require.NoError(t, store.SaveSiteBanner(t.Context(), nil))
+
+	banner, err = store.GetSiteBanner(t.Context())
+	require.NoError(t, err)
+	assert.Nil(t, banner)
+}
+
+func TestStore_Size(t *testing.T) {
+	store := newTestStore(t)
+
+	size, err := store.Size(t.Context())
+	require.NoError(t, err)
+	assert.Zero(t, size)
+
+	require.NoError(t, store.SaveAsset(t.Context(), "owner/repo", "img.png", make([]byte, 1000)))
+	require.NoError(t, store.SaveRedirects(t.Context(), map[string]string{"a/b": "c/d"}))
+
+	size, err = store.Size(t.Context())
+	require.NoError(t, err)
+	assert.Greater(t, size, int64(1000))
+}
@@ -0,0 +1,140 @@
+// Package savedsearch provides a saved search store backed by a JSON file.
+package savedsearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+// Store implements core.SavedSearchStore. The saved searches are kept in a JSON file,
+// which is rewritten through a temporary file on every change, so a crash leaves either
+// the old or the new version.
+type Store struct {
+	path string
+	mu   sync.Mutex
+}
+
+// New opens the saved search store at path, creating its directory if needed.
+func New(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create saved search directory: %w", err)
+	}
+
+	return &Store{path: path}, nil
+}
+
+// List returns every saved search, oldest first.
+func (s *Store) List(_ context.Context) ([]core.SavedSearch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.read()
+}
+
+// Get returns the saved search with the given ID.
+func (s *Store) Get(_ context.Context, id string) (core.SavedSearch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	searches, err := s.read()
+	if err != nil {
+		return core.SavedSearch{}, err
+	}
+
+	i := index(searches, id)
+	if i < 0 {
+		return core.SavedSearch{}, fmt.Errorf("%w: saved search %s", core.ErrNotFound, id)
+	}
+
+	return searches[i], nil
+}
+
+// Save creates or replaces the saved search with the ID of search.
+func (s *Store) Save(_ context.Context, search core.SavedSearch) error { //nolint:gocritic // SavedSearch is passed by value like the SavedSearchStore interface
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	searches, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	if i := index(searches, search.ID); i >= 0 {
+		searches[i] = search
+	} else {
+		searches = append(searches, search)
+	}
+
+	return s.write(searches)
+}
+
+// Delete deletes the saved search with the given ID.
+func (s *Store) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	searches, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	i := index(searches, id)
+	if i < 0 {
+		return fmt.Errorf("%w: saved search %s", core.ErrNotFound, id)
+	}
+
+	return s.write(slices.Delete(searches, i, i+1))
+}
+
+// read returns the saved searches in the file, or none if it does not exist yet.
+func (s *Store) read() ([]core.SavedSearch, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read saved searches: %w", err)
+	}
+
+	var searches []core.SavedSearch
+	if err := json.Unmarshal(data, &searches); err != nil {
+		return nil, fmt.Errorf("failed to decode saved searches: %w", err)
+	}
+
+	return searches, nil
+}
+
+// write replaces the file with searches.
+func (s *Store) write(searches []core.SavedSearch) error {
+	data, err := json.Marshal(searches)
+	if err != nil {
+		return fmt.Errorf("failed to encode saved searches: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write saved searches: %w", err)
+	}
+
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to replace saved searches: %w", err)
+	}
+
+	return nil
+}
+
+// index returns the position of the saved search with the given ID in searches, or -1.
+func index(searches []core.SavedSearch, id string) int {
+	return slices.IndexFunc(searches, func(search core.SavedSearch) bool { return search.ID == id })
+}
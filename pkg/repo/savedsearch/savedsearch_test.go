@@ -0,0 +1,65 @@
+package savedsearch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_SaveGetDelete(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "saved_searches.json")
+
+	s, err := New(path)
+	require.NoError(t, err)
+
+	searches, err := s.List(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, searches, "a missing file has no saved searches")
+
+	now := time.Now().UTC().Truncate(time.Second)
+	deploy := core.SavedSearch{ID: "a1", Name: "Deploys", Query: "deploy", CreatedAt: now}
+	oncall := core.SavedSearch{ID: "b2", Name: "On-call", Query: "on-call", CreatedAt: now}
+
+	require.NoError(t, s.Save(t.Context(), deploy))
+	require.NoError(t, s.Save(t.Context(), oncall))
+
+	deploy.Matches = []core.SearchMatch{{Time: now, Repo: "acme/api", Path: "deploy.md", Title: "Deploying"}}
+	require.NoError(t, s.Save(t.Context(), deploy))
+
+	got, err := s.Get(t.Context(), "a1")
+	require.NoError(t, err)
+	assert.Equal(t, deploy, got)
+
+	reopened, err := New(path)
+	require.NoError(t, err)
+
+	searches, err = reopened.List(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, []core.SavedSearch{deploy, oncall}, searches)
+
+	require.NoError(t, s.Delete(t.Context(), "a1"))
+
+	_, err = s.Get(t.Context(), "a1")
+	require.ErrorIs(t, err, core.ErrNotFound)
+	require.ErrorIs(t, s.Delete(t.Context(), "a1"), core.ErrNotFound)
+
+	searches, err = s.List(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, []core.SavedSearch{oncall}, searches)
+}
+
+func TestStore_MalformedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "saved_searches.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[{"id":`), 0o600))
+
+	s, err := New(path)
+	require.NoError(t, err)
+
+	_, err = s.List(t.Context())
+	assert.ErrorContains(t, err, "failed to decode saved searches")
+}
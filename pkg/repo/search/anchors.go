@@ -0,0 +1,51 @@
+package search
+
+import (
+	"encoding/json"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+// fieldAnchorMap holds the anchor map of a document as JSON. It is stored with the index
+// and returned with the indexed plain text, so that search results resolve the section
+// they matched without loading the document. It is not searchable.
+const fieldAnchorMap = "anchor_map"
+
+// encodeAnchorMap returns the stored form of an anchor map, or an empty string when the
+// document has none.
+func encodeAnchorMap(anchors core.AnchorMap) string {
+	if anchors == nil {
+		return ""
+	}
+
+	data, err := json.Marshal(anchors)
+	if err != nil {
+		return ""
+	}
+
+	return string(data)
+}
+
+// decodeAnchorMap returns the anchor map stored by encodeAnchorMap, or nil when none was
+// stored or it cannot be decoded.
+func decodeAnchorMap(value string) core.AnchorMap {
+	if value == "" {
+		return nil
+	}
+
+	var anchors core.AnchorMap
+	if err := json.Unmarshal([]byte(value), &anchors); err != nil {
+		return nil
+	}
+
+	return anchors
+}
+
+// setIndexedAnchors sets the anchor map of a search result and the plain text it maps
+// from their stored values. Results of documents indexed without an anchor map are left
+// without either, so that their anchors are resolved from the document store.
+func setIndexedAnchors(sr *core.SearchResult, content, anchorMap string) {
+	if sr.Anchors = decodeAnchorMap(anchorMap); sr.Anchors != nil {
+		sr.PlainText = content
+	}
+}
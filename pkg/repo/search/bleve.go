@@ -4,7 +4,15 @@ package search
 import (
 	"context"
 	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/blevesearch/bleve/v2"
 	"github.com/blevesearch/bleve/v2/mapping"
@@ -16,70 +24,313 @@ import (
 
 // searchDocument is the internal representation of a document stored in the Bleve index.
 type searchDocument struct {
-	ID      string `json:"id"`
-	Repo    string `json:"repo"`
-	Path    string `json:"path"`
-	Title   string `json:"title"`
-	Content string `json:"content"`
+	ID          string   `json:"id"`
+	Repo        string   `json:"repo"`
+	Path        string   `json:"path"`
+	Title       string   `json:"title"`
+	Content     string   `json:"content"`
+	Code        string   `json:"code"`
+	ContentType string   `json:"content_type"`
+	AnchorMap   string   `json:"anchor_map"`
+	Langs       []string `json:"langs"`
+	Headings    []string `json:"headings"`
+	Anchors     []string `json:"heading_anchors"`
+	Tags        []string `json:"tags"`
 }
 
+// bleveSchemaVersion identifies the index mapping produced by buildIndexMapping. It must be
+// incremented whenever the mapping changes so that indexes built with an older mapping are
+// detected and rebuilt on startup.
+//
+//	1: title, content, repo and path (indexes created before versioning was introduced)
+//	2: code and langs fields for code search
+//	3: content_type field for content type filters and facets
+//	4: headings and heading_anchors fields for search-as-you-type suggestions
+//	5: tags field for tag: qualifiers
+//	6: anchor_map field for resolving the section anchors of search results
+const bleveSchemaVersion = 6
+
+// schemaVersionKey is the internal index key under which the schema version is stored.
+var schemaVersionKey = []byte("omnidex:schema_version")
+
 // BleveEngine implements full-text search using Bleve embedded search library.
 type BleveEngine struct {
-	index bleve.Index
+	index      bleve.Index
+	runtimeCfg map[string]any
+	shadow     *BleveEngine // index being rebuilt alongside this one, see CreateShadow
+	path       string
+	mu         sync.RWMutex // guards index and shadow, which are swapped on promotion
+	rebuilt    bool
+}
+
+// BleveConfig holds tuning options for the Bleve index. Zero values keep Bleve's defaults,
+// which suit small and medium deployments.
+//
+// Bleve memory-maps the segment files it persists, so their size is accounted to the page
+// cache rather than the heap. Newly indexed segments stay on the heap until the persister
+// writes them to disk; PersistInterval and MaxInMemoryMergeMiB trade heap use against the
+// number of small segment files, and so against indexing and merge throughput.
+type BleveConfig struct {
+	// PersistInterval delays writing in-memory segments to disk so that more of them are
+	// merged in memory first. Longer intervals speed up bulk indexing at the cost of heap.
+	PersistInterval time.Duration `mapstructure:"persist_interval"`
+	// MaxSegmentDocs caps the number of documents in a segment produced by merging.
+	MaxSegmentDocs int64 `mapstructure:"max_segment_docs"`
+	// MaxInMemoryMergeMiB caps the segment data each persister worker merges in memory
+	// before writing it to disk.
+	MaxInMemoryMergeMiB int `mapstructure:"max_in_memory_merge_mib"`
+	// PersisterWorkers is the number of workers merging and writing in-memory segments.
+	PersisterWorkers int `mapstructure:"persister_workers"`
+	// MaxSegmentsPerTier is the number of segments of similar size tolerated before they
+	// are merged. Lower values mean fewer segments and faster searches, but more merging.
+	MaxSegmentsPerTier int `mapstructure:"max_segments_per_tier"`
+	// SegmentsPerMergeTask is the number of segments merged together at once.
+	SegmentsPerMergeTask int `mapstructure:"segments_per_merge"`
+	// AnalysisWorkers is the number of workers analyzing documents for indexing. It is a
+	// process-wide setting applied when the index is opened.
+	AnalysisWorkers int `mapstructure:"analysis_workers"`
+}
+
+// runtimeConfig returns the scorch runtime options for the configured values.
+func (c *BleveConfig) runtimeConfig() (map[string]any, error) {
+	if c.PersistInterval < 0 || c.MaxSegmentDocs < 0 || c.MaxInMemoryMergeMiB < 0 || c.PersisterWorkers < 0 ||
+		c.MaxSegmentsPerTier < 0 || c.SegmentsPerMergeTask < 0 || c.AnalysisWorkers < 0 {
+		return nil, fmt.Errorf("bleve tuning options must not be negative")
+	}
+
+	persister := map[string]any{}
+	if c.PersistInterval > 0 {
+		persister["PersisterNapTimeMSec"] = c.PersistInterval.Milliseconds()
+	}
+
+	if c.MaxInMemoryMergeMiB > 0 {
+		persister["MaxSizeInMemoryMergePerWorker"] = c.MaxInMemoryMergeMiB << 20
+	}
+
+	if c.PersisterWorkers > 0 {
+		persister["NumPersisterWorkers"] = c.PersisterWorkers
+	}
+
+	merge := map[string]any{}
+	if c.MaxSegmentDocs > 0 {
+		merge["MaxSegmentSize"] = c.MaxSegmentDocs
+	}
+
+	if c.MaxSegmentsPerTier > 0 {
+		merge["MaxSegmentsPerTier"] = c.MaxSegmentsPerTier
+	}
+
+	if c.SegmentsPerMergeTask > 0 {
+		merge["SegmentsPerMergeTask"] = c.SegmentsPerMergeTask
+	}
+
+	cfg := map[string]any{}
+	if len(persister) > 0 {
+		cfg["scorchPersisterOptions"] = persister
+	}
+
+	if len(merge) > 0 {
+		cfg["scorchMergePlanOptions"] = merge
+	}
+
+	return cfg, nil
 }
 
 // NewBleve creates a new Bleve search engine. It opens an existing index at indexPath,
-// or creates a new one if it does not exist.
-func NewBleve(indexPath string) (*BleveEngine, error) {
-	index, err := bleve.Open(indexPath)
+// or creates a new one if it does not exist. An existing index built with an older schema
+// version is discarded and recreated empty with the current mapping; NeedsReindex then
+// reports true so the caller can repopulate it. An index built by a newer version of
+// omnidex is rejected rather than modified. The tuning options in cfg apply to this run
+// only; they are not stored with the index.
+func NewBleve(indexPath string, cfg BleveConfig) (*BleveEngine, error) {
+	runtimeCfg, err := cfg.runtimeConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.AnalysisWorkers > 0 {
+		bleve.Config.SetAnalysisQueueSize(cfg.AnalysisWorkers)
+	}
+
+	index, err := bleve.OpenUsing(indexPath, runtimeCfg)
 	if err != nil {
-		index, err = bleve.New(indexPath, buildIndexMapping())
+		index, err = createBleveIndex(indexPath, runtimeCfg)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create bleve index: %w", err)
+			return nil, err
 		}
+
+		return &BleveEngine{index: index, path: indexPath, runtimeCfg: runtimeCfg}, nil
+	}
+
+	version, err := readSchemaVersion(index)
+	if err != nil {
+		_ = index.Close()
+		return nil, err
+	}
+
+	switch {
+	case version == bleveSchemaVersion:
+		return &BleveEngine{index: index, path: indexPath, runtimeCfg: runtimeCfg}, nil
+	case version > bleveSchemaVersion:
+		_ = index.Close()
+		return nil, fmt.Errorf("bleve index schema version %d is newer than supported version %d", version, bleveSchemaVersion)
+	}
+
+	slog.Warn("bleve index schema is outdated, rebuilding index",
+		"path", indexPath,
+		"version", version,
+		"want", bleveSchemaVersion,
+	)
+
+	if err := index.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close outdated bleve index: %w", err)
 	}
 
-	return &BleveEngine{index: index}, nil
+	if err := os.RemoveAll(indexPath); err != nil {
+		return nil, fmt.Errorf("failed to remove outdated bleve index: %w", err)
+	}
+
+	index, err = createBleveIndex(indexPath, runtimeCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BleveEngine{index: index, path: indexPath, runtimeCfg: runtimeCfg, rebuilt: true}, nil
+}
+
+// createBleveIndex creates a new index with the current mapping and records its schema version.
+// The index is then reopened with runtimeCfg, since options given at creation would be
+// stored with the index and outlive a later change of configuration.
+func createBleveIndex(indexPath string, runtimeCfg map[string]any) (bleve.Index, error) {
+	index, err := bleve.New(indexPath, buildIndexMapping())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bleve index: %w", err)
+	}
+
+	if err := index.SetInternal(schemaVersionKey, []byte(strconv.Itoa(bleveSchemaVersion))); err != nil {
+		_ = index.Close()
+		return nil, fmt.Errorf("failed to store bleve index schema version: %w", err)
+	}
+
+	if len(runtimeCfg) == 0 {
+		return index, nil
+	}
+
+	if err := index.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close new bleve index: %w", err)
+	}
+
+	index, err = bleve.OpenUsing(indexPath, runtimeCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bleve index: %w", err)
+	}
+
+	return index, nil
+}
+
+// readSchemaVersion returns the schema version recorded in the index. Indexes created
+// before versioning was introduced have no recorded version and are reported as version 1.
+func readSchemaVersion(index bleve.Index) (int, error) {
+	raw, err := index.GetInternal(schemaVersionKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read bleve index schema version: %w", err)
+	}
+
+	if len(raw) == 0 {
+		return 1, nil
+	}
+
+	version, err := strconv.Atoi(string(raw))
+	if err != nil {
+		return 0, fmt.Errorf("invalid bleve index schema version %q: %w", raw, err)
+	}
+
+	return version, nil
+}
+
+// NeedsReindex reports whether the index was recreated on open because its schema was
+// outdated. A recreated index is empty and must be repopulated from the document store.
+func (e *BleveEngine) NeedsReindex() bool {
+	return e.rebuilt
 }
 
 // Index adds or updates a document in the search index.
-func (e *BleveEngine) Index(_ context.Context, doc core.Document, plainText string) error { //nolint:gocritic // Document is passed by value for immutability
+func (e *BleveEngine) Index(_ context.Context, doc core.Document, plainText string, code []core.CodeBlock, headings []core.Heading) error { //nolint:gocritic // Document is passed by value for immutability
+	codeText, langs := joinCodeBlocks(code)
+	headingTexts, anchors := splitHeadings(headings)
+
 	searchDoc := searchDocument{
-		ID:      doc.ID,
-		Repo:    doc.Repo,
-		Path:    doc.Path,
-		Title:   doc.Title,
-		Content: plainText,
+		ID:          doc.ID,
+		Repo:        doc.Repo,
+		Path:        doc.Path,
+		Title:       doc.Title,
+		Content:     plainText,
+		Code:        codeText,
+		ContentType: indexedContentType(doc.ContentType),
+		Langs:       langs,
+		Headings:    headingTexts,
+		Anchors:     anchors,
+		Tags:        doc.Tags,
+		AnchorMap:   encodeAnchorMap(doc.Anchors),
 	}
 
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
 	if err := e.index.Index(doc.ID, searchDoc); err != nil {
 		return fmt.Errorf("failed to index document %s: %w", doc.ID, err)
 	}
 
+	if e.shadow != nil {
+		if err := e.shadow.index.Index(doc.ID, searchDoc); err != nil {
+			return fmt.Errorf("failed to index document %s in shadow index: %w", doc.ID, err)
+		}
+	}
+
 	return nil
 }
 
 // Remove deletes a document from the search index.
 func (e *BleveEngine) Remove(_ context.Context, docID string) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
 	if err := e.index.Delete(docID); err != nil {
 		return fmt.Errorf("failed to remove document %s from index: %w", docID, err)
 	}
 
+	if e.shadow != nil {
+		if err := e.shadow.index.Delete(docID); err != nil {
+			return fmt.Errorf("failed to remove document %s from shadow index: %w", docID, err)
+		}
+	}
+
 	return nil
 }
 
-// Search performs a full-text search query and returns matching results with highlighted fragments.
+// Search performs a full-text search query and returns matching results with highlighted
+// fragments, along with the plain text and anchor map of their documents, or with their
+// titles and paths only for quick searches.
 func (e *BleveEngine) Search(_ context.Context, query string, opts core.SearchOpts) (*core.SearchResults, error) {
 	if opts.Limit <= 0 {
 		opts.Limit = 20
 	}
 
-	q := buildSearchQuery(query)
+	q := buildSearchQuery(query, opts)
 	req := bleve.NewSearchRequestOptions(q, opts.Limit, opts.Offset, false)
-	req.Highlight = bleve.NewHighlight()
-	req.Fields = []string{fieldRepo, fieldPath, fieldTitle}
+	req.Fields = []string{fieldRepo, fieldPath, fieldTitle, fieldContentType}
 
+	if !opts.Quick {
+		req.Fields = append(req.Fields, fieldContent, fieldAnchorMap)
+		req.Highlight = bleve.NewHighlight()
+		req.AddFacet(fieldLangs, bleve.NewFacetRequest(fieldLangs, langFacetSize))
+		req.AddFacet(fieldContentType, bleve.NewFacetRequest(fieldContentType, contentTypeFacetSize))
+	}
+
+	e.mu.RLock()
 	result, err := e.index.Search(req)
+	e.mu.RUnlock()
+
 	if err != nil {
 		return nil, fmt.Errorf("search failed: %w", err)
 	}
@@ -107,18 +358,136 @@ func (e *BleveEngine) Search(_ context.Context, query string, opts core.SearchOp
 			sr.Title = title
 		}
 
+		if ct, ok := hit.Fields[fieldContentType].(string); ok {
+			sr.ContentType = core.ContentType(ct)
+		}
+
+		anchors, _ := hit.Fields[fieldAnchorMap].(string)
+		content, _ := hit.Fields[fieldContent].(string)
+		setIndexedAnchors(&sr, content, anchors)
+
 		hits = append(hits, sr)
 	}
 
 	return &core.SearchResults{
-		Hits:     hits,
-		Total:    result.Total,
-		Duration: result.Took,
+		Hits:         hits,
+		Langs:        bleveFacetCounts(result.Facets[fieldLangs]),
+		ContentTypes: bleveFacetCounts(result.Facets[fieldContentType]),
+		Total:        result.Total,
+		Duration:     result.Took,
 	}, nil
 }
 
+// Suggest returns up to limit documents whose titles or section headings match prefix,
+// where every word but the last must match a whole word and the last may be the start
+// of one.
+func (e *BleveEngine) Suggest(_ context.Context, prefix string, limit int) ([]core.SearchSuggestion, error) {
+	sq := parseSuggestQuery(prefix)
+	if sq.last == "" {
+		return nil, nil
+	}
+
+	req := bleve.NewSearchRequestOptions(buildSuggestQuery(&sq), limit, 0, false)
+	req.Fields = []string{fieldRepo, fieldPath, fieldTitle, fieldHeadings, fieldHeadingAnchors}
+
+	e.mu.RLock()
+	result, err := e.index.Search(req)
+	e.mu.RUnlock()
+
+	if err != nil {
+		return nil, fmt.Errorf("suggest failed: %w", err)
+	}
+
+	suggestions := make([]core.SearchSuggestion, 0, len(result.Hits))
+
+	for _, hit := range result.Hits {
+		repo, _ := hit.Fields[fieldRepo].(string)
+		path, _ := hit.Fields[fieldPath].(string)
+		title, _ := hit.Fields[fieldTitle].(string)
+
+		suggestions = append(suggestions, sq.suggestion(repo, path, title,
+			bleveStrings(hit.Fields[fieldHeadings]), bleveStrings(hit.Fields[fieldHeadingAnchors])))
+	}
+
+	return suggestions, nil
+}
+
+// buildSuggestQuery matches documents whose title, or one of whose headings, contains the
+// whole words of the query and a word starting with its last word.
+func buildSuggestQuery(sq *suggestQuery) bleveQuery.Query {
+	fields := []string{fieldTitle, fieldHeadings}
+	disjuncts := make([]bleveQuery.Query, 0, len(fields))
+
+	for _, field := range fields {
+		last := bleve.NewPrefixQuery(sq.last)
+		last.SetField(field)
+
+		conjuncts := []bleveQuery.Query{last}
+
+		if len(sq.words) > 0 {
+			words := bleve.NewMatchQuery(strings.Join(sq.words, " "))
+			words.SetField(field)
+			words.SetOperator(bleveQuery.MatchQueryOperatorAnd)
+
+			conjuncts = append(conjuncts, words)
+		}
+
+		disjuncts = append(disjuncts, bleve.NewConjunctionQuery(conjuncts...))
+	}
+
+	return bleve.NewDisjunctionQuery(disjuncts...)
+}
+
+// bleveStrings returns the values of a stored array field, which Bleve returns as a single
+// value when the array has one element.
+func bleveStrings(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		values := make([]string, 0, len(v))
+
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+
+		return values
+	default:
+		return nil
+	}
+}
+
+// bleveFacetCounts returns the term counts of a facet result, which is nil when the facet
+// was not computed.
+func bleveFacetCounts(facet *bleveSearch.FacetResult) []core.FacetCount {
+	if facet == nil || facet.Terms == nil {
+		return nil
+	}
+
+	var counts []core.FacetCount
+
+	for _, term := range facet.Terms.Terms() {
+		counts = append(counts, core.FacetCount{Value: term.Term, Count: term.Count})
+	}
+
+	return counts
+}
+
 // Close closes the Bleve index.
 func (e *BleveEngine) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.shadow != nil {
+		if err := e.shadow.index.Close(); err != nil {
+			slog.Warn("failed to close shadow bleve index", "path", e.shadow.path, "error", err)
+		}
+
+		e.shadow = nil
+	}
+
 	if err := e.index.Close(); err != nil {
 		return fmt.Errorf("failed to close bleve index: %w", err)
 	}
@@ -128,6 +497,9 @@ func (e *BleveEngine) Close() error {
 
 // DocCount returns the number of documents in the index.
 func (e *BleveEngine) DocCount() (uint64, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
 	count, err := e.index.DocCount()
 	if err != nil {
 		return 0, fmt.Errorf("failed to get doc count: %w", err)
@@ -136,6 +508,34 @@ func (e *BleveEngine) DocCount() (uint64, error) {
 	return count, nil
 }
 
+// Size returns the number of bytes the index occupies on disk.
+func (e *BleveEngine) Size(_ context.Context) (int64, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	var size int64
+
+	err := filepath.WalkDir(e.path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		size += info.Size()
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get index size: %w", err)
+	}
+
+	return size, nil
+}
+
 // listByRepoPageSize is the number of documents fetched per page when listing
 // all documents for a repository. The method paginates until all results are
 // collected so no documents are silently truncated.
@@ -157,7 +557,10 @@ func (e *BleveEngine) ListByRepo(_ context.Context, repo string) ([]string, erro
 		req.Fields = []string{}
 		req.SortBy([]string{fieldID})
 
+		e.mu.RLock()
 		result, err := e.index.Search(req)
+		e.mu.RUnlock()
+
 		if err != nil {
 			return nil, fmt.Errorf("failed to list documents for repo %s: %w", repo, err)
 		}
@@ -190,21 +593,40 @@ const longTermThreshold = 7
 
 // field name constants used for indexing and querying.
 const (
-	fieldTitle   = "title"
-	fieldContent = "content"
-	fieldRepo    = "repo"
-	fieldPath    = "path"
-	fieldID      = "_id"
+	fieldTitle       = "title"
+	fieldContent     = "content"
+	fieldRepo        = "repo"
+	fieldPath        = "path"
+	fieldCode        = "code"
+	fieldLangs       = "langs"
+	fieldID          = "_id"
+	fieldContentType = "content_type"
+	fieldTags        = "tags"
 )
 
-// queryTerm represents a single parsed search term.
+// langFacetSize is the maximum number of code languages returned as a search facet.
+const langFacetSize = 10
+
+// contentTypeFacetSize is the maximum number of content types returned as a search facet.
+const contentTypeFacetSize = 10
+
+// queryTerm represents a single parsed search term, or an operator of the query.
 type queryTerm struct {
-	text   string
-	phrase bool // true when the term was enclosed in double quotes
+	text    string
+	field   string  // field the term is qualified with, e.g. "title" for title:getting; empty for plain terms
+	op      queryOp // operator the token stands for; opNone for terms
+	phrase  bool    // true when the term was enclosed in double quotes
+	negated bool    // true when the term or group was preceded by "-"
 }
 
-// splitQueryTerms parses user input into individual search terms.
+// splitQueryTerms parses user input into individual search terms and operators.
 // Double-quoted substrings are treated as phrase terms; unquoted words are split on whitespace.
+// Words qualified with a known field, such as repo:owner/name or title:"getting started",
+// are returned with the field set and the qualifier removed; see cutQualifier.
+// The words OR and AND and parentheses around groups of terms are returned as operators,
+// and a "-" before a term or group marks it negated. A ")" only closes an open group, so
+// words such as "Println()" are kept whole outside groups, and a "(" nested deeper than
+// maxQueryDepth starts a word instead of a group.
 func splitQueryTerms(input string) []queryTerm {
 	var terms []queryTerm
 
@@ -213,6 +635,8 @@ func splitQueryTerms(input string) []queryTerm {
 		return terms
 	}
 
+	depth := 0
+
 	i := 0
 	for i < len(input) {
 		// Skip whitespace.
@@ -221,6 +645,30 @@ func splitQueryTerms(input string) []queryTerm {
 			continue
 		}
 
+		// Handle negation of the following term or group.
+		negated := false
+		if input[i] == '-' && i+1 < len(input) && input[i+1] != ' ' && input[i+1] != '\t' {
+			negated = true
+			i++
+		}
+
+		// Handle group delimiters.
+		if input[i] == '(' && depth < maxQueryDepth {
+			terms = append(terms, queryTerm{op: opOpen, negated: negated})
+			depth++
+			i++
+
+			continue
+		}
+
+		if input[i] == ')' && depth > 0 {
+			terms = append(terms, queryTerm{op: opClose})
+			depth--
+			i++
+
+			continue
+		}
+
 		// Handle quoted phrase.
 		if input[i] == '"' {
 			end := strings.IndexByte(input[i+1:], '"')
@@ -228,7 +676,7 @@ func splitQueryTerms(input string) []queryTerm {
 				// No closing quote -- treat the rest as a single phrase.
 				phrase := strings.TrimSpace(input[i+1:])
 				if phrase != "" {
-					terms = append(terms, queryTerm{text: phrase, phrase: true})
+					terms = append(terms, queryTerm{text: phrase, phrase: true, negated: negated})
 				}
 
 				break
@@ -236,7 +684,7 @@ func splitQueryTerms(input string) []queryTerm {
 
 			phrase := strings.TrimSpace(input[i+1 : i+1+end])
 			if phrase != "" {
-				terms = append(terms, queryTerm{text: phrase, phrase: true})
+				terms = append(terms, queryTerm{text: phrase, phrase: true, negated: negated})
 			}
 
 			i += end + 2 // skip past closing quote
@@ -244,81 +692,460 @@ func splitQueryTerms(input string) []queryTerm {
 			continue
 		}
 
-		// Handle unquoted word.
-		end := strings.IndexAny(input[i:], " \t")
+		// Handle qualified phrase, e.g. title:"getting started".
+		if field, value, ok := cutQualifier(input[i:]); ok && strings.HasPrefix(value, `"`) {
+			start := len(input) - len(value) + 1
+
+			end := strings.IndexByte(input[start:], '"')
+			if end == -1 {
+				end = len(input) - start
+			}
+
+			if phrase := strings.TrimSpace(input[start : start+end]); phrase != "" {
+				terms = append(terms, queryTerm{text: phrase, field: field, phrase: true, negated: negated})
+			}
+
+			i = min(start+end+1, len(input))
+
+			continue
+		}
+
+		// Handle unquoted word, which a ")" ends inside a group.
+		delims := " \t"
+		if depth > 0 {
+			delims += ")"
+		}
+
+		end := strings.IndexAny(input[i:], delims)
 		if end == -1 {
-			terms = append(terms, queryTerm{text: input[i:]})
+			end = len(input) - i
+		}
 
-			break
+		term := queryTerm{text: input[i : i+end], negated: negated}
+
+		switch field, value, ok := cutQualifier(term.text); {
+		case ok:
+			term = queryTerm{text: value, field: field, negated: negated}
+		case !negated && term.text == "OR":
+			term = queryTerm{op: opOr}
+		case !negated && term.text == "AND":
+			term = queryTerm{op: opAnd}
 		}
 
-		terms = append(terms, queryTerm{text: input[i : i+end]})
+		terms = append(terms, term)
 		i += end
 	}
 
 	return terms
 }
 
-// buildSearchQuery constructs a hybrid Bleve query from user input.
+// buildSearchQuery constructs the Bleve query for a search request. The user query is
+// matched against title and content, or against code block contents only when
+// opts.CodeOnly is set, and restricted to documents with code in opts.Lang, to the
+// repositories in opts.Repos, to the single repository opts.Repo and to the content types
+// in opts.ContentTypes if given. repo:, path: and tag: qualifiers in the user query narrow
+// the results further, and -repo:, -path: and -tag: qualifiers exclude documents. A filter
+// or qualifier without query text matches every document passing it.
+func buildSearchQuery(userQuery string, opts core.SearchOpts) bleveQuery.Query {
+	sq := parseSearchQuery(userQuery)
+
+	var q bleveQuery.Query
+
+	switch {
+	case (opts.Lang != "" || len(opts.ContentTypes) > 0 || sq.narrowed()) && sq.expr == nil:
+		q = bleve.NewMatchAllQuery()
+	case opts.CodeOnly:
+		q = buildCodeQuery(sq.expr)
+	default:
+		q = buildTextQuery(sq.expr)
+	}
+
+	if len(sq.excludedRepos) > 0 || len(sq.excludedPaths) > 0 || len(sq.excludedTags) > 0 {
+		var excluded []bleveQuery.Query
+
+		if len(sq.excludedRepos) > 0 {
+			excluded = append(excluded, buildRepoScopeQuery(sq.excludedRepos))
+		}
+
+		if len(sq.excludedPaths) > 0 {
+			excluded = append(excluded, buildPathQuery(sq.excludedPaths))
+		}
+
+		for _, tag := range sq.excludedTags {
+			excluded = append(excluded, buildTagQuery(tag))
+		}
+
+		q = bleveQuery.NewBooleanQuery([]bleveQuery.Query{q}, nil, excluded)
+	}
+
+	if opts.Lang == "" && len(opts.Repos) == 0 && opts.Repo == "" && len(opts.ContentTypes) == 0 &&
+		len(sq.repos) == 0 && len(sq.paths) == 0 && len(sq.tags) == 0 {
+		return q
+	}
+
+	conj := bleve.NewConjunctionQuery(q)
+
+	if len(sq.repos) > 0 {
+		conj.AddQuery(buildRepoScopeQuery(sq.repos))
+	}
+
+	if len(sq.paths) > 0 {
+		conj.AddQuery(buildPathQuery(sq.paths))
+	}
+
+	for _, tag := range sq.tags {
+		conj.AddQuery(buildTagQuery(tag))
+	}
+
+	if opts.Lang != "" {
+		langQ := bleve.NewTermQuery(strings.ToLower(opts.Lang))
+		langQ.SetField(fieldLangs)
+		conj.AddQuery(langQ)
+	}
+
+	if len(opts.Repos) > 0 {
+		conj.AddQuery(buildRepoScopeQuery(opts.Repos))
+	}
+
+	if opts.Repo != "" {
+		repoQ := bleve.NewTermQuery(opts.Repo)
+		repoQ.SetField(fieldRepo)
+		conj.AddQuery(repoQ)
+	}
+
+	if len(opts.ContentTypes) > 0 {
+		typeQueries := make([]bleveQuery.Query, 0, len(opts.ContentTypes))
+
+		for _, ct := range opts.ContentTypes {
+			typeQ := bleve.NewTermQuery(indexedContentType(ct))
+			typeQ.SetField(fieldContentType)
+			typeQueries = append(typeQueries, typeQ)
+		}
+
+		conj.AddQuery(bleve.NewDisjunctionQuery(typeQueries...))
+	}
+
+	return conj
+}
+
+// buildRepoScopeQuery constructs a query matching documents of the given owners or
+// repositories, including their monorepo sub-projects.
+func buildRepoScopeQuery(repos []string) bleveQuery.Query {
+	subQueries := make([]bleveQuery.Query, 0, 2*len(repos))
+
+	for _, repo := range repos {
+		exact := bleve.NewTermQuery(repo)
+		exact.SetField(fieldRepo)
+
+		nested := bleve.NewPrefixQuery(repo + "/")
+		nested.SetField(fieldRepo)
+
+		subQueries = append(subQueries, exact, nested)
+	}
+
+	return bleve.NewDisjunctionQuery(subQueries...)
+}
+
+// buildTagQuery constructs a query matching documents filed under a tag.
+func buildTagQuery(tag string) bleveQuery.Query {
+	q := bleve.NewTermQuery(tag)
+	q.SetField(fieldTags)
+
+	return q
+}
+
+// buildPathQuery constructs a query matching documents whose path matches any of the
+// path: patterns: a wildcard pattern such as "docs/**" or "*.yaml", or else a document
+// path or a directory.
+func buildPathQuery(patterns []string) bleveQuery.Query {
+	subQueries := make([]bleveQuery.Query, 0, 2*len(patterns))
+
+	for _, pattern := range patterns {
+		if wildcard, ok := pathPattern(pattern); ok {
+			q := bleve.NewWildcardQuery(wildcard)
+			q.SetField(fieldPath)
+			subQueries = append(subQueries, q)
+
+			continue
+		}
+
+		exact := bleve.NewTermQuery(pattern)
+		exact.SetField(fieldPath)
+
+		dir := bleve.NewPrefixQuery(strings.TrimSuffix(pattern, "/") + "/")
+		dir.SetField(fieldPath)
+
+		subQueries = append(subQueries, exact, dir)
+	}
+
+	return bleve.NewDisjunctionQuery(subQueries...)
+}
+
+// buildCodeQuery constructs a query against the code field from a query expression.
+// Each term matches either as an analyzed match or as a prefix; quoted terms match as
+// phrases. title: terms match the title instead.
+func buildCodeQuery(expr *queryExpr) bleveQuery.Query {
+	if expr == nil {
+		return bleve.NewMatchNoneQuery()
+	}
+
+	b := exprQueryBuilder{term: buildCodeTermQuery, exclusion: buildCodeExclusionQuery}
+
+	return b.build(expr)
+}
+
+// buildCodeTermQuery creates the query of a single term against the code field.
+func buildCodeTermQuery(term queryTerm) bleveQuery.Query {
+	if term.field == fieldTitle {
+		return buildTitleQuery(term)
+	}
+
+	if term.phrase {
+		phraseQ := bleve.NewMatchPhraseQuery(term.text)
+		phraseQ.SetField(fieldCode)
+
+		return phraseQ
+	}
+
+	matchQ := bleve.NewMatchQuery(term.text)
+	matchQ.SetField(fieldCode)
+
+	prefixQ := bleve.NewPrefixQuery(strings.ToLower(term.text))
+	prefixQ.SetField(fieldCode)
+	prefixQ.SetBoost(0.5)
+
+	return bleve.NewDisjunctionQuery(matchQ, prefixQ)
+}
+
+// buildCodeExclusionQuery creates the query of a negated term against the code field,
+// or the title for title: terms.
+func buildCodeExclusionQuery(term queryTerm) bleveQuery.Query {
+	field := fieldCode
+	if term.field == fieldTitle {
+		field = fieldTitle
+	}
+
+	return buildExactQuery(term, field)
+}
+
+// buildExactQuery creates a query matching a term in the fields as the analyzer indexes
+// it, without prefix or fuzzy variants, or as a phrase when quoted.
+func buildExactQuery(term queryTerm, fields ...string) bleveQuery.Query {
+	queries := make([]bleveQuery.Query, 0, len(fields))
+
+	for _, field := range fields {
+		if term.phrase {
+			q := bleve.NewMatchPhraseQuery(term.text)
+			q.SetField(field)
+			queries = append(queries, q)
+
+			continue
+		}
+
+		q := bleve.NewMatchQuery(term.text)
+		q.SetField(field)
+		q.SetOperator(bleveQuery.MatchQueryOperatorAnd)
+		queries = append(queries, q)
+	}
+
+	if len(queries) == 1 {
+		return queries[0]
+	}
+
+	return bleve.NewDisjunctionQuery(queries...)
+}
+
+// exprQueryBuilder constructs the query of a boolean query expression.
+type exprQueryBuilder struct {
+	// term creates the query of a term.
+	term func(queryTerm) bleveQuery.Query
+	// exclusion creates the query of a negated term, which matches the documents to
+	// exclude. It matches exactly, so excluding a word does not exclude similar words.
+	exclusion func(queryTerm) bleveQuery.Query
+	// fullPhrase, when set, creates the query AND nodes of plain words also match, for
+	// the words as a whole; see buildTextQuery.
+	fullPhrase func(words string) bleveQuery.Query
+}
+
+// excluding returns the builder of the queries of negated expressions.
+func (b exprQueryBuilder) excluding() exprQueryBuilder {
+	return exprQueryBuilder{term: b.exclusion, exclusion: b.exclusion}
+}
+
+// build constructs the query of expr.
+func (b exprQueryBuilder) build(expr *queryExpr) bleveQuery.Query {
+	if expr.negated {
+		return bleveQuery.NewBooleanQuery(
+			[]bleveQuery.Query{bleve.NewMatchAllQuery()}, nil, []bleveQuery.Query{b.excluding().buildPositive(expr)},
+		)
+	}
+
+	return b.buildPositive(expr)
+}
+
+// buildPositive constructs the query of expr, ignoring whether expr itself is negated.
+func (b exprQueryBuilder) buildPositive(expr *queryExpr) bleveQuery.Query {
+	switch expr.op {
+	case exprTerm:
+		return b.term(expr.term)
+	case exprOr:
+		alternatives := make([]bleveQuery.Query, 0, len(expr.exprs))
+		for _, sub := range expr.exprs {
+			alternatives = append(alternatives, b.build(sub))
+		}
+
+		return bleve.NewDisjunctionQuery(alternatives...)
+	}
+
+	var must, mustNot []bleveQuery.Query
+
+	for _, sub := range expr.exprs {
+		if sub.negated {
+			mustNot = append(mustNot, b.excluding().buildPositive(sub))
+			continue
+		}
+
+		must = append(must, b.build(sub))
+	}
+
+	if len(mustNot) > 0 {
+		if len(must) == 0 {
+			must = append(must, bleve.NewMatchAllQuery())
+		}
+
+		return bleveQuery.NewBooleanQuery(must, nil, mustNot)
+	}
+
+	perWordQuery := bleve.NewConjunctionQuery(must...)
+
+	if words, ok := expr.words(); ok && b.fullPhrase != nil {
+		return bleve.NewDisjunctionQuery(perWordQuery, b.fullPhrase(words))
+	}
+
+	return perWordQuery
+}
+
+// indexedContentType returns the value indexed for a content type. Documents published
+// without a content type are markdown.
+func indexedContentType(ct core.ContentType) string {
+	if ct == "" {
+		return string(core.ContentTypeMarkdown)
+	}
+
+	return string(ct)
+}
+
+// joinCodeBlocks concatenates code block contents for indexing and returns the
+// distinct languages of the blocks, in order of first appearance.
+func joinCodeBlocks(code []core.CodeBlock) (string, []string) {
+	var (
+		buf   strings.Builder
+		langs []string
+	)
+
+	for _, block := range code {
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+
+		buf.WriteString(block.Code)
+
+		if block.Lang != "" && !slices.Contains(langs, block.Lang) {
+			langs = append(langs, block.Lang)
+		}
+	}
+
+	return buf.String(), langs
+}
+
+// buildTextQuery constructs a hybrid Bleve query from the expression of a user query.
 // For each term it creates a disjunction of match, prefix, and fuzzy queries
-// targeting both title and content fields with appropriate boost values.
-// Multiple terms are combined with a conjunction in the per-term query path so
-// all specified terms must match there, while some inputs may also receive a
-// full-phrase alternative via a top-level disjunction (see below).
+// targeting both title and content fields with appropriate boost values, or
+// the title only for title: terms. Terms are combined as the expression says:
+// terms next to each other with a conjunction, so all of them must match,
+// alternatives with a disjunction, and negated terms and groups exclude documents.
 //
 // For multi-word unquoted queries a full-phrase MatchQuery (AND operator) on
-// both fields is added as an alternative path via a top-level DisjunctionQuery.
+// both fields is added as an alternative path via a DisjunctionQuery.
 // This handles English stopwords (e.g. "all", "a", "the") that Bleve's default
 // analyzer strips from both the index and per-word MatchQueries, which would
 // otherwise cause the per-word ConjunctionQuery to return zero results.
 // The MatchQuery AND operator skips stopwords internally so the search
 // "List all pets" correctly finds documents containing "list" and "pets".
-func buildSearchQuery(userQuery string) bleveQuery.Query {
-	terms := splitQueryTerms(userQuery)
-	if len(terms) == 0 {
+//
+// This fallback is intentionally skipped for groups that contain quoted
+// phrases (e.g. `welcome "getting started" guide`) because passing the raw
+// input to MatchQuery would ignore the quotes and treat the phrase terms as
+// individual unordered tokens, breaking exact-phrase semantics. Groups with
+// title: terms or negated terms skip it too, as it matches any field and
+// cannot exclude anything.
+func buildTextQuery(expr *queryExpr) bleveQuery.Query {
+	if expr == nil {
 		return bleve.NewMatchNoneQuery()
 	}
 
-	termQueries := make([]bleveQuery.Query, 0, len(terms))
+	b := exprQueryBuilder{term: buildTextTermQuery, exclusion: buildTextExclusionQuery, fullPhrase: buildFullPhraseQueries}
 
-	// Count unquoted word terms to determine whether the stopword-tolerant
-	// full-phrase fallback should be applied.
-	wordTermCount := 0
+	return b.build(expr)
+}
 
-	for _, term := range terms {
-		var disj bleveQuery.Query
-		if term.phrase {
-			disj = buildPhraseQueries(term.text)
-		} else {
-			disj = buildTermQueries(term.text)
-			wordTermCount++
-		}
+// buildTextTermQuery creates the query of a single term against the title and content.
+func buildTextTermQuery(term queryTerm) bleveQuery.Query {
+	switch {
+	case term.field == fieldTitle:
+		return buildTitleQuery(term)
+	case term.phrase:
+		return buildPhraseQueries(term.text)
+	default:
+		return buildTermQueries(term.text)
+	}
+}
 
-		termQueries = append(termQueries, disj)
+// buildTextExclusionQuery creates the query of a negated term against the title and
+// content, or the title only for title: terms.
+func buildTextExclusionQuery(term queryTerm) bleveQuery.Query {
+	if term.field == fieldTitle {
+		return buildExactQuery(term, fieldTitle)
 	}
 
-	var perWordQuery bleveQuery.Query
-	if len(termQueries) == 1 {
-		perWordQuery = termQueries[0]
-	} else {
-		perWordQuery = bleve.NewConjunctionQuery(termQueries...)
+	return buildExactQuery(term, fieldTitle, fieldContent)
+}
+
+// buildTitleQuery creates the query of a title: term, matching it against the title
+// only: as a phrase when quoted, or else as a match, prefix or fuzzy match.
+func buildTitleQuery(term queryTerm) bleveQuery.Query {
+	if term.phrase {
+		q := bleve.NewMatchPhraseQuery(term.text)
+		q.SetField(fieldTitle)
+		q.SetBoost(10.0)
+
+		return q
 	}
 
-	// For pure multi-word unquoted queries, also try the full phrase as a
-	// single MatchQuery with AND operator on each field. Bleve's MatchQuery
-	// applies the same text analysis pipeline as the indexer, so stopwords are
-	// removed from the requirement set rather than causing the whole query to
-	// fail (e.g. "List all pets" → "list" AND "pet").
-	//
-	// This fallback is intentionally skipped for mixed queries that contain
-	// quoted phrases (e.g. `welcome "getting started" guide`) because passing
-	// the raw input to MatchQuery would ignore the quotes and treat the phrase
-	// terms as individual unordered tokens, breaking exact-phrase semantics.
-	if wordTermCount > 1 && wordTermCount == len(terms) {
-		return bleve.NewDisjunctionQuery(perWordQuery, buildFullPhraseQueries(userQuery))
+	match := bleve.NewMatchQuery(term.text)
+	match.SetField(fieldTitle)
+	match.SetBoost(6.0)
+
+	prefix := bleve.NewPrefixQuery(strings.ToLower(term.text))
+	prefix.SetField(fieldTitle)
+	prefix.SetBoost(3.0)
+
+	subQueries := []bleveQuery.Query{match, prefix}
+
+	if len(term.text) >= minFuzzyTermLength {
+		fuzziness := 1
+		if len(term.text) >= longTermThreshold {
+			fuzziness = 2
+		}
+
+		fuzzy := bleve.NewFuzzyQuery(strings.ToLower(term.text))
+		fuzzy.SetField(fieldTitle)
+		fuzzy.SetFuzziness(fuzziness)
+
+		subQueries = append(subQueries, fuzzy)
 	}
 
-	return perWordQuery
+	return bleve.NewDisjunctionQuery(subQueries...)
 }
 
 // buildFullPhraseQueries creates a disjunction of MatchQuery (AND operator) for
@@ -417,6 +1244,18 @@ func buildIndexMapping() mapping.IndexMapping {
 	docMapping.AddFieldMappingsAt(fieldContent, textFieldMapping)
 	docMapping.AddFieldMappingsAt(fieldRepo, keywordFieldMapping)
 	docMapping.AddFieldMappingsAt(fieldPath, keywordFieldMapping)
+	docMapping.AddFieldMappingsAt(fieldCode, textFieldMapping)
+	docMapping.AddFieldMappingsAt(fieldLangs, keywordFieldMapping)
+	docMapping.AddFieldMappingsAt(fieldContentType, keywordFieldMapping)
+	docMapping.AddFieldMappingsAt(fieldHeadings, textFieldMapping)
+	docMapping.AddFieldMappingsAt(fieldTags, keywordFieldMapping)
+
+	anchorFieldMapping := bleve.NewKeywordFieldMapping()
+	anchorFieldMapping.Store = true
+	anchorFieldMapping.Index = false
+
+	docMapping.AddFieldMappingsAt(fieldHeadingAnchors, anchorFieldMapping)
+	docMapping.AddFieldMappingsAt(fieldAnchorMap, anchorFieldMapping)
 	docMapping.AddFieldMappingsAt("id", keywordFieldMapping)
 
 	indexMapping := bleve.NewIndexMapping()
@@ -0,0 +1,97 @@
+package search
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/require"
+)
+
+// benchWords is the vocabulary of the generated corpus.
+var benchWords = strings.Fields(`deploy rollback queue worker database replica latency budget alert dashboard
+	kubernetes helm secret token rotate certificate ingress gateway billing invoice payment refund
+	migration schema index cache eviction timeout retry backoff circuit breaker tracing metrics`)
+
+// benchDoc returns the i-th document of a generated corpus spread over ten repositories,
+// along with its plain text and code blocks.
+func benchDoc(i int) (core.Document, string, []core.CodeBlock) {
+	var b strings.Builder
+
+	for j := range 400 {
+		b.WriteString(benchWords[(i*7+j*13)%len(benchWords)])
+
+		if j%12 == 11 {
+			b.WriteString(".\n")
+		} else {
+			b.WriteByte(' ')
+		}
+	}
+
+	repo := fmt.Sprintf("org/service-%d", i%10)
+	path := fmt.Sprintf("docs/%s-%d.md", benchWords[i%len(benchWords)], i)
+	title := fmt.Sprintf("How to %s the %s", benchWords[i%len(benchWords)], benchWords[(i+5)%len(benchWords)])
+
+	doc := core.Document{
+		ID:        repo + "/" + path,
+		Repo:      repo,
+		Path:      path,
+		Title:     title,
+		UpdatedAt: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	code := []core.CodeBlock{{Lang: "bash", Code: fmt.Sprintf("kubectl rollout restart deployment/worker-%d", i)}}
+
+	return doc, title + "\n" + b.String(), code
+}
+
+// newBenchEngine returns a Bleve engine holding docs documents of the generated corpus.
+func newBenchEngine(b *testing.B, docs int) *BleveEngine {
+	b.Helper()
+
+	engine, err := NewBleve(filepath.Join(b.TempDir(), "bench.bleve"), BleveConfig{})
+	require.NoError(b, err)
+
+	b.Cleanup(func() { _ = engine.Close() })
+
+	for i := range docs {
+		doc, plainText, code := benchDoc(i)
+		require.NoError(b, engine.Index(b.Context(), doc, plainText, code, nil))
+	}
+
+	return engine
+}
+
+func BenchmarkBleveEngine_Index(b *testing.B) {
+	engine := newBenchEngine(b, 0)
+
+	i := 0
+
+	for b.Loop() {
+		doc, plainText, code := benchDoc(i)
+		if err := engine.Index(b.Context(), doc, plainText, code, nil); err != nil {
+			b.Fatal(err)
+		}
+
+		i++
+	}
+}
+
+func BenchmarkBleveEngine_Search(b *testing.B) {
+	engine := newBenchEngine(b, 1000)
+
+	queries := []string{"rollback", "database replica latency", `"circuit breaker"`, "deploy*"}
+
+	for _, q := range queries {
+		b.Run(q, func(b *testing.B) {
+			for b.Loop() {
+				if _, err := engine.Search(b.Context(), q, core.SearchOpts{Limit: 10}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+// bleveShadow is an index built next to the live index of a BleveEngine, in the directory
+// of the live index with a ".shadow" suffix.
+type bleveShadow struct {
+	*BleveEngine
+	live *BleveEngine
+}
+
+// CreateShadow creates an empty index with the current mapping next to the live one. Until
+// it is promoted or discarded, documents indexed into or removed from the live index are
+// indexed into or removed from the shadow index as well. A shadow index left behind by an
+// interrupted rebuild is replaced.
+func (e *BleveEngine) CreateShadow(_ context.Context) (core.ShadowIndex, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.shadow != nil {
+		return nil, fmt.Errorf("%w: a shadow index already exists", core.ErrConflict)
+	}
+
+	path := e.path + ".shadow"
+
+	if err := os.RemoveAll(path); err != nil {
+		return nil, fmt.Errorf("failed to remove stale shadow index: %w", err)
+	}
+
+	index, err := createBleveIndex(path, e.runtimeCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	e.shadow = &BleveEngine{index: index, path: path, runtimeCfg: e.runtimeCfg}
+
+	return &bleveShadow{BleveEngine: e.shadow, live: e}, nil
+}
+
+// Promote replaces the live index with the shadow index. Searches wait while the index
+// directories are swapped. If the shadow index cannot be moved into place, the previous
+// live index is restored.
+func (s *bleveShadow) Promote(_ context.Context) error {
+	e := s.live
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.shadow != s.BleveEngine {
+		return errors.New("shadow index is no longer active")
+	}
+
+	e.shadow = nil
+
+	if err := s.index.Close(); err != nil {
+		return fmt.Errorf("failed to close shadow index: %w", err)
+	}
+
+	if err := e.index.Close(); err != nil {
+		return fmt.Errorf("failed to close live index: %w", err)
+	}
+
+	backup := e.path + ".old"
+
+	if err := os.RemoveAll(backup); err != nil {
+		return e.reopen(fmt.Errorf("failed to remove previous index backup: %w", err))
+	}
+
+	if err := os.Rename(e.path, backup); err != nil {
+		return e.reopen(fmt.Errorf("failed to move live index aside: %w", err))
+	}
+
+	if err := os.Rename(s.path, e.path); err != nil {
+		if restoreErr := os.Rename(backup, e.path); restoreErr != nil {
+			err = errors.Join(err, restoreErr)
+		}
+
+		return e.reopen(fmt.Errorf("failed to move shadow index into place: %w", err))
+	}
+
+	if err := e.reopen(nil); err != nil {
+		return err
+	}
+
+	e.rebuilt = false
+
+	if err := os.RemoveAll(backup); err != nil {
+		slog.Warn("failed to remove previous bleve index", "path", backup, "error", err)
+	}
+
+	return nil
+}
+
+// Discard closes and deletes the shadow index.
+func (s *bleveShadow) Discard(_ context.Context) error {
+	e := s.live
+
+	e.mu.Lock()
+
+	if e.shadow == s.BleveEngine {
+		e.shadow = nil
+	}
+
+	e.mu.Unlock()
+
+	if err := s.index.Close(); err != nil && !errors.Is(err, bleve.ErrorIndexClosed) {
+		return fmt.Errorf("failed to close shadow index: %w", err)
+	}
+
+	if err := os.RemoveAll(s.path); err != nil {
+		return fmt.Errorf("failed to remove shadow index: %w", err)
+	}
+
+	return nil
+}
+
+// reopen opens the index at the engine's path after a promotion, returning cause, if any,
+// joined with the error to reopen. It must be called with mu held.
+func (e *BleveEngine) reopen(cause error) error {
+	index, err := bleve.OpenUsing(e.path, e.runtimeCfg)
+	if err != nil {
+		return errors.Join(cause, fmt.Errorf("failed to reopen bleve index: %w", err))
+	}
+
+	e.index = index
+
+	return cause
+}
@@ -0,0 +1,87 @@
+package search
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBleveEngine_ShadowPromote(t *testing.T) {
+	indexPath := filepath.Join(t.TempDir(), "test.bleve")
+
+	engine, err := NewBleve(indexPath, BleveConfig{})
+	require.NoError(t, err)
+
+	old := core.Document{ID: "owner/repo/old.md", Repo: "owner/repo", Path: "old.md", Title: "Old Guide"}
+	require.NoError(t, engine.Index(t.Context(), old, "old content", nil, nil))
+
+	shadow, err := engine.CreateShadow(t.Context())
+	require.NoError(t, err)
+
+	_, err = engine.CreateShadow(t.Context())
+	require.ErrorIs(t, err, core.ErrConflict)
+
+	rebuilt := core.Document{ID: "owner/repo/rebuilt.md", Repo: "owner/repo", Path: "rebuilt.md", Title: "Rebuilt Guide"}
+	require.NoError(t, shadow.Index(t.Context(), rebuilt, "rebuilt content", nil, nil))
+
+	live := core.Document{ID: "owner/repo/live.md", Repo: "owner/repo", Path: "live.md", Title: "Live Guide"}
+	require.NoError(t, engine.Index(t.Context(), live, "published during the rebuild", nil, nil))
+
+	ids, err := engine.ListByRepo(t.Context(), "owner/repo")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{old.ID, live.ID}, ids, "the live index keeps serving")
+
+	ids, err = shadow.ListByRepo(t.Context(), "owner/repo")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{rebuilt.ID, live.ID}, ids, "changes to the live index reach the shadow index")
+
+	require.NoError(t, shadow.Promote(t.Context()))
+	assert.NoDirExists(t, indexPath+".shadow")
+	assert.NoDirExists(t, indexPath+".old")
+
+	ids, err = engine.ListByRepo(t.Context(), "owner/repo")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{rebuilt.ID, live.ID}, ids)
+
+	require.NoError(t, engine.Remove(t.Context(), live.ID))
+	require.NoError(t, engine.Close())
+
+	reopened, err := NewBleve(indexPath, BleveConfig{})
+	require.NoError(t, err)
+
+	defer reopened.Close()
+
+	count, err := reopened.DocCount()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), count, "the promoted index is kept on restart")
+}
+
+func TestBleveEngine_ShadowDiscard(t *testing.T) {
+	indexPath := filepath.Join(t.TempDir(), "test.bleve")
+
+	engine, err := NewBleve(indexPath, BleveConfig{})
+	require.NoError(t, err)
+
+	defer engine.Close()
+
+	shadow, err := engine.CreateShadow(t.Context())
+	require.NoError(t, err)
+	assert.DirExists(t, indexPath+".shadow")
+
+	require.NoError(t, shadow.Discard(t.Context()))
+	assert.NoDirExists(t, indexPath+".shadow")
+	require.Error(t, shadow.Promote(t.Context()))
+
+	doc := core.Document{ID: "owner/repo/a.md", Repo: "owner/repo", Path: "a.md", Title: "A"}
+	require.NoError(t, engine.Index(t.Context(), doc, "a", nil, nil))
+
+	count, err := engine.DocCount()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), count)
+
+	_, err = engine.CreateShadow(t.Context())
+	require.NoError(t, err, "a new shadow index can be created after discarding one")
+}
@@ -2,6 +2,7 @@ package search
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"testing"
 	"time"
@@ -15,7 +16,7 @@ func TestNewBleve(t *testing.T) {
 	tmpDir := t.TempDir()
 	indexPath := filepath.Join(tmpDir, "test.bleve")
 
-	engine, err := NewBleve(indexPath)
+	engine, err := NewBleve(indexPath, BleveConfig{})
 	require.NoError(t, err)
 	assert.NotNil(t, engine)
 
@@ -26,7 +27,7 @@ func TestBleveEngine_IndexAndSearch(t *testing.T) {
 	tmpDir := t.TempDir()
 	indexPath := filepath.Join(tmpDir, "test.bleve")
 
-	engine, err := NewBleve(indexPath)
+	engine, err := NewBleve(indexPath, BleveConfig{})
 	require.NoError(t, err)
 
 	defer engine.Close()
@@ -41,7 +42,7 @@ func TestBleveEngine_IndexAndSearch(t *testing.T) {
 		UpdatedAt: time.Now(),
 	}
 
-	err = engine.Index(t.Context(), doc, "Getting Started Guide Welcome to the project")
+	err = engine.Index(t.Context(), doc, "Getting Started Guide Welcome to the project", nil, nil)
 	require.NoError(t, err)
 
 	// Search for the document.
@@ -57,7 +58,7 @@ func TestBleveEngine_Remove(t *testing.T) {
 	tmpDir := t.TempDir()
 	indexPath := filepath.Join(tmpDir, "test.bleve")
 
-	engine, err := NewBleve(indexPath)
+	engine, err := NewBleve(indexPath, BleveConfig{})
 	require.NoError(t, err)
 
 	defer engine.Close()
@@ -72,7 +73,7 @@ func TestBleveEngine_Remove(t *testing.T) {
 		UpdatedAt: time.Now(),
 	}
 
-	err = engine.Index(t.Context(), doc, "To Remove content")
+	err = engine.Index(t.Context(), doc, "To Remove content", nil, nil)
 	require.NoError(t, err)
 
 	err = engine.Remove(t.Context(), "owner/repo/to-remove.md")
@@ -87,7 +88,7 @@ func TestBleveEngine_DocCount(t *testing.T) {
 	tmpDir := t.TempDir()
 	indexPath := filepath.Join(tmpDir, "test.bleve")
 
-	engine, err := NewBleve(indexPath)
+	engine, err := NewBleve(indexPath, BleveConfig{})
 	require.NoError(t, err)
 
 	defer engine.Close()
@@ -104,7 +105,7 @@ func TestBleveEngine_DocCount(t *testing.T) {
 		UpdatedAt: time.Now(),
 	}
 
-	err = engine.Index(t.Context(), doc, "Test document content")
+	err = engine.Index(t.Context(), doc, "Test document content", nil, nil)
 	require.NoError(t, err)
 
 	count, err = engine.DocCount()
@@ -112,11 +113,22 @@ func TestBleveEngine_DocCount(t *testing.T) {
 	assert.Equal(t, uint64(1), count)
 }
 
+func TestBleveEngine_Size(t *testing.T) {
+	engine, err := NewBleve(filepath.Join(t.TempDir(), "test.bleve"), BleveConfig{})
+	require.NoError(t, err)
+
+	defer engine.Close()
+
+	size, err := engine.Size(t.Context())
+	require.NoError(t, err)
+	assert.Positive(t, size)
+}
+
 func TestBleveEngine_SearchEmpty(t *testing.T) {
 	tmpDir := t.TempDir()
 	indexPath := filepath.Join(tmpDir, "test.bleve")
 
-	engine, err := NewBleve(indexPath)
+	engine, err := NewBleve(indexPath, BleveConfig{})
 	require.NoError(t, err)
 
 	defer engine.Close()
@@ -131,7 +143,7 @@ func TestBleveEngine_SearchDefaultLimit(t *testing.T) {
 	tmpDir := t.TempDir()
 	indexPath := filepath.Join(tmpDir, "test.bleve")
 
-	engine, err := NewBleve(indexPath)
+	engine, err := NewBleve(indexPath, BleveConfig{})
 	require.NoError(t, err)
 
 	defer engine.Close()
@@ -144,7 +156,7 @@ func TestBleveEngine_SearchDefaultLimit(t *testing.T) {
 		UpdatedAt: time.Now(),
 	}
 
-	err = engine.Index(t.Context(), doc, "Default limit content for testing")
+	err = engine.Index(t.Context(), doc, "Default limit content for testing", nil, nil)
 	require.NoError(t, err)
 
 	// Search with Limit=0 to trigger the default limit branch (opts.Limit <= 0).
@@ -158,7 +170,7 @@ func TestBleveEngine_SearchFieldExtraction(t *testing.T) {
 	tmpDir := t.TempDir()
 	indexPath := filepath.Join(tmpDir, "test.bleve")
 
-	engine, err := NewBleve(indexPath)
+	engine, err := NewBleve(indexPath, BleveConfig{})
 	require.NoError(t, err)
 
 	defer engine.Close()
@@ -171,7 +183,7 @@ func TestBleveEngine_SearchFieldExtraction(t *testing.T) {
 		UpdatedAt: time.Now(),
 	}
 
-	err = engine.Index(t.Context(), doc, "Field extraction test content")
+	err = engine.Index(t.Context(), doc, "Field extraction test content", nil, nil)
 	require.NoError(t, err)
 
 	results, err := engine.Search(t.Context(), "field extraction", core.SearchOpts{Limit: 10})
@@ -185,18 +197,55 @@ func TestBleveEngine_SearchFieldExtraction(t *testing.T) {
 	assert.Equal(t, "Field Extraction Test", hit.Title)
 }
 
+func TestBleveEngine_SearchAnchorMap(t *testing.T) {
+	engine, err := NewBleve(filepath.Join(t.TempDir(), "test.bleve"), BleveConfig{})
+	require.NoError(t, err)
+
+	defer engine.Close()
+
+	anchors := core.AnchorMap{{ID: "install", Text: "Install", Offset: 6, Level: 2}}
+
+	withMap := core.Document{ID: "owner/repo/guide.md", Repo: "owner/repo", Path: "guide.md", Title: "Guide", Anchors: anchors}
+	require.NoError(t, engine.Index(t.Context(), withMap, "Intro\nInstall\nrun the installer", nil, nil))
+
+	withoutMap := core.Document{ID: "owner/repo/old.md", Repo: "owner/repo", Path: "old.md", Title: "Old"}
+	require.NoError(t, engine.Index(t.Context(), withoutMap, "the legacy installer", nil, nil))
+
+	results, err := engine.Search(t.Context(), "installer", core.SearchOpts{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, results.Hits, 2)
+
+	for _, hit := range results.Hits {
+		switch hit.ID {
+		case withMap.ID:
+			assert.Equal(t, anchors, hit.Anchors)
+			assert.Equal(t, "Intro\nInstall\nrun the installer", hit.PlainText)
+		default:
+			assert.Nil(t, hit.Anchors)
+			assert.Empty(t, hit.PlainText)
+		}
+	}
+
+	// Quick searches return titles and paths only.
+	results, err = engine.Search(t.Context(), "installer", core.SearchOpts{Limit: 10, Quick: true})
+	require.NoError(t, err)
+	require.NotEmpty(t, results.Hits)
+	assert.Nil(t, results.Hits[0].Anchors)
+	assert.Empty(t, results.Hits[0].PlainText)
+}
+
 func TestBleveEngine_CloseExplicit(t *testing.T) {
 	tmpDir := t.TempDir()
 	indexPath := filepath.Join(tmpDir, "test.bleve")
 
-	engine, err := NewBleve(indexPath)
+	engine, err := NewBleve(indexPath, BleveConfig{})
 	require.NoError(t, err)
 
 	err = engine.Close()
 	require.NoError(t, err)
 
 	// Verify we can reopen after explicit close.
-	engine2, err := NewBleve(indexPath)
+	engine2, err := NewBleve(indexPath, BleveConfig{})
 	require.NoError(t, err)
 	assert.NotNil(t, engine2)
 
@@ -208,7 +257,7 @@ func TestBleveEngine_ReopenIndex(t *testing.T) {
 	indexPath := filepath.Join(tmpDir, "test.bleve")
 
 	// Create and populate.
-	engine, err := NewBleve(indexPath)
+	engine, err := NewBleve(indexPath, BleveConfig{})
 	require.NoError(t, err)
 
 	doc := core.Document{
@@ -219,14 +268,14 @@ func TestBleveEngine_ReopenIndex(t *testing.T) {
 		UpdatedAt: time.Now(),
 	}
 
-	err = engine.Index(t.Context(), doc, "Persistent document content")
+	err = engine.Index(t.Context(), doc, "Persistent document content", nil, nil)
 	require.NoError(t, err)
 
 	err = engine.Close()
 	require.NoError(t, err)
 
 	// Reopen and verify.
-	engine2, err := NewBleve(indexPath)
+	engine2, err := NewBleve(indexPath, BleveConfig{})
 	require.NoError(t, err)
 
 	defer engine2.Close()
@@ -312,6 +361,79 @@ func TestSplitQueryTerms(t *testing.T) {
 				{text: "foo bar", phrase: true},
 			},
 		},
+		{
+			name:  "field qualifiers",
+			input: `deploy repo:acme/api PATH:docs/** title:getting`,
+			expected: []queryTerm{
+				{text: "deploy"},
+				{text: "acme/api", field: fieldRepo},
+				{text: "docs/**", field: fieldPath},
+				{text: "getting", field: fieldTitle},
+			},
+		},
+		{
+			name:  "qualified phrase",
+			input: `title:"getting started" guide`,
+			expected: []queryTerm{
+				{text: "getting started", field: fieldTitle, phrase: true},
+				{text: "guide"},
+			},
+		},
+		{
+			name:  "unclosed qualified phrase",
+			input: `title:"getting started`,
+			expected: []queryTerm{
+				{text: "getting started", field: fieldTitle, phrase: true},
+			},
+		},
+		{
+			name:  "operators and negation",
+			input: `deploy -kubernetes OR -"helm chart" AND nomad -repo:acme/old`,
+			expected: []queryTerm{
+				{text: "deploy"},
+				{text: "kubernetes", negated: true},
+				{op: opOr},
+				{text: "helm chart", phrase: true, negated: true},
+				{op: opAnd},
+				{text: "nomad"},
+				{text: "acme/old", field: fieldRepo, negated: true},
+			},
+		},
+		{
+			name:  "groups",
+			input: `deploy -(kubernetes OR "helm chart") (nomad)`,
+			expected: []queryTerm{
+				{text: "deploy"},
+				{op: opOpen, negated: true},
+				{text: "kubernetes"},
+				{op: opOr},
+				{text: "helm chart", phrase: true},
+				{op: opClose},
+				{op: opOpen},
+				{text: "nomad"},
+				{op: opClose},
+			},
+		},
+		{
+			name:  "parentheses and dashes in words",
+			input: `Println() - or -- co-op`,
+			expected: []queryTerm{
+				{text: "Println()"},
+				{text: "-"},
+				{text: "or"},
+				{text: "-", negated: true},
+				{text: "co-op"},
+			},
+		},
+		{
+			name:  "unknown and empty qualifiers",
+			input: `author:alice http://example.com repo:`,
+			expected: []queryTerm{
+				{text: "author:alice"},
+				{text: "http://example.com"},
+				{text: "repo:"},
+			},
+		},
 	}
 
 	for _, tc := range tests {
@@ -326,7 +448,7 @@ func TestBleveEngine_SearchPartialWord(t *testing.T) {
 	tmpDir := t.TempDir()
 	indexPath := filepath.Join(tmpDir, "test.bleve")
 
-	engine, err := NewBleve(indexPath)
+	engine, err := NewBleve(indexPath, BleveConfig{})
 	require.NoError(t, err)
 
 	defer engine.Close()
@@ -339,7 +461,7 @@ func TestBleveEngine_SearchPartialWord(t *testing.T) {
 		UpdatedAt: time.Now(),
 	}
 
-	err = engine.Index(t.Context(), doc, "This is a comprehensive markdown formatting guide")
+	err = engine.Index(t.Context(), doc, "This is a comprehensive markdown formatting guide", nil, nil)
 	require.NoError(t, err)
 
 	// Searching for "mark" should match "markdown" via prefix query.
@@ -353,7 +475,7 @@ func TestBleveEngine_SearchPartialWordGet(t *testing.T) {
 	tmpDir := t.TempDir()
 	indexPath := filepath.Join(tmpDir, "test.bleve")
 
-	engine, err := NewBleve(indexPath)
+	engine, err := NewBleve(indexPath, BleveConfig{})
 	require.NoError(t, err)
 
 	defer engine.Close()
@@ -366,7 +488,7 @@ func TestBleveEngine_SearchPartialWordGet(t *testing.T) {
 		UpdatedAt: time.Now(),
 	}
 
-	err = engine.Index(t.Context(), doc, "Getting started with the project setup and configuration")
+	err = engine.Index(t.Context(), doc, "Getting started with the project setup and configuration", nil, nil)
 	require.NoError(t, err)
 
 	// Searching for "get" should match "getting" via prefix query.
@@ -380,7 +502,7 @@ func TestBleveEngine_SearchFuzzyTypo(t *testing.T) {
 	tmpDir := t.TempDir()
 	indexPath := filepath.Join(tmpDir, "test.bleve")
 
-	engine, err := NewBleve(indexPath)
+	engine, err := NewBleve(indexPath, BleveConfig{})
 	require.NoError(t, err)
 
 	defer engine.Close()
@@ -393,7 +515,7 @@ func TestBleveEngine_SearchFuzzyTypo(t *testing.T) {
 		UpdatedAt: time.Now(),
 	}
 
-	err = engine.Index(t.Context(), doc, "This is a comprehensive markdown formatting guide")
+	err = engine.Index(t.Context(), doc, "This is a comprehensive markdown formatting guide", nil, nil)
 	require.NoError(t, err)
 
 	// Searching for "markdwon" (typo) should match "markdown" via fuzzy query.
@@ -407,7 +529,7 @@ func TestBleveEngine_SearchQuotedPhrase(t *testing.T) {
 	tmpDir := t.TempDir()
 	indexPath := filepath.Join(tmpDir, "test.bleve")
 
-	engine, err := NewBleve(indexPath)
+	engine, err := NewBleve(indexPath, BleveConfig{})
 	require.NoError(t, err)
 
 	defer engine.Close()
@@ -420,7 +542,7 @@ func TestBleveEngine_SearchQuotedPhrase(t *testing.T) {
 		UpdatedAt: time.Now(),
 	}
 
-	err = engine.Index(t.Context(), doc, "Getting started with the project setup and configuration")
+	err = engine.Index(t.Context(), doc, "Getting started with the project setup and configuration", nil, nil)
 	require.NoError(t, err)
 
 	// Quoted phrase search should match exact phrase.
@@ -434,7 +556,7 @@ func TestBleveEngine_SearchMultipleTerms(t *testing.T) {
 	tmpDir := t.TempDir()
 	indexPath := filepath.Join(tmpDir, "test.bleve")
 
-	engine, err := NewBleve(indexPath)
+	engine, err := NewBleve(indexPath, BleveConfig{})
 	require.NoError(t, err)
 
 	defer engine.Close()
@@ -455,10 +577,10 @@ func TestBleveEngine_SearchMultipleTerms(t *testing.T) {
 		UpdatedAt: time.Now(),
 	}
 
-	err = engine.Index(t.Context(), matchDoc, "Learn markdown formatting for your documents")
+	err = engine.Index(t.Context(), matchDoc, "Learn markdown formatting for your documents", nil, nil)
 	require.NoError(t, err)
 
-	err = engine.Index(t.Context(), noMatchDoc, "Welcome to the project introduction")
+	err = engine.Index(t.Context(), noMatchDoc, "Welcome to the project introduction", nil, nil)
 	require.NoError(t, err)
 
 	// Both terms must match -- only the markdown guide has both "markdown" and "formatting".
@@ -477,13 +599,13 @@ func TestBleveEngine_SearchMultipleTerms(t *testing.T) {
 func TestBleveEngine_SearchBoostRanking(t *testing.T) {
 	tests := []struct {
 		name        string
-		doc1        core.Document
 		doc1Content string
-		doc2        core.Document
 		doc2Content string
 		query       string
 		expectedID  string
 		reason      string
+		doc1        core.Document
+		doc2        core.Document
 	}{
 		{
 			name: "exact match ranks higher than prefix match",
@@ -536,15 +658,15 @@ func TestBleveEngine_SearchBoostRanking(t *testing.T) {
 			tmpDir := t.TempDir()
 			indexPath := filepath.Join(tmpDir, "test.bleve")
 
-			engine, err := NewBleve(indexPath)
+			engine, err := NewBleve(indexPath, BleveConfig{})
 			require.NoError(t, err)
 
 			defer engine.Close()
 
-			err = engine.Index(t.Context(), tc.doc1, tc.doc1Content)
+			err = engine.Index(t.Context(), tc.doc1, tc.doc1Content, nil, nil)
 			require.NoError(t, err)
 
-			err = engine.Index(t.Context(), tc.doc2, tc.doc2Content)
+			err = engine.Index(t.Context(), tc.doc2, tc.doc2Content, nil, nil)
 			require.NoError(t, err)
 
 			results, err := engine.Search(t.Context(), tc.query, core.SearchOpts{Limit: 10})
@@ -560,7 +682,7 @@ func TestBleveEngine_SearchEmptyQuery(t *testing.T) {
 	tmpDir := t.TempDir()
 	indexPath := filepath.Join(tmpDir, "test.bleve")
 
-	engine, err := NewBleve(indexPath)
+	engine, err := NewBleve(indexPath, BleveConfig{})
 	require.NoError(t, err)
 
 	defer engine.Close()
@@ -573,7 +695,7 @@ func TestBleveEngine_SearchEmptyQuery(t *testing.T) {
 		UpdatedAt: time.Now(),
 	}
 
-	err = engine.Index(t.Context(), doc, "Some content here")
+	err = engine.Index(t.Context(), doc, "Some content here", nil, nil)
 	require.NoError(t, err)
 
 	// Empty query should return no results (MatchNoneQuery).
@@ -591,7 +713,7 @@ func TestBleveEngine_SearchHighlightingWorks(t *testing.T) {
 	tmpDir := t.TempDir()
 	indexPath := filepath.Join(tmpDir, "test.bleve")
 
-	engine, err := NewBleve(indexPath)
+	engine, err := NewBleve(indexPath, BleveConfig{})
 	require.NoError(t, err)
 
 	defer engine.Close()
@@ -604,7 +726,7 @@ func TestBleveEngine_SearchHighlightingWorks(t *testing.T) {
 		UpdatedAt: time.Now(),
 	}
 
-	err = engine.Index(t.Context(), doc, "This document contains markdown formatting examples")
+	err = engine.Index(t.Context(), doc, "This document contains markdown formatting examples", nil, nil)
 	require.NoError(t, err)
 
 	results, err := engine.Search(t.Context(), "markdown", core.SearchOpts{Limit: 10})
@@ -620,15 +742,15 @@ func TestBleveEngine_ListByRepo(t *testing.T) {
 	tmpDir := t.TempDir()
 	indexPath := filepath.Join(tmpDir, "test.bleve")
 
-	engine, err := NewBleve(indexPath)
+	engine, err := NewBleve(indexPath, BleveConfig{})
 	require.NoError(t, err)
 
 	defer engine.Close()
 
 	// Index documents across two different repos.
 	docs := []struct {
-		doc     core.Document
 		content string
+		doc     core.Document
 	}{
 		{
 			doc: core.Document{
@@ -663,7 +785,7 @@ func TestBleveEngine_ListByRepo(t *testing.T) {
 	}
 
 	for _, d := range docs {
-		err = engine.Index(t.Context(), d.doc, d.content)
+		err = engine.Index(t.Context(), d.doc, d.content, nil, nil)
 		require.NoError(t, err)
 	}
 
@@ -684,7 +806,7 @@ func TestBleveEngine_ListByRepoEmpty(t *testing.T) {
 	tmpDir := t.TempDir()
 	indexPath := filepath.Join(tmpDir, "test.bleve")
 
-	engine, err := NewBleve(indexPath)
+	engine, err := NewBleve(indexPath, BleveConfig{})
 	require.NoError(t, err)
 
 	defer engine.Close()
@@ -699,7 +821,7 @@ func TestBleveEngine_ListByRepoManyDocuments(t *testing.T) {
 	tmpDir := t.TempDir()
 	indexPath := filepath.Join(tmpDir, "test.bleve")
 
-	engine, err := NewBleve(indexPath)
+	engine, err := NewBleve(indexPath, BleveConfig{})
 	require.NoError(t, err)
 
 	defer engine.Close()
@@ -721,7 +843,7 @@ func TestBleveEngine_ListByRepoManyDocuments(t *testing.T) {
 			UpdatedAt: time.Now(),
 		}
 
-		err = engine.Index(t.Context(), doc, fmt.Sprintf("Content of document %d", i))
+		err = engine.Index(t.Context(), doc, fmt.Sprintf("Content of document %d", i), nil, nil)
 		require.NoError(t, err)
 
 		expected = append(expected, doc.ID)
@@ -737,7 +859,7 @@ func TestBleveEngine_SearchStopwordInPhrase(t *testing.T) {
 	tmpDir := t.TempDir()
 	indexPath := filepath.Join(tmpDir, "test.bleve")
 
-	engine, err := NewBleve(indexPath)
+	engine, err := NewBleve(indexPath, BleveConfig{})
 	require.NoError(t, err)
 
 	defer engine.Close()
@@ -752,7 +874,7 @@ func TestBleveEngine_SearchStopwordInPhrase(t *testing.T) {
 		UpdatedAt: time.Now(),
 	}
 
-	err = engine.Index(t.Context(), doc, "Petstore API\nGET /pets\nList all pets\nReturns all pets from the system.")
+	err = engine.Index(t.Context(), doc, "Petstore API\nGET /pets\nList all pets\nReturns all pets from the system.", nil, nil)
 	require.NoError(t, err)
 
 	tests := []struct {
@@ -790,7 +912,7 @@ func TestBleveEngine_SearchMixedPhraseAndWordQuery(t *testing.T) {
 	tmpDir := t.TempDir()
 	indexPath := filepath.Join(tmpDir, "test.bleve")
 
-	engine, err := NewBleve(indexPath)
+	engine, err := NewBleve(indexPath, BleveConfig{})
 	require.NoError(t, err)
 
 	defer engine.Close()
@@ -814,10 +936,10 @@ func TestBleveEngine_SearchMixedPhraseAndWordQuery(t *testing.T) {
 		UpdatedAt: time.Now(),
 	}
 
-	err = engine.Index(t.Context(), matchDoc, "getting started guide for new users")
+	err = engine.Index(t.Context(), matchDoc, "getting started guide for new users", nil, nil)
 	require.NoError(t, err)
 
-	err = engine.Index(t.Context(), noMatchDoc, "getting the guide started for new users")
+	err = engine.Index(t.Context(), noMatchDoc, "getting the guide started for new users", nil, nil)
 	require.NoError(t, err)
 
 	// Quoted phrase + unquoted word: exact phrase semantics must be preserved.
@@ -834,7 +956,7 @@ func TestBleveEngine_ListByRepoAfterRemove(t *testing.T) {
 	tmpDir := t.TempDir()
 	indexPath := filepath.Join(tmpDir, "test.bleve")
 
-	engine, err := NewBleve(indexPath)
+	engine, err := NewBleve(indexPath, BleveConfig{})
 	require.NoError(t, err)
 
 	defer engine.Close()
@@ -847,7 +969,7 @@ func TestBleveEngine_ListByRepoAfterRemove(t *testing.T) {
 		UpdatedAt: time.Now(),
 	}
 
-	err = engine.Index(t.Context(), doc, "content")
+	err = engine.Index(t.Context(), doc, "content", nil, nil)
 	require.NoError(t, err)
 
 	ids, err := engine.ListByRepo(t.Context(), "owner/repo")
@@ -928,3 +1050,552 @@ func TestExtractFragments(t *testing.T) {
 		})
 	}
 }
+
+func TestBleveEngine_SearchCode(t *testing.T) {
+	engine, err := NewBleve(filepath.Join(t.TempDir(), "test.bleve"), BleveConfig{})
+	require.NoError(t, err)
+
+	defer engine.Close()
+
+	goDoc := core.Document{ID: "owner/repo/server.md", Repo: "owner/repo", Path: "server.md", Title: "Server"}
+	err = engine.Index(t.Context(), goDoc, "Running the server\nhttp.ListenAndServe(addr, mux)", []core.CodeBlock{
+		{Lang: "go", Code: "http.ListenAndServe(addr, mux)"},
+		{Lang: "bash", Code: "go run ./cmd/server"},
+	}, nil)
+	require.NoError(t, err)
+
+	pyDoc := core.Document{ID: "owner/repo/client.md", Repo: "owner/repo", Path: "client.md", Title: "Client"}
+	err = engine.Index(t.Context(), pyDoc, "Calling the server\nrequests.get(url)", []core.CodeBlock{
+		{Lang: "python", Code: "requests.get(url)"},
+	}, nil)
+	require.NoError(t, err)
+
+	proseDoc := core.Document{ID: "owner/repo/about.md", Repo: "owner/repo", Path: "about.md", Title: "About"}
+	err = engine.Index(t.Context(), proseDoc, "The server handles requests", nil, nil)
+	require.NoError(t, err)
+
+	t.Run("code only matches code blocks", func(t *testing.T) {
+		results, err := engine.Search(t.Context(), "requests", core.SearchOpts{CodeOnly: true})
+		require.NoError(t, err)
+		require.Len(t, results.Hits, 1)
+		assert.Equal(t, "owner/repo/client.md", results.Hits[0].ID)
+		assert.NotEmpty(t, results.Hits[0].ContentFragments)
+	})
+
+	t.Run("lang filter restricts results", func(t *testing.T) {
+		results, err := engine.Search(t.Context(), "server", core.SearchOpts{Lang: "go"})
+		require.NoError(t, err)
+		require.Len(t, results.Hits, 1)
+		assert.Equal(t, "owner/repo/server.md", results.Hits[0].ID)
+	})
+
+	t.Run("lang filter without text", func(t *testing.T) {
+		results, err := engine.Search(t.Context(), "", core.SearchOpts{Lang: "Python"})
+		require.NoError(t, err)
+		require.Len(t, results.Hits, 1)
+		assert.Equal(t, "owner/repo/client.md", results.Hits[0].ID)
+	})
+
+	t.Run("language facets", func(t *testing.T) {
+		results, err := engine.Search(t.Context(), "server", core.SearchOpts{})
+		require.NoError(t, err)
+		assert.Len(t, results.Hits, 3)
+		assert.ElementsMatch(t, []core.FacetCount{
+			{Value: "go", Count: 1},
+			{Value: "bash", Count: 1},
+			{Value: "python", Count: 1},
+		}, results.Langs)
+	})
+}
+
+func TestJoinCodeBlocks(t *testing.T) {
+	code, langs := joinCodeBlocks([]core.CodeBlock{
+		{Lang: "go", Code: "a()"},
+		{Lang: "", Code: "b"},
+		{Lang: "go", Code: "c()"},
+	})
+
+	assert.Equal(t, "a()\nb\nc()", code)
+	assert.Equal(t, []string{"go"}, langs)
+}
+
+func TestNewBleve_SchemaVersion(t *testing.T) {
+	indexPath := filepath.Join(t.TempDir(), "test.bleve")
+
+	engine, err := NewBleve(indexPath, BleveConfig{})
+	require.NoError(t, err)
+	assert.False(t, engine.NeedsReindex())
+
+	version, err := readSchemaVersion(engine.index)
+	require.NoError(t, err)
+	assert.Equal(t, bleveSchemaVersion, version)
+
+	require.NoError(t, engine.Index(t.Context(), core.Document{ID: "owner/repo/a.md", Repo: "owner/repo", Path: "a.md"}, "alpha", nil, nil))
+	require.NoError(t, engine.Close())
+
+	engine, err = NewBleve(indexPath, BleveConfig{})
+	require.NoError(t, err)
+
+	defer engine.Close()
+
+	assert.False(t, engine.NeedsReindex())
+
+	count, err := engine.DocCount()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), count)
+}
+
+func TestNewBleve_OutdatedSchemaRebuilds(t *testing.T) {
+	tests := []struct {
+		name    string
+		version []byte
+	}{
+		{name: "unversioned index", version: nil},
+		{name: "older version", version: []byte("1")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			indexPath := filepath.Join(t.TempDir(), "test.bleve")
+
+			engine, err := NewBleve(indexPath, BleveConfig{})
+			require.NoError(t, err)
+
+			if tt.version == nil {
+				require.NoError(t, engine.index.DeleteInternal(schemaVersionKey))
+			} else {
+				require.NoError(t, engine.index.SetInternal(schemaVersionKey, tt.version))
+			}
+
+			require.NoError(t, engine.Index(t.Context(), core.Document{ID: "owner/repo/a.md", Repo: "owner/repo", Path: "a.md"}, "alpha", nil, nil))
+			require.NoError(t, engine.Close())
+
+			engine, err = NewBleve(indexPath, BleveConfig{})
+			require.NoError(t, err)
+
+			defer engine.Close()
+
+			assert.True(t, engine.NeedsReindex())
+
+			count, err := engine.DocCount()
+			require.NoError(t, err)
+			assert.Zero(t, count)
+
+			version, err := readSchemaVersion(engine.index)
+			require.NoError(t, err)
+			assert.Equal(t, bleveSchemaVersion, version)
+		})
+	}
+}
+
+func TestNewBleve_NewerSchemaRejected(t *testing.T) {
+	indexPath := filepath.Join(t.TempDir(), "test.bleve")
+
+	engine, err := NewBleve(indexPath, BleveConfig{})
+	require.NoError(t, err)
+	require.NoError(t, engine.index.SetInternal(schemaVersionKey, []byte(fmt.Sprint(bleveSchemaVersion+1))))
+	require.NoError(t, engine.Close())
+
+	_, err = NewBleve(indexPath, BleveConfig{})
+	assert.ErrorContains(t, err, "is newer than supported version")
+}
+
+func TestNewBleve_Tuning(t *testing.T) {
+	indexPath := filepath.Join(t.TempDir(), "test.bleve")
+	cfg := BleveConfig{
+		PersistInterval:      50 * time.Millisecond,
+		MaxSegmentDocs:       100000,
+		MaxInMemoryMergeMiB:  64,
+		PersisterWorkers:     2,
+		MaxSegmentsPerTier:   4,
+		SegmentsPerMergeTask: 4,
+	}
+
+	engine, err := NewBleve(indexPath, cfg)
+	require.NoError(t, err)
+
+	doc := core.Document{ID: "owner/repo/doc.md", Repo: "owner/repo", Path: "doc.md", Title: "Tuned", UpdatedAt: time.Now()}
+	require.NoError(t, engine.Index(t.Context(), doc, "tuned index content", nil, nil))
+
+	results, err := engine.Search(t.Context(), "tuned", core.SearchOpts{})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), results.Total)
+	require.NoError(t, engine.Close())
+
+	meta, err := os.ReadFile(filepath.Join(indexPath, "index_meta.json"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(meta), "scorchPersisterOptions", "tuning options are not stored with the index")
+
+	engine, err = NewBleve(indexPath, BleveConfig{})
+	require.NoError(t, err)
+
+	defer engine.Close()
+
+	count, err := engine.DocCount()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), count)
+}
+
+func TestBleveConfig_RuntimeConfig(t *testing.T) {
+	tests := []struct {
+		want    map[string]any
+		name    string
+		wantErr string
+		cfg     BleveConfig
+	}{
+		{name: "defaults", cfg: BleveConfig{}, want: map[string]any{}},
+		{
+			name: "persister and merge options",
+			cfg:  BleveConfig{PersistInterval: 2 * time.Second, MaxInMemoryMergeMiB: 8, MaxSegmentsPerTier: 5},
+			want: map[string]any{
+				"scorchPersisterOptions": map[string]any{"PersisterNapTimeMSec": int64(2000), "MaxSizeInMemoryMergePerWorker": 8 << 20},
+				"scorchMergePlanOptions": map[string]any{"MaxSegmentsPerTier": 5},
+			},
+		},
+		{name: "negative", cfg: BleveConfig{PersisterWorkers: -1}, wantErr: "must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.cfg.runtimeConfig()
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBleveEngine_SearchRepoScope(t *testing.T) {
+	engine, err := NewBleve(filepath.Join(t.TempDir(), "test.bleve"), BleveConfig{})
+	require.NoError(t, err)
+
+	defer engine.Close()
+
+	for _, doc := range []core.Document{
+		{ID: "team-x/api/deploy.md", Repo: "team-x/api", Path: "deploy.md", Title: "Deploy"},
+		{ID: "team-x/mono/billing/deploy.md", Repo: "team-x/mono/billing", Path: "deploy.md", Title: "Deploy"},
+		{ID: "team-xyz/api/deploy.md", Repo: "team-xyz/api", Path: "deploy.md", Title: "Deploy"},
+		{ID: "team-y/web/deploy.md", Repo: "team-y/web", Path: "deploy.md", Title: "Deploy"},
+	} {
+		require.NoError(t, engine.Index(t.Context(), doc, "How to deploy", nil, nil))
+	}
+
+	ids := func(results *core.SearchResults) []string {
+		var got []string
+		for _, hit := range results.Hits {
+			got = append(got, hit.ID)
+		}
+
+		return got
+	}
+
+	results, err := engine.Search(t.Context(), "deploy", core.SearchOpts{Repos: []string{"team-x"}})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"team-x/api/deploy.md", "team-x/mono/billing/deploy.md"}, ids(results))
+
+	results, err = engine.Search(t.Context(), "deploy", core.SearchOpts{Repos: []string{"team-x/mono", "team-y/web"}})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"team-x/mono/billing/deploy.md", "team-y/web/deploy.md"}, ids(results))
+
+	results, err = engine.Search(t.Context(), "deploy", core.SearchOpts{Repo: "team-x/mono"})
+	require.NoError(t, err)
+	assert.Empty(t, ids(results), "the repository filter does not include monorepo projects")
+
+	results, err = engine.Search(t.Context(), "deploy", core.SearchOpts{Repos: []string{"team-x"}, Repo: "team-x/api"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"team-x/api/deploy.md"}, ids(results))
+}
+
+func TestBleveEngine_SearchContentTypes(t *testing.T) {
+	engine, err := NewBleve(filepath.Join(t.TempDir(), "test.bleve"), BleveConfig{})
+	require.NoError(t, err)
+
+	defer engine.Close()
+
+	for _, doc := range []core.Document{
+		{ID: "acme/api/users.md", Repo: "acme/api", Path: "users.md", Title: "Users", ContentType: core.ContentTypeMarkdown},
+		{ID: "acme/api/guide.md", Repo: "acme/api", Path: "guide.md", Title: "Guide"},
+		{ID: "acme/api/openapi.yaml", Repo: "acme/api", Path: "openapi.yaml", Title: "Users API", ContentType: core.ContentTypeOpenAPI},
+	} {
+		require.NoError(t, engine.Index(t.Context(), doc, "Manage users", nil, nil))
+	}
+
+	results, err := engine.Search(t.Context(), "users", core.SearchOpts{})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), results.Total)
+	assert.Equal(t, []core.FacetCount{{Value: "markdown", Count: 2}, {Value: "openapi", Count: 1}}, results.ContentTypes,
+		"documents without a content type are counted as markdown")
+
+	results, err = engine.Search(t.Context(), "users", core.SearchOpts{ContentTypes: []core.ContentType{core.ContentTypeOpenAPI}})
+	require.NoError(t, err)
+	require.Len(t, results.Hits, 1)
+	assert.Equal(t, "acme/api/openapi.yaml", results.Hits[0].ID)
+	assert.Equal(t, core.ContentTypeOpenAPI, results.Hits[0].ContentType)
+
+	results, err = engine.Search(t.Context(), "guide", core.SearchOpts{})
+	require.NoError(t, err)
+	require.Len(t, results.Hits, 1)
+	assert.Equal(t, core.ContentTypeMarkdown, results.Hits[0].ContentType, "documents without a content type are returned as markdown")
+
+	results, err = engine.Search(t.Context(), "", core.SearchOpts{ContentTypes: []core.ContentType{core.ContentTypeMarkdown}})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), results.Total, "a content type filter without query text matches every document of the type")
+}
+
+func TestBleveEngine_Suggest(t *testing.T) {
+	engine, err := NewBleve(filepath.Join(t.TempDir(), "test.bleve"), BleveConfig{})
+	require.NoError(t, err)
+
+	defer engine.Close()
+
+	docs := []struct {
+		headings []core.Heading
+		doc      core.Document
+	}{
+		{
+			doc:      core.Document{ID: "owner/repo/install.md", Repo: "owner/repo", Path: "install.md", Title: "Installation Guide"},
+			headings: []core.Heading{{Level: 2, Text: "Requirements", ID: "requirements"}},
+		},
+		{
+			doc: core.Document{ID: "owner/repo/deploy.md", Repo: "owner/repo", Path: "deploy.md", Title: "Deploying"},
+			headings: []core.Heading{
+				{Level: 2, Text: "Rolling Updates", ID: "rolling-updates"},
+				{Level: 2, Text: "Install the Agent", ID: "install-the-agent"},
+			},
+		},
+	}
+
+	for _, d := range docs {
+		require.NoError(t, engine.Index(t.Context(), d.doc, d.doc.Title, nil, d.headings))
+	}
+
+	suggestions, err := engine.Suggest(t.Context(), "Inst", 10)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []core.SearchSuggestion{
+		{Repo: "owner/repo", Path: "install.md", Title: "Installation Guide"},
+		{Repo: "owner/repo", Path: "deploy.md", Title: "Deploying", Heading: "Install the Agent", Anchor: "install-the-agent"},
+	}, suggestions)
+
+	suggestions, err = engine.Suggest(t.Context(), "rolling upd", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []core.SearchSuggestion{
+		{Repo: "owner/repo", Path: "deploy.md", Title: "Deploying", Heading: "Rolling Updates", Anchor: "rolling-updates"},
+	}, suggestions)
+
+	suggestions, err = engine.Suggest(t.Context(), "roll install", 10)
+	require.NoError(t, err)
+	assert.Empty(t, suggestions, "words before the last must match whole words of the same field")
+
+	suggestions, err = engine.Suggest(t.Context(), "  -- ", 10)
+	require.NoError(t, err)
+	assert.Empty(t, suggestions)
+}
+
+func TestBleveEngine_SearchQualifiers(t *testing.T) {
+	engine, err := NewBleve(filepath.Join(t.TempDir(), "test.bleve"), BleveConfig{})
+	require.NoError(t, err)
+
+	defer engine.Close()
+
+	for _, doc := range []core.Document{
+		{ID: "acme/api/docs/deploy.md", Repo: "acme/api", Path: "docs/deploy.md", Title: "Deploying the API", Tags: []string{"ops", "ci"}},
+		{ID: "acme/api/docs/guides/getting-started.md", Repo: "acme/api", Path: "docs/guides/getting-started.md", Title: "Getting started", Tags: []string{"ops"}},
+		{ID: "acme/api/README.md", Repo: "acme/api", Path: "README.md", Title: "API"},
+		{ID: "acme/web/docs/deploy.md", Repo: "acme/web", Path: "docs/deploy.md", Title: "Deploying the web app"},
+	} {
+		require.NoError(t, engine.Index(t.Context(), doc, "How to deploy after getting the code", nil, nil))
+	}
+
+	ids := func(results *core.SearchResults) []string {
+		var got []string
+		for _, hit := range results.Hits {
+			got = append(got, hit.ID)
+		}
+
+		return got
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{
+			name:  "repo qualifier",
+			query: "deploy repo:acme/web",
+			want:  []string{"acme/web/docs/deploy.md"},
+		},
+		{
+			name:  "repo qualifiers match any",
+			query: "repo:acme/web repo:acme/api deploy",
+			want:  []string{"acme/api/docs/deploy.md", "acme/api/docs/guides/getting-started.md", "acme/api/README.md", "acme/web/docs/deploy.md"},
+		},
+		{
+			name:  "path pattern",
+			query: "deploy path:docs/**",
+			want:  []string{"acme/api/docs/deploy.md", "acme/api/docs/guides/getting-started.md", "acme/web/docs/deploy.md"},
+		},
+		{
+			name:  "path directory",
+			query: "deploy path:docs/guides repo:acme/api",
+			want:  []string{"acme/api/docs/guides/getting-started.md"},
+		},
+		{
+			name:  "exact path",
+			query: "path:README.md",
+			want:  []string{"acme/api/README.md"},
+		},
+		{
+			name:  "title qualifier",
+			query: "title:getting",
+			want:  []string{"acme/api/docs/guides/getting-started.md"},
+		},
+		{
+			name:  "title phrase",
+			query: `title:"web app"`,
+			want:  []string{"acme/web/docs/deploy.md"},
+		},
+		{
+			name:  "tag qualifiers match all",
+			query: "deploy tag:ops tag:CI",
+			want:  []string{"acme/api/docs/deploy.md"},
+		},
+		{
+			name:  "excluded tag",
+			query: "tag:ops -tag:ci",
+			want:  []string{"acme/api/docs/guides/getting-started.md"},
+		},
+		{
+			name:  "unknown qualifier is a plain term",
+			query: "deploy lang:go",
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := engine.Search(t.Context(), tt.query, core.SearchOpts{})
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tt.want, ids(results))
+		})
+	}
+}
+
+func TestBleveEngine_SearchBooleanOperators(t *testing.T) {
+	engine, err := NewBleve(filepath.Join(t.TempDir(), "test.bleve"), BleveConfig{})
+	require.NoError(t, err)
+
+	defer engine.Close()
+
+	for _, doc := range []core.Document{
+		{ID: "acme/ops/kubernetes.md", Repo: "acme/ops", Path: "kubernetes.md", Title: "Kubernetes"},
+		{ID: "acme/ops/nomad.md", Repo: "acme/ops", Path: "nomad.md", Title: "Nomad"},
+		{ID: "acme/ops/vms.md", Repo: "acme/ops", Path: "vms.md", Title: "Virtual machines"},
+		{ID: "acme/old/nomad.md", Repo: "acme/old", Path: "nomad.md", Title: "Nomad"},
+	} {
+		content := map[string]string{
+			"acme/ops/kubernetes.md": "How to deploy services on kubernetes clusters",
+			"acme/ops/nomad.md":      "How to deploy services with nomad jobs",
+			"acme/ops/vms.md":        "How to deploy services on virtual machines",
+			"acme/old/nomad.md":      "Legacy nomad setup",
+		}[doc.ID]
+
+		require.NoError(t, engine.Index(t.Context(), doc, content, nil, nil))
+	}
+
+	ids := func(results *core.SearchResults) []string {
+		var got []string
+		for _, hit := range results.Hits {
+			got = append(got, hit.ID)
+		}
+
+		return got
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{
+			name:  "negation",
+			query: "deploy -kubernetes",
+			want:  []string{"acme/ops/nomad.md", "acme/ops/vms.md"},
+		},
+		{
+			name:  "negation matches exactly",
+			query: "deploy -kube -virtul",
+			want:  []string{"acme/ops/kubernetes.md", "acme/ops/nomad.md", "acme/ops/vms.md"},
+		},
+		{
+			name:  "negation and alternative",
+			query: "deploy -kubernetes OR nomad",
+			want:  []string{"acme/ops/nomad.md", "acme/ops/vms.md", "acme/old/nomad.md"},
+		},
+		{
+			name:  "group",
+			query: "deploy (kubernetes OR virtual)",
+			want:  []string{"acme/ops/kubernetes.md", "acme/ops/vms.md"},
+		},
+		{
+			name:  "negated group",
+			query: "deploy -(kubernetes OR virtual)",
+			want:  []string{"acme/ops/nomad.md"},
+		},
+		{
+			name:  "negated phrase",
+			query: `services -"virtual machines"`,
+			want:  []string{"acme/ops/kubernetes.md", "acme/ops/nomad.md"},
+		},
+		{
+			name:  "negation only",
+			query: "-deploy",
+			want:  []string{"acme/old/nomad.md"},
+		},
+		{
+			name:  "excluded repository",
+			query: "nomad -repo:acme/old",
+			want:  []string{"acme/ops/nomad.md"},
+		},
+		{
+			name:  "excluded path",
+			query: "deploy -path:kubernetes.md -path:vm*",
+			want:  []string{"acme/ops/nomad.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := engine.Search(t.Context(), tt.query, core.SearchOpts{})
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tt.want, ids(results))
+		})
+	}
+}
+
+func TestBleveEngine_SearchQuick(t *testing.T) {
+	engine, err := NewBleve(filepath.Join(t.TempDir(), "test.bleve"), BleveConfig{})
+	require.NoError(t, err)
+
+	defer engine.Close()
+
+	doc := core.Document{ID: "acme/api/deploy.md", Repo: "acme/api", Path: "deploy.md", Title: "Deploying"}
+	require.NoError(t, engine.Index(t.Context(), doc, "How to deploy the API", []core.CodeBlock{{Lang: "go", Code: "deploy()"}}, nil))
+
+	results, err := engine.Search(t.Context(), "deploy", core.SearchOpts{Quick: true})
+	require.NoError(t, err)
+	require.Len(t, results.Hits, 1)
+
+	hit := results.Hits[0]
+	assert.Equal(t, "acme/api", hit.Repo)
+	assert.Equal(t, "deploy.md", hit.Path)
+	assert.Equal(t, "Deploying", hit.Title)
+	assert.Empty(t, hit.TitleFragments)
+	assert.Empty(t, hit.ContentFragments)
+	assert.Empty(t, results.Langs)
+	assert.Empty(t, results.ContentTypes)
+}
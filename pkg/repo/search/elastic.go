@@ -3,11 +3,13 @@ package search
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/elastic/go-elasticsearch/v8"
@@ -18,16 +20,17 @@ import (
 type ElasticSearchConfig struct {
 	Index     string   `mapstructure:"index"`
 	Username  string   `mapstructure:"username"`
-	Password  string   `mapstructure:"password"`
-	APIKey    string   `mapstructure:"api_key"`
+	Password  string   `mapstructure:"password" redact:"true"`
+	APIKey    string   `mapstructure:"api_key" redact:"true"`
 	CACert    string   `mapstructure:"ca_cert"`
 	Addresses []string `mapstructure:"addresses"`
 }
 
 // ElasticEngine implements full-text search using Elasticsearch.
 type ElasticEngine struct {
-	client *elasticsearch.Client
-	index  string
+	client  *elasticsearch.Client
+	index   string
+	rebuilt bool
 }
 
 // NewElastic creates a new Elasticsearch search engine.
@@ -81,15 +84,8 @@ func readCACert(path string) ([]byte, error) {
 }
 
 // Index adds or updates a document in the Elasticsearch index.
-func (e *ElasticEngine) Index(ctx context.Context, doc core.Document, plainText string) error { //nolint:gocritic // Document is passed by value for immutability
-	body := map[string]string{
-		fieldTitle:   doc.Title,
-		fieldContent: plainText,
-		fieldRepo:    doc.Repo,
-		fieldPath:    doc.Path,
-	}
-
-	data, err := json.Marshal(body)
+func (e *ElasticEngine) Index(ctx context.Context, doc core.Document, plainText string, code []core.CodeBlock, headings []core.Heading) error { //nolint:gocritic // Document is passed by value for immutability
+	data, err := json.Marshal(buildDocumentBody(doc, plainText, code, headings))
 	if err != nil {
 		return fmt.Errorf("failed to marshal document %s: %w", doc.ID, err)
 	}
@@ -98,7 +94,7 @@ func (e *ElasticEngine) Index(ctx context.Context, doc core.Document, plainText
 		e.index,
 		bytes.NewReader(data),
 		e.client.Index.WithContext(ctx),
-		e.client.Index.WithDocumentID(doc.ID),
+		e.client.Index.WithDocumentID(documentKey(doc.ID)),
 		e.client.Index.WithRefresh("false"),
 	)
 	if err != nil {
@@ -117,7 +113,7 @@ func (e *ElasticEngine) Index(ctx context.Context, doc core.Document, plainText
 func (e *ElasticEngine) Remove(ctx context.Context, docID string) error {
 	resp, err := e.client.Delete(
 		e.index,
-		docID,
+		documentKey(docID),
 		e.client.Delete.WithContext(ctx),
 		e.client.Delete.WithRefresh("false"),
 	)
@@ -140,21 +136,19 @@ func (e *ElasticEngine) Search(ctx context.Context, query string, opts core.Sear
 		opts.Limit = 20
 	}
 
-	esQuery := e.buildSearchQuery(query)
+	esQuery := e.buildSearchQuery(query, opts)
 
 	body := map[string]any{
 		dslQuery:  esQuery,
 		dslSize:   opts.Limit,
 		"from":    opts.Offset,
-		dslSource: []string{fieldRepo, fieldPath, fieldTitle},
-		dslHighlight: map[string]any{
-			dslFields: map[string]any{
-				fieldTitle:   map[string]any{dslNumberOfFragments: 3},
-				fieldContent: map[string]any{"fragment_size": 200, dslNumberOfFragments: 3},
-			},
-			"pre_tags":  []string{"<mark>"},
-			"post_tags": []string{"</mark>"},
-		},
+		dslSource: []string{fieldRepo, fieldPath, fieldTitle, fieldContentType},
+	}
+
+	if !opts.Quick {
+		body[dslSource] = []string{fieldRepo, fieldPath, fieldTitle, fieldContentType, fieldContent, fieldAnchorMap}
+		body[dslHighlight] = buildHighlight()
+		body[dslAggs] = buildFacetAggs()
 	}
 
 	data, err := json.Marshal(body)
@@ -190,24 +184,89 @@ func (e *ElasticEngine) Search(ctx context.Context, query string, opts core.Sear
 
 	for _, hit := range result.Hits.Hits {
 		sr := core.SearchResult{
-			ID:               hit.ID,
+			ID:               keyDocumentID(hit.ID),
 			Score:            hit.Score,
 			Repo:             hit.Source.Repo,
 			Path:             hit.Source.Path,
 			Title:            hit.Source.Title,
+			ContentType:      core.ContentType(hit.Source.ContentType),
 			TitleFragments:   hit.Highlight[fieldTitle],
-			ContentFragments: hit.Highlight[fieldContent],
+			ContentFragments: append(hit.Highlight[fieldContent], hit.Highlight[fieldCode]...),
 		}
+		setIndexedAnchors(&sr, hit.Source.Content, hit.Source.AnchorMap)
 		hits = append(hits, sr)
 	}
 
 	return &core.SearchResults{
-		Hits:     hits,
-		Total:    result.Hits.Total.Value,
-		Duration: duration,
+		Hits:         hits,
+		Langs:        result.Aggregations.Langs.facets(),
+		ContentTypes: result.Aggregations.ContentTypes.facets(),
+		Total:        result.Hits.Total.Value,
+		Duration:     duration,
 	}, nil
 }
 
+// Suggest returns up to limit documents whose titles or section headings match prefix,
+// where every word but the last must match a whole word and the last may be the start
+// of one.
+func (e *ElasticEngine) Suggest(ctx context.Context, prefix string, limit int) ([]core.SearchSuggestion, error) {
+	sq := parseSuggestQuery(prefix)
+	if sq.last == "" {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(buildSuggestDSL(prefix, limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal suggest query: %w", err)
+	}
+
+	resp, err := e.client.Search(
+		e.client.Search.WithContext(ctx),
+		e.client.Search.WithIndex(e.index),
+		e.client.Search.WithBody(bytes.NewReader(data)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("suggest request failed: %w", err)
+	}
+
+	if resp.IsError() {
+		resp.Body.Close()
+		return nil, fmt.Errorf("elasticsearch suggest error: %s", resp.String())
+	}
+
+	var result esSearchResponse
+	if err := decodeAndClose(resp.Body, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode suggest response: %w", err)
+	}
+
+	suggestions := make([]core.SearchSuggestion, 0, len(result.Hits.Hits))
+
+	for _, hit := range result.Hits.Hits {
+		src := &hit.Source
+		suggestions = append(suggestions, sq.suggestion(src.Repo, src.Path, src.Title, src.Headings, src.HeadingAnchors))
+	}
+
+	return suggestions, nil
+}
+
+// buildSuggestDSL constructs the query DSL for search-as-you-type suggestions, shared by
+// Elasticsearch and OpenSearch: every word of the prefix must match the title or the
+// headings of a document, the last one as the start of a word.
+func buildSuggestDSL(prefix string, limit int) map[string]any {
+	return map[string]any{
+		dslQuery: map[string]any{
+			dslMultiMatch: map[string]any{
+				dslQuery:   prefix,
+				dslType:    "bool_prefix",
+				dslFields:  []string{fieldTitle, fieldHeadings},
+				"operator": "and",
+			},
+		},
+		dslSize:   limit,
+		dslSource: []string{fieldRepo, fieldPath, fieldTitle, fieldHeadings, fieldHeadingAnchors},
+	}
+}
+
 // esListByRepoPageSize is the page size used when collecting all document IDs for a repository.
 const esListByRepoPageSize = 10000
 
@@ -229,6 +288,9 @@ const (
 	dslBoost              = "boost"
 	dslMultiMatch         = "multi_match"
 	dslType               = "type"
+	dslAggs               = "aggs"
+	dslMust               = "must"
+	dslMustNot            = "must_not"
 
 	mappingTypeText            = "text"
 	mappingTypeKeyword         = "keyword"
@@ -236,6 +298,21 @@ const (
 	mappingTermVector          = "term_vector"
 	mappingAnalyzerStandard    = "standard"
 	mappingTermVectorPositions = "with_positions_offsets"
+	mappingMeta                = "_meta"
+)
+
+const (
+	// keySchemeVersion identifies how documents are keyed in Elasticsearch and OpenSearch
+	// indexes. It is recorded in the _meta of the index mapping, so that indexes keyed by
+	// an older scheme are detected and rebuilt on startup.
+	//
+	//	0: raw document IDs (indexes created before the scheme was recorded)
+	//	1: base64url-encoded document IDs, see documentKey
+	keySchemeVersion = 1
+	// metaKeyScheme is the mapping _meta field recording keySchemeVersion.
+	metaKeyScheme = "omnidex_key_scheme"
+	// matchAllQuery is the body of a delete by query request deleting every document.
+	matchAllQuery = `{"query":{"match_all":{}}}`
 )
 
 // ListByRepo returns the IDs of all documents in the index that belong to the given repository.
@@ -289,7 +366,7 @@ func (e *ElasticEngine) ListByRepo(ctx context.Context, repo string) ([]string,
 		}
 
 		for _, hit := range result.Hits.Hits {
-			ids = append(ids, hit.ID)
+			ids = append(ids, keyDocumentID(hit.ID))
 		}
 
 		lastHit := result.Hits.Hits[len(result.Hits.Hits)-1]
@@ -308,8 +385,13 @@ func (e *ElasticEngine) ensureIndex(ctx context.Context) error {
 	defer resp.Body.Close()
 
 	if !resp.IsError() {
-		// Index already exists.
-		return nil
+		// Index already exists; drop documents keyed by an older scheme and add the fields
+		// introduced since it was created.
+		if err := e.migrateKeys(ctx); err != nil {
+			return err
+		}
+
+		return e.updateMapping(ctx)
 	}
 
 	if resp.StatusCode != http.StatusNotFound {
@@ -318,6 +400,7 @@ func (e *ElasticEngine) ensureIndex(ctx context.Context) error {
 
 	mapping := map[string]any{
 		"mappings": map[string]any{
+			mappingMeta: keySchemeMeta(),
 			"properties": map[string]any{
 				fieldTitle: map[string]any{
 					dslType:           mappingTypeText,
@@ -335,6 +418,33 @@ func (e *ElasticEngine) ensureIndex(ctx context.Context) error {
 				fieldPath: map[string]any{
 					dslType: mappingTypeKeyword,
 				},
+				fieldCode: map[string]any{
+					dslType:           mappingTypeText,
+					mappingAnalyzer:   mappingAnalyzerStandard,
+					mappingTermVector: mappingTermVectorPositions,
+				},
+				fieldLangs: map[string]any{
+					dslType: mappingTypeKeyword,
+				},
+				fieldContentType: map[string]any{
+					dslType: mappingTypeKeyword,
+				},
+				fieldHeadings: map[string]any{
+					dslType:         mappingTypeText,
+					mappingAnalyzer: mappingAnalyzerStandard,
+				},
+				fieldHeadingAnchors: map[string]any{
+					dslType: mappingTypeKeyword,
+					"index": false,
+				},
+				fieldAnchorMap: map[string]any{
+					dslType:      mappingTypeKeyword,
+					"index":      false,
+					"doc_values": false,
+				},
+				fieldTags: map[string]any{
+					dslType: mappingTypeKeyword,
+				},
 			},
 		},
 	}
@@ -361,10 +471,494 @@ func (e *ElasticEngine) ensureIndex(ctx context.Context) error {
 	return nil
 }
 
+// updateMapping adds the fields introduced after the first release to an existing index,
+// so that they are not mapped dynamically as text when documents are reindexed.
+func (e *ElasticEngine) updateMapping(ctx context.Context) error {
+	data, err := json.Marshal(addedFieldsMapping())
+	if err != nil {
+		return fmt.Errorf("failed to marshal index mapping: %w", err)
+	}
+
+	resp, err := e.client.Indices.PutMapping(
+		[]string{e.index},
+		bytes.NewReader(data),
+		e.client.Indices.PutMapping.WithContext(ctx),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update index mapping: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		return fmt.Errorf("elasticsearch update index mapping error: %s", resp.String())
+	}
+
+	return nil
+}
+
+// NeedsReindex reports whether the documents of the index were deleted on startup because
+// they were keyed by an older scheme. The index must then be repopulated from the
+// document store.
+func (e *ElasticEngine) NeedsReindex() bool {
+	return e.rebuilt
+}
+
+// migrateKeys deletes the documents of an index created before documents were keyed by
+// documentKey, since they could not be replaced or removed by their new keys and would
+// show up twice in results, and marks the index for reindexing. updateMapping then
+// records the current key scheme.
+func (e *ElasticEngine) migrateKeys(ctx context.Context) error {
+	resp, err := e.client.Indices.GetMapping(
+		e.client.Indices.GetMapping.WithIndex(e.index),
+		e.client.Indices.GetMapping.WithContext(ctx),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to get index mapping: %w", err)
+	}
+
+	var mappings map[string]struct {
+		Mappings json.RawMessage `json:"mappings"`
+	}
+
+	if err := decodeAndClose(resp.Body, &mappings); err != nil {
+		return fmt.Errorf("failed to decode index mapping: %w", err)
+	}
+
+	if resp.IsError() {
+		return fmt.Errorf("elasticsearch get index mapping error: %s", resp.String())
+	}
+
+	for _, m := range mappings {
+		if keyScheme(m.Mappings) >= keySchemeVersion {
+			return nil
+		}
+	}
+
+	delResp, err := e.client.DeleteByQuery(
+		[]string{e.index},
+		strings.NewReader(matchAllQuery),
+		e.client.DeleteByQuery.WithContext(ctx),
+		e.client.DeleteByQuery.WithConflicts("proceed"),
+		e.client.DeleteByQuery.WithRefresh(true),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete documents keyed by raw IDs: %w", err)
+	}
+	defer delResp.Body.Close()
+
+	if delResp.IsError() {
+		return fmt.Errorf("elasticsearch delete documents keyed by raw IDs error: %s", delResp.String())
+	}
+
+	e.rebuilt = true
+
+	return nil
+}
+
+// keyScheme returns the key scheme recorded in the _meta of an index mapping, or 0 for
+// indexes created before it was recorded, whose documents are keyed by raw IDs.
+func keyScheme(mappings json.RawMessage) int {
+	var m struct {
+		Meta map[string]any `json:"_meta"`
+	}
+
+	if err := json.Unmarshal(mappings, &m); err != nil {
+		return 0
+	}
+
+	v, _ := m.Meta[metaKeyScheme].(float64)
+
+	return int(v)
+}
+
+// keySchemeMeta returns the mapping _meta recording the current key scheme.
+func keySchemeMeta() map[string]any {
+	return map[string]any{metaKeyScheme: keySchemeVersion}
+}
+
+// addedFieldsMapping returns the mapping of the fields added to the index after the first
+// release, and the _meta recording the current key scheme, shared by Elasticsearch and
+// OpenSearch.
+func addedFieldsMapping() map[string]any {
+	return map[string]any{
+		mappingMeta: keySchemeMeta(),
+		"properties": map[string]any{
+			fieldContentType: map[string]any{
+				dslType: mappingTypeKeyword,
+			},
+			fieldHeadings: map[string]any{
+				dslType:         mappingTypeText,
+				mappingAnalyzer: mappingAnalyzerStandard,
+			},
+			fieldHeadingAnchors: map[string]any{
+				dslType: mappingTypeKeyword,
+				"index": false,
+			},
+			fieldAnchorMap: map[string]any{
+				dslType:      mappingTypeKeyword,
+				"index":      false,
+				"doc_values": false,
+			},
+			fieldTags: map[string]any{
+				dslType: mappingTypeKeyword,
+			},
+		},
+	}
+}
+
 // buildSearchQuery constructs an Elasticsearch query DSL from user input.
 // It mirrors the hybrid query logic from BleveEngine.buildSearchQuery.
-func (e *ElasticEngine) buildSearchQuery(userQuery string) map[string]any {
-	return buildQueryDSL(userQuery)
+func (e *ElasticEngine) buildSearchQuery(userQuery string, opts core.SearchOpts) map[string]any {
+	return buildSearchDSL(userQuery, opts)
+}
+
+// documentKey returns the key a document is stored under by the engines addressing
+// documents in URL paths, Elasticsearch, OpenSearch and Meilisearch. Elasticsearch and
+// OpenSearch indexes created before documents were keyed this way are emptied and
+// reindexed on startup, see keySchemeVersion. Document IDs hold
+// slashes and may hold characters such as "#", "%" or "?", so they are base64url-encoded.
+func documentKey(docID string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(docID))
+}
+
+// keyDocumentID returns the document ID stored under key. Keys that are not encoded, such
+// as raw IDs, which always hold a slash that base64url does not use, are returned as they
+// are.
+func keyDocumentID(key string) string {
+	if strings.Contains(key, "/") {
+		return key
+	}
+
+	id, err := base64.RawURLEncoding.DecodeString(key)
+	if err != nil {
+		return key
+	}
+
+	return string(id)
+}
+
+// buildDocumentBody returns the indexed source of a document, shared by Elasticsearch
+// and OpenSearch. Code, heading and tag fields are only included for documents with code
+// blocks, headings and tags, and the anchor map for documents that have one.
+func buildDocumentBody(doc core.Document, plainText string, code []core.CodeBlock, headings []core.Heading) map[string]any { //nolint:gocritic // Document is passed by value for immutability
+	body := map[string]any{
+		fieldTitle:       doc.Title,
+		fieldContent:     plainText,
+		fieldRepo:        doc.Repo,
+		fieldPath:        doc.Path,
+		fieldContentType: indexedContentType(doc.ContentType),
+	}
+
+	if codeText, langs := joinCodeBlocks(code); codeText != "" {
+		body[fieldCode] = codeText
+		body[fieldLangs] = langs
+	}
+
+	if len(headings) > 0 {
+		body[fieldHeadings], body[fieldHeadingAnchors] = splitHeadings(headings)
+	}
+
+	if len(doc.Tags) > 0 {
+		body[fieldTags] = doc.Tags
+	}
+
+	if anchors := encodeAnchorMap(doc.Anchors); anchors != "" {
+		body[fieldAnchorMap] = anchors
+	}
+
+	return body
+}
+
+// buildSearchDSL constructs the query DSL for a search request, mirroring the Bleve
+// engine: code-only queries target the code field, and language, repository and content
+// type filters and repo:, path: and tag: qualifiers are applied as non-scoring filters,
+// and -repo:, -path: and -tag: qualifiers exclude documents. A filter or qualifier without query text
+// matches every document passing it.
+func buildSearchDSL(userQuery string, opts core.SearchOpts) map[string]any {
+	sq := parseSearchQuery(userQuery)
+
+	var q map[string]any
+
+	switch {
+	case (opts.Lang != "" || len(opts.ContentTypes) > 0 || sq.narrowed()) && sq.expr == nil:
+		q = map[string]any{"match_all": map[string]any{}}
+	case opts.CodeOnly:
+		q = buildESCodeQuery(sq.expr)
+	default:
+		q = buildQueryDSL(sq.expr)
+	}
+
+	var mustNot []any
+
+	if len(sq.excludedRepos) > 0 {
+		mustNot = append(mustNot, buildESRepoScopeFilter(sq.excludedRepos))
+	}
+
+	if len(sq.excludedPaths) > 0 {
+		mustNot = append(mustNot, buildESPathFilter(sq.excludedPaths))
+	}
+
+	if len(sq.excludedTags) > 0 {
+		mustNot = append(mustNot, map[string]any{"terms": map[string]any{fieldTags: sq.excludedTags}})
+	}
+
+	var filter []any
+
+	if len(sq.repos) > 0 {
+		filter = append(filter, buildESRepoScopeFilter(sq.repos))
+	}
+
+	if len(sq.paths) > 0 {
+		filter = append(filter, buildESPathFilter(sq.paths))
+	}
+
+	for _, tag := range sq.tags {
+		filter = append(filter, map[string]any{"term": map[string]any{fieldTags: tag}})
+	}
+
+	if opts.Lang != "" {
+		filter = append(filter, map[string]any{"term": map[string]any{fieldLangs: strings.ToLower(opts.Lang)}})
+	}
+
+	if len(opts.Repos) > 0 {
+		filter = append(filter, buildESRepoScopeFilter(opts.Repos))
+	}
+
+	if opts.Repo != "" {
+		filter = append(filter, map[string]any{"term": map[string]any{fieldRepo: opts.Repo}})
+	}
+
+	if len(opts.ContentTypes) > 0 {
+		types := make([]string, 0, len(opts.ContentTypes))
+		for _, ct := range opts.ContentTypes {
+			types = append(types, indexedContentType(ct))
+		}
+
+		filter = append(filter, map[string]any{"terms": map[string]any{fieldContentType: types}})
+	}
+
+	if len(filter) == 0 && len(mustNot) == 0 {
+		return q
+	}
+
+	boolQ := map[string]any{dslMust: []any{q}}
+
+	if len(filter) > 0 {
+		boolQ["filter"] = filter
+	}
+
+	if len(mustNot) > 0 {
+		boolQ[dslMustNot] = mustNot
+	}
+
+	return map[string]any{dslBool: boolQ}
+}
+
+// buildESRepoScopeFilter creates a filter matching documents of the given owners or
+// repositories, including their monorepo sub-projects.
+func buildESRepoScopeFilter(repos []string) map[string]any {
+	should := make([]any, 0, 2*len(repos))
+
+	for _, repo := range repos {
+		should = append(should,
+			map[string]any{"term": map[string]any{fieldRepo: repo}},
+			map[string]any{"prefix": map[string]any{fieldRepo: repo + "/"}},
+		)
+	}
+
+	return map[string]any{
+		dslBool: map[string]any{
+			dslShould:             should,
+			dslMinimumShouldMatch: 1,
+		},
+	}
+}
+
+// buildESPathFilter creates a filter matching documents whose path matches any of the
+// path: patterns: a wildcard pattern, or else a document path or a directory.
+func buildESPathFilter(patterns []string) map[string]any {
+	should := make([]any, 0, 2*len(patterns))
+
+	for _, pattern := range patterns {
+		if wildcard, ok := pathPattern(pattern); ok {
+			should = append(should, map[string]any{"wildcard": map[string]any{fieldPath: wildcard}})
+			continue
+		}
+
+		should = append(should,
+			map[string]any{"term": map[string]any{fieldPath: pattern}},
+			map[string]any{"prefix": map[string]any{fieldPath: strings.TrimSuffix(pattern, "/") + "/"}},
+		)
+	}
+
+	return map[string]any{
+		dslBool: map[string]any{
+			dslShould:             should,
+			dslMinimumShouldMatch: 1,
+		},
+	}
+}
+
+// buildESCodeQuery creates a query against the code field from a query expression. Each
+// term matches either as an analyzed match or as a prefix; quoted terms match as phrases.
+// title: terms match the title instead.
+func buildESCodeQuery(expr *queryExpr) map[string]any {
+	if expr == nil {
+		return map[string]any{"match_none": map[string]any{}}
+	}
+
+	b := esExprQueryBuilder{term: buildESCodeTermQuery, exclusion: buildESCodeExclusionQuery}
+
+	return b.build(expr)
+}
+
+// buildESCodeTermQuery creates the query of a single term against the code field.
+func buildESCodeTermQuery(term queryTerm) map[string]any {
+	if term.field == fieldTitle {
+		return buildESTitleQuery(term)
+	}
+
+	if term.phrase {
+		return map[string]any{"match_phrase": map[string]any{fieldCode: term.text}}
+	}
+
+	return map[string]any{
+		dslBool: map[string]any{
+			dslShould: []any{
+				map[string]any{"match": map[string]any{fieldCode: term.text}},
+				map[string]any{"match_phrase_prefix": map[string]any{fieldCode: term.text}},
+			},
+			dslMinimumShouldMatch: 1,
+		},
+	}
+}
+
+// buildESCodeExclusionQuery creates the query of a negated term against the code field,
+// or the title for title: terms.
+func buildESCodeExclusionQuery(term queryTerm) map[string]any {
+	if term.field == fieldTitle {
+		return buildESExactQuery(term, fieldTitle)
+	}
+
+	return buildESExactQuery(term, fieldCode)
+}
+
+// buildESExactQuery creates a query matching a term in the fields as the analyzer indexes
+// it, without prefix or fuzzy variants, or as a phrase when quoted.
+func buildESExactQuery(term queryTerm, fields ...string) map[string]any {
+	if term.phrase {
+		return map[string]any{
+			dslMultiMatch: map[string]any{dslQuery: term.text, dslFields: fields, dslType: "phrase"},
+		}
+	}
+
+	return map[string]any{
+		dslMultiMatch: map[string]any{dslQuery: term.text, dslFields: fields, dslType: "cross_fields", "operator": "and"},
+	}
+}
+
+// esExprQueryBuilder constructs the query of a boolean query expression, mirroring
+// exprQueryBuilder.
+type esExprQueryBuilder struct {
+	// term creates the query of a term.
+	term func(queryTerm) map[string]any
+	// exclusion creates the query of a negated term, which matches the documents to
+	// exclude. It matches exactly, so excluding a word does not exclude similar words.
+	exclusion func(queryTerm) map[string]any
+	// fullPhrase, when set, creates the query AND nodes of plain words also match, for
+	// the words as a whole.
+	fullPhrase func(words string) map[string]any
+}
+
+// excluding returns the builder of the queries of negated expressions.
+func (b esExprQueryBuilder) excluding() esExprQueryBuilder {
+	return esExprQueryBuilder{term: b.exclusion, exclusion: b.exclusion}
+}
+
+// build constructs the query of expr.
+func (b esExprQueryBuilder) build(expr *queryExpr) map[string]any {
+	if expr.negated {
+		return map[string]any{dslBool: map[string]any{dslMustNot: []any{b.excluding().buildPositive(expr)}}}
+	}
+
+	return b.buildPositive(expr)
+}
+
+// buildPositive constructs the query of expr, ignoring whether expr itself is negated.
+func (b esExprQueryBuilder) buildPositive(expr *queryExpr) map[string]any {
+	switch expr.op {
+	case exprTerm:
+		return b.term(expr.term)
+	case exprOr:
+		should := make([]any, 0, len(expr.exprs))
+		for _, sub := range expr.exprs {
+			should = append(should, b.build(sub))
+		}
+
+		return map[string]any{dslBool: map[string]any{dslShould: should, dslMinimumShouldMatch: 1}}
+	}
+
+	var must, mustNot []any
+
+	for _, sub := range expr.exprs {
+		if sub.negated {
+			mustNot = append(mustNot, b.excluding().buildPositive(sub))
+			continue
+		}
+
+		must = append(must, b.build(sub))
+	}
+
+	boolQ := map[string]any{}
+
+	if len(must) > 0 {
+		boolQ[dslMust] = must
+	}
+
+	if len(mustNot) > 0 {
+		boolQ[dslMustNot] = mustNot
+	}
+
+	perWordQuery := map[string]any{dslBool: boolQ}
+
+	// For multi-word unquoted queries, add a full-phrase fallback (mirrors Bleve logic).
+	if words, ok := expr.words(); ok && b.fullPhrase != nil {
+		return map[string]any{
+			dslBool: map[string]any{
+				dslShould:             []any{perWordQuery, b.fullPhrase(words)},
+				dslMinimumShouldMatch: 1,
+			},
+		}
+	}
+
+	return perWordQuery
+}
+
+// buildHighlight returns the highlighting of matched terms in the fragments of search
+// results.
+func buildHighlight() map[string]any {
+	return map[string]any{
+		dslFields: map[string]any{
+			fieldTitle:   map[string]any{dslNumberOfFragments: 3},
+			fieldContent: map[string]any{"fragment_size": 200, dslNumberOfFragments: 3},
+			fieldCode:    map[string]any{"fragment_size": 200, dslNumberOfFragments: 3},
+		},
+		"pre_tags":  []string{"<mark>"},
+		"post_tags": []string{"</mark>"},
+	}
+}
+
+// buildFacetAggs returns the terms aggregations that count matching documents per code
+// block language and per content type.
+func buildFacetAggs() map[string]any {
+	return map[string]any{
+		fieldLangs: map[string]any{
+			"terms": map[string]any{"field": fieldLangs, dslSize: langFacetSize},
+		},
+		fieldContentType: map[string]any{
+			"terms": map[string]any{"field": fieldContentType, dslSize: contentTypeFacetSize},
+		},
+	}
 }
 
 // buildESTermQuery creates an ES query for a single non-phrase term with match, prefix, and fuzzy variants.
@@ -468,61 +1062,107 @@ func buildESFullPhraseQuery(phrase string) map[string]any {
 	}
 }
 
-// buildQueryDSL constructs a query DSL map from user input.
+// buildQueryDSL constructs a query DSL map from the expression of a user query.
 // It is shared between ElasticEngine and OpenSearchEngine as both use compatible query DSL.
-func buildQueryDSL(userQuery string) map[string]any {
-	terms := splitQueryTerms(userQuery)
-	if len(terms) == 0 {
+func buildQueryDSL(expr *queryExpr) map[string]any {
+	if expr == nil {
 		return map[string]any{"match_none": map[string]any{}}
 	}
 
-	mustClauses := make([]map[string]any, 0, len(terms))
-	wordTermCount := 0
+	b := esExprQueryBuilder{term: buildESTextTermQuery, exclusion: buildESTextExclusionQuery, fullPhrase: buildESFullPhraseQuery}
 
-	for _, term := range terms {
-		if term.phrase {
-			mustClauses = append(mustClauses, buildESPhraseQuery(term.text))
-		} else {
-			mustClauses = append(mustClauses, buildESTermQuery(term.text))
-			wordTermCount++
-		}
+	return b.build(expr)
+}
+
+// buildESTextTermQuery creates the query of a single term against the title and content.
+func buildESTextTermQuery(term queryTerm) map[string]any {
+	switch {
+	case term.field == fieldTitle:
+		return buildESTitleQuery(term)
+	case term.phrase:
+		return buildESPhraseQuery(term.text)
+	default:
+		return buildESTermQuery(term.text)
 	}
+}
 
-	var perWordQuery map[string]any
-	if len(mustClauses) == 1 {
-		perWordQuery = mustClauses[0]
-	} else {
-		must := make([]any, len(mustClauses))
-		for i, c := range mustClauses {
-			must[i] = c
-		}
+// buildESTextExclusionQuery creates the query of a negated term against the title and
+// content, or the title only for title: terms.
+func buildESTextExclusionQuery(term queryTerm) map[string]any {
+	if term.field == fieldTitle {
+		return buildESExactQuery(term, fieldTitle)
+	}
 
-		perWordQuery = map[string]any{
-			dslBool: map[string]any{
-				"must": must,
+	return buildESExactQuery(term, fieldTitle, fieldContent)
+}
+
+// buildESTitleQuery creates the query of a title: term, matching it against the title
+// only: as a phrase when quoted, or else as a match, prefix or fuzzy match.
+func buildESTitleQuery(term queryTerm) map[string]any {
+	if term.phrase {
+		return map[string]any{
+			"match_phrase": map[string]any{
+				fieldTitle: map[string]any{dslQuery: term.text, dslBoost: 10.0},
 			},
 		}
 	}
 
-	// For multi-word unquoted queries, add a full-phrase fallback (mirrors Bleve logic).
-	if wordTermCount > 1 && wordTermCount == len(terms) {
-		return map[string]any{
-			dslBool: map[string]any{
-				dslShould: []any{
-					perWordQuery,
-					buildESFullPhraseQuery(userQuery),
-				},
-				dslMinimumShouldMatch: 1,
-			},
+	should := []any{
+		map[string]any{"match": map[string]any{fieldTitle: map[string]any{dslQuery: term.text, dslBoost: 6.0}}},
+		map[string]any{"match_phrase_prefix": map[string]any{fieldTitle: map[string]any{dslQuery: term.text, dslBoost: 3.0}}},
+	}
+
+	if len(term.text) >= minFuzzyTermLength {
+		fuzziness := "1"
+		if len(term.text) >= longTermThreshold {
+			fuzziness = "2"
 		}
+
+		should = append(should, map[string]any{
+			"match": map[string]any{fieldTitle: map[string]any{dslQuery: term.text, "fuzziness": fuzziness}},
+		})
 	}
 
-	return perWordQuery
+	return map[string]any{
+		dslBool: map[string]any{
+			dslShould:             should,
+			dslMinimumShouldMatch: 1,
+		},
+	}
 }
 
 // esSearchResponse represents the Elasticsearch search response structure.
 type esSearchResponse struct {
-	Hits esHits `json:"hits"`
+	Aggregations esAggregations `json:"aggregations"`
+	Hits         esHits         `json:"hits"`
+}
+
+// esAggregations represents the aggregations requested alongside a search.
+type esAggregations struct {
+	Langs        esTermsAgg `json:"langs"`
+	ContentTypes esTermsAgg `json:"content_type"`
+}
+
+// esTermsAgg represents the buckets of a terms aggregation.
+type esTermsAgg struct {
+	Buckets []esBucket `json:"buckets"`
+}
+
+// esBucket represents a single terms aggregation bucket.
+type esBucket struct {
+	Key      string `json:"key"`
+	DocCount int    `json:"doc_count"`
+}
+
+// facets converts the aggregation buckets to facet counts.
+func (a esTermsAgg) facets() []core.FacetCount {
+	var counts []core.FacetCount
+
+	for _, b := range a.Buckets {
+		counts = append(counts, core.FacetCount{Value: b.Key, Count: b.DocCount})
+	}
+
+	return counts
 }
 
 // esHits represents the hits section of an ES search response.
@@ -547,9 +1187,14 @@ type esHit struct {
 
 // esSource represents the _source fields of an ES hit.
 type esSource struct {
-	Repo  string `json:"repo"`
-	Path  string `json:"path"`
-	Title string `json:"title"`
+	Repo           string   `json:"repo"`
+	Path           string   `json:"path"`
+	Title          string   `json:"title"`
+	ContentType    string   `json:"content_type"`
+	Content        string   `json:"content"`
+	AnchorMap      string   `json:"anchor_map"`
+	Headings       []string `json:"headings"`
+	HeadingAnchors []string `json:"heading_anchors"`
 }
 
 // decodeAndClose decodes a JSON response body and closes it.
@@ -3,9 +3,12 @@ package search
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"sync"
 	"testing"
 
@@ -64,6 +67,12 @@ func (h *mockESHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Default: existing indexes are keyed by the current scheme.
+	if index, ok := strings.CutSuffix(r.URL.Path, "/_mapping"); ok && r.Method == http.MethodGet {
+		_, _ = fmt.Fprintf(w, `{%q:{"mappings":{"_meta":{%q:%d}}}}`, strings.TrimPrefix(index, "/"), metaKeyScheme, keySchemeVersion)
+		return
+	}
+
 	// Default: return 200 with empty JSON.
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -111,6 +120,64 @@ func TestNewElastic_Success(t *testing.T) {
 	assert.Equal(t, "omnidex", engine.index)
 }
 
+func TestNewElastic_UpdatesExistingIndexMapping(t *testing.T) {
+	handler := newMockESHandler()
+
+	_, srv := newTestElasticEngine(t, handler)
+	defer srv.Close()
+
+	var body string
+
+	for _, r := range handler.getRequests() {
+		if r.Method == http.MethodPut && r.Path == "/omnidex/_mapping" {
+			body = r.Body
+		}
+	}
+
+	assert.JSONEq(t, `{"_meta":{"omnidex_key_scheme":1},"properties":{"content_type":{"type":"keyword"},`+
+		`"headings":{"type":"text","analyzer":"standard"},"heading_anchors":{"type":"keyword","index":false},`+
+		`"anchor_map":{"type":"keyword","index":false,"doc_values":false},"tags":{"type":"keyword"}}}`, body)
+}
+
+func TestNewElastic_MigratesRawKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping string
+		migrate bool
+	}{
+		{name: "raw keys", mapping: `{"omnidex":{"mappings":{"properties":{}}}}`, migrate: true},
+		{name: "encoded keys", mapping: `{"omnidex":{"mappings":{"_meta":{"omnidex_key_scheme":1}}}}`, migrate: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newMockESHandler()
+			handler.handlers["GET /omnidex/_mapping"] = func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte(tt.mapping))
+			}
+
+			engine, srv := newTestElasticEngine(t, handler)
+			defer srv.Close()
+
+			var deleted string
+
+			for _, r := range handler.getRequests() {
+				if r.Method == http.MethodPost && r.Path == "/omnidex/_delete_by_query" {
+					deleted = r.Body
+				}
+			}
+
+			assert.Equal(t, tt.migrate, engine.NeedsReindex())
+
+			if tt.migrate {
+				assert.JSONEq(t, `{"query":{"match_all":{}}}`, deleted)
+			} else {
+				assert.Empty(t, deleted)
+			}
+		})
+	}
+}
+
 func TestNewElastic_DefaultIndex(t *testing.T) {
 	handler := newMockESHandler()
 	handler.handlers["HEAD /omnidex"] = func(w http.ResponseWriter, _ *http.Request) {
@@ -176,32 +243,36 @@ func TestElasticEngine_Index(t *testing.T) {
 	defer srv.Close()
 
 	doc := core.Document{
-		ID:    "owner/repo/doc.md",
-		Repo:  "owner/repo",
-		Path:  "doc.md",
-		Title: "Test Document",
+		ID:      "owner/repo/doc.md",
+		Repo:    "owner/repo",
+		Path:    "doc.md",
+		Title:   "Test Document",
+		Anchors: core.AnchorMap{{ID: "plain", Text: "Plain", Level: 1}},
 	}
 
-	err := engine.Index(t.Context(), doc, "plain text content")
+	err := engine.Index(t.Context(), doc, "plain text content", nil, nil)
 	require.NoError(t, err)
 
 	// Verify the indexed document body from recorded requests.
 	reqs := handler.getRequests()
 
-	var indexedBody string
+	var indexedPath, indexedBody string
 
 	for _, r := range reqs {
-		if r.Method == "PUT" && r.Body != "" {
-			indexedBody = r.Body
+		if r.Method == "PUT" && strings.Contains(r.Path, "/_doc/") {
+			indexedPath, indexedBody = r.Path, r.Body
 
 			break
 		}
 	}
 
+	assert.Equal(t, "/omnidex/_doc/b3duZXIvcmVwby9kb2MubWQ", indexedPath)
+
 	var m map[string]string
 	require.NoError(t, json.Unmarshal([]byte(indexedBody), &m))
 	assert.Equal(t, "Test Document", m["title"])
 	assert.Equal(t, "plain text content", m["content"])
+	assert.JSONEq(t, `[{"id":"plain","text":"Plain","offset":0,"level":1}]`, m["anchor_map"])
 	assert.Equal(t, "owner/repo", m["repo"])
 	assert.Equal(t, "doc.md", m["path"])
 }
@@ -244,12 +315,15 @@ func TestElasticEngine_Search(t *testing.T) {
 				"total": map[string]any{"value": 1},
 				"hits": []any{
 					map[string]any{
-						"_id":    "owner/repo/doc.md",
+						"_id":    documentKey("owner/repo/doc.md"),
 						"_score": 5.5,
 						"_source": map[string]any{
-							"repo":  "owner/repo",
-							"path":  "doc.md",
-							"title": "Getting Started",
+							"repo":         "owner/repo",
+							"path":         "doc.md",
+							"title":        "Getting Started",
+							"content_type": "openapi",
+							"content":      "Welcome to the getting started guide",
+							"anchor_map":   `[{"id":"welcome","text":"Welcome","offset":0,"level":1}]`,
 						},
 						"highlight": map[string]any{
 							"title":   []any{"<mark>Getting</mark> Started"},
@@ -276,9 +350,12 @@ func TestElasticEngine_Search(t *testing.T) {
 	assert.Equal(t, "owner/repo", results.Hits[0].Repo)
 	assert.Equal(t, "doc.md", results.Hits[0].Path)
 	assert.Equal(t, "Getting Started", results.Hits[0].Title)
+	assert.Equal(t, core.ContentTypeOpenAPI, results.Hits[0].ContentType)
 	assert.InDelta(t, 5.5, results.Hits[0].Score, 0.01)
 	assert.Len(t, results.Hits[0].TitleFragments, 1)
 	assert.Len(t, results.Hits[0].ContentFragments, 1)
+	assert.Equal(t, core.AnchorMap{{ID: "welcome", Text: "Welcome", Level: 1}}, results.Hits[0].Anchors)
+	assert.Equal(t, "Welcome to the getting started guide", results.Hits[0].PlainText)
 }
 
 func TestElasticEngine_Search_DefaultLimit(t *testing.T) {
@@ -323,7 +400,8 @@ func TestElasticEngine_ListByRepo(t *testing.T) {
 				"hits": map[string]any{
 					"total": map[string]any{"value": 2},
 					"hits": []any{
-						map[string]any{"_id": "owner/repo/a.md", "sort": []any{0}},
+						map[string]any{"_id": documentKey("owner/repo/a.md"), "sort": []any{0}},
+						// Keys that are not encoded are returned as they are.
 						map[string]any{"_id": "owner/repo/b.md", "sort": []any{1}},
 					},
 				},
@@ -349,6 +427,17 @@ func TestElasticEngine_ListByRepo(t *testing.T) {
 	assert.Equal(t, []string{"owner/repo/a.md", "owner/repo/b.md"}, ids)
 }
 
+func TestDocumentKey(t *testing.T) {
+	for _, docID := range []string{"owner/repo/doc.md", "owner/mono/api/notes/50% off #2?.md", "owner/repo/guías/café.md"} {
+		key := documentKey(docID)
+
+		assert.Equal(t, url.PathEscape(key), key, "key of %q must not need escaping in URL paths", docID)
+		assert.Equal(t, docID, keyDocumentID(key))
+	}
+
+	assert.Equal(t, "owner/repo/doc.md", keyDocumentID("owner/repo/doc.md"), "raw IDs are returned as they are")
+}
+
 func TestBuildESTermQuery(t *testing.T) {
 	q := buildESTermQuery("hello")
 	boolQ, ok := q["bool"].(map[string]any)
@@ -385,21 +474,21 @@ func TestBuildESPhraseQuery(t *testing.T) {
 
 func TestElasticEngine_BuildSearchQuery_EmptyQuery(t *testing.T) {
 	engine := &ElasticEngine{index: "test"}
-	q := engine.buildSearchQuery("")
+	q := engine.buildSearchQuery("", core.SearchOpts{})
 	_, hasMatchNone := q["match_none"]
 	assert.True(t, hasMatchNone)
 }
 
 func TestElasticEngine_BuildSearchQuery_SingleTerm(t *testing.T) {
 	engine := &ElasticEngine{index: "test"}
-	q := engine.buildSearchQuery("kubernetes")
+	q := engine.buildSearchQuery("kubernetes", core.SearchOpts{})
 	_, hasBool := q["bool"]
 	assert.True(t, hasBool, "single term should produce a bool query")
 }
 
 func TestElasticEngine_BuildSearchQuery_MultiWord(t *testing.T) {
 	engine := &ElasticEngine{index: "test"}
-	q := engine.buildSearchQuery("getting started guide")
+	q := engine.buildSearchQuery("getting started guide", core.SearchOpts{})
 
 	// Multi-word should have a top-level bool with should (per-word + full-phrase fallback).
 	boolQ, ok := q["bool"].(map[string]any)
@@ -412,10 +501,214 @@ func TestElasticEngine_BuildSearchQuery_MultiWord(t *testing.T) {
 
 func TestElasticEngine_BuildSearchQuery_QuotedPhrase(t *testing.T) {
 	engine := &ElasticEngine{index: "test"}
-	q := engine.buildSearchQuery(`"exact match"`)
+	q := engine.buildSearchQuery(`"exact match"`, core.SearchOpts{})
 
 	// Quoted phrase should produce a bool with match_phrase queries.
 	boolQ, ok := q["bool"].(map[string]any)
 	require.True(t, ok)
 	assert.NotNil(t, boolQ["should"])
 }
+
+func TestElasticEngine_BuildSearchQuery_CodeOnlyWithLang(t *testing.T) {
+	engine := &ElasticEngine{index: "test"}
+	q := engine.buildSearchQuery(`handler "func main"`, core.SearchOpts{CodeOnly: true, Lang: "Go"})
+
+	boolQ, ok := q["bool"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, []any{map[string]any{"term": map[string]any{"langs": "go"}}}, boolQ["filter"])
+
+	must, ok := boolQ["must"].([]any)
+	require.True(t, ok)
+	require.Len(t, must, 1)
+
+	data, err := json.Marshal(must[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"match_phrase":{"code":"func main"}`)
+	assert.Contains(t, string(data), `"match_phrase_prefix":{"code":"handler"}`)
+	assert.NotContains(t, string(data), `"content"`)
+}
+
+func TestElasticEngine_BuildSearchQuery_LangOnly(t *testing.T) {
+	engine := &ElasticEngine{index: "test"}
+	q := engine.buildSearchQuery("", core.SearchOpts{Lang: "rust"})
+
+	boolQ, ok := q["bool"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, []any{map[string]any{"match_all": map[string]any{}}}, boolQ["must"])
+}
+
+func TestElasticEngine_BuildSearchQuery_RepoScope(t *testing.T) {
+	engine := &ElasticEngine{index: "test"}
+	q := engine.buildSearchQuery("deploy", core.SearchOpts{Lang: "go", Repos: []string{"team-x"}})
+
+	boolQ, ok := q["bool"].(map[string]any)
+	require.True(t, ok)
+
+	data, err := json.Marshal(boolQ["filter"])
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"term":{"langs":"go"}},
+		{"bool":{"should":[{"term":{"repo":"team-x"}},{"prefix":{"repo":"team-x/"}}],"minimum_should_match":1}}
+	]`, string(data))
+}
+
+func TestElasticEngine_BuildSearchQuery_Repo(t *testing.T) {
+	engine := &ElasticEngine{index: "test"}
+	q := engine.buildSearchQuery("deploy", core.SearchOpts{Repo: "team-x/api"})
+
+	boolQ, ok := q["bool"].(map[string]any)
+	require.True(t, ok)
+
+	data, err := json.Marshal(boolQ["filter"])
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"term":{"repo":"team-x/api"}}]`, string(data))
+}
+
+func TestElasticEngine_BuildSearchQuery_Qualifiers(t *testing.T) {
+	engine := &ElasticEngine{index: "test"}
+	q := engine.buildSearchQuery("repo:team-x path:docs/** path:README.md", core.SearchOpts{})
+
+	data, err := json.Marshal(q)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"bool":{
+		"must":[{"match_all":{}}],
+		"filter":[
+			{"bool":{"should":[{"term":{"repo":"team-x"}},{"prefix":{"repo":"team-x/"}}],"minimum_should_match":1}},
+			{"bool":{"should":[
+				{"wildcard":{"path":"docs/*"}},
+				{"term":{"path":"README.md"}},
+				{"prefix":{"path":"README.md/"}}
+			],"minimum_should_match":1}}
+		]
+	}}`, string(data), "qualifiers without terms match every document they narrow to")
+}
+
+func TestElasticEngine_BuildSearchQuery_TagQualifiers(t *testing.T) {
+	engine := &ElasticEngine{index: "test"}
+	q := engine.buildSearchQuery("tag:Ops tag:ci -tag:legacy", core.SearchOpts{})
+
+	data, err := json.Marshal(q)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"bool":{
+		"must":[{"match_all":{}}],
+		"must_not":[{"terms":{"tags":["legacy"]}}],
+		"filter":[{"term":{"tags":"ops"}},{"term":{"tags":"ci"}}]
+	}}`, string(data))
+}
+
+func TestElasticEngine_BuildSearchQuery_TitleQualifier(t *testing.T) {
+	engine := &ElasticEngine{index: "test"}
+	q := engine.buildSearchQuery("title:getting", core.SearchOpts{})
+
+	data, err := json.Marshal(q)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"title"`)
+	assert.NotContains(t, string(data), `"content"`, "title: terms match titles only")
+}
+
+func TestElasticEngine_BuildSearchQuery_ContentTypes(t *testing.T) {
+	engine := &ElasticEngine{index: "test"}
+	q := engine.buildSearchQuery("", core.SearchOpts{ContentTypes: []core.ContentType{core.ContentTypeOpenAPI}})
+
+	data, err := json.Marshal(q)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"bool":{
+		"must":[{"match_all":{}}],
+		"filter":[{"terms":{"content_type":["openapi"]}}]
+	}}`, string(data), "a content type filter without query text matches every document of the type")
+}
+
+func TestElasticEngine_SearchLangFacets(t *testing.T) {
+	handler := newMockESHandler()
+	handler.handlers["POST"] = func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"hits":{"total":{"value":0},"hits":[]},` +
+			`"aggregations":{"langs":{"buckets":[{"key":"go","doc_count":3},{"key":"yaml","doc_count":1}]}}}`))
+	}
+
+	engine, srv := newTestElasticEngine(t, handler)
+	defer srv.Close()
+
+	results, err := engine.Search(t.Context(), "config", core.SearchOpts{})
+	require.NoError(t, err)
+	assert.Equal(t, []core.FacetCount{{Value: "go", Count: 3}, {Value: "yaml", Count: 1}}, results.Langs)
+}
+
+func TestElasticEngine_SearchContentTypeFacets(t *testing.T) {
+	handler := newMockESHandler()
+	handler.handlers["POST"] = func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"hits":{"total":{"value":0},"hits":[]},` +
+			`"aggregations":{"content_type":{"buckets":[{"key":"markdown","doc_count":5},{"key":"openapi","doc_count":2}]}}}`))
+	}
+
+	engine, srv := newTestElasticEngine(t, handler)
+	defer srv.Close()
+
+	results, err := engine.Search(t.Context(), "users", core.SearchOpts{})
+	require.NoError(t, err)
+	assert.Equal(t, []core.FacetCount{{Value: "markdown", Count: 5}, {Value: "openapi", Count: 2}}, results.ContentTypes)
+}
+
+func TestElasticEngine_IndexCode(t *testing.T) {
+	handler := newMockESHandler()
+	handler.handlers["PUT"] = func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"result":"created"}`))
+	}
+
+	engine, srv := newTestElasticEngine(t, handler)
+	defer srv.Close()
+
+	doc := core.Document{ID: "owner/repo/doc.md", Repo: "owner/repo", Path: "doc.md", Title: "Doc"}
+	err := engine.Index(t.Context(), doc, "text", []core.CodeBlock{{Lang: "go", Code: "x := 1"}}, nil)
+	require.NoError(t, err)
+
+	var m map[string]any
+
+	for _, r := range handler.getRequests() {
+		if r.Method == "PUT" && strings.Contains(r.Path, "/_doc/") {
+			require.NoError(t, json.Unmarshal([]byte(r.Body), &m))
+			break
+		}
+	}
+
+	assert.Equal(t, "x := 1", m["code"])
+	assert.Equal(t, []any{"go"}, m["langs"])
+	assert.Equal(t, "markdown", m["content_type"], "documents without a content type are markdown")
+}
+
+func TestElasticEngine_BuildSearchQuery_BooleanOperators(t *testing.T) {
+	engine := &ElasticEngine{index: "test"}
+	q := engine.buildSearchQuery(`deploy -kubernetes OR title:nomad -repo:acme/old`, core.SearchOpts{})
+
+	data, err := json.Marshal(q)
+	require.NoError(t, err)
+
+	want, err := json.Marshal(map[string]any{"bool": map[string]any{
+		"must": []any{map[string]any{"bool": map[string]any{
+			"should": []any{
+				map[string]any{"bool": map[string]any{
+					"must": []any{buildESTermQuery("deploy")},
+					"must_not": []any{map[string]any{"multi_match": map[string]any{
+						"query": "kubernetes", "fields": []string{"title", "content"}, "type": "cross_fields", "operator": "and",
+					}}},
+				}},
+				buildESTitleQuery(queryTerm{text: "nomad", field: fieldTitle}),
+			},
+			"minimum_should_match": 1,
+		}}},
+		"must_not": []any{buildESRepoScopeFilter([]string{"acme/old"})},
+	}})
+	require.NoError(t, err)
+	assert.JSONEq(t, string(want), string(data))
+}
+
+func TestElasticEngine_BuildSearchQuery_NegationOnly(t *testing.T) {
+	engine := &ElasticEngine{index: "test"}
+	q := engine.buildSearchQuery(`-"helm chart"`, core.SearchOpts{CodeOnly: true})
+
+	data, err := json.Marshal(q)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"bool":{"must_not":[
+		{"multi_match":{"query":"helm chart","fields":["code"],"type":"phrase"}}
+	]}}`, string(data), "a negation without other terms matches every other document")
+}
@@ -0,0 +1,547 @@
+package search
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+const (
+	// meiliPrimaryKey is the primary key of indexed documents. Meilisearch only accepts
+	// alphanumeric characters, '-' and '_' in document identifiers, so the document ID
+	// is stored base64url-encoded under this key and verbatim in fieldID.
+	meiliPrimaryKey = "key"
+	// meiliFieldID holds the unencoded document ID.
+	meiliFieldID = "id"
+	// meiliFieldScopes holds the repository of a document and its ancestors, so that a
+	// filter on an owner or repository also matches monorepo sub-projects.
+	meiliFieldScopes = "scopes"
+	// meiliListPageSize is the number of document IDs fetched per request by ListByRepo.
+	meiliListPageSize = 1000
+	// meiliCropLength is the number of words around a match kept in content fragments.
+	meiliCropLength = 30
+)
+
+// MeilisearchConfig holds configuration for the Meilisearch backend.
+type MeilisearchConfig struct {
+	Address string `mapstructure:"address"`
+	APIKey  string `mapstructure:"api_key" redact:"true"`
+	Index   string `mapstructure:"index"`
+}
+
+// MeilisearchEngine implements full-text search using Meilisearch. Meilisearch applies
+// changes asynchronously, so indexed and removed documents show up in search results
+// shortly after the calls return.
+type MeilisearchEngine struct {
+	client  *http.Client
+	address string
+	apiKey  string
+	index   string
+}
+
+// NewMeilisearch creates a new Meilisearch search engine.
+// It creates the index if it does not exist yet and applies the index settings.
+func NewMeilisearch(ctx context.Context, cfg *MeilisearchConfig) (*MeilisearchEngine, error) {
+	if cfg.Address == "" {
+		return nil, fmt.Errorf("meilisearch address is required")
+	}
+
+	index := cfg.Index
+	if index == "" {
+		index = defaultIndex
+	}
+
+	engine := &MeilisearchEngine{
+		client:  &http.Client{Timeout: 30 * time.Second},
+		address: strings.TrimRight(cfg.Address, "/"),
+		apiKey:  cfg.APIKey,
+		index:   index,
+	}
+
+	if err := engine.ensureIndex(ctx); err != nil {
+		return nil, fmt.Errorf("failed to ensure meilisearch index: %w", err)
+	}
+
+	return engine, nil
+}
+
+// repoScopes returns repo and its ancestors, e.g. "owner", "owner/mono" and
+// "owner/mono/service" for "owner/mono/service".
+func repoScopes(repo string) []string {
+	parts := strings.Split(repo, "/")
+	scopes := make([]string, 0, len(parts))
+
+	for i := range parts {
+		scopes = append(scopes, strings.Join(parts[:i+1], "/"))
+	}
+
+	return scopes
+}
+
+// Index adds or updates a document in the Meilisearch index.
+func (e *MeilisearchEngine) Index(ctx context.Context, doc core.Document, plainText string, code []core.CodeBlock, headings []core.Heading) error { //nolint:gocritic // Document is passed by value for immutability
+	body := buildDocumentBody(doc, plainText, code, headings)
+	body[meiliPrimaryKey] = documentKey(doc.ID)
+	body[meiliFieldID] = doc.ID
+	body[meiliFieldScopes] = repoScopes(doc.Repo)
+
+	if err := e.do(ctx, http.MethodPost, e.indexPath("documents"), []any{body}, nil); err != nil {
+		return fmt.Errorf("failed to index document %s: %w", doc.ID, err)
+	}
+
+	return nil
+}
+
+// Remove deletes a document from the Meilisearch index. Removing a document that is not
+// indexed is not an error.
+func (e *MeilisearchEngine) Remove(ctx context.Context, docID string) error {
+	if err := e.do(ctx, http.MethodDelete, e.indexPath("documents", documentKey(docID)), nil, nil); err != nil {
+		return fmt.Errorf("failed to remove document %s: %w", docID, err)
+	}
+
+	return nil
+}
+
+// meiliSearchResponse represents the Meilisearch search response structure.
+type meiliSearchResponse struct {
+	FacetDistribution  map[string]map[string]int `json:"facetDistribution"`
+	Hits               []meiliHit                `json:"hits"`
+	EstimatedTotalHits uint64                    `json:"estimatedTotalHits"`
+	ProcessingTimeMs   int64                     `json:"processingTimeMs"`
+}
+
+// meiliHit represents a single hit in a Meilisearch search response.
+type meiliHit struct {
+	ID           string         `json:"id"`
+	Repo         string         `json:"repo"`
+	Path         string         `json:"path"`
+	Title        string         `json:"title"`
+	ContentType  string         `json:"content_type"`
+	Content      string         `json:"content"`
+	AnchorMap    string         `json:"anchor_map"`
+	Formatted    meiliFormatted `json:"_formatted"`
+	RankingScore float64        `json:"_rankingScore"`
+}
+
+// meiliFormatted holds the highlighted and cropped fields of a hit.
+type meiliFormatted struct {
+	Title   string `json:"title"`
+	Content string `json:"content"`
+	Code    string `json:"code"`
+}
+
+// Search performs a full-text search query against Meilisearch and returns matching results.
+// Matches are highlighted with <mark> tags; fields without a match yield no fragments.
+func (e *MeilisearchEngine) Search(ctx context.Context, query string, opts core.SearchOpts) (*core.SearchResults, error) {
+	if opts.Limit <= 0 {
+		opts.Limit = 20
+	}
+
+	searchOn := []string{fieldTitle, fieldContent, fieldCode}
+	if opts.CodeOnly {
+		searchOn = []string{fieldCode}
+	}
+
+	sq := parseSearchQuery(query)
+
+	body := map[string]any{
+		"q":                    meiliQueryText(&sq),
+		"limit":                opts.Limit,
+		"offset":               opts.Offset,
+		"attributesToSearchOn": searchOn,
+		"attributesToRetrieve": []string{meiliFieldID, fieldRepo, fieldPath, fieldTitle, fieldContentType},
+		"showRankingScore":     true,
+	}
+
+	if !opts.Quick {
+		body["attributesToRetrieve"] = []string{meiliFieldID, fieldRepo, fieldPath, fieldTitle, fieldContentType, fieldContent, fieldAnchorMap}
+		body["attributesToHighlight"] = []string{fieldTitle, fieldContent, fieldCode}
+		body["attributesToCrop"] = []string{fieldContent, fieldCode}
+		body["cropLength"] = meiliCropLength
+		body["highlightPreTag"] = "<mark>"
+		body["highlightPostTag"] = "</mark>"
+		body["facets"] = []string{fieldLangs, fieldContentType}
+	}
+
+	if filter := buildMeiliFilter(opts, &sq); len(filter) > 0 {
+		body["filter"] = filter
+	}
+
+	var resp meiliSearchResponse
+	if err := e.do(ctx, http.MethodPost, e.indexPath("search"), body, &resp); err != nil {
+		return nil, fmt.Errorf("search request failed: %w", err)
+	}
+
+	hits := make([]core.SearchResult, 0, len(resp.Hits))
+
+	for i := range resp.Hits {
+		hit := &resp.Hits[i]
+
+		sr := core.SearchResult{
+			ID:               hit.ID,
+			Score:            hit.RankingScore,
+			Repo:             hit.Repo,
+			Path:             hit.Path,
+			Title:            hit.Title,
+			ContentType:      core.ContentType(hit.ContentType),
+			TitleFragments:   highlightedFragments(hit.Formatted.Title),
+			ContentFragments: highlightedFragments(hit.Formatted.Content, hit.Formatted.Code),
+		}
+		setIndexedAnchors(&sr, hit.Content, hit.AnchorMap)
+		hits = append(hits, sr)
+	}
+
+	return &core.SearchResults{
+		Hits:         hits,
+		Langs:        meiliFacets(resp.FacetDistribution[fieldLangs]),
+		ContentTypes: meiliFacets(resp.FacetDistribution[fieldContentType]),
+		Total:        resp.EstimatedTotalHits,
+		Duration:     time.Duration(resp.ProcessingTimeMs) * time.Millisecond,
+	}, nil
+}
+
+// meiliSuggestHit represents a single hit of a suggestion search.
+type meiliSuggestHit struct {
+	Repo           string   `json:"repo"`
+	Path           string   `json:"path"`
+	Title          string   `json:"title"`
+	Headings       []string `json:"headings"`
+	HeadingAnchors []string `json:"heading_anchors"`
+}
+
+// Suggest returns up to limit documents whose titles or section headings match prefix.
+// Meilisearch matches the last word of the prefix as the start of a word.
+func (e *MeilisearchEngine) Suggest(ctx context.Context, prefix string, limit int) ([]core.SearchSuggestion, error) {
+	sq := parseSuggestQuery(prefix)
+	if sq.last == "" {
+		return nil, nil
+	}
+
+	body := map[string]any{
+		"q":                    prefix,
+		"limit":                limit,
+		"attributesToSearchOn": []string{fieldTitle, fieldHeadings},
+		"attributesToRetrieve": []string{fieldRepo, fieldPath, fieldTitle, fieldHeadings, fieldHeadingAnchors},
+		"matchingStrategy":     "all",
+	}
+
+	var resp struct {
+		Hits []meiliSuggestHit `json:"hits"`
+	}
+
+	if err := e.do(ctx, http.MethodPost, e.indexPath("search"), body, &resp); err != nil {
+		return nil, fmt.Errorf("suggest request failed: %w", err)
+	}
+
+	suggestions := make([]core.SearchSuggestion, 0, len(resp.Hits))
+
+	for i := range resp.Hits {
+		hit := &resp.Hits[i]
+		suggestions = append(suggestions, sq.suggestion(hit.Repo, hit.Path, hit.Title, hit.Headings, hit.HeadingAnchors))
+	}
+
+	return suggestions, nil
+}
+
+// highlightedFragments returns the formatted fields that contain a highlighted match.
+func highlightedFragments(fields ...string) []string {
+	var fragments []string
+
+	for _, f := range fields {
+		if strings.Contains(f, "<mark>") {
+			fragments = append(fragments, f)
+		}
+	}
+
+	return fragments
+}
+
+// meiliFacets converts a facet distribution to facet counts, ordered by count like the
+// other engines and capped at langFacetSize values.
+func meiliFacets(dist map[string]int) []core.FacetCount {
+	var counts []core.FacetCount
+
+	for value, count := range dist {
+		counts = append(counts, core.FacetCount{Value: value, Count: count})
+	}
+
+	slices.SortFunc(counts, func(a, b core.FacetCount) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.Value, b.Value)
+	})
+
+	if len(counts) > langFacetSize {
+		counts = counts[:langFacetSize]
+	}
+
+	return counts
+}
+
+// buildMeiliFilter returns the filter expressions for the language, repository and
+// content type restrictions of opts, for the owners or repositories of the repo: and
+// -repo: qualifiers of sq and for its tag: and -tag: qualifiers. Expressions in the
+// returned slice are combined with AND.
+func buildMeiliFilter(opts core.SearchOpts, sq *searchQuery) []string {
+	var filter []string
+
+	if opts.Lang != "" {
+		filter = append(filter, fieldLangs+" = "+quoteMeiliValue(strings.ToLower(opts.Lang)))
+	}
+
+	for _, scopes := range [][]string{opts.Repos, sq.repos} {
+		if len(scopes) == 0 {
+			continue
+		}
+
+		values := make([]string, 0, len(scopes))
+		for _, repo := range scopes {
+			values = append(values, quoteMeiliValue(repo))
+		}
+
+		filter = append(filter, meiliFieldScopes+" IN ["+strings.Join(values, ", ")+"]")
+	}
+
+	if len(sq.excludedRepos) > 0 {
+		values := make([]string, 0, len(sq.excludedRepos))
+		for _, repo := range sq.excludedRepos {
+			values = append(values, quoteMeiliValue(repo))
+		}
+
+		filter = append(filter, meiliFieldScopes+" NOT IN ["+strings.Join(values, ", ")+"]")
+	}
+
+	for _, tag := range sq.tags {
+		filter = append(filter, fieldTags+" = "+quoteMeiliValue(tag))
+	}
+
+	if len(sq.excludedTags) > 0 {
+		values := make([]string, 0, len(sq.excludedTags))
+		for _, tag := range sq.excludedTags {
+			values = append(values, quoteMeiliValue(tag))
+		}
+
+		filter = append(filter, fieldTags+" NOT IN ["+strings.Join(values, ", ")+"]")
+	}
+
+	if opts.Repo != "" {
+		filter = append(filter, fieldRepo+" = "+quoteMeiliValue(opts.Repo))
+	}
+
+	if len(opts.ContentTypes) > 0 {
+		values := make([]string, 0, len(opts.ContentTypes))
+		for _, ct := range opts.ContentTypes {
+			values = append(values, quoteMeiliValue(indexedContentType(ct)))
+		}
+
+		filter = append(filter, fieldContentType+" IN ["+strings.Join(values, ", ")+"]")
+	}
+
+	return filter
+}
+
+// meiliQueryText returns the text Meilisearch searches for a query: its terms, with
+// phrases quoted and negated terms prefixed with "-". Meilisearch has no OR or grouping,
+// so alternatives and grouped terms are searched like other terms and negated groups are
+// dropped. It cannot restrict a term to a field, so title: terms are searched like other
+// terms, and it cannot match path patterns, so path: qualifiers are ignored.
+func meiliQueryText(sq *searchQuery) string {
+	var words []string
+
+	var walk func(e *queryExpr)
+	walk = func(e *queryExpr) {
+		if e.op != exprTerm {
+			if !e.negated {
+				for _, sub := range e.exprs {
+					walk(sub)
+				}
+			}
+
+			return
+		}
+
+		word := e.term.text
+		if e.term.phrase {
+			word = `"` + word + `"`
+		}
+
+		if e.negated {
+			word = "-" + word
+		}
+
+		words = append(words, word)
+	}
+
+	if sq.expr != nil {
+		walk(sq.expr)
+	}
+
+	return strings.Join(words, " ")
+}
+
+// quoteMeiliValue quotes a value for use in a Meilisearch filter expression.
+func quoteMeiliValue(v string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(v) + `"`
+}
+
+// meiliDocumentsResponse represents a page of documents fetched from Meilisearch.
+type meiliDocumentsResponse struct {
+	Results []struct {
+		ID string `json:"id"`
+	} `json:"results"`
+	Total int `json:"total"`
+}
+
+// ListByRepo returns the IDs of all documents in the index that belong to the given repository.
+func (e *MeilisearchEngine) ListByRepo(ctx context.Context, repo string) ([]string, error) {
+	var ids []string
+
+	for offset := 0; ; offset += meiliListPageSize {
+		body := map[string]any{
+			"filter": fieldRepo + " = " + quoteMeiliValue(repo),
+			"fields": []string{meiliFieldID},
+			"limit":  meiliListPageSize,
+			"offset": offset,
+		}
+
+		var resp meiliDocumentsResponse
+		if err := e.do(ctx, http.MethodPost, e.indexPath("documents", "fetch"), body, &resp); err != nil {
+			return nil, fmt.Errorf("failed to list documents for repo %s: %w", repo, err)
+		}
+
+		for _, r := range resp.Results {
+			ids = append(ids, r.ID)
+		}
+
+		if len(resp.Results) < meiliListPageSize || offset+len(resp.Results) >= resp.Total {
+			return ids, nil
+		}
+	}
+}
+
+// ensureIndex creates the Meilisearch index if it does not exist and updates its settings.
+// Typo tolerance mirrors the fuzzy matching of the other engines: one typo is allowed in
+// words of minFuzzyTermLength characters and two in words of longTermThreshold.
+func (e *MeilisearchEngine) ensureIndex(ctx context.Context) error {
+	err := e.do(ctx, http.MethodGet, e.indexPath(), nil, nil)
+
+	var apiErr *meiliError
+
+	switch {
+	case err == nil:
+	case errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound:
+		create := map[string]any{"uid": e.index, "primaryKey": meiliPrimaryKey}
+		if err := e.do(ctx, http.MethodPost, "/indexes", create, nil); err != nil {
+			return fmt.Errorf("failed to create index: %w", err)
+		}
+	default:
+		return fmt.Errorf("failed to check index existence: %w", err)
+	}
+
+	settings := map[string]any{
+		"searchableAttributes": []string{fieldTitle, fieldContent, fieldCode, fieldHeadings},
+		"filterableAttributes": []string{fieldRepo, meiliFieldScopes, fieldLangs, fieldContentType, fieldTags},
+		"typoTolerance": map[string]any{
+			"enabled": true,
+			"minWordSizeForTypos": map[string]any{
+				"oneTypo":  minFuzzyTermLength,
+				"twoTypos": longTermThreshold,
+			},
+		},
+	}
+
+	if err := e.do(ctx, http.MethodPatch, e.indexPath("settings"), settings, nil); err != nil {
+		return fmt.Errorf("failed to update index settings: %w", err)
+	}
+
+	return nil
+}
+
+// indexPath returns the API path of the engine's index, followed by the given segments.
+func (e *MeilisearchEngine) indexPath(segments ...string) string {
+	p := "/indexes/" + url.PathEscape(e.index)
+
+	for _, s := range segments {
+		p += "/" + url.PathEscape(s)
+	}
+
+	return p
+}
+
+// meiliError is an error response of the Meilisearch API.
+type meiliError struct {
+	Message string `json:"message"`
+	Code    string `json:"code"`
+	Status  int    `json:"-"`
+}
+
+func (e *meiliError) Error() string {
+	return fmt.Sprintf("meilisearch error %d (%s): %s", e.Status, e.Code, e.Message)
+}
+
+// do sends a request with a JSON body to the Meilisearch API and decodes the JSON
+// response into out, if out is not nil. Non-2xx responses are returned as *meiliError.
+func (e *MeilisearchEngine) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, e.address+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := &meiliError{Status: resp.StatusCode}
+		if err := json.NewDecoder(resp.Body).Decode(apiErr); err != nil || apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+
+		return apiErr
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,320 @@
+package search
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestMeilisearchEngine creates a MeilisearchEngine backed by a mock HTTP server whose
+// index already exists.
+func newTestMeilisearchEngine(t *testing.T, handler *mockESHandler) *MeilisearchEngine {
+	t.Helper()
+
+	handler.handlers["GET /indexes/omnidex"] = func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"uid":"omnidex","primaryKey":"key"}`))
+	}
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	engine, err := NewMeilisearch(t.Context(), &MeilisearchConfig{Address: srv.URL + "/", APIKey: "secret"})
+	require.NoError(t, err)
+
+	return engine
+}
+
+// requestBody returns the decoded JSON body of the last request to method and path.
+func requestBody(t *testing.T, handler *mockESHandler, method, path string) any {
+	t.Helper()
+
+	var body any
+
+	for _, r := range handler.getRequests() {
+		if r.Method == method && r.Path == path {
+			require.NoError(t, json.Unmarshal([]byte(r.Body), &body))
+		}
+	}
+
+	require.NotNil(t, body, "no request to %s %s", method, path)
+
+	return body
+}
+
+func TestNewMeilisearch_CreatesIndex(t *testing.T) {
+	handler := newMockESHandler()
+	handler.handlers["GET /indexes/docs"] = func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"Index docs not found.","code":"index_not_found"}`))
+	}
+
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	_, err := NewMeilisearch(t.Context(), &MeilisearchConfig{Address: srv.URL, Index: "docs"})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{"uid": "docs", "primaryKey": "key"}, requestBody(t, handler, http.MethodPost, "/indexes"))
+
+	settings := requestBody(t, handler, http.MethodPatch, "/indexes/docs/settings").(map[string]any)
+	assert.Equal(t, []any{"repo", "scopes", "langs", "content_type", "tags"}, settings["filterableAttributes"])
+	assert.Equal(t, map[string]any{
+		"enabled":             true,
+		"minWordSizeForTypos": map[string]any{"oneTypo": float64(4), "twoTypos": float64(7)},
+	}, settings["typoTolerance"])
+}
+
+func TestNewMeilisearch_Errors(t *testing.T) {
+	_, err := NewMeilisearch(t.Context(), &MeilisearchConfig{})
+	require.Error(t, err)
+
+	handler := newMockESHandler()
+	handler.handlers["GET /indexes/omnidex"] = func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message":"The provided API key is invalid.","code":"invalid_api_key"}`))
+	}
+
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	_, err = NewMeilisearch(t.Context(), &MeilisearchConfig{Address: srv.URL})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid_api_key")
+}
+
+func TestMeilisearchEngine_Index(t *testing.T) {
+	handler := newMockESHandler()
+
+	var auth string
+
+	handler.handlers["POST /indexes/omnidex/documents"] = func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"taskUid":1}`))
+	}
+
+	engine := newTestMeilisearchEngine(t, handler)
+
+	doc := core.Document{ID: "owner/mono/service/guide.md", Repo: "owner/mono/service", Path: "guide.md", Title: "Guide"}
+	err := engine.Index(t.Context(), doc, "plain text", []core.CodeBlock{{Lang: "go", Code: "fmt.Println()"}}, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Bearer secret", auth)
+	assert.Equal(t, []any{map[string]any{
+		"key":          "b3duZXIvbW9uby9zZXJ2aWNlL2d1aWRlLm1k",
+		"id":           "owner/mono/service/guide.md",
+		"repo":         "owner/mono/service",
+		"path":         "guide.md",
+		"title":        "Guide",
+		"content":      "plain text",
+		"code":         "fmt.Println()",
+		"langs":        []any{"go"},
+		"scopes":       []any{"owner", "owner/mono", "owner/mono/service"},
+		"content_type": "markdown",
+	}}, requestBody(t, handler, http.MethodPost, "/indexes/omnidex/documents"))
+}
+
+func TestMeilisearchEngine_Remove(t *testing.T) {
+	handler := newMockESHandler()
+	handler.handlers["DELETE /indexes/omnidex/documents/b3duZXIvcmVwby9ndWlkZS5tZA"] = func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"taskUid":2}`))
+	}
+	handler.handlers["DELETE"] = func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+
+	engine := newTestMeilisearchEngine(t, handler)
+
+	require.NoError(t, engine.Remove(t.Context(), "owner/repo/guide.md"))
+	assert.Error(t, engine.Remove(t.Context(), "owner/repo/other.md"))
+}
+
+func TestMeilisearchEngine_Search(t *testing.T) {
+	handler := newMockESHandler()
+	handler.handlers["POST /indexes/omnidex/search"] = func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{
+			"hits": [
+				{
+					"id": "owner/repo/guide.md", "repo": "owner/repo", "path": "guide.md", "title": "Install Guide", "content_type": "markdown",
+					"content": "Setup\nrun the installer", "anchor_map": "[{\"id\":\"setup\",\"text\":\"Setup\",\"offset\":0,\"level\":2}]",
+					"_formatted": {"title": "<mark>Install</mark> Guide", "content": "…run the <mark>installer</mark>…", "code": "go build"},
+					"_rankingScore": 0.9
+				},
+				{
+					"id": "owner/repo/api.md", "repo": "owner/repo", "path": "api.md", "title": "API",
+					"_formatted": {"title": "API", "content": "", "code": "…<mark>install</mark>()…"},
+					"_rankingScore": 0.5
+				}
+			],
+			"estimatedTotalHits": 2,
+			"processingTimeMs": 3,
+			"facetDistribution": {"langs": {"python": 1, "go": 2, "bash": 1}}
+		}`))
+	}
+
+	engine := newTestMeilisearchEngine(t, handler)
+
+	results, err := engine.Search(t.Context(), "instal", core.SearchOpts{Repos: []string{"owner", `my"org/repo`}, Lang: "Go"})
+	require.NoError(t, err)
+
+	assert.Equal(t, &core.SearchResults{
+		Hits: []core.SearchResult{
+			{
+				ID: "owner/repo/guide.md", Repo: "owner/repo", Path: "guide.md", Title: "Install Guide", ContentType: core.ContentTypeMarkdown, Score: 0.9,
+				TitleFragments:   []string{"<mark>Install</mark> Guide"},
+				ContentFragments: []string{"…run the <mark>installer</mark>…"},
+				Anchors:          core.AnchorMap{{ID: "setup", Text: "Setup", Level: 2}},
+				PlainText:        "Setup\nrun the installer",
+			},
+			{
+				ID: "owner/repo/api.md", Repo: "owner/repo", Path: "api.md", Title: "API", Score: 0.5,
+				ContentFragments: []string{"…<mark>install</mark>()…"},
+			},
+		},
+		Langs:    []core.FacetCount{{Value: "go", Count: 2}, {Value: "bash", Count: 1}, {Value: "python", Count: 1}},
+		Total:    2,
+		Duration: 3 * time.Millisecond,
+	}, results)
+
+	body := requestBody(t, handler, http.MethodPost, "/indexes/omnidex/search").(map[string]any)
+	assert.Equal(t, "instal", body["q"])
+	assert.Equal(t, float64(20), body["limit"])
+	assert.Equal(t, []any{"title", "content", "code"}, body["attributesToSearchOn"])
+	assert.Equal(t, []any{"id", "repo", "path", "title", "content_type", "content", "anchor_map"}, body["attributesToRetrieve"])
+	assert.Equal(t, []any{`langs = "go"`, `scopes IN ["owner", "my\"org/repo"]`}, body["filter"])
+}
+
+func TestMeilisearchEngine_SearchQualifiers(t *testing.T) {
+	handler := newMockESHandler()
+	handler.handlers["POST /indexes/omnidex/search"] = func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"hits": [], "estimatedTotalHits": 0}`))
+	}
+
+	engine := newTestMeilisearchEngine(t, handler)
+
+	_, err := engine.Search(t.Context(), `deploy repo:acme/api path:docs/** title:"getting started" tag:ops -tag:legacy`, core.SearchOpts{Lang: "go"})
+	require.NoError(t, err)
+
+	body := requestBody(t, handler, http.MethodPost, "/indexes/omnidex/search").(map[string]any)
+	assert.Equal(t, `deploy "getting started"`, body["q"])
+	assert.Equal(t, []any{`langs = "go"`, `scopes IN ["acme/api"]`, `tags = "ops"`, `tags NOT IN ["legacy"]`}, body["filter"])
+}
+
+func TestMeilisearchEngine_SearchBooleanOperators(t *testing.T) {
+	handler := newMockESHandler()
+	handler.handlers["POST /indexes/omnidex/search"] = func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"hits": [], "estimatedTotalHits": 0}`))
+	}
+
+	engine := newTestMeilisearchEngine(t, handler)
+
+	_, err := engine.Search(t.Context(), `deploy -kubernetes OR (nomad -"helm chart") -(draft OR wip) -repo:acme/old`, core.SearchOpts{})
+	require.NoError(t, err)
+
+	body := requestBody(t, handler, http.MethodPost, "/indexes/omnidex/search").(map[string]any)
+	assert.Equal(t, `deploy -kubernetes nomad -"helm chart"`, body["q"])
+	assert.Equal(t, []any{`scopes NOT IN ["acme/old"]`}, body["filter"])
+}
+
+func TestMeilisearchEngine_SearchQuick(t *testing.T) {
+	handler := newMockESHandler()
+	handler.handlers["POST /indexes/omnidex/search"] = func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"hits": [{"id": "acme/api/deploy.md", "repo": "acme/api", "path": "deploy.md", "title": "Deploying"}], "estimatedTotalHits": 1}`))
+	}
+
+	engine := newTestMeilisearchEngine(t, handler)
+
+	results, err := engine.Search(t.Context(), "deploy", core.SearchOpts{Quick: true, Limit: 5})
+	require.NoError(t, err)
+	require.Len(t, results.Hits, 1)
+	assert.Equal(t, "Deploying", results.Hits[0].Title)
+
+	body := requestBody(t, handler, http.MethodPost, "/indexes/omnidex/search").(map[string]any)
+	assert.NotContains(t, body, "attributesToHighlight")
+	assert.NotContains(t, body, "attributesToCrop")
+	assert.NotContains(t, body, "facets")
+}
+
+func TestMeilisearchEngine_SearchContentTypes(t *testing.T) {
+	handler := newMockESHandler()
+	handler.handlers["POST /indexes/omnidex/search"] = func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"hits": [], "estimatedTotalHits": 3, "facetDistribution": {"content_type": {"markdown": 1, "openapi": 2}}}`))
+	}
+
+	engine := newTestMeilisearchEngine(t, handler)
+
+	results, err := engine.Search(t.Context(), "users", core.SearchOpts{ContentTypes: []core.ContentType{core.ContentTypeOpenAPI, ""}})
+	require.NoError(t, err)
+	assert.Equal(t, []core.FacetCount{{Value: "openapi", Count: 2}, {Value: "markdown", Count: 1}}, results.ContentTypes)
+
+	body := requestBody(t, handler, http.MethodPost, "/indexes/omnidex/search").(map[string]any)
+	assert.Equal(t, []any{"langs", "content_type"}, body["facets"])
+	assert.Equal(t, []any{`content_type IN ["openapi", "markdown"]`}, body["filter"])
+}
+
+func TestMeilisearchEngine_SearchCodeOnly(t *testing.T) {
+	handler := newMockESHandler()
+	handler.handlers["POST /indexes/omnidex/search"] = func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"hits": [], "estimatedTotalHits": 0}`))
+	}
+
+	engine := newTestMeilisearchEngine(t, handler)
+
+	results, err := engine.Search(t.Context(), "Println", core.SearchOpts{CodeOnly: true, Limit: 5, Offset: 10})
+	require.NoError(t, err)
+	assert.Empty(t, results.Hits)
+
+	body := requestBody(t, handler, http.MethodPost, "/indexes/omnidex/search").(map[string]any)
+	assert.Equal(t, []any{"code"}, body["attributesToSearchOn"])
+	assert.Equal(t, float64(5), body["limit"])
+	assert.Equal(t, float64(10), body["offset"])
+	assert.NotContains(t, body, "filter")
+}
+
+func TestMeilisearchEngine_ListByRepo(t *testing.T) {
+	handler := newMockESHandler()
+
+	calls := 0
+
+	handler.handlers["POST /indexes/omnidex/documents/fetch"] = func(w http.ResponseWriter, _ *http.Request) {
+		var req struct {
+			Filter string `json:"filter"`
+			Offset int    `json:"offset"`
+		}
+
+		reqs := handler.getRequests()
+		_ = json.Unmarshal([]byte(reqs[len(reqs)-1].Body), &req)
+
+		calls++
+
+		assert.Equal(t, `repo = "owner/repo"`, req.Filter)
+
+		if req.Offset == 0 {
+			results := make([]map[string]string, meiliListPageSize)
+			for i := range results {
+				results[i] = map[string]string{"id": "owner/repo/a.md"}
+			}
+
+			_ = json.NewEncoder(w).Encode(map[string]any{"results": results, "total": meiliListPageSize + 1})
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"results": [{"id": "owner/repo/b.md"}], "total": 1001}`))
+	}
+
+	engine := newTestMeilisearchEngine(t, handler)
+
+	ids, err := engine.ListByRepo(t.Context(), "owner/repo")
+	require.NoError(t, err)
+	assert.Len(t, ids, meiliListPageSize+1)
+	assert.Equal(t, "owner/repo/b.md", ids[meiliListPageSize])
+	assert.Equal(t, 2, calls)
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/ksysoev/omnidex/pkg/core"
@@ -17,15 +18,16 @@ import (
 type OpenSearchConfig struct {
 	Index     string   `mapstructure:"index"`
 	Username  string   `mapstructure:"username"`
-	Password  string   `mapstructure:"password"`
+	Password  string   `mapstructure:"password" redact:"true"`
 	CACert    string   `mapstructure:"ca_cert"`
 	Addresses []string `mapstructure:"addresses"`
 }
 
 // OpenSearchEngine implements full-text search using OpenSearch.
 type OpenSearchEngine struct {
-	client *opensearchapi.Client
-	index  string
+	client  *opensearchapi.Client
+	index   string
+	rebuilt bool
 }
 
 // NewOpenSearch creates a new OpenSearch search engine.
@@ -76,22 +78,15 @@ func buildOSClientConfig(cfg *OpenSearchConfig) opensearch.Config {
 }
 
 // Index adds or updates a document in the OpenSearch index.
-func (e *OpenSearchEngine) Index(ctx context.Context, doc core.Document, plainText string) error { //nolint:gocritic // Document is passed by value for immutability
-	body := map[string]string{
-		fieldTitle:   doc.Title,
-		fieldContent: plainText,
-		fieldRepo:    doc.Repo,
-		fieldPath:    doc.Path,
-	}
-
-	data, err := json.Marshal(body)
+func (e *OpenSearchEngine) Index(ctx context.Context, doc core.Document, plainText string, code []core.CodeBlock, headings []core.Heading) error { //nolint:gocritic // Document is passed by value for immutability
+	data, err := json.Marshal(buildDocumentBody(doc, plainText, code, headings))
 	if err != nil {
 		return fmt.Errorf("failed to marshal document %s: %w", doc.ID, err)
 	}
 
 	resp, err := e.client.Index(ctx, opensearchapi.IndexReq{
 		Index:      e.index,
-		DocumentID: doc.ID,
+		DocumentID: documentKey(doc.ID),
 		Body:       bytes.NewReader(data),
 		Params:     opensearchapi.IndexParams{Refresh: "false"},
 	})
@@ -110,7 +105,7 @@ func (e *OpenSearchEngine) Index(ctx context.Context, doc core.Document, plainTe
 func (e *OpenSearchEngine) Remove(ctx context.Context, docID string) error {
 	resp, err := e.client.Document.Delete(ctx, opensearchapi.DocumentDeleteReq{
 		Index:      e.index,
-		DocumentID: docID,
+		DocumentID: documentKey(docID),
 	})
 
 	// Document.Delete returns an error for non-2xx responses including 404.
@@ -132,21 +127,19 @@ func (e *OpenSearchEngine) Search(ctx context.Context, query string, opts core.S
 		opts.Limit = 20
 	}
 
-	esQuery := e.buildSearchQuery(query)
+	esQuery := e.buildSearchQuery(query, opts)
 
 	body := map[string]any{
 		dslQuery:  esQuery,
 		dslSize:   opts.Limit,
 		"from":    opts.Offset,
-		dslSource: []string{fieldRepo, fieldPath, fieldTitle},
-		dslHighlight: map[string]any{
-			dslFields: map[string]any{
-				fieldTitle:   map[string]any{dslNumberOfFragments: 3},
-				fieldContent: map[string]any{"fragment_size": 200, dslNumberOfFragments: 3},
-			},
-			"pre_tags":  []string{"<mark>"},
-			"post_tags": []string{"</mark>"},
-		},
+		dslSource: []string{fieldRepo, fieldPath, fieldTitle, fieldContentType},
+	}
+
+	if !opts.Quick {
+		body[dslSource] = []string{fieldRepo, fieldPath, fieldTitle, fieldContentType, fieldContent, fieldAnchorMap}
+		body[dslHighlight] = buildHighlight()
+		body[dslAggs] = buildFacetAggs()
 	}
 
 	data, err := json.Marshal(body)
@@ -182,14 +175,16 @@ func (e *OpenSearchEngine) Search(ctx context.Context, query string, opts core.S
 		}
 
 		sr := core.SearchResult{
-			ID:               hit.ID,
+			ID:               keyDocumentID(hit.ID),
 			Score:            float64(hit.Score),
 			Repo:             src.Repo,
 			Path:             src.Path,
 			Title:            src.Title,
+			ContentType:      core.ContentType(src.ContentType),
 			TitleFragments:   hit.Highlight[fieldTitle],
-			ContentFragments: hit.Highlight[fieldContent],
+			ContentFragments: append(hit.Highlight[fieldContent], hit.Highlight[fieldCode]...),
 		}
+		setIndexedAnchors(&sr, src.Content, src.AnchorMap)
 		hits = append(hits, sr)
 	}
 
@@ -198,13 +193,62 @@ func (e *OpenSearchEngine) Search(ctx context.Context, query string, opts core.S
 		total = uint64(resp.Hits.Total.Value)
 	}
 
+	var aggs esAggregations
+	if len(resp.Aggregations) > 0 {
+		if err := json.Unmarshal(resp.Aggregations, &aggs); err != nil {
+			return nil, fmt.Errorf("failed to decode aggregations: %w", err)
+		}
+	}
+
 	return &core.SearchResults{
-		Hits:     hits,
-		Total:    total,
-		Duration: duration,
+		Hits:         hits,
+		Langs:        aggs.Langs.facets(),
+		ContentTypes: aggs.ContentTypes.facets(),
+		Total:        total,
+		Duration:     duration,
 	}, nil
 }
 
+// Suggest returns up to limit documents whose titles or section headings match prefix,
+// where every word but the last must match a whole word and the last may be the start
+// of one.
+func (e *OpenSearchEngine) Suggest(ctx context.Context, prefix string, limit int) ([]core.SearchSuggestion, error) {
+	sq := parseSuggestQuery(prefix)
+	if sq.last == "" {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(buildSuggestDSL(prefix, limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal suggest query: %w", err)
+	}
+
+	resp, err := e.client.Search(ctx, &opensearchapi.SearchReq{
+		Indices: []string{e.index},
+		Body:    bytes.NewReader(data),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("suggest request failed: %w", err)
+	}
+
+	if resp.Inspect().Response.IsError() {
+		return nil, fmt.Errorf("opensearch suggest error: %s", resp.Inspect().Response.String())
+	}
+
+	suggestions := make([]core.SearchSuggestion, 0, len(resp.Hits.Hits))
+
+	for i := range resp.Hits.Hits {
+		var src esSource
+		if err := json.Unmarshal(resp.Hits.Hits[i].Source, &src); err != nil {
+			return nil, fmt.Errorf("failed to decode hit source: %w", err)
+		}
+
+		suggestions = append(suggestions, sq.suggestion(src.Repo, src.Path, src.Title, src.Headings, src.HeadingAnchors))
+	}
+
+	return suggestions, nil
+}
+
 // ListByRepo returns the IDs of all documents in the index that belong to the given repository.
 func (e *OpenSearchEngine) ListByRepo(ctx context.Context, repo string) ([]string, error) {
 	ids := make([]string, 0, esListByRepoPageSize)
@@ -249,7 +293,7 @@ func (e *OpenSearchEngine) ListByRepo(ctx context.Context, repo string) ([]strin
 		}
 
 		for i := range resp.Hits.Hits {
-			ids = append(ids, resp.Hits.Hits[i].ID)
+			ids = append(ids, keyDocumentID(resp.Hits.Hits[i].ID))
 		}
 
 		lastHit := resp.Hits.Hits[len(resp.Hits.Hits)-1]
@@ -277,12 +321,18 @@ func (e *OpenSearchEngine) ensureIndex(ctx context.Context) error {
 			return fmt.Errorf("failed to check index existence: %w", err)
 		}
 	} else {
-		// 2xx — index already exists.
-		return nil
+		// 2xx — index already exists; drop documents keyed by an older scheme and add the
+		// fields introduced since it was created.
+		if err := e.migrateKeys(ctx); err != nil {
+			return err
+		}
+
+		return e.updateMapping(ctx)
 	}
 
 	mapping := map[string]any{
 		"mappings": map[string]any{
+			mappingMeta: keySchemeMeta(),
 			"properties": map[string]any{
 				fieldTitle: map[string]any{
 					dslType:           mappingTypeText,
@@ -300,6 +350,33 @@ func (e *OpenSearchEngine) ensureIndex(ctx context.Context) error {
 				fieldPath: map[string]any{
 					dslType: mappingTypeKeyword,
 				},
+				fieldCode: map[string]any{
+					dslType:           mappingTypeText,
+					mappingAnalyzer:   mappingAnalyzerStandard,
+					mappingTermVector: mappingTermVectorPositions,
+				},
+				fieldLangs: map[string]any{
+					dslType: mappingTypeKeyword,
+				},
+				fieldContentType: map[string]any{
+					dslType: mappingTypeKeyword,
+				},
+				fieldHeadings: map[string]any{
+					dslType:         mappingTypeText,
+					mappingAnalyzer: mappingAnalyzerStandard,
+				},
+				fieldHeadingAnchors: map[string]any{
+					dslType: mappingTypeKeyword,
+					"index": false,
+				},
+				fieldAnchorMap: map[string]any{
+					dslType:      mappingTypeKeyword,
+					"index":      false,
+					"doc_values": false,
+				},
+				fieldTags: map[string]any{
+					dslType: mappingTypeKeyword,
+				},
 			},
 		},
 	}
@@ -324,8 +401,70 @@ func (e *OpenSearchEngine) ensureIndex(ctx context.Context) error {
 	return nil
 }
 
+// NeedsReindex reports whether the documents of the index were deleted on startup because
+// they were keyed by an older scheme. The index must then be repopulated from the
+// document store.
+func (e *OpenSearchEngine) NeedsReindex() bool {
+	return e.rebuilt
+}
+
+// migrateKeys deletes the documents of an index created before documents were keyed by
+// documentKey and marks the index for reindexing, as ElasticEngine.migrateKeys does.
+func (e *OpenSearchEngine) migrateKeys(ctx context.Context) error {
+	resp, err := e.client.Indices.Mapping.Get(ctx, &opensearchapi.MappingGetReq{Indices: []string{e.index}})
+	if err != nil {
+		return fmt.Errorf("failed to get index mapping: %w", err)
+	}
+
+	for _, m := range resp.Indices {
+		if keyScheme(m.Mappings) >= keySchemeVersion {
+			return nil
+		}
+	}
+
+	delResp, err := e.client.Document.DeleteByQuery(ctx, opensearchapi.DocumentDeleteByQueryReq{
+		Indices: []string{e.index},
+		Body:    strings.NewReader(matchAllQuery),
+		Params:  opensearchapi.DocumentDeleteByQueryParams{Conflicts: "proceed", Refresh: opensearchapi.ToPointer(true)},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete documents keyed by raw IDs: %w", err)
+	}
+
+	if delResp.Inspect().Response.IsError() {
+		return fmt.Errorf("opensearch delete documents keyed by raw IDs error: %s", delResp.Inspect().Response.String())
+	}
+
+	e.rebuilt = true
+
+	return nil
+}
+
+// updateMapping adds the fields introduced after the first release to an existing index,
+// so that they are not mapped dynamically as text when documents are reindexed.
+func (e *OpenSearchEngine) updateMapping(ctx context.Context) error {
+	data, err := json.Marshal(addedFieldsMapping())
+	if err != nil {
+		return fmt.Errorf("failed to marshal index mapping: %w", err)
+	}
+
+	resp, err := e.client.Indices.Mapping.Put(ctx, opensearchapi.MappingPutReq{
+		Indices: []string{e.index},
+		Body:    bytes.NewReader(data),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update index mapping: %w", err)
+	}
+
+	if resp.Inspect().Response.IsError() {
+		return fmt.Errorf("opensearch update index mapping error: %s", resp.Inspect().Response.String())
+	}
+
+	return nil
+}
+
 // buildSearchQuery constructs an OpenSearch query DSL from user input.
 // It mirrors the hybrid query logic from ElasticEngine.buildSearchQuery.
-func (e *OpenSearchEngine) buildSearchQuery(userQuery string) map[string]any {
-	return buildQueryDSL(userQuery)
+func (e *OpenSearchEngine) buildSearchQuery(userQuery string, opts core.SearchOpts) map[string]any {
+	return buildSearchDSL(userQuery, opts)
 }
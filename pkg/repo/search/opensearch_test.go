@@ -41,6 +41,64 @@ func TestNewOpenSearch_Success(t *testing.T) {
 	assert.Equal(t, "omnidex", engine.index)
 }
 
+func TestNewOpenSearch_UpdatesExistingIndexMapping(t *testing.T) {
+	handler := newMockESHandler()
+
+	_, srv := newTestOpenSearchEngine(t, handler)
+	defer srv.Close()
+
+	var body string
+
+	for _, r := range handler.getRequests() {
+		if r.Method == http.MethodPut && r.Path == "/omnidex/_mapping" {
+			body = r.Body
+		}
+	}
+
+	assert.JSONEq(t, `{"_meta":{"omnidex_key_scheme":1},"properties":{"content_type":{"type":"keyword"},`+
+		`"headings":{"type":"text","analyzer":"standard"},"heading_anchors":{"type":"keyword","index":false},`+
+		`"anchor_map":{"type":"keyword","index":false,"doc_values":false},"tags":{"type":"keyword"}}}`, body)
+}
+
+func TestNewOpenSearch_MigratesRawKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping string
+		migrate bool
+	}{
+		{name: "raw keys", mapping: `{"omnidex":{"mappings":{"properties":{}}}}`, migrate: true},
+		{name: "encoded keys", mapping: `{"omnidex":{"mappings":{"_meta":{"omnidex_key_scheme":1}}}}`, migrate: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newMockESHandler()
+			handler.handlers["GET /omnidex/_mapping"] = func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte(tt.mapping))
+			}
+
+			engine, srv := newTestOpenSearchEngine(t, handler)
+			defer srv.Close()
+
+			var deleted string
+
+			for _, r := range handler.getRequests() {
+				if r.Method == http.MethodPost && r.Path == "/omnidex/_delete_by_query" {
+					deleted = r.Body
+				}
+			}
+
+			assert.Equal(t, tt.migrate, engine.NeedsReindex())
+
+			if tt.migrate {
+				assert.JSONEq(t, `{"query":{"match_all":{}}}`, deleted)
+			} else {
+				assert.Empty(t, deleted)
+			}
+		})
+	}
+}
+
 func TestNewOpenSearch_DefaultIndex(t *testing.T) {
 	handler := newMockESHandler()
 	handler.handlers["HEAD /omnidex"] = func(w http.ResponseWriter, _ *http.Request) {
@@ -111,9 +169,9 @@ func TestNewOpenSearch_EnsureIndexError(t *testing.T) {
 func TestOpenSearchEngine_Index(t *testing.T) {
 	handler := newMockESHandler()
 
-	handler.handlers["PUT /omnidex/_doc/owner/repo/doc.md"] = func(w http.ResponseWriter, _ *http.Request) {
+	handler.handlers["PUT /omnidex/_doc/b3duZXIvcmVwby9kb2MubWQ"] = func(w http.ResponseWriter, _ *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
-		_, _ = w.Write([]byte(`{"_index":"omnidex","_id":"owner/repo/doc.md","result":"created","_version":1,"_shards":{"total":1,"successful":1,"failed":0},"_seq_no":0,"_primary_term":1}`))
+		_, _ = w.Write([]byte(`{"_index":"omnidex","_id":"b3duZXIvcmVwby9kb2MubWQ","result":"created","_version":1,"_shards":{"total":1,"successful":1,"failed":0},"_seq_no":0,"_primary_term":1}`))
 	}
 
 	engine, srv := newTestOpenSearchEngine(t, handler)
@@ -126,16 +184,16 @@ func TestOpenSearchEngine_Index(t *testing.T) {
 		Path:  "doc.md",
 	}
 
-	err := engine.Index(context.Background(), doc, "plain text content")
+	err := engine.Index(context.Background(), doc, "plain text content", nil, nil)
 	require.NoError(t, err)
 }
 
 func TestOpenSearchEngine_Remove(t *testing.T) {
 	handler := newMockESHandler()
 
-	handler.handlers["DELETE /omnidex/_doc/owner/repo/doc.md"] = func(w http.ResponseWriter, _ *http.Request) {
+	handler.handlers["DELETE /omnidex/_doc/b3duZXIvcmVwby9kb2MubWQ"] = func(w http.ResponseWriter, _ *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
-		_, _ = w.Write([]byte(`{"_index":"omnidex","_id":"owner/repo/doc.md","result":"deleted","_version":2,"_shards":{"total":1,"successful":1,"failed":0},"_seq_no":1,"_primary_term":1}`))
+		_, _ = w.Write([]byte(`{"_index":"omnidex","_id":"b3duZXIvcmVwby9kb2MubWQ","result":"deleted","_version":2,"_shards":{"total":1,"successful":1,"failed":0},"_seq_no":1,"_primary_term":1}`))
 	}
 
 	engine, srv := newTestOpenSearchEngine(t, handler)
@@ -148,10 +206,10 @@ func TestOpenSearchEngine_Remove(t *testing.T) {
 func TestOpenSearchEngine_Remove_NotFound(t *testing.T) {
 	handler := newMockESHandler()
 
-	handler.handlers["DELETE /omnidex/_doc/missing/doc"] = func(w http.ResponseWriter, _ *http.Request) {
+	handler.handlers["DELETE /omnidex/_doc/bWlzc2luZy9kb2M"] = func(w http.ResponseWriter, _ *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusNotFound)
-		_, _ = w.Write([]byte(`{"_index":"omnidex","_id":"missing/doc","result":"not_found","_version":0,"_shards":{"total":1,"successful":1,"failed":0},"_seq_no":-2,"_primary_term":0}`))
+		_, _ = w.Write([]byte(`{"_index":"omnidex","_id":"bWlzc2luZy9kb2M","result":"not_found","_version":0,"_shards":{"total":1,"successful":1,"failed":0},"_seq_no":-2,"_primary_term":0}`))
 	}
 
 	engine, srv := newTestOpenSearchEngine(t, handler)
@@ -173,7 +231,7 @@ func TestOpenSearchEngine_Search(t *testing.T) {
 				"hits": [{
 					"_id": "owner/repo/doc.md",
 					"_score": 1.5,
-					"_source": {"repo": "owner/repo", "path": "doc.md", "title": "Test Doc"},
+					"_source": {"repo": "owner/repo", "path": "doc.md", "title": "Test Doc", "content_type": "asyncapi"},
 					"highlight": {"content": ["some <mark>match</mark>"]}
 				}]
 			}
@@ -190,6 +248,7 @@ func TestOpenSearchEngine_Search(t *testing.T) {
 	assert.Equal(t, "owner/repo", results.Hits[0].Repo)
 	assert.Equal(t, "doc.md", results.Hits[0].Path)
 	assert.Equal(t, "Test Doc", results.Hits[0].Title)
+	assert.Equal(t, core.ContentTypeAsyncAPI, results.Hits[0].ContentType)
 	assert.Equal(t, []string{"some <mark>match</mark>"}, results.Hits[0].ContentFragments)
 }
 
@@ -234,8 +293,8 @@ func TestOpenSearchEngine_ListByRepo(t *testing.T) {
 				"hits": map[string]any{
 					"total": map[string]any{"value": 2, "relation": "eq"},
 					"hits": []any{
-						map[string]any{"_id": "owner/repo/a.md", "sort": []any{0}},
-						map[string]any{"_id": "owner/repo/b.md", "sort": []any{1}},
+						map[string]any{"_id": documentKey("owner/repo/a.md"), "sort": []any{0}},
+						map[string]any{"_id": documentKey("owner/repo/b.md"), "sort": []any{1}},
 					},
 				},
 			}
@@ -264,7 +323,7 @@ func TestOpenSearchEngine_ListByRepo(t *testing.T) {
 
 func TestOpenSearchEngine_BuildSearchQuery_SingleTerm(t *testing.T) {
 	engine := &OpenSearchEngine{index: "test"}
-	q := engine.buildSearchQuery("hello")
+	q := engine.buildSearchQuery("hello", core.SearchOpts{})
 
 	boolQ, ok := q["bool"].(map[string]any)
 	require.True(t, ok)
@@ -273,7 +332,7 @@ func TestOpenSearchEngine_BuildSearchQuery_SingleTerm(t *testing.T) {
 
 func TestOpenSearchEngine_BuildSearchQuery_MultiWord(t *testing.T) {
 	engine := &OpenSearchEngine{index: "test"}
-	q := engine.buildSearchQuery("hello world")
+	q := engine.buildSearchQuery("hello world", core.SearchOpts{})
 
 	boolQ, ok := q["bool"].(map[string]any)
 	require.True(t, ok)
@@ -282,7 +341,7 @@ func TestOpenSearchEngine_BuildSearchQuery_MultiWord(t *testing.T) {
 
 func TestOpenSearchEngine_BuildSearchQuery_Empty(t *testing.T) {
 	engine := &OpenSearchEngine{index: "test"}
-	q := engine.buildSearchQuery("")
+	q := engine.buildSearchQuery("", core.SearchOpts{})
 
 	_, hasMatchNone := q["match_none"]
 	assert.True(t, hasMatchNone)
@@ -0,0 +1,253 @@
+package search
+
+import (
+	"strings"
+)
+
+// qualifierFields are the fields a query term can be qualified with: repo:owner/name,
+// path:docs/** and tag:ci narrow the results, title:getting matches titles only.
+var qualifierFields = map[string]string{
+	"repo":  fieldRepo,
+	"path":  fieldPath,
+	"tag":   fieldTags,
+	"title": fieldTitle,
+}
+
+// cutQualifier splits s, starting with a word such as "repo:owner/name", into the field
+// the word is qualified with and the rest of s after the colon. It reports false when the
+// word has no known qualifier or nothing follows the colon, so unknown qualifiers such as
+// "http:" stay plain terms.
+func cutQualifier(s string) (field, value string, ok bool) {
+	name, value, found := strings.Cut(s, ":")
+	if !found || value == "" || value[0] == ' ' || value[0] == '\t' {
+		return "", "", false
+	}
+
+	field, ok = qualifierFields[strings.ToLower(name)]
+
+	return field, value, ok
+}
+
+// maxQueryDepth bounds the nesting of groups in a user query, so that parsing and
+// building the engine query of a query with thousands of parentheses cannot exhaust the
+// stack. A "(" opening a group deeper than this is kept as literal text.
+const maxQueryDepth = 32
+
+// queryOp identifies the operator tokens of a user query.
+type queryOp int
+
+const (
+	opNone  queryOp = iota // a term
+	opOr                   // OR
+	opAnd                  // AND
+	opOpen                 // ( opening a group
+	opClose                // ) closing a group
+)
+
+// exprOp identifies the kind of a node of a query expression.
+type exprOp int
+
+const (
+	exprTerm exprOp = iota // a single term
+	exprAnd                // all of the sub-expressions match
+	exprOr                 // any of the sub-expressions matches
+)
+
+// queryExpr is a boolean expression over the terms of a user query.
+type queryExpr struct {
+	exprs   []*queryExpr
+	term    queryTerm // the term of an exprTerm node
+	op      exprOp
+	negated bool // the expression must not match
+}
+
+// words returns the terms of an AND node joined by spaces, and whether they are all plain
+// unquoted words matched in any field.
+func (e *queryExpr) words() (string, bool) {
+	if e.op != exprAnd {
+		return "", false
+	}
+
+	words := make([]string, 0, len(e.exprs))
+
+	for _, sub := range e.exprs {
+		if sub.op != exprTerm || sub.negated || sub.term.phrase || sub.term.field != "" {
+			return "", false
+		}
+
+		words = append(words, sub.term.text)
+	}
+
+	return strings.Join(words, " "), true
+}
+
+// searchQuery is a user query split into the expression to match and the qualifiers
+// narrowing the results.
+type searchQuery struct {
+	expr          *queryExpr // terms to match; nil when the query has none
+	repos         []string   // owners or repositories of repo: qualifiers; results match any of them
+	paths         []string   // path patterns of path: qualifiers; results match any of them
+	excludedRepos []string   // owners or repositories of -repo: qualifiers; results match none of them
+	excludedPaths []string   // path patterns of -path: qualifiers; results match none of them
+	tags          []string   // lowercased tags of tag: qualifiers; results have all of them
+	excludedTags  []string   // lowercased tags of -tag: qualifiers; results have none of them
+}
+
+// parseSearchQuery parses user input into a searchQuery. Terms next to each other must
+// all match, while OR between them lets either match; AND binds tighter than OR, so
+// "deploy -kubernetes OR nomad" matches documents about deploying without kubernetes and
+// documents about nomad. Parentheses group terms, as in "deploy (kubernetes OR nomad)",
+// and a leading "-" excludes the documents matching a term or group. repo:, path: and tag:
+// qualifiers apply to the whole query wherever they appear.
+func parseSearchQuery(input string) searchQuery {
+	var (
+		q      searchQuery
+		tokens []queryTerm
+	)
+
+	for _, t := range splitQueryTerms(input) {
+		switch {
+		case t.field == fieldRepo && t.negated:
+			q.excludedRepos = append(q.excludedRepos, strings.Trim(t.text, "/"))
+		case t.field == fieldRepo:
+			q.repos = append(q.repos, strings.Trim(t.text, "/"))
+		case t.field == fieldPath && t.negated:
+			q.excludedPaths = append(q.excludedPaths, strings.TrimPrefix(t.text, "/"))
+		case t.field == fieldPath:
+			q.paths = append(q.paths, strings.TrimPrefix(t.text, "/"))
+		case t.field == fieldTags && t.negated:
+			q.excludedTags = append(q.excludedTags, strings.ToLower(t.text))
+		case t.field == fieldTags:
+			q.tags = append(q.tags, strings.ToLower(t.text))
+		default:
+			tokens = append(tokens, t)
+		}
+	}
+
+	p := queryParser{tokens: dropUnmatchedClosers(tokens)}
+	q.expr = p.parseOr()
+
+	return q
+}
+
+// narrowed reports whether the query has qualifiers narrowing the results.
+func (q *searchQuery) narrowed() bool {
+	return len(q.repos) > 0 || len(q.paths) > 0 || len(q.tags) > 0 ||
+		len(q.excludedRepos) > 0 || len(q.excludedPaths) > 0 || len(q.excludedTags) > 0
+}
+
+// queryParser parses the tokens of a user query into a queryExpr.
+type queryParser struct {
+	tokens []queryTerm
+	pos    int
+}
+
+// parseOr parses alternatives separated by OR up to the end of the current group. Stray
+// operators and empty groups are skipped, so any input parses; unmatched closing
+// parentheses are dropped before parsing, see dropUnmatchedClosers.
+func (p *queryParser) parseOr() *queryExpr {
+	var alternatives []*queryExpr
+
+	for p.pos < len(p.tokens) && p.tokens[p.pos].op != opClose {
+		if p.tokens[p.pos].op == opOr {
+			p.pos++
+			continue
+		}
+
+		if e := p.parseAnd(); e != nil {
+			alternatives = append(alternatives, e)
+		}
+	}
+
+	return newQueryExpr(exprOr, alternatives)
+}
+
+// parseAnd parses terms and groups that must all match, up to the next OR or the end of
+// the current group.
+func (p *queryParser) parseAnd() *queryExpr {
+	var all []*queryExpr
+
+	for p.pos < len(p.tokens) {
+		t := p.tokens[p.pos]
+
+		switch t.op {
+		case opOr, opClose:
+			return newQueryExpr(exprAnd, all)
+		case opAnd:
+			p.pos++
+		case opOpen:
+			p.pos++
+
+			e := p.parseOr()
+			if p.pos < len(p.tokens) {
+				p.pos++ // skip the closing parenthesis
+			}
+
+			if e != nil {
+				e.negated = e.negated != t.negated
+				all = append(all, e)
+			}
+		default:
+			p.pos++
+
+			negated := t.negated
+			t.negated = false
+			all = append(all, &queryExpr{op: exprTerm, term: t, negated: negated})
+		}
+	}
+
+	return newQueryExpr(exprAnd, all)
+}
+
+// dropUnmatchedClosers returns tokens without the closing parentheses that close no group.
+// parseOr stops at a closing parenthesis, so one at the top level would leave the terms
+// after it out of the query.
+func dropUnmatchedClosers(tokens []queryTerm) []queryTerm {
+	kept := tokens[:0:0]
+	depth := 0
+
+	for _, t := range tokens {
+		switch t.op {
+		case opOpen:
+			depth++
+		case opClose:
+			if depth == 0 {
+				continue
+			}
+
+			depth--
+		}
+
+		kept = append(kept, t)
+	}
+
+	return kept
+}
+
+// newQueryExpr returns the node combining exprs with op, the only expression when there
+// is one, or nil when there are none.
+func newQueryExpr(op exprOp, exprs []*queryExpr) *queryExpr {
+	switch len(exprs) {
+	case 0:
+		return nil
+	case 1:
+		return exprs[0]
+	default:
+		return &queryExpr{op: op, exprs: exprs}
+	}
+}
+
+// pathPattern returns a path: pattern as a wildcard pattern matching document paths, and
+// false when it has no wildcards. Search engine wildcards match across "/", so "**" is
+// the same as "*".
+func pathPattern(pattern string) (string, bool) {
+	if !strings.ContainsAny(pattern, "*?") {
+		return "", false
+	}
+
+	for strings.Contains(pattern, "**") {
+		pattern = strings.ReplaceAll(pattern, "**", "*")
+	}
+
+	return pattern, true
+}
@@ -0,0 +1,101 @@
+package search
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+// querySeeds are user queries with the quotes, parentheses, operators and qualifiers the
+// query parser handles, balanced or not.
+var querySeeds = []string{
+	"",
+	"deploy nomad",
+	"deploy -kubernetes OR nomad",
+	"deploy (kubernetes OR nomad)",
+	"-(a OR b) AND c",
+	`"getting started" OR title:"quick start"`,
+	`unbalanced "quote (group`,
+	"((a b) OR (c -d)) e",
+	"a ) b (",
+	"OR AND ) ( -",
+	`repo:acme/api -repo:acme/web path:docs/** -path:*.md tag:CI -tag:old title:deploy`,
+	`repo: path:"" tag:"a b" -"phrase" -`,
+	"a\tOR\nb (c)",
+}
+
+func FuzzParseSearchQuery(f *testing.F) {
+	for _, seed := range querySeeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		q := parseSearchQuery(input)
+
+		var check func(e *queryExpr)
+
+		check = func(e *queryExpr) {
+			switch e.op {
+			case exprTerm:
+				if e.term.op != opNone {
+					t.Fatalf("operator token %d parsed as a term of %q", e.term.op, input)
+				}
+			case exprAnd, exprOr:
+				if len(e.exprs) < 2 {
+					t.Fatalf("node with %d sub-expressions parsed from %q", len(e.exprs), input)
+				}
+
+				for _, sub := range e.exprs {
+					if sub == nil {
+						t.Fatalf("nil sub-expression parsed from %q", input)
+					}
+
+					check(sub)
+				}
+			}
+		}
+
+		if q.expr != nil {
+			check(q.expr)
+		}
+	})
+}
+
+func FuzzBuildSearchQuery(f *testing.F) {
+	for _, seed := range querySeeds {
+		f.Add(seed, "", false)
+		f.Add(seed, "go", true)
+	}
+
+	f.Fuzz(func(t *testing.T, input, lang string, codeOnly bool) {
+		opts := core.SearchOpts{Lang: lang, CodeOnly: codeOnly}
+
+		q := buildSearchQuery(input, opts)
+		if q == nil {
+			t.Fatalf("no query built for %q", input)
+		}
+
+		if _, err := json.Marshal(q); err != nil {
+			t.Fatalf("query built for %q does not marshal: %v", input, err)
+		}
+	})
+}
+
+func FuzzBuildSearchDSL(f *testing.F) {
+	for _, seed := range querySeeds {
+		f.Add(seed, "", false)
+		f.Add(seed, "go", true)
+	}
+
+	f.Fuzz(func(t *testing.T, input, lang string, codeOnly bool) {
+		dsl := buildSearchDSL(input, core.SearchOpts{Lang: lang, CodeOnly: codeOnly})
+		if len(dsl) != 1 {
+			t.Fatalf("query built for %q has %d clauses, want one", input, len(dsl))
+		}
+
+		if _, err := json.Marshal(dsl); err != nil {
+			t.Fatalf("query DSL built for %q does not marshal: %v", input, err)
+		}
+	})
+}
@@ -0,0 +1,148 @@
+package search
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	term := func(text string) *queryExpr { return &queryExpr{op: exprTerm, term: queryTerm{text: text}} }
+	not := func(e *queryExpr) *queryExpr { e.negated = true; return e }
+	and := func(exprs ...*queryExpr) *queryExpr { return &queryExpr{op: exprAnd, exprs: exprs} }
+	or := func(exprs ...*queryExpr) *queryExpr { return &queryExpr{op: exprOr, exprs: exprs} }
+
+	tests := []struct {
+		name  string
+		input string
+		want  searchQuery
+	}{
+		{
+			name:  "empty",
+			input: "",
+			want:  searchQuery{},
+		},
+		{
+			name:  "terms",
+			input: "deploy nomad",
+			want:  searchQuery{expr: and(term("deploy"), term("nomad"))},
+		},
+		{
+			name:  "AND binds tighter than OR",
+			input: "deploy -kubernetes OR nomad",
+			want:  searchQuery{expr: or(and(term("deploy"), not(term("kubernetes"))), term("nomad"))},
+		},
+		{
+			name:  "explicit AND",
+			input: "deploy AND nomad OR consul",
+			want:  searchQuery{expr: or(and(term("deploy"), term("nomad")), term("consul"))},
+		},
+		{
+			name:  "groups",
+			input: "deploy (kubernetes OR nomad) -(draft OR wip)",
+			want: searchQuery{expr: and(
+				term("deploy"),
+				or(term("kubernetes"), term("nomad")),
+				not(or(term("draft"), term("wip"))),
+			)},
+		},
+		{
+			name:  "single term group",
+			input: "-(draft) -(-wip)",
+			want:  searchQuery{expr: and(not(term("draft")), term("wip"))},
+		},
+		{
+			name:  "stray operators and unclosed group",
+			input: "OR deploy OR OR AND (nomad OR",
+			want:  searchQuery{expr: or(term("deploy"), term("nomad"))},
+		},
+		{
+			name:  "unmatched closing parentheses",
+			input: "deploy ) nomad (a OR b)) c",
+			want:  searchQuery{expr: and(term("deploy"), term(")"), term("nomad"), or(term("a"), term("b")), term(")"), term("c"))},
+		},
+		{
+			name:  "empty group",
+			input: "deploy ()",
+			want:  searchQuery{expr: term("deploy")},
+		},
+		{
+			name:  "qualifiers",
+			input: "deploy OR repo:/acme/api/ -repo:acme/old path:/docs/** -path:drafts/**",
+			want: searchQuery{
+				expr:          term("deploy"),
+				repos:         []string{"acme/api"},
+				paths:         []string{"docs/**"},
+				excludedRepos: []string{"acme/old"},
+				excludedPaths: []string{"drafts/**"},
+			},
+		},
+		{
+			name:  "tag qualifiers",
+			input: `tag:CI tag:"getting started" -tag:legacy`,
+			want: searchQuery{
+				tags:         []string{"ci", "getting started"},
+				excludedTags: []string{"legacy"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseSearchQuery(tt.input))
+		})
+	}
+}
+
+func TestQueryParser_UnmatchedClosers(t *testing.T) {
+	closer := queryTerm{op: opClose}
+	a, b, c := queryTerm{text: "a"}, queryTerm{text: "b"}, queryTerm{text: "c"}
+
+	// A closing parenthesis at the top level does not end the query: the terms after it
+	// are still parsed.
+	p := queryParser{tokens: dropUnmatchedClosers([]queryTerm{a, closer, b, {op: opOpen}, c, closer, closer})}
+	e := p.parseOr()
+
+	assert.Equal(t, len(p.tokens), p.pos, "all tokens are parsed")
+	assert.Equal(t, &queryExpr{op: exprAnd, exprs: []*queryExpr{
+		{op: exprTerm, term: a},
+		{op: exprTerm, term: b},
+		{op: exprTerm, term: c},
+	}}, e)
+}
+
+func TestParseSearchQuery_DeepGroups(t *testing.T) {
+	// Groups nested deeper than maxQueryDepth are not parsed as groups: their "(" is kept
+	// as literal text of the term that follows.
+	input := strings.Repeat("(", maxQueryDepth+2) + "a b" + strings.Repeat(")", maxQueryDepth)
+
+	groups := 0
+
+	for _, tok := range splitQueryTerms(input) {
+		if tok.op == opOpen {
+			groups++
+		}
+	}
+
+	assert.Equal(t, maxQueryDepth, groups)
+	assert.Equal(t, &queryExpr{op: exprAnd, exprs: []*queryExpr{
+		{op: exprTerm, term: queryTerm{text: "((a"}},
+		{op: exprTerm, term: queryTerm{text: "b"}},
+	}}, parseSearchQuery(input).expr)
+
+	// A query of many thousands of parentheses parses without exhausting the stack.
+	q := parseSearchQuery(strings.Repeat("(a OR ", 100_000))
+	assert.NotNil(t, q.expr)
+}
+
+func TestQueryExpr_Words(t *testing.T) {
+	words, ok := parseSearchQuery("list all pets").expr.words()
+	assert.True(t, ok)
+	assert.Equal(t, "list all pets", words)
+
+	for _, input := range []string{"pets", `list "all pets"`, "list -pets", "title:list pets", "list OR pets"} {
+		_, ok := parseSearchQuery(input).expr.words()
+		assert.False(t, ok, input)
+	}
+}
@@ -0,0 +1,188 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	index "github.com/blevesearch/bleve_index_api"
+)
+
+// minCorrectionLength is the minimum length of a query word to be corrected. Shorter
+// words are within a couple of edits of too many terms to guess which one was meant.
+const minCorrectionLength = 3
+
+// correctionFields are the fields whose term dictionaries corrections are taken from.
+var correctionFields = []string{fieldTitle, fieldContent}
+
+// termCandidate is an indexed term close to a query word.
+type termCandidate struct {
+	term     string
+	count    uint64
+	distance uint8
+}
+
+// better reports whether c is a more likely correction than o: it is fewer edits away, or
+// as many edits away and used in more documents.
+func (c termCandidate) better(o termCandidate) bool {
+	if c.distance != o.distance {
+		return c.distance < o.distance
+	}
+
+	if c.count != o.count {
+		return c.count > o.count
+	}
+
+	return c.term < o.term
+}
+
+// CorrectQuery returns query with each word missing from the title and content term
+// dictionaries replaced by the closest term found there, preferring the terms used in
+// the most documents among equally close ones. Words are looked up as the content
+// analyzer indexes them, so stopwords are kept and a correction may be a stemmed term.
+// Quoted phrases, filters such as "lang:go" and words with punctuation are kept as they
+// are. It returns an empty string when no word needs or has a correction.
+func (e *BleveEngine) CorrectQuery(_ context.Context, query string) (string, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	idx, err := e.index.Advanced()
+	if err != nil {
+		return "", fmt.Errorf("failed to access index: %w", err)
+	}
+
+	reader, err := idx.Reader()
+	if err != nil {
+		return "", fmt.Errorf("failed to open index reader: %w", err)
+	}
+
+	defer reader.Close()
+
+	fuzzy, ok := reader.(index.IndexReaderFuzzy)
+	if !ok {
+		return "", nil
+	}
+
+	m := e.index.Mapping()
+	analyzer := m.AnalyzerNamed(m.AnalyzerNameForPath(fieldContent))
+
+	words := strings.Fields(query)
+	corrected := false
+	inPhrase := false
+
+	for i, w := range words {
+		quoted := inPhrase || strings.HasPrefix(w, `"`)
+
+		if strings.Count(w, `"`)%2 == 1 {
+			inPhrase = !inPhrase
+		}
+
+		if quoted || !correctableWord(w) {
+			continue
+		}
+
+		term := strings.ToLower(w)
+
+		if analyzer != nil {
+			tokens := analyzer.Analyze([]byte(w))
+			if len(tokens) != 1 {
+				continue // a stopword, which is never indexed
+			}
+
+			term = string(tokens[0].Term)
+		}
+
+		closest, err := closestTerm(fuzzy, term)
+		if err != nil {
+			return "", err
+		}
+
+		if closest != "" && closest != term {
+			words[i] = closest
+			corrected = true
+		}
+	}
+
+	if !corrected {
+		return "", nil
+	}
+
+	return strings.Join(words, " "), nil
+}
+
+// correctableWord reports whether w is a query word that may be corrected: long enough,
+// made of letters and digits only, and not a number.
+func correctableWord(w string) bool {
+	if len(w) < minCorrectionLength {
+		return false
+	}
+
+	letters := false
+
+	for _, r := range w {
+		switch {
+		case unicode.IsLetter(r):
+			letters = true
+		case !unicode.IsDigit(r):
+			return false
+		}
+	}
+
+	return letters
+}
+
+// closestTerm returns term itself when it is indexed, or else the best indexed candidate
+// within the fuzzy matching distance used by searches for terms of its length, or an
+// empty string when there is none.
+func closestTerm(reader index.IndexReaderFuzzy, term string) (string, error) {
+	fuzziness := 1
+	if len(term) >= longTermThreshold {
+		fuzziness = 2
+	}
+
+	var best *termCandidate
+
+	for _, field := range correctionFields {
+		dict, automaton, err := reader.FieldDictFuzzyAutomaton(field, term, fuzziness, "")
+		if err != nil {
+			return "", fmt.Errorf("failed to read term dictionary: %w", err)
+		}
+
+		for {
+			entry, err := dict.Next()
+			if err != nil {
+				_ = dict.Close()
+				return "", fmt.Errorf("failed to read term dictionary: %w", err)
+			}
+
+			if entry == nil {
+				break
+			}
+
+			if entry.Term == term {
+				_ = dict.Close()
+				return term, nil
+			}
+
+			c := termCandidate{term: entry.Term, count: entry.Count, distance: uint8(fuzziness)} //nolint:gosec // fuzziness is 1 or 2
+			if automaton != nil {
+				_, c.distance = automaton.MatchAndDistance(entry.Term)
+			}
+
+			if best == nil || c.better(*best) {
+				best = &c
+			}
+		}
+
+		if err := dict.Close(); err != nil {
+			return "", fmt.Errorf("failed to close term dictionary: %w", err)
+		}
+	}
+
+	if best == nil {
+		return "", nil
+	}
+
+	return best.term, nil
+}
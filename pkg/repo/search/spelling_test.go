@@ -0,0 +1,74 @@
+package search
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBleveEngine_CorrectQuery(t *testing.T) {
+	engine, err := NewBleve(filepath.Join(t.TempDir(), "test.bleve"), BleveConfig{})
+	require.NoError(t, err)
+
+	defer engine.Close()
+
+	docs := []struct {
+		text string
+		doc  core.Document
+	}{
+		{
+			doc:  core.Document{ID: "owner/repo/kubernetes.md", Repo: "owner/repo", Path: "kubernetes.md", Title: "Kubernetes"},
+			text: "Deploy the service to a Kubernetes cluster",
+		},
+		{
+			doc:  core.Document{ID: "owner/repo/deploy.md", Repo: "owner/repo", Path: "deploy.md", Title: "Deploy"},
+			text: "Deploy with the release pipeline",
+		},
+		{
+			doc:  core.Document{ID: "owner/repo/display.md", Repo: "owner/repo", Path: "display.md", Title: "Display"},
+			text: "Display settings",
+		},
+	}
+
+	for _, d := range docs {
+		require.NoError(t, engine.Index(t.Context(), d.doc, d.text, nil, nil))
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "misspelled word", query: "kubernets", want: "kubernetes"},
+		{name: "one misspelled word of several", query: "deploi to kubernets", want: "deploy to kubernetes"},
+		{name: "closest term wins", query: "deploi", want: "deploy"},
+		{name: "stopwords kept", query: "the kuberntes cluster", want: "the kubernetes cluster"},
+		{name: "indexed words", query: "deploy kubernetes", want: ""},
+		{name: "case insensitive", query: "Kubernetes", want: ""},
+		{name: "no close term", query: "xylophone", want: ""},
+		{name: "short word", query: "kb", want: ""},
+		{name: "quoted phrase kept", query: `"kubernets cluster"`, want: ""},
+		{name: "filter kept", query: "lang:goo kubernets", want: "lang:goo kubernetes"},
+		{name: "punctuation kept", query: "kubernets.yaml", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := engine.CorrectQuery(t.Context(), tt.query)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCorrectableWord(t *testing.T) {
+	assert.True(t, correctableWord("deploy"))
+	assert.True(t, correctableWord("http2"))
+	assert.False(t, correctableWord("go"))
+	assert.False(t, correctableWord("2024"))
+	assert.False(t, correctableWord("lang:go"))
+	assert.False(t, correctableWord("v1.2"))
+}
@@ -0,0 +1,90 @@
+package search
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+// Fields holding the section headings of a document for search-as-you-type suggestions.
+// The anchors are stored in the order of the headings and are not searchable.
+const (
+	fieldHeadings       = "headings"
+	fieldHeadingAnchors = "heading_anchors"
+)
+
+// suggestQuery is a search-as-you-type prefix split into the words typed so far, which
+// must match whole words, and the last word, which may be the start of one.
+type suggestQuery struct {
+	last  string
+	words []string
+}
+
+// parseSuggestQuery splits prefix into lowercase words. The query has no words when the
+// prefix has no letters or digits.
+func parseSuggestQuery(prefix string) suggestQuery {
+	words := suggestWords(prefix)
+	if len(words) == 0 {
+		return suggestQuery{}
+	}
+
+	return suggestQuery{words: words[:len(words)-1], last: words[len(words)-1]}
+}
+
+// suggestWords returns the lowercase words of s.
+func suggestWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// matches reports whether text contains every whole word of the query and a word
+// starting with its last word.
+func (q *suggestQuery) matches(text string) bool {
+	words := suggestWords(text)
+
+	for _, w := range q.words {
+		if !slices.Contains(words, w) {
+			return false
+		}
+	}
+
+	return slices.ContainsFunc(words, func(w string) bool { return strings.HasPrefix(w, q.last) })
+}
+
+// suggestion returns the suggestion of a document found for the query: its title when
+// the title matches, or else its first matching section heading. Documents the engine
+// matched through stemming alone suggest their title.
+func (q *suggestQuery) suggestion(repo, path, title string, headings, anchors []string) core.SearchSuggestion {
+	s := core.SearchSuggestion{Repo: repo, Path: path, Title: title}
+
+	if q.matches(title) {
+		return s
+	}
+
+	for i, h := range headings {
+		if q.matches(h) {
+			s.Heading = h
+
+			if i < len(anchors) {
+				s.Anchor = anchors[i]
+			}
+
+			break
+		}
+	}
+
+	return s
+}
+
+// splitHeadings returns the texts and anchors of headings, indexed as parallel arrays.
+func splitHeadings(headings []core.Heading) (texts, anchors []string) {
+	for _, h := range headings {
+		texts = append(texts, h.Text)
+		anchors = append(anchors, h.ID)
+	}
+
+	return texts, anchors
+}
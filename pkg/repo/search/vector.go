@@ -0,0 +1,318 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	vectorMetaBucket    = []byte("meta")
+	vectorEntriesBucket = []byte("vectors")
+	vectorModelKey      = []byte("model")
+)
+
+// vectorEntry is an embedding held in memory, with the norm of its vector.
+type vectorEntry struct {
+	core.VectorEntry
+	norm float64
+}
+
+// VectorIndex implements core.VectorIndex. Embeddings are persisted in a bbolt database
+// and searched exhaustively in memory, which is fast enough for the document counts of a
+// documentation portal.
+type VectorIndex struct {
+	db      *bolt.DB
+	entries map[string]vectorEntry
+	mu      sync.RWMutex
+}
+
+// NewVectorIndex opens the vector index at path, or creates it if it does not exist.
+// model identifies the embedding model; the embeddings of an index built with another
+// model are discarded, since vectors of different models cannot be compared.
+func NewVectorIndex(path, model string) (*VectorIndex, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open vector index: %w", err)
+	}
+
+	idx := &VectorIndex{db: db, entries: make(map[string]vectorEntry)}
+
+	if err := idx.load(model); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return idx, nil
+}
+
+// load reads the embeddings into memory, discarding them first if they were built with
+// another model.
+func (v *VectorIndex) load(model string) error {
+	return v.db.Update(func(tx *bolt.Tx) error {
+		meta, err := tx.CreateBucketIfNotExists(vectorMetaBucket)
+		if err != nil {
+			return fmt.Errorf("failed to create meta bucket: %w", err)
+		}
+
+		if prev := string(meta.Get(vectorModelKey)); prev != model {
+			if tx.Bucket(vectorEntriesBucket) != nil {
+				slog.Info("embedding model changed, discarding vector index", "previous", prev, "model", model)
+
+				if err := tx.DeleteBucket(vectorEntriesBucket); err != nil {
+					return fmt.Errorf("failed to discard vector index: %w", err)
+				}
+			}
+
+			if err := meta.Put(vectorModelKey, []byte(model)); err != nil {
+				return fmt.Errorf("failed to store embedding model: %w", err)
+			}
+		}
+
+		entries, err := tx.CreateBucketIfNotExists(vectorEntriesBucket)
+		if err != nil {
+			return fmt.Errorf("failed to create vectors bucket: %w", err)
+		}
+
+		return entries.ForEach(func(k, data []byte) error {
+			var e core.VectorEntry
+			if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&e); err != nil {
+				return fmt.Errorf("failed to decode embedding %s: %w", k, err)
+			}
+
+			v.entries[e.ID] = vectorEntry{VectorEntry: e, norm: norm(e.Vector)}
+
+			return nil
+		})
+	})
+}
+
+// Close closes the underlying database.
+func (v *VectorIndex) Close() error {
+	return v.db.Close()
+}
+
+// Upsert stores the embedding of a document, replacing any previous one.
+func (v *VectorIndex) Upsert(_ context.Context, entry core.VectorEntry) error { //nolint:gocritic // VectorEntry is passed by value like the core.VectorIndex interface
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(entry); err != nil {
+		return fmt.Errorf("failed to encode embedding: %w", err)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	err := v.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(vectorEntriesBucket).Put([]byte(entry.ID), buf.Bytes())
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store embedding: %w", err)
+	}
+
+	v.entries[entry.ID] = vectorEntry{VectorEntry: entry, norm: norm(entry.Vector)}
+
+	return nil
+}
+
+// Remove deletes the embedding of a document. Removing a document without an embedding
+// is not an error.
+func (v *VectorIndex) Remove(_ context.Context, docID string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	err := v.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(vectorEntriesBucket).Delete([]byte(docID))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete embedding: %w", err)
+	}
+
+	delete(v.entries, docID)
+
+	return nil
+}
+
+// Checksum returns the checksum of the text embedded for a document, or an empty string
+// if the document has no embedding.
+func (v *VectorIndex) Checksum(_ context.Context, docID string) (string, error) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	return v.entries[docID].Checksum, nil
+}
+
+// Nearest returns the documents passing the filters of opts and the repo:, path: and tag:
+// qualifiers of query, ranked by the cosine similarity of their embedding to vector.
+// Language filters and code-only searches keep the documents with code in the language, or
+// with any code. Documents with no positive similarity, or whose embedding has another
+// length, are not returned.
+func (v *VectorIndex) Nearest(_ context.Context, query string, vector []float32, opts core.SearchOpts) (*core.SearchResults, error) { //nolint:gocritic // SearchOpts is passed by value like the core.VectorIndex interface
+	if opts.Limit <= 0 {
+		opts.Limit = 20
+	}
+
+	qNorm := norm(vector)
+	if qNorm == 0 {
+		return &core.SearchResults{}, nil
+	}
+
+	sq := parseSearchQuery(query)
+
+	v.mu.RLock()
+
+	hits := make([]core.SearchResult, 0, len(v.entries))
+
+	for _, e := range v.entries {
+		if len(e.Vector) != len(vector) || e.norm == 0 || !matchesVectorFilters(&e.VectorEntry, &sq, &opts) {
+			continue
+		}
+
+		var dot float64
+		for i, x := range vector {
+			dot += float64(x) * float64(e.Vector[i])
+		}
+
+		score := dot / (qNorm * e.norm)
+		if score <= 0 {
+			continue
+		}
+
+		hits = append(hits, core.SearchResult{ID: e.ID, Repo: e.Repo, Path: e.Path, Title: e.Title, ContentType: e.ContentType, Score: score})
+	}
+
+	v.mu.RUnlock()
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+
+		return hits[i].ID < hits[j].ID
+	})
+
+	total := len(hits)
+	start := min(max(opts.Offset, 0), total)
+	end := min(start+opts.Limit, total)
+
+	return &core.SearchResults{Hits: hits[start:end], Total: uint64(total)}, nil
+}
+
+// matchesVectorFilters reports whether an embedded document passes the filters of opts and
+// the qualifiers of a query, as the search engines apply them.
+func matchesVectorFilters(e *core.VectorEntry, sq *searchQuery, opts *core.SearchOpts) bool {
+	switch {
+	case !inRepos(e.Repo, opts.Repos), opts.Repo != "" && e.Repo != opts.Repo:
+		return false
+	case opts.CodeOnly && !e.Code, opts.Lang != "" && !slices.Contains(e.Langs, strings.ToLower(opts.Lang)):
+		return false
+	case len(opts.ContentTypes) > 0 && !slices.ContainsFunc(opts.ContentTypes, func(ct core.ContentType) bool {
+		return indexedContentType(ct) == indexedContentType(e.ContentType)
+	}):
+		return false
+	case len(sq.repos) > 0 && !inRepos(e.Repo, sq.repos), len(sq.excludedRepos) > 0 && inRepos(e.Repo, sq.excludedRepos):
+		return false
+	case len(sq.paths) > 0 && !matchesPaths(e.Path, sq.paths), matchesPaths(e.Path, sq.excludedPaths):
+		return false
+	}
+
+	for _, tag := range sq.tags {
+		if !slices.Contains(e.Tags, tag) {
+			return false
+		}
+	}
+
+	for _, tag := range sq.excludedTags {
+		if slices.Contains(e.Tags, tag) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchesPaths reports whether a document path matches any of the path: patterns, like
+// buildPathQuery: a wildcard pattern, or else the document path or one of its directories.
+func matchesPaths(docPath string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if wildcard, ok := pathPattern(pattern); ok {
+			if matchWildcard(wildcard, docPath) {
+				return true
+			}
+
+			continue
+		}
+
+		if docPath == pattern || strings.HasPrefix(docPath, strings.TrimSuffix(pattern, "/")+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchWildcard reports whether s matches a wildcard pattern in which "*" matches any run
+// of characters, "/" included, and "?" any single character, as in search engine wildcard
+// queries.
+func matchWildcard(pattern, s string) bool {
+	px, sx := 0, 0
+	star, next := -1, 0
+
+	for sx < len(s) {
+		switch {
+		case px < len(pattern) && (pattern[px] == '?' || pattern[px] == s[sx]):
+			px++
+			sx++
+		case px < len(pattern) && pattern[px] == '*':
+			star, next = px, sx
+			px++
+		case star >= 0:
+			next++
+			px, sx = star+1, next
+		default:
+			return false
+		}
+	}
+
+	for px < len(pattern) && pattern[px] == '*' {
+		px++
+	}
+
+	return px == len(pattern)
+}
+
+// inRepos reports whether repo is one of the given owners or repositories, or one of their
+// monorepo sub-projects. An empty list matches every repository.
+func inRepos(repo string, repos []string) bool {
+	if len(repos) == 0 {
+		return true
+	}
+
+	for _, r := range repos {
+		if repo == r || strings.HasPrefix(repo, r+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// norm returns the Euclidean norm of a vector.
+func norm(vector []float32) float64 {
+	var sum float64
+	for _, x := range vector {
+		sum += float64(x) * float64(x)
+	}
+
+	return math.Sqrt(sum)
+}
@@ -0,0 +1,157 @@
+package search
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVectorIndex_Nearest(t *testing.T) {
+	idx, err := NewVectorIndex(filepath.Join(t.TempDir(), "search.vectors"), "openai/small/3")
+	require.NoError(t, err)
+
+	defer func() { _ = idx.Close() }()
+
+	entries := []core.VectorEntry{
+		{ID: "acme/ops/deploy.md", Repo: "acme/ops", Path: "deploy.md", Title: "Deploying", Vector: []float32{1, 0, 0}},
+		{ID: "acme/ops/rollback.md", Repo: "acme/ops", Path: "rollback.md", Title: "Rolling back", Vector: []float32{0.8, 0.6, 0}},
+		{ID: "acme/mono/api/auth.md", Repo: "acme/mono/api", Path: "auth.md", Title: "Auth", Vector: []float32{0.6, 0.8, 0}},
+		{ID: "other/wiki/lunch.md", Repo: "other/wiki", Path: "lunch.md", Title: "Lunch", Vector: []float32{0, 0, 1}},
+		{ID: "other/wiki/old.md", Repo: "other/wiki", Path: "old.md", Title: "Old model", Vector: []float32{1, 0}},
+	}
+
+	for _, e := range entries {
+		require.NoError(t, idx.Upsert(t.Context(), e))
+	}
+
+	results, err := idx.Nearest(t.Context(), "", []float32{2, 0, 0}, core.SearchOpts{})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), results.Total, "orthogonal documents and vectors of another length are not returned")
+	require.Len(t, results.Hits, 3)
+	assert.Equal(t, "acme/ops/deploy.md", results.Hits[0].ID)
+	assert.InDelta(t, 1.0, results.Hits[0].Score, 1e-9)
+	assert.Equal(t, "Deploying", results.Hits[0].Title)
+	assert.Equal(t, "acme/ops/rollback.md", results.Hits[1].ID)
+	assert.InDelta(t, 0.8, results.Hits[1].Score, 1e-6)
+
+	results, err = idx.Nearest(t.Context(), "", []float32{1, 0, 0}, core.SearchOpts{Limit: 1, Offset: 1})
+	require.NoError(t, err)
+	require.Len(t, results.Hits, 1)
+	assert.Equal(t, "acme/ops/rollback.md", results.Hits[0].ID)
+
+	results, err = idx.Nearest(t.Context(), "", []float32{1, 0, 0}, core.SearchOpts{Repos: []string{"acme/mono"}})
+	require.NoError(t, err)
+	require.Len(t, results.Hits, 1)
+	assert.Equal(t, "acme/mono/api/auth.md", results.Hits[0].ID, "repository filters include monorepo projects")
+
+	results, err = idx.Nearest(t.Context(), "", []float32{1, 0, 0}, core.SearchOpts{Repo: "acme/ops", Repos: []string{"acme"}})
+	require.NoError(t, err)
+	require.Len(t, results.Hits, 2)
+	assert.Equal(t, "acme/ops/deploy.md", results.Hits[0].ID)
+	assert.Equal(t, "acme/ops/rollback.md", results.Hits[1].ID)
+
+	results, err = idx.Nearest(t.Context(), "", []float32{0, 0, 0}, core.SearchOpts{})
+	require.NoError(t, err)
+	assert.Empty(t, results.Hits)
+}
+
+func TestVectorIndex_NearestFilters(t *testing.T) {
+	idx, err := NewVectorIndex(filepath.Join(t.TempDir(), "search.vectors"), "openai/small/2")
+	require.NoError(t, err)
+
+	defer func() { _ = idx.Close() }()
+
+	entries := []core.VectorEntry{
+		{ID: "acme/ops/deploy.md", Repo: "acme/ops", Path: "deploy.md", Tags: []string{"ci"}, Langs: []string{"go"}, Code: true, Vector: []float32{1, 0}},
+		{ID: "acme/ops/guides/rollback.md", Repo: "acme/ops", Path: "guides/rollback.md", Tags: []string{"ci", "legacy"}, Vector: []float32{0.9, 0.1}},
+		{ID: "acme/ops/script.md", Repo: "acme/ops", Path: "script.md", Code: true, Vector: []float32{0.8, 0.2}},
+		{ID: "acme/api/openapi.yaml", Repo: "acme/api", Path: "openapi.yaml", ContentType: core.ContentTypeOpenAPI, Vector: []float32{0.7, 0.3}},
+	}
+
+	for _, e := range entries {
+		require.NoError(t, idx.Upsert(t.Context(), e))
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+		opts  core.SearchOpts
+	}{
+		{name: "no filters", query: "deploy", want: []string{"acme/ops/deploy.md", "acme/ops/guides/rollback.md", "acme/ops/script.md", "acme/api/openapi.yaml"}},
+		{name: "repo qualifier", query: "deploy repo:acme/api", want: []string{"acme/api/openapi.yaml"}},
+		{name: "excluded repo", query: "-repo:acme/ops deploy", want: []string{"acme/api/openapi.yaml"}},
+		{name: "path directory", query: "path:guides", want: []string{"acme/ops/guides/rollback.md"}},
+		{name: "path wildcard", query: "path:*.yaml", want: []string{"acme/api/openapi.yaml"}},
+		{name: "excluded path", query: "-path:guides/** -path:script.md repo:acme/ops", want: []string{"acme/ops/deploy.md"}},
+		{name: "tags", query: "tag:CI tag:legacy", want: []string{"acme/ops/guides/rollback.md"}},
+		{name: "excluded tag", query: "tag:ci -tag:legacy", want: []string{"acme/ops/deploy.md"}},
+		{name: "language", opts: core.SearchOpts{Lang: "Go"}, want: []string{"acme/ops/deploy.md"}},
+		{name: "code only", opts: core.SearchOpts{CodeOnly: true}, want: []string{"acme/ops/deploy.md", "acme/ops/script.md"}},
+		{name: "content types", opts: core.SearchOpts{ContentTypes: []core.ContentType{core.ContentTypeMarkdown}}, want: []string{"acme/ops/deploy.md", "acme/ops/guides/rollback.md", "acme/ops/script.md"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := idx.Nearest(t.Context(), tt.query, []float32{1, 0}, tt.opts)
+			require.NoError(t, err)
+
+			ids := make([]string, 0, len(results.Hits))
+			for _, hit := range results.Hits {
+				ids = append(ids, hit.ID)
+			}
+
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
+
+func TestMatchWildcard(t *testing.T) {
+	assert.True(t, matchWildcard("docs/*", "docs/guides/intro.md"), "* matches across directories")
+	assert.True(t, matchWildcard("*.md", "intro.md"))
+	assert.True(t, matchWildcard("v?/api.md", "v2/api.md"))
+	assert.False(t, matchWildcard("*.md", "openapi.yaml"))
+	assert.False(t, matchWildcard("docs/*", "guides/docs/intro.md"))
+}
+
+func TestVectorIndex_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "search.vectors")
+
+	idx, err := NewVectorIndex(path, "openai/small/2")
+	require.NoError(t, err)
+
+	require.NoError(t, idx.Upsert(t.Context(), core.VectorEntry{ID: "acme/ops/a.md", Repo: "acme/ops", Path: "a.md", Checksum: "sum-a", Vector: []float32{1, 0}}))
+	require.NoError(t, idx.Upsert(t.Context(), core.VectorEntry{ID: "acme/ops/b.md", Repo: "acme/ops", Path: "b.md", Checksum: "sum-b", Vector: []float32{0, 1}}))
+	require.NoError(t, idx.Remove(t.Context(), "acme/ops/b.md"))
+	require.NoError(t, idx.Remove(t.Context(), "acme/ops/missing.md"))
+	require.NoError(t, idx.Close())
+
+	idx, err = NewVectorIndex(path, "openai/small/2")
+	require.NoError(t, err)
+
+	sum, err := idx.Checksum(t.Context(), "acme/ops/a.md")
+	require.NoError(t, err)
+	assert.Equal(t, "sum-a", sum)
+
+	sum, err = idx.Checksum(t.Context(), "acme/ops/b.md")
+	require.NoError(t, err)
+	assert.Empty(t, sum)
+
+	results, err := idx.Nearest(t.Context(), "", []float32{1, 0}, core.SearchOpts{})
+	require.NoError(t, err)
+	require.Len(t, results.Hits, 1)
+	assert.Equal(t, "acme/ops/a.md", results.Hits[0].ID)
+	require.NoError(t, idx.Close())
+
+	idx, err = NewVectorIndex(path, "ollama/nomic-embed-text/0")
+	require.NoError(t, err)
+
+	defer func() { _ = idx.Close() }()
+
+	sum, err = idx.Checksum(t.Context(), "acme/ops/a.md")
+	require.NoError(t, err)
+	assert.Empty(t, sum, "embeddings of another model are discarded")
+}
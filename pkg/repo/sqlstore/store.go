@@ -0,0 +1,732 @@
+// Package sqlstore provides document storage backed by a single SQLite database file.
+// It implements the same interface as pkg/repo/docstore, keeping documents, their
+// metadata and assets in one file that is simple to back up and move between hosts.
+//
+// Tables:
+//
+//	repos     – repository name and time of the last update
+//	documents – document content and metadata, keyed by repository and path
+//	assets    – binary asset bodies, keyed by repository and path
+//	settings  – redirects and managed API keys, stored as JSON
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	stdpath "path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" database/sql driver
+)
+
+const (
+	redirectsKey  = "redirects"
+	apiKeysKey    = "api_keys"
+	highlightsKey = "highlights"
+	bannerKey     = "banner"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS repos (
+	name         TEXT PRIMARY KEY,
+	last_updated TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS documents (
+	repo         TEXT NOT NULL,
+	path         TEXT NOT NULL,
+	content      TEXT NOT NULL,
+	title        TEXT NOT NULL,
+	summary      TEXT NOT NULL,
+	commit_sha   TEXT NOT NULL,
+	content_type TEXT NOT NULL,
+	home         INTEGER NOT NULL,
+	provenance   TEXT,
+	anchors      TEXT,
+	doc_text     TEXT,
+	images       TEXT,
+	tags         TEXT,
+	doc_order    INTEGER NOT NULL DEFAULT 0,
+	draft        INTEGER NOT NULL DEFAULT 0,
+	updated_at   TEXT NOT NULL,
+	PRIMARY KEY (repo, path)
+);
+
+CREATE TABLE IF NOT EXISTS assets (
+	repo TEXT NOT NULL,
+	path TEXT NOT NULL,
+	data BLOB NOT NULL,
+	PRIMARY KEY (repo, path)
+);
+
+CREATE TABLE IF NOT EXISTS settings (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+`
+
+// Store implements SQLite-backed document storage.
+type Store struct {
+	db *sql.DB
+}
+
+// New opens the SQLite database at path, creating the file, its parent directory and
+// the tables when they do not exist yet.
+func New(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	dsn := "file:" + path + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)"
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// SQLite allows a single writer; serializing access through one connection avoids
+	// SQLITE_BUSY errors between concurrent writes.
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(schema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to create database schema: %w", err)
+	}
+
+	for _, column := range [][2]string{
+		{"anchors", "TEXT"},
+		{"doc_text", "TEXT"},
+		{"tags", "TEXT"},
+		{"doc_order", "INTEGER NOT NULL DEFAULT 0"},
+		{"draft", "INTEGER NOT NULL DEFAULT 0"},
+		{"images", "TEXT"},
+	} {
+		if err := addColumn(db, "documents", column[0], column[1]); err != nil {
+			_ = db.Close()
+			return nil, err
+		}
+	}
+
+	return &Store{db: db}, nil
+}
+
+// addColumn adds a column to a table of a database created before the column was part of
+// the schema.
+func addColumn(db *sql.DB, table, column, decl string) error {
+	var exists bool
+
+	err := db.QueryRow(`SELECT COUNT(*) > 0 FROM pragma_table_info(?) WHERE name = ?`, table, column).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to inspect table %s: %w", table, err)
+	}
+
+	if exists {
+		return nil
+	}
+
+	if _, err := db.Exec("ALTER TABLE " + table + " ADD COLUMN " + column + " " + decl); err != nil {
+		return fmt.Errorf("failed to add column %s.%s: %w", table, column, err)
+	}
+
+	return nil
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// validateRelPath rejects relative paths that are empty, absolute, or that escape
+// their prefix via directory traversal (e.g. "../docs/x"), mirroring the validation
+// of the filesystem backend so that both present the same errors to callers.
+func validateRelPath(relPath string) error {
+	if relPath == "" {
+		return fmt.Errorf("%w: path must not be empty", core.ErrInvalidPath)
+	}
+
+	if stdpath.IsAbs(relPath) {
+		return fmt.Errorf("%w: path must not be absolute", core.ErrInvalidPath)
+	}
+
+	clean := stdpath.Clean(relPath)
+
+	if clean == "." || clean == ".." {
+		return fmt.Errorf("%w: path resolves to directory root", core.ErrInvalidPath)
+	}
+
+	if strings.HasPrefix(clean, "../") {
+		return fmt.Errorf("%w: path attempts directory traversal", core.ErrInvalidPath)
+	}
+
+	return nil
+}
+
+// validateKey validates a repository name and a path within it.
+func validateKey(repo, path string) error {
+	if err := validateRelPath(repo); err != nil {
+		return err
+	}
+
+	return validateRelPath(path)
+}
+
+// formatTime encodes t for storage; RFC3339Nano keeps the sub-second precision.
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// parseTime decodes a time stored by formatTime, returning the zero time if it is malformed.
+func parseTime(value string) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return t
+}
+
+// contentType returns the stored content type, defaulting to markdown when empty.
+func contentType(value string) core.ContentType {
+	if value == "" {
+		return core.ContentTypeMarkdown
+	}
+
+	return core.ContentType(value)
+}
+
+// unmarshalTags decodes the tags column, which is NULL for documents without tags.
+func unmarshalTags(value sql.NullString) ([]string, error) {
+	if !value.Valid {
+		return nil, nil
+	}
+
+	var tags []string
+	if err := json.Unmarshal([]byte(value.String), &tags); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// Save persists a document and updates the repository's last update time in a single
+// transaction.
+func (s *Store) Save(ctx context.Context, doc core.Document) error { //nolint:gocritic // Document is passed by value for immutability
+	if err := validateKey(doc.Repo, doc.Path); err != nil {
+		return err
+	}
+
+	var provenance sql.NullString
+
+	if doc.Provenance != nil {
+		data, err := json.Marshal(doc.Provenance)
+		if err != nil {
+			return fmt.Errorf("failed to marshal provenance: %w", err)
+		}
+
+		provenance = sql.NullString{String: string(data), Valid: true}
+	}
+
+	var anchors sql.NullString
+
+	if doc.Anchors != nil {
+		data, err := json.Marshal(doc.Anchors)
+		if err != nil {
+			return fmt.Errorf("failed to marshal anchors: %w", err)
+		}
+
+		anchors = sql.NullString{String: string(data), Valid: true}
+	}
+
+	var text sql.NullString
+
+	if doc.Text != nil {
+		data, err := json.Marshal(doc.Text)
+		if err != nil {
+			return fmt.Errorf("failed to marshal document text: %w", err)
+		}
+
+		text = sql.NullString{String: string(data), Valid: true}
+	}
+
+	var images sql.NullString
+
+	if len(doc.Images) > 0 {
+		data, err := json.Marshal(doc.Images)
+		if err != nil {
+			return fmt.Errorf("failed to marshal images: %w", err)
+		}
+
+		images = sql.NullString{String: string(data), Valid: true}
+	}
+
+	var tags sql.NullString
+
+	if len(doc.Tags) > 0 {
+		data, err := json.Marshal(doc.Tags)
+		if err != nil {
+			return fmt.Errorf("failed to marshal tags: %w", err)
+		}
+
+		tags = sql.NullString{String: string(data), Valid: true}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO documents (repo, path, content, title, summary, commit_sha, content_type, home, provenance, anchors, doc_text,
+			images, tags, doc_order, draft, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (repo, path) DO UPDATE SET
+			content = excluded.content, title = excluded.title, summary = excluded.summary,
+			commit_sha = excluded.commit_sha, content_type = excluded.content_type, home = excluded.home,
+			provenance = excluded.provenance, anchors = excluded.anchors, doc_text = excluded.doc_text,
+			images = excluded.images, tags = excluded.tags, doc_order = excluded.doc_order, draft = excluded.draft,
+			updated_at = excluded.updated_at`,
+		doc.Repo, doc.Path, doc.Content, doc.Title, doc.Summary, doc.CommitSHA, string(doc.ContentType),
+		doc.Home, provenance, anchors, text, images, tags, doc.Order, doc.Draft, formatTime(doc.UpdatedAt))
+	if err != nil {
+		return fmt.Errorf("failed to write document: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO repos (name, last_updated) VALUES (?, ?)
+		ON CONFLICT (name) DO UPDATE SET last_updated = excluded.last_updated`,
+		doc.Repo, formatTime(doc.UpdatedAt))
+	if err != nil {
+		return fmt.Errorf("failed to update repo metadata: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit document: %w", err)
+	}
+
+	return nil
+}
+
+// Get retrieves a document by its repository and path.
+func (s *Store) Get(ctx context.Context, repo, path string) (core.Document, error) {
+	if err := validateKey(repo, path); err != nil {
+		return core.Document{}, err
+	}
+
+	var (
+		ct, updatedAt string
+		provenance    sql.NullString
+		anchors       sql.NullString
+		text          sql.NullString
+		images        sql.NullString
+		tags          sql.NullString
+	)
+
+	doc := core.Document{ID: core.NewDocumentID(repo, path).String(), Repo: repo, Path: path}
+
+	err := s.db.QueryRowContext(ctx, `
+		SELECT content, title, summary, commit_sha, content_type, home, provenance, anchors, doc_text, images, tags, doc_order,
+			draft, updated_at
+		FROM documents WHERE repo = ? AND path = ?`, repo, path).
+		Scan(&doc.Content, &doc.Title, &doc.Summary, &doc.CommitSHA, &ct, &doc.Home, &provenance, &anchors, &text,
+			&images, &tags, &doc.Order, &doc.Draft, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.Document{}, fmt.Errorf("%w: %s/%s", core.ErrNotFound, repo, path)
+	}
+
+	if err != nil {
+		return core.Document{}, fmt.Errorf("failed to read document: %w", err)
+	}
+
+	if provenance.Valid {
+		doc.Provenance = &core.Provenance{}
+		if err := json.Unmarshal([]byte(provenance.String), doc.Provenance); err != nil {
+			return core.Document{}, fmt.Errorf("failed to unmarshal provenance: %w", err)
+		}
+	}
+
+	if anchors.Valid {
+		if err := json.Unmarshal([]byte(anchors.String), &doc.Anchors); err != nil {
+			return core.Document{}, fmt.Errorf("failed to unmarshal anchors: %w", err)
+		}
+	}
+
+	if text.Valid {
+		doc.Text = &core.DocumentText{}
+		if err := json.Unmarshal([]byte(text.String), doc.Text); err != nil {
+			return core.Document{}, fmt.Errorf("failed to unmarshal document text: %w", err)
+		}
+	}
+
+	if images.Valid {
+		if err := json.Unmarshal([]byte(images.String), &doc.Images); err != nil {
+			return core.Document{}, fmt.Errorf("failed to unmarshal images: %w", err)
+		}
+	}
+
+	if doc.Tags, err = unmarshalTags(tags); err != nil {
+		return core.Document{}, err
+	}
+
+	doc.ContentType = contentType(ct)
+	doc.UpdatedAt = parseTime(updatedAt)
+
+	return doc, nil
+}
+
+// Delete removes a document. Deleting a document that does not exist is not an error.
+func (s *Store) Delete(ctx context.Context, repo, path string) error {
+	if err := validateKey(repo, path); err != nil {
+		return err
+	}
+
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM documents WHERE repo = ? AND path = ?`, repo, path); err != nil {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+
+	return nil
+}
+
+// List returns metadata for all documents in a repository, ordered by path.
+func (s *Store) List(ctx context.Context, repo string) ([]core.DocumentMeta, error) {
+	if err := validateRelPath(repo); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT path, title, summary, content_type, home, tags, doc_order, draft, updated_at
+		FROM documents WHERE repo = ? ORDER BY path`, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+
+	defer rows.Close()
+
+	var docs []core.DocumentMeta
+
+	for rows.Next() {
+		var (
+			ct, updatedAt string
+			tags          sql.NullString
+		)
+
+		meta := core.DocumentMeta{Repo: repo}
+
+		if err := rows.Scan(&meta.Path, &meta.Title, &meta.Summary, &ct, &meta.Home, &tags, &meta.Order, &meta.Draft, &updatedAt); err != nil {
+			return nil, fmt.Errorf("failed to read document metadata: %w", err)
+		}
+
+		var err error
+		if meta.Tags, err = unmarshalTags(tags); err != nil {
+			return nil, err
+		}
+
+		meta.ID = core.NewDocumentID(repo, meta.Path).String()
+		meta.ContentType = contentType(ct)
+		meta.UpdatedAt = parseTime(updatedAt)
+
+		docs = append(docs, meta)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+
+	return docs, nil
+}
+
+// ListRepos returns metadata for all indexed repositories, ordered by name.
+func (s *Store) ListRepos(ctx context.Context) ([]core.RepoInfo, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT r.name, r.last_updated, COUNT(d.path)
+		FROM repos r LEFT JOIN documents d ON d.repo = r.name
+		GROUP BY r.name ORDER BY r.name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repos: %w", err)
+	}
+
+	defer rows.Close()
+
+	var repos []core.RepoInfo
+
+	for rows.Next() {
+		var (
+			info        core.RepoInfo
+			lastUpdated string
+		)
+
+		if err := rows.Scan(&info.Name, &lastUpdated, &info.DocCount); err != nil {
+			return nil, fmt.Errorf("failed to read repo metadata: %w", err)
+		}
+
+		info.LastUpdated = parseTime(lastUpdated)
+
+		repos = append(repos, info)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list repos: %w", err)
+	}
+
+	return repos, nil
+}
+
+// SaveAsset stores a binary asset, replacing any asset at the same path.
+func (s *Store) SaveAsset(ctx context.Context, repo, path string, data []byte) error {
+	if err := validateKey(repo, path); err != nil {
+		return err
+	}
+
+	if data == nil {
+		data = []byte{}
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO assets (repo, path, data) VALUES (?, ?, ?)
+		ON CONFLICT (repo, path) DO UPDATE SET data = excluded.data`, repo, path, data)
+	if err != nil {
+		return fmt.Errorf("failed to write asset: %w", err)
+	}
+
+	return nil
+}
+
+// GetAsset reads a binary asset by its repository and path.
+func (s *Store) GetAsset(ctx context.Context, repo, path string) ([]byte, error) {
+	if err := validateKey(repo, path); err != nil {
+		return nil, err
+	}
+
+	var data []byte
+
+	err := s.db.QueryRowContext(ctx, `SELECT data FROM assets WHERE repo = ? AND path = ?`, repo, path).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: asset %s/%s", core.ErrNotFound, repo, path)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read asset: %w", err)
+	}
+
+	return data, nil
+}
+
+// DeleteAsset removes a binary asset. Deleting an asset that does not exist is not an error.
+func (s *Store) DeleteAsset(ctx context.Context, repo, path string) error {
+	if err := validateKey(repo, path); err != nil {
+		return err
+	}
+
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM assets WHERE repo = ? AND path = ?`, repo, path); err != nil {
+		return fmt.Errorf("failed to delete asset: %w", err)
+	}
+
+	return nil
+}
+
+// ListAssets returns all asset paths of a repository, sorted.
+func (s *Store) ListAssets(ctx context.Context, repo string) ([]string, error) {
+	if err := validateRelPath(repo); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT path FROM assets WHERE repo = ? ORDER BY path`, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list assets: %w", err)
+	}
+
+	defer rows.Close()
+
+	var paths []string
+
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, fmt.Errorf("failed to read asset path: %w", err)
+		}
+
+		paths = append(paths, path)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list assets: %w", err)
+	}
+
+	return paths, nil
+}
+
+// DeleteRepo removes a repository with all of its documents, assets and metadata.
+// Sub-projects of the repository are kept, as they are stored under their own names.
+func (s *Store) DeleteRepo(ctx context.Context, repo string) error {
+	if err := validateRelPath(repo); err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() { _ = tx.Rollback() }()
+
+	for _, query := range []string{
+		`DELETE FROM documents WHERE repo = ?`,
+		`DELETE FROM assets WHERE repo = ?`,
+		`DELETE FROM repos WHERE name = ?`,
+	} {
+		if _, err := tx.ExecContext(ctx, query, repo); err != nil {
+			return fmt.Errorf("failed to delete repo: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to delete repo: %w", err)
+	}
+
+	return nil
+}
+
+// getSetting decodes the JSON value stored under key into v. It reports false when the
+// key has never been saved.
+func (s *Store) getSetting(ctx context.Context, key string, v any) (bool, error) {
+	var value string
+
+	err := s.db.QueryRowContext(ctx, `SELECT value FROM settings WHERE key = ?`, key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	if err := json.Unmarshal([]byte(value), v); err != nil {
+		return false, fmt.Errorf("failed to unmarshal: %w", err)
+	}
+
+	return true, nil
+}
+
+// saveSetting stores v as JSON under key, replacing the previous value.
+func (s *Store) saveSetting(ctx context.Context, key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO settings (key, value) VALUES (?, ?)
+		ON CONFLICT (key) DO UPDATE SET value = excluded.value`, key, string(data))
+
+	return err
+}
+
+// GetRedirects returns the recorded repository redirects, keyed by old identifier.
+// It returns an empty map when no redirects have been recorded.
+func (s *Store) GetRedirects(ctx context.Context) (map[string]string, error) {
+	redirects := make(map[string]string)
+
+	if _, err := s.getSetting(ctx, redirectsKey, &redirects); err != nil {
+		return nil, fmt.Errorf("failed to read redirects: %w", err)
+	}
+
+	return redirects, nil
+}
+
+// SaveRedirects replaces the recorded repository redirects.
+func (s *Store) SaveRedirects(ctx context.Context, redirects map[string]string) error {
+	if err := s.saveSetting(ctx, redirectsKey, redirects); err != nil {
+		return fmt.Errorf("failed to write redirects: %w", err)
+	}
+
+	return nil
+}
+
+// GetAPIKeys returns the managed API keys. It returns an empty list when no keys have
+// been created.
+func (s *Store) GetAPIKeys(ctx context.Context) ([]core.APIKey, error) {
+	var keys []core.APIKey
+
+	found, err := s.getSetting(ctx, apiKeysKey, &keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read api keys: %w", err)
+	}
+
+	if !found {
+		return []core.APIKey{}, nil
+	}
+
+	return keys, nil
+}
+
+// SaveAPIKeys replaces the managed API keys.
+func (s *Store) SaveAPIKeys(ctx context.Context, keys []core.APIKey) error {
+	if err := s.saveSetting(ctx, apiKeysKey, keys); err != nil {
+		return fmt.Errorf("failed to write api keys: %w", err)
+	}
+
+	return nil
+}
+
+// GetHighlights returns the highlights of every repository, keyed by repository. It
+// returns an empty map when no repository has highlights.
+func (s *Store) GetHighlights(ctx context.Context) (map[string]core.RepoHighlights, error) {
+	highlights := make(map[string]core.RepoHighlights)
+
+	if _, err := s.getSetting(ctx, highlightsKey, &highlights); err != nil {
+		return nil, fmt.Errorf("failed to read highlights: %w", err)
+	}
+
+	return highlights, nil
+}
+
+// SaveHighlights replaces the highlights of every repository.
+func (s *Store) SaveHighlights(ctx context.Context, highlights map[string]core.RepoHighlights) error {
+	if err := s.saveSetting(ctx, highlightsKey, highlights); err != nil {
+		return fmt.Errorf("failed to write highlights: %w", err)
+	}
+
+	return nil
+}
+
+// GetSiteBanner returns the site banner, or nil when none is set.
+func (s *Store) GetSiteBanner(ctx context.Context) (*core.SiteBanner, error) {
+	var banner *core.SiteBanner
+
+	if _, err := s.getSetting(ctx, bannerKey, &banner); err != nil {
+		return nil, fmt.Errorf("failed to read site banner: %w", err)
+	}
+
+	return banner, nil
+}
+
+// SaveSiteBanner replaces the site banner; a nil banner clears it.
+func (s *Store) SaveSiteBanner(ctx context.Context, banner *core.SiteBanner) error {
+	if err := s.saveSetting(ctx, bannerKey, banner); err != nil {
+		return fmt.Errorf("failed to write site banner: %w", err)
+	}
+
+	return nil
+}
+
+// Size returns the number of bytes occupied by the database pages.
+func (s *Store) Size(ctx context.Context) (int64, error) {
+	var size int64
+
+	err := s.db.QueryRowContext(ctx,
+		`SELECT page_count * page_size FROM pragma_page_count(), pragma_page_size()`).Scan(&size)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get storage size: %w", err)
+	}
+
+	return size, nil
+}
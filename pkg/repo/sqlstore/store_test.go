@@ -0,0 +1,365 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+
+	store, err := New(filepath.Join(t.TempDir(), "data", "omnidex.db"))
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = store.Close() })
+
+	return store
+}
+
+func TestNew(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "omnidex.db")
+
+	store, err := New(path)
+	require.NoError(t, err)
+	require.NoError(t, store.Save(t.Context(), core.Document{Repo: "owner/repo", Path: "guide.md", UpdatedAt: time.Now()}))
+	require.NoError(t, store.Close())
+
+	store, err = New(path)
+	require.NoError(t, err)
+
+	defer store.Close()
+
+	_, err = store.Get(t.Context(), "owner/repo", "guide.md")
+	assert.NoError(t, err, "documents persist across reopening")
+}
+
+func TestStore_SaveAndGet(t *testing.T) {
+	store := newTestStore(t)
+
+	doc := core.Document{
+		Repo:       "owner/repo",
+		Path:       "guides/getting-started.md",
+		Title:      "Getting Started",
+		Summary:    "How to start.",
+		Content:    "# Getting Started\n\nWelcome!",
+		CommitSHA:  "abc123",
+		Provenance: &core.Provenance{Workflow: "publish.yml", RunURL: "https://example.com/run/1", Verified: true},
+		UpdatedAt:  time.Date(2026, 3, 4, 5, 6, 7, 890, time.UTC),
+		Home:       true,
+		Tags:       []string{"onboarding", "getting started"},
+		Order:      1,
+		Draft:      true,
+		Anchors:    core.AnchorMap{{ID: "getting-started", Text: "Getting Started", Offset: 0, Level: 1}},
+		Text: &core.DocumentText{
+			SHA256:    "0f1e",
+			PlainText: "Getting Started\nWelcome!",
+			Headings:  []core.Heading{{ID: "getting-started", Text: "Getting Started", Level: 1}},
+		},
+		Images: map[string]core.ImageVariants{"guides/shot.png": {Widths: []int{480}, Width: 800}},
+	}
+
+	require.NoError(t, store.Save(t.Context(), doc))
+
+	got, err := store.Get(t.Context(), "owner/repo", "guides/getting-started.md")
+	require.NoError(t, err)
+
+	doc.ID = "owner/repo/guides/getting-started.md"
+	doc.ContentType = core.ContentTypeMarkdown
+	assert.Equal(t, doc, got)
+
+	doc.Content = "# Updated"
+	doc.ContentType = core.ContentTypeOpenAPI
+	doc.Provenance = nil
+	doc.Anchors = core.AnchorMap{}
+	doc.Text = nil
+	doc.Images = nil
+	require.NoError(t, store.Save(t.Context(), doc))
+
+	got, err = store.Get(t.Context(), "owner/repo", "guides/getting-started.md")
+	require.NoError(t, err)
+	assert.Equal(t, doc, got)
+}
+
+func TestNew_AddsColumns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "omnidex.db")
+
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+
+	_, err = db.Exec(`CREATE TABLE documents (
+		repo TEXT NOT NULL, path TEXT NOT NULL, content TEXT NOT NULL, title TEXT NOT NULL,
+		summary TEXT NOT NULL, commit_sha TEXT NOT NULL, content_type TEXT NOT NULL,
+		home INTEGER NOT NULL, provenance TEXT, updated_at TEXT NOT NULL, PRIMARY KEY (repo, path))`)
+	require.NoError(t, err)
+
+	_, err = db.Exec(`INSERT INTO documents VALUES ('owner/repo', 'old.md', '# Old', 'Old', '', '', 'markdown', 0, NULL, '')`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	store, err := New(path)
+	require.NoError(t, err)
+
+	defer store.Close()
+
+	got, err := store.Get(t.Context(), "owner/repo", "old.md")
+	require.NoError(t, err)
+	assert.Nil(t, got.Anchors, "documents saved before the column was added have no anchor map")
+	assert.Nil(t, got.Text)
+	assert.Nil(t, got.Images)
+
+	require.NoError(t, store.Save(t.Context(), core.Document{Repo: "owner/repo", Path: "new.md", Anchors: core.AnchorMap{}}))
+
+	got, err = store.Get(t.Context(), "owner/repo", "new.md")
+	require.NoError(t, err)
+	assert.Equal(t, core.AnchorMap{}, got.Anchors)
+}
+
+func TestStore_GetNotFound(t *testing.T) {
+	store := newTestStore(t)
+
+	_, err := store.Get(t.Context(), "owner/repo", "nonexistent.md")
+	assert.ErrorIs(t, err, core.ErrNotFound)
+}
+
+func TestStore_Delete(t *testing.T) {
+	store := newTestStore(t)
+
+	require.NoError(t, store.Save(t.Context(), core.Document{Repo: "owner/repo", Path: "a.md", UpdatedAt: time.Now()}))
+	require.NoError(t, store.Delete(t.Context(), "owner/repo", "a.md"))
+
+	_, err := store.Get(t.Context(), "owner/repo", "a.md")
+	assert.ErrorIs(t, err, core.ErrNotFound)
+
+	assert.NoError(t, store.Delete(t.Context(), "owner/repo", "a.md"), "deleting a missing document is not an error")
+}
+
+func TestStore_List(t *testing.T) {
+	store := newTestStore(t)
+
+	docs, err := store.List(t.Context(), "owner/repo")
+	require.NoError(t, err)
+	assert.Empty(t, docs)
+
+	for _, path := range []string{"z.md", "a.md", "nested/b.md"} {
+		require.NoError(t, store.Save(t.Context(), core.Document{Repo: "owner/repo", Path: path, Title: path, UpdatedAt: time.Now()}))
+	}
+
+	require.NoError(t, store.Save(t.Context(), core.Document{Repo: "owner/repo/sub", Path: "c.md", UpdatedAt: time.Now()}))
+
+	docs, err = store.List(t.Context(), "owner/repo")
+	require.NoError(t, err)
+	require.Len(t, docs, 3)
+	assert.Equal(t, "a.md", docs[0].Path)
+	assert.Equal(t, "owner/repo/nested/b.md", docs[1].ID)
+	assert.Equal(t, "z.md", docs[2].Title)
+	assert.Equal(t, core.ContentTypeMarkdown, docs[2].ContentType)
+}
+
+func TestStore_ListRepos(t *testing.T) {
+	store := newTestStore(t)
+
+	repos, err := store.ListRepos(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, repos)
+
+	older := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+
+	require.NoError(t, store.Save(t.Context(), core.Document{Repo: "owner/mono/service", Path: "a.md", UpdatedAt: older}))
+	require.NoError(t, store.Save(t.Context(), core.Document{Repo: "owner/mono", Path: "a.md", UpdatedAt: older}))
+	require.NoError(t, store.Save(t.Context(), core.Document{Repo: "owner/mono", Path: "b.md", UpdatedAt: newer}))
+
+	repos, err = store.ListRepos(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, []core.RepoInfo{
+		{Name: "owner/mono", DocCount: 2, LastUpdated: newer},
+		{Name: "owner/mono/service", DocCount: 1, LastUpdated: older},
+	}, repos)
+}
+
+func TestStore_Assets(t *testing.T) {
+	store := newTestStore(t)
+
+	_, err := store.GetAsset(t.Context(), "owner/repo", "img/a.png")
+	assert.ErrorIs(t, err, core.ErrNotFound)
+
+	require.NoError(t, store.SaveAsset(t.Context(), "owner/repo", "img/b.png", []byte("b")))
+	require.NoError(t, store.SaveAsset(t.Context(), "owner/repo", "img/a.png", []byte("old")))
+	require.NoError(t, store.SaveAsset(t.Context(), "owner/repo", "img/a.png", []byte("new")))
+
+	data, err := store.GetAsset(t.Context(), "owner/repo", "img/a.png")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("new"), data)
+
+	paths, err := store.ListAssets(t.Context(), "owner/repo")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"img/a.png", "img/b.png"}, paths)
+
+	require.NoError(t, store.DeleteAsset(t.Context(), "owner/repo", "img/a.png"))
+	require.NoError(t, store.DeleteAsset(t.Context(), "owner/repo", "img/a.png"))
+
+	paths, err = store.ListAssets(t.Context(), "owner/repo")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"img/b.png"}, paths)
+}
+
+func TestStore_PathTraversal(t *testing.T) {
+	store := newTestStore(t)
+
+	for _, tc := range []struct {
+		name, repo, path string
+	}{
+		{name: "parent path", repo: "owner/repo", path: "../../etc/passwd"},
+		{name: "absolute path", repo: "owner/repo", path: "/etc/passwd"},
+		{name: "empty path", repo: "owner/repo", path: ""},
+		{name: "parent repo", repo: "../outside", path: "a.md"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := store.Save(t.Context(), core.Document{Repo: tc.repo, Path: tc.path})
+			assert.ErrorIs(t, err, core.ErrInvalidPath)
+
+			_, err = store.Get(t.Context(), tc.repo, tc.path)
+			assert.ErrorIs(t, err, core.ErrInvalidPath)
+
+			err = store.SaveAsset(t.Context(), tc.repo, tc.path, []byte("x"))
+			assert.ErrorIs(t, err, core.ErrInvalidPath)
+		})
+	}
+
+	_, err := store.List(t.Context(), "../outside")
+	assert.ErrorIs(t, err, core.ErrInvalidPath)
+}
+
+func TestStore_DeleteRepo(t *testing.T) {
+	store := newTestStore(t)
+
+	for _, repo := range []string{"owner/mono", "owner/mono/service"} {
+		require.NoError(t, store.Save(t.Context(), core.Document{Repo: repo, Path: "guide.md", UpdatedAt: time.Now()}))
+		require.NoError(t, store.SaveAsset(t.Context(), repo, "img.png", []byte("png")))
+	}
+
+	require.NoError(t, store.DeleteRepo(t.Context(), "owner/mono"))
+
+	repos, err := store.ListRepos(t.Context())
+	require.NoError(t, err)
+	require.Len(t, repos, 1)
+	assert.Equal(t, "owner/mono/service", repos[0].Name)
+
+	assets, err := store.ListAssets(t.Context(), "owner/mono")
+	require.NoError(t, err)
+	assert.Empty(t, assets)
+
+	assets, err = store.ListAssets(t.Context(), "owner/mono/service")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"img.png"}, assets)
+
+	assert.ErrorIs(t, store.DeleteRepo(t.Context(), "../outside"), core.ErrInvalidPath)
+}
+
+func TestStore_Redirects(t *testing.T) {
+	store := newTestStore(t)
+
+	redirects, err := store.GetRedirects(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, redirects)
+
+	require.NoError(t, store.SaveRedirects(t.Context(), map[string]string{"old/repo": "new/repo"}))
+
+	redirects, err = store.GetRedirects(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"old/repo": "new/repo"}, redirects)
+}
+
+func TestStore_APIKeys(t *testing.T) {
+	store := newTestStore(t)
+
+	keys, err := store.GetAPIKeys(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, []core.APIKey{}, keys)
+
+	want := []core.APIKey{{ID: "a1b2c3", Name: "ci", Hash: "deadbeef", CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}}
+	require.NoError(t, store.SaveAPIKeys(t.Context(), want))
+
+	keys, err = store.GetAPIKeys(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, want, keys)
+
+	repos, err := store.ListRepos(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, repos)
+}
+
+func TestStore_Highlights(t *testing.T) {
+	store := newTestStore(t)
+
+	highlights, err := store.GetHighlights(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, highlights)
+
+	want := map[string]core.RepoHighlights{"acme/api": {
+		Repo:         "acme/api",
+		Announcement: "**v1 is deprecated**",
+		Pinned:       []string{"guides/migrate.md"},
+		UpdatedAt:    time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}}
+	require.NoError(t, store.SaveHighlights(t.Context(), want))
+
+	highlights, err = store.GetHighlights(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, want, highlights)
+
+	repos, err := store.ListRepos(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, repos)
+}
+
+func TestStore_SiteBanner(t *testing.T) {
+	store := newTestStore(t)
+
+	banner, err := store.GetSiteBanner(t.Context())
+	require.NoError(t, err)
+	assert.Nil(t, banner)
+
+	want := &core.SiteBanner{
+		Message:   "Publishing is paused during the storage migration.",
+		Severity:  core.BannerWarning,
+		StartsAt:  time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC),
+		EndsAt:    time.Date(2026, 1, 2, 5, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, store.SaveSiteBanner(t.Context(), want))
+
+	banner, err = store.GetSiteBanner(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, want, banner)
+
+	repos, err := store.ListRepos(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, repos)
+
+	require.NoError(t, store.SaveSiteBanner(t.Context(), nil))
+
+	banner, err = store.GetSiteBanner(t.Context())
+	require.NoError(t, err)
+	assert.Nil(t, banner)
+}
+
+func TestStore_Size(t *testing.T) {
+	store := newTestStore(t)
+
+	empty, err := store.Size(t.Context())
+	require.NoError(t, err)
+
+	require.NoError(t, store.SaveAsset(t.Context(), "owner/repo", "img.png", make([]byte, 100_000)))
+
+	size, err := store.Size(t.Context())
+	require.NoError(t, err)
+	assert.Greater(t, size, empty)
+	assert.GreaterOrEqual(t, size, int64(100_000))
+}
@@ -0,0 +1,98 @@
+package views
+
+import (
+	"html/template"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+// typeIconPaths holds the SVG shapes of the content type icons by icon name. Content types
+// with an icon missing here are shown with the document icon.
+var typeIconPaths = map[string]string{
+	"document": `<path d="M14 2H6a2 2 0 0 0-2 2v16a2 2 0 0 0 2 2h12a2 2 0 0 0 2-2V8z"/><polyline points="14 2 14 8 20 8"/>`,
+	"api":      `<polyline points="16 18 22 12 16 6"/><polyline points="8 6 2 12 8 18"/>`,
+	"events":   `<polygon points="13 2 3 14 12 14 11 22 21 10 12 10 13 2"/>`,
+	"schema":   `<path d="M8 3H7a2 2 0 0 0-2 2v5a2 2 0 0 1-2 2 2 2 0 0 1 2 2v5c0 1.1.9 2 2 2h1"/><path d="M16 21h1a2 2 0 0 0 2-2v-5c0-1.1.9-2 2-2a2 2 0 0 1-2-2V5a2 2 0 0 0-2-2h-1"/>`,
+	"graph":    `<circle cx="18" cy="5" r="3"/><circle cx="6" cy="12" r="3"/><circle cx="18" cy="19" r="3"/><line x1="8.59" y1="13.51" x2="15.42" y2="17.49"/><line x1="15.41" y1="6.51" x2="8.59" y2="10.49"/>`,
+}
+
+// typeBadges renders the icons and badges marking the content type of documents in
+// listings, navigation and search results.
+type typeBadges struct {
+	types    map[core.ContentType]core.ContentTypeInfo
+	fallback core.ContentType
+}
+
+// newTypeBadges creates the badges of the given content types. Documents without a
+// content type are shown as the fallback type, like the server processes them.
+func newTypeBadges(types []core.ContentTypeInfo) typeBadges {
+	b := typeBadges{types: make(map[core.ContentType]core.ContentTypeInfo, len(types)), fallback: core.ContentTypeMarkdown}
+
+	for _, t := range types {
+		b.types[t.Name] = t
+
+		if t.Fallback {
+			b.fallback = t.Name
+		}
+	}
+
+	return b
+}
+
+// info returns the description of a content type. Types the server does not process are
+// named by their identifier.
+func (b typeBadges) info(ct core.ContentType) core.ContentTypeInfo {
+	if ct == "" {
+		ct = b.fallback
+	}
+
+	if t, ok := b.types[ct]; ok {
+		return t
+	}
+
+	return core.ContentTypeInfo{Name: ct, DisplayName: string(ct)}
+}
+
+// icon returns the icon of a content type, labelled with the type's display name for
+// screen readers and on hover.
+func (b typeBadges) icon(ct core.ContentType) template.HTML {
+	t := b.info(ct)
+
+	return template.HTML(`<span class="type-icon inline-flex shrink-0 text-gray-400 dark:text-gray-500" data-content-type="` + //nolint:gosec // escaped below
+		template.HTMLEscapeString(string(t.Name)) + `" title="` + template.HTMLEscapeString(t.DisplayName) + `">` + iconSVG(t.Icon, t.DisplayName) + `</span>`)
+}
+
+// badge returns a pill naming the content type of a document next to its icon.
+func (b typeBadges) badge(ct core.ContentType) template.HTML {
+	t := b.info(ct)
+
+	return template.HTML(`<span class="type-badge inline-flex items-center gap-1 px-2 py-0.5 rounded-full text-xs font-medium bg-gray-100 text-gray-600 dark:bg-gray-700 dark:text-gray-300" data-content-type="` + //nolint:gosec // escaped below
+		template.HTMLEscapeString(string(t.Name)) + `">` + iconSVG(t.Icon, "") + template.HTMLEscapeString(t.DisplayName) + `</span>`)
+}
+
+// embedType returns the media type documents of a binary content type are served with, or
+// an empty string for text content types.
+func (b typeBadges) embedType(ct core.ContentType) string {
+	if t := b.info(ct); t.Binary {
+		return t.MediaType
+	}
+
+	return ""
+}
+
+// iconSVG returns the SVG of the named icon. The icon is announced as label, or hidden
+// from screen readers when label is empty because the text next to it names the type.
+func iconSVG(name, label string) string {
+	paths, ok := typeIconPaths[name]
+	if !ok {
+		paths = typeIconPaths["document"]
+	}
+
+	a11y := `aria-hidden="true"`
+	if label != "" {
+		a11y = `role="img" aria-label="` + template.HTMLEscapeString(label) + `"`
+	}
+
+	return `<svg xmlns="http://www.w3.org/2000/svg" width="12" height="12" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2" stroke-linecap="round" stroke-linejoin="round" ` +
+		a11y + `>` + paths + `</svg>`
+}
@@ -0,0 +1,83 @@
+package views
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypeBadges(t *testing.T) {
+	b := newTypeBadges(append(core.DefaultContentTypes(), core.ContentTypeInfo{Name: "graphql", DisplayName: "GraphQL <SDL>", Icon: "graph"}))
+
+	tests := []struct {
+		name      string
+		ct        core.ContentType
+		wantBadge string
+		wantIcon  string
+	}{
+		{name: "markdown", ct: core.ContentTypeMarkdown, wantBadge: `data-content-type="markdown"`, wantIcon: `aria-label="Markdown"`},
+		{name: "empty is the fallback type", ct: "", wantBadge: `data-content-type="markdown"`, wantIcon: `aria-label="Markdown"`},
+		{name: "openapi", ct: core.ContentTypeOpenAPI, wantBadge: `<polyline points="16 18 22 12 16 6"/>`, wantIcon: `title="OpenAPI"`},
+		{name: "escaped display name", ct: "graphql", wantBadge: `GraphQL &lt;SDL&gt;</span>`, wantIcon: `aria-label="GraphQL &lt;SDL&gt;"`},
+		{name: "unknown type", ct: "notebook", wantBadge: `data-content-type="notebook">`, wantIcon: typeIconPaths["document"]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Contains(t, string(b.badge(tt.ct)), tt.wantBadge)
+			assert.Contains(t, string(b.icon(tt.ct)), tt.wantIcon)
+		})
+	}
+}
+
+func TestRender_ContentTypeBadges(t *testing.T) {
+	r := New()
+
+	docs := []core.DocumentMeta{
+		{Repo: "acme/api", Path: "guide.md", Title: "Guide", ContentType: core.ContentTypeMarkdown},
+		{Repo: "acme/api", Path: "events.yaml", Title: "Events", ContentType: core.ContentTypeAsyncAPI},
+	}
+
+	var buf bytes.Buffer
+
+	require.NoError(t, r.RenderRepoIndex(&buf, "acme/api", docs, nil, nil, false, true))
+	assert.Contains(t, buf.String(), `class="type-badge`)
+	assert.Contains(t, buf.String(), `data-content-type="asyncapi">`)
+
+	buf.Reset()
+
+	doc := core.Document{Repo: "acme/api", Path: "guide.md", Title: "Guide"}
+	require.NoError(t, r.RenderDoc(&buf, doc, []byte("<p>Guide</p>"), nil, docs, nil, true))
+	assert.Contains(t, buf.String(), `class="type-icon`)
+	assert.Contains(t, buf.String(), `aria-label="AsyncAPI"`)
+
+	buf.Reset()
+
+	results := &core.SearchResults{
+		Hits:  []core.SearchResult{{Repo: "acme/api", Path: "events.yaml", Title: "Events", ContentType: core.ContentTypeAsyncAPI}},
+		Total: 1,
+	}
+	require.NoError(t, r.RenderSearch(&buf, "events", core.SearchOpts{}, results, nil, true))
+	assert.Contains(t, buf.String(), `data-content-type="asyncapi">`)
+}
+
+func TestRenderDoc_BinaryViewer(t *testing.T) {
+	pdf := core.ContentTypeInfo{Name: core.ContentTypePDF, DisplayName: "PDF", Extensions: []string{".pdf"}, Binary: true, MediaType: "application/pdf"}
+	r := New(WithContentTypes(append(core.DefaultContentTypes(), pdf)))
+
+	var buf bytes.Buffer
+
+	doc := core.Document{Repo: "acme/api", Path: "specs/design doc.pdf", Title: "Design", ContentType: core.ContentTypePDF}
+	require.NoError(t, r.RenderDoc(&buf, doc, []byte(`<section class="pdf-page"><h2 id="page-1">Page 1</h2></section>`), nil, nil, nil, true))
+	assert.Contains(t, buf.String(), `<object class="doc-viewer" data="/raw/acme/api/specs/design%20doc.pdf" type="application/pdf"`)
+	assert.Contains(t, buf.String(), `<h2 id="page-1">Page 1</h2>`)
+
+	buf.Reset()
+
+	doc = core.Document{Repo: "acme/api", Path: "guide.md", Title: "Guide"}
+	require.NoError(t, r.RenderDoc(&buf, doc, []byte("<p>Guide</p>"), nil, nil, nil, true))
+	assert.NotContains(t, buf.String(), `class="doc-viewer"`)
+}
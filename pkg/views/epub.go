@@ -0,0 +1,394 @@
+package views
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+// epubStylesheet is the stylesheet shared by the chapters of an EPUB export.
+const epubStylesheet = `body { font-family: serif; line-height: 1.5; }
+pre { white-space: pre-wrap; font-size: 0.85em; background: #f3f4f6; padding: 0.5em; }
+code { font-family: monospace; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #999; padding: 0.2em 0.4em; }
+img { max-width: 100%; }
+`
+
+// epubImageTypes maps the image extensions embedded in EPUB exports to their media types.
+var epubImageTypes = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".gif":  "image/gif",
+	".svg":  "image/svg+xml",
+	".webp": "image/webp",
+}
+
+// epubChapter is a document of an EPUB export.
+type epubChapter struct {
+	File string
+	sectionDoc
+}
+
+// epubImage is an image embedded in an EPUB export.
+type epubImage struct {
+	File      string
+	MediaType string
+	Data      []byte
+}
+
+// epubBook collects the contents of an EPUB export while its chapters are converted.
+type epubBook struct {
+	asset    func(path string) ([]byte, error)
+	byPath   map[string]string
+	images   map[string]*epubImage
+	repo     string
+	chapters []epubChapter
+	order    []string
+}
+
+// RenderEPUB writes the documents of a repository as an EPUB 3 e-book, one chapter per
+// document in the order of the sidebar navigation. Images stored as repository assets are
+// embedded using asset, which loads an asset by its path; images that cannot be loaded
+// are replaced by their alt text. Links between documents of the book point to their
+// chapters.
+func (v *Renderer) RenderEPUB(w io.Writer, repo string, docs []core.SectionDocument, asset func(path string) ([]byte, error)) error {
+	book := &epubBook{
+		repo:   repo,
+		asset:  asset,
+		byPath: make(map[string]string, len(docs)),
+		images: make(map[string]*epubImage),
+	}
+
+	for _, d := range orderSection(docs) {
+		ch := epubChapter{sectionDoc: d, File: "ch" + strconv.Itoa(len(book.chapters)+1) + ".xhtml"}
+		book.byPath[d.Doc.Path] = ch.File
+		book.chapters = append(book.chapters, ch)
+	}
+
+	zw := zip.NewWriter(w)
+
+	// The mimetype entry must come first and be stored uncompressed.
+	mimetype, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return fmt.Errorf("failed to write epub: %w", err)
+	}
+
+	if _, err := io.WriteString(mimetype, "application/epub+zip"); err != nil {
+		return fmt.Errorf("failed to write epub: %w", err)
+	}
+
+	files := map[string]string{
+		"META-INF/container.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`,
+		"OEBPS/style.css": epubStylesheet,
+		"OEBPS/nav.xhtml": book.nav(),
+	}
+
+	for _, ch := range book.chapters {
+		body, err := book.chapterBody(ch)
+		if err != nil {
+			return fmt.Errorf("failed to convert %s: %w", ch.Doc.Path, err)
+		}
+
+		files["OEBPS/"+ch.File] = xhtmlPage(ch.Title, body)
+	}
+
+	// The package document lists the images, so it is built after the chapters.
+	files["OEBPS/content.opf"] = book.packageDocument()
+
+	names := []string{"META-INF/container.xml", "OEBPS/content.opf", "OEBPS/nav.xhtml", "OEBPS/style.css"}
+	for _, ch := range book.chapters {
+		names = append(names, "OEBPS/"+ch.File)
+	}
+
+	for _, name := range names {
+		if err := writeZipFile(zw, name, []byte(files[name])); err != nil {
+			return err
+		}
+	}
+
+	for _, src := range book.order {
+		img := book.images[src]
+		if err := writeZipFile(zw, "OEBPS/"+img.File, img.Data); err != nil {
+			return err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to write epub: %w", err)
+	}
+
+	return nil
+}
+
+// writeZipFile adds a compressed file to the archive.
+func writeZipFile(zw *zip.Writer, name string, data []byte) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to write epub: %w", err)
+	}
+
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("failed to write epub: %w", err)
+	}
+
+	return nil
+}
+
+// chapterBody converts the rendered HTML of a chapter to XHTML, embedding its images and
+// pointing links between documents of the book at their chapters.
+func (b *epubBook) chapterBody(ch epubChapter) (string, error) {
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+
+	nodes, err := html.ParseFragment(strings.NewReader(ch.HTML), body)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	var buf bytes.Buffer
+
+	for _, n := range nodes {
+		b.rewrite(n, ch.Doc.Path)
+
+		if err := html.Render(&buf, n); err != nil {
+			return "", fmt.Errorf("failed to render XHTML: %w", err)
+		}
+	}
+
+	return buf.String(), nil
+}
+
+// rewrite adjusts the links and images of a node tree for the book.
+func (b *epubBook) rewrite(n *html.Node, docPath string) {
+	if n.Type == html.ElementNode {
+		switch n.DataAtom {
+		case atom.A:
+			for i, a := range n.Attr {
+				if a.Key == "href" {
+					n.Attr[i].Val = b.chapterLink(docPath, a.Val)
+				}
+			}
+		case atom.Img:
+			b.embedImage(n)
+			return
+		}
+	}
+
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		b.rewrite(c, docPath)
+		c = next
+	}
+}
+
+// chapterLink returns the target of a link in the book: the chapter of a linked document
+// of the book, or the original link otherwise.
+func (b *epubBook) chapterLink(docPath, href string) string {
+	u, err := url.Parse(href)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" {
+		return href
+	}
+
+	target := strings.TrimPrefix(u.Path, "/docs/"+b.repo+"/")
+	if !strings.HasPrefix(u.Path, "/") {
+		target = path.Join(path.Dir(docPath), u.Path)
+	}
+
+	file, ok := b.byPath[target]
+	if !ok {
+		return href
+	}
+
+	if u.Fragment != "" {
+		return file + "#" + u.Fragment
+	}
+
+	return file
+}
+
+// embedImage adds an image stored as a repository asset to the book and points the img
+// element at it, or replaces the element by its alt text when the image is not available.
+func (b *epubBook) embedImage(n *html.Node) {
+	var src, alt string
+
+	attrs := n.Attr[:0]
+
+	for _, a := range n.Attr {
+		switch a.Key {
+		case "src":
+			src = a.Val
+		case "alt":
+			alt = a.Val
+			attrs = append(attrs, a)
+		case "srcset", "sizes", "loading", "decoding":
+			// Responsive variants are not embedded.
+		default:
+			attrs = append(attrs, a)
+		}
+	}
+
+	n.Attr = attrs
+
+	if img := b.image(src); img != nil {
+		n.Attr = append(n.Attr, html.Attribute{Key: "src", Val: img.File})
+		return
+	}
+
+	if n.Parent != nil {
+		n.Parent.InsertBefore(&html.Node{Type: html.TextNode, Data: alt}, n)
+		n.Parent.RemoveChild(n)
+	}
+}
+
+// image returns the embedded image for an img src, loading it on first use. It returns
+// nil for images that are not repository assets of a supported type or cannot be loaded.
+func (b *epubBook) image(src string) *epubImage {
+	if img, ok := b.images[src]; ok {
+		return img
+	}
+
+	u, err := url.Parse(src)
+	if err != nil {
+		return nil
+	}
+
+	assetPath, ok := strings.CutPrefix(u.Path, "/assets/"+b.repo+"/")
+	if !ok {
+		return nil
+	}
+
+	ext := strings.ToLower(path.Ext(assetPath))
+
+	mediaType, ok := epubImageTypes[ext]
+	if !ok {
+		return nil
+	}
+
+	data, err := b.asset(assetPath)
+	if err != nil {
+		return nil
+	}
+
+	img := &epubImage{File: "images/img" + strconv.Itoa(len(b.order)+1) + ext, MediaType: mediaType, Data: data}
+	b.images[src] = img
+	b.order = append(b.order, src)
+
+	return img
+}
+
+// nav returns the navigation document of the book, listing each chapter with its second
+// and third level headings.
+func (b *epubBook) nav() string {
+	var s strings.Builder
+
+	s.WriteString(`<nav epub:type="toc" id="toc"><h1>Contents</h1><ol>`)
+
+	for _, ch := range b.chapters {
+		fmt.Fprintf(&s, `<li><a href="%s">%s</a>`, ch.File, xmlEscape(ch.Title))
+
+		if len(ch.Headings) > 0 {
+			s.WriteString("<ol>")
+
+			for _, h := range ch.Headings {
+				fmt.Fprintf(&s, `<li><a href="%s#%s">%s</a></li>`, ch.File, xmlEscape(h.ID), xmlEscape(h.Text))
+			}
+
+			s.WriteString("</ol>")
+		}
+
+		s.WriteString("</li>")
+	}
+
+	s.WriteString("</ol></nav>")
+
+	return xhtmlPage(b.repo, s.String())
+}
+
+// packageDocument returns the OPF package document describing the book.
+func (b *epubBook) packageDocument() string {
+	var modified time.Time
+
+	for _, ch := range b.chapters {
+		if ch.Doc.UpdatedAt.After(modified) {
+			modified = ch.Doc.UpdatedAt
+		}
+	}
+
+	if modified.IsZero() {
+		modified = time.Now()
+	}
+
+	var manifest, spine strings.Builder
+
+	for i, ch := range b.chapters {
+		fmt.Fprintf(&manifest, `    <item id="ch%d" href="%s" media-type="application/xhtml+xml"/>`+"\n", i+1, ch.File)
+		fmt.Fprintf(&spine, `    <itemref idref="ch%d"/>`+"\n", i+1)
+	}
+
+	for i, src := range b.order {
+		img := b.images[src]
+		fmt.Fprintf(&manifest, `    <item id="img%d" href="%s" media-type="%s"/>`+"\n", i+1, img.File, img.MediaType)
+	}
+
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="book-id">urn:omnidex:` + xmlEscape(b.repo) + `</dc:identifier>
+    <dc:title>` + xmlEscape(b.repo) + `</dc:title>
+    <dc:language>en</dc:language>
+    <meta property="dcterms:modified">` + modified.UTC().Format("2006-01-02T15:04:05Z") + `</meta>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="css" href="style.css" media-type="text/css"/>
+` + manifest.String() + `  </manifest>
+  <spine>
+` + spine.String() + `  </spine>
+</package>
+`
+}
+
+// xhtmlPage wraps an XHTML body in a page of the book.
+func xhtmlPage(title, body string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="en">
+<head>
+<meta charset="UTF-8"/>
+<title>` + xmlEscape(title) + `</title>
+<link rel="stylesheet" type="text/css" href="style.css"/>
+</head>
+<body>
+` + body + `
+</body>
+</html>
+`
+}
+
+// xmlEscape escapes s for use in XML text and attribute values.
+func xmlEscape(s string) string {
+	var b strings.Builder
+
+	_ = xml.EscapeText(&b, []byte(s))
+
+	return b.String()
+}
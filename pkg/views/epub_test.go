@@ -0,0 +1,109 @@
+package views
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+func TestRenderEPUB(t *testing.T) {
+	r := New()
+
+	docs := []core.SectionDocument{
+		{
+			Doc: core.Document{Repo: "my-org/repo", Path: "guides/setup.md", Title: "Setup", UpdatedAt: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
+			HTML: []byte(`<h1 id="setup">Setup</h1><h2 id="install">Install</h2>` +
+				`<p>Read the <a href="../readme.md#intro">intro</a> &amp; <a href="https://example.com">site</a>.<br>` +
+				`<img src="/assets/my-org/repo/img/diagram.png" alt="Diagram" loading="lazy" srcset="/assets/my-org/repo/img/diagram.png?w=320 320w">` +
+				`<img src="/assets/my-org/repo/img/missing.png" alt="Missing chart"></p>`),
+			Headings: []core.Heading{{Level: 1, ID: "setup", Text: "Setup"}, {Level: 2, ID: "install", Text: "Install"}},
+		},
+		{
+			Doc:  core.Document{Repo: "my-org/repo", Path: "readme.md", Title: "Readme <Intro>", UpdatedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+			HTML: []byte(`<h1 id="intro">Intro</h1><p>Hello</p>`),
+		},
+	}
+
+	asset := func(path string) ([]byte, error) {
+		if path == "img/diagram.png" {
+			return []byte("PNGDATA"), nil
+		}
+
+		return nil, errors.New("not found")
+	}
+
+	var buf bytes.Buffer
+
+	require.NoError(t, r.RenderEPUB(&buf, "my-org/repo", docs, asset))
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	require.NotEmpty(t, zr.File)
+	assert.Equal(t, "mimetype", zr.File[0].Name)
+	assert.Equal(t, zip.Store, zr.File[0].Method)
+
+	files := make(map[string]string)
+
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+
+		files[f.Name] = string(data)
+
+		if strings.HasSuffix(f.Name, ".xhtml") || strings.HasSuffix(f.Name, ".opf") || strings.HasSuffix(f.Name, ".xml") {
+			assertWellFormedXML(t, f.Name, data)
+		}
+	}
+
+	assert.Equal(t, "application/epub+zip", files["mimetype"])
+	assert.Equal(t, "PNGDATA", files["OEBPS/images/img1.png"])
+
+	assert.Contains(t, files["OEBPS/ch1.xhtml"], "<title>Readme &lt;Intro&gt;</title>", "root documents come first, as in the sidebar")
+	assert.Contains(t, files["OEBPS/ch2.xhtml"], `<a href="ch1.xhtml#intro">intro</a>`)
+	assert.Contains(t, files["OEBPS/ch2.xhtml"], `<a href="https://example.com">site</a>`)
+	assert.Contains(t, files["OEBPS/ch2.xhtml"], `<br/>`)
+	assert.Contains(t, files["OEBPS/ch2.xhtml"], `<img alt="Diagram" src="images/img1.png"/>`)
+	assert.Contains(t, files["OEBPS/ch2.xhtml"], "Missing chart", "images that cannot be loaded are replaced by their alt text")
+	assert.NotContains(t, files["OEBPS/ch2.xhtml"], "missing.png")
+
+	opf := files["OEBPS/content.opf"]
+	assert.Contains(t, opf, `<dc:title>my-org/repo</dc:title>`)
+	assert.Contains(t, opf, `<meta property="dcterms:modified">2026-02-01T00:00:00Z</meta>`)
+	assert.Contains(t, opf, `<item id="img1" href="images/img1.png" media-type="image/png"/>`)
+	assert.Less(t, strings.Index(opf, `<itemref idref="ch1"/>`), strings.Index(opf, `<itemref idref="ch2"/>`))
+
+	assert.Contains(t, files["OEBPS/nav.xhtml"], `<a href="ch2.xhtml#install">Install</a>`)
+}
+
+// assertWellFormedXML fails the test if data is not well-formed XML.
+func assertWellFormedXML(t *testing.T, name string, data []byte) {
+	t.Helper()
+
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.Strict = true
+	dec.Entity = map[string]string{}
+
+	for {
+		_, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return
+		}
+
+		require.NoError(t, err, name)
+	}
+}
@@ -0,0 +1,76 @@
+package views
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+// FreshnessConfig configures the banner shown on documents that may be outdated. A zero
+// age disables the corresponding check.
+type FreshnessConfig struct {
+	// MaxDocumentAge flags documents whose published version is older than this.
+	MaxDocumentAge time.Duration `mapstructure:"max_document_age"`
+	// MaxRepoAge flags all documents of a repository that has had no publish for longer
+	// than this, e.g. an abandoned service.
+	MaxRepoAge time.Duration `mapstructure:"max_repo_age"`
+}
+
+// staleness explains why a document may be outdated.
+type staleness struct {
+	DocAge  string // time since the document was last published
+	RepoAge string // time since the repository was last published; set when the repository is stale
+}
+
+// checkFreshness returns why doc may be outdated, or nil if it is fresh. The last publish of
+// the repository is the latest update among navDocs, the documents of the repository.
+func (c FreshnessConfig) checkFreshness(doc *core.Document, navDocs []core.DocumentMeta, now time.Time) *staleness {
+	if doc.UpdatedAt.IsZero() {
+		return nil
+	}
+
+	repoUpdated := doc.UpdatedAt
+	for _, d := range navDocs {
+		if d.UpdatedAt.After(repoUpdated) {
+			repoUpdated = d.UpdatedAt
+		}
+	}
+
+	docAge, repoAge := now.Sub(doc.UpdatedAt), now.Sub(repoUpdated)
+
+	switch {
+	case c.MaxRepoAge > 0 && repoAge > c.MaxRepoAge:
+		return &staleness{DocAge: formatAge(docAge), RepoAge: formatAge(repoAge)}
+	case c.MaxDocumentAge > 0 && docAge > c.MaxDocumentAge:
+		return &staleness{DocAge: formatAge(docAge)}
+	default:
+		return nil
+	}
+}
+
+// formatAge formats d in the largest whole unit from hours to years, e.g. "3 months".
+func formatAge(d time.Duration) string {
+	const (
+		day   = 24 * time.Hour
+		month = 30 * day
+		year  = 365 * day
+	)
+
+	n, unit := int(d/time.Hour), "hour"
+
+	switch {
+	case d >= year:
+		n, unit = int(d/year), "year"
+	case d >= 2*month:
+		n, unit = int(d/month), "month"
+	case d >= 2*day:
+		n, unit = int(d/day), "day"
+	}
+
+	if n == 1 {
+		return "1 " + unit
+	}
+
+	return fmt.Sprintf("%d %ss", n, unit)
+}
@@ -0,0 +1,74 @@
+package views
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+func TestCheckFreshness(t *testing.T) {
+	const day = 24 * time.Hour
+
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	cfg := FreshnessConfig{MaxDocumentAge: 180 * day, MaxRepoAge: 90 * day}
+
+	tests := []struct {
+		want    *staleness
+		name    string
+		navDocs []core.DocumentMeta
+		cfg     FreshnessConfig
+		docAge  time.Duration
+	}{
+		{name: "fresh", cfg: cfg, docAge: 10 * day},
+		{
+			name:    "old document",
+			cfg:     cfg,
+			docAge:  200 * day,
+			navDocs: []core.DocumentMeta{{UpdatedAt: now.Add(-5 * day)}},
+			want:    &staleness{DocAge: "6 months"},
+		},
+		{
+			name:    "stale repository",
+			cfg:     cfg,
+			docAge:  100 * day,
+			navDocs: []core.DocumentMeta{{UpdatedAt: now.Add(-95 * day)}},
+			want:    &staleness{DocAge: "3 months", RepoAge: "3 months"},
+		},
+		{name: "disabled", docAge: 400 * day},
+		{name: "unknown publish time", cfg: cfg},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := core.Document{Repo: "owner/repo", Path: "a.md"}
+			if tt.docAge > 0 {
+				doc.UpdatedAt = now.Add(-tt.docAge)
+			}
+
+			assert.Equal(t, tt.want, tt.cfg.checkFreshness(&doc, tt.navDocs, now))
+		})
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	const day = 24 * time.Hour
+
+	tests := []struct {
+		want string
+		age  time.Duration
+	}{
+		{want: "1 hour", age: time.Hour},
+		{want: "47 hours", age: 47 * time.Hour},
+		{want: "45 days", age: 45 * day},
+		{want: "2 months", age: 65 * day},
+		{want: "1 year", age: 400 * day},
+		{want: "3 years", age: 3 * 366 * day},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, formatAge(tt.age))
+	}
+}
@@ -25,7 +25,8 @@ type docEntry struct {
 }
 
 // BuildDocTree converts a flat list of DocumentMeta into a directory tree.
-// Root-level documents (no "/" in path) appear first, sorted alphabetically by path.
+// Root-level documents (no "/" in path) appear first: those with an order set, by
+// ascending order, then the others, sorted alphabetically by path within each.
 // Subdirectory groups appear after, sorted alphabetically by folder name.
 // Supports arbitrary nesting depth via recursion.
 // The original DocumentMeta values (including full paths) are never mutated.
@@ -66,7 +67,7 @@ func buildTree(entries []docEntry) []DocNode {
 	}
 
 	sort.Slice(rootEntries, func(i, j int) bool {
-		return rootEntries[i].doc.Path < rootEntries[j].doc.Path
+		return docBefore(rootEntries[i].doc, rootEntries[j].doc)
 	})
 
 	nodes := make([]DocNode, 0, len(rootEntries)+len(folderGroups))
@@ -94,3 +95,17 @@ func buildTree(entries []docEntry) []DocNode {
 
 	return nodes
 }
+
+// docBefore reports whether document a is listed before b in its folder. Documents whose
+// order is set come before those without, by ascending order, and ties are broken by path.
+func docBefore(a, b *core.DocumentMeta) bool {
+	if (a.Order != 0) != (b.Order != 0) {
+		return a.Order != 0
+	}
+
+	if a.Order != b.Order {
+		return a.Order < b.Order
+	}
+
+	return a.Path < b.Path
+}
@@ -225,3 +225,37 @@ func TestBuildDocTree_DeepNestingThreeLevels(t *testing.T) {
 	assert.Equal(t, "deep.md", result[0].Children[0].Children[0].Children[0].Name)
 	assert.NotNil(t, result[0].Children[0].Children[0].Children[0].Doc)
 }
+
+func TestBuildDocTree_Order(t *testing.T) {
+	ordered := func(path string, order int) core.DocumentMeta {
+		doc := meta(path)
+		doc.Order = order
+
+		return doc
+	}
+
+	docs := []core.DocumentMeta{
+		meta("changelog.md"),
+		ordered("install.md", 2),
+		meta("api.md"),
+		ordered("intro.md", 1),
+		ordered("guides/advanced.md", 1),
+		meta("guides/basics.md"),
+	}
+
+	result := BuildDocTree(docs)
+
+	require.Len(t, result, 5)
+
+	// Ordered documents come first, the rest alphabetically.
+	names := make([]string, 0, len(result))
+	for _, n := range result {
+		names = append(names, n.Name)
+	}
+
+	assert.Equal(t, []string{"intro.md", "install.md", "api.md", "changelog.md", "guides"}, names)
+
+	require.Len(t, result[4].Children, 2)
+	assert.Equal(t, "advanced.md", result[4].Children[0].Name)
+	assert.Equal(t, "basics.md", result[4].Children[1].Name)
+}
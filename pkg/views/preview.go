@@ -0,0 +1,138 @@
+package views
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+const (
+	// maxPreviewBlocks is the number of top-level blocks of a document shown in its preview.
+	maxPreviewBlocks = 3
+	// maxPreviewText is the amount of text, in runes, after which no further blocks are added
+	// to a preview.
+	maxPreviewText = 500
+)
+
+// previewBlocks lists the top-level elements that may appear in a preview excerpt. Other
+// blocks, such as tables, diagrams and images, are too large for a hover card.
+var previewBlocks = map[atom.Atom]bool{
+	atom.P:          true,
+	atom.Ul:         true,
+	atom.Ol:         true,
+	atom.Blockquote: true,
+	atom.Pre:        true,
+	atom.H2:         true,
+	atom.H3:         true,
+}
+
+// previewData is the data passed to the preview template.
+type previewData struct {
+	Excerpt string
+	Doc     core.Document
+}
+
+// RenderPreview renders the hover preview of a document: its title, location and the first
+// blocks of its rendered HTML for prose documents, markdown and AsciiDoc, or its summary
+// for other content types.
+func (v *Renderer) RenderPreview(w io.Writer, doc core.Document, htmlBody []byte) error { //nolint:gocritic // Document is passed by value like RenderDoc
+	data := previewData{Doc: doc}
+
+	switch doc.ContentType {
+	case "", core.ContentTypeMarkdown, core.ContentTypeAsciiDoc:
+		data.Excerpt = previewExcerpt(htmlBody)
+	}
+
+	return execTemplate(w, v.preview, data)
+}
+
+// previewExcerpt returns the first top-level blocks of a rendered, sanitized document,
+// after its title, up to maxPreviewBlocks blocks or maxPreviewText runes of text. Images
+// and id attributes are dropped, so the excerpt neither loads media nor duplicates the
+// anchors of the page it is shown on.
+func previewExcerpt(htmlBody []byte) string {
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+
+	nodes, err := html.ParseFragment(bytes.NewReader(htmlBody), body)
+	if err != nil {
+		return ""
+	}
+
+	var (
+		buf    strings.Builder
+		blocks int
+		text   int
+	)
+
+	for _, n := range nodes {
+		if blocks == maxPreviewBlocks || text >= maxPreviewText {
+			break
+		}
+
+		if n.Type != html.ElementNode || !previewBlocks[n.DataAtom] {
+			continue
+		}
+
+		stripPreviewNode(n)
+
+		content := nodeText(n)
+		if strings.TrimSpace(content) == "" {
+			continue
+		}
+
+		if err := html.Render(&buf, n); err != nil {
+			return ""
+		}
+
+		blocks++
+		text += utf8.RuneCountInString(content)
+	}
+
+	return buf.String()
+}
+
+// stripPreviewNode removes the images and id attributes of n and its descendants.
+func stripPreviewNode(n *html.Node) {
+	attrs := n.Attr[:0]
+
+	for _, a := range n.Attr {
+		if a.Key != "id" {
+			attrs = append(attrs, a)
+		}
+	}
+
+	n.Attr = attrs
+
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+
+		if c.Type == html.ElementNode && (c.DataAtom == atom.Img || c.DataAtom == atom.Svg) {
+			n.RemoveChild(c)
+		} else {
+			stripPreviewNode(c)
+		}
+
+		c = next
+	}
+}
+
+// nodeText returns the text content of n.
+func nodeText(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+
+	var b strings.Builder
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(nodeText(c))
+	}
+
+	return b.String()
+}
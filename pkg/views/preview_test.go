@@ -0,0 +1,115 @@
+package views
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+func TestPreviewExcerpt(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			name: "skips the title and stops after three blocks",
+			html: `<h1 id="guide">Guide</h1><p>One</p><h2 id="setup">Setup</h2><ul><li>Two</li></ul><p>Three</p>`,
+			want: `<p>One</p><h2>Setup</h2><ul><li>Two</li></ul>`,
+		},
+		{
+			name: "drops images and skips large blocks",
+			html: `<p><img src="/assets/a.png" alt="diagram"/></p><table><tr><td>x</td></tr></table><p>Text <img src="b.png"/>here</p>`,
+			want: `<p>Text here</p>`,
+		},
+		{
+			name: "stops once the text is long enough",
+			html: `<p>` + strings.Repeat("a", maxPreviewText) + `</p><p>Next</p>`,
+			want: `<p>` + strings.Repeat("a", maxPreviewText) + `</p>`,
+		},
+		{
+			name: "empty",
+			html: ``,
+			want: ``,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, previewExcerpt([]byte(tt.html)))
+		})
+	}
+}
+
+func TestRenderPreview(t *testing.T) {
+	r := New()
+
+	doc := core.Document{
+		Repo:      "my-org/repo",
+		Path:      "guide.md",
+		Title:     "Guide <b>",
+		Summary:   "Set up the CLI.",
+		UpdatedAt: time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC),
+	}
+
+	var buf bytes.Buffer
+
+	require.NoError(t, r.RenderPreview(&buf, doc, []byte(`<h1 id="guide">Guide</h1><p>Install it with <code>go install</code>.</p>`)))
+
+	output := buf.String()
+	assert.NotContains(t, output, "<!DOCTYPE html>")
+	assert.Contains(t, output, "Guide &lt;b&gt;")
+	assert.Contains(t, output, "my-org/repo/guide.md")
+	assert.Contains(t, output, "<p>Install it with <code>go install</code>.</p>")
+	assert.NotContains(t, output, "Set up the CLI.", "the excerpt replaces the summary")
+	assert.Contains(t, output, "Updated Jun 15, 2025")
+
+	buf.Reset()
+
+	doc.ContentType = core.ContentTypeAsciiDoc
+	require.NoError(t, r.RenderPreview(&buf, doc, []byte(`<h1 id="_guide">Guide</h1><p>Install it with <code>go install</code>.</p>`)))
+	assert.Contains(t, buf.String(), "<p>Install it with <code>go install</code>.</p>", "AsciiDoc documents show an excerpt")
+
+	buf.Reset()
+
+	doc.ContentType = core.ContentTypeOpenAPI
+	require.NoError(t, r.RenderPreview(&buf, doc, []byte(`{"openapi":"3.0.3"}`)))
+	assert.Contains(t, buf.String(), "Set up the CLI.", "documents without HTML show their summary")
+	assert.NotContains(t, buf.String(), "openapi")
+}
+
+func TestRenderSearch_PreviewLinks(t *testing.T) {
+	r := New()
+
+	results := &core.SearchResults{
+		Hits:  []core.SearchResult{{Repo: "org/repo", Path: "guide.md", Title: "User Guide"}},
+		Total: 1,
+	}
+
+	var buf bytes.Buffer
+
+	require.NoError(t, r.RenderSearch(&buf, "guide", core.SearchOpts{}, results, nil, true))
+	assert.Contains(t, buf.String(), `data-preview="/preview/org/repo/guide.md"`)
+}
+
+func TestRenderDoc_SidebarPreviewLinks(t *testing.T) {
+	r := New()
+
+	doc := core.Document{Repo: "my-org/repo", Path: "guide.md", Title: "Guide"}
+	navDocs := []core.DocumentMeta{
+		{Repo: "my-org/repo", Path: "guide.md", Title: "Guide"},
+		{Repo: "my-org/repo", Path: "faq.md", Title: "FAQ"},
+	}
+
+	var buf bytes.Buffer
+
+	require.NoError(t, r.RenderDoc(&buf, doc, []byte("<p>Body</p>"), nil, navDocs, nil, true))
+	assert.Contains(t, buf.String(), `data-preview="/preview/my-org/repo/faq.md"`)
+	assert.NotContains(t, buf.String(), `data-preview="/preview/my-org/repo/guide.md"`, "the current document has no preview")
+}
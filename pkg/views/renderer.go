@@ -6,7 +6,10 @@ import (
 	"html/template"
 	"io"
 	"net/url"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/microcosm-cc/bluemonday"
 
@@ -14,21 +17,37 @@ import (
 )
 
 // githubBlobURL constructs a GitHub blob URL for viewing a file at a specific commit.
-// If commitSHA is empty, it falls back to the "main" branch.
-// Each segment of path is percent-encoded to handle spaces and reserved characters
-// (e.g. '#', '?') while preserving the slash separators.
+// If commitSHA is empty, it falls back to the "main" branch. The path is escaped with
+// escapePath.
 func githubBlobURL(repo, path, commitSHA string) string {
 	ref := commitSHA
 	if ref == "" {
 		ref = "main"
 	}
 
+	return "https://github.com/" + repo + "/blob/" + ref + "/" + escapePath(path)
+}
+
+// escapePath percent-encodes each segment of a slash-separated path, so that document
+// paths with spaces, Unicode or reserved characters (e.g. '#', '?') can be used in URLs.
+func escapePath(path string) string {
 	segments := strings.Split(path, "/")
 	for i, seg := range segments {
 		segments[i] = url.PathEscape(seg)
 	}
 
-	return "https://github.com/" + repo + "/blob/" + ref + "/" + strings.Join(segments, "/")
+	return strings.Join(segments, "/")
+}
+
+// tagSearchURL returns the URL of the search for the documents filed under a tag, quoting
+// tags made of several words.
+func tagSearchURL(tag string) template.URL {
+	query := "tag:" + tag
+	if strings.ContainsAny(tag, " \t") {
+		query = `tag:"` + tag + `"`
+	}
+
+	return template.URL("/search?q=" + url.QueryEscape(query)) //nolint:gosec // the query is escaped
 }
 
 // fragmentPolicy is a bluemonday policy that allows only <mark> tags in search fragments.
@@ -42,28 +61,198 @@ var fragmentPolicy = func() *bluemonday.Policy {
 
 // Renderer renders HTML views for the documentation portal.
 type Renderer struct {
-	homeFull          *template.Template
-	homePartial       *template.Template
-	repoIndexFull     *template.Template
-	repoIndexPartial  *template.Template
-	docFull           *template.Template
-	docPartial        *template.Template
-	openapiDocFull    *template.Template
-	openapiDocPartial *template.Template
-	searchFull        *template.Template
-	searchPartial     *template.Template
-	searchResults     *template.Template
-	notFoundFull      *template.Template
+	homeFull           *template.Template
+	homePartial        *template.Template
+	repoIndexFull      *template.Template
+	repoIndexPartial   *template.Template
+	docFull            *template.Template
+	docPartial         *template.Template
+	openapiDocFull     *template.Template
+	openapiDocPartial  *template.Template
+	searchFull         *template.Template
+	searchPartial      *template.Template
+	searchResults      *template.Template
+	repoSearchFull     *template.Template
+	repoSearchPartial  *template.Template
+	searchSuggestions  *template.Template
+	statsFull          *template.Template
+	statsPartial       *template.Template
+	inboxFull          *template.Template
+	inboxPartial       *template.Template
+	inboxLink          *template.Template
+	notFoundFull       *template.Template
+	maintenanceFull    *template.Template
+	maintenancePartial *template.Template
+	sectionPrint       *template.Template
+	preview            *template.Template
+	editor             *template.Template
+	suggestion         *template.Template
+	typeLabels         map[string]string
+	freshness          FreshnessConfig
+	semantic           bool
+}
+
+// defaultSiteName is the site name shown when none is configured.
+const defaultSiteName = "Omnidex"
+
+// defaultKaTeXURL is the KaTeX distribution formulas are typeset with when none is configured.
+const defaultKaTeXURL = "https://cdn.jsdelivr.net/npm/katex@0.16.22/dist"
+
+// Option configures a Renderer.
+type Option func(*rendererOptions)
+
+type rendererOptions struct {
+	editable    map[string]bool
+	suggestible map[string]bool
+	banner      func() *core.PageBanner
+	siteName    string
+	katexURL    string
+	types       []core.ContentTypeInfo
+	freshness   FreshnessConfig
+	semantic    bool
+	noScripts   bool
+	inbox       bool
+}
+
+// WithSiteName sets the site name shown in the page header and titles in place of
+// "Omnidex", such as the name of a team portal served on its own hostname.
+func WithSiteName(name string) Option {
+	return func(o *rendererOptions) {
+		if name != "" {
+			o.siteName = name
+		}
+	}
+}
+
+// WithFreshness shows a banner on documents that may be outdated because they, or their
+// repository, were last published longer ago than configured.
+func WithFreshness(cfg FreshnessConfig) Option {
+	return func(o *rendererOptions) {
+		o.freshness = cfg
+	}
+}
+
+// WithEditableRepos shows an Edit link on the documents of the given repositories, which
+// opens them in the web editor.
+func WithEditableRepos(repos ...string) Option {
+	return func(o *rendererOptions) {
+		if o.editable == nil {
+			o.editable = make(map[string]bool, len(repos))
+		}
+
+		for _, r := range repos {
+			o.editable[r] = true
+		}
+	}
+}
+
+// WithSuggestibleRepos shows a "Suggest an edit" link on the documents of the given
+// repositories, which lets readers propose a change as a pull request.
+func WithSuggestibleRepos(repos ...string) Option {
+	return func(o *rendererOptions) {
+		if o.suggestible == nil {
+			o.suggestible = make(map[string]bool, len(repos))
+		}
+
+		for _, r := range repos {
+			o.suggestible[r] = true
+		}
+	}
+}
+
+// WithSemanticSearch shows the search modes on the search page, letting readers rank results
+// by keyword, by closeness in meaning to the query or by both, when enabled is set.
+func WithSemanticSearch(enabled bool) Option {
+	return func(o *rendererOptions) {
+		o.semantic = enabled
+	}
+}
+
+// WithNotifications shows a link to the inbox of signed-in readers, with their unread
+// count, in the page header when enabled is set. It is left out for readers who have not
+// signed in.
+func WithNotifications(enabled bool) Option {
+	return func(o *rendererOptions) {
+		o.inbox = enabled
+	}
+}
+
+// WithoutInlineScripts leaves the inline scripts, and the scripts they initialize, out of
+// every page, for deployments whose Content-Security-Policy forbids inline scripts. Pages
+// then work as they do without JavaScript: controls that need scripts, such as the theme
+// toggle, reading settings and the web editor, are hidden, and navigation falls back to
+// regular links and form submissions where htmx is unavailable.
+func WithoutInlineScripts(disabled bool) Option {
+	return func(o *rendererOptions) {
+		o.noScripts = disabled
+	}
+}
+
+// WithKaTeXURL sets the base URL of the KaTeX distribution, the directory holding
+// katex.min.js, katex.min.css and their fonts, that formulas in documents are typeset with,
+// so that it can be served from the instance's own network instead of a public CDN.
+func WithKaTeXURL(url string) Option {
+	return func(o *rendererOptions) {
+		if url != "" {
+			o.katexURL = strings.TrimSuffix(url, "/")
+		}
+	}
+}
+
+// WithSiteBanner shows the banner returned by banner, when it returns one, at the top of
+// every full page. It is called on every full page render, so it should be cheap.
+func WithSiteBanner(banner func() *core.PageBanner) Option {
+	return func(o *rendererOptions) {
+		o.banner = banner
+	}
+}
+
+// WithContentTypes sets the content types the server processes, shown by their display
+// names and icons on the search page, in document listings and in the navigation sidebar.
+// Without it, the default content types are used.
+func WithContentTypes(types []core.ContentTypeInfo) Option {
+	return func(o *rendererOptions) {
+		o.types = types
+	}
 }
 
 // New creates a new view Renderer with all templates parsed.
-func New() *Renderer {
-	const tocIndentDefault = "pl-3"
+func New(opts ...Option) *Renderer {
+	const (
+		tocIndentDefault = "pl-3"
+		shortSHALen      = 7
+	)
+
+	o := rendererOptions{siteName: defaultSiteName, katexURL: defaultKaTeXURL, types: core.DefaultContentTypes()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	typeLabels := make(map[string]string, len(o.types))
+	for _, t := range o.types {
+		typeLabels[string(t.Name)] = t.DisplayName
+	}
+
+	badges := newTypeBadges(o.types)
 
 	funcMap := template.FuncMap{
+		"siteName": func() string {
+			return o.siteName
+		},
+		// siteBanner returns the site banner to show at the top of the page, or nil.
+		"siteBanner": func() *core.PageBanner {
+			if o.banner == nil {
+				return nil
+			}
+
+			return o.banner()
+		},
 		"html": func(s string) template.HTML {
 			return template.HTML(s) //nolint:gosec // trusted content from markdown renderer
 		},
+		"structuredData": func(doc core.Document) (template.JS, error) { //nolint:gocritic // called from templates
+			return docStructuredData(&doc, o.siteName)
+		},
 		"safeJS": func(s string) template.JS {
 			return template.JS(s) //nolint:gosec // trusted JSON from OpenAPI processor
 		},
@@ -83,6 +272,49 @@ func New() *Renderer {
 			}
 		},
 		"githubURL": githubBlobURL,
+		// editable reports whether the documents of a repository can be edited in the portal.
+		"editable": func(repo string) bool {
+			return o.editable[repo]
+		},
+		// suggestible reports whether readers can suggest edits to the documents of a repository.
+		"suggestible": func(repo string) bool {
+			return o.suggestible[repo]
+		},
+		// inlineScripts reports whether pages include their inline scripts.
+		"inlineScripts": func() bool {
+			return !o.noScripts
+		},
+		// notifications reports whether the page header loads the link to the reader's inbox.
+		"notifications": func() bool {
+			return o.inbox
+		},
+		// katexURL returns the base URL KaTeX is loaded from.
+		"katexURL": func() string {
+			return o.katexURL
+		},
+		// urlPath escapes a document path for use in portal URLs.
+		"urlPath": escapePath,
+		// tagSearchURL links a document tag to a search for it.
+		"tagSearchURL": tagSearchURL,
+		// resultRank returns the 1-based rank of the i-th hit of a results page starting
+		// at offset.
+		"resultRank": func(offset, i int) int {
+			return offset + i + 1
+		},
+		// percent scales a ratio to a percentage.
+		"percent": func(ratio float64) float64 {
+			return ratio * 100
+		},
+		// typeIcon and typeBadge mark the content type of a document in listings, the
+		// sidebar and search results.
+		"typeIcon":  badges.icon,
+		"typeBadge": badges.badge,
+		// embedType returns the media type of binary content types, whose documents are
+		// embedded in an inline viewer above their extracted text, or an empty string.
+		"embedType": badges.embedType,
+		"shortSHA": func(sha string) string {
+			return sha[:min(len(sha), shortSHALen)]
+		},
 		// sidebarNav builds a sidebarCtx from a node slice and current path, used to
 		// initialise the sidebarDocTree recursive sub-template from the outer template.
 		"sidebarNav": newSidebarCtx,
@@ -94,18 +326,35 @@ func New() *Renderer {
 	}
 
 	return &Renderer{
-		homeFull:          template.Must(template.New("home_full").Funcs(funcMap).Parse(layoutHeader + homeContentBody + layoutFooter)),
-		homePartial:       template.Must(template.New("home_partial").Funcs(funcMap).Parse(homeContentBody)),
-		repoIndexFull:     template.Must(template.New("repo_index_full").Funcs(funcMap).Parse(layoutHeader + repoIndexContentBody + layoutFooter + repoDocTreeSubTemplate)),
-		repoIndexPartial:  template.Must(template.New("repo_index_partial").Funcs(funcMap).Parse(repoIndexContentBody + repoDocTreeSubTemplate)),
-		docFull:           template.Must(template.New("doc_full").Funcs(funcMap).Parse(layoutHeader + docContentBody + layoutFooter + sidebarDocTreeSubTemplate)),
-		docPartial:        template.Must(template.New("doc_partial").Funcs(funcMap).Parse(docContentBody + sidebarDocTreeSubTemplate)),
-		openapiDocFull:    template.Must(template.New("openapi_doc_full").Funcs(funcMap).Parse(layoutHeader + openapiDocContentBody + layoutFooter + sidebarDocTreeSubTemplate)),
-		openapiDocPartial: template.Must(template.New("openapi_doc_partial").Funcs(funcMap).Parse(openapiDocContentBody + sidebarDocTreeSubTemplate)),
-		searchFull:        template.Must(template.New("search_full").Funcs(funcMap).Parse(layoutHeader + searchContentBody + layoutFooter)),
-		searchPartial:     template.Must(template.New("search_partial").Funcs(funcMap).Parse(searchContentBody)),
-		searchResults:     template.Must(template.New("search_results").Funcs(funcMap).Parse(searchResultsBody)),
-		notFoundFull:      template.Must(template.New("notfound").Funcs(funcMap).Parse(layoutHeader + notFoundBody + layoutFooter)),
+		homeFull:           template.Must(template.New("home_full").Funcs(funcMap).Parse(layoutHeader + homeContentBody + layoutFooter)),
+		homePartial:        template.Must(template.New("home_partial").Funcs(funcMap).Parse(homeContentBody)),
+		repoIndexFull:      template.Must(template.New("repo_index_full").Funcs(funcMap).Parse(layoutHeader + repoIndexContentBody + layoutFooter + repoDocTreeSubTemplate + repoSearchBoxSubTemplate + announcementBannerSubTemplate)),
+		repoIndexPartial:   template.Must(template.New("repo_index_partial").Funcs(funcMap).Parse(repoIndexContentBody + repoDocTreeSubTemplate + repoSearchBoxSubTemplate + announcementBannerSubTemplate)),
+		docFull:            template.Must(template.New("doc_full").Funcs(funcMap).Parse(layoutHeader + docContentBody + layoutFooter + sidebarDocTreeSubTemplate + provenanceBadgeSubTemplate + announcementBannerSubTemplate + freshnessBannerSubTemplate + structuredDataSubTemplate + repoSearchBoxSubTemplate)),
+		docPartial:         template.Must(template.New("doc_partial").Funcs(funcMap).Parse(docContentBody + sidebarDocTreeSubTemplate + provenanceBadgeSubTemplate + announcementBannerSubTemplate + freshnessBannerSubTemplate + structuredDataSubTemplate + repoSearchBoxSubTemplate)),
+		openapiDocFull:     template.Must(template.New("openapi_doc_full").Funcs(funcMap).Parse(layoutHeader + openapiDocContentBody + layoutFooter + sidebarDocTreeSubTemplate + provenanceBadgeSubTemplate + announcementBannerSubTemplate + freshnessBannerSubTemplate + structuredDataSubTemplate + repoSearchBoxSubTemplate)),
+		openapiDocPartial:  template.Must(template.New("openapi_doc_partial").Funcs(funcMap).Parse(openapiDocContentBody + sidebarDocTreeSubTemplate + provenanceBadgeSubTemplate + announcementBannerSubTemplate + freshnessBannerSubTemplate + structuredDataSubTemplate + repoSearchBoxSubTemplate)),
+		searchFull:         template.Must(template.New("search_full").Funcs(funcMap).Parse(layoutHeader + searchContentBody + layoutFooter)),
+		searchPartial:      template.Must(template.New("search_partial").Funcs(funcMap).Parse(searchContentBody)),
+		searchResults:      template.Must(template.New("search_results").Funcs(funcMap).Parse(searchResultsBody)),
+		repoSearchFull:     template.Must(template.New("repo_search_full").Funcs(funcMap).Parse(layoutHeader + repoSearchContentBody + layoutFooter)),
+		repoSearchPartial:  template.Must(template.New("repo_search_partial").Funcs(funcMap).Parse(repoSearchContentBody)),
+		searchSuggestions:  template.Must(template.New("search_suggestions").Funcs(funcMap).Parse(searchSuggestionsBody)),
+		statsFull:          template.Must(template.New("stats_full").Funcs(funcMap).Parse(layoutHeader + statsContentBody + layoutFooter)),
+		statsPartial:       template.Must(template.New("stats_partial").Funcs(funcMap).Parse(statsContentBody)),
+		inboxFull:          template.Must(template.New("notifications_full").Funcs(funcMap).Parse(layoutHeader + notificationsContentBody + layoutFooter)),
+		inboxPartial:       template.Must(template.New("notifications_partial").Funcs(funcMap).Parse(notificationsContentBody)),
+		inboxLink:          template.Must(template.New("notifications_link").Funcs(funcMap).Parse(notificationsLinkBody)),
+		notFoundFull:       template.Must(template.New("notfound").Funcs(funcMap).Parse(layoutHeader + notFoundBody + layoutFooter)),
+		maintenanceFull:    template.Must(template.New("maintenance_full").Funcs(funcMap).Parse(layoutHeader + maintenanceBody + layoutFooter)),
+		maintenancePartial: template.Must(template.New("maintenance_partial").Funcs(funcMap).Parse(maintenanceBody)),
+		sectionPrint:       template.Must(template.New("section_print").Funcs(funcMap).Parse(sectionPrintBody)),
+		preview:            template.Must(template.New("preview").Funcs(funcMap).Parse(previewBody)),
+		editor:             template.Must(template.New("editor").Funcs(funcMap).Parse(layoutHeader + editorBody + layoutFooter)),
+		suggestion:         template.Must(template.New("suggestion").Funcs(funcMap).Parse(layoutHeader + suggestionBody + layoutFooter)),
+		typeLabels:         typeLabels,
+		freshness:          o.freshness,
+		semantic:           o.semantic,
 	}
 }
 
@@ -127,14 +376,34 @@ func (v *Renderer) RenderHome(w io.Writer, repos []core.RepoInfo, partial bool)
 }
 
 // repoIndexData is the data passed to the repo index page template.
+// Landing is nil when the repository has no landing document.
 type repoIndexData struct {
-	Repo string
-	Docs []DocNode
+	Landing      *core.Document
+	Repo         string
+	LandingHTML  string
+	Announcement string // rendered announcement of the repository's owners
+	Docs         []DocNode
+	Pinned       []core.DocumentMeta
+	FilesTab     bool
 }
 
-// RenderRepoIndex renders the repository index page with documents grouped by directory tree.
-func (v *Renderer) RenderRepoIndex(w io.Writer, repo string, docs []core.DocumentMeta, partial bool) error {
-	data := repoIndexData{Repo: repo, Docs: BuildDocTree(docs)}
+// RenderRepoIndex renders the repository index page. When the repository has a landing
+// document, the page shows it under an Overview tab, with the documents grouped by
+// directory tree under a Files tab; filesTab selects the Files tab. Otherwise only the
+// document tree is shown. The announcement and pinned documents of highlights, which may
+// be nil, are shown above either.
+func (v *Renderer) RenderRepoIndex(w io.Writer, repo string, docs []core.DocumentMeta, landing *core.RepoLanding, highlights *core.PageHighlights, filesTab, partial bool) error {
+	data := repoIndexData{Repo: repo, Docs: BuildDocTree(docs), FilesTab: filesTab}
+
+	if landing != nil {
+		data.Landing = &landing.Doc
+		data.LandingHTML = string(landing.HTML)
+	}
+
+	if highlights != nil {
+		data.Announcement = string(highlights.AnnouncementHTML)
+		data.Pinned = highlights.Pinned
+	}
 
 	tmpl := v.repoIndexFull
 	if partial {
@@ -156,23 +425,40 @@ func newSidebarCtx(nodes []DocNode, currentPath string) sidebarCtx {
 	return sidebarCtx{Nodes: nodes, CurrentPath: currentPath}
 }
 
+// resumeMinLines is the source length, in lines, from which a document page remembers the
+// reader's position and offers to resume reading there.
+const resumeMinLines = 200
+
 type docData struct {
-	Doc         core.Document
-	HTML        string
-	CurrentPath string
-	Headings    []core.Heading
-	NavDocs     []DocNode
+	Stale        *staleness // set when the document may be outdated
+	HTML         string
+	CurrentPath  string
+	Announcement string // rendered announcement of the repository's owners
+	Headings     []core.Heading
+	NavDocs      []DocNode
+	Doc          core.Document
+	// TrackProgress enables the resume-reading prompt for long documents.
+	TrackProgress bool
 }
 
-// RenderDoc renders a document page with sidebar navigation and table of contents.
+// RenderDoc renders a document page with sidebar navigation and table of contents, below
+// the announcement of highlights when it is not nil.
 // For OpenAPI documents, it renders the Scalar API Reference template instead of the markdown prose template.
-func (v *Renderer) RenderDoc(w io.Writer, doc core.Document, html []byte, headings []core.Heading, navDocs []core.DocumentMeta, partial bool) error { //nolint:gocritic // Document is passed by value for immutability
+func (v *Renderer) RenderDoc(w io.Writer, doc core.Document, html []byte, headings []core.Heading, navDocs []core.DocumentMeta, highlights *core.PageHighlights, partial bool) error { //nolint:gocritic // Document is passed by value for immutability
 	data := docData{
 		Doc:         doc,
 		HTML:        string(html),
 		Headings:    headings,
 		NavDocs:     BuildDocTree(navDocs),
 		CurrentPath: doc.Path,
+		// Positions are restored relative to heading anchors, so documents without
+		// headings are not tracked.
+		TrackProgress: len(headings) > 0 && strings.Count(doc.Content, "\n") >= resumeMinLines,
+		Stale:         v.freshness.checkFreshness(&doc, navDocs, time.Now()),
+	}
+
+	if highlights != nil {
+		data.Announcement = string(highlights.AnnouncementHTML)
 	}
 
 	tmpl := v.selectDocTemplate(doc.ContentType, partial)
@@ -197,17 +483,133 @@ func (v *Renderer) selectDocTemplate(ct core.ContentType, partial bool) *templat
 	return v.docFull
 }
 
-// searchData is the data passed to the search page template.
+// searchData is the data passed to the search page template. Modes is empty when semantic
+// search is not configured. Repos lists the repositories offered by the repository filter.
+// Mode and Types are the mode and content type filters requested in the URL, kept when
+// the repository filter changes. Pages is empty when the results fit on a single page.
 type searchData struct {
-	Results *core.SearchResults
-	Query   string
+	Results       *core.SearchResults
+	Query         string
+	CodeToggleURL string
+	SuggestionURL string // runs the corrected query offered when nothing was found
+	Repo          string
+	Mode          string
+	PrevURL       string
+	NextURL       string
+	LangFacets    []langFacetLink
+	TypeFacets    []typeFacetLink
+	Modes         []searchModeLink
+	Repos         []string
+	AllReposURL   string // runs the query across all repositories from a repository search page
+	Types         []string
+	Pages         []pageLink
+	Offset        int // number of results on the pages before this one
+	CodeOnly      bool
+	Semantic      bool
 }
 
-// RenderSearch renders the search page with results.
-func (v *Renderer) RenderSearch(w io.Writer, query string, results *core.SearchResults, partial bool) error {
-	data := searchData{
-		Query:   query,
-		Results: results,
+// pageLink is a page number shown in the search page navigation. Gap marks skipped pages.
+type pageLink struct {
+	URL    string
+	Number int
+	Active bool
+	Gap    bool
+}
+
+// searchPageWindow is the number of pages linked on each side of the current page.
+const searchPageWindow = 2
+
+// searchModeLink is a search mode shown on the search page. URL runs the query in the mode.
+type searchModeLink struct {
+	Label  string
+	URL    string
+	Active bool
+}
+
+// searchModes lists the search modes shown on the search page with their labels.
+var searchModes = []struct {
+	label string
+	mode  core.SearchMode
+}{
+	{"Hybrid", core.SearchModeHybrid},
+	{"Keyword", core.SearchModeKeyword},
+	{"By meaning", core.SearchModeSemantic},
+}
+
+// langFacetLink is a code language filter shown on the search page. URL toggles the
+// filter: it applies the language, or removes it when the language is already active.
+type langFacetLink struct {
+	Lang   string
+	URL    string
+	Count  int
+	Active bool
+}
+
+// typeFacetLink is a content type filter shown on the search page. URL toggles the
+// content type in the active filters.
+type typeFacetLink struct {
+	Label  string
+	URL    string
+	Count  int
+	Active bool
+}
+
+// searchParams are the parameters of a search page URL.
+type searchParams struct {
+	text     string // query text without the language filter
+	lang     string
+	repo     string
+	scope    string // repository searched by a repository search page; empty for /search
+	mode     core.SearchMode
+	types    []core.ContentType
+	page     int // 1-based; the first page is left out of the URL
+	codeOnly bool
+}
+
+// url returns the search page URL for the parameters. Hybrid is the default mode, so it
+// is left out of the URL.
+func (p *searchParams) url() string {
+	q := strings.TrimSpace(p.text)
+	if p.lang != "" {
+		q = strings.TrimSpace(q + " lang:" + p.lang)
+	}
+
+	v := url.Values{"q": {q}}
+	if p.repo != "" && p.scope == "" {
+		v.Set("repo", p.repo)
+	}
+
+	if p.mode != "" && p.mode != core.SearchModeHybrid {
+		v.Set("mode", string(p.mode))
+	}
+
+	for _, ct := range p.types {
+		v.Add("type", string(ct))
+	}
+
+	if p.codeOnly {
+		v.Set("code", "1")
+	}
+
+	if p.page > 1 {
+		v.Set("page", strconv.Itoa(p.page))
+	}
+
+	if p.scope != "" {
+		return "/docs/" + p.scope + "/search?" + v.Encode()
+	}
+
+	return "/search?" + v.Encode()
+}
+
+// RenderSearch renders the search page with results. The active language filter is
+// taken from opts.Lang or from a "lang:<name>" token in the query, and the repository
+// filter offers the given repositories with opts.Repo selected.
+func (v *Renderer) RenderSearch(w io.Writer, query string, opts core.SearchOpts, results *core.SearchResults, repos []core.RepoInfo, partial bool) error {
+	data := v.newSearchData(query, opts, results, "")
+
+	for _, repo := range repos {
+		data.Repos = append(data.Repos, repo.Name)
 	}
 
 	tmpl := v.searchFull
@@ -218,11 +620,389 @@ func (v *Renderer) RenderSearch(w io.Writer, query string, results *core.SearchR
 	return execTemplate(w, tmpl, data)
 }
 
+// RenderRepoSearch renders the search page of a single repository, whose results and
+// filter links stay within the repository.
+func (v *Renderer) RenderRepoSearch(w io.Writer, repo, query string, opts core.SearchOpts, results *core.SearchResults, partial bool) error {
+	opts.Repo = repo
+	data := v.newSearchData(query, opts, results, repo)
+	data.AllReposURL = "/search?" + url.Values{"q": {query}}.Encode()
+
+	tmpl := v.repoSearchFull
+	if partial {
+		tmpl = v.repoSearchPartial
+	}
+
+	return execTemplate(w, tmpl, data)
+}
+
+// newSearchData builds the data of a search page for query and its results. Links on the
+// page run searches in the repository scope when it is set, and on /search otherwise.
+func (v *Renderer) newSearchData(query string, opts core.SearchOpts, results *core.SearchResults, scope string) searchData {
+	text, lang := core.ParseLangFilter(query)
+	if opts.Lang != "" {
+		lang = strings.ToLower(opts.Lang)
+	}
+
+	params := searchParams{text: text, lang: lang, repo: opts.Repo, scope: scope, types: opts.ContentTypes, codeOnly: opts.CodeOnly}
+
+	codeToggle := params
+	codeToggle.codeOnly = !opts.CodeOnly
+
+	data := searchData{
+		Query:         query,
+		Results:       results,
+		Repo:          opts.Repo,
+		Mode:          string(opts.Mode),
+		Offset:        max(opts.Offset, 0),
+		CodeOnly:      opts.CodeOnly,
+		CodeToggleURL: codeToggle.url(),
+		LangFacets:    buildLangFacetLinks(&params, results),
+		TypeFacets:    buildTypeFacetLinks(&params, results, v.typeLabels),
+	}
+
+	data.PrevURL, data.NextURL, data.Pages = buildPageLinks(&params, results, opts.Limit)
+
+	if results != nil && results.Suggestion != "" {
+		suggested := params
+		suggested.text = results.Suggestion
+		suggested.mode = opts.Mode
+		data.SuggestionURL = suggested.url()
+	}
+
+	for _, ct := range opts.ContentTypes {
+		data.Types = append(data.Types, string(ct))
+	}
+
+	if v.semantic {
+		active := opts.EffectiveMode(true)
+		data.Semantic = active == core.SearchModeSemantic
+
+		for _, m := range searchModes {
+			data.Modes = append(data.Modes, searchModeLink{
+				Label:  m.label,
+				URL:    searchModeURL(&params, m.mode),
+				Active: m.mode == active,
+			})
+		}
+	}
+
+	return data
+}
+
+// searchSuggestionsData is the data passed to the search suggestions template.
+type searchSuggestionsData struct {
+	Query       string
+	Suggestions []core.SearchSuggestion
+}
+
+// RenderSuggestions renders the dropdown of the navigation search box listing the
+// suggestions for query, followed by a link to the full search results.
+func (v *Renderer) RenderSuggestions(w io.Writer, query string, suggestions []core.SearchSuggestion) error {
+	return execTemplate(w, v.searchSuggestions, searchSuggestionsData{Query: query, Suggestions: suggestions})
+}
+
+// buildPageLinks returns the navigation between the pages of the results: the previous and
+// next page URLs, empty on the first and last page, and the page numbers around the
+// current page with the first and last pages. It returns nothing when the results fit on
+// a single page.
+func buildPageLinks(params *searchParams, results *core.SearchResults, limit int) (prev, next string, pages []pageLink) {
+	if results == nil || results.Page == 0 || limit <= 0 || (results.Page == 1 && !results.HasMore) {
+		return "", "", nil
+	}
+
+	current := results.Page
+
+	last := int((results.Total + uint64(limit) - 1) / uint64(limit))
+	if results.HasMore {
+		last = max(last, current+1)
+	} else {
+		last = current
+	}
+
+	withPage := func(page int) string {
+		p := *params
+		p.page = page
+
+		return p.url()
+	}
+
+	if current > 1 {
+		prev = withPage(current - 1)
+	}
+
+	if results.HasMore {
+		next = withPage(current + 1)
+	}
+
+	from, to := max(1, current-searchPageWindow), min(last, current+searchPageWindow)
+
+	if from > 1 {
+		pages = append(pages, pageLink{Number: 1, URL: withPage(1)})
+
+		if from > 2 {
+			pages = append(pages, pageLink{Gap: true})
+		}
+	}
+
+	for page := from; page <= to; page++ {
+		pages = append(pages, pageLink{Number: page, URL: withPage(page), Active: page == current})
+	}
+
+	if to < last {
+		if to < last-1 {
+			pages = append(pages, pageLink{Gap: true})
+		}
+
+		pages = append(pages, pageLink{Number: last, URL: withPage(last)})
+	}
+
+	return prev, next, pages
+}
+
+// buildLangFacetLinks returns the language filters for the search page: one per language
+// facet of the results, plus the active language when it has no matching results.
+func buildLangFacetLinks(params *searchParams, results *core.SearchResults) []langFacetLink {
+	var links []langFacetLink
+
+	active := params.lang
+	seen := false
+
+	withLang := func(lang string) string {
+		p := *params
+		p.lang = lang
+
+		return p.url()
+	}
+
+	if results != nil {
+		for _, f := range results.Langs {
+			link := langFacetLink{Lang: f.Value, Count: f.Count, URL: withLang(f.Value)}
+
+			if f.Value == active {
+				seen = true
+				link.Active = true
+				link.URL = withLang("")
+			}
+
+			links = append(links, link)
+		}
+	}
+
+	if active != "" && !seen {
+		links = append(links, langFacetLink{Lang: active, Active: true, URL: withLang("")})
+	}
+
+	return links
+}
+
+// buildTypeFacetLinks returns the content type filters for the search page: one per
+// content type facet of the results, plus the active content types without matching
+// results. Results of a single content type with no active filter get no filters. Content
+// types are shown with their label in labels, or as indexed without one.
+func buildTypeFacetLinks(params *searchParams, results *core.SearchResults, labels map[string]string) []typeFacetLink {
+	var facets []core.FacetCount
+	if results != nil {
+		facets = results.ContentTypes
+	}
+
+	if len(facets) < 2 && len(params.types) == 0 {
+		return nil
+	}
+
+	for _, ct := range params.types {
+		if !slices.ContainsFunc(facets, func(f core.FacetCount) bool { return f.Value == string(ct) }) {
+			facets = append(facets, core.FacetCount{Value: string(ct)})
+		}
+	}
+
+	links := make([]typeFacetLink, 0, len(facets))
+
+	for _, f := range facets {
+		ct := core.ContentType(f.Value)
+
+		p := *params
+		p.types = slices.DeleteFunc(slices.Clone(params.types), func(t core.ContentType) bool { return t == ct })
+
+		active := len(p.types) < len(params.types)
+		if !active {
+			p.types = append(p.types, ct)
+		}
+
+		label := labels[f.Value]
+		if label == "" {
+			label = f.Value
+		}
+
+		links = append(links, typeFacetLink{Label: label, Count: f.Count, URL: p.url(), Active: active})
+	}
+
+	return links
+}
+
+// searchModeURL builds a search page URL running the query in the given mode. The
+// repository filter is kept, and code-only search and content type filters are kept for
+// keyword searches, the only mode they apply to.
+func searchModeURL(params *searchParams, mode core.SearchMode) string {
+	p := *params
+	p.mode = mode
+
+	if mode != core.SearchModeKeyword {
+		p.codeOnly = false
+		p.types = nil
+	}
+
+	return p.url()
+}
+
+// statsData is the data passed to the stats page template. The sizes are empty when the
+// store or search engine cannot report them.
+type statsData struct {
+	Stats        *core.Stats
+	IndexSize    string
+	StorageSize  string
+	RenderBudget string
+	Searches     []searchDayBar
+	OverBudget   []overBudgetRow
+	SearchesWeek int
+}
+
+// overBudgetRow is a document exceeding the render budget on the stats page.
+type overBudgetRow struct {
+	Repo   string
+	Path   string
+	Render string
+	Size   string
+}
+
+// searchDayBar is a day in the searches chart of the stats page. Percent is the height
+// of its bar relative to the busiest day.
+type searchDayBar struct {
+	Date    string
+	Count   int
+	Percent int
+}
+
+// RenderStats renders the instance statistics page.
+func (v *Renderer) RenderStats(w io.Writer, stats *core.Stats, partial bool) error {
+	data := statsData{Stats: stats}
+
+	if stats.IndexBytes != nil {
+		data.IndexSize = formatBytes(*stats.IndexBytes)
+	}
+
+	if stats.StorageBytes != nil {
+		data.StorageSize = formatBytes(*stats.StorageBytes)
+	}
+
+	busiest := 0
+
+	for _, d := range stats.SearchesPerDay {
+		busiest = max(busiest, d.Count)
+		data.SearchesWeek += d.Count
+	}
+
+	for _, d := range stats.SearchesPerDay {
+		bar := searchDayBar{Date: d.Date, Count: d.Count}
+		if busiest > 0 {
+			bar.Percent = d.Count * 100 / busiest
+		}
+
+		data.Searches = append(data.Searches, bar)
+	}
+
+	if rb := stats.RenderBudget; rb != nil {
+		data.RenderBudget = rb.Budget.MaxDuration.String() + " to render or " + formatBytes(int64(rb.Budget.MaxBytes)) + " of HTML"
+
+		for _, doc := range rb.OverBudget {
+			data.OverBudget = append(data.OverBudget, overBudgetRow{
+				Repo:   doc.Repo,
+				Path:   doc.Path,
+				Render: doc.Slowest().Round(time.Millisecond).String(),
+				Size:   formatBytes(int64(doc.HTMLBytes)),
+			})
+		}
+	}
+
+	tmpl := v.statsFull
+	if partial {
+		tmpl = v.statsPartial
+	}
+
+	return execTemplate(w, tmpl, data)
+}
+
+// notificationsData is the data passed to the inbox page template.
+type notificationsData struct {
+	Notifications []core.Notification
+	Unread        bool
+}
+
+// RenderNotifications renders the inbox page of a signed-in reader.
+func (v *Renderer) RenderNotifications(w io.Writer, notifications []core.Notification, partial bool) error {
+	data := notificationsData{
+		Notifications: notifications,
+		Unread:        slices.ContainsFunc(notifications, func(n core.Notification) bool { return n.Unread }),
+	}
+
+	tmpl := v.inboxFull
+	if partial {
+		tmpl = v.inboxPartial
+	}
+
+	return execTemplate(w, tmpl, data)
+}
+
+// RenderNotificationsLink renders the link to the inbox of a signed-in reader shown in the
+// page header, with their unread count.
+func (v *Renderer) RenderNotificationsLink(w io.Writer, unread int) error {
+	return execTemplate(w, v.inboxLink, unread)
+}
+
+// formatBytes formats a byte count with a binary unit, e.g. "1.5 MiB".
+func formatBytes(n int64) string {
+	const unit = 1024
+
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// RenderEditor renders the web editor of a document. The editor loads the document's
+// source through the API once it holds the document's lock.
+func (v *Renderer) RenderEditor(w io.Writer, doc core.Document) error { //nolint:gocritic // Document is passed by value like RenderDoc
+	return execTemplate(w, v.editor, doc)
+}
+
+// RenderSuggestion renders the page where readers suggest an edit of a document, with the
+// document's source ready to edit.
+func (v *Renderer) RenderSuggestion(w io.Writer, doc core.Document) error { //nolint:gocritic // Document is passed by value like RenderDoc
+	return execTemplate(w, v.suggestion, doc)
+}
+
 // RenderNotFound renders the 404 not found page.
 func (v *Renderer) RenderNotFound(w io.Writer) error {
 	return execTemplate(w, v.notFoundFull, nil)
 }
 
+// RenderMaintenance renders the maintenance page with the operator's message, which may
+// be empty.
+func (v *Renderer) RenderMaintenance(w io.Writer, message string, partial bool) error {
+	tmpl := v.maintenanceFull
+	if partial {
+		tmpl = v.maintenancePartial
+	}
+
+	return execTemplate(w, tmpl, message)
+}
+
 func execTemplate(w io.Writer, tmpl *template.Template, data any) error {
 	if err := tmpl.Execute(w, data); err != nil {
 		return fmt.Errorf("failed to render template %s: %w", tmpl.Name(), err)
@@ -102,6 +102,21 @@ func TestRenderHome_FullPage(t *testing.T) {
 	assert.Contains(t, output, "initHeadingAnchors")
 }
 
+func TestRenderHome_SiteName(t *testing.T) {
+	var buf bytes.Buffer
+
+	require.NoError(t, New(WithSiteName("Team X <Docs>")).RenderHome(&buf, nil, false))
+
+	output := buf.String()
+	assert.Contains(t, output, "<title>Team X &lt;Docs&gt; - Documentation Portal</title>")
+	assert.NotContains(t, output, "<title>Omnidex")
+
+	buf.Reset()
+
+	require.NoError(t, New(WithSiteName("")).RenderHome(&buf, nil, false))
+	assert.Contains(t, buf.String(), "<title>Omnidex - Documentation Portal</title>")
+}
+
 func TestRenderHome_Partial(t *testing.T) {
 	r := New()
 
@@ -142,7 +157,7 @@ func TestRenderRepoIndex_FullPage(t *testing.T) {
 
 	var buf bytes.Buffer
 
-	err := r.RenderRepoIndex(&buf, "my-org/repo", docs, false)
+	err := r.RenderRepoIndex(&buf, "my-org/repo", docs, nil, nil, false, false)
 	require.NoError(t, err)
 
 	output := buf.String()
@@ -153,6 +168,20 @@ func TestRenderRepoIndex_FullPage(t *testing.T) {
 	assert.Contains(t, output, "Advanced Usage")
 	assert.Contains(t, output, "getting-started.md")
 	assert.Contains(t, output, "advanced.md")
+	assert.Contains(t, output, `href="/print/my-org/repo/"`)
+}
+
+func TestRenderRepoIndex_Summary(t *testing.T) {
+	r := New()
+
+	docs := []core.DocumentMeta{
+		{Repo: "my-org/repo", Path: "deploy.md", Title: "Deploy", Summary: "Roll out a release to production."},
+	}
+
+	var buf bytes.Buffer
+
+	require.NoError(t, r.RenderRepoIndex(&buf, "my-org/repo", docs, nil, nil, false, true))
+	assert.Contains(t, buf.String(), "Roll out a release to production.")
 }
 
 func TestRenderRepoIndex_Partial(t *testing.T) {
@@ -164,7 +193,7 @@ func TestRenderRepoIndex_Partial(t *testing.T) {
 
 	var buf bytes.Buffer
 
-	err := r.RenderRepoIndex(&buf, "my-org/repo", docs, true)
+	err := r.RenderRepoIndex(&buf, "my-org/repo", docs, nil, nil, false, true)
 	require.NoError(t, err)
 
 	output := buf.String()
@@ -178,13 +207,117 @@ func TestRenderRepoIndex_EmptyDocs(t *testing.T) {
 
 	var buf bytes.Buffer
 
-	err := r.RenderRepoIndex(&buf, "my-org/repo", nil, false)
+	err := r.RenderRepoIndex(&buf, "my-org/repo", nil, nil, nil, false, false)
 	require.NoError(t, err)
 
 	output := buf.String()
 	assert.Contains(t, output, "No documents in this repository yet.")
 }
 
+func TestRenderRepoIndex_Highlights(t *testing.T) {
+	r := New()
+
+	docs := []core.DocumentMeta{
+		{Repo: "my-org/repo", Path: "guide.md", Title: "Guide"},
+		{Repo: "my-org/repo", Path: "guides/migrate.md", Title: "Migrating to v2", Summary: "Move off the v1 API."},
+	}
+	highlights := &core.PageHighlights{
+		AnnouncementHTML: []byte(`<p><strong>v1 is deprecated</strong></p>`),
+		Pinned:           docs[1:],
+	}
+
+	var buf bytes.Buffer
+
+	require.NoError(t, r.RenderRepoIndex(&buf, "my-org/repo", docs, nil, highlights, false, true))
+
+	output := buf.String()
+	assert.Contains(t, output, `data-announcement`)
+	assert.Contains(t, output, `<p><strong>v1 is deprecated</strong></p>`)
+	assert.Contains(t, output, `data-pinned-doc`)
+	assert.Contains(t, output, `href="/docs/my-org/repo/guides/migrate.md" data-pinned-doc`)
+	assert.Contains(t, output, "Move off the v1 API.")
+
+	buf.Reset()
+
+	require.NoError(t, r.RenderRepoIndex(&buf, "my-org/repo", docs, nil, nil, false, true))
+	assert.NotContains(t, buf.String(), "data-announcement")
+	assert.NotContains(t, buf.String(), "data-pinned-doc")
+}
+
+func TestRenderDoc_Announcement(t *testing.T) {
+	r := New()
+
+	doc := core.Document{Repo: "my-org/repo", Path: "guide.md", Title: "Guide", ContentType: core.ContentTypeMarkdown}
+	highlights := &core.PageHighlights{AnnouncementHTML: []byte(`<p>Incident in progress</p>`)}
+
+	var buf bytes.Buffer
+
+	require.NoError(t, r.RenderDoc(&buf, doc, []byte("<p>Body</p>"), nil, nil, highlights, true))
+	assert.Contains(t, buf.String(), `<p>Incident in progress</p>`)
+
+	buf.Reset()
+
+	require.NoError(t, r.RenderDoc(&buf, doc, []byte("<p>Body</p>"), nil, nil, nil, true))
+	assert.NotContains(t, buf.String(), "data-announcement")
+}
+
+func TestRenderHome_SiteBanner(t *testing.T) {
+	banner := &core.PageBanner{Severity: core.BannerCritical, HTML: []byte(`<p>Search is <strong>degraded</strong></p>`)}
+	r := New(WithSiteBanner(func() *core.PageBanner { return banner }))
+
+	var buf bytes.Buffer
+
+	require.NoError(t, r.RenderHome(&buf, nil, false))
+	assert.Contains(t, buf.String(), `role="alert" data-site-banner="critical"`)
+	assert.Contains(t, buf.String(), `<p>Search is <strong>degraded</strong></p>`)
+
+	buf.Reset()
+
+	require.NoError(t, r.RenderHome(&buf, nil, true))
+	assert.NotContains(t, buf.String(), "data-site-banner", "partial renders keep the banner already on the page")
+
+	banner = nil
+
+	buf.Reset()
+
+	require.NoError(t, r.RenderHome(&buf, nil, false))
+	assert.NotContains(t, buf.String(), "data-site-banner")
+}
+
+func TestRenderRepoIndex_Landing(t *testing.T) {
+	r := New()
+
+	docs := []core.DocumentMeta{
+		{ID: "my-org/repo/README.md", Repo: "my-org/repo", Path: "README.md", Title: "Welcome"},
+		{ID: "my-org/repo/guide.md", Repo: "my-org/repo", Path: "guide.md", Title: "Guide"},
+	}
+	landing := &core.RepoLanding{
+		Doc:  core.Document{Repo: "my-org/repo", Path: "README.md", Title: "Welcome"},
+		HTML: []byte(`<h1 id="welcome">Welcome</h1><p>Landing body</p>`),
+	}
+
+	var buf bytes.Buffer
+
+	err := r.RenderRepoIndex(&buf, "my-org/repo", docs, landing, nil, false, false)
+	require.NoError(t, err)
+
+	output := buf.String()
+	assert.Contains(t, output, `<p>Landing body</p>`)
+	assert.Contains(t, output, `href="/docs/my-org/repo/?tab=files"`)
+	assert.Contains(t, output, `href="/docs/my-org/repo/README.md"`)
+	assert.NotContains(t, output, "guide.md")
+
+	buf.Reset()
+
+	err = r.RenderRepoIndex(&buf, "my-org/repo", docs, landing, nil, true, false)
+	require.NoError(t, err)
+
+	output = buf.String()
+	assert.NotContains(t, output, "Landing body")
+	assert.Contains(t, output, "guide.md")
+	assert.Contains(t, output, "Overview")
+}
+
 func TestRenderDoc_FullPage(t *testing.T) {
 	r := New()
 
@@ -212,7 +345,7 @@ func TestRenderDoc_FullPage(t *testing.T) {
 
 	var buf bytes.Buffer
 
-	err := r.RenderDoc(&buf, doc, htmlContent, headings, navDocs, false)
+	err := r.RenderDoc(&buf, doc, htmlContent, headings, navDocs, nil, false)
 	require.NoError(t, err)
 
 	output := buf.String()
@@ -227,6 +360,241 @@ func TestRenderDoc_FullPage(t *testing.T) {
 	assert.Contains(t, output, "https://github.com/my-org/repo/blob/abc123/getting-started.md", "View source link should use CommitSHA")
 }
 
+func TestRenderDoc_ReadingSettings(t *testing.T) {
+	r := New()
+
+	doc := core.Document{ID: "my-org/repo/readme.md", Repo: "my-org/repo", Path: "readme.md"}
+
+	var buf bytes.Buffer
+
+	err := r.RenderDoc(&buf, doc, []byte("<p>Body</p>"), nil, nil, nil, true)
+	require.NoError(t, err)
+
+	output := buf.String()
+	assert.Contains(t, output, `id="reader-mode-toggle"`)
+	assert.Contains(t, output, `class="doc-sidebar`)
+	assert.Contains(t, output, `data-reading-pref="contentWidth"`)
+	assert.Contains(t, output, `data-reading-pref="fontSize"`)
+	assert.Contains(t, output, `data-reading-pref="lineHeight"`)
+}
+
+func TestRenderDoc_ShareMenu(t *testing.T) {
+	r := New()
+
+	doc := core.Document{ID: "my-org/repo/docs/guide.md", Repo: "my-org/repo", Path: "docs/guide.md"}
+
+	var buf bytes.Buffer
+
+	err := r.RenderDoc(&buf, doc, []byte("<p>Body</p>"), nil, nil, nil, true)
+	require.NoError(t, err)
+
+	output := buf.String()
+	assert.Contains(t, output, `class="share-menu relative js-only"`)
+	assert.Contains(t, output, `data-share="link"`)
+	assert.Contains(t, output, `data-share="markdown" data-share-url="/raw/my-org/repo/docs/guide.md"`)
+	assert.Contains(t, output, `data-share="html" data-share-url="/html/my-org/repo/docs/guide.md"`)
+}
+
+func TestRenderDoc_Tags(t *testing.T) {
+	r := New()
+
+	doc := core.Document{ID: "my-org/repo/guide.md", Repo: "my-org/repo", Path: "guide.md", Tags: []string{"ci", "getting started"}}
+
+	var buf bytes.Buffer
+
+	err := r.RenderDoc(&buf, doc, []byte("<p>Body</p>"), nil, nil, nil, true)
+	require.NoError(t, err)
+
+	output := buf.String()
+	assert.Contains(t, output, `<ul class="doc-tags`)
+	assert.Contains(t, output, `href="/search?q=tag%3Aci" hx-get="/search?q=tag%3Aci"`)
+	assert.Contains(t, output, `href="/search?q=tag%3A%22getting&#43;started%22"`)
+	assert.Contains(t, output, `>#getting started</a>`)
+
+	buf.Reset()
+
+	doc.Tags = nil
+
+	err = r.RenderDoc(&buf, doc, []byte("<p>Body</p>"), nil, nil, nil, true)
+	require.NoError(t, err)
+	assert.NotContains(t, buf.String(), "doc-tags")
+}
+
+func TestRenderDoc_EscapesPathInURLs(t *testing.T) {
+	r := New()
+
+	doc := core.Document{ID: "my-org/repo/c# notes/100% done?.md", Repo: "my-org/repo", Path: "c# notes/100% done?.md"}
+
+	var buf bytes.Buffer
+
+	err := r.RenderDoc(&buf, doc, []byte("<p>Body</p>"), nil, nil, nil, true)
+	require.NoError(t, err)
+
+	output := buf.String()
+	assert.Contains(t, output, `data-share-url="/raw/my-org/repo/c%23%20notes/100%25%20done%3F.md"`)
+	assert.NotContains(t, output, `/raw/my-org/repo/c# notes`)
+}
+
+func TestRenderDoc_EditLink(t *testing.T) {
+	doc := core.Document{ID: "my-org/wiki/team notes.md", Repo: "my-org/wiki", Path: "team notes.md"}
+
+	var buf bytes.Buffer
+
+	require.NoError(t, New(WithEditableRepos("my-org/wiki")).RenderDoc(&buf, doc, []byte("<p>Body</p>"), nil, nil, nil, true))
+	assert.Contains(t, buf.String(), `href="/edit/my-org/wiki/team%20notes.md"`)
+
+	buf.Reset()
+
+	require.NoError(t, New().RenderDoc(&buf, doc, []byte("<p>Body</p>"), nil, nil, nil, true))
+	assert.NotContains(t, buf.String(), `/edit/`)
+}
+
+func TestRenderEditor(t *testing.T) {
+	var buf bytes.Buffer
+
+	doc := core.Document{Repo: "my-org/wiki", Path: "team notes.md", Title: "Team <Notes>", Content: "secret source"}
+
+	require.NoError(t, New().RenderEditor(&buf, doc))
+
+	output := buf.String()
+	assert.Contains(t, output, `data-repo="my-org/wiki" data-path="team notes.md" data-doc-url="/docs/my-org/wiki/team%20notes.md"`)
+	assert.Contains(t, output, "Edit Team &lt;Notes&gt;")
+	assert.NotContains(t, output, "secret source", "the source is loaded once the editor holds the lock")
+	assert.NotContains(t, output, "Authorization", "editors are identified by signing in, not by an API key")
+}
+
+func TestRenderDoc_SuggestLink(t *testing.T) {
+	doc := core.Document{ID: "my-org/wiki/team notes.md", Repo: "my-org/wiki", Path: "team notes.md"}
+
+	var buf bytes.Buffer
+
+	require.NoError(t, New(WithSuggestibleRepos("my-org/wiki")).RenderDoc(&buf, doc, []byte("<p>Body</p>"), nil, nil, nil, true))
+	assert.Contains(t, buf.String(), `href="/suggest/my-org/wiki/team%20notes.md"`)
+	assert.NotContains(t, buf.String(), `/edit/`)
+
+	buf.Reset()
+
+	require.NoError(t, New().RenderDoc(&buf, doc, []byte("<p>Body</p>"), nil, nil, nil, true))
+	assert.NotContains(t, buf.String(), `/suggest/`)
+}
+
+func TestRenderSuggestion(t *testing.T) {
+	var buf bytes.Buffer
+
+	doc := core.Document{Repo: "my-org/wiki", Path: "team notes.md", Title: "Team Notes", Content: "# Notes\n\n<b>bold</b>"}
+
+	require.NoError(t, New().RenderSuggestion(&buf, doc))
+
+	output := buf.String()
+	assert.Contains(t, output, `data-repo="my-org/wiki" data-path="team notes.md"`)
+	assert.Contains(t, output, "Suggest an edit to Team Notes")
+	assert.Contains(t, output, "# Notes\n\n&lt;b&gt;bold&lt;/b&gt;</textarea>", "the source is escaped in the editor")
+	assert.Contains(t, output, `href="/docs/my-org/wiki/team%20notes.md"`)
+}
+
+func TestRenderDoc_LiveUpdates(t *testing.T) {
+	r := New()
+
+	doc := core.Document{ID: "my-org/repo/docs/runbook.md", Repo: "my-org/repo", Path: "docs/runbook.md"}
+
+	var buf bytes.Buffer
+
+	require.NoError(t, r.RenderDoc(&buf, doc, []byte("<p>Body</p>"), nil, nil, nil, false))
+
+	output := buf.String()
+	assert.Contains(t, output, `data-live-repo="my-org/repo" data-live-path="docs/runbook.md"`)
+	assert.Contains(t, output, `id="update-toast" hidden`)
+	assert.Contains(t, output, `id="presence-bar" hidden`)
+	assert.Contains(t, output, `new EventSource('/events')`)
+
+	buf.Reset()
+
+	require.NoError(t, r.RenderHome(&buf, nil, true))
+	assert.Contains(t, buf.String(), `data-live-home`)
+}
+
+func TestRenderDoc_FreshnessBanner(t *testing.T) {
+	r := New(WithFreshness(FreshnessConfig{MaxDocumentAge: 90 * 24 * time.Hour}))
+
+	doc := core.Document{
+		Repo:      "my-org/repo",
+		Path:      "docs/runbook.md",
+		CommitSHA: "abc1234def",
+		UpdatedAt: time.Now().Add(-200 * 24 * time.Hour),
+	}
+
+	var buf bytes.Buffer
+
+	require.NoError(t, r.RenderDoc(&buf, doc, []byte("<p>Body</p>"), nil, nil, nil, false))
+	assert.Contains(t, buf.String(), "This page may be outdated")
+	assert.Contains(t, buf.String(), "last published 6 months ago from commit abc1234.")
+
+	doc.UpdatedAt = time.Now()
+
+	buf.Reset()
+
+	require.NoError(t, r.RenderDoc(&buf, doc, []byte("<p>Body</p>"), nil, nil, nil, true))
+	assert.NotContains(t, buf.String(), "data-stale-banner")
+}
+
+func TestRenderDoc_ProvenanceBadge(t *testing.T) {
+	r := New()
+
+	tests := []struct {
+		prov      *core.Provenance
+		name      string
+		want      []string
+		wantBadge bool
+	}{
+		{name: "no provenance"},
+		{name: "unverified", prov: &core.Provenance{Workflow: "my-org/repo/.github/workflows/docs.yml@refs/heads/main"}},
+		{
+			name: "verified",
+			prov: &core.Provenance{
+				Workflow: "my-org/repo/.github/workflows/docs.yml@refs/heads/main",
+				RunURL:   "https://github.com/my-org/repo/actions/runs/42",
+				Verified: true,
+			},
+			wantBadge: true,
+			want: []string{
+				`from commit 0123456789abcdef by workflow my-org/repo/.github/workflows/docs.yml@refs/heads/main`,
+				`href="https://github.com/my-org/repo/actions/runs/42"`,
+				`<code class="font-mono">0123456</code>`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, ct := range []core.ContentType{core.ContentTypeMarkdown, core.ContentTypeOpenAPI} {
+				doc := core.Document{
+					Repo:        "my-org/repo",
+					Path:        "docs/guide.md",
+					CommitSHA:   "0123456789abcdef",
+					ContentType: ct,
+					Provenance:  tt.prov,
+				}
+
+				var buf bytes.Buffer
+
+				require.NoError(t, r.RenderDoc(&buf, doc, []byte("{}"), nil, nil, nil, true))
+
+				output := buf.String()
+				if !tt.wantBadge {
+					assert.NotContains(t, output, "Verified provenance")
+					continue
+				}
+
+				assert.Contains(t, output, "Verified provenance")
+
+				for _, want := range tt.want {
+					assert.Contains(t, output, want)
+				}
+			}
+		})
+	}
+}
+
 func TestRenderDoc_Partial(t *testing.T) {
 	r := New()
 
@@ -240,7 +608,7 @@ func TestRenderDoc_Partial(t *testing.T) {
 
 	var buf bytes.Buffer
 
-	err := r.RenderDoc(&buf, doc, htmlContent, nil, nil, true)
+	err := r.RenderDoc(&buf, doc, htmlContent, nil, nil, nil, true)
 	require.NoError(t, err)
 
 	output := buf.String()
@@ -271,7 +639,7 @@ func TestRenderDoc_TOCHiddenWithFewHeadings(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			var buf bytes.Buffer
 
-			err := r.RenderDoc(&buf, doc, htmlContent, tt.headings, nil, false)
+			err := r.RenderDoc(&buf, doc, htmlContent, tt.headings, nil, nil, false)
 			require.NoError(t, err)
 
 			output := buf.String()
@@ -299,7 +667,7 @@ func TestRenderDoc_TOCRenderedWithMultipleHeadings(t *testing.T) {
 
 	var buf bytes.Buffer
 
-	err := r.RenderDoc(&buf, doc, htmlContent, headings, nil, false)
+	err := r.RenderDoc(&buf, doc, htmlContent, headings, nil, nil, false)
 	require.NoError(t, err)
 
 	output := buf.String()
@@ -333,7 +701,7 @@ func TestRenderSearch_FullPage(t *testing.T) {
 
 	var buf bytes.Buffer
 
-	err := r.RenderSearch(&buf, "test query", results, false)
+	err := r.RenderSearch(&buf, "test query", core.SearchOpts{}, results, nil, false)
 	require.NoError(t, err)
 
 	output := buf.String()
@@ -363,7 +731,7 @@ func TestRenderSearch_Partial(t *testing.T) {
 
 	var buf bytes.Buffer
 
-	err := r.RenderSearch(&buf, "guide", results, true)
+	err := r.RenderSearch(&buf, "guide", core.SearchOpts{}, results, nil, true)
 	require.NoError(t, err)
 
 	output := buf.String()
@@ -373,12 +741,33 @@ func TestRenderSearch_Partial(t *testing.T) {
 	assert.Contains(t, output, "1 results found")
 }
 
+func TestRenderSearch_Summary(t *testing.T) {
+	r := New()
+
+	results := &core.SearchResults{
+		Hits: []core.SearchResult{
+			{Repo: "org/repo", Path: "guide.md", Title: "User Guide", TitleFragments: []string{"User <mark>Guide</mark>"}, Summary: "How to set up the CLI."},
+			{Repo: "org/repo", Path: "faq.md", Title: "FAQ", ContentFragments: []string{"the <mark>guide</mark> answers"}, Summary: "Not shown."},
+		},
+		Total: 2,
+	}
+
+	var buf bytes.Buffer
+
+	require.NoError(t, r.RenderSearch(&buf, "guide", core.SearchOpts{}, results, nil, true))
+
+	output := buf.String()
+	assert.Contains(t, output, "How to set up the CLI.", "the summary replaces the missing snippet")
+	assert.NotContains(t, output, "Matched in title")
+	assert.NotContains(t, output, "Not shown.", "fragments take precedence over the summary")
+}
+
 func TestRenderSearch_EmptyQuery(t *testing.T) {
 	r := New()
 
 	var buf bytes.Buffer
 
-	err := r.RenderSearch(&buf, "", nil, false)
+	err := r.RenderSearch(&buf, "", core.SearchOpts{}, nil, nil, false)
 	require.NoError(t, err)
 
 	output := buf.String()
@@ -396,12 +785,320 @@ func TestRenderSearch_NoResults(t *testing.T) {
 
 	var buf bytes.Buffer
 
-	err := r.RenderSearch(&buf, "nonexistent", results, false)
+	err := r.RenderSearch(&buf, "nonexistent", core.SearchOpts{}, results, nil, false)
 	require.NoError(t, err)
 
 	output := buf.String()
 	assert.Contains(t, output, "No results found")
 	assert.Contains(t, output, "nonexistent")
+	assert.NotContains(t, output, "Did you mean")
+}
+
+func TestRenderSearch_Suggestion(t *testing.T) {
+	r := New()
+
+	results := &core.SearchResults{Suggestion: "kubernetes deploy"}
+
+	var buf bytes.Buffer
+
+	err := r.RenderSearch(&buf, "kubernets deploy lang:go", core.SearchOpts{Repo: "owner/repo", Mode: core.SearchModeKeyword}, results, nil, true)
+	require.NoError(t, err)
+
+	output := buf.String()
+	assert.Contains(t, output, "No results found")
+	assert.Contains(t, output, "Did you mean")
+	assert.Contains(t, output, ">kubernetes deploy</a>?")
+	assert.Contains(t, output, `href="/search?mode=keyword&amp;q=kubernetes&#43;deploy&#43;lang%3Ago&amp;repo=owner%2Frepo"`)
+}
+
+func TestRenderSearch_CodeFilters(t *testing.T) {
+	r := New()
+
+	results := &core.SearchResults{
+		Langs: []core.FacetCount{{Value: "go", Count: 2}, {Value: "python", Count: 1}},
+		Total: 2,
+	}
+
+	var buf bytes.Buffer
+
+	err := r.RenderSearch(&buf, "handler lang:go", core.SearchOpts{CodeOnly: true}, results, nil, true)
+	require.NoError(t, err)
+
+	output := buf.String()
+	assert.Contains(t, output, `href="/search?q=handler&#43;lang%3Ago"`, "toggling off code-only keeps the query")
+	assert.Contains(t, output, `href="/search?code=1&amp;q=handler"`, "active language links remove the filter")
+	assert.Contains(t, output, `href="/search?code=1&amp;q=handler&#43;lang%3Apython"`)
+	assert.Contains(t, output, `aria-pressed="true">Code only</a>`)
+	assert.Contains(t, output, "lang:python")
+}
+
+func TestRenderSearch_ActiveLangWithoutResults(t *testing.T) {
+	r := New()
+
+	var buf bytes.Buffer
+
+	err := r.RenderSearch(&buf, "zzz", core.SearchOpts{Lang: "Rust"}, &core.SearchResults{}, nil, true)
+	require.NoError(t, err)
+
+	output := buf.String()
+	assert.Contains(t, output, `aria-pressed="true">lang:rust</a>`)
+	assert.Contains(t, output, `href="/search?q=zzz"`)
+}
+
+func TestRenderSearch_SearchModes(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := New().RenderSearch(&buf, "deploy", core.SearchOpts{}, &core.SearchResults{}, nil, true)
+	require.NoError(t, err)
+	assert.NotContains(t, buf.String(), "By meaning", "no search modes without semantic search")
+
+	r := New(WithSemanticSearch(true))
+
+	buf.Reset()
+
+	err = r.RenderSearch(&buf, "deploy", core.SearchOpts{}, &core.SearchResults{}, nil, true)
+	require.NoError(t, err)
+
+	output := buf.String()
+	assert.Contains(t, output, `aria-pressed="true">Hybrid</a>`, "hybrid is the default mode")
+	assert.Contains(t, output, `href="/search?mode=keyword&amp;q=deploy"`)
+	assert.Contains(t, output, `href="/search?mode=semantic&amp;q=deploy"`)
+	assert.Contains(t, output, `aria-pressed="false">By meaning</a>`)
+
+	buf.Reset()
+
+	err = r.RenderSearch(&buf, "deploy", core.SearchOpts{CodeOnly: true}, &core.SearchResults{}, nil, true)
+	require.NoError(t, err)
+
+	output = buf.String()
+	assert.Contains(t, output, `aria-pressed="true">Keyword</a>`, "code-only searches run as keyword searches")
+	assert.Contains(t, output, `href="/search?code=1&amp;mode=keyword&amp;q=deploy"`)
+
+	buf.Reset()
+
+	err = r.RenderSearch(&buf, "deploy", core.SearchOpts{Mode: core.SearchModeSemantic}, &core.SearchResults{}, nil, true)
+	require.NoError(t, err)
+
+	output = buf.String()
+	assert.Contains(t, output, `href="/search?q=deploy"`, "hybrid links leave the mode out")
+	assert.Contains(t, output, `aria-pressed="true">By meaning</a>`)
+	assert.NotContains(t, output, "Code only", "code-only search does not apply to semantic search")
+}
+
+func TestRenderSearch_RepoFilter(t *testing.T) {
+	repos := []core.RepoInfo{{Name: "acme/api"}, {Name: "acme/web"}}
+	results := &core.SearchResults{Langs: []core.FacetCount{{Value: "go", Count: 1}}, Total: 1}
+
+	var buf bytes.Buffer
+
+	err := New().RenderSearch(&buf, "deploy", core.SearchOpts{Repo: "acme/web", CodeOnly: true}, results, repos, true)
+	require.NoError(t, err)
+
+	output := buf.String()
+	assert.Contains(t, output, `<select name="repo"`)
+	assert.Contains(t, output, `<option value="acme/api">acme/api</option>`)
+	assert.Contains(t, output, `<option value="acme/web" selected>acme/web</option>`)
+	assert.Contains(t, output, `<input type="hidden" name="code" value="1">`, "changing the repository keeps code-only search")
+	assert.Contains(t, output, `href="/search?q=deploy&amp;repo=acme%2Fweb"`, "toggling code-only keeps the repository")
+	assert.Contains(t, output, `href="/search?code=1&amp;q=deploy&#43;lang%3Ago&amp;repo=acme%2Fweb"`)
+
+	buf.Reset()
+
+	err = New().RenderSearch(&buf, "deploy", core.SearchOpts{}, results, repos[:1], true)
+	require.NoError(t, err)
+	assert.NotContains(t, buf.String(), `<select name="repo"`, "a single repository needs no filter")
+}
+
+func TestRenderSearch_RankingExperiment(t *testing.T) {
+	hits := []core.SearchResult{{Repo: "acme/api", Path: "a.md"}, {Repo: "acme/api", Path: "b.md"}}
+	results := &core.SearchResults{Hits: hits, Total: 42, Page: 3, Variant: "weighted"}
+
+	var buf bytes.Buffer
+
+	require.NoError(t, New().RenderSearch(&buf, "deploy", core.SearchOpts{Limit: 20, Offset: 40}, results, nil, true))
+
+	output := buf.String()
+	assert.Contains(t, output, `data-search-variant="weighted"`)
+	assert.Contains(t, output, `data-rank="41"`)
+	assert.Contains(t, output, `data-rank="42"`)
+
+	buf.Reset()
+
+	results.Variant = ""
+
+	require.NoError(t, New().RenderSearch(&buf, "deploy", core.SearchOpts{Limit: 20}, results, nil, true))
+	assert.NotContains(t, buf.String(), "data-search-variant", "clicks are not sent outside experiments")
+}
+
+func TestRenderRepoSearch(t *testing.T) {
+	hits := []core.SearchResult{{Repo: "acme/api", Path: "guide.md", Title: "Guide"}}
+	results := &core.SearchResults{Hits: hits, Langs: []core.FacetCount{{Value: "go", Count: 1}}, Total: 60, Page: 1, HasMore: true}
+
+	var buf bytes.Buffer
+
+	err := New().RenderRepoSearch(&buf, "acme/api", "deploy", core.SearchOpts{Limit: 20}, results, false)
+	require.NoError(t, err)
+
+	output := buf.String()
+	assert.Contains(t, output, "<title>")
+	assert.Contains(t, output, "Search acme/api")
+	assert.Contains(t, output, `action="/docs/acme/api/search"`)
+	assert.Contains(t, output, `name="q" value="deploy"`)
+	assert.Contains(t, output, `href="/search?q=deploy"`, "the query can be run across all repositories")
+	assert.Contains(t, output, `href="/docs/acme/api/search?page=2&amp;q=deploy"`, "pages stay in the repository")
+	assert.Contains(t, output, `href="/docs/acme/api/search?q=deploy&#43;lang%3Ago"`, "filters stay in the repository")
+	assert.NotContains(t, output, `<select name="repo"`)
+
+	buf.Reset()
+
+	require.NoError(t, New().RenderRepoSearch(&buf, "acme/api", "", core.SearchOpts{}, nil, true))
+	assert.NotContains(t, buf.String(), "<title>")
+	assert.NotContains(t, buf.String(), "Search all repositories")
+}
+
+func TestRenderRepoIndex_SearchBox(t *testing.T) {
+	docs := []core.DocumentMeta{{Repo: "acme/api", Path: "guide.md", Title: "Guide"}}
+
+	var buf bytes.Buffer
+
+	require.NoError(t, New().RenderRepoIndex(&buf, "acme/api", docs, nil, nil, false, true))
+	assert.Contains(t, buf.String(), `<form action="/docs/acme/api/search"`)
+}
+
+func TestRenderSearch_Pagination(t *testing.T) {
+	hits := []core.SearchResult{{Repo: "acme/api", Path: "guide.md", Title: "Guide"}}
+	opts := core.SearchOpts{Repo: "acme/api", Limit: 20, Offset: 100}
+	results := &core.SearchResults{Hits: hits, Total: 200, Page: 6, HasMore: true}
+
+	var buf bytes.Buffer
+
+	require.NoError(t, New().RenderSearch(&buf, "deploy", opts, results, nil, true))
+
+	output := buf.String()
+	assert.Contains(t, output, `href="/search?page=5&amp;q=deploy&amp;repo=acme%2Fapi" hx-get="/search?page=5&amp;q=deploy&amp;repo=acme%2Fapi" hx-target="#main-content" hx-push-url="true" rel="prev"`)
+	assert.Contains(t, output, `href="/search?page=7&amp;q=deploy&amp;repo=acme%2Fapi" hx-get="/search?page=7&amp;q=deploy&amp;repo=acme%2Fapi" hx-target="#main-content" hx-push-url="true" rel="next"`)
+	assert.Contains(t, output, `<span aria-current="page"`)
+	assert.Contains(t, output, `href="/search?q=deploy&amp;repo=acme%2Fapi" hx-get`, "the first page is linked without a page number")
+	assert.Contains(t, output, `>10</a>`, "the last page is linked")
+	assert.NotContains(t, output, `page=6&amp;`, "the current page is not linked")
+	assert.NotContains(t, output, `page=9&amp;`)
+
+	buf.Reset()
+
+	results = &core.SearchResults{Hits: hits, Total: 1, Page: 1}
+
+	require.NoError(t, New().RenderSearch(&buf, "deploy", core.SearchOpts{Limit: 20}, results, nil, true))
+	assert.NotContains(t, buf.String(), "search-pagination", "a single page needs no navigation")
+}
+
+func TestBuildPageLinks(t *testing.T) {
+	params := &searchParams{text: "q"}
+
+	numbers := func(pages []pageLink) []int {
+		var n []int
+
+		for _, p := range pages {
+			if p.Gap {
+				n = append(n, 0)
+				continue
+			}
+
+			n = append(n, p.Number)
+		}
+
+		return n
+	}
+
+	prev, next, pages := buildPageLinks(params, &core.SearchResults{Total: 45, Page: 1, HasMore: true}, 20)
+	assert.Empty(t, prev)
+	assert.Equal(t, "/search?page=2&q=q", next)
+	assert.Equal(t, []int{1, 2, 3}, numbers(pages))
+	assert.True(t, pages[0].Active)
+
+	prev, next, pages = buildPageLinks(params, &core.SearchResults{Total: 45, Page: 3}, 20)
+	assert.Equal(t, "/search?page=2&q=q", prev)
+	assert.Empty(t, next)
+	assert.Equal(t, []int{1, 2, 3}, numbers(pages))
+
+	_, _, pages = buildPageLinks(params, &core.SearchResults{Total: 1000, Page: 10, HasMore: true}, 20)
+	assert.Equal(t, []int{1, 0, 8, 9, 10, 11, 12, 0, 50}, numbers(pages), "distant pages are skipped")
+
+	_, _, pages = buildPageLinks(params, &core.SearchResults{Total: 100, Page: 4, HasMore: true}, 20)
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, numbers(pages))
+
+	_, _, pages = buildPageLinks(params, &core.SearchResults{Total: 20, Page: 1}, 20)
+	assert.Empty(t, pages)
+}
+
+func TestRenderSearch_TypeFacets(t *testing.T) {
+	results := &core.SearchResults{
+		ContentTypes: []core.FacetCount{{Value: "markdown", Count: 4}, {Value: "openapi", Count: 2}},
+		Total:        6,
+	}
+
+	var buf bytes.Buffer
+
+	err := New().RenderSearch(&buf, "users", core.SearchOpts{}, results, nil, true)
+	require.NoError(t, err)
+
+	output := buf.String()
+	assert.Contains(t, output, `href="/search?q=users&amp;type=openapi"`)
+	assert.Contains(t, output, `aria-pressed="false">OpenAPI <span class="text-gray-400">2</span></a>`)
+	assert.Contains(t, output, `aria-pressed="false">Markdown <span class="text-gray-400">4</span></a>`)
+
+	buf.Reset()
+
+	opts := core.SearchOpts{ContentTypes: []core.ContentType{core.ContentTypeOpenAPI, core.ContentTypeAsyncAPI}}
+	results = &core.SearchResults{ContentTypes: []core.FacetCount{{Value: "openapi", Count: 2}}, Total: 2}
+
+	err = New().RenderSearch(&buf, "users", opts, results, nil, true)
+	require.NoError(t, err)
+
+	output = buf.String()
+	assert.Contains(t, output, `href="/search?q=users&amp;type=asyncapi"`, "active content type links remove the filter")
+	assert.Contains(t, output, `aria-pressed="true">OpenAPI <span class="text-gray-400">2</span></a>`)
+	assert.Contains(t, output, `aria-pressed="true">AsyncAPI</a>`, "active content types without results are kept")
+
+	buf.Reset()
+
+	results = &core.SearchResults{ContentTypes: []core.FacetCount{{Value: "markdown", Count: 4}}, Total: 4}
+
+	err = New().RenderSearch(&buf, "users", core.SearchOpts{}, results, nil, true)
+	require.NoError(t, err)
+	assert.NotContains(t, buf.String(), "type-facet", "results of a single content type get no filters")
+
+	buf.Reset()
+
+	results = &core.SearchResults{ContentTypes: []core.FacetCount{{Value: "markdown", Count: 4}, {Value: "graphql", Count: 1}}, Total: 5}
+	types := append(core.DefaultContentTypes(), core.ContentTypeInfo{Name: "graphql", DisplayName: "GraphQL"})
+
+	err = New(WithContentTypes(types)).RenderSearch(&buf, "users", core.SearchOpts{}, results, nil, true)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), `aria-pressed="false">GraphQL <span class="text-gray-400">1</span></a>`, "registered content types are shown by their display name")
+}
+
+func TestRenderSuggestions(t *testing.T) {
+	suggestions := []core.SearchSuggestion{
+		{Repo: "org/repo", Path: "deploy.md", Title: "Deploying", Heading: "Rolling Updates", Anchor: "rolling-updates"},
+		{Repo: "org/repo", Path: "guides/rollout.md", Title: "Rollout <Guide>"},
+	}
+
+	var buf bytes.Buffer
+
+	require.NoError(t, New().RenderSuggestions(&buf, "roll up", suggestions))
+
+	output := buf.String()
+	assert.NotContains(t, output, "<!DOCTYPE html>")
+	assert.Contains(t, output, `href="/docs/org/repo/deploy.md#rolling-updates"`)
+	assert.Contains(t, output, "Rolling Updates</span>")
+	assert.Contains(t, output, `href="/docs/org/repo/guides/rollout.md"`)
+	assert.Contains(t, output, "Rollout &lt;Guide&gt;</span>")
+	assert.Contains(t, output, `href="/search?q=roll%20up"`)
+
+	buf.Reset()
+
+	require.NoError(t, New().RenderSuggestions(&buf, "zzz", nil))
+	assert.Empty(t, strings.TrimSpace(buf.String()), "no suggestions close the dropdown")
 }
 
 func TestSafeFragment(t *testing.T) {
@@ -462,7 +1159,7 @@ func TestSafeFragment(t *testing.T) {
 
 			var buf bytes.Buffer
 
-			err := r.RenderSearch(&buf, "q", results, true)
+			err := r.RenderSearch(&buf, "q", core.SearchOpts{}, results, nil, true)
 			require.NoError(t, err)
 
 			output := buf.String()
@@ -478,6 +1175,162 @@ func TestSafeFragment(t *testing.T) {
 	}
 }
 
+func TestRenderStats(t *testing.T) {
+	r := New()
+
+	stats := &core.Stats{
+		Since:             time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC),
+		StorageBytes:      new(int64(3 << 20)),
+		Repos:             2,
+		Documents:         7,
+		Ingests:           4,
+		IngestedDocuments: 9,
+		SearchesPerDay: []core.DayCount{
+			{Date: "2025-06-14", Count: 2},
+			{Date: "2025-06-15", Count: 8},
+		},
+	}
+
+	var buf bytes.Buffer
+
+	require.NoError(t, r.RenderStats(&buf, stats, false))
+
+	output := buf.String()
+	assert.Contains(t, output, "<!DOCTYPE html>")
+	assert.Contains(t, output, "Jun 01, 2025 08:00 UTC")
+	assert.Contains(t, output, "3.0 MiB")
+	assert.NotContains(t, output, "Search index")
+	assert.Contains(t, output, "9 documents ingested")
+	assert.Contains(t, output, ">10</div>")
+	assert.Contains(t, output, "width: 25%")
+	assert.Contains(t, output, "width: 100%")
+
+	assert.NotContains(t, output, "Render budget")
+
+	buf.Reset()
+
+	require.NoError(t, r.RenderStats(&buf, &core.Stats{}, true))
+	assert.NotContains(t, buf.String(), "<!DOCTYPE html>")
+}
+
+func TestRenderStats_RenderBudget(t *testing.T) {
+	r := New()
+
+	stats := &core.Stats{RenderBudget: &core.RenderBudgetStats{
+		Budget:          core.RenderBudget{MaxDuration: 250 * time.Millisecond, MaxBytes: 1 << 20},
+		Measured:        12,
+		OverBudgetCount: 1,
+		OverBudget: []core.RenderStat{
+			{Repo: "acme/api", Path: "ref/big table.md", ViewRender: 1234567890, HTMLBytes: 2 << 20, OverBudget: true},
+		},
+	}}
+
+	var buf bytes.Buffer
+
+	require.NoError(t, r.RenderStats(&buf, stats, true))
+
+	output := buf.String()
+	assert.Contains(t, output, "1 of 12 measured documents exceed the budget of 250ms to render or 1.0 MiB of HTML.")
+	assert.Contains(t, output, `href="/docs/acme/api/ref/big%20table.md"`)
+	assert.Contains(t, output, "1.235s")
+	assert.Contains(t, output, "2.0 MiB")
+}
+
+func TestRenderStats_RankingExperiment(t *testing.T) {
+	stats := &core.Stats{Experiment: &core.ExperimentStats{
+		Name: "fusion",
+		Variants: []core.VariantStats{
+			{Name: "control", Searches: 200, Clicks: 90, ClickThroughRate: 0.45, MeanClickRank: 2.4},
+			{Name: "weighted"},
+		},
+	}}
+
+	var buf bytes.Buffer
+
+	require.NoError(t, New().RenderStats(&buf, stats, true))
+
+	output := buf.String()
+	assert.Contains(t, output, "Ranking experiment: fusion")
+	assert.Contains(t, output, "45.0%")
+	assert.Contains(t, output, "2.40")
+	assert.Contains(t, output, "&ndash;", "variants without clicks have no mean click rank")
+}
+
+func TestRenderNotifications(t *testing.T) {
+	r := New()
+
+	notifications := []core.Notification{
+		{Time: time.Date(2025, 6, 2, 9, 30, 0, 0, time.UTC), Search: "Deploys", Repo: "acme/api", Path: "ops/deploy guide.md", Title: "Deploy guide", Unread: true},
+		{Time: time.Date(2025, 6, 1, 9, 30, 0, 0, time.UTC), Search: "Deploys", Repo: "acme/api", Path: "rollback.md"},
+	}
+
+	var buf bytes.Buffer
+
+	require.NoError(t, r.RenderNotifications(&buf, notifications, false))
+
+	output := buf.String()
+	assert.Contains(t, output, "<!DOCTYPE html>")
+	assert.Contains(t, output, `href="/docs/acme/api/ops/deploy%20guide.md"`)
+	assert.Contains(t, output, "Deploy guide")
+	assert.Contains(t, output, "matched &ldquo;Deploys&rdquo;")
+	assert.Equal(t, 1, strings.Count(output, "data-unread"))
+	assert.Contains(t, output, "Mark all read")
+
+	buf.Reset()
+
+	require.NoError(t, r.RenderNotifications(&buf, notifications[1:], true))
+	assert.NotContains(t, buf.String(), "<!DOCTYPE html>")
+	assert.NotContains(t, buf.String(), "Mark all read")
+
+	buf.Reset()
+
+	require.NoError(t, r.RenderNotifications(&buf, nil, true))
+	assert.Contains(t, buf.String(), "No notifications yet.")
+}
+
+func TestRenderNotificationsLink(t *testing.T) {
+	r := New()
+
+	var buf bytes.Buffer
+
+	require.NoError(t, r.RenderNotificationsLink(&buf, 3))
+	assert.Contains(t, buf.String(), `aria-label="3 unread"`)
+
+	buf.Reset()
+
+	require.NoError(t, r.RenderNotificationsLink(&buf, 0))
+	assert.Contains(t, buf.String(), "Inbox")
+	assert.NotContains(t, buf.String(), "unread")
+}
+
+func TestRender_WithNotifications(t *testing.T) {
+	var buf bytes.Buffer
+
+	require.NoError(t, New().RenderStats(&buf, &core.Stats{}, false))
+	assert.NotContains(t, buf.String(), "/notifications/unread")
+
+	buf.Reset()
+
+	require.NoError(t, New(WithNotifications(true)).RenderStats(&buf, &core.Stats{}, false))
+	assert.Contains(t, buf.String(), `hx-get="/notifications/unread"`)
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		want string
+		n    int64
+	}{
+		{n: 0, want: "0 B"},
+		{n: 1023, want: "1023 B"},
+		{n: 1536, want: "1.5 KiB"},
+		{n: 5 << 30, want: "5.0 GiB"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, formatBytes(tt.n))
+	}
+}
+
 func TestRenderNotFound(t *testing.T) {
 	r := New()
 
@@ -492,6 +1345,25 @@ func TestRenderNotFound(t *testing.T) {
 	assert.Contains(t, output, "<!DOCTYPE html>")
 }
 
+func TestRenderMaintenance(t *testing.T) {
+	r := New(WithSiteName("Team Docs"))
+
+	var buf bytes.Buffer
+
+	require.NoError(t, r.RenderMaintenance(&buf, "Migrating to <new> storage", false))
+
+	output := buf.String()
+	assert.Contains(t, output, "<!DOCTYPE html>")
+	assert.Contains(t, output, "Team Docs is undergoing maintenance")
+	assert.Contains(t, output, "Migrating to &lt;new&gt; storage")
+
+	buf.Reset()
+
+	require.NoError(t, r.RenderMaintenance(&buf, "", true))
+	assert.NotContains(t, buf.String(), "<!DOCTYPE html>")
+	assert.Contains(t, buf.String(), "Down for Maintenance")
+}
+
 func TestRenderDoc_OpenAPI_FullPage(t *testing.T) {
 	r := New()
 
@@ -511,7 +1383,7 @@ func TestRenderDoc_OpenAPI_FullPage(t *testing.T) {
 
 	var buf bytes.Buffer
 
-	err := r.RenderDoc(&buf, doc, specJSON, nil, navDocs, false)
+	err := r.RenderDoc(&buf, doc, specJSON, nil, navDocs, nil, false)
 	require.NoError(t, err)
 
 	output := buf.String()
@@ -539,7 +1411,7 @@ func TestRenderDoc_OpenAPI_SpecJSONNotCorrupted(t *testing.T) {
 
 	var buf bytes.Buffer
 
-	err := r.RenderDoc(&buf, doc, specJSON, nil, nil, false)
+	err := r.RenderDoc(&buf, doc, specJSON, nil, nil, nil, false)
 	require.NoError(t, err)
 
 	output := buf.String()
@@ -583,7 +1455,7 @@ func TestRenderDoc_OpenAPI_Partial(t *testing.T) {
 
 	var buf bytes.Buffer
 
-	err := r.RenderDoc(&buf, doc, specJSON, nil, nil, true)
+	err := r.RenderDoc(&buf, doc, specJSON, nil, nil, nil, true)
 	require.NoError(t, err)
 
 	output := buf.String()
@@ -606,7 +1478,7 @@ func TestRenderDoc_MarkdownDefault_WhenContentTypeEmpty(t *testing.T) {
 
 	var buf bytes.Buffer
 
-	err := r.RenderDoc(&buf, doc, htmlContent, nil, nil, false)
+	err := r.RenderDoc(&buf, doc, htmlContent, nil, nil, nil, false)
 	require.NoError(t, err)
 
 	output := buf.String()
@@ -646,10 +1518,95 @@ func TestRenderDoc_ViewSourceLink(t *testing.T) {
 
 			var buf bytes.Buffer
 
-			err := r.RenderDoc(&buf, doc, []byte("<p>Intro</p>"), nil, nil, false)
+			err := r.RenderDoc(&buf, doc, []byte("<p>Intro</p>"), nil, nil, nil, false)
 			require.NoError(t, err)
 
 			assert.Contains(t, buf.String(), tt.expectedURL)
 		})
 	}
 }
+
+func TestRenderDoc_ResumePrompt(t *testing.T) {
+	r := New()
+
+	headings := []core.Heading{{Level: 1, Text: "Runbook", ID: "runbook"}}
+	long := core.Document{Repo: "my-org/repo", Path: "ops/runbook.md", Content: strings.Repeat("line\n", resumeMinLines)}
+
+	var buf bytes.Buffer
+
+	require.NoError(t, r.RenderDoc(&buf, long, []byte(`<h1 id="runbook">Runbook</h1>`), headings, nil, nil, true))
+	assert.Contains(t, buf.String(), `id="resume-prompt"`)
+	assert.Contains(t, buf.String(), `data-progress-key="my-org/repo/ops/runbook.md"`)
+
+	for name, doc := range map[string]struct {
+		headings []core.Heading
+		doc      core.Document
+	}{
+		"short":       {doc: core.Document{Repo: "my-org/repo", Path: "readme.md", Content: "# Readme\n"}, headings: headings},
+		"no headings": {doc: long},
+	} {
+		buf.Reset()
+
+		require.NoError(t, r.RenderDoc(&buf, doc.doc, []byte("<p>Body</p>"), doc.headings, nil, nil, true), name)
+		assert.NotContains(t, buf.String(), `id="resume-prompt"`, name)
+		assert.NotContains(t, buf.String(), "data-progress-key", name)
+	}
+}
+
+func TestRender_WithoutInlineScripts(t *testing.T) {
+	doc := core.Document{ID: "my-org/repo/docs/guide.md", Repo: "my-org/repo", Path: "docs/guide.md"}
+	spec := core.Document{ID: "my-org/repo/api.yaml", Repo: "my-org/repo", Path: "api.yaml", ContentType: core.ContentTypeOpenAPI}
+
+	render := func(t *testing.T, r *Renderer) string {
+		t.Helper()
+
+		var buf bytes.Buffer
+
+		require.NoError(t, r.RenderDoc(&buf, doc, []byte("<p>Body</p>"), nil, nil, nil, false))
+		require.NoError(t, r.RenderDoc(&buf, spec, []byte(`{"openapi":"3.0.0"}`), nil, nil, nil, false))
+		require.NoError(t, r.RenderEditor(&buf, doc))
+
+		return buf.String()
+	}
+
+	t.Run("enabled by default", func(t *testing.T) {
+		output := render(t, New())
+
+		assert.Contains(t, output, "<script>")
+		assert.Contains(t, output, `setAttribute('data-js', '')`)
+		assert.Contains(t, output, "mermaid.min.js")
+		assert.NotContains(t, output, `name="htmx-config"`)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		output := render(t, New(WithoutInlineScripts(true)))
+
+		assert.NotContains(t, output, "<script>")
+		assert.NotContains(t, output, "onclick=")
+		assert.NotContains(t, output, "mermaid.min.js")
+		assert.Contains(t, output, `<script src="/static/js/htmx.min.js"></script>`)
+		assert.Contains(t, output, `<meta name="htmx-config" content='{"includeIndicatorStyles":false,"allowEval":false}'>`)
+		assert.Contains(t, output, `id="openapi-spec"`, "the spec is data, not a script")
+		assert.Contains(t, output, `class="no-js-only text-sm text-gray-500 dark:text-gray-400">The interactive API reference needs JavaScript.`)
+		assert.Contains(t, output, `<a href="/raw/my-org/repo/api.yaml"`)
+		assert.Contains(t, output, "The editor needs JavaScript.")
+	})
+}
+
+func TestRender_KaTeXURL(t *testing.T) {
+	doc := core.Document{ID: "my-org/repo/math.md", Repo: "my-org/repo", Path: "math.md"}
+
+	render := func(t *testing.T, r *Renderer) string {
+		t.Helper()
+
+		var buf bytes.Buffer
+
+		require.NoError(t, r.RenderDoc(&buf, doc, []byte(`<p><span class="math math-inline">x^2</span></p>`), nil, nil, nil, false))
+
+		return buf.String()
+	}
+
+	assert.Contains(t, render(t, New()), `var katexBase = "https://cdn.jsdelivr.net/npm/katex@0.16.22/dist";`)
+	assert.Contains(t, render(t, New(WithKaTeXURL("/assets/katex/"))), `var katexBase = "/assets/katex";`)
+	assert.NotContains(t, render(t, New(WithKaTeXURL("/assets/katex"), WithoutInlineScripts(true))), "katexBase")
+}
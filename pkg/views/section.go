@@ -0,0 +1,111 @@
+package views
+
+import (
+	"io"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+// sectionData is the data passed to the section print template.
+type sectionData struct {
+	GeneratedAt time.Time
+	Repo        string
+	Dir         string
+	Docs        []sectionDoc
+}
+
+// sectionDoc is a document of a section bundle. Anchor identifies the document within the
+// bundle and, on the combined print page, prefixes its heading IDs so they stay unique.
+type sectionDoc struct {
+	Title    string
+	Anchor   string
+	HTML     string
+	Headings []core.Heading
+	Doc      core.Document
+}
+
+// anchorAttrRe matches id attributes and in-page link targets in rendered HTML.
+var anchorAttrRe = regexp.MustCompile(`(\sid="|\shref="#)([^"]*)"`)
+
+// RenderSection renders the documents of a repository section as one printable page, in
+// the order of the sidebar navigation, with a combined table of contents listing each
+// document and its second and third level headings.
+func (v *Renderer) RenderSection(w io.Writer, repo, dir string, docs []core.SectionDocument) error {
+	data := sectionData{Repo: repo, Dir: dir, GeneratedAt: time.Now().UTC(), Docs: orderSection(docs)}
+
+	for i := range data.Docs {
+		d := &data.Docs[i]
+		d.HTML = scopeAnchors(d.HTML, d.Anchor)
+
+		for j := range d.Headings {
+			d.Headings[j].ID = d.Anchor + "-" + d.Headings[j].ID
+		}
+	}
+
+	return execTemplate(w, v.sectionPrint, data)
+}
+
+// orderSection returns the documents of a section in the order the sidebar lists them,
+// numbered by their Anchor, with their second and third level headings.
+func orderSection(docs []core.SectionDocument) []sectionDoc {
+	byPath := make(map[string]*core.SectionDocument, len(docs))
+	metas := make([]core.DocumentMeta, 0, len(docs))
+
+	for i := range docs {
+		byPath[docs[i].Doc.Path] = &docs[i]
+		metas = append(metas, core.DocumentMeta{Path: docs[i].Doc.Path})
+	}
+
+	ordered := make([]sectionDoc, 0, len(docs))
+
+	for _, path := range navOrder(BuildDocTree(metas)) {
+		d := byPath[path]
+
+		title := d.Doc.Title
+		if title == "" {
+			title = d.Doc.Path
+		}
+
+		sd := sectionDoc{
+			Doc:    d.Doc,
+			Title:  title,
+			Anchor: "doc-" + strconv.Itoa(len(ordered)+1),
+			HTML:   string(d.HTML),
+		}
+
+		for _, h := range d.Headings {
+			if h.Level == 2 || h.Level == 3 {
+				sd.Headings = append(sd.Headings, h)
+			}
+		}
+
+		ordered = append(ordered, sd)
+	}
+
+	return ordered
+}
+
+// navOrder returns the document paths of a tree in the order the sidebar lists them.
+func navOrder(nodes []DocNode) []string {
+	var paths []string
+
+	for _, n := range nodes {
+		if n.Doc != nil {
+			paths = append(paths, n.Doc.Path)
+			continue
+		}
+
+		paths = append(paths, navOrder(n.Children)...)
+	}
+
+	return paths
+}
+
+// scopeAnchors prefixes the element IDs and in-page links of a document's HTML, so that
+// documents combined into one page do not share anchors.
+func scopeAnchors(html, prefix string) string {
+	return anchorAttrRe.ReplaceAllString(html, `${1}`+prefix+`-${2}"`)
+}
@@ -0,0 +1,55 @@
+package views
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+func TestRenderSection(t *testing.T) {
+	r := New()
+
+	docs := []core.SectionDocument{
+		{
+			Doc:      core.Document{Repo: "my-org/repo", Path: "ops/alerts/disk.md", Title: "Disk Alerts"},
+			HTML:     []byte(`<h1 id="disk-alerts">Disk Alerts</h1><h2 id="setup">Setup</h2>`),
+			Headings: []core.Heading{{Level: 1, ID: "disk-alerts", Text: "Disk Alerts"}, {Level: 2, ID: "setup", Text: "Setup"}},
+		},
+		{
+			Doc:      core.Document{Repo: "my-org/repo", Path: "ops/runbook.md", CommitSHA: "abcdef1234"},
+			HTML:     []byte(`<h2 id="setup">Setup</h2><p><a href="#setup">again</a> <a href="other.md">other</a></p>`),
+			Headings: []core.Heading{{Level: 2, ID: "setup", Text: "Setup"}},
+		},
+	}
+
+	var buf bytes.Buffer
+
+	require.NoError(t, r.RenderSection(&buf, "my-org/repo", "ops", docs))
+
+	output := buf.String()
+	assert.Contains(t, output, "<title>my-org/repo/ops - Omnidex</title>")
+	assert.Contains(t, output, "2 document(s)")
+
+	runbook := strings.Index(output, `<article class="doc" id="doc-1">`)
+	alerts := strings.Index(output, `<article class="doc" id="doc-2">`)
+
+	require.NotEqual(t, -1, runbook)
+	require.NotEqual(t, -1, alerts)
+	assert.Less(t, strings.Index(output, "ops/runbook.md"), strings.Index(output, "ops/alerts/disk.md"),
+		"documents follow the sidebar order, files before subdirectories")
+
+	assert.Contains(t, output, `<a href="#doc-1">ops/runbook.md</a>`, "untitled documents are listed by path")
+	assert.Contains(t, output, `<a href="#doc-2">Disk Alerts</a>`)
+	assert.Contains(t, output, `<a href="#doc-1-setup">Setup</a>`)
+	assert.Contains(t, output, `<a href="#doc-2-setup">Setup</a>`)
+	assert.NotContains(t, output, `href="#doc-2-disk-alerts"`, "level 1 headings are not repeated in the contents")
+
+	assert.Contains(t, output, `<h2 id="doc-1-setup">Setup</h2><p><a href="#doc-1-setup">again</a> <a href="other.md">other</a>`)
+	assert.Contains(t, output, `<h1 id="doc-2-disk-alerts">`)
+	assert.Contains(t, output, "abcdef1")
+}
@@ -0,0 +1,97 @@
+package views
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+// ldThing is a schema.org entity referenced by name, such as an author or publisher.
+type ldThing struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+// ldListItem is an entry of a schema.org BreadcrumbList.
+type ldListItem struct {
+	Type     string `json:"@type"`
+	Name     string `json:"name"`
+	Item     string `json:"item"`
+	Position int    `json:"position"`
+}
+
+// ldBreadcrumbs is a schema.org BreadcrumbList.
+type ldBreadcrumbs struct {
+	Type  string       `json:"@type"`
+	Items []ldListItem `json:"itemListElement"`
+}
+
+// ldArticle is the schema.org TechArticle describing a document page.
+type ldArticle struct {
+	Author       ldThing       `json:"author"`
+	Publisher    ldThing       `json:"publisher"`
+	IsPartOf     ldThing       `json:"isPartOf"`
+	Context      string        `json:"@context"`
+	Type         string        `json:"@type"`
+	Headline     string        `json:"headline"`
+	Description  string        `json:"description,omitempty"`
+	Keywords     string        `json:"keywords,omitempty"`
+	URL          string        `json:"url"`
+	DateModified string        `json:"dateModified,omitempty"`
+	Version      string        `json:"version,omitempty"`
+	Breadcrumb   ldBreadcrumbs `json:"breadcrumb"`
+}
+
+// docStructuredData returns the schema.org TechArticle JSON-LD of a document page, so
+// that crawlers and intranet search appliances index it with its title, summary, tags,
+// modification date, author and breadcrumbs. The author is the owner of the repository.
+// URLs are relative to the portal, which JSON-LD processors resolve against the page URL.
+func docStructuredData(doc *core.Document, siteName string) (template.JS, error) {
+	repoURL := "/docs/" + (&url.URL{Path: doc.Repo}).EscapedPath() + "/"
+	docURL := repoURL + (&url.URL{Path: doc.Path}).EscapedPath()
+
+	owner, _, _ := strings.Cut(doc.Repo, "/")
+
+	title := doc.Title
+	if title == "" {
+		title = doc.Path
+	}
+
+	article := ldArticle{
+		Context:     "https://schema.org",
+		Type:        "TechArticle",
+		Headline:    title,
+		Description: doc.Summary,
+		Keywords:    strings.Join(doc.Tags, ", "),
+		URL:         docURL,
+		Version:     doc.CommitSHA,
+		Author:      ldThing{Type: "Organization", Name: owner},
+		Publisher:   ldThing{Type: "Organization", Name: siteName},
+		IsPartOf:    ldThing{Type: "CreativeWorkSeries", Name: doc.Repo},
+		Breadcrumb: ldBreadcrumbs{
+			Type: "BreadcrumbList",
+			Items: []ldListItem{
+				{Type: "ListItem", Position: 1, Name: siteName, Item: "/"},
+				{Type: "ListItem", Position: 2, Name: doc.Repo, Item: repoURL},
+				{Type: "ListItem", Position: 3, Name: title, Item: docURL},
+			},
+		},
+	}
+
+	if !doc.UpdatedAt.IsZero() {
+		article.DateModified = doc.UpdatedAt.UTC().Format(time.RFC3339)
+	}
+
+	// json.Marshal escapes <, > and &, so the data cannot close the script element.
+	data, err := json.Marshal(article)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal structured data: %w", err)
+	}
+
+	return template.JS(data), nil //nolint:gosec // JSON with HTML characters escaped
+}
@@ -0,0 +1,61 @@
+package views
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ksysoev/omnidex/pkg/core"
+)
+
+var ldScript = regexp.MustCompile(`<script type="application/ld\+json">(.*)</script>`)
+
+func TestRenderDoc_StructuredData(t *testing.T) {
+	r := New(WithSiteName("Team Docs"))
+
+	doc := core.Document{
+		Repo:      "my-org/repo",
+		Path:      "docs/run book.md",
+		Title:     "Runbook </script><b>",
+		Summary:   "Restart the \"billing\" service.",
+		CommitSHA: "abc1234",
+		UpdatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	var buf bytes.Buffer
+
+	require.NoError(t, r.RenderDoc(&buf, doc, []byte("<p>Body</p>"), nil, nil, nil, false))
+
+	match := ldScript.FindStringSubmatch(buf.String())
+	require.Len(t, match, 2, "the page head has JSON-LD")
+	assert.NotContains(t, match[1], "</script>", "the title cannot close the script element")
+
+	var article map[string]any
+
+	require.NoError(t, json.Unmarshal([]byte(match[1]), &article))
+	assert.Equal(t, "TechArticle", article["@type"])
+	assert.Equal(t, "Runbook </script><b>", article["headline"])
+	assert.Equal(t, `Restart the "billing" service.`, article["description"])
+	assert.Equal(t, "2026-01-02T03:04:05Z", article["dateModified"])
+	assert.Equal(t, "/docs/my-org/repo/docs/run%20book.md", article["url"])
+	assert.Equal(t, map[string]any{"@type": "Organization", "name": "my-org"}, article["author"])
+
+	crumbs := article["breadcrumb"].(map[string]any)["itemListElement"].([]any)
+	require.Len(t, crumbs, 3)
+	assert.Equal(t, "Team Docs", crumbs[0].(map[string]any)["name"])
+	assert.Equal(t, "/docs/my-org/repo/", crumbs[1].(map[string]any)["item"])
+
+	assert.Contains(t, buf.String(), `<meta property="og:title" content="Runbook &lt;/script&gt;&lt;b&gt;">`)
+	assert.Contains(t, buf.String(), `<meta property="og:description" content="Restart the &#34;billing&#34; service.">`)
+	assert.Contains(t, buf.String(), `<meta name="description" content="Restart the &#34;billing&#34; service.">`)
+
+	buf.Reset()
+
+	require.NoError(t, r.RenderHome(&buf, nil, false))
+	assert.NotContains(t, buf.String(), "application/ld+json", "only document pages have JSON-LD")
+}
@@ -6,10 +6,14 @@ const layoutHeader = `<!DOCTYPE html>
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Omnidex - Documentation Portal</title>
+    <title>{{siteName}} - Documentation Portal</title>
+    {{block "structuredData" .}}{{end}}
+    {{if inlineScripts}}
     <!-- FOUC prevention: apply stored or system theme before any paint -->
     <script>
     (function(){
+        // Marks scripts as running, which shows the controls that need them (.js-only).
+        document.documentElement.setAttribute('data-js', '');
         var s = null;
         try {
             s = window.localStorage ? window.localStorage.getItem('theme') : null;
@@ -21,10 +25,28 @@ const layoutHeader = `<!DOCTYPE html>
         } else if (window.matchMedia && window.matchMedia('(prefers-color-scheme: dark)').matches) {
             document.documentElement.setAttribute('data-theme', 'dark');
         }
+        // Reading preferences are applied before paint too, so the layout does not jump.
+        var prefs = null;
+        try {
+            prefs = JSON.parse(window.localStorage.getItem('readingPrefs') || 'null');
+        } catch (e) {
+            prefs = null;
+        }
+        if (prefs && typeof prefs === 'object') {
+            ['contentWidth', 'fontSize', 'lineHeight', 'readerMode'].forEach(function(k) {
+                if (typeof prefs[k] === 'string' && /^[a-z]+$/.test(prefs[k])) {
+                    document.documentElement.setAttribute('data-' + k.replace(/[A-Z]/g, function(c) { return '-' + c.toLowerCase(); }), prefs[k]);
+                }
+            });
+        }
     })();
     </script>
+    {{else}}
+    <!-- Inline scripts are disabled: keep htmx from injecting styles or evaluating code. -->
+    <meta name="htmx-config" content='{"includeIndicatorStyles":false,"allowEval":false}'>
+    {{end}}
     <script src="/static/js/htmx.min.js"></script>
-    <script src="https://cdn.jsdelivr.net/npm/mermaid@11.12.3/dist/mermaid.min.js" integrity="sha384-jFhLSLFn4m565eRAS0CDMWubMqOtfZWWbE8kqgGdU+VHbJ3B2G/4X8u+0BM8MtdU" crossorigin="anonymous"></script>
+    {{if inlineScripts}}<script src="https://cdn.jsdelivr.net/npm/mermaid@11.12.3/dist/mermaid.min.js" integrity="sha384-jFhLSLFn4m565eRAS0CDMWubMqOtfZWWbE8kqgGdU+VHbJ3B2G/4X8u+0BM8MtdU" crossorigin="anonymous"></script>{{end}}
     <link rel="stylesheet" href="/static/css/style.css">
     <style>
         /* Chroma syntax highlighting — github-dark theme */
@@ -105,7 +127,10 @@ const layoutHeader = `<!DOCTYPE html>
         /* GenericTraceback */ .chroma .gt { color: #ff7b72 }
         /* GenericUnderline */ .chroma .gl { text-decoration: underline }
         /* TextWhitespace */ .chroma .w { color: #6e7681 }
+        /* Display formulas, typeset or shown as TeX source */
+        .prose .math-display { display: block; margin: 1em 0; overflow-x: auto; text-align: center; }
     </style>
+    {{if inlineScripts}}
     <script>
         /* ================================================================
            Theme helpers
@@ -165,6 +190,7 @@ const layoutHeader = `<!DOCTYPE html>
                 try { id = decodeURIComponent(id); } catch (e) { /* use raw id */ }
                 var target = document.getElementById(id);
                 if (target) {
+                    openCollapsedAncestors(target);
                     var scrollBehavior = 'smooth';
                     if (window.matchMedia && window.matchMedia('(prefers-reduced-motion: reduce)').matches) {
                         scrollBehavior = 'auto';
@@ -173,6 +199,26 @@ const layoutHeader = `<!DOCTYPE html>
                 }
             }
         }
+        /* Expand any collapsed <details> sections containing el, so deep links and
+           search hits that target collapsed content are visible. */
+        function openCollapsedAncestors(el) {
+            var node = el.parentElement;
+            while (node) {
+                if (node.tagName === 'DETAILS' && !node.open) {
+                    node.open = true;
+                }
+                node = node.parentElement;
+            }
+        }
+        window.addEventListener('hashchange', function() {
+            var id = window.location.hash.slice(1);
+            try { id = decodeURIComponent(id); } catch (e) { /* use raw id */ }
+            var target = id ? document.getElementById(id) : null;
+            if (target) {
+                openCollapsedAncestors(target);
+                target.scrollIntoView();
+            }
+        });
         function initScrollSpy() {
             if (window._tocObserver) {
                 window._tocObserver.disconnect();
@@ -261,20 +307,27 @@ const layoutHeader = `<!DOCTYPE html>
             });
         }
         document.addEventListener('DOMContentLoaded', function() {
-            initScrollSpy(); scrollToHash(); initHeadingAnchors(); initThemeToggle();
+            initScrollSpy(); scrollToHash(); initHeadingAnchors(); initThemeToggle(); syncReadingControls(); initResumePrompt();
+            initLiveUpdates(); initPresence();
             if (typeof mermaid !== 'undefined') {
                 saveMermaidSources(document);
                 mermaid.run().then(initMermaidExpand).catch(function(e) {
                     console.error('Mermaid rendering failed:', e);
                     initMermaidExpand();
                 });
+            } else {
+                initMermaidExpand();
             }
             initImageExpand();
+            renderMath(document);
         });
         document.addEventListener('htmx:afterSwap', function(event) {
             initScrollSpy();
             scrollToHash();
             initHeadingAnchors();
+            syncReadingControls();
+            initResumePrompt();
+            initPresence();
             if (typeof mermaid !== 'undefined') {
                 var target = event.detail.elt;
                 saveMermaidSources(target);
@@ -290,9 +343,125 @@ const layoutHeader = `<!DOCTYPE html>
                 initMermaidExpand();
             }
             initImageExpand();
+            renderMath(event.detail.elt);
         });
         document.addEventListener('htmx:beforeSwap', function() { closeMediaModal(); });
 
+        /* Instant navigation: documents linked from the sidebar are prefetched by the
+           service worker on hover or focus and served from memory when HTMX requests them.
+           Prefetching is skipped when the user has asked the browser to save data. */
+        (function() {
+            if (!('serviceWorker' in navigator)) return;
+            navigator.serviceWorker.register('/sw.js').catch(function(e) {
+                console.warn('Service worker registration failed:', e);
+            });
+            var HOVER_DELAY_MS = 65;
+            var requested = new Set();
+            var timer = null;
+            function saveData() {
+                return navigator.connection && navigator.connection.saveData;
+            }
+            function prefetchLink(link) {
+                var worker = navigator.serviceWorker.controller;
+                var url = link.getAttribute('hx-get');
+                if (!worker || !url || saveData() || requested.has(url)) return;
+                if (url === window.location.pathname) return;
+                requested.add(url);
+                worker.postMessage({ type: 'prefetch', url: url });
+            }
+            function onIntent(e) {
+                var link = e.target.closest && e.target.closest('.doc-sidebar a[hx-get^="/docs/"]');
+                if (!link) return;
+                clearTimeout(timer);
+                timer = setTimeout(function() { prefetchLink(link); }, e.type === 'focusin' ? 0 : HOVER_DELAY_MS);
+            }
+            document.addEventListener('mouseover', onIntent);
+            document.addEventListener('focusin', onIntent);
+            document.addEventListener('mouseout', function(e) {
+                if (e.target.closest && e.target.closest('.doc-sidebar a')) clearTimeout(timer);
+            });
+            // Prefetched responses are single-use, so links may be prefetched again after navigating.
+            document.addEventListener('htmx:afterSwap', function() { requested.clear(); });
+        })();
+
+        /* Hover previews: resting the pointer on a search result or a sidebar link loads an
+           excerpt of the document into a popover through HTMX, so readers can check that a
+           document is relevant before opening it. */
+        (function() {
+            var SHOW_DELAY_MS = 400;
+            var GAP_PX = 8;
+            var timer = null;
+            var current = null;
+            function popover() { return document.getElementById('doc-preview'); }
+            function hidePreview() {
+                clearTimeout(timer);
+                current = null;
+                var el = popover();
+                if (el) el.hidden = true;
+            }
+            function place(el, link) {
+                var rect = link.getBoundingClientRect();
+                el.hidden = false;
+                var top = rect.bottom + GAP_PX;
+                if (top + el.offsetHeight > window.innerHeight) top = Math.max(GAP_PX, rect.top - el.offsetHeight - GAP_PX);
+                el.style.top = top + 'px';
+                el.style.left = Math.max(GAP_PX, Math.min(rect.left, window.innerWidth - el.offsetWidth - GAP_PX)) + 'px';
+            }
+            function showPreview(link) {
+                var el = popover();
+                var url = link.getAttribute('data-preview');
+                if (!el || !url || typeof htmx === 'undefined') return;
+                current = link;
+                el.hidden = true;
+                el.innerHTML = '';
+                htmx.ajax('GET', url, {target: el, swap: 'innerHTML'}).then(function() {
+                    if (current === link && el.innerHTML.trim() !== '') place(el, link);
+                });
+            }
+            document.addEventListener('mouseover', function(e) {
+                var link = e.target.closest && e.target.closest('[data-preview]');
+                if (!link || link === current) return;
+                clearTimeout(timer);
+                timer = setTimeout(function() { showPreview(link); }, SHOW_DELAY_MS);
+            });
+            document.addEventListener('mouseout', function(e) {
+                var link = e.target.closest && e.target.closest('[data-preview]');
+                if (link && !link.contains(e.relatedTarget)) hidePreview();
+            });
+            document.addEventListener('keydown', function(e) { if (e.key === 'Escape') hidePreview(); });
+            document.addEventListener('htmx:beforeRequest', function(e) {
+                if (e.detail.elt && e.detail.elt.closest && e.detail.elt.closest('[data-preview]')) hidePreview();
+            });
+        })();
+
+        /* ================================================================
+           Search suggestions: the dropdown of the navigation search box closes
+           when a page loads, on Escape and on clicks outside the search box.
+           ================================================================ */
+        (function() {
+            function closeSuggestions() {
+                var box = document.getElementById('search-suggestions');
+                if (box) box.innerHTML = '';
+            }
+            document.addEventListener('htmx:beforeRequest', function(e) {
+                if (e.detail.target && e.detail.target.id === 'main-content') closeSuggestions();
+            });
+            document.addEventListener('keydown', function(e) { if (e.key === 'Escape') closeSuggestions(); });
+            document.addEventListener('click', function(e) {
+                if (!e.target.closest || !e.target.closest('#nav-search')) closeSuggestions();
+            });
+        })();
+
+        /* ================================================================
+           Ranking experiments: while one runs, clicks on search results are
+           counted for the ranking variant of the reader's session.
+           ================================================================ */
+        document.addEventListener('click', function(e) {
+            var link = e.target.closest && e.target.closest('[data-search-variant] a[data-rank]');
+            if (!link || !navigator.sendBeacon) return;
+            navigator.sendBeacon('/api/v1/search/click', new URLSearchParams({rank: link.dataset.rank}));
+        });
+
         /* ================================================================
            Media fullscreen viewer (mermaid diagrams + images)
            ================================================================ */
@@ -652,6 +821,447 @@ const layoutHeader = `<!DOCTYPE html>
             });
         }
 
+        /* Reading preferences: content width, font size, line height and reader mode.
+           Values are stored in localStorage and mirrored as data-* attributes on <html>,
+           which the stylesheet uses to adjust the document layout. */
+        var READING_PREF_ATTRS = {
+            contentWidth: 'data-content-width',
+            fontSize: 'data-font-size',
+            lineHeight: 'data-line-height',
+            readerMode: 'data-reader-mode'
+        };
+        function loadReadingPrefs() {
+            try {
+                var prefs = JSON.parse(localStorage.getItem('readingPrefs') || 'null');
+                return (prefs && typeof prefs === 'object') ? prefs : {};
+            } catch (e) {
+                return {};
+            }
+        }
+        function setReadingPref(key, value) {
+            if (!READING_PREF_ATTRS[key]) return;
+            var prefs = loadReadingPrefs();
+            prefs[key] = value;
+            document.documentElement.setAttribute(READING_PREF_ATTRS[key], value);
+            try {
+                localStorage.setItem('readingPrefs', JSON.stringify(prefs));
+            } catch (e) {
+                // Ignore storage failures; preferences still apply for this page view.
+            }
+            syncReadingControls();
+        }
+        function syncReadingControls() {
+            var html = document.documentElement;
+            document.querySelectorAll('[data-reading-pref]').forEach(function(el) {
+                var key = el.getAttribute('data-reading-pref');
+                var current = html.getAttribute(READING_PREF_ATTRS[key]) || el.getAttribute('data-reading-default');
+                el.setAttribute('aria-pressed', el.getAttribute('data-reading-value') === current ? 'true' : 'false');
+            });
+            var toggle = document.getElementById('reader-mode-toggle');
+            if (toggle) {
+                toggle.setAttribute('aria-pressed', html.getAttribute('data-reader-mode') === 'on' ? 'true' : 'false');
+            }
+        }
+        document.addEventListener('click', function(e) {
+            var opt = e.target.closest('[data-reading-pref]');
+            if (opt) {
+                setReadingPref(opt.getAttribute('data-reading-pref'), opt.getAttribute('data-reading-value'));
+                return;
+            }
+            if (e.target.closest('#reader-mode-toggle')) {
+                var on = document.documentElement.getAttribute('data-reader-mode') === 'on';
+                setReadingPref('readerMode', on ? 'off' : 'on');
+            }
+        });
+
+        /* Reading progress: long documents remember the reader's position as the last
+           heading above the top of the viewport plus the distance scrolled past it, so the
+           position survives layout changes and edits elsewhere in the document. Returning
+           to the document offers to resume there; reaching the end forgets the position.
+           Positions of the most recently read documents are kept in localStorage. */
+        var PROGRESS_STORAGE_KEY = 'readingProgress';
+        var PROGRESS_MAX_DOCS = 50;
+        var progressTimer = null;
+        function loadProgress() {
+            try {
+                var all = JSON.parse(localStorage.getItem(PROGRESS_STORAGE_KEY) || 'null');
+                return (all && typeof all === 'object') ? all : {};
+            } catch (e) {
+                return {};
+            }
+        }
+        function storeProgress(all) {
+            var keys = Object.keys(all);
+            if (keys.length > PROGRESS_MAX_DOCS) {
+                keys.sort(function(a, b) { return (all[a].at || 0) - (all[b].at || 0); });
+                keys.slice(0, keys.length - PROGRESS_MAX_DOCS).forEach(function(k) { delete all[k]; });
+            }
+            try {
+                localStorage.setItem(PROGRESS_STORAGE_KEY, JSON.stringify(all));
+            } catch (e) {
+                // Ignore storage failures; reading works without a remembered position.
+            }
+        }
+        function recordProgress() {
+            var prose = document.querySelector('[data-progress-key]');
+            if (!prose) return;
+            var key = prose.getAttribute('data-progress-key');
+            var all = loadProgress();
+            if (window.innerHeight + window.scrollY >= document.documentElement.scrollHeight - 80) {
+                if (all[key]) {
+                    delete all[key];
+                    storeProgress(all);
+                }
+                return;
+            }
+            var anchor = null;
+            var headings = prose.querySelectorAll('h1[id], h2[id], h3[id]');
+            for (var i = 0; i < headings.length; i++) {
+                if (headings[i].getBoundingClientRect().top > 1) break;
+                anchor = headings[i];
+            }
+            if (!anchor) return;
+            all[key] = {
+                anchor: anchor.id,
+                offset: Math.round(-anchor.getBoundingClientRect().top),
+                title: anchor.textContent.trim(),
+                at: Date.now()
+            };
+            storeProgress(all);
+            var prompt = document.getElementById('resume-prompt');
+            if (prompt) prompt.hidden = true;
+        }
+        window.addEventListener('scroll', function() {
+            if (progressTimer) return;
+            progressTimer = setTimeout(function() { progressTimer = null; recordProgress(); }, 500);
+        }, { passive: true });
+        function initResumePrompt() {
+            var prose = document.querySelector('[data-progress-key]');
+            var prompt = document.getElementById('resume-prompt');
+            if (!prose || !prompt || window.location.hash) return;
+            var saved = loadProgress()[prose.getAttribute('data-progress-key')];
+            if (!saved || !saved.anchor || !document.getElementById(saved.anchor)) return;
+            var section = prompt.querySelector('[data-resume-section]');
+            if (section) section.textContent = saved.title ? ' in \u201c' + saved.title + '\u201d' : '';
+            prompt.hidden = false;
+        }
+        document.addEventListener('click', function(e) {
+            var btn = e.target.closest('[data-resume]');
+            if (!btn) return;
+            var prose = document.querySelector('[data-progress-key]');
+            var prompt = document.getElementById('resume-prompt');
+            if (prompt) prompt.hidden = true;
+            if (!prose) return;
+            var key = prose.getAttribute('data-progress-key');
+            var all = loadProgress();
+            var saved = all[key];
+            if (btn.getAttribute('data-resume') === 'dismiss') {
+                delete all[key];
+                storeProgress(all);
+                return;
+            }
+            var target = saved ? document.getElementById(saved.anchor) : null;
+            if (!target) return;
+            openCollapsedAncestors(target);
+            window.scrollTo({ top: target.getBoundingClientRect().top + window.scrollY + (saved.offset || 0) });
+        });
+
+        /* Live updates: the server streams document changes as server-sent events. A page
+           showing a changed document offers to reload it, and the home page refreshes its
+           repository list, so readers do not keep acting on a stale page. */
+        var liveHomeTimer = null;
+        function showUpdateToast(message) {
+            var toast = document.getElementById('update-toast');
+            if (!toast) return;
+            toast.querySelector('[data-update-message]').textContent = message;
+            toast.hidden = false;
+        }
+        function refreshLiveHome() {
+            if (liveHomeTimer) return;
+            liveHomeTimer = setTimeout(function() {
+                liveHomeTimer = null;
+                if (document.querySelector('[data-live-home]') && typeof htmx !== 'undefined') {
+                    htmx.ajax('GET', '/', {target: '#main-content', swap: 'innerHTML'});
+                }
+            }, 1000);
+        }
+        function initLiveUpdates() {
+            if (!window.EventSource) return;
+            var source = new EventSource('/events');
+            source.addEventListener('document', function(e) {
+                var ev;
+                try { ev = JSON.parse(e.data); } catch (ex) { return; }
+                if (isLiveDoc(ev)) {
+                    showUpdateToast(ev.type === 'deleted' ? 'This page was moved or removed.' : 'This page was updated.');
+                }
+                if (document.querySelector('[data-live-home]')) refreshLiveHome();
+            });
+            source.addEventListener('incident', function(e) {
+                var ev;
+                try { ev = JSON.parse(e.data); } catch (ex) { return; }
+                if (isLiveDoc(ev)) initPresence();
+            });
+            source.addEventListener('presence', function(e) {
+                var ev;
+                try { ev = JSON.parse(e.data); } catch (ex) { return; }
+                if (presence.doc && isLiveDoc(ev)) renderPresence(ev.viewers || []);
+            });
+        }
+        function liveDoc() {
+            var doc = document.querySelector('[data-live-repo]');
+            if (!doc) return null;
+            return {repo: doc.getAttribute('data-live-repo'), path: doc.getAttribute('data-live-path')};
+        }
+        function isLiveDoc(ev) {
+            var doc = liveDoc();
+            return !!doc && doc.repo === ev.repo && doc.path === ev.path;
+        }
+
+        /* Incident presence: while a document is flagged as in use during an incident, its
+           readers send heartbeats with the section they are reading. The presence bar lists
+           the responders on the page, and the headings and table of contents entries of the
+           sections teammates are on are highlighted. */
+        var PRESENCE_HEARTBEAT_MS = 15000;
+        var presence = {doc: null, timer: null, section: null, incident: null};
+        function presenceViewerID() {
+            var id = null;
+            try { id = sessionStorage.getItem('presenceId'); } catch (e) {}
+            if (!id) {
+                id = Math.random().toString(36).slice(2) + Date.now().toString(36);
+                try { sessionStorage.setItem('presenceId', id); } catch (e) {}
+            }
+            return id;
+        }
+        function presenceName() {
+            try { return localStorage.getItem('presenceName') || ''; } catch (e) { return ''; }
+        }
+        function sendPresence(leave) {
+            if (!presence.doc) return;
+            var body = JSON.stringify({
+                repo: presence.doc.repo, path: presence.doc.path, id: presenceViewerID(),
+                name: presenceName(), section: presence.section || '', leave: !!leave
+            });
+            if (leave && navigator.sendBeacon) {
+                navigator.sendBeacon('/presence', body);
+                return;
+            }
+            fetch('/presence', {method: 'POST', body: body, keepalive: !!leave}).then(function(resp) {
+                if (resp.status === 404) stopPresence();
+            }).catch(function() {});
+        }
+        function stopPresence() {
+            if (presence.timer) clearInterval(presence.timer);
+            presence.doc = null;
+            presence.timer = null;
+            presence.section = null;
+            var bar = document.getElementById('presence-bar');
+            if (bar) bar.hidden = true;
+            document.querySelectorAll('.presence-highlight').forEach(function(el) {
+                el.classList.remove('presence-highlight');
+                el.removeAttribute('data-presence-names');
+            });
+        }
+        function initPresence() {
+            var doc = liveDoc();
+            if (presence.doc && (!doc || doc.repo !== presence.doc.repo || doc.path !== presence.doc.path)) {
+                sendPresence(true);
+            }
+            stopPresence();
+            if (!doc || !window.fetch) return;
+            var query = '?repo=' + encodeURIComponent(doc.repo) + '&path=' + encodeURIComponent(doc.path);
+            fetch('/presence' + query).then(function(resp) {
+                return resp.ok ? resp.json() : null;
+            }).then(function(data) {
+                if (!data || !isLiveDoc(doc)) return;
+                presence.doc = doc;
+                presence.incident = data.incident;
+                presence.section = window._tocActiveId || null;
+                renderPresence(data.viewers || []);
+                sendPresence(false);
+                presence.timer = setInterval(function() {
+                    presence.section = window._tocActiveId || presence.section;
+                    sendPresence(false);
+                }, PRESENCE_HEARTBEAT_MS);
+            }).catch(function() {});
+        }
+        function renderPresence(viewers) {
+            var bar = document.getElementById('presence-bar');
+            if (!bar) return;
+            var self = presenceViewerID();
+            var others = viewers.filter(function(v) { return v.id !== self; });
+            var name = presence.incident && presence.incident.name;
+            bar.querySelector('[data-presence-incident]').textContent = name ? 'Incident ' + name : 'Active incident';
+            bar.querySelector('[data-presence-count]').textContent = others.length === 1 ? '1 teammate viewing' : others.length + ' teammates viewing';
+            bar.querySelector('[data-presence-names]').textContent = others.map(function(v) { return v.name || 'Anonymous'; }).join(', ');
+            bar.hidden = false;
+            var sections = {};
+            others.forEach(function(v) {
+                if (!v.section) return;
+                (sections[v.section] = sections[v.section] || []).push(v.name || 'Anonymous');
+            });
+            document.querySelectorAll('.presence-highlight').forEach(function(el) {
+                el.classList.remove('presence-highlight');
+                el.removeAttribute('data-presence-names');
+            });
+            Object.keys(sections).forEach(function(id) {
+                var escapedId = (window.CSS && window.CSS.escape) ? window.CSS.escape(id) : id;
+                var label = sections[id].join(', ');
+                [document.getElementById(id), document.querySelector('[data-toc-link="' + escapedId + '"]')].forEach(function(el) {
+                    if (!el) return;
+                    el.classList.add('presence-highlight');
+                    el.setAttribute('data-presence-names', label);
+                });
+            });
+        }
+        window.addEventListener('scroll', function() {
+            if (!presence.doc || !window._tocActiveId || window._tocActiveId === presence.section) return;
+            presence.section = window._tocActiveId;
+            sendPresence(false);
+        }, {passive: true});
+        window.addEventListener('pagehide', function() { sendPresence(true); });
+        document.addEventListener('click', function(e) {
+            if (e.target.closest('[data-update-reload]')) {
+                window.location.reload();
+                return;
+            }
+            if (e.target.closest('[data-update-dismiss]')) {
+                document.getElementById('update-toast').hidden = true;
+                return;
+            }
+            if (e.target.closest('[data-presence-rename]')) {
+                var name = window.prompt('Your name, shown to teammates viewing this page:', presenceName());
+                if (name === null) return;
+                try { localStorage.setItem('presenceName', name.trim().slice(0, 64)); } catch (ex) {}
+                sendPresence(false);
+            }
+        });
+        document.addEventListener('htmx:afterSwap', function(event) {
+            var toast = document.getElementById('update-toast');
+            if (toast && event.detail.target && event.detail.target.id === 'main-content') toast.hidden = true;
+        });
+
+        /* Share menu: copies a link to the current section, the raw markdown source, or the
+           rendered HTML of the document. Rendered HTML is copied as rich text where the
+           Clipboard API supports it, with relative URLs made absolute so it can be pasted
+           elsewhere. */
+        function copyTextFallback(text) {
+            var ta = document.createElement('textarea');
+            ta.value = text;
+            ta.style.position = 'fixed';
+            ta.style.opacity = '0';
+            document.body.appendChild(ta);
+            ta.select();
+            var ok = false;
+            try { ok = document.execCommand('copy'); } catch (ex) { ok = false; }
+            document.body.removeChild(ta);
+            return ok ? Promise.resolve() : Promise.reject(new Error('copy failed'));
+        }
+        function copyText(text) {
+            if (navigator.clipboard && navigator.clipboard.writeText) {
+                return navigator.clipboard.writeText(text).catch(function() { return copyTextFallback(text); });
+            }
+            return copyTextFallback(text);
+        }
+        function absolutizeHTML(html) {
+            var tpl = document.createElement('template');
+            tpl.innerHTML = html;
+            tpl.content.querySelectorAll('[href], [src]').forEach(function(el) {
+                ['href', 'src'].forEach(function(attr) {
+                    var v = el.getAttribute(attr);
+                    if (v === null) return;
+                    try { el.setAttribute(attr, new URL(v, window.location.href).href); } catch (ex) {}
+                });
+            });
+            return tpl.innerHTML;
+        }
+        function copyHTML(html) {
+            var text = (function() {
+                var tpl = document.createElement('template');
+                tpl.innerHTML = html;
+                return tpl.content.textContent;
+            })();
+            if (navigator.clipboard && navigator.clipboard.write && typeof ClipboardItem !== 'undefined') {
+                var item = new ClipboardItem({
+                    'text/html': new Blob([html], { type: 'text/html' }),
+                    'text/plain': new Blob([text], { type: 'text/plain' })
+                });
+                return navigator.clipboard.write([item]).catch(function() { return copyText(html); });
+            }
+            return copyText(html);
+        }
+        function shareLink() {
+            var id = window._tocActiveId || decodeURIComponent(window.location.hash.replace(/^#/, ''));
+            var url = window.location.origin + window.location.pathname;
+            return id ? url + '#' + encodeURIComponent(id) : url;
+        }
+        function fetchShareSource(url) {
+            return fetch(url, { credentials: 'same-origin' }).then(function(resp) {
+                if (!resp.ok) throw new Error('HTTP ' + resp.status);
+                return resp.text();
+            });
+        }
+        document.addEventListener('click', function(e) {
+            var opt = e.target.closest('[data-share]');
+            if (!opt) return;
+            var kind = opt.getAttribute('data-share');
+            var job;
+            if (kind === 'link') {
+                job = copyText(shareLink());
+            } else if (kind === 'markdown') {
+                job = fetchShareSource(opt.getAttribute('data-share-url')).then(copyText);
+            } else if (kind === 'html') {
+                job = fetchShareSource(opt.getAttribute('data-share-url')).then(function(html) {
+                    return copyHTML(absolutizeHTML(html));
+                });
+            } else {
+                return;
+            }
+            job.then(function() {
+                opt.classList.add('copied');
+                setTimeout(function() {
+                    opt.classList.remove('copied');
+                    var menu = opt.closest('details');
+                    if (menu) menu.removeAttribute('open');
+                }, 1200);
+            }).catch(function(err) {
+                console.error('Share action failed:', err);
+                opt.classList.add('failed');
+                setTimeout(function() { opt.classList.remove('failed'); }, 2000);
+            });
+        });
+
+        /* Typeset formulas with KaTeX, loading it on the first page that has any.
+           Without it, formulas stay readable as their TeX source. */
+        var katexBase = {{katexURL}};
+        function renderMath(root) {
+            var nodes = root.querySelectorAll('.prose .math:not([data-math-rendered])');
+            if (nodes.length === 0) return;
+            if (typeof katex === 'undefined') {
+                loadKaTeX();
+                return;
+            }
+            nodes.forEach(function(el) {
+                el.setAttribute('data-math-rendered', 'true');
+                katex.render(el.textContent, el, {
+                    displayMode: el.classList.contains('math-display'),
+                    throwOnError: false
+                });
+            });
+        }
+        function loadKaTeX() {
+            if (document.querySelector('script[data-katex]')) return;
+            var css = document.createElement('link');
+            css.rel = 'stylesheet';
+            css.href = katexBase + '/katex.min.css';
+            document.head.appendChild(css);
+            var script = document.createElement('script');
+            script.src = katexBase + '/katex.min.js';
+            script.async = true;
+            script.setAttribute('data-katex', 'true');
+            script.onload = function() { renderMath(document); };
+            document.head.appendChild(script);
+        }
+
         /* Stash Mermaid source text before rendering so we can re-render on theme change */
         function saveMermaidSources(root) {
             var pres = root.querySelectorAll('.prose pre.mermaid:not([data-mermaid-source])');
@@ -691,7 +1301,7 @@ const layoutHeader = `<!DOCTYPE html>
         });
 
         function initMermaidExpand() {
-            var containers = document.querySelectorAll('.prose pre.mermaid');
+            var containers = document.querySelectorAll('.prose pre.mermaid, .prose div.mermaid-static');
             containers.forEach(function(pre) {
                 if (pre.querySelector('.mermaid-expand-btn')) return;
                 var svg = pre.querySelector(':scope > svg');
@@ -739,19 +1349,28 @@ const layoutHeader = `<!DOCTYPE html>
             });
         }
     </script>
+    {{end}}
 </head>
 <body class="bg-gray-50 dark:bg-gray-950 min-h-screen flex flex-col">
     <nav class="bg-white dark:bg-gray-900 border-b border-gray-200 dark:border-gray-700 px-6 py-3">
         <div class="max-w-7xl mx-auto flex items-center justify-between">
             <a href="/" class="text-xl font-bold text-gray-900 dark:text-gray-100" hx-get="/" hx-target="#main-content" hx-push-url="true">
-                Omnidex
+                {{siteName}}
             </a>
             <div class="flex items-center gap-4">
-                <input type="search" name="q" placeholder="Search documentation..."
-                    class="w-64 px-4 py-2 border border-gray-300 rounded-lg text-sm focus:ring-2 focus:ring-blue-500 focus:border-transparent dark:bg-gray-800 dark:border-gray-600 dark:text-gray-100 dark:placeholder-gray-400"
-                    hx-get="/search" hx-trigger="keyup changed delay:300ms" hx-target="#main-content" hx-push-url="true">
+                <form id="nav-search" action="/search" method="get" role="search" class="relative"
+                      hx-get="/search" hx-target="#main-content" hx-push-url="true">
+                    <input type="search" name="q" placeholder="Search documentation..." autocomplete="off"
+                        aria-controls="search-suggestions" aria-autocomplete="list"
+                        class="w-64 px-4 py-2 border border-gray-300 rounded-lg text-sm focus:ring-2 focus:ring-blue-500 focus:border-transparent dark:bg-gray-800 dark:border-gray-600 dark:text-gray-100 dark:placeholder-gray-400"
+                        hx-get="/api/v1/suggest" hx-trigger="input changed delay:150ms, search" hx-target="#search-suggestions" hx-sync="this:replace">
+                    <div id="search-suggestions" class="absolute right-0 z-40 mt-1 w-96"></div>
+                </form>
+                {{if notifications}}
+                <span hx-get="/notifications/unread" hx-trigger="load" hx-swap="outerHTML"></span>
+                {{end}}
                 <button id="theme-toggle" type="button" aria-label="Toggle dark mode"
-                    class="p-2 rounded-lg border border-gray-200 text-gray-500 hover:border-blue-300 hover:text-blue-600 dark:border-gray-700 dark:text-gray-400 dark:hover:border-blue-500 dark:hover:text-blue-400 transition-colors flex-shrink-0">
+                    class="js-only p-2 rounded-lg border border-gray-200 text-gray-500 hover:border-blue-300 hover:text-blue-600 dark:border-gray-700 dark:text-gray-400 dark:hover:border-blue-500 dark:hover:text-blue-400 transition-colors flex-shrink-0">
                     <!-- Sun icon: shown in dark mode -->
                     <svg id="theme-icon-sun" xmlns="http://www.w3.org/2000/svg" width="16" height="16" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2" stroke-linecap="round" stroke-linejoin="round" class="hidden dark:block" aria-hidden="true"><circle cx="12" cy="12" r="5"/><line x1="12" y1="1" x2="12" y2="3"/><line x1="12" y1="21" x2="12" y2="23"/><line x1="4.22" y1="4.22" x2="5.64" y2="5.64"/><line x1="18.36" y1="18.36" x2="19.78" y2="19.78"/><line x1="1" y1="12" x2="3" y2="12"/><line x1="21" y1="12" x2="23" y2="12"/><line x1="4.22" y1="19.78" x2="5.64" y2="18.36"/><line x1="18.36" y1="5.64" x2="19.78" y2="4.22"/></svg>
                     <!-- Moon icon: shown in light mode -->
@@ -760,14 +1379,38 @@ const layoutHeader = `<!DOCTYPE html>
             </div>
         </div>
     </nav>
+    {{with siteBanner}}
+    <div role="{{if eq .Severity "critical"}}alert{{else}}status{{end}}" data-site-banner="{{.Severity}}"
+         class="site-banner px-6 py-2 text-sm border-b {{if eq .Severity "critical"}}border-red-300 dark:border-red-800 bg-red-50 dark:bg-red-950 text-red-900 dark:text-red-200{{else if eq .Severity "warning"}}border-amber-300 dark:border-amber-800 bg-amber-50 dark:bg-amber-950 text-amber-900 dark:text-amber-200{{else}}border-blue-300 dark:border-blue-800 bg-blue-50 dark:bg-blue-950 text-blue-900 dark:text-blue-200{{end}}">
+        <div class="max-w-7xl mx-auto"><div class="prose prose-sm dark:prose-invert max-w-none">{{html (printf "%s" .HTML)}}</div></div>
+    </div>
+    {{end}}
     <main id="main-content" class="max-w-7xl mx-auto px-6 py-8 flex-1 w-full">`
 
 // layoutFooter is the closing portion of the HTML layout.
 const layoutFooter = `</main>
     <footer class="border-t border-gray-200 dark:border-gray-700 py-6 text-center text-sm text-gray-500 dark:text-gray-400">
-        <p>Powered by Omnidex</p>
+        <p>Powered by Omnidex &middot; <a href="/stats" hx-get="/stats" hx-target="#main-content" hx-push-url="true" class="hover:text-blue-600 dark:hover:text-blue-400">Statistics</a></p>
     </footer>
 
+    <div id="update-toast" hidden role="status" aria-live="polite"
+         class="fixed bottom-4 right-4 z-50 px-4 py-3 space-x-3 text-sm rounded-lg shadow-lg border border-blue-200 dark:border-blue-900 bg-blue-50 dark:bg-blue-950 text-blue-800 dark:text-blue-200">
+        <span data-update-message>This page was updated.</span>
+        <button type="button" class="font-medium underline" data-update-reload>Reload</button>
+        <button type="button" data-update-dismiss aria-label="Dismiss">&times;</button>
+    </div>
+
+    <div id="doc-preview" hidden role="tooltip"
+         class="fixed z-50 w-96 max-w-[calc(100vw-1rem)] max-h-80 overflow-hidden p-4 rounded-lg shadow-lg border border-gray-200 dark:border-gray-700 bg-white dark:bg-gray-800 pointer-events-none"></div>
+
+    <div id="presence-bar" hidden role="status" aria-live="polite"
+         class="fixed bottom-4 left-4 z-50 max-w-md px-4 py-3 space-x-2 text-sm rounded-lg shadow-lg border border-red-200 dark:border-red-900 bg-red-50 dark:bg-red-950 text-red-800 dark:text-red-200">
+        <span class="font-semibold" data-presence-incident>Active incident</span>
+        <span data-presence-count></span>
+        <span class="text-red-700 dark:text-red-300" data-presence-names></span>
+        <button type="button" class="font-medium underline" data-presence-rename>Set your name</button>
+    </div>
+
     <!-- Media fullscreen viewer modal (mermaid diagrams + images) -->
     <div id="media-modal" role="dialog" aria-modal="true" aria-label="Media viewer">
         <div id="media-modal-header">
@@ -794,7 +1437,7 @@ const layoutFooter = `</main>
 
 // homeContentBody is the home page content template.
 const homeContentBody = `
-<div>
+<div data-live-home>
     <h1 class="text-3xl font-bold text-gray-900 dark:text-gray-100 mb-6">Documentation Portal</h1>
     {{if .Repos}}
     <div class="grid grid-cols-1 md:grid-cols-2 lg:grid-cols-3 gap-6">
@@ -821,19 +1464,20 @@ const homeContentBody = `
 // docContentBody is the document page content template.
 const docContentBody = `
 <div class="flex gap-8">
-    <aside class="w-64 flex-shrink-0 hidden md:block">
+    <aside class="doc-sidebar w-64 flex-shrink-0 hidden md:block">
         <nav class="sticky top-8">
             <h3 class="text-sm font-semibold text-gray-500 dark:text-gray-400 uppercase tracking-wider mb-3">
                 <a href="/docs/{{.Doc.Repo}}/"
                    hx-get="/docs/{{.Doc.Repo}}/" hx-target="#main-content" hx-push-url="true"
                    class="block hover:text-blue-600 dark:hover:text-blue-400 transition-colors">{{.Doc.Repo}}</a>
             </h3>
+            {{template "repoSearchBox" .Doc.Repo}}
             <ul class="space-y-1">
                 {{template "sidebarDocTree" (sidebarNav .NavDocs .CurrentPath)}}
             </ul>
         </nav>
     </aside>
-    <article id="doc-content" class="flex-1 min-w-0">
+    <article id="doc-content" class="flex-1 min-w-0" data-live-repo="{{.Doc.Repo}}" data-live-path="{{.Doc.Path}}">
         <div class="mb-4 text-sm text-gray-500 dark:text-gray-400 flex items-center justify-between">
             <div>
                 <a href="/" hx-get="/" hx-target="#main-content" hx-push-url="true" class="hover:text-blue-600 dark:hover:text-blue-400">Home</a>
@@ -842,18 +1486,110 @@ const docContentBody = `
                 <span class="mx-1">/</span>
                 <span>{{.Doc.Path}}</span>
             </div>
-            <a href="{{githubURL .Doc.Repo .Doc.Path .Doc.CommitSHA}}" target="_blank" rel="noopener noreferrer"
-               class="inline-flex items-center gap-1 text-gray-400 dark:text-gray-500 hover:text-blue-600 dark:hover:text-blue-400 transition-colors">
-                <svg xmlns="http://www.w3.org/2000/svg" width="14" height="14" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2" stroke-linecap="round" stroke-linejoin="round" aria-hidden="true"><path d="M18 13v6a2 2 0 0 1-2 2H5a2 2 0 0 1-2-2V8a2 2 0 0 1 2-2h6"/><polyline points="15 3 21 3 21 9"/><line x1="10" y1="14" x2="21" y2="3"/></svg>
-                View source
-            </a>
+            <div class="flex items-center gap-3">
+                <button id="reader-mode-toggle" type="button" aria-pressed="false" aria-label="Toggle reader mode"
+                        class="js-only reading-btn inline-flex items-center gap-1 text-gray-400 dark:text-gray-500 hover:text-blue-600 dark:hover:text-blue-400 transition-colors">
+                    <svg xmlns="http://www.w3.org/2000/svg" width="14" height="14" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2" stroke-linecap="round" stroke-linejoin="round" aria-hidden="true"><path d="M2 3h6a4 4 0 0 1 4 4v14a3 3 0 0 0-3-3H2z"/><path d="M22 3h-6a4 4 0 0 0-4 4v14a3 3 0 0 1 3-3h7z"/></svg>
+                    Reader
+                </button>
+                <details class="share-menu relative js-only">
+                    <summary class="list-none cursor-pointer inline-flex items-center gap-1 text-gray-400 dark:text-gray-500 hover:text-blue-600 dark:hover:text-blue-400 transition-colors" aria-label="Share">
+                        <svg xmlns="http://www.w3.org/2000/svg" width="14" height="14" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2" stroke-linecap="round" stroke-linejoin="round" aria-hidden="true"><path d="M4 12v8a2 2 0 0 0 2 2h12a2 2 0 0 0 2-2v-8"/><polyline points="16 6 12 2 8 6"/><line x1="12" y1="2" x2="12" y2="15"/></svg>
+                        Share
+                    </summary>
+                    <div class="absolute right-0 z-20 mt-2 w-56 py-1 bg-white dark:bg-gray-800 border border-gray-200 dark:border-gray-700 rounded-lg shadow-lg text-gray-700 dark:text-gray-300">
+                        <button type="button" class="share-opt" data-share="link">Copy link</button>
+                        <button type="button" class="share-opt" data-share="markdown" data-share-url="/raw/{{.Doc.Repo}}/{{urlPath .Doc.Path}}">Copy as Markdown</button>
+                        <button type="button" class="share-opt" data-share="html" data-share-url="/html/{{.Doc.Repo}}/{{urlPath .Doc.Path}}">Copy rendered HTML</button>
+                    </div>
+                </details>
+                <details class="reading-settings relative js-only">
+                    <summary class="list-none cursor-pointer inline-flex items-center gap-1 text-gray-400 dark:text-gray-500 hover:text-blue-600 dark:hover:text-blue-400 transition-colors" aria-label="Reading settings">
+                        <span aria-hidden="true" class="font-serif">Aa</span>
+                        Display
+                    </summary>
+                    <div class="absolute right-0 z-20 mt-2 w-60 p-3 space-y-3 bg-white dark:bg-gray-800 border border-gray-200 dark:border-gray-700 rounded-lg shadow-lg text-gray-700 dark:text-gray-300">
+                        <div>
+                            <div class="text-xs font-semibold uppercase tracking-wider mb-1">Width</div>
+                            <div class="flex gap-1" role="group" aria-label="Content width">
+                                <button type="button" class="reading-opt" data-reading-pref="contentWidth" data-reading-value="narrow" data-reading-default="normal">Narrow</button>
+                                <button type="button" class="reading-opt" data-reading-pref="contentWidth" data-reading-value="normal" data-reading-default="normal">Normal</button>
+                                <button type="button" class="reading-opt" data-reading-pref="contentWidth" data-reading-value="wide" data-reading-default="normal">Wide</button>
+                            </div>
+                        </div>
+                        <div>
+                            <div class="text-xs font-semibold uppercase tracking-wider mb-1">Font size</div>
+                            <div class="flex gap-1" role="group" aria-label="Font size">
+                                <button type="button" class="reading-opt" data-reading-pref="fontSize" data-reading-value="sm" data-reading-default="md">S</button>
+                                <button type="button" class="reading-opt" data-reading-pref="fontSize" data-reading-value="md" data-reading-default="md">M</button>
+                                <button type="button" class="reading-opt" data-reading-pref="fontSize" data-reading-value="lg" data-reading-default="md">L</button>
+                                <button type="button" class="reading-opt" data-reading-pref="fontSize" data-reading-value="xl" data-reading-default="md">XL</button>
+                            </div>
+                        </div>
+                        <div>
+                            <div class="text-xs font-semibold uppercase tracking-wider mb-1">Line height</div>
+                            <div class="flex gap-1" role="group" aria-label="Line height">
+                                <button type="button" class="reading-opt" data-reading-pref="lineHeight" data-reading-value="compact" data-reading-default="normal">Compact</button>
+                                <button type="button" class="reading-opt" data-reading-pref="lineHeight" data-reading-value="normal" data-reading-default="normal">Normal</button>
+                                <button type="button" class="reading-opt" data-reading-pref="lineHeight" data-reading-value="relaxed" data-reading-default="normal">Relaxed</button>
+                            </div>
+                        </div>
+                    </div>
+                </details>
+                {{template "provenanceBadge" .Doc}}
+                <a href="{{githubURL .Doc.Repo .Doc.Path .Doc.CommitSHA}}" target="_blank" rel="noopener noreferrer"
+                   class="inline-flex items-center gap-1 text-gray-400 dark:text-gray-500 hover:text-blue-600 dark:hover:text-blue-400 transition-colors">
+                    <svg xmlns="http://www.w3.org/2000/svg" width="14" height="14" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2" stroke-linecap="round" stroke-linejoin="round" aria-hidden="true"><path d="M18 13v6a2 2 0 0 1-2 2H5a2 2 0 0 1-2-2V8a2 2 0 0 1 2-2h6"/><polyline points="15 3 21 3 21 9"/><line x1="10" y1="14" x2="21" y2="3"/></svg>
+                    View source
+                </a>
+                {{if editable .Doc.Repo}}
+                <a href="/edit/{{.Doc.Repo}}/{{urlPath .Doc.Path}}"
+                   class="js-only inline-flex items-center gap-1 text-gray-400 dark:text-gray-500 hover:text-blue-600 dark:hover:text-blue-400 transition-colors">
+                    <svg xmlns="http://www.w3.org/2000/svg" width="14" height="14" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2" stroke-linecap="round" stroke-linejoin="round" aria-hidden="true"><path d="M12 20h9"/><path d="M16.5 3.5a2.1 2.1 0 0 1 3 3L7 19l-4 1 1-4z"/></svg>
+                    Edit
+                </a>
+                {{end}}
+                {{if suggestible .Doc.Repo}}
+                <a href="/suggest/{{.Doc.Repo}}/{{urlPath .Doc.Path}}"
+                   class="js-only inline-flex items-center gap-1 text-gray-400 dark:text-gray-500 hover:text-blue-600 dark:hover:text-blue-400 transition-colors">
+                    <svg xmlns="http://www.w3.org/2000/svg" width="14" height="14" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2" stroke-linecap="round" stroke-linejoin="round" aria-hidden="true"><circle cx="18" cy="18" r="3"/><circle cx="6" cy="6" r="3"/><path d="M13 6h3a2 2 0 0 1 2 2v7"/><line x1="6" y1="9" x2="6" y2="21"/></svg>
+                    Suggest an edit
+                </a>
+                {{end}}
+            </div>
         </div>
-        <div class="prose prose-gray dark:prose-invert max-w-none bg-white dark:bg-gray-800 rounded-lg border border-gray-200 dark:border-gray-700 p-8">
+        {{with .Doc.Tags}}
+        <ul class="doc-tags mb-4 flex flex-wrap gap-2 text-xs" aria-label="Tags">
+            {{range .}}
+            <li><a href="{{tagSearchURL .}}" hx-get="{{tagSearchURL .}}" hx-target="#main-content" hx-push-url="true"
+                   class="inline-block px-2 py-0.5 rounded-full bg-gray-100 dark:bg-gray-700 text-gray-600 dark:text-gray-300 hover:bg-blue-100 hover:text-blue-700 dark:hover:bg-blue-900 dark:hover:text-blue-300 transition-colors">#{{.}}</a></li>
+            {{end}}
+        </ul>
+        {{end}}
+        {{template "announcementBanner" .Announcement}}
+        {{template "freshnessBanner" .}}
+        {{if .TrackProgress}}
+        <div id="resume-prompt" hidden role="status"
+             class="mb-4 px-4 py-2 flex items-center justify-between gap-3 text-sm rounded-lg border border-blue-200 dark:border-blue-900 bg-blue-50 dark:bg-blue-950 text-blue-800 dark:text-blue-200">
+            <span>Continue where you left off<span data-resume-section></span>?</span>
+            <span class="flex items-center gap-2">
+                <button type="button" class="resume-btn font-medium" data-resume="go">Resume</button>
+                <button type="button" class="resume-btn" data-resume="dismiss" aria-label="Dismiss">Dismiss</button>
+            </span>
+        </div>
+        {{end}}
+        {{with embedType .Doc.ContentType}}
+        <object class="doc-viewer" data="/raw/{{$.Doc.Repo}}/{{urlPath $.Doc.Path}}" type="{{.}}" aria-label="{{$.Doc.Title}}">
+            <p class="p-8 text-sm text-gray-500 dark:text-gray-400">Your browser cannot display this document inline.
+                <a href="/raw/{{$.Doc.Repo}}/{{urlPath $.Doc.Path}}" class="text-blue-600 dark:text-blue-400 hover:underline">Download it</a> instead.</p>
+        </object>
+        {{end}}
+        <div class="prose prose-gray dark:prose-invert max-w-none bg-white dark:bg-gray-800 rounded-lg border border-gray-200 dark:border-gray-700 p-8"{{if .TrackProgress}} data-progress-key="{{.Doc.Repo}}/{{.Doc.Path}}"{{end}}>
             {{html .HTML}}
         </div>
     </article>
     {{if gt (len .Headings) 1}}
-    <aside class="w-56 flex-shrink-0 hidden lg:block">
+    <aside class="doc-toc w-56 flex-shrink-0 hidden lg:block">
         <nav class="sticky top-8">
             <h3 class="text-sm font-semibold text-gray-500 dark:text-gray-400 uppercase tracking-wider mb-3">On this page</h3>
             <ul class="space-y-1 border-l border-gray-200 dark:border-gray-700">
@@ -878,13 +1614,114 @@ const searchContentBody = `
     <div id="search-results">` + searchResultsBody + `</div>
 </div>`
 
+// repoSearchContentBody is the search page of a single repository.
+const repoSearchContentBody = `
+<div>
+    <div class="mb-4 text-sm text-gray-500 dark:text-gray-400">
+        <a href="/" hx-get="/" hx-target="#main-content" hx-push-url="true" class="hover:text-blue-600 dark:hover:text-blue-400">Home</a>
+        <span class="mx-1">/</span>
+        <a href="/docs/{{.Repo}}/" hx-get="/docs/{{.Repo}}/" hx-target="#main-content" hx-push-url="true" class="hover:text-blue-600 dark:hover:text-blue-400">{{.Repo}}</a>
+        <span class="mx-1">/</span>
+        <span>Search</span>
+    </div>
+    <h1 class="text-3xl font-bold text-gray-900 dark:text-gray-100 mb-6">Search {{.Repo}}</h1>
+    <form action="/docs/{{.Repo}}/search" method="get" role="search" class="repo-search mb-6 max-w-xl"
+          hx-get="/docs/{{.Repo}}/search" hx-target="#main-content" hx-push-url="true">
+        <input type="search" name="q" value="{{.Query}}" placeholder="Search {{.Repo}}..." autocomplete="off" aria-label="Search {{.Repo}}"
+               class="w-full px-4 py-2 text-sm border border-gray-300 rounded-lg bg-white dark:bg-gray-800 dark:border-gray-600 dark:text-gray-100 focus:outline-none focus:ring-2 focus:ring-blue-500">
+    </form>
+    <div id="search-results">` + repoSearchResultsBody + `</div>
+</div>`
+
+// repoSearchResultsBody is the results of a repository search: the search results partial
+// with a link running the query across all repositories.
+const repoSearchResultsBody = `{{if .Query}}
+    <p class="repo-search-scope mb-4 text-sm text-gray-500 dark:text-gray-400">Showing results from {{.Repo}} only.
+        <a href="{{.AllReposURL}}" hx-get="{{.AllReposURL}}" hx-target="#main-content" hx-push-url="true"
+           class="text-blue-600 hover:underline dark:text-blue-400">Search all repositories</a></p>
+{{end}}` + searchResultsBody
+
+// repoSearchBoxSubTemplate is the search box on repository and document pages, searching
+// the documents of the repository it is given only.
+const repoSearchBoxSubTemplate = `{{define "repoSearchBox"}}
+<form action="/docs/{{.}}/search" method="get" role="search" class="repo-search-box mb-3"
+      hx-get="/docs/{{.}}/search" hx-target="#main-content" hx-push-url="true">
+    <input type="search" name="q" placeholder="Search this repository..." autocomplete="off" aria-label="Search {{.}}"
+           class="w-full px-3 py-1.5 text-sm border border-gray-300 rounded-lg bg-white dark:bg-gray-800 dark:border-gray-600 dark:text-gray-100 focus:outline-none focus:ring-2 focus:ring-blue-500">
+</form>
+{{end}}`
+
+// searchSuggestionsBody is the dropdown of the navigation search box, listing the documents
+// whose titles or section headings start with the words typed so far. It is empty when
+// nothing matches, which closes the dropdown.
+const searchSuggestionsBody = `{{if .Suggestions}}
+<ul role="listbox" aria-label="Search suggestions"
+    class="py-1 bg-white dark:bg-gray-800 rounded-lg border border-gray-200 dark:border-gray-700 shadow-lg text-sm">
+    {{range .Suggestions}}
+    <li role="option">
+        <a href="/docs/{{.Repo}}/{{urlPath .Path}}{{if .Anchor}}#{{.Anchor}}{{end}}" hx-get="/docs/{{.Repo}}/{{urlPath .Path}}" hx-target="#main-content" hx-push-url="/docs/{{.Repo}}/{{urlPath .Path}}{{if .Anchor}}#{{.Anchor}}{{end}}"
+           class="search-suggestion block px-4 py-2 hover:bg-blue-50 focus:bg-blue-50 dark:hover:bg-gray-700 dark:focus:bg-gray-700 outline-none">
+            <span class="block font-medium text-gray-900 dark:text-gray-100 truncate">{{if .Heading}}{{.Heading}}{{else}}{{or .Title .Path}}{{end}}</span>
+            <span class="block text-xs text-gray-500 dark:text-gray-400 truncate">{{.Repo}}{{if .Heading}} &middot; {{or .Title .Path}}{{else}}/{{.Path}}{{end}}</span>
+        </a>
+    </li>
+    {{end}}
+    <li role="option" class="border-t border-gray-100 dark:border-gray-700">
+        <a href="/search?q={{.Query}}" hx-get="/search?q={{.Query}}" hx-target="#main-content" hx-push-url="true"
+           class="search-suggestion block px-4 py-2 text-blue-600 dark:text-blue-400 hover:bg-blue-50 focus:bg-blue-50 dark:hover:bg-gray-700 dark:focus:bg-gray-700 outline-none">Search for &ldquo;{{.Query}}&rdquo;</a>
+    </li>
+</ul>
+{{end}}`
+
 // searchResultsBody is the search results partial template.
-const searchResultsBody = `{{if .Results}}
+const searchResultsBody = `{{if .Query}}
+    <div class="search-filters flex flex-wrap items-center gap-2 mb-4 text-sm">
+        {{range .Modes}}
+        <a href="{{.URL}}" hx-get="{{.URL}}" hx-target="#main-content" hx-push-url="true"
+           class="search-mode px-3 py-1 rounded-full border transition-colors {{if .Active}}border-blue-500 bg-blue-50 text-blue-700 dark:bg-blue-900/40 dark:text-blue-300{{else}}border-gray-300 text-gray-600 hover:border-blue-400 dark:border-gray-600 dark:text-gray-300{{end}}"
+           aria-pressed="{{if .Active}}true{{else}}false{{end}}">{{.Label}}</a>
+        {{end}}
+        {{if not .Semantic}}
+        <a href="{{.CodeToggleURL}}" hx-get="{{.CodeToggleURL}}" hx-target="#main-content" hx-push-url="true"
+           class="code-only-toggle px-3 py-1 rounded-full border transition-colors {{if .CodeOnly}}border-blue-500 bg-blue-50 text-blue-700 dark:bg-blue-900/40 dark:text-blue-300{{else}}border-gray-300 text-gray-600 hover:border-blue-400 dark:border-gray-600 dark:text-gray-300{{end}}"
+           aria-pressed="{{if .CodeOnly}}true{{else}}false{{end}}">Code only</a>
+        {{range .LangFacets}}
+        <a href="{{.URL}}" hx-get="{{.URL}}" hx-target="#main-content" hx-push-url="true"
+           class="lang-facet px-3 py-1 rounded-full border font-mono text-xs transition-colors {{if .Active}}border-blue-500 bg-blue-50 text-blue-700 dark:bg-blue-900/40 dark:text-blue-300{{else}}border-gray-300 text-gray-600 hover:border-blue-400 dark:border-gray-600 dark:text-gray-300{{end}}"
+           aria-pressed="{{if .Active}}true{{else}}false{{end}}">lang:{{.Lang}}{{if .Count}} <span class="text-gray-400">{{.Count}}</span>{{end}}</a>
+        {{end}}
+        {{range .TypeFacets}}
+        <a href="{{.URL}}" hx-get="{{.URL}}" hx-target="#main-content" hx-push-url="true"
+           class="type-facet px-3 py-1 rounded-full border transition-colors {{if .Active}}border-blue-500 bg-blue-50 text-blue-700 dark:bg-blue-900/40 dark:text-blue-300{{else}}border-gray-300 text-gray-600 hover:border-blue-400 dark:border-gray-600 dark:text-gray-300{{end}}"
+           aria-pressed="{{if .Active}}true{{else}}false{{end}}">{{.Label}}{{if .Count}} <span class="text-gray-400">{{.Count}}</span>{{end}}</a>
+        {{end}}
+        {{end}}
+        {{if gt (len .Repos) 1}}
+        <form action="/search" method="get" hx-get="/search" hx-trigger="change" hx-target="#main-content" hx-push-url="true"
+              class="repo-filter ml-auto">
+            <input type="hidden" name="q" value="{{.Query}}">
+            {{if .Mode}}<input type="hidden" name="mode" value="{{.Mode}}">{{end}}
+            {{if .CodeOnly}}<input type="hidden" name="code" value="1">{{end}}
+            {{range .Types}}<input type="hidden" name="type" value="{{.}}">{{end}}
+            <select name="repo" aria-label="Repository"
+                    class="px-3 py-1 rounded-lg border border-gray-300 bg-white text-gray-600 dark:bg-gray-800 dark:border-gray-600 dark:text-gray-300">
+                <option value="">All repositories</option>
+                {{range .Repos}}
+                <option value="{{.}}"{{if eq . $.Repo}} selected{{end}}>{{.}}</option>
+                {{end}}
+            </select>
+            <noscript><button type="submit" class="ml-1 text-blue-600 dark:text-blue-400">Apply</button></noscript>
+        </form>
+        {{end}}
+    </div>
+{{end}}
+{{if .Results}}
     <p class="text-sm text-gray-500 dark:text-gray-400 mb-4">{{.Results.Total}} results found</p>
     {{if .Results.Hits}}
-    <div class="space-y-4">
-        {{range .Results.Hits}}
-        <a href="/docs/{{.Repo}}/{{.Path}}{{if .Anchor}}#{{.Anchor}}{{end}}" hx-get="/docs/{{.Repo}}/{{.Path}}" hx-target="#main-content" hx-push-url="/docs/{{.Repo}}/{{.Path}}{{if .Anchor}}#{{.Anchor}}{{end}}"
+    <div class="space-y-4"{{with .Results.Variant}} data-search-variant="{{.}}"{{end}}>
+        {{range $i, $hit := .Results.Hits}}
+        <a href="/docs/{{.Repo}}/{{urlPath .Path}}{{if .Anchor}}#{{.Anchor}}{{end}}" hx-get="/docs/{{.Repo}}/{{urlPath .Path}}" hx-target="#main-content" hx-push-url="/docs/{{.Repo}}/{{urlPath .Path}}{{if .Anchor}}#{{.Anchor}}{{end}}"
+           data-preview="/preview/{{.Repo}}/{{urlPath .Path}}" data-rank="{{resultRank $.Offset $i}}"
            class="search-result block p-4 bg-white dark:bg-gray-800 rounded-lg border border-gray-200 dark:border-gray-700 hover:border-blue-500 dark:hover:border-blue-500 hover:shadow-sm transition-all">
             <h3 class="text-lg font-semibold text-gray-900 dark:text-gray-100 mb-1">
                 {{- if .TitleFragments -}}
@@ -896,7 +1733,7 @@ const searchResultsBody = `{{if .Results}}
                     {{.Title}}
                 {{- end -}}
             </h3>
-            <p class="text-xs text-gray-400 dark:text-gray-500 mb-2">{{.Repo}}/{{.Path}}</p>
+            <p class="flex items-center gap-2 text-xs text-gray-400 dark:text-gray-500 mb-2">{{typeBadge .ContentType}}<span>{{.Repo}}/{{.Path}}</span></p>
             {{if .ContentFragments}}
             <p class="text-sm text-gray-600 dark:text-gray-300 leading-relaxed">
                 {{- range $i, $f := .ContentFragments -}}
@@ -904,14 +1741,43 @@ const searchResultsBody = `{{if .Results}}
                     {{safeFragment $f}}
                 {{- end -}}
             </p>
+            {{else if .Summary}}
+            <p class="text-sm text-gray-600 dark:text-gray-300 leading-relaxed">{{.Summary}}</p>
             {{else if .TitleFragments}}
             <p class="text-xs text-gray-400 dark:text-gray-500 italic">Matched in title</p>
             {{end}}
         </a>
         {{end}}
     </div>
+    {{if .Pages}}
+    <nav class="search-pagination flex flex-wrap items-center justify-center gap-1 mt-6 text-sm" aria-label="Search result pages">
+        {{with .PrevURL}}
+        <a href="{{.}}" hx-get="{{.}}" hx-target="#main-content" hx-push-url="true" rel="prev"
+           class="px-3 py-1 rounded-lg text-gray-600 hover:text-blue-600 dark:text-gray-300 dark:hover:text-blue-400">&larr; Previous</a>
+        {{end}}
+        {{range .Pages}}
+        {{if .Gap}}
+        <span class="px-2 text-gray-400">&hellip;</span>
+        {{else if .Active}}
+        <span aria-current="page" class="px-3 py-1 rounded-lg border border-blue-500 bg-blue-50 text-blue-700 dark:bg-blue-900/40 dark:text-blue-300">{{.Number}}</span>
+        {{else}}
+        <a href="{{.URL}}" hx-get="{{.URL}}" hx-target="#main-content" hx-push-url="true"
+           class="px-3 py-1 rounded-lg border border-gray-300 text-gray-600 hover:border-blue-400 dark:border-gray-600 dark:text-gray-300">{{.Number}}</a>
+        {{end}}
+        {{end}}
+        {{with .NextURL}}
+        <a href="{{.}}" hx-get="{{.}}" hx-target="#main-content" hx-push-url="true" rel="next"
+           class="px-3 py-1 rounded-lg text-gray-600 hover:text-blue-600 dark:text-gray-300 dark:hover:text-blue-400">Next &rarr;</a>
+        {{end}}
+    </nav>
+    {{end}}
     {{else}}
     <p class="text-gray-500 dark:text-gray-400">No results found for &ldquo;{{$.Query}}&rdquo;.</p>
+    {{if .SuggestionURL}}
+    <p class="search-suggestion mt-2 text-gray-600 dark:text-gray-300">Did you mean
+        <a href="{{.SuggestionURL}}" hx-get="{{.SuggestionURL}}" hx-target="#main-content" hx-push-url="true"
+           class="font-semibold text-blue-600 hover:underline dark:text-blue-400">{{.Results.Suggestion}}</a>?</p>
+    {{end}}
     {{end}}
 {{else if .Query}}
     <p class="text-gray-500 dark:text-gray-400">No results found for &ldquo;{{.Query}}&rdquo;.</p>
@@ -927,8 +1793,51 @@ const repoIndexContentBody = `
         <span class="mx-1">/</span>
         <span>{{.Repo}}</span>
     </div>
-    <h1 class="text-3xl font-bold text-gray-900 dark:text-gray-100 mb-6">{{.Repo}}</h1>
-    {{if .Docs}}
+    <div class="flex items-baseline justify-between gap-4 mb-6">
+        <h1 class="text-3xl font-bold text-gray-900 dark:text-gray-100">{{.Repo}}</h1>
+        {{if .Docs}}
+        <a href="/print/{{.Repo}}/" target="_blank" rel="noopener"
+           class="text-sm text-gray-400 dark:text-gray-500 hover:text-blue-600 dark:hover:text-blue-400 transition-colors">Print all</a>
+        {{end}}
+    </div>
+    {{if .Docs}}<div class="mb-6 max-w-md">{{template "repoSearchBox" .Repo}}</div>{{end}}
+    {{template "announcementBanner" .Announcement}}
+    {{if .Pinned}}
+    <section class="mb-6" aria-labelledby="pinned-docs">
+        <h2 id="pinned-docs" class="flex items-center gap-1.5 mb-2 text-sm font-semibold text-gray-500 dark:text-gray-400 uppercase tracking-wider">
+            <svg xmlns="http://www.w3.org/2000/svg" width="14" height="14" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2" stroke-linecap="round" stroke-linejoin="round" aria-hidden="true"><line x1="12" y1="17" x2="12" y2="22"/><path d="M5 17h14v-1.76a2 2 0 0 0-1.11-1.79l-1.78-.9A2 2 0 0 1 15 10.76V6h1a2 2 0 0 0 0-4H8a2 2 0 0 0 0 4h1v4.76a2 2 0 0 1-1.11 1.79l-1.78.9A2 2 0 0 0 5 15.24z"/></svg>
+            Pinned
+        </h2>
+        {{range .Pinned}}
+        <a href="/docs/{{.Repo}}/{{urlPath .Path}}" data-pinned-doc
+           hx-get="/docs/{{.Repo}}/{{urlPath .Path}}" hx-target="#main-content" hx-push-url="true"
+           class="block p-4 bg-white dark:bg-gray-800 rounded-lg border border-blue-200 dark:border-blue-900 hover:border-blue-500 dark:hover:border-blue-500 hover:shadow-sm transition-all mb-2">
+            <span class="flex items-center gap-2">
+                <span class="text-lg font-semibold text-gray-900 dark:text-gray-100">{{.Title}}</span>
+                {{typeBadge .ContentType}}
+            </span>
+            {{with .Summary}}<span class="block mt-1 text-sm text-gray-600 dark:text-gray-300 line-clamp-2">{{.}}</span>{{end}}
+        </a>
+        {{end}}
+    </section>
+    {{end}}
+    {{if .Landing}}
+    <nav class="repo-tabs flex gap-6 mb-6 border-b border-gray-200 dark:border-gray-700 text-sm font-medium" aria-label="Repository views">
+        <a href="/docs/{{.Repo}}/" hx-get="/docs/{{.Repo}}/" hx-target="#main-content" hx-push-url="true"
+           class="repo-tab{{if not .FilesTab}} active{{end}}"{{if not .FilesTab}} aria-current="page"{{end}}>Overview</a>
+        <a href="/docs/{{.Repo}}/?tab=files" hx-get="/docs/{{.Repo}}/?tab=files" hx-target="#main-content" hx-push-url="true"
+           class="repo-tab{{if .FilesTab}} active{{end}}"{{if .FilesTab}} aria-current="page"{{end}}>Files</a>
+    </nav>
+    {{end}}
+    {{if and .Landing (not .FilesTab)}}
+    <article id="doc-content" class="prose prose-gray dark:prose-invert max-w-none bg-white dark:bg-gray-800 rounded-lg border border-gray-200 dark:border-gray-700 p-8">
+        {{html .LandingHTML}}
+    </article>
+    <p class="mt-3 text-sm text-gray-500 dark:text-gray-400">
+        <a href="/docs/{{.Repo}}/{{urlPath .Landing.Path}}" hx-get="/docs/{{.Repo}}/{{urlPath .Landing.Path}}" hx-target="#main-content" hx-push-url="true"
+           class="hover:text-blue-600 dark:hover:text-blue-400">{{.Landing.Path}}</a>
+    </p>
+    {{else if .Docs}}
     <div class="space-y-1">
         {{template "repoDocTree" .Docs}}
     </div>
@@ -945,19 +1854,20 @@ const repoIndexContentBody = `
 // The spec JSON is embedded inline and fed to Scalar on initialisation.
 const openapiDocContentBody = `
 <div class="flex gap-8">
-    <aside class="w-64 flex-shrink-0 hidden md:block">
+    <aside class="doc-sidebar w-64 flex-shrink-0 hidden md:block">
         <nav class="sticky top-8">
             <h3 class="text-sm font-semibold text-gray-500 dark:text-gray-400 uppercase tracking-wider mb-3">
                 <a href="/docs/{{.Doc.Repo}}/"
                    hx-get="/docs/{{.Doc.Repo}}/" hx-target="#main-content" hx-push-url="true"
                    class="block hover:text-blue-600 dark:hover:text-blue-400 transition-colors">{{.Doc.Repo}}</a>
             </h3>
+            {{template "repoSearchBox" .Doc.Repo}}
             <ul class="space-y-1">
                 {{template "sidebarDocTree" (sidebarNav .NavDocs .CurrentPath)}}
             </ul>
         </nav>
     </aside>
-    <article id="doc-content" class="flex-1 min-w-0">
+    <article id="doc-content" class="flex-1 min-w-0" data-live-repo="{{.Doc.Repo}}" data-live-path="{{.Doc.Path}}">
         <div class="mb-4 text-sm text-gray-500 dark:text-gray-400 flex items-center justify-between">
             <div>
                 <a href="/" hx-get="/" hx-target="#main-content" hx-push-url="true" class="hover:text-blue-600 dark:hover:text-blue-400">Home</a>
@@ -966,15 +1876,23 @@ const openapiDocContentBody = `
                 <span class="mx-1">/</span>
                 <span>{{.Doc.Path}}</span>
             </div>
-            <a href="{{githubURL .Doc.Repo .Doc.Path .Doc.CommitSHA}}" target="_blank" rel="noopener noreferrer"
-               class="inline-flex items-center gap-1 text-gray-400 dark:text-gray-500 hover:text-blue-600 dark:hover:text-blue-400 transition-colors">
-                <svg xmlns="http://www.w3.org/2000/svg" width="14" height="14" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2" stroke-linecap="round" stroke-linejoin="round" aria-hidden="true"><path d="M18 13v6a2 2 0 0 1-2 2H5a2 2 0 0 1-2-2V8a2 2 0 0 1 2-2h6"/><polyline points="15 3 21 3 21 9"/><line x1="10" y1="14" x2="21" y2="3"/></svg>
-                View source
-            </a>
+            <div class="flex items-center gap-3">
+                {{template "provenanceBadge" .Doc}}
+                <a href="{{githubURL .Doc.Repo .Doc.Path .Doc.CommitSHA}}" target="_blank" rel="noopener noreferrer"
+                   class="inline-flex items-center gap-1 text-gray-400 dark:text-gray-500 hover:text-blue-600 dark:hover:text-blue-400 transition-colors">
+                    <svg xmlns="http://www.w3.org/2000/svg" width="14" height="14" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2" stroke-linecap="round" stroke-linejoin="round" aria-hidden="true"><path d="M18 13v6a2 2 0 0 1-2 2H5a2 2 0 0 1-2-2V8a2 2 0 0 1 2-2h6"/><polyline points="15 3 21 3 21 9"/><line x1="10" y1="14" x2="21" y2="3"/></svg>
+                    View source
+                </a>
+            </div>
         </div>
+        {{template "announcementBanner" .Announcement}}
+        {{template "freshnessBanner" .}}
         <div class="bg-white dark:bg-gray-800 rounded-lg border border-gray-200 dark:border-gray-700 p-4 scalar-card">
             <div id="scalar-api-reference"></div>
+            <p class="no-js-only text-sm text-gray-500 dark:text-gray-400">The interactive API reference needs JavaScript.
+                <a href="/raw/{{.Doc.Repo}}/{{urlPath .Doc.Path}}" class="text-blue-600 dark:text-blue-400 hover:underline">Download the specification</a> instead.</p>
             <script type="application/json" id="openapi-spec">{{safeJS .HTML}}</script>
+            {{if inlineScripts}}
             <script>
             (function() {
                 var specEl = document.getElementById('openapi-spec');
@@ -1123,10 +2041,138 @@ const openapiDocContentBody = `
                 document.head.appendChild(script);
             })();
             </script>
+            {{end}}
         </div>
     </article>
 </div>`
 
+// statsContentBody is the instance statistics page content template.
+const statsContentBody = `
+<div>
+    <h1 class="text-3xl font-bold text-gray-900 dark:text-gray-100 mb-2">Statistics</h1>
+    <p class="text-sm text-gray-500 dark:text-gray-400 mb-6">Activity counted since {{.Stats.Since.Format "Jan 02, 2006 15:04 MST"}}.</p>
+    <div class="grid grid-cols-2 md:grid-cols-3 gap-4 mb-8">
+        <div class="p-4 bg-white dark:bg-gray-800 rounded-lg border border-gray-200 dark:border-gray-700">
+            <div class="text-sm text-gray-500 dark:text-gray-400">Repositories</div>
+            <div class="text-2xl font-semibold text-gray-900 dark:text-gray-100">{{.Stats.Repos}}</div>
+        </div>
+        <div class="p-4 bg-white dark:bg-gray-800 rounded-lg border border-gray-200 dark:border-gray-700">
+            <div class="text-sm text-gray-500 dark:text-gray-400">Documents</div>
+            <div class="text-2xl font-semibold text-gray-900 dark:text-gray-100">{{.Stats.Documents}}</div>
+        </div>
+        <div class="p-4 bg-white dark:bg-gray-800 rounded-lg border border-gray-200 dark:border-gray-700">
+            <div class="text-sm text-gray-500 dark:text-gray-400">Searches (7 days)</div>
+            <div class="text-2xl font-semibold text-gray-900 dark:text-gray-100">{{.SearchesWeek}}</div>
+        </div>
+        <div class="p-4 bg-white dark:bg-gray-800 rounded-lg border border-gray-200 dark:border-gray-700">
+            <div class="text-sm text-gray-500 dark:text-gray-400">Ingests</div>
+            <div class="text-2xl font-semibold text-gray-900 dark:text-gray-100">{{.Stats.Ingests}}</div>
+            <div class="text-xs text-gray-400 dark:text-gray-500">{{.Stats.IngestedDocuments}} documents ingested</div>
+        </div>
+        {{if .StorageSize}}
+        <div class="p-4 bg-white dark:bg-gray-800 rounded-lg border border-gray-200 dark:border-gray-700">
+            <div class="text-sm text-gray-500 dark:text-gray-400">Storage</div>
+            <div class="text-2xl font-semibold text-gray-900 dark:text-gray-100">{{.StorageSize}}</div>
+        </div>
+        {{end}}
+        {{if .IndexSize}}
+        <div class="p-4 bg-white dark:bg-gray-800 rounded-lg border border-gray-200 dark:border-gray-700">
+            <div class="text-sm text-gray-500 dark:text-gray-400">Search index</div>
+            <div class="text-2xl font-semibold text-gray-900 dark:text-gray-100">{{.IndexSize}}</div>
+        </div>
+        {{end}}
+    </div>
+    <h2 class="text-lg font-semibold text-gray-900 dark:text-gray-100 mb-3">Searches per day</h2>
+    <div class="space-y-2">
+        {{range .Searches}}
+        <div class="flex items-center gap-3 text-sm">
+            <span class="w-24 flex-shrink-0 text-gray-500 dark:text-gray-400">{{.Date}}</span>
+            <div class="flex-1 h-3 bg-gray-100 dark:bg-gray-800 rounded">
+                <div class="h-3 bg-blue-500 rounded" style="width: {{.Percent}}%"></div>
+            </div>
+            <span class="w-12 text-right text-gray-700 dark:text-gray-300">{{.Count}}</span>
+        </div>
+        {{end}}
+    </div>
+    {{with .Stats.Experiment}}
+    <h2 class="text-lg font-semibold text-gray-900 dark:text-gray-100 mt-8 mb-3">Ranking experiment: {{.Name}}</h2>
+    <table class="ranking-experiment w-full text-sm text-left">
+        <thead class="text-gray-500 dark:text-gray-400">
+            <tr><th class="py-1">Variant</th><th class="py-1 text-right">Searches</th><th class="py-1 text-right">Clicks</th><th class="py-1 text-right">Click-through</th><th class="py-1 text-right">Mean click rank</th></tr>
+        </thead>
+        <tbody class="text-gray-700 dark:text-gray-300">
+            {{range .Variants}}
+            <tr class="border-t border-gray-200 dark:border-gray-700">
+                <td class="py-1">{{.Name}}</td>
+                <td class="py-1 text-right">{{.Searches}}</td>
+                <td class="py-1 text-right">{{.Clicks}}</td>
+                <td class="py-1 text-right">{{printf "%.1f%%" (percent .ClickThroughRate)}}</td>
+                <td class="py-1 text-right">{{if .Clicks}}{{printf "%.2f" .MeanClickRank}}{{else}}&ndash;{{end}}</td>
+            </tr>
+            {{end}}
+        </tbody>
+    </table>
+    {{end}}
+    {{with .Stats.RenderBudget}}
+    <h2 class="text-lg font-semibold text-gray-900 dark:text-gray-100 mt-8 mb-3">Render budget</h2>
+    <p class="text-sm text-gray-500 dark:text-gray-400 mb-3">{{.OverBudgetCount}} of {{.Measured}} measured documents exceed the budget of {{$.RenderBudget}}.</p>
+    {{if $.OverBudget}}
+    <div class="space-y-2">
+        {{range $.OverBudget}}
+        <a href="/docs/{{.Repo}}/{{urlPath .Path}}"
+           hx-get="/docs/{{.Repo}}/{{urlPath .Path}}" hx-target="#main-content" hx-push-url="true"
+           class="flex items-center gap-3 text-sm p-2 bg-white dark:bg-gray-800 rounded border border-gray-200 dark:border-gray-700 hover:border-blue-500">
+            <span class="flex-1 min-w-0 truncate text-gray-900 dark:text-gray-100">{{.Repo}}/{{.Path}}</span>
+            <span class="w-20 text-right text-gray-700 dark:text-gray-300">{{.Render}}</span>
+            <span class="w-20 text-right text-gray-700 dark:text-gray-300">{{.Size}}</span>
+        </a>
+        {{end}}
+    </div>
+    {{end}}
+    {{end}}
+</div>`
+
+// notificationsContentBody is the inbox page content template, listing the documents
+// newly published matching the reader's saved searches.
+const notificationsContentBody = `
+<div>
+    <div class="flex items-center justify-between mb-6">
+        <h1 class="text-3xl font-bold text-gray-900 dark:text-gray-100">Inbox</h1>
+        {{if .Unread}}
+        <button type="button" hx-post="/api/v1/notifications/read" hx-target="#main-content"
+                class="js-only px-3 py-1 text-sm rounded-lg border border-gray-300 dark:border-gray-600 text-gray-700 dark:text-gray-300 hover:border-blue-500">Mark all read</button>
+        {{end}}
+    </div>
+    {{if .Notifications}}
+    <ul class="notifications space-y-2">
+        {{range .Notifications}}
+        <li>
+            <a href="/docs/{{.Repo}}/{{urlPath .Path}}"
+               hx-get="/docs/{{.Repo}}/{{urlPath .Path}}" hx-target="#main-content" hx-push-url="true"
+               class="notification flex items-center gap-3 p-3 bg-white dark:bg-gray-800 rounded-lg border hover:border-blue-500 {{if .Unread}}border-blue-300 dark:border-blue-700{{else}}border-gray-200 dark:border-gray-700{{end}}"
+               {{- if .Unread}} data-unread{{end}}>
+                {{if .Unread}}<span class="w-2 h-2 flex-shrink-0 rounded-full bg-blue-500" aria-label="Unread"></span>{{end}}
+                <span class="flex-1 min-w-0">
+                    <span class="block font-medium text-gray-900 dark:text-gray-100 truncate">{{or .Title .Path}}</span>
+                    <span class="block text-xs text-gray-500 dark:text-gray-400 truncate">{{.Repo}}/{{.Path}} &middot; matched &ldquo;{{.Search}}&rdquo;</span>
+                </span>
+                <time datetime="{{.Time.Format "2006-01-02T15:04:05Z07:00"}}" class="flex-shrink-0 text-xs text-gray-500 dark:text-gray-400">{{.Time.Format "Jan 02, 2006 15:04"}}</time>
+            </a>
+        </li>
+        {{end}}
+    </ul>
+    {{else}}
+    <p class="text-gray-500 dark:text-gray-400">No notifications yet. Save a search to be notified here when documents matching it are published.</p>
+    {{end}}
+</div>`
+
+// notificationsLinkBody is the navigation link to the reader's inbox, with their unread
+// count.
+const notificationsLinkBody = `<a href="/notifications" hx-get="/notifications" hx-target="#main-content" hx-push-url="true"
+   class="notifications-link flex items-center gap-1 text-sm text-gray-600 hover:text-blue-600 dark:text-gray-300 dark:hover:text-blue-400">
+    Inbox{{if .}} <span class="px-1.5 rounded-full bg-blue-600 text-xs text-white" aria-label="{{.}} unread">{{.}}</span>{{end}}
+</a>`
+
 // notFoundBody is the 404 page content template.
 const notFoundBody = `
 <div class="text-center py-16">
@@ -1138,6 +2184,14 @@ const notFoundBody = `
     </a>
 </div>`
 
+// maintenanceBody is the maintenance page content template.
+const maintenanceBody = `
+<div class="text-center py-16">
+    <h1 class="text-4xl font-bold text-gray-900 dark:text-gray-100 mb-4">Down for Maintenance</h1>
+    <p class="text-gray-500 dark:text-gray-400 mb-2">{{siteName}} is undergoing maintenance and will be back shortly.</p>
+    {{if .}}<p class="text-gray-700 dark:text-gray-300">{{.}}</p>{{end}}
+</div>`
+
 // repoDocTreeSubTemplate is a recursive named sub-template that renders a []DocNode
 // as a directory tree for the repo index page.
 // Folder nodes render as a heading followed by an indented subtree.
@@ -1145,10 +2199,16 @@ const notFoundBody = `
 const repoDocTreeSubTemplate = `{{define "repoDocTree"}}
 {{range .}}
 {{if .Doc}}
-<a href="/docs/{{.Doc.Repo}}/{{.Doc.Path}}"
-   hx-get="/docs/{{.Doc.Repo}}/{{.Doc.Path}}" hx-target="#main-content" hx-push-url="true"
+<a href="/docs/{{.Doc.Repo}}/{{urlPath .Doc.Path}}"
+   hx-get="/docs/{{.Doc.Repo}}/{{urlPath .Doc.Path}}" hx-target="#main-content" hx-push-url="true"
    class="flex items-center justify-between p-4 bg-white dark:bg-gray-800 rounded-lg border border-gray-200 dark:border-gray-700 hover:border-blue-500 dark:hover:border-blue-500 hover:shadow-sm transition-all mb-2">
-    <h2 class="text-lg font-semibold text-gray-900 dark:text-gray-100">{{.Doc.Title}}</h2>
+    <div class="min-w-0">
+        <div class="flex items-center gap-2">
+            <h2 class="text-lg font-semibold text-gray-900 dark:text-gray-100">{{.Doc.Title}}</h2>
+            {{typeBadge .Doc.ContentType}}
+        </div>
+        {{with .Doc.Summary}}<p class="mt-1 text-sm text-gray-600 dark:text-gray-300 line-clamp-2">{{.}}</p>{{end}}
+    </div>
     <span class="text-sm text-gray-500 dark:text-gray-400 shrink-0 ml-4">Updated {{.Doc.UpdatedAt.Format "Jan 02, 2006"}}</span>
 </a>
 {{else}}
@@ -1173,10 +2233,12 @@ const sidebarDocTreeSubTemplate = `{{define "sidebarDocTree"}}
 {{range .Nodes}}
 {{if .Doc}}
 <li>
-    <a href="/docs/{{.Doc.Repo}}/{{.Doc.Path}}"
-       hx-get="/docs/{{.Doc.Repo}}/{{.Doc.Path}}" hx-target="#main-content" hx-push-url="true"
-       class="block px-3 py-1.5 text-sm rounded-md {{if eq .Doc.Path $.CurrentPath}}bg-blue-50 dark:bg-blue-900 text-blue-700 dark:text-blue-300 font-medium{{else}}text-gray-700 dark:text-gray-300 hover:bg-gray-100 dark:hover:bg-gray-800 hover:text-gray-900 dark:hover:text-gray-100{{end}}">
-        {{.Doc.Title}}
+    <a href="/docs/{{.Doc.Repo}}/{{urlPath .Doc.Path}}"
+       hx-get="/docs/{{.Doc.Repo}}/{{urlPath .Doc.Path}}" hx-target="#main-content" hx-push-url="true"
+       {{if ne .Doc.Path $.CurrentPath}}data-preview="/preview/{{.Doc.Repo}}/{{urlPath .Doc.Path}}"{{end}}
+       class="flex items-center gap-2 px-3 py-1.5 text-sm rounded-md {{if eq .Doc.Path $.CurrentPath}}bg-blue-50 dark:bg-blue-900 text-blue-700 dark:text-blue-300 font-medium{{else}}text-gray-700 dark:text-gray-300 hover:bg-gray-100 dark:hover:bg-gray-800 hover:text-gray-900 dark:hover:text-gray-100{{end}}">
+        {{typeIcon .Doc.ContentType}}
+        <span class="min-w-0">{{.Doc.Title}}</span>
     </a>
 </li>
 {{else}}
@@ -1192,3 +2254,390 @@ const sidebarDocTreeSubTemplate = `{{define "sidebarDocTree"}}
 {{end}}
 {{end}}
 {{end}}`
+
+// provenanceBadgeSubTemplate renders a badge on document pages for documents published
+// with a verified payload signature, naming the commit and the workflow that published
+// them and linking to the workflow run when it is known.
+const provenanceBadgeSubTemplate = `{{define "provenanceBadge"}}
+{{if and .Provenance .Provenance.Verified}}
+<span class="provenance-badge inline-flex items-center gap-1 px-2 py-0.5 rounded-full text-xs font-medium bg-green-50 dark:bg-green-900 text-green-700 dark:text-green-300"
+      title="Published by a signed payload{{if .CommitSHA}} from commit {{.CommitSHA}}{{end}}{{if .Provenance.Workflow}} by workflow {{.Provenance.Workflow}}{{end}}">
+    <svg xmlns="http://www.w3.org/2000/svg" width="12" height="12" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2" stroke-linecap="round" stroke-linejoin="round" aria-hidden="true"><path d="M12 22s8-4 8-10V5l-8-3-8 3v7c0 6 8 10 8 10z"/><polyline points="9 12 11 14 15 10"/></svg>
+    {{if .Provenance.RunURL}}<a href="{{.Provenance.RunURL}}" target="_blank" rel="noopener noreferrer" class="hover:underline">Verified provenance</a>{{else}}Verified provenance{{end}}
+    {{if .CommitSHA}}<code class="font-mono">{{shortSHA .CommitSHA}}</code>{{end}}
+</span>
+{{end}}
+{{end}}`
+
+// sectionPrintBody is a standalone page combining the documents of a repository section,
+// with a table of contents, for printing or saving as PDF. Styles are inlined so the page
+// also works when saved for offline reading.
+const sectionPrintBody = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>{{.Repo}}{{if .Dir}}/{{.Dir}}{{end}} - {{siteName}}</title>
+    <style>
+        body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif; color: #111827; line-height: 1.6; max-width: 48rem; margin: 2rem auto; padding: 0 1rem; }
+        a { color: #2563eb; }
+        pre { background: #f3f4f6; padding: 0.75rem; overflow-x: auto; white-space: pre-wrap; }
+        code { font-family: ui-monospace, SFMono-Regular, Menlo, monospace; font-size: 0.875em; }
+        table { border-collapse: collapse; }
+        th, td { border: 1px solid #d1d5db; padding: 0.25rem 0.5rem; }
+        img { max-width: 100%; }
+        .cover { border-bottom: 1px solid #d1d5db; margin-bottom: 2rem; }
+        .meta { color: #6b7280; font-size: 0.875rem; }
+        .toc ol { list-style: none; padding-left: 1.25rem; }
+        .toc > ol { padding-left: 0; }
+        .doc { border-top: 1px solid #d1d5db; margin-top: 3rem; padding-top: 1rem; }
+        @media print {
+            body { max-width: none; margin: 0; }
+            .no-print { display: none; }
+            .doc { break-before: page; border-top: none; margin-top: 0; }
+            a { color: inherit; text-decoration: none; }
+            pre, table, img { break-inside: avoid; }
+        }
+    </style>
+</head>
+<body>
+    <header class="cover">
+        <h1>{{.Repo}}{{if .Dir}}/{{.Dir}}{{end}}</h1>
+        <p class="meta">{{len .Docs}} document(s) · generated {{.GeneratedAt.Format "2006-01-02 15:04 UTC"}}</p>
+        {{if inlineScripts}}<p class="no-print"><button type="button" onclick="window.print()">Print or save as PDF</button></p>{{end}}
+    </header>
+    <nav class="toc" aria-label="Contents">
+        <h2>Contents</h2>
+        <ol>
+            {{range .Docs}}
+            <li>
+                <a href="#{{.Anchor}}">{{.Title}}</a>
+                {{if .Headings}}
+                <ol>
+                    {{range .Headings}}<li{{if eq .Level 3}} style="padding-left: 1rem"{{end}}><a href="#{{.ID}}">{{.Text}}</a></li>
+                    {{end}}
+                </ol>
+                {{end}}
+            </li>
+            {{end}}
+        </ol>
+    </nav>
+    {{range .Docs}}
+    <article class="doc" id="{{.Anchor}}">
+        <p class="meta">{{.Doc.Path}}{{if .Doc.CommitSHA}} · {{shortSHA .Doc.CommitSHA}}{{end}} · updated {{.Doc.UpdatedAt.Format "2006-01-02"}}</p>
+        {{html .HTML}}
+    </article>
+    {{end}}
+</body>
+</html>`
+
+// freshnessBannerSubTemplate renders a banner on document pages for documents that may be
+// outdated, see FreshnessConfig.
+const freshnessBannerSubTemplate = `{{define "freshnessBanner"}}
+{{with .Stale}}
+<div role="note" data-stale-banner
+     class="mb-4 px-4 py-3 text-sm rounded-lg border border-amber-300 dark:border-amber-800 bg-amber-50 dark:bg-amber-950 text-amber-900 dark:text-amber-200">
+    <strong class="font-semibold">This page may be outdated</strong> &mdash;
+    {{if .RepoAge}}{{$.Doc.Repo}} has not been published for {{.RepoAge}}.{{else}}last published {{.DocAge}} ago{{if $.Doc.CommitSHA}} from commit {{shortSHA $.Doc.CommitSHA}}{{end}}.{{end}}
+</div>
+{{end}}
+{{end}}`
+
+// announcementBannerSubTemplate renders the announcement of a repository, posted by its
+// owners, on its index and document pages. It expects the rendered announcement, which is
+// empty when the repository has none.
+const announcementBannerSubTemplate = `{{define "announcementBanner"}}
+{{if .}}
+<div role="note" data-announcement
+     class="announcement prose prose-sm prose-gray dark:prose-invert max-w-none mb-4 px-4 py-3 rounded-lg border border-blue-300 dark:border-blue-800 bg-blue-50 dark:bg-blue-950">
+    {{html .}}
+</div>
+{{end}}
+{{end}}`
+
+// previewBody is the hover preview of a document, loaded into the popover shown over search
+// results and sidebar links.
+const previewBody = `<div data-doc-preview>
+    <p class="text-xs text-gray-400 dark:text-gray-500 mb-1 truncate">{{.Doc.Repo}}/{{.Doc.Path}}</p>
+    <h3 class="text-base font-semibold text-gray-900 dark:text-gray-100 mb-2">{{or .Doc.Title .Doc.Path}}</h3>
+    {{if .Excerpt}}
+    <div class="prose prose-sm prose-gray dark:prose-invert max-w-none">{{html .Excerpt}}</div>
+    {{else if .Doc.Summary}}
+    <p class="text-sm text-gray-600 dark:text-gray-300">{{.Doc.Summary}}</p>
+    {{end}}
+    <p class="mt-2 text-xs text-gray-400 dark:text-gray-500">Updated {{.Doc.UpdatedAt.Format "Jan 02, 2006"}}</p>
+</div>`
+
+// editorBody is the web editor of a document of an editable repository. Once the signed-in
+// reader starts editing, the page locks the document for them, loads its source and renews
+// the lock while it is open. The lock is released when the editor saves, cancels or leaves
+// the page.
+const editorBody = `
+<div id="editor" data-repo="{{.Repo}}" data-path="{{.Path}}" data-doc-url="/docs/{{.Repo}}/{{urlPath .Path}}">
+    <div class="text-sm text-gray-500 dark:text-gray-400 mb-2">
+        <a href="/" class="hover:text-blue-600 dark:hover:text-blue-400">Home</a>
+        <span class="mx-1">/</span>
+        <a href="/docs/{{.Repo}}/" class="hover:text-blue-600 dark:hover:text-blue-400">{{.Repo}}</a>
+        <span class="mx-1">/</span>
+        <a href="/docs/{{.Repo}}/{{urlPath .Path}}" class="hover:text-blue-600 dark:hover:text-blue-400">{{.Path}}</a>
+    </div>
+    <h1 class="text-3xl font-bold text-gray-900 dark:text-gray-100 mb-4">Edit {{or .Title .Path}}</h1>
+    <p id="editor-status" role="status" aria-live="polite" class="mb-4 text-sm text-gray-600 dark:text-gray-300"></p>
+    <p class="no-js-only mb-4 text-sm text-gray-600 dark:text-gray-300">The editor needs JavaScript.</p>
+    <form id="editor-login" class="js-only max-w-md space-y-3">
+        <button type="submit" class="px-4 py-2 bg-blue-600 text-white rounded-lg hover:bg-blue-700 transition-colors">Start editing</button>
+    </form>
+    <div id="editor-main" hidden class="space-y-3">
+        <div class="flex gap-2 text-sm" role="tablist">
+            <button type="button" role="tab" data-editor-tab="write" aria-selected="true" class="px-3 py-1 rounded-lg border border-gray-300 dark:border-gray-600">Write</button>
+            <button type="button" role="tab" data-editor-tab="preview" aria-selected="false" class="px-3 py-1 rounded-lg border border-gray-300 dark:border-gray-600">Preview</button>
+        </div>
+        <textarea id="editor-source" spellcheck="true" aria-label="Document source"
+                  class="block w-full h-[60vh] p-4 font-mono text-sm rounded-lg border border-gray-300 dark:border-gray-600 bg-white dark:bg-gray-800 text-gray-900 dark:text-gray-100"></textarea>
+        <div id="editor-preview" hidden
+             class="prose prose-gray dark:prose-invert max-w-none min-h-[60vh] bg-white dark:bg-gray-800 rounded-lg border border-gray-200 dark:border-gray-700 p-8"></div>
+        <input id="editor-message" maxlength="200" placeholder="Update {{.Path}}" aria-label="Commit message"
+               class="block w-full px-3 py-2 rounded-lg border border-gray-300 dark:border-gray-600 bg-white dark:bg-gray-800">
+        <div class="flex gap-2">
+            <button type="button" data-editor-save class="px-4 py-2 bg-blue-600 text-white rounded-lg hover:bg-blue-700 transition-colors">Save</button>
+            <button type="button" data-editor-cancel class="px-4 py-2 rounded-lg border border-gray-300 dark:border-gray-600">Cancel</button>
+        </div>
+    </div>
+</div>
+{{if inlineScripts}}
+<script>
+    (function () {
+        var root = document.getElementById('editor');
+        var repo = root.dataset.repo, path = root.dataset.path, docURL = root.dataset.docUrl;
+        var status = document.getElementById('editor-status');
+        var login = document.getElementById('editor-login');
+        var main = document.getElementById('editor-main');
+        var source = document.getElementById('editor-source');
+        var preview = document.getElementById('editor-preview');
+        var token = '', renewTimer = null, dirty = false;
+
+        function setStatus(msg) { status.textContent = msg; }
+
+        function call(method, url, body) {
+            return fetch(url, {
+                method: method,
+                headers: {'Content-Type': 'application/json'},
+                body: body ? JSON.stringify(body) : undefined
+            }).then(function (resp) {
+                if (!resp.ok) {
+                    return resp.text().then(function (text) { throw new Error(text.trim() || resp.statusText); });
+                }
+                return resp.status === 204 ? null : resp.json();
+            });
+        }
+
+        function unlockURL() {
+            return '/api/v1/locks?repo=' + encodeURIComponent(repo) + '&path=' + encodeURIComponent(path) +
+                '&token=' + encodeURIComponent(token);
+        }
+
+        /* lock claims the document, or renews the lock held, and schedules the next renewal
+           halfway to its expiry. */
+        function lock() {
+            return call('POST', '/api/v1/locks', {repo: repo, path: path, token: token}).then(function (res) {
+                var first = !token;
+                token = res.lock.token;
+                if (first) { source.value = res.content; }
+                clearTimeout(renewTimer);
+                renewTimer = setTimeout(function () {
+                    lock().catch(function (err) { setStatus('Lost the lock: ' + err.message); });
+                }, Math.max((new Date(res.lock.expires_at) - Date.now()) / 2, 10000));
+                setStatus('Editing as ' + res.lock.owner + '. Others cannot edit this document until you save or cancel.');
+            });
+        }
+
+        function release() {
+            clearTimeout(renewTimer);
+            if (token) {
+                fetch(unlockURL(), {method: 'DELETE', keepalive: true});
+                token = '';
+            }
+        }
+
+        login.addEventListener('submit', function (e) {
+            e.preventDefault();
+            lock().then(function () {
+                login.hidden = true;
+                main.hidden = false;
+                source.focus();
+            }).catch(function (err) { setStatus(err.message); });
+        });
+
+        source.addEventListener('input', function () { dirty = true; });
+
+        root.querySelectorAll('[data-editor-tab]').forEach(function (tab) {
+            tab.addEventListener('click', function () {
+                var showPreview = tab.dataset.editorTab === 'preview';
+                root.querySelectorAll('[data-editor-tab]').forEach(function (t) {
+                    t.setAttribute('aria-selected', String(t === tab));
+                });
+                source.hidden = showPreview;
+                preview.hidden = !showPreview;
+                if (!showPreview) { return; }
+                call('POST', '/api/v1/preview', {repo: repo, path: path, content: source.value}).then(function (res) {
+                    preview.innerHTML = res.html;
+                    if (typeof mermaid !== 'undefined') {
+                        mermaid.run({nodes: Array.from(preview.querySelectorAll('.mermaid'))})
+                            .catch(function (e) { console.error('Mermaid rendering failed:', e); });
+                    }
+                }).catch(function (err) { setStatus('Preview failed: ' + err.message); });
+            });
+        });
+
+        root.querySelector('[data-editor-save]').addEventListener('click', function () {
+            var message = document.getElementById('editor-message').value.trim();
+            setStatus('Saving…');
+            call('POST', '/api/v1/edits', {repo: repo, path: path, content: source.value, message: message, token: token}).then(function (res) {
+                clearTimeout(renewTimer);
+                token = '';
+                dirty = false;
+                setStatus('Saved as commit ' + res.commit_sha.slice(0, 7) + '.');
+                window.location.href = docURL;
+            }).catch(function (err) { setStatus('Save failed: ' + err.message); });
+        });
+
+        root.querySelector('[data-editor-cancel]').addEventListener('click', function () {
+            dirty = false;
+            release();
+            window.location.href = docURL;
+        });
+
+        window.addEventListener('beforeunload', function (e) {
+            if (dirty) { e.preventDefault(); }
+        });
+        window.addEventListener('pagehide', release);
+    })();
+</script>
+{{end}}`
+
+// suggestionBody is the page where readers suggest an edit of a document. The source is
+// edited in the browser and submitted as a pull request on the source repository, after
+// which the page links to it.
+const suggestionBody = `
+<div id="suggestion" data-repo="{{.Repo}}" data-path="{{.Path}}">
+    <div class="text-sm text-gray-500 dark:text-gray-400 mb-2">
+        <a href="/" class="hover:text-blue-600 dark:hover:text-blue-400">Home</a>
+        <span class="mx-1">/</span>
+        <a href="/docs/{{.Repo}}/" class="hover:text-blue-600 dark:hover:text-blue-400">{{.Repo}}</a>
+        <span class="mx-1">/</span>
+        <a href="/docs/{{.Repo}}/{{urlPath .Path}}" class="hover:text-blue-600 dark:hover:text-blue-400">{{.Path}}</a>
+    </div>
+    <h1 class="text-3xl font-bold text-gray-900 dark:text-gray-100 mb-2">Suggest an edit to {{or .Title .Path}}</h1>
+    <p class="mb-4 text-sm text-gray-600 dark:text-gray-300">Your change is proposed to the maintainers of {{.Repo}} as a pull request, which they review before it is published.</p>
+    <p id="suggestion-status" role="status" aria-live="polite" class="mb-4 text-sm text-gray-600 dark:text-gray-300"></p>
+    <p class="no-js-only mb-4 text-sm text-gray-600 dark:text-gray-300">Suggesting an edit needs JavaScript.</p>
+    <form id="suggestion-form" class="js-only space-y-3">
+        <div class="flex gap-2 text-sm" role="tablist">
+            <button type="button" role="tab" data-suggestion-tab="write" aria-selected="true" class="px-3 py-1 rounded-lg border border-gray-300 dark:border-gray-600">Write</button>
+            <button type="button" role="tab" data-suggestion-tab="preview" aria-selected="false" class="px-3 py-1 rounded-lg border border-gray-300 dark:border-gray-600">Preview</button>
+        </div>
+        <textarea name="content" spellcheck="true" aria-label="Document source"
+                  class="block w-full h-[60vh] p-4 font-mono text-sm rounded-lg border border-gray-300 dark:border-gray-600 bg-white dark:bg-gray-800 text-gray-900 dark:text-gray-100">{{.Content}}</textarea>
+        <div id="suggestion-preview" hidden
+             class="prose prose-gray dark:prose-invert max-w-none min-h-[60vh] bg-white dark:bg-gray-800 rounded-lg border border-gray-200 dark:border-gray-700 p-8"></div>
+        <input name="title" maxlength="200" placeholder="Update {{.Path}}" aria-label="Title"
+               class="block w-full px-3 py-2 rounded-lg border border-gray-300 dark:border-gray-600 bg-white dark:bg-gray-800">
+        <textarea name="description" rows="3" maxlength="2000" placeholder="Why is this change needed? (optional)" aria-label="Description"
+                  class="block w-full px-3 py-2 rounded-lg border border-gray-300 dark:border-gray-600 bg-white dark:bg-gray-800"></textarea>
+        <input name="author" maxlength="100" autocomplete="name" placeholder="Your name (optional)" aria-label="Your name"
+               class="block w-full px-3 py-2 rounded-lg border border-gray-300 dark:border-gray-600 bg-white dark:bg-gray-800">
+        <div class="flex gap-2">
+            <button type="submit" class="px-4 py-2 bg-blue-600 text-white rounded-lg hover:bg-blue-700 transition-colors">Propose change</button>
+            <a href="/docs/{{.Repo}}/{{urlPath .Path}}" class="px-4 py-2 rounded-lg border border-gray-300 dark:border-gray-600">Cancel</a>
+        </div>
+    </form>
+</div>
+{{if inlineScripts}}
+<script>
+    (function () {
+        var root = document.getElementById('suggestion');
+        var repo = root.dataset.repo, path = root.dataset.path;
+        var status = document.getElementById('suggestion-status');
+        var form = document.getElementById('suggestion-form');
+        var source = form.elements.content;
+        var preview = document.getElementById('suggestion-preview');
+        var original = source.value, submitted = false;
+
+        try { form.elements.author.value = localStorage.getItem('presenceName') || ''; } catch (e) {}
+
+        function post(url, body) {
+            return fetch(url, {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify(body)
+            }).then(function (resp) {
+                if (!resp.ok) {
+                    return resp.text().then(function (text) { throw new Error(text.trim() || resp.statusText); });
+                }
+                return resp.json();
+            });
+        }
+
+        root.querySelectorAll('[data-suggestion-tab]').forEach(function (tab) {
+            tab.addEventListener('click', function () {
+                var showPreview = tab.dataset.suggestionTab === 'preview';
+                root.querySelectorAll('[data-suggestion-tab]').forEach(function (t) {
+                    t.setAttribute('aria-selected', String(t === tab));
+                });
+                source.hidden = showPreview;
+                preview.hidden = !showPreview;
+                if (!showPreview) { return; }
+                post('/suggestions/preview', {repo: repo, path: path, content: source.value}).then(function (res) {
+                    preview.innerHTML = res.html;
+                    if (typeof mermaid !== 'undefined') {
+                        mermaid.run({nodes: Array.from(preview.querySelectorAll('.mermaid'))})
+                            .catch(function (e) { console.error('Mermaid rendering failed:', e); });
+                    }
+                }).catch(function (err) { status.textContent = 'Preview failed: ' + err.message; });
+            });
+        });
+
+        form.addEventListener('submit', function (e) {
+            e.preventDefault();
+            var author = form.elements.author.value.trim();
+            try { if (author) { localStorage.setItem('presenceName', author); } } catch (ex) {}
+            status.textContent = 'Proposing your change…';
+            post('/suggestions', {
+                repo: repo,
+                path: path,
+                content: source.value,
+                title: form.elements.title.value.trim(),
+                description: form.elements.description.value.trim(),
+                author: author
+            }).then(function (res) {
+                submitted = true;
+                form.hidden = true;
+                status.textContent = 'Thank you! Your change was proposed as ';
+                var link = document.createElement('a');
+                link.href = res.url;
+                link.textContent = 'a pull request';
+                link.className = 'text-blue-600 dark:text-blue-400 underline';
+                status.appendChild(link);
+                status.appendChild(document.createTextNode('.'));
+            }).catch(function (err) { status.textContent = 'Could not propose the change: ' + err.message; });
+        });
+
+        window.addEventListener('beforeunload', function (e) {
+            if (!submitted && source.value !== original) { e.preventDefault(); }
+        });
+    })();
+</script>
+{{end}}`
+
+// structuredDataSubTemplate fills the head of document pages with schema.org JSON-LD and
+// Open Graph tags describing the document, used by crawlers and link previews.
+const structuredDataSubTemplate = `{{define "structuredData"}}
+    <meta property="og:type" content="article">
+    <meta property="og:site_name" content="{{siteName}}">
+    <meta property="og:title" content="{{or .Doc.Title .Doc.Path}}">
+    {{- with .Doc.Summary}}
+    <meta name="description" content="{{.}}">
+    <meta property="og:description" content="{{.}}">
+    {{- end}}
+    <script type="application/ld+json">{{structuredData .Doc}}</script>
+{{end}}`
@@ -0,0 +1,799 @@
+package omnidex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/api"
+	"github.com/ksysoev/omnidex/pkg/api/middleware"
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/ksysoev/omnidex/pkg/prov/asciidoc"
+	"github.com/ksysoev/omnidex/pkg/prov/asyncapi"
+	"github.com/ksysoev/omnidex/pkg/prov/embed"
+	"github.com/ksysoev/omnidex/pkg/prov/github"
+	"github.com/ksysoev/omnidex/pkg/prov/graphql"
+	"github.com/ksysoev/omnidex/pkg/prov/jsonschema"
+	"github.com/ksysoev/omnidex/pkg/prov/linkcheck"
+	"github.com/ksysoev/omnidex/pkg/prov/llm"
+	"github.com/ksysoev/omnidex/pkg/prov/mail"
+	"github.com/ksysoev/omnidex/pkg/prov/markdown"
+	"github.com/ksysoev/omnidex/pkg/prov/oidc"
+	"github.com/ksysoev/omnidex/pkg/prov/openapi"
+	"github.com/ksysoev/omnidex/pkg/prov/pdf"
+	"github.com/ksysoev/omnidex/pkg/prov/policy"
+	"github.com/ksysoev/omnidex/pkg/prov/webhook"
+	"github.com/ksysoev/omnidex/pkg/repo/docstore"
+	"github.com/ksysoev/omnidex/pkg/repo/journal"
+	"github.com/ksysoev/omnidex/pkg/repo/s3store"
+	"github.com/ksysoev/omnidex/pkg/repo/savedsearch"
+	"github.com/ksysoev/omnidex/pkg/repo/search"
+	"github.com/ksysoev/omnidex/pkg/repo/sqlstore"
+	"github.com/ksysoev/omnidex/pkg/views"
+)
+
+const (
+	defaultWarmupTimeout     = 2 * time.Minute
+	defaultWarmupDocsPerRepo = 5
+	defaultLinkCheckInterval = 24 * time.Hour
+	defaultJournalPath       = "./data/changes.jsonl"
+	defaultJournalRetention  = 90 * 24 * time.Hour
+	defaultRetentionInterval = 24 * time.Hour
+	defaultSavedSearchPath   = "./data/saved_searches.json"
+)
+
+// DocStore is the storage of documents, assets and portal settings, implemented by the
+// storage backends in pkg/repo.
+type DocStore interface {
+	Save(ctx context.Context, doc core.Document) error
+	Get(ctx context.Context, repo, path string) (core.Document, error)
+	Delete(ctx context.Context, repo, path string) error
+	List(ctx context.Context, repo string) ([]core.DocumentMeta, error)
+	ListRepos(ctx context.Context) ([]core.RepoInfo, error)
+	SaveAsset(ctx context.Context, repo, path string, data []byte) error
+	GetAsset(ctx context.Context, repo, path string) ([]byte, error)
+	DeleteAsset(ctx context.Context, repo, path string) error
+	ListAssets(ctx context.Context, repo string) ([]string, error)
+	DeleteRepo(ctx context.Context, repo string) error
+	GetRedirects(ctx context.Context) (map[string]string, error)
+	SaveRedirects(ctx context.Context, redirects map[string]string) error
+	GetAPIKeys(ctx context.Context) ([]core.APIKey, error)
+	SaveAPIKeys(ctx context.Context, keys []core.APIKey) error
+	GetHighlights(ctx context.Context) (map[string]core.RepoHighlights, error)
+	SaveHighlights(ctx context.Context, highlights map[string]core.RepoHighlights) error
+	GetSiteBanner(ctx context.Context) (*core.SiteBanner, error)
+	SaveSiteBanner(ctx context.Context, banner *core.SiteBanner) error
+}
+
+// SearchEngine is the full-text search index of documents, implemented by the search
+// backends in pkg/repo/search.
+type SearchEngine interface {
+	Index(ctx context.Context, doc core.Document, plainText string, code []core.CodeBlock, headings []core.Heading) error
+	Remove(ctx context.Context, docID string) error
+	Search(ctx context.Context, query string, opts core.SearchOpts) (*core.SearchResults, error)
+	Suggest(ctx context.Context, prefix string, limit int) ([]core.SearchSuggestion, error)
+	ListByRepo(ctx context.Context, repo string) ([]string, error)
+}
+
+// ViewsFactory creates the view renderer of the portal from the view options the server
+// configures, such as the site name of a host with its own branding.
+type ViewsFactory func(opts ...views.Option) api.ViewRenderer
+
+// Option customizes how NewServer assembles a Server.
+type Option func(*serverOptions)
+
+type serverOptions struct {
+	store     DocStore
+	search    SearchEngine
+	newViews  ViewsFactory
+	viewOpts  []views.Option
+	verifiers []middleware.KeyVerifier
+}
+
+// WithDocStore keeps documents in store instead of the storage backend selected by the
+// storage section of the configuration.
+func WithDocStore(store DocStore) Option {
+	return func(o *serverOptions) {
+		o.store = store
+	}
+}
+
+// WithSearchEngine indexes documents in engine instead of the search backend selected by
+// the search section of the configuration. The index is not rebuilt on startup; call
+// Service().ReindexAll to fill a new index.
+func WithSearchEngine(engine SearchEngine) Option {
+	return func(o *serverOptions) {
+		o.search = engine
+	}
+}
+
+// WithKeyVerifier accepts the Bearer tokens verify reports as valid wherever an API key is
+// accepted, such as tokens issued by the identity provider of the embedding application.
+func WithKeyVerifier(verify middleware.KeyVerifier) Option {
+	return func(o *serverOptions) {
+		o.verifiers = append(o.verifiers, verify)
+	}
+}
+
+// WithViewOptions adds view options to those derived from the configuration, for example
+// views.WithSiteName to brand the portal. They apply to every host.
+func WithViewOptions(opts ...views.Option) Option {
+	return func(o *serverOptions) {
+		o.viewOpts = append(o.viewOpts, opts...)
+	}
+}
+
+// WithViews renders the portal with the view renderers created by newViews instead of the
+// built-in templates. It is called with the view options of the default portal and of each
+// host with its own site name.
+func WithViews(newViews ViewsFactory) Option {
+	return func(o *serverOptions) {
+		o.newViews = newViews
+	}
+}
+
+// Server is an Omnidex server: the core service wired to its storage, search engine and
+// content processors, and the ingest API and portal serving it.
+type Server struct {
+	svc     *core.Service
+	api     *api.API
+	closers []func() error
+}
+
+// NewServer assembles a server from the configuration, as the server command does, with
+// the storage, search engine, authentication or views replaced by opts. Background work,
+// such as rebuilding a recreated search index or checking links, starts right away and
+// runs until ctx is cancelled. Applications embedding the portal serve Handler from their
+// own HTTP server; others call Run. Close releases the storage and indexes once the server
+// is no longer used.
+func NewServer(ctx context.Context, cfg *Config, opts ...Option) (*Server, error) {
+	var o serverOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	s := &Server{}
+
+	if err := s.init(ctx, cfg, &o); err != nil {
+		return nil, errors.Join(err, s.Close())
+	}
+
+	return s, nil
+}
+
+// init wires the server, registering the resources to release in s.closers as they are
+// opened.
+func (s *Server) init(ctx context.Context, cfg *Config, o *serverOptions) error {
+	searchEngine, rebuildIndex := o.search, false
+
+	if searchEngine == nil {
+		engine, rebuild, err := s.newSearchEngine(ctx, &cfg.Search)
+		if err != nil {
+			return err
+		}
+
+		searchEngine, rebuildIndex = engine, rebuild
+	}
+
+	// Initialize markdown renderer.
+	renderer, err := markdown.New(cfg.Markdown)
+	if err != nil {
+		return fmt.Errorf("failed to create markdown renderer: %w", err)
+	}
+
+	// Initialize core service with content processors.
+	processors := map[core.ContentType]core.ContentProcessor{
+		core.ContentTypeMarkdown:   renderer,
+		core.ContentTypeOpenAPI:    openapi.New(),
+		core.ContentTypeAsyncAPI:   asyncapi.New(),
+		core.ContentTypeGraphQL:    graphql.New(),
+		core.ContentTypeJSONSchema: jsonschema.New(),
+		core.ContentTypeAsciiDoc:   asciidoc.New(cfg.AsciiDoc),
+		core.ContentTypePDF:        pdf.New(),
+	}
+
+	svcOpts, err := s.serviceOptions(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	store := o.store
+
+	if store == nil {
+		if store, err = s.newDocStore(ctx, &cfg.Storage); err != nil {
+			return err
+		}
+	}
+
+	// Prune the version history of the store past its retention.
+	if cfg.Retention.History > 0 {
+		history, ok := store.(core.Pruner)
+		if !ok {
+			return fmt.Errorf("retention.history requires the git storage backend")
+		}
+
+		svcOpts = append(svcOpts, core.WithRetention(core.RetentionPolicy{Class: "history", Period: cfg.Retention.History, Pruner: history}))
+	}
+
+	s.svc = core.New(store, searchEngine, processors, svcOpts...)
+
+	if err := s.initAPI(ctx, cfg, o); err != nil {
+		return err
+	}
+
+	s.startBackground(ctx, cfg, rebuildIndex)
+
+	return nil
+}
+
+// newSearchEngine creates the search engine selected by the configuration. It reports
+// whether the index was recreated empty and must be repopulated from the document store.
+func (s *Server) newSearchEngine(ctx context.Context, cfg *SearchConfig) (SearchEngine, bool, error) {
+	switch cfg.Type {
+	case "elasticsearch":
+		eng, err := search.NewElastic(ctx, &cfg.Elastic)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to create elasticsearch engine: %w", err)
+		}
+
+		return eng, eng.NeedsReindex(), nil
+	case "opensearch":
+		eng, err := search.NewOpenSearch(ctx, &cfg.OpenSearch)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to create opensearch engine: %w", err)
+		}
+
+		return eng, eng.NeedsReindex(), nil
+	case "meilisearch":
+		eng, err := search.NewMeilisearch(ctx, &cfg.Meilisearch)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to create meilisearch engine: %w", err)
+		}
+
+		return eng, false, nil
+	case "", "bleve":
+		eng, err := search.NewBleve(cfg.IndexPath, cfg.Bleve)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to create search engine: %w", err)
+		}
+
+		s.closers = append(s.closers, eng.Close)
+
+		return eng, eng.NeedsReindex(), nil
+	default:
+		return nil, false, fmt.Errorf("unknown search type %q: must be \"bleve\", \"elasticsearch\", \"opensearch\" or \"meilisearch\"", cfg.Type)
+	}
+}
+
+// newDocStore creates the document storage backend selected by the configuration.
+func (s *Server) newDocStore(ctx context.Context, cfg *StorageConfig) (DocStore, error) {
+	switch cfg.Type {
+	case "s3":
+		store, err := s3store.New(ctx, cfg.S3)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create S3 document store: %w", err)
+		}
+
+		return store, nil
+	case "", "local":
+		store, err := docstore.New(cfg.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create document store: %w", err)
+		}
+
+		return store, nil
+	case "git":
+		store, err := docstore.NewGit(cfg.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create git document store: %w", err)
+		}
+
+		return store, nil
+	case "sqlite":
+		store, err := sqlstore.New(filepath.Join(cfg.Path, sqliteFileName))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create SQLite document store: %w", err)
+		}
+
+		s.closers = append(s.closers, store.Close)
+
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unknown storage type %q: must be \"local\", \"git\", \"s3\" or \"sqlite\"", cfg.Type)
+	}
+}
+
+// serviceOptions returns the options of the core service enabled by the configuration.
+func (s *Server) serviceOptions(ctx context.Context, cfg *Config) ([]core.Option, error) {
+	if err := cfg.Lint.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid lint config: %w", err)
+	}
+
+	svcOpts := []core.Option{core.WithLint(cfg.Lint)}
+
+	if cfg.Policy.Enabled() {
+		contentPolicy, err := policy.New(cfg.Policy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create content policy: %w", err)
+		}
+
+		svcOpts = append(svcOpts, core.WithContentPolicy(contentPolicy))
+	}
+
+	// Trigger republishes through repository_dispatch events when a GitHub token is configured.
+	if cfg.Republish.Enabled() {
+		svcOpts = append(svcOpts, core.WithRepublisher(github.New(cfg.Republish)))
+	}
+
+	// Edits made in the web editor are committed through the GitHub contents API with the
+	// republish token, on behalf of readers signed in through the authenticating proxy.
+	if len(cfg.Edit.Repos) > 0 {
+		if !cfg.Republish.Enabled() {
+			return nil, fmt.Errorf("edit.repos requires a GitHub token in republish.token")
+		}
+
+		if cfg.API.Login.UserHeader == "" {
+			return nil, fmt.Errorf("edit.repos requires readers to sign in through api.login.user_header")
+		}
+
+		svcOpts = append(svcOpts, core.WithEditor(cfg.Edit, github.NewCommitter(cfg.Republish)))
+	}
+
+	// Edits suggested by readers are proposed as pull requests opened with the republish token.
+	if len(cfg.Suggest.Repos) > 0 {
+		if !cfg.Republish.Enabled() {
+			return nil, fmt.Errorf("suggest.repos requires a GitHub token in republish.token")
+		}
+
+		svcOpts = append(svcOpts, core.WithSuggestions(cfg.Suggest, github.NewCommitter(cfg.Republish)))
+	}
+
+	if cfg.LinkCheck.Enabled {
+		svcOpts = append(svcOpts, core.WithLinkChecker(linkcheck.New(cfg.LinkCheck)))
+	}
+
+	if cfg.Dedup.Enabled {
+		svcOpts = append(svcOpts, core.WithDuplicateDetection())
+	}
+
+	if cfg.Render.Enabled {
+		svcOpts = append(svcOpts, core.WithRenderBudget(core.RenderBudget{MaxDuration: cfg.Render.MaxDuration, MaxBytes: cfg.Render.MaxBytes}))
+	}
+
+	// Summarize documents with a language model instead of their first paragraph when configured.
+	if cfg.Summary.Enabled() {
+		svcOpts = append(svcOpts, core.WithSummarizer(llm.New(cfg.Summary)))
+	}
+
+	searchOpts, err := s.searchOptions(ctx, &cfg.Search)
+	if err != nil {
+		return nil, err
+	}
+
+	svcOpts = append(svcOpts, searchOpts...)
+
+	// Record document changes in the journal and email them to teams as weekly digests.
+	if cfg.Digest.Enabled() {
+		if err := cfg.Digest.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid digest config: %w", err)
+		}
+
+		if !cfg.SMTP.Enabled() {
+			return nil, fmt.Errorf("digest.teams requires an SMTP server in smtp.host and smtp.from")
+		}
+
+		changes, err := journal.New(journalPath(cfg.Journal), journalRetention(cfg.Journal))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open change journal: %w", err)
+		}
+
+		svcOpts = append(svcOpts,
+			core.WithChangeJournal(changes),
+			core.WithDigests(cfg.Digest, mail.New(cfg.SMTP)),
+			core.WithRetention(core.RetentionPolicy{Class: "journal", Period: journalRetention(cfg.Journal), Pruner: changes}),
+		)
+	}
+
+	// Keep saved searches and alert them of newly published matches.
+	if cfg.Saved.Enabled {
+		saved, err := savedsearch.New(savedSearchPath(cfg.Saved))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open saved searches: %w", err)
+		}
+
+		svcOpts = append(svcOpts, core.WithSavedSearches(saved, webhook.New(cfg.Saved.PortalURL), cfg.Saved.WebhookHosts))
+	}
+
+	return svcOpts, nil
+}
+
+// searchOptions returns the options of the core service for semantic search, ranking
+// experiments and search migrations enabled by the configuration.
+func (s *Server) searchOptions(ctx context.Context, cfg *SearchConfig) ([]core.Option, error) {
+	var svcOpts []core.Option
+
+	// Embed documents for semantic search, keeping the vectors next to the search index.
+	if cfg.Semantic.Enabled() {
+		embedder, err := embed.New(cfg.Semantic)
+		if err != nil {
+			return nil, fmt.Errorf("invalid search.semantic config: %w", err)
+		}
+
+		if err := cfg.Hybrid.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid search.hybrid config: %w", err)
+		}
+
+		if cfg.IndexPath == "" {
+			return nil, fmt.Errorf("search.semantic requires search.index_path for the vector index")
+		}
+
+		vectors, err := search.NewVectorIndex(cfg.IndexPath+".vectors", embedder.Model())
+		if err != nil {
+			return nil, fmt.Errorf("failed to create vector index: %w", err)
+		}
+
+		s.closers = append(s.closers, vectors.Close)
+
+		svcOpts = append(svcOpts, core.WithSemanticSearch(embedder, vectors), core.WithHybridRanking(cfg.Hybrid))
+	}
+
+	// Rank the searches of readers' sessions with the variants of a ranking experiment.
+	if cfg.Experiment.Enabled() {
+		if !cfg.Semantic.Enabled() {
+			return nil, fmt.Errorf("search.experiment requires search.semantic: without it every search is ranked the same")
+		}
+
+		if err := cfg.Experiment.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid search.experiment config: %w", err)
+		}
+
+		svcOpts = append(svcOpts, core.WithRankingExperiment(cfg.Experiment))
+	}
+
+	// Write every index change to the backend being migrated to as well.
+	if cfg.Migration.Enabled() {
+		migration, err := newSearchMigration(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		svcOpts = append(svcOpts, migration)
+	}
+
+	return svcOpts, nil
+}
+
+// initAPI creates the view renderers and the API serving the core service.
+func (s *Server) initAPI(ctx context.Context, cfg *Config, o *serverOptions) error {
+	editableRepos := make([]string, 0, len(cfg.Edit.Repos))
+	for _, r := range cfg.Edit.Repos {
+		editableRepos = append(editableRepos, r.Repo)
+	}
+
+	suggestibleRepos := make([]string, 0, len(cfg.Suggest.Repos))
+	for _, r := range cfg.Suggest.Repos {
+		suggestibleRepos = append(suggestibleRepos, r.Repo)
+	}
+
+	viewOpts := []views.Option{
+		views.WithFreshness(cfg.Freshness),
+		views.WithEditableRepos(editableRepos...),
+		views.WithSuggestibleRepos(suggestibleRepos...),
+		views.WithSemanticSearch(cfg.Search.Semantic.Enabled()),
+		views.WithSiteBanner(func() *core.PageBanner { return s.svc.ActiveSiteBanner(ctx) }),
+		views.WithContentTypes(s.svc.ContentTypes()),
+		views.WithoutInlineScripts(cfg.UI.DisableInlineScripts),
+		views.WithKaTeXURL(cfg.UI.KaTeXURL),
+		views.WithNotifications(cfg.Saved.Enabled && cfg.API.Login.UserHeader != ""),
+	}
+	viewOpts = append(viewOpts, o.viewOpts...)
+
+	newViews := o.newViews
+	if newViews == nil {
+		newViews = func(opts ...views.Option) api.ViewRenderer { return views.New(opts...) }
+	}
+
+	apiCfg := cfg.API
+	if apiCfg.StaticFS == nil {
+		apiCfg.StaticFS = StaticFiles
+	}
+
+	// Accept GitHub Actions OIDC ID tokens as ingest credentials when an audience is configured.
+	if cfg.OIDC.Enabled() {
+		if err := cfg.OIDC.Validate(); err != nil {
+			return fmt.Errorf("invalid oidc config: %w", err)
+		}
+
+		apiCfg.RepoTokens = oidc.New(cfg.OIDC).VerifyRepoToken
+	}
+
+	// Hosts with their own site name are rendered with their own branding.
+	apiOpts := []api.Option{api.WithSiteViews(func(siteName string) api.ViewRenderer {
+		return newViews(append([]views.Option{views.WithSiteName(siteName)}, viewOpts...)...)
+	})}
+
+	for _, verify := range o.verifiers {
+		apiOpts = append(apiOpts, api.WithKeyVerifier(verify))
+	}
+
+	apiSvc, err := api.New(apiCfg, s.svc, newViews(viewOpts...), apiOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to create API service: %w", err)
+	}
+
+	s.api = apiSvc
+
+	return nil
+}
+
+// startBackground starts the background work enabled by the configuration, which runs
+// until ctx is cancelled.
+func (s *Server) startBackground(ctx context.Context, cfg *Config, rebuildIndex bool) {
+	// Repopulate a recreated search index in the background; documents are still
+	// served from the store while search results fill in.
+	if rebuildIndex {
+		go func() {
+			slog.InfoContext(ctx, "rebuilding search index from document store")
+
+			indexed, failed, err := s.svc.ReindexAll(ctx)
+			if err != nil {
+				slog.ErrorContext(ctx, "search index rebuild failed", "error", err, "indexed", indexed, "failed", failed)
+				return
+			}
+
+			slog.InfoContext(ctx, "search index rebuilt", "indexed", indexed, "failed", failed)
+		}()
+	}
+
+	// Embed documents published before semantic search was enabled, or embedded by another
+	// model, in the background; semantic results fill in as they are embedded.
+	if cfg.Search.Semantic.Enabled() {
+		go func() {
+			embedded, failed, err := s.svc.EmbedAll(ctx)
+			if err != nil {
+				slog.ErrorContext(ctx, "embedding documents failed", "error", err, "embedded", embedded, "failed", failed)
+				return
+			}
+
+			slog.InfoContext(ctx, "documents embedded", "embedded", embedded, "failed", failed)
+		}()
+	}
+
+	if cfg.Warmup.Enabled {
+		s.api.SetReady(false)
+
+		go runWarmup(ctx, s.svc, s.api, cfg.Warmup)
+	}
+
+	if cfg.LinkCheck.Enabled {
+		go runLinkChecks(ctx, s.svc, cfg.LinkCheck.Interval)
+	}
+
+	if cfg.Digest.Enabled() {
+		go runDigests(ctx, s.svc, cfg.Digest)
+	}
+
+	if cfg.Retention.History > 0 || cfg.Digest.Enabled() {
+		go runRetention(ctx, s.svc, cfg.Retention.Interval)
+	}
+}
+
+// Service returns the core service of the server, for applications that publish or query
+// documents directly rather than through the API.
+func (s *Server) Service() *core.Service {
+	return s.svc
+}
+
+// Handler returns the handler serving the ingest API and the portal, to be served by the
+// embedding application's HTTP server, wrapped in its own middleware. The portal must be
+// served at the root of its host, as it links to its pages by absolute paths.
+func (s *Server) Handler() (http.Handler, error) {
+	return s.api.Handler()
+}
+
+// Run serves the API and portal on the configured listen address until ctx is cancelled.
+func (s *Server) Run(ctx context.Context) error {
+	return s.api.Run(ctx)
+}
+
+// Close releases the storage and indexes opened by the server, in the reverse order they
+// were opened.
+func (s *Server) Close() error {
+	var errs []error
+
+	for i := len(s.closers) - 1; i >= 0; i-- {
+		errs = append(errs, s.closers[i]())
+	}
+
+	s.closers = nil
+
+	return errors.Join(errs...)
+}
+
+// newSearchMigration creates the search engine the index is migrated to and returns the
+// option writing index changes to it. The target must be an external backend other than
+// the configured search type.
+func newSearchMigration(ctx context.Context, cfg *SearchConfig) (core.Option, error) {
+	if cfg.Migration.Target == cfg.Type {
+		return nil, fmt.Errorf("search.migration.target %q must differ from search.type", cfg.Migration.Target)
+	}
+
+	switch cfg.Migration.Target {
+	case "elasticsearch":
+		target, err := search.NewElastic(ctx, &cfg.Elastic)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create elasticsearch migration target: %w", err)
+		}
+
+		return core.WithSearchMigration(target, cfg.Migration.ReadTarget), nil
+	case "opensearch":
+		target, err := search.NewOpenSearch(ctx, &cfg.OpenSearch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create opensearch migration target: %w", err)
+		}
+
+		return core.WithSearchMigration(target, cfg.Migration.ReadTarget), nil
+	case "meilisearch":
+		target, err := search.NewMeilisearch(ctx, &cfg.Meilisearch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create meilisearch migration target: %w", err)
+		}
+
+		return core.WithSearchMigration(target, cfg.Migration.ReadTarget), nil
+	default:
+		return nil, fmt.Errorf("unknown search.migration.target %q: must be \"elasticsearch\", \"opensearch\" or \"meilisearch\"", cfg.Migration.Target)
+	}
+}
+
+// journalPath returns the path of the change journal file.
+func journalPath(cfg JournalConfig) string {
+	if cfg.Path == "" {
+		return defaultJournalPath
+	}
+
+	return cfg.Path
+}
+
+// journalRetention returns how long the change journal keeps changes.
+func journalRetention(cfg JournalConfig) time.Duration {
+	if cfg.Retention <= 0 {
+		return defaultJournalRetention
+	}
+
+	return cfg.Retention
+}
+
+// savedSearchPath returns the path of the saved search file.
+func savedSearchPath(cfg SavedSearchConfig) string {
+	if cfg.Path == "" {
+		return defaultSavedSearchPath
+	}
+
+	return cfg.Path
+}
+
+// runWarmup warms the search index and renderers, then marks the API ready. The API is
+// marked ready even if the warm-up fails or times out, since it is only an optimization.
+func runWarmup(ctx context.Context, svc *core.Service, apiSvc *api.API, cfg WarmupConfig) {
+	defer apiSvc.SetReady(true)
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultWarmupTimeout
+	}
+
+	docsPerRepo := cfg.DocsPerRepo
+	if docsPerRepo <= 0 {
+		docsPerRepo = defaultWarmupDocsPerRepo
+	}
+
+	warmCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+
+	if err := svc.Warmup(warmCtx, cfg.Queries, docsPerRepo); err != nil {
+		slog.WarnContext(ctx, "startup warm-up did not complete", "error", err, "duration", time.Since(start))
+		return
+	}
+
+	slog.InfoContext(ctx, "startup warm-up complete", "duration", time.Since(start))
+}
+
+// runLinkChecks checks the external links of the published documents on startup and then
+// every interval until ctx is cancelled.
+func runLinkChecks(ctx context.Context, svc *core.Service, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultLinkCheckInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		start := time.Now()
+
+		if err := svc.CheckExternalLinks(ctx); err != nil {
+			slog.WarnContext(ctx, "external link check failed", "error", err)
+		} else {
+			slog.InfoContext(ctx, "external link check complete", "duration", time.Since(start))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runRetention discards the data past its retention on startup and then every interval
+// until ctx is cancelled.
+func runRetention(ctx context.Context, svc *core.Service, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultRetentionInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := svc.EnforceRetention(ctx); err != nil {
+			slog.WarnContext(ctx, "enforcing retention failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runDigests emails the weekly digests at the configured weekday and time, each covering
+// the week before, until ctx is cancelled.
+func runDigests(ctx context.Context, svc *core.Service, cfg core.DigestConfig) {
+	day, at, err := cfg.Schedule()
+	if err != nil {
+		slog.ErrorContext(ctx, "invalid digest schedule", "error", err)
+		return
+	}
+
+	for {
+		next := nextDigest(time.Now(), day, at)
+
+		timer := time.NewTimer(time.Until(next))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		sent, err := svc.SendDigests(ctx, next.Add(-core.DigestPeriod), next)
+		if err != nil {
+			slog.WarnContext(ctx, "sending digests failed", "error", err, "sent", sent)
+			continue
+		}
+
+		slog.InfoContext(ctx, "digests sent", "sent", sent)
+	}
+}
+
+// nextDigest returns the first time after now that falls on day at the given UTC time of day.
+func nextDigest(now time.Time, day time.Weekday, at time.Duration) time.Time {
+	now = now.UTC()
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	next := midnight.AddDate(0, 0, (int(day)-int(now.Weekday())+7)%7).Add(at)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 7)
+	}
+
+	return next
+}
@@ -0,0 +1,194 @@
+package omnidex
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ksysoev/omnidex/pkg/api"
+	"github.com/ksysoev/omnidex/pkg/core"
+	"github.com/ksysoev/omnidex/pkg/repo/docstore"
+	"github.com/ksysoev/omnidex/pkg/views"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestConfig returns the configuration of a server keeping its documents and index in
+// a temporary directory.
+func newTestConfig(t *testing.T) *Config {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	return &Config{
+		API:     api.Config{Listen: ":0"},
+		Storage: StorageConfig{Path: filepath.Join(dir, "repos")},
+		Search:  SearchConfig{IndexPath: filepath.Join(dir, "search.bleve")},
+	}
+}
+
+// serve sends a GET request for target to the handler of srv.
+func serve(t *testing.T, srv *Server, target, token string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	handler, err := srv.Handler()
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, target, http.NoBody)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestNewServer(t *testing.T) {
+	srv, err := NewServer(t.Context(), newTestConfig(t), WithViewOptions(views.WithSiteName("Acme Docs")))
+	require.NoError(t, err)
+
+	defer func() { assert.NoError(t, srv.Close()) }()
+
+	rec := serve(t, srv, "/", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Acme Docs")
+
+	assert.Equal(t, http.StatusOK, serve(t, srv, "/static/js/htmx.min.js", "").Code)
+}
+
+func TestNewServer_Overrides(t *testing.T) {
+	store, err := docstore.New(t.TempDir())
+	require.NoError(t, err)
+
+	cfg := newTestConfig(t)
+	cfg.Storage.Type = "unknown"
+
+	srv, err := NewServer(t.Context(), cfg,
+		WithDocStore(store),
+		WithKeyVerifier(func(_ context.Context, token string) bool { return token == "sso-token" }),
+	)
+	require.NoError(t, err, "the configured storage is not used")
+
+	defer func() { assert.NoError(t, srv.Close()) }()
+
+	_, err = srv.Service().IngestDocuments(t.Context(), &core.IngestRequest{
+		Repo:      "acme/handbook",
+		Documents: []core.IngestDocument{{Path: "README.md", Content: "# Handbook", Action: "upsert"}},
+	})
+	require.NoError(t, err)
+
+	docs, err := store.List(t.Context(), "acme/handbook")
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+
+	assert.Equal(t, http.StatusOK, serve(t, srv, "/api/v1/repos", "sso-token").Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(t, srv, "/api/v1/repos", "other").Code)
+}
+
+func TestNewServer_WithViews(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.API.Hosts = []api.HostConfig{{Host: "docs.team-x.example.com", Name: "Team X", Repos: []string{"team-x"}}}
+
+	var created int
+
+	srv, err := NewServer(t.Context(), cfg, WithViews(func(opts ...views.Option) api.ViewRenderer {
+		created++
+
+		return views.New(opts...)
+	}))
+	require.NoError(t, err)
+
+	defer func() { assert.NoError(t, srv.Close()) }()
+
+	assert.Equal(t, 2, created, "the default portal and the branded host")
+	assert.Contains(t, serve(t, srv, "/", "").Body.String(), "Omnidex")
+}
+
+func TestNewServer_InvalidConfig(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.Storage.Type = "unknown"
+
+	_, err := NewServer(t.Context(), cfg)
+	assert.ErrorContains(t, err, "unknown storage type")
+
+	// The search index opened before the failure was closed, so it can be opened again.
+	cfg.Storage.Type = ""
+
+	srv, err := NewServer(t.Context(), cfg)
+	require.NoError(t, err)
+	assert.NoError(t, srv.Close())
+}
+
+func TestNewServer_OIDCWithoutOwners(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.OIDC.Audience = "https://docs.example.com"
+
+	_, err := NewServer(t.Context(), cfg)
+	assert.ErrorContains(t, err, "owners is required")
+}
+
+func TestNewServer_EditWithoutLogin(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.Republish.Token = "github_pat_test"
+	cfg.Edit.Repos = []core.EditRepo{{Repo: "acme/wiki"}}
+
+	_, err := NewServer(t.Context(), cfg)
+	assert.ErrorContains(t, err, "edit.repos requires readers to sign in")
+}
+
+func TestNewServer_HistoryRetention(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.Retention.History = 30 * 24 * time.Hour
+
+	_, err := NewServer(t.Context(), cfg)
+	assert.ErrorContains(t, err, "retention.history requires the git storage backend")
+
+	cfg.Storage.Type = "git"
+
+	srv, err := NewServer(t.Context(), cfg)
+	require.NoError(t, err)
+
+	defer func() { assert.NoError(t, srv.Close()) }()
+
+	assert.NoError(t, srv.Service().EnforceRetention(t.Context()))
+}
+
+func TestServer_Run(t *testing.T) {
+	srv, err := NewServer(t.Context(), newTestConfig(t))
+	require.NoError(t, err)
+
+	defer func() { assert.NoError(t, srv.Close()) }()
+
+	ctx, cancel := context.WithCancel(t.Context())
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+
+	assert.NoError(t, srv.Run(ctx))
+}
+
+func TestNewSearchMigration_InvalidTarget(t *testing.T) {
+	_, err := newSearchMigration(t.Context(), &SearchConfig{Type: "meilisearch", Migration: SearchMigrationConfig{Target: "meilisearch"}})
+	assert.ErrorContains(t, err, "must differ from search.type")
+
+	_, err = newSearchMigration(t.Context(), &SearchConfig{Migration: SearchMigrationConfig{Target: "bleve"}})
+	assert.ErrorContains(t, err, "unknown search.migration.target")
+}
+
+func TestNextDigest(t *testing.T) {
+	monday9 := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, monday9, nextDigest(time.Date(2026, 1, 5, 8, 0, 0, 0, time.UTC), time.Monday, 9*time.Hour))
+	assert.Equal(t, monday9.AddDate(0, 0, 7), nextDigest(monday9, time.Monday, 9*time.Hour), "a digest due now is sent next week")
+	assert.Equal(t, time.Date(2026, 1, 9, 16, 30, 0, 0, time.UTC),
+		nextDigest(monday9, time.Friday, 16*time.Hour+30*time.Minute))
+	assert.Equal(t, monday9, nextDigest(time.Date(2026, 1, 4, 20, 0, 0, 0, time.FixedZone("PST", -8*3600)), time.Monday, 9*time.Hour),
+		"times are compared in UTC")
+}
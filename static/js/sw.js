@@ -0,0 +1,110 @@
+/* Omnidex service worker: instant navigation between documents.
+ *
+ * The page asks the worker to prefetch documents linked from the sidebar when the
+ * user hovers or focuses a link. Prefetched HTMX partial responses are kept in memory
+ * for a short time and served to the matching HTMX navigation request, so switching
+ * between documents does not wait for the network. Every other request goes straight
+ * to the network.
+ */
+'use strict';
+
+var PREFETCH_TTL_MS = 60 * 1000;
+var PREFETCH_MAX_ENTRIES = 20;
+var PREFETCH_CONCURRENCY = 2;
+var PREFETCH_QUEUE_LIMIT = 8;
+
+var entries = new Map(); // url -> {promise, expires}
+var queue = [];
+var inFlight = 0;
+
+self.addEventListener('install', function() {
+    self.skipWaiting();
+});
+
+self.addEventListener('activate', function(event) {
+    event.waitUntil(self.clients.claim());
+});
+
+function isDocPartial(request) {
+    if (request.method !== 'GET' || request.headers.get('HX-Request') !== 'true') return false;
+    var url = new URL(request.url);
+    return url.origin === self.location.origin && url.pathname.indexOf('/docs/') === 0;
+}
+
+function prune() {
+    var now = Date.now();
+    entries.forEach(function(entry, url) {
+        if (entry.expires < now) entries.delete(url);
+    });
+    while (entries.size > PREFETCH_MAX_ENTRIES) {
+        entries.delete(entries.keys().next().value);
+    }
+}
+
+function runQueue() {
+    while (inFlight < PREFETCH_CONCURRENCY && queue.length > 0) {
+        var job = queue.shift();
+        inFlight++;
+        fetch(job.url, { credentials: 'same-origin', headers: { 'HX-Request': 'true' } })
+            .then(function(resp) {
+                if (!resp.ok) throw new Error('HTTP ' + resp.status);
+                return resp;
+            })
+            .then(job.resolve, job.reject)
+            .finally(function() {
+                inFlight--;
+                runQueue();
+            });
+    }
+}
+
+function prefetch(url) {
+    prune();
+    if (entries.has(url)) return;
+    if (queue.length >= PREFETCH_QUEUE_LIMIT) {
+        // Drop the oldest pending prefetch: the user has moved on from that link.
+        var dropped = queue.shift();
+        entries.delete(dropped.url);
+        dropped.reject(new Error('prefetch dropped'));
+    }
+    var job = { url: url };
+    var promise = new Promise(function(resolve, reject) {
+        job.resolve = resolve;
+        job.reject = reject;
+    });
+    // A failed prefetch is forgotten so the navigation falls back to the network.
+    promise.catch(function() {
+        if (entries.get(url) && entries.get(url).promise === promise) entries.delete(url);
+    });
+    entries.set(url, { promise: promise, expires: Date.now() + PREFETCH_TTL_MS });
+    queue.push(job);
+    runQueue();
+}
+
+self.addEventListener('message', function(event) {
+    var data = event.data;
+    if (!data || data.type !== 'prefetch' || typeof data.url !== 'string') return;
+    var url;
+    try {
+        url = new URL(data.url, self.location.origin);
+    } catch (e) {
+        return;
+    }
+    if (url.origin !== self.location.origin || url.pathname.indexOf('/docs/') !== 0) return;
+    url.hash = '';
+    prefetch(url.href);
+});
+
+self.addEventListener('fetch', function(event) {
+    if (!isDocPartial(event.request)) return;
+    var url = new URL(event.request.url);
+    url.hash = '';
+    prune();
+    var entry = entries.get(url.href);
+    if (!entry) return;
+    // Prefetched responses are used once so later visits see fresh content.
+    entries.delete(url.href);
+    event.respondWith(entry.promise.catch(function() {
+        return fetch(event.request);
+    }));
+});